@@ -2,10 +2,14 @@
 //
 // 演示如何创建一个 MCP 服务器，注册工具并运行。
 // 运行方式: go run examples/mcp/server/main.go
+// HTTP 模式: go run examples/mcp/server/main.go -http :8080
+// （Streamable HTTP 端点 /mcp，旧版 SSE 端点 /sse）
+// WebSocket 模式: go run examples/mcp/server/main.go -ws :8081
 package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"os"
 	"os/signal"
@@ -15,6 +19,10 @@ import (
 )
 
 func main() {
+	httpAddr := flag.String("http", "", "listen address for HTTP mode (stdio when empty)")
+	wsAddr := flag.String("ws", "", "listen address for WebSocket mode")
+	flag.Parse()
+
 	// 创建 MCP 服务器
 	server := mcp.NewServer("example-server", "Example MCP Server with calculator and greeting tools")
 
@@ -120,6 +128,31 @@ func main() {
 		},
 	})
 
+	// 添加天气资源模板，客户端读取 weather://beijing 等 URI
+	if err := server.AddResourceTemplate(mcp.ServerResourceTemplate{
+		URITemplate: "weather://{city}",
+		Name:        "Weather",
+		Description: "Get the weather of a city",
+		MimeType:    "text/plain",
+		Handler: func(ctx context.Context, uri string, params map[string]string) (string, error) {
+			return fmt.Sprintf("%s: sunny, 25°C", params["city"]), nil
+		},
+	}); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to add resource template: %v\n", err)
+		os.Exit(1)
+	}
+
+	// 添加提示词模板
+	server.AddPrompt(mcp.ServerPrompt{
+		Name:        "summarize",
+		Description: "Summarize a piece of text",
+		Arguments: []mcp.PromptArgument{
+			{Name: "text", Description: "Text to summarize", Required: true},
+			{Name: "style", Description: "Summary style, e.g. bullet points"},
+		},
+		Handler: mcp.TemplatePromptHandler("Summarize the following text ({{style}}):\n\n{{text}}"),
+	})
+
 	// 设置信号处理
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -135,13 +168,29 @@ func main() {
 	// 打印服务器信息
 	fmt.Fprintln(os.Stderr, "🚀 Starting MCP Server...")
 	fmt.Fprintln(os.Stderr, "📝 Server: example-server")
-	fmt.Fprintln(os.Stderr, "🔌 Protocol: MCP (stdio)")
+	switch {
+	case *httpAddr != "":
+		fmt.Fprintf(os.Stderr, "🔌 Protocol: MCP (http, %s)\n", *httpAddr)
+	case *wsAddr != "":
+		fmt.Fprintf(os.Stderr, "🔌 Protocol: MCP (websocket, %s)\n", *wsAddr)
+	default:
+		fmt.Fprintln(os.Stderr, "🔌 Protocol: MCP (stdio)")
+	}
 	fmt.Fprintln(os.Stderr, "🛠️  Tools: calculator, greet")
 	fmt.Fprintln(os.Stderr, "📁 Resources: info://system")
 	fmt.Fprintln(os.Stderr, "")
 
-	// 运行服务器（Stdio 模式）
-	if err := server.Run(ctx, os.Stdin, os.Stdout); err != nil {
+	// 运行服务器（Stdio、HTTP 或 WebSocket 模式）
+	var err error
+	switch {
+	case *httpAddr != "":
+		err = server.RunHTTP(ctx, *httpAddr)
+	case *wsAddr != "":
+		err = server.RunWebSocket(ctx, *wsAddr)
+	default:
+		err = server.Run(ctx, os.Stdin, os.Stdout)
+	}
+	if err != nil {
 		if err != context.Canceled {
 			fmt.Fprintf(os.Stderr, "Server error: %v\n", err)
 			os.Exit(1)
@@ -6,10 +6,8 @@ import (
 	"fmt"
 	"log"
 	"os"
-	"strings"
 
 	"github.com/ahhsitt/helloagents-go/pkg/core/llm"
-	"github.com/ahhsitt/helloagents-go/pkg/core/message"
 	"github.com/ahhsitt/helloagents-go/pkg/rag"
 )
 
@@ -62,9 +60,8 @@ func main() {
 	retriever := rag.NewVectorRetriever(store, embedder, rag.WithScoreThreshold(0.5))
 	pipeline.SetRetriever(retriever)
 
-	// 创建回答生成器
-	generator := &llmAnswerGenerator{provider: provider}
-	pipeline.SetGenerator(generator)
+	// 创建回答生成器（支持流式输出）
+	pipeline.SetGenerator(rag.NewLLMAnswerGenerator(provider))
 
 	// 测试查询
 	queries := []string{
@@ -76,20 +73,28 @@ func main() {
 	for _, query := range queries {
 		fmt.Printf("\n--- Query: %s ---\n", query)
 
-		response, err := pipeline.Query(ctx, query, 3)
+		// 来源在检索完成后即可展示，回答逐片段输出
+		stream, err := pipeline.QueryStream(ctx, query, 3)
 		if err != nil {
 			log.Printf("Query failed: %v", err)
 			continue
 		}
 
-		fmt.Printf("\nAnswer:\n%s\n", response.Answer)
-
-		if len(response.Sources) > 0 {
+		if len(stream.Sources) > 0 {
 			fmt.Println("\nSources:")
-			for i, src := range response.Sources {
+			for i, src := range stream.Sources {
 				fmt.Printf("  %d. [Score: %.2f] %s\n", i+1, src.Score, truncate(src.Content, 80))
 			}
 		}
+
+		fmt.Print("\nAnswer:\n")
+		for chunk := range stream.Answer {
+			fmt.Print(chunk)
+		}
+		fmt.Println()
+		if err := <-stream.Err; err != nil {
+			log.Printf("Generation failed: %v", err)
+		}
 	}
 
 	fmt.Println("\n=== Example Complete ===")
@@ -150,37 +155,6 @@ func (e *llmEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, e
 	return e.provider.Embed(ctx, texts)
 }
 
-// llmAnswerGenerator LLM 回答生成器
-type llmAnswerGenerator struct {
-	provider llm.Provider
-}
-
-func (g *llmAnswerGenerator) Generate(ctx context.Context, query string, ragContext *rag.RAGContext) (string, error) {
-	// 构建提示
-	var contextText strings.Builder
-	contextText.WriteString("Based on the following context, answer the question.\n\n")
-	contextText.WriteString("Context:\n")
-	for _, result := range ragContext.Results {
-		contextText.WriteString(fmt.Sprintf("- %s\n", result.Chunk.Content))
-	}
-	contextText.WriteString(fmt.Sprintf("\nQuestion: %s\n\nAnswer:", query))
-
-	// 调用 LLM
-	resp, err := g.provider.Generate(ctx, llm.Request{
-		Messages: []message.Message{
-			{
-				Role:    message.RoleUser,
-				Content: contextText.String(),
-			},
-		},
-	})
-	if err != nil {
-		return "", err
-	}
-
-	return resp.Content, nil
-}
-
 // truncate 截断字符串
 func truncate(s string, maxLen int) string {
 	if len(s) <= maxLen {
@@ -80,6 +80,8 @@ type StreamChunk struct {
 	Content string `json:"content"`
 	// Step 推理步骤（当 Type=ChunkStep 时）
 	Step *ReasoningStep `json:"step,omitempty"`
+	// Event 进度事件（当 Type=ChunkTypeEvent 时）
+	Event *StreamEvent `json:"event,omitempty"`
 	// Done 是否完成
 	Done bool `json:"done"`
 }
@@ -96,6 +98,8 @@ const (
 	ChunkTypeTool ChunkType = "tool"
 	// ChunkTypeError 错误信息
 	ChunkTypeError ChunkType = "error"
+	// ChunkTypeEvent 类型化进度事件
+	ChunkTypeEvent ChunkType = "event"
 	// ChunkTypeDone 完成标志
 	ChunkTypeDone ChunkType = "done"
 )
@@ -0,0 +1,206 @@
+package agents
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	agentctx "github.com/ahhsitt/helloagents-go/pkg/context"
+	"github.com/ahhsitt/helloagents-go/pkg/core/message"
+	"github.com/ahhsitt/helloagents-go/pkg/tools"
+)
+
+// budgetWarningRatio 迭代次数用到该比例时发出预算告警
+const budgetWarningRatio = 0.8
+
+// EventType 流式事件类型
+type EventType string
+
+const (
+	// EventStepStarted 开始新的推理迭代或计划步骤
+	EventStepStarted EventType = "step_started"
+	// EventToolCallStarted 开始调用工具
+	EventToolCallStarted EventType = "tool_call_started"
+	// EventToolCallFinished 工具调用结束
+	EventToolCallFinished EventType = "tool_call_finished"
+	// EventCompressionApplied 上下文构建时压缩了内容以适应 Token 预算
+	EventCompressionApplied EventType = "compression_applied"
+	// EventBudgetWarning 迭代次数接近上限
+	EventBudgetWarning EventType = "budget_warning"
+	// EventUnknownsDetected 上下文中缺少查询关键词的证据
+	EventUnknownsDetected EventType = "unknowns_detected"
+)
+
+// StreamEvent RunStream 中的类型化进度事件（当 StreamChunk.Type=ChunkTypeEvent 时）
+//
+// 事件在运行过程中实时发出，UI 可据此展示进度，无需事后解析步骤文本。
+type StreamEvent struct {
+	// Type 事件类型
+	Type EventType `json:"type"`
+	// Iteration 迭代或计划步骤序号（从 1 开始）
+	Iteration int `json:"iteration,omitempty"`
+	// ToolName 工具名称（工具事件）
+	ToolName string `json:"tool_name,omitempty"`
+	// ToolCallID 工具调用 ID（工具事件）
+	ToolCallID string `json:"tool_call_id,omitempty"`
+	// ToolArgs 工具参数（EventToolCallStarted）
+	ToolArgs map[string]interface{} `json:"tool_args,omitempty"`
+	// Duration 工具调用耗时（EventToolCallFinished）
+	Duration time.Duration `json:"duration,omitempty"`
+	// Success 工具调用是否成功（EventToolCallFinished）
+	Success bool `json:"success,omitempty"`
+	// Error 工具调用的错误信息（EventToolCallFinished）
+	Error string `json:"error,omitempty"`
+	// Tokens 压缩后的上下文 token 数（EventCompressionApplied）
+	Tokens int `json:"tokens,omitempty"`
+	// Unknowns 缺少证据覆盖的查询关键词（EventUnknownsDetected）
+	Unknowns []string `json:"unknowns,omitempty"`
+	// Message 事件说明
+	Message string `json:"message,omitempty"`
+	// Timestamp 事件时间
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// eventSinkKey 事件接收函数在 context 中的键
+type eventSinkKey struct{}
+
+// withEventSink 在 ctx 中设置事件接收函数
+func withEventSink(ctx context.Context, sink func(StreamEvent)) context.Context {
+	return context.WithValue(ctx, eventSinkKey{}, sink)
+}
+
+// emitEvent 将事件发送给 ctx 中的接收函数（未设置时忽略）
+func emitEvent(ctx context.Context, event StreamEvent) {
+	sink, ok := ctx.Value(eventSinkKey{}).(func(StreamEvent))
+	if !ok {
+		return
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+	sink(event)
+}
+
+// emitStepStarted 发出迭代开始事件，并在迭代次数接近上限时发出预算告警
+func emitStepStarted(ctx context.Context, iteration, maxIterations int, description string) {
+	emitEvent(ctx, StreamEvent{Type: EventStepStarted, Iteration: iteration, Message: description})
+
+	if threshold := int(float64(maxIterations) * budgetWarningRatio); threshold > 0 && iteration == threshold+1 {
+		emitEvent(ctx, StreamEvent{
+			Type:      EventBudgetWarning,
+			Iteration: iteration,
+			Message:   fmt.Sprintf("iteration %d of %d, %d remaining", iteration, maxIterations, maxIterations-iteration),
+		})
+	}
+}
+
+// buildContextMessages 使用 ContextBuilder 构建消息，构建器支持报告且发生压缩时发出压缩事件
+//
+// clarify 为 true 且报告中存在缺少证据的关键词时，发出 EventUnknownsDetected 事件并在系统消息中追加澄清提示。
+func buildContextMessages(ctx context.Context, builder agentctx.Builder, input *agentctx.BuildInput, clarify bool) ([]message.Message, error) {
+	rb, ok := builder.(agentctx.ReportingBuilder)
+	if !ok {
+		return builder.BuildMessages(ctx, input)
+	}
+
+	messages, report, err := rb.BuildMessagesWithReport(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	if report != nil && report.Compressed {
+		emitEvent(ctx, StreamEvent{
+			Type:    EventCompressionApplied,
+			Tokens:  report.Tokens,
+			Message: fmt.Sprintf("context compressed to %d tokens", report.Tokens),
+		})
+	}
+	if clarify && report != nil && report.HasUnknowns() {
+		emitEvent(ctx, StreamEvent{
+			Type:     EventUnknownsDetected,
+			Unknowns: report.Unknowns,
+			Message:  fmt.Sprintf("no evidence for %s", strings.Join(report.Unknowns, ", ")),
+		})
+		messages = appendClarificationNote(messages, report.Unknowns)
+	}
+	return messages, nil
+}
+
+// appendClarificationNote 在首条系统消息末尾追加澄清提示，没有系统消息时在开头插入一条
+func appendClarificationNote(messages []message.Message, unknowns []string) []message.Message {
+	note := fmt.Sprintf("The available context contains no information about: %s. "+
+		"If any of these are essential to answer, ask the user a clarifying question instead of guessing.",
+		strings.Join(unknowns, ", "))
+
+	for i := range messages {
+		if messages[i].Role == message.RoleSystem {
+			messages[i].Content += "\n\n" + note
+			return messages
+		}
+	}
+	return append([]message.Message{{Role: message.RoleSystem, Content: note}}, messages...)
+}
+
+// executeTool 执行工具调用并发出开始/结束事件
+func executeTool(ctx context.Context, executor *tools.Executor, tc message.ToolCall) tools.ToolResult {
+	emitEvent(ctx, StreamEvent{
+		Type:       EventToolCallStarted,
+		ToolName:   tc.Name,
+		ToolCallID: tc.ID,
+		ToolArgs:   tc.Arguments,
+	})
+
+	start := time.Now()
+	result := executor.Execute(ctx, tc.Name, tc.Arguments)
+
+	emitEvent(ctx, StreamEvent{
+		Type:       EventToolCallFinished,
+		ToolName:   tc.Name,
+		ToolCallID: tc.ID,
+		Duration:   time.Since(start),
+		Success:    result.Success,
+		Error:      result.Error,
+	})
+	return result
+}
+
+// streamRun 在后台执行 run 并以流式块输出
+//
+// 运行过程中的事件实时以 ChunkTypeEvent 块发出，结束后依次发出推理步骤、最终响应和完成信号。
+func streamRun(ctx context.Context, input Input, run func(context.Context, Input) (Output, error)) (<-chan StreamChunk, <-chan error) {
+	chunkChan := make(chan StreamChunk, 10)
+	errChan := make(chan error, 1)
+
+	go func() {
+		defer close(chunkChan)
+		defer close(errChan)
+
+		runCtx := withEventSink(ctx, func(event StreamEvent) {
+			select {
+			case chunkChan <- StreamChunk{Type: ChunkTypeEvent, Event: &event}:
+			case <-ctx.Done():
+			}
+		})
+
+		output, err := run(runCtx, input)
+		if err != nil {
+			errChan <- err
+		}
+
+		// 发送推理步骤
+		for i := range output.Steps {
+			step := output.Steps[i]
+			chunkChan <- StreamChunk{Type: ChunkTypeStep, Step: &step}
+		}
+
+		// 发送最终响应
+		if output.Response != "" {
+			chunkChan <- StreamChunk{Type: ChunkTypeText, Content: output.Response}
+		}
+
+		// 发送完成信号
+		chunkChan <- StreamChunk{Type: ChunkTypeDone, Done: true}
+	}()
+
+	return chunkChan, errChan
+}
@@ -4,6 +4,8 @@ import (
 	"time"
 
 	agentctx "github.com/ahhsitt/helloagents-go/pkg/context"
+	"github.com/ahhsitt/helloagents-go/pkg/memory"
+	"github.com/ahhsitt/helloagents-go/pkg/tools"
 )
 
 // Option Agent 配置选项函数
@@ -18,6 +20,15 @@ type AgentOptions struct {
 	MaxTokens      int
 	Timeout        time.Duration
 	ContextBuilder agentctx.Builder
+
+	// ScratchpadOptions 配置每次运行创建的工具暂存区
+	ScratchpadOptions []tools.ScratchpadOption
+
+	// Procedures 程序性记忆，用于召回和学习解决相似任务的工具调用序列
+	Procedures *memory.ProceduralMemoryStore
+
+	// ClarifyOnUnknowns 查询关键词缺少证据覆盖时提示模型向用户澄清
+	ClarifyOnUnknowns bool
 }
 
 // DefaultAgentOptions 返回默认选项
@@ -82,3 +93,35 @@ func WithContextBuilder(builder agentctx.Builder) Option {
 		o.ContextBuilder = builder
 	}
 }
+
+// WithScratchpadLimits 设置工具暂存区的大小上限（字节）
+//
+// 每次 Run 都会创建新的暂存区供工具共享中间数据，Run 结束时清空。
+func WithScratchpadLimits(maxEntrySize, maxTotalSize int) Option {
+	return func(o *AgentOptions) {
+		o.ScratchpadOptions = append(o.ScratchpadOptions,
+			tools.WithScratchMaxEntrySize(maxEntrySize),
+			tools.WithScratchMaxTotalSize(maxTotalSize),
+		)
+	}
+}
+
+// WithProceduralMemory 设置程序性记忆
+//
+// ReActAgent 在 Run 开始时召回与任务相似的程序并作为参考提供给模型，
+// 结束时更新所遵循程序的成功/失败次数，或将成功的工具调用序列学习为新程序。
+func WithProceduralMemory(procedures *memory.ProceduralMemoryStore) Option {
+	return func(o *AgentOptions) {
+		o.Procedures = procedures
+	}
+}
+
+// WithClarifyOnUnknowns 设置是否在查询关键词缺少证据时提示模型澄清
+//
+// 需要配合支持报告的 ContextBuilder 使用。启用后，若构建报告中存在没有任何证据或记忆覆盖的关键词，
+// Agent 会发出 EventUnknownsDetected 事件，并在系统消息中提示模型先向用户提出澄清问题，而不是猜测作答。
+func WithClarifyOnUnknowns(enabled bool) Option {
+	return func(o *AgentOptions) {
+		o.ClarifyOnUnknowns = enabled
+	}
+}
@@ -156,6 +156,10 @@ func (a *PlanAndSolveAgent) Run(ctx context.Context, input Input) (Output, error
 		defer cancel()
 	}
 
+	// Scratchpad shared by tools within this run, cleared when the run ends
+	ctx, release := withRunScratchpad(ctx, a.options.ScratchpadOptions)
+	defer release()
+
 	// Pre-allocate steps slice with reasonable capacity
 	steps := make([]ReasoningStep, 0, a.config.MaxIterations+2)
 	var totalUsage message.TokenUsage
@@ -192,9 +196,15 @@ func (a *PlanAndSolveAgent) Run(ctx context.Context, input Input) (Output, error
 		}
 
 		if i >= a.config.MaxIterations {
+			emitEvent(ctx, StreamEvent{
+				Type:    EventBudgetWarning,
+				Message: fmt.Sprintf("plan has %d steps, only the first %d are executed", len(plan.Steps), a.config.MaxIterations),
+			})
 			break
 		}
 
+		emitStepStarted(ctx, i+1, a.config.MaxIterations, planStep.Description)
+
 		result, stepUsage, err := a.executeStep(ctx, input, planStep, stepResults)
 		if err != nil {
 			steps = append(steps, NewObservationStep(fmt.Sprintf("step_%d", planStep.ID),
@@ -335,7 +345,7 @@ func (a *PlanAndSolveAgent) executeStep(ctx context.Context, input Input, step P
 		if len(resp.ToolCalls) > 0 {
 			var results []string
 			for _, tc := range resp.ToolCalls {
-				result := a.executor.Execute(ctx, tc.Name, tc.Arguments)
+				result := executeTool(ctx, a.executor, tc)
 				if result.Success {
 					results = append(results, result.Result)
 				} else {
@@ -424,32 +434,10 @@ func (a *PlanAndSolveAgent) getToolDefinitions() []llm.ToolDefinition {
 }
 
 // RunStream 流式执行
+//
+// 计划步骤和工具调用以 ChunkTypeEvent 块实时发出，结束后发送推理步骤和最终响应。
 func (a *PlanAndSolveAgent) RunStream(ctx context.Context, input Input) (<-chan StreamChunk, <-chan error) {
-	chunkChan := make(chan StreamChunk, 10)
-	errChan := make(chan error, 1)
-
-	go func() {
-		defer close(chunkChan)
-		defer close(errChan)
-
-		output, err := a.Run(ctx, input)
-		if err != nil {
-			errChan <- err
-		}
-
-		for i := range output.Steps {
-			step := output.Steps[i]
-			chunkChan <- StreamChunk{Type: ChunkTypeStep, Step: &step}
-		}
-
-		if output.Response != "" {
-			chunkChan <- StreamChunk{Type: ChunkTypeText, Content: output.Response}
-		}
-
-		chunkChan <- StreamChunk{Type: ChunkTypeDone, Done: true}
-	}()
-
-	return chunkChan, errChan
+	return streamRun(ctx, input, a.Run)
 }
 
 func (a *PlanAndSolveAgent) addToHistory(query, response string) {
@@ -0,0 +1,126 @@
+package agents
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ahhsitt/helloagents-go/pkg/core/message"
+	"github.com/ahhsitt/helloagents-go/pkg/memory"
+)
+
+const (
+	// recallProcedureLimit 每次 Run 最多召回的程序数
+	recallProcedureLimit = 3
+	// recallProcedureMinScore 召回程序的最低得分
+	recallProcedureMinScore = 0.2
+	// procedureNameMaxRunes 学习到的程序名称的最大长度
+	procedureNameMaxRunes = 80
+)
+
+// recallProcedures 检索与任务相似的程序，出错时返回 nil
+func recallProcedures(ctx context.Context, procedures *memory.ProceduralMemoryStore, input Input) []memory.ProcedureMatch {
+	if procedures == nil {
+		return nil
+	}
+
+	opts := []memory.RetrieveOption{
+		memory.WithLimit(recallProcedureLimit),
+		memory.WithMinScore(recallProcedureMinScore),
+	}
+	if input.UserID != "" {
+		opts = append(opts, memory.WithUserIDFilter(input.UserID))
+	}
+
+	matches, err := procedures.FindProcedures(ctx, input.Query, opts...)
+	if err != nil {
+		return nil
+	}
+	return matches
+}
+
+// withProcedureHint 将召回的程序作为系统消息插入到开头的系统消息之后
+func withProcedureHint(messages []message.Message, matches []memory.ProcedureMatch) []message.Message {
+	if len(matches) == 0 {
+		return messages
+	}
+
+	var sb strings.Builder
+	sb.WriteString("Procedures that solved similar tasks before. Follow them when they fit and adapt them when they don't:")
+	for _, match := range matches {
+		p := match.Procedure
+		fmt.Fprintf(&sb, "\n\n%s\n(succeeded %d of %d times)", p.Format(), p.SuccessCount, p.SuccessCount+p.FailureCount)
+	}
+
+	pos := 0
+	for pos < len(messages) && messages[pos].Role == message.RoleSystem {
+		pos++
+	}
+
+	result := make([]message.Message, 0, len(messages)+1)
+	result = append(result, messages[:pos]...)
+	result = append(result, message.NewSystemMessage(sb.String()))
+	return append(result, messages[pos:]...)
+}
+
+// recordProcedure 记录本次 Run 的工具调用序列
+//
+// 与召回程序的工具序列一致时更新该程序的成功/失败次数；
+// 成功且没有一致的程序时，将工具序列学习为新程序。
+func recordProcedure(ctx context.Context, procedures *memory.ProceduralMemoryStore, input Input, steps []ReasoningStep, recalled []memory.ProcedureMatch, success bool) {
+	if procedures == nil {
+		return
+	}
+
+	var learned []memory.ProcedureStep
+	for _, step := range steps {
+		if step.Type == StepTypeAction {
+			learned = append(learned, memory.ProcedureStep{Tool: step.ToolName, Arguments: step.ToolArgs})
+		}
+	}
+	if len(learned) == 0 {
+		return
+	}
+
+	followed := false
+	for _, match := range recalled {
+		if sameToolSequence(match.Procedure.Steps, learned) {
+			_ = procedures.RecordOutcome(ctx, match.Procedure.ID, success)
+			followed = true
+		}
+	}
+	if followed || !success {
+		return
+	}
+
+	_, _ = procedures.AddProcedure(ctx, memory.Procedure{
+		Name:         truncateRunes(input.Query, procedureNameMaxRunes),
+		Triggers:     []string{input.Query},
+		Steps:        learned,
+		SuccessCount: 1,
+		Importance:   0.5,
+		UserID:       input.UserID,
+	})
+}
+
+// sameToolSequence 判断两组步骤的工具调用顺序是否一致
+func sameToolSequence(a, b []memory.ProcedureStep) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Tool != b[i].Tool {
+			return false
+		}
+	}
+	return true
+}
+
+// truncateRunes 按字符截断文本
+func truncateRunes(s string, n int) string {
+	runes := []rune(strings.TrimSpace(s))
+	if len(runes) <= n {
+		return string(runes)
+	}
+	return string(runes[:n]) + "..."
+}
@@ -135,13 +135,19 @@ func (a *ReActAgent) Run(ctx context.Context, input Input) (Output, error) {
 		defer cancel()
 	}
 
+	// 工具共享的暂存区，Run 结束时清空
+	ctx, release := withRunScratchpad(ctx, a.options.ScratchpadOptions)
+	defer release()
+
 	var steps []ReasoningStep
 	var totalUsage message.TokenUsage
 
-	// 构建初始消息
-	messages := a.buildMessages(input)
+	// 构建初始消息，附上召回的相似任务程序
+	recalled := recallProcedures(ctx, a.options.Procedures, input)
+	messages := withProcedureHint(a.buildMessages(ctx, input), recalled)
 
-	// 获取工具定义
+	// 获取工具定义，记录对应的注册表版本
+	toolsVersion := a.registry.Version()
 	toolDefs := a.getToolDefinitions()
 
 	// ReAct 循环
@@ -158,6 +164,14 @@ func (a *ReActAgent) Run(ctx context.Context, input Input) (Output, error) {
 		default:
 		}
 
+		emitStepStarted(ctx, iteration+1, a.config.MaxIterations, "")
+
+		// 运行期间工具列表变化（如提供者热注册）时刷新工具定义
+		if version := a.registry.Version(); version != toolsVersion {
+			toolsVersion = version
+			toolDefs = a.getToolDefinitions()
+		}
+
 		// 构建 LLM 请求
 		temp := a.config.Temperature
 		maxTokens := a.config.MaxTokens
@@ -189,6 +203,7 @@ func (a *ReActAgent) Run(ctx context.Context, input Input) (Output, error) {
 		if len(resp.ToolCalls) == 0 {
 			// 没有工具调用，返回最终答案
 			a.addToHistory(input.Query, resp.Content)
+			recordProcedure(ctx, a.options.Procedures, input, steps, recalled, true)
 
 			return Output{
 				Response:   resp.Content,
@@ -217,17 +232,20 @@ func (a *ReActAgent) Run(ctx context.Context, input Input) (Output, error) {
 			steps = append(steps, NewActionStep(tc.Name, tc.Arguments))
 
 			// 执行工具
-			result := a.executor.Execute(ctx, tc.Name, tc.Arguments)
+			result := executeTool(ctx, a.executor, tc)
 
 			// 记录观察步骤
 			if result.Success {
-				steps = append(steps, NewObservationStep(tc.Name, result.Result))
+				observation := NewObservationStep(tc.Name, result.Result)
+				observation.ToolData = result.Data
+				steps = append(steps, observation)
 			} else {
 				steps = append(steps, NewObservationStep(tc.Name, fmt.Sprintf("Error: %s", result.Error)))
 			}
 
 			// 添加工具结果消息
 			toolMsg := message.NewToolMessage(tc.ID, tc.Name, result.Result)
+			toolMsg.StructuredContent = result.Data
 			if !result.Success {
 				toolMsg.Content = fmt.Sprintf("Error: %s", result.Error)
 			}
@@ -236,6 +254,7 @@ func (a *ReActAgent) Run(ctx context.Context, input Input) (Output, error) {
 	}
 
 	// 超出最大迭代次数
+	recordProcedure(ctx, a.options.Procedures, input, steps, recalled, false)
 	return Output{
 		Steps:      steps,
 		TokenUsage: totalUsage,
@@ -245,61 +264,25 @@ func (a *ReActAgent) Run(ctx context.Context, input Input) (Output, error) {
 }
 
 // RunStream 以流式方式执行 ReAct
+//
+// 迭代、工具调用、上下文压缩和预算告警以 ChunkTypeEvent 块实时发出，
+// 结束后发送推理步骤和最终响应。
 func (a *ReActAgent) RunStream(ctx context.Context, input Input) (<-chan StreamChunk, <-chan error) {
-	chunkChan := make(chan StreamChunk, 10)
-	errChan := make(chan error, 1)
-
-	go func() {
-		defer close(chunkChan)
-		defer close(errChan)
-
-		// 对于 ReAct，我们逐步发送推理过程
-		// 由于工具调用的复杂性，这里使用非流式方式执行，然后发送步骤
-
-		output, err := a.Run(ctx, input)
-		if err != nil {
-			errChan <- err
-		}
-
-		// 发送推理步骤
-		for i := range output.Steps {
-			step := output.Steps[i]
-			chunkChan <- StreamChunk{
-				Type: ChunkTypeStep,
-				Step: &step,
-			}
-		}
-
-		// 发送最终响应
-		if output.Response != "" {
-			chunkChan <- StreamChunk{
-				Type:    ChunkTypeText,
-				Content: output.Response,
-			}
-		}
-
-		// 发送完成信号
-		chunkChan <- StreamChunk{
-			Type: ChunkTypeDone,
-			Done: true,
-		}
-	}()
-
-	return chunkChan, errChan
+	return streamRun(ctx, input, a.Run)
 }
 
 // buildMessages 构建初始消息列表
-func (a *ReActAgent) buildMessages(input Input) []message.Message {
+func (a *ReActAgent) buildMessages(ctx context.Context, input Input) []message.Message {
 	// 如果配置了 ContextBuilder，使用它来构建消息
 	if a.options.ContextBuilder != nil {
-		return a.buildMessagesWithContextBuilder(input)
+		return a.buildMessagesWithContextBuilder(ctx, input)
 	}
 
 	return a.buildMessagesSimple(input)
 }
 
 // buildMessagesWithContextBuilder 使用 ContextBuilder 构建消息
-func (a *ReActAgent) buildMessagesWithContextBuilder(input Input) []message.Message {
+func (a *ReActAgent) buildMessagesWithContextBuilder(ctx context.Context, input Input) []message.Message {
 	a.mu.RLock()
 	history := make([]message.Message, len(a.history))
 	copy(history, a.history)
@@ -311,7 +294,7 @@ func (a *ReActAgent) buildMessagesWithContextBuilder(input Input) []message.Mess
 		History:            history,
 	}
 
-	messages, err := a.options.ContextBuilder.BuildMessages(context.Background(), buildInput)
+	messages, err := buildContextMessages(ctx, a.options.ContextBuilder, buildInput, a.options.ClarifyOnUnknowns)
 	if err != nil {
 		// 降级到简单构建
 		return a.buildMessagesSimple(input)
@@ -152,6 +152,8 @@ func (a *ReflectionAgent) Run(ctx context.Context, input Input) (Output, error)
 		default:
 		}
 
+		emitStepStarted(ctx, iteration, a.config.MaxIterations-1, "reflection")
+
 		// Build reflection prompt
 		reflectionPrompt := fmt.Sprintf(reflectionPromptTemplate, currentResponse)
 		reflectionMessages := append(messages,
@@ -193,33 +195,11 @@ func (a *ReflectionAgent) Run(ctx context.Context, input Input) (Output, error)
 	}, nil
 }
 
-// RunStream 流式执行（简化实现，逐步发送结果）
+// RunStream 流式执行
+//
+// 每轮反思开始时发出 ChunkTypeEvent 块，结束后发送推理步骤和最终响应。
 func (a *ReflectionAgent) RunStream(ctx context.Context, input Input) (<-chan StreamChunk, <-chan error) {
-	chunkChan := make(chan StreamChunk, 10)
-	errChan := make(chan error, 1)
-
-	go func() {
-		defer close(chunkChan)
-		defer close(errChan)
-
-		output, err := a.Run(ctx, input)
-		if err != nil {
-			errChan <- err
-		}
-
-		for i := range output.Steps {
-			step := output.Steps[i]
-			chunkChan <- StreamChunk{Type: ChunkTypeStep, Step: &step}
-		}
-
-		if output.Response != "" {
-			chunkChan <- StreamChunk{Type: ChunkTypeText, Content: output.Response}
-		}
-
-		chunkChan <- StreamChunk{Type: ChunkTypeDone, Done: true}
-	}()
-
-	return chunkChan, errChan
+	return streamRun(ctx, input, a.Run)
 }
 
 func (a *ReflectionAgent) buildMessages(input Input) []message.Message {
@@ -0,0 +1,19 @@
+package agents
+
+import (
+	"context"
+
+	"github.com/ahhsitt/helloagents-go/pkg/tools"
+)
+
+// withRunScratchpad 为一次 Run 准备工具暂存区
+//
+// ctx 中已有暂存区时（如 Agent 作为工具被另一个 Agent 调用）沿用外层暂存区，
+// 由外层负责清空；否则创建新的暂存区，返回的 release 在 Run 结束时清空它。
+func withRunScratchpad(ctx context.Context, opts []tools.ScratchpadOption) (context.Context, func()) {
+	if _, ok := tools.ScratchpadFromContext(ctx); ok {
+		return ctx, func() {}
+	}
+	scratch := tools.NewScratchpad(opts...)
+	return tools.ContextWithScratchpad(ctx, scratch), scratch.Clear
+}
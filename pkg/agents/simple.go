@@ -103,7 +103,7 @@ func (a *SimpleAgent) Run(ctx context.Context, input Input) (Output, error) {
 	}
 
 	// 构建消息列表
-	messages := a.buildMessages(input)
+	messages := a.buildMessages(ctx, input)
 
 	// 构建 LLM 请求
 	temp := a.config.Temperature
@@ -154,7 +154,7 @@ func (a *SimpleAgent) RunStream(ctx context.Context, input Input) (<-chan Stream
 		}
 
 		// 构建消息列表
-		messages := a.buildMessages(input)
+		messages := a.buildMessages(ctx, input)
 
 		// 构建 LLM 请求
 		temp := a.config.Temperature
@@ -215,17 +215,17 @@ func (a *SimpleAgent) RunStream(ctx context.Context, input Input) (<-chan Stream
 }
 
 // buildMessages 构建发送给 LLM 的消息列表
-func (a *SimpleAgent) buildMessages(input Input) []message.Message {
+func (a *SimpleAgent) buildMessages(ctx context.Context, input Input) []message.Message {
 	// 如果配置了 ContextBuilder，使用它来构建消息
 	if a.options.ContextBuilder != nil {
-		return a.buildMessagesWithContextBuilder(input)
+		return a.buildMessagesWithContextBuilder(ctx, input)
 	}
 
 	return a.buildMessagesSimple(input)
 }
 
 // buildMessagesWithContextBuilder 使用 ContextBuilder 构建消息
-func (a *SimpleAgent) buildMessagesWithContextBuilder(input Input) []message.Message {
+func (a *SimpleAgent) buildMessagesWithContextBuilder(ctx context.Context, input Input) []message.Message {
 	a.mu.RLock()
 	history := make([]message.Message, len(a.history))
 	copy(history, a.history)
@@ -237,7 +237,7 @@ func (a *SimpleAgent) buildMessagesWithContextBuilder(input Input) []message.Mes
 		History:            history,
 	}
 
-	messages, err := a.options.ContextBuilder.BuildMessages(context.Background(), buildInput)
+	messages, err := buildContextMessages(ctx, a.options.ContextBuilder, buildInput, a.options.ClarifyOnUnknowns)
 	if err != nil {
 		// 降级到简单构建
 		return a.buildMessagesSimple(input)
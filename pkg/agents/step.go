@@ -14,6 +14,8 @@ type ReasoningStep struct {
 	ToolArgs map[string]interface{} `json:"tool_args,omitempty"`
 	// ToolResult 工具结果（当 Type=observation 时）
 	ToolResult string `json:"tool_result,omitempty"`
+	// ToolData 工具的结构化结果（当 Type=observation 且工具返回结构化结果时）
+	ToolData interface{} `json:"tool_data,omitempty"`
 	// Timestamp 时间戳
 	Timestamp time.Time `json:"timestamp"`
 }
@@ -2,8 +2,20 @@ package context
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/ahhsitt/helloagents-go/pkg/core/message"
+	"github.com/ahhsitt/helloagents-go/pkg/otel"
+)
+
+// 慢收集器告警的默认阈值
+const (
+	// DefaultSlowGathererRatio 是收集器耗时占整个构建耗时的告警比例。
+	DefaultSlowGathererRatio = 0.5
+
+	// DefaultSlowGathererMinDuration 是触发告警的最小收集器耗时。
+	DefaultSlowGathererMinDuration = 100 * time.Millisecond
 )
 
 // Builder 定义构建上下文的接口。
@@ -16,6 +28,16 @@ type Builder interface {
 	BuildMessages(ctx context.Context, input *BuildInput) ([]message.Message, error)
 }
 
+// ReportingBuilder 是能在构建消息时返回诊断报告的构建器。
+//
+// Agent 借此在流式输出中报告压缩等构建事件。
+type ReportingBuilder interface {
+	Builder
+
+	// BuildMessagesWithReport 构建消息列表并返回诊断报告。
+	BuildMessagesWithReport(ctx context.Context, input *BuildInput) ([]message.Message, *BuildReport, error)
+}
+
 // BuildInput 包含上下文构建的所有输入数据。
 type BuildInput struct {
 	// Query 是当前用户查询。
@@ -29,6 +51,15 @@ type BuildInput struct {
 
 	// AdditionalPackets 是要包含的额外上下文包。
 	AdditionalPackets []*Packet
+
+	// Style 是本次请求使用的回答风格，优先于 StyleName 和 Config.Style。
+	Style *StyleProfile
+
+	// StyleName 按名称选择通过 WithStyleProfiles 注册的回答风格。
+	StyleName string
+
+	// Task 是结构化的任务元数据（语言区域、紧急程度、用户等级、功能开关）。
+	Task *TaskMeta
 }
 
 // GSSCBuilder 实现 GSSC（收集-筛选-结构化-压缩）流水线。
@@ -38,6 +69,13 @@ type GSSCBuilder struct {
 	selector   Selector
 	structurer Structurer
 	compressor Compressor
+
+	styles map[string]*StyleProfile
+
+	metrics         otel.Metrics
+	logger          otel.Logger
+	slowRatio       float64
+	slowMinDuration time.Duration
 }
 
 // BuilderOption 配置 GSSCBuilder。
@@ -78,10 +116,55 @@ func WithCompressor(compressor Compressor) BuilderOption {
 	}
 }
 
+// WithStyleProfiles 注册可通过 BuildInput.StyleName 选择的回答风格。
+//
+// 内置的 RunbookStyle 和 ChatStyle 默认已注册，同名风格会被覆盖。
+func WithStyleProfiles(profiles ...*StyleProfile) BuilderOption {
+	return func(b *GSSCBuilder) {
+		for _, profile := range profiles {
+			if profile != nil {
+				b.styles[profile.Name] = profile
+			}
+		}
+	}
+}
+
+// WithMetrics 设置指标收集器，各阶段和收集器耗时以直方图导出。
+func WithMetrics(metrics otel.Metrics) BuilderOption {
+	return func(b *GSSCBuilder) {
+		b.metrics = metrics
+	}
+}
+
+// WithLogger 设置日志记录器，用于输出慢收集器告警。
+func WithLogger(logger otel.Logger) BuilderOption {
+	return func(b *GSSCBuilder) {
+		b.logger = logger
+	}
+}
+
+// WithSlowGathererThreshold 设置慢收集器告警阈值。
+//
+// 当存在多个收集器，且某个收集器耗时不低于 minDuration 并占整个构建耗时的 ratio 以上时，
+// 该收集器会被记录到 BuildReport.SlowGatherers。ratio <= 0 时禁用告警。
+func WithSlowGathererThreshold(ratio float64, minDuration time.Duration) BuilderOption {
+	return func(b *GSSCBuilder) {
+		b.slowRatio = ratio
+		b.slowMinDuration = minDuration
+	}
+}
+
 // NewGSSCBuilder 使用给定选项创建新的 GSSCBuilder。
 func NewGSSCBuilder(opts ...BuilderOption) *GSSCBuilder {
 	b := &GSSCBuilder{
-		config: DefaultConfig(),
+		config:          DefaultConfig(),
+		styles:          make(map[string]*StyleProfile),
+		slowRatio:       DefaultSlowGathererRatio,
+		slowMinDuration: DefaultSlowGathererMinDuration,
+	}
+
+	for _, style := range []*StyleProfile{RunbookStyle(), ChatStyle()} {
+		b.styles[style.Name] = style
 	}
 
 	for _, opt := range opts {
@@ -114,17 +197,39 @@ func NewGSSCBuilder(opts ...BuilderOption) *GSSCBuilder {
 
 // Build 使用 GSSC 流水线构建上下文。
 func (b *GSSCBuilder) Build(ctx context.Context, input *BuildInput) (string, error) {
+	result, err := b.BuildWithReport(ctx, input)
+	if err != nil {
+		return "", err
+	}
+	return result.Context, nil
+}
+
+// BuildWithReport 使用 GSSC 流水线构建上下文，并返回筛选后的包和诊断报告。
+func (b *GSSCBuilder) BuildWithReport(ctx context.Context, input *BuildInput) (*BuildResult, error) {
+	report := &BuildReport{}
+	buildStart := time.Now()
+
+	config, err := b.configFor(input)
+	if err != nil {
+		return nil, err
+	}
+	if config.Style != nil {
+		report.Style = config.Style.Name
+	}
+
 	// 1. 收集：收集候选包
 	gatherInput := &GatherInput{
 		Query:              input.Query,
 		SystemInstructions: input.SystemInstructions,
 		History:            input.History,
-		Config:             b.config,
+		Config:             config,
 	}
 
-	packets, err := b.gatherer.Gather(ctx, gatherInput)
+	stageStart := time.Now()
+	packets, err := b.gather(ctx, gatherInput, report)
+	report.Stages.Gather = time.Since(stageStart)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	// 添加额外的包
@@ -132,32 +237,176 @@ func (b *GSSCBuilder) Build(ctx context.Context, input *BuildInput) (string, err
 		packets = append(packets, input.AdditionalPackets...)
 	}
 
+	// 稳定排序，使相同输入得到相同的包顺序
+	SortPackets(packets)
+	report.GatheredPackets = len(packets)
+
 	// 2. 筛选：对包进行评分和过滤
-	selected := b.selector.Select(packets, input.Query, b.config)
+	stageStart = time.Now()
+	selected := b.selector.Select(packets, input.Query, config)
+	report.Stages.Select = time.Since(stageStart)
+	report.SelectedPackets = len(selected)
+	report.KeyTerms, report.Unknowns = detectUnknowns(input.Query, selected)
 
 	// 3. 结构化：组织成模板
-	structured := b.structurer.Structure(selected, input.Query, b.config)
+	stageStart = time.Now()
+	structured := b.structurer.Structure(selected, input.Query, config)
+	report.Stages.Structure = time.Since(stageStart)
 
 	// 4. 压缩：适应预算
+	stageStart = time.Now()
 	compressed := b.compressor.Compress(structured, b.config)
+	report.Tokens = b.config.GetTokenCounter().Count(compressed)
+	report.Compressed = compressed != structured
+	report.Stages.Compress = time.Since(stageStart)
+
+	report.Stages.Total = time.Since(buildStart)
+	b.detectSlowGatherers(report)
+	b.recordMetrics(ctx, report)
+
+	return &BuildResult{
+		Context: compressed,
+		Packets: selected,
+		Report:  report,
+	}, nil
+}
 
-	return compressed, nil
+// configFor 返回应用了本次请求回答风格和任务元数据的配置。
+//
+// 任务元数据按用户等级覆盖 Token 预算，按语言区域覆盖输出模板。
+func (b *GSSCBuilder) configFor(input *BuildInput) (*Config, error) {
+	style := input.Style
+	if style == nil && input.StyleName != "" {
+		var ok bool
+		if style, ok = b.styles[input.StyleName]; !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownStyle, input.StyleName)
+		}
+	}
+	if style == nil && input.Task == nil {
+		return b.config, nil
+	}
+
+	config := *b.config
+	if style != nil {
+		config.Style = style
+	}
+	if task := input.Task; task != nil {
+		config.Task = task
+		if tokens, ok := config.TierMaxTokens[task.UserTier]; ok && tokens > 0 {
+			config.MaxTokens = tokens
+		}
+		if template, ok := config.LocaleOutputTemplates[task.Locale]; ok {
+			config.OutputTemplate = template
+		}
+	}
+	return &config, nil
+}
+
+// detectSlowGatherers 找出耗时占比过高的收集器并记录告警。
+func (b *GSSCBuilder) detectSlowGatherers(report *BuildReport) {
+	if b.slowRatio <= 0 || len(report.Gatherers) < 2 || report.Stages.Total <= 0 {
+		return
+	}
+
+	for _, g := range report.Gatherers {
+		if g.Duration < b.slowMinDuration {
+			continue
+		}
+		share := float64(g.Duration) / float64(report.Stages.Total)
+		if share < b.slowRatio {
+			continue
+		}
+
+		report.SlowGatherers = append(report.SlowGatherers, g.Name)
+		report.Warnings = append(report.Warnings, fmt.Sprintf(
+			"gatherer %s took %s (%.0f%% of build time)", g.Name, g.Duration, share*100))
+		if b.logger != nil {
+			b.logger.Warn("slow context gatherer",
+				"gatherer", g.Name,
+				"duration", g.Duration,
+				"share", share,
+			)
+		}
+	}
+}
+
+// recordMetrics 将各阶段和收集器耗时导出为直方图。
+func (b *GSSCBuilder) recordMetrics(ctx context.Context, report *BuildReport) {
+	if b.metrics == nil {
+		return
+	}
+
+	stages := b.metrics.Histogram(otel.MetricContextStageDuration)
+	for _, stage := range []struct {
+		name     string
+		duration time.Duration
+	}{
+		{"gather", report.Stages.Gather},
+		{"select", report.Stages.Select},
+		{"structure", report.Stages.Structure},
+		{"compress", report.Stages.Compress},
+		{"total", report.Stages.Total},
+	} {
+		stages.Record(ctx, durationMillis(stage.duration), otel.NewAttr(otel.AttrContextStage, stage.name))
+	}
+
+	gatherers := b.metrics.Histogram(otel.MetricContextGathererDuration)
+	for _, g := range report.Gatherers {
+		gatherers.Record(ctx, durationMillis(g.Duration), otel.NewAttr(otel.AttrContextGatherer, g.Name))
+	}
+
+	if len(report.SlowGatherers) > 0 {
+		slow := b.metrics.Counter(otel.MetricContextSlowGatherers)
+		for _, name := range report.SlowGatherers {
+			slow.Add(ctx, 1, otel.NewAttr(otel.AttrContextGatherer, name))
+		}
+	}
+}
+
+// durationMillis 将耗时转换为毫秒。
+func durationMillis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// gather 运行收集器并将执行情况写入报告。
+func (b *GSSCBuilder) gather(ctx context.Context, input *GatherInput, report *BuildReport) ([]*Packet, error) {
+	if rg, ok := b.gatherer.(ReportingGatherer); ok {
+		packets, reports, err := rg.GatherWithReport(ctx, input)
+		report.Gatherers = reports
+		return packets, err
+	}
+
+	start := time.Now()
+	packets, err := b.gatherer.Gather(ctx, input)
+	report.Gatherers = []GathererReport{{
+		Name:        GathererName(b.gatherer),
+		PacketCount: len(packets),
+		Duration:    time.Since(start),
+		Err:         err,
+	}}
+	return packets, err
 }
 
 // BuildMessages 从上下文构建消息列表。
 func (b *GSSCBuilder) BuildMessages(ctx context.Context, input *BuildInput) ([]message.Message, error) {
-	contextStr, err := b.Build(ctx, input)
+	messages, _, err := b.BuildMessagesWithReport(ctx, input)
+	return messages, err
+}
+
+// BuildMessagesWithReport 从上下文构建消息列表，并返回构建的诊断报告。
+func (b *GSSCBuilder) BuildMessagesWithReport(ctx context.Context, input *BuildInput) ([]message.Message, *BuildReport, error) {
+	result, err := b.BuildWithReport(ctx, input)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	var messages []message.Message
 
 	// 添加带有结构化上下文的系统消息
-	if contextStr != "" {
+	if result.Context != "" {
 		messages = append(messages, message.Message{
 			Role:    message.RoleSystem,
-			Content: contextStr,
+			Content: result.Context,
 		})
 	}
 
@@ -169,7 +418,7 @@ func (b *GSSCBuilder) BuildMessages(ctx context.Context, input *BuildInput) ([]m
 		})
 	}
 
-	return messages, nil
+	return messages, result.Report, nil
 }
 
 // Config 返回构建器的配置。
@@ -192,20 +441,19 @@ func NewSimpleBuilder(config *Config) *SimpleBuilder {
 }
 
 // Build 构建简单的上下文字符串。
-func (b *SimpleBuilder) Build(_ context.Context, input *BuildInput) (string, error) {
-	// 估算容量：指令 + 历史 + 查询
-	capacity := 1 + len(input.History) + 1
+func (b *SimpleBuilder) Build(ctx context.Context, input *BuildInput) (string, error) {
+	// 估算容量：指令 + 摘要 + 历史 + 查询
+	capacity := 1 + 1 + len(input.History) + 1
 	parts := make([]string, 0, capacity)
 
 	if input.SystemInstructions != "" {
 		parts = append(parts, input.SystemInstructions)
 	}
 
-	// 添加有限的历史
-	maxHistory := b.config.MaxHistoryMessages
-	history := input.History
-	if len(history) > maxHistory {
-		history = history[len(history)-maxHistory:]
+	// 添加有限的历史，溢出部分压缩为摘要
+	history, summary := b.history(ctx, input.History)
+	if summary != "" {
+		parts = append(parts, historySummaryPrefix+summary)
 	}
 
 	for _, msg := range history {
@@ -236,7 +484,9 @@ func (b *SimpleBuilder) Build(_ context.Context, input *BuildInput) (string, err
 }
 
 // BuildMessages 构建消息列表。
-func (b *SimpleBuilder) BuildMessages(_ context.Context, input *BuildInput) ([]message.Message, error) {
+//
+// 历史按 Config 的消息数和轮次上限裁剪，溢出摘要作为一条系统消息放在历史之前。
+func (b *SimpleBuilder) BuildMessages(ctx context.Context, input *BuildInput) ([]message.Message, error) {
 	var messages []message.Message
 
 	if input.SystemInstructions != "" {
@@ -247,10 +497,12 @@ func (b *SimpleBuilder) BuildMessages(_ context.Context, input *BuildInput) ([]m
 	}
 
 	// 添加有限的历史
-	maxHistory := b.config.MaxHistoryMessages
-	history := input.History
-	if len(history) > maxHistory {
-		history = history[len(history)-maxHistory:]
+	history, summary := b.history(ctx, input.History)
+	if summary != "" {
+		messages = append(messages, message.Message{
+			Role:    message.RoleSystem,
+			Content: historySummaryPrefix + summary,
+		})
 	}
 	messages = append(messages, history...)
 
@@ -264,6 +516,20 @@ func (b *SimpleBuilder) BuildMessages(_ context.Context, input *BuildInput) ([]m
 	return messages, nil
 }
 
+// history 按配置裁剪历史并返回溢出摘要，摘要失败时只丢弃溢出部分。
+func (b *SimpleBuilder) history(ctx context.Context, history []message.Message) ([]message.Message, string) {
+	if b.config.MaxHistoryMessages <= 0 {
+		return nil, ""
+	}
+	kept, overflow := limitHistory(history, b.config.MaxHistoryMessages, b.config)
+	summary, err := summarizeHistory(ctx, b.config, overflow)
+	if err != nil {
+		return kept, ""
+	}
+	return kept, summary
+}
+
 // 编译时接口检查
 var _ Builder = (*GSSCBuilder)(nil)
 var _ Builder = (*SimpleBuilder)(nil)
+var _ ReportingBuilder = (*GSSCBuilder)(nil)
@@ -112,19 +112,20 @@ func (c *TruncateCompressor) compressWithStructure(context string, config *Confi
 	return rebuildContext(sections, priorities)
 }
 
+// sectionHeaders 是已知的分段标题，按结构化顺序排列。
+var sectionHeaders = []string{
+	"[Role & Policies]",
+	"[Task]",
+	"[State]",
+	"[Evidence]",
+	"[Context]",
+	"[Output]",
+}
+
 // parseSections 按标题将上下文分割成分段。
 func parseSections(context string) map[string]string {
 	sections := make(map[string]string)
-
-	// 已知的分段标题
-	headers := []string{
-		"[Role & Policies]",
-		"[Task]",
-		"[State]",
-		"[Evidence]",
-		"[Context]",
-		"[Output]",
-	}
+	headers := sectionHeaders
 
 	// 查找每个分段
 	for i, header := range headers {
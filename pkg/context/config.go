@@ -1,5 +1,7 @@
 package context
 
+import "github.com/ahhsitt/helloagents-go/pkg/core/message"
+
 // Config 保存上下文构建的配置。
 type Config struct {
 	// MaxTokens 是上下文的总 Token 预算。
@@ -38,8 +40,29 @@ type Config struct {
 	// MaxHistoryMessages 限制要包含的历史消息数量。
 	MaxHistoryMessages int
 
+	// MaxHistoryTurns 限制历史的总轮次（一条消息计一轮），0 表示只受 MaxHistoryMessages 限制。
+	MaxHistoryTurns int
+
+	// MaxHistoryTurnsPerRole 按角色限制历史轮次，未列出的角色不限制。
+	MaxHistoryTurnsPerRole map[message.Role]int
+
+	// HistorySummarizer 压缩超出上限的历史，为 nil 时溢出的历史直接丢弃。
+	HistorySummarizer HistorySummarizer
+
 	// OutputTemplate 是可选的输出格式指令模板。
 	OutputTemplate string
+
+	// Style 是默认的回答风格，可被 BuildInput 按请求覆盖。
+	Style *StyleProfile
+
+	// Task 是本次请求的任务元数据，由 BuildInput.Task 按请求设置。
+	Task *TaskMeta
+
+	// TierMaxTokens 按用户等级覆盖 MaxTokens。
+	TierMaxTokens map[string]int
+
+	// LocaleOutputTemplates 按语言区域覆盖 OutputTemplate。
+	LocaleOutputTemplates map[string]string
 }
 
 // ConfigOption 配置 Config。
@@ -110,6 +133,30 @@ func WithMaxHistoryMessages(n int) ConfigOption {
 	}
 }
 
+// WithMaxHistoryTurns 设置历史的最大总轮次。
+func WithMaxHistoryTurns(n int) ConfigOption {
+	return func(c *Config) {
+		c.MaxHistoryTurns = n
+	}
+}
+
+// WithMaxHistoryTurnsPerRole 设置指定角色的最大历史轮次。
+func WithMaxHistoryTurnsPerRole(role message.Role, n int) ConfigOption {
+	return func(c *Config) {
+		if c.MaxHistoryTurnsPerRole == nil {
+			c.MaxHistoryTurnsPerRole = make(map[message.Role]int)
+		}
+		c.MaxHistoryTurnsPerRole[role] = n
+	}
+}
+
+// WithHistorySummarizer 设置溢出历史的摘要器。
+func WithHistorySummarizer(summarizer HistorySummarizer) ConfigOption {
+	return func(c *Config) {
+		c.HistorySummarizer = summarizer
+	}
+}
+
 // WithOutputTemplate 设置输出格式模板。
 func WithOutputTemplate(template string) ConfigOption {
 	return func(c *Config) {
@@ -117,6 +164,33 @@ func WithOutputTemplate(template string) ConfigOption {
 	}
 }
 
+// WithStyle 设置默认的回答风格。
+func WithStyle(style *StyleProfile) ConfigOption {
+	return func(c *Config) {
+		c.Style = style
+	}
+}
+
+// WithTierMaxTokens 为指定用户等级设置 Token 预算。
+func WithTierMaxTokens(tier string, tokens int) ConfigOption {
+	return func(c *Config) {
+		if c.TierMaxTokens == nil {
+			c.TierMaxTokens = make(map[string]int)
+		}
+		c.TierMaxTokens[tier] = tokens
+	}
+}
+
+// WithLocaleOutputTemplate 为指定语言区域设置输出格式模板。
+func WithLocaleOutputTemplate(locale, template string) ConfigOption {
+	return func(c *Config) {
+		if c.LocaleOutputTemplates == nil {
+			c.LocaleOutputTemplates = make(map[string]string)
+		}
+		c.LocaleOutputTemplates[locale] = template
+	}
+}
+
 // DefaultConfig 返回具有合理默认值的 Config。
 func DefaultConfig() *Config {
 	return &Config{
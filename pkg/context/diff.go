@@ -0,0 +1,194 @@
+package context
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ContextDiff 描述两次构建结果之间的差异。
+//
+// 用于排查多轮对话中上下文漂移的问题，例如某条约束为何在中途丢失。
+type ContextDiff struct {
+	// AddedSections 是新结果中新增的分段。
+	AddedSections []string
+
+	// RemovedSections 是新结果中被移除的分段。
+	RemovedSections []string
+
+	// ChangedSections 是两次结果中都存在但内容不同的分段。
+	ChangedSections []string
+
+	// AddedPackets 是新结果中新增的包。
+	AddedPackets []*Packet
+
+	// RemovedPackets 是新结果中被移除的包。
+	RemovedPackets []*Packet
+
+	// RerankedPackets 是两次结果中都存在但相对排名发生变化的包。
+	RerankedPackets []PacketRankChange
+}
+
+// PacketRankChange 描述包在两次构建间的排名变化。
+type PacketRankChange struct {
+	// Packet 是新结果中的包。
+	Packet *Packet
+
+	// OldRank 是包在旧结果中的排名（从 0 开始）。
+	OldRank int
+
+	// NewRank 是包在新结果中的排名（从 0 开始）。
+	NewRank int
+
+	// OldScore 是包在旧结果中的综合评分。
+	OldScore float64
+
+	// NewScore 是包在新结果中的综合评分。
+	NewScore float64
+}
+
+// Diff 比较两次构建结果，返回分段和包的结构化差异。
+func (b *GSSCBuilder) Diff(prev, next *BuildResult) *ContextDiff {
+	return Diff(prev, next)
+}
+
+// Diff 比较两次构建结果，返回分段和包的结构化差异。
+// 任一参数为 nil 时视为空结果。
+func Diff(prev, next *BuildResult) *ContextDiff {
+	if prev == nil {
+		prev = &BuildResult{}
+	}
+	if next == nil {
+		next = &BuildResult{}
+	}
+
+	diff := &ContextDiff{}
+	diffSections(diff, parseSections(prev.Context), parseSections(next.Context))
+	diffPackets(diff, prev.Packets, next.Packets)
+	return diff
+}
+
+// diffSections 比较分段。
+func diffSections(diff *ContextDiff, prev, next map[string]string) {
+	for _, header := range sectionHeaders {
+		oldContent, inPrev := prev[header]
+		newContent, inNext := next[header]
+
+		switch {
+		case inPrev && !inNext:
+			diff.RemovedSections = append(diff.RemovedSections, header)
+		case !inPrev && inNext:
+			diff.AddedSections = append(diff.AddedSections, header)
+		case inPrev && inNext && oldContent != newContent:
+			diff.ChangedSections = append(diff.ChangedSections, header)
+		}
+	}
+}
+
+// diffPackets 比较包，排名变化只在两次结果共有的包之间比较，
+// 避免新增或移除的包导致其他包的排名全部偏移。
+func diffPackets(diff *ContextDiff, prev, next []*Packet) {
+	prevKeys := make(map[string]bool, len(prev))
+	for _, p := range prev {
+		prevKeys[packetKey(p)] = true
+	}
+	nextKeys := make(map[string]bool, len(next))
+	for _, p := range next {
+		nextKeys[packetKey(p)] = true
+	}
+
+	type ranked struct {
+		rank  int
+		score float64
+	}
+	prevRanks := make(map[string]ranked)
+	for _, p := range prev {
+		key := packetKey(p)
+		if !nextKeys[key] {
+			diff.RemovedPackets = append(diff.RemovedPackets, p)
+			continue
+		}
+		if _, exists := prevRanks[key]; !exists {
+			prevRanks[key] = ranked{rank: len(prevRanks), score: p.CompositeScore}
+		}
+	}
+
+	seen := make(map[string]bool)
+	rank := 0
+	for _, p := range next {
+		key := packetKey(p)
+		if !prevKeys[key] {
+			diff.AddedPackets = append(diff.AddedPackets, p)
+			continue
+		}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		old := prevRanks[key]
+		if old.rank != rank {
+			diff.RerankedPackets = append(diff.RerankedPackets, PacketRankChange{
+				Packet:   p,
+				OldRank:  old.rank,
+				NewRank:  rank,
+				OldScore: old.score,
+				NewScore: p.CompositeScore,
+			})
+		}
+		rank++
+	}
+}
+
+// packetKey 返回用于比较包的键。
+func packetKey(p *Packet) string {
+	if p.ID != "" {
+		return p.ID
+	}
+	return StablePacketID(p.Type, p.Source, p.Content)
+}
+
+// IsEmpty 返回两次结果是否没有差异。
+func (d *ContextDiff) IsEmpty() bool {
+	return len(d.AddedSections) == 0 && len(d.RemovedSections) == 0 && len(d.ChangedSections) == 0 &&
+		len(d.AddedPackets) == 0 && len(d.RemovedPackets) == 0 && len(d.RerankedPackets) == 0
+}
+
+// String 返回差异的可读摘要。
+func (d *ContextDiff) String() string {
+	if d.IsEmpty() {
+		return "no changes"
+	}
+
+	var sb strings.Builder
+	for _, s := range d.AddedSections {
+		fmt.Fprintf(&sb, "+ section %s\n", s)
+	}
+	for _, s := range d.RemovedSections {
+		fmt.Fprintf(&sb, "- section %s\n", s)
+	}
+	for _, s := range d.ChangedSections {
+		fmt.Fprintf(&sb, "~ section %s\n", s)
+	}
+	for _, p := range d.AddedPackets {
+		fmt.Fprintf(&sb, "+ packet [%s] %s\n", p.Type, truncateForDiff(p.Content))
+	}
+	for _, p := range d.RemovedPackets {
+		fmt.Fprintf(&sb, "- packet [%s] %s\n", p.Type, truncateForDiff(p.Content))
+	}
+	for _, c := range d.RerankedPackets {
+		fmt.Fprintf(&sb, "~ packet [%s] %s: rank %d -> %d (score %.3f -> %.3f)\n",
+			c.Packet.Type, truncateForDiff(c.Packet.Content), c.OldRank, c.NewRank, c.OldScore, c.NewScore)
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// truncateForDiff 截断内容用于摘要显示。
+func truncateForDiff(content string) string {
+	const maxRunes = 60
+	content = strings.ReplaceAll(content, "\n", " ")
+	runes := []rune(content)
+	if len(runes) <= maxRunes {
+		return content
+	}
+	return string(runes[:maxRunes]) + "..."
+}
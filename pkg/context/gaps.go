@@ -0,0 +1,107 @@
+package context
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// 缺口检测的关键词长度限制
+const (
+	// minGapTermLength 是参与缺口检测的拉丁字母关键词的最小长度，含数字的词不受限制。
+	minGapTermLength = 3
+
+	// maxGapCJKTermLength 是作为关键词的中文词元的最大字数。
+	// 中文没有空格分词，更长的词元通常是整句，无法按词判断覆盖。
+	maxGapCJKTermLength = 4
+)
+
+// gapStopWords 是不参与缺口检测的常见英文功能词和疑问词。
+var gapStopWords = map[string]struct{}{
+	"the": {}, "and": {}, "for": {}, "are": {}, "but": {}, "not": {}, "you": {}, "all": {},
+	"any": {}, "can": {}, "had": {}, "her": {}, "was": {}, "one": {}, "our": {}, "out": {},
+	"has": {}, "have": {}, "how": {}, "what": {}, "when": {}, "where": {}, "which": {}, "who": {},
+	"why": {}, "will": {}, "with": {}, "this": {}, "that": {}, "these": {}, "those": {}, "from": {},
+	"into": {}, "about": {}, "there": {}, "their": {}, "them": {}, "they": {}, "then": {}, "than": {},
+	"does": {}, "did": {}, "doing": {}, "should": {}, "would": {}, "could": {}, "please": {}, "tell": {},
+	"know": {}, "want": {}, "need": {}, "some": {}, "its": {}, "also": {}, "just": {}, "get": {},
+	"use": {}, "using": {}, "been": {}, "being": {}, "were": {}, "your": {}, "yours": {}, "mine": {},
+	"more": {}, "most": {}, "other": {}, "such": {}, "only": {}, "own": {}, "same": {}, "very": {},
+	"let": {}, "may": {}, "might": {}, "must": {}, "shall": {}, "here": {}, "after": {}, "before": {},
+	"again": {}, "still": {}, "give": {}, "show": {}, "explain": {}, "describe": {}, "find": {},
+}
+
+// coversQueryTerms 返回该类型的包是否可以作为查询关键词的覆盖来源。
+//
+// 指令和任务包包含查询本身，不计入覆盖。
+func coversQueryTerms(t PacketType) bool {
+	return t != PacketTypeInstructions && t != PacketTypeTask
+}
+
+// extractKeyTerms 从查询中提取参与缺口检测的关键词（去重，保持出现顺序）。
+func extractKeyTerms(query string) []string {
+	var terms []string
+	seen := make(map[string]struct{})
+	for _, token := range tokenize(query) {
+		if _, ok := seen[token]; ok {
+			continue
+		}
+		seen[token] = struct{}{}
+
+		if isCJKToken(token) {
+			if n := utf8.RuneCountInString(token); n < 2 || n > maxGapCJKTermLength {
+				continue
+			}
+		} else {
+			if _, stop := gapStopWords[token]; stop {
+				continue
+			}
+			if len(token) < minGapTermLength && !strings.ContainsAny(token, "0123456789") {
+				continue
+			}
+		}
+		terms = append(terms, token)
+	}
+	return terms
+}
+
+// detectUnknowns 返回查询关键词，以及在所选包中没有任何覆盖的关键词。
+//
+// 拉丁字母关键词按词元完全匹配，中文关键词按子串匹配。
+func detectUnknowns(query string, selected []*Packet) (terms, unknowns []string) {
+	terms = extractKeyTerms(query)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+
+	covered := make(map[string]struct{})
+	var cjkContent strings.Builder
+	for _, p := range selected {
+		if !coversQueryTerms(p.Type) {
+			continue
+		}
+		content := strings.ToLower(p.Content)
+		for _, token := range tokenize(content) {
+			covered[token] = struct{}{}
+		}
+		cjkContent.WriteString(content)
+		cjkContent.WriteByte('\n')
+	}
+
+	text := cjkContent.String()
+	for _, term := range terms {
+		if _, ok := covered[term]; ok {
+			continue
+		}
+		if isCJKToken(term) && strings.Contains(text, term) {
+			continue
+		}
+		unknowns = append(unknowns, term)
+	}
+	return terms, unknowns
+}
+
+// isCJKToken 返回词元是否以中文字符开头。
+func isCJKToken(token string) bool {
+	r, _ := utf8.DecodeRuneInString(token)
+	return r >= 0x4E00 && r <= 0x9FFF
+}
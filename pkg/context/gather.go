@@ -2,7 +2,9 @@ package context
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -67,6 +69,10 @@ func (g *TaskGatherer) Gather(_ context.Context, input *GatherInput) ([]*Packet,
 }
 
 // HistoryGatherer 收集对话历史作为上下文包。
+//
+// 除 MaxMessages 外，还遵守 GatherInput.Config 中的轮次上限
+// （MaxHistoryTurns、MaxHistoryTurnsPerRole），配置了 HistorySummarizer 时
+// 溢出的历史压缩为摘要放在包的开头。
 type HistoryGatherer struct {
 	// MaxMessages 限制要包含的消息数量。
 	MaxMessages int
@@ -83,21 +89,35 @@ func NewHistoryGatherer(maxMessages int) *HistoryGatherer {
 }
 
 // Gather 收集对话历史。
-func (g *HistoryGatherer) Gather(_ context.Context, input *GatherInput) ([]*Packet, error) {
+func (g *HistoryGatherer) Gather(ctx context.Context, input *GatherInput) ([]*Packet, error) {
 	if len(input.History) == 0 {
 		return nil, nil
 	}
 
-	// 获取最近的消息
-	messages := input.History
-	if len(messages) > g.MaxMessages {
-		messages = messages[len(messages)-g.MaxMessages:]
+	// 按消息数和轮次上限保留最近的消息
+	messages, overflow := limitHistory(input.History, g.MaxMessages, input.Config)
+
+	metadata := map[string]interface{}{
+		"message_count": len(messages),
 	}
 
-	// 将历史格式化为单个包
+	// 将历史格式化为单个包，溢出摘要在前
 	var content string
+	summary, err := summarizeHistory(ctx, input.Config, overflow)
+	if err != nil {
+		// 摘要失败不影响保留的历史
+		metadata["summary_error"] = err.Error()
+	} else if summary != "" {
+		content += historySummaryPrefix + summary + "\n"
+		metadata["summarized_count"] = len(overflow)
+	}
+	attachments := 0
 	for _, msg := range messages {
-		content += fmt.Sprintf("[%s] %s\n", msg.Role, msg.Content)
+		content += fmt.Sprintf("[%s] %s%s\n", msg.Role, msg.Content, attachmentNotes(msg.Attachments))
+		attachments += len(msg.Attachments)
+	}
+	if attachments > 0 {
+		metadata["attachment_count"] = attachments
 	}
 
 	if content == "" {
@@ -107,14 +127,28 @@ func (g *HistoryGatherer) Gather(_ context.Context, input *GatherInput) ([]*Pack
 	packet := NewPacket(content,
 		WithPacketType(PacketTypeHistory),
 		WithSource("history"),
-		WithMetadata(map[string]interface{}{
-			"message_count": len(messages),
-		}),
+		WithMetadata(metadata),
 	)
 
 	return []*Packet{packet}, nil
 }
 
+// attachmentNotes 将附件格式化为文字说明，附件内容本身不进入文本上下文。
+func attachmentNotes(attachments []message.Attachment) string {
+	var notes string
+	for _, att := range attachments {
+		desc := att.Caption
+		if desc == "" {
+			desc = att.ID
+		}
+		if att.MimeType != "" {
+			desc = att.MimeType + ": " + desc
+		}
+		notes += " [附件 " + desc + "]"
+	}
+	return notes
+}
+
 // MemoryGatherer 收集相关记忆作为上下文包。
 // 这是一个用户可以实现以集成其记忆系统的接口。
 type MemoryGatherer struct {
@@ -226,68 +260,206 @@ func (g *RAGGatherer) Gather(ctx context.Context, input *GatherInput) ([]*Packet
 	return packets, nil
 }
 
+// GatherErrorPolicy 定义组合收集器处理子收集器错误的策略。
+type GatherErrorPolicy int
+
+const (
+	// GatherBestEffort 忽略失败或超时的收集器，返回其余收集器的部分结果（默认）。
+	GatherBestEffort GatherErrorPolicy = iota
+	// GatherFailFast 任一收集器失败或超时即取消其余收集器并返回错误。
+	GatherFailFast
+)
+
+// ErrGathererTimeout 表示收集器超过了单个收集器的超时时间。
+var ErrGathererTimeout = errors.New("gatherer timed out")
+
+// ReportingGatherer 是可以报告各子收集器执行情况的收集器。
+// GSSCBuilder.BuildWithReport 会将这些报告写入 BuildReport。
+type ReportingGatherer interface {
+	Gatherer
+
+	// GatherWithReport 收集包并返回每个子收集器的执行报告。
+	GatherWithReport(ctx context.Context, input *GatherInput) ([]*Packet, []GathererReport, error)
+}
+
 // CompositeGatherer 组合多个收集器。
 type CompositeGatherer struct {
-	gatherers []Gatherer
-	parallel  bool
+	gatherers   []Gatherer
+	parallel    bool
+	timeout     time.Duration
+	errorPolicy GatherErrorPolicy
+}
+
+// CompositeGathererOption 配置 CompositeGatherer。
+type CompositeGathererOption func(*CompositeGatherer)
+
+// WithGathererTimeout 设置单个收集器的超时时间。
+// 超时的收集器结果被丢弃，不会阻塞其他收集器；0 表示不限制。
+func WithGathererTimeout(timeout time.Duration) CompositeGathererOption {
+	return func(g *CompositeGatherer) {
+		g.timeout = timeout
+	}
+}
+
+// WithGathererErrorPolicy 设置收集器错误处理策略。
+func WithGathererErrorPolicy(policy GatherErrorPolicy) CompositeGathererOption {
+	return func(g *CompositeGatherer) {
+		g.errorPolicy = policy
+	}
 }
 
 // NewCompositeGatherer 创建新的 CompositeGatherer。
-func NewCompositeGatherer(gatherers []Gatherer, parallel bool) *CompositeGatherer {
-	return &CompositeGatherer{
+func NewCompositeGatherer(gatherers []Gatherer, parallel bool, opts ...CompositeGathererOption) *CompositeGatherer {
+	g := &CompositeGatherer{
 		gatherers: gatherers,
 		parallel:  parallel,
 	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g
 }
 
 // Gather 从所有收集器收集包。
 func (g *CompositeGatherer) Gather(ctx context.Context, input *GatherInput) ([]*Packet, error) {
+	packets, _, err := g.GatherWithReport(ctx, input)
+	return packets, err
+}
+
+// GatherWithReport 从所有收集器收集包，并报告每个收集器的耗时、超时和错误情况。
+// 结果按收集器的注册顺序合并。
+func (g *CompositeGatherer) GatherWithReport(ctx context.Context, input *GatherInput) ([]*Packet, []GathererReport, error) {
+	results := make([][]*Packet, len(g.gatherers))
+	reports := make([]GathererReport, len(g.gatherers))
+
+	var err error
 	if g.parallel {
-		return g.gatherParallel(ctx, input)
+		err = g.gatherParallel(ctx, input, results, reports)
+	} else {
+		err = g.gatherSequential(ctx, input, results, reports)
+	}
+	if err != nil {
+		return nil, reports, err
 	}
-	return g.gatherSequential(ctx, input)
-}
 
-func (g *CompositeGatherer) gatherSequential(ctx context.Context, input *GatherInput) ([]*Packet, error) {
 	var allPackets []*Packet
-
-	for _, gatherer := range g.gatherers {
-		packets, err := gatherer.Gather(ctx, input)
-		if err != nil {
-			// 记录错误但继续其他收集器
-			continue
-		}
+	for _, packets := range results {
 		allPackets = append(allPackets, packets...)
 	}
+	return allPackets, reports, nil
+}
 
-	return allPackets, nil
+func (g *CompositeGatherer) gatherSequential(ctx context.Context, input *GatherInput, results [][]*Packet, reports []GathererReport) error {
+	for i, gatherer := range g.gatherers {
+		results[i], reports[i] = g.runGatherer(ctx, gatherer, input)
+		if reports[i].Err != nil && g.errorPolicy == GatherFailFast {
+			return fmt.Errorf("gatherer %s: %w", reports[i].Name, reports[i].Err)
+		}
+	}
+	return nil
 }
 
-func (g *CompositeGatherer) gatherParallel(ctx context.Context, input *GatherInput) ([]*Packet, error) {
+func (g *CompositeGatherer) gatherParallel(ctx context.Context, input *GatherInput, results [][]*Packet, reports []GathererReport) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
 	var (
-		mu         sync.Mutex
-		wg         sync.WaitGroup
-		allPackets []*Packet
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
 	)
 
-	for _, gatherer := range g.gatherers {
+	for i, gatherer := range g.gatherers {
 		wg.Add(1)
-		go func(gth Gatherer) {
+		go func(i int, gth Gatherer) {
 			defer wg.Done()
 
-			packets, err := gth.Gather(ctx, input)
-			if err != nil {
-				return
+			results[i], reports[i] = g.runGatherer(ctx, gth, input)
+			if reports[i].Err != nil && g.errorPolicy == GatherFailFast {
+				once.Do(func() {
+					firstErr = fmt.Errorf("gatherer %s: %w", reports[i].Name, reports[i].Err)
+					cancel()
+				})
 			}
-
-			mu.Lock()
-			allPackets = append(allPackets, packets...)
-			mu.Unlock()
-		}(gatherer)
+		}(i, gatherer)
 	}
 
 	wg.Wait()
-	return allPackets, nil
+	return firstErr
+}
+
+// runGatherer 在超时控制下运行单个收集器。
+// 即使收集器不响应 ctx 取消，超时后也会立即返回。
+func (g *CompositeGatherer) runGatherer(ctx context.Context, gatherer Gatherer, input *GatherInput) ([]*Packet, GathererReport) {
+	report := GathererReport{Name: GathererName(gatherer)}
+	start := time.Now()
+
+	gctx := ctx
+	if g.timeout > 0 {
+		var cancel context.CancelFunc
+		gctx, cancel = context.WithTimeout(ctx, g.timeout)
+		defer cancel()
+	}
+
+	type result struct {
+		packets []*Packet
+		err     error
+	}
+	done := make(chan result, 1)
+	go func() {
+		packets, err := gatherer.Gather(gctx, input)
+		done <- result{packets: packets, err: err}
+	}()
+
+	var packets []*Packet
+	select {
+	case r := <-done:
+		packets, report.Err = r.packets, r.err
+	case <-gctx.Done():
+		report.Err = gctx.Err()
+	}
+	report.Duration = time.Since(start)
+
+	if report.Err != nil {
+		// 仅当父 ctx 仍有效时，才将截止时间错误归因于单个收集器超时
+		if errors.Is(report.Err, context.DeadlineExceeded) && g.timeout > 0 && ctx.Err() == nil {
+			report.TimedOut = true
+			report.Err = ErrGathererTimeout
+		}
+		return nil, report
+	}
+
+	report.PacketCount = len(packets)
+	return packets, report
+}
+
+// GathererName 返回收集器的名称。
+// 实现了 Name() string 的收集器使用其返回值，否则使用类型名。
+func GathererName(g Gatherer) string {
+	if named, ok := g.(interface{ Name() string }); ok {
+		return named.Name()
+	}
+	name := fmt.Sprintf("%T", g)
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
+
+// namedGatherer 为收集器指定名称。
+type namedGatherer struct {
+	Gatherer
+	name string
+}
+
+// Name 返回收集器名称。
+func (g *namedGatherer) Name() string {
+	return g.name
+}
+
+// NamedGatherer 为收集器指定名称，用于 BuildReport 中区分同类型的多个收集器。
+func NamedGatherer(name string, g Gatherer) Gatherer {
+	return &namedGatherer{Gatherer: g, name: name}
 }
 
 // NoteRetriever 定义笔记检索接口。
@@ -443,4 +615,5 @@ var _ Gatherer = (*HistoryGatherer)(nil)
 var _ Gatherer = (*MemoryGatherer)(nil)
 var _ Gatherer = (*RAGGatherer)(nil)
 var _ Gatherer = (*CompositeGatherer)(nil)
+var _ ReportingGatherer = (*CompositeGatherer)(nil)
 var _ Gatherer = (*NoteGatherer)(nil)
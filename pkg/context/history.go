@@ -0,0 +1,106 @@
+package context
+
+import (
+	"context"
+	"strings"
+
+	"github.com/ahhsitt/helloagents-go/pkg/core/message"
+)
+
+// HistorySummarizer 将超出轮次上限的历史消息压缩为摘要。
+type HistorySummarizer interface {
+	// Summarize 返回溢出消息的摘要，消息按时间顺序排列。
+	Summarize(ctx context.Context, overflow []message.Message) (string, error)
+}
+
+// HistorySummarizerFunc 是函数形式的 HistorySummarizer，便于接入 LLM 摘要。
+type HistorySummarizerFunc func(ctx context.Context, overflow []message.Message) (string, error)
+
+// Summarize 调用函数本身。
+func (f HistorySummarizerFunc) Summarize(ctx context.Context, overflow []message.Message) (string, error) {
+	return f(ctx, overflow)
+}
+
+// BriefHistorySummarizer 不调用模型，把每条溢出消息的开头按 "角色: 内容" 拼成一行摘要。
+type BriefHistorySummarizer struct {
+	// MaxRunes 是每条消息保留的最大字符数。
+	MaxRunes int
+}
+
+// NewBriefHistorySummarizer 创建新的 BriefHistorySummarizer。
+func NewBriefHistorySummarizer(maxRunes int) *BriefHistorySummarizer {
+	if maxRunes <= 0 {
+		maxRunes = 40
+	}
+	return &BriefHistorySummarizer{MaxRunes: maxRunes}
+}
+
+// Summarize 生成逐条截断的单行摘要。
+func (s *BriefHistorySummarizer) Summarize(_ context.Context, overflow []message.Message) (string, error) {
+	parts := make([]string, 0, len(overflow))
+	for _, msg := range overflow {
+		content := []rune(strings.Join(strings.Fields(msg.Content), " "))
+		if len(content) == 0 {
+			continue
+		}
+		if len(content) > s.MaxRunes {
+			content = append(content[:s.MaxRunes], '…')
+		}
+		parts = append(parts, string(msg.Role)+": "+string(content))
+	}
+	return strings.Join(parts, "; "), nil
+}
+
+// historySummaryPrefix 是溢出摘要在上下文中的前缀。
+const historySummaryPrefix = "[对话摘要] "
+
+// limitHistory 按消息数和轮次上限裁剪历史。
+//
+// 一条消息计为该角色的一轮。从最新消息开始保留，超出 maxMessages、
+// Config.MaxHistoryTurns 或 Config.MaxHistoryTurnsPerRole 的消息作为溢出返回，
+// kept 和 overflow 都保持原有的时间顺序。
+func limitHistory(history []message.Message, maxMessages int, config *Config) (kept, overflow []message.Message) {
+	maxTurns := maxMessages
+	var perRole map[message.Role]int
+	if config != nil {
+		if config.MaxHistoryTurns > 0 && (maxTurns <= 0 || config.MaxHistoryTurns < maxTurns) {
+			maxTurns = config.MaxHistoryTurns
+		}
+		perRole = config.MaxHistoryTurnsPerRole
+	}
+
+	keep := make([]bool, len(history))
+	roleTurns := make(map[message.Role]int, len(perRole))
+	total := 0
+	for i := len(history) - 1; i >= 0; i-- {
+		role := history[i].Role
+		if maxTurns > 0 && total >= maxTurns {
+			break
+		}
+		if limit, ok := perRole[role]; ok && roleTurns[role] >= limit {
+			continue
+		}
+		keep[i] = true
+		roleTurns[role]++
+		total++
+	}
+
+	kept = make([]message.Message, 0, total)
+	overflow = make([]message.Message, 0, len(history)-total)
+	for i, msg := range history {
+		if keep[i] {
+			kept = append(kept, msg)
+		} else {
+			overflow = append(overflow, msg)
+		}
+	}
+	return kept, overflow
+}
+
+// summarizeHistory 使用配置的摘要器压缩溢出消息，未配置摘要器或没有溢出时返回空字符串。
+func summarizeHistory(ctx context.Context, config *Config, overflow []message.Message) (string, error) {
+	if config == nil || config.HistorySummarizer == nil || len(overflow) == 0 {
+		return "", nil
+	}
+	return config.HistorySummarizer.Summarize(ctx, overflow)
+}
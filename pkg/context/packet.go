@@ -1,6 +1,9 @@
 package context
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
 	"time"
 )
 
@@ -45,6 +48,9 @@ func (t PacketType) Priority() int {
 
 // Packet 表示带有元数据的上下文信息单元。
 type Packet struct {
+	// ID 是包的稳定标识，默认由类型、来源和内容计算，相同输入在多次构建间保持一致。
+	ID string
+
 	// Content 是包的实际文本内容。
 	Content string
 
@@ -76,6 +82,13 @@ type Packet struct {
 // PacketOption 配置 Packet。
 type PacketOption func(*Packet)
 
+// WithPacketID 设置包的标识（跳过自动计算）。
+func WithPacketID(id string) PacketOption {
+	return func(p *Packet) {
+		p.ID = id
+	}
+}
+
 // WithPacketType 设置包类型。
 func WithPacketType(t PacketType) PacketOption {
 	return func(p *Packet) {
@@ -138,9 +151,47 @@ func NewPacket(content string, opts ...PacketOption) *Packet {
 		p.TokenCount = counter.Count(content)
 	}
 
+	p.ensureID()
+
 	return p
 }
 
+// StablePacketID 根据类型、来源和内容计算稳定的包标识。
+func StablePacketID(t PacketType, source, content string) string {
+	h := sha256.New()
+	h.Write([]byte(t))
+	h.Write([]byte{0})
+	h.Write([]byte(source))
+	h.Write([]byte{0})
+	h.Write([]byte(content))
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// ensureID 为未设置标识的包计算稳定标识。
+func (p *Packet) ensureID() {
+	if p.ID == "" {
+		p.ID = StablePacketID(p.Type, p.Source, p.Content)
+	}
+}
+
+// SortPackets 将包按优先级、来源、稳定标识排序，使结果与收集顺序无关。
+// 未设置标识的包会先计算稳定标识。
+func SortPackets(packets []*Packet) {
+	for _, p := range packets {
+		p.ensureID()
+	}
+	sort.SliceStable(packets, func(i, j int) bool {
+		a, b := packets[i], packets[j]
+		if a.Type.Priority() != b.Type.Priority() {
+			return a.Type.Priority() < b.Type.Priority()
+		}
+		if a.Source != b.Source {
+			return a.Source < b.Source
+		}
+		return a.ID < b.ID
+	})
+}
+
 // NewInstructionsPacket 创建系统指令包。
 func NewInstructionsPacket(content string) *Packet {
 	return NewPacket(content,
@@ -188,6 +239,7 @@ func NewTaskStatePacket(content string) *Packet {
 // Clone 创建包的深拷贝。
 func (p *Packet) Clone() *Packet {
 	clone := &Packet{
+		ID:             p.ID,
 		Content:        p.Content,
 		Type:           p.Type,
 		Timestamp:      p.Timestamp,
@@ -0,0 +1,129 @@
+package context
+
+import "time"
+
+// BuildResult 包含一次上下文构建的输出和诊断报告。
+type BuildResult struct {
+	// Context 是最终的上下文字符串。
+	Context string
+
+	// Packets 是筛选后进入上下文的包。
+	Packets []*Packet
+
+	// Report 是构建过程的诊断报告。
+	Report *BuildReport
+}
+
+// BuildReport 记录 GSSC 流水线各阶段的诊断信息。
+type BuildReport struct {
+	// Gatherers 是每个收集器的执行报告。
+	Gatherers []GathererReport
+
+	// GatheredPackets 是收集到的候选包数量（含额外包）。
+	GatheredPackets int
+
+	// SelectedPackets 是筛选后保留的包数量。
+	SelectedPackets int
+
+	// Tokens 是最终上下文的 token 数量。
+	Tokens int
+
+	// Compressed 表示压缩阶段改写了上下文以适应 Token 预算。
+	Compressed bool
+
+	// Stages 是各阶段的耗时。
+	Stages StageTimings
+
+	// SlowGatherers 是耗时占比过高的收集器名称。
+	SlowGatherers []string
+
+	// Warnings 是构建过程中产生的告警信息。
+	Warnings []string
+
+	// Style 是本次构建使用的回答风格名称，未使用风格时为空。
+	Style string
+
+	// KeyTerms 是从查询中提取的关键词。
+	KeyTerms []string
+
+	// Unknowns 是在筛选后的证据和记忆中没有任何覆盖的关键词。
+	// 智能体可据此向用户澄清，而不是凭空作答。
+	Unknowns []string
+}
+
+// StageTimings 记录 GSSC 流水线各阶段的耗时。
+type StageTimings struct {
+	// Gather 是收集阶段耗时。
+	Gather time.Duration
+
+	// Select 是筛选阶段耗时。
+	Select time.Duration
+
+	// Structure 是结构化阶段耗时。
+	Structure time.Duration
+
+	// Compress 是压缩阶段耗时（含 token 计数）。
+	Compress time.Duration
+
+	// Total 是整个构建的耗时。
+	Total time.Duration
+}
+
+// GathererReport 记录单个收集器的执行情况。
+type GathererReport struct {
+	// Name 是收集器名称。
+	Name string
+
+	// PacketCount 是收集器返回的包数量。
+	PacketCount int
+
+	// Duration 是收集器的耗时。
+	Duration time.Duration
+
+	// TimedOut 表示收集器是否超时。
+	TimedOut bool
+
+	// Err 是收集器返回的错误（超时时为 ErrGathererTimeout）。
+	Err error
+}
+
+// TimedOutGatherers 返回超时的收集器名称。
+func (r *BuildReport) TimedOutGatherers() []string {
+	var names []string
+	for _, g := range r.Gatherers {
+		if g.TimedOut {
+			names = append(names, g.Name)
+		}
+	}
+	return names
+}
+
+// FailedGatherers 返回出错（含超时）的收集器名称。
+func (r *BuildReport) FailedGatherers() []string {
+	var names []string
+	for _, g := range r.Gatherers {
+		if g.Err != nil {
+			names = append(names, g.Name)
+		}
+	}
+	return names
+}
+
+// HasUnknowns 返回查询中是否存在没有任何证据覆盖的关键词。
+func (r *BuildReport) HasUnknowns() bool {
+	return len(r.Unknowns) > 0
+}
+
+// SlowestGatherer 返回耗时最长的收集器报告。
+func (r *BuildReport) SlowestGatherer() (GathererReport, bool) {
+	if len(r.Gatherers) == 0 {
+		return GathererReport{}, false
+	}
+	slowest := r.Gatherers[0]
+	for _, g := range r.Gatherers[1:] {
+		if g.Duration > slowest.Duration {
+			slowest = g
+		}
+	}
+	return slowest, true
+}
@@ -4,6 +4,7 @@ import (
 	"math"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -33,19 +34,31 @@ func (s *RelevanceScorer) Score(packet *Packet, query string) float64 {
 		return 0.0
 	}
 
-	queryTokens := tokenize(query)
+	buf := getTokenBuffer()
+	defer putTokenBuffer(buf)
+
+	queryTokens := appendTokens((*buf)[:0], query)
 	if len(queryTokens) == 0 {
+		*buf = queryTokens
 		return 0.0
 	}
+	numQuery := len(queryTokens)
 
-	contentTokens := tokenize(packet.Content)
+	// 内容词元追加在查询词元之后，共用同一个缓冲区
+	tokens := appendTokens(queryTokens, packet.Content)
+	*buf = tokens
+	contentTokens := tokens[numQuery:]
 	if len(contentTokens) == 0 {
 		return 0.0
 	}
 
 	// 计算重叠的词元
-	querySet := make(map[string]struct{}, len(queryTokens))
-	for _, token := range queryTokens {
+	querySet := tokenSetPool.Get().(map[string]struct{})
+	defer func() {
+		clear(querySet)
+		tokenSetPool.Put(querySet)
+	}()
+	for _, token := range tokens[:numQuery] {
 		querySet[token] = struct{}{}
 	}
 
@@ -57,7 +70,7 @@ func (s *RelevanceScorer) Score(packet *Packet, query string) float64 {
 	}
 
 	// 返回类 Jaccard 的重叠比率
-	return float64(overlap) / float64(len(queryTokens))
+	return float64(overlap) / float64(numQuery)
 }
 
 // RecencyScorer 基于包的新近程度进行评分。
@@ -112,9 +125,14 @@ func NewCompositeScorer(scorers []Scorer, weights []float64) *CompositeScorer {
 
 // Score 计算加权复合分数。
 func (s *CompositeScorer) Score(packet *Packet, query string) float64 {
+	return s.ScoreTask(packet, query, nil)
+}
+
+// ScoreTask 计算加权复合分数，支持 TaskScorer 的子评分器参考任务元数据。
+func (s *CompositeScorer) ScoreTask(packet *Packet, query string, task *TaskMeta) float64 {
 	var total float64
 	for i, scorer := range s.scorers {
-		score := scorer.Score(packet, query)
+		score := scoreWithTask(scorer, packet, query, task)
 		total += score * s.weights[i]
 	}
 	return total
@@ -138,6 +156,13 @@ func NewDefaultSelector(config *Config) *DefaultSelector {
 	return &DefaultSelector{scorer: scorer}
 }
 
+// NewDefaultSelectorWithScorer 创建使用自定义复合评分器的 DefaultSelector。
+//
+// scorer 实现 TaskScorer 时，请求带有 TaskMeta 的包按任务评分。
+func NewDefaultSelectorWithScorer(scorer Scorer) *DefaultSelector {
+	return &DefaultSelector{scorer: scorer}
+}
+
 // Select 过滤和评分包，返回在预算内选中的包。
 func (s *DefaultSelector) Select(packets []*Packet, query string, config *Config) []*Packet {
 	if len(packets) == 0 {
@@ -155,7 +180,7 @@ func (s *DefaultSelector) Select(packets []*Packet, query string, config *Config
 		packet.RecencyScore = NewRecencyScorer(config.RecencyTau).Score(packet, query)
 
 		// 计算复合分数
-		packet.CompositeScore = s.scorer.Score(packet, query)
+		packet.CompositeScore = scoreWithTask(s.scorer, packet, query, config.Task)
 	}
 
 	// 2. 按优先级分类 - P0（指令）始终包含
@@ -179,7 +204,8 @@ func (s *DefaultSelector) Select(packets []*Packet, query string, config *Config
 	}
 
 	// 4. 按复合分数排序（降序）
-	sort.Slice(filtered, func(i, j int) bool {
+	// 稳定排序：同分的包保持输入顺序
+	sort.SliceStable(filtered, func(i, j int) bool {
 		// 首先按优先级（越低越好）
 		if filtered[i].Type.Priority() != filtered[j].Type.Priority() {
 			return filtered[i].Type.Priority() < filtered[j].Type.Priority()
@@ -215,26 +241,60 @@ func (s *DefaultSelector) Select(packets []*Packet, query string, config *Config
 
 // tokenize 将文本分割为小写词元用于比较。
 func tokenize(text string) []string {
+	return appendTokens(nil, text)
+}
+
+// appendTokens 将 text 的词元追加到 dst 并返回。
+//
+// 词元直接切自小写化后的文本，不再逐字符构建字符串。
+func appendTokens(dst []string, text string) []string {
 	text = strings.ToLower(text)
 
 	// 按空格和标点符号进行简单分词
-	var tokens []string
-	var current strings.Builder
-
-	for _, r := range text {
+	start := -1
+	for i, r := range text {
 		if isTokenChar(r) {
-			current.WriteRune(r)
-		} else if current.Len() > 0 {
-			tokens = append(tokens, current.String())
-			current.Reset()
+			if start < 0 {
+				start = i
+			}
+		} else if start >= 0 {
+			dst = append(dst, text[start:i])
+			start = -1
 		}
 	}
 
-	if current.Len() > 0 {
-		tokens = append(tokens, current.String())
+	if start >= 0 {
+		dst = append(dst, text[start:])
 	}
 
-	return tokens
+	return dst
+}
+
+// tokenBufferPool 复用评分时的词元切片。
+var tokenBufferPool = sync.Pool{
+	New: func() interface{} {
+		buf := make([]string, 0, 256)
+		return &buf
+	},
+}
+
+// tokenSetPool 复用评分时的查询词元集合。
+var tokenSetPool = sync.Pool{
+	New: func() interface{} {
+		return make(map[string]struct{}, 32)
+	},
+}
+
+// getTokenBuffer 从池中取出词元切片。
+func getTokenBuffer() *[]string {
+	return tokenBufferPool.Get().(*[]string)
+}
+
+// putTokenBuffer 清空引用后将词元切片放回池中。
+func putTokenBuffer(buf *[]string) {
+	clear(*buf)
+	*buf = (*buf)[:0]
+	tokenBufferPool.Put(buf)
 }
 
 // isTokenChar 返回该字符是否应该是词元的一部分。
@@ -249,3 +309,4 @@ var _ Selector = (*DefaultSelector)(nil)
 var _ Scorer = (*RelevanceScorer)(nil)
 var _ Scorer = (*RecencyScorer)(nil)
 var _ Scorer = (*CompositeScorer)(nil)
+var _ TaskScorer = (*CompositeScorer)(nil)
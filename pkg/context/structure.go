@@ -1,8 +1,10 @@
 package context
 
 import (
+	"bytes"
 	"sort"
 	"strings"
+	"sync"
 )
 
 // Structurer 定义将包组织成结构化上下文的接口。
@@ -26,71 +28,79 @@ func (s *DefaultStructurer) Structure(packets []*Packet, query string, config *C
 	}
 
 	// 按类型分组包
-	groups := make(map[PacketType][]*Packet)
+	groups := make(map[PacketType][]*Packet, 6)
 	for _, packet := range packets {
 		groups[packet.Type] = append(groups[packet.Type], packet)
 	}
 
-	var sections []string
+	w := newSectionWriter()
+	defer w.release()
 
-	// [Role & Policies] - P0：系统指令
-	if instructions := groups[PacketTypeInstructions]; len(instructions) > 0 {
-		section := "[Role & Policies]\n"
+	// [Role & Policies] - P0：系统指令与风格策略
+	instructions := groups[PacketTypeInstructions]
+	policies := config.Style.RolePolicies()
+	if len(instructions) > 0 || policies != "" {
+		w.begin("[Role & Policies]\n")
 		for _, p := range instructions {
-			section += p.Content
+			w.buf.WriteString(p.Content)
+		}
+		if policies != "" {
+			if len(instructions) > 0 && !w.endsWith('\n') {
+				w.buf.WriteByte('\n')
+			}
+			w.buf.WriteString(policies)
 		}
-		sections = append(sections, section)
 	}
 
-	// [Task] - P1：当前任务/查询
-	if tasks := groups[PacketTypeTask]; len(tasks) > 0 {
-		section := "[Task]\n"
-		section += "用户问题：" + query
-		sections = append(sections, section)
-	} else if query != "" {
-		// 如果没有任务包则降级
-		section := "[Task]\n"
-		section += "用户问题：" + query
-		sections = append(sections, section)
+	// [Task] - P1：当前任务/查询及任务元数据
+	if tasks := groups[PacketTypeTask]; len(tasks) > 0 || query != "" {
+		// 没有任务包时降级为直接使用查询
+		w.begin("[Task]\n")
+		w.buf.WriteString("用户问题：")
+		w.buf.WriteString(query)
+		if meta := config.Task.Describe(); meta != "" {
+			w.buf.WriteByte('\n')
+			w.buf.WriteString(meta)
+		}
 	}
 
 	// [State] - P1：任务状态和关键结论
 	if taskState := groups[PacketTypeTaskState]; len(taskState) > 0 {
-		section := "[State]\n关键进展与未决问题：\n"
+		w.begin("[State]\n关键进展与未决问题：\n")
 		for _, p := range taskState {
-			section += p.Content + "\n"
+			w.buf.WriteString(p.Content)
+			w.buf.WriteByte('\n')
 		}
-		sections = append(sections, section)
 	}
 
 	// [Evidence] - P2：来自 Memory/RAG 的事实证据
 	if evidence := groups[PacketTypeEvidence]; len(evidence) > 0 {
-		section := "[Evidence]\n事实与引用：\n"
+		w.begin("[Evidence]\n事实与引用：\n")
 
-		// 按相关性分数排序
-		sorted := make([]*Packet, len(evidence))
-		copy(sorted, evidence)
-		sort.Slice(sorted, func(i, j int) bool {
-			return sorted[i].RelevanceScore > sorted[j].RelevanceScore
+		// 按相关性分数排序（分组切片归本次调用所有，可原地排序）
+		sort.SliceStable(evidence, func(i, j int) bool {
+			return evidence[i].RelevanceScore > evidence[j].RelevanceScore
 		})
 
-		for _, p := range sorted {
+		for _, p := range evidence {
 			source := p.Source
 			if source == "" {
 				source = "unknown"
 			}
-			section += "\n[来源: " + source + "]\n" + p.Content + "\n"
+			w.buf.WriteString("\n[来源: ")
+			w.buf.WriteString(source)
+			w.buf.WriteString("]\n")
+			w.buf.WriteString(p.Content)
+			w.buf.WriteByte('\n')
 		}
-		sections = append(sections, section)
 	}
 
 	// [Context] - P3：对话历史
 	if history := groups[PacketTypeHistory]; len(history) > 0 {
-		section := "[Context]\n对话历史与背景：\n"
+		w.begin("[Context]\n对话历史与背景：\n")
 		for _, p := range history {
-			section += p.Content
+			w.buf.WriteString(p.Content)
 		}
-		sections = append(sections, section)
 	}
 
 	// [Output] - 输出约束
@@ -98,9 +108,56 @@ func (s *DefaultStructurer) Structure(packets []*Packet, query string, config *C
 	if outputTemplate == "" {
 		outputTemplate = defaultOutputTemplate
 	}
-	sections = append(sections, "[Output]\n"+outputTemplate)
+	w.begin("[Output]\n")
+	w.buf.WriteString(config.Style.Output(outputTemplate))
+
+	return w.buf.String()
+}
+
+// structureBufferPool 复用结构化时的缓冲区。
+var structureBufferPool = sync.Pool{
+	New: func() interface{} {
+		return new(bytes.Buffer)
+	},
+}
 
-	return strings.Join(sections, "\n\n")
+// maxPooledBufferSize 是放回池中的缓冲区容量上限，避免偶发的大上下文长期占用内存。
+const maxPooledBufferSize = 64 << 10
+
+// sectionWriter 将分段写入池化缓冲区，分段之间以空行分隔。
+type sectionWriter struct {
+	buf      *bytes.Buffer
+	sections int
+}
+
+// newSectionWriter 从池中取出缓冲区创建 sectionWriter。
+func newSectionWriter() *sectionWriter {
+	buf := structureBufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	return &sectionWriter{buf: buf}
+}
+
+// begin 开始一个新分段并写入标题。
+func (w *sectionWriter) begin(header string) {
+	if w.sections > 0 {
+		w.buf.WriteString("\n\n")
+	}
+	w.sections++
+	w.buf.WriteString(header)
+}
+
+// endsWith 返回当前内容是否以 c 结尾。
+func (w *sectionWriter) endsWith(c byte) bool {
+	b := w.buf.Bytes()
+	return len(b) > 0 && b[len(b)-1] == c
+}
+
+// release 将缓冲区放回池中。
+func (w *sectionWriter) release() {
+	if w.buf.Cap() <= maxPooledBufferSize {
+		structureBufferPool.Put(w.buf)
+	}
+	w.buf = nil
 }
 
 // MinimalStructurer 提供不带分段标题的简单结构。
@@ -120,7 +177,7 @@ func (s *MinimalStructurer) Structure(packets []*Packet, query string, _ *Config
 	// 先按优先级排序，再按分数排序
 	sorted := make([]*Packet, len(packets))
 	copy(sorted, packets)
-	sort.Slice(sorted, func(i, j int) bool {
+	sort.SliceStable(sorted, func(i, j int) bool {
 		if sorted[i].Type.Priority() != sorted[j].Type.Priority() {
 			return sorted[i].Type.Priority() < sorted[j].Type.Priority()
 		}
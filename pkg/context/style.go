@@ -0,0 +1,132 @@
+package context
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrUnknownStyle 表示 BuildInput 选择了未注册的风格配置。
+var ErrUnknownStyle = errors.New("unknown style profile")
+
+// Verbosity 表示回答的详略程度。
+type Verbosity string
+
+const (
+	// VerbosityTerse 只给出必要信息。
+	VerbosityTerse Verbosity = "terse"
+	// VerbosityNormal 不额外约束详略。
+	VerbosityNormal Verbosity = "normal"
+	// VerbosityDetailed 详尽解释推理过程。
+	VerbosityDetailed Verbosity = "detailed"
+)
+
+// instruction 返回详略程度对应的指令。
+func (v Verbosity) instruction() string {
+	switch v {
+	case VerbosityTerse:
+		return "回答力求简短，只给出必要信息，不做寒暄。"
+	case VerbosityDetailed:
+		return "回答要详尽，解释推理过程并在合适时给出示例。"
+	default:
+		return ""
+	}
+}
+
+// StyleProfile 是可复用的回答风格配置。
+//
+// 结构化时 Persona、Language 和 Verbosity 编译进 [Role & Policies] 分段，
+// OutputTemplate 和 FormattingRules 编译进 [Output] 分段。
+type StyleProfile struct {
+	// Name 是风格名称，用于 BuildInput.StyleName 选择。
+	Name string
+
+	// Persona 是角色设定。
+	Persona string
+
+	// Language 是回答语言，如 "中文"、"English"。
+	Language string
+
+	// Verbosity 是详略程度。
+	Verbosity Verbosity
+
+	// FormattingRules 是格式规则，逐条列在输出约束之后。
+	FormattingRules []string
+
+	// OutputTemplate 覆盖 Config.OutputTemplate，为空时沿用配置。
+	OutputTemplate string
+}
+
+// RolePolicies 返回编译后的角色与策略指令，无内容时返回空字符串。
+func (s *StyleProfile) RolePolicies() string {
+	if s == nil {
+		return ""
+	}
+
+	var lines []string
+	if s.Persona != "" {
+		lines = append(lines, s.Persona)
+	}
+	if s.Language != "" {
+		lines = append(lines, "请使用"+s.Language+"回答。")
+	}
+	if instruction := s.Verbosity.instruction(); instruction != "" {
+		lines = append(lines, instruction)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Output 返回编译后的输出约束，base 是未被风格覆盖时使用的模板。
+func (s *StyleProfile) Output(base string) string {
+	if s == nil {
+		return base
+	}
+
+	output := base
+	if s.OutputTemplate != "" {
+		output = s.OutputTemplate
+	}
+	if len(s.FormattingRules) == 0 {
+		return output
+	}
+
+	var sb strings.Builder
+	sb.WriteString(output)
+	sb.WriteString("\n\n格式要求：")
+	for _, rule := range s.FormattingRules {
+		sb.WriteString("\n- ")
+		sb.WriteString(rule)
+	}
+	return sb.String()
+}
+
+// RunbookStyle 返回简洁的运维手册风格。
+func RunbookStyle() *StyleProfile {
+	return &StyleProfile{
+		Name:      "runbook",
+		Persona:   "你是一名值班运维工程师，为操作人员提供可直接执行的处置步骤。",
+		Verbosity: VerbosityTerse,
+		FormattingRules: []string{
+			"使用编号步骤，每步只包含一个动作",
+			"命令放在代码块中",
+			"不使用表情符号",
+		},
+		OutputTemplate: `请按以下格式回答：
+1. 结论（一句话）
+2. 处置步骤
+3. 回滚方式（如适用）`,
+	}
+}
+
+// ChatStyle 返回友好的对话风格。
+func ChatStyle() *StyleProfile {
+	return &StyleProfile{
+		Name:      "chat",
+		Persona:   "你是一个友好、耐心的助手。",
+		Verbosity: VerbosityNormal,
+		FormattingRules: []string{
+			"使用自然的对话语气",
+			"必要时使用简短列表",
+		},
+		OutputTemplate: "直接回答用户的问题，必要时说明依据与来源。",
+	}
+}
@@ -0,0 +1,87 @@
+package context
+
+import (
+	"strings"
+	"time"
+)
+
+// Urgency 表示任务的紧急程度。
+type Urgency string
+
+const (
+	// UrgencyLow 表示可以延后处理的任务。
+	UrgencyLow Urgency = "low"
+	// UrgencyNormal 表示常规任务。
+	UrgencyNormal Urgency = "normal"
+	// UrgencyHigh 表示需要优先处理的任务。
+	UrgencyHigh Urgency = "high"
+)
+
+// TaskMeta 是随请求传入的结构化任务元数据。
+//
+// 通过 BuildInput.Task 传入，构建时挂到本次请求的 Config.Task 上，
+// 收集器（GatherInput.Config）、评分器（TaskScorer）和结构化器均可读取，
+// 无需把这些信息拼进查询字符串。
+type TaskMeta struct {
+	// Locale 是用户的语言区域，如 "zh-CN"、"en-US"。
+	Locale string
+
+	// Urgency 是紧急程度。
+	Urgency Urgency
+
+	// Deadline 是任务截止时间，零值表示没有截止时间。
+	Deadline time.Time
+
+	// UserTier 是用户等级，如 "free"、"pro"。
+	UserTier string
+
+	// Flags 是按请求开启的功能开关。
+	Flags map[string]bool
+}
+
+// Flag 返回功能开关是否开启，nil 安全。
+func (t *TaskMeta) Flag(name string) bool {
+	if t == nil {
+		return false
+	}
+	return t.Flags[name]
+}
+
+// Describe 返回写入 [Task] 分段的任务说明，无内容时返回空字符串。
+//
+// 只包含影响回答的语言区域、紧急程度和截止时间，用户等级和功能开关不暴露给模型。
+func (t *TaskMeta) Describe() string {
+	if t == nil {
+		return ""
+	}
+
+	var lines []string
+	if t.Locale != "" {
+		lines = append(lines, "语言区域："+t.Locale)
+	}
+	if t.Urgency != "" && t.Urgency != UrgencyNormal {
+		lines = append(lines, "紧急程度："+string(t.Urgency))
+	}
+	if !t.Deadline.IsZero() {
+		lines = append(lines, "截止时间："+t.Deadline.Format(time.RFC3339))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// TaskScorer 是可以参考任务元数据评分的评分器。
+//
+// DefaultSelector 在请求带有 TaskMeta 时优先调用 ScoreTask。
+type TaskScorer interface {
+	Scorer
+
+	// ScoreTask 根据查询和任务元数据计算包的分数。
+	ScoreTask(packet *Packet, query string, task *TaskMeta) float64
+}
+
+// scoreWithTask 在评分器支持且存在任务元数据时按任务评分。
+func scoreWithTask(scorer Scorer, packet *Packet, query string, task *TaskMeta) float64 {
+	if ts, ok := scorer.(TaskScorer); ok && task != nil {
+		return ts.ScoreTask(packet, query, task)
+	}
+	return scorer.Score(packet, query)
+}
@@ -18,6 +18,8 @@ type Config struct {
 	Agent AgentConfig `koanf:"agent"`
 	// Observability 可观测性配置
 	Observability ObservabilityConfig `koanf:"observability"`
+	// Retry 全局重试策略
+	Retry RetryConfig `koanf:"retry"`
 }
 
 // ObservabilityConfig 可观测性配置
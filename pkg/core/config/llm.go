@@ -49,10 +49,24 @@ type LLMConfig struct {
 	RetryDelay time.Duration `koanf:"retry_delay"`
 	// EmbeddingModel 嵌入模型名称
 	EmbeddingModel string `koanf:"embedding_model"`
+	// Retry 重试策略，覆盖全局重试配置；设置后 MaxRetries 和 RetryDelay 不再生效
+	Retry *RetryConfig `koanf:"retry"`
+	// RoleMapping 消息角色映射配置（用于限制角色的后端）
+	RoleMapping RoleMappingConfig `koanf:"role_mapping"`
 	// Fallback 备用提供商配置
 	Fallback *LLMConfig `koanf:"fallback"`
 }
 
+// RoleMappingConfig 消息角色映射配置
+type RoleMappingConfig struct {
+	// MergeSystem 将 system 消息合并到第一条 user 消息
+	MergeSystem bool `koanf:"merge_system"`
+	// ConvertToolMessages 将 tool 消息转换为 user 文本消息
+	ConvertToolMessages bool `koanf:"convert_tool_messages"`
+	// MergeConsecutive 合并相邻的同角色消息
+	MergeConsecutive bool `koanf:"merge_consecutive"`
+}
+
 // Validate 验证 LLM 配置
 func (c *LLMConfig) Validate() error {
 	if c.Model == "" {
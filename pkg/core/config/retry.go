@@ -0,0 +1,24 @@
+package config
+
+import "time"
+
+// RetryConfig 重试策略配置
+//
+// 顶层 Config.Retry 为全局默认策略，组件配置（如 LLMConfig.Retry）中的同名字段覆盖全局值。
+// 未设置（零值）的字段沿用全局默认。
+type RetryConfig struct {
+	// MaxAttempts 最大尝试次数（含首次）
+	MaxAttempts int `koanf:"max_attempts"`
+	// InitialBackoff 首次重试前的等待时间
+	InitialBackoff time.Duration `koanf:"initial_backoff"`
+	// MaxBackoff 单次等待的上限
+	MaxBackoff time.Duration `koanf:"max_backoff"`
+	// Multiplier 退避倍数
+	Multiplier float64 `koanf:"multiplier"`
+	// Jitter 随机抖动比例 [0, 1]
+	Jitter float64 `koanf:"jitter"`
+	// PerAttemptTimeout 单次尝试超时
+	PerAttemptTimeout time.Duration `koanf:"per_attempt_timeout"`
+	// RetryOn 可重试的错误类别: rate_limited, timeout, unavailable
+	RetryOn []string `koanf:"retry_on"`
+}
@@ -58,6 +58,20 @@ var (
 	ErrInvalidTool = errors.New("invalid tool")
 	// ErrToolTimeout 工具执行超时
 	ErrToolTimeout = errors.New("tool execution timeout")
+	// ErrToolBusy 工具并发执行数已达上限
+	ErrToolBusy = errors.New("tool concurrency limit reached")
+	// ErrCircuitOpen 工具连续失败后熔断，暂停执行
+	ErrCircuitOpen = errors.New("tool circuit breaker open")
+	// ErrToolProviderAlreadyRegistered 工具提供者已注册
+	ErrToolProviderAlreadyRegistered = errors.New("tool provider already registered")
+	// ErrToolProviderNotFound 工具提供者未找到
+	ErrToolProviderNotFound = errors.New("tool provider not found")
+	// ErrScratchEntryNotFound 暂存区条目不存在
+	ErrScratchEntryNotFound = errors.New("scratch entry not found")
+	// ErrScratchEntryTooLarge 暂存区条目超出大小限制
+	ErrScratchEntryTooLarge = errors.New("scratch entry too large")
+	// ErrScratchFull 暂存区已满
+	ErrScratchFull = errors.New("scratchpad full")
 )
 
 // Memory 相关错误
@@ -61,7 +61,7 @@ func (c *DeepSeekClient) Generate(ctx context.Context, req Request) (Response, e
 	var resp openai.ChatCompletionResponse
 	var err error
 
-	err = retry(ctx, c.options.MaxRetries, c.options.RetryDelay, func() error {
+	err = c.options.withRetry(ctx, "llm.deepseek", func(ctx context.Context) error {
 		resp, err = c.client.CreateChatCompletion(ctx, chatReq)
 		return mapOpenAIError(err)
 	})
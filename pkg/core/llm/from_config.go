@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/ahhsitt/helloagents-go/pkg/core/config"
+	"github.com/ahhsitt/helloagents-go/pkg/core/retry"
 )
 
 // FromConfig 从配置创建 LLM Provider
@@ -21,6 +22,16 @@ func FromConfig(cfg config.LLMConfig) (Provider, error) {
 		return nil, err
 	}
 
+	// 按配置改写消息角色
+	policy := RolePolicy{
+		MergeSystem:         cfg.RoleMapping.MergeSystem,
+		ConvertToolMessages: cfg.RoleMapping.ConvertToolMessages,
+		MergeConsecutive:    cfg.RoleMapping.MergeConsecutive,
+	}
+	if !policy.IsZero() {
+		primary = NewRoleMappingProvider(primary, policy)
+	}
+
 	// 如果有备用配置，创建 FallbackProvider
 	if cfg.Fallback != nil {
 		fallback, err := FromConfig(*cfg.Fallback)
@@ -67,6 +78,14 @@ func createOpenAIFromConfig(cfg config.LLMConfig) (*OpenAIClient, error) {
 		opts = append(opts, WithBaseURL(cfg.BaseURL))
 	}
 
+	if cfg.Retry != nil {
+		policy, err := retry.FromConfig(*cfg.Retry)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, WithRetryPolicy(policy))
+	}
+
 	return NewOpenAI(opts...)
 }
 
@@ -85,6 +104,14 @@ func createDeepSeekFromConfig(cfg config.LLMConfig) (*DeepSeekClient, error) {
 		opts = append(opts, WithModel(cfg.Model))
 	}
 
+	if cfg.Retry != nil {
+		policy, err := retry.FromConfig(*cfg.Retry)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, WithRetryPolicy(policy))
+	}
+
 	return NewDeepSeek(opts...)
 }
 
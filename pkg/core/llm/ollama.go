@@ -328,7 +328,7 @@ func (c *OllamaClient) buildRequest(req Request, stream bool) ollamaRequest {
 	for i, msg := range req.Messages {
 		ollamaReq.Messages[i] = ollamaMessage{
 			Role:    string(msg.Role),
-			Content: msg.Content,
+			Content: messageContent(msg),
 		}
 	}
 
@@ -67,7 +67,7 @@ func (c *OpenAIClient) Generate(ctx context.Context, req Request) (Response, err
 	var resp openai.ChatCompletionResponse
 	var err error
 
-	err = retry(ctx, c.options.MaxRetries, c.options.RetryDelay, func() error {
+	err = c.options.withRetry(ctx, "llm.openai", func(ctx context.Context) error {
 		resp, err = c.client.CreateChatCompletion(ctx, chatReq)
 		return mapOpenAIError(err)
 	})
@@ -127,7 +127,7 @@ func (c *OpenAIClient) convertMessages(msgs []message.Message) []openai.ChatComp
 	for _, msg := range msgs {
 		chatMsg := openai.ChatCompletionMessage{
 			Role:    string(msg.Role),
-			Content: msg.Content,
+			Content: messageContent(msg),
 		}
 
 		// 处理工具调用
@@ -222,7 +222,7 @@ func (c *OpenAIClient) Embed(ctx context.Context, texts []string) ([][]float32,
 	var resp openai.EmbeddingResponse
 	var err error
 
-	err = retry(ctx, c.options.MaxRetries, c.options.RetryDelay, func() error {
+	err = c.options.withRetry(ctx, "llm.openai", func(ctx context.Context) error {
 		resp, err = c.client.CreateEmbeddings(ctx, req)
 		return mapOpenAIError(err)
 	})
@@ -298,7 +298,7 @@ func convertMessagesToOpenAI(msgs []message.Message) []openai.ChatCompletionMess
 	for _, msg := range msgs {
 		chatMsg := openai.ChatCompletionMessage{
 			Role:    string(msg.Role),
-			Content: msg.Content,
+			Content: messageContent(msg),
 		}
 
 		if len(msg.ToolCalls) > 0 {
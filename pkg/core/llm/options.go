@@ -1,6 +1,10 @@
 package llm
 
-import "time"
+import (
+	"time"
+
+	"github.com/ahhsitt/helloagents-go/pkg/core/retry"
+)
 
 // Option LLM 配置选项函数
 type Option func(*Options)
@@ -19,6 +23,8 @@ type Options struct {
 	MaxRetries int
 	// RetryDelay 重试间隔基数
 	RetryDelay time.Duration
+	// RetryPolicy 重试策略，设置后覆盖 MaxRetries 和 RetryDelay
+	RetryPolicy *retry.Policy
 	// Temperature 默认温度
 	Temperature float64
 	// MaxTokens 默认最大 token
@@ -80,6 +86,13 @@ func WithRetryDelay(d time.Duration) Option {
 	}
 }
 
+// WithRetryPolicy 设置重试策略，覆盖全局默认策略以及 MaxRetries 和 RetryDelay
+func WithRetryPolicy(p retry.Policy) Option {
+	return func(o *Options) {
+		o.RetryPolicy = &p
+	}
+}
+
 // WithTemperature 设置默认温度
 func WithTemperature(t float64) Option {
 	return func(o *Options) {
@@ -388,7 +388,7 @@ func (c *QwenClient) buildRequest(req Request, stream bool) qwenRequest {
 	for i, msg := range req.Messages {
 		qwenReq.Messages[i] = qwenMessage{
 			Role:    string(msg.Role),
-			Content: msg.Content,
+			Content: messageContent(msg),
 		}
 		if msg.ToolCallID != "" {
 			qwenReq.Messages[i].ToolCallID = msg.ToolCallID
@@ -0,0 +1,197 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ahhsitt/helloagents-go/pkg/core/message"
+)
+
+// RolePolicy 消息角色映射策略
+//
+// 部分 OpenAI 兼容后端不接受 system 或 tool 角色的消息，
+// 或要求 user/assistant 严格交替。RolePolicy 在发送请求前
+// 改写消息列表，使调用方无需针对特定后端做处理。
+type RolePolicy struct {
+	// MergeSystem 将 system 消息合并到第一条 user 消息之前
+	MergeSystem bool
+
+	// ConvertToolMessages 将 tool 消息转换为带前缀的 user 消息，
+	// 并将 assistant 的工具调用转换为文本描述
+	ConvertToolMessages bool
+
+	// MergeConsecutive 合并相邻的同角色消息
+	MergeConsecutive bool
+
+	// ToolResultFormat 工具结果前缀格式（%s 为工具名），默认 "[Tool result: %s]"
+	ToolResultFormat string
+
+	// ToolCallFormat 工具调用描述格式（%s 依次为工具名和 JSON 参数），默认 "[Tool call: %s(%s)]"
+	ToolCallFormat string
+}
+
+// 默认的工具消息转换格式
+const (
+	defaultToolResultFormat = "[Tool result: %s]"
+	defaultToolCallFormat   = "[Tool call: %s(%s)]"
+)
+
+// StrictRolePolicy 返回适用于仅支持 user/assistant 交替的后端的策略
+func StrictRolePolicy() RolePolicy {
+	return RolePolicy{
+		MergeSystem:         true,
+		ConvertToolMessages: true,
+		MergeConsecutive:    true,
+	}
+}
+
+// IsZero 检查策略是否未启用任何映射
+func (p RolePolicy) IsZero() bool {
+	return !p.MergeSystem && !p.ConvertToolMessages && !p.MergeConsecutive
+}
+
+// Apply 按策略改写消息列表，不修改输入切片
+func (p RolePolicy) Apply(msgs []message.Message) []message.Message {
+	result := make([]message.Message, len(msgs))
+	copy(result, msgs)
+
+	if p.ConvertToolMessages {
+		result = p.convertToolMessages(result)
+	}
+	if p.MergeSystem {
+		result = mergeSystemMessages(result)
+	}
+	if p.MergeConsecutive {
+		result = mergeConsecutiveMessages(result)
+	}
+	return result
+}
+
+// convertToolMessages 将工具相关消息转换为纯文本消息
+func (p RolePolicy) convertToolMessages(msgs []message.Message) []message.Message {
+	resultFormat := p.ToolResultFormat
+	if resultFormat == "" {
+		resultFormat = defaultToolResultFormat
+	}
+	callFormat := p.ToolCallFormat
+	if callFormat == "" {
+		callFormat = defaultToolCallFormat
+	}
+
+	for i, msg := range msgs {
+		switch {
+		case msg.Role == message.RoleTool:
+			msgs[i] = message.Message{
+				Role:      message.RoleUser,
+				Content:   fmt.Sprintf(resultFormat, msg.Name) + "\n" + messageContent(msg),
+				Timestamp: msg.Timestamp,
+			}
+		case msg.Role == message.RoleAssistant && len(msg.ToolCalls) > 0:
+			parts := make([]string, 0, len(msg.ToolCalls)+1)
+			if msg.Content != "" {
+				parts = append(parts, msg.Content)
+			}
+			for _, tc := range msg.ToolCalls {
+				args, err := json.Marshal(tc.Arguments)
+				if err != nil {
+					args = []byte("{}")
+				}
+				parts = append(parts, fmt.Sprintf(callFormat, tc.Name, args))
+			}
+			msgs[i] = message.Message{
+				Role:      message.RoleAssistant,
+				Content:   strings.Join(parts, "\n"),
+				Timestamp: msg.Timestamp,
+			}
+		}
+	}
+	return msgs
+}
+
+// mergeSystemMessages 将所有 system 消息合并到第一条 user 消息之前
+//
+// 没有 user 消息时，合并后的内容作为第一条 user 消息插入。
+func mergeSystemMessages(msgs []message.Message) []message.Message {
+	var systemParts []string
+	rest := make([]message.Message, 0, len(msgs))
+	for _, msg := range msgs {
+		if msg.Role == message.RoleSystem {
+			if msg.Content != "" {
+				systemParts = append(systemParts, msg.Content)
+			}
+			continue
+		}
+		rest = append(rest, msg)
+	}
+
+	if len(systemParts) == 0 {
+		return rest
+	}
+	system := strings.Join(systemParts, "\n\n")
+
+	for i, msg := range rest {
+		if msg.Role == message.RoleUser {
+			rest[i].Content = system + "\n\n" + msg.Content
+			return rest
+		}
+	}
+	return append([]message.Message{message.NewUserMessage(system)}, rest...)
+}
+
+// mergeConsecutiveMessages 合并相邻的同角色消息
+//
+// 带工具调用的消息和 tool 消息保持独立，以免破坏调用与结果的对应关系。
+func mergeConsecutiveMessages(msgs []message.Message) []message.Message {
+	result := make([]message.Message, 0, len(msgs))
+	for _, msg := range msgs {
+		if n := len(result); n > 0 && mergeable(result[n-1], msg) {
+			result[n-1].Content += "\n\n" + msg.Content
+			continue
+		}
+		result = append(result, msg)
+	}
+	return result
+}
+
+// mergeable 检查两条消息是否可以合并
+func mergeable(prev, next message.Message) bool {
+	return prev.Role == next.Role &&
+		prev.Role != message.RoleTool &&
+		len(prev.ToolCalls) == 0 && len(next.ToolCalls) == 0
+}
+
+// RoleMappingProvider 在发送请求前按策略改写消息角色的提供商
+type RoleMappingProvider struct {
+	Provider
+	policy RolePolicy
+}
+
+// NewRoleMappingProvider 创建角色映射提供商
+func NewRoleMappingProvider(provider Provider, policy RolePolicy) *RoleMappingProvider {
+	return &RoleMappingProvider{
+		Provider: provider,
+		policy:   policy,
+	}
+}
+
+// Generate 生成响应（非流式）
+func (p *RoleMappingProvider) Generate(ctx context.Context, req Request) (Response, error) {
+	req.Messages = p.policy.Apply(req.Messages)
+	return p.Provider.Generate(ctx, req)
+}
+
+// GenerateStream 生成响应（流式）
+func (p *RoleMappingProvider) GenerateStream(ctx context.Context, req Request) (<-chan StreamChunk, <-chan error) {
+	req.Messages = p.policy.Apply(req.Messages)
+	return p.Provider.GenerateStream(ctx, req)
+}
+
+// Policy 返回角色映射策略
+func (p *RoleMappingProvider) Policy() RolePolicy {
+	return p.policy
+}
+
+// compile-time interface check
+var _ Provider = (*RoleMappingProvider)(nil)
@@ -1,6 +1,11 @@
 package llm
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ahhsitt/helloagents-go/pkg/core/message"
+)
 
 // marshalJSON JSON 序列化
 func marshalJSON(v interface{}) ([]byte, error) {
@@ -11,3 +16,17 @@ func marshalJSON(v interface{}) ([]byte, error) {
 func unmarshalJSON(data []byte, v interface{}) error {
 	return json.Unmarshal(data, v)
 }
+
+// messageContent 返回发送给提供商的消息内容
+//
+// 工具消息的 Content 为空而带有结构化结果时，将结构化结果编码为 JSON 作为内容。
+func messageContent(msg message.Message) string {
+	if msg.Content != "" || msg.Role != message.RoleTool || msg.StructuredContent == nil {
+		return msg.Content
+	}
+	data, err := marshalJSON(msg.StructuredContent)
+	if err != nil {
+		return fmt.Sprintf("%v", msg.StructuredContent)
+	}
+	return string(data)
+}
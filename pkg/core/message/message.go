@@ -2,6 +2,7 @@
 package message
 
 import (
+	"strings"
 	"time"
 )
 
@@ -39,6 +40,25 @@ type ToolCall struct {
 	Arguments map[string]interface{} `json:"arguments"`
 }
 
+// Attachment 表示消息附带的图片、文件等附件引用
+//
+// 只保存引用和说明，不保存附件内容本身；附件内容由外部制品存储按 ID 或 URI 取回。
+type Attachment struct {
+	// ID 制品标识
+	ID string `json:"id"`
+	// MimeType MIME 类型，如 "image/png"
+	MimeType string `json:"mime_type,omitempty"`
+	// Caption 附件的文字说明，用于检索和无法直接展示附件时的替代文本
+	Caption string `json:"caption,omitempty"`
+	// URI 可选的附件地址
+	URI string `json:"uri,omitempty"`
+}
+
+// IsImage 检查附件是否为图片
+func (a Attachment) IsImage() bool {
+	return strings.HasPrefix(a.MimeType, "image/")
+}
+
 // Message 表示对话中的一条消息
 type Message struct {
 	// ID 消息唯一标识
@@ -53,6 +73,12 @@ type Message struct {
 	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
 	// ToolCallID 对应的工具调用 ID（当 Role=tool 时）
 	ToolCallID string `json:"tool_call_id,omitempty"`
+	// StructuredContent 工具的结构化结果（当 Role=tool 时，可选）
+	//
+	// Content 为空时，提供商将其编码为 JSON 作为工具结果内容。
+	StructuredContent interface{} `json:"structured_content,omitempty"`
+	// Attachments 附件引用
+	Attachments []Attachment `json:"attachments,omitempty"`
 	// Metadata 元数据
 	Metadata map[string]interface{} `json:"metadata,omitempty"`
 	// Timestamp 时间戳
@@ -99,7 +125,10 @@ func (m *Message) Validate() error {
 	if !m.Role.IsValid() {
 		return ErrInvalidRole
 	}
-	// Content 可以为空（当 Role=assistant 且有 ToolCalls 时）
+	// Content 可以为空（当 Role=assistant 且有 ToolCalls 时，或 Role=user 且有附件时）
+	if m.Content == "" && m.Role == RoleUser && len(m.Attachments) > 0 {
+		return nil
+	}
 	if m.Content == "" && m.Role != RoleAssistant {
 		return ErrEmptyContent
 	}
@@ -117,3 +146,8 @@ func (m *Message) Validate() error {
 func (m *Message) HasToolCalls() bool {
 	return len(m.ToolCalls) > 0
 }
+
+// HasAttachments 检查消息是否包含附件
+func (m *Message) HasAttachments() bool {
+	return len(m.Attachments) > 0
+}
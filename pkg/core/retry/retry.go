@@ -0,0 +1,261 @@
+// Package retry 提供各子系统共享的结构化重试策略
+//
+// LLM 提供商、嵌入器、MCP 客户端和外部存储统一使用 Policy 描述重试行为：
+// 最大尝试次数、指数退避曲线、可重试的错误类别以及单次尝试超时。
+// 全局默认策略通过 SetDefault 配置，各组件可通过自身的选项覆盖。
+//
+// 每次重试都会作为事件记录到上下文中的当前 Span 上，便于在追踪中观察。
+package retry
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/ahhsitt/helloagents-go/pkg/core/config"
+	"github.com/ahhsitt/helloagents-go/pkg/core/errors"
+)
+
+// 追踪中记录重试使用的事件名和属性键
+const (
+	// EventRetry 每次重试前记录的 Span 事件名
+	EventRetry = "retry"
+	// AttrRetryComponent 发起重试的组件
+	AttrRetryComponent = "retry.component"
+	// AttrRetryAttempt 失败的尝试序号（从 1 开始）
+	AttrRetryAttempt = "retry.attempt"
+	// AttrRetryDelay 下一次尝试前的等待时间（毫秒）
+	AttrRetryDelay = "retry.delay_ms"
+	// AttrRetryError 导致重试的错误
+	AttrRetryError = "retry.error"
+	// AttrRetryAttempts 本次调用的总尝试次数（发生重试时设置）
+	AttrRetryAttempts = "retry.attempts"
+)
+
+// Class 可重试的错误类别
+type Class string
+
+const (
+	// ClassRateLimited 限速错误（errors.ErrRateLimited）
+	ClassRateLimited Class = "rate_limited"
+	// ClassTimeout 超时错误（errors.ErrTimeout，包括单次尝试超时）
+	ClassTimeout Class = "timeout"
+	// ClassUnavailable 服务不可用（errors.ErrProviderUnavailable）
+	ClassUnavailable Class = "unavailable"
+)
+
+// classErrors 错误类别对应的哨兵错误
+var classErrors = map[Class]error{
+	ClassRateLimited: errors.ErrRateLimited,
+	ClassTimeout:     errors.ErrTimeout,
+	ClassUnavailable: errors.ErrProviderUnavailable,
+}
+
+// Policy 重试策略
+//
+// 第 n 次重试前等待 InitialBackoff × Multiplier^(n-1)，不超过 MaxBackoff，
+// 并叠加 [0, Jitter) 比例的随机抖动。Policy 是值类型，可以安全地复制后修改。
+type Policy struct {
+	// MaxAttempts 最大尝试次数（含首次），<= 1 表示不重试
+	MaxAttempts int
+	// InitialBackoff 首次重试前的等待时间
+	InitialBackoff time.Duration
+	// MaxBackoff 单次等待的上限，0 表示不限制
+	MaxBackoff time.Duration
+	// Multiplier 退避倍数，<= 1 时使用固定间隔
+	Multiplier float64
+	// Jitter 随机抖动比例 [0, 1]
+	Jitter float64
+	// PerAttemptTimeout 单次尝试超时，0 表示只受调用方上下文约束
+	PerAttemptTimeout time.Duration
+	// Retryable 判断错误是否可重试，为空时使用 errors.IsRetryable
+	Retryable func(error) bool
+	// OnRetry 每次重试前的回调，attempt 为失败的尝试序号（从 1 开始）
+	OnRetry func(ctx context.Context, attempt int, err error, delay time.Duration)
+}
+
+// DefaultPolicy 返回内置的默认策略：最多 4 次尝试，1s 起步的指数退避，上限 30s
+func DefaultPolicy() Policy {
+	return Policy{
+		MaxAttempts:    4,
+		InitialBackoff: time.Second,
+		MaxBackoff:     30 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.1,
+	}
+}
+
+var (
+	defaultMu     sync.RWMutex
+	defaultPolicy = DefaultPolicy()
+)
+
+// Default 返回全局默认策略的副本
+func Default() Policy {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultPolicy
+}
+
+// SetDefault 设置全局默认策略
+//
+// 未单独配置重试策略的组件在下一次调用时使用新的默认值。
+func SetDefault(p Policy) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultPolicy = p
+}
+
+// RetryOn 返回只重试指定错误类别的判断函数
+func RetryOn(classes ...Class) func(error) bool {
+	targets := make([]error, 0, len(classes))
+	for _, class := range classes {
+		if target, ok := classErrors[class]; ok {
+			targets = append(targets, target)
+		}
+	}
+	return func(err error) bool {
+		for _, target := range targets {
+			if stderrors.Is(err, target) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// FromConfig 根据配置构建策略，未设置的字段取全局默认值
+func FromConfig(cfg config.RetryConfig) (Policy, error) {
+	p := Default()
+	if cfg.MaxAttempts > 0 {
+		p.MaxAttempts = cfg.MaxAttempts
+	}
+	if cfg.InitialBackoff > 0 {
+		p.InitialBackoff = cfg.InitialBackoff
+	}
+	if cfg.MaxBackoff > 0 {
+		p.MaxBackoff = cfg.MaxBackoff
+	}
+	if cfg.Multiplier > 0 {
+		p.Multiplier = cfg.Multiplier
+	}
+	if cfg.Jitter > 0 {
+		p.Jitter = cfg.Jitter
+	}
+	if cfg.PerAttemptTimeout > 0 {
+		p.PerAttemptTimeout = cfg.PerAttemptTimeout
+	}
+	if len(cfg.RetryOn) > 0 {
+		classes := make([]Class, len(cfg.RetryOn))
+		for i, name := range cfg.RetryOn {
+			class := Class(name)
+			if _, ok := classErrors[class]; !ok {
+				return Policy{}, fmt.Errorf("%w: unknown retry class %q", errors.ErrInvalidConfig, name)
+			}
+			classes[i] = class
+		}
+		p.Retryable = RetryOn(classes...)
+	}
+	return p, nil
+}
+
+// Do 按策略执行 fn，直到成功、遇到不可重试的错误或用尽尝试次数
+//
+// fn 收到的上下文带有单次尝试超时；单次尝试超时而调用方上下文仍有效时，
+// 错误被归类为 errors.ErrTimeout。调用方上下文取消时返回 errors.ErrContextCanceled。
+func (p Policy) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	return p.DoNamed(ctx, "", fn)
+}
+
+// DoNamed 与 Do 相同，component 记录在追踪事件中用于区分发起重试的组件
+func (p Policy) DoNamed(ctx context.Context, component string, fn func(ctx context.Context) error) error {
+	maxAttempts := p.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	retryable := p.Retryable
+	if retryable == nil {
+		retryable = errors.IsRetryable
+	}
+	span := trace.SpanFromContext(ctx)
+
+	var lastErr error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		if ctx.Err() != nil {
+			return errors.ErrContextCanceled
+		}
+
+		err := p.attempt(ctx, fn)
+		if err == nil {
+			if attempt > 1 {
+				span.SetAttributes(attribute.Int(AttrRetryAttempts, attempt))
+			}
+			return nil
+		}
+		lastErr = err
+
+		if !retryable(err) || attempt == maxAttempts {
+			break
+		}
+
+		delay := p.Backoff(attempt)
+		span.AddEvent(EventRetry, trace.WithAttributes(
+			attribute.String(AttrRetryComponent, component),
+			attribute.Int(AttrRetryAttempt, attempt),
+			attribute.Int64(AttrRetryDelay, delay.Milliseconds()),
+			attribute.String(AttrRetryError, err.Error()),
+		))
+		if p.OnRetry != nil {
+			p.OnRetry(ctx, attempt, err, delay)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return errors.ErrContextCanceled
+		case <-timer.C:
+		}
+	}
+	if maxAttempts > 1 {
+		span.SetAttributes(attribute.Int(AttrRetryAttempts, maxAttempts))
+	}
+	return lastErr
+}
+
+// attempt 执行单次尝试，应用单次尝试超时
+func (p Policy) attempt(ctx context.Context, fn func(ctx context.Context) error) error {
+	if p.PerAttemptTimeout <= 0 {
+		return fn(ctx)
+	}
+	attemptCtx, cancel := context.WithTimeout(ctx, p.PerAttemptTimeout)
+	defer cancel()
+
+	err := fn(attemptCtx)
+	if err != nil && ctx.Err() == nil && attemptCtx.Err() == context.DeadlineExceeded && !stderrors.Is(err, errors.ErrTimeout) {
+		return fmt.Errorf("%w: attempt exceeded %s: %v", errors.ErrTimeout, p.PerAttemptTimeout, err)
+	}
+	return err
+}
+
+// Backoff 返回第 attempt 次失败后的等待时间
+func (p Policy) Backoff(attempt int) time.Duration {
+	delay := float64(p.InitialBackoff)
+	if p.Multiplier > 1 && attempt > 1 {
+		delay *= math.Pow(p.Multiplier, float64(attempt-1))
+	}
+	if p.Jitter > 0 {
+		delay += delay * p.Jitter * rand.Float64()
+	}
+	if p.MaxBackoff > 0 && delay > float64(p.MaxBackoff) {
+		delay = float64(p.MaxBackoff)
+	}
+	return time.Duration(delay)
+}
@@ -0,0 +1,445 @@
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ahhsitt/helloagents-go/pkg/agents"
+	"github.com/ahhsitt/helloagents-go/pkg/evaluation"
+	"github.com/ahhsitt/helloagents-go/pkg/tools"
+)
+
+// AgentFactory 使用给定工具注册表创建待回放的 Agent
+//
+// 注册表中的工具由录制的观察结果驱动，不会产生真实副作用。
+// 通常在此函数中使用新的 Provider/模型创建 Agent。
+type AgentFactory func(registry *tools.Registry) (agents.Agent, error)
+
+// Comparator 计算原始回答与回放回答的相似度 (0-1)
+type Comparator func(original, replayed string) float64
+
+// Replayer 会话回放器
+type Replayer struct {
+	factory          AgentFactory
+	tools            []tools.Tool
+	liveFallback     bool
+	comparator       Comparator
+	successThreshold float64
+}
+
+// Option 回放器选项
+type Option func(*Replayer)
+
+// WithTools 设置原始工具
+//
+// 回放时使用这些工具的名称、描述和参数 Schema，
+// 但执行结果来自录制的观察。未提供的工具使用无参数 Schema。
+func WithTools(toolList ...tools.Tool) Option {
+	return func(r *Replayer) {
+		r.tools = append(r.tools, toolList...)
+	}
+}
+
+// WithLiveFallback 设置没有匹配录制时是否真实执行工具
+//
+// 默认关闭：没有录制的工具调用返回错误，避免意外的副作用。
+func WithLiveFallback(enabled bool) Option {
+	return func(r *Replayer) {
+		r.liveFallback = enabled
+	}
+}
+
+// WithComparator 设置回答相似度计算函数（默认为词集合 Jaccard 相似度）
+func WithComparator(comparator Comparator) Option {
+	return func(r *Replayer) {
+		r.comparator = comparator
+	}
+}
+
+// WithSuccessThreshold 设置判定回答一致的相似度阈值（默认 0.8）
+func WithSuccessThreshold(threshold float64) Option {
+	return func(r *Replayer) {
+		r.successThreshold = threshold
+	}
+}
+
+// NewReplayer 创建会话回放器
+func NewReplayer(factory AgentFactory, opts ...Option) *Replayer {
+	r := &Replayer{
+		factory:          factory,
+		comparator:       JaccardSimilarity,
+		successThreshold: 0.8,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Report 回放对比报告
+type Report struct {
+	// SessionID 会话标识
+	SessionID string `json:"session_id"`
+
+	// OriginalAgent 录制时的 Agent 名称
+	OriginalAgent string `json:"original_agent,omitempty"`
+
+	// ReplayAgent 回放使用的 Agent 名称
+	ReplayAgent string `json:"replay_agent"`
+
+	// Turns 逐轮对比
+	Turns []TurnComparison `json:"turns"`
+
+	// Result 以 evaluation 格式表示的汇总结果
+	Result *evaluation.EvalResult `json:"result"`
+}
+
+// TurnComparison 单轮对比结果
+type TurnComparison struct {
+	// Index 轮次序号
+	Index int `json:"index"`
+
+	// Query 用户查询
+	Query string `json:"query"`
+
+	// OriginalResponse 原始回答
+	OriginalResponse string `json:"original_response"`
+
+	// ReplayedResponse 回放回答
+	ReplayedResponse string `json:"replayed_response"`
+
+	// Similarity 回答相似度
+	Similarity float64 `json:"similarity"`
+
+	// OriginalToolCalls 原始工具调用序列
+	OriginalToolCalls []string `json:"original_tool_calls,omitempty"`
+
+	// ReplayedToolCalls 回放工具调用序列
+	ReplayedToolCalls []string `json:"replayed_tool_calls,omitempty"`
+
+	// MissingObservations 没有匹配录制的工具调用数
+	MissingObservations int `json:"missing_observations,omitempty"`
+
+	// OriginalTokens 原始 token 用量
+	OriginalTokens int `json:"original_tokens"`
+
+	// ReplayedTokens 回放 token 用量
+	ReplayedTokens int `json:"replayed_tokens"`
+
+	// OriginalDuration 原始耗时
+	OriginalDuration time.Duration `json:"original_duration"`
+
+	// ReplayedDuration 回放耗时
+	ReplayedDuration time.Duration `json:"replayed_duration"`
+
+	// Error 回放错误（如有）
+	Error string `json:"error,omitempty"`
+}
+
+// Replay 在新的 Agent 上按顺序重新执行会话的所有轮次
+//
+// 所有轮次使用同一个 Agent 实例，以保留多轮对话历史。
+func (r *Replayer) Replay(ctx context.Context, session *Session) (*Report, error) {
+	if r.factory == nil {
+		return nil, fmt.Errorf("未设置 Agent 工厂")
+	}
+
+	store := newObservationStore(session.Observations())
+	registry, err := r.buildRegistry(session, store)
+	if err != nil {
+		return nil, err
+	}
+
+	agent, err := r.factory(registry)
+	if err != nil {
+		return nil, fmt.Errorf("创建回放 Agent 失败: %w", err)
+	}
+
+	report := &Report{
+		SessionID:     session.ID,
+		OriginalAgent: session.AgentName,
+		ReplayAgent:   agent.Name(),
+	}
+
+	startTime := time.Now()
+	for i, turn := range session.Turns {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		missedBefore := store.missed()
+		output, runErr := agent.Run(ctx, turn.Input)
+
+		comparison := TurnComparison{
+			Index:               i,
+			Query:               turn.Input.Query,
+			OriginalResponse:    turn.Output.Response,
+			ReplayedResponse:    output.Response,
+			Similarity:          r.comparator(turn.Output.Response, output.Response),
+			OriginalToolCalls:   toolCalls(turn.Output.Steps),
+			ReplayedToolCalls:   toolCalls(output.Steps),
+			MissingObservations: store.missed() - missedBefore,
+			OriginalTokens:      turn.Output.TokenUsage.TotalTokens,
+			ReplayedTokens:      output.TokenUsage.TotalTokens,
+			OriginalDuration:    turn.Output.Duration,
+			ReplayedDuration:    output.Duration,
+		}
+		if runErr != nil {
+			comparison.Error = runErr.Error()
+			comparison.Similarity = 0
+		}
+		report.Turns = append(report.Turns, comparison)
+	}
+
+	report.Result = r.buildEvalResult(report, time.Since(startTime))
+	return report, nil
+}
+
+// buildRegistry 构建由录制观察驱动的工具注册表
+func (r *Replayer) buildRegistry(session *Session, store *observationStore) (*tools.Registry, error) {
+	registry := tools.NewRegistry()
+
+	for _, t := range r.tools {
+		if err := registry.Register(&recordedTool{Tool: t, store: store, live: r.liveFallback}); err != nil {
+			return nil, err
+		}
+	}
+
+	// 录制中出现但未提供定义的工具
+	for _, obs := range session.Observations() {
+		if registry.Has(obs.ToolName) {
+			continue
+		}
+		placeholder := tools.NewFuncTool(obs.ToolName, "Recorded tool "+obs.ToolName,
+			tools.ParameterSchema{Type: "object", Properties: map[string]tools.PropertySchema{}},
+			func(context.Context, map[string]interface{}) (string, error) {
+				return "", fmt.Errorf("工具 %s 没有原始实现", obs.ToolName)
+			})
+		if err := registry.Register(&recordedTool{Tool: placeholder, store: store}); err != nil {
+			return nil, err
+		}
+	}
+
+	return registry, nil
+}
+
+// buildEvalResult 将逐轮对比转换为 evaluation 结果
+func (r *Replayer) buildEvalResult(report *Report, duration time.Duration) *evaluation.EvalResult {
+	result := &evaluation.EvalResult{
+		BenchmarkName:   "Replay",
+		AgentName:       report.ReplayAgent,
+		TotalSamples:    len(report.Turns),
+		DetailedResults: make([]*evaluation.SampleResult, 0, len(report.Turns)),
+		TotalDuration:   duration,
+		EvaluationTime:  time.Now(),
+	}
+
+	var (
+		totalScore     float64
+		originalTokens int
+		replayedTokens int
+		missing        int
+	)
+	for _, turn := range report.Turns {
+		success := turn.Error == "" && turn.Similarity >= r.successThreshold
+		if success {
+			result.SuccessCount++
+		}
+		totalScore += turn.Similarity
+		originalTokens += turn.OriginalTokens
+		replayedTokens += turn.ReplayedTokens
+		missing += turn.MissingObservations
+
+		result.DetailedResults = append(result.DetailedResults, &evaluation.SampleResult{
+			SampleID:      fmt.Sprintf("%s-%d", report.SessionID, turn.Index),
+			Predicted:     turn.ReplayedResponse,
+			Expected:      turn.OriginalResponse,
+			Success:       success,
+			Score:         turn.Similarity,
+			ExecutionTime: turn.ReplayedDuration,
+			Error:         turn.Error,
+			AgentResponse: turn.ReplayedResponse,
+			Details: map[string]interface{}{
+				"original_tool_calls":  turn.OriginalToolCalls,
+				"replayed_tool_calls":  turn.ReplayedToolCalls,
+				"missing_observations": turn.MissingObservations,
+				"original_tokens":      turn.OriginalTokens,
+				"replayed_tokens":      turn.ReplayedTokens,
+			},
+		})
+	}
+
+	if result.TotalSamples > 0 {
+		result.OverallAccuracy = float64(result.SuccessCount) / float64(result.TotalSamples)
+		result.Metrics = &evaluation.MetricsSummary{
+			Accuracy:     result.OverallAccuracy,
+			AverageScore: totalScore / float64(result.TotalSamples),
+			Extra: map[string]interface{}{
+				"original_tokens":      originalTokens,
+				"replayed_tokens":      replayedTokens,
+				"missing_observations": missing,
+			},
+		}
+	}
+
+	return result
+}
+
+// String 返回逐轮并排对比的文本报告
+func (r *Report) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Session %s: %s -> %s\n", r.SessionID, r.OriginalAgent, r.ReplayAgent)
+	for _, turn := range r.Turns {
+		fmt.Fprintf(&sb, "\n[%d] %s\n", turn.Index, turn.Query)
+		fmt.Fprintf(&sb, "  original: %s\n", turn.OriginalResponse)
+		fmt.Fprintf(&sb, "  replayed: %s\n", turn.ReplayedResponse)
+		fmt.Fprintf(&sb, "  similarity=%.2f tokens=%d->%d tools=%v->%v",
+			turn.Similarity, turn.OriginalTokens, turn.ReplayedTokens, turn.OriginalToolCalls, turn.ReplayedToolCalls)
+		if turn.MissingObservations > 0 {
+			fmt.Fprintf(&sb, " missing=%d", turn.MissingObservations)
+		}
+		if turn.Error != "" {
+			fmt.Fprintf(&sb, " error=%s", turn.Error)
+		}
+		sb.WriteString("\n")
+	}
+	if r.Result != nil && r.Result.Metrics != nil {
+		fmt.Fprintf(&sb, "\nagreement=%.2f average_similarity=%.2f\n", r.Result.OverallAccuracy, r.Result.Metrics.AverageScore)
+	}
+	return sb.String()
+}
+
+// toolCalls 提取推理步骤中的工具调用序列
+func toolCalls(steps []agents.ReasoningStep) []string {
+	var names []string
+	for _, step := range steps {
+		if step.Type == agents.StepTypeAction {
+			names = append(names, step.ToolName)
+		}
+	}
+	return names
+}
+
+// JaccardSimilarity 计算两段文本词集合的 Jaccard 相似度
+func JaccardSimilarity(a, b string) float64 {
+	setA := wordSet(a)
+	setB := wordSet(b)
+	if len(setA) == 0 && len(setB) == 0 {
+		return 1
+	}
+
+	intersection := 0
+	for w := range setA {
+		if setB[w] {
+			intersection++
+		}
+	}
+	union := len(setA) + len(setB) - intersection
+	return float64(intersection) / float64(union)
+}
+
+// wordSet 将文本转换为小写词集合
+func wordSet(text string) map[string]bool {
+	set := make(map[string]bool)
+	for _, w := range strings.Fields(strings.ToLower(text)) {
+		w = strings.Trim(w, ".,!?;:\"'()[]{}，。！？；：")
+		if w != "" {
+			set[w] = true
+		}
+	}
+	return set
+}
+
+// observationStore 录制观察的存储
+type observationStore struct {
+	mu      sync.Mutex
+	entries map[string][]*recordedEntry
+	misses  int
+}
+
+// recordedEntry 单条录制观察
+type recordedEntry struct {
+	argsKey string
+	result  string
+	used    bool
+}
+
+// newObservationStore 创建录制观察存储
+func newObservationStore(observations []Observation) *observationStore {
+	store := &observationStore{entries: make(map[string][]*recordedEntry)}
+	for _, obs := range observations {
+		store.entries[obs.ToolName] = append(store.entries[obs.ToolName], &recordedEntry{
+			argsKey: argsKey(obs.Args),
+			result:  obs.Result,
+		})
+	}
+	return store
+}
+
+// take 取出与工具名和参数匹配的录制观察
+//
+// 优先返回未使用的录制；全部用完后复用最后一条匹配的录制。
+func (s *observationStore) take(toolName string, args map[string]interface{}) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := argsKey(args)
+	var last *recordedEntry
+	for _, entry := range s.entries[toolName] {
+		if entry.argsKey != key {
+			continue
+		}
+		if !entry.used {
+			entry.used = true
+			return entry.result, true
+		}
+		last = entry
+	}
+	if last != nil {
+		return last.result, true
+	}
+
+	s.misses++
+	return "", false
+}
+
+// missed 返回没有匹配录制的调用次数
+func (s *observationStore) missed() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.misses
+}
+
+// argsKey 返回参数的规范化键（JSON 序列化对 map 键排序）
+func argsKey(args map[string]interface{}) string {
+	if len(args) == 0 {
+		return "{}"
+	}
+	data, err := json.Marshal(args)
+	if err != nil {
+		return fmt.Sprintf("%v", args)
+	}
+	return string(data)
+}
+
+// recordedTool 使用录制观察代替真实执行的工具
+type recordedTool struct {
+	tools.Tool
+	store *observationStore
+	live  bool
+}
+
+// Execute 返回录制的观察结果
+func (t *recordedTool) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
+	if result, ok := t.store.take(t.Name(), args); ok {
+		return result, nil
+	}
+	if t.live {
+		return t.Tool.Execute(ctx, args)
+	}
+	return "", fmt.Errorf("工具 %s 没有匹配参数 %s 的录制观察", t.Name(), argsKey(args))
+}
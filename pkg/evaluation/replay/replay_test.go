@@ -0,0 +1,151 @@
+package replay
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/ahhsitt/helloagents-go/pkg/agents"
+	"github.com/ahhsitt/helloagents-go/pkg/core/llm"
+	"github.com/ahhsitt/helloagents-go/pkg/core/message"
+	"github.com/ahhsitt/helloagents-go/pkg/tools"
+)
+
+// scriptedProvider 按顺序返回预设响应的 Provider
+type scriptedProvider struct {
+	responses []llm.Response
+	calls     int
+}
+
+func (p *scriptedProvider) Generate(ctx context.Context, req llm.Request) (llm.Response, error) {
+	resp := p.responses[p.calls%len(p.responses)]
+	p.calls++
+	return resp, nil
+}
+
+func (p *scriptedProvider) GenerateStream(ctx context.Context, req llm.Request) (<-chan llm.StreamChunk, <-chan error) {
+	return nil, nil
+}
+
+func (p *scriptedProvider) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	return nil, nil
+}
+
+func (p *scriptedProvider) Name() string  { return "scripted" }
+func (p *scriptedProvider) Model() string { return "scripted-model" }
+func (p *scriptedProvider) Close() error  { return nil }
+
+func recordedSession() *Session {
+	session := NewSession("s1")
+	session.AgentName = "original"
+	session.Record(agents.Input{Query: "What is the weather in Paris?"}, agents.Output{
+		Response: "It is sunny in Paris today",
+		Steps: []agents.ReasoningStep{
+			agents.NewActionStep("weather", map[string]interface{}{"city": "Paris"}),
+			agents.NewObservationStep("weather", "sunny, 22C"),
+		},
+		TokenUsage: message.TokenUsage{TotalTokens: 100},
+	})
+	return session
+}
+
+func TestSessionObservations(t *testing.T) {
+	observations := recordedSession().Observations()
+	if len(observations) != 1 {
+		t.Fatalf("expected 1 observation, got %d", len(observations))
+	}
+	if observations[0].ToolName != "weather" || observations[0].Result != "sunny, 22C" {
+		t.Errorf("unexpected observation: %+v", observations[0])
+	}
+}
+
+func TestSessionSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.json")
+	if err := recordedSession().Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := LoadSession(path)
+	if err != nil {
+		t.Fatalf("LoadSession() error = %v", err)
+	}
+	if loaded.ID != "s1" || len(loaded.Turns) != 1 || len(loaded.Observations()) != 1 {
+		t.Errorf("unexpected loaded session: %+v", loaded)
+	}
+}
+
+func TestReplayerReusesRecordedObservations(t *testing.T) {
+	executed := false
+	weather := tools.NewFuncTool("weather", "Get weather", tools.ParameterSchema{
+		Type:       "object",
+		Properties: map[string]tools.PropertySchema{"city": {Type: "string"}},
+	}, func(context.Context, map[string]interface{}) (string, error) {
+		executed = true
+		return "live result", nil
+	})
+
+	provider := &scriptedProvider{responses: []llm.Response{
+		{ToolCalls: []message.ToolCall{{ID: "1", Name: "weather", Arguments: map[string]interface{}{"city": "Paris"}}}},
+		{Content: "It is sunny in Paris today", TokenUsage: message.TokenUsage{TotalTokens: 80}},
+	}}
+
+	replayer := NewReplayer(func(registry *tools.Registry) (agents.Agent, error) {
+		return agents.NewReAct(provider, registry, agents.WithName("candidate"))
+	}, WithTools(weather))
+
+	report, err := replayer.Replay(context.Background(), recordedSession())
+	if err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+
+	if executed {
+		t.Error("replay should not execute the live tool")
+	}
+	if len(report.Turns) != 1 {
+		t.Fatalf("expected 1 turn, got %d", len(report.Turns))
+	}
+
+	turn := report.Turns[0]
+	if turn.Similarity != 1 || turn.MissingObservations != 0 {
+		t.Errorf("unexpected comparison: %+v", turn)
+	}
+	if turn.OriginalTokens != 100 || turn.ReplayedTokens != 80 {
+		t.Errorf("unexpected tokens: %d -> %d", turn.OriginalTokens, turn.ReplayedTokens)
+	}
+	if report.ReplayAgent != "candidate" || report.Result.SuccessCount != 1 || report.Result.OverallAccuracy != 1 {
+		t.Errorf("unexpected eval result: %+v", report.Result)
+	}
+}
+
+func TestReplayerMissingObservation(t *testing.T) {
+	provider := &scriptedProvider{responses: []llm.Response{
+		{ToolCalls: []message.ToolCall{{ID: "1", Name: "weather", Arguments: map[string]interface{}{"city": "London"}}}},
+		{Content: "I could not get the weather"},
+	}}
+
+	replayer := NewReplayer(func(registry *tools.Registry) (agents.Agent, error) {
+		return agents.NewReAct(provider, registry)
+	})
+
+	report, err := replayer.Replay(context.Background(), recordedSession())
+	if err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+
+	turn := report.Turns[0]
+	if turn.MissingObservations != 1 {
+		t.Errorf("expected 1 missing observation, got %d", turn.MissingObservations)
+	}
+	if report.Result.SuccessCount != 0 {
+		t.Errorf("diverging answer should not count as success")
+	}
+}
+
+func TestJaccardSimilarity(t *testing.T) {
+	if got := JaccardSimilarity("Hello, world", "hello world!"); got != 1 {
+		t.Errorf("JaccardSimilarity() = %v, want 1", got)
+	}
+	if got := JaccardSimilarity("a b", "c d"); got != 0 {
+		t.Errorf("JaccardSimilarity() = %v, want 0", got)
+	}
+}
@@ -0,0 +1,138 @@
+// Package replay 提供会话回放工具
+//
+// 将持久化的会话线程（每轮输入 + 运行轨迹）在另一个 Provider/模型上重新执行，
+// 工具调用复用录制的观察结果而不重新产生副作用，
+// 并通过 evaluation 包生成新旧回答的对比报告，用于模型迁移决策。
+package replay
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ahhsitt/helloagents-go/pkg/agents"
+)
+
+// Session 持久化的会话线程及运行轨迹
+type Session struct {
+	// ID 会话标识
+	ID string `json:"id"`
+
+	// AgentName 录制时的 Agent 名称
+	AgentName string `json:"agent_name,omitempty"`
+
+	// Model 录制时使用的模型
+	Model string `json:"model,omitempty"`
+
+	// Turns 按顺序排列的对话轮次
+	Turns []Turn `json:"turns"`
+
+	// CreatedAt 创建时间
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Turn 单轮对话的输入和运行轨迹
+type Turn struct {
+	// Input Agent 输入
+	Input agents.Input `json:"input"`
+
+	// Output Agent 输出（含推理步骤和工具观察）
+	Output agents.Output `json:"output"`
+}
+
+// Observation 录制的工具观察结果
+type Observation struct {
+	// ToolName 工具名称
+	ToolName string `json:"tool_name"`
+
+	// Args 工具参数
+	Args map[string]interface{} `json:"args,omitempty"`
+
+	// Result 工具结果
+	Result string `json:"result"`
+}
+
+// NewSession 创建会话
+func NewSession(id string) *Session {
+	return &Session{
+		ID:        id,
+		CreatedAt: time.Now(),
+	}
+}
+
+// Record 记录一轮对话
+func (s *Session) Record(input agents.Input, output agents.Output) {
+	s.Turns = append(s.Turns, Turn{Input: input, Output: output})
+}
+
+// Observations 从运行轨迹中提取工具观察结果
+//
+// 每个 action 步骤与其后第一个同名工具的 observation 步骤配对。
+func (s *Session) Observations() []Observation {
+	var observations []Observation
+	for _, turn := range s.Turns {
+		observations = append(observations, turnObservations(turn.Output.Steps)...)
+	}
+	return observations
+}
+
+// turnObservations 从单轮推理步骤中提取工具观察结果
+func turnObservations(steps []agents.ReasoningStep) []Observation {
+	var (
+		observations []Observation
+		pending      []agents.ReasoningStep
+	)
+	for _, step := range steps {
+		switch step.Type {
+		case agents.StepTypeAction:
+			pending = append(pending, step)
+		case agents.StepTypeObservation:
+			for i, action := range pending {
+				if action.ToolName != step.ToolName {
+					continue
+				}
+				observations = append(observations, Observation{
+					ToolName: action.ToolName,
+					Args:     action.ToolArgs,
+					Result:   step.ToolResult,
+				})
+				pending = append(pending[:i], pending[i+1:]...)
+				break
+			}
+		}
+	}
+	return observations
+}
+
+// LoadSession 从 JSON 文件加载会话
+func LoadSession(path string) (*Session, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取会话文件失败: %w", err)
+	}
+
+	var session Session
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, fmt.Errorf("解析会话文件失败: %w", err)
+	}
+	return &session, nil
+}
+
+// Save 将会话保存为 JSON 文件
+func (s *Session) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("创建目录失败: %w", err)
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化会话失败: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("写入会话文件失败: %w", err)
+	}
+	return nil
+}
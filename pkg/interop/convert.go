@@ -0,0 +1,98 @@
+package interop
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+
+	"github.com/ahhsitt/helloagents-go/pkg/rag"
+	"github.com/ahhsitt/helloagents-go/pkg/tools"
+)
+
+// 与 rag.DocumentMetadata 字段对应的元数据键
+const (
+	metadataID     = "id"
+	metadataSource = "source"
+	metadataTitle  = "title"
+	metadataAuthor = "author"
+)
+
+// chunkID 返回外部文档的块 ID：优先使用元数据中的 id，否则使用内容哈希
+//
+// 稳定的 ID 使同一文档在多路检索融合时可以去重。
+func chunkID(id string, metadata map[string]any, content string) string {
+	if id != "" {
+		return id
+	}
+	if s, ok := metadata[metadataID].(string); ok && s != "" {
+		return s
+	}
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:8])
+}
+
+// documentMetadata 将外部文档的元数据映射为 rag.DocumentMetadata
+//
+// source/title/author 映射到对应字段，其余保留在 Custom 中。
+func documentMetadata(metadata map[string]any) rag.DocumentMetadata {
+	var m rag.DocumentMetadata
+	for k, v := range metadata {
+		s, isString := v.(string)
+		switch {
+		case k == metadataSource && isString:
+			m.Source = s
+		case k == metadataTitle && isString:
+			m.Title = s
+		case k == metadataAuthor && isString:
+			m.Author = s
+		default:
+			if m.Custom == nil {
+				m.Custom = make(map[string]interface{})
+			}
+			m.Custom[k] = v
+		}
+	}
+	return m
+}
+
+// chunkMetadata 将文档块的元数据展开为外部文档的元数据
+func chunkMetadata(chunk rag.DocumentChunk) map[string]any {
+	metadata := make(map[string]any, len(chunk.Metadata.Custom)+4)
+	for k, v := range chunk.Metadata.Custom {
+		metadata[k] = v
+	}
+	if chunk.ID != "" {
+		metadata[metadataID] = chunk.ID
+	}
+	if chunk.Metadata.Source != "" {
+		metadata[metadataSource] = chunk.Metadata.Source
+	}
+	if chunk.Metadata.Title != "" {
+		metadata[metadataTitle] = chunk.Metadata.Title
+	}
+	if chunk.Metadata.Author != "" {
+		metadata[metadataAuthor] = chunk.Metadata.Author
+	}
+	return metadata
+}
+
+// toolArgs 将字符串输入转换为工具参数
+//
+// 输入是 JSON 对象时直接作为参数；否则作为工具唯一参数的值，工具有多个参数时作为 input 参数。
+func toolArgs(t tools.Tool, input string) map[string]interface{} {
+	if trimmed := strings.TrimSpace(input); strings.HasPrefix(trimmed, "{") {
+		var args map[string]interface{}
+		if err := json.Unmarshal([]byte(trimmed), &args); err == nil {
+			return args
+		}
+	}
+
+	name := langChainInputParam
+	if params := t.Parameters(); len(params.Properties) == 1 {
+		for n := range params.Properties {
+			name = n
+		}
+	}
+	return map[string]interface{}{name: input}
+}
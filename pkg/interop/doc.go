@@ -0,0 +1,30 @@
+// Package interop 提供与其他 Go 智能体框架互通的适配器。
+//
+// 适配器在 LangChainGo、Eino 的 Tool/Retriever/Embedder 接口与 HelloAgents 的
+// tools.Tool、rag.Retriever、rag.Embedder（memory.Embedder）之间双向转换，
+// 便于逐步迁移已有的工具和检索器，而不必一次性重写。
+//
+// 本包不依赖这些框架：适配器按方法签名定义镜像接口，框架中的类型可直接传入。
+// 签名中含有框架自身类型（如文档、调用选项）时，镜像接口以类型参数表示，
+// 文档类型通过转换函数在两边之间映射。
+//
+// # LangChainGo
+//
+// LangChainGo 的 tools.Tool 和 embeddings.Embedder 只使用内置类型，可直接适配：
+//
+//	registry.Register(interop.FromLangChainTool(calculator))
+//	pipeline := rag.NewRAGPipeline(rag.WithEmbedder(interop.FromLangChainEmbedder(embedder)))
+//
+// schema.Document 与 LangChainDocument 字段相同，可直接做类型转换：
+//
+//	retriever := interop.FromLangChainRetriever(lcRetriever,
+//	    func(d schema.Document) interop.LangChainDocument { return interop.LangChainDocument(d) })
+//
+// # Eino
+//
+// Eino 接口的可变参数选项类型需要显式指定：
+//
+//	embedder := interop.FromEinoEmbedder[embedding.Option](einoEmbedder)
+//	retriever := interop.FromEinoRetriever[retriever.Option](einoRetriever,
+//	    func(d *schema.Document) interop.EinoDocument { return interop.EinoDocument(*d) })
+package interop
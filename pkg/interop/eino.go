@@ -0,0 +1,280 @@
+package interop
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ahhsitt/helloagents-go/pkg/rag"
+	"github.com/ahhsitt/helloagents-go/pkg/tools"
+)
+
+// einoScoreKey Eino 文档在 MetaData 中保存相关性分数的键
+const einoScoreKey = "_score"
+
+// EinoEmbedder Eino embedding.Embedder 接口的镜像，O 为 embedding.Option
+type EinoEmbedder[O any] interface {
+	EmbedStrings(ctx context.Context, texts []string, opts ...O) ([][]float64, error)
+}
+
+// EinoRetriever Eino retriever.Retriever 接口的镜像，O 为 retriever.Option，D 为 *schema.Document
+type EinoRetriever[O, D any] interface {
+	Retrieve(ctx context.Context, query string, opts ...O) ([]D, error)
+}
+
+// EinoTool Eino tool.InvokableTool 接口的镜像，O 为 tool.Option，I 为 *schema.ToolInfo
+type EinoTool[O, I any] interface {
+	Info(ctx context.Context) (I, error)
+	InvokableRun(ctx context.Context, argumentsInJSON string, opts ...O) (string, error)
+}
+
+// EinoDocument 与 Eino schema.Document 字段相同的文档，两者可直接类型转换
+type EinoDocument struct {
+	ID       string
+	Content  string
+	MetaData map[string]any
+}
+
+// Score 返回文档的相关性分数（与 schema.Document.Score 一致）
+func (d EinoDocument) Score() float64 {
+	score, _ := d.MetaData[einoScoreKey].(float64)
+	return score
+}
+
+// EinoToolInfo Eino 工具的描述信息
+//
+// Eino 的参数定义（ParamsOneOf）可以导出为 JSON Schema，再反序列化为 tools.ParameterSchema。
+type EinoToolInfo struct {
+	Name        string
+	Description string
+	Parameters  tools.ParameterSchema
+}
+
+// ============================================================================
+// Eino -> HelloAgents
+// ============================================================================
+
+// EinoEmbedderAdapter 将 Eino 嵌入模型适配为 rag.Embedder（同样满足 memory.Embedder）
+type EinoEmbedderAdapter[O any] struct {
+	embedder EinoEmbedder[O]
+	opts     []O
+}
+
+// FromEinoEmbedder 将 Eino 嵌入模型适配为 rag.Embedder，opts 在每次调用时传给 Eino 嵌入模型
+func FromEinoEmbedder[O any](e EinoEmbedder[O], opts ...O) *EinoEmbedderAdapter[O] {
+	return &EinoEmbedderAdapter[O]{embedder: e, opts: opts}
+}
+
+// Embed 生成文本嵌入向量
+func (a *EinoEmbedderAdapter[O]) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors, err := a.embedder.EmbedStrings(ctx, texts, a.opts...)
+	if err != nil {
+		return nil, err
+	}
+	result := make([][]float32, len(vectors))
+	for i, v := range vectors {
+		result[i] = make([]float32, len(v))
+		for j, x := range v {
+			result[i][j] = float32(x)
+		}
+	}
+	return result, nil
+}
+
+// EinoRetrieverAdapter 将 Eino 检索器适配为 rag.Retriever
+type EinoRetrieverAdapter[O, D any] struct {
+	retriever EinoRetriever[O, D]
+	convert   func(D) EinoDocument
+	opts      []O
+}
+
+// FromEinoRetriever 将 Eino 检索器适配为 rag.Retriever
+//
+// convert 将 *schema.Document 转换为 EinoDocument，通常直接做类型转换；
+// opts 在每次调用时传给 Eino 检索器。
+func FromEinoRetriever[O, D any](r EinoRetriever[O, D], convert func(D) EinoDocument, opts ...O) *EinoRetrieverAdapter[O, D] {
+	return &EinoRetrieverAdapter[O, D]{retriever: r, convert: convert, opts: opts}
+}
+
+// Retrieve 检索与查询相关的文档块
+//
+// Eino 的 TopK 通过检索器选项设置，结果超过 topK 时截断。
+func (a *EinoRetrieverAdapter[O, D]) Retrieve(ctx context.Context, query string, topK int) ([]rag.RetrievalResult, error) {
+	docs, err := a.retriever.Retrieve(ctx, query, a.opts...)
+	if err != nil {
+		return nil, err
+	}
+	if topK > 0 && len(docs) > topK {
+		docs = docs[:topK]
+	}
+
+	results := make([]rag.RetrievalResult, len(docs))
+	for i, d := range docs {
+		doc := a.convert(d)
+		metadata := make(map[string]any, len(doc.MetaData))
+		for k, v := range doc.MetaData {
+			if k != einoScoreKey {
+				metadata[k] = v
+			}
+		}
+		results[i] = rag.RetrievalResult{
+			Chunk: rag.DocumentChunk{
+				ID:       chunkID(doc.ID, nil, doc.Content),
+				Content:  doc.Content,
+				Index:    i,
+				Metadata: documentMetadata(metadata),
+			},
+			Score: float32(doc.Score()),
+		}
+	}
+	return results, nil
+}
+
+// EinoToolAdapter 将 Eino 工具适配为 tools.Tool
+type EinoToolAdapter struct {
+	info EinoToolInfo
+	run  func(ctx context.Context, argumentsInJSON string) (string, error)
+}
+
+// FromEinoTool 将 Eino 工具适配为 tools.Tool
+//
+// 创建时调用一次 Info 获取工具描述，describe 将 *schema.ToolInfo 转换为 EinoToolInfo；
+// opts 在每次调用时传给 Eino 工具。
+func FromEinoTool[O, I any](ctx context.Context, t EinoTool[O, I], describe func(I) EinoToolInfo, opts ...O) (*EinoToolAdapter, error) {
+	info, err := t.Info(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("interop: %w", err)
+	}
+	return &EinoToolAdapter{
+		info: describe(info),
+		run: func(ctx context.Context, argumentsInJSON string) (string, error) {
+			return t.InvokableRun(ctx, argumentsInJSON, opts...)
+		},
+	}, nil
+}
+
+// Name 返回工具名称
+func (a *EinoToolAdapter) Name() string {
+	return a.info.Name
+}
+
+// Description 返回工具描述
+func (a *EinoToolAdapter) Description() string {
+	return a.info.Description
+}
+
+// Parameters 返回参数 Schema
+func (a *EinoToolAdapter) Parameters() tools.ParameterSchema {
+	return a.info.Parameters
+}
+
+// Execute 执行工具，参数序列化为 JSON 传给 Eino 工具
+func (a *EinoToolAdapter) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
+	raw, err := json.Marshal(args)
+	if err != nil {
+		return "", fmt.Errorf("interop: %w", err)
+	}
+	return a.run(ctx, string(raw))
+}
+
+// ============================================================================
+// HelloAgents -> Eino
+// ============================================================================
+
+// EinoEmbedderWrapper 将 rag.Embedder 包装为 Eino 嵌入模型
+type EinoEmbedderWrapper[O any] struct {
+	embedder rag.Embedder
+}
+
+// ToEinoEmbedder 将 rag.Embedder（或 memory.Embedder）包装为 Eino 嵌入模型
+func ToEinoEmbedder[O any](e rag.Embedder) *EinoEmbedderWrapper[O] {
+	return &EinoEmbedderWrapper[O]{embedder: e}
+}
+
+// EmbedStrings 生成文本嵌入向量（忽略 Eino 调用选项）
+func (w *EinoEmbedderWrapper[O]) EmbedStrings(ctx context.Context, texts []string, _ ...O) ([][]float64, error) {
+	vectors, err := w.embedder.Embed(ctx, texts)
+	if err != nil {
+		return nil, err
+	}
+	result := make([][]float64, len(vectors))
+	for i, v := range vectors {
+		result[i] = make([]float64, len(v))
+		for j, x := range v {
+			result[i][j] = float64(x)
+		}
+	}
+	return result, nil
+}
+
+// EinoRetrieverWrapper 将 rag.Retriever 包装为 Eino 检索器
+type EinoRetrieverWrapper[O, D any] struct {
+	retriever rag.Retriever
+	topK      int
+	convert   func(EinoDocument) D
+}
+
+// ToEinoRetriever 将 rag.Retriever 包装为 Eino 检索器
+//
+// convert 将 EinoDocument 转换为 *schema.Document，通常直接做类型转换。
+// Eino 调用选项无法解析，返回数量由 topK 决定。
+func ToEinoRetriever[O, D any](r rag.Retriever, topK int, convert func(EinoDocument) D) *EinoRetrieverWrapper[O, D] {
+	return &EinoRetrieverWrapper[O, D]{retriever: r, topK: topK, convert: convert}
+}
+
+// Retrieve 检索与查询相关的文档（忽略 Eino 调用选项）
+func (w *EinoRetrieverWrapper[O, D]) Retrieve(ctx context.Context, query string, _ ...O) ([]D, error) {
+	results, err := w.retriever.Retrieve(ctx, query, w.topK)
+	if err != nil {
+		return nil, err
+	}
+	docs := make([]D, len(results))
+	for i, r := range results {
+		metadata := chunkMetadata(r.Chunk)
+		delete(metadata, metadataID)
+		metadata[einoScoreKey] = float64(r.Score)
+		docs[i] = w.convert(EinoDocument{
+			ID:       r.Chunk.ID,
+			Content:  r.Chunk.Content,
+			MetaData: metadata,
+		})
+	}
+	return docs, nil
+}
+
+// EinoToolWrapper 将 tools.Tool 包装为 Eino 工具
+type EinoToolWrapper[O, I any] struct {
+	tool     tools.Tool
+	describe func(EinoToolInfo) I
+}
+
+// ToEinoTool 将 tools.Tool 包装为 Eino 工具
+//
+// describe 将 EinoToolInfo 转换为 *schema.ToolInfo。
+func ToEinoTool[O, I any](t tools.Tool, describe func(EinoToolInfo) I) *EinoToolWrapper[O, I] {
+	return &EinoToolWrapper[O, I]{tool: t, describe: describe}
+}
+
+// Info 返回工具描述
+func (w *EinoToolWrapper[O, I]) Info(context.Context) (I, error) {
+	return w.describe(EinoToolInfo{
+		Name:        w.tool.Name(),
+		Description: w.tool.Description(),
+		Parameters:  w.tool.Parameters(),
+	}), nil
+}
+
+// InvokableRun 执行工具（忽略 Eino 调用选项）
+func (w *EinoToolWrapper[O, I]) InvokableRun(ctx context.Context, argumentsInJSON string, _ ...O) (string, error) {
+	args := make(map[string]interface{})
+	if strings.TrimSpace(argumentsInJSON) != "" {
+		if err := json.Unmarshal([]byte(argumentsInJSON), &args); err != nil {
+			return "", fmt.Errorf("interop: invalid tool arguments: %w", err)
+		}
+	}
+	return w.tool.Execute(ctx, args)
+}
+
+// compile-time interface check
+var _ tools.Tool = (*EinoToolAdapter)(nil)
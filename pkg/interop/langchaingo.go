@@ -0,0 +1,242 @@
+package interop
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ahhsitt/helloagents-go/pkg/rag"
+	"github.com/ahhsitt/helloagents-go/pkg/tools"
+)
+
+// langChainInputParam LangChainGo 工具在 HelloAgents 中的参数名
+const langChainInputParam = "input"
+
+// LangChainTool LangChainGo tools.Tool 接口的镜像
+type LangChainTool interface {
+	Name() string
+	Description() string
+	Call(ctx context.Context, input string) (string, error)
+}
+
+// LangChainEmbedder LangChainGo embeddings.Embedder 接口的镜像
+type LangChainEmbedder interface {
+	EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error)
+	EmbedQuery(ctx context.Context, text string) ([]float32, error)
+}
+
+// LangChainRetriever LangChainGo schema.Retriever 接口的镜像，D 为 schema.Document
+type LangChainRetriever[D any] interface {
+	GetRelevantDocuments(ctx context.Context, query string) ([]D, error)
+}
+
+// LangChainDocument 与 LangChainGo schema.Document 字段相同的文档，两者可直接类型转换
+type LangChainDocument struct {
+	PageContent string
+	Metadata    map[string]any
+	Score       float32
+}
+
+// ============================================================================
+// LangChainGo -> HelloAgents
+// ============================================================================
+
+// LangChainToolAdapter 将 LangChainGo 工具适配为 tools.Tool
+//
+// LangChainGo 工具只接收一个字符串输入，适配后的工具有一个必需的 input 参数。
+type LangChainToolAdapter struct {
+	tool LangChainTool
+}
+
+// FromLangChainTool 将 LangChainGo 工具适配为 tools.Tool
+func FromLangChainTool(t LangChainTool) *LangChainToolAdapter {
+	return &LangChainToolAdapter{tool: t}
+}
+
+// Name 返回工具名称
+func (a *LangChainToolAdapter) Name() string {
+	return a.tool.Name()
+}
+
+// Description 返回工具描述
+func (a *LangChainToolAdapter) Description() string {
+	return a.tool.Description()
+}
+
+// Parameters 返回参数 Schema
+func (a *LangChainToolAdapter) Parameters() tools.ParameterSchema {
+	return tools.ParameterSchema{
+		Type: "object",
+		Properties: map[string]tools.PropertySchema{
+			langChainInputParam: {Type: "string", Description: "tool input"},
+		},
+		Required: []string{langChainInputParam},
+	}
+}
+
+// Execute 执行工具
+//
+// 优先使用 input 参数；没有时将全部参数序列化为 JSON 作为输入。
+func (a *LangChainToolAdapter) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
+	if input, ok := args[langChainInputParam].(string); ok {
+		return a.tool.Call(ctx, input)
+	}
+	raw, err := json.Marshal(args)
+	if err != nil {
+		return "", fmt.Errorf("interop: %w", err)
+	}
+	return a.tool.Call(ctx, string(raw))
+}
+
+// LangChainEmbedderAdapter 将 LangChainGo 嵌入模型适配为 rag.Embedder（同样满足 memory.Embedder）
+type LangChainEmbedderAdapter struct {
+	embedder LangChainEmbedder
+}
+
+// FromLangChainEmbedder 将 LangChainGo 嵌入模型适配为 rag.Embedder
+func FromLangChainEmbedder(e LangChainEmbedder) *LangChainEmbedderAdapter {
+	return &LangChainEmbedderAdapter{embedder: e}
+}
+
+// Embed 生成文本嵌入向量
+func (a *LangChainEmbedderAdapter) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	return a.embedder.EmbedDocuments(ctx, texts)
+}
+
+// LangChainRetrieverAdapter 将 LangChainGo 检索器适配为 rag.Retriever
+type LangChainRetrieverAdapter[D any] struct {
+	retriever LangChainRetriever[D]
+	convert   func(D) LangChainDocument
+}
+
+// FromLangChainRetriever 将 LangChainGo 检索器适配为 rag.Retriever
+//
+// convert 将 schema.Document 转换为 LangChainDocument，通常直接做类型转换。
+func FromLangChainRetriever[D any](r LangChainRetriever[D], convert func(D) LangChainDocument) *LangChainRetrieverAdapter[D] {
+	return &LangChainRetrieverAdapter[D]{retriever: r, convert: convert}
+}
+
+// Retrieve 检索与查询相关的文档块
+//
+// LangChainGo 检索器自行决定返回数量，结果超过 topK 时截断。
+func (a *LangChainRetrieverAdapter[D]) Retrieve(ctx context.Context, query string, topK int) ([]rag.RetrievalResult, error) {
+	docs, err := a.retriever.GetRelevantDocuments(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if topK > 0 && len(docs) > topK {
+		docs = docs[:topK]
+	}
+
+	results := make([]rag.RetrievalResult, len(docs))
+	for i, d := range docs {
+		doc := a.convert(d)
+		results[i] = rag.RetrievalResult{
+			Chunk: rag.DocumentChunk{
+				ID:       chunkID("", doc.Metadata, doc.PageContent),
+				Content:  doc.PageContent,
+				Index:    i,
+				Metadata: documentMetadata(doc.Metadata),
+			},
+			Score: doc.Score,
+		}
+	}
+	return results, nil
+}
+
+// ============================================================================
+// HelloAgents -> LangChainGo
+// ============================================================================
+
+// LangChainToolWrapper 将 tools.Tool 包装为 LangChainGo 工具
+type LangChainToolWrapper struct {
+	tool tools.Tool
+}
+
+// ToLangChainTool 将 tools.Tool 包装为 LangChainGo 工具
+func ToLangChainTool(t tools.Tool) *LangChainToolWrapper {
+	return &LangChainToolWrapper{tool: t}
+}
+
+// Name 返回工具名称
+func (w *LangChainToolWrapper) Name() string {
+	return w.tool.Name()
+}
+
+// Description 返回工具描述
+func (w *LangChainToolWrapper) Description() string {
+	return w.tool.Description()
+}
+
+// Call 执行工具
+//
+// 输入是 JSON 对象时作为参数传入；否则作为唯一参数（只有一个参数时）或 input 参数的值。
+func (w *LangChainToolWrapper) Call(ctx context.Context, input string) (string, error) {
+	return w.tool.Execute(ctx, toolArgs(w.tool, input))
+}
+
+// LangChainEmbedderWrapper 将 rag.Embedder 包装为 LangChainGo 嵌入模型
+type LangChainEmbedderWrapper struct {
+	embedder rag.Embedder
+}
+
+// ToLangChainEmbedder 将 rag.Embedder（或 memory.Embedder）包装为 LangChainGo 嵌入模型
+func ToLangChainEmbedder(e rag.Embedder) *LangChainEmbedderWrapper {
+	return &LangChainEmbedderWrapper{embedder: e}
+}
+
+// EmbedDocuments 生成文档嵌入向量
+func (w *LangChainEmbedderWrapper) EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
+	return w.embedder.Embed(ctx, texts)
+}
+
+// EmbedQuery 生成查询嵌入向量
+func (w *LangChainEmbedderWrapper) EmbedQuery(ctx context.Context, text string) ([]float32, error) {
+	vectors, err := w.embedder.Embed(ctx, []string{text})
+	if err != nil {
+		return nil, err
+	}
+	if len(vectors) == 0 {
+		return nil, fmt.Errorf("interop: embedder returned no vectors")
+	}
+	return vectors[0], nil
+}
+
+// LangChainRetrieverWrapper 将 rag.Retriever 包装为 LangChainGo 检索器
+type LangChainRetrieverWrapper[D any] struct {
+	retriever rag.Retriever
+	topK      int
+	convert   func(LangChainDocument) D
+}
+
+// ToLangChainRetriever 将 rag.Retriever 包装为 LangChainGo 检索器
+//
+// convert 将 LangChainDocument 转换为 schema.Document，通常直接做类型转换。
+func ToLangChainRetriever[D any](r rag.Retriever, topK int, convert func(LangChainDocument) D) *LangChainRetrieverWrapper[D] {
+	return &LangChainRetrieverWrapper[D]{retriever: r, topK: topK, convert: convert}
+}
+
+// GetRelevantDocuments 检索与查询相关的文档
+func (w *LangChainRetrieverWrapper[D]) GetRelevantDocuments(ctx context.Context, query string) ([]D, error) {
+	results, err := w.retriever.Retrieve(ctx, query, w.topK)
+	if err != nil {
+		return nil, err
+	}
+	docs := make([]D, len(results))
+	for i, r := range results {
+		docs[i] = w.convert(LangChainDocument{
+			PageContent: r.Chunk.Content,
+			Metadata:    chunkMetadata(r.Chunk),
+			Score:       r.Score,
+		})
+	}
+	return docs, nil
+}
+
+// compile-time interface check
+var (
+	_ tools.Tool        = (*LangChainToolAdapter)(nil)
+	_ rag.Embedder      = (*LangChainEmbedderAdapter)(nil)
+	_ LangChainTool     = (*LangChainToolWrapper)(nil)
+	_ LangChainEmbedder = (*LangChainEmbedderWrapper)(nil)
+)
@@ -0,0 +1,221 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ahhsitt/helloagents-go/pkg/memory/store"
+)
+
+// DefaultArchiveCollection 归档记忆默认集合
+const DefaultArchiveCollection = "memory_archive"
+
+// ArchivedMemory 被遗忘后归档的记忆
+type ArchivedMemory struct {
+	// Item 原始记忆项（含原始元数据）
+	Item *MemoryItem `json:"item"`
+	// Strategy 触发归档的遗忘策略
+	Strategy ForgetStrategy `json:"strategy"`
+	// ArchivedAt 归档时间
+	ArchivedAt time.Time `json:"archived_at"`
+}
+
+// ArchiveStore 遗忘记忆的冷存储
+//
+// 将被遗忘的记忆连同原始元数据写入文档存储的独立集合，
+// 可通过 MemoryManager.RestoreMemories 恢复，实现"软遗忘"和合规留存。
+type ArchiveStore struct {
+	docs       store.DocumentStore
+	collection string
+}
+
+// ArchiveOption 归档存储配置选项
+type ArchiveOption func(*ArchiveStore)
+
+// WithArchiveCollection 设置归档集合名称
+func WithArchiveCollection(collection string) ArchiveOption {
+	return func(a *ArchiveStore) {
+		a.collection = collection
+	}
+}
+
+// NewArchiveStore 创建归档存储
+func NewArchiveStore(docs store.DocumentStore, opts ...ArchiveOption) *ArchiveStore {
+	a := &ArchiveStore{
+		docs:       docs,
+		collection: DefaultArchiveCollection,
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// Archive 归档记忆项
+func (a *ArchiveStore) Archive(ctx context.Context, strategy ForgetStrategy, items []*MemoryItem) error {
+	now := time.Now()
+	for _, item := range items {
+		if err := a.docs.Put(ctx, a.collection, item.ID, archiveDocument(item, strategy, now)); err != nil {
+			return fmt.Errorf("archive memory %s: %w", item.ID, err)
+		}
+	}
+	return nil
+}
+
+// Get 获取归档记忆，不存在时返回 ErrNotFound
+func (a *ArchiveStore) Get(ctx context.Context, id string) (*ArchivedMemory, error) {
+	doc, err := a.docs.Get(ctx, a.collection, id)
+	if errors.Is(err, store.ErrNotFound) || (err == nil && doc == nil) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return documentToArchived(*doc), nil
+}
+
+// List 列出归档记忆，memType 为空时返回所有类型
+func (a *ArchiveStore) List(ctx context.Context, memType MemoryType, opts ...store.QueryOption) ([]*ArchivedMemory, error) {
+	var filter store.Filter
+	if memType != "" {
+		filter = store.Filter{Field: "memory_type", Op: "eq", Value: string(memType)}
+	}
+	docs, err := a.docs.Query(ctx, a.collection, filter, opts...)
+	if err != nil {
+		return nil, err
+	}
+	archived := make([]*ArchivedMemory, len(docs))
+	for i, doc := range docs {
+		archived[i] = documentToArchived(doc)
+	}
+	return archived, nil
+}
+
+// Delete 永久删除归档记忆
+func (a *ArchiveStore) Delete(ctx context.Context, ids ...string) error {
+	for _, id := range ids {
+		if err := a.docs.Delete(ctx, a.collection, id); err != nil && !errors.Is(err, store.ErrNotFound) {
+			return err
+		}
+	}
+	return nil
+}
+
+// archiveDocument 将记忆项转换为归档文档
+func archiveDocument(item *MemoryItem, strategy ForgetStrategy, archivedAt time.Time) store.Document {
+	metadata := map[string]interface{}{
+		"memory_type":     string(item.MemoryType),
+		"importance":      item.Importance,
+		"forget_strategy": string(strategy),
+		"archived_at":     archivedAt.UnixMilli(),
+	}
+	if item.UserID != "" {
+		metadata["user_id"] = item.UserID
+	}
+	if original := conflictMetadata(item.Metadata); len(original) > 0 {
+		metadata["metadata"] = original
+	}
+
+	return store.Document{
+		ID:        item.ID,
+		Content:   item.Content,
+		Metadata:  metadata,
+		CreatedAt: item.Timestamp,
+		UpdatedAt: archivedAt,
+	}
+}
+
+// documentToArchived 将归档文档还原为归档记忆
+func documentToArchived(doc store.Document) *ArchivedMemory {
+	item := &MemoryItem{
+		ID:        doc.ID,
+		Content:   doc.Content,
+		Timestamp: doc.CreatedAt,
+	}
+	memType, _ := doc.Metadata["memory_type"].(string)
+	item.MemoryType = MemoryType(memType)
+	item.UserID, _ = doc.Metadata["user_id"].(string)
+	if importance, ok := explicitImportance(doc.Metadata); ok {
+		item.Importance = importance
+	}
+	item.Metadata, _ = doc.Metadata["metadata"].(map[string]interface{})
+	if item.Metadata == nil {
+		item.Metadata = make(map[string]interface{})
+	}
+
+	archived := &ArchivedMemory{Item: item, ArchivedAt: doc.UpdatedAt}
+	strategy, _ := doc.Metadata["forget_strategy"].(string)
+	archived.Strategy = ForgetStrategy(strategy)
+	switch at := doc.Metadata["archived_at"].(type) {
+	case int64:
+		archived.ArchivedAt = time.UnixMilli(at)
+	case float64:
+		archived.ArchivedAt = time.UnixMilli(int64(at))
+	}
+	return archived
+}
+
+// WithForgetArchive 遗忘前将被遗忘的记忆写入归档存储
+//
+// 归档失败时遗忘中止，记忆保持不变。
+func WithForgetArchive(archive *ArchiveStore) ForgetOption {
+	return func(o *forgetOptions) {
+		o.archive = archive
+	}
+}
+
+// archiveItems 归档即将被遗忘的记忆（未设置归档存储时不做任何事）
+func (o *forgetOptions) archiveItems(ctx context.Context, strategy ForgetStrategy, items []*MemoryItem) error {
+	if o.archive == nil || len(items) == 0 {
+		return nil
+	}
+	return o.archive.Archive(ctx, strategy, items)
+}
+
+// WithArchiveStore 设置遗忘记忆的归档存储
+//
+// 设置后 ForgetMemories（包括 Tick 触发的遗忘）先将被遗忘的记忆归档，
+// 再从记忆存储中删除，可通过 RestoreMemories 恢复。
+func WithArchiveStore(archive *ArchiveStore) ManagerOption {
+	return func(m *MemoryManager) {
+		m.archive = archive
+	}
+}
+
+// RestoreMemories 从归档存储恢复记忆，返回恢复的数量
+//
+// 记忆写回归档时的记忆类型并从归档中删除。未设置归档存储时返回 ErrArchiveNotConfigured，
+// 归档中不存在的 ID 返回 ErrNotFound，记忆类型未注册时返回 ErrMemoryTypeNotFound；
+// 出错时已恢复的记忆保持恢复状态。
+func (m *MemoryManager) RestoreMemories(ctx context.Context, ids ...string) (int, error) {
+	if m.archive == nil {
+		return 0, ErrArchiveNotConfigured
+	}
+
+	restored := 0
+	for _, id := range ids {
+		archived, err := m.archive.Get(ctx, id)
+		if err != nil {
+			return restored, fmt.Errorf("restore memory %s: %w", id, err)
+		}
+
+		m.mu.RLock()
+		memory, exists := m.memoryTypes[archived.Item.MemoryType]
+		m.mu.RUnlock()
+		if !exists {
+			return restored, fmt.Errorf("restore memory %s: %w", id, ErrMemoryTypeNotFound)
+		}
+
+		if _, err := memory.Add(ctx, archived.Item); err != nil {
+			return restored, fmt.Errorf("restore memory %s: %w", id, err)
+		}
+		if err := m.archive.Delete(ctx, id); err != nil {
+			return restored, fmt.Errorf("restore memory %s: %w", id, err)
+		}
+		m.publish(MemoryEvent{Type: EventMemoryRestored, MemoryType: archived.Item.MemoryType, ID: id, Content: archived.Item.Content, Count: 1})
+		restored++
+	}
+	return restored, nil
+}
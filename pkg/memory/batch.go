@@ -0,0 +1,60 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// validateItems 校验批量记忆项，任一无效时整批拒绝
+func validateItems(items []*MemoryItem) error {
+	for i, item := range items {
+		if item == nil {
+			return fmt.Errorf("item %d: %w", i, ErrInvalidInput)
+		}
+		if err := item.Validate(); err != nil {
+			return fmt.Errorf("item %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// idSet 将 ID 列表转换为集合
+func idSet(ids []string) map[string]bool {
+	set := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+	return set
+}
+
+// missingIDsError 构造批量删除中未找到记录的错误
+//
+// removed 为实际删除的 ID 集合，全部找到时返回 nil。
+func missingIDsError(ids []string, removed map[string]bool) error {
+	var missing []string
+	for _, id := range ids {
+		if !removed[id] {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrNotFound, strings.Join(missing, ", "))
+}
+
+// retrieveEach 逐个执行查询
+//
+// 用于不依赖嵌入模型的存储，结果顺序与 queries 一致。
+func retrieveEach(ctx context.Context, m Memory, queries []string, opts ...RetrieveOption) ([][]*MemoryItem, error) {
+	results := make([][]*MemoryItem, len(queries))
+	for i, query := range queries {
+		items, err := m.Retrieve(ctx, query, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("query %d: %w", i, err)
+		}
+		results[i] = items
+	}
+	return results, nil
+}
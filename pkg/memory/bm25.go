@@ -0,0 +1,135 @@
+package memory
+
+import (
+	"math"
+	"sort"
+	"sync"
+)
+
+// BM25 默认参数
+const (
+	// DefaultBM25K1 词频饱和参数
+	DefaultBM25K1 = 1.2
+	// DefaultBM25B 文档长度归一化参数
+	DefaultBM25B = 0.75
+)
+
+// BM25Index BM25 倒排索引
+//
+// 与 TFIDFVectorizer 使用相同的分词规则（中文字符单独成词），
+// 适合精确关键词命中；分数未归一化，只在同一查询内可比较。
+type BM25Index struct {
+	k1        float64
+	b         float64
+	postings  map[string][]bm25Posting // 词 -> 包含该词的文档及词频
+	docLens   []int                    // 文档长度（词元数）
+	avgDocLen float64
+	mu        sync.RWMutex
+}
+
+// bm25Posting 倒排表项
+type bm25Posting struct {
+	doc  int
+	freq int
+}
+
+// NewBM25Index 创建 BM25 索引，k1 或 b 小于 0 时使用默认值
+func NewBM25Index(k1, b float64) *BM25Index {
+	if k1 < 0 {
+		k1 = DefaultBM25K1
+	}
+	if b < 0 {
+		b = DefaultBM25B
+	}
+	return &BM25Index{
+		k1:       k1,
+		b:        b,
+		postings: make(map[string][]bm25Posting),
+	}
+}
+
+// Fit 以文档集合重建索引，文档下标即 SimilarityResult.Index
+func (x *BM25Index) Fit(documents []string) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	scratch := scratchPool.Get().(*vectorScratch)
+	defer scratch.release()
+
+	x.postings = make(map[string][]bm25Posting)
+	x.docLens = make([]int, len(documents))
+	total := 0
+	freqs := make(map[string]int)
+
+	for i, doc := range documents {
+		scratch.tokens = appendTokens(scratch.tokens[:0], doc)
+		clear(freqs)
+		for _, token := range scratch.tokens {
+			freqs[token]++
+		}
+		for token, freq := range freqs {
+			x.postings[token] = append(x.postings[token], bm25Posting{doc: i, freq: freq})
+		}
+		x.docLens[i] = len(scratch.tokens)
+		total += len(scratch.tokens)
+	}
+
+	x.avgDocLen = 0
+	if len(documents) > 0 {
+		x.avgDocLen = float64(total) / float64(len(documents))
+	}
+}
+
+// Search 返回与查询有词项重叠的文档，按 BM25 分数降序排列，topK <= 0 时返回全部
+func (x *BM25Index) Search(query string, topK int) []SimilarityResult {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	n := float64(len(x.docLens))
+	if n == 0 || x.avgDocLen == 0 {
+		return nil
+	}
+
+	scores := make(map[int]float64)
+	seen := make(map[string]struct{})
+	for _, token := range appendTokens(nil, query) {
+		if _, ok := seen[token]; ok {
+			continue
+		}
+		seen[token] = struct{}{}
+
+		postings := x.postings[token]
+		if len(postings) == 0 {
+			continue
+		}
+		df := float64(len(postings))
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		for _, p := range postings {
+			tf := float64(p.freq)
+			norm := x.k1 * (1 - x.b + x.b*float64(x.docLens[p.doc])/x.avgDocLen)
+			scores[p.doc] += idf * tf * (x.k1 + 1) / (tf + norm)
+		}
+	}
+
+	results := make([]SimilarityResult, 0, len(scores))
+	for doc, score := range scores {
+		results = append(results, SimilarityResult{Index: doc, Score: float32(score)})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Index < results[j].Index
+	})
+	if topK > 0 && topK < len(results) {
+		results = results[:topK]
+	}
+	return results
+}
+
+// DocumentCount 返回索引中的文档数量
+func (x *BM25Index) DocumentCount() int {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return len(x.docLens)
+}
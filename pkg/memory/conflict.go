@@ -0,0 +1,323 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ahhsitt/helloagents-go/pkg/core/llm"
+	"github.com/ahhsitt/helloagents-go/pkg/core/message"
+)
+
+// ConflictPolicy 冲突解决策略
+type ConflictPolicy string
+
+const (
+	// ConflictSupersede 新记忆取代冲突的旧记忆，旧记忆被删除
+	ConflictSupersede ConflictPolicy = "supersede"
+	// ConflictMerge 将新旧记忆合并为一条，更新旧记忆而不新增
+	ConflictMerge ConflictPolicy = "merge"
+	// ConflictKeepBoth 保留新旧记忆，并在双方元数据中标记冲突
+	ConflictKeepBoth ConflictPolicy = "keep_both"
+)
+
+// 冲突相关的元数据键
+const (
+	// MetadataSupersedes 新记忆取代的旧记忆 ID 列表
+	MetadataSupersedes = "supersedes"
+	// MetadataConflictsWith 与该记忆冲突的记忆 ID 列表
+	MetadataConflictsWith = "conflicts_with"
+	// MetadataMergedFrom 合并进该记忆的内容列表
+	MetadataMergedFrom = "merged_from"
+)
+
+// defaultConflictCandidates 冲突检测时召回的候选记忆数量
+const defaultConflictCandidates = 5
+
+// Conflict 新记忆与已有记忆之间的冲突
+type Conflict struct {
+	// Existing 冲突的已有记忆
+	Existing *MemoryItem
+	// Similarity 新旧记忆的嵌入相似度（未计算时为 0）
+	Similarity float32
+	// Reason 冲突原因
+	Reason string
+	// Merged 合并后的内容（可选，ConflictMerge 策略使用）
+	Merged string
+}
+
+// ConflictDetector 冲突检测器
+type ConflictDetector interface {
+	// Detect 返回 candidates 中与 item 相矛盾的记忆
+	Detect(ctx context.Context, item *MemoryItem, candidates []*MemoryItem) ([]Conflict, error)
+}
+
+// DefaultConflictPrompt 默认冲突验证提示模板
+const DefaultConflictPrompt = `请判断新记忆是否与已有记忆相矛盾（例如同一事实的取值发生了变化："用户住在旧金山" 与 "用户搬到了纽约"）。
+只是补充信息或描述不同事实的记忆不算冲突。
+
+新记忆：
+%s
+
+已有记忆：
+%s
+
+对每条冲突的已有记忆，给出其序号、冲突原因，以及把两者合并为一条最新事实的内容。
+请只输出符合以下 JSON Schema 的 JSON，不要输出其他内容：
+{"type":"object","properties":{"conflicts":{"type":"array","items":{"type":"object","properties":{"index":{"type":"integer"},"reason":{"type":"string"},"merged":{"type":"string"}},"required":["index"]}}},"required":["conflicts"]}`
+
+// LLMConflictDetector 基于嵌入相似度和 LLM 验证的冲突检测器
+//
+// 先用嵌入相似度筛掉无关的候选记忆，再由 LLM 判断剩余候选是否与新记忆相矛盾。
+// 未设置嵌入模型时跳过相似度筛选。
+type LLMConflictDetector struct {
+	provider      llm.Provider
+	embedder      Embedder
+	prompt        string
+	minSimilarity float32
+}
+
+// ConflictDetectorOption 冲突检测器选项
+type ConflictDetectorOption func(*LLMConflictDetector)
+
+// WithConflictPrompt 设置自定义提示模板（需包含两个 %s 占位符：新记忆和已有记忆列表）
+func WithConflictPrompt(prompt string) ConflictDetectorOption {
+	return func(d *LLMConflictDetector) {
+		d.prompt = prompt
+	}
+}
+
+// WithConflictSimilarity 设置进入 LLM 验证的最低嵌入相似度
+func WithConflictSimilarity(threshold float32) ConflictDetectorOption {
+	return func(d *LLMConflictDetector) {
+		d.minSimilarity = threshold
+	}
+}
+
+// NewLLMConflictDetector 创建冲突检测器
+//
+// embedder 可以为 nil，此时所有候选都交给 LLM 验证。
+func NewLLMConflictDetector(provider llm.Provider, embedder Embedder, opts ...ConflictDetectorOption) *LLMConflictDetector {
+	d := &LLMConflictDetector{
+		provider:      provider,
+		embedder:      embedder,
+		prompt:        DefaultConflictPrompt,
+		minSimilarity: 0.6,
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+// Detect 检测冲突
+func (d *LLMConflictDetector) Detect(ctx context.Context, item *MemoryItem, candidates []*MemoryItem) ([]Conflict, error) {
+	if d.provider == nil {
+		return nil, fmt.Errorf("conflict detector: llm provider not set")
+	}
+
+	similar, err := d.similarCandidates(ctx, item, candidates)
+	if err != nil {
+		return nil, err
+	}
+	if len(similar) == 0 {
+		return nil, nil
+	}
+	return d.verify(ctx, item, similar)
+}
+
+// similarCandidates 返回与新记忆嵌入相似度不低于阈值的候选
+func (d *LLMConflictDetector) similarCandidates(ctx context.Context, item *MemoryItem, candidates []*MemoryItem) ([]Conflict, error) {
+	similar := make([]Conflict, 0, len(candidates))
+	if d.embedder == nil {
+		for _, c := range candidates {
+			similar = append(similar, Conflict{Existing: c})
+		}
+		return similar, nil
+	}
+
+	texts := make([]string, 0, len(candidates)+1)
+	texts = append(texts, item.Content)
+	for _, c := range candidates {
+		texts = append(texts, c.Content)
+	}
+	vectors, err := d.embedder.Embed(ctx, texts)
+	if err != nil {
+		return nil, fmt.Errorf("conflict detector: %w", err)
+	}
+	if len(vectors) != len(texts) {
+		return nil, fmt.Errorf("conflict detector: %w", ErrEmbeddingFailed)
+	}
+
+	for i, c := range candidates {
+		similarity := cosineSimilarity(vectors[0], vectors[i+1])
+		if similarity >= d.minSimilarity {
+			similar = append(similar, Conflict{Existing: c, Similarity: similarity})
+		}
+	}
+	return similar, nil
+}
+
+// llmConflicts LLM 输出的 JSON 结构
+type llmConflicts struct {
+	Conflicts []struct {
+		Index  int    `json:"index"`
+		Reason string `json:"reason"`
+		Merged string `json:"merged"`
+	} `json:"conflicts"`
+}
+
+// verify 调用 LLM 判断候选是否与新记忆相矛盾
+func (d *LLMConflictDetector) verify(ctx context.Context, item *MemoryItem, similar []Conflict) ([]Conflict, error) {
+	var existing strings.Builder
+	for i, c := range similar {
+		fmt.Fprintf(&existing, "%d. %s\n", i+1, c.Existing.Content)
+	}
+
+	resp, err := d.provider.Generate(ctx, llm.Request{
+		Messages: []message.Message{
+			message.NewUserMessage(fmt.Sprintf(d.prompt, item.Content, existing.String())),
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("conflict detector: %w", err)
+	}
+
+	raw := jsonObject(resp.Content)
+	if raw == "" {
+		return nil, fmt.Errorf("conflict detector: no json in response %q", resp.Content)
+	}
+	var parsed llmConflicts
+	if err := json.Unmarshal([]byte(raw), &parsed); err != nil {
+		return nil, fmt.Errorf("conflict detector: %w", err)
+	}
+
+	conflicts := make([]Conflict, 0, len(parsed.Conflicts))
+	seen := make(map[int]struct{}, len(parsed.Conflicts))
+	for _, pc := range parsed.Conflicts {
+		if pc.Index < 1 || pc.Index > len(similar) {
+			continue
+		}
+		if _, ok := seen[pc.Index]; ok {
+			continue
+		}
+		seen[pc.Index] = struct{}{}
+
+		conflict := similar[pc.Index-1]
+		conflict.Reason = strings.TrimSpace(pc.Reason)
+		conflict.Merged = strings.TrimSpace(pc.Merged)
+		conflicts = append(conflicts, conflict)
+	}
+	return conflicts, nil
+}
+
+// detectConflicts 召回与新记忆相近的已有记忆并检测冲突
+//
+// 只对语义记忆检测。召回或检测失败时不报告冲突，不影响记忆写入。
+func (m *MemoryManager) detectConflicts(ctx context.Context, memory Memory, item *MemoryItem) []Conflict {
+	if m.conflicts == nil || item.MemoryType != MemoryTypeSemantic {
+		return nil
+	}
+
+	opts := []RetrieveOption{WithLimit(defaultConflictCandidates)}
+	if item.UserID != "" {
+		opts = append(opts, WithUserIDFilter(item.UserID))
+	}
+	candidates, err := memory.Retrieve(ctx, item.Content, opts...)
+	if err != nil || len(candidates) == 0 {
+		return nil
+	}
+
+	conflicts, err := m.conflicts.Detect(ctx, item, candidates)
+	if err != nil {
+		return nil
+	}
+	return conflicts
+}
+
+// resolveConflicts 按策略写入新记忆并处理冲突的旧记忆，返回新记忆（或合并后记忆）的 ID
+func (m *MemoryManager) resolveConflicts(ctx context.Context, memory Memory, item *MemoryItem, conflicts []Conflict) (string, error) {
+	ids := make([]string, len(conflicts))
+	for i, c := range conflicts {
+		ids[i] = c.Existing.ID
+	}
+	// 复制元数据，避免修改调用方传入的 map
+	metadata := make(map[string]interface{}, len(item.Metadata)+1)
+	for k, v := range item.Metadata {
+		metadata[k] = v
+	}
+	item.Metadata = metadata
+
+	switch m.conflictPolicy {
+	case ConflictMerge:
+		target := conflicts[0]
+		merged := target.Merged
+		if merged == "" {
+			merged = item.Content
+		}
+		metadata := conflictMetadata(target.Existing.Metadata)
+		metadata[MetadataMergedFrom] = appendMetadataList(metadata[MetadataMergedFrom], target.Existing.Content)
+		if err := memory.Update(ctx, target.Existing.ID,
+			WithContentUpdate(merged),
+			WithMetadataUpdate(metadata),
+		); err != nil {
+			return "", err
+		}
+		// 同时与其它旧记忆冲突时，合并后的记忆取代它们
+		for _, c := range conflicts[1:] {
+			_ = memory.Remove(ctx, c.Existing.ID)
+		}
+		return target.Existing.ID, nil
+
+	case ConflictKeepBoth:
+		item.Metadata[MetadataConflictsWith] = ids
+		id, err := memory.Add(ctx, item)
+		if err != nil {
+			return "", err
+		}
+		for _, c := range conflicts {
+			metadata := conflictMetadata(c.Existing.Metadata)
+			metadata[MetadataConflictsWith] = appendMetadataList(metadata[MetadataConflictsWith], id)
+			_ = memory.Update(ctx, c.Existing.ID, WithMetadataUpdate(metadata))
+		}
+		return id, nil
+
+	default:
+		item.Metadata[MetadataSupersedes] = ids
+		id, err := memory.Add(ctx, item)
+		if err != nil {
+			return "", err
+		}
+		for _, c := range conflicts {
+			_ = memory.Remove(ctx, c.Existing.ID)
+		}
+		return id, nil
+	}
+}
+
+// conflictMetadata 复制已有记忆的元数据，去掉检索时附加的分数
+func conflictMetadata(metadata map[string]interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(metadata)+1)
+	for k, v := range metadata {
+		copied[k] = v
+	}
+	delete(copied, "score")
+	return copied
+}
+
+// appendMetadataList 向元数据中的字符串列表追加一项
+func appendMetadataList(existing interface{}, value string) []string {
+	var list []string
+	switch v := existing.(type) {
+	case []string:
+		list = append(list, v...)
+	case []interface{}:
+		for _, s := range v {
+			if str, ok := s.(string); ok {
+				list = append(list, str)
+			}
+		}
+	}
+	return append(list, value)
+}
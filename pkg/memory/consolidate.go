@@ -0,0 +1,108 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ahhsitt/helloagents-go/pkg/core/llm"
+	"github.com/ahhsitt/helloagents-go/pkg/core/message"
+)
+
+// DefaultConsolidateClusterThreshold 默认的聚类相似度阈值
+const DefaultConsolidateClusterThreshold float32 = 0.3
+
+// consolidatePrompt 记忆整合摘要提示词
+const consolidatePrompt = `以下是若干条相关的工作记忆，请将它们整合为一条精炼的长期记忆。
+要求：保留关键事实、结论和决定，去除重复内容，只输出整合后的记忆内容。
+
+%s`
+
+// WithConsolidateSummarizer 使用 LLM 将相关记忆摘要为一条记忆
+//
+// 设置后，待整合的记忆先按 TF-IDF 相似度聚类，包含多条记忆的簇由 LLM 摘要为
+// 一条记忆写入目标类型，原始 ID 记录在 metadata["consolidated_from"]；
+// 单条记忆仍按原文转移。摘要失败的簇保留在工作记忆中，等待下次整合。
+func WithConsolidateSummarizer(provider llm.Provider) ConsolidateOption {
+	return func(o *consolidateOptions) {
+		o.summarizer = provider
+	}
+}
+
+// WithConsolidateClusterThreshold 设置聚类相似度阈值 (0-1)
+func WithConsolidateClusterThreshold(threshold float32) ConsolidateOption {
+	return func(o *consolidateOptions) {
+		o.clusterThreshold = threshold
+	}
+}
+
+// clusterItems 按内容相似度对记忆贪心聚类
+//
+// 每个簇以最早未分配的记忆为中心，吸收与其相似度不低于 threshold 的记忆。
+func clusterItems(items []*MemoryItem, threshold float32) [][]*MemoryItem {
+	contents := make([]string, len(items))
+	for i, item := range items {
+		contents[i] = item.Content
+	}
+
+	vectorizer := NewTFIDFVectorizer()
+	vectors := vectorizer.FitTransform(contents)
+
+	assigned := make([]bool, len(items))
+	var clusters [][]*MemoryItem
+	for i := range items {
+		if assigned[i] {
+			continue
+		}
+		assigned[i] = true
+		cluster := []*MemoryItem{items[i]}
+		for j := i + 1; j < len(items); j++ {
+			if assigned[j] {
+				continue
+			}
+			if vectorizer.CosineSimilarity(vectors[i], vectors[j]) >= threshold {
+				assigned[j] = true
+				cluster = append(cluster, items[j])
+			}
+		}
+		clusters = append(clusters, cluster)
+	}
+	return clusters
+}
+
+// summarizeCluster 调用 LLM 将一簇记忆摘要为一条目标类型的记忆
+func summarizeCluster(ctx context.Context, provider llm.Provider, cluster []*MemoryItem, targetType MemoryType) (*MemoryItem, error) {
+	var sb strings.Builder
+	ids := make([]string, len(cluster))
+	importance := float32(0)
+	for i, item := range cluster {
+		sb.WriteString("- ")
+		sb.WriteString(item.Content)
+		sb.WriteString("\n")
+		ids[i] = item.ID
+		if item.Importance > importance {
+			importance = item.Importance
+		}
+	}
+
+	resp, err := provider.Generate(ctx, llm.Request{
+		Messages: []message.Message{
+			message.NewUserMessage(fmt.Sprintf(consolidatePrompt, sb.String())),
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("summarize memories: %w", err)
+	}
+	summary := strings.TrimSpace(resp.Content)
+	if summary == "" {
+		return nil, fmt.Errorf("summarize memories: empty summary: %w", ErrInvalidInput)
+	}
+
+	item := NewMemoryItem(summary, targetType,
+		WithImportance(importance),
+		WithUserID(cluster[0].UserID),
+	)
+	item.Metadata["consolidated_from"] = ids
+	item.Metadata["consolidated_count"] = len(ids)
+	return item, nil
+}
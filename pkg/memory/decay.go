@@ -0,0 +1,230 @@
+package memory
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// DecayPolicy 重要性衰减与强化策略
+//
+// 重要性随时间指数衰减：每经过 HalfLife，重要性减半；
+// 记忆被检索命中时得到强化：importance += ReinforceBoost × (1 - importance)。
+type DecayPolicy struct {
+	// HalfLife 重要性衰减一半所需的时间
+	HalfLife time.Duration
+	// ReinforceBoost 检索命中时的强化比例 (0-1)，0 表示不强化
+	ReinforceBoost float32
+	// MinImportance 衰减下限
+	MinImportance float32
+	// ForgetThreshold Tick 时遗忘重要性低于此值的记忆，0 表示使用 MemoryConfig.ImportanceThreshold
+	ForgetThreshold float32
+}
+
+// DefaultDecayPolicy 返回默认衰减策略（半衰期 7 天，命中强化 20%）
+func DefaultDecayPolicy() DecayPolicy {
+	return DecayPolicy{
+		HalfLife:       7 * 24 * time.Hour,
+		ReinforceBoost: 0.2,
+	}
+}
+
+// factor 返回经过 elapsed 时间后的衰减系数
+func (p DecayPolicy) factor(elapsed time.Duration) float64 {
+	if p.HalfLife <= 0 || elapsed <= 0 {
+		return 1
+	}
+	return math.Exp2(-float64(elapsed) / float64(p.HalfLife))
+}
+
+// reinforce 返回强化后的重要性
+func (p DecayPolicy) reinforce(importance float32) float32 {
+	return reinforceImportance(importance, p.ReinforceBoost)
+}
+
+// reinforceImportance 计算强化后的重要性
+func reinforceImportance(importance, boost float32) float32 {
+	return clampImportance(importance + boost*(1-importance))
+}
+
+// ImportanceDecayer 支持重要性衰减的记忆接口
+type ImportanceDecayer interface {
+	// DecayImportance 将所有记忆的重要性乘以 factor，结果不低于 floor，返回被修改的记忆数量
+	DecayImportance(ctx context.Context, factor float64, floor float32) (int, error)
+}
+
+// ImportanceReinforcer 支持批量强化重要性的记忆接口
+//
+// 只修改重要性，不重新生成向量、不产生事件版本。
+type ImportanceReinforcer interface {
+	// ReinforceImportance 强化 ids 对应记忆的重要性（importance += boost × (1 - importance)），返回被修改的记忆数量
+	ReinforceImportance(ctx context.Context, ids []string, boost float32) (int, error)
+}
+
+// compile-time interface check
+var _ ImportanceDecayer = (*WorkingMemory)(nil)
+var _ ImportanceDecayer = (*EpisodicMemoryStore)(nil)
+var _ ImportanceDecayer = (*SemanticMemoryStore)(nil)
+var _ ImportanceReinforcer = (*WorkingMemory)(nil)
+var _ ImportanceReinforcer = (*EpisodicMemoryStore)(nil)
+var _ ImportanceReinforcer = (*SemanticMemoryStore)(nil)
+
+// decayImportance 计算衰减后的重要性（原本低于 floor 的值保持不变）
+func decayImportance(importance float32, factor float64, floor float32) float32 {
+	if importance <= floor {
+		return importance
+	}
+	decayed := float32(float64(importance) * factor)
+	if decayed < floor {
+		decayed = floor
+	}
+	return decayed
+}
+
+// WithDecayPolicy 启用重要性衰减与强化
+//
+// 启用后 RetrieveMemories 会强化命中的记忆，Decay/Tick 会按时间衰减所有记忆。
+func WithDecayPolicy(policy DecayPolicy) ManagerOption {
+	return func(m *MemoryManager) {
+		m.decay = &policy
+	}
+}
+
+// Decay 按距离上次衰减经过的时间衰减所有记忆的重要性
+//
+// 只作用于实现了 ImportanceDecayer 的记忆类型，返回被修改的记忆数量。
+// 未配置 DecayPolicy 时不做任何操作。
+func (m *MemoryManager) Decay(ctx context.Context) (int, error) {
+	return m.decayAt(ctx, time.Now())
+}
+
+// decayAt 以指定时间为当前时间执行衰减
+func (m *MemoryManager) decayAt(ctx context.Context, now time.Time) (int, error) {
+	if m.decay == nil {
+		return 0, nil
+	}
+
+	m.decayMu.Lock()
+	defer m.decayMu.Unlock()
+
+	factor := m.decay.factor(now.Sub(m.lastDecay))
+	m.lastDecay = now
+	if factor >= 1 {
+		return 0, nil
+	}
+
+	var (
+		total    int
+		firstErr error
+	)
+	for _, memory := range m.snapshotMemories() {
+		decayer, ok := memory.(ImportanceDecayer)
+		if !ok {
+			continue
+		}
+		n, err := decayer.DecayImportance(ctx, factor, m.decay.MinImportance)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		total += n
+	}
+
+	return total, firstErr
+}
+
+// Tick 执行一次衰减，并遗忘重要性低于阈值的记忆
+//
+// 返回被衰减和被遗忘的记忆数量。
+func (m *MemoryManager) Tick(ctx context.Context) (decayed, forgotten int, err error) {
+	return m.tickAt(ctx, time.Now())
+}
+
+// tickAt 以指定时间为当前时间执行 Tick
+func (m *MemoryManager) tickAt(ctx context.Context, now time.Time) (decayed, forgotten int, err error) {
+	if m.decay == nil {
+		return 0, 0, nil
+	}
+
+	decayed, err = m.decayAt(ctx, now)
+	if err != nil {
+		return decayed, 0, err
+	}
+
+	threshold := m.decay.ForgetThreshold
+	if threshold <= 0 {
+		threshold = m.config.ImportanceThreshold
+	}
+	forgotten, err = m.ForgetMemories(ctx, ForgetByImportance, WithThreshold(threshold))
+	return decayed, forgotten, err
+}
+
+// RunDecayLoop 按固定间隔执行 Tick，直到 ctx 被取消
+//
+// 通常在后台 goroutine 中运行：go manager.RunDecayLoop(ctx, time.Hour)。
+func (m *MemoryManager) RunDecayLoop(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			_, _, _ = m.Tick(ctx)
+		}
+	}
+}
+
+// reinforce 强化检索命中的记忆
+//
+// 按记忆类型分组，实现了 ImportanceReinforcer 的记忆类型一次批量写入重要性，
+// 其他记忆类型逐条通过 Update 更新。
+func (m *MemoryManager) reinforce(ctx context.Context, items []*MemoryItem) {
+	if m.decay == nil || m.decay.ReinforceBoost <= 0 {
+		return
+	}
+
+	memories := m.snapshotMemories()
+	byType := make(map[MemoryType][]*MemoryItem)
+	for _, item := range items {
+		if _, ok := memories[item.MemoryType]; ok {
+			byType[item.MemoryType] = append(byType[item.MemoryType], item)
+		}
+	}
+
+	for memType, group := range byType {
+		memory := memories[memType]
+		if reinforcer, ok := memory.(ImportanceReinforcer); ok {
+			ids := make([]string, len(group))
+			for i, item := range group {
+				ids[i] = item.ID
+			}
+			if _, err := reinforcer.ReinforceImportance(ctx, ids, m.decay.ReinforceBoost); err != nil {
+				continue
+			}
+			for _, item := range group {
+				item.Importance = m.decay.reinforce(item.Importance)
+			}
+			continue
+		}
+
+		for _, item := range group {
+			importance := m.decay.reinforce(item.Importance)
+			if err := memory.Update(ctx, item.ID, WithImportanceUpdate(importance)); err == nil {
+				item.Importance = importance
+			}
+		}
+	}
+}
+
+// snapshotMemories 返回已注册记忆类型的副本
+func (m *MemoryManager) snapshotMemories() map[MemoryType]Memory {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	memories := make(map[MemoryType]Memory, len(m.memoryTypes))
+	for k, v := range m.memoryTypes {
+		memories[k] = v
+	}
+	return memories
+}
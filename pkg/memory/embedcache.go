@@ -0,0 +1,251 @@
+package memory
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/ahhsitt/helloagents-go/pkg/memory/store"
+)
+
+// 嵌入缓存默认参数
+const (
+	// DefaultEmbeddingCacheSize 内存 LRU 默认容量（条）
+	DefaultEmbeddingCacheSize = 10000
+	// DefaultEmbeddingCacheCollection 持久化缓存默认集合名
+	DefaultEmbeddingCacheCollection = "embedding_cache"
+)
+
+// EmbeddingCache 按内容哈希缓存嵌入向量的嵌入器
+//
+// 包装任意 Embedder：相同文本（在同一命名空间内）只嵌入一次，重复摄取时直接返回缓存的向量。
+// 缓存分两级：内存 LRU，以及可选的文档存储（如 store.SQLiteDocumentStore），
+// 持久化缓存在进程重启后仍然有效。持久化读写失败时按未命中处理，不影响嵌入结果。
+//
+// EmbeddingCache 同样满足 rag.Embedder，可在记忆和 RAG 之间共享同一个缓存。
+type EmbeddingCache struct {
+	embedder   Embedder
+	capacity   int
+	namespace  string
+	docs       store.DocumentStore
+	collection string
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List // 最近使用的在前
+	hits    int64
+	misses  int64
+}
+
+// embeddingCacheEntry LRU 缓存项
+type embeddingCacheEntry struct {
+	key    string
+	vector []float32
+}
+
+// EmbeddingCacheStats 嵌入缓存统计
+type EmbeddingCacheStats struct {
+	// Hits 命中次数（按文本计）
+	Hits int64 `json:"hits"`
+	// Misses 未命中次数（按文本计）
+	Misses int64 `json:"misses"`
+	// Size 内存缓存中的条目数
+	Size int `json:"size"`
+}
+
+// EmbeddingCacheOption 嵌入缓存选项
+type EmbeddingCacheOption func(*EmbeddingCache)
+
+// WithEmbeddingCacheSize 设置内存 LRU 容量
+func WithEmbeddingCacheSize(size int) EmbeddingCacheOption {
+	return func(c *EmbeddingCache) {
+		c.capacity = size
+	}
+}
+
+// WithEmbeddingCacheNamespace 设置命名空间（通常为模型名称），不同命名空间的缓存互不命中
+func WithEmbeddingCacheNamespace(namespace string) EmbeddingCacheOption {
+	return func(c *EmbeddingCache) {
+		c.namespace = namespace
+	}
+}
+
+// WithEmbeddingCacheStore 将缓存持久化到文档存储，collection 为空时使用 DefaultEmbeddingCacheCollection
+func WithEmbeddingCacheStore(docStore store.DocumentStore, collection string) EmbeddingCacheOption {
+	return func(c *EmbeddingCache) {
+		if collection == "" {
+			collection = DefaultEmbeddingCacheCollection
+		}
+		c.docs = docStore
+		c.collection = collection
+	}
+}
+
+// NewEmbeddingCache 创建嵌入缓存
+func NewEmbeddingCache(embedder Embedder, opts ...EmbeddingCacheOption) *EmbeddingCache {
+	c := &EmbeddingCache{
+		embedder: embedder,
+		capacity: DefaultEmbeddingCacheSize,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Embed 返回文本的嵌入向量，未命中的文本（去重后）通过一次 Embed 调用生成
+func (c *EmbeddingCache) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	keys := make([]string, len(texts))
+
+	// 查询内存和持久化缓存，pending 记录未命中的 key 及其在 texts 中的位置
+	pending := make(map[string][]int)
+	var missTexts []string
+	var missKeys []string
+	for i, text := range texts {
+		keys[i] = c.key(text)
+		if vector, ok := c.lookup(ctx, keys[i]); ok {
+			vectors[i] = vector
+			continue
+		}
+		if _, ok := pending[keys[i]]; !ok {
+			missTexts = append(missTexts, text)
+			missKeys = append(missKeys, keys[i])
+		}
+		pending[keys[i]] = append(pending[keys[i]], i)
+	}
+
+	c.mu.Lock()
+	c.hits += int64(len(texts) - len(missTexts))
+	c.misses += int64(len(missTexts))
+	c.mu.Unlock()
+
+	if len(missTexts) == 0 {
+		return vectors, nil
+	}
+
+	embedded, err := c.embedder.Embed(ctx, missTexts)
+	if err != nil {
+		return nil, err
+	}
+	if len(embedded) != len(missTexts) {
+		return nil, ErrEmbeddingFailed
+	}
+
+	for j, key := range missKeys {
+		c.put(ctx, key, embedded[j])
+		for _, i := range pending[key] {
+			vectors[i] = embedded[j]
+		}
+	}
+	return vectors, nil
+}
+
+// Stats 返回缓存统计
+func (c *EmbeddingCache) Stats() EmbeddingCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return EmbeddingCacheStats{
+		Hits:   c.hits,
+		Misses: c.misses,
+		Size:   c.order.Len(),
+	}
+}
+
+// key 返回文本在命名空间内的内容哈希
+func (c *EmbeddingCache) key(text string) string {
+	h := sha256.New()
+	h.Write([]byte(c.namespace))
+	h.Write([]byte{0})
+	h.Write([]byte(text))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// lookup 依次查询内存和持久化缓存，持久化命中时回填内存缓存
+func (c *EmbeddingCache) lookup(ctx context.Context, key string) ([]float32, bool) {
+	c.mu.Lock()
+	if elem, ok := c.entries[key]; ok {
+		c.order.MoveToFront(elem)
+		vector := elem.Value.(*embeddingCacheEntry).vector
+		c.mu.Unlock()
+		return vector, true
+	}
+	c.mu.Unlock()
+
+	if c.docs == nil {
+		return nil, false
+	}
+	doc, err := c.docs.Get(ctx, c.collection, key)
+	if err != nil || doc == nil {
+		return nil, false
+	}
+	vector := metadataVector(doc.Metadata["vector"])
+	if vector == nil {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	c.insertLocked(key, vector)
+	c.mu.Unlock()
+	return vector, true
+}
+
+// put 写入内存缓存和持久化缓存（持久化失败被忽略）
+func (c *EmbeddingCache) put(ctx context.Context, key string, vector []float32) {
+	c.mu.Lock()
+	c.insertLocked(key, vector)
+	c.mu.Unlock()
+
+	if c.docs != nil {
+		_ = c.docs.Put(ctx, c.collection, key, store.Document{
+			ID:        key,
+			Metadata:  map[string]interface{}{"vector": vector, "namespace": c.namespace},
+			CreatedAt: time.Now(),
+		})
+	}
+}
+
+// insertLocked 插入或刷新缓存项，超出容量时淘汰最久未使用的项（调用方需持有锁）
+func (c *EmbeddingCache) insertLocked(key string, vector []float32) {
+	if c.capacity <= 0 {
+		return
+	}
+	if elem, ok := c.entries[key]; ok {
+		elem.Value.(*embeddingCacheEntry).vector = vector
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&embeddingCacheEntry{key: key, vector: vector})
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*embeddingCacheEntry).key)
+	}
+}
+
+// metadataVector 解析元数据中的向量，支持 []float32 和 JSON 解码得到的 []interface{}
+func metadataVector(v interface{}) []float32 {
+	switch vec := v.(type) {
+	case []float32:
+		return vec
+	case []interface{}:
+		vector := make([]float32, len(vec))
+		for i, x := range vec {
+			f, ok := x.(float64)
+			if !ok {
+				return nil
+			}
+			vector[i] = float32(f)
+		}
+		return vector
+	}
+	return nil
+}
+
+// compile-time interface check
+var _ Embedder = (*EmbeddingCache)(nil)
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/ahhsitt/helloagents-go/pkg/core/llm"
+	"github.com/ahhsitt/helloagents-go/pkg/core/retry"
 )
 
 // OpenAIEmbedder OpenAI 嵌入实现
@@ -21,5 +22,39 @@ func (e *OpenAIEmbedder) Embed(ctx context.Context, texts []string) ([][]float32
 	return e.provider.Embed(ctx, texts)
 }
 
+// RetryEmbedder 按重试策略重试失败嵌入调用的嵌入器
+//
+// 包装任意 Embedder（包括本地模型或自定义 HTTP 嵌入服务），
+// 可重试的错误（限速、超时、服务不可用）按策略退避后重试。
+// 同样满足 rag.Embedder。
+type RetryEmbedder struct {
+	embedder Embedder
+	policy   *retry.Policy
+}
+
+// NewRetryEmbedder 创建重试嵌入器，policy 为空时使用全局默认策略（retry.Default）
+func NewRetryEmbedder(embedder Embedder, policy *retry.Policy) *RetryEmbedder {
+	return &RetryEmbedder{embedder: embedder, policy: policy}
+}
+
+// Embed 将文本转换为向量，失败时按策略重试
+func (e *RetryEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	policy := retry.Default()
+	if e.policy != nil {
+		policy = *e.policy
+	}
+
+	var vectors [][]float32
+	err := policy.DoNamed(ctx, "embedder", func(ctx context.Context) error {
+		var err error
+		vectors, err = e.embedder.Embed(ctx, texts)
+		return err
+	})
+	return vectors, err
+}
+
 // compile-time interface check
-var _ Embedder = (*OpenAIEmbedder)(nil)
+var (
+	_ Embedder = (*OpenAIEmbedder)(nil)
+	_ Embedder = (*RetryEmbedder)(nil)
+)
@@ -14,26 +14,53 @@ import (
 //
 // 用于存储和检索特定事件或经历，支持会话管理、模式识别和时间线视图。
 type EpisodicMemoryStore struct {
-	episodes []Episode
-	sessions map[string][]string // sessionID -> episodeIDs
-	tfidf    *TFIDFVectorizer    // 用于本地语义检索
-	mu       sync.RWMutex
+	episodes   []Episode
+	sessions   map[string][]string // sessionID -> episodeIDs
+	tfidf      *TFIDFVectorizer    // 用于本地语义检索
+	tfidfStale bool                // TF-IDF 索引是否需要重建
+	scope      scopeIndex          // 用户/会话索引，与 TF-IDF 一同重建
+	persist    *persistence        // 可选的持久化存储
+	forgotten  forgetLog           // 遗忘记录，用于统计
+	history    *episodeHistory     // 可选的事件版本记录
+	mu         sync.RWMutex
 }
 
 // NewEpisodicMemory 创建情景记忆存储
-func NewEpisodicMemory() *EpisodicMemoryStore {
-	return &EpisodicMemoryStore{
+func NewEpisodicMemory(opts ...EpisodicMemoryOption) *EpisodicMemoryStore {
+	m := &EpisodicMemoryStore{
 		episodes: make([]Episode, 0),
 		sessions: make(map[string][]string),
 		tfidf:    NewTFIDFVectorizer(),
 	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return m
 }
 
 // AddEpisode 添加事件
 func (m *EpisodicMemoryStore) AddEpisode(ctx context.Context, episode Episode) error {
+	if err := m.ensureLoaded(ctx); err != nil {
+		return err
+	}
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	if _, err := m.appendLocked(ctx, episode); err != nil {
+		return err
+	}
+
+	// 标记 TF-IDF 索引过期，检索时统一重建
+	m.tfidfStale = true
+
+	return nil
+}
+
+// appendLocked 追加事件并维护 sessions 索引，返回事件 ID（调用方需持有写锁并负责重建 TF-IDF）
+func (m *EpisodicMemoryStore) appendLocked(ctx context.Context, episode Episode) (string, error) {
 	// 生成 ID（如果未提供）
 	if episode.ID == "" {
 		episode.ID = uuid.New().String()
@@ -44,17 +71,33 @@ func (m *EpisodicMemoryStore) AddEpisode(ctx context.Context, episode Episode) e
 		episode.Timestamp = time.Now().UnixMilli()
 	}
 
+	if m.persist != nil {
+		if err := m.persist.put(ctx, episodeToDocument(episode)); err != nil {
+			return "", err
+		}
+	}
+
 	m.episodes = append(m.episodes, episode)
+	if m.history != nil {
+		m.history.add(episode, time.Now())
+	}
 
 	// 维护 sessions 索引
 	if episode.SessionID != "" {
 		m.sessions[episode.SessionID] = append(m.sessions[episode.SessionID], episode.ID)
 	}
 
-	// 重建 TF-IDF
-	m.rebuildTFIDF()
+	return episode.ID, nil
+}
 
-	return nil
+// rebuildSessions 重建 sessions 索引（调用方需持有写锁）
+func (m *EpisodicMemoryStore) rebuildSessions() {
+	m.sessions = make(map[string][]string)
+	for _, ep := range m.episodes {
+		if ep.SessionID != "" {
+			m.sessions[ep.SessionID] = append(m.sessions[ep.SessionID], ep.ID)
+		}
+	}
 }
 
 // rebuildTFIDF 重建 TF-IDF 向量化器
@@ -77,8 +120,58 @@ func (m *EpisodicMemoryStore) rebuildTFIDF() {
 	}
 }
 
+// rebuildScope 重建用户/会话索引
+func (m *EpisodicMemoryStore) rebuildScope() {
+	m.scope.rebuild(len(m.episodes), func(i int) (string, string) {
+		return m.episodes[i].UserID, m.episodes[i].SessionID
+	})
+}
+
+// Flush 重建过期的 TF-IDF 索引和用户/会话索引
+//
+// 写操作只将索引标记为过期，索引在下次检索或调用 Flush 时统一重建，
+// 批量写入后可主动调用 Flush 以避免首次检索的重建延迟。
+func (m *EpisodicMemoryStore) Flush() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.tfidfStale {
+		m.rebuildTFIDF()
+		m.rebuildScope()
+		m.tfidfStale = false
+	}
+}
+
+// scopedEpisodes 返回满足用户/会话过滤条件的事件（调用方需持有读锁且索引已重建）
+func (m *EpisodicMemoryStore) scopedEpisodes(options *retrieveOptions) []Episode {
+	if !options.scoped() {
+		return m.episodes
+	}
+	positions := m.scope.positions(options)
+	episodes := make([]Episode, len(positions))
+	for i, pos := range positions {
+		episodes[i] = m.episodes[pos]
+	}
+	return episodes
+}
+
+// ensureTFIDF 检索前确保 TF-IDF 索引是最新的
+func (m *EpisodicMemoryStore) ensureTFIDF() {
+	m.mu.RLock()
+	stale := m.tfidfStale
+	m.mu.RUnlock()
+
+	if stale {
+		m.Flush()
+	}
+}
+
 // GetEpisodes 获取事件列表
 func (m *EpisodicMemoryStore) GetEpisodes(ctx context.Context, filter *EpisodeFilter) ([]Episode, error) {
+	if err := m.ensureLoaded(ctx); err != nil {
+		return nil, err
+	}
+
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
@@ -114,6 +207,10 @@ func (m *EpisodicMemoryStore) GetEpisodes(ctx context.Context, filter *EpisodeFi
 
 // GetByTimeRange 按时间范围获取事件
 func (m *EpisodicMemoryStore) GetByTimeRange(ctx context.Context, start, end int64) ([]Episode, error) {
+	if err := m.ensureLoaded(ctx); err != nil {
+		return nil, err
+	}
+
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
@@ -143,6 +240,10 @@ func (m *EpisodicMemoryStore) GetByType(ctx context.Context, typ string, limit i
 
 // GetMostImportant 获取最重要的事件
 func (m *EpisodicMemoryStore) GetMostImportant(ctx context.Context, limit int) ([]Episode, error) {
+	if err := m.ensureLoaded(ctx); err != nil {
+		return nil, err
+	}
+
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
@@ -163,16 +264,31 @@ func (m *EpisodicMemoryStore) GetMostImportant(ctx context.Context, limit int) (
 
 // Clear 清空所有事件
 func (m *EpisodicMemoryStore) Clear(ctx context.Context) error {
+	if err := m.ensureLoaded(ctx); err != nil {
+		return err
+	}
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	if m.history != nil {
+		now := time.Now()
+		for _, ep := range m.episodes {
+			m.history.remove(ep, now)
+		}
+	}
 	m.episodes = make([]Episode, 0)
 	m.sessions = make(map[string][]string)
+	m.scope = scopeIndex{}
 	m.tfidf.Clear()
+	if m.persist != nil {
+		return m.persist.clear(ctx)
+	}
 	return nil
 }
 
 // Size 返回事件数量
 func (m *EpisodicMemoryStore) Size() int {
+	_ = m.ensureLoaded(context.Background())
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 	return len(m.episodes)
@@ -198,6 +314,12 @@ func (m *EpisodicMemoryStore) Add(ctx context.Context, item *MemoryItem) (string
 		return "", err
 	}
 
+	episode := itemToEpisode(item)
+	return episode.ID, m.AddEpisode(ctx, episode)
+}
+
+// itemToEpisode 将记忆项转换为事件
+func itemToEpisode(item *MemoryItem) Episode {
 	episode := Episode{
 		ID:         item.ID,
 		Type:       item.GetMetadataString("type"),
@@ -214,13 +336,19 @@ func (m *EpisodicMemoryStore) Add(ctx context.Context, item *MemoryItem) (string
 		episode.Context = ctx
 	}
 
-	return episode.ID, m.AddEpisode(ctx, episode)
+	return episode
 }
 
 // Retrieve 检索记忆（实现 Memory 接口）
 //
 // 使用 TF-IDF 语义检索，失败时回退到关键词匹配。
+// 设置 WithUserIDFilter/WithSessionIDFilter 时只对索引命中的事件评分。
+// 设置 WithAsOf 时在该时刻的事件上检索（参见 GetStateAt）。
 func (m *EpisodicMemoryStore) Retrieve(ctx context.Context, query string, opts ...RetrieveOption) ([]*MemoryItem, error) {
+	if err := m.ensureLoaded(ctx); err != nil {
+		return nil, err
+	}
+
 	options := &retrieveOptions{
 		limit: 10,
 	}
@@ -228,18 +356,26 @@ func (m *EpisodicMemoryStore) Retrieve(ctx context.Context, query string, opts .
 		opt(options)
 	}
 
+	m.ensureTFIDF()
+
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	if len(m.episodes) == 0 {
-		return nil, nil
-	}
+	var results []*MemoryItem
+	if !options.asOf.IsZero() {
+		results = m.retrieveAsOfLocked(query, options.asOf, options)
+	} else {
+		episodes := m.scopedEpisodes(options)
+		if len(episodes) == 0 {
+			return nil, nil
+		}
 
-	// 尝试 TF-IDF 检索
-	results := m.tfidfSearch(query, options.limit)
-	if len(results) == 0 {
-		// 回退到关键词检索
-		results = m.keywordSearch(query, options.limit)
+		// 尝试 TF-IDF 检索
+		results = m.tfidfSearch(m.tfidf, episodes, query, options.limit)
+		if len(results) == 0 {
+			// 回退到关键词检索
+			results = m.keywordSearch(episodes, query, options.limit)
+		}
 	}
 
 	// 过滤最小分数
@@ -263,25 +399,25 @@ type scoredEpisode struct {
 	similarity float32
 }
 
-// tfidfSearch TF-IDF 语义检索
-func (m *EpisodicMemoryStore) tfidfSearch(query string, limit int) []*MemoryItem {
-	if m.tfidf.VocabularySize() == 0 {
+// tfidfSearch 使用 tfidf 向量化器进行语义检索（事件向量须由同一向量化器生成）
+func (m *EpisodicMemoryStore) tfidfSearch(tfidf *TFIDFVectorizer, episodes []Episode, query string, limit int) []*MemoryItem {
+	if tfidf.VocabularySize() == 0 {
 		return nil
 	}
 
-	queryVector := m.tfidf.Transform(query)
+	queryVector := tfidf.Transform(query)
 	if queryVector == nil {
 		return nil
 	}
 
-	scored := make([]scoredEpisode, 0, len(m.episodes))
+	scored := make([]scoredEpisode, 0, len(episodes))
 	now := time.Now().UnixMilli()
 
-	for _, ep := range m.episodes {
+	for _, ep := range episodes {
 		if ep.Vector == nil {
 			continue
 		}
-		similarity := m.tfidf.CosineSimilarity(queryVector, ep.Vector)
+		similarity := tfidf.CosineSimilarity(queryVector, ep.Vector)
 		ageDays := float32(now-ep.Timestamp) / (24 * 60 * 60 * 1000)
 		score := m.calculateScore(similarity, ageDays, ep.Importance)
 		scored = append(scored, scoredEpisode{
@@ -309,14 +445,14 @@ func (m *EpisodicMemoryStore) tfidfSearch(query string, limit int) []*MemoryItem
 }
 
 // keywordSearch 关键词匹配检索
-func (m *EpisodicMemoryStore) keywordSearch(query string, limit int) []*MemoryItem {
+func (m *EpisodicMemoryStore) keywordSearch(episodes []Episode, query string, limit int) []*MemoryItem {
 	query = strings.ToLower(query)
 	keywords := strings.Fields(query)
 
-	scored := make([]scoredEpisode, 0, len(m.episodes))
+	scored := make([]scoredEpisode, 0, len(episodes))
 	now := time.Now().UnixMilli()
 
-	for _, ep := range m.episodes {
+	for _, ep := range episodes {
 		content := strings.ToLower(ep.Content)
 		matchCount := 0
 		for _, kw := range keywords {
@@ -394,6 +530,10 @@ func (m *EpisodicMemoryStore) episodeToItem(ep Episode, score float32) *MemoryIt
 
 // Update 更新记忆（实现 Memory 接口）
 func (m *EpisodicMemoryStore) Update(ctx context.Context, id string, opts ...UpdateOption) error {
+	if err := m.ensureLoaded(ctx); err != nil {
+		return err
+	}
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -404,6 +544,7 @@ func (m *EpisodicMemoryStore) Update(ctx context.Context, id string, opts ...Upd
 
 	for i := range m.episodes {
 		if m.episodes[i].ID == id {
+			prev := m.episodes[i]
 			if options.content != nil {
 				m.episodes[i].Content = *options.content
 			}
@@ -413,7 +554,16 @@ func (m *EpisodicMemoryStore) Update(ctx context.Context, id string, opts ...Upd
 			if options.metadata != nil {
 				m.episodes[i].Metadata = options.metadata
 			}
-			m.rebuildTFIDF()
+			// 只调整重要性（如检索强化）与衰减一样不产生新版本，避免版本链无限增长
+			if options.content != nil || options.metadata != nil {
+				if m.history != nil {
+					m.history.update(prev, m.episodes[i], time.Now())
+				}
+				m.tfidfStale = true
+			}
+			if m.persist != nil {
+				return m.persist.put(ctx, episodeToDocument(m.episodes[i]))
+			}
 			return nil
 		}
 	}
@@ -423,6 +573,10 @@ func (m *EpisodicMemoryStore) Update(ctx context.Context, id string, opts ...Upd
 
 // Remove 删除记忆（实现 Memory 接口）
 func (m *EpisodicMemoryStore) Remove(ctx context.Context, id string) error {
+	if err := m.ensureLoaded(ctx); err != nil {
+		return err
+	}
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -439,8 +593,14 @@ func (m *EpisodicMemoryStore) Remove(ctx context.Context, id string) error {
 					}
 				}
 			}
+			if m.history != nil {
+				m.history.remove(m.episodes[i], time.Now())
+			}
 			m.episodes = append(m.episodes[:i], m.episodes[i+1:]...)
-			m.rebuildTFIDF()
+			m.tfidfStale = true
+			if m.persist != nil {
+				return m.persist.delete(ctx, id)
+			}
 			return nil
 		}
 	}
@@ -448,8 +608,85 @@ func (m *EpisodicMemoryStore) Remove(ctx context.Context, id string) error {
 	return ErrNotFound
 }
 
+// AddBatch 批量添加记忆项（实现 Memory 接口）
+//
+// 所有记忆项校验通过后才会写入。
+func (m *EpisodicMemoryStore) AddBatch(ctx context.Context, items []*MemoryItem) ([]string, error) {
+	if err := validateItems(items); err != nil {
+		return nil, err
+	}
+	if err := m.ensureLoaded(ctx); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		id, err := m.appendLocked(ctx, itemToEpisode(item))
+		if err != nil {
+			m.tfidfStale = true
+			return ids, err
+		}
+		ids = append(ids, id)
+	}
+
+	m.tfidfStale = true
+	return ids, nil
+}
+
+// RemoveBatch 批量删除记忆（实现 Memory 接口）
+//
+// 删除所有存在的记录；有记录不存在时返回 ErrNotFound。
+func (m *EpisodicMemoryStore) RemoveBatch(ctx context.Context, ids []string) error {
+	if err := m.ensureLoaded(ctx); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	targets := idSet(ids)
+	var removed []string
+	kept := m.episodes[:0]
+	now := time.Now()
+	for _, ep := range m.episodes {
+		if targets[ep.ID] {
+			removed = append(removed, ep.ID)
+			if m.history != nil {
+				m.history.remove(ep, now)
+			}
+			continue
+		}
+		kept = append(kept, ep)
+	}
+	m.episodes = kept
+
+	if len(removed) > 0 {
+		m.rebuildSessions()
+		m.tfidfStale = true
+		if m.persist != nil {
+			if err := m.persist.delete(ctx, removed...); err != nil {
+				return err
+			}
+		}
+	}
+
+	return missingIDsError(ids, idSet(removed))
+}
+
+// RetrieveMulti 批量检索记忆（实现 Memory 接口）
+//
+// 结果顺序与 queries 一致。
+func (m *EpisodicMemoryStore) RetrieveMulti(ctx context.Context, queries []string, opts ...RetrieveOption) ([][]*MemoryItem, error) {
+	return retrieveEach(ctx, m, queries, opts...)
+}
+
 // Has 检查记忆是否存在（实现 Memory 接口）
 func (m *EpisodicMemoryStore) Has(ctx context.Context, id string) bool {
+	_ = m.ensureLoaded(ctx)
+
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
@@ -462,37 +699,16 @@ func (m *EpisodicMemoryStore) Has(ctx context.Context, id string) bool {
 }
 
 // GetStats 获取统计信息（实现 Memory 接口）
-func (m *EpisodicMemoryStore) GetStats(ctx context.Context) (*MemoryStats, error) {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
-
-	if len(m.episodes) == 0 {
-		return &MemoryStats{Count: 0}, nil
-	}
-
-	var totalImportance float32
-	var oldestTs, newestTs int64
-
-	for i, ep := range m.episodes {
-		totalImportance += ep.Importance
-		if i == 0 || ep.Timestamp < oldestTs {
-			oldestTs = ep.Timestamp
-		}
-		if i == 0 || ep.Timestamp > newestTs {
-			newestTs = ep.Timestamp
-		}
-	}
-
-	return &MemoryStats{
-		Count:           len(m.episodes),
-		OldestTimestamp: oldestTs,
-		NewestTimestamp: newestTs,
-		AvgImportance:   totalImportance / float32(len(m.episodes)),
-	}, nil
+func (m *EpisodicMemoryStore) GetStats(ctx context.Context, opts ...RetrieveOption) (*MemoryStats, error) {
+	return collectStats(ctx, m, opts)
 }
 
 // GetSessionEpisodes 获取指定会话的所有事件
 func (m *EpisodicMemoryStore) GetSessionEpisodes(ctx context.Context, sessionID string) ([]Episode, error) {
+	if err := m.ensureLoaded(ctx); err != nil {
+		return nil, err
+	}
+
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
@@ -523,6 +739,8 @@ func (m *EpisodicMemoryStore) GetSessionEpisodes(ctx context.Context, sessionID
 
 // GetSessions 获取所有会话 ID
 func (m *EpisodicMemoryStore) GetSessions(ctx context.Context) []string {
+	_ = m.ensureLoaded(ctx)
+
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
@@ -569,6 +787,10 @@ func WithMaxPatterns(max int) PatternOption {
 //
 // 基于关键词频率分析，找出重复出现的模式。
 func (m *EpisodicMemoryStore) FindPatterns(ctx context.Context, opts ...PatternOption) ([]Pattern, error) {
+	if err := m.ensureLoaded(ctx); err != nil {
+		return nil, err
+	}
+
 	options := &patternOptions{
 		minFrequency: 2,
 		maxPatterns:  10,
@@ -674,6 +896,10 @@ func WithTimelineLimit(limit int) TimelineOption {
 
 // GetTimeline 获取时间线视图
 func (m *EpisodicMemoryStore) GetTimeline(ctx context.Context, opts ...TimelineOption) ([]TimelineEntry, error) {
+	if err := m.ensureLoaded(ctx); err != nil {
+		return nil, err
+	}
+
 	options := &timelineOptions{
 		limit: 50,
 	}
@@ -745,8 +971,68 @@ func formatRelativeTime(t, now time.Time) string {
 	return t.Format("2006-01-02")
 }
 
+// DecayImportance 衰减所有事件的重要性（实现 ImportanceDecayer 接口）
+func (m *EpisodicMemoryStore) DecayImportance(ctx context.Context, factor float64, floor float32) (int, error) {
+	if err := m.ensureLoaded(ctx); err != nil {
+		return 0, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	decayed := 0
+	for i := range m.episodes {
+		importance := decayImportance(m.episodes[i].Importance, factor, floor)
+		if importance == m.episodes[i].Importance {
+			continue
+		}
+		m.episodes[i].Importance = importance
+		decayed++
+		if m.persist != nil {
+			if err := m.persist.put(ctx, episodeToDocument(m.episodes[i])); err != nil {
+				return decayed, err
+			}
+		}
+	}
+
+	return decayed, nil
+}
+
+// ReinforceImportance 强化指定事件的重要性（实现 ImportanceReinforcer 接口）
+//
+// 与 DecayImportance 一样不产生新版本，也不使 TF-IDF 索引失效。
+func (m *EpisodicMemoryStore) ReinforceImportance(ctx context.Context, ids []string, boost float32) (int, error) {
+	if err := m.ensureLoaded(ctx); err != nil {
+		return 0, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	set := idSet(ids)
+	reinforced := 0
+	for i := range m.episodes {
+		if !set[m.episodes[i].ID] {
+			continue
+		}
+		m.episodes[i].Importance = reinforceImportance(m.episodes[i].Importance, boost)
+		reinforced++
+		if m.persist != nil {
+			if err := m.persist.put(ctx, episodeToDocument(m.episodes[i])); err != nil {
+				return reinforced, err
+			}
+		}
+	}
+
+	return reinforced, nil
+}
+
 // Forget 执行遗忘（实现扩展）
 func (m *EpisodicMemoryStore) Forget(ctx context.Context, strategy ForgetStrategy, opts ...ForgetOption) (int, error) {
+	if err := m.ensureLoaded(ctx); err != nil {
+		return 0, err
+	}
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -803,6 +1089,44 @@ func (m *EpisodicMemoryStore) Forget(ctx context.Context, strategy ForgetStrateg
 		})
 	}
 
+	kept := make(map[string]bool, len(remaining))
+	for _, ep := range remaining {
+		kept[ep.ID] = true
+	}
+	var forgotten []Episode
+	for _, ep := range m.episodes {
+		if !kept[ep.ID] {
+			forgotten = append(forgotten, ep)
+		}
+	}
+
+	if options.archive != nil {
+		items := make([]*MemoryItem, len(forgotten))
+		for i, ep := range forgotten {
+			ep.Metadata = cloneMetadata(ep.Metadata)
+			items[i] = m.episodeToItem(ep, 0)
+		}
+		if err := options.archiveItems(ctx, strategy, items); err != nil {
+			return 0, err
+		}
+	}
+
+	if m.persist != nil {
+		ids := make([]string, len(forgotten))
+		for i, ep := range forgotten {
+			ids[i] = ep.ID
+		}
+		if err := m.persist.delete(ctx, ids...); err != nil {
+			return 0, err
+		}
+	}
+	if m.history != nil {
+		now := time.Now()
+		for _, ep := range forgotten {
+			m.history.remove(ep, now)
+		}
+	}
+
 	// 重建 sessions 索引
 	m.sessions = make(map[string][]string)
 	for _, ep := range remaining {
@@ -812,7 +1136,8 @@ func (m *EpisodicMemoryStore) Forget(ctx context.Context, strategy ForgetStrateg
 	}
 
 	m.episodes = remaining
-	m.rebuildTFIDF()
+	m.tfidfStale = true
+	m.forgotten.record(time.Now(), originalCount-len(m.episodes))
 
 	return originalCount - len(m.episodes), nil
 }
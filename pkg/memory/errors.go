@@ -12,4 +12,10 @@ var (
 	ErrMemoryFull = errors.New("memory is full")
 	// ErrInvalidInput 输入无效
 	ErrInvalidInput = errors.New("invalid input")
+	// ErrSnapshotUnsupported 记忆存储不支持快照导出/导入
+	ErrSnapshotUnsupported = errors.New("memory does not support snapshots")
+	// ErrSnapshotVersion 快照版本不受支持
+	ErrSnapshotVersion = errors.New("unsupported snapshot version")
+	// ErrArchiveNotConfigured 未设置归档存储
+	ErrArchiveNotConfigured = errors.New("archive store not configured")
 )
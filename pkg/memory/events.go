@@ -0,0 +1,170 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// defaultEventBuffer 订阅通道的默认缓冲大小
+const defaultEventBuffer = 64
+
+// MemoryEventType 记忆变更事件类型
+type MemoryEventType string
+
+const (
+	// EventMemoryAdded 记忆已添加（AddMemory）
+	EventMemoryAdded MemoryEventType = "added"
+	// EventMemoryUpdated 记忆已更新（UpdateMemory）
+	EventMemoryUpdated MemoryEventType = "updated"
+	// EventMemoryRemoved 记忆已删除（RemoveMemory）
+	EventMemoryRemoved MemoryEventType = "removed"
+	// EventMemoryForgotten 记忆已被遗忘（ForgetMemories，每个记忆类型一个事件）
+	EventMemoryForgotten MemoryEventType = "forgotten"
+	// EventMemoryConsolidated 工作记忆已整合（ConsolidateMemories）
+	EventMemoryConsolidated MemoryEventType = "consolidated"
+	// EventMemoryRestored 记忆已从归档恢复（RestoreMemories，每条记忆一个事件）
+	EventMemoryRestored MemoryEventType = "restored"
+)
+
+// MemoryEvent 记忆变更事件
+type MemoryEvent struct {
+	// Type 事件类型
+	Type MemoryEventType `json:"type"`
+	// MemoryType 发生变更的记忆类型（整合事件为目标记忆类型）
+	MemoryType MemoryType `json:"memory_type,omitempty"`
+	// ID 发生变更的记忆 ID（遗忘和整合事件为空）
+	ID string `json:"id,omitempty"`
+	// Content 新增记忆的内容（仅添加和恢复事件）
+	Content string `json:"content,omitempty"`
+	// Strategy 遗忘策略（仅遗忘事件）
+	Strategy ForgetStrategy `json:"strategy,omitempty"`
+	// Count 受影响的记忆数量
+	Count int `json:"count"`
+	// UserID 管理器的用户 ID
+	UserID string `json:"user_id,omitempty"`
+	// Timestamp 事件时间
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// SubscribeOption 订阅选项
+type SubscribeOption func(*subscribeOptions)
+
+type subscribeOptions struct {
+	buffer int
+	types  map[MemoryEventType]struct{}
+}
+
+// WithEventBuffer 设置订阅通道的缓冲大小（默认 64）
+func WithEventBuffer(size int) SubscribeOption {
+	return func(o *subscribeOptions) {
+		o.buffer = size
+	}
+}
+
+// WithEventTypes 只订阅指定类型的事件
+func WithEventTypes(types ...MemoryEventType) SubscribeOption {
+	return func(o *subscribeOptions) {
+		if o.types == nil {
+			o.types = make(map[MemoryEventType]struct{}, len(types))
+		}
+		for _, t := range types {
+			o.types[t] = struct{}{}
+		}
+	}
+}
+
+// subscriber 单个订阅者
+type subscriber struct {
+	ch      chan MemoryEvent
+	types   map[MemoryEventType]struct{}
+	dropped atomic.Int64
+}
+
+// eventBus 记忆事件分发，零值可用
+type eventBus struct {
+	mu      sync.RWMutex
+	subs    map[*subscriber]struct{}
+	dropped int64 // 已取消订阅者丢弃的事件数
+}
+
+// Subscribe 订阅记忆变更事件
+//
+// 返回的通道在 ctx 取消后关闭。事件以非阻塞方式投递，订阅者处理不及、
+// 通道缓冲已满时新事件被丢弃（可通过 DroppedEvents 查看），记忆操作不会因订阅者而阻塞。
+func (m *MemoryManager) Subscribe(ctx context.Context, opts ...SubscribeOption) <-chan MemoryEvent {
+	options := &subscribeOptions{buffer: defaultEventBuffer}
+	for _, opt := range opts {
+		opt(options)
+	}
+	if options.buffer < 0 {
+		options.buffer = 0
+	}
+
+	sub := &subscriber{
+		ch:    make(chan MemoryEvent, options.buffer),
+		types: options.types,
+	}
+
+	m.events.mu.Lock()
+	if m.events.subs == nil {
+		m.events.subs = make(map[*subscriber]struct{})
+	}
+	m.events.subs[sub] = struct{}{}
+	m.events.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		m.events.mu.Lock()
+		delete(m.events.subs, sub)
+		m.events.dropped += sub.dropped.Load()
+		close(sub.ch)
+		m.events.mu.Unlock()
+	}()
+
+	return sub.ch
+}
+
+// DroppedEvents 返回因订阅通道已满而丢弃的事件总数
+func (m *MemoryManager) DroppedEvents() int64 {
+	m.events.mu.RLock()
+	defer m.events.mu.RUnlock()
+
+	total := m.events.dropped
+	for sub := range m.events.subs {
+		total += sub.dropped.Load()
+	}
+	return total
+}
+
+// publish 向所有订阅者投递事件
+func (m *MemoryManager) publish(event MemoryEvent) {
+	m.events.mu.RLock()
+	defer m.events.mu.RUnlock()
+	if len(m.events.subs) == 0 {
+		return
+	}
+
+	event.UserID = m.userID
+	event.Timestamp = time.Now()
+	for sub := range m.events.subs {
+		if sub.types != nil {
+			if _, ok := sub.types[event.Type]; !ok {
+				continue
+			}
+		}
+		select {
+		case sub.ch <- event:
+		default:
+			sub.dropped.Add(1)
+		}
+	}
+}
+
+// publishConsolidated 有记忆被整合时投递整合事件
+func (m *MemoryManager) publishConsolidated(targetType MemoryType, consolidated int) {
+	if consolidated > 0 {
+		m.publish(MemoryEvent{Type: EventMemoryConsolidated, MemoryType: targetType, Count: consolidated})
+	}
+}
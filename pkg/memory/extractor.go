@@ -0,0 +1,257 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ahhsitt/helloagents-go/pkg/core/llm"
+	"github.com/ahhsitt/helloagents-go/pkg/core/message"
+)
+
+// ExtractionResult 实体和关系提取结果
+type ExtractionResult struct {
+	// Entities 提取的实体
+	Entities []ExtractedEntity `json:"entities"`
+	// Relations 提取的关系，两端以实体名称表示
+	Relations []ExtractedRelation `json:"relations"`
+}
+
+// EntityExtractor 实体和关系提取器
+type EntityExtractor interface {
+	// Extract 从文本中提取实体和关系
+	Extract(ctx context.Context, content string) (*ExtractionResult, error)
+}
+
+// RuleEntityExtractor 基于规则的提取器
+//
+// 使用正则表达式识别实体，按句内共现推断关系，不需要调用 LLM。
+type RuleEntityExtractor struct{}
+
+// NewRuleEntityExtractor 创建基于规则的提取器
+func NewRuleEntityExtractor() *RuleEntityExtractor {
+	return &RuleEntityExtractor{}
+}
+
+// Extract 提取实体和关系
+func (e *RuleEntityExtractor) Extract(ctx context.Context, content string) (*ExtractionResult, error) {
+	entities := extractEntities(content)
+	return &ExtractionResult{
+		Entities:  entities,
+		Relations: extractRelations(content, entities),
+	}, nil
+}
+
+// DefaultExtractionPrompt 默认实体提取提示模板
+const DefaultExtractionPrompt = `请从以下内容中提取实体和实体之间的关系。
+
+实体类型只能是：person、organization、location、concept、event、product、other。
+关系类型只能是：related_to、part_of、has_a、is_a、located_in、works_at、knows、created_by、depends_on、similar_to。
+confidence 为 0 到 1 之间的置信度；关系的 from 和 to 必须是 entities 中的实体名称。
+
+内容：
+%s
+
+请只输出符合以下 JSON Schema 的 JSON，不要输出其他内容：
+{"type":"object","properties":{"entities":{"type":"array","items":{"type":"object","properties":{"name":{"type":"string"},"type":{"type":"string"},"confidence":{"type":"number"}},"required":["name","type"]}},"relations":{"type":"array","items":{"type":"object","properties":{"from":{"type":"string"},"to":{"type":"string"},"type":{"type":"string"},"confidence":{"type":"number"},"context":{"type":"string"}},"required":["from","to","type"]}}},"required":["entities","relations"]}`
+
+// LLMEntityExtractor 基于 LLM 的实体和关系提取器
+//
+// 要求 LLM 按 JSON Schema 输出结构化结果。如果调用失败或无法解析输出，
+// 且设置了回退提取器，则使用回退提取器的结果。
+type LLMEntityExtractor struct {
+	provider      llm.Provider
+	prompt        string
+	minConfidence float32
+	fallback      EntityExtractor
+}
+
+// LLMExtractorOption LLM 提取器选项
+type LLMExtractorOption func(*LLMEntityExtractor)
+
+// WithExtractionPrompt 设置自定义提示模板（需包含一个 %s 占位符）
+func WithExtractionPrompt(prompt string) LLMExtractorOption {
+	return func(e *LLMEntityExtractor) {
+		e.prompt = prompt
+	}
+}
+
+// WithMinExtractionConfidence 设置最低置信度，低于该值的实体和关系被丢弃
+func WithMinExtractionConfidence(confidence float32) LLMExtractorOption {
+	return func(e *LLMEntityExtractor) {
+		e.minConfidence = confidence
+	}
+}
+
+// WithExtractionFallback 设置回退提取器
+func WithExtractionFallback(fallback EntityExtractor) LLMExtractorOption {
+	return func(e *LLMEntityExtractor) {
+		e.fallback = fallback
+	}
+}
+
+// NewLLMEntityExtractor 创建 LLM 实体提取器
+func NewLLMEntityExtractor(provider llm.Provider, opts ...LLMExtractorOption) *LLMEntityExtractor {
+	e := &LLMEntityExtractor{
+		provider: provider,
+		prompt:   DefaultExtractionPrompt,
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// Extract 提取实体和关系
+func (e *LLMEntityExtractor) Extract(ctx context.Context, content string) (*ExtractionResult, error) {
+	result, err := e.generate(ctx, content)
+	if err != nil {
+		if e.fallback != nil {
+			return e.fallback.Extract(ctx, content)
+		}
+		return nil, err
+	}
+	return result, nil
+}
+
+// llmExtraction LLM 输出的 JSON 结构
+type llmExtraction struct {
+	Entities []struct {
+		Name       string  `json:"name"`
+		Type       string  `json:"type"`
+		Confidence float32 `json:"confidence"`
+	} `json:"entities"`
+	Relations []struct {
+		From       string  `json:"from"`
+		To         string  `json:"to"`
+		Type       string  `json:"type"`
+		Confidence float32 `json:"confidence"`
+		Context    string  `json:"context"`
+	} `json:"relations"`
+}
+
+// generate 调用 LLM 并解析提取结果
+func (e *LLMEntityExtractor) generate(ctx context.Context, content string) (*ExtractionResult, error) {
+	if e.provider == nil {
+		return nil, fmt.Errorf("entity extractor: llm provider not set")
+	}
+
+	resp, err := e.provider.Generate(ctx, llm.Request{
+		Messages: []message.Message{
+			message.NewUserMessage(fmt.Sprintf(e.prompt, content)),
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("entity extractor: %w", err)
+	}
+
+	raw := jsonObject(resp.Content)
+	if raw == "" {
+		return nil, fmt.Errorf("entity extractor: no json in response %q", resp.Content)
+	}
+	var parsed llmExtraction
+	if err := json.Unmarshal([]byte(raw), &parsed); err != nil {
+		return nil, fmt.Errorf("entity extractor: %w", err)
+	}
+
+	result := &ExtractionResult{
+		Entities:  make([]ExtractedEntity, 0, len(parsed.Entities)),
+		Relations: make([]ExtractedRelation, 0, len(parsed.Relations)),
+	}
+	kept := make(map[string]struct{}, len(parsed.Entities))
+	for _, pe := range parsed.Entities {
+		name := strings.TrimSpace(pe.Name)
+		confidence := normalizeConfidence(pe.Confidence)
+		if name == "" || confidence < e.minConfidence {
+			continue
+		}
+		if _, ok := kept[strings.ToLower(name)]; ok {
+			continue
+		}
+		kept[strings.ToLower(name)] = struct{}{}
+
+		entity := ExtractedEntity{
+			Name:       name,
+			Type:       parseEntityType(pe.Type),
+			StartPos:   -1,
+			EndPos:     -1,
+			Confidence: confidence,
+		}
+		if pos := strings.Index(content, name); pos >= 0 {
+			entity.StartPos = pos
+			entity.EndPos = pos + len(name)
+		}
+		result.Entities = append(result.Entities, entity)
+	}
+
+	for _, pr := range parsed.Relations {
+		from, to := strings.TrimSpace(pr.From), strings.TrimSpace(pr.To)
+		confidence := normalizeConfidence(pr.Confidence)
+		if confidence < e.minConfidence {
+			continue
+		}
+		_, fromOK := kept[strings.ToLower(from)]
+		_, toOK := kept[strings.ToLower(to)]
+		if !fromOK || !toOK || strings.EqualFold(from, to) {
+			continue
+		}
+		result.Relations = append(result.Relations, ExtractedRelation{
+			FromEntity:   from,
+			ToEntity:     to,
+			RelationType: parseRelationType(pr.Type),
+			Confidence:   confidence,
+			Context:      pr.Context,
+		})
+	}
+
+	return result, nil
+}
+
+// jsonObject 截取输出中第一个 { 到最后一个 } 之间的内容（兼容 Markdown 代码块）
+func jsonObject(s string) string {
+	start := strings.Index(s, "{")
+	end := strings.LastIndex(s, "}")
+	if start < 0 || end <= start {
+		return ""
+	}
+	return s[start : end+1]
+}
+
+// normalizeConfidence 规范化置信度，未给出时视为 0.5
+func normalizeConfidence(c float32) float32 {
+	if c <= 0 {
+		return 0.5
+	}
+	if c > 1 {
+		return 1
+	}
+	return c
+}
+
+// parseEntityType 解析实体类型，未知类型归为 other
+func parseEntityType(s string) EntityType {
+	t := EntityType(strings.ToLower(strings.TrimSpace(s)))
+	switch t {
+	case EntityTypePerson, EntityTypeOrganization, EntityTypeLocation,
+		EntityTypeConcept, EntityTypeEvent, EntityTypeProduct, EntityTypeOther:
+		return t
+	}
+	return EntityTypeOther
+}
+
+// parseRelationType 解析关系类型，未知类型归为 related_to
+func parseRelationType(s string) RelationType {
+	t := RelationType(strings.ToLower(strings.TrimSpace(s)))
+	switch t {
+	case RelationTypeRelatedTo, RelationTypePartOf, RelationTypeHasA, RelationTypeIsA,
+		RelationTypeLocatedIn, RelationTypeWorksAt, RelationTypeKnows, RelationTypeCreatedBy,
+		RelationTypeDependsOn, RelationTypeSimilarTo:
+		return t
+	}
+	return RelationTypeRelatedTo
+}
+
+// compile-time interface check
+var _ EntityExtractor = (*RuleEntityExtractor)(nil)
+var _ EntityExtractor = (*LLMEntityExtractor)(nil)
@@ -0,0 +1,229 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"strings"
+)
+
+// GraphExpansionResult 图扩展检索结果
+//
+// Hop 为 0 表示直接由向量检索命中；Hop >= 1 表示经由种子记忆中的实体在关系图上
+// 走 Hop 跳到达邻居实体，再由邻居实体关联到该记忆。
+type GraphExpansionResult struct {
+	SearchResult
+	// Hop 贡献该结果的跳数（0 为直接命中）
+	Hop int `json:"hop"`
+	// SeedID 触发扩展的直接命中记忆 ID（直接命中时为自身 ID）
+	SeedID string `json:"seed_id"`
+	// Entity 关联到该记忆的邻居实体（直接命中时为 nil）
+	Entity *Entity `json:"entity,omitempty"`
+	// Path 从种子实体到邻居实体经过的关系
+	Path []*Relation `json:"path,omitempty"`
+}
+
+// GraphExpansionOption 图扩展检索选项
+type GraphExpansionOption func(*graphExpansionOptions)
+
+type graphExpansionOptions struct {
+	hops  int
+	decay float32
+	limit int
+}
+
+// WithExpansionHops 设置关系图遍历的最大跳数（默认 1）
+func WithExpansionHops(hops int) GraphExpansionOption {
+	return func(o *graphExpansionOptions) {
+		o.hops = hops
+	}
+}
+
+// WithExpansionDecay 设置每跳的分数衰减系数（默认 0.5）
+//
+// 扩展结果的分数为：种子分数 × decay^hop × 路径关系强度。
+func WithExpansionDecay(decay float32) GraphExpansionOption {
+	return func(o *graphExpansionOptions) {
+		o.decay = decay
+	}
+}
+
+// WithExpansionLimit 设置返回结果总数上限（默认不限制）
+func WithExpansionLimit(limit int) GraphExpansionOption {
+	return func(o *graphExpansionOptions) {
+		o.limit = limit
+	}
+}
+
+// SearchWithGraphExpansion GraphRAG 风格的检索：向量检索后沿实体关系图扩展
+//
+// 先取 topK 条直接命中的记忆作为种子，找出种子中的实体（内容中出现的已知实体名称或别名，
+// 以及以种子为证据的关系两端的实体），在关系图上遍历 1~hops 跳，再把与邻居实体关联的记忆
+// （以该实体相关关系为证据、或内容中提及该实体）加入结果。
+// 同一记忆只保留分数最高的来源，结果按分数降序排列。
+func (m *SemanticMemoryStore) SearchWithGraphExpansion(ctx context.Context, query string, topK int, opts ...GraphExpansionOption) ([]GraphExpansionResult, error) {
+	options := &graphExpansionOptions{
+		hops:  1,
+		decay: 0.5,
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+	if options.hops <= 0 {
+		options.hops = 1
+	}
+
+	seeds, err := m.Search(ctx, query, topK)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make(map[string]*GraphExpansionResult, len(seeds))
+	for _, seed := range seeds {
+		results[seed.ID] = &GraphExpansionResult{SearchResult: seed, SeedID: seed.ID}
+	}
+
+	for _, seed := range seeds {
+		for _, entityID := range m.seedEntities(ctx, seed) {
+			related, err := m.GetRelatedEntities(ctx, entityID, options.hops)
+			if err != nil {
+				continue
+			}
+			for _, neighbor := range related {
+				score := seed.Score * pow32(options.decay, neighbor.Depth) * pathStrength(neighbor.Path)
+				for _, linked := range m.entityMemories(neighbor.Entity) {
+					if existing, ok := results[linked.ID]; ok && existing.Score >= score {
+						continue
+					}
+					linked.Score = score
+					results[linked.ID] = &GraphExpansionResult{
+						SearchResult: linked,
+						Hop:          neighbor.Depth,
+						SeedID:       seed.ID,
+						Entity:       neighbor.Entity,
+						Path:         neighbor.Path,
+					}
+				}
+			}
+		}
+	}
+
+	combined := make([]GraphExpansionResult, 0, len(results))
+	for _, r := range results {
+		combined = append(combined, *r)
+	}
+	sort.Slice(combined, func(i, j int) bool {
+		if combined[i].Score != combined[j].Score {
+			return combined[i].Score > combined[j].Score
+		}
+		if combined[i].Hop != combined[j].Hop {
+			return combined[i].Hop < combined[j].Hop
+		}
+		return combined[i].ID < combined[j].ID
+	})
+	if options.limit > 0 && len(combined) > options.limit {
+		combined = combined[:options.limit]
+	}
+	return combined, nil
+}
+
+// seedEntities 返回种子记忆中的实体 ID
+func (m *SemanticMemoryStore) seedEntities(ctx context.Context, seed SearchResult) []string {
+	ids := make([]string, 0)
+	seen := make(map[string]struct{})
+	add := func(id string) {
+		if _, ok := seen[id]; !ok && id != "" {
+			seen[id] = struct{}{}
+			ids = append(ids, id)
+		}
+	}
+
+	m.graphMu.RLock()
+	for _, rel := range m.relations {
+		if containsString(rel.Evidence, seed.ID) {
+			add(rel.FromEntityID)
+			add(rel.ToEntityID)
+		}
+	}
+	// 本地实体按名称和别名匹配内容
+	content := strings.ToLower(seed.Content)
+	for name, id := range m.entityIndex {
+		if strings.Contains(content, name) {
+			add(id)
+		}
+	}
+	m.graphMu.RUnlock()
+
+	// 图数据库模式下按规则提取的名称查找实体
+	if m.graph != nil {
+		for _, extracted := range extractEntities(seed.Content) {
+			if entity, err := m.graphEntityByName(ctx, extracted.Name); err == nil {
+				add(entity.ID)
+			}
+		}
+	}
+	return ids
+}
+
+// entityMemories 返回与实体关联的本地记忆：以实体相关关系为证据，或内容中提及实体名称
+func (m *SemanticMemoryStore) entityMemories(entity *Entity) []SearchResult {
+	evidence := make(map[string]struct{})
+	m.graphMu.RLock()
+	for _, rel := range m.relations {
+		if rel.FromEntityID == entity.ID || rel.ToEntityID == entity.ID {
+			for _, id := range rel.Evidence {
+				evidence[id] = struct{}{}
+			}
+		}
+	}
+	m.graphMu.RUnlock()
+
+	names := append([]string{entity.Name}, entityAliases(entity)...)
+	for i, name := range names {
+		names[i] = strings.ToLower(name)
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	linked := make([]SearchResult, 0)
+	for _, rec := range m.records {
+		_, ok := evidence[rec.ID]
+		if !ok && !mentionsAny(strings.ToLower(rec.Content), names) {
+			continue
+		}
+		linked = append(linked, SearchResult{
+			ID:       rec.ID,
+			Content:  rec.Content,
+			Metadata: cloneMetadata(rec.Metadata),
+		})
+	}
+	return linked
+}
+
+// pathStrength 返回路径上关系强度的乘积，无路径信息时为 1
+func pathStrength(path []*Relation) float32 {
+	strength := float32(1)
+	for _, rel := range path {
+		if rel != nil && rel.Strength > 0 {
+			strength *= rel.Strength
+		}
+	}
+	return strength
+}
+
+func pow32(base float32, exp int) float32 {
+	result := float32(1)
+	for i := 0; i < exp; i++ {
+		result *= base
+	}
+	return result
+}
+
+func mentionsAny(content string, names []string) bool {
+	for _, name := range names {
+		if name != "" && strings.Contains(content, name) {
+			return true
+		}
+	}
+	return false
+}
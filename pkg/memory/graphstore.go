@@ -0,0 +1,279 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/ahhsitt/helloagents-go/pkg/memory/store"
+)
+
+// defaultGraphSearchLimit 图数据库实体搜索的默认条数上限
+//
+// 本地实现中 limit <= 0 表示不限制，而 Cypher 的 LIMIT 0 不返回任何结果，
+// 因此委托给图数据库时需要一个正的上限。
+const defaultGraphSearchLimit = 100
+
+// SemanticMemoryOption 语义记忆配置选项
+type SemanticMemoryOption func(*SemanticMemoryStore)
+
+// WithSemanticGraphStore 将实体和关系保存到图数据库（如 store.Neo4jGraphStore）
+//
+// 设置后 AddEntity、AddRelation、GetRelatedEntities 等实体接口直接读写 graph，
+// 知识图谱随图数据库持久化，多跳遍历由图数据库在服务端执行（Neo4j 下为 Cypher 路径查询），
+// 不再在本地做 BFS。本地只镜像本实例写入的关系，用于 GetRelation 和补全遍历路径。
+func WithSemanticGraphStore(graph store.GraphStore) SemanticMemoryOption {
+	return func(m *SemanticMemoryStore) {
+		m.graph = graph
+	}
+}
+
+// graphError 将图数据库错误转换为记忆错误
+func graphError(op string, err error) error {
+	if errors.Is(err, store.ErrNotFound) {
+		return ErrNotFound
+	}
+	if errors.Is(err, store.ErrInvalidInput) {
+		return ErrInvalidInput
+	}
+	return fmt.Errorf("%s: %w", op, err)
+}
+
+// graphAddEntity 写入图数据库，同名实体（不区分大小写）只增加频率
+func (m *SemanticMemoryStore) graphAddEntity(ctx context.Context, entity *Entity) error {
+	existing, err := m.graphEntityByName(ctx, entity.Name)
+	switch {
+	case err == nil:
+		node := entityToGraph(existing)
+		node.Frequency++
+		if err := m.graph.AddEntity(ctx, node); err != nil {
+			return graphError("update graph entity", err)
+		}
+		return nil
+	case !errors.Is(err, ErrNotFound):
+		return err
+	}
+
+	if entity.ID == "" {
+		entity.ID = uuid.New().String()
+	}
+	m.embedEntity(ctx, entity)
+
+	if err := m.graph.AddEntity(ctx, entityToGraph(entity)); err != nil {
+		return graphError("add graph entity", err)
+	}
+	return nil
+}
+
+// graphGetEntity 从图数据库获取实体
+func (m *SemanticMemoryStore) graphGetEntity(ctx context.Context, id string) (*Entity, error) {
+	node, err := m.graph.GetEntity(ctx, id)
+	if err != nil {
+		return nil, graphError("get graph entity", err)
+	}
+	return graphToEntity(node), nil
+}
+
+// graphEntityByName 在图数据库中按名称精确查找实体（不区分大小写）
+func (m *SemanticMemoryStore) graphEntityByName(ctx context.Context, name string) (*Entity, error) {
+	nodes, err := m.graph.SearchEntities(ctx, name, "", defaultGraphSearchLimit)
+	if err != nil {
+		return nil, graphError("search graph entities", err)
+	}
+	for _, node := range nodes {
+		if strings.EqualFold(node.Name, name) {
+			return graphToEntity(node), nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// graphSearchEntities 在图数据库中按名称模式搜索实体
+func (m *SemanticMemoryStore) graphSearchEntities(ctx context.Context, pattern string, limit int) ([]*Entity, error) {
+	if limit <= 0 {
+		limit = defaultGraphSearchLimit
+	}
+	nodes, err := m.graph.SearchEntities(ctx, pattern, "", limit)
+	if err != nil {
+		return nil, graphError("search graph entities", err)
+	}
+
+	results := make([]*Entity, 0, len(nodes))
+	for _, node := range nodes {
+		results = append(results, graphToEntity(node))
+	}
+	return results, nil
+}
+
+// graphDeleteEntity 从图数据库删除实体及其关系
+func (m *SemanticMemoryStore) graphDeleteEntity(ctx context.Context, id string) error {
+	if err := m.graph.DeleteEntity(ctx, id); err != nil {
+		return graphError("delete graph entity", err)
+	}
+
+	m.graphMu.Lock()
+	defer m.graphMu.Unlock()
+	for relID, rel := range m.relations {
+		if rel.FromEntityID == id || rel.ToEntityID == id {
+			delete(m.relations, relID)
+		}
+	}
+	return nil
+}
+
+// graphAddRelation 写入图数据库，实体是否存在由图数据库校验
+func (m *SemanticMemoryStore) graphAddRelation(ctx context.Context, relation *Relation) error {
+	if relation.ID == "" {
+		relation.ID = uuid.New().String()
+	}
+	if err := m.graph.AddRelation(ctx, relationToGraph(relation)); err != nil {
+		return graphError("add graph relation", err)
+	}
+
+	m.graphMu.Lock()
+	defer m.graphMu.Unlock()
+	m.relations[relation.ID] = relation
+	return nil
+}
+
+// graphDeleteRelation 从图数据库删除关系
+func (m *SemanticMemoryStore) graphDeleteRelation(ctx context.Context, id string) error {
+	if err := m.graph.DeleteRelation(ctx, id); err != nil {
+		return graphError("delete graph relation", err)
+	}
+
+	m.graphMu.Lock()
+	defer m.graphMu.Unlock()
+	delete(m.relations, id)
+	return nil
+}
+
+// graphRelatedEntities 由图数据库执行多跳遍历
+//
+// 图数据库返回的关系可能只有 ID、类型和强度（如 Neo4j 只返回路径上的最后一条关系），
+// 本地镜像中有完整关系时优先使用镜像。
+func (m *SemanticMemoryStore) graphRelatedEntities(ctx context.Context, entityID string, maxDepth int) ([]GraphSearchResult, error) {
+	if maxDepth <= 0 {
+		maxDepth = 2
+	}
+
+	hits, err := m.graph.FindRelatedEntities(ctx, entityID, "", maxDepth)
+	if err != nil {
+		return nil, graphError("traverse graph", err)
+	}
+
+	m.graphMu.RLock()
+	defer m.graphMu.RUnlock()
+
+	results := make([]GraphSearchResult, 0, len(hits))
+	for _, hit := range hits {
+		if hit == nil || hit.Entity == nil {
+			continue
+		}
+
+		var path []*Relation
+		if len(hit.Path) > 0 {
+			path = make([]*Relation, 0, len(hit.Path))
+			for _, rel := range hit.Path {
+				path = append(path, m.mirroredRelation(rel))
+			}
+		} else if hit.Relation != nil {
+			path = []*Relation{m.mirroredRelation(hit.Relation)}
+		}
+
+		results = append(results, GraphSearchResult{
+			Entity: graphToEntity(hit.Entity),
+			Depth:  hit.Depth,
+			Path:   path,
+			Score:  hit.Score,
+		})
+	}
+
+	// 按得分排序
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+
+	return results, nil
+}
+
+// mirroredRelation 优先返回本地镜像中的完整关系（调用方需持有读锁）
+func (m *SemanticMemoryStore) mirroredRelation(rel *store.GraphRelation) *Relation {
+	if local, ok := m.relations[rel.ID]; ok {
+		return local
+	}
+	return graphToRelation(rel)
+}
+
+// graphStats 获取图数据库统计信息，失败时返回零值
+func (m *SemanticMemoryStore) graphStats() store.GraphStoreStats {
+	stats, err := m.graph.GetStats(context.Background())
+	if err != nil || stats == nil {
+		return store.GraphStoreStats{}
+	}
+	return *stats
+}
+
+// entityToGraph 将实体转换为图节点
+func entityToGraph(e *Entity) *store.GraphEntity {
+	return &store.GraphEntity{
+		ID:          e.ID,
+		Name:        e.Name,
+		Type:        string(e.Type),
+		Description: e.Description,
+		Properties:  e.Properties,
+		Frequency:   e.Frequency,
+		Vector:      e.Vector,
+		CreatedAt:   e.CreatedAt,
+		UpdatedAt:   e.UpdatedAt,
+	}
+}
+
+// graphToEntity 将图节点转换为实体
+func graphToEntity(g *store.GraphEntity) *Entity {
+	return &Entity{
+		ID:          g.ID,
+		Name:        g.Name,
+		Type:        EntityType(g.Type),
+		Description: g.Description,
+		Properties:  g.Properties,
+		Frequency:   g.Frequency,
+		Vector:      g.Vector,
+		CreatedAt:   g.CreatedAt,
+		UpdatedAt:   g.UpdatedAt,
+	}
+}
+
+// relationToGraph 将关系转换为图关系
+func relationToGraph(r *Relation) *store.GraphRelation {
+	return &store.GraphRelation{
+		ID:           r.ID,
+		FromEntityID: r.FromEntityID,
+		ToEntityID:   r.ToEntityID,
+		Type:         string(r.RelationType),
+		Strength:     r.Strength,
+		Properties:   r.Properties,
+		Evidence:     r.Evidence,
+		CreatedAt:    r.CreatedAt,
+		UpdatedAt:    r.UpdatedAt,
+	}
+}
+
+// graphToRelation 将图关系转换为关系
+func graphToRelation(g *store.GraphRelation) *Relation {
+	return &Relation{
+		ID:           g.ID,
+		FromEntityID: g.FromEntityID,
+		ToEntityID:   g.ToEntityID,
+		RelationType: RelationType(g.Type),
+		Strength:     g.Strength,
+		Evidence:     g.Evidence,
+		Properties:   g.Properties,
+		CreatedAt:    g.CreatedAt,
+		UpdatedAt:    g.UpdatedAt,
+	}
+}
@@ -0,0 +1,129 @@
+package memory
+
+import (
+	"sort"
+	"time"
+)
+
+// HybridFusion 混合检索的融合方式
+type HybridFusion string
+
+const (
+	// HybridFusionWeighted 加权融合：alpha × 向量分数 + (1 - alpha) × BM25 分数
+	HybridFusionWeighted HybridFusion = "weighted"
+	// HybridFusionRRF 倒数排名融合：score = Σ 1 / (k + rank)
+	HybridFusionRRF HybridFusion = "rrf"
+)
+
+// hybridCandidateMultiplier 融合前每路检索的候选倍数
+const hybridCandidateMultiplier = 2
+
+// hybridSearch 混合检索配置
+type hybridSearch struct {
+	fusion HybridFusion
+	alpha  float32
+	rrfK   int
+}
+
+// WithSemanticHybridSearch 启用 BM25 + 向量的加权混合检索
+//
+// alpha 为向量检索的权重 (0-1)，BM25 的权重为 1 - alpha。
+// BM25 分数按本次查询的最高分归一化后与向量分数一样经过时间衰减和重要性加权，
+// 两路分数在同一尺度上加权求和，精确关键词命中和语义近邻都能排在前面。
+func WithSemanticHybridSearch(alpha float32) SemanticMemoryOption {
+	return func(m *SemanticMemoryStore) {
+		m.hybrid = &hybridSearch{fusion: HybridFusionWeighted, alpha: alpha}
+		m.bm25 = NewBM25Index(DefaultBM25K1, DefaultBM25B)
+	}
+}
+
+// WithSemanticHybridRRF 启用 BM25 + 向量的倒数排名融合混合检索，k <= 0 时使用 60
+func WithSemanticHybridRRF(k int) SemanticMemoryOption {
+	return func(m *SemanticMemoryStore) {
+		if k <= 0 {
+			k = 60
+		}
+		m.hybrid = &hybridSearch{fusion: HybridFusionRRF, rrfK: k}
+		m.bm25 = NewBM25Index(DefaultBM25K1, DefaultBM25B)
+	}
+}
+
+// bm25Search BM25 关键词检索（调用方需持有读锁且索引已重建）
+//
+// BM25 分数按最高分归一化到 0-1 后参与综合评分。
+func (m *SemanticMemoryStore) bm25Search(records []semanticRecord, query string, topK int) []SearchResult {
+	hits := m.bm25.Search(query, 0)
+	if len(hits) == 0 {
+		return nil
+	}
+
+	maxScore := hits[0].Score
+	similarity := make(map[string]float32, len(hits))
+	for _, hit := range hits {
+		if hit.Index < len(m.records) && maxScore > 0 {
+			similarity[m.records[hit.Index].ID] = hit.Score / maxScore
+		}
+	}
+
+	now := time.Now()
+	results := make([]SearchResult, 0, len(hits))
+	for _, rec := range records {
+		sim, ok := similarity[rec.ID]
+		if !ok {
+			continue
+		}
+		ageDays := float32(now.Sub(rec.Timestamp).Hours() / 24)
+		results = append(results, SearchResult{
+			ID:       rec.ID,
+			Content:  rec.Content,
+			Score:    m.calculateScore(sim, ageDays, rec.Importance),
+			Metadata: rec.Metadata,
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	if topK < len(results) {
+		results = results[:topK]
+	}
+	return results
+}
+
+// fuse 融合向量检索和 BM25 检索的结果，返回前 topK 条
+func (h *hybridSearch) fuse(vector, keyword []SearchResult, topK int) []SearchResult {
+	scores := make(map[string]float32, len(vector)+len(keyword))
+	byID := make(map[string]SearchResult, len(vector)+len(keyword))
+
+	add := func(results []SearchResult, weight float32) {
+		for rank, r := range results {
+			switch h.fusion {
+			case HybridFusionRRF:
+				scores[r.ID] += 1 / float32(h.rrfK+rank+1)
+			default:
+				scores[r.ID] += weight * r.Score
+			}
+			if _, ok := byID[r.ID]; !ok {
+				byID[r.ID] = r
+			}
+		}
+	}
+	add(vector, h.alpha)
+	add(keyword, 1-h.alpha)
+
+	fused := make([]SearchResult, 0, len(byID))
+	for id, r := range byID {
+		r.Score = scores[id]
+		fused = append(fused, r)
+	}
+	sort.Slice(fused, func(i, j int) bool {
+		if fused[i].Score != fused[j].Score {
+			return fused[i].Score > fused[j].Score
+		}
+		return fused[i].ID < fused[j].ID
+	})
+	if topK < len(fused) {
+		fused = fused[:topK]
+	}
+	return fused
+}
@@ -0,0 +1,350 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ahhsitt/helloagents-go/pkg/core/llm"
+	"github.com/ahhsitt/helloagents-go/pkg/core/message"
+)
+
+// ImportanceScorer 重要性评分接口
+//
+// 在记忆写入时估计内容的重要性 (0-1)，
+// 使基于重要性的遗忘策略有意义。
+type ImportanceScorer interface {
+	// Score 计算内容的重要性评分
+	Score(ctx context.Context, content string, metadata map[string]interface{}) (float32, error)
+}
+
+// ImportanceRule 重要性启发式规则
+//
+// 当 Match 返回 true 时，将 Weight 累加到基础分上。
+// Weight 可以为负数，用于降低重要性。
+type ImportanceRule struct {
+	// Name 规则名称
+	Name string
+	// Weight 命中时的分数调整
+	Weight float32
+	// Match 匹配函数
+	Match func(content string, metadata map[string]interface{}) bool
+}
+
+// KeywordRule 创建基于关键词的规则
+//
+// 内容（忽略大小写）包含任一关键词即命中。
+func KeywordRule(name string, weight float32, keywords ...string) ImportanceRule {
+	lowered := make([]string, len(keywords))
+	for i, kw := range keywords {
+		lowered[i] = strings.ToLower(kw)
+	}
+	return ImportanceRule{
+		Name:   name,
+		Weight: weight,
+		Match: func(content string, _ map[string]interface{}) bool {
+			contentLower := strings.ToLower(content)
+			for _, kw := range lowered {
+				if strings.Contains(contentLower, kw) {
+					return true
+				}
+			}
+			return false
+		},
+	}
+}
+
+// 默认规则使用的信号
+var (
+	// explicitMarkerKeywords 显式记忆标记
+	explicitMarkerKeywords = []string{
+		"remember that", "remember:", "don't forget", "do not forget", "note that", "keep in mind",
+		"记住", "别忘了", "不要忘记", "请记得",
+	}
+	// urgencyKeywords 重要/紧急信号
+	urgencyKeywords = []string{
+		"important", "critical", "urgent", "essential", "must", "key",
+		"重要", "关键", "紧急", "必须", "一定",
+	}
+	// preferenceKeywords 用户偏好信号
+	preferenceKeywords = []string{
+		"i prefer", "i like", "i love", "i hate", "i dislike", "i don't like", "my favorite", "my favourite",
+		"我喜欢", "我偏好", "我讨厌", "我不喜欢", "我最爱", "我习惯",
+	}
+	// identityKeywords 用户身份信号
+	identityKeywords = []string{
+		"my name is", "i am a", "i'm a", "i work at", "i work as", "i live in", "my job", "my email",
+		"我叫", "我的名字", "我是一名", "我住在", "我的工作", "我的邮箱",
+	}
+	// hedgingKeywords 不确定/琐碎信号
+	hedgingKeywords = []string{
+		"maybe", "perhaps", "might", "possibly", "trivial",
+		"也许", "可能", "或许", "无关紧要",
+	}
+
+	// questionPattern 疑问句模式
+	questionPattern = regexp.MustCompile(`(?i)(^\s*(what|why|how|when|where|who|which|is|are|can|could|do|does|did|should|would)\b)|[?？]\s*$|吗[。？?]?\s*$`)
+)
+
+// DefaultImportanceRules 返回默认启发式规则
+//
+// 信号包括：显式记忆标记、重要/紧急关键词、用户偏好、用户身份、
+// 命名实体、疑问句（降低）、不确定表述（降低）以及内容长度。
+func DefaultImportanceRules() []ImportanceRule {
+	return []ImportanceRule{
+		KeywordRule("explicit_marker", 0.25, explicitMarkerKeywords...),
+		KeywordRule("urgency", 0.15, urgencyKeywords...),
+		KeywordRule("preference", 0.2, preferenceKeywords...),
+		KeywordRule("identity", 0.2, identityKeywords...),
+		KeywordRule("hedging", -0.1, hedgingKeywords...),
+		{
+			Name:   "question",
+			Weight: -0.1,
+			Match: func(content string, _ map[string]interface{}) bool {
+				return questionPattern.MatchString(content)
+			},
+		},
+		{
+			Name:   "named_entities",
+			Weight: 0.1,
+			Match: func(content string, _ map[string]interface{}) bool {
+				return len(extractEntities(content)) > 0
+			},
+		},
+		{
+			Name:   "short_content",
+			Weight: -0.1,
+			Match: func(content string, _ map[string]interface{}) bool {
+				return len(content) < 20
+			},
+		},
+		{
+			Name:   "medium_content",
+			Weight: 0.1,
+			Match: func(content string, _ map[string]interface{}) bool {
+				return len(content) > 200 && len(content) <= 500
+			},
+		},
+		{
+			Name:   "long_content",
+			Weight: 0.2,
+			Match: func(content string, _ map[string]interface{}) bool {
+				return len(content) > 500
+			},
+		},
+	}
+}
+
+// HeuristicImportanceScorer 基于规则的重要性评分器
+//
+// 评分 = 基础分 + 命中规则权重之和，并结合元数据中的 priority 标记。
+// 如果元数据中显式给出了 importance，则直接使用该值。
+type HeuristicImportanceScorer struct {
+	base  float32
+	rules []ImportanceRule
+}
+
+// HeuristicScorerOption 启发式评分器选项
+type HeuristicScorerOption func(*HeuristicImportanceScorer)
+
+// WithImportanceBase 设置基础分（默认 0.5）
+func WithImportanceBase(base float32) HeuristicScorerOption {
+	return func(s *HeuristicImportanceScorer) {
+		s.base = base
+	}
+}
+
+// WithImportanceRules 替换规则集
+func WithImportanceRules(rules ...ImportanceRule) HeuristicScorerOption {
+	return func(s *HeuristicImportanceScorer) {
+		s.rules = rules
+	}
+}
+
+// WithExtraImportanceRules 在默认规则之外追加规则
+func WithExtraImportanceRules(rules ...ImportanceRule) HeuristicScorerOption {
+	return func(s *HeuristicImportanceScorer) {
+		s.rules = append(s.rules, rules...)
+	}
+}
+
+// NewHeuristicImportanceScorer 创建启发式重要性评分器
+func NewHeuristicImportanceScorer(opts ...HeuristicScorerOption) *HeuristicImportanceScorer {
+	s := &HeuristicImportanceScorer{
+		base:  0.5,
+		rules: DefaultImportanceRules(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Score 计算重要性评分
+func (s *HeuristicImportanceScorer) Score(_ context.Context, content string, metadata map[string]interface{}) (float32, error) {
+	return s.score(content, metadata), nil
+}
+
+// score 计算重要性评分（同步版本）
+func (s *HeuristicImportanceScorer) score(content string, metadata map[string]interface{}) float32 {
+	if imp, ok := explicitImportance(metadata); ok {
+		return clampImportance(imp)
+	}
+
+	importance := s.base
+	for _, rule := range s.rules {
+		if rule.Match != nil && rule.Match(content, metadata) {
+			importance += rule.Weight
+		}
+	}
+
+	if priority, ok := metadata["priority"].(string); ok {
+		switch strings.ToLower(priority) {
+		case "high":
+			importance += 0.2
+		case "low":
+			importance -= 0.2
+		}
+	}
+
+	return clampImportance(importance)
+}
+
+// MatchedRules 返回内容命中的规则名称（用于调试）
+func (s *HeuristicImportanceScorer) MatchedRules(content string, metadata map[string]interface{}) []string {
+	var names []string
+	for _, rule := range s.rules {
+		if rule.Match != nil && rule.Match(content, metadata) {
+			names = append(names, rule.Name)
+		}
+	}
+	return names
+}
+
+// DefaultImportancePrompt 默认 LLM 重要性评分提示模板
+const DefaultImportancePrompt = `你是一个记忆重要性评估助手。请评估以下内容作为长期记忆的重要性。
+
+评分标准：
+- 0.9-1.0: 用户身份、长期偏好、明确要求记住的信息
+- 0.6-0.8: 有用的事实、决定、计划
+- 0.3-0.5: 一般性对话内容
+- 0.0-0.2: 寒暄、无意义或临时性内容
+
+内容: %s
+
+请只输出一个 0 到 1 之间的数字，不要输出其他内容：`
+
+// LLMImportanceScorer 基于 LLM 的重要性评分器
+//
+// 调用 LLM 对内容评分。如果调用失败或无法解析输出，
+// 且设置了回退评分器，则使用回退评分器的结果。
+type LLMImportanceScorer struct {
+	provider llm.Provider
+	prompt   string
+	fallback ImportanceScorer
+}
+
+// LLMScorerOption LLM 评分器选项
+type LLMScorerOption func(*LLMImportanceScorer)
+
+// WithImportancePrompt 设置自定义提示模板（需包含一个 %s 占位符）
+func WithImportancePrompt(prompt string) LLMScorerOption {
+	return func(s *LLMImportanceScorer) {
+		s.prompt = prompt
+	}
+}
+
+// WithImportanceFallback 设置回退评分器
+func WithImportanceFallback(fallback ImportanceScorer) LLMScorerOption {
+	return func(s *LLMImportanceScorer) {
+		s.fallback = fallback
+	}
+}
+
+// NewLLMImportanceScorer 创建 LLM 重要性评分器
+func NewLLMImportanceScorer(provider llm.Provider, opts ...LLMScorerOption) *LLMImportanceScorer {
+	s := &LLMImportanceScorer{
+		provider: provider,
+		prompt:   DefaultImportancePrompt,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// numberPattern 匹配输出中的第一个数字
+var numberPattern = regexp.MustCompile(`\d+(?:\.\d+)?`)
+
+// Score 计算重要性评分
+func (s *LLMImportanceScorer) Score(ctx context.Context, content string, metadata map[string]interface{}) (float32, error) {
+	if imp, ok := explicitImportance(metadata); ok {
+		return clampImportance(imp), nil
+	}
+
+	score, err := s.generate(ctx, content)
+	if err != nil {
+		if s.fallback != nil {
+			return s.fallback.Score(ctx, content, metadata)
+		}
+		return 0, err
+	}
+	return score, nil
+}
+
+// generate 调用 LLM 并解析评分
+func (s *LLMImportanceScorer) generate(ctx context.Context, content string) (float32, error) {
+	if s.provider == nil {
+		return 0, fmt.Errorf("importance scorer: llm provider not set")
+	}
+
+	resp, err := s.provider.Generate(ctx, llm.Request{
+		Messages: []message.Message{
+			message.NewUserMessage(fmt.Sprintf(s.prompt, content)),
+		},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("importance scorer: %w", err)
+	}
+
+	match := numberPattern.FindString(resp.Content)
+	if match == "" {
+		return 0, fmt.Errorf("importance scorer: no score in response %q", resp.Content)
+	}
+	value, err := strconv.ParseFloat(match, 32)
+	if err != nil {
+		return 0, fmt.Errorf("importance scorer: %w", err)
+	}
+	return clampImportance(float32(value)), nil
+}
+
+// explicitImportance 从元数据中读取显式指定的重要性
+func explicitImportance(metadata map[string]interface{}) (float32, bool) {
+	if metadata == nil {
+		return 0, false
+	}
+	switch imp := metadata["importance"].(type) {
+	case float32:
+		return imp, true
+	case float64:
+		return float32(imp), true
+	}
+	return 0, false
+}
+
+// clampImportance 将重要性限制在 [0, 1] 范围内
+func clampImportance(importance float32) float32 {
+	if importance < 0 {
+		return 0
+	}
+	if importance > 1 {
+		return 1
+	}
+	return importance
+}
+
+// compile-time interface check
+var _ ImportanceScorer = (*HeuristicImportanceScorer)(nil)
+var _ ImportanceScorer = (*LLMImportanceScorer)(nil)
@@ -0,0 +1,158 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ahhsitt/helloagents-go/pkg/core/llm"
+)
+
+// mockLLMProvider is a minimal llm.Provider for testing
+type mockLLMProvider struct {
+	response string
+	err      error
+	calls    int
+}
+
+func (p *mockLLMProvider) Generate(ctx context.Context, req llm.Request) (llm.Response, error) {
+	p.calls++
+	if p.err != nil {
+		return llm.Response{}, p.err
+	}
+	return llm.Response{Content: p.response}, nil
+}
+
+func (p *mockLLMProvider) GenerateStream(ctx context.Context, req llm.Request) (<-chan llm.StreamChunk, <-chan error) {
+	return nil, nil
+}
+
+func (p *mockLLMProvider) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	return nil, nil
+}
+
+func (p *mockLLMProvider) Name() string  { return "mock" }
+func (p *mockLLMProvider) Model() string { return "mock-model" }
+func (p *mockLLMProvider) Close() error  { return nil }
+
+func TestHeuristicImportanceScorer(t *testing.T) {
+	scorer := NewHeuristicImportanceScorer()
+	ctx := context.Background()
+
+	neutral, _ := scorer.Score(ctx, "The weather report was published this afternoon", nil)
+
+	tests := []struct {
+		name    string
+		content string
+		higher  bool
+	}{
+		{"explicit_marker", "Please remember that the deploy window is Friday", true},
+		{"preference", "I prefer short answers without bullet points", true},
+		{"identity", "My name is Alice and I work at a bank", true},
+		{"named_entity", "Yesterday we discussed the plan with Ada Lovelace", true},
+		{"question", "What time does the weather report come out?", false},
+		{"hedging", "Maybe the weather report was published later", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			score, err := scorer.Score(ctx, tt.content, nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.higher && score <= neutral {
+				t.Errorf("expected score > %f, got %f (rules: %v)", neutral, score, scorer.MatchedRules(tt.content, nil))
+			}
+			if !tt.higher && score >= neutral {
+				t.Errorf("expected score < %f, got %f (rules: %v)", neutral, score, scorer.MatchedRules(tt.content, nil))
+			}
+		})
+	}
+}
+
+func TestHeuristicImportanceScorerCustomRules(t *testing.T) {
+	scorer := NewHeuristicImportanceScorer(
+		WithImportanceBase(0.2),
+		WithImportanceRules(KeywordRule("billing", 0.5, "invoice")),
+	)
+
+	score, _ := scorer.Score(context.Background(), "Send the INVOICE to finance", nil)
+	if score < 0.69 || score > 0.71 {
+		t.Errorf("expected score 0.7, got %f", score)
+	}
+
+	score, _ = scorer.Score(context.Background(), "remember that this is important", nil)
+	if score != 0.2 {
+		t.Errorf("expected base score 0.2 with custom rules only, got %f", score)
+	}
+}
+
+func TestLLMImportanceScorer(t *testing.T) {
+	ctx := context.Background()
+
+	provider := &mockLLMProvider{response: "Score: 0.85"}
+	scorer := NewLLMImportanceScorer(provider)
+	score, err := scorer.Score(ctx, "content", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if score != 0.85 {
+		t.Errorf("expected score 0.85, got %f", score)
+	}
+
+	// 显式重要性不调用 LLM
+	provider.calls = 0
+	score, _ = scorer.Score(ctx, "content", map[string]interface{}{"importance": 0.3})
+	if score != 0.3 || provider.calls != 0 {
+		t.Errorf("expected explicit importance 0.3 without LLM call, got %f (calls=%d)", score, provider.calls)
+	}
+
+	// 无法解析时报错
+	scorer = NewLLMImportanceScorer(&mockLLMProvider{response: "very important"})
+	if _, err := scorer.Score(ctx, "content", nil); err == nil {
+		t.Error("expected parse error")
+	}
+
+	// 出错时使用回退评分器
+	scorer = NewLLMImportanceScorer(
+		&mockLLMProvider{err: errors.New("unavailable")},
+		WithImportanceFallback(NewHeuristicImportanceScorer(WithImportanceRules(), WithImportanceBase(0.4))),
+	)
+	score, err = scorer.Score(ctx, "content", nil)
+	if err != nil || score != 0.4 {
+		t.Errorf("expected fallback score 0.4, got %f (err=%v)", score, err)
+	}
+}
+
+func TestAddMemoryWithImportanceScorer(t *testing.T) {
+	ctx := context.Background()
+	manager := NewMemoryManager(nil, WithImportanceScorer(NewLLMImportanceScorer(&mockLLMProvider{response: "0.95"})))
+	mock := newMockMemory(MemoryTypeWorking)
+	_ = manager.RegisterMemory(MemoryTypeWorking, mock)
+
+	id, err := manager.AddMemory(ctx, "any content", WithAddMemoryType(MemoryTypeWorking))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := mock.items[id].Importance; got != 0.95 {
+		t.Errorf("expected importance 0.95, got %f", got)
+	}
+
+	// 显式指定 0.5 时不再被自动评分覆盖
+	id, _ = manager.AddMemory(ctx, "any content", WithAddMemoryType(MemoryTypeWorking), WithAddImportance(0.5))
+	if got := mock.items[id].Importance; got != 0.5 {
+		t.Errorf("expected explicit importance 0.5, got %f", got)
+	}
+
+	// 评分器失败时回退到默认启发式规则
+	manager = NewMemoryManager(nil, WithImportanceScorer(NewLLMImportanceScorer(&mockLLMProvider{err: errors.New("down")})))
+	mock = newMockMemory(MemoryTypeWorking)
+	_ = manager.RegisterMemory(MemoryTypeWorking, mock)
+	id, err = manager.AddMemory(ctx, "hi", WithAddMemoryType(MemoryTypeWorking))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := mock.items[id].Importance; got != manager.calculateImportance("hi", nil) {
+		t.Errorf("expected heuristic fallback importance, got %f", got)
+	}
+}
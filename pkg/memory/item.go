@@ -16,6 +16,8 @@ const (
 	MemoryTypeEpisodic MemoryType = "episodic"
 	// MemoryTypeSemantic 语义记忆
 	MemoryTypeSemantic MemoryType = "semantic"
+	// MemoryTypeProcedural 程序性记忆
+	MemoryTypeProcedural MemoryType = "procedural"
 )
 
 // MemoryItem 统一记忆项数据结构
@@ -0,0 +1,310 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// DefaultIteratePageSize 遍历时每次持锁读取的记录数
+const DefaultIteratePageSize = 256
+
+// 遍历相关错误
+var (
+	// ErrStopIteration 回调返回该错误时提前结束遍历，Iterate 返回 nil
+	ErrStopIteration = errors.New("stop iteration")
+	// ErrIterateUnsupported 记忆存储不支持遍历
+	ErrIterateUnsupported = errors.New("memory does not support iteration")
+)
+
+// IterateFilter 遍历过滤条件，零值字段不参与过滤
+type IterateFilter struct {
+	// MemoryTypes 限定记忆类型（仅 MemoryManager 使用），为空表示全部已注册类型
+	MemoryTypes []MemoryType
+	// UserID 用户 ID
+	UserID string
+	// SessionID 会话 ID，取自 metadata["session_id"]
+	SessionID string
+	// Since 只包含该时间及之后的记忆
+	Since time.Time
+	// Until 只包含该时间之前的记忆
+	Until time.Time
+	// MinImportance 最低重要性
+	MinImportance float32
+}
+
+// Match 判断记忆项是否满足过滤条件（MemoryTypes 由 MemoryManager 按注册类型处理，此处不检查）
+func (f IterateFilter) Match(item *MemoryItem) bool {
+	if f.UserID != "" && item.UserID != f.UserID {
+		return false
+	}
+	if f.SessionID != "" {
+		if sessionID, _ := item.Metadata["session_id"].(string); sessionID != f.SessionID {
+			return false
+		}
+	}
+	if !f.Since.IsZero() && item.Timestamp.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !item.Timestamp.Before(f.Until) {
+		return false
+	}
+	return item.Importance >= f.MinImportance
+}
+
+// includesType 判断记忆类型是否在过滤范围内
+func (f IterateFilter) includesType(t MemoryType) bool {
+	if len(f.MemoryTypes) == 0 {
+		return true
+	}
+	for _, memType := range f.MemoryTypes {
+		if memType == t {
+			return true
+		}
+	}
+	return false
+}
+
+// Iterator 支持流式遍历的记忆存储
+//
+// 内置的 WorkingMemory、EpisodicMemoryStore、SemanticMemoryStore 和 ProceduralMemoryStore 均已实现。
+// 遍历按页持读锁复制记录，回调在锁外执行，因此回调中可以安全地读写同一存储。
+// 遍历是弱一致的：遍历期间新增的记录可能被访问也可能不被访问，被并发删除的记录在删除后不再访问；
+// 下一页的第一条记录恰好被其他协程删除时，可能跳过少量前移的记录。
+type Iterator interface {
+	// Iterate 按存储顺序对满足过滤条件的每条记忆调用 fn，fn 返回错误时停止遍历
+	Iterate(ctx context.Context, filter IterateFilter, fn func(*MemoryItem) error) error
+}
+
+// iterCursor 分页遍历的游标
+//
+// 记录下一页的起始位置和该位置上尚未访问的记录 ID；
+// 位置因并发删除（包括回调删除已访问的记录）而前移时按 ID 重新定位。
+type iterCursor struct {
+	pos    int
+	nextID string
+}
+
+// pageRange 返回下一页的 [start, end) 并推进游标（调用方需持有读锁）
+func (c *iterCursor) pageRange(n int, id func(i int) string) (int, int) {
+	start := min(c.pos, n)
+	if c.nextID != "" && (start == n || id(start) != c.nextID) {
+		// 删除只会让记录前移，从原位置向前查找下一条未访问的记录
+		for i := min(start, n-1); i >= 0; i-- {
+			if id(i) == c.nextID {
+				start = i
+				break
+			}
+		}
+	}
+	end := min(start+DefaultIteratePageSize, n)
+	c.pos, c.nextID = end, ""
+	if end < n {
+		c.nextID = id(end)
+	}
+	return start, end
+}
+
+// iteratePages 循环读取分页并在锁外调用 fn，page 的第二个返回值为 false 表示已无更多记录
+func iteratePages(ctx context.Context, filter IterateFilter, fn func(*MemoryItem) error, page func(c *iterCursor) ([]*MemoryItem, bool)) error {
+	var cursor iterCursor
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		items, more := page(&cursor)
+		if !more {
+			return nil
+		}
+		for _, item := range items {
+			if !filter.Match(item) {
+				continue
+			}
+			if err := fn(item); err != nil {
+				if errors.Is(err, ErrStopIteration) {
+					return nil
+				}
+				return err
+			}
+		}
+	}
+}
+
+// cloneMetadata 复制元数据，避免遍历结果与存储共享 map
+func cloneMetadata(metadata map[string]interface{}) map[string]interface{} {
+	cloned := make(map[string]interface{}, len(metadata)+4)
+	for k, v := range metadata {
+		cloned[k] = v
+	}
+	return cloned
+}
+
+// Iterate 遍历未过期的消息（实现 Iterator 接口）
+func (m *WorkingMemory) Iterate(ctx context.Context, filter IterateFilter, fn func(*MemoryItem) error) error {
+	if err := m.ensureLoaded(ctx); err != nil {
+		return err
+	}
+	return iteratePages(ctx, filter, fn, func(c *iterCursor) ([]*MemoryItem, bool) {
+		m.mu.RLock()
+		defer m.mu.RUnlock()
+
+		var cutoff time.Time
+		if m.ttl > 0 {
+			cutoff = time.Now().Add(-m.ttl)
+		}
+		start, end := c.pageRange(len(m.messages), func(i int) string { return m.messages[i].Message.ID })
+		items := make([]*MemoryItem, 0, end-start)
+		for _, wm := range m.messages[start:end] {
+			if !cutoff.IsZero() && !wm.Message.Timestamp.After(cutoff) {
+				continue
+			}
+			wm.Message.Metadata = cloneMetadata(wm.Message.Metadata)
+			items = append(items, m.messageToItem(wm, 0))
+		}
+		return items, end > start
+	})
+}
+
+// Iterate 遍历全部事件（实现 Iterator 接口）
+func (m *EpisodicMemoryStore) Iterate(ctx context.Context, filter IterateFilter, fn func(*MemoryItem) error) error {
+	if err := m.ensureLoaded(ctx); err != nil {
+		return err
+	}
+	return iteratePages(ctx, filter, fn, func(c *iterCursor) ([]*MemoryItem, bool) {
+		m.mu.RLock()
+		defer m.mu.RUnlock()
+
+		start, end := c.pageRange(len(m.episodes), func(i int) string { return m.episodes[i].ID })
+		items := make([]*MemoryItem, 0, end-start)
+		for _, ep := range m.episodes[start:end] {
+			ep.Metadata = cloneMetadata(ep.Metadata)
+			items = append(items, m.episodeToItem(ep, 0))
+		}
+		return items, end > start
+	})
+}
+
+// Iterate 遍历全部语义记录（实现 Iterator 接口）
+func (m *SemanticMemoryStore) Iterate(ctx context.Context, filter IterateFilter, fn func(*MemoryItem) error) error {
+	return iteratePages(ctx, filter, fn, func(c *iterCursor) ([]*MemoryItem, bool) {
+		m.mu.RLock()
+		defer m.mu.RUnlock()
+
+		start, end := c.pageRange(len(m.records), func(i int) string { return m.records[i].ID })
+		items := make([]*MemoryItem, 0, end-start)
+		for _, rec := range m.records[start:end] {
+			items = append(items, &MemoryItem{
+				ID:         rec.ID,
+				Content:    rec.Content,
+				MemoryType: MemoryTypeSemantic,
+				UserID:     rec.UserID,
+				Timestamp:  rec.Timestamp,
+				Importance: rec.Importance,
+				Metadata:   cloneMetadata(rec.Metadata),
+			})
+		}
+		return items, end > start
+	})
+}
+
+// Iterate 遍历全部程序（实现 Iterator 接口）
+func (m *ProceduralMemoryStore) Iterate(ctx context.Context, filter IterateFilter, fn func(*MemoryItem) error) error {
+	return iteratePages(ctx, filter, fn, func(c *iterCursor) ([]*MemoryItem, bool) {
+		m.mu.RLock()
+		defer m.mu.RUnlock()
+
+		start, end := c.pageRange(len(m.procedures), func(i int) string { return m.procedures[i].ID })
+		items := make([]*MemoryItem, 0, end-start)
+		for _, p := range m.procedures[start:end] {
+			items = append(items, procedureToItem(p, 0))
+		}
+		return items, end > start
+	})
+}
+
+// Iterate 按记忆类型名称顺序遍历所有已注册记忆
+//
+// 设置了管理器用户 ID 且 filter.UserID 为空时只遍历该用户的记忆。
+// filter.MemoryTypes 范围内的存储未实现 Iterator 时返回 ErrIterateUnsupported。
+func (m *MemoryManager) Iterate(ctx context.Context, filter IterateFilter, fn func(*MemoryItem) error) error {
+	if filter.UserID == "" {
+		filter.UserID = m.userID
+	}
+
+	m.mu.RLock()
+	types := make([]MemoryType, 0, len(m.memoryTypes))
+	iterators := make(map[MemoryType]Iterator, len(m.memoryTypes))
+	for memType, mem := range m.memoryTypes {
+		if !filter.includesType(memType) {
+			continue
+		}
+		it, ok := mem.(Iterator)
+		if !ok {
+			m.mu.RUnlock()
+			return fmt.Errorf("iterate %s memory: %w", memType, ErrIterateUnsupported)
+		}
+		types = append(types, memType)
+		iterators[memType] = it
+	}
+	m.mu.RUnlock()
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+	filter.MemoryTypes = nil
+
+	stopped := false
+	for _, memType := range types {
+		err := iterators[memType].Iterate(ctx, filter, func(item *MemoryItem) error {
+			if err := fn(item); err != nil {
+				if errors.Is(err, ErrStopIteration) {
+					stopped = true
+				}
+				return err
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("iterate %s memory: %w", memType, err)
+		}
+		if stopped {
+			return nil
+		}
+	}
+	return nil
+}
+
+// Stream 以通道形式遍历所有已注册记忆
+//
+// 记忆项通道在遍历结束后关闭；遍历出错时错误通道收到一个错误。
+// 取消 ctx 可提前结束遍历，buffer 为记忆项通道的缓冲大小。
+func (m *MemoryManager) Stream(ctx context.Context, filter IterateFilter, buffer int) (<-chan *MemoryItem, <-chan error) {
+	itemCh := make(chan *MemoryItem, max(buffer, 0))
+	errCh := make(chan error, 1)
+
+	go func() {
+		defer close(itemCh)
+		defer close(errCh)
+
+		err := m.Iterate(ctx, filter, func(item *MemoryItem) error {
+			select {
+			case itemCh <- item:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+		if err != nil {
+			errCh <- err
+		}
+	}()
+
+	return itemCh, errCh
+}
+
+// compile-time interface check
+var (
+	_ Iterator = (*WorkingMemory)(nil)
+	_ Iterator = (*EpisodicMemoryStore)(nil)
+	_ Iterator = (*SemanticMemoryStore)(nil)
+	_ Iterator = (*ProceduralMemoryStore)(nil)
+)
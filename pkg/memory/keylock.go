@@ -0,0 +1,45 @@
+package memory
+
+import (
+	"hash/fnv"
+	"sort"
+	"sync"
+)
+
+// keyLockStripes 按 ID 加锁的分段数
+const keyLockStripes = 64
+
+// keyLocks 按 ID 分段的互斥锁
+//
+// 同一 ID 的写操作串行执行，不同 ID 大多落在不同分段上互不阻塞。
+// 用于让本地记录和外部存储（如向量数据库）的写入顺序保持一致。
+type keyLocks struct {
+	stripes [keyLockStripes]sync.Mutex
+}
+
+// lock 锁定 ids 所在的分段并返回解锁函数
+//
+// 分段按下标升序加锁，批量操作之间不会死锁。
+func (l *keyLocks) lock(ids ...string) func() {
+	seen := make(map[int]bool, len(ids))
+	indexes := make([]int, 0, len(ids))
+	for _, id := range ids {
+		h := fnv.New32a()
+		h.Write([]byte(id))
+		idx := int(h.Sum32() % keyLockStripes)
+		if !seen[idx] {
+			seen[idx] = true
+			indexes = append(indexes, idx)
+		}
+	}
+	sort.Ints(indexes)
+
+	for _, idx := range indexes {
+		l.stripes[idx].Lock()
+	}
+	return func() {
+		for i := len(indexes) - 1; i >= 0; i-- {
+			l.stripes[indexes[i]].Unlock()
+		}
+	}
+}
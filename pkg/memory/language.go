@@ -0,0 +1,216 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"unicode"
+)
+
+// 语言标签（写入 metadata["language"]）
+const (
+	// LanguageChinese 中文
+	LanguageChinese = "zh"
+	// LanguageEnglish 英文
+	LanguageEnglish = "en"
+	// LanguageJapanese 日文
+	LanguageJapanese = "ja"
+	// LanguageKorean 韩文
+	LanguageKorean = "ko"
+	// LanguageMixed 多种语言混合，没有占主导的语言
+	LanguageMixed = "mixed"
+	// LanguageUnknown 无法识别（如纯数字、符号）
+	LanguageUnknown = ""
+)
+
+// languageDominance 主导语言所占比例的下限，低于该比例视为混合
+const languageDominance = 0.7
+
+// latinLetterWeight 每个拉丁字母的权重
+//
+// 同样的信息量，英文字母数约为汉字数的 2-3 倍，按 0.4 折算后两种文字可直接比较。
+const latinLetterWeight = 0.4
+
+// DetectLanguage 根据文字脚本检测文本语言
+//
+// 统计汉字、假名、谚文和拉丁字母的数量（拉丁字母按 latinLetterWeight 折算），
+// 某种语言占比不低于 70% 时返回该语言，否则返回 LanguageMixed；
+// 含假名的文本视为日文（汉字计入日文），没有任何字母时返回 LanguageUnknown。
+func DetectLanguage(text string) string {
+	var han, kana, hangul, latin float64
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			han++
+		case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+			kana++
+		case unicode.Is(unicode.Hangul, r):
+			hangul++
+		case r < unicode.MaxASCII && unicode.IsLetter(r):
+			latin += latinLetterWeight
+		}
+	}
+
+	counts := map[string]float64{
+		LanguageChinese: han,
+		LanguageKorean:  hangul,
+		LanguageEnglish: latin,
+	}
+	if kana > 0 {
+		counts[LanguageChinese] = 0
+		counts[LanguageJapanese] = han + kana
+	}
+
+	var total, best float64
+	lang := LanguageUnknown
+	for l, n := range counts {
+		total += n
+		if n > best {
+			best, lang = n, l
+		}
+	}
+	if total == 0 {
+		return LanguageUnknown
+	}
+	if best/total < languageDominance {
+		return LanguageMixed
+	}
+	return lang
+}
+
+// LanguageRoutingEmbedder 按文本语言路由的嵌入器
+//
+// 每条文本按检测出的语言交给对应的嵌入器（如中文使用中文嵌入模型），
+// 没有对应路由的语言（包括混合和无法识别的文本）交给 fallback，通常是多语言模型。
+// 同一批文本按语言分组，每个嵌入器只调用一次，结果顺序与输入一致。
+//
+// 不同模型的向量不可直接比较：查询向量由查询语言的嵌入器生成，
+// 只与同一嵌入器生成的记录比较才有意义，建议配合 WithSemanticLanguageDetection 使用。
+// 也同样满足 rag.Embedder，可用于 RAG 摄取。
+type LanguageRoutingEmbedder struct {
+	fallback Embedder
+	routes   map[string]Embedder
+	detect   func(string) string
+}
+
+// LanguageRoutingOption 语言路由嵌入器选项
+type LanguageRoutingOption func(*LanguageRoutingEmbedder)
+
+// WithLanguageRoute 为语言设置嵌入器
+func WithLanguageRoute(language string, embedder Embedder) LanguageRoutingOption {
+	return func(e *LanguageRoutingEmbedder) {
+		e.routes[language] = embedder
+	}
+}
+
+// WithLanguageDetector 设置自定义语言检测函数（默认 DetectLanguage）
+func WithLanguageDetector(detect func(string) string) LanguageRoutingOption {
+	return func(e *LanguageRoutingEmbedder) {
+		e.detect = detect
+	}
+}
+
+// NewLanguageRoutingEmbedder 创建按语言路由的嵌入器
+func NewLanguageRoutingEmbedder(fallback Embedder, opts ...LanguageRoutingOption) *LanguageRoutingEmbedder {
+	e := &LanguageRoutingEmbedder{
+		fallback: fallback,
+		routes:   make(map[string]Embedder),
+		detect:   DetectLanguage,
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// Embed 将文本按语言分组后分别嵌入
+func (e *LanguageRoutingEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	type group struct {
+		embedder Embedder
+		indexes  []int
+		texts    []string
+	}
+
+	// 按路由分组：key 为有路由的语言，没有路由的文本归入 fallback 组
+	const fallbackKey = "\x00fallback"
+	var order []string
+	groups := make(map[string]*group)
+	for i, text := range texts {
+		key := e.detect(text)
+		embedder, ok := e.routes[key]
+		if !ok {
+			key, embedder = fallbackKey, e.fallback
+		}
+		if embedder == nil {
+			return nil, fmt.Errorf("embed: no embedder for language %q: %w", e.detect(text), ErrInvalidInput)
+		}
+		g, ok := groups[key]
+		if !ok {
+			g = &group{embedder: embedder}
+			groups[key] = g
+			order = append(order, key)
+		}
+		g.indexes = append(g.indexes, i)
+		g.texts = append(g.texts, text)
+	}
+
+	vectors := make([][]float32, len(texts))
+	for _, key := range order {
+		g := groups[key]
+		embedded, err := g.embedder.Embed(ctx, g.texts)
+		if err != nil {
+			return nil, err
+		}
+		if len(embedded) != len(g.texts) {
+			return nil, ErrEmbeddingFailed
+		}
+		for j, i := range g.indexes {
+			vectors[i] = embedded[j]
+		}
+	}
+	return vectors, nil
+}
+
+// compile-time interface check
+var _ Embedder = (*LanguageRoutingEmbedder)(nil)
+
+// WithSemanticLanguageDetection 启用语言检测
+//
+// 写入时检测内容语言并写入 metadata["language"]（已有该字段时保留）；
+// boost > 0 时检索偏好与查询语言相同的记录：这些记录的分数乘以 (1 + boost) 后重新排序。
+func WithSemanticLanguageDetection(boost float32) SemanticMemoryOption {
+	return func(m *SemanticMemoryStore) {
+		m.detectLanguage = true
+		m.languageBoost = boost
+	}
+}
+
+// tagLanguage 在启用语言检测时为元数据添加语言标签，返回（可能新建的）元数据
+func (m *SemanticMemoryStore) tagLanguage(content string, metadata map[string]interface{}) map[string]interface{} {
+	if !m.detectLanguage {
+		return metadata
+	}
+	if metadata == nil {
+		metadata = make(map[string]interface{})
+	}
+	if _, ok := metadata["language"]; !ok {
+		metadata["language"] = DetectLanguage(content)
+	}
+	return metadata
+}
+
+// preferLanguage 提升与查询语言相同的结果的分数，重新排序后截取 topK
+func preferLanguage(results []SearchResult, language string, boost float32, topK int) []SearchResult {
+	for i := range results {
+		if lang, _ := results[i].Metadata["language"].(string); lang == language {
+			results[i].Score *= 1 + boost
+		}
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	if topK < len(results) {
+		results = results[:topK]
+	}
+	return results
+}
@@ -0,0 +1,403 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ahhsitt/helloagents-go/pkg/otel"
+)
+
+// 维护调度相关错误
+var (
+	// ErrJobNotFound 维护任务不存在
+	ErrJobNotFound = errors.New("maintenance job not found")
+	// ErrJobExists 维护任务已存在
+	ErrJobExists = errors.New("maintenance job already exists")
+	// ErrInvalidSchedule 调度规则无效
+	ErrInvalidSchedule = errors.New("invalid maintenance schedule")
+)
+
+// Schedule 维护任务的调度规则
+type Schedule interface {
+	// Next 返回 after 之后的下一次执行时间
+	Next(after time.Time) time.Time
+}
+
+// intervalSchedule 固定间隔调度
+type intervalSchedule time.Duration
+
+// Next 返回 after 加上间隔
+func (s intervalSchedule) Next(after time.Time) time.Time {
+	return after.Add(time.Duration(s))
+}
+
+// Every 返回按固定间隔执行的调度规则
+func Every(interval time.Duration) Schedule {
+	return intervalSchedule(interval)
+}
+
+// dailySchedule 每天固定时刻调度
+type dailySchedule struct {
+	hour, minute int
+}
+
+// Next 返回 after 之后最近的 hour:minute（本地时间）
+func (s dailySchedule) Next(after time.Time) time.Time {
+	next := time.Date(after.Year(), after.Month(), after.Day(), s.hour, s.minute, 0, 0, after.Location())
+	if !next.After(after) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// DailyAt 返回每天在 hour:minute（本地时间）执行的调度规则
+func DailyAt(hour, minute int) Schedule {
+	return dailySchedule{hour: hour, minute: minute}
+}
+
+// ParseSchedule 解析类 cron 的调度描述
+//
+// 支持 "@every <时长>"（如 "@every 30m"）、"@hourly"、"@daily"（每天 0 点）、
+// "@weekly"（每 7 天）以及 "HH:MM"（每天该时刻）。
+func ParseSchedule(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	switch spec {
+	case "@hourly":
+		return Every(time.Hour), nil
+	case "@daily", "@midnight":
+		return DailyAt(0, 0), nil
+	case "@weekly":
+		return Every(7 * 24 * time.Hour), nil
+	}
+
+	if rest, ok := strings.CutPrefix(spec, "@every "); ok {
+		interval, err := time.ParseDuration(strings.TrimSpace(rest))
+		if err != nil || interval <= 0 {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidSchedule, spec)
+		}
+		return Every(interval), nil
+	}
+
+	var hour, minute int
+	if n, err := fmt.Sscanf(spec, "%d:%d", &hour, &minute); err == nil && n == 2 &&
+		hour >= 0 && hour < 24 && minute >= 0 && minute < 60 {
+		return DailyAt(hour, minute), nil
+	}
+	return nil, fmt.Errorf("%w: %q", ErrInvalidSchedule, spec)
+}
+
+// MaintenanceJob 记忆维护任务
+type MaintenanceJob struct {
+	// Name 任务名称（调度器内唯一）
+	Name string
+	// Schedule 调度规则
+	Schedule Schedule
+	// Jitter 每次执行前额外等待 [0, Jitter) 的随机时长，避免多个实例同时执行
+	Jitter time.Duration
+	// Timeout 单次执行超时，0 表示不限制
+	Timeout time.Duration
+	// Run 执行任务，返回处理的记忆数量
+	Run func(ctx context.Context, manager *MemoryManager) (int, error)
+}
+
+// JobStats 维护任务的执行统计
+type JobStats struct {
+	// Name 任务名称
+	Name string `json:"name"`
+	// Runs 执行次数
+	Runs int `json:"runs"`
+	// Failures 失败次数
+	Failures int `json:"failures"`
+	// Affected 累计处理的记忆数量
+	Affected int `json:"affected"`
+	// LastRun 最近一次开始执行的时间
+	LastRun time.Time `json:"last_run,omitempty"`
+	// LastDuration 最近一次执行耗时
+	LastDuration time.Duration `json:"last_duration,omitempty"`
+	// LastError 最近一次执行的错误（成功时为空）
+	LastError string `json:"last_error,omitempty"`
+	// NextRun 下一次计划执行的时间（调度器未运行时为零值）
+	NextRun time.Time `json:"next_run,omitempty"`
+}
+
+// DecayJob 按 DecayPolicy 衰减记忆重要性的任务
+func DecayJob(schedule Schedule) MaintenanceJob {
+	return MaintenanceJob{
+		Name:     "decay",
+		Schedule: schedule,
+		Run: func(ctx context.Context, m *MemoryManager) (int, error) {
+			return m.Decay(ctx)
+		},
+	}
+}
+
+// ForgetByCapacityJob 将各记忆类型缩减到 capacity 条的任务
+func ForgetByCapacityJob(schedule Schedule, capacity int) MaintenanceJob {
+	return MaintenanceJob{
+		Name:     "forget_by_capacity",
+		Schedule: schedule,
+		Run: func(ctx context.Context, m *MemoryManager) (int, error) {
+			return m.ForgetMemories(ctx, ForgetByCapacity, WithTargetCapacity(capacity))
+		},
+	}
+}
+
+// ConsolidationJob 整合工作记忆的任务
+func ConsolidationJob(schedule Schedule, opts ...ConsolidateOption) MaintenanceJob {
+	return MaintenanceJob{
+		Name:     "consolidate",
+		Schedule: schedule,
+		Run: func(ctx context.Context, m *MemoryManager) (int, error) {
+			return m.ConsolidateMemories(ctx, opts...)
+		},
+	}
+}
+
+// flusher 支持重建索引的记忆存储
+type flusher interface {
+	Flush()
+}
+
+// ReindexJob 重建过期检索索引的任务，返回重建的记忆类型数量
+//
+// 作用于实现了 Flush 的记忆类型（内置记忆类型均已实现），
+// 使写入后的首次检索不必承担索引重建的延迟。
+func ReindexJob(schedule Schedule) MaintenanceJob {
+	return MaintenanceJob{
+		Name:     "reindex",
+		Schedule: schedule,
+		Run: func(ctx context.Context, m *MemoryManager) (int, error) {
+			flushed := 0
+			for _, memory := range m.snapshotMemories() {
+				if f, ok := memory.(flusher); ok {
+					f.Flush()
+					flushed++
+				}
+			}
+			return flushed, nil
+		},
+	}
+}
+
+// SnapshotJob 将所有记忆导出为 JSONL 快照的任务
+//
+// 每次执行调用 open 获取快照写入目标（如按时间命名的文件），导出完成后关闭。
+func SnapshotJob(schedule Schedule, open func(ctx context.Context) (io.WriteCloser, error)) MaintenanceJob {
+	return MaintenanceJob{
+		Name:     "snapshot",
+		Schedule: schedule,
+		Run: func(ctx context.Context, m *MemoryManager) (int, error) {
+			w, err := open(ctx)
+			if err != nil {
+				return 0, fmt.Errorf("snapshot: %w", err)
+			}
+			if err := m.Export(ctx, w); err != nil {
+				_ = w.Close()
+				return 0, err
+			}
+			return 0, w.Close()
+		},
+	}
+}
+
+// MaintenanceScheduler 记忆维护调度器
+//
+// 按各任务的调度规则在后台执行衰减、遗忘、整合、重建索引和快照等维护任务。
+// 每个任务在独立的 goroutine 中调度，同一任务不会重叠执行；任务失败不影响后续调度。
+type MaintenanceScheduler struct {
+	manager *MemoryManager
+	metrics otel.Metrics
+	logger  otel.Logger
+
+	mu    sync.Mutex
+	jobs  map[string]*scheduledJob
+	order []string
+}
+
+// scheduledJob 调度器中的任务及其统计
+type scheduledJob struct {
+	job   MaintenanceJob
+	runMu sync.Mutex // 保证同一任务不重叠执行
+	stats JobStats
+}
+
+// SchedulerOption 维护调度器配置选项
+type SchedulerOption func(*MaintenanceScheduler)
+
+// WithSchedulerMetrics 设置指标，导出各任务的执行次数、耗时和处理的记忆数量
+func WithSchedulerMetrics(metrics otel.Metrics) SchedulerOption {
+	return func(s *MaintenanceScheduler) {
+		s.metrics = metrics
+	}
+}
+
+// WithSchedulerLogger 设置日志记录器，用于输出任务失败
+func WithSchedulerLogger(logger otel.Logger) SchedulerOption {
+	return func(s *MaintenanceScheduler) {
+		s.logger = logger
+	}
+}
+
+// NewMaintenanceScheduler 创建维护调度器
+func NewMaintenanceScheduler(manager *MemoryManager, opts ...SchedulerOption) *MaintenanceScheduler {
+	s := &MaintenanceScheduler{
+		manager: manager,
+		jobs:    make(map[string]*scheduledJob),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// AddJob 添加维护任务
+//
+// 名称为空、缺少调度规则或执行函数时返回 ErrInvalidInput，调度规则不会推进时间
+// （如 Every(0)）时返回 ErrInvalidSchedule，名称重复时返回 ErrJobExists。
+// 调度器运行期间添加的任务在下次调用 Run 时才开始调度。
+func (s *MaintenanceScheduler) AddJob(job MaintenanceJob) error {
+	if job.Name == "" || job.Schedule == nil || job.Run == nil {
+		return ErrInvalidInput
+	}
+	if now := time.Now(); !job.Schedule.Next(now).After(now) {
+		return fmt.Errorf("%w: job %s", ErrInvalidSchedule, job.Name)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.jobs[job.Name]; exists {
+		return fmt.Errorf("%w: %s", ErrJobExists, job.Name)
+	}
+	s.jobs[job.Name] = &scheduledJob{job: job, stats: JobStats{Name: job.Name}}
+	s.order = append(s.order, job.Name)
+	return nil
+}
+
+// Run 调度所有任务，直到 ctx 被取消
+//
+// 通常在后台 goroutine 中运行：go scheduler.Run(ctx)。返回前等待正在执行的任务结束。
+func (s *MaintenanceScheduler) Run(ctx context.Context) error {
+	s.mu.Lock()
+	jobs := make([]*scheduledJob, 0, len(s.order))
+	for _, name := range s.order {
+		jobs = append(jobs, s.jobs[name])
+	}
+	s.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, sj := range jobs {
+		wg.Add(1)
+		go func(sj *scheduledJob) {
+			defer wg.Done()
+			s.loop(ctx, sj)
+		}(sj)
+	}
+	wg.Wait()
+	return ctx.Err()
+}
+
+// RunNow 立即执行一次指定任务，返回处理的记忆数量
+func (s *MaintenanceScheduler) RunNow(ctx context.Context, name string) (int, error) {
+	s.mu.Lock()
+	sj, ok := s.jobs[name]
+	s.mu.Unlock()
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrJobNotFound, name)
+	}
+	return s.execute(ctx, sj)
+}
+
+// Stats 返回各任务的执行统计（按添加顺序）
+func (s *MaintenanceScheduler) Stats() []JobStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := make([]JobStats, 0, len(s.order))
+	for _, name := range s.order {
+		stats = append(stats, s.jobs[name].stats)
+	}
+	return stats
+}
+
+// loop 按调度规则循环执行任务
+func (s *MaintenanceScheduler) loop(ctx context.Context, sj *scheduledJob) {
+	for {
+		next := sj.job.Schedule.Next(time.Now())
+		if sj.job.Jitter > 0 {
+			next = next.Add(time.Duration(rand.Int64N(int64(sj.job.Jitter))))
+		}
+		s.mu.Lock()
+		sj.stats.NextRun = next
+		s.mu.Unlock()
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			s.mu.Lock()
+			sj.stats.NextRun = time.Time{}
+			s.mu.Unlock()
+			return
+		case <-timer.C:
+		}
+		_, _ = s.execute(ctx, sj)
+	}
+}
+
+// execute 执行一次任务并记录统计和指标
+func (s *MaintenanceScheduler) execute(ctx context.Context, sj *scheduledJob) (int, error) {
+	sj.runMu.Lock()
+	defer sj.runMu.Unlock()
+
+	if sj.job.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, sj.job.Timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	affected, err := sj.job.Run(ctx, s.manager)
+	duration := time.Since(start)
+
+	s.mu.Lock()
+	sj.stats.Runs++
+	sj.stats.Affected += affected
+	sj.stats.LastRun = start
+	sj.stats.LastDuration = duration
+	sj.stats.LastError = ""
+	if err != nil {
+		sj.stats.Failures++
+		sj.stats.LastError = err.Error()
+	}
+	s.mu.Unlock()
+
+	s.recordJob(ctx, sj.job.Name, affected, duration, err)
+	return affected, err
+}
+
+// recordJob 导出任务执行指标并记录失败日志
+func (s *MaintenanceScheduler) recordJob(ctx context.Context, name string, affected int, duration time.Duration, err error) {
+	if err != nil && s.logger != nil {
+		s.logger.Warn("memory maintenance job failed", "job", name, "error", err)
+	}
+	if s.metrics == nil {
+		return
+	}
+
+	status := "success"
+	if err != nil {
+		status = "error"
+	}
+	jobAttr := otel.NewAttr(otel.AttrMemoryJob, name)
+	s.metrics.Counter(otel.MetricMemoryMaintenanceRuns).Add(ctx, 1, jobAttr, otel.NewAttr("status", status))
+	s.metrics.Histogram(otel.MetricMemoryMaintenanceDuration).Record(ctx, durationMillis(duration), jobAttr)
+	if affected > 0 {
+		s.metrics.Counter(otel.MetricMemoryMaintenanceAffected).Add(ctx, int64(affected), jobAttr)
+	}
+}
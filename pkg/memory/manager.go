@@ -6,6 +6,11 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"time"
+
+	"github.com/ahhsitt/helloagents-go/pkg/core/llm"
+	"github.com/ahhsitt/helloagents-go/pkg/otel"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // 管理器相关错误
@@ -32,14 +37,23 @@ type Memory interface {
 	// Remove 删除记忆
 	Remove(ctx context.Context, id string) error
 
+	// AddBatch 批量添加记忆项，返回与 items 顺序一致的 ID
+	AddBatch(ctx context.Context, items []*MemoryItem) ([]string, error)
+
+	// RemoveBatch 批量删除记忆
+	RemoveBatch(ctx context.Context, ids []string) error
+
+	// RetrieveMulti 批量检索记忆，返回与 queries 顺序一致的结果
+	RetrieveMulti(ctx context.Context, queries []string, opts ...RetrieveOption) ([][]*MemoryItem, error)
+
 	// Has 检查记忆是否存在
 	Has(ctx context.Context, id string) bool
 
 	// Clear 清空记忆
 	Clear(ctx context.Context) error
 
-	// GetStats 获取统计信息
-	GetStats(ctx context.Context) (*MemoryStats, error)
+	// GetStats 获取统计信息，可用 WithUserIDFilter/WithSessionIDFilter 限定范围
+	GetStats(ctx context.Context, opts ...RetrieveOption) (*MemoryStats, error)
 }
 
 // MemoryStats 记忆统计信息
@@ -54,6 +68,12 @@ type MemoryStats struct {
 	NewestTimestamp int64 `json:"newest_timestamp,omitempty"`
 	// AvgImportance 平均重要性
 	AvgImportance float32 `json:"avg_importance,omitempty"`
+	// AddedLast24h 最近 24 小时（StatsWindow）内新增的记忆数，按记忆时间戳计算
+	AddedLast24h int `json:"added_last_24h,omitempty"`
+	// ForgottenLast24h 最近 24 小时内被遗忘（Forget 或容量淘汰）的记忆数，限定统计范围时为 0
+	ForgottenLast24h int `json:"forgotten_last_24h,omitempty"`
+	// StorageBytes 估算的存储字节数（内容、元数据和本地向量）
+	StorageBytes int64 `json:"storage_bytes,omitempty"`
 }
 
 // RetrieveOption 检索选项
@@ -64,6 +84,8 @@ type retrieveOptions struct {
 	minScore   float32
 	memoryType MemoryType
 	userID     string
+	sessionID  string
+	asOf       time.Time
 }
 
 // WithLimit 设置返回数量限制
@@ -94,6 +116,16 @@ func WithUserIDFilter(userID string) RetrieveOption {
 	}
 }
 
+// WithAsOf 在指定时刻的记忆状态上检索
+//
+// 用于事后审计智能体在该时刻知道什么。目前只有情景记忆支持，其它记忆类型忽略该选项；
+// 情景记忆启用 WithEpisodicVersioning 时可还原之后被更新或删除的事件。
+func WithAsOf(t time.Time) RetrieveOption {
+	return func(o *retrieveOptions) {
+		o.asOf = t
+	}
+}
+
 // UpdateOption 更新选项
 type UpdateOption func(*updateOptions)
 
@@ -143,6 +175,7 @@ type forgetOptions struct {
 	threshold      float32
 	maxAgeDays     int
 	targetCapacity int
+	archive        *ArchiveStore
 }
 
 // WithThreshold 设置重要性阈值（低于此值的记忆将被遗忘）
@@ -173,7 +206,33 @@ type MemoryManager struct {
 	config      *MemoryConfig
 	userID      string
 	memoryTypes map[MemoryType]Memory
+	scorer      ImportanceScorer
+	extractor   EntityExtractor
+	normalizer  *QueryNormalizer
 	mu          sync.RWMutex
+
+	// 跨记忆类型排序
+	normalization ScoreNormalization
+	reranker      Reranker
+
+	// 冲突检测
+	conflicts      ConflictDetector
+	conflictPolicy ConflictPolicy
+
+	// 重要性衰减
+	decay     *DecayPolicy
+	lastDecay time.Time
+	decayMu   sync.Mutex
+
+	// 遗忘归档
+	archive *ArchiveStore
+
+	// 可观测性
+	tracer  otel.Tracer
+	metrics otel.Metrics
+
+	// 变更事件订阅
+	events eventBus
 }
 
 // ManagerOption 管理器配置选项
@@ -186,6 +245,48 @@ func WithManagerUserID(userID string) ManagerOption {
 	}
 }
 
+// WithImportanceScorer 设置重要性评分器
+//
+// AddMemory 未显式指定重要性时，使用该评分器估计重要性。
+// 未设置时使用默认的启发式规则。
+func WithImportanceScorer(scorer ImportanceScorer) ManagerOption {
+	return func(m *MemoryManager) {
+		m.scorer = scorer
+	}
+}
+
+// WithEntityExtractor 设置实体提取器
+//
+// 设置后 AddMemory 写入记忆后会从内容中提取实体和关系，
+// 并写入已注册的语义记忆（AddEntity/AddRelation），关系以该记忆 ID 作为证据。
+// 提取失败不影响记忆写入。
+func WithEntityExtractor(extractor EntityExtractor) ManagerOption {
+	return func(m *MemoryManager) {
+		m.extractor = extractor
+	}
+}
+
+// WithQueryNormalizer 设置查询规范化器
+//
+// 设置后 RetrieveMemories 在分发到各记忆类型前规范化查询，
+// AddMemory 写入的内容会加入规范化器的拼写纠正词表。
+func WithQueryNormalizer(normalizer *QueryNormalizer) ManagerOption {
+	return func(m *MemoryManager) {
+		m.normalizer = normalizer
+	}
+}
+
+// WithConflictDetection 设置冲突检测器和解决策略
+//
+// 设置后 AddMemory 写入语义记忆前，召回相近的已有记忆并检测是否相互矛盾，
+// 发现冲突时按 policy 处理（为空时使用 ConflictSupersede）。检测失败不影响记忆写入。
+func WithConflictDetection(detector ConflictDetector, policy ConflictPolicy) ManagerOption {
+	return func(m *MemoryManager) {
+		m.conflicts = detector
+		m.conflictPolicy = policy
+	}
+}
+
 // NewMemoryManager 创建记忆管理器
 func NewMemoryManager(config *MemoryConfig, opts ...ManagerOption) *MemoryManager {
 	if config == nil {
@@ -195,6 +296,7 @@ func NewMemoryManager(config *MemoryConfig, opts ...ManagerOption) *MemoryManage
 	m := &MemoryManager{
 		config:      config,
 		memoryTypes: make(map[MemoryType]Memory),
+		lastDecay:   time.Now(),
 	}
 
 	for _, opt := range opts {
@@ -247,9 +349,11 @@ func (m *MemoryManager) GetMemory(memType MemoryType) (Memory, error) {
 type AddMemoryOption func(*addMemoryOptions)
 
 type addMemoryOptions struct {
-	memoryType MemoryType
-	importance float32
-	metadata   map[string]interface{}
+	memoryType    MemoryType
+	importance    float32
+	importanceSet bool
+	metadata      map[string]interface{}
+	sessionID     string
 }
 
 // WithAddMemoryType 指定记忆类型
@@ -263,6 +367,7 @@ func WithAddMemoryType(t MemoryType) AddMemoryOption {
 func WithAddImportance(importance float32) AddMemoryOption {
 	return func(o *addMemoryOptions) {
 		o.importance = importance
+		o.importanceSet = true
 	}
 }
 
@@ -273,10 +378,31 @@ func WithAddMetadata(metadata map[string]interface{}) AddMemoryOption {
 	}
 }
 
+// WithAddSessionID 指定会话 ID（写入 metadata["session_id"]）
+func WithAddSessionID(sessionID string) AddMemoryOption {
+	return func(o *addMemoryOptions) {
+		o.sessionID = sessionID
+	}
+}
+
 // AddMemory 添加记忆
 //
 // 如果未指定记忆类型，将自动分类。
 func (m *MemoryManager) AddMemory(ctx context.Context, content string, opts ...AddMemoryOption) (string, error) {
+	ctx, span := m.startSpan(ctx, "memory."+memoryOpAdd)
+	start := time.Now()
+	id, memType, err := m.addMemory(ctx, content, opts...)
+	span.SetAttributes(otel.MemoryType(string(memType)))
+	m.recordOperation(ctx, memoryOpAdd, memType, start, err)
+	endSpan(span, err)
+	if err == nil {
+		m.publish(MemoryEvent{Type: EventMemoryAdded, MemoryType: memType, ID: id, Content: content, Count: 1})
+	}
+	return id, err
+}
+
+// addMemory 添加记忆，同时返回写入的记忆类型
+func (m *MemoryManager) addMemory(ctx context.Context, content string, opts ...AddMemoryOption) (string, MemoryType, error) {
 	options := &addMemoryOptions{
 		importance: 0.5,
 	}
@@ -292,16 +418,20 @@ func (m *MemoryManager) AddMemory(ctx context.Context, content string, opts ...A
 
 	// 如果未指定重要性，自动计算
 	importance := options.importance
-	if importance == 0.5 {
-		importance = m.calculateImportance(content, options.metadata)
+	if !options.importanceSet {
+		importance = m.scoreImportance(ctx, content, options.metadata)
 	}
 
 	// 创建记忆项
-	item := NewMemoryItem(content, memType,
+	itemOpts := []MemoryItemOption{
 		WithUserID(m.userID),
 		WithImportance(importance),
 		WithMetadata(options.metadata),
-	)
+	}
+	if options.sessionID != "" {
+		itemOpts = append(itemOpts, WithMetadataKV("session_id", options.sessionID))
+	}
+	item := NewMemoryItem(content, memType, itemOpts...)
 
 	// 获取对应的记忆存储
 	m.mu.RLock()
@@ -309,16 +439,49 @@ func (m *MemoryManager) AddMemory(ctx context.Context, content string, opts ...A
 	m.mu.RUnlock()
 
 	if !exists {
-		return "", ErrMemoryTypeNotFound
+		return "", memType, ErrMemoryTypeNotFound
 	}
 
-	return memory.Add(ctx, item)
+	var id string
+	var err error
+	if conflicts := m.detectConflicts(ctx, memory, item); len(conflicts) > 0 {
+		id, err = m.resolveConflicts(ctx, memory, item, conflicts)
+	} else {
+		id, err = memory.Add(ctx, item)
+	}
+	if err != nil {
+		return "", memType, err
+	}
+	if m.normalizer != nil {
+		m.normalizer.Add(content)
+	}
+
+	m.linkEntities(ctx, id, content)
+	return id, memType, nil
 }
 
 // RetrieveMemories 从所有记忆类型检索
 //
-// 返回按相关性排序的结果。
+// 返回按相关性排序的结果。设置了 WithManagerUserID 时默认只检索该用户的记忆，
+// 可通过 WithUserIDFilter 覆盖。
 func (m *MemoryManager) RetrieveMemories(ctx context.Context, query string, opts ...RetrieveOption) ([]*MemoryItem, error) {
+	ctx, span := m.startSpan(ctx, "memory."+memoryOpRetrieve)
+	start := time.Now()
+	items, err := m.retrieveMemories(ctx, query, opts...)
+	span.SetAttributes(attribute.Int(otel.AttrMemoryResults, len(items)))
+	m.recordOperation(ctx, memoryOpRetrieve, "", start, err)
+	endSpan(span, err)
+	return items, err
+}
+
+// retrieveMemories 从所有记忆类型检索
+func (m *MemoryManager) retrieveMemories(ctx context.Context, query string, opts ...RetrieveOption) ([]*MemoryItem, error) {
+	if m.normalizer != nil {
+		if normalized := m.normalizer.NormalizeText(query); normalized != "" {
+			query = normalized
+		}
+	}
+	opts = m.scopeOptions(opts)
 	options := &retrieveOptions{
 		limit: 10,
 	}
@@ -339,7 +502,14 @@ func (m *MemoryManager) RetrieveMemories(ctx context.Context, query string, opts
 		if !exists {
 			return nil, ErrMemoryTypeNotFound
 		}
-		return memory.Retrieve(ctx, query, opts...)
+		items, err := m.retrieveFrom(ctx, options.memoryType, memory, query, opts)
+		if err != nil {
+			return nil, err
+		}
+		normalizeScores(items, m.normalization)
+		items = m.rerank(ctx, query, items)
+		m.reinforce(ctx, items)
+		return items, nil
 	}
 
 	// 并行从所有记忆类型检索
@@ -350,11 +520,12 @@ func (m *MemoryManager) RetrieveMemories(ctx context.Context, query string, opts
 		errs    []error
 	)
 
-	for _, memory := range memories {
+	for memType, memory := range memories {
 		wg.Add(1)
-		go func(mem Memory) {
+		go func(memType MemoryType, mem Memory) {
 			defer wg.Done()
-			items, err := mem.Retrieve(ctx, query, opts...)
+			items, err := m.retrieveFrom(ctx, memType, mem, query, opts)
+			normalizeScores(items, m.normalization)
 			mu.Lock()
 			if err != nil {
 				errs = append(errs, err)
@@ -362,7 +533,7 @@ func (m *MemoryManager) RetrieveMemories(ctx context.Context, query string, opts
 				results = append(results, items...)
 			}
 			mu.Unlock()
-		}(memory)
+		}(memType, memory)
 	}
 
 	wg.Wait()
@@ -371,16 +542,23 @@ func (m *MemoryManager) RetrieveMemories(ctx context.Context, query string, opts
 		return nil, errs[0]
 	}
 
-	// 按重要性排序
-	sort.Slice(results, func(i, j int) bool {
-		return results[i].Importance > results[j].Importance
-	})
+	// 归一化后按分数排序，否则按重要性排序
+	if m.normalization != NormalizeNone {
+		sortByScore(results)
+	} else {
+		sort.Slice(results, func(i, j int) bool {
+			return results[i].Importance > results[j].Importance
+		})
+	}
+	results = m.rerank(ctx, query, results)
 
 	// 限制返回数量
 	if options.limit > 0 && len(results) > options.limit {
 		results = results[:options.limit]
 	}
 
+	m.reinforce(ctx, results)
+
 	return results, nil
 }
 
@@ -394,7 +572,11 @@ func (m *MemoryManager) UpdateMemory(ctx context.Context, memType MemoryType, id
 		return ErrMemoryTypeNotFound
 	}
 
-	return memory.Update(ctx, id, opts...)
+	if err := memory.Update(ctx, id, opts...); err != nil {
+		return err
+	}
+	m.publish(MemoryEvent{Type: EventMemoryUpdated, MemoryType: memType, ID: id, Count: 1})
+	return nil
 }
 
 // RemoveMemory 删除记忆
@@ -407,13 +589,25 @@ func (m *MemoryManager) RemoveMemory(ctx context.Context, memType MemoryType, id
 		return ErrMemoryTypeNotFound
 	}
 
-	return memory.Remove(ctx, id)
+	if err := memory.Remove(ctx, id); err != nil {
+		return err
+	}
+	m.publish(MemoryEvent{Type: EventMemoryRemoved, MemoryType: memType, ID: id, Count: 1})
+	return nil
 }
 
 // ForgetMemories 执行遗忘
 //
 // 根据策略从所有记忆类型中删除记忆。
 func (m *MemoryManager) ForgetMemories(ctx context.Context, strategy ForgetStrategy, opts ...ForgetOption) (int, error) {
+	ctx, span := m.startSpan(ctx, "memory."+memoryOpForget, attribute.String(otel.AttrMemoryStrategy, string(strategy)))
+	start := time.Now()
+	defer func() {
+		m.refreshSizes(ctx)
+		m.recordOperation(ctx, memoryOpForget, "", start, nil)
+		endSpan(span, nil)
+	}()
+
 	m.mu.RLock()
 	memories := make(map[MemoryType]Memory, len(m.memoryTypes))
 	for k, v := range m.memoryTypes {
@@ -421,19 +615,27 @@ func (m *MemoryManager) ForgetMemories(ctx context.Context, strategy ForgetStrat
 	}
 	m.mu.RUnlock()
 
+	if m.archive != nil {
+		opts = append([]ForgetOption{WithForgetArchive(m.archive)}, opts...)
+	}
+
 	totalForgotten := 0
 
-	for _, memory := range memories {
+	for memType, memory := range memories {
 		// 检查是否实现了 Forgetter 接口
 		if forgetter, ok := memory.(Forgetter); ok {
-			count, err := forgetter.Forget(ctx, strategy, opts...)
+			count, err := m.forgetFrom(ctx, memType, forgetter, strategy, opts)
 			if err != nil {
 				continue // 忽略单个记忆类型的错误
 			}
+			if count > 0 {
+				m.publish(MemoryEvent{Type: EventMemoryForgotten, MemoryType: memType, Strategy: strategy, Count: count})
+			}
 			totalForgotten += count
 		}
 	}
 
+	span.SetAttributes(attribute.Int(otel.AttrMemoryResults, totalForgotten))
 	return totalForgotten, nil
 }
 
@@ -446,9 +648,11 @@ type Forgetter interface {
 type ConsolidateOption func(*consolidateOptions)
 
 type consolidateOptions struct {
-	minImportance float32
-	maxAge        int
-	targetType    MemoryType
+	minImportance    float32
+	maxAge           int
+	targetType       MemoryType
+	summarizer       llm.Provider
+	clusterThreshold float32
 }
 
 // WithConsolidateMinImportance 设置最小重要性阈值
@@ -474,11 +678,28 @@ func WithConsolidateTargetType(t MemoryType) ConsolidateOption {
 
 // ConsolidateMemories 整合记忆
 //
-// 将高重要性的工作记忆转移到情景/语义记忆。
+// 将高重要性的工作记忆转移到情景/语义记忆，返回被整合的工作记忆数量。
+// 配置 WithConsolidateSummarizer 时，相关记忆会被摘要为一条记忆后再写入。
 func (m *MemoryManager) ConsolidateMemories(ctx context.Context, opts ...ConsolidateOption) (int, error) {
+	ctx, span := m.startSpan(ctx, "memory."+memoryOpConsolidate)
+	start := time.Now()
+	consolidated, err := m.consolidateMemories(ctx, opts...)
+	span.SetAttributes(attribute.Int(otel.AttrMemoryResults, consolidated))
+	if m.metrics != nil && consolidated > 0 {
+		m.metrics.Counter(otel.MetricMemoryConsolidated).Add(ctx, int64(consolidated))
+	}
+	m.recordOperation(ctx, memoryOpConsolidate, "", start, err)
+	m.refreshSizes(ctx)
+	endSpan(span, err)
+	return consolidated, err
+}
+
+// consolidateMemories 整合记忆，返回被整合的工作记忆数量
+func (m *MemoryManager) consolidateMemories(ctx context.Context, opts ...ConsolidateOption) (int, error) {
 	options := &consolidateOptions{
-		minImportance: 0.7,                // 默认只整合重要性 >= 0.7 的记忆
-		targetType:    MemoryTypeEpisodic, // 默认整合到情景记忆
+		minImportance:    0.7,                // 默认只整合重要性 >= 0.7 的记忆
+		targetType:       MemoryTypeEpisodic, // 默认整合到情景记忆
+		clusterThreshold: DefaultConsolidateClusterThreshold,
 	}
 	for _, opt := range opts {
 		opt(options)
@@ -502,7 +723,7 @@ func (m *MemoryManager) ConsolidateMemories(ctx context.Context, opts ...Consoli
 		return 0, err
 	}
 
-	consolidated := 0
+	var candidates []*MemoryItem
 	for _, item := range items {
 		// 检查重要性阈值
 		if item.Importance < options.minImportance {
@@ -514,56 +735,173 @@ func (m *MemoryManager) ConsolidateMemories(ctx context.Context, opts ...Consoli
 			continue
 		}
 
-		// 创建新的记忆项（转换类型）
-		newItem := item.Clone()
-		newItem.MemoryType = options.targetType
+		candidates = append(candidates, item)
+	}
+
+	if options.summarizer == nil {
+		consolidated := 0
+		for _, item := range candidates {
+			if moveMemory(ctx, workingMem, targetMem, item, options.targetType) {
+				consolidated++
+			}
+		}
+		m.publishConsolidated(options.targetType, consolidated)
+		return consolidated, nil
+	}
+
+	consolidated := 0
+	for _, cluster := range clusterItems(candidates, options.clusterThreshold) {
+		if len(cluster) == 1 {
+			if moveMemory(ctx, workingMem, targetMem, cluster[0], options.targetType) {
+				consolidated++
+			}
+			continue
+		}
 
-		// 添加到目标记忆
-		_, err := targetMem.Add(ctx, newItem)
+		summary, err := summarizeCluster(ctx, options.summarizer, cluster, options.targetType)
 		if err != nil {
 			continue
 		}
+		if _, err := targetMem.Add(ctx, summary); err != nil {
+			continue
+		}
 
-		// 从工作记忆中删除
-		if err := workingMem.Remove(ctx, item.ID); err != nil {
+		ids := make([]string, len(cluster))
+		for i, item := range cluster {
+			ids[i] = item.ID
+		}
+		if err := workingMem.RemoveBatch(ctx, ids); err != nil {
 			continue
 		}
 
-		consolidated++
+		consolidated += len(cluster)
 	}
 
+	m.publishConsolidated(options.targetType, consolidated)
 	return consolidated, nil
 }
 
+// moveMemory 将记忆按原文转移到目标记忆
+func moveMemory(ctx context.Context, from, to Memory, item *MemoryItem, targetType MemoryType) bool {
+	// 创建新的记忆项（转换类型）
+	newItem := item.Clone()
+	newItem.MemoryType = targetType
+
+	// 添加到目标记忆
+	if _, err := to.Add(ctx, newItem); err != nil {
+		return false
+	}
+
+	// 从工作记忆中删除
+	return from.Remove(ctx, item.ID) == nil
+}
+
 // ManagerStats 管理器统计信息
 type ManagerStats struct {
 	// TotalCount 总记忆数
 	TotalCount int `json:"total_count"`
-	// ByType 按类型统计
+	// ByType 按类型统计，各类型的 token 数见 MemoryStats.TotalTokens
 	ByType map[MemoryType]*MemoryStats `json:"by_type"`
+	// TotalTokens 估算的总 token 数
+	TotalTokens int `json:"total_tokens"`
+	// AddedLast24h 最近 24 小时内新增的记忆数
+	AddedLast24h int `json:"added_last_24h"`
+	// ForgottenLast24h 最近 24 小时内被遗忘的记忆数
+	ForgottenLast24h int `json:"forgotten_last_24h"`
+	// StorageBytes 估算的总存储字节数
+	StorageBytes int64 `json:"storage_bytes"`
+	// SnapshotAt 统计快照的时间
+	SnapshotAt time.Time `json:"snapshot_at"`
+	// Consistent 各类型统计是否来自同一时刻的快照
+	Consistent bool `json:"consistent"`
+}
+
+// scopeOptions 在设置了管理器用户 ID 时为检索选项补充默认的用户过滤
+//
+// 默认过滤放在最前，调用方传入的 WithUserIDFilter 可以覆盖。
+func (m *MemoryManager) scopeOptions(opts []RetrieveOption) []RetrieveOption {
+	if m.userID == "" {
+		return opts
+	}
+	return append([]RetrieveOption{WithUserIDFilter(m.userID)}, opts...)
 }
 
 // GetStats 获取统计信息
-func (m *MemoryManager) GetStats(ctx context.Context) (*ManagerStats, error) {
-	m.mu.RLock()
-	memories := make(map[MemoryType]Memory, len(m.memoryTypes))
-	for k, v := range m.memoryTypes {
-		memories[k] = v
+//
+// opts 可用 WithUserIDFilter/WithSessionIDFilter 限定统计范围。
+// 内置记忆类型在同时持有各自读锁的情况下统计，结果是同一时刻的一致快照；
+// 存在未实现快照的自定义记忆类型时，这些类型单独统计，Consistent 为 false。
+func (m *MemoryManager) GetStats(ctx context.Context, opts ...RetrieveOption) (*ManagerStats, error) {
+	memories := m.snapshotMemories()
+	types := make([]MemoryType, 0, len(memories))
+	for memType := range memories {
+		types = append(types, memType)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+
+	options := &retrieveOptions{}
+	for _, opt := range opts {
+		opt(options)
 	}
-	m.mu.RUnlock()
 
 	stats := &ManagerStats{
-		ByType: make(map[MemoryType]*MemoryStats),
+		ByType:     make(map[MemoryType]*MemoryStats),
+		Consistent: true,
 	}
 
-	for memType, memory := range memories {
-		memStats, err := memory.GetStats(ctx)
+	// 加锁前完成懒加载和索引重建，加载失败的类型与之前一样跳过
+	snapshotters := make(map[MemoryType]statsSnapshotter)
+	var others []MemoryType
+	for _, memType := range types {
+		s, ok := memories[memType].(statsSnapshotter)
+		if !ok {
+			others = append(others, memType)
+			continue
+		}
+		if err := s.prepareStats(ctx, options); err != nil {
+			continue
+		}
+		snapshotters[memType] = s
+	}
+
+	// 按类型名称顺序加读锁，同一存储注册为多个类型时只加一次
+	var lockers []sync.Locker
+	held := make(map[sync.Locker]bool)
+	for _, memType := range types {
+		if s, ok := snapshotters[memType]; ok {
+			locker := s.statsLocker()
+			if !held[locker] {
+				held[locker] = true
+				locker.Lock()
+				lockers = append(lockers, locker)
+			}
+		}
+	}
+	stats.SnapshotAt = time.Now()
+	for memType, s := range snapshotters {
+		stats.ByType[memType] = s.statsLocked(options, stats.SnapshotAt)
+	}
+	for i := len(lockers) - 1; i >= 0; i-- {
+		lockers[i].Unlock()
+	}
+
+	for _, memType := range others {
+		memStats, err := memories[memType].GetStats(ctx, opts...)
 		if err != nil {
 			continue
 		}
 		stats.ByType[memType] = memStats
+		stats.Consistent = false
+	}
+
+	for _, memStats := range stats.ByType {
 		stats.TotalCount += memStats.Count
+		stats.TotalTokens += memStats.TotalTokens
+		stats.AddedLast24h += memStats.AddedLast24h
+		stats.ForgottenLast24h += memStats.ForgottenLast24h
+		stats.StorageBytes += memStats.StorageBytes
 	}
+	m.recordSizes(ctx, stats)
 
 	return stats, nil
 }
@@ -604,80 +942,82 @@ func (m *MemoryManager) classifyMemoryType(content string) MemoryType {
 	return MemoryTypeWorking
 }
 
-// calculateImportance 计算重要性
+// scoreImportance 使用配置的评分器计算重要性
 //
-// 基于内容长度、关键词、元数据计算。
-func (m *MemoryManager) calculateImportance(content string, metadata map[string]interface{}) float32 {
-	var importance float32 = 0.5
-
-	// 基于内容长度
-	length := len(content)
-	if length < 20 {
-		importance -= 0.1
-	} else if length > 500 {
-		importance += 0.2
-	} else if length > 200 {
-		importance += 0.1
+// 评分器出错时回退到默认启发式规则。
+func (m *MemoryManager) scoreImportance(ctx context.Context, content string, metadata map[string]interface{}) float32 {
+	if m.scorer != nil {
+		if importance, err := m.scorer.Score(ctx, content, metadata); err == nil {
+			return clampImportance(importance)
+		}
 	}
+	return m.calculateImportance(content, metadata)
+}
 
-	// 基于关键词
-	contentLower := strings.ToLower(content)
-	highImportanceKeywords := []string{
-		"important", "critical", "urgent", "remember", "don't forget",
-		"key", "essential", "must", "should",
-		"重要", "关键", "紧急", "记住", "别忘了",
-		"必须", "应该", "一定",
-	}
-	for _, kw := range highImportanceKeywords {
-		if strings.Contains(contentLower, kw) {
-			importance += 0.15
-			break
-		}
+// entityGraph 可写入实体和关系的记忆存储（如 SemanticMemoryStore）
+type entityGraph interface {
+	AddEntity(ctx context.Context, entity *Entity) error
+	GetEntityByName(ctx context.Context, name string) (*Entity, error)
+	AddRelation(ctx context.Context, relation *Relation) error
+}
+
+// linkEntities 提取内容中的实体和关系并写入语义记忆（尽力而为，错误被忽略）
+func (m *MemoryManager) linkEntities(ctx context.Context, memoryID, content string) {
+	if m.extractor == nil {
+		return
 	}
 
-	lowImportanceKeywords := []string{
-		"maybe", "perhaps", "might", "possibly", "trivial",
-		"也许", "可能", "或许", "无关紧要",
+	m.mu.RLock()
+	graph, ok := m.memoryTypes[MemoryTypeSemantic].(entityGraph)
+	m.mu.RUnlock()
+	if !ok {
+		return
 	}
-	for _, kw := range lowImportanceKeywords {
-		if strings.Contains(contentLower, kw) {
-			importance -= 0.1
-			break
-		}
+
+	result, err := m.extractor.Extract(ctx, content)
+	if err != nil || result == nil {
+		return
 	}
 
-	// 基于元数据
-	if metadata != nil {
-		// 如果明确指定了重要性
-		if imp, ok := metadata["importance"].(float32); ok {
-			return imp
-		}
-		if imp, ok := metadata["importance"].(float64); ok {
-			return float32(imp)
+	ids := make(map[string]string, len(result.Entities))
+	for _, extracted := range result.Entities {
+		entity := NewEntity(extracted.Name, extracted.Type)
+		entity.SetProperty("confidence", extracted.Confidence)
+		if err := graph.AddEntity(ctx, entity); err != nil {
+			continue
 		}
-
-		// 如果有特殊标记
-		if priority, ok := metadata["priority"].(string); ok {
-			switch strings.ToLower(priority) {
-			case "high":
-				importance += 0.2
-			case "low":
-				importance -= 0.2
-			}
+		// 同名实体已存在时 AddEntity 只增加频率，以存储中的实体为准
+		if stored, err := graph.GetEntityByName(ctx, extracted.Name); err == nil {
+			ids[strings.ToLower(extracted.Name)] = stored.ID
 		}
 	}
 
-	// 确保在有效范围内
-	if importance < 0 {
-		importance = 0
-	}
-	if importance > 1 {
-		importance = 1
+	for _, extracted := range result.Relations {
+		fromID, fromOK := ids[strings.ToLower(extracted.FromEntity)]
+		toID, toOK := ids[strings.ToLower(extracted.ToEntity)]
+		if !fromOK || !toOK || fromID == toID {
+			continue
+		}
+		relation := NewRelation(fromID, toID, extracted.RelationType)
+		relation.UpdateStrength(extracted.Confidence)
+		relation.AddEvidence(memoryID)
+		if extracted.Context != "" {
+			relation.SetProperty("context", extracted.Context)
+		}
+		_ = graph.AddRelation(ctx, relation)
 	}
+}
 
-	return importance
+// calculateImportance 计算重要性
+//
+// 基于默认启发式规则（内容长度、关键词、偏好/身份信号、实体、元数据）计算。
+func (m *MemoryManager) calculateImportance(content string, metadata map[string]interface{}) float32 {
+	return defaultImportanceScorer.score(content, metadata)
 }
 
+// defaultImportanceScorer 默认启发式评分器
+var defaultImportanceScorer = NewHeuristicImportanceScorer()
+
 // Config 返回配置
 func (m *MemoryManager) Config() *MemoryConfig {
 	return m.config
@@ -2,7 +2,9 @@ package memory
 
 import (
 	"context"
+	"math"
 	"testing"
+	"time"
 )
 
 // mockMemory is a mock implementation of the Memory interface for testing
@@ -54,6 +56,31 @@ func (m *mockMemory) Remove(ctx context.Context, id string) error {
 	return nil
 }
 
+func (m *mockMemory) AddBatch(ctx context.Context, items []*MemoryItem) ([]string, error) {
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		id, err := m.Add(ctx, item)
+		if err != nil {
+			return ids, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func (m *mockMemory) RemoveBatch(ctx context.Context, ids []string) error {
+	for _, id := range ids {
+		if err := m.Remove(ctx, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *mockMemory) RetrieveMulti(ctx context.Context, queries []string, opts ...RetrieveOption) ([][]*MemoryItem, error) {
+	return retrieveEach(ctx, m, queries, opts...)
+}
+
 func (m *mockMemory) Has(ctx context.Context, id string) bool {
 	_, exists := m.items[id]
 	return exists
@@ -64,7 +91,7 @@ func (m *mockMemory) Clear(ctx context.Context) error {
 	return nil
 }
 
-func (m *mockMemory) GetStats(ctx context.Context) (*MemoryStats, error) {
+func (m *mockMemory) GetStats(ctx context.Context, opts ...RetrieveOption) (*MemoryStats, error) {
 	return &MemoryStats{
 		Count: len(m.items),
 	}, nil
@@ -533,3 +560,71 @@ func TestUpdateOptions(t *testing.T) {
 		t.Errorf("expected metadata key=value, got %v", opts.metadata)
 	}
 }
+
+func TestMemoryManager_DecayAndReinforce(t *testing.T) {
+	ctx := context.Background()
+	manager := NewMemoryManager(nil, WithDecayPolicy(DecayPolicy{
+		HalfLife:        2 * time.Hour,
+		ReinforceBoost:  0.5,
+		ForgetThreshold: 0.42,
+	}))
+	working := NewWorkingMemory()
+	if err := manager.RegisterMemory(MemoryTypeWorking, working); err != nil {
+		t.Fatalf("RegisterMemory() error = %v", err)
+	}
+
+	alphaID, _ := manager.AddMemory(ctx, "alpha project deadline", WithAddMemoryType(MemoryTypeWorking), WithAddImportance(0.8))
+	betaID, _ := manager.AddMemory(ctx, "beta lunch menu", WithAddMemoryType(MemoryTypeWorking), WithAddImportance(0.8))
+
+	// 检索命中强化：0.8 + 0.5 × 0.2 = 0.9
+	items, err := manager.RetrieveMemories(ctx, "alpha", WithMemoryTypeFilter(MemoryTypeWorking), WithLimit(1))
+	if err != nil || len(items) != 1 || items[0].ID != alphaID {
+		t.Fatalf("RetrieveMemories() = %v, %v", items, err)
+	}
+	if math.Abs(float64(items[0].Importance)-0.9) > 1e-4 {
+		t.Errorf("expected reinforced importance 0.9, got %f", items[0].Importance)
+	}
+
+	// 经过一个半衰期：alpha 0.45，beta 0.4 低于遗忘阈值
+	decayed, forgotten, err := manager.tickAt(ctx, manager.lastDecay.Add(2*time.Hour))
+	if err != nil {
+		t.Fatalf("tickAt() error = %v", err)
+	}
+	if decayed != 2 || forgotten != 1 {
+		t.Errorf("expected 2 decayed and 1 forgotten, got %d and %d", decayed, forgotten)
+	}
+	if !working.Has(ctx, alphaID) || working.Has(ctx, betaID) {
+		t.Error("expected reinforced memory to survive and stale memory to be forgotten")
+	}
+}
+
+func TestMemoryManager_ReinforceSkipsVersions(t *testing.T) {
+	ctx := context.Background()
+	manager := NewMemoryManager(nil, WithDecayPolicy(DecayPolicy{ReinforceBoost: 0.5}))
+	episodic := NewEpisodicMemory(WithEpisodicVersioning())
+	if err := manager.RegisterMemory(MemoryTypeEpisodic, episodic); err != nil {
+		t.Fatalf("RegisterMemory() error = %v", err)
+	}
+	_ = episodic.AddEpisode(ctx, Episode{ID: "e1", Content: "alpha release shipped", Importance: 0.6})
+
+	// 多次命中只修改重要性，不产生新版本
+	for i := 0; i < 3; i++ {
+		if items, err := manager.RetrieveMemories(ctx, "alpha", WithMemoryTypeFilter(MemoryTypeEpisodic)); err != nil || len(items) != 1 {
+			t.Fatalf("RetrieveMemories() = %v, %v", items, err)
+		}
+	}
+	episodes, _ := episodic.GetMostImportant(ctx, 1)
+	if len(episodes) != 1 || math.Abs(float64(episodes[0].Importance)-0.95) > 1e-4 {
+		t.Errorf("expected reinforced importance 0.95, got %+v", episodes)
+	}
+	if versions, _ := episodic.GetVersions(ctx, "e1"); len(versions) != 1 {
+		t.Errorf("reinforcement created %d versions, want 1", len(versions))
+	}
+}
+
+func TestMemoryManager_DecayWithoutPolicy(t *testing.T) {
+	manager := NewMemoryManager(nil)
+	if n, err := manager.Decay(context.Background()); n != 0 || err != nil {
+		t.Errorf("Decay() without policy = %d, %v", n, err)
+	}
+}
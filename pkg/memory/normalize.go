@@ -0,0 +1,265 @@
+package memory
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// 查询规范化默认参数
+const (
+	// DefaultSpellMaxDistance 拼写纠正允许的最大编辑距离
+	DefaultSpellMaxDistance = 2
+	// DefaultSpellMinWordLength 参与拼写纠正的最短单词长度，更短的词误纠率过高
+	DefaultSpellMinWordLength = 4
+)
+
+// QueryNormalizer 查询规范化器
+//
+// 在评分、检索和记忆搜索之前统一处理查询：Unicode NFKC 规范化（全角转半角等）、
+// 大小写折叠、标点清理（词内的 - _ . ' 保留），以及基于语料词表的简单拼写纠正。
+// 拼写纠正只作用于词表中不存在的拉丁字母单词，替换为编辑距离最小、词频最高的词表单词。
+//
+// 词表通过 Fit 或 Add 从语料构建，未构建词表时只做规范化不做纠正。
+// QueryNormalizer 是并发安全的。
+type QueryNormalizer struct {
+	caseFold      bool
+	maxDistance   int
+	minWordLength int
+
+	mu         sync.RWMutex
+	vocabulary map[string]int // 词 -> 词频
+}
+
+// NormalizedQuery 规范化结果
+type NormalizedQuery struct {
+	// Original 原始查询，用于展示
+	Original string `json:"original"`
+	// Text 规范化后的查询，用于检索和评分
+	Text string `json:"text"`
+	// Corrections 拼写纠正记录（原词 -> 纠正后的词）
+	Corrections map[string]string `json:"corrections,omitempty"`
+}
+
+// QueryNormalizerOption 查询规范化器选项
+type QueryNormalizerOption func(*QueryNormalizer)
+
+// WithCaseFolding 设置是否将查询转为小写，默认开启
+func WithCaseFolding(enabled bool) QueryNormalizerOption {
+	return func(n *QueryNormalizer) {
+		n.caseFold = enabled
+	}
+}
+
+// WithSpellCorrection 设置拼写纠正的最大编辑距离和最短单词长度，maxDistance <= 0 时关闭纠正
+func WithSpellCorrection(maxDistance, minWordLength int) QueryNormalizerOption {
+	return func(n *QueryNormalizer) {
+		n.maxDistance = maxDistance
+		n.minWordLength = minWordLength
+	}
+}
+
+// NewQueryNormalizer 创建查询规范化器
+func NewQueryNormalizer(opts ...QueryNormalizerOption) *QueryNormalizer {
+	n := &QueryNormalizer{
+		caseFold:      true,
+		maxDistance:   DefaultSpellMaxDistance,
+		minWordLength: DefaultSpellMinWordLength,
+		vocabulary:    make(map[string]int),
+	}
+	for _, opt := range opts {
+		opt(n)
+	}
+	return n
+}
+
+// Fit 用语料重建词表
+func (n *QueryNormalizer) Fit(documents []string) {
+	vocabulary := make(map[string]int)
+	var tokens []string
+	for _, doc := range documents {
+		tokens = appendTokens(tokens[:0], norm.NFKC.String(doc))
+		for _, token := range tokens {
+			vocabulary[token]++
+		}
+	}
+
+	n.mu.Lock()
+	n.vocabulary = vocabulary
+	n.mu.Unlock()
+}
+
+// Add 将文本中的词加入词表
+func (n *QueryNormalizer) Add(text string) {
+	tokens := appendTokens(nil, norm.NFKC.String(text))
+
+	n.mu.Lock()
+	for _, token := range tokens {
+		n.vocabulary[token]++
+	}
+	n.mu.Unlock()
+}
+
+// NormalizeText 返回规范化后的查询文本
+func (n *QueryNormalizer) NormalizeText(query string) string {
+	return n.Normalize(query).Text
+}
+
+// Normalize 规范化查询，保留原始查询
+func (n *QueryNormalizer) Normalize(query string) NormalizedQuery {
+	result := NormalizedQuery{Original: query}
+
+	text := norm.NFKC.String(query)
+	if n.caseFold {
+		text = strings.ToLower(text)
+	}
+	words := strings.Fields(cleanPunctuation(text))
+
+	if n.maxDistance > 0 {
+		n.mu.RLock()
+		for i, word := range words {
+			corrected, ok := n.correctLocked(word)
+			if !ok {
+				continue
+			}
+			if result.Corrections == nil {
+				result.Corrections = make(map[string]string)
+			}
+			result.Corrections[word] = corrected
+			words[i] = corrected
+		}
+		n.mu.RUnlock()
+	}
+
+	result.Text = strings.Join(words, " ")
+	return result
+}
+
+// correctLocked 返回 word 在词表中的纠正结果（调用方需持有读锁）
+func (n *QueryNormalizer) correctLocked(word string) (string, bool) {
+	if len(n.vocabulary) == 0 || len([]rune(word)) < n.minWordLength || !isLatinWord(word) {
+		return "", false
+	}
+	// 词表为小写，大小写不同的已知词同样不纠正
+	word = strings.ToLower(word)
+	if _, ok := n.vocabulary[word]; ok {
+		return "", false
+	}
+
+	type candidate struct {
+		term     string
+		distance int
+		freq     int
+	}
+	var candidates []candidate
+	for term, freq := range n.vocabulary {
+		if d := boundedEditDistance(word, term, n.maxDistance); d >= 0 {
+			candidates = append(candidates, candidate{term: term, distance: d, freq: freq})
+		}
+	}
+	if len(candidates) == 0 {
+		return "", false
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].distance != candidates[j].distance {
+			return candidates[i].distance < candidates[j].distance
+		}
+		if candidates[i].freq != candidates[j].freq {
+			return candidates[i].freq > candidates[j].freq
+		}
+		return candidates[i].term < candidates[j].term
+	})
+	return candidates[0].term, true
+}
+
+// cleanPunctuation 将标点和符号替换为空格，保留字母数字之间的 - _ . '
+func cleanPunctuation(text string) string {
+	runes := []rune(text)
+	var b strings.Builder
+	b.Grow(len(text))
+	for i, r := range runes {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsNumber(r) || unicode.IsMark(r):
+			b.WriteRune(r)
+		case strings.ContainsRune("-_.'", r) && i > 0 && i < len(runes)-1 &&
+			isWordRune(runes[i-1]) && isWordRune(runes[i+1]):
+			b.WriteRune(r)
+		default:
+			b.WriteByte(' ')
+		}
+	}
+	return b.String()
+}
+
+// isWordRune 判断字符是否为字母或数字
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsNumber(r)
+}
+
+// isLatinWord 判断是否为纯拉丁字母单词
+func isLatinWord(word string) bool {
+	for _, r := range word {
+		if !unicode.Is(unicode.Latin, r) {
+			return false
+		}
+	}
+	return word != ""
+}
+
+// boundedEditDistance 计算 a 和 b 的编辑距离，超过 limit 时返回 -1
+func boundedEditDistance(a, b string, limit int) int {
+	ra, rb := []rune(a), []rune(b)
+	if d := len(ra) - len(rb); d > limit || -d > limit {
+		return -1
+	}
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		rowMin := curr[0]
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			rowMin = min(rowMin, curr[j])
+		}
+		if rowMin > limit {
+			return -1
+		}
+		prev, curr = curr, prev
+	}
+	if prev[len(rb)] > limit {
+		return -1
+	}
+	return prev[len(rb)]
+}
+
+// WithSemanticQueryNormalizer 在检索前规范化查询
+//
+// 规范化器的词表在 TF-IDF 索引重建时用已存储的内容重新构建，
+// 向量、BM25、TF-IDF 和关键词检索使用同一个规范化后的查询。
+func WithSemanticQueryNormalizer(normalizer *QueryNormalizer) SemanticMemoryOption {
+	return func(m *SemanticMemoryStore) {
+		m.normalizer = normalizer
+	}
+}
+
+// normalizeQuery 返回规范化后的查询，未配置规范化器或结果为空时返回原查询
+func (m *SemanticMemoryStore) normalizeQuery(query string) string {
+	if m.normalizer == nil {
+		return query
+	}
+	if normalized := m.normalizer.NormalizeText(query); normalized != "" {
+		return normalized
+	}
+	return query
+}
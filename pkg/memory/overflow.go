@@ -0,0 +1,141 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ahhsitt/helloagents-go/pkg/core/llm"
+	"github.com/ahhsitt/helloagents-go/pkg/core/message"
+	"github.com/ahhsitt/helloagents-go/pkg/memory/store"
+)
+
+// overflowSummaryID 滚动摘要在持久化存储中的文档 ID
+const overflowSummaryID = "__overflow_summary__"
+
+// overflowSummaryPrefix 滚动摘要消息的内容前缀
+const overflowSummaryPrefix = "早期对话摘要：\n"
+
+// overflowSummaryPrompt 溢出摘要提示词
+const overflowSummaryPrompt = `以下是一段对话的已有摘要，以及随后因超出容量被移出工作记忆的对话轮次。
+请将它们合并为一份新的摘要。要求：保留关键事实、用户偏好、决定和未完成的事项，
+去除寒暄和重复内容，只输出摘要内容。
+
+已有摘要：
+%s
+
+移出的对话：
+%s`
+
+// WithOverflowSummarizer 使用 LLM 将超出 maxSize 的消息压缩为滚动摘要
+//
+// 设置后，LRU 淘汰的消息不再直接丢弃，而是与已有摘要一起由 LLM 合并为新的摘要。
+// 摘要以系统消息的形式保留在记忆中（不占用 maxSize），GetHistory 返回完整历史时和
+// GetMessagesWithinTokenLimit 都会将其放在最前面。摘要失败时被淘汰的消息暂存，
+// 在下次溢出时一并重试。
+func WithOverflowSummarizer(provider llm.Provider) WorkingMemoryOption {
+	return func(m *WorkingMemory) {
+		m.summarizer = provider
+	}
+}
+
+// GetOverflowSummary 返回当前的滚动摘要，没有摘要时返回空字符串
+func (m *WorkingMemory) GetOverflowSummary(ctx context.Context) (string, error) {
+	if err := m.ensureLoaded(ctx); err != nil {
+		return "", err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.summary, nil
+}
+
+// summaryMessageLocked 返回滚动摘要消息（调用方需持有读锁）
+func (m *WorkingMemory) summaryMessageLocked() (message.Message, bool) {
+	if m.summary == "" {
+		return message.Message{}, false
+	}
+	msg := message.NewSystemMessage(overflowSummaryPrefix + m.summary)
+	msg.ID = overflowSummaryID
+	msg.Timestamp = m.summaryAt
+	return msg, true
+}
+
+// queueOverflowLocked 暂存被淘汰的消息等待摘要（调用方需持有写锁）
+func (m *WorkingMemory) queueOverflowLocked(evicted []workingMessage) {
+	if m.summarizer == nil || len(evicted) == 0 {
+		return
+	}
+	m.overflow = append(m.overflow, evicted...)
+}
+
+// summarizeOverflow 将暂存的淘汰消息合并进滚动摘要，只返回持久化错误
+//
+// LLM 调用期间不持有 m.mu，summaryMu 保证同一时间只有一次合并。
+func (m *WorkingMemory) summarizeOverflow(ctx context.Context) error {
+	if m.summarizer == nil {
+		return nil
+	}
+
+	m.summaryMu.Lock()
+	defer m.summaryMu.Unlock()
+
+	m.mu.Lock()
+	pending := m.overflow
+	m.overflow = nil
+	previous := m.summary
+	m.mu.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	summary, err := m.mergeSummary(ctx, previous, pending)
+	if err != nil {
+		// 摘要失败不影响写入：放回暂存区，下次溢出时重试
+		m.mu.Lock()
+		m.overflow = append(pending, m.overflow...)
+		m.mu.Unlock()
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.summary = summary
+	m.summaryAt = time.Now()
+	if m.persist != nil {
+		return m.persist.put(ctx, store.Document{
+			ID:        overflowSummaryID,
+			Content:   summary,
+			Metadata:  map[string]interface{}{"role": string(message.RoleSystem)},
+			CreatedAt: m.summaryAt,
+		})
+	}
+	return nil
+}
+
+// mergeSummary 调用 LLM 将已有摘要和淘汰的消息合并为新摘要
+func (m *WorkingMemory) mergeSummary(ctx context.Context, previous string, evicted []workingMessage) (string, error) {
+	var turns strings.Builder
+	for _, wm := range evicted {
+		fmt.Fprintf(&turns, "%s: %s\n", wm.Message.Role, wm.indexText())
+	}
+	if previous == "" {
+		previous = "（无）"
+	}
+
+	resp, err := m.summarizer.Generate(ctx, llm.Request{
+		Messages: []message.Message{
+			message.NewUserMessage(fmt.Sprintf(overflowSummaryPrompt, previous, turns.String())),
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("overflow summarizer: %w", err)
+	}
+	summary := strings.TrimSpace(resp.Content)
+	if summary == "" {
+		return "", fmt.Errorf("overflow summarizer: empty summary")
+	}
+	return summary, nil
+}
@@ -0,0 +1,359 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/ahhsitt/helloagents-go/pkg/core/message"
+	"github.com/ahhsitt/helloagents-go/pkg/memory/store"
+)
+
+// 持久化集合的默认名称
+const (
+	// DefaultWorkingCollection 工作记忆默认集合
+	DefaultWorkingCollection = "working_memory"
+	// DefaultEpisodicCollection 情景记忆默认集合
+	DefaultEpisodicCollection = "episodic_memory"
+)
+
+// persistence 基于 DocumentStore 的持久化
+//
+// 首次访问时从存储懒加载全部记录，之后的写操作同步写入存储（write-through）。
+// 设置了 reloadInterval 时，距上次加载超过该间隔的访问会重新加载，用于多个副本共享同一存储。
+type persistence struct {
+	store          store.DocumentStore
+	collection     string
+	ttl            time.Duration // 记录过期时间，存储支持时映射为文档过期
+	reloadInterval time.Duration // 重新加载间隔，0 表示只加载一次
+	loaded         bool
+	loadedAt       time.Time
+	mu             sync.Mutex
+}
+
+// newPersistence 创建持久化
+func newPersistence(docStore store.DocumentStore, collection, defaultCollection string) *persistence {
+	if collection == "" {
+		collection = defaultCollection
+	}
+	return &persistence{
+		store:      docStore,
+		collection: collection,
+	}
+}
+
+// ensureLoaded 确保已从存储加载（加载失败时下次访问会重试）
+//
+// 调用 load 时持有 p.mu，load 可通过 p.loaded 区分首次加载和重新加载。
+func (p *persistence) ensureLoaded(load func() error) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.loaded && (p.reloadInterval <= 0 || time.Since(p.loadedAt) < p.reloadInterval) {
+		return nil
+	}
+	if err := load(); err != nil {
+		return fmt.Errorf("load persisted memory: %w", err)
+	}
+	p.loaded = true
+	p.loadedAt = time.Now()
+	return nil
+}
+
+// loadAll 按创建时间加载集合中的所有文档
+func (p *persistence) loadAll(ctx context.Context) ([]store.Document, error) {
+	docs, err := p.store.Query(ctx, p.collection, store.Filter{},
+		store.WithQueryLimit(0),
+		store.WithQueryOrderBy("created_at", false),
+	)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(docs, func(i, j int) bool {
+		return docs[i].CreatedAt.Before(docs[j].CreatedAt)
+	})
+	return docs, nil
+}
+
+// put 写入文档
+//
+// 设置了 ttl 且存储实现 store.ExpiringDocumentStore 时，文档在创建时间 + ttl 后由存储过期，
+// 已经过期的文档不再写入。
+func (p *persistence) put(ctx context.Context, doc store.Document) error {
+	var err error
+	if expiring, ok := p.store.(store.ExpiringDocumentStore); ok && p.ttl > 0 {
+		remaining := p.ttl - time.Since(doc.CreatedAt)
+		if remaining <= 0 {
+			return nil
+		}
+		err = expiring.PutWithTTL(ctx, p.collection, doc.ID, doc, remaining)
+	} else {
+		err = p.store.Put(ctx, p.collection, doc.ID, doc)
+	}
+	if err != nil {
+		return fmt.Errorf("persist memory %s: %w", doc.ID, err)
+	}
+	return nil
+}
+
+// delete 删除文档（文档不存在时忽略）
+func (p *persistence) delete(ctx context.Context, ids ...string) error {
+	for _, id := range ids {
+		if err := p.store.Delete(ctx, p.collection, id); err != nil && !errors.Is(err, store.ErrNotFound) {
+			return fmt.Errorf("delete persisted memory %s: %w", id, err)
+		}
+	}
+	return nil
+}
+
+// clear 清空集合
+func (p *persistence) clear(ctx context.Context) error {
+	if err := p.store.Clear(ctx, p.collection); err != nil {
+		return fmt.Errorf("clear persisted memory: %w", err)
+	}
+	return nil
+}
+
+// WithPersistence 将工作记忆持久化到文档存储（如 store.SQLiteDocumentStore）
+//
+// collection 为空时使用 DefaultWorkingCollection。
+// 首次访问时懒加载已有记录，Add/Update/Remove 等写操作同步写入存储。
+func WithPersistence(docStore store.DocumentStore, collection string) WorkingMemoryOption {
+	return func(m *WorkingMemory) {
+		m.persist = newPersistence(docStore, collection, DefaultWorkingCollection)
+	}
+}
+
+// WithPersistenceReload 设置从存储重新加载工作记忆的间隔
+//
+// 多个 Agent 副本通过同一存储（如 store.RedisDocumentStore）共享工作记忆时，
+// 访问距上次加载超过 interval 会用存储中的记录替换本地消息，从而看到其他副本的写入。
+// 需要与 WithPersistence 同时使用，interval <= 0 表示只在首次访问时加载。
+func WithPersistenceReload(interval time.Duration) WorkingMemoryOption {
+	return func(m *WorkingMemory) {
+		m.reloadInterval = interval
+	}
+}
+
+// ensureLoaded 确保已从存储加载历史消息
+func (m *WorkingMemory) ensureLoaded(ctx context.Context) error {
+	if m.persist == nil {
+		return nil
+	}
+	return m.persist.ensureLoaded(func() error {
+		docs, err := m.persist.loadAll(ctx)
+		if err != nil {
+			return err
+		}
+
+		loaded := make([]workingMessage, 0, len(docs))
+		var summary *store.Document
+		for i, doc := range docs {
+			if doc.ID == overflowSummaryID {
+				summary = &docs[i]
+				continue
+			}
+			loaded = append(loaded, documentToWorkingMessage(doc))
+		}
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		if summary != nil {
+			m.summary = summary.Content
+			m.summaryAt = summary.CreatedAt
+		}
+		if m.persist.loaded {
+			// 重新加载：存储是共享的事实来源，本地写入均已同步到存储
+			m.messages = loaded
+		} else {
+			m.messages = append(loaded, m.messages...)
+		}
+		m.trimLoadedLocked()
+		m.tfidfStale = true
+		return nil
+	})
+}
+
+// trimLoadedLocked 去掉加载后已过期的消息，并只保留最新的 maxSize 条（调用方需持有写锁）
+//
+// 共享存储中可能有其他副本写入的更多消息，只裁剪本地视图，不从存储中删除。
+func (m *WorkingMemory) trimLoadedLocked() {
+	m.messages = m.filterExpired()
+	if m.maxSize > 0 && len(m.messages) > m.maxSize {
+		m.messages = m.messages[len(m.messages)-m.maxSize:]
+	}
+}
+
+// workingMessageToDocument 将工作记忆消息转换为文档
+func workingMessageToDocument(wm workingMessage) store.Document {
+	metadata := map[string]interface{}{
+		"role":       string(wm.Message.Role),
+		"importance": wm.Importance,
+	}
+	if wm.Message.Name != "" {
+		metadata["name"] = wm.Message.Name
+	}
+	if wm.Message.ToolCallID != "" {
+		metadata["tool_call_id"] = wm.Message.ToolCallID
+	}
+	if len(wm.Message.ToolCalls) > 0 {
+		if data, err := json.Marshal(wm.Message.ToolCalls); err == nil {
+			metadata["tool_calls"] = string(data)
+		}
+	}
+	if len(wm.Message.Attachments) > 0 {
+		if data, err := json.Marshal(wm.Message.Attachments); err == nil {
+			metadata["attachments"] = string(data)
+		}
+	}
+	if wm.Message.Metadata != nil {
+		metadata["metadata"] = wm.Message.Metadata
+	}
+
+	return store.Document{
+		ID:        wm.Message.ID,
+		Content:   wm.Message.Content,
+		Metadata:  metadata,
+		CreatedAt: wm.Message.Timestamp,
+		UpdatedAt: time.Now(),
+	}
+}
+
+// documentToWorkingMessage 将文档还原为工作记忆消息
+func documentToWorkingMessage(doc store.Document) workingMessage {
+	msg := message.Message{
+		ID:        doc.ID,
+		Role:      message.RoleUser,
+		Content:   doc.Content,
+		Timestamp: doc.CreatedAt,
+	}
+	if role, ok := doc.Metadata["role"].(string); ok && role != "" {
+		msg.Role = message.Role(role)
+	}
+	if name, ok := doc.Metadata["name"].(string); ok {
+		msg.Name = name
+	}
+	if id, ok := doc.Metadata["tool_call_id"].(string); ok {
+		msg.ToolCallID = id
+	}
+	if calls, ok := doc.Metadata["tool_calls"].(string); ok {
+		_ = json.Unmarshal([]byte(calls), &msg.ToolCalls)
+	}
+	if atts, ok := doc.Metadata["attachments"].(string); ok {
+		_ = json.Unmarshal([]byte(atts), &msg.Attachments)
+	}
+	if md, ok := doc.Metadata["metadata"].(map[string]interface{}); ok {
+		msg.Metadata = md
+	}
+
+	importance, ok := explicitImportance(doc.Metadata)
+	if !ok {
+		importance = 0.5
+	}
+
+	return workingMessage{
+		Message:    msg,
+		Importance: importance,
+	}
+}
+
+// EpisodicMemoryOption 情景记忆配置选项
+type EpisodicMemoryOption func(*EpisodicMemoryStore)
+
+// WithEpisodicPersistence 将情景记忆持久化到文档存储（如 store.SQLiteDocumentStore）
+//
+// collection 为空时使用 DefaultEpisodicCollection。
+// 首次访问时懒加载已有记录，Add/Update/Remove 等写操作同步写入存储。
+func WithEpisodicPersistence(docStore store.DocumentStore, collection string) EpisodicMemoryOption {
+	return func(m *EpisodicMemoryStore) {
+		m.persist = newPersistence(docStore, collection, DefaultEpisodicCollection)
+	}
+}
+
+// ensureLoaded 确保已从存储加载历史事件
+func (m *EpisodicMemoryStore) ensureLoaded(ctx context.Context) error {
+	if m.persist == nil {
+		return nil
+	}
+	return m.persist.ensureLoaded(func() error {
+		docs, err := m.persist.loadAll(ctx)
+		if err != nil {
+			return err
+		}
+
+		loaded := make([]Episode, 0, len(docs))
+		for _, doc := range docs {
+			loaded = append(loaded, documentToEpisode(doc))
+		}
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		m.episodes = append(loaded, m.episodes...)
+		m.rebuildSessions()
+		m.tfidfStale = true
+		return nil
+	})
+}
+
+// episodeToDocument 将事件转换为文档
+func episodeToDocument(ep Episode) store.Document {
+	metadata := map[string]interface{}{
+		"type":       ep.Type,
+		"importance": ep.Importance,
+	}
+	if ep.UserID != "" {
+		metadata["user_id"] = ep.UserID
+	}
+	if ep.SessionID != "" {
+		metadata["session_id"] = ep.SessionID
+	}
+	if ep.Outcome != "" {
+		metadata["outcome"] = ep.Outcome
+	}
+	if ep.Context != nil {
+		metadata["context"] = ep.Context
+	}
+	if ep.Metadata != nil {
+		metadata["metadata"] = ep.Metadata
+	}
+
+	return store.Document{
+		ID:        ep.ID,
+		Content:   ep.Content,
+		Metadata:  metadata,
+		CreatedAt: time.UnixMilli(ep.Timestamp),
+		UpdatedAt: time.Now(),
+	}
+}
+
+// documentToEpisode 将文档还原为事件
+func documentToEpisode(doc store.Document) Episode {
+	ep := Episode{
+		ID:        doc.ID,
+		Content:   doc.Content,
+		Timestamp: doc.CreatedAt.UnixMilli(),
+	}
+	ep.Type, _ = doc.Metadata["type"].(string)
+	ep.UserID, _ = doc.Metadata["user_id"].(string)
+	ep.SessionID, _ = doc.Metadata["session_id"].(string)
+	ep.Outcome, _ = doc.Metadata["outcome"].(string)
+	ep.Context, _ = doc.Metadata["context"].(map[string]interface{})
+	ep.Metadata, _ = doc.Metadata["metadata"].(map[string]interface{})
+	if importance, ok := explicitImportance(doc.Metadata); ok {
+		ep.Importance = importance
+	}
+	return ep
+}
+
+// ensureMessageID 持久化时为没有 ID 的消息生成 ID
+func ensureMessageID(msg *message.Message) {
+	if msg.ID == "" {
+		msg.ID = uuid.New().String()
+	}
+}
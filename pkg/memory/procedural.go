@@ -0,0 +1,619 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ProcedureStep 程序中的一个步骤
+type ProcedureStep struct {
+	// Description 步骤说明
+	Description string `json:"description,omitempty"`
+	// Tool 使用的工具名称（可选）
+	Tool string `json:"tool,omitempty"`
+	// Arguments 工具参数（可选，仅作参考）
+	Arguments map[string]interface{} `json:"arguments,omitempty"`
+}
+
+// String 返回步骤的单行描述
+func (s ProcedureStep) String() string {
+	if s.Tool == "" {
+		return s.Description
+	}
+
+	text := s.Tool
+	if len(s.Arguments) > 0 {
+		if args, err := json.Marshal(s.Arguments); err == nil {
+			text += " " + string(args)
+		}
+	}
+	if s.Description != "" {
+		text = s.Description + " (" + text + ")"
+	}
+	return text
+}
+
+// Procedure 程序性记忆：完成某类任务的步骤
+type Procedure struct {
+	// ID 唯一标识
+	ID string `json:"id"`
+	// Name 程序名称
+	Name string `json:"name"`
+	// Description 程序说明
+	Description string `json:"description,omitempty"`
+	// Triggers 触发条件（适用的任务描述）
+	Triggers []string `json:"triggers,omitempty"`
+	// Steps 按顺序执行的步骤
+	Steps []ProcedureStep `json:"steps"`
+	// SuccessCount 成功次数
+	SuccessCount int `json:"success_count"`
+	// FailureCount 失败次数
+	FailureCount int `json:"failure_count"`
+	// Importance 重要性评分 (0-1)
+	Importance float32 `json:"importance,omitempty"`
+	// UserID 用户标识
+	UserID string `json:"user_id,omitempty"`
+	// Metadata 元数据
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+	// CreatedAt 创建时间
+	CreatedAt time.Time `json:"created_at"`
+	// LastUsedAt 最近一次记录结果的时间
+	LastUsedAt time.Time `json:"last_used_at"`
+	// Vector TF-IDF 向量（内部使用）
+	Vector []float32 `json:"-"`
+}
+
+// SuccessRate 返回平滑后的成功率
+//
+// 使用拉普拉斯平滑 (成功+1)/(总数+2)，未使用过的程序为 0.5。
+func (p *Procedure) SuccessRate() float32 {
+	return float32(p.SuccessCount+1) / float32(p.SuccessCount+p.FailureCount+2)
+}
+
+// Format 将程序格式化为可放入提示词的文本
+func (p *Procedure) Format() string {
+	var sb strings.Builder
+	sb.WriteString(p.Name)
+	if p.Description != "" && p.Description != p.Name {
+		sb.WriteString("\n")
+		sb.WriteString(p.Description)
+	}
+	if len(p.Triggers) > 0 {
+		sb.WriteString("\nWhen: ")
+		sb.WriteString(strings.Join(p.Triggers, "; "))
+	}
+	for i, step := range p.Steps {
+		fmt.Fprintf(&sb, "\n%d. %s", i+1, step.String())
+	}
+	return sb.String()
+}
+
+// indexText 返回用于相似度检索的文本
+func (p *Procedure) indexText() string {
+	parts := make([]string, 0, len(p.Triggers)+len(p.Steps)+2)
+	parts = append(parts, p.Name, p.Description)
+	parts = append(parts, p.Triggers...)
+	for _, step := range p.Steps {
+		parts = append(parts, step.Description, step.Tool)
+	}
+	return strings.Join(parts, " ")
+}
+
+// ProcedureMatch 程序检索结果
+type ProcedureMatch struct {
+	// Procedure 命中的程序
+	Procedure Procedure
+	// Score 综合得分（任务相似度 × 成功率权重）
+	Score float32
+}
+
+// ProceduralMemoryStore 程序性记忆存储实现
+//
+// 保存解决过的任务步骤（技能、操作方法），按任务相似度检索，
+// 成功率越高的程序排名越靠前，Agent 可借此复用过去的工具调用序列。
+type ProceduralMemoryStore struct {
+	procedures []Procedure
+	tfidf      *TFIDFVectorizer // 用于本地语义检索
+	tfidfStale bool             // TF-IDF 索引是否需要重建
+	scope      scopeIndex       // 用户索引，与 TF-IDF 一同重建
+	mu         sync.RWMutex
+}
+
+// NewProceduralMemory 创建程序性记忆存储
+func NewProceduralMemory() *ProceduralMemoryStore {
+	return &ProceduralMemoryStore{
+		procedures: make([]Procedure, 0),
+		tfidf:      NewTFIDFVectorizer(),
+	}
+}
+
+// AddProcedure 添加程序，ID 已存在时覆盖，返回程序 ID
+func (m *ProceduralMemoryStore) AddProcedure(ctx context.Context, p Procedure) (string, error) {
+	if strings.TrimSpace(p.Name) == "" || len(p.Steps) == 0 {
+		return "", fmt.Errorf("%w: procedure requires a name and at least one step", ErrInvalidInput)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	id := m.putLocked(p)
+	m.tfidfStale = true
+	return id, nil
+}
+
+// putLocked 写入程序，补全 ID 和时间戳（调用方需持有写锁并负责重建 TF-IDF）
+func (m *ProceduralMemoryStore) putLocked(p Procedure) string {
+	if p.ID == "" {
+		p.ID = uuid.New().String()
+	}
+	if p.CreatedAt.IsZero() {
+		p.CreatedAt = time.Now()
+	}
+	p.Vector = nil
+
+	if i := m.indexLocked(p.ID); i >= 0 {
+		m.procedures[i] = p
+	} else {
+		m.procedures = append(m.procedures, p)
+	}
+	return p.ID
+}
+
+// indexLocked 返回程序在切片中的位置，不存在时返回 -1（调用方需持有锁）
+func (m *ProceduralMemoryStore) indexLocked(id string) int {
+	for i := range m.procedures {
+		if m.procedures[i].ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// GetProcedure 获取程序
+func (m *ProceduralMemoryStore) GetProcedure(ctx context.Context, id string) (*Procedure, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	i := m.indexLocked(id)
+	if i < 0 {
+		return nil, ErrNotFound
+	}
+	p := m.procedures[i]
+	p.Vector = nil
+	return &p, nil
+}
+
+// RecordOutcome 记录一次使用程序的结果，更新成功/失败次数
+func (m *ProceduralMemoryStore) RecordOutcome(ctx context.Context, id string, success bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := m.indexLocked(id)
+	if i < 0 {
+		return ErrNotFound
+	}
+	if success {
+		m.procedures[i].SuccessCount++
+	} else {
+		m.procedures[i].FailureCount++
+	}
+	m.procedures[i].LastUsedAt = time.Now()
+	return nil
+}
+
+// FindProcedures 按任务描述检索相似的程序
+//
+// 支持 WithLimit、WithMinScore 和 WithUserIDFilter。
+func (m *ProceduralMemoryStore) FindProcedures(ctx context.Context, task string, opts ...RetrieveOption) ([]ProcedureMatch, error) {
+	options := &retrieveOptions{
+		limit: 5,
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	m.ensureTFIDF()
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.matchLocked(task, options), nil
+}
+
+// matchLocked 对范围内的程序评分并排序（调用方需持有读锁且索引已重建）
+//
+// 得分 = 任务相似度 × (0.5 + 成功率 × 0.5)；TF-IDF 无命中时回退到关键词匹配。
+func (m *ProceduralMemoryStore) matchLocked(task string, options *retrieveOptions) []ProcedureMatch {
+	positions := m.scopedPositions(options)
+	if len(positions) == 0 {
+		return nil
+	}
+
+	similarities := m.tfidfSimilarities(task, positions)
+	if similarities == nil {
+		similarities = m.keywordSimilarities(task, positions)
+	}
+
+	matches := make([]ProcedureMatch, 0, len(positions))
+	for j, pos := range positions {
+		if similarities[j] <= 0 {
+			continue
+		}
+		p := m.procedures[pos]
+		score := similarities[j] * (0.5 + p.SuccessRate()*0.5)
+		if score < options.minScore {
+			continue
+		}
+		p.Vector = nil
+		matches = append(matches, ProcedureMatch{Procedure: p, Score: score})
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Score > matches[j].Score
+	})
+	if options.limit > 0 && options.limit < len(matches) {
+		matches = matches[:options.limit]
+	}
+	return matches
+}
+
+// scopedPositions 返回满足用户过滤条件的程序位置（调用方需持有读锁且索引已重建）
+//
+// 程序不属于任何会话，会话过滤条件被忽略。
+func (m *ProceduralMemoryStore) scopedPositions(options *retrieveOptions) []int {
+	if options.userID != "" {
+		return m.scope.positions(&retrieveOptions{userID: options.userID})
+	}
+	positions := make([]int, len(m.procedures))
+	for i := range positions {
+		positions[i] = i
+	}
+	return positions
+}
+
+// tfidfSimilarities 计算任务与各程序的 TF-IDF 相似度，全部为 0 时返回 nil
+func (m *ProceduralMemoryStore) tfidfSimilarities(task string, positions []int) []float32 {
+	if m.tfidf.VocabularySize() == 0 {
+		return nil
+	}
+	queryVector := m.tfidf.Transform(task)
+	if queryVector == nil {
+		return nil
+	}
+
+	similarities := make([]float32, len(positions))
+	hit := false
+	for j, pos := range positions {
+		if vector := m.procedures[pos].Vector; vector != nil {
+			similarities[j] = m.tfidf.CosineSimilarity(queryVector, vector)
+			hit = hit || similarities[j] > 0
+		}
+	}
+	if !hit {
+		return nil
+	}
+	return similarities
+}
+
+// keywordSimilarities 按关键词命中比例计算相似度
+func (m *ProceduralMemoryStore) keywordSimilarities(task string, positions []int) []float32 {
+	keywords := strings.Fields(strings.ToLower(task))
+	similarities := make([]float32, len(positions))
+	if len(keywords) == 0 {
+		return similarities
+	}
+
+	for j, pos := range positions {
+		text := strings.ToLower(m.procedures[pos].indexText())
+		matched := 0
+		for _, kw := range keywords {
+			if strings.Contains(text, kw) {
+				matched++
+			}
+		}
+		similarities[j] = float32(matched) / float32(len(keywords))
+	}
+	return similarities
+}
+
+// rebuildTFIDF 重建 TF-IDF 向量化器
+func (m *ProceduralMemoryStore) rebuildTFIDF() {
+	if len(m.procedures) == 0 {
+		m.tfidf.Clear()
+		return
+	}
+
+	docs := make([]string, len(m.procedures))
+	for i := range m.procedures {
+		docs[i] = m.procedures[i].indexText()
+	}
+
+	vectors := m.tfidf.FitTransform(docs)
+	for i := range m.procedures {
+		if i < len(vectors) {
+			m.procedures[i].Vector = vectors[i]
+		}
+	}
+}
+
+// Flush 重建过期的 TF-IDF 索引和用户索引
+func (m *ProceduralMemoryStore) Flush() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.tfidfStale {
+		m.rebuildTFIDF()
+		m.scope.rebuild(len(m.procedures), func(i int) (string, string) {
+			return m.procedures[i].UserID, ""
+		})
+		m.tfidfStale = false
+	}
+}
+
+// ensureTFIDF 检索前确保 TF-IDF 索引是最新的
+func (m *ProceduralMemoryStore) ensureTFIDF() {
+	m.mu.RLock()
+	stale := m.tfidfStale
+	m.mu.RUnlock()
+
+	if stale {
+		m.Flush()
+	}
+}
+
+// Size 返回程序数量
+func (m *ProceduralMemoryStore) Size() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.procedures)
+}
+
+// Add 添加记忆项（实现 Memory 接口）
+//
+// 记忆项内容作为程序说明，metadata["name"]、metadata["triggers"] 和
+// metadata["steps"]（字符串列表或 []ProcedureStep）提供名称、触发条件和步骤；
+// 未提供步骤时内容按行拆分为步骤。
+func (m *ProceduralMemoryStore) Add(ctx context.Context, item *MemoryItem) (string, error) {
+	if err := item.Validate(); err != nil {
+		return "", err
+	}
+	return m.AddProcedure(ctx, itemToProcedure(item))
+}
+
+// itemToProcedure 将记忆项转换为程序
+func itemToProcedure(item *MemoryItem) Procedure {
+	p := Procedure{
+		ID:          item.ID,
+		Name:        item.GetMetadataString("name"),
+		Description: item.Content,
+		Triggers:    metadataStrings(item.Metadata["triggers"]),
+		Importance:  item.Importance,
+		UserID:      item.UserID,
+		Metadata:    item.Metadata,
+		CreatedAt:   item.Timestamp,
+	}
+
+	switch steps := item.Metadata["steps"].(type) {
+	case []ProcedureStep:
+		p.Steps = steps
+	default:
+		for _, s := range metadataStrings(steps) {
+			p.Steps = append(p.Steps, ProcedureStep{Description: s})
+		}
+	}
+
+	if len(p.Steps) == 0 {
+		for _, line := range strings.Split(item.Content, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				p.Steps = append(p.Steps, ProcedureStep{Description: line})
+			}
+		}
+	}
+	if p.Name == "" {
+		p.Name = firstLine(item.Content)
+	}
+	return p
+}
+
+// metadataStrings 将元数据值转换为字符串列表
+func metadataStrings(v interface{}) []string {
+	switch values := v.(type) {
+	case []string:
+		return values
+	case []interface{}:
+		result := make([]string, 0, len(values))
+		for _, value := range values {
+			if s, ok := value.(string); ok {
+				result = append(result, s)
+			}
+		}
+		return result
+	case string:
+		return []string{values}
+	}
+	return nil
+}
+
+// firstLine 返回文本的第一行
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return strings.TrimSpace(s[:i])
+	}
+	return strings.TrimSpace(s)
+}
+
+// procedureToItem 将程序转换为 MemoryItem
+func procedureToItem(p Procedure, score float32) *MemoryItem {
+	metadata := make(map[string]interface{}, len(p.Metadata)+6)
+	for k, v := range p.Metadata {
+		metadata[k] = v
+	}
+	metadata["name"] = p.Name
+	metadata["triggers"] = p.Triggers
+	metadata["steps"] = p.Steps
+	metadata["success_count"] = p.SuccessCount
+	metadata["failure_count"] = p.FailureCount
+	metadata["score"] = score
+
+	return &MemoryItem{
+		ID:         p.ID,
+		Content:    p.Format(),
+		MemoryType: MemoryTypeProcedural,
+		UserID:     p.UserID,
+		Timestamp:  p.CreatedAt,
+		Importance: p.Importance,
+		Metadata:   metadata,
+	}
+}
+
+// Retrieve 检索记忆（实现 Memory 接口）
+//
+// 按任务相似度和成功率检索程序，WithMinScore 作用于综合得分。
+func (m *ProceduralMemoryStore) Retrieve(ctx context.Context, query string, opts ...RetrieveOption) ([]*MemoryItem, error) {
+	matches, err := m.FindProcedures(ctx, query, append([]RetrieveOption{WithLimit(10)}, opts...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*MemoryItem, len(matches))
+	for i, match := range matches {
+		results[i] = procedureToItem(match.Procedure, match.Score)
+	}
+	return results, nil
+}
+
+// Update 更新记忆（实现 Memory 接口）
+//
+// 内容更新程序说明。
+func (m *ProceduralMemoryStore) Update(ctx context.Context, id string, opts ...UpdateOption) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	options := &updateOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	i := m.indexLocked(id)
+	if i < 0 {
+		return ErrNotFound
+	}
+	if options.content != nil {
+		m.procedures[i].Description = *options.content
+	}
+	if options.importance != nil {
+		m.procedures[i].Importance = *options.importance
+	}
+	if options.metadata != nil {
+		m.procedures[i].Metadata = options.metadata
+	}
+	m.tfidfStale = true
+	return nil
+}
+
+// Remove 删除记忆（实现 Memory 接口）
+func (m *ProceduralMemoryStore) Remove(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := m.indexLocked(id)
+	if i < 0 {
+		return ErrNotFound
+	}
+	m.procedures = append(m.procedures[:i], m.procedures[i+1:]...)
+	m.tfidfStale = true
+	return nil
+}
+
+// AddBatch 批量添加记忆项（实现 Memory 接口）
+//
+// 所有记忆项校验通过后才会写入。
+func (m *ProceduralMemoryStore) AddBatch(ctx context.Context, items []*MemoryItem) ([]string, error) {
+	if err := validateItems(items); err != nil {
+		return nil, err
+	}
+
+	procedures := make([]Procedure, len(items))
+	for i, item := range items {
+		procedures[i] = itemToProcedure(item)
+		if procedures[i].Name == "" || len(procedures[i].Steps) == 0 {
+			return nil, fmt.Errorf("item %d: %w", i, ErrInvalidInput)
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ids := make([]string, len(procedures))
+	for i, p := range procedures {
+		ids[i] = m.putLocked(p)
+	}
+	m.tfidfStale = true
+	return ids, nil
+}
+
+// RemoveBatch 批量删除记忆（实现 Memory 接口）
+//
+// 删除所有存在的记录；有记录不存在时返回 ErrNotFound。
+func (m *ProceduralMemoryStore) RemoveBatch(ctx context.Context, ids []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	targets := idSet(ids)
+	removed := make(map[string]bool)
+	kept := m.procedures[:0]
+	for _, p := range m.procedures {
+		if targets[p.ID] {
+			removed[p.ID] = true
+			continue
+		}
+		kept = append(kept, p)
+	}
+	m.procedures = kept
+
+	if len(removed) > 0 {
+		m.tfidfStale = true
+	}
+	return missingIDsError(ids, removed)
+}
+
+// RetrieveMulti 批量检索记忆（实现 Memory 接口）
+//
+// 结果顺序与 queries 一致。
+func (m *ProceduralMemoryStore) RetrieveMulti(ctx context.Context, queries []string, opts ...RetrieveOption) ([][]*MemoryItem, error) {
+	return retrieveEach(ctx, m, queries, opts...)
+}
+
+// Has 检查记忆是否存在（实现 Memory 接口）
+func (m *ProceduralMemoryStore) Has(ctx context.Context, id string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.indexLocked(id) >= 0
+}
+
+// Clear 清空所有程序（实现 Memory 接口）
+func (m *ProceduralMemoryStore) Clear(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.procedures = make([]Procedure, 0)
+	m.scope = scopeIndex{}
+	m.tfidf.Clear()
+	m.tfidfStale = false
+	return nil
+}
+
+// GetStats 获取统计信息（实现 Memory 接口）
+func (m *ProceduralMemoryStore) GetStats(ctx context.Context, opts ...RetrieveOption) (*MemoryStats, error) {
+	return collectStats(ctx, m, opts)
+}
+
+// compile-time interface check
+var _ Memory = (*ProceduralMemoryStore)(nil)
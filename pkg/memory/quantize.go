@@ -0,0 +1,241 @@
+package memory
+
+import (
+	"math"
+)
+
+// VectorQuantization 本地向量的量化存储方式
+type VectorQuantization string
+
+const (
+	// QuantizationNone 不量化，按 float32 保存（每维 4 字节）
+	QuantizationNone VectorQuantization = ""
+	// QuantizationFloat16 按 IEEE 754 半精度保存（每维 2 字节），
+	// 相对误差约 0.05%，余弦相似度误差通常小于 1e-3，检索排序几乎不受影响
+	QuantizationFloat16 VectorQuantization = "float16"
+	// QuantizationInt8 按每个向量独立缩放的对称 int8 保存（每维 1 字节加 4 字节缩放系数），
+	// 余弦相似度误差通常小于 1e-2，相似度非常接近的记录之间可能交换名次
+	QuantizationInt8 VectorQuantization = "int8"
+)
+
+// WithSemanticVectorQuantization 设置本地向量的量化存储方式
+//
+// 大规模内存语义存储中 float32 向量占用了大部分内存。开启量化后向量以 float16 或 int8
+// 保存，检索时在计算余弦相似度的同时反量化，不会还原出完整的 float32 向量。
+// 导出快照时向量反量化为 float32。配置了向量数据库时本地不保存向量，该选项不生效。
+func WithSemanticVectorQuantization(q VectorQuantization) SemanticMemoryOption {
+	return func(m *SemanticMemoryStore) {
+		m.quantization = q
+	}
+}
+
+// quantizedVector 量化后的向量
+type quantizedVector struct {
+	halves []uint16 // float16 编码
+	ints   []int8   // int8 编码
+	scale  float32  // int8 缩放系数：原值 ≈ int8 × scale
+	norm   float64  // 量化值的模，余弦计算时复用
+}
+
+// quantizeVector 按指定方式量化向量，QuantizationNone 或空向量返回 nil
+func quantizeVector(v []float32, q VectorQuantization) *quantizedVector {
+	if len(v) == 0 {
+		return nil
+	}
+
+	switch q {
+	case QuantizationFloat16:
+		qv := &quantizedVector{halves: make([]uint16, len(v))}
+		var norm float64
+		for i, x := range v {
+			h := float32ToHalf(x)
+			qv.halves[i] = h
+			f := float64(halfToFloat32(h))
+			norm += f * f
+		}
+		qv.norm = math.Sqrt(norm)
+		return qv
+	case QuantizationInt8:
+		var maxAbs float32
+		for _, x := range v {
+			if x < 0 {
+				x = -x
+			}
+			if x > maxAbs {
+				maxAbs = x
+			}
+		}
+		qv := &quantizedVector{ints: make([]int8, len(v))}
+		if maxAbs == 0 {
+			return qv
+		}
+		qv.scale = maxAbs / 127
+		var norm float64
+		for i, x := range v {
+			n := math.Round(float64(x / qv.scale))
+			n = math.Max(-127, math.Min(127, n))
+			qv.ints[i] = int8(n)
+			norm += n * n
+		}
+		qv.norm = math.Sqrt(norm)
+		return qv
+	}
+	return nil
+}
+
+// dim 返回向量维度
+func (q *quantizedVector) dim() int {
+	if q.halves != nil {
+		return len(q.halves)
+	}
+	return len(q.ints)
+}
+
+// bytes 返回量化向量占用的字节数
+func (q *quantizedVector) bytes() int64 {
+	if q.halves != nil {
+		return int64(len(q.halves)) * 2
+	}
+	return int64(len(q.ints)) + 4
+}
+
+// cosine 计算查询向量与量化向量的余弦相似度
+//
+// int8 的缩放系数在余弦中相互抵消，直接使用整数值计算。
+func (q *quantizedVector) cosine(query []float32) float32 {
+	if len(query) != q.dim() || q.norm == 0 {
+		return 0
+	}
+
+	var dotProduct, normQuery float64
+	if q.halves != nil {
+		for i, h := range q.halves {
+			x := float64(query[i])
+			dotProduct += x * float64(halfToFloat32(h))
+			normQuery += x * x
+		}
+	} else {
+		for i, n := range q.ints {
+			x := float64(query[i])
+			dotProduct += x * float64(n)
+			normQuery += x * x
+		}
+	}
+
+	if normQuery == 0 {
+		return 0
+	}
+	return float32(dotProduct / (math.Sqrt(normQuery) * q.norm))
+}
+
+// dequantize 还原为 float32 向量
+func (q *quantizedVector) dequantize() []float32 {
+	v := make([]float32, q.dim())
+	if q.halves != nil {
+		for i, h := range q.halves {
+			v[i] = halfToFloat32(h)
+		}
+		return v
+	}
+	for i, n := range q.ints {
+		v[i] = float32(n) * q.scale
+	}
+	return v
+}
+
+// float32ToHalf 将 float32 转换为 IEEE 754 半精度（就近舍入到偶数）
+func float32ToHalf(f float32) uint16 {
+	bits := math.Float32bits(f)
+	sign := uint16(bits>>16) & 0x8000
+	exp := int32(bits>>23&0xff) - 127 + 15
+	mant := bits & 0x7fffff
+
+	switch {
+	case bits&0x7fffffff >= 0x7f800000: // Inf 和 NaN
+		if mant != 0 {
+			return sign | 0x7e00
+		}
+		return sign | 0x7c00
+	case exp >= 0x1f: // 上溢
+		return sign | 0x7c00
+	case exp <= 0: // 非规格化数或下溢为 0
+		if exp < -10 {
+			return sign
+		}
+		mant |= 0x800000
+		shift := uint32(14 - exp)
+		half := mant >> shift
+		rem := mant & (1<<shift - 1)
+		mid := uint32(1) << (shift - 1)
+		if rem > mid || (rem == mid && half&1 == 1) {
+			half++
+		}
+		return sign | uint16(half)
+	}
+
+	half := uint32(exp)<<10 | mant>>13
+	rem := mant & 0x1fff
+	if rem > 0x1000 || (rem == 0x1000 && half&1 == 1) {
+		half++ // 进位到指数位时得到正确的结果（最大值进位为 Inf）
+	}
+	return sign | uint16(half)
+}
+
+// halfToFloat32 将 IEEE 754 半精度转换为 float32
+func halfToFloat32(h uint16) float32 {
+	sign := uint32(h&0x8000) << 16
+	exp := uint32(h>>10) & 0x1f
+	mant := uint32(h & 0x3ff)
+
+	switch exp {
+	case 0:
+		if mant == 0 {
+			return math.Float32frombits(sign)
+		}
+		v := float32(mant) / (1 << 24)
+		if sign != 0 {
+			v = -v
+		}
+		return v
+	case 0x1f:
+		return math.Float32frombits(sign | 0x7f800000 | mant<<13)
+	}
+	return math.Float32frombits(sign | (exp+112)<<23 | mant<<13)
+}
+
+// encodeVector 按存储的量化配置编码本地向量，返回 float32 向量或量化向量之一
+func (m *SemanticMemoryStore) encodeVector(vector []float32) ([]float32, *quantizedVector) {
+	if m.quantization == QuantizationNone || vector == nil {
+		return vector, nil
+	}
+	return nil, quantizeVector(vector, m.quantization)
+}
+
+// hasVector 记录是否保存了本地向量
+func (r *semanticRecord) hasVector() bool {
+	return r.Vector != nil || r.Quantized != nil
+}
+
+// similarity 计算查询向量与记录向量的余弦相似度
+func (r *semanticRecord) similarity(query []float32) float32 {
+	if r.Quantized != nil {
+		return r.Quantized.cosine(query)
+	}
+	return cosineSimilarity(query, r.Vector)
+}
+
+// vector 返回记录的 float32 向量，量化存储时反量化
+func (r *semanticRecord) vector() []float32 {
+	if r.Quantized != nil {
+		return r.Quantized.dequantize()
+	}
+	return r.Vector
+}
+
+// vectorBytes 返回记录向量占用的字节数
+func (r *semanticRecord) vectorBytes() int64 {
+	if r.Quantized != nil {
+		return r.Quantized.bytes()
+	}
+	return int64(len(r.Vector)) * 4
+}
@@ -0,0 +1,287 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ahhsitt/helloagents-go/pkg/core/llm"
+	"github.com/ahhsitt/helloagents-go/pkg/core/message"
+)
+
+// 反思默认参数
+const (
+	// DefaultReflectionMinEpisodes 触发反思所需的最少新事件数
+	DefaultReflectionMinEpisodes = 3
+	// DefaultReflectionMaxEpisodes 单次反思提交给 LLM 的最多事件数
+	DefaultReflectionMaxEpisodes = 50
+)
+
+// DefaultReflectionPrompt 默认反思提示模板
+const DefaultReflectionPrompt = `以下是按时间排序的若干条事件记录，每条以 [编号] 开头。
+请从中归纳出具有普遍性的洞察，例如用户的偏好和习惯、反复出现的问题、工具或服务的稳定性规律。
+要求：每条洞察是一句可独立理解的陈述；只输出有多条事件或明确事实支撑的洞察；
+evidence 为支撑该洞察的事件编号；importance 为 0 到 1 之间的重要性。
+
+事件：
+%s
+
+请只输出符合以下 JSON Schema 的 JSON，不要输出其他内容：
+{"type":"object","properties":{"insights":{"type":"array","items":{"type":"object","properties":{"content":{"type":"string"},"importance":{"type":"number"},"evidence":{"type":"array","items":{"type":"integer"}}},"required":["content","evidence"]}}},"required":["insights"]}`
+
+// Insight 由情景记忆提炼出的洞察
+type Insight struct {
+	// ID 写入语义记忆后的记忆 ID
+	ID string `json:"id"`
+	// Content 洞察内容
+	Content string `json:"content"`
+	// Importance 重要性 (0-1)
+	Importance float32 `json:"importance"`
+	// SourceEpisodes 支撑该洞察的事件 ID
+	SourceEpisodes []string `json:"source_episodes"`
+	// UserID 用户标识
+	UserID string `json:"user_id,omitempty"`
+}
+
+// Reflector 反思引擎
+//
+// 扫描尚未反思过的情景记忆，由 LLM 归纳出通用洞察（如"用户偏好简洁的回答"、
+// "API X 经常超时"），写入语义记忆，并在 metadata["source_episodes"] 中记录
+// 支撑洞察的事件 ID。不同用户的事件分别反思，洞察归属于对应用户。
+type Reflector struct {
+	provider    llm.Provider
+	episodic    *EpisodicMemoryStore
+	semantic    Memory
+	prompt      string
+	minEpisodes int
+	maxEpisodes int
+	window      time.Duration
+
+	mu        sync.Mutex
+	reflected map[string]struct{} // 已反思过的事件 ID
+}
+
+// ReflectorOption 反思引擎选项
+type ReflectorOption func(*Reflector)
+
+// WithReflectionPrompt 设置自定义提示模板（需包含一个 %s 占位符）
+func WithReflectionPrompt(prompt string) ReflectorOption {
+	return func(r *Reflector) {
+		r.prompt = prompt
+	}
+}
+
+// WithReflectionMinEpisodes 设置触发反思所需的最少新事件数
+func WithReflectionMinEpisodes(n int) ReflectorOption {
+	return func(r *Reflector) {
+		r.minEpisodes = n
+	}
+}
+
+// WithReflectionMaxEpisodes 设置单次反思提交给 LLM 的最多事件数，超出部分留待下次反思
+func WithReflectionMaxEpisodes(n int) ReflectorOption {
+	return func(r *Reflector) {
+		r.maxEpisodes = n
+	}
+}
+
+// WithReflectionWindow 只反思最近 window 时间内的事件，0 表示不限
+func WithReflectionWindow(window time.Duration) ReflectorOption {
+	return func(r *Reflector) {
+		r.window = window
+	}
+}
+
+// NewReflector 创建反思引擎
+func NewReflector(provider llm.Provider, episodic *EpisodicMemoryStore, semantic Memory, opts ...ReflectorOption) *Reflector {
+	r := &Reflector{
+		provider:    provider,
+		episodic:    episodic,
+		semantic:    semantic,
+		prompt:      DefaultReflectionPrompt,
+		minEpisodes: DefaultReflectionMinEpisodes,
+		maxEpisodes: DefaultReflectionMaxEpisodes,
+		reflected:   make(map[string]struct{}),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Reflect 立即执行一次反思，返回写入语义记忆的洞察
+//
+// 新事件不足 minEpisodes 的用户本次跳过；LLM 调用或解析失败时返回错误，
+// 对应事件不标记为已反思，下次重试。
+func (r *Reflector) Reflect(ctx context.Context) ([]Insight, error) {
+	if r.provider == nil || r.episodic == nil || r.semantic == nil {
+		return nil, fmt.Errorf("reflect: provider, episodic and semantic memory are required: %w", ErrInvalidInput)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	start := int64(0)
+	if r.window > 0 {
+		start = time.Now().Add(-r.window).UnixMilli()
+	}
+	episodes, err := r.episodic.GetByTimeRange(ctx, start, math.MaxInt64)
+	if err != nil {
+		return nil, err
+	}
+
+	// 按用户分组未反思的事件（保持时间升序）
+	var users []string
+	pending := make(map[string][]Episode)
+	for _, ep := range episodes {
+		if _, ok := r.reflected[ep.ID]; ok {
+			continue
+		}
+		if _, ok := pending[ep.UserID]; !ok {
+			users = append(users, ep.UserID)
+		}
+		pending[ep.UserID] = append(pending[ep.UserID], ep)
+	}
+
+	var (
+		insights []Insight
+		firstErr error
+	)
+	for _, userID := range users {
+		batch := pending[userID]
+		if len(batch) < r.minEpisodes {
+			continue
+		}
+		if r.maxEpisodes > 0 && len(batch) > r.maxEpisodes {
+			batch = batch[:r.maxEpisodes]
+		}
+
+		derived, err := r.reflectBatch(ctx, userID, batch)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		insights = append(insights, derived...)
+		for _, ep := range batch {
+			r.reflected[ep.ID] = struct{}{}
+		}
+	}
+
+	return insights, firstErr
+}
+
+// Run 按固定间隔执行 Reflect，直到 ctx 被取消
+//
+// 通常在后台 goroutine 中运行：go reflector.Run(ctx, time.Hour)。
+func (r *Reflector) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			_, _ = r.Reflect(ctx)
+		}
+	}
+}
+
+// llmReflection LLM 输出的 JSON 结构
+type llmReflection struct {
+	Insights []struct {
+		Content    string  `json:"content"`
+		Importance float32 `json:"importance"`
+		Evidence   []int   `json:"evidence"`
+	} `json:"insights"`
+}
+
+// reflectBatch 对同一用户的一批事件调用 LLM，并将洞察写入语义记忆
+//
+// 没有有效事件编号支撑的洞察被丢弃。
+func (r *Reflector) reflectBatch(ctx context.Context, userID string, batch []Episode) ([]Insight, error) {
+	var sb strings.Builder
+	for i, ep := range batch {
+		fmt.Fprintf(&sb, "[%d] ", i+1)
+		if ep.Type != "" {
+			fmt.Fprintf(&sb, "(%s) ", ep.Type)
+		}
+		sb.WriteString(ep.Content)
+		if ep.Outcome != "" {
+			sb.WriteString(" => ")
+			sb.WriteString(ep.Outcome)
+		}
+		sb.WriteString("\n")
+	}
+
+	resp, err := r.provider.Generate(ctx, llm.Request{
+		Messages: []message.Message{
+			message.NewUserMessage(fmt.Sprintf(r.prompt, sb.String())),
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reflect: %w", err)
+	}
+
+	raw := jsonObject(resp.Content)
+	if raw == "" {
+		return nil, fmt.Errorf("reflect: no json in response %q: %w", resp.Content, ErrInvalidInput)
+	}
+	var parsed llmReflection
+	if err := json.Unmarshal([]byte(raw), &parsed); err != nil {
+		return nil, fmt.Errorf("reflect: %w", err)
+	}
+
+	insights := make([]Insight, 0, len(parsed.Insights))
+	for _, pi := range parsed.Insights {
+		content := strings.TrimSpace(pi.Content)
+		sources := evidenceIDs(batch, pi.Evidence)
+		if content == "" || len(sources) == 0 {
+			continue
+		}
+
+		importance := normalizeConfidence(pi.Importance)
+		item := NewMemoryItem(content, MemoryTypeSemantic,
+			WithImportance(importance),
+			WithUserID(userID),
+			WithMetadataKV("source", "reflection"),
+			WithMetadataKV("source_episodes", sources),
+		)
+		id, err := r.semantic.Add(ctx, item)
+		if err != nil {
+			return insights, fmt.Errorf("reflect: store insight: %w", err)
+		}
+
+		insights = append(insights, Insight{
+			ID:             id,
+			Content:        content,
+			Importance:     importance,
+			SourceEpisodes: sources,
+			UserID:         userID,
+		})
+	}
+	return insights, nil
+}
+
+// evidenceIDs 将 LLM 给出的事件编号（从 1 开始）转换为去重后的事件 ID
+func evidenceIDs(batch []Episode, evidence []int) []string {
+	ids := make([]string, 0, len(evidence))
+	seen := make(map[int]struct{}, len(evidence))
+	for _, n := range evidence {
+		if n < 1 || n > len(batch) {
+			continue
+		}
+		if _, ok := seen[n]; ok {
+			continue
+		}
+		seen[n] = struct{}{}
+		ids = append(ids, batch[n-1].ID)
+	}
+	return ids
+}
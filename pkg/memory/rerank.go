@@ -0,0 +1,296 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/ahhsitt/helloagents-go/pkg/core/llm"
+	"github.com/ahhsitt/helloagents-go/pkg/core/message"
+)
+
+// ScoreNormalization 跨记忆类型合并检索结果前的分数归一化方法
+//
+// 各记忆类型的分数尺度不同（TF-IDF、余弦相似度、关键词命中率等），
+// 归一化后才能在同一尺度上排序。
+type ScoreNormalization string
+
+const (
+	// NormalizeNone 不归一化，合并结果按重要性排序（默认）
+	NormalizeNone ScoreNormalization = ""
+	// NormalizeMinMax 按每个记忆类型结果的最小/最大分数缩放到 [0, 1]
+	NormalizeMinMax ScoreNormalization = "min_max"
+	// NormalizeZScore 按每个记忆类型结果的均值和标准差标准化
+	NormalizeZScore ScoreNormalization = "z_score"
+)
+
+// 检索分数相关的元数据键
+const (
+	// MetadataScore 检索分数（归一化或重排序后的分数）
+	MetadataScore = "score"
+	// MetadataRawScore 记忆类型给出的原始检索分数
+	MetadataRawScore = "raw_score"
+)
+
+// WithScoreNormalization 设置跨记忆类型检索时的分数归一化方法
+//
+// 设置后 RetrieveMemories 对每个记忆类型的结果分别归一化 metadata["score"]
+// （原始分数保留在 metadata["raw_score"]），合并后按归一化分数而不是重要性排序。
+func WithScoreNormalization(method ScoreNormalization) ManagerOption {
+	return func(m *MemoryManager) {
+		m.normalization = method
+	}
+}
+
+// WithReranker 设置检索结果重排序器
+//
+// 设置后 RetrieveMemories 在合并各记忆类型的结果后、截断到 limit 前调用重排序器。
+// 重排序失败时保留重排序前的顺序。
+func WithReranker(reranker Reranker) ManagerOption {
+	return func(m *MemoryManager) {
+		m.reranker = reranker
+	}
+}
+
+// normalizeScores 归一化单个记忆类型的检索结果分数
+func normalizeScores(items []*MemoryItem, method ScoreNormalization) {
+	if method == NormalizeNone || len(items) == 0 {
+		return
+	}
+
+	raw := make([]float64, len(items))
+	for i, item := range items {
+		raw[i] = item.GetMetadataFloat(MetadataScore)
+	}
+
+	var normalized []float64
+	switch method {
+	case NormalizeZScore:
+		normalized = zScores(raw)
+	default:
+		normalized = minMaxScores(raw)
+	}
+
+	for i, item := range items {
+		item.Metadata = cloneMetadata(item.Metadata)
+		item.Metadata[MetadataRawScore] = raw[i]
+		item.Metadata[MetadataScore] = normalized[i]
+	}
+}
+
+// minMaxScores 将分数缩放到 [0, 1]，分数全部相同时均为 1
+func minMaxScores(scores []float64) []float64 {
+	lo, hi := scores[0], scores[0]
+	for _, s := range scores[1:] {
+		lo = math.Min(lo, s)
+		hi = math.Max(hi, s)
+	}
+	result := make([]float64, len(scores))
+	for i, s := range scores {
+		if hi == lo {
+			result[i] = 1
+		} else {
+			result[i] = (s - lo) / (hi - lo)
+		}
+	}
+	return result
+}
+
+// zScores 按均值和标准差标准化分数，标准差为 0 时均为 0
+func zScores(scores []float64) []float64 {
+	var mean float64
+	for _, s := range scores {
+		mean += s
+	}
+	mean /= float64(len(scores))
+
+	var variance float64
+	for _, s := range scores {
+		variance += (s - mean) * (s - mean)
+	}
+	std := math.Sqrt(variance / float64(len(scores)))
+
+	result := make([]float64, len(scores))
+	for i, s := range scores {
+		if std > 0 {
+			result[i] = (s - mean) / std
+		}
+	}
+	return result
+}
+
+// sortByScore 按 metadata["score"] 降序排序，分数相同时按重要性排序
+func sortByScore(items []*MemoryItem) {
+	sort.SliceStable(items, func(i, j int) bool {
+		si, sj := items[i].GetMetadataFloat(MetadataScore), items[j].GetMetadataFloat(MetadataScore)
+		if si != sj {
+			return si > sj
+		}
+		return items[i].Importance > items[j].Importance
+	})
+}
+
+// Reranker 检索结果重排序器
+type Reranker interface {
+	// Rerank 按与查询的相关性重新排序记忆，返回排序后的记忆
+	Rerank(ctx context.Context, query string, items []*MemoryItem) ([]*MemoryItem, error)
+}
+
+// CrossEncoderFunc 交叉编码器打分函数，返回与 passages 顺序一致的相关性分数
+type CrossEncoderFunc func(ctx context.Context, query string, passages []string) ([]float32, error)
+
+// CrossEncoderReranker 基于交叉编码器打分的重排序器
+type CrossEncoderReranker struct {
+	score CrossEncoderFunc
+}
+
+// NewCrossEncoderReranker 创建交叉编码器重排序器
+//
+// score 通常调用外部的交叉编码器服务（如 bge-reranker、Cohere Rerank）。
+func NewCrossEncoderReranker(score CrossEncoderFunc) *CrossEncoderReranker {
+	return &CrossEncoderReranker{score: score}
+}
+
+// Rerank 重排序
+func (r *CrossEncoderReranker) Rerank(ctx context.Context, query string, items []*MemoryItem) ([]*MemoryItem, error) {
+	if len(items) == 0 {
+		return items, nil
+	}
+
+	passages := make([]string, len(items))
+	for i, item := range items {
+		passages[i] = item.Content
+	}
+	scores, err := r.score(ctx, query, passages)
+	if err != nil {
+		return nil, fmt.Errorf("cross encoder reranker: %w", err)
+	}
+	if len(scores) != len(items) {
+		return nil, fmt.Errorf("cross encoder reranker: got %d scores for %d items", len(scores), len(items))
+	}
+
+	return applyRerankScores(items, func(i int) float64 { return float64(scores[i]) }), nil
+}
+
+// DefaultRerankPrompt 默认 LLM 重排序提示模板
+const DefaultRerankPrompt = `请评估以下每条记忆与查询的相关性，给出 0 到 1 之间的分数（1 表示完全相关）。
+
+查询：
+%s
+
+记忆：
+%s
+
+请只输出符合以下 JSON Schema 的 JSON，不要输出其他内容：
+{"type":"object","properties":{"scores":{"type":"array","items":{"type":"object","properties":{"index":{"type":"integer"},"score":{"type":"number"}},"required":["index","score"]}}},"required":["scores"]}`
+
+// LLMReranker 使用 LLM 评估相关性的重排序器
+type LLMReranker struct {
+	provider llm.Provider
+	prompt   string
+}
+
+// LLMRerankerOption LLM 重排序器选项
+type LLMRerankerOption func(*LLMReranker)
+
+// WithRerankPrompt 设置自定义提示模板（需包含两个 %s 占位符：查询和记忆列表）
+func WithRerankPrompt(prompt string) LLMRerankerOption {
+	return func(r *LLMReranker) {
+		r.prompt = prompt
+	}
+}
+
+// NewLLMReranker 创建 LLM 重排序器
+func NewLLMReranker(provider llm.Provider, opts ...LLMRerankerOption) *LLMReranker {
+	r := &LLMReranker{
+		provider: provider,
+		prompt:   DefaultRerankPrompt,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// llmRerankScores LLM 输出的 JSON 结构
+type llmRerankScores struct {
+	Scores []struct {
+		Index int     `json:"index"`
+		Score float64 `json:"score"`
+	} `json:"scores"`
+}
+
+// Rerank 重排序，LLM 未评分的记忆分数为 0
+func (r *LLMReranker) Rerank(ctx context.Context, query string, items []*MemoryItem) ([]*MemoryItem, error) {
+	if len(items) == 0 {
+		return items, nil
+	}
+	if r.provider == nil {
+		return nil, fmt.Errorf("llm reranker: llm provider not set")
+	}
+
+	var listing strings.Builder
+	for i, item := range items {
+		fmt.Fprintf(&listing, "%d. %s\n", i+1, item.Content)
+	}
+
+	resp, err := r.provider.Generate(ctx, llm.Request{
+		Messages: []message.Message{
+			message.NewUserMessage(fmt.Sprintf(r.prompt, query, listing.String())),
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("llm reranker: %w", err)
+	}
+
+	raw := jsonObject(resp.Content)
+	if raw == "" {
+		return nil, fmt.Errorf("llm reranker: no json in response %q", resp.Content)
+	}
+	var parsed llmRerankScores
+	if err := json.Unmarshal([]byte(raw), &parsed); err != nil {
+		return nil, fmt.Errorf("llm reranker: %w", err)
+	}
+
+	scores := make([]float64, len(items))
+	for _, s := range parsed.Scores {
+		if s.Index >= 1 && s.Index <= len(items) {
+			scores[s.Index-1] = s.Score
+		}
+	}
+	return applyRerankScores(items, func(i int) float64 { return scores[i] }), nil
+}
+
+// applyRerankScores 写入重排序分数并按分数降序返回记忆
+func applyRerankScores(items []*MemoryItem, score func(i int) float64) []*MemoryItem {
+	reranked := make([]*MemoryItem, len(items))
+	for i, item := range items {
+		item.Metadata = cloneMetadata(item.Metadata)
+		if _, ok := item.Metadata[MetadataRawScore]; !ok {
+			item.Metadata[MetadataRawScore] = item.GetMetadataFloat(MetadataScore)
+		}
+		item.Metadata[MetadataScore] = score(i)
+		reranked[i] = item
+	}
+	sortByScore(reranked)
+	return reranked
+}
+
+// rerank 调用重排序器，未设置重排序器或重排序失败时保持原顺序
+func (m *MemoryManager) rerank(ctx context.Context, query string, items []*MemoryItem) []*MemoryItem {
+	if m.reranker == nil || len(items) == 0 {
+		return items
+	}
+	reranked, err := m.reranker.Rerank(ctx, query, items)
+	if err != nil {
+		return items
+	}
+	return reranked
+}
+
+// compile-time interface check
+var _ Reranker = (*CrossEncoderReranker)(nil)
+var _ Reranker = (*LLMReranker)(nil)
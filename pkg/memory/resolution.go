@@ -0,0 +1,657 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+
+	"github.com/ahhsitt/helloagents-go/pkg/core/llm"
+	"github.com/ahhsitt/helloagents-go/pkg/core/message"
+)
+
+// PropertyAliases 实体合并后记录被合并实体名称的属性键
+const PropertyAliases = "aliases"
+
+// defaultResolveSimilarity 向量相似度匹配的默认阈值
+const defaultResolveSimilarity = 0.92
+
+// entityNameSuffixes 规范化名称时去除的组织后缀（已小写）
+var entityNameSuffixes = []string{
+	"股份有限公司", "有限责任公司", "有限公司",
+	"incorporated", "corporation", "limited", "company",
+	"inc", "corp", "ltd", "llc", "plc", "gmbh", "co",
+}
+
+// normalizeEntityName 规范化实体名称用于别名匹配
+//
+// NFKC 规范化、小写、去除标点和空白，并去除末尾的组织后缀，
+// 使 "OpenAI"、"OpenAI Inc." 和 "openai" 得到相同的结果。
+func normalizeEntityName(name string) string {
+	name = strings.ToLower(norm.NFKC.String(name))
+
+	fields := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	// 至少保留一个词，避免 "Company" 这类名称被清空
+	for len(fields) > 1 && isEntityNameSuffix(fields[len(fields)-1]) {
+		fields = fields[:len(fields)-1]
+	}
+	joined := strings.Join(fields, "")
+
+	for _, suffix := range entityNameSuffixes {
+		if !isLatinSuffix(suffix) {
+			if trimmed := strings.TrimSuffix(joined, suffix); trimmed != "" {
+				joined = trimmed
+			}
+		}
+	}
+	return joined
+}
+
+// isEntityNameSuffix 判断单词是否为拉丁字母组织后缀
+func isEntityNameSuffix(word string) bool {
+	for _, suffix := range entityNameSuffixes {
+		if isLatinSuffix(suffix) && word == suffix {
+			return true
+		}
+	}
+	return false
+}
+
+// isLatinSuffix 判断后缀是否由 ASCII 字母组成（按单词匹配），否则按字符串末尾匹配
+func isLatinSuffix(suffix string) bool {
+	for _, r := range suffix {
+		if r > unicode.MaxASCII {
+			return false
+		}
+	}
+	return true
+}
+
+// entityTypesCompatible 判断两个实体类型是否可以合并，未知类型可以与任意类型合并
+func entityTypesCompatible(a, b EntityType) bool {
+	if a == b || a == "" || b == "" || a == EntityTypeOther || b == EntityTypeOther {
+		return true
+	}
+	return false
+}
+
+// EntityMerge 一组被识别为同一实体的实体
+type EntityMerge struct {
+	// KeepID 保留的实体 ID（频率最高的实体）
+	KeepID string
+	// DuplicateIDs 合并到 KeepID 的实体 ID
+	DuplicateIDs []string
+	// Names 组内所有实体名称，保留实体在前
+	Names []string
+}
+
+// ResolveOption 实体消解选项
+type ResolveOption func(*resolveOptions)
+
+type resolveOptions struct {
+	similarity float32
+	provider   llm.Provider
+	prompt     string
+	dryRun     bool
+	limit      int
+}
+
+// WithResolveSimilarity 设置向量相似度匹配阈值，<= 0 时只按规范化名称匹配（默认 0.92）
+func WithResolveSimilarity(threshold float32) ResolveOption {
+	return func(o *resolveOptions) {
+		o.similarity = threshold
+	}
+}
+
+// WithResolveAdjudicator 设置 LLM 裁决
+//
+// 设置后仅由向量相似度发现的候选对需要 LLM 确认才会合并；
+// 规范化名称相同的实体直接合并，不经过 LLM。
+func WithResolveAdjudicator(provider llm.Provider) ResolveOption {
+	return func(o *resolveOptions) {
+		o.provider = provider
+	}
+}
+
+// WithResolvePrompt 设置 LLM 裁决的提示模板（需包含一个 %s 占位符：候选实体对列表）
+func WithResolvePrompt(prompt string) ResolveOption {
+	return func(o *resolveOptions) {
+		o.prompt = prompt
+	}
+}
+
+// WithResolveDryRun 只返回识别出的合并组，不执行合并
+func WithResolveDryRun() ResolveOption {
+	return func(o *resolveOptions) {
+		o.dryRun = true
+	}
+}
+
+// WithResolveLimit 设置图数据库模式下参与消解的实体数量上限
+func WithResolveLimit(limit int) ResolveOption {
+	return func(o *resolveOptions) {
+		o.limit = limit
+	}
+}
+
+// DefaultResolvePrompt 默认实体消解裁决提示模板
+const DefaultResolvePrompt = `以下每一行是一对候选实体（名称、类型和描述）。请判断每一对是否指向现实世界中的同一个实体（例如同一家公司的不同写法或简称）。
+
+候选实体对：
+%s
+
+请只输出符合以下 JSON Schema 的 JSON，不要输出其他内容，same 为指向同一实体的候选对序号：
+{"type":"object","properties":{"same":{"type":"array","items":{"type":"integer"}}},"required":["same"]}`
+
+// ResolveEntities 识别并合并指向同一现实实体的重复实体
+//
+// 依次执行：
+//  1. 规范化名称匹配（NFKC、大小写、标点、组织后缀）；
+//  2. 向量相似度匹配（实体向量余弦相似度 >= 阈值，需要嵌入器或实体自带向量）；
+//  3. 可选的 LLM 裁决，只确认第 2 步发现的候选对。
+//
+// 类型不同的实体不会合并（未知类型和 EntityTypeOther 除外）。每组保留频率最高的实体，
+// 其余实体通过 MergeEntities 合并。返回识别出的合并组。
+func (m *SemanticMemoryStore) ResolveEntities(ctx context.Context, opts ...ResolveOption) ([]EntityMerge, error) {
+	options := &resolveOptions{
+		similarity: defaultResolveSimilarity,
+		prompt:     DefaultResolvePrompt,
+		limit:      defaultGraphSearchLimit,
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	entities, err := m.listEntities(ctx, options.limit)
+	if err != nil {
+		return nil, err
+	}
+	// 按频率降序，保证每组的代表和保留实体稳定
+	sort.SliceStable(entities, func(i, j int) bool {
+		return preferEntity(entities[i], entities[j])
+	})
+
+	groups := newEntityUnion(entities)
+
+	// 1. 规范化名称匹配
+	byName := make(map[string]int)
+	for i, e := range entities {
+		key := normalizeEntityName(e.Name)
+		if key == "" {
+			continue
+		}
+		if j, ok := byName[key]; ok && entityTypesCompatible(entities[j].Type, e.Type) {
+			groups.union(j, i)
+			continue
+		}
+		byName[key] = i
+	}
+
+	// 2. 向量相似度匹配
+	if options.similarity > 0 {
+		candidates := m.similarEntityPairs(ctx, entities, groups, options.similarity)
+
+		// 3. LLM 裁决
+		if options.provider != nil && len(candidates) > 0 {
+			candidates, err = adjudicateEntityPairs(ctx, options, entities, candidates)
+			if err != nil {
+				return nil, err
+			}
+		}
+		for _, pair := range candidates {
+			groups.union(pair[0], pair[1])
+		}
+	}
+
+	merges := groups.merges()
+	if options.dryRun {
+		return merges, nil
+	}
+	for _, merge := range merges {
+		if err := m.MergeEntities(ctx, merge.KeepID, merge.DuplicateIDs); err != nil {
+			return nil, fmt.Errorf("merge entity %s: %w", merge.KeepID, err)
+		}
+	}
+	return merges, nil
+}
+
+// listEntities 返回实体副本，避免消解过程中持有锁
+func (m *SemanticMemoryStore) listEntities(ctx context.Context, limit int) ([]*Entity, error) {
+	if m.graph != nil {
+		if limit <= 0 {
+			limit = defaultGraphSearchLimit
+		}
+		return m.graphSearchEntities(ctx, "", limit)
+	}
+
+	m.graphMu.RLock()
+	defer m.graphMu.RUnlock()
+	entities := make([]*Entity, 0, len(m.entities))
+	for _, e := range m.entities {
+		clone := *e
+		entities = append(entities, &clone)
+	}
+	return entities, nil
+}
+
+// preferEntity 判断 a 是否应优先于 b 作为保留实体：频率高、创建早、ID 小
+func preferEntity(a, b *Entity) bool {
+	if a.Frequency != b.Frequency {
+		return a.Frequency > b.Frequency
+	}
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.Before(b.CreatedAt)
+	}
+	return a.ID < b.ID
+}
+
+// similarEntityPairs 返回尚未在同一组、向量相似度不低于阈值的实体对
+func (m *SemanticMemoryStore) similarEntityPairs(ctx context.Context, entities []*Entity, groups *entityUnion, threshold float32) [][2]int {
+	for _, e := range entities {
+		m.embedEntity(ctx, e)
+	}
+
+	var pairs [][2]int
+	for i := range entities {
+		if entities[i].Vector == nil {
+			continue
+		}
+		for j := i + 1; j < len(entities); j++ {
+			if entities[j].Vector == nil || groups.find(i) == groups.find(j) ||
+				!entityTypesCompatible(entities[i].Type, entities[j].Type) {
+				continue
+			}
+			if cosineSimilarity(entities[i].Vector, entities[j].Vector) >= threshold {
+				pairs = append(pairs, [2]int{i, j})
+			}
+		}
+	}
+	return pairs
+}
+
+// llmResolveDecision LLM 输出的 JSON 结构
+type llmResolveDecision struct {
+	Same []int `json:"same"`
+}
+
+// adjudicateEntityPairs 由 LLM 确认候选实体对，返回确认为同一实体的候选对
+func adjudicateEntityPairs(ctx context.Context, options *resolveOptions, entities []*Entity, pairs [][2]int) ([][2]int, error) {
+	var listing strings.Builder
+	for i, pair := range pairs {
+		a, b := entities[pair[0]], entities[pair[1]]
+		fmt.Fprintf(&listing, "%d. %s (%s) %s | %s (%s) %s\n", i+1,
+			a.Name, a.Type, a.Description, b.Name, b.Type, b.Description)
+	}
+
+	resp, err := options.provider.Generate(ctx, llm.Request{
+		Messages: []message.Message{
+			message.NewUserMessage(fmt.Sprintf(options.prompt, listing.String())),
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("entity resolution: %w", err)
+	}
+
+	raw := jsonObject(resp.Content)
+	if raw == "" {
+		return nil, fmt.Errorf("entity resolution: no json in response %q", resp.Content)
+	}
+	var decision llmResolveDecision
+	if err := json.Unmarshal([]byte(raw), &decision); err != nil {
+		return nil, fmt.Errorf("entity resolution: %w", err)
+	}
+
+	confirmed := make([][2]int, 0, len(decision.Same))
+	for _, index := range decision.Same {
+		if index >= 1 && index <= len(pairs) {
+			confirmed = append(confirmed, pairs[index-1])
+		}
+	}
+	return confirmed, nil
+}
+
+// entityUnion 实体并查集，根节点始终是组内最优先保留的实体
+type entityUnion struct {
+	entities []*Entity
+	parent   []int
+}
+
+func newEntityUnion(entities []*Entity) *entityUnion {
+	parent := make([]int, len(entities))
+	for i := range parent {
+		parent[i] = i
+	}
+	return &entityUnion{entities: entities, parent: parent}
+}
+
+func (u *entityUnion) find(i int) int {
+	for u.parent[i] != i {
+		u.parent[i] = u.parent[u.parent[i]]
+		i = u.parent[i]
+	}
+	return i
+}
+
+// union 合并两组，entities 已按优先级排序，因此下标小的根节点为保留实体
+func (u *entityUnion) union(i, j int) {
+	ri, rj := u.find(i), u.find(j)
+	if ri == rj {
+		return
+	}
+	if rj < ri {
+		ri, rj = rj, ri
+	}
+	u.parent[rj] = ri
+}
+
+// merges 返回包含多个实体的组
+func (u *entityUnion) merges() []EntityMerge {
+	index := make(map[int]int)
+	var merges []EntityMerge
+	for i, e := range u.entities {
+		root := u.find(i)
+		if root == i {
+			continue
+		}
+		pos, ok := index[root]
+		if !ok {
+			pos = len(merges)
+			index[root] = pos
+			keep := u.entities[root]
+			merges = append(merges, EntityMerge{KeepID: keep.ID, Names: []string{keep.Name}})
+		}
+		merges[pos].DuplicateIDs = append(merges[pos].DuplicateIDs, e.ID)
+		merges[pos].Names = append(merges[pos].Names, e.Name)
+	}
+	return merges
+}
+
+// MergeEntities 将重复实体合并到 keepID
+//
+// 重复实体的关系改为指向保留实体：合并后与已有关系重复（起点、终点和类型相同）的关系
+// 合并证据并取较大的强度，合并后成为自环的关系被删除。保留实体累加重复实体的频率，
+// 重复实体的名称记录在 Properties["aliases"] 中，之后按别名 GetEntityByName 或
+// AddEntity 会命中保留实体（图数据库模式下别名只记录不索引）。最后删除重复实体。
+func (m *SemanticMemoryStore) MergeEntities(ctx context.Context, keepID string, dupIDs []string) error {
+	if keepID == "" {
+		return ErrInvalidInput
+	}
+	dups := make([]string, 0, len(dupIDs))
+	for _, id := range dupIDs {
+		if id != "" && id != keepID {
+			dups = append(dups, id)
+		}
+	}
+	if len(dups) == 0 {
+		return nil
+	}
+	if m.graph != nil {
+		return m.graphMergeEntities(ctx, keepID, dups)
+	}
+
+	m.graphMu.Lock()
+	defer m.graphMu.Unlock()
+
+	keep, ok := m.entities[keepID]
+	if !ok {
+		return ErrNotFound
+	}
+	for _, id := range dups {
+		if _, ok := m.entities[id]; !ok {
+			return ErrNotFound
+		}
+	}
+
+	merged := make(map[string]struct{}, len(dups))
+	for _, id := range dups {
+		merged[id] = struct{}{}
+		absorbEntity(keep, m.entities[id])
+	}
+
+	// 已有的保留实体关系作为合并目标
+	existing := make(map[string]*Relation)
+	for _, rel := range m.relations {
+		if _, ok := merged[rel.FromEntityID]; ok {
+			continue
+		}
+		if _, ok := merged[rel.ToEntityID]; ok {
+			continue
+		}
+		if rel.FromEntityID == keepID || rel.ToEntityID == keepID {
+			existing[relationKey(rel)] = rel
+		}
+	}
+
+	relIDs := make([]string, 0)
+	for id := range m.relations {
+		relIDs = append(relIDs, id)
+	}
+	sort.Strings(relIDs)
+	for _, id := range relIDs {
+		rel := m.relations[id]
+		if !rewriteRelation(rel, keepID, merged) {
+			continue
+		}
+		if rel.FromEntityID == rel.ToEntityID {
+			delete(m.relations, id)
+			continue
+		}
+		if target, ok := existing[relationKey(rel)]; ok {
+			absorbRelation(target, rel)
+			delete(m.relations, id)
+			continue
+		}
+		existing[relationKey(rel)] = rel
+	}
+
+	for _, id := range dups {
+		delete(m.entities, id)
+	}
+	// 重复实体的名称（包括之前记录的别名）指向保留实体
+	for name, id := range m.entityIndex {
+		if _, ok := merged[id]; ok {
+			m.entityIndex[name] = keepID
+		}
+	}
+	return nil
+}
+
+// graphMergeEntities 在图数据库中合并实体
+//
+// 先写入改写后的关系和保留实体，再删除重复实体（同时删除其原有关系），
+// 中途失败时图中可能同时存在新旧关系，重新执行合并即可收敛。
+func (m *SemanticMemoryStore) graphMergeEntities(ctx context.Context, keepID string, dups []string) error {
+	keep, err := m.graphGetEntity(ctx, keepID)
+	if err != nil {
+		return err
+	}
+	merged := make(map[string]struct{}, len(dups))
+	duplicates := make([]*Entity, 0, len(dups))
+	for _, id := range dups {
+		dup, err := m.graphGetEntity(ctx, id)
+		if err != nil {
+			return err
+		}
+		merged[id] = struct{}{}
+		duplicates = append(duplicates, dup)
+	}
+
+	existing := make(map[string]*Relation)
+	keepRels, err := m.graph.GetRelations(ctx, keepID)
+	if err != nil {
+		return graphError("get graph relations", err)
+	}
+	for _, g := range keepRels {
+		rel := graphToRelation(g)
+		if _, ok := merged[rel.FromEntityID]; ok {
+			continue
+		}
+		if _, ok := merged[rel.ToEntityID]; ok {
+			continue
+		}
+		existing[relationKey(rel)] = rel
+	}
+
+	changed := make(map[string]*Relation)
+	removed := make(map[string]struct{})
+	for _, id := range dups {
+		rels, err := m.graph.GetRelations(ctx, id)
+		if err != nil {
+			return graphError("get graph relations", err)
+		}
+		for _, g := range rels {
+			if _, ok := removed[g.ID]; ok {
+				continue
+			}
+			if _, ok := changed[g.ID]; ok {
+				continue
+			}
+			rel := graphToRelation(g)
+			rewriteRelation(rel, keepID, merged)
+			switch target, ok := existing[relationKey(rel)]; {
+			case rel.FromEntityID == rel.ToEntityID:
+				removed[rel.ID] = struct{}{}
+			case ok:
+				absorbRelation(target, rel)
+				changed[target.ID] = target
+				removed[rel.ID] = struct{}{}
+			default:
+				existing[relationKey(rel)] = rel
+				changed[rel.ID] = rel
+			}
+		}
+	}
+
+	for _, dup := range duplicates {
+		absorbEntity(keep, dup)
+	}
+	if err := m.graph.AddEntity(ctx, entityToGraph(keep)); err != nil {
+		return graphError("update graph entity", err)
+	}
+	// 重复实体被删除时其关系一并删除，因此先删除再写入改写后的关系
+	for _, id := range dups {
+		if err := m.graph.DeleteEntity(ctx, id); err != nil {
+			return graphError("delete graph entity", err)
+		}
+	}
+	relIDs := make([]string, 0, len(changed))
+	for id := range changed {
+		relIDs = append(relIDs, id)
+	}
+	sort.Strings(relIDs)
+	for _, id := range relIDs {
+		if err := m.graph.AddRelation(ctx, relationToGraph(changed[id])); err != nil {
+			return graphError("add graph relation", err)
+		}
+	}
+
+	m.graphMu.Lock()
+	defer m.graphMu.Unlock()
+	for id := range removed {
+		delete(m.relations, id)
+	}
+	for id, rel := range changed {
+		m.relations[id] = rel
+	}
+	return nil
+}
+
+// absorbEntity 将重复实体的频率、别名、描述和属性并入保留实体
+func absorbEntity(keep, dup *Entity) {
+	keep.Frequency += dup.Frequency
+	if keep.Description == "" {
+		keep.Description = dup.Description
+	}
+	for k, v := range dup.Properties {
+		if k == PropertyAliases {
+			continue
+		}
+		if _, ok := keep.GetProperty(k); !ok {
+			keep.SetProperty(k, v)
+		}
+	}
+
+	aliases := entityAliases(keep)
+	for _, name := range append([]string{dup.Name}, entityAliases(dup)...) {
+		if strings.EqualFold(name, keep.Name) || containsFold(aliases, name) {
+			continue
+		}
+		aliases = append(aliases, name)
+	}
+	keep.SetProperty(PropertyAliases, aliases)
+	keep.UpdatedAt = time.Now()
+}
+
+// entityAliases 读取实体的别名列表（兼容 JSON 反序列化后的 []interface{}）
+func entityAliases(e *Entity) []string {
+	v, ok := e.GetProperty(PropertyAliases)
+	if !ok {
+		return nil
+	}
+	switch aliases := v.(type) {
+	case []string:
+		return append([]string(nil), aliases...)
+	case []interface{}:
+		result := make([]string, 0, len(aliases))
+		for _, a := range aliases {
+			if s, ok := a.(string); ok {
+				result = append(result, s)
+			}
+		}
+		return result
+	}
+	return nil
+}
+
+func containsFold(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// rewriteRelation 将关系中指向重复实体的端点改为保留实体，返回是否有改动
+func rewriteRelation(rel *Relation, keepID string, merged map[string]struct{}) bool {
+	changed := false
+	if _, ok := merged[rel.FromEntityID]; ok {
+		rel.FromEntityID = keepID
+		changed = true
+	}
+	if _, ok := merged[rel.ToEntityID]; ok {
+		rel.ToEntityID = keepID
+		changed = true
+	}
+	if changed {
+		rel.UpdatedAt = time.Now()
+	}
+	return changed
+}
+
+// relationKey 关系去重键：起点、终点和类型
+func relationKey(rel *Relation) string {
+	return rel.FromEntityID + "\x00" + rel.ToEntityID + "\x00" + string(rel.RelationType)
+}
+
+// absorbRelation 将重复关系的证据和强度并入目标关系
+func absorbRelation(target, dup *Relation) {
+	for _, evidence := range dup.Evidence {
+		target.AddEvidence(evidence)
+	}
+	if dup.Strength > target.Strength {
+		target.UpdateStrength(dup.Strength)
+	}
+	for k, v := range dup.Properties {
+		if _, ok := target.Properties[k]; !ok {
+			target.SetProperty(k, v)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package memory
+
+// WithSessionIDFilter 按会话 ID 过滤
+//
+// 会话 ID 取自 Episode.SessionID 或记忆项的 metadata["session_id"]。
+func WithSessionIDFilter(sessionID string) RetrieveOption {
+	return func(o *retrieveOptions) {
+		o.sessionID = sessionID
+	}
+}
+
+// scoped 返回是否设置了用户或会话过滤条件
+func (o *retrieveOptions) scoped() bool {
+	return o.userID != "" || o.sessionID != ""
+}
+
+// matchScope 判断记录是否满足用户和会话过滤条件
+func (o *retrieveOptions) matchScope(userID, sessionID string) bool {
+	if o.userID != "" && userID != o.userID {
+		return false
+	}
+	if o.sessionID != "" && sessionID != o.sessionID {
+		return false
+	}
+	return true
+}
+
+// scopeIndex 按用户和会话索引记录在存储切片中的位置
+//
+// 与 TF-IDF 索引一同在检索前惰性重建，带过滤条件的检索只对命中位置评分，
+// 避免每次查询全量扫描。
+type scopeIndex struct {
+	users    map[string][]int
+	sessions map[string][]int
+}
+
+// rebuild 重建索引，scope 返回第 i 条记录的用户和会话 ID
+func (x *scopeIndex) rebuild(n int, scope func(i int) (userID, sessionID string)) {
+	x.users = make(map[string][]int)
+	x.sessions = make(map[string][]int)
+	for i := 0; i < n; i++ {
+		userID, sessionID := scope(i)
+		if userID != "" {
+			x.users[userID] = append(x.users[userID], i)
+		}
+		if sessionID != "" {
+			x.sessions[sessionID] = append(x.sessions[sessionID], i)
+		}
+	}
+}
+
+// positions 返回满足过滤条件的记录位置（升序）
+func (x *scopeIndex) positions(options *retrieveOptions) []int {
+	switch {
+	case options.sessionID == "":
+		return x.users[options.userID]
+	case options.userID == "":
+		return x.sessions[options.sessionID]
+	default:
+		return intersectPositions(x.users[options.userID], x.sessions[options.sessionID])
+	}
+}
+
+// intersectPositions 求两个升序位置列表的交集
+func intersectPositions(a, b []int) []int {
+	var result []int
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] < b[j]:
+			i++
+		case a[i] > b[j]:
+			j++
+		default:
+			result = append(result, a[i])
+			i++
+			j++
+		}
+	}
+	return result
+}
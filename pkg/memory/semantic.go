@@ -2,6 +2,7 @@ package memory
 
 import (
 	"context"
+	"fmt"
 	"math"
 	"regexp"
 	"sort"
@@ -10,6 +11,8 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+
+	"github.com/ahhsitt/helloagents-go/pkg/memory/store"
 )
 
 // SemanticMemoryStore 语义记忆存储实现
@@ -17,22 +20,45 @@ import (
 // 基于向量相似度的记忆存储，支持语义搜索、实体管理和图遍历。
 // 注意：这是一个简化的内存实现，生产环境建议使用专用向量数据库和图数据库。
 type SemanticMemoryStore struct {
-	embedder Embedder
-	records  []semanticRecord
-	tfidf    *TFIDFVectorizer // 本地 TF-IDF 回退
+	embedder   Embedder
+	records    []semanticRecord
+	tfidf      *TFIDFVectorizer  // 本地 TF-IDF 回退
+	tfidfStale bool              // TF-IDF 索引是否需要重建
+	scope      scopeIndex        // 用户/会话索引，与 TF-IDF 一同重建
+	vectors    store.VectorStore // 可选的向量数据库，设置后向量不再保存在本地
+	collection string            // 向量数据库集合名
+	graph      store.GraphStore  // 可选的图数据库，设置后实体和关系保存在图数据库中
+
+	detectLanguage bool    // 写入时检测语言并写入 metadata["language"]
+	languageBoost  float32 // 检索时对查询语言相同的记录的分数提升比例
+
+	hybrid *hybridSearch // 可选的 BM25 + 向量混合检索
+	bm25   *BM25Index    // 混合检索的 BM25 索引，与 TF-IDF 一同重建
+
+	normalizer *QueryNormalizer // 可选的查询规范化，词表与 TF-IDF 一同重建
+
+	quantization VectorQuantization // 本地向量的量化存储方式
+
+	forgotten forgetLog // 遗忘记录，用于统计
 
-	// 实体和关系存储
+	// 实体和关系存储，由 graphMu 保护
 	entities    map[string]*Entity   // entityID -> Entity
 	relations   map[string]*Relation // relationID -> Relation
 	entityIndex map[string]string    // entityName (lowercase) -> entityID
 
-	mu sync.RWMutex
+	// mu 保护记录和检索索引，graphMu 保护实体和关系，两者互不阻塞；
+	// 需要同时持有时先取 mu 再取 graphMu。嵌入和向量数据库等网络调用不在锁内进行。
+	// writeLocks 按 ID 串行化写操作，使向量数据库和本地记录的写入顺序一致，需在 mu 之前获取。
+	mu         sync.RWMutex
+	graphMu    sync.RWMutex
+	writeLocks keyLocks
 }
 
 type semanticRecord struct {
 	ID         string
 	Content    string
 	Vector     []float32
+	Quantized  *quantizedVector // 开启量化时代替 Vector 保存向量
 	TFIDFVec   []float32
 	Metadata   map[string]interface{}
 	Importance float32
@@ -41,8 +67,8 @@ type semanticRecord struct {
 }
 
 // NewSemanticMemory 创建语义记忆存储
-func NewSemanticMemory(embedder Embedder) *SemanticMemoryStore {
-	return &SemanticMemoryStore{
+func NewSemanticMemory(embedder Embedder, opts ...SemanticMemoryOption) *SemanticMemoryStore {
+	m := &SemanticMemoryStore{
 		embedder:    embedder,
 		records:     make([]semanticRecord, 0),
 		tfidf:       NewTFIDFVectorizer(),
@@ -50,6 +76,12 @@ func NewSemanticMemory(embedder Embedder) *SemanticMemoryStore {
 		relations:   make(map[string]*Relation),
 		entityIndex: make(map[string]string),
 	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return m
 }
 
 // Store 存储文本及其向量
@@ -72,9 +104,26 @@ func (m *SemanticMemoryStore) Store(ctx context.Context, id string, content stri
 		vector = vectors[0]
 	}
 
+	metadata = m.tagLanguage(content, metadata)
+
+	unlock := m.writeLocks.lock(id)
+	defer unlock()
+
+	// 向量数据库写入在取写锁之前完成
+	if err := m.putVectors(ctx, []store.VectorRecord{vectorRecord(id, content, vector, metadata)}); err != nil {
+		return err
+	}
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	m.upsertLocked(id, content, vector, metadata)
+	m.tfidfStale = true
+	return nil
+}
+
+// upsertLocked 插入或更新记录，返回记录下标（调用方需持有写锁并负责重建 TF-IDF）
+func (m *SemanticMemoryStore) upsertLocked(id, content string, vector []float32, metadata map[string]interface{}) int {
 	// 生成 TF-IDF 向量
 	var tfidfVec []float32
 	if m.tfidf != nil {
@@ -95,44 +144,47 @@ func (m *SemanticMemoryStore) Store(ctx context.Context, id string, content stri
 		}
 	}
 
-	// 检查是否已存在，如果存在则更新
-	for i, rec := range m.records {
-		if rec.ID == id {
-			m.records[i] = semanticRecord{
-				ID:         id,
-				Content:    content,
-				Vector:     vector,
-				TFIDFVec:   tfidfVec,
-				Metadata:   metadata,
-				Importance: importance,
-				Timestamp:  time.Now(),
-				UserID:     userID,
-			}
-			m.rebuildTFIDF()
-			return nil
-		}
+	// 配置了向量数据库时向量只保存在数据库中
+	if m.vectors != nil {
+		vector = nil
 	}
+	vector, quantized := m.encodeVector(vector)
 
-	// 添加新记录
-	m.records = append(m.records, semanticRecord{
+	record := semanticRecord{
 		ID:         id,
 		Content:    content,
 		Vector:     vector,
+		Quantized:  quantized,
 		TFIDFVec:   tfidfVec,
 		Metadata:   metadata,
 		Importance: importance,
 		Timestamp:  time.Now(),
 		UserID:     userID,
-	})
+	}
 
-	m.rebuildTFIDF()
-	return nil
+	// 检查是否已存在，如果存在则更新
+	for i, rec := range m.records {
+		if rec.ID == id {
+			m.records[i] = record
+			return i
+		}
+	}
+
+	// 添加新记录
+	m.records = append(m.records, record)
+	return len(m.records) - 1
 }
 
 // rebuildTFIDF 重建 TF-IDF 向量化器
 func (m *SemanticMemoryStore) rebuildTFIDF() {
 	if len(m.records) == 0 {
 		m.tfidf.Clear()
+		if m.bm25 != nil {
+			m.bm25.Fit(nil)
+		}
+		if m.normalizer != nil {
+			m.normalizer.Fit(nil)
+		}
 		return
 	}
 
@@ -147,56 +199,195 @@ func (m *SemanticMemoryStore) rebuildTFIDF() {
 			m.records[i].TFIDFVec = vectors[i]
 		}
 	}
+	if m.bm25 != nil {
+		m.bm25.Fit(docs)
+	}
+	if m.normalizer != nil {
+		m.normalizer.Fit(docs)
+	}
+}
+
+// rebuildScope 重建用户/会话索引，会话 ID 取自 metadata["session_id"]
+func (m *SemanticMemoryStore) rebuildScope() {
+	m.scope.rebuild(len(m.records), func(i int) (string, string) {
+		sessionID, _ := m.records[i].Metadata["session_id"].(string)
+		return m.records[i].UserID, sessionID
+	})
+}
+
+// Flush 重建过期的 TF-IDF 索引和用户/会话索引
+//
+// 写操作只将索引标记为过期，索引在下次检索或调用 Flush 时统一重建，
+// 批量写入后可主动调用 Flush 以避免首次检索的重建延迟。
+func (m *SemanticMemoryStore) Flush() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.tfidfStale {
+		m.rebuildTFIDF()
+		m.rebuildScope()
+		m.tfidfStale = false
+	}
+}
+
+// scopedRecords 返回满足用户/会话过滤条件的记录（调用方需持有读锁且索引已重建）
+func (m *SemanticMemoryStore) scopedRecords(options *retrieveOptions) []semanticRecord {
+	if !options.scoped() {
+		return m.records
+	}
+	positions := m.scope.positions(options)
+	records := make([]semanticRecord, len(positions))
+	for i, pos := range positions {
+		records[i] = m.records[pos]
+	}
+	return records
+}
+
+// ensureTFIDF 检索前确保 TF-IDF 索引是最新的
+func (m *SemanticMemoryStore) ensureTFIDF() {
+	m.mu.RLock()
+	stale := m.tfidfStale
+	m.mu.RUnlock()
+
+	if stale {
+		m.Flush()
+	}
 }
 
 // Search 搜索相似内容
 func (m *SemanticMemoryStore) Search(ctx context.Context, query string, topK int) ([]SearchResult, error) {
+	return m.search(ctx, query, topK, &retrieveOptions{})
+}
+
+// search 在满足用户/会话过滤条件的记录中搜索
+//
+// 查询嵌入和向量数据库检索都是网络调用，在取读锁之前完成，避免慢请求阻塞写操作。
+func (m *SemanticMemoryStore) search(ctx context.Context, query string, topK int, options *retrieveOptions) ([]SearchResult, error) {
+	m.ensureTFIDF()
+	query = m.normalizeQuery(query)
+
+	if m.vectors == nil && m.Size() == 0 {
+		return nil, nil
+	}
+
+	queryVector := m.embedQueries(ctx, []string{query})
+	var vector []float32
+	if queryVector != nil {
+		vector = queryVector[0]
+	}
+	hits, err := m.remoteSearch(ctx, vector, m.searchPlan(query, topK).candidates, options)
+	if err != nil {
+		return nil, err
+	}
+
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	if len(m.records) == 0 {
+	records := m.scopedRecords(options)
+	if len(records) == 0 && m.vectors == nil {
 		return nil, nil
 	}
+	return m.searchLocked(records, query, vector, hits, topK), nil
+}
+
+// embedQueries 嵌入查询，未配置嵌入器或嵌入失败时返回 nil（检索回退到 TF-IDF 和关键词匹配）
+func (m *SemanticMemoryStore) embedQueries(ctx context.Context, queries []string) [][]float32 {
+	if m.embedder == nil {
+		return nil
+	}
+	vectors, err := m.embedder.Embed(ctx, queries)
+	if err != nil || len(vectors) != len(queries) {
+		return nil
+	}
+	return vectors
+}
+
+// searchPlan 单次检索的候选数量
+type searchPlan struct {
+	language   string // 识别出的查询语言，未启用语言偏好时为 LanguageUnknown
+	topK       int    // 语言偏好提升前的候选数
+	candidates int    // 每一路检索的候选数
+}
+
+// searchPlan 计算检索的候选数量：启用语言偏好时多取候选，提升同语言结果后再截取；
+// 启用混合检索时每路多取候选，融合后再截取
+func (m *SemanticMemoryStore) searchPlan(query string, topK int) searchPlan {
+	plan := searchPlan{language: LanguageUnknown, topK: topK}
+	if m.languageBoost > 0 {
+		if plan.language = DetectLanguage(query); plan.language != LanguageUnknown {
+			plan.topK *= 2
+		}
+	}
+	plan.candidates = plan.topK
+	if m.hybrid != nil {
+		plan.candidates = plan.topK * hybridCandidateMultiplier
+	}
+	return plan
+}
+
+// searchLocked 在 records 中使用已生成的查询向量检索（调用方需持有读锁）
+//
+// queryVector 为空时跳过向量检索，直接使用 TF-IDF 和关键词匹配；
+// 配置了向量数据库时使用加锁前由 remoteSearch 取得的 hits。
+func (m *SemanticMemoryStore) searchLocked(records []semanticRecord, query string, queryVector []float32, hits []store.VectorSearchResult, limit int) []SearchResult {
+	plan := m.searchPlan(query, limit)
+	language, topK, candidates := plan.language, plan.topK, plan.candidates
 
-	// 尝试嵌入向量检索
 	var results []SearchResult
-	if m.embedder != nil {
-		vectors, err := m.embedder.Embed(ctx, []string{query})
-		if err == nil && len(vectors) > 0 {
-			results = m.vectorSearch(vectors[0], topK)
+	if queryVector != nil {
+		if m.vectors != nil {
+			results = m.remoteResultsLocked(hits)
+		} else {
+			results = m.vectorSearch(records, queryVector, candidates)
+		}
+	}
+
+	if m.hybrid != nil {
+		results = m.hybrid.fuse(results, m.bm25Search(records, query, candidates), topK)
+		// 融合分数与单路分数不在同一尺度，有融合结果时不再用 TF-IDF 和关键词补足
+		if len(results) > 0 {
+			return m.preferQueryLanguage(results, language, limit)
 		}
 	}
 
 	// 如果嵌入失败或结果不足，使用 TF-IDF
 	if len(results) < topK && m.tfidf.VocabularySize() > 0 {
-		tfidfResults := m.tfidfSearch(query, topK)
+		tfidfResults := m.tfidfSearch(records, query, topK)
 		results = m.mergeResults(results, tfidfResults, topK)
 	}
 
 	// 如果仍然不足，使用关键词匹配
 	if len(results) < topK {
-		keywordResults := m.keywordSearch(query, topK)
+		keywordResults := m.keywordSearch(records, query, topK)
 		results = m.mergeResults(results, keywordResults, topK)
 	}
 
-	return results, nil
+	return m.preferQueryLanguage(results, language, limit)
+}
+
+// preferQueryLanguage 在启用语言偏好且识别出查询语言时提升同语言结果
+func (m *SemanticMemoryStore) preferQueryLanguage(results []SearchResult, language string, topK int) []SearchResult {
+	if language == LanguageUnknown {
+		return results
+	}
+	return preferLanguage(results, language, m.languageBoost, topK)
 }
 
 // vectorSearch 向量相似度搜索
-func (m *SemanticMemoryStore) vectorSearch(queryVector []float32, topK int) []SearchResult {
+func (m *SemanticMemoryStore) vectorSearch(records []semanticRecord, queryVector []float32, topK int) []SearchResult {
 	type scoredRecord struct {
 		record semanticRecord
 		score  float32
 	}
 
-	scored := make([]scoredRecord, 0, len(m.records))
+	scored := make([]scoredRecord, 0, len(records))
 	now := time.Now()
 
-	for _, rec := range m.records {
-		if rec.Vector == nil {
+	for _, rec := range records {
+		if !rec.hasVector() {
 			continue
 		}
-		similarity := cosineSimilarity(queryVector, rec.Vector)
+		similarity := rec.similarity(queryVector)
 		ageDays := float32(now.Sub(rec.Timestamp).Hours() / 24)
 		score := m.calculateScore(similarity, ageDays, rec.Importance)
 		scored = append(scored, scoredRecord{record: rec, score: score})
@@ -223,7 +414,7 @@ func (m *SemanticMemoryStore) vectorSearch(queryVector []float32, topK int) []Se
 }
 
 // tfidfSearch TF-IDF 语义检索
-func (m *SemanticMemoryStore) tfidfSearch(query string, topK int) []SearchResult {
+func (m *SemanticMemoryStore) tfidfSearch(records []semanticRecord, query string, topK int) []SearchResult {
 	queryVector := m.tfidf.Transform(query)
 	if queryVector == nil {
 		return nil
@@ -234,10 +425,10 @@ func (m *SemanticMemoryStore) tfidfSearch(query string, topK int) []SearchResult
 		score  float32
 	}
 
-	scored := make([]scoredRecord, 0, len(m.records))
+	scored := make([]scoredRecord, 0, len(records))
 	now := time.Now()
 
-	for _, rec := range m.records {
+	for _, rec := range records {
 		if rec.TFIDFVec == nil {
 			continue
 		}
@@ -268,7 +459,7 @@ func (m *SemanticMemoryStore) tfidfSearch(query string, topK int) []SearchResult
 }
 
 // keywordSearch 关键词匹配检索
-func (m *SemanticMemoryStore) keywordSearch(query string, topK int) []SearchResult {
+func (m *SemanticMemoryStore) keywordSearch(records []semanticRecord, query string, topK int) []SearchResult {
 	query = strings.ToLower(query)
 	keywords := strings.Fields(query)
 	if len(keywords) == 0 {
@@ -280,10 +471,10 @@ func (m *SemanticMemoryStore) keywordSearch(query string, topK int) []SearchResu
 		score  float32
 	}
 
-	scored := make([]scoredRecord, 0, len(m.records))
+	scored := make([]scoredRecord, 0, len(records))
 	now := time.Now()
 
-	for _, rec := range m.records {
+	for _, rec := range records {
 		content := strings.ToLower(rec.Content)
 		matchCount := 0
 		for _, kw := range keywords {
@@ -382,18 +573,30 @@ func (m *SemanticMemoryStore) SearchWithThreshold(ctx context.Context, query str
 }
 
 // Delete 删除指定记录
+//
+// 配置了向量数据库时同时删除数据库中的向量，本地未缓存的记录也视为删除成功。
 func (m *SemanticMemoryStore) Delete(ctx context.Context, id string) error {
+	unlock := m.writeLocks.lock(id)
+	defer unlock()
+
+	if err := m.deleteVectors(ctx, []string{id}); err != nil {
+		return err
+	}
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
 	for i, rec := range m.records {
 		if rec.ID == id {
 			m.records = append(m.records[:i], m.records[i+1:]...)
-			m.rebuildTFIDF()
+			m.tfidfStale = true
 			return nil
 		}
 	}
 
+	if m.vectors != nil {
+		return nil
+	}
 	return ErrNotFound
 }
 
@@ -401,11 +604,25 @@ func (m *SemanticMemoryStore) Delete(ctx context.Context, id string) error {
 func (m *SemanticMemoryStore) Clear(ctx context.Context) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	if m.vectors != nil {
+		if err := m.vectors.Clear(ctx, m.collection); err != nil {
+			return fmt.Errorf("clear semantic vectors: %w", err)
+		}
+	}
+	if m.graph != nil {
+		if err := m.graph.Clear(ctx); err != nil {
+			return fmt.Errorf("clear semantic graph: %w", err)
+		}
+	}
 	m.records = make([]semanticRecord, 0)
+	m.scope = scopeIndex{}
+	m.tfidf.Clear()
+
+	m.graphMu.Lock()
 	m.entities = make(map[string]*Entity)
 	m.relations = make(map[string]*Relation)
 	m.entityIndex = make(map[string]string)
-	m.tfidf.Clear()
+	m.graphMu.Unlock()
 	return nil
 }
 
@@ -451,21 +668,27 @@ func (m *SemanticMemoryStore) Add(ctx context.Context, item *MemoryItem) (string
 		return "", err
 	}
 
+	if err := m.Store(ctx, item.ID, item.Content, itemMetadata(item)); err != nil {
+		return "", err
+	}
+
+	return item.ID, nil
+}
+
+// itemMetadata 返回写入记录的元数据（附带重要性和用户 ID）
+func itemMetadata(item *MemoryItem) map[string]interface{} {
 	metadata := item.Metadata
 	if metadata == nil {
 		metadata = make(map[string]interface{})
 	}
 	metadata["importance"] = item.Importance
 	metadata["user_id"] = item.UserID
-
-	if err := m.Store(ctx, item.ID, item.Content, metadata); err != nil {
-		return "", err
-	}
-
-	return item.ID, nil
+	return metadata
 }
 
 // Retrieve 检索记忆（实现 Memory 接口）
+//
+// 设置 WithUserIDFilter/WithSessionIDFilter 时只对索引命中的记录评分。
 func (m *SemanticMemoryStore) Retrieve(ctx context.Context, query string, opts ...RetrieveOption) ([]*MemoryItem, error) {
 	options := &retrieveOptions{
 		limit: 10,
@@ -474,11 +697,16 @@ func (m *SemanticMemoryStore) Retrieve(ctx context.Context, query string, opts .
 		opt(options)
 	}
 
-	results, err := m.Search(ctx, query, options.limit)
+	results, err := m.search(ctx, query, options.limit, options)
 	if err != nil {
 		return nil, err
 	}
 
+	return m.resultsToItems(results, options), nil
+}
+
+// resultsToItems 将搜索结果转换为 MemoryItem 并按最小分数过滤
+func (m *SemanticMemoryStore) resultsToItems(results []SearchResult, options *retrieveOptions) []*MemoryItem {
 	items := make([]*MemoryItem, 0, len(results))
 	for _, r := range results {
 		if options.minScore > 0 && r.Score < options.minScore {
@@ -487,8 +715,7 @@ func (m *SemanticMemoryStore) Retrieve(ctx context.Context, query string, opts .
 		item := m.resultToItem(r)
 		items = append(items, item)
 	}
-
-	return items, nil
+	return items
 }
 
 // resultToItem 将搜索结果转换为 MemoryItem
@@ -523,39 +750,74 @@ func (m *SemanticMemoryStore) resultToItem(r SearchResult) *MemoryItem {
 }
 
 // Update 更新记忆（实现 Memory 接口）
+//
+// 更新内容时新向量在取写锁之前生成，向量数据库在释放写锁之后写入；
+// 同一 ID 的写操作按 ID 串行，本地记录和向量数据库不会被并发写入交错成不同的值。
 func (m *SemanticMemoryStore) Update(ctx context.Context, id string, opts ...UpdateOption) error {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-
 	options := &updateOptions{}
 	for _, opt := range opts {
 		opt(options)
 	}
 
+	// 重新生成向量
+	var vector []float32
+	if options.content != nil && m.embedder != nil && m.Has(ctx, id) {
+		vectors, err := m.embedder.Embed(ctx, []string{*options.content})
+		if err == nil && len(vectors) > 0 {
+			vector = vectors[0]
+		}
+	}
+
+	unlock := m.writeLocks.lock(id)
+	defer unlock()
+
+	remote, err := m.updateLocal(id, options, vector)
+	if err != nil || remote == nil {
+		return err
+	}
+	return m.putVectors(ctx, []store.VectorRecord{*remote})
+}
+
+// updateLocal 在写锁内更新本地记录，配置了向量数据库时返回需要写入的向量记录
+func (m *SemanticMemoryStore) updateLocal(id string, options *updateOptions, vector []float32) (*store.VectorRecord, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	for i := range m.records {
-		if m.records[i].ID == id {
-			if options.content != nil {
-				m.records[i].Content = *options.content
-				// 重新生成向量
-				if m.embedder != nil {
-					vectors, err := m.embedder.Embed(ctx, []string{*options.content})
-					if err == nil && len(vectors) > 0 {
-						m.records[i].Vector = vectors[0]
-					}
-				}
+		if m.records[i].ID != id {
+			continue
+		}
+
+		var remote *store.VectorRecord
+		if options.content != nil {
+			m.records[i].Content = *options.content
+			if m.detectLanguage && m.records[i].Metadata != nil {
+				delete(m.records[i].Metadata, "language")
 			}
-			if options.importance != nil {
-				m.records[i].Importance = *options.importance
+			m.records[i].Metadata = m.tagLanguage(*options.content, m.records[i].Metadata)
+			if vector != nil {
+				if m.vectors == nil {
+					m.records[i].Vector, m.records[i].Quantized = m.encodeVector(vector)
+				} else {
+					rec := vectorRecord(id, *options.content, vector, m.records[i].Metadata)
+					remote = &rec
+				}
 			}
-			if options.metadata != nil {
-				m.records[i].Metadata = options.metadata
+		}
+		if options.metadata != nil {
+			m.records[i].Metadata = options.metadata
+		}
+		if options.importance != nil {
+			m.records[i].Importance = *options.importance
+			if m.records[i].Metadata != nil {
+				m.records[i].Metadata["importance"] = *options.importance
 			}
-			m.rebuildTFIDF()
-			return nil
 		}
+		m.tfidfStale = true
+		return remote, nil
 	}
 
-	return ErrNotFound
+	return nil, ErrNotFound
 }
 
 // Remove 删除记忆（实现 Memory 接口）
@@ -563,47 +825,179 @@ func (m *SemanticMemoryStore) Remove(ctx context.Context, id string) error {
 	return m.Delete(ctx, id)
 }
 
-// Has 检查记忆是否存在（实现 Memory 接口）
-func (m *SemanticMemoryStore) Has(ctx context.Context, id string) bool {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+// AddBatch 批量添加记忆项（实现 Memory 接口）
+//
+// 所有记忆项校验通过后才会写入；嵌入向量通过一次 Embed 调用生成。
+func (m *SemanticMemoryStore) AddBatch(ctx context.Context, items []*MemoryItem) ([]string, error) {
+	if err := validateItems(items); err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, nil
+	}
 
+	ids := make([]string, len(items))
+	contents := make([]string, len(items))
+	for i, item := range items {
+		ids[i] = item.ID
+		if ids[i] == "" {
+			ids[i] = uuid.New().String()
+		}
+		contents[i] = item.Content
+	}
+
+	// 批量生成嵌入向量
+	var vectors [][]float32
+	if m.embedder != nil {
+		var err error
+		vectors, err = m.embedder.Embed(ctx, contents)
+		if err != nil {
+			return nil, err
+		}
+		if len(vectors) != len(items) {
+			return nil, ErrEmbeddingFailed
+		}
+	}
+
+	metadata := make([]map[string]interface{}, len(items))
+	records := make([]store.VectorRecord, len(items))
+	for i, item := range items {
+		metadata[i] = m.tagLanguage(item.Content, itemMetadata(item))
+		if vectors != nil {
+			records[i] = vectorRecord(ids[i], item.Content, vectors[i], metadata[i])
+		}
+	}
+
+	unlock := m.writeLocks.lock(ids...)
+	defer unlock()
+
+	if err := m.putVectors(ctx, records); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, item := range items {
+		var vector []float32
+		if vectors != nil {
+			vector = vectors[i]
+		}
+		m.upsertLocked(ids[i], item.Content, vector, metadata[i])
+	}
+	m.tfidfStale = true
+
+	return ids, nil
+}
+
+// RemoveBatch 批量删除记忆（实现 Memory 接口）
+//
+// 删除所有存在的记录；有记录不存在时返回 ErrNotFound。
+func (m *SemanticMemoryStore) RemoveBatch(ctx context.Context, ids []string) error {
+	unlock := m.writeLocks.lock(ids...)
+	defer unlock()
+
+	if err := m.deleteVectors(ctx, ids); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	targets := idSet(ids)
+	var removed []string
+	kept := m.records[:0]
 	for _, rec := range m.records {
-		if rec.ID == id {
-			return true
+		if targets[rec.ID] {
+			removed = append(removed, rec.ID)
+			continue
 		}
+		kept = append(kept, rec)
 	}
-	return false
+	m.records = kept
+
+	if len(removed) > 0 {
+		m.tfidfStale = true
+	}
+
+	return missingIDsError(ids, idSet(removed))
 }
 
-// GetStats 获取统计信息（实现 Memory 接口）
-func (m *SemanticMemoryStore) GetStats(ctx context.Context) (*MemoryStats, error) {
+// RetrieveMulti 批量检索记忆（实现 Memory 接口）
+//
+// 所有查询的嵌入向量通过一次 Embed 调用生成，结果顺序与 queries 一致。
+// 嵌入失败时回退到 TF-IDF 和关键词匹配。
+func (m *SemanticMemoryStore) RetrieveMulti(ctx context.Context, queries []string, opts ...RetrieveOption) ([][]*MemoryItem, error) {
+	options := &retrieveOptions{
+		limit: 10,
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	results := make([][]*MemoryItem, len(queries))
+	if len(queries) == 0 {
+		return results, nil
+	}
+
+	m.ensureTFIDF()
+	if m.normalizer != nil {
+		normalized := make([]string, len(queries))
+		for i, query := range queries {
+			normalized[i] = m.normalizeQuery(query)
+		}
+		queries = normalized
+	}
+
+	// 嵌入和向量数据库检索在取读锁之前完成
+	queryVectors := m.embedQueries(ctx, queries)
+	hits := make([][]store.VectorSearchResult, len(queries))
+	if queryVectors != nil && m.vectors != nil {
+		for i, query := range queries {
+			var err error
+			hits[i], err = m.remoteSearch(ctx, queryVectors[i], m.searchPlan(query, options.limit).candidates, options)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	if len(m.records) == 0 {
-		return &MemoryStats{Count: 0}, nil
+	records := m.scopedRecords(options)
+	if len(records) == 0 && m.vectors == nil {
+		return results, nil
 	}
 
-	var totalImportance float32
-	var oldestTs, newestTs time.Time
-
-	for i, rec := range m.records {
-		totalImportance += rec.Importance
-		if i == 0 || rec.Timestamp.Before(oldestTs) {
-			oldestTs = rec.Timestamp
+	for i, query := range queries {
+		var queryVector []float32
+		if queryVectors != nil {
+			queryVector = queryVectors[i]
 		}
-		if i == 0 || rec.Timestamp.After(newestTs) {
-			newestTs = rec.Timestamp
+		found := m.searchLocked(records, query, queryVector, hits[i], options.limit)
+		results[i] = m.resultsToItems(found, options)
+	}
+
+	return results, nil
+}
+
+// Has 检查记忆是否存在（实现 Memory 接口）
+func (m *SemanticMemoryStore) Has(ctx context.Context, id string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, rec := range m.records {
+		if rec.ID == id {
+			return true
 		}
 	}
+	return false
+}
 
-	return &MemoryStats{
-		Count:           len(m.records),
-		OldestTimestamp: oldestTs.UnixMilli(),
-		NewestTimestamp: newestTs.UnixMilli(),
-		AvgImportance:   totalImportance / float32(len(m.records)),
-	}, nil
+// GetStats 获取统计信息（实现 Memory 接口）
+func (m *SemanticMemoryStore) GetStats(ctx context.Context, opts ...RetrieveOption) (*MemoryStats, error) {
+	return collectStats(ctx, m, opts)
 }
 
 // ============================================================================
@@ -615,12 +1009,23 @@ func (m *SemanticMemoryStore) AddEntity(ctx context.Context, entity *Entity) err
 	if entity == nil || entity.Name == "" {
 		return ErrInvalidInput
 	}
+	if m.graph != nil {
+		return m.graphAddEntity(ctx, entity)
+	}
 
-	m.mu.Lock()
-	defer m.mu.Unlock()
+	// 新实体的向量在取写锁之前生成
+	nameLower := strings.ToLower(entity.Name)
+	m.graphMu.RLock()
+	_, exists := m.entities[m.entityIndex[nameLower]]
+	m.graphMu.RUnlock()
+	if !exists {
+		m.embedEntity(ctx, entity)
+	}
+
+	m.graphMu.Lock()
+	defer m.graphMu.Unlock()
 
 	// 检查是否已存在同名实体
-	nameLower := strings.ToLower(entity.Name)
 	if existingID, ok := m.entityIndex[nameLower]; ok {
 		// 更新频率
 		if existing, ok := m.entities[existingID]; ok {
@@ -634,27 +1039,34 @@ func (m *SemanticMemoryStore) AddEntity(ctx context.Context, entity *Entity) err
 		entity.ID = uuid.New().String()
 	}
 
-	// 生成向量（如果有嵌入器）
-	if m.embedder != nil && entity.Vector == nil {
-		desc := entity.Name
-		if entity.Description != "" {
-			desc = entity.Name + ": " + entity.Description
-		}
-		vectors, err := m.embedder.Embed(ctx, []string{desc})
-		if err == nil && len(vectors) > 0 {
-			entity.Vector = vectors[0]
-		}
-	}
-
 	m.entities[entity.ID] = entity
 	m.entityIndex[nameLower] = entity.ID
 	return nil
 }
 
+// embedEntity 生成实体向量（如果有嵌入器且尚无向量）
+func (m *SemanticMemoryStore) embedEntity(ctx context.Context, entity *Entity) {
+	if m.embedder == nil || entity.Vector != nil {
+		return
+	}
+	desc := entity.Name
+	if entity.Description != "" {
+		desc = entity.Name + ": " + entity.Description
+	}
+	vectors, err := m.embedder.Embed(ctx, []string{desc})
+	if err == nil && len(vectors) > 0 {
+		entity.Vector = vectors[0]
+	}
+}
+
 // GetEntity 获取实体
 func (m *SemanticMemoryStore) GetEntity(ctx context.Context, id string) (*Entity, error) {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+	if m.graph != nil {
+		return m.graphGetEntity(ctx, id)
+	}
+
+	m.graphMu.RLock()
+	defer m.graphMu.RUnlock()
 
 	if entity, ok := m.entities[id]; ok {
 		return entity, nil
@@ -664,8 +1076,12 @@ func (m *SemanticMemoryStore) GetEntity(ctx context.Context, id string) (*Entity
 
 // GetEntityByName 按名称获取实体
 func (m *SemanticMemoryStore) GetEntityByName(ctx context.Context, name string) (*Entity, error) {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+	if m.graph != nil {
+		return m.graphEntityByName(ctx, name)
+	}
+
+	m.graphMu.RLock()
+	defer m.graphMu.RUnlock()
 
 	nameLower := strings.ToLower(name)
 	if id, ok := m.entityIndex[nameLower]; ok {
@@ -678,8 +1094,12 @@ func (m *SemanticMemoryStore) GetEntityByName(ctx context.Context, name string)
 
 // SearchEntities 搜索实体
 func (m *SemanticMemoryStore) SearchEntities(ctx context.Context, pattern string, limit int) ([]*Entity, error) {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+	if m.graph != nil {
+		return m.graphSearchEntities(ctx, pattern, limit)
+	}
+
+	m.graphMu.RLock()
+	defer m.graphMu.RUnlock()
 
 	patternLower := strings.ToLower(pattern)
 	results := make([]*Entity, 0)
@@ -704,8 +1124,12 @@ func (m *SemanticMemoryStore) SearchEntities(ctx context.Context, pattern string
 
 // DeleteEntity 删除实体
 func (m *SemanticMemoryStore) DeleteEntity(ctx context.Context, id string) error {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+	if m.graph != nil {
+		return m.graphDeleteEntity(ctx, id)
+	}
+
+	m.graphMu.Lock()
+	defer m.graphMu.Unlock()
 
 	entity, ok := m.entities[id]
 	if !ok {
@@ -719,16 +1143,26 @@ func (m *SemanticMemoryStore) DeleteEntity(ctx context.Context, id string) error
 		}
 	}
 
-	// 从索引中删除
+	// 从索引中删除（包括合并实体时记录的别名）
 	delete(m.entityIndex, strings.ToLower(entity.Name))
+	for name, indexed := range m.entityIndex {
+		if indexed == id {
+			delete(m.entityIndex, name)
+		}
+	}
 	delete(m.entities, id)
 	return nil
 }
 
 // EntityCount 返回实体数量
 func (m *SemanticMemoryStore) EntityCount() int {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+	if m.graph != nil {
+		stats := m.graphStats()
+		return stats.EntityCount
+	}
+
+	m.graphMu.RLock()
+	defer m.graphMu.RUnlock()
 	return len(m.entities)
 }
 
@@ -741,9 +1175,12 @@ func (m *SemanticMemoryStore) AddRelation(ctx context.Context, relation *Relatio
 	if relation == nil || relation.FromEntityID == "" || relation.ToEntityID == "" {
 		return ErrInvalidInput
 	}
+	if m.graph != nil {
+		return m.graphAddRelation(ctx, relation)
+	}
 
-	m.mu.Lock()
-	defer m.mu.Unlock()
+	m.graphMu.Lock()
+	defer m.graphMu.Unlock()
 
 	// 验证实体存在
 	if _, ok := m.entities[relation.FromEntityID]; !ok {
@@ -764,8 +1201,8 @@ func (m *SemanticMemoryStore) AddRelation(ctx context.Context, relation *Relatio
 
 // GetRelation 获取关系
 func (m *SemanticMemoryStore) GetRelation(ctx context.Context, id string) (*Relation, error) {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+	m.graphMu.RLock()
+	defer m.graphMu.RUnlock()
 
 	if rel, ok := m.relations[id]; ok {
 		return rel, nil
@@ -775,8 +1212,12 @@ func (m *SemanticMemoryStore) GetRelation(ctx context.Context, id string) (*Rela
 
 // GetRelatedEntities 获取相关实体（图遍历）
 func (m *SemanticMemoryStore) GetRelatedEntities(ctx context.Context, entityID string, maxDepth int) ([]GraphSearchResult, error) {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+	if m.graph != nil {
+		return m.graphRelatedEntities(ctx, entityID, maxDepth)
+	}
+
+	m.graphMu.RLock()
+	defer m.graphMu.RUnlock()
 
 	if _, ok := m.entities[entityID]; !ok {
 		return nil, ErrNotFound
@@ -858,8 +1299,12 @@ func (m *SemanticMemoryStore) GetRelatedEntities(ctx context.Context, entityID s
 
 // DeleteRelation 删除关系
 func (m *SemanticMemoryStore) DeleteRelation(ctx context.Context, id string) error {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+	if m.graph != nil {
+		return m.graphDeleteRelation(ctx, id)
+	}
+
+	m.graphMu.Lock()
+	defer m.graphMu.Unlock()
 
 	if _, ok := m.relations[id]; !ok {
 		return ErrNotFound
@@ -870,8 +1315,13 @@ func (m *SemanticMemoryStore) DeleteRelation(ctx context.Context, id string) err
 
 // RelationCount 返回关系数量
 func (m *SemanticMemoryStore) RelationCount() int {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+	if m.graph != nil {
+		stats := m.graphStats()
+		return stats.RelationCount
+	}
+
+	m.graphMu.RLock()
+	defer m.graphMu.RUnlock()
 	return len(m.relations)
 }
 
@@ -895,6 +1345,11 @@ var (
 
 // ExtractEntities 从文本中提取实体
 func (m *SemanticMemoryStore) ExtractEntities(content string) []ExtractedEntity {
+	return extractEntities(content)
+}
+
+// extractEntities 基于正则表达式提取实体
+func extractEntities(content string) []ExtractedEntity {
 	entities := make([]ExtractedEntity, 0)
 	seen := make(map[string]struct{})
 
@@ -981,6 +1436,11 @@ func (m *SemanticMemoryStore) ExtractEntities(content string) []ExtractedEntity
 
 // ExtractRelations 从文本中提取关系（基于共现）
 func (m *SemanticMemoryStore) ExtractRelations(content string, entities []ExtractedEntity) []ExtractedRelation {
+	return extractRelations(content, entities)
+}
+
+// extractRelations 按句内共现提取关系
+func extractRelations(content string, entities []ExtractedEntity) []ExtractedRelation {
 	relations := make([]ExtractedRelation, 0)
 
 	if len(entities) < 2 {
@@ -1089,6 +1549,51 @@ func inferRelationType(type1, type2 EntityType, context string) RelationType {
 // Forget 方法
 // ============================================================================
 
+// DecayImportance 衰减所有记录的重要性（实现 ImportanceDecayer 接口）
+func (m *SemanticMemoryStore) DecayImportance(ctx context.Context, factor float64, floor float32) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	decayed := 0
+	for i := range m.records {
+		importance := decayImportance(m.records[i].Importance, factor, floor)
+		if importance == m.records[i].Importance {
+			continue
+		}
+		m.records[i].Importance = importance
+		if m.records[i].Metadata != nil {
+			m.records[i].Metadata["importance"] = importance
+		}
+		decayed++
+	}
+
+	return decayed, nil
+}
+
+// ReinforceImportance 强化指定记录的重要性（实现 ImportanceReinforcer 接口）
+//
+// 只修改本地记录的重要性，不重新生成向量，也不写入向量数据库。
+func (m *SemanticMemoryStore) ReinforceImportance(ctx context.Context, ids []string, boost float32) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	set := idSet(ids)
+	reinforced := 0
+	for i := range m.records {
+		if !set[m.records[i].ID] {
+			continue
+		}
+		importance := reinforceImportance(m.records[i].Importance, boost)
+		m.records[i].Importance = importance
+		if m.records[i].Metadata != nil {
+			m.records[i].Metadata["importance"] = importance
+		}
+		reinforced++
+	}
+
+	return reinforced, nil
+}
+
 // Forget 执行遗忘
 func (m *SemanticMemoryStore) Forget(ctx context.Context, strategy ForgetStrategy, opts ...ForgetOption) (int, error) {
 	m.mu.Lock()
@@ -1147,8 +1652,48 @@ func (m *SemanticMemoryStore) Forget(ctx context.Context, strategy ForgetStrateg
 		})
 	}
 
+	kept := make(map[string]bool, len(remaining))
+	for _, rec := range remaining {
+		kept[rec.ID] = true
+	}
+	var forgotten []semanticRecord
+	for _, rec := range m.records {
+		if !kept[rec.ID] {
+			forgotten = append(forgotten, rec)
+		}
+	}
+
+	if options.archive != nil {
+		items := make([]*MemoryItem, len(forgotten))
+		for i, rec := range forgotten {
+			items[i] = &MemoryItem{
+				ID:         rec.ID,
+				Content:    rec.Content,
+				MemoryType: MemoryTypeSemantic,
+				UserID:     rec.UserID,
+				Timestamp:  rec.Timestamp,
+				Importance: rec.Importance,
+				Metadata:   cloneMetadata(rec.Metadata),
+			}
+		}
+		if err := options.archiveItems(ctx, strategy, items); err != nil {
+			return 0, err
+		}
+	}
+
+	if m.vectors != nil {
+		ids := make([]string, len(forgotten))
+		for i, rec := range forgotten {
+			ids[i] = rec.ID
+		}
+		if err := m.deleteVectors(ctx, ids); err != nil {
+			return 0, err
+		}
+	}
+
 	m.records = remaining
-	m.rebuildTFIDF()
+	m.tfidfStale = true
+	m.forgotten.record(time.Now(), originalCount-len(m.records))
 
 	return originalCount - len(m.records), nil
 }
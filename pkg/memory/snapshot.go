@@ -0,0 +1,551 @@
+package memory
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/ahhsitt/helloagents-go/pkg/core/message"
+	"github.com/ahhsitt/helloagents-go/pkg/memory/store"
+)
+
+// SnapshotVersion 当前快照格式版本
+const SnapshotVersion = 1
+
+// 快照行类型
+const (
+	// SnapshotKindHeader 文件头，记录格式版本和导出时间
+	SnapshotKindHeader = "header"
+	// SnapshotKindMessage 工作记忆消息
+	SnapshotKindMessage = "message"
+	// SnapshotKindEpisode 情景记忆事件
+	SnapshotKindEpisode = "episode"
+	// SnapshotKindSemantic 语义记忆记录
+	SnapshotKindSemantic = "semantic"
+	// SnapshotKindEntity 知识图谱实体
+	SnapshotKindEntity = "entity"
+	// SnapshotKindRelation 知识图谱关系
+	SnapshotKindRelation = "relation"
+	// SnapshotKindProcedure 程序性记忆程序
+	SnapshotKindProcedure = "procedure"
+)
+
+// SnapshotRecord JSONL 快照中的一行
+//
+// Kind 决定哪个字段有效；MemoryType 记录所属的记忆类型，导入时据此分发到已注册的存储。
+type SnapshotRecord struct {
+	// Kind 行类型
+	Kind string `json:"kind"`
+	// MemoryType 所属记忆类型（文件头为空）
+	MemoryType MemoryType `json:"memory_type,omitempty"`
+
+	// Version 快照格式版本（仅文件头）
+	Version int `json:"version,omitempty"`
+	// ExportedAt 导出时间（仅文件头）
+	ExportedAt *time.Time `json:"exported_at,omitempty"`
+
+	// Message 工作记忆消息
+	Message *message.Message `json:"message,omitempty"`
+	// Importance 消息重要性
+	Importance float32 `json:"importance,omitempty"`
+	// Episode 情景记忆事件
+	Episode *Episode `json:"episode,omitempty"`
+	// Semantic 语义记忆记录
+	Semantic *SemanticSnapshot `json:"semantic,omitempty"`
+	// Entity 知识图谱实体
+	Entity *Entity `json:"entity,omitempty"`
+	// Relation 知识图谱关系
+	Relation *Relation `json:"relation,omitempty"`
+	// Procedure 程序性记忆程序
+	Procedure *Procedure `json:"procedure,omitempty"`
+}
+
+// SemanticSnapshot 语义记忆记录快照
+type SemanticSnapshot struct {
+	ID         string                 `json:"id"`
+	Content    string                 `json:"content"`
+	Metadata   map[string]interface{} `json:"metadata,omitempty"`
+	Importance float32                `json:"importance"`
+	Timestamp  time.Time              `json:"timestamp"`
+	UserID     string                 `json:"user_id,omitempty"`
+	// Vector 嵌入向量，导入时存在则不再重新嵌入
+	Vector []float32 `json:"vector,omitempty"`
+}
+
+// Snapshotter 支持快照导出和导入的记忆存储
+//
+// 内置的 WorkingMemory、EpisodicMemoryStore、SemanticMemoryStore 和 ProceduralMemoryStore 均已实现；
+// 自定义存储实现该接口后即可参与 MemoryManager.Export/Import。
+type Snapshotter interface {
+	// ExportSnapshot 导出存储中的全部数据
+	ExportSnapshot(ctx context.Context) ([]SnapshotRecord, error)
+
+	// ImportSnapshot 将快照数据追加到存储中
+	ImportSnapshot(ctx context.Context, records []SnapshotRecord) error
+}
+
+// Export 将所有已注册记忆导出为 JSONL 快照
+//
+// 第一行是文件头（格式版本和导出时间），之后每行一条记录，按记忆类型名称排序。
+// 任一已注册存储未实现 Snapshotter 时返回 ErrSnapshotUnsupported。
+func (m *MemoryManager) Export(ctx context.Context, w io.Writer) error {
+	m.mu.RLock()
+	types := make([]MemoryType, 0, len(m.memoryTypes))
+	memories := make(map[MemoryType]Memory, len(m.memoryTypes))
+	for memType, mem := range m.memoryTypes {
+		types = append(types, memType)
+		memories[memType] = mem
+	}
+	m.mu.RUnlock()
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+
+	snapshotters := make([]Snapshotter, len(types))
+	for i, memType := range types {
+		s, ok := memories[memType].(Snapshotter)
+		if !ok {
+			return fmt.Errorf("export %s memory: %w", memType, ErrSnapshotUnsupported)
+		}
+		snapshotters[i] = s
+	}
+
+	buf := bufio.NewWriter(w)
+	enc := json.NewEncoder(buf)
+
+	now := time.Now()
+	if err := enc.Encode(SnapshotRecord{Kind: SnapshotKindHeader, Version: SnapshotVersion, ExportedAt: &now}); err != nil {
+		return fmt.Errorf("write snapshot header: %w", err)
+	}
+
+	for i, s := range snapshotters {
+		records, err := s.ExportSnapshot(ctx)
+		if err != nil {
+			return fmt.Errorf("export %s memory: %w", types[i], err)
+		}
+		for _, rec := range records {
+			rec.MemoryType = types[i]
+			if err := enc.Encode(rec); err != nil {
+				return fmt.Errorf("write %s snapshot: %w", types[i], err)
+			}
+		}
+	}
+
+	return buf.Flush()
+}
+
+// Import 从 JSONL 快照恢复记忆
+//
+// 记录按 memory_type 分发到已注册的存储并追加到现有数据中；
+// 快照中的记忆类型未注册时返回 ErrMemoryTypeNotFound，版本高于 SnapshotVersion 时返回 ErrSnapshotVersion。
+func (m *MemoryManager) Import(ctx context.Context, r io.Reader) error {
+	dec := json.NewDecoder(bufio.NewReader(r))
+
+	var header SnapshotRecord
+	if err := dec.Decode(&header); err != nil {
+		return fmt.Errorf("read snapshot header: %w", err)
+	}
+	if header.Kind != SnapshotKindHeader {
+		return fmt.Errorf("read snapshot header: %w: first line is %q", ErrInvalidInput, header.Kind)
+	}
+	if header.Version < 1 || header.Version > SnapshotVersion {
+		return fmt.Errorf("%w: %d", ErrSnapshotVersion, header.Version)
+	}
+
+	var types []MemoryType
+	grouped := make(map[MemoryType][]SnapshotRecord)
+	for {
+		var rec SnapshotRecord
+		if err := dec.Decode(&rec); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return fmt.Errorf("read snapshot record: %w", err)
+		}
+		if _, ok := grouped[rec.MemoryType]; !ok {
+			types = append(types, rec.MemoryType)
+		}
+		grouped[rec.MemoryType] = append(grouped[rec.MemoryType], rec)
+	}
+
+	m.mu.RLock()
+	snapshotters := make([]Snapshotter, len(types))
+	for i, memType := range types {
+		mem, ok := m.memoryTypes[memType]
+		if !ok {
+			m.mu.RUnlock()
+			return fmt.Errorf("import %s memory: %w", memType, ErrMemoryTypeNotFound)
+		}
+		s, ok := mem.(Snapshotter)
+		if !ok {
+			m.mu.RUnlock()
+			return fmt.Errorf("import %s memory: %w", memType, ErrSnapshotUnsupported)
+		}
+		snapshotters[i] = s
+	}
+	m.mu.RUnlock()
+
+	for i, s := range snapshotters {
+		if err := s.ImportSnapshot(ctx, grouped[types[i]]); err != nil {
+			return fmt.Errorf("import %s memory: %w", types[i], err)
+		}
+	}
+	return nil
+}
+
+// unexpectedSnapshotRecord 返回记录类型与存储不匹配的错误
+func unexpectedSnapshotRecord(kind string) error {
+	return fmt.Errorf("%w: unexpected %q snapshot record", ErrInvalidInput, kind)
+}
+
+// ExportSnapshot 导出所有消息（实现 Snapshotter 接口）
+func (m *WorkingMemory) ExportSnapshot(ctx context.Context) ([]SnapshotRecord, error) {
+	if err := m.ensureLoaded(ctx); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	records := make([]SnapshotRecord, 0, len(m.messages))
+	for _, wm := range m.messages {
+		msg := wm.Message
+		records = append(records, SnapshotRecord{
+			Kind:       SnapshotKindMessage,
+			Message:    &msg,
+			Importance: wm.Importance,
+		})
+	}
+	return records, nil
+}
+
+// ImportSnapshot 追加快照中的消息，已存在的消息被跳过（实现 Snapshotter 接口）
+func (m *WorkingMemory) ImportSnapshot(ctx context.Context, records []SnapshotRecord) error {
+	for _, rec := range records {
+		if rec.Kind != SnapshotKindMessage || rec.Message == nil {
+			return unexpectedSnapshotRecord(rec.Kind)
+		}
+	}
+
+	if err := m.ensureLoaded(ctx); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	existing := make(map[string]struct{}, len(m.messages))
+	for _, wm := range m.messages {
+		existing[snapshotMessageKey(wm.Message)] = struct{}{}
+	}
+
+	var evicted []string
+	for _, rec := range records {
+		key := snapshotMessageKey(*rec.Message)
+		if _, ok := existing[key]; ok {
+			continue
+		}
+		existing[key] = struct{}{}
+		ids, err := m.appendLocked(ctx, *rec.Message, rec.Importance)
+		if err != nil {
+			return err
+		}
+		evicted = append(evicted, ids...)
+	}
+	m.tfidfStale = true
+
+	if m.persist != nil && len(evicted) > 0 {
+		return m.persist.delete(ctx, evicted...)
+	}
+	return nil
+}
+
+// snapshotMessageKey 返回消息的去重键，没有 ID 的消息按角色、时间戳和内容识别
+func snapshotMessageKey(msg message.Message) string {
+	if msg.ID != "" {
+		return msg.ID
+	}
+	return fmt.Sprintf("%s|%d|%s", msg.Role, msg.Timestamp.UnixNano(), msg.Content)
+}
+
+// ExportSnapshot 导出所有事件（实现 Snapshotter 接口）
+func (m *EpisodicMemoryStore) ExportSnapshot(ctx context.Context) ([]SnapshotRecord, error) {
+	if err := m.ensureLoaded(ctx); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	records := make([]SnapshotRecord, 0, len(m.episodes))
+	for _, ep := range m.episodes {
+		ep.Vector = nil
+		episode := ep
+		records = append(records, SnapshotRecord{
+			Kind:    SnapshotKindEpisode,
+			Episode: &episode,
+		})
+	}
+	return records, nil
+}
+
+// ImportSnapshot 追加快照中的事件，已存在的事件 ID 被跳过（实现 Snapshotter 接口）
+func (m *EpisodicMemoryStore) ImportSnapshot(ctx context.Context, records []SnapshotRecord) error {
+	for _, rec := range records {
+		if rec.Kind != SnapshotKindEpisode || rec.Episode == nil {
+			return unexpectedSnapshotRecord(rec.Kind)
+		}
+	}
+
+	if err := m.ensureLoaded(ctx); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	existing := make(map[string]struct{}, len(m.episodes))
+	for _, ep := range m.episodes {
+		existing[ep.ID] = struct{}{}
+	}
+
+	for _, rec := range records {
+		if _, ok := existing[rec.Episode.ID]; ok {
+			continue
+		}
+		if _, err := m.appendLocked(ctx, *rec.Episode); err != nil {
+			return err
+		}
+	}
+	m.tfidfStale = true
+	return nil
+}
+
+// ExportSnapshot 导出语义记录、实体和关系（实现 Snapshotter 接口）
+//
+// 配置了向量数据库时本地不保存向量，导出的记录不含向量，导入时重新嵌入；
+// 配置了图数据库时实体和关系从图数据库读取。
+func (m *SemanticMemoryStore) ExportSnapshot(ctx context.Context) ([]SnapshotRecord, error) {
+	m.mu.RLock()
+	records := make([]SnapshotRecord, 0, len(m.records))
+	for _, rec := range m.records {
+		records = append(records, SnapshotRecord{
+			Kind: SnapshotKindSemantic,
+			Semantic: &SemanticSnapshot{
+				ID:         rec.ID,
+				Content:    rec.Content,
+				Metadata:   rec.Metadata,
+				Importance: rec.Importance,
+				Timestamp:  rec.Timestamp,
+				UserID:     rec.UserID,
+				Vector:     rec.vector(),
+			},
+		})
+	}
+
+	m.mu.RUnlock()
+
+	var entities []*Entity
+	var relations []*Relation
+	if m.graph == nil {
+		m.graphMu.RLock()
+		entities = make([]*Entity, 0, len(m.entities))
+		for _, entity := range m.entities {
+			entities = append(entities, entity)
+		}
+		relations = make([]*Relation, 0, len(m.relations))
+		for _, relation := range m.relations {
+			relations = append(relations, relation)
+		}
+		m.graphMu.RUnlock()
+	}
+
+	if m.graph != nil {
+		var err error
+		entities, relations, err = m.exportGraph(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// 按 ID 排序，使相同数据得到相同的快照
+	sort.Slice(entities, func(i, j int) bool { return entities[i].ID < entities[j].ID })
+	sort.Slice(relations, func(i, j int) bool { return relations[i].ID < relations[j].ID })
+
+	for _, entity := range entities {
+		records = append(records, SnapshotRecord{Kind: SnapshotKindEntity, Entity: entity})
+	}
+	for _, relation := range relations {
+		records = append(records, SnapshotRecord{Kind: SnapshotKindRelation, Relation: relation})
+	}
+	return records, nil
+}
+
+// exportGraph 从图数据库读取全部实体和关系
+func (m *SemanticMemoryStore) exportGraph(ctx context.Context) ([]*Entity, []*Relation, error) {
+	nodes, err := m.graph.SearchEntities(ctx, "", "", math.MaxInt32)
+	if err != nil {
+		return nil, nil, graphError("export graph entities", err)
+	}
+
+	entities := make([]*Entity, 0, len(nodes))
+	relations := make([]*Relation, 0)
+	seen := make(map[string]struct{})
+	for _, node := range nodes {
+		entities = append(entities, graphToEntity(node))
+
+		rels, err := m.graph.GetRelations(ctx, node.ID)
+		if err != nil {
+			return nil, nil, graphError("export graph relations", err)
+		}
+		for _, rel := range rels {
+			if _, ok := seen[rel.ID]; ok {
+				continue
+			}
+			seen[rel.ID] = struct{}{}
+			relations = append(relations, graphToRelation(rel))
+		}
+	}
+	return entities, relations, nil
+}
+
+// ImportSnapshot 导入语义记录、实体和关系（实现 Snapshotter 接口）
+//
+// 同 ID 的记录被覆盖；没有向量的记录在配置了嵌入器时重新嵌入。
+// 实体和关系经由 AddEntity/AddRelation 写入，因此同样会写入配置的图数据库。
+func (m *SemanticMemoryStore) ImportSnapshot(ctx context.Context, records []SnapshotRecord) error {
+	var semantic []*SemanticSnapshot
+	var entities []*Entity
+	var relations []*Relation
+	for _, rec := range records {
+		switch {
+		case rec.Kind == SnapshotKindSemantic && rec.Semantic != nil:
+			semantic = append(semantic, rec.Semantic)
+		case rec.Kind == SnapshotKindEntity && rec.Entity != nil:
+			entities = append(entities, rec.Entity)
+		case rec.Kind == SnapshotKindRelation && rec.Relation != nil:
+			relations = append(relations, rec.Relation)
+		default:
+			return unexpectedSnapshotRecord(rec.Kind)
+		}
+	}
+
+	if err := m.importRecords(ctx, semantic); err != nil {
+		return err
+	}
+	for _, entity := range entities {
+		if err := m.AddEntity(ctx, entity); err != nil {
+			return fmt.Errorf("import entity %s: %w", entity.ID, err)
+		}
+	}
+	for _, relation := range relations {
+		if err := m.AddRelation(ctx, relation); err != nil {
+			return fmt.Errorf("import relation %s: %w", relation.ID, err)
+		}
+	}
+	return nil
+}
+
+// importRecords 写入语义记录，保留快照中的时间戳和重要性
+func (m *SemanticMemoryStore) importRecords(ctx context.Context, snapshots []*SemanticSnapshot) error {
+	if len(snapshots) == 0 {
+		return nil
+	}
+
+	// 批量嵌入缺少向量的记录
+	if m.embedder != nil {
+		var missing []int
+		var contents []string
+		for i, snap := range snapshots {
+			if snap.Vector == nil {
+				missing = append(missing, i)
+				contents = append(contents, snap.Content)
+			}
+		}
+		if len(missing) > 0 {
+			vectors, err := m.embedder.Embed(ctx, contents)
+			if err != nil {
+				return err
+			}
+			if len(vectors) != len(missing) {
+				return ErrEmbeddingFailed
+			}
+			for j, i := range missing {
+				snapshots[i].Vector = vectors[j]
+			}
+		}
+	}
+
+	ids := make([]string, len(snapshots))
+	vectorRecords := make([]store.VectorRecord, len(snapshots))
+	for i, snap := range snapshots {
+		ids[i] = snap.ID
+		vectorRecords[i] = vectorRecord(snap.ID, snap.Content, snap.Vector, snap.Metadata)
+	}
+
+	unlock := m.writeLocks.lock(ids...)
+	defer unlock()
+
+	if err := m.putVectors(ctx, vectorRecords); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, snap := range snapshots {
+		i := m.upsertLocked(snap.ID, snap.Content, snap.Vector, snap.Metadata)
+		m.records[i].Importance = snap.Importance
+		m.records[i].UserID = snap.UserID
+		if !snap.Timestamp.IsZero() {
+			m.records[i].Timestamp = snap.Timestamp
+		}
+	}
+	m.tfidfStale = true
+	return nil
+}
+
+// ExportSnapshot 导出所有程序（实现 Snapshotter 接口）
+func (m *ProceduralMemoryStore) ExportSnapshot(ctx context.Context) ([]SnapshotRecord, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	records := make([]SnapshotRecord, 0, len(m.procedures))
+	for _, p := range m.procedures {
+		p.Vector = nil
+		procedure := p
+		records = append(records, SnapshotRecord{
+			Kind:      SnapshotKindProcedure,
+			Procedure: &procedure,
+		})
+	}
+	return records, nil
+}
+
+// ImportSnapshot 导入快照中的程序，同 ID 的程序被覆盖（实现 Snapshotter 接口）
+func (m *ProceduralMemoryStore) ImportSnapshot(ctx context.Context, records []SnapshotRecord) error {
+	for _, rec := range records {
+		if rec.Kind != SnapshotKindProcedure || rec.Procedure == nil {
+			return unexpectedSnapshotRecord(rec.Kind)
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, rec := range records {
+		m.putLocked(*rec.Procedure)
+	}
+	m.tfidfStale = true
+	return nil
+}
+
+// compile-time interface check
+var _ Snapshotter = (*WorkingMemory)(nil)
+var _ Snapshotter = (*EpisodicMemoryStore)(nil)
+var _ Snapshotter = (*SemanticMemoryStore)(nil)
+var _ Snapshotter = (*ProceduralMemoryStore)(nil)
@@ -0,0 +1,251 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// StatsWindow 统计近期新增和遗忘数量的时间窗口
+const StatsWindow = 24 * time.Hour
+
+// statsSnapshotter 支持一致快照统计的记忆存储
+//
+// MemoryManager.GetStats 先对所有存储调用 prepareStats，再同时持有所有存储的读锁
+// 计算统计，使各类型的统计来自同一时刻。内置记忆类型均已实现。
+type statsSnapshotter interface {
+	// prepareStats 在加锁前完成懒加载和索引重建
+	prepareStats(ctx context.Context, options *retrieveOptions) error
+	// statsLocker 返回统计期间需要持有的读锁
+	statsLocker() sync.Locker
+	// statsLocked 计算统计信息（调用方需持有 statsLocker）
+	statsLocked(options *retrieveOptions, now time.Time) *MemoryStats
+}
+
+// collectStats 按 GetStats 的语义计算单个存储的统计信息
+func collectStats(ctx context.Context, s statsSnapshotter, opts []RetrieveOption) (*MemoryStats, error) {
+	options := &retrieveOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	if err := s.prepareStats(ctx, options); err != nil {
+		return nil, err
+	}
+
+	locker := s.statsLocker()
+	locker.Lock()
+	defer locker.Unlock()
+	return s.statsLocked(options, time.Now()), nil
+}
+
+// statsAccumulator 累加记忆项的统计信息
+type statsAccumulator struct {
+	now             time.Time
+	stats           MemoryStats
+	totalImportance float32
+}
+
+// add 累加一条记忆，extraBytes 为内容和元数据之外的存储开销（如向量）
+func (a *statsAccumulator) add(content string, metadata map[string]interface{}, importance float32, ts time.Time, extraBytes int64) {
+	ms := ts.UnixMilli()
+	if a.stats.Count == 0 || ms < a.stats.OldestTimestamp {
+		a.stats.OldestTimestamp = ms
+	}
+	if a.stats.Count == 0 || ms > a.stats.NewestTimestamp {
+		a.stats.NewestTimestamp = ms
+	}
+	a.stats.Count++
+	a.totalImportance += importance
+	a.stats.TotalTokens += estimateTokenCount(content)
+	a.stats.StorageBytes += int64(len(content)) + estimateValueBytes(metadata) + extraBytes
+	if ts.After(a.now.Add(-StatsWindow)) {
+		a.stats.AddedLast24h++
+	}
+}
+
+// result 返回统计结果，forgotten 为窗口内被遗忘的记忆数
+func (a *statsAccumulator) result(forgotten int) *MemoryStats {
+	stats := a.stats
+	if stats.Count > 0 {
+		stats.AvgImportance = a.totalImportance / float32(stats.Count)
+	}
+	stats.ForgottenLast24h = forgotten
+	return &stats
+}
+
+// estimateTokenCount 估算文本的 token 数（1 token ≈ 3 字节）
+func estimateTokenCount(text string) int {
+	return len(text) / 3
+}
+
+// estimateValueBytes 估算元数据值占用的字节数，标量按 8 字节计
+func estimateValueBytes(v interface{}) int64 {
+	switch val := v.(type) {
+	case nil:
+		return 0
+	case string:
+		return int64(len(val))
+	case []byte:
+		return int64(len(val))
+	case []float32:
+		return int64(len(val)) * 4
+	case []string:
+		var n int64
+		for _, s := range val {
+			n += int64(len(s))
+		}
+		return n
+	case []interface{}:
+		var n int64
+		for _, item := range val {
+			n += estimateValueBytes(item)
+		}
+		return n
+	case map[string]interface{}:
+		var n int64
+		for k, item := range val {
+			n += int64(len(k)) + estimateValueBytes(item)
+		}
+		return n
+	default:
+		return 8
+	}
+}
+
+// forgetLog 记录遗忘事件，用于统计窗口内的遗忘数量
+//
+// 在存储的写锁内调用 record，在读锁内调用 since。
+type forgetLog struct {
+	events []forgetEvent
+}
+
+type forgetEvent struct {
+	at    time.Time
+	count int
+}
+
+// record 记录一次遗忘，并丢弃窗口之外的旧事件
+func (l *forgetLog) record(now time.Time, count int) {
+	if count <= 0 {
+		return
+	}
+	cutoff := now.Add(-StatsWindow)
+	kept := l.events[:0]
+	for _, e := range l.events {
+		if e.at.After(cutoff) {
+			kept = append(kept, e)
+		}
+	}
+	l.events = append(kept, forgetEvent{at: now, count: count})
+}
+
+// since 返回 cutoff 之后被遗忘的记忆数
+func (l *forgetLog) since(cutoff time.Time) int {
+	total := 0
+	for _, e := range l.events {
+		if e.at.After(cutoff) {
+			total += e.count
+		}
+	}
+	return total
+}
+
+// scopedForgotten 返回窗口内的遗忘数量
+//
+// 遗忘记录不区分用户和会话，限定了统计范围时返回 0。
+func scopedForgotten(l *forgetLog, options *retrieveOptions, now time.Time) int {
+	if options.scoped() {
+		return 0
+	}
+	return l.since(now.Add(-StatsWindow))
+}
+
+// ============================================================================
+// 各记忆类型的快照统计
+// ============================================================================
+
+func (m *WorkingMemory) prepareStats(ctx context.Context, _ *retrieveOptions) error {
+	return m.ensureLoaded(ctx)
+}
+
+func (m *WorkingMemory) statsLocker() sync.Locker {
+	return m.mu.RLocker()
+}
+
+func (m *WorkingMemory) statsLocked(options *retrieveOptions, now time.Time) *MemoryStats {
+	acc := statsAccumulator{now: now}
+	for _, wm := range filterScope(m.filterExpired(), options) {
+		acc.add(wm.Message.Content, wm.Message.Metadata, wm.Importance, wm.Message.Timestamp, 0)
+	}
+	return acc.result(scopedForgotten(&m.forgotten, options, now))
+}
+
+func (m *EpisodicMemoryStore) prepareStats(ctx context.Context, options *retrieveOptions) error {
+	if err := m.ensureLoaded(ctx); err != nil {
+		return err
+	}
+	if options.scoped() {
+		m.ensureTFIDF()
+	}
+	return nil
+}
+
+func (m *EpisodicMemoryStore) statsLocker() sync.Locker {
+	return m.mu.RLocker()
+}
+
+func (m *EpisodicMemoryStore) statsLocked(options *retrieveOptions, now time.Time) *MemoryStats {
+	acc := statsAccumulator{now: now}
+	for _, ep := range m.scopedEpisodes(options) {
+		extra := int64(len(ep.Type)+len(ep.UserID)+len(ep.SessionID)+len(ep.Outcome)) + estimateValueBytes(ep.Context)
+		acc.add(ep.Content, ep.Metadata, ep.Importance, time.UnixMilli(ep.Timestamp), extra)
+	}
+	return acc.result(scopedForgotten(&m.forgotten, options, now))
+}
+
+func (m *SemanticMemoryStore) prepareStats(_ context.Context, options *retrieveOptions) error {
+	if options.scoped() {
+		m.ensureTFIDF()
+	}
+	return nil
+}
+
+func (m *SemanticMemoryStore) statsLocker() sync.Locker {
+	return m.mu.RLocker()
+}
+
+func (m *SemanticMemoryStore) statsLocked(options *retrieveOptions, now time.Time) *MemoryStats {
+	acc := statsAccumulator{now: now}
+	for _, rec := range m.scopedRecords(options) {
+		acc.add(rec.Content, rec.Metadata, rec.Importance, rec.Timestamp, rec.vectorBytes())
+	}
+	return acc.result(scopedForgotten(&m.forgotten, options, now))
+}
+
+func (m *ProceduralMemoryStore) prepareStats(_ context.Context, options *retrieveOptions) error {
+	if options.userID != "" {
+		m.ensureTFIDF()
+	}
+	return nil
+}
+
+func (m *ProceduralMemoryStore) statsLocker() sync.Locker {
+	return m.mu.RLocker()
+}
+
+func (m *ProceduralMemoryStore) statsLocked(options *retrieveOptions, now time.Time) *MemoryStats {
+	acc := statsAccumulator{now: now}
+	for _, pos := range m.scopedPositions(options) {
+		p := m.procedures[pos]
+		acc.add(p.Format(), p.Metadata, p.Importance, p.CreatedAt, 0)
+	}
+	return acc.result(0)
+}
+
+// compile-time interface check
+var (
+	_ statsSnapshotter = (*WorkingMemory)(nil)
+	_ statsSnapshotter = (*EpisodicMemoryStore)(nil)
+	_ statsSnapshotter = (*SemanticMemoryStore)(nil)
+	_ statsSnapshotter = (*ProceduralMemoryStore)(nil)
+)
@@ -9,6 +9,14 @@ func NewDocumentStore(config *Config) (DocumentStore, error) {
 	switch config.Type {
 	case StoreTypeSQLite:
 		return NewSQLiteDocumentStore(config.SQLitePath)
+	case StoreTypeRedis:
+		return NewRedisDocumentStore(RedisConfig{
+			Addr:      config.RedisAddr,
+			Password:  config.RedisPassword,
+			DB:        config.RedisDB,
+			KeyPrefix: config.RedisKeyPrefix,
+			TTL:       config.RedisTTL,
+		})
 	case StoreTypeMemory:
 		fallthrough
 	default:
@@ -29,6 +37,20 @@ func NewVectorStore(config *Config) (VectorStore, error) {
 			APIKey:     config.QdrantAPIKey,
 			Dimensions: config.VectorDimensions,
 		})
+	case StoreTypePgVector:
+		return NewPgVectorStore(PgVectorConfig{
+			DSN:        config.PgVectorDSN,
+			DriverName: config.PgVectorDriver,
+			Table:      config.PgVectorTable,
+			Dimensions: config.VectorDimensions,
+			Index:      PgVectorIndex(config.PgVectorIndex),
+		})
+	case StoreTypeSQLite:
+		return NewSQLiteVectorStore(SQLiteVectorConfig{
+			Path:         config.SQLitePath,
+			Dimensions:   config.VectorDimensions,
+			VecExtension: config.SQLiteVecExtension,
+		})
 	case StoreTypeMemory:
 		fallthrough
 	default:
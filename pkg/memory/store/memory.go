@@ -97,20 +97,25 @@ func (s *MemoryDocumentStore) Query(ctx context.Context, collection string, filt
 
 	var results []Document
 	for _, doc := range s.collections[collection] {
-		if s.matchFilter(doc, filter) {
+		if matchFilter(doc, filter) {
 			results = append(results, *doc)
 		}
 	}
 
+	return sortAndPage(results, options), nil
+}
+
+// sortAndPage 对查询结果排序和分页（内存和 Redis 文档存储共用）
+func sortAndPage(results []Document, options *queryOptions) []Document {
 	// 排序
 	if options.orderBy != "" {
 		sort.Slice(results, func(i, j int) bool {
-			vi := s.getFieldValue(&results[i], options.orderBy)
-			vj := s.getFieldValue(&results[j], options.orderBy)
+			vi := getFieldValue(&results[i], options.orderBy)
+			vj := getFieldValue(&results[j], options.orderBy)
 			if options.desc {
-				return s.compareValues(vi, vj) > 0
+				return compareValues(vi, vj) > 0
 			}
-			return s.compareValues(vi, vj) < 0
+			return compareValues(vi, vj) < 0
 		})
 	}
 
@@ -122,7 +127,7 @@ func (s *MemoryDocumentStore) Query(ctx context.Context, collection string, filt
 		results = results[:options.limit]
 	}
 
-	return results, nil
+	return results
 }
 
 // Count 统计数量
@@ -136,7 +141,7 @@ func (s *MemoryDocumentStore) Count(ctx context.Context, collection string, filt
 
 	count := 0
 	for _, doc := range s.collections[collection] {
-		if s.matchFilter(doc, filter) {
+		if matchFilter(doc, filter) {
 			count++
 		}
 	}
@@ -159,7 +164,7 @@ func (s *MemoryDocumentStore) Close() error {
 }
 
 // matchFilter 检查文档是否匹配过滤条件
-func (s *MemoryDocumentStore) matchFilter(doc *Document, filter Filter) bool {
+func matchFilter(doc *Document, filter Filter) bool {
 	// 空过滤器匹配所有
 	if filter.Field == "" && len(filter.And) == 0 && len(filter.Or) == 0 {
 		return true
@@ -168,7 +173,7 @@ func (s *MemoryDocumentStore) matchFilter(doc *Document, filter Filter) bool {
 	// 处理 And 条件
 	if len(filter.And) > 0 {
 		for _, f := range filter.And {
-			if !s.matchFilter(doc, f) {
+			if !matchFilter(doc, f) {
 				return false
 			}
 		}
@@ -178,7 +183,7 @@ func (s *MemoryDocumentStore) matchFilter(doc *Document, filter Filter) bool {
 	// 处理 Or 条件
 	if len(filter.Or) > 0 {
 		for _, f := range filter.Or {
-			if s.matchFilter(doc, f) {
+			if matchFilter(doc, f) {
 				return true
 			}
 		}
@@ -186,12 +191,12 @@ func (s *MemoryDocumentStore) matchFilter(doc *Document, filter Filter) bool {
 	}
 
 	// 处理单个条件
-	value := s.getFieldValue(doc, filter.Field)
-	return s.matchCondition(value, filter.Op, filter.Value)
+	value := getFieldValue(doc, filter.Field)
+	return matchCondition(value, filter.Op, filter.Value)
 }
 
 // getFieldValue 获取文档字段值
-func (s *MemoryDocumentStore) getFieldValue(doc *Document, field string) interface{} {
+func getFieldValue(doc *Document, field string) interface{} {
 	switch field {
 	case "id":
 		return doc.ID
@@ -210,20 +215,20 @@ func (s *MemoryDocumentStore) getFieldValue(doc *Document, field string) interfa
 }
 
 // matchCondition 匹配条件
-func (s *MemoryDocumentStore) matchCondition(value interface{}, op string, target interface{}) bool {
+func matchCondition(value interface{}, op string, target interface{}) bool {
 	switch op {
 	case "eq", "":
-		return s.compareValues(value, target) == 0
+		return compareValues(value, target) == 0
 	case "ne":
-		return s.compareValues(value, target) != 0
+		return compareValues(value, target) != 0
 	case "gt":
-		return s.compareValues(value, target) > 0
+		return compareValues(value, target) > 0
 	case "gte":
-		return s.compareValues(value, target) >= 0
+		return compareValues(value, target) >= 0
 	case "lt":
-		return s.compareValues(value, target) < 0
+		return compareValues(value, target) < 0
 	case "lte":
-		return s.compareValues(value, target) <= 0
+		return compareValues(value, target) <= 0
 	case "contains":
 		if str, ok := value.(string); ok {
 			if tgt, ok := target.(string); ok {
@@ -234,7 +239,7 @@ func (s *MemoryDocumentStore) matchCondition(value interface{}, op string, targe
 	case "in":
 		if arr, ok := target.([]interface{}); ok {
 			for _, v := range arr {
-				if s.compareValues(value, v) == 0 {
+				if compareValues(value, v) == 0 {
 					return true
 				}
 			}
@@ -246,7 +251,7 @@ func (s *MemoryDocumentStore) matchCondition(value interface{}, op string, targe
 }
 
 // compareValues 比较两个值
-func (s *MemoryDocumentStore) compareValues(a, b interface{}) int {
+func compareValues(a, b interface{}) int {
 	if a == nil && b == nil {
 		return 0
 	}
@@ -450,6 +455,29 @@ func (s *MemoryVectorStore) DeleteByFilter(ctx context.Context, collection strin
 	return nil
 }
 
+// ScanVectors 遍历集合中满足过滤条件的记录
+func (s *MemoryVectorStore) ScanVectors(ctx context.Context, collection string, filter *VectorFilter, fn func(VectorRecord) error) error {
+	s.mu.RLock()
+	var matched []VectorRecord
+	for _, rec := range s.collections[collection] {
+		if s.matchVectorFilter(rec, filter) {
+			rec.Vector = nil
+			matched = append(matched, rec)
+		}
+	}
+	s.mu.RUnlock()
+
+	for _, rec := range matched {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(rec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Clear 清空集合
 func (s *MemoryVectorStore) Clear(ctx context.Context, collection string) error {
 	s.mu.Lock()
@@ -555,6 +583,7 @@ func cosineSimilarity(a, b []float32) float32 {
 
 // Compile-time interface check
 var _ VectorStore = (*MemoryVectorStore)(nil)
+var _ VectorScanner = (*MemoryVectorStore)(nil)
 
 // ============================================================================
 // Memory Graph Store
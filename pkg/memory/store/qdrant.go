@@ -8,6 +8,9 @@ import (
 	"io"
 	"net/http"
 	"time"
+
+	"github.com/ahhsitt/helloagents-go/pkg/core/errors"
+	"github.com/ahhsitt/helloagents-go/pkg/core/retry"
 )
 
 // QdrantVectorStore Qdrant 向量存储
@@ -18,6 +21,7 @@ type QdrantVectorStore struct {
 	apiKey     string
 	httpClient *http.Client
 	dimensions int
+	retry      *retry.Policy
 }
 
 // QdrantConfig Qdrant 配置
@@ -26,6 +30,8 @@ type QdrantConfig struct {
 	APIKey     string
 	Dimensions int
 	Timeout    time.Duration
+	// Retry 请求重试策略，为空时使用全局默认策略
+	Retry *retry.Policy
 }
 
 // NewQdrantVectorStore 创建 Qdrant 向量存储
@@ -45,6 +51,7 @@ func NewQdrantVectorStore(config QdrantConfig) (*QdrantVectorStore, error) {
 		apiKey:     config.APIKey,
 		dimensions: config.Dimensions,
 		httpClient: &http.Client{Timeout: config.Timeout},
+		retry:      config.Retry,
 	}
 
 	return store, nil
@@ -58,7 +65,7 @@ func (s *QdrantVectorStore) ensureCollection(ctx context.Context, collection str
 		return err
 	}
 
-	resp, err := s.httpClient.Do(req)
+	resp, err := s.do(req)
 	if err != nil {
 		return fmt.Errorf("failed to check collection: %w", err)
 	}
@@ -82,7 +89,7 @@ func (s *QdrantVectorStore) ensureCollection(ctx context.Context, collection str
 		return err
 	}
 
-	resp, err = s.httpClient.Do(req)
+	resp, err = s.do(req)
 	if err != nil {
 		return fmt.Errorf("failed to create collection: %w", err)
 	}
@@ -121,7 +128,7 @@ func (s *QdrantVectorStore) AddVectors(ctx context.Context, collection string, v
 		return err
 	}
 
-	resp, err := s.httpClient.Do(req)
+	resp, err := s.do(req)
 	if err != nil {
 		return fmt.Errorf("failed to upsert vectors: %w", err)
 	}
@@ -167,7 +174,7 @@ func (s *QdrantVectorStore) SearchSimilar(ctx context.Context, collection string
 		return nil, err
 	}
 
-	resp, err := s.httpClient.Do(req)
+	resp, err := s.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search: %w", err)
 	}
@@ -271,7 +278,7 @@ func (s *QdrantVectorStore) DeleteVectors(ctx context.Context, collection string
 		return err
 	}
 
-	resp, err := s.httpClient.Do(req)
+	resp, err := s.do(req)
 	if err != nil {
 		return fmt.Errorf("failed to delete vectors: %w", err)
 	}
@@ -301,7 +308,7 @@ func (s *QdrantVectorStore) DeleteByFilter(ctx context.Context, collection strin
 		return err
 	}
 
-	resp, err := s.httpClient.Do(req)
+	resp, err := s.do(req)
 	if err != nil {
 		return fmt.Errorf("failed to delete by filter: %w", err)
 	}
@@ -315,6 +322,76 @@ func (s *QdrantVectorStore) DeleteByFilter(ctx context.Context, collection strin
 	return nil
 }
 
+// qdrantScrollPageSize 遍历集合时每页的记录数
+const qdrantScrollPageSize = 256
+
+// ScanVectors 使用 scroll API 分页遍历集合中满足过滤条件的记录
+func (s *QdrantVectorStore) ScanVectors(ctx context.Context, collection string, filter *VectorFilter, fn func(VectorRecord) error) error {
+	var offset interface{}
+	for {
+		body := map[string]interface{}{
+			"limit":        qdrantScrollPageSize,
+			"with_payload": true,
+			"with_vector":  false,
+		}
+		if offset != nil {
+			body["offset"] = offset
+		}
+		if filter != nil {
+			if qdrantFilter := s.buildQdrantFilter(filter); len(qdrantFilter) > 0 {
+				body["filter"] = qdrantFilter
+			}
+		}
+
+		req, err := s.newRequest(ctx, "POST", fmt.Sprintf("/collections/%s/points/scroll", collection), body)
+		if err != nil {
+			return err
+		}
+		resp, err := s.do(req)
+		if err != nil {
+			return fmt.Errorf("failed to scroll: %w", err)
+		}
+		if resp.StatusCode == http.StatusNotFound {
+			resp.Body.Close()
+			return nil
+		}
+		if resp.StatusCode != http.StatusOK {
+			respBody, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			return fmt.Errorf("scroll failed: %s", string(respBody))
+		}
+
+		var result struct {
+			Result struct {
+				Points []struct {
+					ID      interface{}            `json:"id"`
+					Payload map[string]interface{} `json:"payload"`
+				} `json:"points"`
+				NextPageOffset interface{} `json:"next_page_offset"`
+			} `json:"result"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+
+		for _, p := range result.Result.Points {
+			rec := VectorRecord{ID: fmt.Sprint(p.ID), Payload: p.Payload}
+			if mid, ok := p.Payload["memory_id"].(string); ok {
+				rec.MemoryID = mid
+			}
+			if err := fn(rec); err != nil {
+				return err
+			}
+		}
+		if result.Result.NextPageOffset == nil {
+			return nil
+		}
+		offset = result.Result.NextPageOffset
+	}
+}
+
 // Clear 清空集合
 func (s *QdrantVectorStore) Clear(ctx context.Context, collection string) error {
 	// 删除并重建集合
@@ -323,7 +400,7 @@ func (s *QdrantVectorStore) Clear(ctx context.Context, collection string) error
 		return err
 	}
 
-	resp, err := s.httpClient.Do(req)
+	resp, err := s.do(req)
 	if err != nil {
 		return fmt.Errorf("failed to clear collection: %w", err)
 	}
@@ -345,7 +422,7 @@ func (s *QdrantVectorStore) GetStats(ctx context.Context, collection string) (*V
 		return nil, err
 	}
 
-	resp, err := s.httpClient.Do(req)
+	resp, err := s.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get stats: %w", err)
 	}
@@ -391,7 +468,7 @@ func (s *QdrantVectorStore) HealthCheck(ctx context.Context) error {
 		return err
 	}
 
-	resp, err := s.httpClient.Do(req)
+	resp, err := s.do(req)
 	if err != nil {
 		return fmt.Errorf("health check failed: %w", err)
 	}
@@ -434,5 +511,53 @@ func (s *QdrantVectorStore) newRequest(ctx context.Context, method, path string,
 	return req, nil
 }
 
+// do 发送请求，网络错误、429 和 5xx 响应按重试策略重试
+//
+// 响应体在重试循环内读取完毕，用尽重试后仍返回最后一次的响应，由调用方按状态码处理。
+func (s *QdrantVectorStore) do(req *http.Request) (*http.Response, error) {
+	policy := retry.Default()
+	if s.retry != nil {
+		policy = *s.retry
+	}
+
+	var resp *http.Response
+	err := policy.DoNamed(req.Context(), "qdrant", func(ctx context.Context) error {
+		resp = nil
+		attempt := req.Clone(ctx)
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return err
+			}
+			attempt.Body = body
+		}
+
+		r, err := s.httpClient.Do(attempt)
+		if err != nil {
+			return fmt.Errorf("%w: %w", errors.ErrProviderUnavailable, err)
+		}
+		data, err := io.ReadAll(r.Body)
+		r.Body.Close()
+		if err != nil {
+			return fmt.Errorf("%w: %w", errors.ErrProviderUnavailable, err)
+		}
+		r.Body = io.NopCloser(bytes.NewReader(data))
+		resp = r
+
+		switch {
+		case r.StatusCode == http.StatusTooManyRequests:
+			return errors.ErrRateLimited
+		case r.StatusCode >= http.StatusInternalServerError:
+			return errors.ErrProviderUnavailable
+		}
+		return nil
+	})
+	if resp != nil {
+		return resp, nil
+	}
+	return nil, err
+}
+
 // Compile-time interface check
 var _ VectorStore = (*QdrantVectorStore)(nil)
+var _ VectorScanner = (*QdrantVectorStore)(nil)
@@ -0,0 +1,482 @@
+package store
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// redisBatchSize 批量读取/删除时每条命令携带的键数
+const redisBatchSize = 500
+
+// ExpiringDocumentStore 支持按文档设置过期时间的文档存储
+//
+// 工作记忆配置了 TTL 时，持久化写入会使用 PutWithTTL，使过期由存储自身完成。
+type ExpiringDocumentStore interface {
+	DocumentStore
+
+	// PutWithTTL 存储文档并在 ttl 后过期，ttl <= 0 表示不过期
+	PutWithTTL(ctx context.Context, collection string, id string, doc Document, ttl time.Duration) error
+}
+
+// RedisConfig Redis 配置
+type RedisConfig struct {
+	// Addr 服务器地址，默认 localhost:6379
+	Addr string
+	// Username ACL 用户名（可选）
+	Username string
+	// Password 密码（可选）
+	Password string
+	// DB 数据库编号
+	DB int
+	// KeyPrefix 键前缀，默认 "helloagents:"
+	KeyPrefix string
+	// TTL 文档默认过期时间，0 表示不过期
+	TTL time.Duration
+	// DialTimeout 连接超时，默认 5s
+	DialTimeout time.Duration
+	// PoolSize 空闲连接池大小，默认 10
+	PoolSize int
+}
+
+// RedisDocumentStore Redis 文档存储
+//
+// 基于 RESP 协议的轻量实现，多个 Agent 副本可通过同一 Redis 共享记忆。
+// 每个文档以 JSON 存储在 {prefix}{collection}:doc:{id}，
+// 集合内的 ID 记录在集合 {prefix}{collection}:ids 中，用于 Query/Count/Clear。
+// 文档过期由 Redis 键过期完成，过期文档的 ID 在下次查询时从索引中清除。
+//
+// 条件查询在客户端完成过滤和排序，适合工作记忆、情景记忆等中小规模集合。
+type RedisDocumentStore struct {
+	config RedisConfig
+	pool   chan *redisConn
+	closed bool
+	mu     sync.Mutex
+}
+
+// NewRedisDocumentStore 创建 Redis 文档存储并检查连接
+func NewRedisDocumentStore(config RedisConfig) (*RedisDocumentStore, error) {
+	if config.Addr == "" {
+		config.Addr = "localhost:6379"
+	}
+	if config.KeyPrefix == "" {
+		config.KeyPrefix = "helloagents:"
+	}
+	if config.DialTimeout <= 0 {
+		config.DialTimeout = 5 * time.Second
+	}
+	if config.PoolSize <= 0 {
+		config.PoolSize = 10
+	}
+
+	s := &RedisDocumentStore{
+		config: config,
+		pool:   make(chan *redisConn, config.PoolSize),
+	}
+	if err := s.HealthCheck(context.Background()); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Put 存储文档，使用配置的默认 TTL
+func (s *RedisDocumentStore) Put(ctx context.Context, collection string, id string, doc Document) error {
+	return s.PutWithTTL(ctx, collection, id, doc, s.config.TTL)
+}
+
+// PutWithTTL 存储文档并在 ttl 后过期
+func (s *RedisDocumentStore) PutWithTTL(ctx context.Context, collection string, id string, doc Document, ttl time.Duration) error {
+	doc.ID = id
+	if doc.CreatedAt.IsZero() {
+		doc.CreatedAt = time.Now()
+	}
+	doc.UpdatedAt = time.Now()
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("marshal document: %w", err)
+	}
+
+	set := []string{"SET", s.docKey(collection, id), string(data)}
+	if ttl > 0 {
+		set = append(set, "PX", strconv.FormatInt(max(ttl.Milliseconds(), 1), 10))
+	}
+	_, err = s.pipeline(ctx, set, []string{"SADD", s.indexKey(collection), id})
+	return err
+}
+
+// Get 获取文档
+func (s *RedisDocumentStore) Get(ctx context.Context, collection string, id string) (*Document, error) {
+	reply, err := s.do(ctx, "GET", s.docKey(collection, id))
+	if err != nil {
+		return nil, err
+	}
+	data, ok := reply.(string)
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	var doc Document
+	if err := json.Unmarshal([]byte(data), &doc); err != nil {
+		return nil, fmt.Errorf("unmarshal document: %w", err)
+	}
+	return &doc, nil
+}
+
+// Delete 删除文档
+func (s *RedisDocumentStore) Delete(ctx context.Context, collection string, id string) error {
+	replies, err := s.pipeline(ctx,
+		[]string{"DEL", s.docKey(collection, id)},
+		[]string{"SREM", s.indexKey(collection), id},
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := replies[0].(int64); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Query 条件查询
+func (s *RedisDocumentStore) Query(ctx context.Context, collection string, filter Filter, opts ...QueryOption) ([]Document, error) {
+	options := &queryOptions{limit: 100}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	docs, err := s.loadCollection(ctx, collection)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []Document
+	for i := range docs {
+		if matchFilter(&docs[i], filter) {
+			results = append(results, docs[i])
+		}
+	}
+	return sortAndPage(results, options), nil
+}
+
+// Count 统计数量
+func (s *RedisDocumentStore) Count(ctx context.Context, collection string, filter Filter) (int, error) {
+	docs, err := s.loadCollection(ctx, collection)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for i := range docs {
+		if matchFilter(&docs[i], filter) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// Clear 清空集合
+func (s *RedisDocumentStore) Clear(ctx context.Context, collection string) error {
+	ids, err := s.collectionIDs(ctx, collection)
+	if err != nil {
+		return err
+	}
+
+	for start := 0; start < len(ids); start += redisBatchSize {
+		end := min(start+redisBatchSize, len(ids))
+		args := []string{"DEL"}
+		for _, id := range ids[start:end] {
+			args = append(args, s.docKey(collection, id))
+		}
+		if _, err := s.do(ctx, args...); err != nil {
+			return err
+		}
+	}
+	_, err = s.do(ctx, "DEL", s.indexKey(collection))
+	return err
+}
+
+// HealthCheck 健康检查
+func (s *RedisDocumentStore) HealthCheck(ctx context.Context) error {
+	_, err := s.do(ctx, "PING")
+	return err
+}
+
+// Close 关闭连接池
+func (s *RedisDocumentStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	close(s.pool)
+	for conn := range s.pool {
+		conn.close()
+	}
+	return nil
+}
+
+// docKey 返回文档键
+func (s *RedisDocumentStore) docKey(collection, id string) string {
+	return s.config.KeyPrefix + collection + ":doc:" + id
+}
+
+// indexKey 返回集合 ID 索引键
+func (s *RedisDocumentStore) indexKey(collection string) string {
+	return s.config.KeyPrefix + collection + ":ids"
+}
+
+// collectionIDs 返回集合索引中的全部 ID
+func (s *RedisDocumentStore) collectionIDs(ctx context.Context, collection string) ([]string, error) {
+	reply, err := s.do(ctx, "SMEMBERS", s.indexKey(collection))
+	if err != nil {
+		return nil, err
+	}
+	members, _ := reply.([]interface{})
+	ids := make([]string, 0, len(members))
+	for _, m := range members {
+		if id, ok := m.(string); ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// loadCollection 读取集合中的全部文档，并从索引中清除已过期的 ID
+func (s *RedisDocumentStore) loadCollection(ctx context.Context, collection string) ([]Document, error) {
+	ids, err := s.collectionIDs(ctx, collection)
+	if err != nil {
+		return nil, err
+	}
+
+	docs := make([]Document, 0, len(ids))
+	var expired []string
+	for start := 0; start < len(ids); start += redisBatchSize {
+		batch := ids[start:min(start+redisBatchSize, len(ids))]
+		args := []string{"MGET"}
+		for _, id := range batch {
+			args = append(args, s.docKey(collection, id))
+		}
+		reply, err := s.do(ctx, args...)
+		if err != nil {
+			return nil, err
+		}
+		values, _ := reply.([]interface{})
+		for i, v := range values {
+			data, ok := v.(string)
+			if !ok {
+				expired = append(expired, batch[i])
+				continue
+			}
+			var doc Document
+			if err := json.Unmarshal([]byte(data), &doc); err != nil {
+				return nil, fmt.Errorf("unmarshal document %s: %w", batch[i], err)
+			}
+			docs = append(docs, doc)
+		}
+	}
+
+	if len(expired) > 0 {
+		// 清理失败不影响查询结果，下次查询会重试
+		_, _ = s.do(ctx, append([]string{"SREM", s.indexKey(collection)}, expired...)...)
+	}
+	return docs, nil
+}
+
+// do 执行单条命令
+func (s *RedisDocumentStore) do(ctx context.Context, args ...string) (interface{}, error) {
+	replies, err := s.pipeline(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	return replies[0], nil
+}
+
+// pipeline 在同一连接上依次发送多条命令并读取全部回复
+//
+// 任一命令返回 Redis 错误时返回该错误；连接错误包装为 ErrConnectionFailed。
+func (s *RedisDocumentStore) pipeline(ctx context.Context, commands ...[]string) ([]interface{}, error) {
+	conn, err := s.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	replies, err := conn.pipeline(ctx, commands)
+	var redisErr redisError
+	if err != nil && !errors.As(err, &redisErr) {
+		conn.close()
+		return nil, fmt.Errorf("%w: %v", ErrConnectionFailed, err)
+	}
+	s.release(conn)
+	return replies, err
+}
+
+// acquire 从连接池取出连接，池为空时新建连接
+func (s *RedisDocumentStore) acquire(ctx context.Context) (*redisConn, error) {
+	select {
+	case conn, ok := <-s.pool:
+		if ok {
+			return conn, nil
+		}
+		return nil, fmt.Errorf("%w: store is closed", ErrConnectionFailed)
+	default:
+	}
+
+	dialer := net.Dialer{Timeout: s.config.DialTimeout}
+	netConn, err := dialer.DialContext(ctx, "tcp", s.config.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrConnectionFailed, err)
+	}
+	conn := &redisConn{conn: netConn, reader: bufio.NewReader(netConn)}
+
+	var setup [][]string
+	if s.config.Password != "" {
+		if s.config.Username != "" {
+			setup = append(setup, []string{"AUTH", s.config.Username, s.config.Password})
+		} else {
+			setup = append(setup, []string{"AUTH", s.config.Password})
+		}
+	}
+	if s.config.DB != 0 {
+		setup = append(setup, []string{"SELECT", strconv.Itoa(s.config.DB)})
+	}
+	if len(setup) > 0 {
+		if _, err := conn.pipeline(ctx, setup); err != nil {
+			conn.close()
+			return nil, fmt.Errorf("%w: %v", ErrConnectionFailed, err)
+		}
+	}
+	return conn, nil
+}
+
+// release 将连接放回连接池，池已满或已关闭时关闭连接
+func (s *RedisDocumentStore) release(conn *redisConn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		conn.close()
+		return
+	}
+	select {
+	case s.pool <- conn:
+	default:
+		conn.close()
+	}
+}
+
+// redisError Redis 返回的错误回复
+type redisError string
+
+func (e redisError) Error() string {
+	return "redis: " + string(e)
+}
+
+// redisConn 单个 RESP 连接
+type redisConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// pipeline 发送多条命令并按顺序读取回复
+func (c *redisConn) pipeline(ctx context.Context, commands [][]string) ([]interface{}, error) {
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = c.conn.SetDeadline(deadline)
+	} else {
+		_ = c.conn.SetDeadline(time.Time{})
+	}
+
+	var buf strings.Builder
+	for _, args := range commands {
+		fmt.Fprintf(&buf, "*%d\r\n", len(args))
+		for _, arg := range args {
+			fmt.Fprintf(&buf, "$%d\r\n%s\r\n", len(arg), arg)
+		}
+	}
+	if _, err := io.WriteString(c.conn, buf.String()); err != nil {
+		return nil, err
+	}
+
+	replies := make([]interface{}, len(commands))
+	var firstErr error
+	for i := range commands {
+		reply, err := c.readReply()
+		if err != nil {
+			var redisErr redisError
+			if !errors.As(err, &redisErr) {
+				return nil, err
+			}
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+		replies[i] = reply
+	}
+	return replies, firstErr
+}
+
+// readReply 读取一个 RESP 回复：简单字符串和批量字符串为 string，整数为 int64，
+// 数组为 []interface{}，空批量字符串和空数组为 nil
+func (c *redisConn) readReply() (interface{}, error) {
+	line, err := c.reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, fmt.Errorf("malformed reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, redisError(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		data := make([]byte, n+2)
+		if _, err := io.ReadFull(c.reader, data); err != nil {
+			return nil, err
+		}
+		return string(data[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		values := make([]interface{}, n)
+		for i := range values {
+			v, err := c.readReply()
+			if err != nil {
+				return nil, err
+			}
+			values[i] = v
+		}
+		return values, nil
+	default:
+		return nil, fmt.Errorf("unexpected reply type %q", line[0])
+	}
+}
+
+// close 关闭连接
+func (c *redisConn) close() {
+	_ = c.conn.Close()
+}
+
+// Compile-time interface check
+var _ ExpiringDocumentStore = (*RedisDocumentStore)(nil)
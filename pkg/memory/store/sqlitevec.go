@@ -0,0 +1,422 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SQLiteVectorConfig SQLite 向量存储配置
+type SQLiteVectorConfig struct {
+	// Path 数据库文件路径，":memory:" 为内存数据库
+	Path string
+	// DriverName database/sql 驱动名，默认 "sqlite3"
+	//
+	// 使用 sqlite-vec 扩展时需注册一个加载了扩展的驱动（如通过 sqlite3.SQLiteDriver 的 Extensions），
+	// 并在此填写其名称。
+	DriverName string
+	// DB 已有的连接，设置后忽略 Path/DriverName，Close 不会关闭它
+	DB *sql.DB
+	// Table 向量表名，默认 "vectors"
+	Table string
+	// Dimensions 向量维度，大于 0 时写入和查询时校验
+	Dimensions int
+	// VecExtension 使用 sqlite-vec 扩展的 vec_distance_cosine 在 SQL 中计算相似度并排序
+	//
+	// 未启用时在进程内对集合做精确扫描，适合十万级以下的向量。
+	// 两种方式的向量存储格式相同（小端 float32 BLOB），可随时切换。
+	VecExtension bool
+}
+
+// SQLiteVectorStore SQLite 向量存储
+//
+// 单文件持久化的向量存储，适合单机部署和本地开发。所有集合保存在同一张表中，
+// payload 保存为 JSON，过滤条件通过 json_extract 在 SQL 中匹配。
+type SQLiteVectorStore struct {
+	db           *sql.DB
+	ownsDB       bool
+	table        string
+	dimensions   int
+	vecExtension bool
+}
+
+// NewSQLiteVectorStore 创建 SQLite 向量存储并初始化表结构
+func NewSQLiteVectorStore(config SQLiteVectorConfig) (*SQLiteVectorStore, error) {
+	if config.Table == "" {
+		config.Table = "vectors"
+	}
+	if !pgIdentifier.MatchString(config.Table) {
+		return nil, fmt.Errorf("%w: invalid table name %q", ErrInvalidInput, config.Table)
+	}
+
+	s := &SQLiteVectorStore{
+		db:           config.DB,
+		table:        config.Table,
+		dimensions:   config.Dimensions,
+		vecExtension: config.VecExtension,
+	}
+	if s.db == nil {
+		if config.DriverName == "" {
+			config.DriverName = "sqlite3"
+		}
+		db, err := sql.Open(config.DriverName, config.Path)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrConnectionFailed, err)
+		}
+		// SQLite 单写者，避免并发写入时的 database is locked
+		db.SetMaxOpenConns(1)
+		s.db = db
+		s.ownsDB = true
+	}
+
+	if err := s.HealthCheck(context.Background()); err != nil {
+		_ = s.Close()
+		return nil, err
+	}
+	if err := s.initSchema(context.Background()); err != nil {
+		_ = s.Close()
+		return nil, fmt.Errorf("failed to init schema: %w", err)
+	}
+	if s.vecExtension {
+		if _, err := s.db.Exec(`SELECT vec_version()`); err != nil {
+			_ = s.Close()
+			return nil, fmt.Errorf("%w: sqlite-vec extension not loaded: %v", ErrConnectionFailed, err)
+		}
+	}
+	return s, nil
+}
+
+// initSchema 初始化表结构
+func (s *SQLiteVectorStore) initSchema(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, fmt.Sprintf(`
+	CREATE TABLE IF NOT EXISTS %[1]s (
+		collection TEXT NOT NULL,
+		id TEXT NOT NULL,
+		memory_id TEXT NOT NULL DEFAULT '',
+		embedding BLOB NOT NULL,
+		payload TEXT NOT NULL DEFAULT '{}',
+		created_at INTEGER NOT NULL,
+		updated_at INTEGER NOT NULL,
+		PRIMARY KEY (collection, id)
+	);
+	CREATE INDEX IF NOT EXISTS %[1]s_memory_id_idx ON %[1]s(collection, memory_id);
+	`, s.table))
+	return err
+}
+
+// AddVectors 批量添加向量，ID 已存在时覆盖
+func (s *SQLiteVectorStore) AddVectors(ctx context.Context, collection string, vectors []VectorRecord) error {
+	if len(vectors) == 0 {
+		return nil
+	}
+	for _, v := range vectors {
+		if err := s.checkDimensions(v.Vector); err != nil {
+			return fmt.Errorf("vector %s: %w", v.ID, err)
+		}
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	stmt, err := tx.PrepareContext(ctx, fmt.Sprintf(`
+	INSERT INTO %s (collection, id, memory_id, embedding, payload, created_at, updated_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?)
+	ON CONFLICT(collection, id) DO UPDATE SET
+		memory_id = excluded.memory_id,
+		embedding = excluded.embedding,
+		payload = excluded.payload,
+		updated_at = excluded.updated_at`, s.table))
+	if err != nil {
+		return fmt.Errorf("failed to prepare upsert: %w", err)
+	}
+	defer stmt.Close()
+
+	now := time.Now().UnixMilli()
+	for _, v := range vectors {
+		payload, err := json.Marshal(buildVectorPayload(v))
+		if err != nil {
+			return fmt.Errorf("failed to marshal payload: %w", err)
+		}
+		if _, err := stmt.ExecContext(ctx, collection, v.ID, v.MemoryID, encodeVectorBlob(v.Vector), string(payload), now, now); err != nil {
+			return fmt.Errorf("failed to upsert vector %s: %w", v.ID, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// SearchSimilar 相似度搜索
+func (s *SQLiteVectorStore) SearchSimilar(ctx context.Context, collection string, vector []float32, topK int, filter *VectorFilter) ([]VectorSearchResult, error) {
+	if err := s.checkDimensions(vector); err != nil {
+		return nil, err
+	}
+	if topK <= 0 {
+		topK = 10
+	}
+
+	where, args := s.buildWhereClause(collection, filter)
+	if s.vecExtension {
+		args = append([]interface{}{encodeVectorBlob(vector)}, args...)
+		args = append(args, topK)
+		rows, err := s.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT id, memory_id, payload, 1 - vec_distance_cosine(embedding, ?) AS score
+		FROM %s WHERE %s ORDER BY score DESC LIMIT ?`, s.table, where), args...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to search: %w", err)
+		}
+		defer rows.Close()
+
+		var results []VectorSearchResult
+		for rows.Next() {
+			var (
+				r       VectorSearchResult
+				payload string
+				score   float64
+			)
+			if err := rows.Scan(&r.ID, &r.MemoryID, &payload, &score); err != nil {
+				return nil, fmt.Errorf("failed to scan result: %w", err)
+			}
+			if err := json.Unmarshal([]byte(payload), &r.Payload); err != nil {
+				return nil, fmt.Errorf("failed to decode payload: %w", err)
+			}
+			r.Score = float32(score)
+			results = append(results, r)
+		}
+		return results, rows.Err()
+	}
+
+	rows, err := s.db.QueryContext(ctx, fmt.Sprintf(`SELECT id, memory_id, payload, embedding FROM %s WHERE %s`, s.table, where), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search: %w", err)
+	}
+	defer rows.Close()
+
+	var results []VectorSearchResult
+	for rows.Next() {
+		var (
+			r         VectorSearchResult
+			payload   string
+			embedding []byte
+		)
+		if err := rows.Scan(&r.ID, &r.MemoryID, &payload, &embedding); err != nil {
+			return nil, fmt.Errorf("failed to scan result: %w", err)
+		}
+		r.Score = cosineSimilarity(vector, decodeVectorBlob(embedding))
+		if err := json.Unmarshal([]byte(payload), &r.Payload); err != nil {
+			return nil, fmt.Errorf("failed to decode payload: %w", err)
+		}
+		results = append(results, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+	if len(results) > topK {
+		results = results[:topK]
+	}
+	return results, nil
+}
+
+// ScanVectors 遍历集合中满足过滤条件的记录
+func (s *SQLiteVectorStore) ScanVectors(ctx context.Context, collection string, filter *VectorFilter, fn func(VectorRecord) error) error {
+	where, args := s.buildWhereClause(collection, filter)
+	rows, err := s.db.QueryContext(ctx, fmt.Sprintf(`SELECT id, memory_id, payload FROM %s WHERE %s ORDER BY id`, s.table, where), args...)
+	if err != nil {
+		return fmt.Errorf("failed to scan vectors: %w", err)
+	}
+
+	// 单连接时需先读完结果集再回调，否则回调中的写操作会等待连接
+	var records []VectorRecord
+	for rows.Next() {
+		var (
+			rec     VectorRecord
+			payload string
+		)
+		if err := rows.Scan(&rec.ID, &rec.MemoryID, &payload); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan row: %w", err)
+		}
+		if err := json.Unmarshal([]byte(payload), &rec.Payload); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to decode payload: %w", err)
+		}
+		records = append(records, rec)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, rec := range records {
+		if err := fn(rec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// buildWhereClause 构建过滤条件，payload 条件通过 json_extract 匹配
+func (s *SQLiteVectorStore) buildWhereClause(collection string, filter *VectorFilter) (string, []interface{}) {
+	clauses := []string{"collection = ?"}
+	args := []interface{}{collection}
+	if filter == nil {
+		return clauses[0], args
+	}
+
+	if filter.MemoryID != "" {
+		clauses = append(clauses, "memory_id = ?")
+		args = append(args, filter.MemoryID)
+	}
+
+	conditions := make(map[string]interface{}, len(filter.Conditions)+2)
+	for k, v := range filter.Conditions {
+		conditions[k] = v
+	}
+	if filter.UserID != "" {
+		conditions["user_id"] = filter.UserID
+	}
+	if filter.MemoryType != "" {
+		conditions["memory_type"] = filter.MemoryType
+	}
+
+	keys := make([]string, 0, len(conditions))
+	for k := range conditions {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		clauses = append(clauses, "json_extract(payload, ?) = ?")
+		args = append(args, `$."`+strings.ReplaceAll(k, `"`, `\"`)+`"`, sqliteJSONValue(conditions[k]))
+	}
+	return strings.Join(clauses, " AND "), args
+}
+
+// sqliteJSONValue 将条件值转换为与 json_extract 结果可比较的 SQL 值
+func sqliteJSONValue(v interface{}) interface{} {
+	if b, ok := v.(bool); ok {
+		if b {
+			return 1
+		}
+		return 0
+	}
+	return v
+}
+
+// DeleteVectors 按 ID 删除向量
+func (s *SQLiteVectorStore) DeleteVectors(ctx context.Context, collection string, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	args := make([]interface{}, 0, len(ids)+1)
+	args = append(args, collection)
+	placeholders := make([]string, len(ids))
+	for i, id := range ids {
+		args = append(args, id)
+		placeholders[i] = "?"
+	}
+
+	_, err := s.db.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE collection = ? AND id IN (%s)`,
+		s.table, strings.Join(placeholders, ", ")), args...)
+	if err != nil {
+		return fmt.Errorf("failed to delete vectors: %w", err)
+	}
+	return nil
+}
+
+// DeleteByFilter 按条件删除，空过滤条件不删除任何向量
+func (s *SQLiteVectorStore) DeleteByFilter(ctx context.Context, collection string, filter *VectorFilter) error {
+	if filter == nil || (filter.MemoryID == "" && filter.UserID == "" && filter.MemoryType == "" && len(filter.Conditions) == 0) {
+		return nil
+	}
+
+	where, args := s.buildWhereClause(collection, filter)
+	if _, err := s.db.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE %s`, s.table, where), args...); err != nil {
+		return fmt.Errorf("failed to delete by filter: %w", err)
+	}
+	return nil
+}
+
+// Clear 清空集合
+func (s *SQLiteVectorStore) Clear(ctx context.Context, collection string) error {
+	if _, err := s.db.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE collection = ?`, s.table), collection); err != nil {
+		return fmt.Errorf("failed to clear collection: %w", err)
+	}
+	return nil
+}
+
+// GetStats 获取统计信息
+func (s *SQLiteVectorStore) GetStats(ctx context.Context, collection string) (*VectorStoreStats, error) {
+	var count int
+	var dims sql.NullInt64
+	err := s.db.QueryRowContext(ctx, fmt.Sprintf(`SELECT count(*), max(length(embedding)) / 4 FROM %s WHERE collection = ?`, s.table), collection).Scan(&count, &dims)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stats: %w", err)
+	}
+
+	stats := &VectorStoreStats{
+		VectorCount: count,
+		Dimensions:  int(dims.Int64),
+	}
+	if s.dimensions > 0 {
+		stats.Dimensions = s.dimensions
+	}
+	if s.vecExtension {
+		stats.IndexedCount = count
+	}
+	return stats, nil
+}
+
+// HealthCheck 健康检查
+func (s *SQLiteVectorStore) HealthCheck(ctx context.Context) error {
+	if err := s.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("%w: %v", ErrConnectionFailed, err)
+	}
+	return nil
+}
+
+// Close 关闭连接（通过 SQLiteVectorConfig.DB 传入的连接由调用方关闭）
+func (s *SQLiteVectorStore) Close() error {
+	if s.ownsDB {
+		return s.db.Close()
+	}
+	return nil
+}
+
+// checkDimensions 校验向量维度
+func (s *SQLiteVectorStore) checkDimensions(vector []float32) error {
+	if s.dimensions > 0 && len(vector) != s.dimensions {
+		return fmt.Errorf("%w: vector has %d dimensions, expected %d", ErrInvalidInput, len(vector), s.dimensions)
+	}
+	return nil
+}
+
+// encodeVectorBlob 将向量编码为小端 float32 BLOB（与 sqlite-vec 的格式相同）
+func encodeVectorBlob(vector []float32) []byte {
+	buf := make([]byte, len(vector)*4)
+	for i, x := range vector {
+		binary.LittleEndian.PutUint32(buf[i*4:], math.Float32bits(x))
+	}
+	return buf
+}
+
+// decodeVectorBlob 解码小端 float32 BLOB
+func decodeVectorBlob(buf []byte) []float32 {
+	vector := make([]float32, len(buf)/4)
+	for i := range vector {
+		vector[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[i*4:]))
+	}
+	return vector
+}
+
+// Compile-time interface check
+var _ VectorStore = (*SQLiteVectorStore)(nil)
+var _ VectorScanner = (*SQLiteVectorStore)(nil)
@@ -121,6 +121,16 @@ type VectorStore interface {
 	Close() error
 }
 
+// VectorScanner 可遍历集合的向量存储（可选接口）
+//
+// 用于按 VectorFilter 无法表达的条件（如时间范围）批量管理记录：先遍历 payload，
+// 再按 ID 删除。内置的向量存储都实现了该接口。
+type VectorScanner interface {
+	// ScanVectors 遍历集合中满足过滤条件的记录，返回的记录不含向量值；
+	// fn 返回错误时停止遍历并返回该错误
+	ScanVectors(ctx context.Context, collection string, filter *VectorFilter, fn func(VectorRecord) error) error
+}
+
 // VectorRecord 向量记录
 type VectorRecord struct {
 	ID       string                 `json:"id"`
@@ -260,6 +270,10 @@ const (
 	StoreTypeQdrant StoreType = "qdrant"
 	// StoreTypeNeo4j Neo4j 存储
 	StoreTypeNeo4j StoreType = "neo4j"
+	// StoreTypeRedis Redis 存储
+	StoreTypeRedis StoreType = "redis"
+	// StoreTypePgVector PostgreSQL + pgvector 存储
+	StoreTypePgVector StoreType = "pgvector"
 )
 
 // Config 存储配置
@@ -269,6 +283,8 @@ type Config struct {
 
 	// SQLite 配置
 	SQLitePath string `json:"sqlite_path,omitempty"`
+	// SQLiteVecExtension 向量存储使用 sqlite-vec 扩展计算相似度（需使用加载了扩展的驱动）
+	SQLiteVecExtension bool `json:"sqlite_vec_extension,omitempty"`
 
 	// Qdrant 配置
 	QdrantURL    string `json:"qdrant_url,omitempty"`
@@ -279,6 +295,19 @@ type Config struct {
 	Neo4jUsername string `json:"neo4j_username,omitempty"`
 	Neo4jPassword string `json:"neo4j_password,omitempty"`
 
+	// Redis 配置
+	RedisAddr      string        `json:"redis_addr,omitempty"`
+	RedisPassword  string        `json:"redis_password,omitempty"`
+	RedisDB        int           `json:"redis_db,omitempty"`
+	RedisKeyPrefix string        `json:"redis_key_prefix,omitempty"`
+	RedisTTL       time.Duration `json:"redis_ttl,omitempty"`
+
+	// PostgreSQL + pgvector 配置（需导入对应的 database/sql 驱动）
+	PgVectorDSN    string `json:"pgvector_dsn,omitempty"`
+	PgVectorDriver string `json:"pgvector_driver,omitempty"`
+	PgVectorTable  string `json:"pgvector_table,omitempty"`
+	PgVectorIndex  string `json:"pgvector_index,omitempty"`
+
 	// 向量维度
 	VectorDimensions int `json:"vector_dimensions,omitempty"`
 }
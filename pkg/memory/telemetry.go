@@ -0,0 +1,130 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/ahhsitt/helloagents-go/pkg/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// 记忆操作名称，用于 Span 名称和 memory.operation 属性
+const (
+	memoryOpAdd         = "add"
+	memoryOpRetrieve    = "retrieve"
+	memoryOpForget      = "forget"
+	memoryOpConsolidate = "consolidate"
+)
+
+// WithTelemetry 设置记忆操作的追踪器和指标
+//
+// 设置后 AddMemory、RetrieveMemories、ForgetMemories 和 ConsolidateMemories 会创建
+// memory.<操作> Span，检索和遗忘还会为每个记忆类型创建子 Span。导出的指标包括：
+// 操作次数和耗时、各记忆类型的检索耗时和命中/未命中次数、遗忘和整合数量，
+// 以及遗忘、整合和 GetStats 之后各记忆类型的记忆数量（memory.size）。
+// tracer 或 metrics 为 nil 时不导出对应数据。
+func WithTelemetry(tracer otel.Tracer, metrics otel.Metrics) ManagerOption {
+	return func(m *MemoryManager) {
+		m.tracer = tracer
+		m.metrics = metrics
+	}
+}
+
+// startSpan 开始一个记忆操作 Span，未设置追踪器时返回空 Span
+func (m *MemoryManager) startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, otel.Span) {
+	if m.tracer == nil {
+		return ctx, &otel.NoopSpan{}
+	}
+	return m.tracer.Start(ctx, name, otel.WithAttributes(attrs...))
+}
+
+// endSpan 按操作结果设置 Span 状态并结束 Span
+func endSpan(span otel.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otel.StatusError, err.Error())
+	} else {
+		span.SetStatus(otel.StatusOK, "")
+	}
+	span.End()
+}
+
+// recordOperation 记录记忆操作次数和耗时
+func (m *MemoryManager) recordOperation(ctx context.Context, op string, memType MemoryType, start time.Time, err error) {
+	if m.metrics == nil {
+		return
+	}
+	status := "success"
+	if err != nil {
+		status = "error"
+	}
+	attrs := []otel.Attr{
+		otel.NewAttr(otel.AttrMemoryOp, op),
+		otel.NewAttr(otel.AttrMemoryType, string(memType)),
+		otel.NewAttr("status", status),
+	}
+	m.metrics.Counter(otel.MetricMemoryOperations).Add(ctx, 1, attrs...)
+	m.metrics.Histogram(otel.MetricMemoryOperationDuration).Record(ctx, durationMillis(time.Since(start)), attrs...)
+}
+
+// retrieveFrom 从单个记忆类型检索，记录子 Span、检索耗时和命中情况
+func (m *MemoryManager) retrieveFrom(ctx context.Context, memType MemoryType, memory Memory, query string, opts []RetrieveOption) ([]*MemoryItem, error) {
+	ctx, span := m.startSpan(ctx, "memory.retrieve."+string(memType), otel.MemoryType(string(memType)))
+	start := time.Now()
+	items, err := memory.Retrieve(ctx, query, opts...)
+	span.SetAttributes(attribute.Int(otel.AttrMemoryResults, len(items)))
+	endSpan(span, err)
+
+	if m.metrics != nil {
+		typeAttr := otel.NewAttr(otel.AttrMemoryType, string(memType))
+		m.metrics.Histogram(otel.MetricMemoryRetrieveDuration).Record(ctx, durationMillis(time.Since(start)), typeAttr)
+		if err == nil {
+			if len(items) > 0 {
+				m.metrics.Counter(otel.MetricMemoryHits).Add(ctx, 1, typeAttr)
+			} else {
+				m.metrics.Counter(otel.MetricMemoryMisses).Add(ctx, 1, typeAttr)
+			}
+		}
+	}
+	return items, err
+}
+
+// forgetFrom 对单个记忆类型执行遗忘，记录子 Span 和遗忘数量
+func (m *MemoryManager) forgetFrom(ctx context.Context, memType MemoryType, forgetter Forgetter, strategy ForgetStrategy, opts []ForgetOption) (int, error) {
+	ctx, span := m.startSpan(ctx, "memory.forget."+string(memType), otel.MemoryType(string(memType)))
+	count, err := forgetter.Forget(ctx, strategy, opts...)
+	span.SetAttributes(attribute.Int(otel.AttrMemoryResults, count))
+	endSpan(span, err)
+
+	if m.metrics != nil && count > 0 {
+		m.metrics.Counter(otel.MetricMemoryForgotten).Add(ctx, int64(count),
+			otel.NewAttr(otel.AttrMemoryType, string(memType)),
+			otel.NewAttr(otel.AttrMemoryStrategy, string(strategy)),
+		)
+	}
+	return count, err
+}
+
+// refreshSizes 重新统计各记忆类型的记忆数量并导出（仅设置了指标时）
+func (m *MemoryManager) refreshSizes(ctx context.Context) {
+	if m.metrics == nil {
+		return
+	}
+	_, _ = m.GetStats(ctx)
+}
+
+// recordSizes 导出各记忆类型的记忆数量
+func (m *MemoryManager) recordSizes(ctx context.Context, stats *ManagerStats) {
+	if m.metrics == nil {
+		return
+	}
+	gauge := m.metrics.Gauge(otel.MetricMemorySize)
+	for memType, memStats := range stats.ByType {
+		gauge.Set(ctx, float64(memStats.Count), otel.NewAttr(otel.AttrMemoryType, string(memType)))
+	}
+}
+
+// durationMillis 将耗时转换为毫秒
+func durationMillis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
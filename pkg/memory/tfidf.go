@@ -6,6 +6,7 @@ import (
 	"strings"
 	"sync"
 	"unicode"
+	"unicode/utf8"
 )
 
 // TFIDFVectorizer TF-IDF 向量化器
@@ -32,35 +33,69 @@ func NewTFIDFVectorizer() *TFIDFVectorizer {
 //
 // 支持英文空格分词和中文字符分词。
 func (v *TFIDFVectorizer) tokenize(text string) []string {
+	return appendTokens(make([]string, 0, len(text)/4), text)
+}
+
+// appendTokens 将 text 的词元追加到 dst 并返回
+//
+// 词元直接切自小写化后的文本，中文字符单独成词。
+func appendTokens(dst []string, text string) []string {
 	text = strings.ToLower(text)
-	var tokens []string
-	var currentWord strings.Builder
-
-	for _, r := range text {
-		if unicode.IsLetter(r) || unicode.IsNumber(r) {
-			// 中文字符单独成词
-			if unicode.Is(unicode.Han, r) {
-				if currentWord.Len() > 0 {
-					tokens = append(tokens, currentWord.String())
-					currentWord.Reset()
-				}
-				tokens = append(tokens, string(r))
-			} else {
-				currentWord.WriteRune(r)
+
+	start := -1
+	for i, r := range text {
+		if !unicode.IsLetter(r) && !unicode.IsNumber(r) {
+			if start >= 0 {
+				dst = append(dst, text[start:i])
+				start = -1
 			}
-		} else {
-			if currentWord.Len() > 0 {
-				tokens = append(tokens, currentWord.String())
-				currentWord.Reset()
+			continue
+		}
+
+		// 中文字符单独成词
+		if unicode.Is(unicode.Han, r) {
+			if start >= 0 {
+				dst = append(dst, text[start:i])
+				start = -1
 			}
+			dst = append(dst, text[i:i+utf8.RuneLen(r)])
+			continue
+		}
+
+		if start < 0 {
+			start = i
 		}
 	}
 
-	if currentWord.Len() > 0 {
-		tokens = append(tokens, currentWord.String())
+	if start >= 0 {
+		dst = append(dst, text[start:])
 	}
 
-	return tokens
+	return dst
+}
+
+// vectorScratch 向量化时复用的临时缓冲区
+type vectorScratch struct {
+	tokens  []string
+	touched []int
+}
+
+// scratchPool 复用向量化的临时缓冲区，降低检索热路径的分配
+var scratchPool = sync.Pool{
+	New: func() interface{} {
+		return &vectorScratch{
+			tokens:  make([]string, 0, 64),
+			touched: make([]int, 0, 64),
+		}
+	},
+}
+
+// release 清空引用后将缓冲区放回池中
+func (s *vectorScratch) release() {
+	clear(s.tokens)
+	s.tokens = s.tokens[:0]
+	s.touched = s.touched[:0]
+	scratchPool.Put(s)
 }
 
 // Fit 训练向量化器
@@ -70,15 +105,17 @@ func (v *TFIDFVectorizer) Fit(documents []string) {
 	v.mu.Lock()
 	defer v.mu.Unlock()
 
-	// 统计词频
+	scratch := scratchPool.Get().(*vectorScratch)
+	defer scratch.release()
+
+	// 统计文档频率，seen 在文档之间复用
 	wordDocCount := make(map[string]int)
-	allWords := make(map[string]struct{})
+	seen := make(map[string]struct{})
 
 	for _, doc := range documents {
-		tokens := v.tokenize(doc)
-		seen := make(map[string]struct{})
-		for _, token := range tokens {
-			allWords[token] = struct{}{}
+		scratch.tokens = appendTokens(scratch.tokens[:0], doc)
+		clear(seen)
+		for _, token := range scratch.tokens {
 			if _, ok := seen[token]; !ok {
 				wordDocCount[token]++
 				seen[token] = struct{}{}
@@ -87,8 +124,8 @@ func (v *TFIDFVectorizer) Fit(documents []string) {
 	}
 
 	// 构建词汇表（按字母顺序排序以保证一致性）
-	words := make([]string, 0, len(allWords))
-	for word := range allWords {
+	words := make([]string, 0, len(wordDocCount))
+	for word := range wordDocCount {
 		words = append(words, word)
 	}
 	sort.Strings(words)
@@ -101,7 +138,7 @@ func (v *TFIDFVectorizer) Fit(documents []string) {
 	// 计算 IDF
 	v.idf = make([]float32, len(words))
 	n := float64(len(documents))
-	for word, idx := range v.vocabulary {
+	for idx, word := range words {
 		df := float64(wordDocCount[word])
 		v.idf[idx] = float32(math.Log(n/df) + 1.0)
 	}
@@ -114,35 +151,7 @@ func (v *TFIDFVectorizer) Transform(text string) []float32 {
 	v.mu.RLock()
 	defer v.mu.RUnlock()
 
-	if len(v.vocabulary) == 0 {
-		return nil
-	}
-
-	tokens := v.tokenize(text)
-	if len(tokens) == 0 {
-		return make([]float32, len(v.vocabulary))
-	}
-
-	// 计算 TF
-	tf := make(map[string]int)
-	for _, token := range tokens {
-		tf[token]++
-	}
-
-	// 计算 TF-IDF 向量
-	vector := make([]float32, len(v.vocabulary))
-	for word, count := range tf {
-		if idx, ok := v.vocabulary[word]; ok {
-			// TF = log(1 + count)
-			tfValue := float32(math.Log(1 + float64(count)))
-			vector[idx] = tfValue * v.idf[idx]
-		}
-	}
-
-	// L2 归一化
-	v.normalize(vector)
-
-	return vector
+	return v.transformInternal(text)
 }
 
 // FitTransform 训练并转换
@@ -152,10 +161,17 @@ func (v *TFIDFVectorizer) FitTransform(documents []string) [][]float32 {
 	v.mu.Lock()
 	defer v.mu.Unlock()
 
+	// 所有文档向量共用一块连续内存，减少分配次数
+	dim := len(v.vocabulary)
+	backing := make([]float32, len(documents)*dim)
+
 	v.documents = make([][]float32, len(documents))
 	for i, doc := range documents {
+		if dim == 0 {
+			continue
+		}
 		// 使用内部方法避免死锁
-		v.documents[i] = v.transformInternal(doc)
+		v.documents[i] = v.transformInto(backing[i*dim:(i+1)*dim:(i+1)*dim], doc)
 	}
 
 	return v.documents
@@ -166,25 +182,38 @@ func (v *TFIDFVectorizer) transformInternal(text string) []float32 {
 	if len(v.vocabulary) == 0 {
 		return nil
 	}
+	return v.transformInto(make([]float32, len(v.vocabulary)), text)
+}
 
-	tokens := v.tokenize(text)
-	if len(tokens) == 0 {
-		return make([]float32, len(v.vocabulary))
+// transformInto 将文本的 TF-IDF 向量写入已清零的 vector（调用者需持有锁）
+//
+// 词频直接累加在 vector 上，只对出现过的维度计算权重，不分配词频表。
+func (v *TFIDFVectorizer) transformInto(vector []float32, text string) []float32 {
+	scratch := scratchPool.Get().(*vectorScratch)
+	defer scratch.release()
+
+	scratch.tokens = appendTokens(scratch.tokens[:0], text)
+	if len(scratch.tokens) == 0 {
+		return vector
 	}
 
-	tf := make(map[string]int)
-	for _, token := range tokens {
-		tf[token]++
+	// 计算 TF
+	for _, token := range scratch.tokens {
+		if idx, ok := v.vocabulary[token]; ok {
+			if vector[idx] == 0 {
+				scratch.touched = append(scratch.touched, idx)
+			}
+			vector[idx]++
+		}
 	}
 
-	vector := make([]float32, len(v.vocabulary))
-	for word, count := range tf {
-		if idx, ok := v.vocabulary[word]; ok {
-			tfValue := float32(math.Log(1 + float64(count)))
-			vector[idx] = tfValue * v.idf[idx]
-		}
+	// 计算 TF-IDF：TF = log(1 + count)
+	for _, idx := range scratch.touched {
+		tfValue := float32(math.Log(1 + float64(vector[idx])))
+		vector[idx] = tfValue * v.idf[idx]
 	}
 
+	// L2 归一化
 	v.normalize(vector)
 	return vector
 }
@@ -1,6 +1,7 @@
 package memory
 
 import (
+	"context"
 	"testing"
 )
 
@@ -233,3 +234,72 @@ func TestChineseText(t *testing.T) {
 		t.Error("expected results for Chinese query")
 	}
 }
+
+func TestDeferredTFIDFRebuild(t *testing.T) {
+	ctx := context.Background()
+	m := NewWorkingMemory(WithMaxSize(0))
+
+	for _, content := range []string{"deploy the api service", "rotate database credentials", "review the deploy logs"} {
+		if _, err := m.Add(ctx, NewMemoryItem(content, MemoryTypeWorking)); err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+	}
+
+	// 写操作只标记过期，不重建索引
+	if !m.tfidfStale || m.tfidf.DocumentCount() != 0 {
+		t.Fatalf("expected deferred rebuild, stale=%v docs=%d", m.tfidfStale, m.tfidf.DocumentCount())
+	}
+
+	m.Flush()
+	if m.tfidfStale || m.tfidf.DocumentCount() != 3 {
+		t.Fatalf("expected index rebuilt after Flush, stale=%v docs=%d", m.tfidfStale, m.tfidf.DocumentCount())
+	}
+
+	// 检索前自动重建
+	if _, err := m.Add(ctx, NewMemoryItem("deploy rollback plan", MemoryTypeWorking)); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	items, err := m.Retrieve(ctx, "deploy", WithLimit(10))
+	if err != nil {
+		t.Fatalf("Retrieve() error = %v", err)
+	}
+	if m.tfidf.DocumentCount() != 4 || len(items) == 0 {
+		t.Errorf("expected retrieve to rebuild index, docs=%d items=%d", m.tfidf.DocumentCount(), len(items))
+	}
+}
+
+// benchmarkCorpus 生成基准测试语料
+func benchmarkCorpus(n int) []string {
+	words := []string{"deploy", "service", "api", "staging", "cluster", "failed", "rollback", "metrics", "latency", "用户", "偏好", "记忆", "检索"}
+	docs := make([]string, n)
+	for i := range docs {
+		doc := ""
+		for j := 0; j < 12; j++ {
+			doc += words[(i*7+j*3)%len(words)] + " "
+		}
+		docs[i] = doc
+	}
+	return docs
+}
+
+func BenchmarkTFIDFVectorizer_Transform(b *testing.B) {
+	v := NewTFIDFVectorizer()
+	v.Fit(benchmarkCorpus(200))
+	query := "API deploy failed on staging cluster, 用户偏好"
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		v.Transform(query)
+	}
+}
+
+func BenchmarkTFIDFVectorizer_FitTransform(b *testing.B) {
+	docs := benchmarkCorpus(200)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		NewTFIDFVectorizer().FitTransform(docs)
+	}
+}
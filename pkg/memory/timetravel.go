@@ -0,0 +1,224 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// EpisodeVersion 事件在一段时间内的一个版本
+//
+// 有效期以记录时间（写入存储的时间）计，而不是事件本身的 Timestamp。
+type EpisodeVersion struct {
+	// Version 版本号（从 1 开始）
+	Version int `json:"version"`
+	// Episode 该版本的事件内容
+	Episode Episode `json:"episode"`
+	// ValidFrom 版本生效时间
+	ValidFrom time.Time `json:"valid_from"`
+	// ValidTo 版本失效时间（被更新或删除），零值表示仍是当前版本
+	ValidTo time.Time `json:"valid_to,omitempty"`
+}
+
+// validAt 判断版本在 t 时是否有效
+func (v EpisodeVersion) validAt(t time.Time) bool {
+	return !v.ValidFrom.After(t) && (v.ValidTo.IsZero() || t.Before(v.ValidTo))
+}
+
+// episodeHistory 事件版本记录
+//
+// 按事件 ID 保存版本链。启用版本记录前已存在的事件（如从持久化存储加载的事件）
+// 在首次更新或删除时以其 Timestamp 作为初始版本的生效时间。
+type episodeHistory struct {
+	versions map[string][]EpisodeVersion
+}
+
+// newEpisodeHistory 创建事件版本记录
+func newEpisodeHistory() *episodeHistory {
+	return &episodeHistory{versions: make(map[string][]EpisodeVersion)}
+}
+
+// add 记录新事件
+func (h *episodeHistory) add(ep Episode, at time.Time) {
+	h.push(ep, at)
+}
+
+// update 记录事件的新版本
+func (h *episodeHistory) update(prev, next Episode, at time.Time) {
+	h.seed(prev)
+	h.close(prev.ID, at)
+	h.push(next, at)
+}
+
+// remove 记录事件被删除
+func (h *episodeHistory) remove(prev Episode, at time.Time) {
+	h.seed(prev)
+	h.close(prev.ID, at)
+}
+
+// seed 为没有版本记录的事件补充初始版本
+func (h *episodeHistory) seed(ep Episode) {
+	if len(h.versions[ep.ID]) > 0 {
+		return
+	}
+	h.versions[ep.ID] = []EpisodeVersion{{
+		Version:   1,
+		Episode:   versionedEpisode(ep),
+		ValidFrom: time.UnixMilli(ep.Timestamp),
+	}}
+}
+
+// push 追加版本
+func (h *episodeHistory) push(ep Episode, at time.Time) {
+	versions := h.versions[ep.ID]
+	h.versions[ep.ID] = append(versions, EpisodeVersion{
+		Version:   len(versions) + 1,
+		Episode:   versionedEpisode(ep),
+		ValidFrom: at,
+	})
+}
+
+// close 结束事件的当前版本
+func (h *episodeHistory) close(id string, at time.Time) {
+	versions := h.versions[id]
+	if n := len(versions); n > 0 && versions[n-1].ValidTo.IsZero() {
+		versions[n-1].ValidTo = at
+	}
+}
+
+// versionedEpisode 复制事件用于版本记录：复制元数据，去掉可重建的向量
+func versionedEpisode(ep Episode) Episode {
+	if ep.Metadata != nil {
+		metadata := make(map[string]interface{}, len(ep.Metadata))
+		for k, v := range ep.Metadata {
+			metadata[k] = v
+		}
+		ep.Metadata = metadata
+	}
+	ep.Vector = nil
+	return ep
+}
+
+// WithEpisodicVersioning 启用事件版本记录
+//
+// 启用后 Update/Remove/Forget 等修改保留事件的历史版本，GetStateAt 和 WithAsOf
+// 可以精确还原任意时刻的记忆状态，GetVersions 返回单个事件的版本链。
+// 重要性衰减（DecayImportance）和只更新重要性的 Update 不产生新版本。历史版本只保存在内存中。
+func WithEpisodicVersioning() EpisodicMemoryOption {
+	return func(m *EpisodicMemoryStore) {
+		m.history = newEpisodeHistory()
+	}
+}
+
+// GetStateAt 返回 t 时刻存储中的事件，即智能体在该时刻"知道"的内容
+//
+// 启用 WithEpisodicVersioning 时按版本记录还原，包括之后被更新或删除的事件；
+// 未启用时只能返回当前仍存在且 Timestamp 不晚于该时刻的事件。结果按 Timestamp 排序。
+func (m *EpisodicMemoryStore) GetStateAt(ctx context.Context, t time.Time) ([]Episode, error) {
+	if err := m.ensureLoaded(ctx); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.stateAtLocked(t), nil
+}
+
+// GetVersions 返回事件的版本链（按版本号排序）
+//
+// 未启用版本记录或事件从未被修改时，只返回当前版本。事件不存在时返回 ErrNotFound。
+func (m *EpisodicMemoryStore) GetVersions(ctx context.Context, id string) ([]EpisodeVersion, error) {
+	if err := m.ensureLoaded(ctx); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.history != nil {
+		if versions := m.history.versions[id]; len(versions) > 0 {
+			result := make([]EpisodeVersion, len(versions))
+			copy(result, versions)
+			return result, nil
+		}
+	}
+	for _, ep := range m.episodes {
+		if ep.ID == id {
+			return []EpisodeVersion{{
+				Version:   1,
+				Episode:   versionedEpisode(ep),
+				ValidFrom: time.UnixMilli(ep.Timestamp),
+			}}, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// stateAtLocked 还原 t 时刻的事件（调用方需持有读锁）
+func (m *EpisodicMemoryStore) stateAtLocked(t time.Time) []Episode {
+	cutoff := t.UnixMilli()
+	var state []Episode
+
+	// 没有版本记录的事件以 Timestamp 作为生效时间
+	for _, ep := range m.episodes {
+		if m.history != nil && len(m.history.versions[ep.ID]) > 0 {
+			continue
+		}
+		if ep.Timestamp <= cutoff {
+			state = append(state, versionedEpisode(ep))
+		}
+	}
+
+	if m.history != nil {
+		for _, versions := range m.history.versions {
+			for _, v := range versions {
+				if v.validAt(t) {
+					state = append(state, versionedEpisode(v.Episode))
+					break
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(state, func(i, j int) bool {
+		if state[i].Timestamp != state[j].Timestamp {
+			return state[i].Timestamp < state[j].Timestamp
+		}
+		return state[i].ID < state[j].ID
+	})
+	return state
+}
+
+// retrieveAsOfLocked 在 t 时刻的事件上检索（调用方需持有读锁）
+//
+// 历史状态与当前 TF-IDF 索引不一致，因此为其单独拟合向量化器。
+func (m *EpisodicMemoryStore) retrieveAsOfLocked(query string, t time.Time, options *retrieveOptions) []*MemoryItem {
+	var episodes []Episode
+	for _, ep := range m.stateAtLocked(t) {
+		if options.matchScope(ep.UserID, ep.SessionID) {
+			episodes = append(episodes, ep)
+		}
+	}
+	if len(episodes) == 0 {
+		return nil
+	}
+
+	docs := make([]string, len(episodes))
+	for i, ep := range episodes {
+		docs[i] = ep.Content
+	}
+	tfidf := NewTFIDFVectorizer()
+	vectors := tfidf.FitTransform(docs)
+	for i := range episodes {
+		if i < len(vectors) {
+			episodes[i].Vector = vectors[i]
+		}
+	}
+
+	results := m.tfidfSearch(tfidf, episodes, query, options.limit)
+	if len(results) == 0 {
+		results = m.keywordSearch(episodes, query, options.limit)
+	}
+	return results
+}
@@ -0,0 +1,183 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ahhsitt/helloagents-go/pkg/memory/store"
+)
+
+// DefaultSemanticCollection 语义记忆默认向量集合
+const DefaultSemanticCollection = "semantic_memory"
+
+// 向量 payload 中的保留字段，其余字段即记录的元数据
+const (
+	payloadContent   = "content"
+	payloadTimestamp = "timestamp"
+)
+
+// NewSemanticMemoryWithVectorStore 创建由向量数据库支撑的语义记忆存储
+//
+// 向量写入、删除和相似度检索委托给 vectorStore（如 store.QdrantVectorStore），
+// 本地只缓存内容和元数据，用于 TF-IDF 回退、统计和遗忘，不再保存向量；
+// 本地缓存中没有的记录（如重启后）直接由向量 payload 还原。
+// 实体和关系默认保存在本地，可通过 WithSemanticGraphStore 交给图数据库。
+// collection 为空时使用 DefaultSemanticCollection。
+func NewSemanticMemoryWithVectorStore(embedder Embedder, vectorStore store.VectorStore, collection string, opts ...SemanticMemoryOption) *SemanticMemoryStore {
+	if collection == "" {
+		collection = DefaultSemanticCollection
+	}
+	m := NewSemanticMemory(embedder, opts...)
+	m.vectors = vectorStore
+	m.collection = collection
+	return m
+}
+
+// vectorRecord 构建写入向量数据库的记录，payload 包含内容、时间戳和元数据
+func vectorRecord(id, content string, vector []float32, metadata map[string]interface{}) store.VectorRecord {
+	payload := make(map[string]interface{}, len(metadata)+2)
+	for k, v := range metadata {
+		payload[k] = v
+	}
+	payload[payloadContent] = content
+	payload[payloadTimestamp] = time.Now().UnixMilli()
+
+	return store.VectorRecord{
+		ID:       id,
+		Vector:   vector,
+		Payload:  payload,
+		MemoryID: id,
+	}
+}
+
+// putVectors 写入向量数据库（未配置向量数据库或没有向量时跳过）
+func (m *SemanticMemoryStore) putVectors(ctx context.Context, records []store.VectorRecord) error {
+	if m.vectors == nil {
+		return nil
+	}
+
+	withVector := make([]store.VectorRecord, 0, len(records))
+	for _, rec := range records {
+		if rec.Vector != nil {
+			withVector = append(withVector, rec)
+		}
+	}
+	if len(withVector) == 0 {
+		return nil
+	}
+
+	if err := m.vectors.AddVectors(ctx, m.collection, withVector); err != nil {
+		return fmt.Errorf("put semantic vectors: %w", err)
+	}
+	return nil
+}
+
+// deleteVectors 从向量数据库删除（未配置向量数据库时跳过）
+func (m *SemanticMemoryStore) deleteVectors(ctx context.Context, ids []string) error {
+	if m.vectors == nil || len(ids) == 0 {
+		return nil
+	}
+	if err := m.vectors.DeleteVectors(ctx, m.collection, ids); err != nil {
+		return fmt.Errorf("delete semantic vectors: %w", err)
+	}
+	return nil
+}
+
+// remoteSearch 在向量数据库中检索（不持有锁，命中结果由 remoteResultsLocked 转换）
+//
+// 未配置向量数据库或没有查询向量时返回 nil。用户/会话过滤条件下推到向量数据库。
+func (m *SemanticMemoryStore) remoteSearch(ctx context.Context, queryVector []float32, topK int, options *retrieveOptions) ([]store.VectorSearchResult, error) {
+	if m.vectors == nil || queryVector == nil {
+		return nil, nil
+	}
+
+	var filter *store.VectorFilter
+	if options.scoped() {
+		filter = &store.VectorFilter{UserID: options.userID}
+		if options.sessionID != "" {
+			filter.Conditions = map[string]interface{}{"session_id": options.sessionID}
+		}
+	}
+
+	hits, err := m.vectors.SearchSimilar(ctx, m.collection, queryVector, topK, filter)
+	if err != nil {
+		return nil, fmt.Errorf("search semantic vectors: %w", err)
+	}
+	return hits, nil
+}
+
+// remoteResultsLocked 将向量数据库命中转换为搜索结果（调用方需持有读锁）
+//
+// 命中记录优先使用本地缓存的内容和元数据，以反映本地的重要性更新。
+func (m *SemanticMemoryStore) remoteResultsLocked(hits []store.VectorSearchResult) []SearchResult {
+	local := make(map[string]int, len(m.records))
+	for i, rec := range m.records {
+		local[rec.ID] = i
+	}
+
+	now := time.Now()
+	results := make([]SearchResult, 0, len(hits))
+	for _, hit := range hits {
+		id := hit.MemoryID
+		if id == "" {
+			id = hit.ID
+		}
+
+		var rec semanticRecord
+		if i, ok := local[id]; ok {
+			rec = m.records[i]
+		} else {
+			rec = payloadToRecord(id, hit.Payload)
+		}
+
+		ageDays := float32(now.Sub(rec.Timestamp).Hours() / 24)
+		results = append(results, SearchResult{
+			ID:       rec.ID,
+			Content:  rec.Content,
+			Score:    m.calculateScore(hit.Score, ageDays, rec.Importance),
+			Metadata: rec.Metadata,
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	return results
+}
+
+// payloadToRecord 由向量 payload 还原记录
+func payloadToRecord(id string, payload map[string]interface{}) semanticRecord {
+	metadata := make(map[string]interface{}, len(payload))
+	for k, v := range payload {
+		if k != payloadContent && k != payloadTimestamp && k != "memory_id" {
+			metadata[k] = v
+		}
+	}
+
+	rec := semanticRecord{
+		ID:         id,
+		Metadata:   metadata,
+		Importance: 0.5,
+		Timestamp:  time.Now(),
+	}
+	rec.Content, _ = payload[payloadContent].(string)
+	rec.UserID, _ = payload["user_id"].(string)
+
+	switch imp := payload["importance"].(type) {
+	case float32:
+		rec.Importance = imp
+	case float64:
+		rec.Importance = float32(imp)
+	}
+
+	switch ts := payload[payloadTimestamp].(type) {
+	case int64:
+		rec.Timestamp = time.UnixMilli(ts)
+	case float64:
+		rec.Timestamp = time.UnixMilli(int64(ts))
+	}
+
+	return rec
+}
@@ -7,6 +7,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/ahhsitt/helloagents-go/pkg/core/llm"
 	"github.com/ahhsitt/helloagents-go/pkg/core/message"
 )
 
@@ -17,6 +18,23 @@ type workingMessage struct {
 	Vector     []float32 // TF-IDF 向量
 }
 
+// indexText 返回用于检索的文本：消息内容加上附件说明
+func (wm workingMessage) indexText() string {
+	if len(wm.Message.Attachments) == 0 {
+		return wm.Message.Content
+	}
+	parts := make([]string, 0, len(wm.Message.Attachments)+1)
+	if wm.Message.Content != "" {
+		parts = append(parts, wm.Message.Content)
+	}
+	for _, att := range wm.Message.Attachments {
+		if att.Caption != "" {
+			parts = append(parts, att.Caption)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
 // WorkingMemory 工作记忆实现
 //
 // 基于内存的对话历史存储，支持容量限制、TTL、重要性评分和语义检索。
@@ -26,7 +44,19 @@ type WorkingMemory struct {
 	tokenLimit int
 	ttl        time.Duration
 	tfidf      *TFIDFVectorizer // TF-IDF 向量化器
+	tfidfStale bool             // TF-IDF 索引是否需要重建
+	persist    *persistence     // 可选的持久化存储
+	forgotten  forgetLog        // 遗忘记录，用于统计
 	mu         sync.RWMutex
+
+	reloadInterval time.Duration // 持久化重新加载间隔
+
+	// 溢出摘要
+	summarizer llm.Provider     // 可选的溢出摘要模型
+	summary    string           // 滚动摘要
+	summaryAt  time.Time        // 滚动摘要更新时间
+	overflow   []workingMessage // 等待合并进摘要的淘汰消息
+	summaryMu  sync.Mutex       // 串行化摘要合并
 }
 
 // WorkingMemoryOption 配置选项
@@ -46,6 +76,11 @@ func NewWorkingMemory(opts ...WorkingMemoryOption) *WorkingMemory {
 		opt(m)
 	}
 
+	if m.persist != nil {
+		m.persist.ttl = m.ttl
+		m.persist.reloadInterval = m.reloadInterval
+	}
+
 	return m
 }
 
@@ -77,9 +112,33 @@ func (m *WorkingMemory) AddMessage(ctx context.Context, msg message.Message) err
 
 // AddMessageWithImportance 添加带重要性的消息到记忆
 func (m *WorkingMemory) AddMessageWithImportance(ctx context.Context, msg message.Message, importance float32) error {
+	if err := m.ensureLoaded(ctx); err != nil {
+		return err
+	}
+
 	m.mu.Lock()
-	defer m.mu.Unlock()
+	evicted, err := m.appendLocked(ctx, msg, importance)
+	if err != nil {
+		m.mu.Unlock()
+		return err
+	}
+
+	// 标记 TF-IDF 索引过期，检索时统一重建
+	m.tfidfStale = true
+
+	if m.persist != nil && len(evicted) > 0 {
+		err = m.persist.delete(ctx, evicted...)
+	}
+	m.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	return m.summarizeOverflow(ctx)
+}
 
+// appendLocked 追加消息并应用 LRU 清理，返回被淘汰的消息 ID（调用方需持有写锁并负责重建 TF-IDF）
+func (m *WorkingMemory) appendLocked(ctx context.Context, msg message.Message, importance float32) ([]string, error) {
 	// 设置时间戳
 	if msg.Timestamp.IsZero() {
 		msg.Timestamp = time.Now()
@@ -93,22 +152,35 @@ func (m *WorkingMemory) AddMessageWithImportance(ctx context.Context, msg messag
 		importance = 1
 	}
 
+	if m.persist != nil {
+		ensureMessageID(&msg)
+	}
+
 	wm := workingMessage{
 		Message:    msg,
 		Importance: importance,
 	}
 
+	if m.persist != nil {
+		if err := m.persist.put(ctx, workingMessageToDocument(wm)); err != nil {
+			return nil, err
+		}
+	}
+
 	m.messages = append(m.messages, wm)
 
 	// 应用 LRU 清理
+	var evicted []string
 	if m.maxSize > 0 && len(m.messages) > m.maxSize {
+		for _, old := range m.messages[:len(m.messages)-m.maxSize] {
+			evicted = append(evicted, old.Message.ID)
+		}
+		m.queueOverflowLocked(m.messages[:len(m.messages)-m.maxSize])
 		m.messages = m.messages[len(m.messages)-m.maxSize:]
+		m.forgotten.record(time.Now(), len(evicted))
 	}
 
-	// 重建 TF-IDF（增量更新）
-	m.rebuildTFIDF()
-
-	return nil
+	return evicted, nil
 }
 
 // rebuildTFIDF 重建 TF-IDF 向量化器
@@ -121,7 +193,7 @@ func (m *WorkingMemory) rebuildTFIDF() {
 	// 收集所有文档
 	docs := make([]string, len(m.messages))
 	for i, wm := range m.messages {
-		docs[i] = wm.Message.Content
+		docs[i] = wm.indexText()
 	}
 
 	// 重新训练和转换
@@ -135,8 +207,37 @@ func (m *WorkingMemory) rebuildTFIDF() {
 	}
 }
 
+// Flush 重建过期的 TF-IDF 索引
+//
+// 写操作只将索引标记为过期，索引在下次检索或调用 Flush 时统一重建，
+// 批量写入后可主动调用 Flush 以避免首次检索的重建延迟。
+func (m *WorkingMemory) Flush() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.tfidfStale {
+		m.rebuildTFIDF()
+		m.tfidfStale = false
+	}
+}
+
+// ensureTFIDF 检索前确保 TF-IDF 索引是最新的
+func (m *WorkingMemory) ensureTFIDF() {
+	m.mu.RLock()
+	stale := m.tfidfStale
+	m.mu.RUnlock()
+
+	if stale {
+		m.Flush()
+	}
+}
+
 // GetHistory 获取对话历史
 func (m *WorkingMemory) GetHistory(ctx context.Context, limit int) ([]message.Message, error) {
+	if err := m.ensureLoaded(ctx); err != nil {
+		return nil, err
+	}
+
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
@@ -144,9 +245,12 @@ func (m *WorkingMemory) GetHistory(ctx context.Context, limit int) ([]message.Me
 	messages := m.filterExpired()
 
 	if limit <= 0 || limit >= len(messages) {
-		result := make([]message.Message, len(messages))
-		for i, wm := range messages {
-			result[i] = wm.Message
+		result := make([]message.Message, 0, len(messages)+1)
+		if summary, ok := m.summaryMessageLocked(); ok {
+			result = append(result, summary)
+		}
+		for _, wm := range messages {
+			result = append(result, wm.Message)
 		}
 		return result, nil
 	}
@@ -167,15 +271,25 @@ func (m *WorkingMemory) GetRecentHistory(ctx context.Context, n int) ([]message.
 
 // Clear 清空记忆
 func (m *WorkingMemory) Clear(ctx context.Context) error {
+	if err := m.ensureLoaded(ctx); err != nil {
+		return err
+	}
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.messages = make([]workingMessage, 0)
+	m.summary = ""
+	m.overflow = nil
 	m.tfidf.Clear()
+	if m.persist != nil {
+		return m.persist.clear(ctx)
+	}
 	return nil
 }
 
 // Size 返回当前消息数量
 func (m *WorkingMemory) Size() int {
+	_ = m.ensureLoaded(context.Background())
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 	return len(m.messages)
@@ -199,18 +313,47 @@ func (m *WorkingMemory) filterExpired() []workingMessage {
 	return result
 }
 
+// filterScope 按用户和会话过滤消息，用户/会话 ID 取自消息元数据
+//
+// 工作记忆容量有限，直接扫描过滤，不维护额外索引。
+func filterScope(messages []workingMessage, options *retrieveOptions) []workingMessage {
+	if !options.scoped() {
+		return messages
+	}
+
+	result := make([]workingMessage, 0, len(messages))
+	for _, wm := range messages {
+		userID, _ := wm.Message.Metadata["user_id"].(string)
+		sessionID, _ := wm.Message.Metadata["session_id"].(string)
+		if options.matchScope(userID, sessionID) {
+			result = append(result, wm)
+		}
+	}
+	return result
+}
+
 // GetMessagesWithinTokenLimit 获取不超过 token 限制的消息
 //
-// 从最新消息开始，向前累计直到达到 token 限制。
+// 从最新消息开始，向前累计直到达到 token 限制。设置了 WithOverflowSummarizer 时，
+// 滚动摘要优先占用预算并放在最前面。
 // 注意：此方法使用简化的 token 计算（按字符数估算）。
 func (m *WorkingMemory) GetMessagesWithinTokenLimit(ctx context.Context) ([]message.Message, error) {
+	if err := m.ensureLoaded(ctx); err != nil {
+		return nil, err
+	}
+
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
+	summary, hasSummary := m.summaryMessageLocked()
+
 	if m.tokenLimit <= 0 {
-		result := make([]message.Message, len(m.messages))
-		for i, wm := range m.messages {
-			result[i] = wm.Message
+		result := make([]message.Message, 0, len(m.messages)+1)
+		if hasSummary {
+			result = append(result, summary)
+		}
+		for _, wm := range m.messages {
+			result = append(result, wm.Message)
 		}
 		return result, nil
 	}
@@ -218,12 +361,19 @@ func (m *WorkingMemory) GetMessagesWithinTokenLimit(ctx context.Context) ([]mess
 	messages := m.filterExpired()
 	result := make([]message.Message, 0)
 	totalTokens := 0
+	if hasSummary {
+		if tokens := len(summary.Content) / 3; tokens <= m.tokenLimit {
+			totalTokens = tokens
+		} else {
+			hasSummary = false
+		}
+	}
 
 	// 从最新消息向前遍历
 	for i := len(messages) - 1; i >= 0; i-- {
 		wm := messages[i]
 		// 简化的 token 估算：1 token ≈ 4 字符（英文），中文约 1-2 字符
-		tokens := len(wm.Message.Content) / 3
+		tokens := len(wm.indexText()) / 3
 		if totalTokens+tokens > m.tokenLimit {
 			break
 		}
@@ -231,6 +381,9 @@ func (m *WorkingMemory) GetMessagesWithinTokenLimit(ctx context.Context) ([]mess
 		result = append([]message.Message{wm.Message}, result...)
 	}
 
+	if hasSummary {
+		result = append([]message.Message{summary}, result...)
+	}
 	return result, nil
 }
 
@@ -244,6 +397,15 @@ func (m *WorkingMemory) Add(ctx context.Context, item *MemoryItem) (string, erro
 		return "", err
 	}
 
+	if err := m.AddMessageWithImportance(ctx, itemToMessage(item), item.Importance); err != nil {
+		return "", err
+	}
+
+	return item.ID, nil
+}
+
+// itemToMessage 将记忆项转换为消息
+func itemToMessage(item *MemoryItem) message.Message {
 	msg := message.Message{
 		ID:        item.ID,
 		Role:      message.RoleUser, // 默认角色
@@ -257,17 +419,29 @@ func (m *WorkingMemory) Add(ctx context.Context, item *MemoryItem) (string, erro
 		msg.Role = message.Role(role)
 	}
 
-	if err := m.AddMessageWithImportance(ctx, msg, item.Importance); err != nil {
-		return "", err
+	// 从元数据还原附件引用
+	msg.Attachments = metadataAttachments(item.Metadata["attachments"])
+
+	// 用户 ID 写入元数据，检索时还原
+	if item.UserID != "" {
+		if msg.Metadata == nil {
+			msg.Metadata = make(map[string]interface{})
+		}
+		msg.Metadata["user_id"] = item.UserID
 	}
 
-	return item.ID, nil
+	return msg
 }
 
 // Retrieve 检索记忆（实现 Memory 接口）
 //
 // 使用 TF-IDF 语义检索，失败时回退到关键词匹配。
+// 用户/会话过滤条件匹配消息元数据中的 user_id 和 session_id。
 func (m *WorkingMemory) Retrieve(ctx context.Context, query string, opts ...RetrieveOption) ([]*MemoryItem, error) {
+	if err := m.ensureLoaded(ctx); err != nil {
+		return nil, err
+	}
+
 	options := &retrieveOptions{
 		limit: 10,
 	}
@@ -275,10 +449,12 @@ func (m *WorkingMemory) Retrieve(ctx context.Context, query string, opts ...Retr
 		opt(options)
 	}
 
+	m.ensureTFIDF()
+
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	messages := m.filterExpired()
+	messages := filterScope(m.filterExpired(), options)
 	if len(messages) == 0 {
 		return nil, nil
 	}
@@ -361,7 +537,7 @@ func (m *WorkingMemory) keywordSearch(query string, messages []workingMessage, l
 
 	scored := make([]scoredMessage, 0, len(messages))
 	for _, wm := range messages {
-		content := strings.ToLower(wm.Message.Content)
+		content := strings.ToLower(wm.indexText())
 		matchCount := 0
 		for _, kw := range keywords {
 			if strings.Contains(content, kw) {
@@ -420,11 +596,16 @@ func (m *WorkingMemory) messageToItem(wm workingMessage, score float32) *MemoryI
 	}
 	metadata["role"] = string(wm.Message.Role)
 	metadata["score"] = score
+	if len(wm.Message.Attachments) > 0 {
+		metadata["attachments"] = wm.Message.Attachments
+	}
+	userID, _ := metadata["user_id"].(string)
 
 	return &MemoryItem{
 		ID:         wm.Message.ID,
 		Content:    wm.Message.Content,
 		MemoryType: MemoryTypeWorking,
+		UserID:     userID,
 		Timestamp:  wm.Message.Timestamp,
 		Importance: wm.Importance,
 		Metadata:   metadata,
@@ -433,6 +614,10 @@ func (m *WorkingMemory) messageToItem(wm workingMessage, score float32) *MemoryI
 
 // Update 更新记忆（实现 Memory 接口）
 func (m *WorkingMemory) Update(ctx context.Context, id string, opts ...UpdateOption) error {
+	if err := m.ensureLoaded(ctx); err != nil {
+		return err
+	}
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -452,8 +637,11 @@ func (m *WorkingMemory) Update(ctx context.Context, id string, opts ...UpdateOpt
 			if options.metadata != nil {
 				m.messages[i].Message.Metadata = options.metadata
 			}
-			// 重建 TF-IDF
-			m.rebuildTFIDF()
+			// 标记 TF-IDF 索引过期
+			m.tfidfStale = true
+			if m.persist != nil {
+				return m.persist.put(ctx, workingMessageToDocument(m.messages[i]))
+			}
 			return nil
 		}
 	}
@@ -463,13 +651,20 @@ func (m *WorkingMemory) Update(ctx context.Context, id string, opts ...UpdateOpt
 
 // Remove 删除记忆（实现 Memory 接口）
 func (m *WorkingMemory) Remove(ctx context.Context, id string) error {
+	if err := m.ensureLoaded(ctx); err != nil {
+		return err
+	}
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
 	for i := range m.messages {
 		if m.messages[i].Message.ID == id {
 			m.messages = append(m.messages[:i], m.messages[i+1:]...)
-			m.rebuildTFIDF()
+			m.tfidfStale = true
+			if m.persist != nil {
+				return m.persist.delete(ctx, id)
+			}
 			return nil
 		}
 	}
@@ -477,8 +672,96 @@ func (m *WorkingMemory) Remove(ctx context.Context, id string) error {
 	return ErrNotFound
 }
 
+// AddBatch 批量添加记忆项（实现 Memory 接口）
+//
+// 所有记忆项校验通过后才会写入。
+func (m *WorkingMemory) AddBatch(ctx context.Context, items []*MemoryItem) ([]string, error) {
+	if err := validateItems(items); err != nil {
+		return nil, err
+	}
+	if err := m.ensureLoaded(ctx); err != nil {
+		return nil, err
+	}
+
+	ids, err := m.addBatchLocked(ctx, items)
+	if err != nil {
+		return ids, err
+	}
+	return ids, m.summarizeOverflow(ctx)
+}
+
+// addBatchLocked 在写锁内追加记忆项并删除被淘汰的持久化消息
+func (m *WorkingMemory) addBatchLocked(ctx context.Context, items []*MemoryItem) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var evicted []string
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		e, err := m.appendLocked(ctx, itemToMessage(item), item.Importance)
+		if err != nil {
+			m.tfidfStale = true
+			return ids, err
+		}
+		evicted = append(evicted, e...)
+		ids = append(ids, item.ID)
+	}
+
+	m.tfidfStale = true
+
+	if m.persist != nil && len(evicted) > 0 {
+		return ids, m.persist.delete(ctx, evicted...)
+	}
+
+	return ids, nil
+}
+
+// RemoveBatch 批量删除记忆（实现 Memory 接口）
+//
+// 删除所有存在的记录；有记录不存在时返回 ErrNotFound。
+func (m *WorkingMemory) RemoveBatch(ctx context.Context, ids []string) error {
+	if err := m.ensureLoaded(ctx); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	targets := idSet(ids)
+	var removed []string
+	kept := m.messages[:0]
+	for _, wm := range m.messages {
+		if targets[wm.Message.ID] {
+			removed = append(removed, wm.Message.ID)
+			continue
+		}
+		kept = append(kept, wm)
+	}
+	m.messages = kept
+
+	if len(removed) > 0 {
+		m.tfidfStale = true
+		if m.persist != nil {
+			if err := m.persist.delete(ctx, removed...); err != nil {
+				return err
+			}
+		}
+	}
+
+	return missingIDsError(ids, idSet(removed))
+}
+
+// RetrieveMulti 批量检索记忆（实现 Memory 接口）
+//
+// 结果顺序与 queries 一致。
+func (m *WorkingMemory) RetrieveMulti(ctx context.Context, queries []string, opts ...RetrieveOption) ([][]*MemoryItem, error) {
+	return retrieveEach(ctx, m, queries, opts...)
+}
+
 // Has 检查记忆是否存在（实现 Memory 接口）
 func (m *WorkingMemory) Has(ctx context.Context, id string) bool {
+	_ = m.ensureLoaded(ctx)
+
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
@@ -491,43 +774,16 @@ func (m *WorkingMemory) Has(ctx context.Context, id string) bool {
 }
 
 // GetStats 获取统计信息（实现 Memory 接口）
-func (m *WorkingMemory) GetStats(ctx context.Context) (*MemoryStats, error) {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
-
-	messages := m.filterExpired()
-	if len(messages) == 0 {
-		return &MemoryStats{Count: 0}, nil
-	}
-
-	var totalImportance float32
-	var oldestTs, newestTs int64
-	totalTokens := 0
-
-	for i, wm := range messages {
-		totalImportance += wm.Importance
-		totalTokens += len(wm.Message.Content) / 3
-
-		ts := wm.Message.Timestamp.UnixMilli()
-		if i == 0 || ts < oldestTs {
-			oldestTs = ts
-		}
-		if i == 0 || ts > newestTs {
-			newestTs = ts
-		}
-	}
-
-	return &MemoryStats{
-		Count:           len(messages),
-		TotalTokens:     totalTokens,
-		OldestTimestamp: oldestTs,
-		NewestTimestamp: newestTs,
-		AvgImportance:   totalImportance / float32(len(messages)),
-	}, nil
+func (m *WorkingMemory) GetStats(ctx context.Context, opts ...RetrieveOption) (*MemoryStats, error) {
+	return collectStats(ctx, m, opts)
 }
 
 // Forget 执行遗忘（实现 Memory 接口的扩展）
 func (m *WorkingMemory) Forget(ctx context.Context, strategy ForgetStrategy, opts ...ForgetOption) (int, error) {
+	if err := m.ensureLoaded(ctx); err != nil {
+		return 0, err
+	}
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -537,7 +793,7 @@ func (m *WorkingMemory) Forget(ctx context.Context, strategy ForgetStrategy, opt
 	}
 
 	originalCount := len(m.messages)
-	var remaining []workingMessage
+	var remaining, forgotten []workingMessage
 
 	switch strategy {
 	case ForgetByImportance:
@@ -548,6 +804,8 @@ func (m *WorkingMemory) Forget(ctx context.Context, strategy ForgetStrategy, opt
 		for _, wm := range m.messages {
 			if wm.Importance >= threshold {
 				remaining = append(remaining, wm)
+			} else {
+				forgotten = append(forgotten, wm)
 			}
 		}
 
@@ -560,6 +818,8 @@ func (m *WorkingMemory) Forget(ctx context.Context, strategy ForgetStrategy, opt
 		for _, wm := range m.messages {
 			if wm.Message.Timestamp.After(cutoff) {
 				remaining = append(remaining, wm)
+			} else {
+				forgotten = append(forgotten, wm)
 			}
 		}
 
@@ -578,20 +838,106 @@ func (m *WorkingMemory) Forget(ctx context.Context, strategy ForgetStrategy, opt
 			return sorted[i].Importance > sorted[j].Importance
 		})
 		remaining = sorted[:targetCapacity]
+		forgotten = sorted[targetCapacity:]
 		// 按时间重新排序
 		sort.Slice(remaining, func(i, j int) bool {
 			return remaining[i].Message.Timestamp.Before(remaining[j].Message.Timestamp)
 		})
+
+	default:
+		forgotten = m.messages
+	}
+
+	if options.archive != nil {
+		items := make([]*MemoryItem, len(forgotten))
+		for i, wm := range forgotten {
+			// 非持久化的消息可能没有 ID，归档时补充以便恢复
+			ensureMessageID(&wm.Message)
+			wm.Message.Metadata = cloneMetadata(wm.Message.Metadata)
+			items[i] = m.messageToItem(wm, 0)
+		}
+		if err := options.archiveItems(ctx, strategy, items); err != nil {
+			return 0, err
+		}
+	}
+
+	if m.persist != nil {
+		ids := make([]string, len(forgotten))
+		for i, wm := range forgotten {
+			ids[i] = wm.Message.ID
+		}
+		if err := m.persist.delete(ctx, ids...); err != nil {
+			return 0, err
+		}
 	}
 
 	m.messages = remaining
-	m.rebuildTFIDF()
+	m.tfidfStale = true
+	m.forgotten.record(time.Now(), originalCount-len(m.messages))
 
 	return originalCount - len(m.messages), nil
 }
 
+// DecayImportance 衰减所有消息的重要性（实现 ImportanceDecayer 接口）
+func (m *WorkingMemory) DecayImportance(ctx context.Context, factor float64, floor float32) (int, error) {
+	if err := m.ensureLoaded(ctx); err != nil {
+		return 0, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	decayed := 0
+	for i := range m.messages {
+		importance := decayImportance(m.messages[i].Importance, factor, floor)
+		if importance == m.messages[i].Importance {
+			continue
+		}
+		m.messages[i].Importance = importance
+		decayed++
+		if m.persist != nil {
+			if err := m.persist.put(ctx, workingMessageToDocument(m.messages[i])); err != nil {
+				return decayed, err
+			}
+		}
+	}
+
+	return decayed, nil
+}
+
+// ReinforceImportance 强化指定消息的重要性（实现 ImportanceReinforcer 接口）
+func (m *WorkingMemory) ReinforceImportance(ctx context.Context, ids []string, boost float32) (int, error) {
+	if err := m.ensureLoaded(ctx); err != nil {
+		return 0, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	set := idSet(ids)
+	reinforced := 0
+	for i := range m.messages {
+		if !set[m.messages[i].Message.ID] {
+			continue
+		}
+		m.messages[i].Importance = reinforceImportance(m.messages[i].Importance, boost)
+		reinforced++
+		if m.persist != nil {
+			if err := m.persist.put(ctx, workingMessageToDocument(m.messages[i])); err != nil {
+				return reinforced, err
+			}
+		}
+	}
+
+	return reinforced, nil
+}
+
 // GetImportant 获取高重要性记忆
 func (m *WorkingMemory) GetImportant(ctx context.Context, limit int) ([]*MemoryItem, error) {
+	if err := m.ensureLoaded(ctx); err != nil {
+		return nil, err
+	}
+
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
@@ -621,6 +967,10 @@ func (m *WorkingMemory) GetImportant(ctx context.Context, limit int) ([]*MemoryI
 
 // GetRecent 获取最近记忆
 func (m *WorkingMemory) GetRecent(ctx context.Context, limit int) ([]*MemoryItem, error) {
+	if err := m.ensureLoaded(ctx); err != nil {
+		return nil, err
+	}
+
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
@@ -651,6 +1001,10 @@ func (m *WorkingMemory) GetRecent(ctx context.Context, limit int) ([]*MemoryItem
 
 // GetContextSummary 获取上下文摘要
 func (m *WorkingMemory) GetContextSummary(ctx context.Context, maxLength int) (string, error) {
+	if err := m.ensureLoaded(ctx); err != nil {
+		return "", err
+	}
+
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
@@ -674,3 +1028,33 @@ func (m *WorkingMemory) GetContextSummary(ctx context.Context, maxLength int) (s
 
 	return builder.String(), nil
 }
+
+// metadataAttachments 解析元数据中的附件引用
+//
+// 支持 []message.Attachment，以及 JSON 解码得到的 []interface{}（元素为对象）。
+func metadataAttachments(v interface{}) []message.Attachment {
+	switch atts := v.(type) {
+	case []message.Attachment:
+		return atts
+	case []interface{}:
+		result := make([]message.Attachment, 0, len(atts))
+		for _, raw := range atts {
+			obj, ok := raw.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			att := message.Attachment{}
+			att.ID, _ = obj["id"].(string)
+			att.MimeType, _ = obj["mime_type"].(string)
+			att.Caption, _ = obj["caption"].(string)
+			att.URI, _ = obj["uri"].(string)
+			if att.ID != "" || att.URI != "" {
+				result = append(result, att)
+			}
+		}
+		if len(result) > 0 {
+			return result
+		}
+	}
+	return nil
+}
@@ -36,6 +36,10 @@ const (
 	AttrMemoryType     = "memory.type"
 	AttrMemoryCapacity = "memory.capacity"
 	AttrMemoryUsage    = "memory.usage"
+	AttrMemoryOp       = "memory.operation"
+	AttrMemoryResults  = "memory.result_count"
+	AttrMemoryStrategy = "memory.forget_strategy"
+	AttrMemoryJob      = "memory.maintenance_job"
 
 	// RAG 相关属性
 	AttrRAGDocCount   = "rag.document_count"
@@ -43,6 +47,10 @@ const (
 	AttrRAGTopK       = "rag.top_k"
 	AttrRAGScore      = "rag.score"
 
+	// Context 相关属性
+	AttrContextStage    = "context.stage"
+	AttrContextGatherer = "context.gatherer"
+
 	// Error 相关属性
 	AttrErrorType      = "error.type"
 	AttrErrorMessage   = "error.message"
@@ -93,6 +101,16 @@ func ToolDuration(ms int64) attribute.KeyValue {
 	return attribute.Int64(AttrToolDuration, ms)
 }
 
+// MemoryType 创建记忆类型属性
+func MemoryType(typ string) attribute.KeyValue {
+	return attribute.String(AttrMemoryType, typ)
+}
+
+// MemoryOp 创建记忆操作属性
+func MemoryOp(op string) attribute.KeyValue {
+	return attribute.String(AttrMemoryOp, op)
+}
+
 // ErrorAttrs 创建错误属性
 func ErrorAttrs(errType, message string, retryable bool) []attribute.KeyValue {
 	return []attribute.KeyValue{
@@ -25,16 +25,28 @@ const (
 	MetricToolErrors       = "tool.errors"        // 计数器: 工具错误次数
 
 	// Memory 指标
-	MetricMemoryOperations = "memory.operations" // 计数器: 记忆操作次数
-	MetricMemorySize       = "memory.size"       // 仪表: 记忆大小
-	MetricMemoryHits       = "memory.hits"       // 计数器: 记忆命中次数
-	MetricMemoryMisses     = "memory.misses"     // 计数器: 记忆未命中次数
+	MetricMemoryOperations          = "memory.operations"           // 计数器: 记忆操作次数
+	MetricMemorySize                = "memory.size"                 // 仪表: 记忆大小
+	MetricMemoryHits                = "memory.hits"                 // 计数器: 记忆命中次数
+	MetricMemoryMisses              = "memory.misses"               // 计数器: 记忆未命中次数
+	MetricMemoryOperationDuration   = "memory.operation.duration"   // 直方图: 记忆操作耗时(ms)
+	MetricMemoryRetrieveDuration    = "memory.retrieve.duration"    // 直方图: 单个记忆类型的检索耗时(ms)
+	MetricMemoryForgotten           = "memory.forgotten"            // 计数器: 被遗忘的记忆数
+	MetricMemoryConsolidated        = "memory.consolidated"         // 计数器: 被整合的工作记忆数
+	MetricMemoryMaintenanceRuns     = "memory.maintenance.runs"     // 计数器: 维护任务执行次数
+	MetricMemoryMaintenanceDuration = "memory.maintenance.duration" // 直方图: 维护任务耗时(ms)
+	MetricMemoryMaintenanceAffected = "memory.maintenance.affected" // 计数器: 维护任务处理的记忆数
 
 	// RAG 指标
 	MetricRAGQueries         = "rag.queries"          // 计数器: RAG 查询次数
 	MetricRAGQueryDuration   = "rag.query.duration"   // 直方图: RAG 查询时间(ms)
 	MetricRAGDocumentsLoaded = "rag.documents.loaded" // 计数器: 加载文档数
 	MetricRAGChunksIndexed   = "rag.chunks.indexed"   // 计数器: 索引块数
+
+	// Context 指标
+	MetricContextStageDuration    = "context.stage.duration"    // 直方图: GSSC 各阶段耗时(ms)
+	MetricContextGathererDuration = "context.gatherer.duration" // 直方图: 单个收集器耗时(ms)
+	MetricContextSlowGatherers    = "context.gatherer.slow"     // 计数器: 慢收集器告警次数
 )
 
 // MetricUnit 指标单位
@@ -80,9 +92,20 @@ var PredefinedMetrics = []MetricDescription{
 	{MetricMemorySize, "Size of memory", UnitCount, "gauge"},
 	{MetricMemoryHits, "Number of memory cache hits", UnitCount, "counter"},
 	{MetricMemoryMisses, "Number of memory cache misses", UnitCount, "counter"},
+	{MetricMemoryOperationDuration, "Duration of memory operations", UnitMilliseconds, "histogram"},
+	{MetricMemoryRetrieveDuration, "Duration of memory retrieval per memory type", UnitMilliseconds, "histogram"},
+	{MetricMemoryForgotten, "Number of forgotten memories", UnitCount, "counter"},
+	{MetricMemoryConsolidated, "Number of consolidated working memories", UnitCount, "counter"},
+	{MetricMemoryMaintenanceRuns, "Number of memory maintenance job runs", UnitCount, "counter"},
+	{MetricMemoryMaintenanceDuration, "Duration of memory maintenance job runs", UnitMilliseconds, "histogram"},
+	{MetricMemoryMaintenanceAffected, "Number of memories affected by maintenance jobs", UnitCount, "counter"},
 
 	{MetricRAGQueries, "Number of RAG queries", UnitCount, "counter"},
 	{MetricRAGQueryDuration, "Duration of RAG queries", UnitMilliseconds, "histogram"},
 	{MetricRAGDocumentsLoaded, "Number of documents loaded", UnitCount, "counter"},
 	{MetricRAGChunksIndexed, "Number of chunks indexed", UnitCount, "counter"},
+
+	{MetricContextStageDuration, "Duration of context build stages", UnitMilliseconds, "histogram"},
+	{MetricContextGathererDuration, "Duration of context gatherers", UnitMilliseconds, "histogram"},
+	{MetricContextSlowGatherers, "Number of slow gatherer warnings", UnitCount, "counter"},
 }
@@ -0,0 +1,56 @@
+package a2a
+
+import (
+	"context"
+	"strings"
+
+	"github.com/ahhsitt/helloagents-go/pkg/agents"
+)
+
+// AgentHandler 将 agents.Agent 适配为 TaskHandler
+//
+// 用户消息的文本作为 Input.Query，任务的 ContextID 作为 Input.SessionID，
+// 使同一上下文的多次任务共享 Agent 的会话记忆。Agent 以 RunStream 执行，文本块实时经 emit 输出。
+func AgentHandler(agent agents.Agent) TaskHandler {
+	return func(ctx context.Context, req TaskRequest, emit func(text string)) (string, error) {
+		input := agents.Input{
+			Query:     req.Message.Text(),
+			SessionID: req.ContextID,
+			Context: map[string]interface{}{
+				"a2a_task_id":    req.TaskID,
+				"a2a_context_id": req.ContextID,
+			},
+		}
+
+		chunks, errs := agent.RunStream(ctx, input)
+		var response strings.Builder
+		for chunk := range chunks {
+			if chunk.Type == agents.ChunkTypeText && chunk.Content != "" {
+				response.WriteString(chunk.Content)
+				emit(chunk.Content)
+			}
+		}
+		if err := <-errs; err != nil {
+			return "", err
+		}
+		return response.String(), nil
+	}
+}
+
+// NewAgentServer 创建将 agent 以 A2A 协议对外提供的服务器
+//
+// card 的 Name 为空时使用 agent.Name()。
+//
+// 使用示例:
+//
+//	server := a2a.NewAgentServer(agent, a2a.AgentCard{
+//	    Description: "Answers questions about the weather",
+//	    Skills: []a2a.AgentSkill{{ID: "forecast", Name: "Forecast", Tags: []string{"weather"}}},
+//	})
+//	server.RunHTTP(ctx, ":8080")
+func NewAgentServer(agent agents.Agent, card AgentCard, opts ...ServerOption) *Server {
+	if card.Name == "" {
+		card.Name = agent.Name()
+	}
+	return NewServer(card, AgentHandler(agent), opts...)
+}
@@ -0,0 +1,379 @@
+package a2a
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+)
+
+// ClientOption 客户端选项
+type ClientOption func(*Client)
+
+// WithHTTPClient 设置发送请求的 HTTP 客户端（默认 http.DefaultClient）
+func WithHTTPClient(client *http.Client) ClientOption {
+	return func(c *Client) {
+		if client != nil {
+			c.httpClient = client
+		}
+	}
+}
+
+// WithHeaders 设置每个请求附带的请求头，如认证信息
+func WithHeaders(headers map[string]string) ClientOption {
+	return func(c *Client) {
+		for k, v := range headers {
+			c.headers[k] = v
+		}
+	}
+}
+
+// Client A2A 客户端
+//
+// 使用示例:
+//
+//	client := a2a.NewClient("http://localhost:8080/")
+//	card, err := client.Card(ctx)
+//	result, err := client.SendMessage(ctx, a2a.NewTextMessage(a2a.RoleUser, "Hello"))
+//	fmt.Println(result.Text())
+type Client struct {
+	url        string
+	httpClient *http.Client
+	headers    map[string]string
+	requestID  atomic.Int64
+}
+
+// NewClient 创建 A2A 客户端，agentURL 为远程智能体的 JSON-RPC 地址（即 AgentCard.URL）
+func NewClient(agentURL string, opts ...ClientOption) *Client {
+	c := &Client{
+		url:        agentURL,
+		httpClient: http.DefaultClient,
+		headers:    make(map[string]string),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// URL 返回远程智能体的地址
+func (c *Client) URL() string {
+	return c.url
+}
+
+// SendResult message/send 的结果，远程智能体返回任务或直接回复一条消息
+type SendResult struct {
+	Task    *Task
+	Message *Message
+}
+
+// Text 返回结果文本
+func (r *SendResult) Text() string {
+	switch {
+	case r.Task != nil:
+		return r.Task.Text()
+	case r.Message != nil:
+		return r.Message.Text()
+	default:
+		return ""
+	}
+}
+
+// StreamEvent message/stream 推送的事件，按 Kind 只有一个字段不为 nil
+type StreamEvent struct {
+	Kind           string
+	Task           *Task
+	Message        *Message
+	StatusUpdate   *TaskStatusUpdateEvent
+	ArtifactUpdate *TaskArtifactUpdateEvent
+}
+
+// Card 获取远程智能体的名片
+//
+// 从地址所在主机的 AgentCardPath 获取，不存在时回退到 LegacyAgentCardPath。
+func (c *Client) Card(ctx context.Context) (*AgentCard, error) {
+	base, err := url.Parse(c.url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid agent url: %w", err)
+	}
+
+	var lastErr error
+	for _, path := range []string{AgentCardPath, LegacyAgentCardPath} {
+		cardURL := (&url.URL{Scheme: base.Scheme, Host: base.Host, Path: path}).String()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, cardURL, nil)
+		if err != nil {
+			return nil, err
+		}
+		c.setHeaders(req)
+		req.Header.Set("Accept", "application/json")
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch agent card: %w", err)
+		}
+		if resp.StatusCode == http.StatusNotFound {
+			resp.Body.Close()
+			lastErr = fmt.Errorf("agent card not found at %s", cardURL)
+			continue
+		}
+		if err := checkStatus(resp); err != nil {
+			return nil, fmt.Errorf("failed to fetch agent card: %w", err)
+		}
+
+		var card AgentCard
+		err = json.NewDecoder(resp.Body).Decode(&card)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse agent card: %w", err)
+		}
+		return &card, nil
+	}
+	return nil, lastErr
+}
+
+// SendMessage 发送消息（message/send）并等待结果
+func (c *Client) SendMessage(ctx context.Context, msg Message) (*SendResult, error) {
+	var raw json.RawMessage
+	if err := c.call(ctx, MethodSendMessage, newSendParams(msg), &raw); err != nil {
+		return nil, err
+	}
+	event, err := parseEvent(raw)
+	if err != nil {
+		return nil, err
+	}
+	if event.Task == nil && event.Message == nil {
+		return nil, fmt.Errorf("unexpected message/send result kind %q", event.Kind)
+	}
+	return &SendResult{Task: event.Task, Message: event.Message}, nil
+}
+
+// StreamMessage 发送消息（message/stream）并接收流式事件
+//
+// 返回两个 channel：
+//   - <-chan StreamEvent: 任务、状态更新和产物更新事件，流结束时关闭
+//   - <-chan error: 错误通道（最多一个错误）
+func (c *Client) StreamMessage(ctx context.Context, msg Message) (<-chan StreamEvent, <-chan error) {
+	events := make(chan StreamEvent, 10)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(events)
+		defer close(errs)
+
+		resp, err := c.post(ctx, MethodStreamMessage, newSendParams(msg), "text/event-stream")
+		if err != nil {
+			errs <- err
+			return
+		}
+		defer resp.Body.Close()
+
+		// 服务器可能以普通 JSON 响应返回错误
+		if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+			var rpcResp JSONRPCResponse
+			if err := json.NewDecoder(resp.Body).Decode(&rpcResp); err != nil {
+				errs <- fmt.Errorf("failed to parse response: %w", err)
+				return
+			}
+			if rpcResp.Error != nil {
+				errs <- rpcResp.Error
+				return
+			}
+			errs <- fmt.Errorf("server did not return an event stream")
+			return
+		}
+
+		err = readEventStream(resp.Body, func(data []byte) error {
+			var rpcResp JSONRPCResponse
+			if err := json.Unmarshal(data, &rpcResp); err != nil {
+				return fmt.Errorf("failed to parse event: %w", err)
+			}
+			if rpcResp.Error != nil {
+				return rpcResp.Error
+			}
+			event, err := parseEvent(rpcResp.Result)
+			if err != nil {
+				return err
+			}
+			select {
+			case events <- *event:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+		if err != nil {
+			errs <- err
+		}
+	}()
+
+	return events, errs
+}
+
+// GetTask 查询任务（tasks/get），historyLength 不为 nil 时只返回最近的历史消息
+func (c *Client) GetTask(ctx context.Context, taskID string, historyLength *int) (*Task, error) {
+	var task Task
+	if err := c.call(ctx, MethodGetTask, TaskQueryParams{ID: taskID, HistoryLength: historyLength}, &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// CancelTask 取消任务（tasks/cancel）
+func (c *Client) CancelTask(ctx context.Context, taskID string) (*Task, error) {
+	var task Task
+	if err := c.call(ctx, MethodCancelTask, TaskIDParams{ID: taskID}, &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// call 发送 JSON-RPC 请求并解析结果，错误响应以 *JSONRPCError 返回
+func (c *Client) call(ctx context.Context, method string, params, result interface{}) error {
+	resp, err := c.post(ctx, method, params, "application/json")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var rpcResp JSONRPCResponse
+	if err := json.NewDecoder(resp.Body).Decode(&rpcResp); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	if rpcResp.Error != nil {
+		return rpcResp.Error
+	}
+	if err := json.Unmarshal(rpcResp.Result, result); err != nil {
+		return fmt.Errorf("failed to parse %s result: %w", method, err)
+	}
+	return nil
+}
+
+// post 发送 JSON-RPC 请求，返回状态码为 200 的响应
+func (c *Client) post(ctx context.Context, method string, params interface{}, accept string) (*http.Response, error) {
+	rawParams, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal params: %w", err)
+	}
+	body, err := json.Marshal(JSONRPCRequest{
+		JSONRPC: JSONRPCVersion,
+		ID:      c.requestID.Add(1),
+		Method:  method,
+		Params:  rawParams,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	c.setHeaders(req)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", accept)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s request failed: %w", method, err)
+	}
+	if err := checkStatus(resp); err != nil {
+		return nil, fmt.Errorf("%s request failed: %w", method, err)
+	}
+	return resp, nil
+}
+
+// setHeaders 设置自定义请求头
+func (c *Client) setHeaders(req *http.Request) {
+	for k, v := range c.headers {
+		req.Header.Set(k, v)
+	}
+}
+
+// checkStatus 状态码不是 200 时关闭响应体并返回错误
+func checkStatus(resp *http.Response) error {
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+}
+
+// newSendParams 创建发送消息的参数
+func newSendParams(msg Message) MessageSendParams {
+	if msg.Kind == "" {
+		msg.Kind = KindMessage
+	}
+	return MessageSendParams{Message: msg}
+}
+
+// parseEvent 按 kind 字段解析任务、消息或更新事件
+func parseEvent(raw json.RawMessage) (*StreamEvent, error) {
+	var head struct {
+		Kind string `json:"kind"`
+	}
+	if err := json.Unmarshal(raw, &head); err != nil {
+		return nil, fmt.Errorf("failed to parse event: %w", err)
+	}
+
+	event := &StreamEvent{Kind: head.Kind}
+	var target interface{}
+	switch head.Kind {
+	case KindTask:
+		event.Task = &Task{}
+		target = event.Task
+	case KindMessage:
+		event.Message = &Message{}
+		target = event.Message
+	case KindStatusUpdate:
+		event.StatusUpdate = &TaskStatusUpdateEvent{}
+		target = event.StatusUpdate
+	case KindArtifactUpdate:
+		event.ArtifactUpdate = &TaskArtifactUpdateEvent{}
+		target = event.ArtifactUpdate
+	default:
+		return nil, fmt.Errorf("unknown event kind %q", head.Kind)
+	}
+	if err := json.Unmarshal(raw, target); err != nil {
+		return nil, fmt.Errorf("failed to parse %s event: %w", head.Kind, err)
+	}
+	return event, nil
+}
+
+// readEventStream 逐个读取 SSE 事件的 data，直到流结束或 onData 返回错误
+func readEventStream(body io.Reader, onData func(data []byte) error) error {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 1024*1024), maxBodySize)
+
+	var data []byte
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if len(data) > 0 {
+				if err := onData(data); err != nil {
+					return err
+				}
+			}
+			data = nil
+		case strings.HasPrefix(line, "data:"):
+			if len(data) > 0 {
+				data = append(data, '\n')
+			}
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " ")...)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read event stream: %w", err)
+	}
+	if len(data) > 0 {
+		return onData(data)
+	}
+	return nil
+}
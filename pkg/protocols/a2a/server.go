@@ -0,0 +1,571 @@
+package a2a
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultMaxTasks 服务器默认保留的任务数
+const DefaultMaxTasks = 1000
+
+// maxBodySize 请求体大小上限
+const maxBodySize = 10 * 1024 * 1024
+
+// errTaskCanceled 任务被 tasks/cancel 取消
+var errTaskCanceled = errors.New("task canceled")
+
+// TaskRequest 交给 TaskHandler 执行的任务
+type TaskRequest struct {
+	// TaskID 任务 ID
+	TaskID string
+	// ContextID 会话上下文 ID，同一上下文的任务属于同一段对话
+	ContextID string
+	// Message 用户消息
+	Message Message
+}
+
+// TaskHandler 执行任务并返回结果文本
+//
+// emit 用于流式输出结果片段（message/stream 时作为产物块实时推送），
+// 不流式输出的处理函数可以忽略 emit。返回的文本为空时以 emit 输出的全部片段作为结果。
+// 任务被取消时 ctx 会被取消。
+type TaskHandler func(ctx context.Context, req TaskRequest, emit func(text string)) (string, error)
+
+// ServerOption 服务器选项
+type ServerOption func(*Server)
+
+// WithMaxTasks 设置保留的任务数上限（默认 DefaultMaxTasks），超出时丢弃最早结束的任务
+func WithMaxTasks(n int) ServerOption {
+	return func(s *Server) {
+		if n > 0 {
+			s.maxTasks = n
+		}
+	}
+}
+
+// Server A2A 服务器
+//
+// 实现 http.Handler：GET 智能体名片路径返回 AgentCard，其余 POST 请求按 JSON-RPC 处理
+// message/send、message/stream、tasks/get 和 tasks/cancel。任务保存在内存中。
+// message/send 阻塞到任务结束后返回任务；message/stream 以 SSE 推送任务状态和产物更新。
+//
+// 使用示例:
+//
+//	server := a2a.NewServer(a2a.AgentCard{
+//	    Name:        "translator",
+//	    Description: "Translate text into English",
+//	}, func(ctx context.Context, req a2a.TaskRequest, emit func(string)) (string, error) {
+//	    return translate(ctx, req.Message.Text())
+//	})
+//	http.ListenAndServe(":8080", server)
+type Server struct {
+	card     AgentCard
+	handler  TaskHandler
+	maxTasks int
+
+	mu    sync.Mutex
+	tasks map[string]*taskEntry
+	order []string
+}
+
+// taskEntry 服务器保存的任务
+type taskEntry struct {
+	task   Task
+	cancel context.CancelCauseFunc
+}
+
+// NewServer 创建 A2A 服务器
+func NewServer(card AgentCard, handler TaskHandler, opts ...ServerOption) *Server {
+	if card.ProtocolVersion == "" {
+		card.ProtocolVersion = ProtocolVersion
+	}
+	if card.Version == "" {
+		card.Version = "1.0.0"
+	}
+	if card.PreferredTransport == "" {
+		card.PreferredTransport = "JSONRPC"
+	}
+	if len(card.DefaultInputModes) == 0 {
+		card.DefaultInputModes = []string{"text/plain"}
+	}
+	if len(card.DefaultOutputModes) == 0 {
+		card.DefaultOutputModes = []string{"text/plain"}
+	}
+	if card.Skills == nil {
+		card.Skills = []AgentSkill{}
+	}
+	card.Capabilities.Streaming = true
+
+	s := &Server{
+		card:     card,
+		handler:  handler,
+		maxTasks: DefaultMaxTasks,
+		tasks:    make(map[string]*taskEntry),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Card 返回智能体名片
+func (s *Server) Card() AgentCard {
+	return s.card
+}
+
+// RunHTTP 在 addr 上运行服务器，ctx 取消时取消全部进行中的任务并优雅退出
+func (s *Server) RunHTTP(ctx context.Context, addr string) error {
+	httpServer := &http.Server{
+		Addr:              addr,
+		Handler:           s,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	httpServer.RegisterOnShutdown(s.Close)
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- httpServer.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return fmt.Errorf("http server failed: %w", err)
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := httpServer.Shutdown(shutdownCtx); err != nil {
+			return fmt.Errorf("failed to shut down http server: %w", err)
+		}
+		return ctx.Err()
+	}
+}
+
+// Close 取消全部进行中的任务
+func (s *Server) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, entry := range s.tasks {
+		if !entry.task.Status.State.Terminal() {
+			entry.cancel(errTaskCanceled)
+		}
+	}
+}
+
+// ServeHTTP 处理 HTTP 请求
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.Method == http.MethodGet && (r.URL.Path == AgentCardPath || r.URL.Path == LegacyAgentCardPath):
+		s.handleCard(w, r)
+	case r.Method == http.MethodPost:
+		s.handleRPC(w, r)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleCard 返回智能体名片，未设置 URL 时使用请求的地址
+func (s *Server) handleCard(w http.ResponseWriter, r *http.Request) {
+	card := s.card
+	if card.URL == "" {
+		scheme := "http"
+		if r.TLS != nil {
+			scheme = "https"
+		}
+		card.URL = scheme + "://" + r.Host + "/"
+	}
+	writeJSON(w, card)
+}
+
+// handleRPC 处理 JSON-RPC 请求
+func (s *Server) handleRPC(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBodySize+1))
+	if err != nil {
+		http.Error(w, "Failed to read request body", http.StatusBadRequest)
+		return
+	}
+	if len(body) > maxBodySize {
+		http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	var req JSONRPCRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		writeJSON(w, errorResponse(nil, -32700, "Parse error", err.Error()))
+		return
+	}
+	if req.JSONRPC != JSONRPCVersion || req.Method == "" {
+		writeJSON(w, errorResponse(req.ID, -32600, "Invalid Request", ""))
+		return
+	}
+
+	switch req.Method {
+	case MethodSendMessage:
+		writeJSON(w, s.handleSend(r.Context(), &req))
+	case MethodStreamMessage:
+		s.handleStream(w, r, &req)
+	case MethodGetTask:
+		writeJSON(w, s.handleGetTask(&req))
+	case MethodCancelTask:
+		writeJSON(w, s.handleCancelTask(&req))
+	default:
+		writeJSON(w, errorResponse(req.ID, -32601, "Method not found", req.Method))
+	}
+}
+
+// handleSend 处理 message/send，阻塞到任务结束
+func (s *Server) handleSend(ctx context.Context, req *JSONRPCRequest) *JSONRPCResponse {
+	params, rpcErr := s.parseSendParams(req)
+	if rpcErr != nil {
+		return &JSONRPCResponse{JSONRPC: JSONRPCVersion, ID: req.ID, Error: rpcErr}
+	}
+
+	ctx, taskID := s.createTask(ctx, params.Message)
+	s.execute(ctx, taskID, params.Message, nil)
+
+	task, _ := s.snapshot(taskID, historyLength(params))
+	return resultResponse(req.ID, task)
+}
+
+// handleStream 处理 message/stream，以 SSE 推送任务事件
+func (s *Server) handleStream(w http.ResponseWriter, r *http.Request, req *JSONRPCRequest) {
+	params, rpcErr := s.parseSendParams(req)
+	if rpcErr != nil {
+		writeJSON(w, &JSONRPCResponse{JSONRPC: JSONRPCVersion, ID: req.ID, Error: rpcErr})
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeJSON(w, errorResponse(req.ID, -32603, "Internal error", "streaming not supported"))
+		return
+	}
+
+	ctx, taskID := s.createTask(r.Context(), params.Message)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	var writeMu sync.Mutex
+	send := func(event interface{}) {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		data, err := json.Marshal(resultResponse(req.ID, event))
+		if err != nil {
+			return
+		}
+		if _, err := fmt.Fprintf(w, "data: %s\n\n", data); err == nil {
+			flusher.Flush()
+		}
+	}
+
+	task, _ := s.snapshot(taskID, nil)
+	send(task)
+	s.execute(ctx, taskID, params.Message, send)
+}
+
+// handleGetTask 处理 tasks/get
+func (s *Server) handleGetTask(req *JSONRPCRequest) *JSONRPCResponse {
+	var params TaskQueryParams
+	if err := json.Unmarshal(req.Params, &params); err != nil || params.ID == "" {
+		return errorResponse(req.ID, -32602, "Invalid params", "task id is required")
+	}
+	task, ok := s.snapshot(params.ID, params.HistoryLength)
+	if !ok {
+		return errorResponse(req.ID, CodeTaskNotFound, "Task not found", params.ID)
+	}
+	return resultResponse(req.ID, task)
+}
+
+// handleCancelTask 处理 tasks/cancel
+func (s *Server) handleCancelTask(req *JSONRPCRequest) *JSONRPCResponse {
+	var params TaskIDParams
+	if err := json.Unmarshal(req.Params, &params); err != nil || params.ID == "" {
+		return errorResponse(req.ID, -32602, "Invalid params", "task id is required")
+	}
+
+	s.mu.Lock()
+	entry, ok := s.tasks[params.ID]
+	if !ok {
+		s.mu.Unlock()
+		return errorResponse(req.ID, CodeTaskNotFound, "Task not found", params.ID)
+	}
+	if entry.task.Status.State.Terminal() {
+		state := entry.task.Status.State
+		s.mu.Unlock()
+		return errorResponse(req.ID, CodeTaskNotCancelable, "Task cannot be canceled", string(state))
+	}
+	entry.task.Status = newStatus(TaskStateCanceled, nil)
+	entry.cancel(errTaskCanceled)
+	s.mu.Unlock()
+
+	task, _ := s.snapshot(params.ID, nil)
+	return resultResponse(req.ID, task)
+}
+
+// parseSendParams 解析并校验 message/send 和 message/stream 的参数
+func (s *Server) parseSendParams(req *JSONRPCRequest) (*MessageSendParams, *JSONRPCError) {
+	var params MessageSendParams
+	if err := json.Unmarshal(req.Params, &params); err != nil {
+		return nil, &JSONRPCError{Code: -32602, Message: "Invalid params", Data: jsonString(err.Error())}
+	}
+	if len(params.Message.Parts) == 0 {
+		return nil, &JSONRPCError{Code: -32602, Message: "Invalid params", Data: jsonString("message has no parts")}
+	}
+	if params.Message.TaskID != "" {
+		s.mu.Lock()
+		_, ok := s.tasks[params.Message.TaskID]
+		s.mu.Unlock()
+		if !ok {
+			return nil, &JSONRPCError{Code: CodeTaskNotFound, Message: "Task not found", Data: jsonString(params.Message.TaskID)}
+		}
+		return nil, &JSONRPCError{Code: CodeUnsupportedOperation, Message: "This operation is not supported",
+			Data: jsonString("continuing an existing task is not supported")}
+	}
+	return &params, nil
+}
+
+// createTask 创建 submitted 状态的任务，返回可被 tasks/cancel 取消的任务上下文
+func (s *Server) createTask(ctx context.Context, msg Message) (context.Context, string) {
+	taskID := uuid.New().String()
+	contextID := msg.ContextID
+	if contextID == "" {
+		contextID = uuid.New().String()
+	}
+	msg.Kind = KindMessage
+	msg.TaskID = taskID
+	msg.ContextID = contextID
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tasks[taskID] = &taskEntry{
+		task: Task{
+			Kind:      KindTask,
+			ID:        taskID,
+			ContextID: contextID,
+			Status:    newStatus(TaskStateSubmitted, nil),
+			History:   []Message{msg},
+		},
+		cancel: cancel,
+	}
+	s.order = append(s.order, taskID)
+	s.evictLocked()
+	return ctx, taskID
+}
+
+// execute 执行任务直到结束，send 不为 nil 时推送流式事件
+func (s *Server) execute(ctx context.Context, taskID string, msg Message, send func(event interface{})) {
+	task, _ := s.snapshot(taskID, nil)
+	defer func() {
+		s.mu.Lock()
+		if entry, ok := s.tasks[taskID]; ok {
+			entry.cancel(nil)
+		}
+		s.mu.Unlock()
+	}()
+
+	if send == nil {
+		send = func(interface{}) {}
+	}
+	working := s.setStatus(taskID, newStatus(TaskStateWorking, nil))
+	send(statusEvent(task, working, false))
+
+	// 保留最近一个片段，结束时以 lastChunk 标记发出
+	artifactID := uuid.New().String()
+	var (
+		mu       sync.Mutex
+		streamed string
+		pending  *string
+		chunks   int
+	)
+	flush := func(last bool) {
+		if pending == nil {
+			return
+		}
+		send(&TaskArtifactUpdateEvent{
+			Kind:      KindArtifactUpdate,
+			TaskID:    task.ID,
+			ContextID: task.ContextID,
+			Artifact:  Artifact{ArtifactID: artifactID, Name: "response", Parts: []Part{TextPart(*pending)}},
+			Append:    chunks > 0,
+			LastChunk: last,
+		})
+		pending = nil
+		chunks++
+	}
+	emit := func(text string) {
+		if text == "" {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		flush(false)
+		streamed += text
+		pending = &text
+	}
+
+	text, err := s.handler(ctx, TaskRequest{TaskID: task.ID, ContextID: task.ContextID, Message: msg}, emit)
+
+	mu.Lock()
+	if text == "" {
+		text = streamed
+	} else if chunks == 0 && pending == nil {
+		pending = &text
+	}
+	flush(true)
+	mu.Unlock()
+
+	var status TaskStatus
+	switch {
+	case ctx.Err() != nil:
+		// 被 tasks/cancel 取消、服务器关闭或流式请求的连接断开
+		status = newStatus(TaskStateCanceled, nil)
+	case err != nil:
+		status = newStatus(TaskStateFailed, agentMessage(task, err.Error()))
+	default:
+		status = newStatus(TaskStateCompleted, agentMessage(task, text))
+	}
+	final := s.finish(taskID, status, Artifact{ArtifactID: artifactID, Name: "response", Parts: []Part{TextPart(text)}})
+	send(statusEvent(task, final, true))
+}
+
+// setStatus 更新未结束任务的状态，返回任务的当前状态
+func (s *Server) setStatus(taskID string, status TaskStatus) TaskStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.tasks[taskID]
+	if !ok {
+		return status
+	}
+	if !entry.task.Status.State.Terminal() {
+		entry.task.Status = status
+	}
+	return entry.task.Status
+}
+
+// finish 结束任务：已取消的任务保持取消状态，完成的任务保存产物，回复追加到历史
+func (s *Server) finish(taskID string, status TaskStatus, artifact Artifact) TaskStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.tasks[taskID]
+	if !ok {
+		return status
+	}
+	if entry.task.Status.State.Terminal() {
+		return entry.task.Status
+	}
+	entry.task.Status = status
+	if status.State == TaskStateCompleted && artifact.Parts[0].Text != "" {
+		entry.task.Artifacts = append(entry.task.Artifacts, artifact)
+	}
+	if status.Message != nil {
+		entry.task.History = append(entry.task.History, *status.Message)
+	}
+	s.evictLocked()
+	return status
+}
+
+// snapshot 返回任务副本，historyLength 不为 nil 时只保留最近的历史消息
+func (s *Server) snapshot(taskID string, historyLength *int) (*Task, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.tasks[taskID]
+	if !ok {
+		return nil, false
+	}
+	task := entry.task
+	task.Artifacts = append([]Artifact(nil), task.Artifacts...)
+	history := task.History
+	if historyLength != nil && *historyLength >= 0 && len(history) > *historyLength {
+		history = history[len(history)-*historyLength:]
+	}
+	task.History = append([]Message(nil), history...)
+	return &task, true
+}
+
+// evictLocked 任务数超出上限时按创建顺序丢弃已结束的任务，调用方需持有 s.mu
+func (s *Server) evictLocked() {
+	if len(s.tasks) <= s.maxTasks {
+		return
+	}
+	kept := s.order[:0]
+	for _, id := range s.order {
+		entry := s.tasks[id]
+		if len(s.tasks) > s.maxTasks && entry.task.Status.State.Terminal() {
+			delete(s.tasks, id)
+			continue
+		}
+		kept = append(kept, id)
+	}
+	s.order = kept
+}
+
+// historyLength 返回发送配置中的历史消息数
+func historyLength(params *MessageSendParams) *int {
+	if params.Configuration == nil {
+		return nil
+	}
+	return params.Configuration.HistoryLength
+}
+
+// newStatus 创建带当前时间的任务状态
+func newStatus(state TaskState, msg *Message) TaskStatus {
+	return TaskStatus{State: state, Message: msg, Timestamp: time.Now().UTC().Format(time.RFC3339)}
+}
+
+// agentMessage 创建属于任务的智能体消息
+func agentMessage(task *Task, text string) *Message {
+	msg := NewTextMessage(RoleAgent, text)
+	msg.TaskID = task.ID
+	msg.ContextID = task.ContextID
+	return &msg
+}
+
+// statusEvent 创建任务状态更新事件
+func statusEvent(task *Task, status TaskStatus, final bool) *TaskStatusUpdateEvent {
+	return &TaskStatusUpdateEvent{
+		Kind:      KindStatusUpdate,
+		TaskID:    task.ID,
+		ContextID: task.ContextID,
+		Status:    status,
+		Final:     final,
+	}
+}
+
+// resultResponse 创建成功响应
+func resultResponse(id interface{}, result interface{}) *JSONRPCResponse {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return errorResponse(id, -32603, "Internal error", err.Error())
+	}
+	return &JSONRPCResponse{JSONRPC: JSONRPCVersion, ID: id, Result: data}
+}
+
+// errorResponse 创建错误响应
+func errorResponse(id interface{}, code int, message, data string) *JSONRPCResponse {
+	rpcErr := &JSONRPCError{Code: code, Message: message}
+	if data != "" {
+		rpcErr.Data = jsonString(data)
+	}
+	return &JSONRPCResponse{JSONRPC: JSONRPCVersion, ID: id, Error: rpcErr}
+}
+
+// jsonString 将字符串编码为 JSON
+func jsonString(s string) json.RawMessage {
+	data, _ := json.Marshal(s)
+	return data
+}
+
+// writeJSON 写出 JSON 响应
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,189 @@
+package a2a
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/ahhsitt/helloagents-go/pkg/tools"
+)
+
+// ToolOption AgentTool 选项
+type ToolOption func(*AgentTool)
+
+// WithToolName 设置工具名称
+func WithToolName(name string) ToolOption {
+	return func(t *AgentTool) {
+		if name != "" {
+			t.name = name
+		}
+	}
+}
+
+// WithToolDescription 设置工具描述
+func WithToolDescription(description string) ToolOption {
+	return func(t *AgentTool) {
+		if description != "" {
+			t.description = description
+		}
+	}
+}
+
+// WithSharedContext 让同一工具的多次调用共享远程智能体的上下文（ContextID），
+// 远程智能体可以据此保留多轮对话。默认每次调用开始新的上下文。
+func WithSharedContext() ToolOption {
+	return func(t *AgentTool) {
+		t.sharedContext = true
+	}
+}
+
+// AgentTool 将远程 A2A 智能体包装为工具
+//
+// 本地 Agent 调用该工具时，query 参数作为消息发送给远程智能体，任务结果作为工具输出返回。
+//
+// 使用示例:
+//
+//	client := a2a.NewClient("http://translator.internal:8080/")
+//	tool, err := a2a.NewAgentToolFromCard(ctx, client)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	registry.Register(tool)
+type AgentTool struct {
+	client        *Client
+	name          string
+	description   string
+	sharedContext bool
+
+	mu        sync.Mutex
+	contextID string
+}
+
+// NewAgentTool 创建委派任务给远程智能体的工具
+func NewAgentTool(client *Client, opts ...ToolOption) *AgentTool {
+	t := &AgentTool{
+		client:      client,
+		name:        "remote_agent",
+		description: "Delegate a task to a remote agent and return its response",
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// NewAgentToolFromCard 获取远程智能体的名片，以名片的名称、描述和技能创建工具
+//
+// opts 中的名称和描述优先于名片。
+func NewAgentToolFromCard(ctx context.Context, client *Client, opts ...ToolOption) (*AgentTool, error) {
+	card, err := client.Card(ctx)
+	if err != nil {
+		return nil, err
+	}
+	base := []ToolOption{
+		WithToolName(toolName(card.Name)),
+		WithToolDescription(cardDescription(card)),
+	}
+	return NewAgentTool(client, append(base, opts...)...), nil
+}
+
+// Name 返回工具名称
+func (t *AgentTool) Name() string {
+	return t.name
+}
+
+// Description 返回工具描述
+func (t *AgentTool) Description() string {
+	return t.description
+}
+
+// Parameters 返回参数 Schema
+func (t *AgentTool) Parameters() tools.ParameterSchema {
+	return tools.ParameterSchema{
+		Type: "object",
+		Properties: map[string]tools.PropertySchema{
+			"query": {
+				Type:        "string",
+				Description: "The task or question to send to the remote agent",
+			},
+		},
+		Required: []string{"query"},
+	}
+}
+
+// Execute 将 query 发送给远程智能体并返回结果
+//
+// 任务失败、被拒绝或被取消时返回错误。
+func (t *AgentTool) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
+	query, _ := args["query"].(string)
+	if query == "" {
+		return "", fmt.Errorf("query is required")
+	}
+
+	msg := NewTextMessage(RoleUser, query)
+	if t.sharedContext {
+		t.mu.Lock()
+		msg.ContextID = t.contextID
+		t.mu.Unlock()
+	}
+
+	result, err := t.client.SendMessage(ctx, msg)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", t.name, err)
+	}
+
+	if task := result.Task; task != nil {
+		if t.sharedContext {
+			t.mu.Lock()
+			t.contextID = task.ContextID
+			t.mu.Unlock()
+		}
+		switch task.Status.State {
+		case TaskStateFailed, TaskStateRejected, TaskStateCanceled:
+			reason := string(task.Status.State)
+			if task.Status.Message != nil && task.Status.Message.Text() != "" {
+				reason += ": " + task.Status.Message.Text()
+			}
+			return "", fmt.Errorf("%s: remote task %s", t.name, reason)
+		}
+	}
+	return result.Text(), nil
+}
+
+// toolName 将智能体名称转换为工具名称，只保留字母、数字、下划线和连字符
+func toolName(name string) string {
+	var b strings.Builder
+	for _, r := range strings.TrimSpace(name) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '-':
+			b.WriteRune(r)
+		default:
+			b.WriteByte('_')
+		}
+	}
+	return b.String()
+}
+
+// cardDescription 由名片的描述和技能生成工具描述
+func cardDescription(card *AgentCard) string {
+	description := card.Description
+	if description == "" {
+		description = "Delegate a task to the remote agent " + card.Name
+	}
+	var skills []string
+	for _, skill := range card.Skills {
+		if skill.Description != "" {
+			skills = append(skills, skill.Name+": "+skill.Description)
+		} else {
+			skills = append(skills, skill.Name)
+		}
+	}
+	if len(skills) > 0 {
+		description += "\nSkills:\n- " + strings.Join(skills, "\n- ")
+	}
+	return description
+}
+
+// compile-time interface check
+var _ tools.Tool = (*AgentTool)(nil)
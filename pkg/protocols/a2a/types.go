@@ -0,0 +1,316 @@
+// Package a2a 实现 A2A (Agent-to-Agent) 协议
+//
+// A2A 是智能体之间相互发现、委派任务的开放协议，基于 HTTP 上的 JSON-RPC 2.0。
+// 本包提供：
+//   - AgentCard: 描述智能体能力的名片，发布在 /.well-known/agent-card.json
+//   - Server: 以 A2A 协议对外提供任务执行（message/send、message/stream、tasks/get、tasks/cancel），
+//     NewAgentServer 将任意 agents.Agent 发布为 A2A 服务
+//   - Client: 调用远程 A2A 智能体，支持 SSE 流式输出
+//   - AgentTool: 将远程智能体包装为工具，供本地 Agent 委派任务
+package a2a
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// A2A 协议版本
+const (
+	ProtocolVersion = "0.3.0"
+	JSONRPCVersion  = "2.0"
+)
+
+// 智能体名片路径
+const (
+	// AgentCardPath 智能体名片的 well-known 路径
+	AgentCardPath = "/.well-known/agent-card.json"
+	// LegacyAgentCardPath 0.2 版本协议使用的名片路径
+	LegacyAgentCardPath = "/.well-known/agent.json"
+)
+
+// A2A 方法名称
+const (
+	MethodSendMessage   = "message/send"
+	MethodStreamMessage = "message/stream"
+	MethodGetTask       = "tasks/get"
+	MethodCancelTask    = "tasks/cancel"
+)
+
+// A2A 错误码（JSON-RPC 标准错误码之外）
+const (
+	CodeTaskNotFound         = -32001
+	CodeTaskNotCancelable    = -32002
+	CodeUnsupportedOperation = -32004
+)
+
+// JSONRPCRequest JSON-RPC 2.0 请求结构
+type JSONRPCRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      interface{}     `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// JSONRPCResponse JSON-RPC 2.0 响应结构
+type JSONRPCResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      interface{}     `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *JSONRPCError   `json:"error,omitempty"`
+}
+
+// JSONRPCError JSON-RPC 2.0 错误结构
+type JSONRPCError struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+// Error 实现 error 接口
+func (e *JSONRPCError) Error() string {
+	return fmt.Sprintf("A2A error %d: %s", e.Code, e.Message)
+}
+
+// AgentCard 智能体名片，描述智能体的身份、地址和能力
+type AgentCard struct {
+	ProtocolVersion    string            `json:"protocolVersion"`
+	Name               string            `json:"name"`
+	Description        string            `json:"description"`
+	URL                string            `json:"url"`
+	PreferredTransport string            `json:"preferredTransport,omitempty"`
+	Version            string            `json:"version"`
+	Provider           *AgentProvider    `json:"provider,omitempty"`
+	Capabilities       AgentCapabilities `json:"capabilities"`
+	DefaultInputModes  []string          `json:"defaultInputModes"`
+	DefaultOutputModes []string          `json:"defaultOutputModes"`
+	Skills             []AgentSkill      `json:"skills"`
+}
+
+// AgentProvider 智能体的提供方
+type AgentProvider struct {
+	Organization string `json:"organization"`
+	URL          string `json:"url,omitempty"`
+}
+
+// AgentCapabilities 智能体支持的可选协议能力
+type AgentCapabilities struct {
+	Streaming              bool `json:"streaming,omitempty"`
+	PushNotifications      bool `json:"pushNotifications,omitempty"`
+	StateTransitionHistory bool `json:"stateTransitionHistory,omitempty"`
+}
+
+// AgentSkill 智能体的一项技能
+type AgentSkill struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Tags        []string `json:"tags"`
+	Examples    []string `json:"examples,omitempty"`
+	InputModes  []string `json:"inputModes,omitempty"`
+	OutputModes []string `json:"outputModes,omitempty"`
+}
+
+// Role 消息发送方
+type Role string
+
+const (
+	// RoleUser 发起任务的一方（客户端）
+	RoleUser Role = "user"
+	// RoleAgent 执行任务的智能体
+	RoleAgent Role = "agent"
+)
+
+// Part 类型
+const (
+	PartKindText = "text"
+	PartKindData = "data"
+	PartKindFile = "file"
+)
+
+// Part 消息或产物中的内容块
+type Part struct {
+	// Kind 内容类型：text、data 或 file
+	Kind     string                 `json:"kind"`
+	Text     string                 `json:"text,omitempty"`
+	Data     map[string]interface{} `json:"data,omitempty"`
+	File     *FileContent           `json:"file,omitempty"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// FileContent 文件内容，Bytes（base64）和 URI 二选一
+type FileContent struct {
+	Name     string `json:"name,omitempty"`
+	MimeType string `json:"mimeType,omitempty"`
+	Bytes    string `json:"bytes,omitempty"`
+	URI      string `json:"uri,omitempty"`
+}
+
+// TextPart 创建文本内容块
+func TextPart(text string) Part {
+	return Part{Kind: PartKindText, Text: text}
+}
+
+// Message 用户与智能体之间的一条消息
+type Message struct {
+	Kind      string                 `json:"kind"`
+	MessageID string                 `json:"messageId"`
+	Role      Role                   `json:"role"`
+	Parts     []Part                 `json:"parts"`
+	TaskID    string                 `json:"taskId,omitempty"`
+	ContextID string                 `json:"contextId,omitempty"`
+	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// NewTextMessage 创建只包含一个文本块的消息
+func NewTextMessage(role Role, text string) Message {
+	return Message{
+		Kind:      "message",
+		MessageID: uuid.New().String(),
+		Role:      role,
+		Parts:     []Part{TextPart(text)},
+	}
+}
+
+// Text 返回消息中全部文本块的内容，以换行连接
+func (m Message) Text() string {
+	return partsText(m.Parts)
+}
+
+// TaskState 任务状态
+type TaskState string
+
+const (
+	TaskStateSubmitted     TaskState = "submitted"
+	TaskStateWorking       TaskState = "working"
+	TaskStateInputRequired TaskState = "input-required"
+	TaskStateCompleted     TaskState = "completed"
+	TaskStateCanceled      TaskState = "canceled"
+	TaskStateFailed        TaskState = "failed"
+	TaskStateRejected      TaskState = "rejected"
+)
+
+// Terminal 任务是否已结束（不会再变化）
+func (s TaskState) Terminal() bool {
+	switch s {
+	case TaskStateCompleted, TaskStateCanceled, TaskStateFailed, TaskStateRejected:
+		return true
+	default:
+		return false
+	}
+}
+
+// TaskStatus 任务当前状态
+type TaskStatus struct {
+	State TaskState `json:"state"`
+	// Message 智能体对当前状态的说明（如失败原因、最终回复）
+	Message *Message `json:"message,omitempty"`
+	// Timestamp RFC 3339 时间
+	Timestamp string `json:"timestamp,omitempty"`
+}
+
+// Artifact 任务产出的结果
+type Artifact struct {
+	ArtifactID  string                 `json:"artifactId"`
+	Name        string                 `json:"name,omitempty"`
+	Description string                 `json:"description,omitempty"`
+	Parts       []Part                 `json:"parts"`
+	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// Task 一次任务
+type Task struct {
+	Kind      string                 `json:"kind"`
+	ID        string                 `json:"id"`
+	ContextID string                 `json:"contextId"`
+	Status    TaskStatus             `json:"status"`
+	Artifacts []Artifact             `json:"artifacts,omitempty"`
+	History   []Message              `json:"history,omitempty"`
+	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// Text 返回任务结果的文本：产物中的文本，没有产物时为状态消息的文本
+func (t *Task) Text() string {
+	var texts []string
+	for _, artifact := range t.Artifacts {
+		if text := partsText(artifact.Parts); text != "" {
+			texts = append(texts, text)
+		}
+	}
+	if len(texts) > 0 {
+		return strings.Join(texts, "\n")
+	}
+	if t.Status.Message != nil {
+		return t.Status.Message.Text()
+	}
+	return ""
+}
+
+// TaskStatusUpdateEvent 流式响应中的任务状态变化事件
+type TaskStatusUpdateEvent struct {
+	Kind      string     `json:"kind"`
+	TaskID    string     `json:"taskId"`
+	ContextID string     `json:"contextId"`
+	Status    TaskStatus `json:"status"`
+	// Final 是否为该流的最后一个事件
+	Final bool `json:"final"`
+}
+
+// TaskArtifactUpdateEvent 流式响应中的产物更新事件
+type TaskArtifactUpdateEvent struct {
+	Kind      string   `json:"kind"`
+	TaskID    string   `json:"taskId"`
+	ContextID string   `json:"contextId"`
+	Artifact  Artifact `json:"artifact"`
+	// Append 为 true 时将内容追加到同 ID 的产物，否则替换
+	Append bool `json:"append,omitempty"`
+	// LastChunk 是否为该产物的最后一块
+	LastChunk bool `json:"lastChunk,omitempty"`
+}
+
+// 事件类型（Kind 字段）
+const (
+	KindTask           = "task"
+	KindMessage        = "message"
+	KindStatusUpdate   = "status-update"
+	KindArtifactUpdate = "artifact-update"
+)
+
+// MessageSendParams message/send 和 message/stream 的请求参数
+type MessageSendParams struct {
+	Message       Message                   `json:"message"`
+	Configuration *MessageSendConfiguration `json:"configuration,omitempty"`
+	Metadata      map[string]interface{}    `json:"metadata,omitempty"`
+}
+
+// MessageSendConfiguration 发送消息的配置
+type MessageSendConfiguration struct {
+	AcceptedOutputModes []string `json:"acceptedOutputModes,omitempty"`
+	// HistoryLength 返回的任务中最多包含的历史消息数
+	HistoryLength *int `json:"historyLength,omitempty"`
+	Blocking      bool `json:"blocking,omitempty"`
+}
+
+// TaskQueryParams tasks/get 的请求参数
+type TaskQueryParams struct {
+	ID            string `json:"id"`
+	HistoryLength *int   `json:"historyLength,omitempty"`
+}
+
+// TaskIDParams tasks/cancel 的请求参数
+type TaskIDParams struct {
+	ID string `json:"id"`
+}
+
+// partsText 连接内容块中的文本
+func partsText(parts []Part) string {
+	var texts []string
+	for _, part := range parts {
+		if part.Kind == PartKindText && part.Text != "" {
+			texts = append(texts, part.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
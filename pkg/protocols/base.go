@@ -2,6 +2,7 @@
 //
 // 本模块支持多种协议：
 //   - MCP (Model Context Protocol): 模型上下文协议，用于工具/资源/提示词管理
+//   - A2A (Agent-to-Agent Protocol): 智能体间协议，用于发现远程智能体并委派任务
 package protocols
 
 // ProtocolType 协议类型枚举
@@ -10,7 +11,7 @@ type ProtocolType string
 const (
 	// ProtocolMCP Model Context Protocol
 	ProtocolMCP ProtocolType = "mcp"
-	// ProtocolA2A Agent-to-Agent Protocol (保留，未实现)
+	// ProtocolA2A Agent-to-Agent Protocol
 	ProtocolA2A ProtocolType = "a2a"
 	// ProtocolANP Agent Network Protocol (保留，未实现)
 	ProtocolANP ProtocolType = "anp"
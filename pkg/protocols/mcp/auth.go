@@ -0,0 +1,546 @@
+package mcp
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 授权相关错误
+var (
+	// ErrUnauthorized 服务器拒绝了请求的凭证（HTTP 401）
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrAuthorizationRequired 没有可用的访问令牌，需要先完成授权码流程
+	ErrAuthorizationRequired = errors.New("authorization required")
+)
+
+// tokenExpirySkew 令牌提前刷新的时间，避免请求途中过期
+const tokenExpirySkew = 30 * time.Second
+
+// OAuthToken OAuth 2.0 访问令牌
+type OAuthToken struct {
+	AccessToken  string    `json:"access_token"`
+	TokenType    string    `json:"token_type,omitempty"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	Scope        string    `json:"scope,omitempty"`
+	ExpiresAt    time.Time `json:"expires_at,omitempty"`
+}
+
+// Valid 令牌是否存在且未（即将）过期
+func (t *OAuthToken) Valid() bool {
+	if t == nil || t.AccessToken == "" {
+		return false
+	}
+	return t.ExpiresAt.IsZero() || time.Now().Add(tokenExpirySkew).Before(t.ExpiresAt)
+}
+
+// TokenSource 访问令牌来源，HTTP 传输在每次请求前调用
+type TokenSource interface {
+	Token(ctx context.Context) (*OAuthToken, error)
+}
+
+// TokenInvalidator 可作废当前令牌的令牌来源
+//
+// 服务器返回 401 时 HTTP 传输调用 Invalidate，然后重新获取令牌并重试一次。
+type TokenInvalidator interface {
+	Invalidate()
+}
+
+// StaticTokenSource 返回固定访问令牌（如 API Key、个人访问令牌）的令牌来源
+func StaticTokenSource(token string) TokenSource {
+	return staticTokenSource{token: &OAuthToken{AccessToken: token, TokenType: "Bearer"}}
+}
+
+// staticTokenSource 固定令牌
+type staticTokenSource struct {
+	token *OAuthToken
+}
+
+// Token 返回固定令牌
+func (s staticTokenSource) Token(context.Context) (*OAuthToken, error) {
+	return s.token, nil
+}
+
+// AuthorizationServerMetadata 授权服务器元数据（RFC 8414）
+type AuthorizationServerMetadata struct {
+	Issuer                        string   `json:"issuer,omitempty"`
+	AuthorizationEndpoint         string   `json:"authorization_endpoint,omitempty"`
+	TokenEndpoint                 string   `json:"token_endpoint"`
+	RegistrationEndpoint          string   `json:"registration_endpoint,omitempty"`
+	ScopesSupported               []string `json:"scopes_supported,omitempty"`
+	GrantTypesSupported           []string `json:"grant_types_supported,omitempty"`
+	CodeChallengeMethodsSupported []string `json:"code_challenge_methods_supported,omitempty"`
+}
+
+// ProtectedResourceMetadata 受保护资源元数据（RFC 9728）
+type ProtectedResourceMetadata struct {
+	Resource             string   `json:"resource"`
+	AuthorizationServers []string `json:"authorization_servers,omitempty"`
+	ScopesSupported      []string `json:"scopes_supported,omitempty"`
+}
+
+// OAuthConfig OAuth 客户端配置
+type OAuthConfig struct {
+	// ServerURL MCP 服务器地址，用于发现授权服务器
+	ServerURL string
+	// AuthorizationServer 授权服务器地址（可选），未设置时通过 ServerURL 发现
+	AuthorizationServer string
+	// ClientID 客户端 ID，为空时通过动态客户端注册（RFC 7591）获取
+	ClientID string
+	// ClientSecret 客户端密钥（公开客户端为空）
+	ClientSecret string
+	// ClientName 动态注册时使用的客户端名称（默认 "helloagents-go"）
+	ClientName string
+	// RedirectURI 授权码流程的回调地址
+	RedirectURI string
+	// Scopes 申请的权限范围
+	Scopes []string
+	// Token 初始令牌（如之前保存的令牌）
+	Token *OAuthToken
+	// OnToken 获取或刷新令牌后的回调，可用于持久化令牌
+	OnToken func(token *OAuthToken)
+	// HTTPClient 访问授权服务器使用的 HTTP 客户端（可选）
+	HTTPClient *http.Client
+}
+
+// OAuthClient 实现 MCP 授权规范的 OAuth 2.1 客户端
+//
+// 支持授权服务器发现（受保护资源元数据、RFC 8414，发现失败时回退到默认端点）、
+// 动态客户端注册、带 PKCE 的授权码流程、客户端凭证流程和令牌刷新。
+// 作为 TokenSource 传给 HTTPTransportConfig 后，每次请求自动注入 Authorization 头，
+// 令牌过期时自动刷新；没有刷新令牌时，配置了 ClientSecret 的客户端使用客户端凭证流程获取新令牌。
+//
+// 使用示例:
+//
+//	oauth := mcp.NewOAuthClient(mcp.OAuthConfig{
+//	    ServerURL:   "https://mcp.example.com/mcp",
+//	    RedirectURI: "http://localhost:8765/callback",
+//	})
+//	authURL, verifier, err := oauth.AuthorizationURL(ctx, state)
+//	// 用户在浏览器中完成授权，回调得到 code
+//	if _, err := oauth.Exchange(ctx, code, verifier); err != nil {
+//	    log.Fatal(err)
+//	}
+//	transport := mcp.NewHTTPTransport(mcp.HTTPTransportConfig{
+//	    URL:         "https://mcp.example.com/mcp",
+//	    TokenSource: oauth,
+//	})
+type OAuthClient struct {
+	config OAuthConfig
+	client *http.Client
+
+	mu           sync.Mutex
+	metadata     *AuthorizationServerMetadata
+	clientID     string
+	clientSecret string
+	token        *OAuthToken
+}
+
+// NewOAuthClient 创建 OAuth 客户端
+func NewOAuthClient(config OAuthConfig) *OAuthClient {
+	client := config.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	if config.ClientName == "" {
+		config.ClientName = "helloagents-go"
+	}
+	return &OAuthClient{
+		config:       config,
+		client:       client,
+		clientID:     config.ClientID,
+		clientSecret: config.ClientSecret,
+		token:        config.Token,
+	}
+}
+
+// Token 返回有效的访问令牌，过期时刷新
+func (c *OAuthClient) Token(ctx context.Context) (*OAuthToken, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.token.Valid() {
+		return c.token, nil
+	}
+	if c.token != nil && c.token.RefreshToken != "" {
+		token, err := c.requestTokenLocked(ctx, url.Values{
+			"grant_type":    {"refresh_token"},
+			"refresh_token": {c.token.RefreshToken},
+		})
+		if err == nil {
+			// 授权服务器可以不轮换刷新令牌
+			if token.RefreshToken == "" {
+				token.RefreshToken = c.token.RefreshToken
+			}
+			c.setTokenLocked(token)
+			return token, nil
+		}
+		if c.clientSecret == "" {
+			return nil, fmt.Errorf("failed to refresh token: %w", err)
+		}
+	}
+	if c.clientSecret != "" {
+		values := url.Values{"grant_type": {"client_credentials"}}
+		if len(c.config.Scopes) > 0 {
+			values.Set("scope", strings.Join(c.config.Scopes, " "))
+		}
+		token, err := c.requestTokenLocked(ctx, values)
+		if err != nil {
+			return nil, fmt.Errorf("client credentials grant failed: %w", err)
+		}
+		c.setTokenLocked(token)
+		return token, nil
+	}
+	return nil, ErrAuthorizationRequired
+}
+
+// Invalidate 作废当前访问令牌，下次 Token 时刷新
+func (c *OAuthClient) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.token != nil {
+		token := *c.token
+		token.AccessToken = ""
+		c.token = &token
+	}
+}
+
+// AuthorizationURL 生成授权码流程（PKCE S256）的授权地址
+//
+// 返回授权地址和 code verifier，verifier 需保存到回调时传给 Exchange。
+// 没有 ClientID 时先进行动态客户端注册。
+func (c *OAuthClient) AuthorizationURL(ctx context.Context, state string) (string, string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	metadata, err := c.discoverLocked(ctx)
+	if err != nil {
+		return "", "", err
+	}
+	if err := c.registerLocked(ctx); err != nil {
+		return "", "", err
+	}
+	if metadata.AuthorizationEndpoint == "" {
+		return "", "", fmt.Errorf("authorization server has no authorization endpoint")
+	}
+
+	verifier, err := randomString(32)
+	if err != nil {
+		return "", "", err
+	}
+	challenge := sha256.Sum256([]byte(verifier))
+
+	values := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {c.clientID},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}
+	if c.config.RedirectURI != "" {
+		values.Set("redirect_uri", c.config.RedirectURI)
+	}
+	if state != "" {
+		values.Set("state", state)
+	}
+	if len(c.config.Scopes) > 0 {
+		values.Set("scope", strings.Join(c.config.Scopes, " "))
+	}
+	if c.config.ServerURL != "" {
+		values.Set("resource", c.config.ServerURL)
+	}
+
+	sep := "?"
+	if strings.Contains(metadata.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return metadata.AuthorizationEndpoint + sep + values.Encode(), verifier, nil
+}
+
+// Exchange 用授权码和 code verifier 换取令牌
+func (c *OAuthClient) Exchange(ctx context.Context, code, verifier string) (*OAuthToken, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	values := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"code_verifier": {verifier},
+	}
+	if c.config.RedirectURI != "" {
+		values.Set("redirect_uri", c.config.RedirectURI)
+	}
+	token, err := c.requestTokenLocked(ctx, values)
+	if err != nil {
+		return nil, fmt.Errorf("authorization code exchange failed: %w", err)
+	}
+	c.setTokenLocked(token)
+	return token, nil
+}
+
+// Metadata 返回（必要时发现）授权服务器元数据
+func (c *OAuthClient) Metadata(ctx context.Context) (*AuthorizationServerMetadata, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.discoverLocked(ctx)
+}
+
+// ClientID 返回当前使用的客户端 ID（动态注册后为注册得到的 ID）
+func (c *OAuthClient) ClientID() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.clientID
+}
+
+// discoverLocked 发现授权服务器元数据（调用方需持有锁）
+//
+// 依次尝试：MCP 服务器的受保护资源元数据给出的授权服务器、授权服务器的
+// /.well-known/oauth-authorization-server；都不可用时使用 /authorize、/token、/register 默认端点。
+func (c *OAuthClient) discoverLocked(ctx context.Context) (*AuthorizationServerMetadata, error) {
+	if c.metadata != nil {
+		return c.metadata, nil
+	}
+
+	issuer := c.config.AuthorizationServer
+	if issuer == "" {
+		if c.config.ServerURL == "" {
+			return nil, fmt.Errorf("either ServerURL or AuthorizationServer is required")
+		}
+		var resource ProtectedResourceMetadata
+		found, err := c.getJSON(ctx, wellKnownURL(c.config.ServerURL, "oauth-protected-resource"), &resource)
+		if err != nil {
+			return nil, err
+		}
+		if found && len(resource.AuthorizationServers) > 0 {
+			issuer = resource.AuthorizationServers[0]
+		} else {
+			issuer = originOf(c.config.ServerURL)
+		}
+	}
+
+	var metadata AuthorizationServerMetadata
+	found, err := c.getJSON(ctx, wellKnownURL(issuer, "oauth-authorization-server"), &metadata)
+	if err != nil {
+		return nil, err
+	}
+	if !found || metadata.TokenEndpoint == "" {
+		base := strings.TrimSuffix(issuer, "/")
+		metadata = AuthorizationServerMetadata{
+			Issuer:                issuer,
+			AuthorizationEndpoint: base + "/authorize",
+			TokenEndpoint:         base + "/token",
+			RegistrationEndpoint:  base + "/register",
+		}
+	}
+	c.metadata = &metadata
+	return c.metadata, nil
+}
+
+// registerLocked 没有客户端 ID 时进行动态客户端注册（调用方需持有锁）
+func (c *OAuthClient) registerLocked(ctx context.Context) error {
+	if c.clientID != "" {
+		return nil
+	}
+	metadata, err := c.discoverLocked(ctx)
+	if err != nil {
+		return err
+	}
+	if metadata.RegistrationEndpoint == "" {
+		return fmt.Errorf("no client ID configured and the authorization server does not support dynamic registration")
+	}
+
+	request := map[string]interface{}{
+		"client_name":                c.config.ClientName,
+		"grant_types":                []string{"authorization_code", "refresh_token"},
+		"response_types":             []string{"code"},
+		"token_endpoint_auth_method": "none",
+	}
+	if c.config.RedirectURI != "" {
+		request["redirect_uris"] = []string{c.config.RedirectURI}
+	}
+	if len(c.config.Scopes) > 0 {
+		request["scope"] = strings.Join(c.config.Scopes, " ")
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, metadata.RegistrationEndpoint, strings.NewReader(string(body)))
+	if err != nil {
+		return fmt.Errorf("failed to create registration request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	var registration struct {
+		ClientID     string `json:"client_id"`
+		ClientSecret string `json:"client_secret"`
+	}
+	if err := c.doJSON(req, &registration); err != nil {
+		return fmt.Errorf("dynamic client registration failed: %w", err)
+	}
+	if registration.ClientID == "" {
+		return fmt.Errorf("dynamic client registration returned no client_id")
+	}
+	c.clientID = registration.ClientID
+	c.clientSecret = registration.ClientSecret
+	return nil
+}
+
+// requestTokenLocked 向令牌端点请求令牌（调用方需持有锁）
+func (c *OAuthClient) requestTokenLocked(ctx context.Context, values url.Values) (*OAuthToken, error) {
+	metadata, err := c.discoverLocked(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if values.Get("grant_type") != "client_credentials" {
+		if err := c.registerLocked(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if c.config.ServerURL != "" {
+		values.Set("resource", c.config.ServerURL)
+	}
+	if c.clientSecret == "" {
+		values.Set("client_id", c.clientID)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, metadata.TokenEndpoint, strings.NewReader(values.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if c.clientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(c.clientID), url.QueryEscape(c.clientSecret))
+	}
+
+	var response struct {
+		AccessToken  string `json:"access_token"`
+		TokenType    string `json:"token_type"`
+		RefreshToken string `json:"refresh_token"`
+		Scope        string `json:"scope"`
+		ExpiresIn    int64  `json:"expires_in"`
+	}
+	if err := c.doJSON(req, &response); err != nil {
+		return nil, err
+	}
+	if response.AccessToken == "" {
+		return nil, fmt.Errorf("token response has no access_token")
+	}
+
+	token := &OAuthToken{
+		AccessToken:  response.AccessToken,
+		TokenType:    response.TokenType,
+		RefreshToken: response.RefreshToken,
+		Scope:        response.Scope,
+	}
+	if response.ExpiresIn > 0 {
+		token.ExpiresAt = time.Now().Add(time.Duration(response.ExpiresIn) * time.Second)
+	}
+	return token, nil
+}
+
+// setTokenLocked 保存令牌并调用 OnToken（调用方需持有锁）
+func (c *OAuthClient) setTokenLocked(token *OAuthToken) {
+	c.token = token
+	if c.config.OnToken != nil {
+		c.config.OnToken(token)
+	}
+}
+
+// getJSON 获取 JSON 文档，404 时返回 found=false
+func (c *OAuthClient) getJSON(ctx context.Context, target string, v interface{}) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to create discovery request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("discovery request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return false, nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return false, fmt.Errorf("failed to parse %s: %w", target, err)
+	}
+	return true, nil
+}
+
+// doJSON 发送请求并解析 JSON 响应，非 2xx 响应返回 OAuth 错误信息
+func (c *OAuthClient) doJSON(req *http.Request, v interface{}) error {
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var oauthErr struct {
+			Error       string `json:"error"`
+			Description string `json:"error_description"`
+		}
+		if json.Unmarshal(body, &oauthErr) == nil && oauthErr.Error != "" {
+			return fmt.Errorf("status %d: %s: %s", resp.StatusCode, oauthErr.Error, oauthErr.Description)
+		}
+		return fmt.Errorf("status %d: %s", resp.StatusCode, string(body))
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
+
+// wellKnownURL 构造 RFC 8414 / RFC 9728 的 well-known 地址（路径插入在 /.well-known/<name> 之后）
+func wellKnownURL(base, name string) string {
+	u, err := url.Parse(base)
+	if err != nil || u.Host == "" {
+		return strings.TrimSuffix(base, "/") + "/.well-known/" + name
+	}
+	path := strings.TrimSuffix(u.Path, "/")
+	u.Path = "/.well-known/" + name + path
+	u.RawQuery = ""
+	u.Fragment = ""
+	return u.String()
+}
+
+// originOf 返回 URL 的 scheme://host 部分
+func originOf(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return raw
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+// randomString 生成 URL 安全的随机字符串
+func randomString(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate random string: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// compile-time interface check
+var (
+	_ TokenSource      = (*OAuthClient)(nil)
+	_ TokenInvalidator = (*OAuthClient)(nil)
+)
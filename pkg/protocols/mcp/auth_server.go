@@ -0,0 +1,172 @@
+package mcp
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// errInvalidToken 令牌不存在或不匹配
+var errInvalidToken = errors.New("invalid token")
+
+// TokenInfo 通过校验的访问令牌信息
+type TokenInfo struct {
+	// Subject 令牌代表的用户
+	Subject string
+	// ClientID 令牌签发给的客户端
+	ClientID string
+	// Scopes 令牌的权限范围
+	Scopes []string
+	// ExpiresAt 过期时间，零值表示不过期
+	ExpiresAt time.Time
+	// Extra 其他声明
+	Extra map[string]interface{}
+}
+
+// HasScope 令牌是否包含指定权限范围
+func (t *TokenInfo) HasScope(scope string) bool {
+	for _, s := range t.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// TokenValidator 校验访问令牌，令牌无效时返回错误
+//
+// 可以对接令牌自省端点（RFC 7662）、校验 JWT 签名，或使用 StaticTokenValidator。
+type TokenValidator func(ctx context.Context, token string) (*TokenInfo, error)
+
+// StaticTokenValidator 只接受给定令牌的校验器，适用于 API Key 式的固定令牌
+func StaticTokenValidator(tokens ...string) TokenValidator {
+	return func(_ context.Context, token string) (*TokenInfo, error) {
+		for _, t := range tokens {
+			if subtle.ConstantTimeCompare([]byte(t), []byte(token)) == 1 {
+				return &TokenInfo{}, nil
+			}
+		}
+		return nil, errInvalidToken
+	}
+}
+
+// tokenInfoKey 上下文中令牌信息的键
+type tokenInfoKey struct{}
+
+// TokenInfoFromContext 返回 BearerAuth 校验通过的令牌信息
+//
+// 工具、资源、提示词的处理函数可以用它获取调用者身份。
+func TokenInfoFromContext(ctx context.Context) (*TokenInfo, bool) {
+	info, ok := ctx.Value(tokenInfoKey{}).(*TokenInfo)
+	return info, ok
+}
+
+// BearerAuthOption BearerAuth 配置选项
+type BearerAuthOption func(*bearerAuth)
+
+// WithRequiredScopes 要求令牌包含全部指定的权限范围，缺少时返回 403
+func WithRequiredScopes(scopes ...string) BearerAuthOption {
+	return func(a *bearerAuth) {
+		a.scopes = append(a.scopes, scopes...)
+	}
+}
+
+// WithProtectedResourceMetadata 发布受保护资源元数据（RFC 9728）
+//
+// 在 /.well-known/oauth-protected-resource 上无需认证返回 metadata，
+// 并在 401 响应的 WWW-Authenticate 头中通过 resource_metadata 指向它，
+// 客户端据此发现授权服务器。
+func WithProtectedResourceMetadata(metadata ProtectedResourceMetadata) BearerAuthOption {
+	return func(a *bearerAuth) {
+		a.metadata = &metadata
+	}
+}
+
+// bearerAuth Bearer 令牌认证配置
+type bearerAuth struct {
+	validator TokenValidator
+	scopes    []string
+	metadata  *ProtectedResourceMetadata
+}
+
+// wellKnownProtectedResource 受保护资源元数据路径前缀
+const wellKnownProtectedResource = "/.well-known/oauth-protected-resource"
+
+// BearerAuth 返回校验 Authorization: Bearer 令牌的 HTTP 中间件
+//
+// 缺少或无效的令牌返回 401（error="invalid_token"），权限范围不足返回 403
+// （error="insufficient_scope"），两者都带有 WWW-Authenticate 头。
+// 校验通过的令牌信息放入请求上下文，可通过 TokenInfoFromContext 获取。
+//
+// 使用示例:
+//
+//	handler := mcp.BearerAuth(mcp.StaticTokenValidator(os.Getenv("MCP_TOKEN")))(mcp.NewHTTPHandler(server))
+//	http.ListenAndServe(":8080", handler)
+func BearerAuth(validator TokenValidator, opts ...BearerAuthOption) func(http.Handler) http.Handler {
+	a := &bearerAuth{validator: validator}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			a.serve(w, r, next)
+		})
+	}
+}
+
+// serve 校验令牌后调用 next
+func (a *bearerAuth) serve(w http.ResponseWriter, r *http.Request, next http.Handler) {
+	if a.metadata != nil && r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, wellKnownProtectedResource) {
+		writeJSON(w, http.StatusOK, a.metadata)
+		return
+	}
+
+	header := r.Header.Get("Authorization")
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+		a.challenge(w, r, http.StatusUnauthorized, "", "")
+		return
+	}
+
+	info, err := a.validator(r.Context(), strings.TrimSpace(token))
+	if err != nil || info == nil {
+		a.challenge(w, r, http.StatusUnauthorized, "invalid_token", "The access token is invalid")
+		return
+	}
+	if !info.ExpiresAt.IsZero() && time.Now().After(info.ExpiresAt) {
+		a.challenge(w, r, http.StatusUnauthorized, "invalid_token", "The access token expired")
+		return
+	}
+	for _, scope := range a.scopes {
+		if !info.HasScope(scope) {
+			a.challenge(w, r, http.StatusForbidden, "insufficient_scope", "Missing scope "+scope)
+			return
+		}
+	}
+
+	next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), tokenInfoKey{}, info)))
+}
+
+// challenge 写出带 WWW-Authenticate 头的错误响应
+func (a *bearerAuth) challenge(w http.ResponseWriter, r *http.Request, status int, code, description string) {
+	params := []string{`realm="mcp"`}
+	if code != "" {
+		params = append(params, fmt.Sprintf("error=%q", code), fmt.Sprintf("error_description=%q", description))
+	}
+	if len(a.scopes) > 0 {
+		params = append(params, fmt.Sprintf("scope=%q", strings.Join(a.scopes, " ")))
+	}
+	if a.metadata != nil {
+		scheme := "http"
+		if r.TLS != nil {
+			scheme = "https"
+		}
+		params = append(params, fmt.Sprintf("resource_metadata=%q", scheme+"://"+r.Host+wellKnownProtectedResource))
+	}
+	w.Header().Set("WWW-Authenticate", "Bearer "+strings.Join(params, ", "))
+	http.Error(w, http.StatusText(status), status)
+}
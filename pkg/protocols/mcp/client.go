@@ -6,6 +6,9 @@ import (
 	"fmt"
 	"sync"
 	"sync/atomic"
+	"time"
+
+	"github.com/ahhsitt/helloagents-go/pkg/core/retry"
 )
 
 // Client MCP 客户端
@@ -39,14 +42,45 @@ type Client struct {
 	requestID   atomic.Int64
 	initialized atomic.Bool
 	serverInfo  *Implementation
-	mu          sync.Mutex
+	retry       *retry.Policy
+
+	progressMu sync.Mutex
+	progress   map[string]func(ProgressParams)
+	progressID atomic.Int64
+
+	rootsMu      sync.RWMutex
+	roots        []Root
+	rootsEnabled bool
+
+	middlewares []Middleware
+}
+
+// ClientOption MCP 客户端选项
+type ClientOption func(*Client)
+
+// WithClientRetryPolicy 设置请求的重试策略，覆盖全局默认策略
+//
+// 只有传输层的可重试错误（限速、服务不可用、超时）会被重试，
+// 服务器返回的 JSON-RPC 错误不会重试。
+func WithClientRetryPolicy(p retry.Policy) ClientOption {
+	return func(c *Client) {
+		c.retry = &p
+	}
 }
 
 // NewClient 创建 MCP 客户端
-func NewClient(transport Transport) *Client {
-	return &Client{
+func NewClient(transport Transport, opts ...ClientOption) *Client {
+	c := &Client{
 		transport: transport,
+		progress:  make(map[string]func(ProgressParams)),
+	}
+	for _, opt := range opts {
+		opt(c)
 	}
+	if source, ok := transport.(notificationSource); ok {
+		source.setNotificationHandler(c.handleMessage)
+	}
+	return c
 }
 
 // Initialize 初始化客户端连接
@@ -68,6 +102,12 @@ func (c *Client) Initialize(ctx context.Context) error {
 		},
 	}
 
+	c.rootsMu.RLock()
+	if c.rootsEnabled {
+		params.Capabilities.Roots = &RootsCapability{ListChanged: true}
+	}
+	c.rootsMu.RUnlock()
+
 	result, err := c.call(ctx, MethodInitialize, params)
 	if err != nil {
 		return fmt.Errorf("initialize failed: %w", err)
@@ -125,18 +165,57 @@ func (c *Client) ListTools(ctx context.Context) ([]ToolInfo, error) {
 	return tools, nil
 }
 
+// CallToolOption 工具调用选项
+type CallToolOption func(*callToolOptions)
+
+// callToolOptions 工具调用配置
+type callToolOptions struct {
+	onProgress func(ProgressParams)
+}
+
+// WithProgress 接收服务器在工具执行期间发送的进度通知
+//
+// 调用时在 _meta.progressToken 中携带进度令牌，服务器通过 ReportProgress 报告的进度
+// 在传输的读取 goroutine 中回调 fn，fn 不应阻塞。
+// 只有能接收服务器消息的传输（Stdio、WebSocket、Streamable HTTP）支持进度通知。
+func WithProgress(fn func(ProgressParams)) CallToolOption {
+	return func(o *callToolOptions) {
+		o.onProgress = fn
+	}
+}
+
 // CallTool 调用工具
-func (c *Client) CallTool(ctx context.Context, name string, arguments map[string]interface{}) (string, error) {
+//
+// ctx 被取消时向服务器发送 notifications/cancelled，服务器据此取消工具的执行。
+func (c *Client) CallTool(ctx context.Context, name string, arguments map[string]interface{}, opts ...CallToolOption) (string, error) {
 	if !c.initialized.Load() {
 		if err := c.Initialize(ctx); err != nil {
 			return "", err
 		}
 	}
 
+	var options callToolOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
 	params := CallToolParams{
 		Name:      name,
 		Arguments: arguments,
 	}
+	if options.onProgress != nil {
+		token := fmt.Sprintf("progress-%d", c.progressID.Add(1))
+		params.Meta = &RequestMeta{ProgressToken: token}
+		key := requestIDKey(token)
+		c.progressMu.Lock()
+		c.progress[key] = options.onProgress
+		c.progressMu.Unlock()
+		defer func() {
+			c.progressMu.Lock()
+			delete(c.progress, key)
+			c.progressMu.Unlock()
+		}()
+	}
 
 	result, err := c.call(ctx, MethodCallTool, params)
 	if err != nil {
@@ -229,6 +308,58 @@ func (c *Client) ReadResource(ctx context.Context, uri string) (string, error) {
 	return content, nil
 }
 
+// ListResourceTemplates 列出所有资源模板
+func (c *Client) ListResourceTemplates(ctx context.Context) ([]ResourceTemplate, error) {
+	if !c.initialized.Load() {
+		if err := c.Initialize(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	result, err := c.call(ctx, MethodListResourceTemplates, nil)
+	if err != nil {
+		return nil, fmt.Errorf("list resource templates failed: %w", err)
+	}
+
+	var listResult ListResourceTemplatesResult
+	if err := json.Unmarshal(result, &listResult); err != nil {
+		return nil, fmt.Errorf("failed to parse list resource templates result: %w", err)
+	}
+
+	return listResult.ResourceTemplates, nil
+}
+
+// SubscribeResource 订阅资源变化
+//
+// 资源变化时服务器发送 notifications/resources/updated 通知，
+// 需要使用支持服务器推送的传输（WebSocket、Stdio）并通过其 OnMessage 回调接收。
+func (c *Client) SubscribeResource(ctx context.Context, uri string) error {
+	if !c.initialized.Load() {
+		if err := c.Initialize(ctx); err != nil {
+			return err
+		}
+	}
+
+	if _, err := c.call(ctx, MethodSubscribeResource, SubscribeResourceParams{URI: uri}); err != nil {
+		return fmt.Errorf("subscribe resource failed: %w", err)
+	}
+	return nil
+}
+
+// UnsubscribeResource 取消订阅资源变化
+func (c *Client) UnsubscribeResource(ctx context.Context, uri string) error {
+	if !c.initialized.Load() {
+		if err := c.Initialize(ctx); err != nil {
+			return err
+		}
+	}
+
+	if _, err := c.call(ctx, MethodUnsubscribeResource, SubscribeResourceParams{URI: uri}); err != nil {
+		return fmt.Errorf("unsubscribe resource failed: %w", err)
+	}
+	return nil
+}
+
 // ListPrompts 列出所有可用提示词
 func (c *Client) ListPrompts(ctx context.Context) ([]PromptInfo, error) {
 	if !c.initialized.Load() {
@@ -303,40 +434,134 @@ func (c *Client) Close() error {
 	return c.transport.Close()
 }
 
+// handleMessage 处理服务器主动发送的消息：响应服务器的请求，分发进度通知
+func (c *Client) handleMessage(message []byte) {
+	var msg struct {
+		ID     json.RawMessage `json:"id"`
+		Method string          `json:"method"`
+		Params json.RawMessage `json:"params"`
+	}
+	if json.Unmarshal(message, &msg) != nil || msg.Method == "" {
+		return
+	}
+	if messageIDKey(msg.ID) != "" {
+		// 在读取 goroutine 之外响应，发送响应不会阻塞后续消息的读取
+		go c.handleServerRequest(msg.ID, msg.Method)
+		return
+	}
+	if msg.Method == NotificationProgress {
+		c.handleProgress(msg.Params)
+	}
+}
+
+// handleServerRequest 响应服务器发起的请求
+func (c *Client) handleServerRequest(id json.RawMessage, method string) {
+	var result interface{}
+	switch method {
+	case MethodPing:
+		result = struct{}{}
+	case MethodListRoots:
+		if roots, ok := c.listRoots(); ok {
+			result = roots
+		}
+	}
+
+	resp := JSONRPCResponse{JSONRPC: JSONRPCVersion, ID: id}
+	if result != nil {
+		resp.Result, _ = json.Marshal(result)
+	} else {
+		resp.Error = &JSONRPCError{Code: -32601, Message: "Method not found"}
+	}
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, _ = c.transport.Send(ctx, data)
+}
+
+// handleProgress 将进度通知交给对应调用的回调
+func (c *Client) handleProgress(raw json.RawMessage) {
+	var token struct {
+		ProgressToken json.RawMessage `json:"progressToken"`
+	}
+	var params ProgressParams
+	if json.Unmarshal(raw, &token) != nil || json.Unmarshal(raw, &params) != nil {
+		return
+	}
+
+	c.progressMu.Lock()
+	fn, ok := c.progress[messageIDKey(token.ProgressToken)]
+	c.progressMu.Unlock()
+	if ok {
+		fn(params)
+	}
+}
+
 // call 发送请求并等待响应
+//
+// ctx 被取消时通知服务器取消该请求（initialize 请求除外）。
 func (c *Client) call(ctx context.Context, method string, params interface{}) (json.RawMessage, error) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-
 	id := c.requestID.Add(1)
 
-	request, err := NewRequest(id, method, params)
+	req := &JSONRPCRequest{JSONRPC: JSONRPCVersion, ID: id, Method: method}
+	if params != nil {
+		raw, err := json.Marshal(params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal params: %w", err)
+		}
+		req.Params = raw
+	}
+
+	resp, err := chainMiddleware(c.send, c.middlewares)(ctx, req)
 	if err != nil {
+		if ctx.Err() != nil && method != MethodInitialize {
+			c.cancel(ctx, id, ctx.Err())
+		}
 		return nil, err
 	}
 
-	response, err := c.transport.Send(ctx, request)
+	if resp.Error != nil {
+		return nil, fmt.Errorf("RPC error %d: %s", resp.Error.Code, resp.Error.Message)
+	}
+
+	return resp.Result, nil
+}
+
+// send 按重试策略经传输层发送请求并解析响应，是客户端拦截器链的最内层
+func (c *Client) send(ctx context.Context, req *JSONRPCRequest) (*JSONRPCResponse, error) {
+	request, err := json.Marshal(req)
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := ParseResponse(response)
+	policy := retry.Default()
+	if c.retry != nil {
+		policy = *c.retry
+	}
+	var response []byte
+	err = policy.DoNamed(ctx, "mcp."+req.Method, func(ctx context.Context) error {
+		response, err = c.transport.Send(ctx, request)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	if resp.Error != nil {
-		return nil, fmt.Errorf("RPC error %d: %s", resp.Error.Code, resp.Error.Message)
-	}
+	return ParseResponse(response)
+}
 
-	return resp.Result, nil
+// cancel 通知服务器取消请求，尽力而为，忽略发送错误
+func (c *Client) cancel(ctx context.Context, id int64, reason error) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+	_ = c.notify(ctx, NotificationCancelled, CancelledParams{RequestID: id, Reason: reason.Error()})
 }
 
 // notify 发送通知（不等待响应）
 func (c *Client) notify(ctx context.Context, method string, params interface{}) error {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-
 	// 通知没有 ID
 	request, err := NewRequest(nil, method, params)
 	if err != nil {
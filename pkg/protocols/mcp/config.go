@@ -0,0 +1,237 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// 服务器传输类型
+const (
+	ServerTypeStdio     = "stdio"
+	ServerTypeHTTP      = "http"
+	ServerTypeWebSocket = "ws"
+)
+
+// ServersConfig MCP 服务器配置文件
+//
+// 兼容 Claude Desktop（claude_desktop_config.json）、Cursor 等使用的 mcpServers 格式:
+//
+//	{
+//	  "mcpServers": {
+//	    "filesystem": {
+//	      "command": "npx",
+//	      "args": ["-y", "@modelcontextprotocol/server-filesystem", "/tmp"]
+//	    },
+//	    "github": {
+//	      "command": "npx",
+//	      "args": ["-y", "@modelcontextprotocol/server-github"],
+//	      "env": {"GITHUB_PERSONAL_ACCESS_TOKEN": "${GITHUB_TOKEN}"}
+//	    },
+//	    "remote": {"url": "https://example.com/mcp", "headers": {"Authorization": "Bearer ${API_KEY}"}}
+//	  }
+//	}
+type ServersConfig struct {
+	MCPServers map[string]ServerConfig `json:"mcpServers"`
+}
+
+// ServerConfig 单个 MCP 服务器的配置
+type ServerConfig struct {
+	// Type 传输类型：stdio、http 或 ws，为空时根据 Command 和 URL 推断
+	Type string `json:"type,omitempty"`
+	// Command 启动命令（stdio）
+	Command string `json:"command,omitempty"`
+	// Args 命令参数（stdio）
+	Args []string `json:"args,omitempty"`
+	// Env 环境变量（stdio），值中的 ${VAR} 会替换为当前进程的环境变量
+	Env map[string]string `json:"env,omitempty"`
+	// Cwd 工作目录（stdio）
+	Cwd string `json:"cwd,omitempty"`
+	// URL 服务器地址（http、ws）
+	URL string `json:"url,omitempty"`
+	// Headers 请求头（http、ws），值中的 ${VAR} 会替换为当前进程的环境变量
+	Headers map[string]string `json:"headers,omitempty"`
+	// Disabled 为 true 时跳过该服务器
+	Disabled bool `json:"disabled,omitempty"`
+}
+
+// LoadServersConfig 读取并校验 mcpServers 格式的配置文件
+func LoadServersConfig(path string) (*ServersConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read MCP config: %w", err)
+	}
+	config, err := ParseServersConfig(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return config, nil
+}
+
+// ParseServersConfig 解析并校验 mcpServers 格式的配置
+func ParseServersConfig(data []byte) (*ServersConfig, error) {
+	var config ServersConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse MCP config: %w", err)
+	}
+	if config.MCPServers == nil {
+		return nil, fmt.Errorf("MCP config has no mcpServers")
+	}
+
+	var errs []error
+	for _, name := range config.Names() {
+		if err := config.MCPServers[name].Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("server %s: %w", name, err))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// Names 返回未禁用的服务器名称，按名称排序
+func (c *ServersConfig) Names() []string {
+	names := make([]string, 0, len(c.MCPServers))
+	for name, server := range c.MCPServers {
+		if !server.Disabled {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// AddToManager 将未禁用的服务器登记到管理器，连接在首次使用时建立
+func (c *ServersConfig) AddToManager(m *Manager) error {
+	var errs []error
+	for _, name := range c.Names() {
+		factory, err := c.MCPServers[name].TransportFactory()
+		if err == nil {
+			err = m.Add(name, factory)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("server %s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// NewManagerFromConfig 读取配置文件并创建登记了全部服务器的管理器
+//
+// 使用示例:
+//
+//	manager, err := mcp.NewManagerFromConfig("claude_desktop_config.json")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer manager.Close()
+//	manager.RegisterTools(ctx, registry)
+func NewManagerFromConfig(path string, opts ...ManagerOption) (*Manager, error) {
+	config, err := LoadServersConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	m := NewManager(opts...)
+	if err := config.AddToManager(m); err != nil {
+		m.Close()
+		return nil, err
+	}
+	return m, nil
+}
+
+// TransportType 返回传输类型，Type 为空时有 Command 为 stdio，
+// URL 以 ws:// 或 wss:// 开头为 ws，其余为 http
+func (c ServerConfig) TransportType() string {
+	if c.Type != "" {
+		switch strings.ToLower(c.Type) {
+		case "streamable-http", "streamablehttp":
+			return ServerTypeHTTP
+		case "websocket":
+			return ServerTypeWebSocket
+		default:
+			return strings.ToLower(c.Type)
+		}
+	}
+	if c.Command != "" {
+		return ServerTypeStdio
+	}
+	if strings.HasPrefix(c.URL, "ws://") || strings.HasPrefix(c.URL, "wss://") {
+		return ServerTypeWebSocket
+	}
+	return ServerTypeHTTP
+}
+
+// Validate 校验配置
+func (c ServerConfig) Validate() error {
+	switch c.TransportType() {
+	case ServerTypeStdio:
+		if c.Command == "" {
+			return fmt.Errorf("command is required")
+		}
+	case ServerTypeHTTP, ServerTypeWebSocket:
+		if c.URL == "" {
+			return fmt.Errorf("url is required")
+		}
+	default:
+		return fmt.Errorf("unsupported transport type %q", c.Type)
+	}
+	return nil
+}
+
+// StdioConfig 返回 stdio 传输配置，环境变量中的 ${VAR} 已展开
+func (c ServerConfig) StdioConfig() StdioTransportConfig {
+	return StdioTransportConfig{
+		Command: c.Command,
+		Args:    c.Args,
+		Env:     expandValues(c.Env),
+		Dir:     c.Cwd,
+	}
+}
+
+// TransportFactory 返回按配置创建传输的工厂
+func (c ServerConfig) TransportFactory() (TransportFactory, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	switch c.TransportType() {
+	case ServerTypeStdio:
+		config := c.StdioConfig()
+		return func(context.Context) (Transport, error) {
+			return NewStdioTransport(config)
+		}, nil
+	case ServerTypeWebSocket:
+		config := WebSocketTransportConfig{URL: c.URL, Headers: expandValues(c.Headers)}
+		return func(context.Context) (Transport, error) {
+			return NewWebSocketTransport(config)
+		}, nil
+	default:
+		config := HTTPTransportConfig{URL: c.URL, Headers: expandValues(c.Headers)}
+		return func(context.Context) (Transport, error) {
+			return NewHTTPTransport(config), nil
+		}, nil
+	}
+}
+
+// envReference 配置值中的 ${VAR} 引用；不展开 $VAR 形式，避免误改令牌等值中的 $
+var envReference = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// expandValues 展开值中的 ${VAR} 环境变量引用
+func expandValues(values map[string]string) map[string]string {
+	if values == nil {
+		return nil
+	}
+	expanded := make(map[string]string, len(values))
+	for k, v := range values {
+		expanded[k] = envReference.ReplaceAllStringFunc(v, func(ref string) string {
+			return os.Getenv(ref[2 : len(ref)-1])
+		})
+	}
+	return expanded
+}
@@ -0,0 +1,516 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ahhsitt/helloagents-go/pkg/tools"
+)
+
+// DefaultHealthCheckInterval 默认的健康检查间隔
+const DefaultHealthCheckInterval = 30 * time.Second
+
+// TransportFactory 创建传输的函数，Manager 在首次连接和每次重连时调用
+type TransportFactory func(ctx context.Context) (Transport, error)
+
+// ManagerOption Manager 配置选项
+type ManagerOption func(*Manager)
+
+// DefaultManagerName Manager 作为工具提供者的默认名称
+const DefaultManagerName = "mcp"
+
+// WithManagerName 设置 Manager 作为工具提供者的名称（默认 DefaultManagerName）
+func WithManagerName(name string) ManagerOption {
+	return func(m *Manager) {
+		if name != "" {
+			m.name = name
+		}
+	}
+}
+
+// WithToolPrefixSeparator 设置合并工具名中服务器名与工具名之间的分隔符（默认 "_"）
+func WithToolPrefixSeparator(sep string) ManagerOption {
+	return func(m *Manager) {
+		m.separator = sep
+	}
+}
+
+// WithHealthCheckInterval 设置后台健康检查间隔（默认 DefaultHealthCheckInterval），不大于 0 时不做后台检查
+func WithHealthCheckInterval(interval time.Duration) ManagerOption {
+	return func(m *Manager) {
+		m.healthInterval = interval
+	}
+}
+
+// WithManagerClientOptions 设置创建客户端时使用的选项
+func WithManagerClientOptions(opts ...ClientOption) ManagerOption {
+	return func(m *Manager) {
+		m.clientOpts = append(m.clientOpts, opts...)
+	}
+}
+
+// ServerStatus 受管服务器的状态
+type ServerStatus struct {
+	Name       string
+	Connected  bool
+	Tools      int
+	Reconnects int
+	LastError  error
+}
+
+// Manager 多 MCP 服务器管理器
+//
+// 按名称管理多个 MCP 客户端：
+//   - 延迟连接：Add 只登记服务器，首次使用时才创建传输并初始化
+//   - 健康检查：后台定期 ping 已连接的服务器，失败时断开并立即尝试重连
+//   - 自动重连：断开的服务器在下次使用时重新连接；工具调用失败且 ping 不通时同样断开
+//   - 合并工具命名空间：各服务器的工具以 "<服务器名><分隔符><工具名>" 暴露为 tools.Tool
+//   - 动态工具提供者：实现 tools.WatchableToolProvider，服务器登记、移除、连接或断开时通知注册表刷新
+//
+// 使用示例:
+//
+//	manager := mcp.NewManager()
+//	defer manager.Close()
+//	manager.AddStdio("filesystem", mcp.StdioTransportConfig{
+//	    Command: "npx",
+//	    Args:    []string{"-y", "@modelcontextprotocol/server-filesystem", "."},
+//	})
+//	manager.AddStdio("github", mcp.StdioTransportConfig{
+//	    Command: "npx",
+//	    Args:    []string{"-y", "@modelcontextprotocol/server-github"},
+//	})
+//
+//	registry := tools.NewRegistry()
+//	if err := registry.RegisterProvider(ctx, manager); err != nil {
+//	    log.Printf("some servers are unavailable: %v", err)
+//	}
+type Manager struct {
+	name           string
+	separator      string
+	healthInterval time.Duration
+	clientOpts     []ClientOption
+
+	mu      sync.Mutex
+	servers map[string]*managedServer
+	closed  bool
+
+	// changes 工具列表变化通知
+	changes tools.ChangeNotifier
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// managedServer 受管的服务器连接
+type managedServer struct {
+	name    string
+	factory TransportFactory
+
+	mu         sync.Mutex
+	client     *Client
+	tools      []ToolInfo
+	everUp     bool
+	reconnects int
+	lastErr    error
+}
+
+// NewManager 创建多服务器管理器
+func NewManager(opts ...ManagerOption) *Manager {
+	m := &Manager{
+		name:           DefaultManagerName,
+		separator:      "_",
+		healthInterval: DefaultHealthCheckInterval,
+		servers:        make(map[string]*managedServer),
+		stop:           make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	if m.healthInterval > 0 {
+		m.wg.Add(1)
+		go m.healthLoop()
+	}
+	return m
+}
+
+// Add 登记服务器，连接在首次使用时建立
+func (m *Manager) Add(name string, factory TransportFactory) error {
+	if name == "" {
+		return fmt.Errorf("server name is required")
+	}
+	if factory == nil {
+		return fmt.Errorf("server %s: transport factory is required", name)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return fmt.Errorf("manager is closed")
+	}
+	if _, ok := m.servers[name]; ok {
+		return fmt.Errorf("server %s already exists", name)
+	}
+	m.servers[name] = &managedServer{name: name, factory: factory}
+	m.changes.Notify()
+	return nil
+}
+
+// AddStdio 登记通过子进程启动的服务器
+func (m *Manager) AddStdio(name string, config StdioTransportConfig) error {
+	return m.Add(name, func(context.Context) (Transport, error) {
+		return NewStdioTransport(config)
+	})
+}
+
+// AddHTTP 登记通过 HTTP 访问的服务器
+func (m *Manager) AddHTTP(name string, config HTTPTransportConfig) error {
+	return m.Add(name, func(context.Context) (Transport, error) {
+		return NewHTTPTransport(config), nil
+	})
+}
+
+// Remove 断开并移除服务器
+func (m *Manager) Remove(name string) error {
+	m.mu.Lock()
+	server, ok := m.servers[name]
+	delete(m.servers, name)
+	m.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("server %s not found", name)
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	server.disconnectLocked(nil)
+	m.changes.Notify()
+	return nil
+}
+
+// Name 返回 Manager 作为工具提供者的名称
+func (m *Manager) Name() string {
+	return m.name
+}
+
+// Watch 订阅工具列表变化通知，返回的通道在 ctx 取消后关闭
+//
+// 服务器登记、移除、建立连接或断开时发出通知。
+func (m *Manager) Watch(ctx context.Context) <-chan struct{} {
+	return m.changes.Watch(ctx)
+}
+
+// Names 返回已登记的服务器名称（按名称排序）
+func (m *Manager) Names() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	names := make([]string, 0, len(m.servers))
+	for name := range m.servers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Client 返回服务器的客户端，未连接时先建立连接
+func (m *Manager) Client(ctx context.Context, name string) (*Client, error) {
+	server, err := m.server(name)
+	if err != nil {
+		return nil, err
+	}
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if err := m.connectLocked(ctx, server); err != nil {
+		return nil, err
+	}
+	return server.client, nil
+}
+
+// CallTool 调用指定服务器的工具
+//
+// 调用失败时 ping 服务器，ping 不通则断开连接，下次使用时重连。调用本身不会重试。
+func (m *Manager) CallTool(ctx context.Context, serverName, toolName string, arguments map[string]interface{}, opts ...CallToolOption) (string, error) {
+	client, err := m.Client(ctx, serverName)
+	if err != nil {
+		return "", err
+	}
+	result, err := client.CallTool(ctx, toolName, arguments, opts...)
+	if err != nil && ctx.Err() == nil {
+		if pingErr := client.Ping(ctx); pingErr != nil {
+			m.markDown(serverName, client, pingErr)
+		}
+	}
+	return result, err
+}
+
+// Tools 连接全部服务器并返回合并后的工具列表
+//
+// 工具名为 "<服务器名><分隔符><工具名>"。无法连接的服务器被跳过，
+// 其错误合并后与其余服务器的工具一并返回。
+func (m *Manager) Tools(ctx context.Context) ([]tools.Tool, error) {
+	var result []tools.Tool
+	var errs []error
+	for _, name := range m.Names() {
+		server, err := m.server(name)
+		if err != nil {
+			continue // 期间已被移除
+		}
+		server.mu.Lock()
+		err = m.connectLocked(ctx, server)
+		infos := server.tools
+		server.mu.Unlock()
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, info := range infos {
+			result = append(result, &managerTool{
+				manager: m,
+				server:  name,
+				info:    info,
+				name:    name + m.separator + info.Name,
+			})
+		}
+	}
+	return result, errors.Join(errs...)
+}
+
+// RegisterTools 将全部服务器的工具注册到注册表
+//
+// 无法连接的服务器被跳过，返回的错误中包含这些服务器的连接错误和注册冲突。
+func (m *Manager) RegisterTools(ctx context.Context, registry *tools.Registry) error {
+	list, err := m.Tools(ctx)
+	errs := []error{err}
+	for _, tool := range list {
+		errs = append(errs, registry.Register(tool))
+	}
+	return errors.Join(errs...)
+}
+
+// HealthCheck ping 全部已连接的服务器，失败的服务器被断开并立即尝试重连
+//
+// 返回 ping 或重连失败的服务器及其错误。未连接过的服务器不检查（保持延迟连接）。
+func (m *Manager) HealthCheck(ctx context.Context) map[string]error {
+	failures := make(map[string]error)
+	for _, name := range m.Names() {
+		server, err := m.server(name)
+		if err != nil {
+			continue
+		}
+		if err := m.checkServer(ctx, server); err != nil {
+			failures[name] = err
+		}
+	}
+	return failures
+}
+
+// Status 返回全部服务器的状态（按名称排序）
+func (m *Manager) Status() []ServerStatus {
+	var statuses []ServerStatus
+	for _, name := range m.Names() {
+		server, err := m.server(name)
+		if err != nil {
+			continue
+		}
+		server.mu.Lock()
+		statuses = append(statuses, ServerStatus{
+			Name:       name,
+			Connected:  server.client != nil,
+			Tools:      len(server.tools),
+			Reconnects: server.reconnects,
+			LastError:  server.lastErr,
+		})
+		server.mu.Unlock()
+	}
+	return statuses
+}
+
+// Close 停止健康检查并断开全部服务器
+func (m *Manager) Close() error {
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return nil
+	}
+	m.closed = true
+	close(m.stop)
+	servers := m.servers
+	m.servers = make(map[string]*managedServer)
+	m.mu.Unlock()
+
+	m.wg.Wait()
+
+	var errs []error
+	for _, server := range servers {
+		server.mu.Lock()
+		if server.client != nil {
+			errs = append(errs, server.client.Close())
+			server.client = nil
+		}
+		server.mu.Unlock()
+	}
+	return errors.Join(errs...)
+}
+
+// server 按名称查找服务器
+func (m *Manager) server(name string) (*managedServer, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return nil, fmt.Errorf("manager is closed")
+	}
+	server, ok := m.servers[name]
+	if !ok {
+		return nil, fmt.Errorf("server %s not found", name)
+	}
+	return server, nil
+}
+
+// connectLocked 在未连接时建立连接并获取工具列表（调用方需持有 server.mu）
+func (m *Manager) connectLocked(ctx context.Context, server *managedServer) error {
+	if server.client != nil {
+		return nil
+	}
+
+	transport, err := server.factory(ctx)
+	if err != nil {
+		server.lastErr = err
+		return fmt.Errorf("server %s: failed to create transport: %w", server.name, err)
+	}
+	client := NewClient(transport, m.clientOpts...)
+	if err := client.Initialize(ctx); err != nil {
+		client.Close()
+		server.lastErr = err
+		return fmt.Errorf("server %s: %w", server.name, err)
+	}
+	infos, err := client.ListTools(ctx)
+	if err != nil {
+		client.Close()
+		server.lastErr = err
+		return fmt.Errorf("server %s: %w", server.name, err)
+	}
+
+	if server.everUp {
+		server.reconnects++
+	}
+	server.everUp = true
+	server.client = client
+	server.tools = infos
+	server.lastErr = nil
+	m.changes.Notify()
+	return nil
+}
+
+// checkServer ping 已连接的服务器，失败时断开并重连
+func (m *Manager) checkServer(ctx context.Context, server *managedServer) error {
+	server.mu.Lock()
+	client := server.client
+	everUp := server.everUp
+	server.mu.Unlock()
+	if client == nil && !everUp {
+		return nil
+	}
+
+	if client != nil {
+		err := client.Ping(ctx)
+		if err == nil {
+			return nil
+		}
+		m.markDown(server.name, client, err)
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	return m.connectLocked(ctx, server)
+}
+
+// markDown 断开连接（仅当当前客户端仍是 client 时），下次使用时重连
+func (m *Manager) markDown(name string, client *Client, err error) {
+	server, lookupErr := m.server(name)
+	if lookupErr != nil {
+		return
+	}
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if server.client == client {
+		server.disconnectLocked(err)
+		m.changes.Notify()
+	}
+}
+
+// disconnectLocked 关闭客户端（调用方需持有 server.mu）
+func (s *managedServer) disconnectLocked(err error) {
+	if s.client != nil {
+		s.client.Close()
+		s.client = nil
+	}
+	if err != nil {
+		s.lastErr = err
+	}
+}
+
+// healthLoop 定期执行健康检查
+func (m *Manager) healthLoop() {
+	defer m.wg.Done()
+	ticker := time.NewTicker(m.healthInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.stop:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), m.healthInterval)
+			m.HealthCheck(ctx)
+			cancel()
+		}
+	}
+}
+
+// managerTool 将受管服务器的工具适配为 tools.Tool
+type managerTool struct {
+	manager *Manager
+	server  string
+	info    ToolInfo
+	name    string
+}
+
+// Name 返回带服务器前缀的工具名称
+func (t *managerTool) Name() string {
+	return t.name
+}
+
+// Description 返回工具描述
+func (t *managerTool) Description() string {
+	return t.info.Description
+}
+
+// Parameters 将 MCP 工具的 InputSchema 转换为参数 Schema
+func (t *managerTool) Parameters() tools.ParameterSchema {
+	schema := tools.ParameterSchema{Type: "object"}
+	if data, err := json.Marshal(t.info.InputSchema); err == nil {
+		_ = json.Unmarshal(data, &schema)
+	}
+	if schema.Type == "" {
+		schema.Type = "object"
+	}
+	return schema
+}
+
+// Execute 通过 Manager 调用服务器上的工具
+func (t *managerTool) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
+	result, err := t.manager.CallTool(ctx, t.server, t.info.Name, args)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", t.name, err)
+	}
+	return result, nil
+}
+
+// compile-time interface check
+var (
+	_ tools.Tool                  = (*managerTool)(nil)
+	_ tools.WatchableToolProvider = (*Manager)(nil)
+)
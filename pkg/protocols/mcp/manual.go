@@ -0,0 +1,65 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ahhsitt/helloagents-go/pkg/tools"
+)
+
+// 工具手册资源常量
+const (
+	// ToolManualScheme 工具手册资源 URI 协议
+	ToolManualScheme = "tool"
+	// ToolManualMimeType 工具手册资源 MIME 类型
+	ToolManualMimeType = "text/markdown"
+)
+
+// ToolManualURI 返回工具手册资源 URI
+//
+// 格式为 tool://<name>/manual，例如 tool://calculator/manual。
+func ToolManualURI(name string) string {
+	return fmt.Sprintf("%s://%s/manual", ToolManualScheme, name)
+}
+
+// ParseToolManualURI 从工具手册资源 URI 中解析工具名称
+func ParseToolManualURI(uri string) (string, bool) {
+	prefix := ToolManualScheme + "://"
+	if !strings.HasPrefix(uri, prefix) || !strings.HasSuffix(uri, "/manual") {
+		return "", false
+	}
+	name := strings.TrimSuffix(strings.TrimPrefix(uri, prefix), "/manual")
+	if name == "" || strings.Contains(name, "/") {
+		return "", false
+	}
+	return name, true
+}
+
+// AddToolManuals 将注册表中所有工具的使用手册注册为 MCP 资源
+//
+// 每个工具对应一个 tool://<name>/manual 资源，内容为 Markdown 格式的
+// 参数说明、JSON Schema 和使用示例。手册在读取时从注册表实时生成，
+// 因此工具描述的变化会立即反映给客户端。
+func (s *Server) AddToolManuals(registry *tools.Registry) {
+	for _, tool := range registry.All() {
+		s.AddToolManual(registry, tool.Name())
+	}
+}
+
+// AddToolManual 将注册表中指定工具的使用手册注册为 MCP 资源
+func (s *Server) AddToolManual(registry *tools.Registry, name string) {
+	s.AddResource(ServerResource{
+		URI:         ToolManualURI(name),
+		Name:        name + " manual",
+		Description: fmt.Sprintf("Usage manual for tool %q: parameters, JSON schema and examples", name),
+		MimeType:    ToolManualMimeType,
+		Handler: func(_ context.Context) (string, error) {
+			manual, err := registry.Manual(name)
+			if err != nil {
+				return "", fmt.Errorf("tool %s: %w", name, err)
+			}
+			return manual.Markdown(), nil
+		},
+	})
+}
@@ -0,0 +1,228 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Handler 处理一个 JSON-RPC 请求并返回响应
+//
+// 服务器端的 Handler 将请求分发给工具、资源和提示词；客户端的 Handler 经传输层发送请求。
+// 返回错误时，服务器将其转换为错误响应（错误是 *JSONRPCError 时保留其错误码，否则为 -32603），
+// 客户端将其作为调用错误返回。
+type Handler func(ctx context.Context, req *JSONRPCRequest) (*JSONRPCResponse, error)
+
+// Middleware 请求拦截器，包装 next 返回新的 Handler
+//
+// 拦截器可以在调用 next 前检查或修改请求，在调用后检查或替换响应，也可以不调用 next 直接返回。
+// 只拦截请求，不拦截通知。
+//
+// 使用示例:
+//
+//	server.Use(mcp.LoggingMiddleware(slog.Default()))
+//	server.Use(func(next mcp.Handler) mcp.Handler {
+//	    return func(ctx context.Context, req *mcp.JSONRPCRequest) (*mcp.JSONRPCResponse, error) {
+//	        start := time.Now()
+//	        resp, err := next(ctx, req)
+//	        requestDuration.WithLabelValues(req.Method).Observe(time.Since(start).Seconds())
+//	        return resp, err
+//	    }
+//	})
+type Middleware func(next Handler) Handler
+
+// Error 实现 error 接口，拦截器可以返回 *JSONRPCError 指定错误响应的错误码
+func (e *JSONRPCError) Error() string {
+	return e.Message
+}
+
+// chainMiddleware 按顺序包装 handler，第一个拦截器在最外层
+func chainMiddleware(handler Handler, middlewares []Middleware) Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+// Use 添加服务器请求拦截器，先添加的在外层
+func (s *Server) Use(middlewares ...Middleware) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.middlewares = append(s.middlewares, middlewares...)
+}
+
+// dispatch 经拦截器处理请求
+func (s *Server) dispatch(ctx context.Context, req *JSONRPCRequest) *JSONRPCResponse {
+	s.mu.RLock()
+	middlewares := s.middlewares
+	s.mu.RUnlock()
+
+	handler := chainMiddleware(func(ctx context.Context, req *JSONRPCRequest) (*JSONRPCResponse, error) {
+		return s.handleCall(ctx, req), nil
+	}, middlewares)
+
+	resp, err := handler(ctx, req)
+	if err != nil {
+		var rpcErr *JSONRPCError
+		if errors.As(err, &rpcErr) {
+			return &JSONRPCResponse{JSONRPC: JSONRPCVersion, ID: req.ID, Error: rpcErr}
+		}
+		return s.errorResponse(req.ID, -32603, "Internal error", err.Error())
+	}
+	return resp
+}
+
+// WithClientMiddleware 添加客户端请求拦截器，先添加的在外层
+//
+// 拦截器在重试策略之外执行，每次调用只经过一次。
+func WithClientMiddleware(middlewares ...Middleware) ClientOption {
+	return func(c *Client) {
+		c.middlewares = append(c.middlewares, middlewares...)
+	}
+}
+
+// LoggingMiddleware 记录每个请求的方法、工具名、耗时和结果的拦截器
+//
+// 成功的请求以 Debug 级别记录，失败的请求（错误或错误响应）以 Warn 级别记录。
+// logger 为 nil 时使用 slog.Default()。服务器和客户端都可以使用。
+func LoggingMiddleware(logger *slog.Logger) Middleware {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return func(next Handler) Handler {
+		return func(ctx context.Context, req *JSONRPCRequest) (*JSONRPCResponse, error) {
+			start := time.Now()
+			resp, err := next(ctx, req)
+
+			attrs := []any{
+				slog.String("method", req.Method),
+				slog.Any("id", req.ID),
+				slog.Duration("duration", time.Since(start)),
+			}
+			if name := toolName(req); name != "" {
+				attrs = append(attrs, slog.String("tool", name))
+			}
+			switch {
+			case err != nil:
+				logger.WarnContext(ctx, "mcp request failed", append(attrs, slog.String("error", err.Error()))...)
+			case resp != nil && resp.Error != nil:
+				logger.WarnContext(ctx, "mcp request failed", append(attrs,
+					slog.Int("code", resp.Error.Code), slog.String("error", resp.Error.Message))...)
+			default:
+				logger.DebugContext(ctx, "mcp request", attrs...)
+			}
+			return resp, err
+		}
+	}
+}
+
+// ToolRateLimitMiddleware 按工具名限制 tools/call 频率的拦截器
+//
+// 每个工具使用独立的令牌桶：每秒补充 rate 个令牌，最多累积 burst 个。
+// 超出限制的调用不执行，返回错误码为 -32000 的 JSONRPCError。
+// 服务器端使用时限制所有客户端的合计频率；客户端使用时限制本客户端发出的调用。
+func ToolRateLimitMiddleware(rate float64, burst int) Middleware {
+	if burst < 1 {
+		burst = 1
+	}
+	limiter := &toolRateLimiter{rate: rate, burst: float64(burst), buckets: make(map[string]*tokenBucket)}
+	return func(next Handler) Handler {
+		return func(ctx context.Context, req *JSONRPCRequest) (*JSONRPCResponse, error) {
+			if name := toolName(req); name != "" && !limiter.allow(name, time.Now()) {
+				data, _ := json.Marshal(name)
+				return nil, &JSONRPCError{Code: -32000, Message: "Rate limit exceeded", Data: data}
+			}
+			return next(ctx, req)
+		}
+	}
+}
+
+// toolRateLimiter 每个工具一个令牌桶
+type toolRateLimiter struct {
+	rate  float64
+	burst float64
+
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+}
+
+// tokenBucket 令牌桶
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// allow 取走工具的一个令牌，没有令牌时返回 false
+func (l *toolRateLimiter) allow(tool string, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	bucket, ok := l.buckets[tool]
+	if !ok {
+		bucket = &tokenBucket{tokens: l.burst, last: now}
+		l.buckets[tool] = bucket
+	}
+	bucket.tokens += now.Sub(bucket.last).Seconds() * l.rate
+	if bucket.tokens > l.burst {
+		bucket.tokens = l.burst
+	}
+	bucket.last = now
+	if bucket.tokens < 1 {
+		return false
+	}
+	bucket.tokens--
+	return true
+}
+
+// ToolArgumentsMiddleware 在 tools/call 执行前检查或改写参数的拦截器
+//
+// fn 返回的参数替换原参数，可用于清理敏感字段、补充默认值或规范化输入；
+// fn 返回错误时调用不执行，错误码为 -32602（*JSONRPCError 除外）。
+func ToolArgumentsMiddleware(fn func(ctx context.Context, tool string, args map[string]interface{}) (map[string]interface{}, error)) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, req *JSONRPCRequest) (*JSONRPCResponse, error) {
+			if req.Method != MethodCallTool {
+				return next(ctx, req)
+			}
+			var params CallToolParams
+			if err := json.Unmarshal(req.Params, &params); err != nil {
+				return next(ctx, req)
+			}
+
+			args, err := fn(ctx, params.Name, params.Arguments)
+			if err != nil {
+				var rpcErr *JSONRPCError
+				if errors.As(err, &rpcErr) {
+					return nil, rpcErr
+				}
+				return nil, &JSONRPCError{Code: -32602, Message: "Invalid params: " + err.Error()}
+			}
+			params.Arguments = args
+			raw, err := json.Marshal(params)
+			if err != nil {
+				return nil, err
+			}
+
+			rewritten := *req
+			rewritten.Params = raw
+			return next(ctx, &rewritten)
+		}
+	}
+}
+
+// toolName tools/call 请求的工具名，其他请求返回空字符串
+func toolName(req *JSONRPCRequest) string {
+	if req.Method != MethodCallTool {
+		return ""
+	}
+	var params struct {
+		Name string `json:"name"`
+	}
+	if json.Unmarshal(req.Params, &params) != nil {
+		return ""
+	}
+	return params.Name
+}
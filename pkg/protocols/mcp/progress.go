@@ -0,0 +1,144 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+)
+
+// errRequestCancelled 客户端取消了请求
+var errRequestCancelled = errors.New("request cancelled by client")
+
+// progressReporter 向请求方推送进度通知
+type progressReporter struct {
+	token interface{}
+	send  func(message []byte) error
+}
+
+// progressKey 上下文中进度报告器的键
+type progressKey struct{}
+
+// notifierKey 上下文中请求级通知发送函数的键
+type notifierKey struct{}
+
+// withNotifier 设置请求级的通知发送函数，优先于会话的发送函数
+//
+// 用于把请求处理中产生的通知（如进度）随该请求的响应流一起返回。
+func withNotifier(ctx context.Context, send func(message []byte) error) context.Context {
+	return context.WithValue(ctx, notifierKey{}, send)
+}
+
+// notifierFrom 返回请求级的通知发送函数，没有时返回会话的发送函数
+func notifierFrom(ctx context.Context) func(message []byte) error {
+	if send, ok := ctx.Value(notifierKey{}).(func(message []byte) error); ok {
+		return send
+	}
+	if session := serverSessionFrom(ctx); session != nil {
+		return session.send
+	}
+	return nil
+}
+
+// ReportProgress 报告当前请求的进度
+//
+// 在工具、资源、提示词的处理函数中调用。请求方在 _meta.progressToken 中提供了进度令牌时，
+// 向其发送 notifications/progress 通知；否则什么也不做并返回 nil。
+// total 不大于 0 表示总量未知，progress 应随每次调用递增。
+//
+// 使用示例:
+//
+//	Handler: func(ctx context.Context, args map[string]interface{}) (string, error) {
+//	    for i, file := range files {
+//	        if err := ctx.Err(); err != nil {
+//	            return "", err // 客户端取消了请求
+//	        }
+//	        process(file)
+//	        mcp.ReportProgress(ctx, float64(i+1), float64(len(files)), file)
+//	    }
+//	    return "done", nil
+//	}
+func ReportProgress(ctx context.Context, progress, total float64, message string) error {
+	reporter, ok := ctx.Value(progressKey{}).(*progressReporter)
+	if !ok {
+		return nil
+	}
+	params := ProgressParams{ProgressToken: reporter.token, Progress: progress, Message: message}
+	if total > 0 {
+		params.Total = total
+	}
+	paramsBytes, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(JSONRPCNotification{
+		JSONRPC: JSONRPCVersion,
+		Method:  NotificationProgress,
+		Params:  paramsBytes,
+	})
+	if err != nil {
+		return err
+	}
+	return reporter.send(data)
+}
+
+// beginRequest 为请求准备上下文：绑定进度报告器，并登记到会话以便客户端取消
+//
+// 返回的 done 在请求处理结束后调用，cancelled 报告请求是否被客户端取消。
+func (s *Server) beginRequest(ctx context.Context, req *JSONRPCRequest) (context.Context, func(), func() bool) {
+	var meta struct {
+		Meta *RequestMeta `json:"_meta"`
+	}
+	if len(req.Params) > 0 && json.Unmarshal(req.Params, &meta) == nil && meta.Meta != nil && meta.Meta.ProgressToken != nil {
+		if send := notifierFrom(ctx); send != nil {
+			ctx = context.WithValue(ctx, progressKey{}, &progressReporter{token: meta.Meta.ProgressToken, send: send})
+		}
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	cancelled := func() bool {
+		return errors.Is(context.Cause(ctx), errRequestCancelled)
+	}
+	session := serverSessionFrom(ctx)
+	key := requestIDKey(req.ID)
+	if session == nil || key == "" {
+		return ctx, func() { cancel(nil) }, cancelled
+	}
+
+	session.track(key, cancel)
+	return ctx, func() {
+		session.untrack(key)
+		cancel(nil)
+	}, cancelled
+}
+
+// handleCancel 处理取消通知，取消对应的进行中请求
+func (s *Server) handleCancel(ctx context.Context, req *JSONRPCRequest) {
+	session := serverSessionFrom(ctx)
+	if session == nil {
+		return
+	}
+	var params struct {
+		RequestID json.RawMessage `json:"requestId"`
+		ID        json.RawMessage `json:"id"` // $/cancelRequest
+	}
+	if err := json.Unmarshal(req.Params, &params); err != nil {
+		return
+	}
+	id := params.RequestID
+	if len(id) == 0 {
+		id = params.ID
+	}
+	session.cancelRequest(messageIDKey(id))
+}
+
+// requestIDKey 请求 ID 的匹配键，与 messageIDKey 对原始 JSON 的结果一致
+func requestIDKey(id interface{}) string {
+	if id == nil {
+		return ""
+	}
+	data, err := json.Marshal(id)
+	if err != nil {
+		return ""
+	}
+	return messageIDKey(data)
+}
@@ -0,0 +1,42 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+)
+
+// TemplatePromptHandler 创建基于文本模板的提示词处理函数
+//
+// 模板中的 {{name}} 替换为同名参数的值（未提供的参数替换为空字符串），
+// 结果作为一条 user 消息返回。例如：
+//
+//	server.AddPrompt(mcp.ServerPrompt{
+//	    Name:      "code-review",
+//	    Arguments: []mcp.PromptArgument{{Name: "code", Required: true}},
+//	    Handler:   mcp.TemplatePromptHandler("Please review the following code:\n\n{{code}}"),
+//	})
+func TemplatePromptHandler(template string) PromptHandler {
+	return func(_ context.Context, arguments map[string]string) ([]PromptMessage, error) {
+		// 逐段扫描，参数值中的 {{ }} 不会被再次替换
+		var text strings.Builder
+		rest := template
+		for {
+			start := strings.Index(rest, "{{")
+			if start < 0 {
+				break
+			}
+			end := strings.Index(rest[start:], "}}")
+			if end < 0 {
+				break
+			}
+			text.WriteString(rest[:start])
+			text.WriteString(arguments[strings.TrimSpace(rest[start+2:start+end])])
+			rest = rest[start+end+2:]
+		}
+		text.WriteString(rest)
+		return []PromptMessage{{
+			Role:    "user",
+			Content: Content{Type: "text", Text: text.String()},
+		}}, nil
+	}
+}
@@ -0,0 +1,83 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ahhsitt/helloagents-go/pkg/tools"
+)
+
+// DefaultRegistryServerName NewServerFromRegistry 创建的服务器名称
+const DefaultRegistryServerName = "helloagents-tools"
+
+// NewServerFromRegistry 创建将注册表中全部工具发布为 MCP 工具的服务器
+//
+// 使用示例:
+//
+//	registry := tools.NewRegistry()
+//	registry.MustRegister(builtin.NewCalculator())
+//
+//	server := mcp.NewServerFromRegistry(registry)
+//	server.Run(ctx, os.Stdin, os.Stdout)
+func NewServerFromRegistry(registry *tools.Registry) *Server {
+	s := NewServer(DefaultRegistryServerName, "HelloAgents tools published over MCP")
+	s.AddRegistryTools(registry)
+	return s
+}
+
+// AddRegistryTools 将注册表中的全部工具添加为 MCP 工具
+//
+// 只添加调用时注册表中已有的工具，之后注册的工具需再次调用或使用 AddRegistryTool。
+func (s *Server) AddRegistryTools(registry *tools.Registry) {
+	for _, tool := range registry.All() {
+		s.AddRegistryTool(tool)
+	}
+}
+
+// AddRegistryTool 将 tools.Tool 添加为 MCP 工具
+//
+// ParameterSchema 转换为 JSON Schema 作为 inputSchema；调用前先校验参数
+// （工具实现 tools.ToolWithValidation 时使用其 Validate，否则按 Schema 校验），
+// 校验或执行失败时以 isError 结果返回错误信息。
+func (s *Server) AddRegistryTool(tool tools.Tool) {
+	s.AddTool(ServerTool{
+		Name:        tool.Name(),
+		Description: tool.Description(),
+		InputSchema: parameterSchemaToJSON(tool.Parameters()),
+		Handler: func(ctx context.Context, arguments map[string]interface{}) (string, error) {
+			if arguments == nil {
+				arguments = make(map[string]interface{})
+			}
+			var err error
+			if validator, ok := tool.(tools.ToolWithValidation); ok {
+				err = validator.Validate(arguments)
+			} else {
+				err = tools.Validate(tool.Parameters(), arguments)
+			}
+			if err != nil {
+				return "", fmt.Errorf("invalid arguments: %w", err)
+			}
+			return tool.Execute(ctx, arguments)
+		},
+	})
+}
+
+// parameterSchemaToJSON 将参数 Schema 转换为 JSON Schema 对象
+func parameterSchemaToJSON(schema tools.ParameterSchema) map[string]interface{} {
+	if schema.Type == "" {
+		schema.Type = "object"
+	}
+	result := map[string]interface{}{"type": schema.Type}
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return result
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return map[string]interface{}{"type": schema.Type}
+	}
+	if _, ok := result["properties"]; !ok {
+		result["properties"] = map[string]interface{}{}
+	}
+	return result
+}
@@ -0,0 +1,94 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// ResourceTemplateHandler 资源模板处理函数类型，params 为从 URI 中解析出的模板变量
+type ResourceTemplateHandler func(ctx context.Context, uri string, params map[string]string) (string, error)
+
+// ServerResourceTemplate 服务器资源模板定义
+//
+// URITemplate 使用 RFC 6570 的简单形式：{name} 匹配一个不含 "/"、"?"、"#" 的片段，
+// {+name} 匹配任意字符（可含 "/"），例如 weather://{city}、file:///{+path}。
+type ServerResourceTemplate struct {
+	URITemplate string
+	Name        string
+	Description string
+	MimeType    string
+	Handler     ResourceTemplateHandler
+}
+
+// resourceTemplate 编译后的资源模板
+type resourceTemplate struct {
+	ServerResourceTemplate
+	pattern *regexp.Regexp
+	vars    []string
+}
+
+// compileResourceTemplate 将 URI 模板编译为正则表达式
+func compileResourceTemplate(tmpl ServerResourceTemplate) (*resourceTemplate, error) {
+	var expr strings.Builder
+	var vars []string
+	expr.WriteString("^")
+	rest := tmpl.URITemplate
+	for rest != "" {
+		open := strings.IndexByte(rest, '{')
+		if open < 0 {
+			if strings.IndexByte(rest, '}') >= 0 {
+				return nil, fmt.Errorf("invalid uri template %q: unmatched }", tmpl.URITemplate)
+			}
+			expr.WriteString(regexp.QuoteMeta(rest))
+			break
+		}
+		if strings.IndexByte(rest[:open], '}') >= 0 {
+			return nil, fmt.Errorf("invalid uri template %q: unmatched }", tmpl.URITemplate)
+		}
+		expr.WriteString(regexp.QuoteMeta(rest[:open]))
+
+		end := strings.IndexByte(rest[open:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("invalid uri template %q: unmatched {", tmpl.URITemplate)
+		}
+		name := rest[open+1 : open+end]
+		segment := `([^/?#]+)`
+		if strings.HasPrefix(name, "+") {
+			name = name[1:]
+			segment = `(.+)`
+		}
+		if name == "" || strings.ContainsAny(name, "{ ") {
+			return nil, fmt.Errorf("invalid uri template %q: bad variable %q", tmpl.URITemplate, name)
+		}
+		vars = append(vars, name)
+		expr.WriteString(segment)
+		rest = rest[open+end+1:]
+	}
+	expr.WriteString("$")
+
+	pattern, err := regexp.Compile(expr.String())
+	if err != nil {
+		return nil, fmt.Errorf("invalid uri template %q: %w", tmpl.URITemplate, err)
+	}
+	return &resourceTemplate{ServerResourceTemplate: tmpl, pattern: pattern, vars: vars}, nil
+}
+
+// match 匹配 URI，返回解码后的模板变量
+func (t *resourceTemplate) match(uri string) (map[string]string, bool) {
+	groups := t.pattern.FindStringSubmatch(uri)
+	if groups == nil {
+		return nil, false
+	}
+	params := make(map[string]string, len(t.vars))
+	for i, name := range t.vars {
+		value, err := url.PathUnescape(groups[i+1])
+		if err != nil {
+			value = groups[i+1]
+		}
+		params[name] = value
+	}
+	return params, true
+}
@@ -0,0 +1,120 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+// ErrRootsNotSupported 客户端没有声明 roots 能力
+var ErrRootsNotSupported = errors.New("client does not support roots")
+
+// FileRoot 将本地目录转换为 file:// 根目录，名称为目录名
+func FileRoot(dir string) (Root, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return Root{}, fmt.Errorf("failed to resolve %s: %w", dir, err)
+	}
+	path := filepath.ToSlash(abs)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path // Windows 盘符路径
+	}
+	u := url.URL{Scheme: "file", Path: path}
+	return Root{URI: u.String(), Name: filepath.Base(abs)}, nil
+}
+
+// WithRoots 声明 roots 能力并设置初始的根目录
+//
+// 服务器通过 roots/list 请求获取根目录，据此限定文件类操作的范围。
+// 只有能接收服务器消息的传输（Stdio、WebSocket，以及以事件流响应的 Streamable HTTP 请求）
+// 能收到服务器的请求。
+func WithRoots(roots ...Root) ClientOption {
+	return func(c *Client) {
+		c.rootsEnabled = true
+		c.roots = append([]Root(nil), roots...)
+	}
+}
+
+// SetRoots 替换根目录，并声明 roots 能力
+//
+// 客户端已初始化时向服务器发送 notifications/roots/list_changed，服务器随后重新获取根目录。
+// 在初始化之后才首次调用时，服务器在初始化时看不到 roots 能力，可能不会请求根目录，
+// 因此需要根目录的客户端应在连接前使用 WithRoots 或调用 SetRoots。
+func (c *Client) SetRoots(ctx context.Context, roots []Root) error {
+	c.rootsMu.Lock()
+	c.rootsEnabled = true
+	c.roots = append([]Root(nil), roots...)
+	c.rootsMu.Unlock()
+
+	if !c.initialized.Load() {
+		return nil
+	}
+	if err := c.notify(ctx, NotificationRootsListChanged, nil); err != nil {
+		return fmt.Errorf("notify roots changed failed: %w", err)
+	}
+	return nil
+}
+
+// Roots 返回当前的根目录
+func (c *Client) Roots() []Root {
+	c.rootsMu.RLock()
+	defer c.rootsMu.RUnlock()
+	return append([]Root(nil), c.roots...)
+}
+
+// listRoots 返回 roots/list 的结果，没有声明 roots 能力时返回 false
+func (c *Client) listRoots() (ListRootsResult, bool) {
+	c.rootsMu.RLock()
+	defer c.rootsMu.RUnlock()
+	if !c.rootsEnabled {
+		return ListRootsResult{}, false
+	}
+	return ListRootsResult{Roots: append([]Root{}, c.roots...)}, true
+}
+
+// ListRoots 向发起当前请求的客户端获取根目录
+//
+// 在工具、资源、提示词的处理函数中调用。客户端没有声明 roots 能力时返回 ErrRootsNotSupported。
+// Streamable HTTP 传输下，请求通过当前请求的事件流或会话的 GET 事件流发送。
+//
+// 使用示例:
+//
+//	Handler: func(ctx context.Context, args map[string]interface{}) (string, error) {
+//	    roots, err := mcp.ListRoots(ctx)
+//	    if err != nil {
+//	        return "", err
+//	    }
+//	    // 只在 roots 范围内读写文件
+//	}
+func ListRoots(ctx context.Context) ([]Root, error) {
+	session := serverSessionFrom(ctx)
+	if session == nil {
+		return nil, fmt.Errorf("no client session in context")
+	}
+	if caps := session.clientCapabilities(); caps.Roots == nil {
+		return nil, ErrRootsNotSupported
+	}
+
+	result, err := session.request(ctx, notifierFrom(ctx), MethodListRoots, nil)
+	if err != nil {
+		return nil, fmt.Errorf("list roots failed: %w", err)
+	}
+	var listResult ListRootsResult
+	if err := json.Unmarshal(result, &listResult); err != nil {
+		return nil, fmt.Errorf("failed to parse roots: %w", err)
+	}
+	return listResult.Roots, nil
+}
+
+// SetRootsChangedHandler 设置客户端根目录变化（notifications/roots/list_changed）时的回调
+//
+// 回调在单独的 goroutine 中调用，上下文携带发出通知的客户端会话，可以在其中调用 ListRoots 获取新的根目录。
+func (s *Server) SetRootsChangedHandler(handler func(ctx context.Context)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rootsChanged = handler
+}
@@ -2,11 +2,13 @@ package mcp
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"os"
+	"sort"
 	"sync"
 )
 
@@ -79,9 +81,16 @@ type Server struct {
 
 	tools     map[string]*ServerTool
 	resources map[string]*ServerResource
+	templates []*resourceTemplate
 	prompts   map[string]*ServerPrompt
 
+	rootsChanged func(ctx context.Context)
+	middlewares  []Middleware
+
 	mu sync.RWMutex
+
+	sessions   map[*serverSession]struct{}
+	sessionsMu sync.Mutex
 }
 
 // NewServer 创建 MCP 服务器
@@ -93,6 +102,7 @@ func NewServer(name, description string) *Server {
 		tools:       make(map[string]*ServerTool),
 		resources:   make(map[string]*ServerResource),
 		prompts:     make(map[string]*ServerPrompt),
+		sessions:    make(map[*serverSession]struct{}),
 	}
 }
 
@@ -103,23 +113,78 @@ func (s *Server) AddTool(tool ServerTool) {
 	s.tools[tool.Name] = &tool
 }
 
-// AddResource 添加资源
+// AddResource 添加资源，并通知已连接的客户端资源列表已变化
 func (s *Server) AddResource(resource ServerResource) {
 	s.mu.Lock()
-	defer s.mu.Unlock()
 	s.resources[resource.URI] = &resource
+	s.mu.Unlock()
+	s.notifyResourcesListChanged()
+}
+
+// RemoveResource 移除资源，并通知已连接的客户端资源列表已变化
+func (s *Server) RemoveResource(uri string) {
+	s.mu.Lock()
+	_, ok := s.resources[uri]
+	delete(s.resources, uri)
+	s.mu.Unlock()
+	if ok {
+		s.notifyResourcesListChanged()
+	}
+}
+
+// AddResourceTemplate 添加资源模板，并通知已连接的客户端资源列表已变化
+//
+// 读取资源时先匹配静态资源，再按添加顺序匹配模板；相同 URITemplate 的模板会被替换。
+func (s *Server) AddResourceTemplate(tmpl ServerResourceTemplate) error {
+	if tmpl.Handler == nil {
+		return fmt.Errorf("resource template %q has no handler", tmpl.URITemplate)
+	}
+	compiled, err := compileResourceTemplate(tmpl)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	replaced := false
+	for i, t := range s.templates {
+		if t.URITemplate == tmpl.URITemplate {
+			s.templates[i] = compiled
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		s.templates = append(s.templates, compiled)
+	}
+	s.mu.Unlock()
+	s.notifyResourcesListChanged()
+	return nil
 }
 
-// AddPrompt 添加提示词
+// AddPrompt 添加提示词，并通知已连接的客户端提示词列表已变化
+//
+// prompts/get 请求缺少 Arguments 中标记为 Required 的参数时返回参数错误，不调用 Handler。
 func (s *Server) AddPrompt(prompt ServerPrompt) {
 	s.mu.Lock()
-	defer s.mu.Unlock()
 	s.prompts[prompt.Name] = &prompt
+	s.mu.Unlock()
+	s.notify(NotificationPromptsListChanged, nil, nil)
+}
+
+// RemovePrompt 移除提示词，并通知已连接的客户端提示词列表已变化
+func (s *Server) RemovePrompt(name string) {
+	s.mu.Lock()
+	_, ok := s.prompts[name]
+	delete(s.prompts, name)
+	s.mu.Unlock()
+	if ok {
+		s.notify(NotificationPromptsListChanged, nil, nil)
+	}
 }
 
 // Run 运行服务器（Stdio 模式）
 //
-// 从 reader 读取请求，将响应写入 writer。
+// 从 reader 读取请求，将响应和服务器通知写入 writer。
 // 如果 reader 和 writer 为 nil，则使用 os.Stdin 和 os.Stdout。
 func (s *Server) Run(ctx context.Context, reader io.Reader, writer io.Writer) error {
 	if reader == nil {
@@ -132,10 +197,32 @@ func (s *Server) Run(ctx context.Context, reader io.Reader, writer io.Writer) er
 	scanner := bufio.NewScanner(reader)
 	scanner.Buffer(make([]byte, 1024*1024), 10*1024*1024) // 10MB max line size
 
+	// 响应和通知可能并发写入，按行加锁
+	var writeMu sync.Mutex
+	writeLine := func(data []byte) error {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		_, err := fmt.Fprintf(writer, "%s\n", data)
+		return err
+	}
+	session := s.openSession(writeLine)
+	defer s.closeSession(session)
+	ctx = withServerSession(ctx, session)
+
+	// 返回前等待进行中的请求结束
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	writeErrs := make(chan error, 1)
+	// prev 在上一个请求的响应写出后关闭
+	prev := make(chan struct{})
+	close(prev)
+
 	for {
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
+		case err := <-writeErrs:
+			return fmt.Errorf("failed to write response: %w", err)
 		default:
 		}
 
@@ -151,24 +238,62 @@ func (s *Server) Run(ctx context.Context, reader io.Reader, writer io.Writer) er
 			continue
 		}
 
-		response := s.handleRequest(ctx, line)
-		if response != nil {
+		// 客户端对服务器请求的响应交给等待中的请求
+		if isClientResponse(line) {
+			session.deliver(append([]byte(nil), line...))
+			continue
+		}
+
+		// 通知（包括取消通知）就地处理；请求并发处理以便长时间运行的请求可以被取消，
+		// 响应仍按请求的顺序写出
+		run := s.prepareRequest(ctx, line)
+		if run == nil {
+			continue
+		}
+
+		next := make(chan struct{})
+		wg.Add(1)
+		go func(prev, next chan struct{}) {
+			defer wg.Done()
+			defer close(next)
+			response := run()
+			<-prev
+			if response == nil {
+				return
+			}
 			responseBytes, err := json.Marshal(response)
 			if err != nil {
-				continue
+				return
 			}
-			if _, err := fmt.Fprintf(writer, "%s\n", responseBytes); err != nil {
-				return fmt.Errorf("failed to write response: %w", err)
+			if err := writeLine(responseBytes); err != nil {
+				select {
+				case writeErrs <- err:
+				default:
+				}
 			}
-		}
+		}(prev, next)
+		prev = next
 	}
 }
 
 // handleRequest 处理单个请求
 func (s *Server) handleRequest(ctx context.Context, data []byte) *JSONRPCResponse {
+	if run := s.prepareRequest(ctx, data); run != nil {
+		return run()
+	}
+	return nil
+}
+
+// prepareRequest 解析消息并就地处理通知（返回 nil）；请求在返回前登记到会话，
+// 返回的函数执行请求并返回响应
+//
+// 登记与执行分开，使并发执行请求时，随后到达的取消通知一定能找到该请求。
+func (s *Server) prepareRequest(ctx context.Context, data []byte) func() *JSONRPCResponse {
 	var req JSONRPCRequest
 	if err := json.Unmarshal(data, &req); err != nil {
-		return s.errorResponse(nil, -32700, "Parse error", err.Error())
+		return func() *JSONRPCResponse {
+			return s.errorResponse(nil, -32700, "Parse error", err.Error())
+		}
 	}
 
 	// 如果是通知（没有 ID），不返回响应
@@ -177,15 +302,88 @@ func (s *Server) handleRequest(ctx context.Context, data []byte) *JSONRPCRespons
 		return nil
 	}
 
-	return s.handleCall(ctx, &req)
+	ctx, done, cancelled := s.beginRequest(ctx, &req)
+	return func() *JSONRPCResponse {
+		defer done()
+		response := s.dispatch(ctx, &req)
+		// 被客户端取消的请求不再返回响应
+		if cancelled() {
+			return nil
+		}
+		return response
+	}
+}
+
+// handleMessages 依次处理一组消息，返回请求的响应（通知和客户端发来的响应没有返回值）
+func (s *Server) handleMessages(ctx context.Context, messages []json.RawMessage) []*JSONRPCResponse {
+	var responses []*JSONRPCResponse
+	for _, msg := range messages {
+		// 客户端对服务器请求（如 roots/list）的响应
+		if isClientResponse(msg) {
+			if session := serverSessionFrom(ctx); session != nil {
+				session.deliver(msg)
+			}
+			continue
+		}
+		if resp := s.handleRequest(ctx, msg); resp != nil {
+			responses = append(responses, resp)
+		}
+	}
+	return responses
+}
+
+// splitMessages 解析单条或批量的 JSON-RPC 消息，返回消息列表以及是否为批量
+func splitMessages(data []byte) ([]json.RawMessage, bool, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '[' {
+		var messages []json.RawMessage
+		if err := json.Unmarshal(data, &messages); err != nil || len(messages) == 0 {
+			return nil, true, fmt.Errorf("invalid batch")
+		}
+		return messages, true, nil
+	}
+	if !json.Valid(data) {
+		return nil, false, fmt.Errorf("invalid JSON")
+	}
+	return []json.RawMessage{data}, false, nil
+}
+
+// isClientResponse 消息是否为 JSON-RPC 响应（有 result 或 error，没有 method）
+func isClientResponse(msg json.RawMessage) bool {
+	var probe struct {
+		Method string          `json:"method"`
+		Result json.RawMessage `json:"result"`
+		Error  json.RawMessage `json:"error"`
+	}
+	if json.Unmarshal(msg, &probe) != nil {
+		return false
+	}
+	return probe.Method == "" && (probe.Result != nil || probe.Error != nil)
+}
+
+// responsePayload 批量请求返回响应数组，否则返回单个响应
+func responsePayload(responses []*JSONRPCResponse, batch bool) interface{} {
+	if batch {
+		return responses
+	}
+	return responses[0]
 }
 
 // handleNotification 处理通知
-func (s *Server) handleNotification(_ context.Context, req *JSONRPCRequest) {
-	// 目前只处理 initialized 通知
+func (s *Server) handleNotification(ctx context.Context, req *JSONRPCRequest) {
 	switch req.Method {
 	case MethodInitialized:
 		// 客户端已初始化，可以开始处理请求
+	case NotificationCancelled, MethodCancelRequest:
+		s.handleCancel(ctx, req)
+	case NotificationRootsListChanged:
+		s.mu.RLock()
+		handler := s.rootsChanged
+		s.mu.RUnlock()
+		// 回调中可能向客户端请求根目录，不能阻塞消息的读取
+		if handler != nil {
+			go handler(context.WithoutCancel(ctx))
+		}
 	}
 }
 
@@ -202,6 +400,12 @@ func (s *Server) handleCall(ctx context.Context, req *JSONRPCRequest) *JSONRPCRe
 		return s.handleListResources(ctx, req)
 	case MethodReadResource:
 		return s.handleReadResource(ctx, req)
+	case MethodListResourceTemplates:
+		return s.handleListResourceTemplates(ctx, req)
+	case MethodSubscribeResource:
+		return s.handleSubscribeResource(ctx, req)
+	case MethodUnsubscribeResource:
+		return s.handleUnsubscribeResource(ctx, req)
 	case MethodListPrompts:
 		return s.handleListPrompts(ctx, req)
 	case MethodGetPrompt:
@@ -214,13 +418,20 @@ func (s *Server) handleCall(ctx context.Context, req *JSONRPCRequest) *JSONRPCRe
 }
 
 // handleInitialize 处理初始化请求
-func (s *Server) handleInitialize(_ context.Context, req *JSONRPCRequest) *JSONRPCResponse {
+func (s *Server) handleInitialize(ctx context.Context, req *JSONRPCRequest) *JSONRPCResponse {
+	var params InitializeParams
+	if len(req.Params) > 0 && json.Unmarshal(req.Params, &params) == nil {
+		if session := serverSessionFrom(ctx); session != nil {
+			session.setClientCapabilities(params.Capabilities)
+		}
+	}
+
 	result := InitializeResult{
 		ProtocolVersion: MCPVersion,
 		Capabilities: Capabilities{
 			Tools:     &ToolsCapability{ListChanged: false},
-			Resources: &ResourcesCapability{ListChanged: false},
-			Prompts:   &PromptsCapability{ListChanged: false},
+			Resources: &ResourcesCapability{Subscribe: true, ListChanged: true},
+			Prompts:   &PromptsCapability{ListChanged: true},
 		},
 		ServerInfo: Implementation{
 			Name:    s.name,
@@ -307,24 +518,106 @@ func (s *Server) handleReadResource(ctx context.Context, req *JSONRPCRequest) *J
 	resource, ok := s.resources[params.URI]
 	s.mu.RUnlock()
 
-	if !ok {
-		return s.errorResponse(req.ID, -32602, "Resource not found", params.URI)
+	var content, mimeType string
+	var err error
+	if ok {
+		mimeType = resource.MimeType
+		content, err = resource.Handler(ctx)
+	} else {
+		tmpl, vars, found := s.matchTemplate(params.URI)
+		if !found {
+			return s.errorResponse(req.ID, -32602, "Resource not found", params.URI)
+		}
+		mimeType = tmpl.MimeType
+		content, err = tmpl.Handler(ctx, params.URI, vars)
 	}
-
-	content, err := resource.Handler(ctx)
 	if err != nil {
 		return s.errorResponse(req.ID, -32603, "Internal error", err.Error())
 	}
 
 	return s.successResponse(req.ID, ReadResourceResult{
 		Contents: []ResourceContent{{
-			URI:      resource.URI,
-			MimeType: resource.MimeType,
+			URI:      params.URI,
+			MimeType: mimeType,
 			Text:     content,
 		}},
 	})
 }
 
+// handleListResourceTemplates 处理列出资源模板请求
+func (s *Server) handleListResourceTemplates(_ context.Context, req *JSONRPCRequest) *JSONRPCResponse {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	templates := make([]ResourceTemplate, 0, len(s.templates))
+	for _, t := range s.templates {
+		templates = append(templates, ResourceTemplate{
+			URITemplate: t.URITemplate,
+			Name:        t.Name,
+			Description: t.Description,
+			MimeType:    t.MimeType,
+		})
+	}
+
+	return s.successResponse(req.ID, ListResourceTemplatesResult{ResourceTemplates: templates})
+}
+
+// handleSubscribeResource 处理订阅资源请求
+func (s *Server) handleSubscribeResource(ctx context.Context, req *JSONRPCRequest) *JSONRPCResponse {
+	var params SubscribeResourceParams
+	if err := json.Unmarshal(req.Params, &params); err != nil {
+		return s.errorResponse(req.ID, -32602, "Invalid params", err.Error())
+	}
+
+	session := serverSessionFrom(ctx)
+	if session == nil {
+		return s.errorResponse(req.ID, -32603, "Internal error", "transport does not support notifications")
+	}
+	if !s.hasResource(params.URI) {
+		return s.errorResponse(req.ID, -32602, "Resource not found", params.URI)
+	}
+
+	session.subscribe(params.URI)
+	return s.successResponse(req.ID, map[string]interface{}{})
+}
+
+// handleUnsubscribeResource 处理取消订阅资源请求
+func (s *Server) handleUnsubscribeResource(ctx context.Context, req *JSONRPCRequest) *JSONRPCResponse {
+	var params SubscribeResourceParams
+	if err := json.Unmarshal(req.Params, &params); err != nil {
+		return s.errorResponse(req.ID, -32602, "Invalid params", err.Error())
+	}
+
+	if session := serverSessionFrom(ctx); session != nil {
+		session.unsubscribe(params.URI)
+	}
+	return s.successResponse(req.ID, map[string]interface{}{})
+}
+
+// matchTemplate 按添加顺序查找匹配 URI 的资源模板
+func (s *Server) matchTemplate(uri string) (*resourceTemplate, map[string]string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, t := range s.templates {
+		if vars, ok := t.match(uri); ok {
+			return t, vars, true
+		}
+	}
+	return nil, nil, false
+}
+
+// hasResource 是否存在 URI 对应的静态资源或匹配的资源模板
+func (s *Server) hasResource(uri string) bool {
+	s.mu.RLock()
+	_, ok := s.resources[uri]
+	s.mu.RUnlock()
+	if ok {
+		return true
+	}
+	_, _, ok = s.matchTemplate(uri)
+	return ok
+}
+
 // handleListPrompts 处理列出提示词请求
 func (s *Server) handleListPrompts(_ context.Context, req *JSONRPCRequest) *JSONRPCResponse {
 	s.mu.RLock()
@@ -338,6 +631,7 @@ func (s *Server) handleListPrompts(_ context.Context, req *JSONRPCRequest) *JSON
 			Arguments:   p.Arguments,
 		})
 	}
+	sort.Slice(prompts, func(i, j int) bool { return prompts[i].Name < prompts[j].Name })
 
 	return s.successResponse(req.ID, ListPromptsResult{Prompts: prompts})
 }
@@ -356,6 +650,11 @@ func (s *Server) handleGetPrompt(ctx context.Context, req *JSONRPCRequest) *JSON
 	if !ok {
 		return s.errorResponse(req.ID, -32602, "Prompt not found", params.Name)
 	}
+	for _, arg := range prompt.Arguments {
+		if _, ok := params.Arguments[arg.Name]; arg.Required && !ok {
+			return s.errorResponse(req.ID, -32602, "Missing required argument", arg.Name)
+		}
+	}
 
 	messages, err := prompt.Handler(ctx, params.Arguments)
 	if err != nil {
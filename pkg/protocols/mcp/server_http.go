@@ -0,0 +1,626 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// HTTP 传输默认参数
+const (
+	// DefaultHTTPEndpoint Streamable HTTP 端点路径
+	DefaultHTTPEndpoint = "/mcp"
+	// DefaultSSEEndpoint 旧版 HTTP+SSE 传输的事件流路径
+	DefaultSSEEndpoint = "/sse"
+	// DefaultSSEMessageEndpoint 旧版 HTTP+SSE 传输的消息提交路径
+	DefaultSSEMessageEndpoint = "/message"
+	// DefaultSessionTimeout 会话空闲超时
+	DefaultSessionTimeout = 30 * time.Minute
+)
+
+// HeaderSessionID Streamable HTTP 会话 ID 头
+const HeaderSessionID = "Mcp-Session-Id"
+
+// maxHTTPBodySize 请求体大小上限，与 Stdio 模式的最大行长度一致
+const maxHTTPBodySize = 10 * 1024 * 1024
+
+// errSessionClosed 会话已关闭
+var errSessionClosed = errors.New("session closed")
+
+// errOutboxFull 会话的待推送队列已满
+var errOutboxFull = errors.New("session outbox full")
+
+// HTTPServerOption HTTP 传输选项
+type HTTPServerOption func(*HTTPHandler)
+
+// WithHTTPEndpoint 设置 Streamable HTTP 端点路径（默认 DefaultHTTPEndpoint）
+func WithHTTPEndpoint(path string) HTTPServerOption {
+	return func(h *HTTPHandler) {
+		if path != "" {
+			h.endpoint = path
+		}
+	}
+}
+
+// WithSSEEndpoints 设置旧版 HTTP+SSE 传输的事件流和消息提交路径，sse 为空时禁用旧版传输
+func WithSSEEndpoints(sse, message string) HTTPServerOption {
+	return func(h *HTTPHandler) {
+		h.sseEndpoint = sse
+		if message != "" {
+			h.messageEndpoint = message
+		}
+	}
+}
+
+// WithSessionTimeout 设置会话空闲超时（默认 DefaultSessionTimeout），有活跃事件流的会话不会过期
+func WithSessionTimeout(timeout time.Duration) HTTPServerOption {
+	return func(h *HTTPHandler) {
+		if timeout > 0 {
+			h.sessionTimeout = timeout
+		}
+	}
+}
+
+// WithAllowedOrigins 只接受来自指定 Origin 的浏览器请求，防止 DNS 重绑定攻击
+//
+// 未设置时只接受 Origin 和请求 Host 都是本机（localhost、回环地址）的浏览器请求；
+// 没有 Origin 头的请求（非浏览器客户端）始终接受。
+func WithAllowedOrigins(origins ...string) HTTPServerOption {
+	return func(h *HTTPHandler) {
+		if h.allowedOrigins == nil {
+			h.allowedOrigins = make(map[string]struct{}, len(origins))
+		}
+		for _, origin := range origins {
+			h.allowedOrigins[origin] = struct{}{}
+		}
+	}
+}
+
+// WithHTTPAuth 要求请求携带有效的 Bearer 令牌，参见 BearerAuth
+func WithHTTPAuth(validator TokenValidator, opts ...BearerAuthOption) HTTPServerOption {
+	return func(h *HTTPHandler) {
+		h.auth = BearerAuth(validator, opts...)
+	}
+}
+
+// HTTPHandler MCP 服务器的 HTTP 传输
+//
+// 实现 MCP Streamable HTTP 传输，并兼容旧版 HTTP+SSE 传输：
+//   - POST {endpoint}：提交 JSON-RPC 消息（支持批量），请求的响应以 application/json 返回，
+//     只有通知时返回 202。initialize 请求创建会话并在 Mcp-Session-Id 响应头中返回会话 ID，
+//     之后的请求必须携带该头，缺少时返回 400，会话不存在或已过期时返回 404
+//   - GET {endpoint}：打开会话的服务器消息事件流（text/event-stream）
+//   - DELETE {endpoint}：结束会话
+//   - GET {sse}：旧版传输，打开事件流并创建会话，首个 endpoint 事件给出消息提交地址
+//   - POST {message}?sessionId=...：旧版传输，提交消息后返回 202，响应通过事件流推送
+//
+// 可直接挂载到自定义的 http.ServeMux，或使用 Server.RunHTTP 启动独立的 HTTP 服务。
+type HTTPHandler struct {
+	server          *Server
+	endpoint        string
+	sseEndpoint     string
+	messageEndpoint string
+	sessionTimeout  time.Duration
+	allowedOrigins  map[string]struct{}
+	auth            func(http.Handler) http.Handler
+	handler         http.Handler
+
+	mu        sync.Mutex
+	sessions  map[string]*httpSession
+	lastSweep time.Time
+}
+
+// httpSession HTTP 会话
+type httpSession struct {
+	id       string
+	outbox   chan []byte
+	done     chan struct{}
+	once     sync.Once
+	lastSeen time.Time // 由 HTTPHandler.mu 保护
+	streams  int       // 活跃的事件流数量，由 HTTPHandler.mu 保护
+
+	mcp     *serverSession // 服务器通知经由 outbox 推送到事件流
+	release func()
+}
+
+// NewHTTPHandler 创建 MCP 服务器的 HTTP 传输
+func NewHTTPHandler(server *Server, opts ...HTTPServerOption) *HTTPHandler {
+	h := &HTTPHandler{
+		server:          server,
+		endpoint:        DefaultHTTPEndpoint,
+		sseEndpoint:     DefaultSSEEndpoint,
+		messageEndpoint: DefaultSSEMessageEndpoint,
+		sessionTimeout:  DefaultSessionTimeout,
+		sessions:        make(map[string]*httpSession),
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	h.handler = http.HandlerFunc(h.serve)
+	if h.auth != nil {
+		h.handler = h.auth(h.handler)
+	}
+	return h
+}
+
+// RunHTTP 运行服务器（HTTP 模式）
+//
+// 在 addr 上监听 Streamable HTTP 和旧版 HTTP+SSE 请求，ctx 取消时关闭全部会话并优雅退出。
+func (s *Server) RunHTTP(ctx context.Context, addr string, opts ...HTTPServerOption) error {
+	handler := NewHTTPHandler(s, opts...)
+	return serveHTTP(ctx, addr, handler, handler.Close)
+}
+
+// serveHTTP 在 addr 上运行 HTTP 服务直到 ctx 取消
+//
+// 长连接（事件流、WebSocket）不会自行结束，onShutdown 在关闭时调用以断开它们，否则 Shutdown 会一直等待。
+func serveHTTP(ctx context.Context, addr string, handler http.Handler, onShutdown func()) error {
+	httpServer := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	httpServer.RegisterOnShutdown(onShutdown)
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- httpServer.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return fmt.Errorf("http server failed: %w", err)
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := httpServer.Shutdown(shutdownCtx); err != nil {
+			return fmt.Errorf("failed to shut down http server: %w", err)
+		}
+		return ctx.Err()
+	}
+}
+
+// ServeHTTP 处理 HTTP 请求
+func (h *HTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.handler.ServeHTTP(w, r)
+}
+
+// serve 按路径和方法分发请求
+func (h *HTTPHandler) serve(w http.ResponseWriter, r *http.Request) {
+	if !h.originAllowed(r) {
+		http.Error(w, "Forbidden origin", http.StatusForbidden)
+		return
+	}
+
+	switch {
+	case r.URL.Path == h.endpoint:
+		switch r.Method {
+		case http.MethodPost:
+			h.handlePost(w, r)
+		case http.MethodGet:
+			h.handleStream(w, r)
+		case http.MethodDelete:
+			h.handleDelete(w, r)
+		default:
+			w.Header().Set("Allow", "GET, POST, DELETE")
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	case h.sseEndpoint != "" && r.URL.Path == h.sseEndpoint:
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", "GET")
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.handleSSE(w, r)
+	case h.sseEndpoint != "" && r.URL.Path == h.messageEndpoint:
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", "POST")
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.handleMessage(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// Close 关闭全部会话，结束所有事件流
+func (h *HTTPHandler) Close() {
+	h.mu.Lock()
+	sessions := h.sessions
+	h.sessions = make(map[string]*httpSession)
+	h.mu.Unlock()
+
+	for _, session := range sessions {
+		session.close()
+	}
+}
+
+// SessionCount 返回当前的会话数量
+func (h *HTTPHandler) SessionCount() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.sessions)
+}
+
+// handlePost 处理 Streamable HTTP 的消息提交
+func (h *HTTPHandler) handlePost(w http.ResponseWriter, r *http.Request) {
+	messages, batch, ok := h.readMessages(w, r)
+	if !ok {
+		return
+	}
+
+	var session *httpSession
+	if containsInitialize(messages) {
+		session = h.newSession()
+		w.Header().Set(HeaderSessionID, session.id)
+	} else if session, ok = h.requireSession(w, r.Header.Get(HeaderSessionID)); !ok {
+		return
+	}
+
+	// 客户端接受事件流时，带进度令牌的请求以事件流响应，进度通知随响应一起推送
+	if !batch && acceptsEventStream(r) && hasProgressToken(messages[0]) {
+		h.streamResponse(w, r, session, messages[0])
+		return
+	}
+
+	responses := h.server.handleMessages(withServerSession(r.Context(), session.mcp), messages)
+	if len(responses) == 0 {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+	writeJSON(w, http.StatusOK, responsePayload(responses, batch))
+}
+
+// streamResponse 以 text/event-stream 返回单个请求的响应，处理期间的进度通知先于响应推送
+func (h *HTTPHandler) streamResponse(w http.ResponseWriter, r *http.Request, session *httpSession, message json.RawMessage) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeJSON(w, http.StatusOK, h.server.handleRequest(withServerSession(r.Context(), session.mcp), message))
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	var mu sync.Mutex
+	send := func(data []byte) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if err := writeEvent(w, "message", data); err != nil {
+			return err
+		}
+		flusher.Flush()
+		return nil
+	}
+
+	ctx := withNotifier(withServerSession(r.Context(), session.mcp), send)
+	response := h.server.handleRequest(ctx, message)
+	if response == nil {
+		return
+	}
+	data, err := json.Marshal(response)
+	if err != nil {
+		return
+	}
+	_ = send(data)
+}
+
+// acceptsEventStream 客户端是否接受 text/event-stream 响应
+func acceptsEventStream(r *http.Request) bool {
+	for _, accept := range r.Header.Values("Accept") {
+		if strings.Contains(accept, "text/event-stream") {
+			return true
+		}
+	}
+	return false
+}
+
+// hasProgressToken 请求是否在 _meta 中携带进度令牌
+func hasProgressToken(msg json.RawMessage) bool {
+	var probe struct {
+		ID     json.RawMessage `json:"id"`
+		Params struct {
+			Meta *RequestMeta `json:"_meta"`
+		} `json:"params"`
+	}
+	if json.Unmarshal(msg, &probe) != nil {
+		return false
+	}
+	return probe.ID != nil && probe.Params.Meta != nil && probe.Params.Meta.ProgressToken != nil
+}
+
+// handleStream 打开 Streamable HTTP 会话的服务器消息事件流
+func (h *HTTPHandler) handleStream(w http.ResponseWriter, r *http.Request) {
+	session, ok := h.requireSession(w, r.Header.Get(HeaderSessionID))
+	if !ok {
+		return
+	}
+	h.stream(w, r, session, nil)
+}
+
+// handleDelete 结束 Streamable HTTP 会话
+func (h *HTTPHandler) handleDelete(w http.ResponseWriter, r *http.Request) {
+	session, ok := h.requireSession(w, r.Header.Get(HeaderSessionID))
+	if !ok {
+		return
+	}
+	h.removeSession(session)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleSSE 旧版传输：创建会话并打开事件流
+func (h *HTTPHandler) handleSSE(w http.ResponseWriter, r *http.Request) {
+	session := h.newSession()
+	defer h.removeSession(session)
+
+	endpoint := fmt.Sprintf("%s?sessionId=%s", h.messageEndpoint, session.id)
+	h.stream(w, r, session, func() error {
+		return writeEvent(w, "endpoint", []byte(endpoint))
+	})
+}
+
+// handleMessage 旧版传输：提交消息，响应通过会话的事件流推送
+func (h *HTTPHandler) handleMessage(w http.ResponseWriter, r *http.Request) {
+	session, ok := h.requireSession(w, r.URL.Query().Get("sessionId"))
+	if !ok {
+		return
+	}
+	messages, batch, ok := h.readMessages(w, r)
+	if !ok {
+		return
+	}
+
+	if responses := h.server.handleMessages(withServerSession(r.Context(), session.mcp), messages); len(responses) > 0 {
+		data, err := json.Marshal(responsePayload(responses, batch))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := session.send(r.Context(), data); err != nil {
+			http.Error(w, err.Error(), http.StatusGone)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// stream 以 text/event-stream 推送会话的消息，直到客户端断开或会话关闭
+//
+// first 在响应头写出后、推送消息前调用，用于发送初始事件。
+func (h *HTTPHandler) stream(w http.ResponseWriter, r *http.Request, session *httpSession, first func() error) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	h.mu.Lock()
+	session.streams++
+	h.mu.Unlock()
+	defer func() {
+		h.mu.Lock()
+		session.streams--
+		session.lastSeen = time.Now()
+		h.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	if first != nil {
+		if err := first(); err != nil {
+			return
+		}
+	}
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-session.done:
+			return
+		case data := <-session.outbox:
+			if err := writeEvent(w, "message", data); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// readMessages 读取请求体中的 JSON-RPC 消息，失败时写出错误响应
+func (h *HTTPHandler) readMessages(w http.ResponseWriter, r *http.Request) ([]json.RawMessage, bool, bool) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxHTTPBodySize))
+	if err != nil {
+		http.Error(w, "Failed to read request body", http.StatusRequestEntityTooLarge)
+		return nil, false, false
+	}
+
+	messages, batch, err := splitMessages(body)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, h.server.errorResponse(nil, -32700, "Parse error", err.Error()))
+		return nil, false, false
+	}
+	return messages, batch, true
+}
+
+// newSession 创建会话
+func (h *HTTPHandler) newSession() *httpSession {
+	session := &httpSession{
+		id:       uuid.NewString(),
+		outbox:   make(chan []byte, 64),
+		done:     make(chan struct{}),
+		lastSeen: time.Now(),
+	}
+	// 通知不阻塞：事件流未打开且队列已满时丢弃
+	session.mcp = h.server.openSession(func(data []byte) error {
+		select {
+		case <-session.done:
+			return errSessionClosed
+		default:
+		}
+		select {
+		case session.outbox <- data:
+			return nil
+		default:
+			return errOutboxFull
+		}
+	})
+	session.release = func() { h.server.closeSession(session.mcp) }
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.sweepLocked(session.lastSeen)
+	h.sessions[session.id] = session
+	return session
+}
+
+// requireSession 查找会话，缺少会话 ID 时写出 400，会话不存在或已过期时写出 404
+func (h *HTTPHandler) requireSession(w http.ResponseWriter, id string) (*httpSession, bool) {
+	if id == "" {
+		http.Error(w, "Missing session ID", http.StatusBadRequest)
+		return nil, false
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	now := time.Now()
+	h.sweepLocked(now)
+	session, ok := h.sessions[id]
+	if !ok {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return nil, false
+	}
+	session.lastSeen = now
+	return session, true
+}
+
+// removeSession 移除并关闭会话
+func (h *HTTPHandler) removeSession(session *httpSession) {
+	h.mu.Lock()
+	if h.sessions[session.id] == session {
+		delete(h.sessions, session.id)
+	}
+	h.mu.Unlock()
+	session.close()
+}
+
+// sweepLocked 关闭空闲超时的会话（调用方需持有锁），最多每分钟（或每个超时周期）执行一次
+func (h *HTTPHandler) sweepLocked(now time.Time) {
+	if now.Sub(h.lastSweep) < min(time.Minute, h.sessionTimeout) {
+		return
+	}
+	h.lastSweep = now
+	for id, session := range h.sessions {
+		if session.streams == 0 && now.Sub(session.lastSeen) > h.sessionTimeout {
+			delete(h.sessions, id)
+			session.close()
+		}
+	}
+}
+
+// originAllowed 校验浏览器请求的 Origin
+func (h *HTTPHandler) originAllowed(r *http.Request) bool {
+	return checkOrigin(r, h.allowedOrigins)
+}
+
+// checkOrigin 校验浏览器请求的 Origin，没有 Origin 头的请求（非浏览器客户端）始终接受
+//
+// 配置了允许列表时只接受列表中的 Origin；未配置时要求 Origin 和请求的 Host 都是本机地址。
+// DNS 重绑定时浏览器发送的 Host 与 Origin 都是攻击者的域名，因此不能以同源作为依据。
+func checkOrigin(r *http.Request, allowed map[string]struct{}) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	if len(allowed) > 0 {
+		_, ok := allowed[origin]
+		return ok
+	}
+
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	return isLoopbackHost(u.Hostname()) && isLoopbackHost(requestHostname(r.Host))
+}
+
+// requestHostname 去掉 Host 头中的端口
+func requestHostname(hostport string) string {
+	if host, _, err := net.SplitHostPort(hostport); err == nil {
+		return host
+	}
+	return strings.Trim(hostport, "[]")
+}
+
+// isLoopbackHost 判断主机名是否为 localhost 或回环地址
+func isLoopbackHost(host string) bool {
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// send 将消息放入会话的待推送队列
+func (s *httpSession) send(ctx context.Context, data []byte) error {
+	select {
+	case s.outbox <- data:
+		return nil
+	case <-s.done:
+		return errSessionClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// close 关闭会话
+func (s *httpSession) close() {
+	s.once.Do(func() {
+		close(s.done)
+		if s.release != nil {
+			s.release()
+		}
+	})
+}
+
+// containsInitialize 消息中是否包含 initialize 请求
+func containsInitialize(messages []json.RawMessage) bool {
+	for _, msg := range messages {
+		var probe struct {
+			Method string `json:"method"`
+		}
+		if json.Unmarshal(msg, &probe) == nil && probe.Method == MethodInitialize {
+			return true
+		}
+	}
+	return false
+}
+
+// writeJSON 写出 JSON 响应
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeEvent 写出一个 SSE 事件
+func writeEvent(w io.Writer, event string, data []byte) error {
+	_, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+	return err
+}
@@ -0,0 +1,220 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// serverSession 服务器与一个客户端之间的会话
+//
+// 由传输层在连接建立时通过 Server.openSession 创建，用于推送服务器通知、记录资源订阅
+// 和进行中的请求。处理请求时会话通过上下文传递给服务器。
+type serverSession struct {
+	send func(message []byte) error
+
+	mu            sync.Mutex
+	subscriptions map[string]struct{}
+	inflight      map[string]context.CancelCauseFunc
+	clientCaps    Capabilities
+	pending       map[string]chan *JSONRPCResponse
+	requestID     atomic.Int64
+}
+
+// serverSessionKey 上下文中会话的键
+type serverSessionKey struct{}
+
+// withServerSession 将会话放入上下文
+func withServerSession(ctx context.Context, session *serverSession) context.Context {
+	return context.WithValue(ctx, serverSessionKey{}, session)
+}
+
+// serverSessionFrom 从上下文中取出会话，没有时返回 nil
+func serverSessionFrom(ctx context.Context) *serverSession {
+	session, _ := ctx.Value(serverSessionKey{}).(*serverSession)
+	return session
+}
+
+// subscribe 订阅资源
+func (s *serverSession) subscribe(uri string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subscriptions[uri] = struct{}{}
+}
+
+// unsubscribe 取消订阅资源
+func (s *serverSession) unsubscribe(uri string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.subscriptions, uri)
+}
+
+// subscribed 是否订阅了资源
+func (s *serverSession) subscribed(uri string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.subscriptions[uri]
+	return ok
+}
+
+// track 登记进行中的请求
+func (s *serverSession) track(key string, cancel context.CancelCauseFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inflight[key] = cancel
+}
+
+// untrack 移除已结束的请求
+func (s *serverSession) untrack(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.inflight, key)
+}
+
+// cancelRequest 取消进行中的请求，请求不存在（如已结束）时忽略
+func (s *serverSession) cancelRequest(key string) {
+	s.mu.Lock()
+	cancel, ok := s.inflight[key]
+	s.mu.Unlock()
+	if ok {
+		cancel(errRequestCancelled)
+	}
+}
+
+// setClientCapabilities 记录客户端在初始化时声明的能力
+func (s *serverSession) setClientCapabilities(caps Capabilities) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.clientCaps = caps
+}
+
+// clientCapabilities 返回客户端声明的能力
+func (s *serverSession) clientCapabilities() Capabilities {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.clientCaps
+}
+
+// request 通过 send 向客户端发送请求并等待响应
+func (s *serverSession) request(ctx context.Context, send func(message []byte) error, method string, params interface{}) (json.RawMessage, error) {
+	id := fmt.Sprintf("srv-%d", s.requestID.Add(1))
+	request, err := NewRequest(id, method, params)
+	if err != nil {
+		return nil, err
+	}
+
+	key := requestIDKey(id)
+	ch := make(chan *JSONRPCResponse, 1)
+	s.mu.Lock()
+	s.pending[key] = ch
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.pending, key)
+		s.mu.Unlock()
+	}()
+
+	if err := send(request); err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case resp := <-ch:
+		if resp.Error != nil {
+			return nil, fmt.Errorf("RPC error %d: %s", resp.Error.Code, resp.Error.Message)
+		}
+		return resp.Result, nil
+	}
+}
+
+// deliver 将客户端的响应交给等待中的请求，没有对应请求的响应被丢弃
+func (s *serverSession) deliver(message []byte) {
+	var probe struct {
+		ID json.RawMessage `json:"id"`
+	}
+	resp, err := ParseResponse(message)
+	if err != nil || json.Unmarshal(message, &probe) != nil {
+		return
+	}
+	key := messageIDKey(probe.ID)
+	s.mu.Lock()
+	ch, ok := s.pending[key]
+	delete(s.pending, key)
+	s.mu.Unlock()
+	if ok {
+		ch <- resp
+	}
+}
+
+// openSession 注册会话，send 用于向客户端推送消息
+func (s *Server) openSession(send func(message []byte) error) *serverSession {
+	session := &serverSession{
+		send:          send,
+		subscriptions: make(map[string]struct{}),
+		inflight:      make(map[string]context.CancelCauseFunc),
+		pending:       make(map[string]chan *JSONRPCResponse),
+	}
+	s.sessionsMu.Lock()
+	defer s.sessionsMu.Unlock()
+	s.sessions[session] = struct{}{}
+	return session
+}
+
+// closeSession 注销会话
+func (s *Server) closeSession(session *serverSession) {
+	s.sessionsMu.Lock()
+	defer s.sessionsMu.Unlock()
+	delete(s.sessions, session)
+}
+
+// NotifyResourceUpdated 通知订阅了该资源的客户端资源内容已变化，返回通知的会话数量
+//
+// 客户端收到 notifications/resources/updated 后通常会重新读取资源。
+func (s *Server) NotifyResourceUpdated(uri string) int {
+	return s.notify(NotificationResourceUpdated, ResourceUpdatedParams{URI: uri}, func(session *serverSession) bool {
+		return session.subscribed(uri)
+	})
+}
+
+// notifyResourcesListChanged 通知所有客户端资源列表已变化
+func (s *Server) notifyResourcesListChanged() {
+	s.notify(NotificationResourcesListChanged, nil, nil)
+}
+
+// notify 向满足 filter 的会话（filter 为空时为全部会话）推送通知，返回成功推送的数量
+//
+// 推送失败（如连接已断开、没有打开的事件流）的会话被跳过，通知不会重发。
+func (s *Server) notify(method string, params interface{}, filter func(*serverSession) bool) int {
+	s.sessionsMu.Lock()
+	targets := make([]*serverSession, 0, len(s.sessions))
+	for session := range s.sessions {
+		if filter == nil || filter(session) {
+			targets = append(targets, session)
+		}
+	}
+	s.sessionsMu.Unlock()
+	if len(targets) == 0 {
+		return 0
+	}
+
+	notification := JSONRPCNotification{JSONRPC: JSONRPCVersion, Method: method}
+	if params != nil {
+		notification.Params, _ = json.Marshal(params)
+	}
+	data, err := json.Marshal(notification)
+	if err != nil {
+		return 0
+	}
+
+	sent := 0
+	for _, session := range targets {
+		if session.send(data) == nil {
+			sent++
+		}
+	}
+	return sent
+}
@@ -0,0 +1,183 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// WebSocketServerOption WebSocket 传输选项
+type WebSocketServerOption func(*WebSocketHandler)
+
+// WithWebSocketKeepAlive 设置 ping 间隔和等待响应的时间（默认 DefaultWebSocketPingInterval、
+// DefaultWebSocketPongTimeout），interval 小于 0 时不发送 ping
+func WithWebSocketKeepAlive(interval, timeout time.Duration) WebSocketServerOption {
+	return func(h *WebSocketHandler) {
+		h.pingInterval = interval
+		if timeout > 0 {
+			h.pongTimeout = timeout
+		}
+	}
+}
+
+// WithWebSocketAllowedOrigins 只接受来自指定 Origin 的浏览器连接
+//
+// 未设置时只接受 Origin 和请求 Host 都是本机（localhost、回环地址）的浏览器连接；
+// 没有 Origin 头的连接（非浏览器客户端）始终接受。
+func WithWebSocketAllowedOrigins(origins ...string) WebSocketServerOption {
+	return func(h *WebSocketHandler) {
+		if h.allowedOrigins == nil {
+			h.allowedOrigins = make(map[string]struct{}, len(origins))
+		}
+		for _, origin := range origins {
+			h.allowedOrigins[origin] = struct{}{}
+		}
+	}
+}
+
+// WithWebSocketAuth 要求握手请求携带有效的 Bearer 令牌，参见 BearerAuth
+func WithWebSocketAuth(validator TokenValidator, opts ...BearerAuthOption) WebSocketServerOption {
+	return func(h *WebSocketHandler) {
+		h.auth = BearerAuth(validator, opts...)
+	}
+}
+
+// WebSocketHandler MCP 服务器的 WebSocket 传输
+//
+// 每个 WebSocket 连接是一个独立的会话，一条 WebSocket 消息承载一条或一批 JSON-RPC 消息，
+// 服务器通知直接在连接上推送。
+// 请求并发处理，响应在处理完成后发送（顺序可能与请求不同，客户端按 ID 匹配）。
+// 服务器定期发送 ping，超时没有收到任何数据的连接会被关闭。
+type WebSocketHandler struct {
+	server         *Server
+	pingInterval   time.Duration
+	pongTimeout    time.Duration
+	allowedOrigins map[string]struct{}
+	auth           func(http.Handler) http.Handler
+	handler        http.Handler
+
+	mu    sync.Mutex
+	conns map[*wsConn]struct{}
+}
+
+// NewWebSocketHandler 创建 MCP 服务器的 WebSocket 传输
+func NewWebSocketHandler(server *Server, opts ...WebSocketServerOption) *WebSocketHandler {
+	h := &WebSocketHandler{
+		server:       server,
+		pingInterval: DefaultWebSocketPingInterval,
+		pongTimeout:  DefaultWebSocketPongTimeout,
+		conns:        make(map[*wsConn]struct{}),
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	h.handler = http.HandlerFunc(h.serve)
+	if h.auth != nil {
+		h.handler = h.auth(h.handler)
+	}
+	return h
+}
+
+// RunWebSocket 运行服务器（WebSocket 模式）
+//
+// 在 addr 上接受 WebSocket 连接（任意路径），ctx 取消时关闭全部连接并优雅退出。
+func (s *Server) RunWebSocket(ctx context.Context, addr string, opts ...WebSocketServerOption) error {
+	handler := NewWebSocketHandler(s, opts...)
+	return serveHTTP(ctx, addr, handler, handler.Close)
+}
+
+// ServeHTTP 升级为 WebSocket 连接并处理消息，直到连接断开
+func (h *WebSocketHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.handler.ServeHTTP(w, r)
+}
+
+// serve 升级连接并循环读取消息
+func (h *WebSocketHandler) serve(w http.ResponseWriter, r *http.Request) {
+	// 浏览器不对 WebSocket 握手执行 CORS，必须在服务端校验 Origin
+	if !checkOrigin(r, h.allowedOrigins) {
+		http.Error(w, "Forbidden origin", http.StatusForbidden)
+		return
+	}
+
+	conn, err := upgradeWebSocket(w, r)
+	if err != nil {
+		return
+	}
+	h.mu.Lock()
+	h.conns[conn] = struct{}{}
+	h.mu.Unlock()
+	defer func() {
+		h.mu.Lock()
+		delete(h.conns, conn)
+		h.mu.Unlock()
+		conn.close()
+	}()
+	go conn.keepalive(h.pingInterval, h.pongTimeout)
+
+	// 连接断开时取消正在执行的请求，等待它们结束后再关闭连接
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	// 保留握手请求上下文中的值（如 TokenInfo），但不随请求结束而取消
+	ctx, cancel := context.WithCancel(context.WithoutCancel(r.Context()))
+	defer cancel()
+
+	session := h.server.openSession(conn.writeMessage)
+	defer h.server.closeSession(session)
+	ctx = withServerSession(ctx, session)
+
+	for {
+		message, err := conn.readMessage()
+		if err != nil {
+			return
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			h.handleMessage(ctx, conn, message)
+		}()
+	}
+}
+
+// handleMessage 处理一条 WebSocket 消息并发送响应
+func (h *WebSocketHandler) handleMessage(ctx context.Context, conn *wsConn, message []byte) {
+	var payload interface{}
+	messages, batch, err := splitMessages(message)
+	if err != nil {
+		payload = h.server.errorResponse(nil, -32700, "Parse error", err.Error())
+	} else if responses := h.server.handleMessages(ctx, messages); len(responses) > 0 {
+		payload = responsePayload(responses, batch)
+	} else {
+		return
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return
+	}
+	if err := conn.writeMessage(data); err != nil {
+		conn.closeNow()
+	}
+}
+
+// Close 关闭全部连接
+func (h *WebSocketHandler) Close() {
+	h.mu.Lock()
+	conns := make([]*wsConn, 0, len(h.conns))
+	for conn := range h.conns {
+		conns = append(conns, conn)
+	}
+	h.mu.Unlock()
+
+	for _, conn := range conns {
+		conn.close()
+	}
+}
+
+// ConnectionCount 返回当前的连接数量
+func (h *WebSocketHandler) ConnectionCount() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.conns)
+}
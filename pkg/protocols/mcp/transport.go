@@ -10,8 +10,12 @@ import (
 	"net/http"
 	"os"
 	"os/exec"
+	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
+
+	"github.com/ahhsitt/helloagents-go/pkg/core/errors"
 )
 
 // Transport 传输层接口
@@ -29,12 +33,17 @@ type Transport interface {
 //
 // 通过启动子进程，使用标准输入/输出与 MCP 服务器通信。
 // 这是最常见的本地 MCP 服务器连接方式。
+// 后台 goroutine 持续读取子进程输出，按请求 ID 将响应匹配到对应的 Send 调用，多个 Send 可以并发。
 type StdioTransport struct {
+	messageHandlers
 	cmd       *exec.Cmd
 	stdin     io.WriteCloser
 	stdout    io.ReadCloser
-	scanner   *bufio.Scanner
+	writeMu   sync.Mutex
 	mu        sync.Mutex
+	pending   map[string]chan []byte
+	readErr   error // 读取结束的原因
+	done      chan struct{}
 	closed    atomic.Bool
 	closeOnce sync.Once
 }
@@ -49,6 +58,8 @@ type StdioTransportConfig struct {
 	Env map[string]string
 	// Dir 工作目录
 	Dir string
+	// OnMessage 服务器主动发送的消息（通知和请求）的回调，在读取 goroutine 中调用
+	OnMessage func(message []byte)
 }
 
 // NewStdioTransport 创建 Stdio 传输
@@ -86,57 +97,133 @@ func NewStdioTransport(config StdioTransportConfig) (*StdioTransport, error) {
 		return nil, fmt.Errorf("failed to start process: %w", err)
 	}
 
-	scanner := bufio.NewScanner(stdout)
-	scanner.Buffer(make([]byte, 1024*1024), 10*1024*1024) // 10MB max line size
-
-	return &StdioTransport{
-		cmd:     cmd,
-		stdin:   stdin,
-		stdout:  stdout,
-		scanner: scanner,
-	}, nil
+	t := &StdioTransport{
+		messageHandlers: messageHandlers{user: config.OnMessage},
+		cmd:             cmd,
+		stdin:           stdin,
+		stdout:          stdout,
+		pending:         make(map[string]chan []byte),
+		done:            make(chan struct{}),
+	}
+	go t.readLoop()
+	return t, nil
 }
 
-// Send 发送请求并返回响应
+// Send 发送请求并返回响应；通知和对服务器请求的响应发送后立即返回 nil
 func (t *StdioTransport) Send(ctx context.Context, request []byte) ([]byte, error) {
-	t.mu.Lock()
-	defer t.mu.Unlock()
-
 	if t.closed.Load() {
 		return nil, fmt.Errorf("transport is closed")
 	}
 
+	var probe struct {
+		ID json.RawMessage `json:"id"`
+	}
+	if err := json.Unmarshal(request, &probe); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+	key := messageIDKey(probe.ID)
+	if isClientResponse(request) {
+		key = "" // 对服务器请求的响应，不等待回复
+	}
+
+	var ch chan []byte
+	if key != "" {
+		t.mu.Lock()
+		if t.readErr != nil {
+			err := t.readErr
+			t.mu.Unlock()
+			return nil, err
+		}
+		ch = make(chan []byte, 1)
+		t.pending[key] = ch
+		t.mu.Unlock()
+		defer t.forget(key, ch)
+	}
+
 	// 写入请求（以换行符结尾）
-	if _, err := t.stdin.Write(append(request, '\n')); err != nil {
+	t.writeMu.Lock()
+	_, err := t.stdin.Write(append(request, '\n'))
+	t.writeMu.Unlock()
+	if err != nil {
 		return nil, fmt.Errorf("failed to write request: %w", err)
 	}
+	if ch == nil {
+		return nil, nil
+	}
 
-	// 在上下文中读取响应
-	responseCh := make(chan []byte, 1)
-	errCh := make(chan error, 1)
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case response, ok := <-ch:
+		if !ok {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			return nil, t.readErr
+		}
+		return response, nil
+	}
+}
+
+// readLoop 读取子进程输出，响应交给等待中的请求，其余消息交给 OnMessage；
+// 输出结束后让等待中的请求失败
+func (t *StdioTransport) readLoop() {
+	scanner := bufio.NewScanner(t.stdout)
+	scanner.Buffer(make([]byte, 1024*1024), 10*1024*1024) // 10MB max line size
 
-	go func() {
-		if t.scanner.Scan() {
-			responseCh <- t.scanner.Bytes()
-		} else {
-			if err := t.scanner.Err(); err != nil {
-				errCh <- fmt.Errorf("failed to read response: %w", err)
-			} else {
-				errCh <- fmt.Errorf("unexpected end of output")
+	for scanner.Scan() {
+		// 复制数据，因为 scanner 的缓冲区会被重用
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		message := make([]byte, len(line))
+		copy(message, line)
+		t.deliver(message)
+	}
+
+	err := fmt.Errorf("unexpected end of output")
+	if scanErr := scanner.Err(); scanErr != nil {
+		err = fmt.Errorf("failed to read response: %w", scanErr)
+	}
+
+	t.mu.Lock()
+	t.readErr = err
+	pending := t.pending
+	t.pending = make(map[string]chan []byte)
+	t.mu.Unlock()
+	for _, ch := range pending {
+		close(ch)
+	}
+	close(t.done)
+}
+
+// deliver 将响应交给等待中的请求，其余消息交给 OnMessage
+func (t *StdioTransport) deliver(message []byte) {
+	if isClientResponse(message) {
+		var probe struct {
+			ID json.RawMessage `json:"id"`
+		}
+		if json.Unmarshal(message, &probe) == nil {
+			key := messageIDKey(probe.ID)
+			t.mu.Lock()
+			ch, ok := t.pending[key]
+			delete(t.pending, key)
+			t.mu.Unlock()
+			if ok {
+				ch <- message
+				return
 			}
 		}
-	}()
+	}
+	t.dispatch(message)
+}
 
-	select {
-	case <-ctx.Done():
-		return nil, ctx.Err()
-	case err := <-errCh:
-		return nil, err
-	case response := <-responseCh:
-		// 复制响应数据，因为 scanner 的缓冲区会被重用
-		result := make([]byte, len(response))
-		copy(result, response)
-		return result, nil
+// forget 移除等待中的请求
+func (t *StdioTransport) forget(key string, ch chan []byte) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.pending[key] == ch {
+		delete(t.pending, key)
 	}
 }
 
@@ -152,7 +239,8 @@ func (t *StdioTransport) Close() error {
 			closeErr = fmt.Errorf("failed to close stdin: %w", err)
 		}
 
-		// 等待进程结束
+		// 读完剩余输出后再等待进程结束，Wait 会关闭 stdout
+		<-t.done
 		if err := t.cmd.Wait(); err != nil {
 			// 进程可能因为 stdin 关闭而正常退出
 			// 只有非预期的错误才需要报告
@@ -165,13 +253,51 @@ func (t *StdioTransport) Close() error {
 	return closeErr
 }
 
+// messageHandlers 服务器主动发送的消息（通知和请求）的回调
+//
+// 由读取服务器消息的传输嵌入：client 是 Client 内部的处理函数（如分发进度通知），
+// user 是传输配置中的 OnMessage，两者都会被调用。
+type messageHandlers struct {
+	client atomic.Pointer[func(message []byte)]
+	user   func(message []byte)
+}
+
+// setNotificationHandler 设置 Client 内部的消息处理函数
+func (h *messageHandlers) setNotificationHandler(handler func(message []byte)) {
+	h.client.Store(&handler)
+}
+
+// dispatch 将服务器主动发送的消息交给回调
+func (h *messageHandlers) dispatch(message []byte) {
+	if handler := h.client.Load(); handler != nil {
+		(*handler)(message)
+	}
+	if h.user != nil {
+		h.user(message)
+	}
+}
+
+// notificationSource 能把服务器主动发送的消息交给 Client 的传输
+type notificationSource interface {
+	setNotificationHandler(handler func(message []byte))
+}
+
 // HTTPTransport HTTP 传输
 //
-// 通过 HTTP POST 请求与远程 MCP 服务器通信。
+// 通过 HTTP POST 请求与远程 MCP 服务器通信，兼容 Streamable HTTP 传输：
+// 保存服务器在 Mcp-Session-Id 响应头中返回的会话 ID 并在之后的请求中携带，
+// 支持 application/json 和 text/event-stream 两种响应，通知返回 202 时视为成功。
+// 配置 TokenSource 时每次请求携带 Authorization: Bearer 头，服务器返回 401 时
+// 作废令牌（TokenSource 实现 TokenInvalidator 时）并用新令牌重试一次。
 type HTTPTransport struct {
-	url     string
-	client  *http.Client
-	headers map[string]string
+	messageHandlers
+	url         string
+	client      *http.Client
+	headers     map[string]string
+	tokenSource TokenSource
+
+	mu        sync.Mutex
+	sessionID string
 }
 
 // HTTPTransportConfig HTTP 传输配置
@@ -182,6 +308,8 @@ type HTTPTransportConfig struct {
 	Headers map[string]string
 	// Client 自定义 HTTP 客户端（可选）
 	Client *http.Client
+	// TokenSource 访问令牌来源（可选），如 StaticTokenSource 或 OAuthClient
+	TokenSource TokenSource
 }
 
 // NewHTTPTransport 创建 HTTP 传输
@@ -192,45 +320,184 @@ func NewHTTPTransport(config HTTPTransportConfig) *HTTPTransport {
 	}
 
 	return &HTTPTransport{
-		url:     config.URL,
-		client:  client,
-		headers: config.Headers,
+		url:         config.URL,
+		client:      client,
+		headers:     config.Headers,
+		tokenSource: config.TokenSource,
 	}
 }
 
 // Send 发送 HTTP 请求
+//
+// 通知没有响应，服务器返回 202 时返回 nil。
 func (t *HTTPTransport) Send(ctx context.Context, request []byte) ([]byte, error) {
+	resp, err := t.post(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized && t.tokenSource != nil {
+		if invalidator, ok := t.tokenSource.(TokenInvalidator); ok {
+			resp.Body.Close()
+			invalidator.Invalidate()
+			if resp, err = t.post(ctx, request); err != nil {
+				return nil, err
+			}
+		}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+		body, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode == http.StatusUnauthorized {
+			return nil, fmt.Errorf("server returned status %d: %s: %w", resp.StatusCode, string(body), ErrUnauthorized)
+		}
+		if class := statusError(resp.StatusCode); class != nil {
+			return nil, fmt.Errorf("server returned status %d: %s: %w", resp.StatusCode, string(body), class)
+		}
+		return nil, fmt.Errorf("server returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	if sessionID := resp.Header.Get(HeaderSessionID); sessionID != "" {
+		t.mu.Lock()
+		t.sessionID = sessionID
+		t.mu.Unlock()
+	}
+	if resp.StatusCode == http.StatusAccepted {
+		return nil, nil
+	}
+
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		return readEventStreamResponse(resp.Body, t.dispatch)
+	}
+
+	response, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	return response, nil
+}
+
+// post 发送一次 POST 请求
+func (t *HTTPTransport) post(ctx context.Context, request []byte) (*http.Response, error) {
 	req, err := http.NewRequestWithContext(ctx, "POST", t.url, bytes.NewReader(request))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	if sessionID := t.session(); sessionID != "" {
+		req.Header.Set(HeaderSessionID, sessionID)
+	}
+	if err := t.authorize(ctx, req); err != nil {
+		return nil, err
+	}
 	for k, v := range t.headers {
 		req.Header.Set(k, v)
 	}
 
 	resp, err := t.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+		return nil, fmt.Errorf("failed to send request: %w: %w", errors.ErrProviderUnavailable, err)
 	}
-	defer resp.Body.Close()
+	return resp, nil
+}
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("server returned status %d: %s", resp.StatusCode, string(body))
+// authorize 设置 Authorization 头
+func (t *HTTPTransport) authorize(ctx context.Context, req *http.Request) error {
+	if t.tokenSource == nil {
+		return nil
 	}
-
-	response, err := io.ReadAll(resp.Body)
+	token, err := t.tokenSource.Token(ctx)
 	if err != nil {
+		return fmt.Errorf("failed to get access token: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+	return nil
+}
+
+// session 返回当前的会话 ID
+func (t *HTTPTransport) session() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.sessionID
+}
+
+// readEventStreamResponse 从事件流中读取第一条 JSON-RPC 响应，响应之前服务器发来的通知和请求交给 onMessage
+func readEventStreamResponse(body io.Reader, onMessage func(message []byte)) ([]byte, error) {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 1024*1024), 10*1024*1024) // 10MB max line size
+
+	var data []byte
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if isClientResponse(data) {
+				return data, nil
+			}
+			if len(data) > 0 {
+				onMessage(data)
+			}
+			data = nil
+		case strings.HasPrefix(line, "data:"):
+			if len(data) > 0 {
+				data = append(data, '\n')
+			}
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " ")...)
+		}
+	}
+	if err := scanner.Err(); err != nil {
 		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
+	if isClientResponse(data) {
+		return data, nil
+	}
+	return nil, fmt.Errorf("event stream ended without a response")
+}
 
-	return response, nil
+// statusError 将可重试的 HTTP 状态码映射为对应的错误类别，其余返回 nil
+func statusError(code int) error {
+	switch code {
+	case http.StatusTooManyRequests:
+		return errors.ErrRateLimited
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return errors.ErrProviderUnavailable
+	default:
+		return nil
+	}
 }
 
-// Close 关闭 HTTP 传输（无操作）
+// Close 关闭 HTTP 传输，存在会话时通知服务器结束会话
 func (t *HTTPTransport) Close() error {
+	sessionID := t.session()
+	if sessionID == "" {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, t.url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set(HeaderSessionID, sessionID)
+	if err := t.authorize(ctx, req); err != nil {
+		return err
+	}
+	for k, v := range t.headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to close session: %w", err)
+	}
+	resp.Body.Close()
+
+	t.mu.Lock()
+	t.sessionID = ""
+	t.mu.Unlock()
 	return nil
 }
 
@@ -3,8 +3,8 @@
 // MCP 是一种标准化的协议，用于 AI 模型与外部工具/服务之间的通信。
 // 本包提供：
 //   - MCPClient: 连接到 MCP 服务器，调用工具、读取资源、获取提示词
-//   - MCPServer: 将本地工具以 MCP 协议暴露给外部客户端
-//   - Transport: 传输层抽象，支持 Stdio 和 HTTP 两种方式
+//   - MCPServer: 将本地工具以 MCP 协议暴露给外部客户端，支持 Stdio、HTTP（Streamable HTTP 与旧版 SSE）和 WebSocket
+//   - Transport: 传输层抽象，支持 Stdio、HTTP 和 WebSocket
 package mcp
 
 import (
@@ -33,6 +33,13 @@ type JSONRPCResponse struct {
 	Error   *JSONRPCError   `json:"error,omitempty"`
 }
 
+// JSONRPCNotification JSON-RPC 2.0 通知结构（没有 ID，不需要响应）
+type JSONRPCNotification struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
 // JSONRPCError JSON-RPC 2.0 错误结构
 type JSONRPCError struct {
 	Code    int             `json:"code"`
@@ -51,6 +58,32 @@ const (
 	MethodListPrompts   = "prompts/list"
 	MethodGetPrompt     = "prompts/get"
 	MethodPing          = "ping"
+
+	MethodListResourceTemplates = "resources/templates/list"
+	MethodSubscribeResource     = "resources/subscribe"
+	MethodUnsubscribeResource   = "resources/unsubscribe"
+
+	// MethodListRoots 服务器向客户端发起的请求
+	MethodListRoots = "roots/list"
+)
+
+// MCP 服务器通知
+const (
+	NotificationResourcesListChanged = "notifications/resources/list_changed"
+	NotificationResourceUpdated      = "notifications/resources/updated"
+	NotificationPromptsListChanged   = "notifications/prompts/list_changed"
+	NotificationProgress             = "notifications/progress"
+)
+
+// MCP 客户端通知
+const (
+	NotificationRootsListChanged = "notifications/roots/list_changed"
+)
+
+// 请求取消通知，任一方都可以发送。MethodCancelRequest 是 LSP 风格的别名，服务器同样接受
+const (
+	NotificationCancelled = "notifications/cancelled"
+	MethodCancelRequest   = "$/cancelRequest"
 )
 
 // InitializeParams 初始化请求参数
@@ -72,6 +105,8 @@ type Capabilities struct {
 	Tools     *ToolsCapability     `json:"tools,omitempty"`
 	Resources *ResourcesCapability `json:"resources,omitempty"`
 	Prompts   *PromptsCapability   `json:"prompts,omitempty"`
+	// Roots 客户端能力：可以向服务器提供根目录
+	Roots *RootsCapability `json:"roots,omitempty"`
 }
 
 // ToolsCapability 工具能力
@@ -90,6 +125,11 @@ type PromptsCapability struct {
 	ListChanged bool `json:"listChanged,omitempty"`
 }
 
+// RootsCapability 根目录能力
+type RootsCapability struct {
+	ListChanged bool `json:"listChanged,omitempty"`
+}
+
 // Implementation 客户端/服务器实现信息
 type Implementation struct {
 	Name    string `json:"name"`
@@ -112,6 +152,40 @@ type ListToolsResult struct {
 type CallToolParams struct {
 	Name      string                 `json:"name"`
 	Arguments map[string]interface{} `json:"arguments,omitempty"`
+	Meta      *RequestMeta           `json:"_meta,omitempty"`
+}
+
+// RequestMeta 请求的元数据
+type RequestMeta struct {
+	// ProgressToken 进度令牌，设置后服务器通过 notifications/progress 报告该请求的进度
+	ProgressToken interface{} `json:"progressToken,omitempty"`
+}
+
+// ProgressParams 进度通知的参数
+type ProgressParams struct {
+	ProgressToken interface{} `json:"progressToken"`
+	Progress      float64     `json:"progress"`
+	Total         float64     `json:"total,omitempty"`
+	Message       string      `json:"message,omitempty"`
+}
+
+// CancelledParams 取消通知的参数
+type CancelledParams struct {
+	RequestID interface{} `json:"requestId"`
+	Reason    string      `json:"reason,omitempty"`
+}
+
+// Root 客户端允许服务器访问的根目录
+type Root struct {
+	// URI 根目录地址，目前只支持 file://
+	URI string `json:"uri"`
+	// Name 显示名称（可选）
+	Name string `json:"name,omitempty"`
+}
+
+// ListRootsResult 列出根目录的响应
+type ListRootsResult struct {
+	Roots []Root `json:"roots"`
 }
 
 // CallToolResult 调用工具的响应
@@ -142,6 +216,29 @@ type ListResourcesResult struct {
 	Resources []Resource `json:"resources"`
 }
 
+// ResourceTemplate MCP 资源模板定义
+type ResourceTemplate struct {
+	URITemplate string `json:"uriTemplate"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+}
+
+// ListResourceTemplatesResult 列出资源模板的响应
+type ListResourceTemplatesResult struct {
+	ResourceTemplates []ResourceTemplate `json:"resourceTemplates"`
+}
+
+// SubscribeResourceParams 订阅/取消订阅资源的请求参数
+type SubscribeResourceParams struct {
+	URI string `json:"uri"`
+}
+
+// ResourceUpdatedParams 资源更新通知的参数
+type ResourceUpdatedParams struct {
+	URI string `json:"uri"`
+}
+
 // ReadResourceParams 读取资源的请求参数
 type ReadResourceParams struct {
 	URI string `json:"uri"`
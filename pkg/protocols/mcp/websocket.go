@@ -0,0 +1,435 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1" // #nosec G505 - required by the WebSocket handshake (RFC 6455)
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ahhsitt/helloagents-go/pkg/core/errors"
+)
+
+// WebSocket 保活默认参数
+const (
+	// DefaultWebSocketPingInterval ping 发送间隔
+	DefaultWebSocketPingInterval = 30 * time.Second
+	// DefaultWebSocketPongTimeout 发送 ping 后等待响应的时间
+	DefaultWebSocketPongTimeout = 10 * time.Second
+)
+
+// WebSocketSubprotocol MCP 的 WebSocket 子协议名
+const WebSocketSubprotocol = "mcp"
+
+// WebSocket 帧类型（RFC 6455）
+const (
+	wsOpContinuation byte = 0x0
+	wsOpText         byte = 0x1
+	wsOpBinary       byte = 0x2
+	wsOpClose        byte = 0x8
+	wsOpPing         byte = 0x9
+	wsOpPong         byte = 0xA
+)
+
+// wsAcceptGUID 握手时拼接在 Sec-WebSocket-Key 后的固定 GUID
+const wsAcceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// wsWriteTimeout 单帧写入超时
+const wsWriteTimeout = 10 * time.Second
+
+// wsConn WebSocket 连接
+//
+// 实现 MCP 需要的 RFC 6455 子集：文本/二进制消息（含分片）、ping/pong 和关闭握手，不支持扩展。
+// 读取只能在一个 goroutine 中进行，写入可以并发。
+type wsConn struct {
+	conn   net.Conn
+	br     *bufio.Reader
+	client bool // 客户端发送的帧需要掩码
+
+	wmu          sync.Mutex
+	lastActivity atomic.Int64 // 最近一次收到帧的时间（UnixNano）
+	done         chan struct{}
+	once         sync.Once
+}
+
+// newWSConn 包装已完成握手的连接
+func newWSConn(conn net.Conn, br *bufio.Reader, client bool) *wsConn {
+	c := &wsConn{conn: conn, br: br, client: client, done: make(chan struct{})}
+	c.lastActivity.Store(time.Now().UnixNano())
+	return c
+}
+
+// readMessage 读取一条完整的消息，自动应答 ping；收到关闭帧时返回 io.EOF
+func (c *wsConn) readMessage() ([]byte, error) {
+	var message []byte
+	started := false
+	for {
+		fin, opcode, payload, err := c.readFrame()
+		if err != nil {
+			return nil, err
+		}
+
+		switch opcode {
+		case wsOpPing:
+			if err := c.writeFrame(wsOpPong, payload); err != nil {
+				return nil, err
+			}
+			continue
+		case wsOpPong:
+			continue
+		case wsOpClose:
+			if len(payload) > 2 {
+				payload = payload[:2]
+			}
+			_ = c.writeFrame(wsOpClose, payload)
+			return nil, io.EOF
+		case wsOpText, wsOpBinary:
+			if started {
+				return nil, fmt.Errorf("websocket: new message before previous one finished")
+			}
+			started = true
+			message = payload
+		case wsOpContinuation:
+			if !started {
+				return nil, fmt.Errorf("websocket: unexpected continuation frame")
+			}
+			message = append(message, payload...)
+		default:
+			return nil, fmt.Errorf("websocket: unknown opcode %d", opcode)
+		}
+
+		if len(message) > maxHTTPBodySize {
+			return nil, fmt.Errorf("websocket: message exceeds %d bytes", maxHTTPBodySize)
+		}
+		if fin {
+			return message, nil
+		}
+	}
+}
+
+// readFrame 读取一个帧并去掉掩码
+func (c *wsConn) readFrame() (bool, byte, []byte, error) {
+	var head [2]byte
+	if _, err := io.ReadFull(c.br, head[:]); err != nil {
+		return false, 0, nil, err
+	}
+	fin := head[0]&0x80 != 0
+	opcode := head[0] & 0x0F
+	if head[0]&0x70 != 0 {
+		return false, 0, nil, fmt.Errorf("websocket: reserved bits set")
+	}
+	// 客户端发送的帧必须带掩码，服务器发送的帧不能带掩码
+	masked := head[1]&0x80 != 0
+	if masked == c.client {
+		return false, 0, nil, fmt.Errorf("websocket: invalid frame masking")
+	}
+
+	length := uint64(head[1] & 0x7F)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(c.br, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(c.br, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+	if opcode >= wsOpClose && (length > 125 || !fin) {
+		return false, 0, nil, fmt.Errorf("websocket: invalid control frame")
+	}
+	if length > maxHTTPBodySize {
+		return false, 0, nil, fmt.Errorf("websocket: frame exceeds %d bytes", maxHTTPBodySize)
+	}
+
+	var key [4]byte
+	if masked {
+		if _, err := io.ReadFull(c.br, key[:]); err != nil {
+			return false, 0, nil, err
+		}
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(c.br, payload); err != nil {
+		return false, 0, nil, err
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= key[i%4]
+		}
+	}
+
+	c.lastActivity.Store(time.Now().UnixNano())
+	return fin, opcode, payload, nil
+}
+
+// writeMessage 发送一条文本消息
+func (c *wsConn) writeMessage(data []byte) error {
+	return c.writeFrame(wsOpText, data)
+}
+
+// writeFrame 发送一个不分片的帧
+func (c *wsConn) writeFrame(opcode byte, payload []byte) error {
+	return c.writeFrameTimeout(opcode, payload, wsWriteTimeout)
+}
+
+// writeFrameTimeout 在 timeout 内发送一个不分片的帧
+func (c *wsConn) writeFrameTimeout(opcode byte, payload []byte, timeout time.Duration) error {
+	frame := make([]byte, 0, len(payload)+14)
+	frame = append(frame, 0x80|opcode)
+
+	var maskBit byte
+	if c.client {
+		maskBit = 0x80
+	}
+	switch n := len(payload); {
+	case n <= 125:
+		frame = append(frame, maskBit|byte(n))
+	case n <= 0xFFFF:
+		frame = append(frame, maskBit|126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(n))
+	default:
+		frame = append(frame, maskBit|127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(n))
+	}
+
+	if c.client {
+		var key [4]byte
+		if _, err := rand.Read(key[:]); err != nil {
+			return err
+		}
+		frame = append(frame, key[:]...)
+		for i, b := range payload {
+			frame = append(frame, b^key[i%4])
+		}
+	} else {
+		frame = append(frame, payload...)
+	}
+
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	if err := c.conn.SetWriteDeadline(time.Now().Add(timeout)); err != nil {
+		return err
+	}
+	_, err := c.conn.Write(frame)
+	return err
+}
+
+// keepalive 每隔 interval 发送 ping，timeout 内没有收到任何帧时关闭连接
+func (c *wsConn) keepalive(interval, timeout time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+		}
+
+		sent := time.Now()
+		if err := c.writeFrame(wsOpPing, nil); err != nil {
+			c.closeNow()
+			return
+		}
+		select {
+		case <-c.done:
+			return
+		case <-time.After(timeout):
+			if time.Unix(0, c.lastActivity.Load()).Before(sent) {
+				c.closeNow()
+				return
+			}
+		}
+	}
+}
+
+// close 发送关闭帧（1000 正常关闭）后关闭连接，对端无响应时不长时间阻塞
+func (c *wsConn) close() {
+	_ = c.writeFrameTimeout(wsOpClose, []byte{0x03, 0xE8}, time.Second)
+	c.closeNow()
+}
+
+// closeNow 直接关闭底层连接
+func (c *wsConn) closeNow() {
+	c.once.Do(func() {
+		close(c.done)
+		c.conn.Close()
+	})
+}
+
+// upgradeWebSocket 完成服务器端握手，失败时写出错误响应
+func upgradeWebSocket(w http.ResponseWriter, r *http.Request) (*wsConn, error) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return nil, fmt.Errorf("websocket: method %s not allowed", r.Method)
+	}
+	if !headerHasToken(r.Header, "Connection", "upgrade") || !headerHasToken(r.Header, "Upgrade", "websocket") {
+		http.Error(w, "Not a WebSocket handshake", http.StatusBadRequest)
+		return nil, fmt.Errorf("websocket: not a websocket handshake")
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		http.Error(w, "Unsupported WebSocket version", http.StatusUpgradeRequired)
+		return nil, fmt.Errorf("websocket: unsupported version")
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if key == "" {
+		http.Error(w, "Missing Sec-WebSocket-Key", http.StatusBadRequest)
+		return nil, fmt.Errorf("websocket: missing key")
+	}
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "WebSocket unsupported", http.StatusInternalServerError)
+		return nil, fmt.Errorf("websocket: response writer does not support hijacking")
+	}
+
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, fmt.Errorf("websocket: hijack failed: %w", err)
+	}
+	// 接管后的连接可能仍带有 HTTP 服务设置的超时
+	_ = conn.SetDeadline(time.Time{})
+	response := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + wsAcceptKey(key) + "\r\n"
+	if headerHasToken(r.Header, "Sec-WebSocket-Protocol", WebSocketSubprotocol) {
+		response += "Sec-WebSocket-Protocol: " + WebSocketSubprotocol + "\r\n"
+	}
+	if _, err := rw.WriteString(response + "\r\n"); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("websocket: failed to write handshake: %w", err)
+	}
+	if err := rw.Flush(); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("websocket: failed to write handshake: %w", err)
+	}
+	return newWSConn(conn, rw.Reader, false), nil
+}
+
+// dialWebSocket 连接 ws:// 或 wss:// 地址并完成客户端握手
+func dialWebSocket(ctx context.Context, rawURL string, headers map[string]string) (*wsConn, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid websocket url: %w", err)
+	}
+	var scheme, port string
+	switch u.Scheme {
+	case "ws":
+		scheme, port = "http", "80"
+	case "wss":
+		scheme, port = "https", "443"
+	default:
+		return nil, fmt.Errorf("unsupported websocket scheme %q", u.Scheme)
+	}
+	addr := u.Host
+	if u.Port() == "" {
+		addr = net.JoinHostPort(u.Hostname(), port)
+	}
+
+	var conn net.Conn
+	dialer := &net.Dialer{}
+	if scheme == "https" {
+		tlsDialer := &tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: u.Hostname(), MinVersion: tls.VersionTLS12}}
+		conn, err = tlsDialer.DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect: %w: %w", errors.ErrProviderUnavailable, err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	key := base64.StdEncoding.EncodeToString(nonce)
+
+	httpURL := *u
+	httpURL.Scheme = scheme
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, httpURL.String(), nil)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Sec-WebSocket-Key", key)
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Protocol", WebSocketSubprotocol)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to send handshake: %w: %w", errors.ErrProviderUnavailable, err)
+	}
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to read handshake: %w: %w", errors.ErrProviderUnavailable, err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		resp.Body.Close()
+		conn.Close()
+		if resp.StatusCode == http.StatusUnauthorized {
+			return nil, fmt.Errorf("server returned status %d: %s: %w", resp.StatusCode, string(body), ErrUnauthorized)
+		}
+		if class := statusError(resp.StatusCode); class != nil {
+			return nil, fmt.Errorf("server returned status %d: %s: %w", resp.StatusCode, string(body), class)
+		}
+		return nil, fmt.Errorf("server returned status %d: %s", resp.StatusCode, string(body))
+	}
+	if resp.Header.Get("Sec-WebSocket-Accept") != wsAcceptKey(key) {
+		conn.Close()
+		return nil, fmt.Errorf("websocket: invalid Sec-WebSocket-Accept")
+	}
+
+	_ = conn.SetDeadline(time.Time{})
+	return newWSConn(conn, br, true), nil
+}
+
+// wsAcceptKey 计算握手响应的 Sec-WebSocket-Accept
+func wsAcceptKey(key string) string {
+	h := sha1.New() // #nosec G401 - required by the WebSocket handshake (RFC 6455)
+	h.Write([]byte(key + wsAcceptGUID))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+// headerHasToken 逗号分隔的请求头中是否包含 token（不区分大小写）
+func headerHasToken(header http.Header, name, token string) bool {
+	for _, value := range header.Values(name) {
+		for _, part := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,320 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ahhsitt/helloagents-go/pkg/core/errors"
+	"github.com/ahhsitt/helloagents-go/pkg/core/retry"
+)
+
+// WebSocketTransportConfig WebSocket 传输配置
+type WebSocketTransportConfig struct {
+	// URL 服务器地址（ws:// 或 wss://）
+	URL string
+	// Headers 握手时的自定义请求头
+	Headers map[string]string
+	// TokenSource 访问令牌来源（可选），每次建立连接（包括重连）时在握手请求中携带 Bearer 令牌
+	TokenSource TokenSource
+	// PingInterval ping 间隔（默认 DefaultWebSocketPingInterval），小于 0 时不发送 ping
+	PingInterval time.Duration
+	// PongTimeout 发送 ping 后等待响应的时间（默认 DefaultWebSocketPongTimeout），超时视为连接断开
+	PongTimeout time.Duration
+	// DialTimeout 建立连接的超时（默认 10s）
+	DialTimeout time.Duration
+	// Reconnect 断线重连的退避策略（默认 500ms 起步、上限 30s 的指数退避），
+	// MaxAttempts 为连续重连的最大次数，不大于 0 时不限制
+	Reconnect *retry.Policy
+	// DisableReconnect 断线后不重连
+	DisableReconnect bool
+	// OnMessage 服务器主动发送的消息（通知和请求）的回调，在读取 goroutine 中调用
+	OnMessage func(message []byte)
+}
+
+// WebSocketTransport WebSocket 传输
+//
+// 在一个长连接上双向收发 JSON-RPC 消息，按请求 ID 将响应匹配到对应的 Send 调用，
+// 多个 Send 可以并发。连接定期发送 ping，PongTimeout 内没有收到任何数据时视为断开；
+// 断开后按退避策略在后台重连，期间的 Send 返回 ErrProviderUnavailable（可被客户端重试策略重试），
+// 等待中的请求同样以 ErrProviderUnavailable 失败。
+type WebSocketTransport struct {
+	messageHandlers
+	config    WebSocketTransportConfig
+	reconnect retry.Policy
+
+	mu      sync.Mutex
+	conn    *wsConn // 重连期间为 nil
+	pending map[string]chan []byte
+	closed  bool
+	failed  error // 重连放弃后的错误
+	done    chan struct{}
+
+	reconnects atomic.Int64
+}
+
+// NewWebSocketTransport 创建 WebSocket 传输并建立连接
+func NewWebSocketTransport(config WebSocketTransportConfig) (*WebSocketTransport, error) {
+	if config.PingInterval == 0 {
+		config.PingInterval = DefaultWebSocketPingInterval
+	}
+	if config.PongTimeout <= 0 {
+		config.PongTimeout = DefaultWebSocketPongTimeout
+	}
+	if config.DialTimeout <= 0 {
+		config.DialTimeout = 10 * time.Second
+	}
+	reconnect := retry.Policy{
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     30 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.1,
+	}
+	if config.Reconnect != nil {
+		reconnect = *config.Reconnect
+	}
+
+	t := &WebSocketTransport{
+		messageHandlers: messageHandlers{user: config.OnMessage},
+		config:          config,
+		reconnect:       reconnect,
+		pending:         make(map[string]chan []byte),
+		done:            make(chan struct{}),
+	}
+	conn, err := t.dial()
+	if err != nil {
+		return nil, err
+	}
+	t.mu.Lock()
+	t.attachLocked(conn)
+	t.mu.Unlock()
+	return t, nil
+}
+
+// Send 发送消息；请求等待匹配 ID 的响应，通知和对服务器请求的响应发送后立即返回 nil
+func (t *WebSocketTransport) Send(ctx context.Context, request []byte) ([]byte, error) {
+	var probe struct {
+		ID json.RawMessage `json:"id"`
+	}
+	if err := json.Unmarshal(request, &probe); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+	key := messageIDKey(probe.ID)
+	if isClientResponse(request) {
+		key = "" // 对服务器请求的响应，不等待回复
+	}
+
+	t.mu.Lock()
+	if t.closed {
+		t.mu.Unlock()
+		return nil, fmt.Errorf("transport is closed")
+	}
+	if t.failed != nil {
+		t.mu.Unlock()
+		return nil, t.failed
+	}
+	conn := t.conn
+	if conn == nil {
+		t.mu.Unlock()
+		return nil, fmt.Errorf("websocket is reconnecting: %w", errors.ErrProviderUnavailable)
+	}
+	var ch chan []byte
+	if key != "" {
+		ch = make(chan []byte, 1)
+		t.pending[key] = ch
+	}
+	t.mu.Unlock()
+
+	if err := conn.writeMessage(request); err != nil {
+		t.forget(key, ch)
+		conn.closeNow()
+		return nil, fmt.Errorf("failed to write request: %w: %w", errors.ErrProviderUnavailable, err)
+	}
+	if ch == nil {
+		return nil, nil
+	}
+
+	select {
+	case response, ok := <-ch:
+		if !ok {
+			return nil, fmt.Errorf("websocket connection lost: %w", errors.ErrProviderUnavailable)
+		}
+		return response, nil
+	case <-ctx.Done():
+		t.forget(key, ch)
+		return nil, ctx.Err()
+	}
+}
+
+// Reconnects 返回成功重连的次数
+func (t *WebSocketTransport) Reconnects() int {
+	return int(t.reconnects.Load())
+}
+
+// Close 关闭连接并停止重连
+func (t *WebSocketTransport) Close() error {
+	t.mu.Lock()
+	if t.closed {
+		t.mu.Unlock()
+		return nil
+	}
+	t.closed = true
+	close(t.done)
+	conn := t.conn
+	t.mu.Unlock()
+
+	if conn != nil {
+		conn.close()
+	}
+	return nil
+}
+
+// dial 建立连接
+func (t *WebSocketTransport) dial() (*wsConn, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), t.config.DialTimeout)
+	defer cancel()
+
+	headers := t.config.Headers
+	if t.config.TokenSource != nil {
+		token, err := t.config.TokenSource.Token(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get access token: %w", err)
+		}
+		headers = make(map[string]string, len(t.config.Headers)+1)
+		headers["Authorization"] = "Bearer " + token.AccessToken
+		for k, v := range t.config.Headers {
+			headers[k] = v
+		}
+	}
+	return dialWebSocket(ctx, t.config.URL, headers)
+}
+
+// attachLocked 使用新连接并启动读取和保活（调用方需持有锁）
+func (t *WebSocketTransport) attachLocked(conn *wsConn) {
+	t.conn = conn
+	go t.readLoop(conn)
+	go conn.keepalive(t.config.PingInterval, t.config.PongTimeout)
+}
+
+// readLoop 读取连接上的消息，连接断开后让等待中的请求失败并重连
+func (t *WebSocketTransport) readLoop(conn *wsConn) {
+	for {
+		message, err := conn.readMessage()
+		if err != nil {
+			break
+		}
+		t.deliver(message)
+	}
+	conn.closeNow()
+
+	t.mu.Lock()
+	if t.conn == conn {
+		t.conn = nil
+	}
+	pending := t.pending
+	t.pending = make(map[string]chan []byte)
+	closed := t.closed
+	t.mu.Unlock()
+
+	for _, ch := range pending {
+		close(ch)
+	}
+	if !closed {
+		t.reconnectLoop()
+	}
+}
+
+// reconnectLoop 按退避策略重连，放弃后 Send 返回最后一次的错误
+func (t *WebSocketTransport) reconnectLoop() {
+	if t.config.DisableReconnect {
+		t.mu.Lock()
+		t.failed = fmt.Errorf("websocket connection lost: %w", errors.ErrProviderUnavailable)
+		t.mu.Unlock()
+		return
+	}
+
+	for attempt := 1; ; attempt++ {
+		select {
+		case <-t.done:
+			return
+		case <-time.After(t.reconnect.Backoff(attempt)):
+		}
+
+		conn, err := t.dial()
+		if err != nil {
+			if t.reconnect.MaxAttempts > 0 && attempt >= t.reconnect.MaxAttempts {
+				t.mu.Lock()
+				t.failed = fmt.Errorf("websocket reconnect failed after %d attempts: %w", attempt, err)
+				t.mu.Unlock()
+				return
+			}
+			continue
+		}
+
+		t.mu.Lock()
+		if t.closed {
+			t.mu.Unlock()
+			conn.close()
+			return
+		}
+		t.reconnects.Add(1)
+		t.attachLocked(conn)
+		t.mu.Unlock()
+		return
+	}
+}
+
+// deliver 将响应交给等待中的请求，其余消息交给 OnMessage
+func (t *WebSocketTransport) deliver(message []byte) {
+	messages, _, err := splitMessages(message)
+	if err != nil {
+		return
+	}
+	for _, msg := range messages {
+		if isClientResponse(msg) {
+			var probe struct {
+				ID json.RawMessage `json:"id"`
+			}
+			if json.Unmarshal(msg, &probe) == nil {
+				key := messageIDKey(probe.ID)
+				t.mu.Lock()
+				ch, ok := t.pending[key]
+				delete(t.pending, key)
+				t.mu.Unlock()
+				if ok {
+					ch <- msg
+					continue
+				}
+			}
+		}
+		t.dispatch(msg)
+	}
+}
+
+// forget 取消等待中的请求
+func (t *WebSocketTransport) forget(key string, ch chan []byte) {
+	if ch == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.pending[key] == ch {
+		delete(t.pending, key)
+	}
+}
+
+// messageIDKey 请求 ID 的匹配键，通知（没有 ID）返回空字符串
+func messageIDKey(id json.RawMessage) string {
+	id = bytes.TrimSpace(id)
+	if len(id) == 0 || string(id) == "null" {
+		return ""
+	}
+	return string(id)
+}
+
+// compile-time interface check
+var _ Transport = (*WebSocketTransport)(nil)
@@ -0,0 +1,245 @@
+package rag
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// 语义回答缓存默认参数
+const (
+	// DefaultAnswerCacheSize 默认最多缓存的回答数量
+	DefaultAnswerCacheSize = 1000
+	// DefaultAnswerCacheThreshold 默认的查询相似度阈值
+	DefaultAnswerCacheThreshold = 0.95
+	// DefaultAnswerCacheMaxAge 默认的回答最长有效期
+	DefaultAnswerCacheMaxAge = 24 * time.Hour
+)
+
+// AnswerCacheStats 语义回答缓存统计
+type AnswerCacheStats struct {
+	Hits   int64 `json:"hits"`
+	Misses int64 `json:"misses"`
+	// Stale 找到相似查询但回答已过期（超过有效期、存储版本变化或相关内容在回答之后重新摄取）的次数
+	Stale int64 `json:"stale"`
+	Size  int   `json:"size"`
+}
+
+// SemanticAnswerCache 语义回答缓存
+//
+// 通过 WithAnswerCache 接入管道后，Query 和 QueryStream 在检索之后、生成之前
+// 查找与当前查询嵌入相似度不低于阈值的已回答查询，命中时直接返回缓存的回答、来源和引用，
+// 不再调用生成器，适合 FAQ 类重复问题较多的场景。
+//
+// 新鲜度检查：设置了 VersionedStore 时（管道的存储实现了 VersionedStore 时自动设置），
+// 存储版本在回答之后变化（写入、删除、过期清理或清空）则缓存的回答作废，
+// 避免继续返回以已删除的块为来源的回答；本次检索到的任一块的摄取时间（IngestedAt）
+// 晚于缓存回答的生成时间时同样作废；回答超过最长有效期时也作废。
+type SemanticAnswerCache struct {
+	embedder  Embedder
+	source    VersionedStore
+	threshold float32
+	capacity  int
+	maxAge    time.Duration
+
+	mu     sync.Mutex
+	order  *list.List // 最近使用的在前
+	hits   int64
+	misses int64
+	stale  int64
+}
+
+// answerCacheEntry 缓存的回答
+type answerCacheEntry struct {
+	query     string
+	vector    []float32
+	response  RAGResponse
+	createdAt time.Time
+	version   uint64 // 回答生成时的存储版本
+}
+
+// AnswerCacheOption 语义回答缓存选项
+type AnswerCacheOption func(*SemanticAnswerCache)
+
+// WithAnswerCacheEmbedder 设置查询嵌入器（默认使用管道的嵌入器）
+func WithAnswerCacheEmbedder(embedder Embedder) AnswerCacheOption {
+	return func(c *SemanticAnswerCache) {
+		c.embedder = embedder
+	}
+}
+
+// WithAnswerCacheSource 设置回答所依据的存储，存储版本变化时缓存的回答作废（默认使用管道的存储）
+func WithAnswerCacheSource(source VersionedStore) AnswerCacheOption {
+	return func(c *SemanticAnswerCache) {
+		c.source = source
+	}
+}
+
+// WithAnswerCacheThreshold 设置查询相似度阈值（默认 DefaultAnswerCacheThreshold）
+func WithAnswerCacheThreshold(threshold float32) AnswerCacheOption {
+	return func(c *SemanticAnswerCache) {
+		if threshold > 0 && threshold <= 1 {
+			c.threshold = threshold
+		}
+	}
+}
+
+// WithAnswerCacheSize 设置最多缓存的回答数量（默认 DefaultAnswerCacheSize），超出时淘汰最久未使用的回答
+func WithAnswerCacheSize(size int) AnswerCacheOption {
+	return func(c *SemanticAnswerCache) {
+		if size > 0 {
+			c.capacity = size
+		}
+	}
+}
+
+// WithAnswerCacheMaxAge 设置回答的最长有效期（默认 DefaultAnswerCacheMaxAge），0 表示只按摄取时间判断
+func WithAnswerCacheMaxAge(maxAge time.Duration) AnswerCacheOption {
+	return func(c *SemanticAnswerCache) {
+		if maxAge >= 0 {
+			c.maxAge = maxAge
+		}
+	}
+}
+
+// NewSemanticAnswerCache 创建语义回答缓存
+func NewSemanticAnswerCache(opts ...AnswerCacheOption) *SemanticAnswerCache {
+	c := &SemanticAnswerCache{
+		threshold: DefaultAnswerCacheThreshold,
+		capacity:  DefaultAnswerCacheSize,
+		maxAge:    DefaultAnswerCacheMaxAge,
+		order:     list.New(),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Lookup 查找与查询向量最相似的新鲜回答
+//
+// results 为本次检索的结果，用于新鲜度检查。找到的相似回答已过期时将其移除。
+func (c *SemanticAnswerCache) Lookup(vector []float32, results []RetrievalResult) (*RAGResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var best *list.Element
+	var bestScore float32
+	for elem := c.order.Front(); elem != nil; elem = elem.Next() {
+		if score := cosineSimilarity(vector, elem.Value.(*answerCacheEntry).vector); score >= c.threshold && score > bestScore {
+			best, bestScore = elem, score
+		}
+	}
+	if best == nil {
+		c.misses++
+		return nil, false
+	}
+
+	entry := best.Value.(*answerCacheEntry)
+	if !c.fresh(entry, results) {
+		c.order.Remove(best)
+		c.stale++
+		return nil, false
+	}
+	c.order.MoveToFront(best)
+	c.hits++
+	response := entry.response
+	response.Sources = append([]Source(nil), entry.response.Sources...)
+	response.Citations = append([]CitedSentence(nil), entry.response.Citations...)
+	return &response, true
+}
+
+// Store 缓存查询的回答
+func (c *SemanticAnswerCache) Store(query string, vector []float32, response *RAGResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cached := *response
+	cached.Sources = append([]Source(nil), response.Sources...)
+	cached.Citations = append([]CitedSentence(nil), response.Citations...)
+	c.order.PushFront(&answerCacheEntry{
+		query:     query,
+		vector:    vector,
+		response:  cached,
+		createdAt: time.Now(),
+		version:   c.sourceVersion(),
+	})
+	for c.order.Len() > c.capacity {
+		c.order.Remove(c.order.Back())
+	}
+}
+
+// Invalidate 清空缓存
+func (c *SemanticAnswerCache) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.order.Init()
+}
+
+// Stats 返回缓存统计
+func (c *SemanticAnswerCache) Stats() AnswerCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return AnswerCacheStats{
+		Hits:   c.hits,
+		Misses: c.misses,
+		Stale:  c.stale,
+		Size:   c.order.Len(),
+	}
+}
+
+// fresh 检查回答是否仍然有效
+func (c *SemanticAnswerCache) fresh(entry *answerCacheEntry, results []RetrievalResult) bool {
+	if c.maxAge > 0 && time.Since(entry.createdAt) > c.maxAge {
+		return false
+	}
+	if c.source != nil && c.source.Version() != entry.version {
+		return false
+	}
+	for _, res := range results {
+		if res.Chunk.Metadata.IngestedAt.After(entry.createdAt) {
+			return false
+		}
+	}
+	return true
+}
+
+// sourceVersion 返回存储的当前版本，未设置存储时返回 0
+func (c *SemanticAnswerCache) sourceVersion() uint64 {
+	if c.source == nil {
+		return 0
+	}
+	return c.source.Version()
+}
+
+// cachedAnswer 查找缓存的回答，返回查询向量供未命中时写入缓存
+//
+// 未启用缓存、没有可用的嵌入器或嵌入失败时返回 nil 向量，此时不读写缓存。
+func (p *DefaultRAGPipeline) cachedAnswer(ctx context.Context, query string, response *RAGResponse) (*RAGResponse, []float32) {
+	if p.answerCache == nil || p.generator == nil {
+		return nil, nil
+	}
+	embedder := p.answerCache.embedder
+	if embedder == nil {
+		embedder = p.embedder
+	}
+	if embedder == nil {
+		return nil, nil
+	}
+	vectors, err := embedder.Embed(ctx, []string{query})
+	if err != nil || len(vectors) == 0 {
+		return nil, nil
+	}
+
+	var results []RetrievalResult
+	if response.Context != nil {
+		results = response.Context.Results
+	}
+	cached, ok := p.answerCache.Lookup(vectors[0], results)
+	if !ok {
+		return nil, vectors[0]
+	}
+	cached.Cached = true
+	return cached, vectors[0]
+}
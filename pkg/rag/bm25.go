@@ -0,0 +1,339 @@
+package rag
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// BM25Retriever BM25 关键词检索器
+//
+// 基于内存倒排索引，对标识符、错误码、版本号等稠密检索容易漏掉的精确词项效果较好。
+// 分词时保留 ERR_CONN_42、v1.2.3、user-id 这类复合词元，同时索引其组成部分；
+// 中日韩文字按单字和相邻二字索引。
+// 索引需与向量存储保持同步，通常通过 NewKeywordIndexedStore 在写入向量存储时一并写入。
+type BM25Retriever struct {
+	k1 float64
+	b  float64
+
+	mu       sync.RWMutex
+	chunks   map[string]DocumentChunk
+	termFreq map[string]map[string]int // 块 ID -> 词项 -> 词频
+	postings map[string]map[string]struct{}
+	lengths  map[string]int
+	totalLen int
+}
+
+// BM25Option BM25 检索器选项
+type BM25Option func(*BM25Retriever)
+
+// WithBM25Params 设置 BM25 参数 k1（词频饱和度，默认 1.2）和 b（长度归一化，默认 0.75）
+func WithBM25Params(k1, b float64) BM25Option {
+	return func(r *BM25Retriever) {
+		r.k1 = k1
+		r.b = b
+	}
+}
+
+// NewBM25Retriever 创建 BM25 检索器
+func NewBM25Retriever(opts ...BM25Option) *BM25Retriever {
+	r := &BM25Retriever{
+		k1:       1.2,
+		b:        0.75,
+		chunks:   make(map[string]DocumentChunk),
+		termFreq: make(map[string]map[string]int),
+		postings: make(map[string]map[string]struct{}),
+		lengths:  make(map[string]int),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Add 索引文档块，ID 已存在时替换
+func (r *BM25Retriever) Add(ctx context.Context, chunks []DocumentChunk) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, chunk := range chunks {
+		r.removeLocked(chunk.ID)
+
+		tokens := tokenizeKeywords(chunk.Content)
+		tf := make(map[string]int, len(tokens))
+		for _, t := range tokens {
+			tf[t]++
+		}
+		for t := range tf {
+			if r.postings[t] == nil {
+				r.postings[t] = make(map[string]struct{})
+			}
+			r.postings[t][chunk.ID] = struct{}{}
+		}
+
+		chunk.Vector = nil
+		r.chunks[chunk.ID] = chunk
+		r.termFreq[chunk.ID] = tf
+		r.lengths[chunk.ID] = len(tokens)
+		r.totalLen += len(tokens)
+	}
+	return nil
+}
+
+// Delete 从索引中删除文档块
+func (r *BM25Retriever) Delete(ctx context.Context, ids []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, id := range ids {
+		r.removeLocked(id)
+	}
+	return nil
+}
+
+// DeleteByFilter 删除满足过滤条件的文档块
+func (r *BM25Retriever) DeleteByFilter(ctx context.Context, filter DeleteFilter) (DeleteStats, error) {
+	if filter.IsEmpty() {
+		return DeleteStats{}, errEmptyDeleteFilter
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	docs := make(map[string]struct{})
+	var stats DeleteStats
+	for id, chunk := range r.chunks {
+		if !filter.Match(chunk) {
+			continue
+		}
+		r.removeLocked(id)
+		docs[chunk.DocumentID] = struct{}{}
+		stats.Chunks++
+	}
+	stats.Documents = len(docs)
+	return stats, nil
+}
+
+// Clear 清空索引
+func (r *BM25Retriever) Clear(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.chunks = make(map[string]DocumentChunk)
+	r.termFreq = make(map[string]map[string]int)
+	r.postings = make(map[string]map[string]struct{})
+	r.lengths = make(map[string]int)
+	r.totalLen = 0
+	return nil
+}
+
+// Size 返回索引的块数量
+func (r *BM25Retriever) Size() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.chunks)
+}
+
+// removeLocked 从索引中移除块（调用方需持有写锁）
+func (r *BM25Retriever) removeLocked(id string) {
+	tf, ok := r.termFreq[id]
+	if !ok {
+		return
+	}
+	for t := range tf {
+		delete(r.postings[t], id)
+		if len(r.postings[t]) == 0 {
+			delete(r.postings, t)
+		}
+	}
+	r.totalLen -= r.lengths[id]
+	delete(r.chunks, id)
+	delete(r.termFreq, id)
+	delete(r.lengths, id)
+}
+
+// Retrieve 按 BM25 分数检索文档块，跳过已过期的块（实现 Retriever 接口）
+//
+// 分数未归一化，只在同一查询内可比较；与向量检索结果融合时使用 HybridRetriever。
+func (r *BM25Retriever) Retrieve(ctx context.Context, query string, topK int) ([]RetrievalResult, error) {
+	terms := tokenizeKeywords(query)
+	if len(terms) == 0 || topK <= 0 {
+		return nil, nil
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	n := float64(len(r.chunks))
+	if n == 0 {
+		return nil, nil
+	}
+	avgLen := float64(r.totalLen) / n
+
+	scores := make(map[string]float64)
+	seen := make(map[string]bool, len(terms))
+	for _, t := range terms {
+		if seen[t] {
+			continue
+		}
+		seen[t] = true
+
+		posting := r.postings[t]
+		if len(posting) == 0 {
+			continue
+		}
+		df := float64(len(posting))
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		for id := range posting {
+			tf := float64(r.termFreq[id][t])
+			norm := 1 - r.b + r.b*float64(r.lengths[id])/avgLen
+			scores[id] += idf * tf * (r.k1 + 1) / (tf + r.k1*norm)
+		}
+	}
+
+	now := time.Now()
+	results := make([]RetrievalResult, 0, len(scores))
+	for id, score := range scores {
+		chunk := r.chunks[id]
+		if chunk.Metadata.Expired(now) {
+			continue
+		}
+		results = append(results, RetrievalResult{Chunk: chunk, Score: float32(score)})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Chunk.ID < results[j].Chunk.ID
+	})
+	if topK < len(results) {
+		results = results[:topK]
+	}
+	return results, nil
+}
+
+// tokenizeKeywords 将文本切分为小写关键词词元
+//
+// 字母数字之间的 _ - . / : 视为词内连接符，复合词元本身及其组成部分都会输出，
+// 中日韩文字输出单字和相邻二字。
+func tokenizeKeywords(text string) []string {
+	var tokens []string
+	runes := []rune(strings.ToLower(text))
+
+	for i := 0; i < len(runes); {
+		switch {
+		case isCJK(runes[i]):
+			tokens = append(tokens, string(runes[i]))
+			if i+1 < len(runes) && isCJK(runes[i+1]) {
+				tokens = append(tokens, string(runes[i:i+2]))
+			}
+			i++
+		case isWordRune(runes[i]):
+			start := i
+			for i < len(runes) && (isWordRune(runes[i]) ||
+				isKeywordJoiner(runes[i]) && i+1 < len(runes) && isWordRune(runes[i+1]) && !isCJK(runes[i+1])) {
+				i++
+			}
+			word := string(runes[start:i])
+			tokens = append(tokens, word)
+			if strings.ContainsAny(word, "_-./:") {
+				for _, part := range strings.FieldsFunc(word, isKeywordJoiner) {
+					tokens = append(tokens, part)
+				}
+			}
+		default:
+			i++
+		}
+	}
+	return tokens
+}
+
+// isWordRune 判断是否为非中日韩的字母或数字
+func isWordRune(r rune) bool {
+	return (unicode.IsLetter(r) || unicode.IsDigit(r)) && !isCJK(r)
+}
+
+// isKeywordJoiner 判断是否为词内连接符
+func isKeywordJoiner(r rune) bool {
+	return r == '_' || r == '-' || r == '.' || r == '/' || r == ':'
+}
+
+// isCJK 判断是否为中日韩文字
+func isCJK(r rune) bool {
+	return unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) ||
+		unicode.Is(unicode.Katakana, r) || unicode.Is(unicode.Hangul, r)
+}
+
+// KeywordIndexedStore 同时写入关键词索引的向量存储
+//
+// 写入、删除和清空操作同步到 BM25Retriever，检索和计数由向量存储完成，
+// 使混合检索的两路结果覆盖相同的文档块。
+type KeywordIndexedStore struct {
+	VectorStore
+	keywords *BM25Retriever
+}
+
+// NewKeywordIndexedStore 包装向量存储，使其写入同步到 keywords
+func NewKeywordIndexedStore(store VectorStore, keywords *BM25Retriever) *KeywordIndexedStore {
+	return &KeywordIndexedStore{
+		VectorStore: store,
+		keywords:    keywords,
+	}
+}
+
+// Add 添加文档块
+func (s *KeywordIndexedStore) Add(ctx context.Context, chunks []DocumentChunk) error {
+	if err := s.VectorStore.Add(ctx, chunks); err != nil {
+		return err
+	}
+	return s.keywords.Add(ctx, chunks)
+}
+
+// Delete 删除文档块
+func (s *KeywordIndexedStore) Delete(ctx context.Context, ids []string) error {
+	if err := s.VectorStore.Delete(ctx, ids); err != nil {
+		return err
+	}
+	return s.keywords.Delete(ctx, ids)
+}
+
+// DeleteByFilter 删除满足过滤条件的文档块，返回向量存储的回收统计
+func (s *KeywordIndexedStore) DeleteByFilter(ctx context.Context, filter DeleteFilter) (DeleteStats, error) {
+	stats, err := s.VectorStore.DeleteByFilter(ctx, filter)
+	if err != nil {
+		return stats, err
+	}
+	_, err = s.keywords.DeleteByFilter(ctx, filter)
+	return stats, err
+}
+
+// SearchWithFilter 在被包装的向量存储上执行元数据过滤检索
+func (s *KeywordIndexedStore) SearchWithFilter(ctx context.Context, query []float32, topK int, filter MetadataFilter) ([]RetrievalResult, error) {
+	return searchWithFilter(ctx, s.VectorStore, query, topK, filter)
+}
+
+// Clear 清空存储
+func (s *KeywordIndexedStore) Clear(ctx context.Context) error {
+	if err := s.VectorStore.Clear(ctx); err != nil {
+		return err
+	}
+	return s.keywords.Clear(ctx)
+}
+
+// Version 返回被包装向量存储的内容版本，未实现 VersionedStore 时返回 0
+func (s *KeywordIndexedStore) Version() uint64 {
+	if versioned, ok := s.VectorStore.(VersionedStore); ok {
+		return versioned.Version()
+	}
+	return 0
+}
+
+// compile-time interface check
+var _ Retriever = (*BM25Retriever)(nil)
+var _ VectorStore = (*KeywordIndexedStore)(nil)
+var _ FilteredSearcher = (*KeywordIndexedStore)(nil)
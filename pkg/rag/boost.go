@@ -0,0 +1,108 @@
+package rag
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultRecencyScale 默认的时效衰减尺度，与记忆检索一致按天衰减
+const DefaultRecencyScale = 24 * time.Hour
+
+// RecencyBooster 按文档时效调整检索分数的后处理器
+//
+// 与记忆检索的时效加权一致，时效分数为 1 / (1 + age/scale)，age 取 CreatedAt，
+// 没有时取 IngestedAt。分数调整为 score × (1 − weight + weight × recency)，
+// weight 为时效所占比重（0~1），没有时间信息的块视为最旧。
+type RecencyBooster struct {
+	weight float32
+	scale  time.Duration
+}
+
+// NewRecencyBooster 创建时效加权后处理器，scale 不大于 0 时使用 DefaultRecencyScale
+func NewRecencyBooster(weight float32, scale time.Duration) *RecencyBooster {
+	if weight < 0 {
+		weight = 0
+	}
+	if weight > 1 {
+		weight = 1
+	}
+	if scale <= 0 {
+		scale = DefaultRecencyScale
+	}
+	return &RecencyBooster{weight: weight, scale: scale}
+}
+
+// Process 按时效加权并重新排序
+func (b *RecencyBooster) Process(ctx context.Context, query string, results []RetrievalResult) ([]RetrievalResult, error) {
+	now := time.Now()
+	return boostResults(results, func(res RetrievalResult) float32 {
+		return 1 - b.weight + b.weight*b.recency(res.Chunk.Metadata, now)
+	}), nil
+}
+
+// recency 返回元数据在 now 时的时效分数
+func (b *RecencyBooster) recency(m DocumentMetadata, now time.Time) float32 {
+	ts := m.CreatedAt
+	if ts.IsZero() {
+		ts = m.IngestedAt
+	}
+	if ts.IsZero() {
+		return 0
+	}
+	age := now.Sub(ts)
+	if age < 0 {
+		age = 0
+	}
+	return float32(1 / (1 + float64(age)/float64(b.scale)))
+}
+
+// SourceWeightBooster 按来源权威度调整检索分数的后处理器
+//
+// 分数乘以来源对应的权重后重新排序。来源先精确匹配，否则使用最长的前缀匹配
+// （如 "https://docs.example.com/" 匹配该站点下的全部页面），没有匹配的来源保持原分数。
+type SourceWeightBooster struct {
+	weights map[string]float32
+}
+
+// NewSourceWeightBooster 创建来源加权后处理器，weights 为来源（或来源前缀）到分数乘数的映射
+func NewSourceWeightBooster(weights map[string]float32) *SourceWeightBooster {
+	return &SourceWeightBooster{weights: weights}
+}
+
+// Process 按来源加权并重新排序
+func (b *SourceWeightBooster) Process(ctx context.Context, query string, results []RetrievalResult) ([]RetrievalResult, error) {
+	return boostResults(results, func(res RetrievalResult) float32 {
+		return b.weight(res.Chunk.Metadata.Source)
+	}), nil
+}
+
+// weight 返回来源的权重，没有匹配时返回 1
+func (b *SourceWeightBooster) weight(source string) float32 {
+	if w, ok := b.weights[source]; ok {
+		return w
+	}
+	weight, longest := float32(1), 0
+	for prefix, w := range b.weights {
+		if len(prefix) > longest && strings.HasPrefix(source, prefix) {
+			weight, longest = w, len(prefix)
+		}
+	}
+	return weight
+}
+
+// boostResults 将每个结果的分数乘以 factor 后按分数降序稳定排序
+func boostResults(results []RetrievalResult, factor func(RetrievalResult) float32) []RetrievalResult {
+	boosted := make([]RetrievalResult, len(results))
+	for i, res := range results {
+		res.Score *= factor(res)
+		boosted[i] = res
+	}
+	sort.SliceStable(boosted, func(i, j int) bool { return boosted[i].Score > boosted[j].Score })
+	return boosted
+}
+
+// compile-time interface check
+var _ PostProcessor = (*RecencyBooster)(nil)
+var _ PostProcessor = (*SourceWeightBooster)(nil)
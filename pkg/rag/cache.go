@@ -0,0 +1,243 @@
+package rag
+
+import (
+	"container/list"
+	"context"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// 查询缓存默认参数
+const (
+	// DefaultQueryCacheSize 默认最多缓存的查询数量
+	DefaultQueryCacheSize = 1000
+	// DefaultQueryCacheTTL 默认缓存有效期
+	DefaultQueryCacheTTL = 5 * time.Minute
+)
+
+// VersionedStore 报告内容版本的存储
+//
+// 每次写入、删除或清空后 Version 递增，QueryCache 据此判断缓存的检索结果是否过期。
+// 内置的 InMemoryVectorStore、StoreVectorStore 和 KeywordIndexedStore 均已实现；
+// StoreVectorStore 只感知经由本进程的写入，其他进程写入同一集合时依赖 TTL 过期。
+type VersionedStore interface {
+	// Version 返回当前内容版本
+	Version() uint64
+}
+
+// QueryCacheStats 查询缓存统计
+type QueryCacheStats struct {
+	Hits          int64 `json:"hits"`
+	Misses        int64 `json:"misses"`
+	Evictions     int64 `json:"evictions"`
+	Invalidations int64 `json:"invalidations"`
+	Size          int   `json:"size"`
+}
+
+// HitRate 返回命中率，没有查询时返回 0
+func (s QueryCacheStats) HitRate() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
+
+// QueryCache 检索结果缓存
+//
+// 包装任意 Retriever，按 (query, topK) 缓存检索结果，相同查询不再重复调用嵌入器和向量存储。
+// 缓存按 LRU 淘汰，条目超过 TTL 后失效；设置 VersionedStore 后，存储版本变化（新的写入、
+// 删除或清空）时整个缓存自动失效。检索出错时不缓存。
+type QueryCache struct {
+	retriever Retriever
+	source    VersionedStore
+	capacity  int
+	ttl       time.Duration
+
+	mu            sync.Mutex
+	entries       map[string]*list.Element
+	order         *list.List
+	version       uint64
+	hits          int64
+	misses        int64
+	evictions     int64
+	invalidations int64
+}
+
+// queryCacheEntry LRU 链表中的缓存项
+type queryCacheEntry struct {
+	key       string
+	results   []RetrievalResult
+	expiresAt time.Time
+}
+
+// QueryCacheOption 查询缓存选项
+type QueryCacheOption func(*QueryCache)
+
+// WithQueryCacheSize 设置最多缓存的查询数量（默认 DefaultQueryCacheSize），0 表示不缓存
+func WithQueryCacheSize(size int) QueryCacheOption {
+	return func(c *QueryCache) {
+		if size >= 0 {
+			c.capacity = size
+		}
+	}
+}
+
+// WithQueryCacheTTL 设置缓存有效期（默认 DefaultQueryCacheTTL），0 表示不按时间过期
+func WithQueryCacheTTL(ttl time.Duration) QueryCacheOption {
+	return func(c *QueryCache) {
+		if ttl >= 0 {
+			c.ttl = ttl
+		}
+	}
+}
+
+// WithQueryCacheSource 设置被检索的存储，存储版本变化时缓存自动失效
+func WithQueryCacheSource(source VersionedStore) QueryCacheOption {
+	return func(c *QueryCache) {
+		c.source = source
+	}
+}
+
+// NewQueryCache 创建检索结果缓存
+func NewQueryCache(retriever Retriever, opts ...QueryCacheOption) *QueryCache {
+	c := &QueryCache{
+		retriever: retriever,
+		capacity:  DefaultQueryCacheSize,
+		ttl:       DefaultQueryCacheTTL,
+		entries:   make(map[string]*list.Element),
+		order:     list.New(),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.source != nil {
+		c.version = c.source.Version()
+	}
+	return c
+}
+
+// Retrieve 返回缓存的检索结果，未命中时调用被包装的检索器并缓存结果
+func (c *QueryCache) Retrieve(ctx context.Context, query string, topK int) ([]RetrievalResult, error) {
+	key := strconv.Itoa(topK) + "\x00" + query
+
+	c.mu.Lock()
+	c.syncVersionLocked()
+	if results, ok := c.lookupLocked(key); ok {
+		c.hits++
+		c.mu.Unlock()
+		return results, nil
+	}
+	c.misses++
+	version := c.version
+	c.mu.Unlock()
+
+	results, err := c.retriever.Retrieve(ctx, query, topK)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	// 检索期间存储发生变化时结果可能已过期，不写入缓存
+	c.syncVersionLocked()
+	if c.version == version {
+		c.insertLocked(key, copyResults(results))
+	}
+	c.mu.Unlock()
+	return results, nil
+}
+
+// Invalidate 清空缓存
+func (c *QueryCache) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.invalidateLocked()
+}
+
+// Stats 返回缓存统计
+func (c *QueryCache) Stats() QueryCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return QueryCacheStats{
+		Hits:          c.hits,
+		Misses:        c.misses,
+		Evictions:     c.evictions,
+		Invalidations: c.invalidations,
+		Size:          c.order.Len(),
+	}
+}
+
+// syncVersionLocked 存储版本变化时清空缓存（调用方需持有锁）
+func (c *QueryCache) syncVersionLocked() {
+	if c.source == nil {
+		return
+	}
+	if version := c.source.Version(); version != c.version {
+		c.version = version
+		c.invalidateLocked()
+	}
+}
+
+// invalidateLocked 清空缓存并计数（调用方需持有锁）
+func (c *QueryCache) invalidateLocked() {
+	c.entries = make(map[string]*list.Element)
+	c.order.Init()
+	c.invalidations++
+}
+
+// lookupLocked 查找未过期的缓存项，过期项被移除（调用方需持有锁）
+func (c *QueryCache) lookupLocked(key string) ([]RetrievalResult, bool) {
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*queryCacheEntry)
+	if !entry.expiresAt.IsZero() && time.Now().After(entry.expiresAt) {
+		c.order.Remove(elem)
+		delete(c.entries, key)
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	return copyResults(entry.results), true
+}
+
+// insertLocked 插入或刷新缓存项，超出容量时淘汰最久未使用的项（调用方需持有锁）
+func (c *QueryCache) insertLocked(key string, results []RetrievalResult) {
+	if c.capacity <= 0 {
+		return
+	}
+	var expiresAt time.Time
+	if c.ttl > 0 {
+		expiresAt = time.Now().Add(c.ttl)
+	}
+	if elem, ok := c.entries[key]; ok {
+		entry := elem.Value.(*queryCacheEntry)
+		entry.results, entry.expiresAt = results, expiresAt
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&queryCacheEntry{key: key, results: results, expiresAt: expiresAt})
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*queryCacheEntry).key)
+		c.evictions++
+	}
+}
+
+// copyResults 复制结果切片，避免调用方修改缓存内容
+func copyResults(results []RetrievalResult) []RetrievalResult {
+	if results == nil {
+		return nil
+	}
+	copied := make([]RetrievalResult, len(results))
+	copy(copied, results)
+	return copied
+}
+
+// compile-time interface check
+var _ Retriever = (*QueryCache)(nil)
+var _ VersionedStore = (*InMemoryVectorStore)(nil)
+var _ VersionedStore = (*StoreVectorStore)(nil)
+var _ VersionedStore = (*KeywordIndexedStore)(nil)
@@ -0,0 +1,158 @@
+package rag
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ahhsitt/helloagents-go/pkg/memory/store"
+)
+
+// DefaultCheckpointCollection 摄取检查点的默认集合名
+const DefaultCheckpointCollection = "rag_ingest_checkpoints"
+
+// IngestStatus 文档的摄取状态
+type IngestStatus string
+
+const (
+	// IngestStatusInProgress 正在摄取，部分块可能已写入向量存储
+	IngestStatusInProgress IngestStatus = "in_progress"
+	// IngestStatusDone 全部块已写入
+	IngestStatusDone IngestStatus = "done"
+	// IngestStatusFailed 摄取失败，下次摄取从已完成的块继续
+	IngestStatusFailed IngestStatus = "failed"
+)
+
+// IngestCheckpoint 文档的摄取检查点
+type IngestCheckpoint struct {
+	// DocumentID 文档 ID
+	DocumentID string `json:"document_id"`
+	// Status 摄取状态
+	Status IngestStatus `json:"status"`
+	// Record 摄取完成后写入增量索引的指纹
+	Record IngestRecord `json:"record"`
+	// TotalChunks 本次需要嵌入的块数量
+	TotalChunks int `json:"total_chunks"`
+	// Completed 已嵌入并写入向量存储的块 ID -> 块指纹
+	Completed map[string]string `json:"completed,omitempty"`
+	// Batches 包含该文档的块并已完成的嵌入批次数
+	Batches int `json:"batches"`
+	// Error 失败原因
+	Error string `json:"error,omitempty"`
+	// UpdatedAt 更新时间
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// IngestReport 摄取报告
+type IngestReport struct {
+	IngestStats
+	// Succeeded 本次成功写入的文档 ID
+	Succeeded []string `json:"succeeded,omitempty"`
+	// Failed 摄取失败的文档 ID -> 失败原因
+	Failed map[string]string `json:"failed,omitempty"`
+	// Skipped 内容未变化或在本批中重复而跳过的文档 ID
+	Skipped []string `json:"skipped,omitempty"`
+	// ResumedChunks 从检查点恢复、无需重新嵌入的块数量
+	ResumedChunks int `json:"resumed_chunks"`
+	// Batches 完成的嵌入批次数
+	Batches int `json:"batches"`
+}
+
+// WithIngestCheckpoints 将摄取进度持久化到文档存储，使中断的摄取可以恢复
+//
+// 每个嵌入批次写入向量存储后立即更新所含文档的检查点（已完成的块、批次数）。
+// 摄取中断后用相同的文档再次调用 Ingest，内容未变的文档只嵌入尚未完成的块；
+// 已完成的文档即使增量索引丢失（如使用内存索引时进程重启）也不会重新嵌入。
+// collection 为空时使用 DefaultCheckpointCollection。
+func WithIngestCheckpoints(docs store.DocumentStore, collection string) RAGPipelineOption {
+	return func(p *DefaultRAGPipeline) {
+		if collection == "" {
+			collection = DefaultCheckpointCollection
+		}
+		p.checkpoints = &checkpointer{docs: docs, collection: collection}
+	}
+}
+
+// IngestCheckpoint 返回文档的摄取检查点，未启用检查点或没有记录时返回 nil
+func (p *DefaultRAGPipeline) IngestCheckpoint(ctx context.Context, docID string) (*IngestCheckpoint, error) {
+	if p.checkpoints == nil {
+		return nil, nil
+	}
+	return p.checkpoints.get(ctx, docID)
+}
+
+// checkpointer 读写文档存储中的摄取检查点
+type checkpointer struct {
+	docs       store.DocumentStore
+	collection string
+	mu         sync.Mutex
+}
+
+// get 读取检查点，不存在时返回 nil
+func (c *checkpointer) get(ctx context.Context, docID string) (*IngestCheckpoint, error) {
+	doc, err := c.docs.Get(ctx, c.collection, docID)
+	if errors.Is(err, store.ErrNotFound) || (err == nil && doc == nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var cp IngestCheckpoint
+	if err := json.Unmarshal([]byte(doc.Content), &cp); err != nil {
+		return nil, fmt.Errorf("failed to decode ingest checkpoint %s: %w", docID, err)
+	}
+	return &cp, nil
+}
+
+// put 写入检查点
+func (c *checkpointer) put(ctx context.Context, cp *IngestCheckpoint) error {
+	cp.UpdatedAt = time.Now()
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	return c.docs.Put(ctx, c.collection, cp.DocumentID, store.Document{
+		ID:        cp.DocumentID,
+		Content:   string(data),
+		Metadata:  map[string]interface{}{"status": string(cp.Status), "hash": cp.Record.Hash},
+		CreatedAt: cp.UpdatedAt,
+		UpdatedAt: cp.UpdatedAt,
+	})
+}
+
+// delete 删除检查点（不存在时忽略）
+func (c *checkpointer) delete(ctx context.Context, docID string) error {
+	if err := c.docs.Delete(ctx, c.collection, docID); err != nil && !errors.Is(err, store.ErrNotFound) {
+		return err
+	}
+	return nil
+}
+
+// completeBatch 记录一批已写入的块，更新所含文档的检查点
+func (c *checkpointer) completeBatch(ctx context.Context, active map[string]*IngestCheckpoint, batch []DocumentChunk) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	touched := make(map[string]*IngestCheckpoint)
+	for _, chunk := range batch {
+		cp, ok := active[chunk.DocumentID]
+		if !ok {
+			continue
+		}
+		if cp.Completed == nil {
+			cp.Completed = make(map[string]string)
+		}
+		cp.Completed[chunk.ID] = hashChunk(chunk)
+		touched[chunk.DocumentID] = cp
+	}
+	for _, cp := range touched {
+		cp.Batches++
+		if err := c.put(ctx, cp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
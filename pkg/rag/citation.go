@@ -0,0 +1,225 @@
+package rag
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ahhsitt/helloagents-go/pkg/core/llm"
+)
+
+// DefaultCitationPrompt 默认引用提示模板，包含两个 %s 占位符（带编号的上下文、问题）
+const DefaultCitationPrompt = `请根据以下带编号的上下文回答问题。
+每个句子末尾必须用方括号标注支持该句的上下文编号，如 [1] 或 [1][3]；只使用上下文中的信息，不要编造。
+如果上下文中没有相关信息，请直接说明无法回答。
+
+上下文：
+%s
+
+问题：%s
+
+回答：`
+
+// CitedSentence 回答中的一个句子及其引用
+type CitedSentence struct {
+	// Text 去掉引用标记后的句子
+	Text string `json:"text"`
+	// Sources 引用的来源下标（对应 RAGResponse.Sources，从 0 开始）
+	Sources []int `json:"sources,omitempty"`
+	// InvalidMarkers 超出来源范围的引用编号（从 1 开始，与回答中的标记一致）
+	InvalidMarkers []int `json:"invalid_markers,omitempty"`
+	// Uncited 句子没有引用任何有效来源（校验未通过）
+	Uncited bool `json:"uncited,omitempty"`
+}
+
+// CitedAnswer 带引用的回答
+type CitedAnswer struct {
+	// Answer 生成的原始回答（保留 [n] 标记）
+	Answer string `json:"answer"`
+	// Sentences 逐句的引用映射
+	Sentences []CitedSentence `json:"sentences"`
+}
+
+// Uncited 返回未引用任何有效来源的句子
+func (a *CitedAnswer) Uncited() []CitedSentence {
+	var uncited []CitedSentence
+	for _, s := range a.Sentences {
+		if s.Uncited {
+			uncited = append(uncited, s)
+		}
+	}
+	return uncited
+}
+
+// Verified 所有句子都引用了有效来源时返回 true
+func (a *CitedAnswer) Verified() bool {
+	for _, s := range a.Sentences {
+		if s.Uncited || len(s.InvalidMarkers) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// CitingAnswerGenerator 生成带引用回答的生成器
+//
+// 管道的 Query 检测到该接口时，将逐句引用映射写入 RAGResponse.Citations。
+type CitingAnswerGenerator interface {
+	AnswerGenerator
+	// GenerateCited 基于上下文生成带引用的回答
+	GenerateCited(ctx context.Context, query string, context *RAGContext) (*CitedAnswer, error)
+}
+
+// CitationGenerator 要求 LLM 用 [n] 标记引用来源的回答生成器
+//
+// 上下文按检索结果顺序编号，[n] 对应 RAGResponse.Sources[n-1]。支持流式输出（片段中保留标记），
+// 流式结束后可用 ParseCitations 解析完整回答。
+type CitationGenerator struct {
+	*LLMAnswerGenerator
+}
+
+// NewCitationGenerator 创建引用回答生成器，默认使用 DefaultCitationPrompt
+func NewCitationGenerator(provider llm.Provider, opts ...LLMAnswerGeneratorOption) *CitationGenerator {
+	opts = append([]LLMAnswerGeneratorOption{WithAnswerPrompt(DefaultCitationPrompt)}, opts...)
+	return &CitationGenerator{LLMAnswerGenerator: NewLLMAnswerGenerator(provider, opts...)}
+}
+
+// GenerateCited 生成回答并解析逐句引用
+func (g *CitationGenerator) GenerateCited(ctx context.Context, query string, ragContext *RAGContext) (*CitedAnswer, error) {
+	answer, err := g.Generate(ctx, query, ragContext)
+	if err != nil {
+		return nil, err
+	}
+	numSources := 0
+	if ragContext != nil {
+		numSources = len(ragContext.Results)
+	}
+	return &CitedAnswer{
+		Answer:    answer,
+		Sentences: ParseCitations(answer, numSources),
+	}, nil
+}
+
+// citationMarker 引用标记，支持 [1]、[1, 2]、[1，2]
+var citationMarker = regexp.MustCompile(`\[(\d+(?:\s*[,，]\s*\d+)*)\]`)
+
+// ParseCitations 将回答切分为句子并解析每句的引用标记
+//
+// numSources 为上下文来源数量，超出范围的编号记入 InvalidMarkers。句末标点之后紧跟的标记
+// （如 "Go 是编译型语言。[1]"）归属前一句；只有标记没有文字的片段合并到前一句。
+func ParseCitations(answer string, numSources int) []CitedSentence {
+	var sentences []CitedSentence
+	for _, raw := range splitCitedSentences(answer) {
+		sentence := CitedSentence{Text: stripCitationMarkers(raw)}
+		seen := make(map[int]bool)
+		for _, m := range citationMarker.FindAllStringSubmatch(raw, -1) {
+			for _, part := range strings.FieldsFunc(m[1], func(r rune) bool { return r == ',' || r == '，' || unicode.IsSpace(r) }) {
+				n, err := strconv.Atoi(part)
+				if err != nil || seen[n] {
+					continue
+				}
+				seen[n] = true
+				if n >= 1 && n <= numSources {
+					sentence.Sources = append(sentence.Sources, n-1)
+				} else {
+					sentence.InvalidMarkers = append(sentence.InvalidMarkers, n)
+				}
+			}
+		}
+
+		if sentence.Text == "" {
+			if len(sentences) > 0 {
+				prev := &sentences[len(sentences)-1]
+				prev.Sources = appendUnique(prev.Sources, sentence.Sources...)
+				prev.InvalidMarkers = appendUnique(prev.InvalidMarkers, sentence.InvalidMarkers...)
+			}
+			continue
+		}
+		sentences = append(sentences, sentence)
+	}
+
+	for i := range sentences {
+		sentences[i].Uncited = len(sentences[i].Sources) == 0
+	}
+	return sentences
+}
+
+// splitCitedSentences 按句末标点和换行切分，句末标点后的引用标记保留在该句中
+func splitCitedSentences(text string) []string {
+	var (
+		sentences []string
+		start     int
+	)
+	cut := func(end int) {
+		if s := strings.TrimSpace(text[start:end]); s != "" {
+			sentences = append(sentences, s)
+		}
+		start = end
+	}
+
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		end := i + size
+
+		switch {
+		case r == '\n':
+			cut(end)
+		case r == '。' || r == '！' || r == '？' || r == '!' || r == '?' || r == '.':
+			// 小数点和缩写中间的点不切分
+			if r == '.' && end < len(text) && !unicode.IsSpace(rune(text[end])) && text[end] != '[' {
+				break
+			}
+			end = consumeTrailingMarkers(text, end)
+			cut(end)
+		}
+		i = end
+	}
+	cut(len(text))
+	return sentences
+}
+
+// consumeTrailingMarkers 跳过 pos 之后紧跟的引用标记（允许标记前有空格），返回新位置
+func consumeTrailingMarkers(text string, pos int) int {
+	for {
+		rest := strings.TrimLeft(text[pos:], " \t")
+		loc := citationMarker.FindStringIndex(rest)
+		if loc == nil || loc[0] != 0 {
+			return pos
+		}
+		pos = len(text) - len(rest) + loc[1]
+	}
+}
+
+// stripCitationMarkers 去掉引用标记及标记前多余的空格
+func stripCitationMarkers(s string) string {
+	s = citationMarker.ReplaceAllString(s, "")
+	s = strings.Join(strings.Fields(s), " ")
+	for _, p := range []string{".", ",", "!", "?", ";", ":"} {
+		s = strings.ReplaceAll(s, " "+p, p)
+	}
+	return strings.TrimSpace(s)
+}
+
+// appendUnique 追加不重复的值
+func appendUnique(dst []int, values ...int) []int {
+	for _, v := range values {
+		found := false
+		for _, d := range dst {
+			if d == v {
+				found = true
+				break
+			}
+		}
+		if !found {
+			dst = append(dst, v)
+		}
+	}
+	return dst
+}
+
+// compile-time interface check
+var _ CitingAnswerGenerator = (*CitationGenerator)(nil)
+var _ StreamingAnswerGenerator = (*CitationGenerator)(nil)
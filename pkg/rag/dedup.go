@@ -0,0 +1,367 @@
+package rag
+
+import (
+	"hash/fnv"
+	"strings"
+	"sync"
+)
+
+const (
+	// MetadataDuplicateOf DedupKeep 策略下，近重复块对应的首个块 ID（Metadata.Custom 键）
+	MetadataDuplicateOf = "duplicate_of"
+	// MetadataDuplicateSources DedupMerge 策略下，被合并的近重复块的来源列表（Metadata.Custom 键）
+	MetadataDuplicateSources = "duplicate_sources"
+)
+
+// DedupPolicy 近重复块的处理策略
+type DedupPolicy string
+
+const (
+	// DedupSkip 丢弃近重复块
+	DedupSkip DedupPolicy = "skip"
+	// DedupMerge 丢弃近重复块，并把它的来源和标签合并到首个块的元数据中
+	DedupMerge DedupPolicy = "merge"
+	// DedupKeep 保留近重复块，在 Metadata.Custom[MetadataDuplicateOf] 中标注首个块 ID
+	DedupKeep DedupPolicy = "keep"
+)
+
+// MinHash 签名参数：32 个 band × 每个 band 4 行，候选阈值约为 (1/32)^(1/4) ≈ 0.42
+const (
+	minHashBands = 32
+	minHashRows  = 4
+	minHashSize  = minHashBands * minHashRows
+)
+
+// DefaultDedupThreshold 默认的近重复相似度阈值（估计的 Jaccard 相似度）
+const DefaultDedupThreshold = 0.9
+
+// DedupStats 去重统计
+type DedupStats struct {
+	// Checked 检查过的块数量
+	Checked int64 `json:"checked"`
+	// Duplicates 判定为近重复的块数量
+	Duplicates int64 `json:"duplicates"`
+	// Dropped 按策略丢弃（未写入向量存储）的近重复块数量
+	Dropped int64 `json:"dropped"`
+}
+
+// ChunkDeduplicator 基于 MinHash 的近重复块检测器
+//
+// 块内容切分为词元后按 ShingleSize 个连续词元组成 shingle，计算 MinHash 签名，
+// 用 LSH 分桶找到候选块，估计的 Jaccard 相似度不低于阈值时判定为近重复。
+// 通过 WithDeduplicator 接入管道后，摄取时新块与已写入的块以及同批次中更早的块比较。
+//
+// 索引保存在内存中（DedupMerge 策略需要保留首个块的副本以便更新元数据），
+// 重启后从空索引开始，只对之后摄取的块去重。
+type ChunkDeduplicator struct {
+	threshold   float64
+	policy      DedupPolicy
+	shingleSize int
+
+	mu      sync.Mutex
+	entries map[string]*dedupEntry
+	buckets map[uint64][]string
+	stats   DedupStats
+}
+
+// dedupEntry 已索引的块
+type dedupEntry struct {
+	chunk     DocumentChunk
+	signature []uint64
+}
+
+// ChunkDeduplicatorOption 去重器选项
+type ChunkDeduplicatorOption func(*ChunkDeduplicator)
+
+// WithDedupThreshold 设置近重复阈值（0~1，默认 DefaultDedupThreshold）
+func WithDedupThreshold(threshold float64) ChunkDeduplicatorOption {
+	return func(d *ChunkDeduplicator) {
+		if threshold > 0 && threshold <= 1 {
+			d.threshold = threshold
+		}
+	}
+}
+
+// WithDedupPolicy 设置近重复块的处理策略（默认 DedupSkip）
+func WithDedupPolicy(policy DedupPolicy) ChunkDeduplicatorOption {
+	return func(d *ChunkDeduplicator) {
+		d.policy = policy
+	}
+}
+
+// WithDedupShingleSize 设置 shingle 包含的连续词元数（默认 3）
+func WithDedupShingleSize(n int) ChunkDeduplicatorOption {
+	return func(d *ChunkDeduplicator) {
+		if n > 0 {
+			d.shingleSize = n
+		}
+	}
+}
+
+// NewChunkDeduplicator 创建近重复块检测器
+func NewChunkDeduplicator(opts ...ChunkDeduplicatorOption) *ChunkDeduplicator {
+	d := &ChunkDeduplicator{
+		threshold:   DefaultDedupThreshold,
+		policy:      DedupSkip,
+		shingleSize: 3,
+		entries:     make(map[string]*dedupEntry),
+		buckets:     make(map[uint64][]string),
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+// Policy 返回处理策略
+func (d *ChunkDeduplicator) Policy() DedupPolicy {
+	return d.policy
+}
+
+// Stats 返回累计的去重统计
+func (d *ChunkDeduplicator) Stats() DedupStats {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.stats
+}
+
+// Similarity 返回两段文本估计的 Jaccard 相似度
+func (d *ChunkDeduplicator) Similarity(a, b string) float64 {
+	return signatureSimilarity(d.signature(a), d.signature(b))
+}
+
+// Check 检查块是否与已索引的块近重复，返回最相似的首个块 ID
+//
+// 不是近重复时把块加入索引，之后的块会与它比较；同一 ID 的块重复检查时视为更新。
+// 没有词元的块（如只有标点）不参与去重。
+func (d *ChunkDeduplicator) Check(chunk DocumentChunk) (string, bool) {
+	signature := d.signature(chunk.Content)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.stats.Checked++
+	d.removeLocked(chunk.ID)
+	if signature == nil {
+		return "", false
+	}
+
+	best, bestScore := "", 0.0
+	seen := make(map[string]struct{})
+	for band := 0; band < minHashBands; band++ {
+		for _, id := range d.buckets[bandKey(signature, band)] {
+			if _, ok := seen[id]; ok {
+				continue
+			}
+			seen[id] = struct{}{}
+			if score := signatureSimilarity(signature, d.entries[id].signature); score >= d.threshold && score > bestScore {
+				best, bestScore = id, score
+			}
+		}
+	}
+	if best != "" {
+		d.stats.Duplicates++
+		if d.policy != DedupKeep {
+			d.stats.Dropped++
+		}
+		return best, true
+	}
+
+	d.entries[chunk.ID] = &dedupEntry{chunk: chunk, signature: signature}
+	for band := 0; band < minHashBands; band++ {
+		key := bandKey(signature, band)
+		d.buckets[key] = append(d.buckets[key], chunk.ID)
+	}
+	return "", false
+}
+
+// merge 把近重复块的来源和标签合并到首个块，返回更新后的首个块
+func (d *ChunkDeduplicator) merge(canonicalID string, duplicate DocumentChunk) (DocumentChunk, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	entry, ok := d.entries[canonicalID]
+	if !ok {
+		return DocumentChunk{}, false
+	}
+
+	metadata := entry.chunk.Metadata
+	metadata.Custom = make(map[string]interface{}, len(entry.chunk.Metadata.Custom)+1)
+	for k, v := range entry.chunk.Metadata.Custom {
+		metadata.Custom[k] = v
+	}
+	source := duplicate.Metadata.Source
+	if source == "" {
+		source = duplicate.DocumentID
+	}
+	sources := stringList(metadata.Custom[MetadataDuplicateSources])
+	if source != "" && source != metadata.Source && !containsID(sources, source) {
+		metadata.Custom[MetadataDuplicateSources] = append(append([]string(nil), sources...), source)
+	}
+	tags := append([]string(nil), metadata.Tags...)
+	for _, tag := range duplicate.Metadata.Tags {
+		if !containsID(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	metadata.Tags = tags
+
+	entry.chunk.Metadata = metadata
+	return entry.chunk, true
+}
+
+// update 用写入向量存储后的块（含向量）更新索引中的副本
+func (d *ChunkDeduplicator) update(chunks []DocumentChunk) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, chunk := range chunks {
+		if entry, ok := d.entries[chunk.ID]; ok {
+			entry.chunk = chunk
+		}
+	}
+}
+
+// RemoveChunks 从索引中移除块
+func (d *ChunkDeduplicator) RemoveChunks(ids []string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, id := range ids {
+		d.removeLocked(id)
+	}
+}
+
+// removeLocked 移除块及其分桶（调用方需持有锁）
+func (d *ChunkDeduplicator) removeLocked(id string) {
+	entry, ok := d.entries[id]
+	if !ok {
+		return
+	}
+	delete(d.entries, id)
+	for band := 0; band < minHashBands; band++ {
+		key := bandKey(entry.signature, band)
+		ids := withoutIDs(d.buckets[key], map[string]struct{}{id: {}})
+		if len(ids) == 0 {
+			delete(d.buckets, key)
+		} else {
+			d.buckets[key] = ids
+		}
+	}
+}
+
+// signature 计算文本的 MinHash 签名，没有词元时返回 nil
+func (d *ChunkDeduplicator) signature(text string) []uint64 {
+	tokens := tokenizeKeywords(text)
+	if len(tokens) == 0 {
+		return nil
+	}
+	signature := make([]uint64, minHashSize)
+	for i := range signature {
+		signature[i] = ^uint64(0)
+	}
+
+	n := min(d.shingleSize, len(tokens))
+	for i := 0; i+n <= len(tokens); i++ {
+		h := fnv.New64a()
+		h.Write([]byte(strings.Join(tokens[i:i+n], " ")))
+		shingle := h.Sum64()
+		for j := range signature {
+			if v := mix64(shingle ^ minHashSeeds[j]); v < signature[j] {
+				signature[j] = v
+			}
+		}
+	}
+	return signature
+}
+
+// signatureSimilarity 签名中相同位置取值相等的比例，即 Jaccard 相似度的估计
+func signatureSimilarity(a, b []uint64) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+	equal := 0
+	for i := range a {
+		if a[i] == b[i] {
+			equal++
+		}
+	}
+	return float64(equal) / float64(len(a))
+}
+
+// bandKey 计算签名第 band 个分段的桶键
+func bandKey(signature []uint64, band int) uint64 {
+	key := uint64(band) + 1
+	for _, v := range signature[band*minHashRows : (band+1)*minHashRows] {
+		key = mix64(key ^ v)
+	}
+	return key
+}
+
+// minHashSeeds 每个哈希函数的种子
+var minHashSeeds = func() []uint64 {
+	seeds := make([]uint64, minHashSize)
+	state := uint64(0x2545f4914f6cdd1d)
+	for i := range seeds {
+		state += 0x9e3779b97f4a7c15
+		seeds[i] = mix64(state)
+	}
+	return seeds
+}()
+
+// mix64 splitmix64 混合函数
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+// dedupChunk 按去重策略处理块，返回处理后的块以及是否写入
+func (p *DefaultRAGPipeline) dedupChunk(chunk DocumentChunk, stats *IngestStats, merged map[string]DocumentChunk) (DocumentChunk, bool) {
+	canonical, dup := p.dedup.Check(chunk)
+	if !dup {
+		return chunk, true
+	}
+	stats.DuplicateChunks++
+
+	switch p.dedup.Policy() {
+	case DedupKeep:
+		custom := make(map[string]interface{}, len(chunk.Metadata.Custom)+1)
+		for k, v := range chunk.Metadata.Custom {
+			custom[k] = v
+		}
+		custom[MetadataDuplicateOf] = canonical
+		chunk.Metadata.Custom = custom
+		return chunk, true
+	case DedupMerge:
+		if updated, ok := p.dedup.merge(canonical, chunk); ok {
+			merged[canonical] = updated
+		}
+	}
+	stats.DroppedDuplicates++
+	return chunk, false
+}
+
+// applyMerged 把合并后的元数据写回本次写入的块，返回需要重新写入的、之前已写入的首个块
+func applyMerged(chunks []DocumentChunk, merged map[string]DocumentChunk) []DocumentChunk {
+	if len(merged) == 0 {
+		return nil
+	}
+	pending := make(map[string]DocumentChunk, len(merged))
+	for id, chunk := range merged {
+		pending[id] = chunk
+	}
+	for i := range chunks {
+		if chunk, ok := pending[chunks[i].ID]; ok {
+			chunks[i].Metadata = chunk.Metadata
+			delete(pending, chunks[i].ID)
+		}
+	}
+
+	var rewrite []DocumentChunk
+	for _, chunk := range pending {
+		if len(chunk.Vector) > 0 {
+			rewrite = append(rewrite, chunk)
+		}
+	}
+	return rewrite
+}
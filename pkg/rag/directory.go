@@ -0,0 +1,284 @@
+package rag
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// LoaderFactory 根据文件来源和内容创建加载器
+type LoaderFactory func(source string, reader io.Reader) DocumentLoader
+
+// defaultLoaderFactories 按扩展名选择的内置加载器
+func defaultLoaderFactories() map[string]LoaderFactory {
+	text := func(source string, r io.Reader) DocumentLoader { return NewTextLoader(source, r) }
+	markdown := func(source string, r io.Reader) DocumentLoader { return NewMarkdownLoader(source, r) }
+	htmlPage := func(source string, r io.Reader) DocumentLoader { return NewHTMLLoader(source, r) }
+	pdf := func(source string, r io.Reader) DocumentLoader { return NewPDFLoader(source, r) }
+	jsonl := func(source string, r io.Reader) DocumentLoader { return NewJSONLLoader(source, r) }
+	return map[string]LoaderFactory{
+		".txt":      text,
+		".text":     text,
+		".md":       markdown,
+		".markdown": markdown,
+		".html":     htmlPage,
+		".htm":      htmlPage,
+		".pdf":      pdf,
+		".csv":      func(source string, r io.Reader) DocumentLoader { return NewCSVLoader(source, r) },
+		".tsv": func(source string, r io.Reader) DocumentLoader {
+			return NewCSVLoader(source, r, WithCSVDelimiter('\t'))
+		},
+		".jsonl":  jsonl,
+		".ndjson": jsonl,
+	}
+}
+
+// FileLoader 单个文件加载器，按扩展名选择内置加载器
+type FileLoader struct {
+	path      string
+	factories map[string]LoaderFactory
+}
+
+// NewFileLoader 创建文件加载器
+func NewFileLoader(path string) *FileLoader {
+	return &FileLoader{
+		path:      path,
+		factories: defaultLoaderFactories(),
+	}
+}
+
+// Load 加载文档，文件修改时间作为未设置 CreatedAt 的文档的创建时间
+func (l *FileLoader) Load(ctx context.Context) ([]Document, error) {
+	factory, ok := l.factories[strings.ToLower(filepath.Ext(l.path))]
+	if !ok {
+		return nil, fmt.Errorf("unsupported file type: %s", l.path)
+	}
+	return loadFile(ctx, l.path, factory)
+}
+
+// SupportedExtensions 支持的文件扩展名
+func (l *FileLoader) SupportedExtensions() []string {
+	return sortedExtensions(l.factories)
+}
+
+// loadFile 打开文件并用 factory 创建的加载器加载
+func loadFile(ctx context.Context, filePath string, factory LoaderFactory) ([]Document, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	docs, err := factory(filePath, f).Load(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s: %w", filePath, err)
+	}
+
+	if info, err := f.Stat(); err == nil {
+		for i := range docs {
+			if docs[i].Metadata.CreatedAt.IsZero() {
+				docs[i].Metadata.CreatedAt = info.ModTime()
+			}
+		}
+	}
+	return docs, nil
+}
+
+// sortedExtensions 返回排序后的扩展名
+func sortedExtensions(factories map[string]LoaderFactory) []string {
+	exts := make([]string, 0, len(factories))
+	for ext := range factories {
+		exts = append(exts, ext)
+	}
+	sort.Strings(exts)
+	return exts
+}
+
+// DirectoryLoaderOption 目录加载器选项
+type DirectoryLoaderOption func(*DirectoryLoader)
+
+// WithGlob 只加载匹配任一模式的文件
+//
+// 模式使用 / 分隔、相对于根目录匹配，** 匹配任意层目录（如 docs/**/*.md）；
+// 不含 / 的模式只匹配文件名（如 *.md）。
+func WithGlob(patterns ...string) DirectoryLoaderOption {
+	return func(l *DirectoryLoader) {
+		l.include = append(l.include, patterns...)
+	}
+}
+
+// WithExcludeGlob 跳过匹配任一模式的文件和目录，模式语法同 WithGlob
+func WithExcludeGlob(patterns ...string) DirectoryLoaderOption {
+	return func(l *DirectoryLoader) {
+		l.exclude = append(l.exclude, patterns...)
+	}
+}
+
+// WithRecursive 设置是否递归子目录（默认递归）
+func WithRecursive(recursive bool) DirectoryLoaderOption {
+	return func(l *DirectoryLoader) {
+		l.recursive = recursive
+	}
+}
+
+// WithLoaderFor 为扩展名（如 ".rst"）注册或替换加载器
+func WithLoaderFor(ext string, factory LoaderFactory) DirectoryLoaderOption {
+	return func(l *DirectoryLoader) {
+		l.factories[strings.ToLower(ext)] = factory
+	}
+}
+
+// WithSkipErrors 跳过加载失败的文件而不是返回错误，失败的文件可通过 Errors 查看
+func WithSkipErrors() DirectoryLoaderOption {
+	return func(l *DirectoryLoader) {
+		l.skipErrors = true
+	}
+}
+
+// DirectoryLoader 目录加载器
+//
+// 遍历目录，按扩展名为每个文件选择加载器（内置纯文本、Markdown、HTML、PDF、CSV/TSV、JSONL），
+// 没有对应加载器的文件被跳过，隐藏文件和目录（以 . 开头）也被跳过。文件按路径顺序加载。
+type DirectoryLoader struct {
+	root       string
+	include    []string
+	exclude    []string
+	recursive  bool
+	skipErrors bool
+	factories  map[string]LoaderFactory
+	errors     map[string]error
+}
+
+// NewDirectoryLoader 创建目录加载器
+func NewDirectoryLoader(root string, opts ...DirectoryLoaderOption) *DirectoryLoader {
+	l := &DirectoryLoader{
+		root:      root,
+		recursive: true,
+		factories: defaultLoaderFactories(),
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// Load 加载目录中的文档
+func (l *DirectoryLoader) Load(ctx context.Context) ([]Document, error) {
+	l.errors = nil
+	var docs []Document
+
+	err := filepath.WalkDir(l.root, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(l.root, filePath)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == "." {
+			return nil
+		}
+
+		if d.IsDir() {
+			if !l.recursive || strings.HasPrefix(d.Name(), ".") || matchAnyGlob(l.exclude, rel) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasPrefix(d.Name(), ".") || matchAnyGlob(l.exclude, rel) {
+			return nil
+		}
+		if len(l.include) > 0 && !matchAnyGlob(l.include, rel) {
+			return nil
+		}
+		factory, ok := l.factories[strings.ToLower(path.Ext(rel))]
+		if !ok {
+			return nil
+		}
+
+		loaded, err := loadFile(ctx, filePath, factory)
+		if err != nil {
+			if !l.skipErrors {
+				return err
+			}
+			if l.errors == nil {
+				l.errors = make(map[string]error)
+			}
+			l.errors[filePath] = err
+			return nil
+		}
+		docs = append(docs, loaded...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return docs, nil
+}
+
+// Errors 返回最近一次 Load 中被跳过的文件及其错误（需启用 WithSkipErrors）
+func (l *DirectoryLoader) Errors() map[string]error {
+	return l.errors
+}
+
+// SupportedExtensions 支持的文件扩展名
+func (l *DirectoryLoader) SupportedExtensions() []string {
+	return sortedExtensions(l.factories)
+}
+
+// matchAnyGlob 判断相对路径是否匹配任一模式
+func matchAnyGlob(patterns []string, rel string) bool {
+	for _, pattern := range patterns {
+		if matchGlob(pattern, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchGlob 匹配支持 ** 的 glob 模式，不含 / 的模式只匹配文件名
+func matchGlob(pattern, rel string) bool {
+	pattern = strings.TrimPrefix(filepath.ToSlash(pattern), "./")
+	if !strings.Contains(pattern, "/") {
+		ok, _ := path.Match(pattern, path.Base(rel))
+		return ok
+	}
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(rel, "/"))
+}
+
+// matchSegments 逐段匹配，** 匹配零或多段
+func matchSegments(pattern, segments []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(segments); i++ {
+				if matchSegments(pattern[1:], segments[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(segments) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], segments[0]); !ok {
+			return false
+		}
+		pattern, segments = pattern[1:], segments[1:]
+	}
+	return len(segments) == 0
+}
+
+// compile-time interface check
+var _ DocumentLoader = (*FileLoader)(nil)
+var _ DocumentLoader = (*DirectoryLoader)(nil)
@@ -25,16 +25,29 @@ type DocumentMetadata struct {
 	CreatedAt time.Time `json:"created_at,omitempty"`
 	// Tags 标签
 	Tags []string `json:"tags,omitempty"`
+	// IngestedAt 摄取时间（由管道在摄取时设置）
+	IngestedAt time.Time `json:"ingested_at,omitempty"`
+	// TTL 存活时间，摄取时据此计算 ExpiresAt，0 表示不过期
+	TTL time.Duration `json:"ttl,omitempty"`
+	// ExpiresAt 过期时间，零值表示不过期；过期的块不再被检索，并由 TTLSweeper 清理
+	ExpiresAt time.Time `json:"expires_at,omitempty"`
 	// Custom 自定义元数据
 	Custom map[string]interface{} `json:"custom,omitempty"`
 }
 
+// Expired 检查元数据在 now 时是否已过期
+func (m DocumentMetadata) Expired(now time.Time) bool {
+	return !m.ExpiresAt.IsZero() && !now.Before(m.ExpiresAt)
+}
+
 // DocumentChunk 文档分块
 type DocumentChunk struct {
 	// ID 分块唯一标识
 	ID string `json:"id"`
 	// DocumentID 所属文档 ID
 	DocumentID string `json:"document_id"`
+	// ParentID 父块 ID（父子分块模式下子块指向其所属父块，见 ChunkStore）
+	ParentID string `json:"parent_id,omitempty"`
 	// Content 分块内容
 	Content string `json:"content"`
 	// Index 分块索引（在文档中的位置）
@@ -0,0 +1,108 @@
+package rag
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/ahhsitt/helloagents-go/pkg/core/retry"
+)
+
+// IngestProgressFunc 摄取进度回调，每完成一批嵌入调用一次
+//
+// embedded 为已完成嵌入的块数，total 为本次需要嵌入的块总数。回调按完成顺序串行调用。
+type IngestProgressFunc func(embedded, total int)
+
+// embedChunks 按批次并发嵌入块内容并写回块的向量，每批嵌入成功后调用 onBatch
+//
+// 任一批次重试后仍失败（或 onBatch 返回错误）时取消其余批次并返回错误。
+func (p *DefaultRAGPipeline) embedChunks(ctx context.Context, chunks []DocumentChunk, onBatch func(ctx context.Context, batch []DocumentChunk) error) error {
+	total := len(chunks)
+	batchSize := p.embedBatchSize
+	if batchSize <= 0 || batchSize > total {
+		batchSize = total
+	}
+	policy := retry.Default()
+	if p.embedRetry != nil {
+		policy = *p.embedRetry
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	batches := make(chan int)
+	go func() {
+		defer close(batches)
+		for start := 0; start < total; start += batchSize {
+			select {
+			case batches <- start:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		embedded int
+		firstErr error
+	)
+	workers := min(max(p.embedConcurrency, 1), (total+batchSize-1)/batchSize)
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for start := range batches {
+				end := min(start+batchSize, total)
+				err := p.embedBatch(ctx, policy, chunks[start:end])
+				if err != nil {
+					err = fmt.Errorf("failed to generate embeddings: %w", err)
+				} else if onBatch != nil {
+					err = onBatch(ctx, chunks[start:end])
+				}
+
+				mu.Lock()
+				if err != nil {
+					if firstErr == nil {
+						firstErr = err
+						cancel()
+					}
+				} else if firstErr == nil {
+					embedded += end - start
+					if p.ingestProgress != nil {
+						p.ingestProgress(embedded, total)
+					}
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return firstErr
+}
+
+// embedBatch 嵌入一批块，失败时按策略重试
+func (p *DefaultRAGPipeline) embedBatch(ctx context.Context, policy retry.Policy, batch []DocumentChunk) error {
+	contents := make([]string, len(batch))
+	for i, chunk := range batch {
+		contents[i] = chunk.Content
+	}
+
+	var vectors [][]float32
+	err := policy.DoNamed(ctx, "rag.ingest", func(ctx context.Context) error {
+		var err error
+		vectors, err = p.embedder.Embed(ctx, contents)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if len(vectors) != len(batch) {
+		return fmt.Errorf("embedder returned %d vectors for %d texts", len(vectors), len(batch))
+	}
+	for i := range batch {
+		batch[i].Vector = vectors[i]
+	}
+	return nil
+}
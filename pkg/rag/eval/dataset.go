@@ -0,0 +1,77 @@
+// Package eval 实现 RAG 管道评估
+//
+// 用标注好的查询集运行 RAG 管道，计算两类指标：
+// - 检索指标：recall@k、MRR、nDCG@k（需要标注相关文档/块 ID）
+// - 生成指标：faithfulness、answer relevancy、context recall（需要参考答案），由 LLM 评委打分
+//
+// 评估结果使用 pkg/evaluation 的 EvalResult，可用 Exporter 导出为 JSONL、JSON 和 Markdown 报告。
+package eval
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Case 标注的评估查询
+type Case struct {
+	// ID 查询唯一标识
+	ID string `json:"id"`
+
+	// Query 查询文本
+	Query string `json:"query"`
+
+	// RelevantIDs 相关的文档 ID 或块 ID，检索结果的块 ID 或文档 ID 命中即视为相关
+	RelevantIDs []string `json:"relevant_ids,omitempty"`
+
+	// ReferenceAnswer 参考答案（用于 context recall）
+	ReferenceAnswer string `json:"reference_answer,omitempty"`
+
+	// Category 查询类别（用于分类统计）
+	Category string `json:"category,omitempty"`
+}
+
+// LoadCases 从文件加载评估查询
+//
+// 支持 JSON 数组或 JSONL（每行一个对象）格式，缺少 ID 的查询按行号编号。
+func LoadCases(path string) ([]Case, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取数据集失败: %w", err)
+	}
+
+	var cases []Case
+	if trimmed := strings.TrimSpace(string(data)); strings.HasPrefix(trimmed, "[") {
+		if err := json.Unmarshal([]byte(trimmed), &cases); err != nil {
+			return nil, fmt.Errorf("解析数据集失败: %w", err)
+		}
+	} else {
+		scanner := bufio.NewScanner(strings.NewReader(trimmed))
+		scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+		line := 0
+		for scanner.Scan() {
+			line++
+			text := strings.TrimSpace(scanner.Text())
+			if text == "" {
+				continue
+			}
+			var c Case
+			if err := json.Unmarshal([]byte(text), &c); err != nil {
+				return nil, fmt.Errorf("解析第 %d 行失败: %w", line, err)
+			}
+			cases = append(cases, c)
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("读取数据集失败: %w", err)
+		}
+	}
+
+	for i := range cases {
+		if cases[i].ID == "" {
+			cases[i].ID = fmt.Sprintf("q%d", i+1)
+		}
+	}
+	return cases, nil
+}
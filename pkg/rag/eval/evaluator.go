@@ -0,0 +1,262 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ahhsitt/helloagents-go/pkg/core/llm"
+	"github.com/ahhsitt/helloagents-go/pkg/evaluation"
+	"github.com/ahhsitt/helloagents-go/pkg/rag"
+)
+
+// BenchmarkName 评估结果中的基准名称
+const BenchmarkName = "RAG"
+
+// Pipeline 被评估的 RAG 管道
+//
+// rag.DefaultRAGPipeline 满足该接口。未设置生成器时只计算检索指标。
+type Pipeline interface {
+	// Query 查询并生成回答
+	Query(ctx context.Context, query string, topK int) (*rag.RAGResponse, error)
+}
+
+// Evaluator RAG 评估器
+type Evaluator struct {
+	// cases 评估查询
+	cases []Case
+
+	// judge 生成指标评委（为 nil 时不计算生成指标）
+	judge *Judge
+
+	// topK 检索数量，也是 recall@k 和 nDCG@k 的 k
+	topK int
+
+	// name 结果中的被评估对象名称
+	name string
+
+	// passThreshold 样本通过的最低分数
+	passThreshold float64
+}
+
+// Option 评估器选项
+type Option func(*Evaluator)
+
+// WithJudge 使用 LLM 评委计算 faithfulness、answer relevancy 和 context recall
+func WithJudge(provider llm.Provider) Option {
+	return func(e *Evaluator) {
+		e.judge = NewJudge(provider)
+	}
+}
+
+// WithTopK 设置检索数量（默认 5）
+func WithTopK(k int) Option {
+	return func(e *Evaluator) {
+		if k > 0 {
+			e.topK = k
+		}
+	}
+}
+
+// WithName 设置结果中的被评估对象名称（默认 "rag-pipeline"），用于对比不同配置
+func WithName(name string) Option {
+	return func(e *Evaluator) {
+		e.name = name
+	}
+}
+
+// WithPassThreshold 设置样本通过的最低分数（默认 0.5），样本分数为各指标的平均值
+func WithPassThreshold(threshold float64) Option {
+	return func(e *Evaluator) {
+		e.passThreshold = threshold
+	}
+}
+
+// NewEvaluator 创建 RAG 评估器
+//
+// 参数:
+//   - cases: 评估查询（可用 LoadCases 从文件加载）
+//   - opts: 评估器选项
+func NewEvaluator(cases []Case, opts ...Option) *Evaluator {
+	e := &Evaluator{
+		cases:         cases,
+		topK:          5,
+		name:          "rag-pipeline",
+		passThreshold: 0.5,
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// Name 返回评估器名称
+func (e *Evaluator) Name() string {
+	return BenchmarkName
+}
+
+// Evaluate 执行完整评估
+func (e *Evaluator) Evaluate(ctx context.Context, pipeline Pipeline, opts ...evaluation.EvalOption) (*evaluation.EvalResult, error) {
+	config := evaluation.DefaultEvalConfig()
+	config.ApplyOptions(opts...)
+
+	startTime := time.Now()
+	result := &evaluation.EvalResult{
+		BenchmarkName:   e.Name(),
+		AgentName:       e.name,
+		DetailedResults: make([]*evaluation.SampleResult, 0, len(e.cases)),
+		EvaluationTime:  startTime,
+	}
+
+	total := len(e.cases)
+	if config.MaxSamples > 0 && config.MaxSamples < total {
+		total = config.MaxSamples
+	}
+	result.TotalSamples = total
+
+	for i := 0; i < total; i++ {
+		select {
+		case <-ctx.Done():
+			return result, ctx.Err()
+		default:
+		}
+
+		sampleResult, err := e.evaluateWithTimeout(ctx, pipeline, e.cases[i], config.Timeout)
+		if err != nil {
+			sampleResult = &evaluation.SampleResult{
+				SampleID: e.cases[i].ID,
+				Category: e.cases[i].Category,
+				Error:    err.Error(),
+			}
+		}
+
+		result.DetailedResults = append(result.DetailedResults, sampleResult)
+		if sampleResult.Success {
+			result.SuccessCount++
+		}
+
+		if config.ProgressCallback != nil {
+			config.ProgressCallback(i+1, total)
+		}
+	}
+
+	result.TotalDuration = time.Since(startTime)
+	if result.TotalSamples > 0 {
+		result.OverallAccuracy = float64(result.SuccessCount) / float64(result.TotalSamples)
+	}
+
+	metrics := NewMetrics()
+	result.Metrics = metrics.Compute(result.DetailedResults)
+	result.Metrics.Extra["k"] = e.topK
+	result.CategoryMetrics = metrics.ComputeCategoryMetrics(result.DetailedResults)
+
+	return result, nil
+}
+
+// evaluateWithTimeout 在单样本超时内评估
+func (e *Evaluator) evaluateWithTimeout(ctx context.Context, pipeline Pipeline, c Case, timeout time.Duration) (*evaluation.SampleResult, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return e.EvaluateSample(ctx, pipeline, c)
+}
+
+// EvaluateSample 评估单个查询
+//
+// 查询失败时返回带 Error 的结果；评委调用失败时跳过对应指标并记录在 Details["judge_errors"] 中。
+func (e *Evaluator) EvaluateSample(ctx context.Context, pipeline Pipeline, c Case) (*evaluation.SampleResult, error) {
+	startTime := time.Now()
+	result := &evaluation.SampleResult{
+		SampleID: c.ID,
+		Category: c.Category,
+		Expected: c.ReferenceAnswer,
+		Details:  make(map[string]interface{}),
+	}
+
+	resp, err := pipeline.Query(ctx, c.Query, e.topK)
+	if err != nil {
+		result.Error = err.Error()
+		result.ExecutionTime = time.Since(startTime)
+		return result, nil
+	}
+
+	var results []rag.RetrievalResult
+	if resp.Context != nil {
+		results = resp.Context.Results
+	}
+	result.Predicted = resp.Answer
+	result.AgentResponse = resp.Answer
+
+	retrieved := make([]string, len(results))
+	contexts := make([]string, len(results))
+	for i, r := range results {
+		retrieved[i] = r.Chunk.ID
+		contexts[i] = r.Chunk.Content
+	}
+	result.Details["query"] = c.Query
+	result.Details["retrieved_ids"] = retrieved
+
+	// 检索指标
+	if len(c.RelevantIDs) > 0 {
+		matched := MatchRelevant(results, c.RelevantIDs)
+		recall := RecallAtK(matched, len(c.RelevantIDs), e.topK)
+		result.Details[MetricRecall] = recall
+		result.Details[MetricMRR] = ReciprocalRank(matched)
+		result.Details[MetricNDCG] = NDCGAtK(matched, len(c.RelevantIDs), e.topK)
+		hit := 0.0
+		if recall > 0 {
+			hit = 1
+		}
+		result.Details[MetricHit] = hit
+	}
+
+	// 生成指标
+	if e.judge != nil {
+		var judgeErrors []string
+		record := func(name string, score JudgeScore, err error) {
+			if err != nil {
+				judgeErrors = append(judgeErrors, fmt.Sprintf("%s: %v", name, err))
+				return
+			}
+			result.Details[name] = score.Score
+			if score.Reason != "" {
+				result.Details[name+"_reason"] = score.Reason
+			}
+		}
+
+		if resp.Answer != "" {
+			score, err := e.judge.Faithfulness(ctx, resp.Answer, contexts)
+			record(MetricFaithfulness, score, err)
+			score, err = e.judge.AnswerRelevancy(ctx, c.Query, resp.Answer)
+			record(MetricAnswerRelevancy, score, err)
+		}
+		if c.ReferenceAnswer != "" {
+			score, err := e.judge.ContextRecall(ctx, c.ReferenceAnswer, contexts)
+			record(MetricContextRecall, score, err)
+		}
+		if len(judgeErrors) > 0 {
+			result.Details["judge_errors"] = judgeErrors
+		}
+	}
+
+	// 样本分数为已计算指标（不含 hit）的平均值
+	sum, n := 0.0, 0
+	for _, name := range metricOrder {
+		if name == MetricHit {
+			continue
+		}
+		if v, ok := result.Details[name].(float64); ok {
+			sum += v
+			n++
+		}
+	}
+	if n > 0 {
+		result.Score = sum / float64(n)
+		result.Success = result.Score >= e.passThreshold
+	}
+
+	result.ExecutionTime = time.Since(startTime)
+	return result, nil
+}
@@ -0,0 +1,176 @@
+package eval
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ahhsitt/helloagents-go/pkg/core/llm"
+	"github.com/ahhsitt/helloagents-go/pkg/evaluation"
+	"github.com/ahhsitt/helloagents-go/pkg/rag"
+)
+
+// stubPipeline 按查询返回固定的检索结果和回答
+type stubPipeline struct {
+	responses map[string]*rag.RAGResponse
+}
+
+func (p *stubPipeline) Query(ctx context.Context, query string, topK int) (*rag.RAGResponse, error) {
+	resp, ok := p.responses[query]
+	if !ok {
+		return nil, errors.New("unknown query")
+	}
+	return resp, nil
+}
+
+// judgeProvider 按提示类型返回固定评分
+type judgeProvider struct {
+	calls int
+}
+
+func (p *judgeProvider) Name() string  { return "judge" }
+func (p *judgeProvider) Model() string { return "judge-model" }
+func (p *judgeProvider) Close() error  { return nil }
+
+func (p *judgeProvider) Generate(ctx context.Context, req llm.Request) (llm.Response, error) {
+	p.calls++
+	prompt := req.Messages[len(req.Messages)-1].Content
+	switch {
+	case strings.Contains(prompt, "忠实度"):
+		return llm.Response{Content: `{"score": 9, "reason": "supported"}`}, nil
+	case strings.Contains(prompt, "相关性"):
+		return llm.Response{Content: `{"score": 7}`}, nil
+	default:
+		return llm.Response{Content: "score: 5"}, nil
+	}
+}
+
+func (p *judgeProvider) GenerateStream(ctx context.Context, req llm.Request) (<-chan llm.StreamChunk, <-chan error) {
+	return nil, nil
+}
+
+func (p *judgeProvider) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	return nil, nil
+}
+
+func response(answer string, ids ...string) *rag.RAGResponse {
+	results := make([]rag.RetrievalResult, len(ids))
+	for i, id := range ids {
+		results[i] = rag.RetrievalResult{Chunk: rag.DocumentChunk{ID: id + "-0", DocumentID: id, Content: "content of " + id}}
+	}
+	return &rag.RAGResponse{Answer: answer, Context: &rag.RAGContext{Results: results}}
+}
+
+func TestEvaluator_Evaluate(t *testing.T) {
+	pipeline := &stubPipeline{responses: map[string]*rag.RAGResponse{
+		"good": response("Go is compiled.", "go", "rust"),
+		"bad":  response("", "rust"),
+	}}
+	cases := []Case{
+		{ID: "q1", Query: "good", RelevantIDs: []string{"go"}, ReferenceAnswer: "Go is compiled.", Category: "lang"},
+		{ID: "q2", Query: "bad", RelevantIDs: []string{"go"}, Category: "lang"},
+		{ID: "q3", Query: "missing"},
+	}
+	judge := &judgeProvider{}
+
+	var progress []int
+	result, err := NewEvaluator(cases, WithJudge(judge), WithTopK(3), WithName("baseline")).
+		Evaluate(context.Background(), pipeline, withProgress(&progress))
+	if err != nil {
+		t.Fatalf("Evaluate() error = %v", err)
+	}
+
+	if result.BenchmarkName != BenchmarkName || result.AgentName != "baseline" || result.TotalSamples != 3 {
+		t.Errorf("result header = %s/%s/%d", result.BenchmarkName, result.AgentName, result.TotalSamples)
+	}
+	if len(progress) != 3 {
+		t.Errorf("progress callback called %d times, want 3", len(progress))
+	}
+	if judge.calls != 3 {
+		t.Errorf("judge called %d times, want 3 (faithfulness, relevancy, context recall for q1)", judge.calls)
+	}
+
+	q1 := result.DetailedResults[0]
+	if q1.Details[MetricRecall] != 1.0 || q1.Details[MetricMRR] != 1.0 || q1.Details[MetricNDCG] != 1.0 {
+		t.Errorf("q1 retrieval metrics = %v", q1.Details)
+	}
+	if q1.Details[MetricFaithfulness] != 0.9 || q1.Details[MetricAnswerRelevancy] != 0.7 || q1.Details[MetricContextRecall] != 0.5 {
+		t.Errorf("q1 judge metrics = %v", q1.Details)
+	}
+	if !q1.Success {
+		t.Errorf("q1 should pass, score = %f", q1.Score)
+	}
+
+	q2 := result.DetailedResults[1]
+	if q2.Success || q2.Details[MetricRecall] != 0.0 {
+		t.Errorf("q2 should fail with zero recall: %+v", q2)
+	}
+	if _, ok := q2.Details[MetricFaithfulness]; ok {
+		t.Error("faithfulness should be skipped without an answer")
+	}
+	if result.DetailedResults[2].Error == "" {
+		t.Error("q3 should record the pipeline error")
+	}
+
+	if result.SuccessCount != 1 || result.Metrics.DimensionScores[MetricRecall] != 0.5 {
+		t.Errorf("summary = %d successes, %v", result.SuccessCount, result.Metrics.DimensionScores)
+	}
+	if result.CategoryMetrics["lang"].Total != 2 {
+		t.Errorf("category metrics = %+v", result.CategoryMetrics["lang"])
+	}
+
+	dir := t.TempDir()
+	exporter := NewExporter()
+	if err := exporter.Export(result, filepath.Join(dir, "results.jsonl")); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	if err := exporter.ExportJSON(result, filepath.Join(dir, "results.json")); err != nil {
+		t.Fatalf("ExportJSON() error = %v", err)
+	}
+	if err := exporter.ExportMarkdownReport(result, filepath.Join(dir, "report.md")); err != nil {
+		t.Fatalf("ExportMarkdownReport() error = %v", err)
+	}
+
+	jsonl, _ := os.ReadFile(filepath.Join(dir, "results.jsonl"))
+	if lines := strings.Split(strings.TrimSpace(string(jsonl)), "\n"); len(lines) != 3 || !strings.Contains(lines[0], `"recall":1`) {
+		t.Errorf("unexpected JSONL export:\n%s", jsonl)
+	}
+	report, _ := os.ReadFile(filepath.Join(dir, "report.md"))
+	for _, want := range []string{"# RAG 评估报告", "Recall@k", "忠实度", "样本: q2"} {
+		if !strings.Contains(string(report), want) {
+			t.Errorf("report missing %q:\n%s", want, report)
+		}
+	}
+}
+
+func TestLoadCases(t *testing.T) {
+	dir := t.TempDir()
+	jsonl := filepath.Join(dir, "cases.jsonl")
+	_ = os.WriteFile(jsonl, []byte(`{"query": "what is go", "relevant_ids": ["go"]}
+
+{"id": "custom", "query": "what is rust", "reference_answer": "A language."}
+`), 0644)
+
+	cases, err := LoadCases(jsonl)
+	if err != nil {
+		t.Fatalf("LoadCases() error = %v", err)
+	}
+	if len(cases) != 2 || cases[0].ID != "q1" || cases[0].RelevantIDs[0] != "go" || cases[1].ID != "custom" {
+		t.Errorf("cases = %+v", cases)
+	}
+
+	array := filepath.Join(dir, "cases.json")
+	_ = os.WriteFile(array, []byte(`[{"id": "a", "query": "x"}]`), 0644)
+	if cases, err := LoadCases(array); err != nil || len(cases) != 1 || cases[0].ID != "a" {
+		t.Errorf("LoadCases(array) = %+v, %v", cases, err)
+	}
+}
+
+func withProgress(progress *[]int) evaluation.EvalOption {
+	return evaluation.WithProgressCallback(func(done, total int) {
+		*progress = append(*progress, done)
+	})
+}
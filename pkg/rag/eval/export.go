@@ -0,0 +1,206 @@
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/ahhsitt/helloagents-go/pkg/evaluation"
+)
+
+// ExportEntry RAG 评估导出条目
+type ExportEntry struct {
+	ID           string             `json:"id"`
+	Query        string             `json:"query"`
+	Answer       string             `json:"answer,omitempty"`
+	RetrievedIDs []string           `json:"retrieved_ids,omitempty"`
+	Metrics      map[string]float64 `json:"metrics"`
+	Score        float64            `json:"score"`
+	Success      bool               `json:"success"`
+	Error        string             `json:"error,omitempty"`
+}
+
+// metricNames 指标的中文名称
+var metricNames = map[string]string{
+	MetricRecall:          "Recall@k",
+	MetricMRR:             "MRR",
+	MetricNDCG:            "nDCG@k",
+	MetricHit:             "命中率",
+	MetricFaithfulness:    "忠实度",
+	MetricAnswerRelevancy: "回答相关性",
+	MetricContextRecall:   "上下文召回",
+}
+
+// Exporter RAG 评估结果导出器
+type Exporter struct{}
+
+// NewExporter 创建导出器
+func NewExporter() *Exporter {
+	return &Exporter{}
+}
+
+// Export 导出逐条评估结果
+//
+// 输出 JSONL 格式，每行一个 JSON 对象
+func (e *Exporter) Export(result *evaluation.EvalResult, outputPath string) error {
+	file, err := createOutput(outputPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	for _, sr := range result.DetailedResults {
+		entry := ExportEntry{
+			ID:      sr.SampleID,
+			Answer:  sr.AgentResponse,
+			Metrics: make(map[string]float64),
+			Score:   sr.Score,
+			Success: sr.Success,
+			Error:   sr.Error,
+		}
+		entry.Query, _ = sr.Details["query"].(string)
+		entry.RetrievedIDs, _ = sr.Details["retrieved_ids"].([]string)
+		for _, name := range metricOrder {
+			if v, ok := sr.Details[name].(float64); ok {
+				entry.Metrics[name] = v
+			}
+		}
+
+		if err := encoder.Encode(entry); err != nil {
+			return fmt.Errorf("写入条目失败: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ExportJSON 导出 JSON 格式结果
+func (e *Exporter) ExportJSON(result *evaluation.EvalResult, outputPath string) error {
+	file, err := createOutput(outputPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(result)
+}
+
+// ExportMarkdownReport 导出 Markdown 报告
+func (e *Exporter) ExportMarkdownReport(result *evaluation.EvalResult, outputPath string) error {
+	file, err := createOutput(outputPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	// 写入报告头
+	fmt.Fprintf(file, "# RAG 评估报告\n\n")
+	fmt.Fprintf(file, "## 概览\n\n")
+	fmt.Fprintf(file, "- **基准**: %s\n", result.BenchmarkName)
+	fmt.Fprintf(file, "- **管道**: %s\n", result.AgentName)
+	fmt.Fprintf(file, "- **评估时间**: %s\n", result.EvaluationTime.Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(file, "- **总耗时**: %s\n\n", result.TotalDuration)
+
+	// 总体指标
+	fmt.Fprintf(file, "## 总体指标\n\n")
+	fmt.Fprintf(file, "| 指标 | 值 |\n")
+	fmt.Fprintf(file, "|------|----|\n")
+	fmt.Fprintf(file, "| 总样本数 | %d |\n", result.TotalSamples)
+	fmt.Fprintf(file, "| 通过数 | %d |\n", result.SuccessCount)
+	fmt.Fprintf(file, "| 通过率 | %.2f%% |\n", result.OverallAccuracy*100)
+	if result.Metrics != nil {
+		fmt.Fprintf(file, "| 平均分 | %.3f |\n", result.Metrics.AverageScore)
+		if k, ok := result.Metrics.Extra["k"].(int); ok {
+			fmt.Fprintf(file, "| k | %d |\n", k)
+		}
+	}
+	fmt.Fprintf(file, "\n")
+
+	// 各指标
+	if result.Metrics != nil && len(result.Metrics.DimensionScores) > 0 {
+		fmt.Fprintf(file, "## 各项指标\n\n")
+		fmt.Fprintf(file, "| 指标 | 平均值 | 样本数 |\n")
+		fmt.Fprintf(file, "|------|--------|--------|\n")
+		for _, name := range sortedMetricNames(result.Metrics.DimensionScores) {
+			label := metricNames[name]
+			if label == "" {
+				label = name
+			}
+			samples, _ := result.Metrics.Extra[name+"_samples"].(int)
+			fmt.Fprintf(file, "| %s | %.3f | %d |\n", label, result.Metrics.DimensionScores[name], samples)
+		}
+		fmt.Fprintf(file, "\n")
+	}
+
+	// 分类别指标
+	if len(result.CategoryMetrics) > 0 {
+		fmt.Fprintf(file, "## 分类别指标\n\n")
+		fmt.Fprintf(file, "| 类别 | 总数 | 通过数 | 通过率 | 平均分 |\n")
+		fmt.Fprintf(file, "|------|------|--------|--------|--------|\n")
+		categories := make([]string, 0, len(result.CategoryMetrics))
+		for category := range result.CategoryMetrics {
+			categories = append(categories, category)
+		}
+		sort.Strings(categories)
+		for _, category := range categories {
+			cm := result.CategoryMetrics[category]
+			fmt.Fprintf(file, "| %s | %d | %d | %.2f%% | %.3f |\n",
+				category, cm.Total, cm.Success, cm.Accuracy*100, cm.AverageScore)
+		}
+		fmt.Fprintf(file, "\n")
+	}
+
+	// 未通过样本
+	var failed []*evaluation.SampleResult
+	for _, sr := range result.DetailedResults {
+		if !sr.Success {
+			failed = append(failed, sr)
+		}
+	}
+
+	if len(failed) > 0 {
+		fmt.Fprintf(file, "## 未通过样本（前 10 个）\n\n")
+		maxShow := 10
+		if len(failed) < maxShow {
+			maxShow = len(failed)
+		}
+		for i := 0; i < maxShow; i++ {
+			sr := failed[i]
+			fmt.Fprintf(file, "### 样本: %s (得分: %.3f)\n\n", sr.SampleID, sr.Score)
+			if query, ok := sr.Details["query"].(string); ok {
+				fmt.Fprintf(file, "**查询**: %s\n\n", query)
+			}
+			if sr.AgentResponse != "" {
+				fmt.Fprintf(file, "**回答**: %s\n\n", sr.AgentResponse)
+			}
+			if retrieved, ok := sr.Details["retrieved_ids"].([]string); ok && len(retrieved) > 0 {
+				fmt.Fprintf(file, "**检索结果**: %v\n\n", retrieved)
+			}
+			if sr.Error != "" {
+				fmt.Fprintf(file, "**错误**: %s\n\n", sr.Error)
+			}
+			fmt.Fprintf(file, "---\n\n")
+		}
+	}
+
+	return nil
+}
+
+// createOutput 创建输出文件（确保目录存在）
+func createOutput(outputPath string) (*os.File, error) {
+	dir := filepath.Dir(outputPath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("创建目录失败: %w", err)
+	}
+
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return nil, fmt.Errorf("创建文件失败: %w", err)
+	}
+	return file, nil
+}
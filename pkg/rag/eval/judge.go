@@ -0,0 +1,133 @@
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ahhsitt/helloagents-go/pkg/core/llm"
+	"github.com/ahhsitt/helloagents-go/pkg/core/message"
+)
+
+// judgeSystemPrompt LLM 评委系统提示
+const judgeSystemPrompt = `你是一个严格的 RAG 系统评估专家。请按要求给出 0 到 10 之间的整数分数，
+并以 JSON 格式返回：{"score": <0-10>, "reason": "<简短理由>"}`
+
+// faithfulnessPrompt 忠实度：回答中的陈述是否都能由上下文支持
+const faithfulnessPrompt = `请评估回答的忠实度：回答中的每个陈述是否都能由上下文直接支持。
+10 表示所有陈述都有上下文依据，0 表示大部分陈述在上下文中找不到依据或与上下文矛盾。
+
+上下文：
+%s
+
+回答：
+%s`
+
+// answerRelevancyPrompt 回答相关性：回答是否直接、完整地回应了问题
+const answerRelevancyPrompt = `请评估回答与问题的相关性：回答是否直接、完整地回应了问题，没有答非所问或冗余内容。
+10 表示完全切题，0 表示与问题无关。
+
+问题：%s
+
+回答：
+%s`
+
+// contextRecallPrompt 上下文召回：参考答案中的信息有多少能在上下文中找到
+const contextRecallPrompt = `请评估检索上下文的召回程度：参考答案中的每条信息是否都能在上下文中找到。
+10 表示参考答案的全部信息都包含在上下文中，0 表示上下文不包含参考答案的任何信息。
+
+上下文：
+%s
+
+参考答案：
+%s`
+
+// JudgeScore 评委评分
+type JudgeScore struct {
+	// Score 归一化到 0-1 的分数
+	Score float64 `json:"score"`
+	// Reason 评分理由
+	Reason string `json:"reason,omitempty"`
+}
+
+// Judge 使用 LLM 评估生成质量
+type Judge struct {
+	provider llm.Provider
+}
+
+// NewJudge 创建 LLM 评委
+func NewJudge(provider llm.Provider) *Judge {
+	return &Judge{provider: provider}
+}
+
+// Faithfulness 评估回答是否忠实于检索上下文
+func (j *Judge) Faithfulness(ctx context.Context, answer string, contexts []string) (JudgeScore, error) {
+	return j.score(ctx, fmt.Sprintf(faithfulnessPrompt, formatContexts(contexts), answer))
+}
+
+// AnswerRelevancy 评估回答与问题的相关性
+func (j *Judge) AnswerRelevancy(ctx context.Context, query, answer string) (JudgeScore, error) {
+	return j.score(ctx, fmt.Sprintf(answerRelevancyPrompt, query, answer))
+}
+
+// ContextRecall 评估检索上下文对参考答案的覆盖程度
+func (j *Judge) ContextRecall(ctx context.Context, reference string, contexts []string) (JudgeScore, error) {
+	return j.score(ctx, fmt.Sprintf(contextRecallPrompt, formatContexts(contexts), reference))
+}
+
+// score 调用 LLM 并解析分数
+func (j *Judge) score(ctx context.Context, prompt string) (JudgeScore, error) {
+	resp, err := j.provider.Generate(ctx, llm.Request{
+		Messages: []message.Message{
+			message.NewSystemMessage(judgeSystemPrompt),
+			message.NewUserMessage(prompt),
+		},
+	})
+	if err != nil {
+		return JudgeScore{}, err
+	}
+	return parseJudgeScore(resp.Content)
+}
+
+var (
+	// jsonObjectPattern 响应中的 JSON 对象（可能包裹在代码块或说明文字中）
+	jsonObjectPattern = regexp.MustCompile(`(?s)\{.*\}`)
+	// numberPattern 回退时取响应中的第一个数字
+	numberPattern = regexp.MustCompile(`\d+(?:\.\d+)?`)
+)
+
+// parseJudgeScore 解析评委响应，0-10 分归一化到 0-1
+func parseJudgeScore(response string) (JudgeScore, error) {
+	var parsed struct {
+		Score  *float64 `json:"score"`
+		Reason string   `json:"reason"`
+	}
+
+	var raw float64
+	if m := jsonObjectPattern.FindString(response); m != "" && json.Unmarshal([]byte(m), &parsed) == nil && parsed.Score != nil {
+		raw = *parsed.Score
+	} else if n := numberPattern.FindString(response); n != "" {
+		raw, _ = strconv.ParseFloat(n, 64)
+		parsed.Reason = strings.TrimSpace(response)
+	} else {
+		return JudgeScore{}, fmt.Errorf("无法解析评委评分: %q", response)
+	}
+
+	raw = min(max(raw, 0), 10)
+	return JudgeScore{Score: raw / 10, Reason: parsed.Reason}, nil
+}
+
+// formatContexts 将上下文格式化为带编号的列表
+func formatContexts(contexts []string) string {
+	if len(contexts) == 0 {
+		return "（无）"
+	}
+	var sb strings.Builder
+	for i, c := range contexts {
+		fmt.Fprintf(&sb, "[%d] %s\n", i+1, c)
+	}
+	return strings.TrimSpace(sb.String())
+}
@@ -0,0 +1,193 @@
+package eval
+
+import (
+	"math"
+	"sort"
+
+	"github.com/ahhsitt/helloagents-go/pkg/evaluation"
+	"github.com/ahhsitt/helloagents-go/pkg/rag"
+)
+
+// 指标名称（SampleResult.Details 和 MetricsSummary.DimensionScores 的键）
+const (
+	MetricRecall          = "recall"
+	MetricMRR             = "mrr"
+	MetricNDCG            = "ndcg"
+	MetricHit             = "hit"
+	MetricFaithfulness    = "faithfulness"
+	MetricAnswerRelevancy = "answer_relevancy"
+	MetricContextRecall   = "context_recall"
+)
+
+// metricOrder 汇总和报告中的指标顺序
+var metricOrder = []string{MetricRecall, MetricMRR, MetricNDCG, MetricHit, MetricFaithfulness, MetricAnswerRelevancy, MetricContextRecall}
+
+// MatchRelevant 将检索结果映射为命中的相关 ID
+//
+// 结果的块 ID 或文档 ID 在 relevant 中时视为命中；同一相关 ID 只在首次出现时计入，
+// 之后的重复命中和未命中的结果对应空字符串。
+func MatchRelevant(results []rag.RetrievalResult, relevant []string) []string {
+	want := make(map[string]bool, len(relevant))
+	for _, id := range relevant {
+		want[id] = true
+	}
+
+	seen := make(map[string]bool)
+	matched := make([]string, len(results))
+	for i, r := range results {
+		for _, id := range []string{r.Chunk.ID, r.Chunk.DocumentID} {
+			if want[id] && !seen[id] {
+				seen[id] = true
+				matched[i] = id
+				break
+			}
+		}
+	}
+	return matched
+}
+
+// RecallAtK 前 k 个结果命中的相关 ID 占全部相关 ID 的比例
+//
+// matched 为 MatchRelevant 的输出，numRelevant 为相关 ID 总数。
+func RecallAtK(matched []string, numRelevant, k int) float64 {
+	if numRelevant == 0 {
+		return 0
+	}
+	hits := 0
+	for i := 0; i < len(matched) && i < k; i++ {
+		if matched[i] != "" {
+			hits++
+		}
+	}
+	return float64(hits) / float64(numRelevant)
+}
+
+// ReciprocalRank 第一个相关结果排名的倒数，没有相关结果时为 0
+func ReciprocalRank(matched []string) float64 {
+	for i, id := range matched {
+		if id != "" {
+			return 1 / float64(i+1)
+		}
+	}
+	return 0
+}
+
+// NDCGAtK 二值相关性下的 nDCG@k
+func NDCGAtK(matched []string, numRelevant, k int) float64 {
+	if numRelevant == 0 {
+		return 0
+	}
+	dcg := 0.0
+	for i := 0; i < len(matched) && i < k; i++ {
+		if matched[i] != "" {
+			dcg += 1 / math.Log2(float64(i+2))
+		}
+	}
+	idcg := 0.0
+	for i := 0; i < numRelevant && i < k; i++ {
+		idcg += 1 / math.Log2(float64(i+2))
+	}
+	return dcg / idcg
+}
+
+// Metrics RAG 指标计算器
+type Metrics struct{}
+
+// NewMetrics 创建 RAG 指标计算器
+func NewMetrics() *Metrics {
+	return &Metrics{}
+}
+
+// Compute 计算汇总指标
+//
+// 每个指标只在计算了该指标的样本上取平均（如只有标注了相关 ID 的样本参与检索指标），
+// 结果写入 DimensionScores；Recall 为平均 recall@k。
+func (m *Metrics) Compute(results []*evaluation.SampleResult) *evaluation.MetricsSummary {
+	summary := &evaluation.MetricsSummary{
+		DimensionScores: make(map[string]float64),
+		Extra:           make(map[string]interface{}),
+	}
+	if len(results) == 0 {
+		return summary
+	}
+
+	sums := make(map[string]float64)
+	counts := make(map[string]int)
+	success, errorCount := 0, 0
+	totalScore := 0.0
+	for _, r := range results {
+		if r.Success {
+			success++
+		}
+		if r.Error != "" {
+			errorCount++
+		}
+		totalScore += r.Score
+		for _, name := range metricOrder {
+			if v, ok := r.Details[name].(float64); ok {
+				sums[name] += v
+				counts[name]++
+			}
+		}
+	}
+
+	for name, sum := range sums {
+		summary.DimensionScores[name] = sum / float64(counts[name])
+	}
+	summary.Recall = summary.DimensionScores[MetricRecall]
+	summary.Accuracy = float64(success) / float64(len(results))
+	summary.PassRate = summary.Accuracy
+	summary.AverageScore = totalScore / float64(len(results))
+
+	summary.Extra["total_samples"] = len(results)
+	summary.Extra["error_count"] = errorCount
+	for name, n := range counts {
+		summary.Extra[name+"_samples"] = n
+	}
+	return summary
+}
+
+// ComputeCategoryMetrics 计算分类别指标
+func (m *Metrics) ComputeCategoryMetrics(results []*evaluation.SampleResult) map[string]*evaluation.CategoryMetrics {
+	categories := make(map[string]*evaluation.CategoryMetrics)
+	for _, r := range results {
+		if r.Category == "" {
+			continue
+		}
+		cm, ok := categories[r.Category]
+		if !ok {
+			cm = &evaluation.CategoryMetrics{Category: r.Category}
+			categories[r.Category] = cm
+		}
+		cm.Total++
+		if r.Success {
+			cm.Success++
+		}
+		cm.AverageScore += r.Score
+	}
+	for _, cm := range categories {
+		cm.Accuracy = float64(cm.Success) / float64(cm.Total)
+		cm.AverageScore /= float64(cm.Total)
+	}
+	return categories
+}
+
+// sortedMetricNames 按 metricOrder 排列出现的指标名，未知指标按字母序放在最后
+func sortedMetricNames(scores map[string]float64) []string {
+	names := make([]string, 0, len(scores))
+	known := make(map[string]bool, len(metricOrder))
+	for _, name := range metricOrder {
+		known[name] = true
+		if _, ok := scores[name]; ok {
+			names = append(names, name)
+		}
+	}
+	var extra []string
+	for name := range scores {
+		if !known[name] {
+			extra = append(extra, name)
+		}
+	}
+	sort.Strings(extra)
+	return append(names, extra...)
+}
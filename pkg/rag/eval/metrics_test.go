@@ -0,0 +1,103 @@
+package eval
+
+import (
+	"math"
+	"testing"
+
+	"github.com/ahhsitt/helloagents-go/pkg/evaluation"
+	"github.com/ahhsitt/helloagents-go/pkg/rag"
+)
+
+func chunkResult(id, docID string) rag.RetrievalResult {
+	return rag.RetrievalResult{Chunk: rag.DocumentChunk{ID: id, DocumentID: docID}}
+}
+
+func TestRetrievalMetrics(t *testing.T) {
+	results := []rag.RetrievalResult{
+		chunkResult("c1", "doc-x"),
+		chunkResult("c2", "doc-a"),
+		chunkResult("c3", "doc-a"), // 同一文档重复命中不重复计分
+		chunkResult("c4", "doc-b"),
+	}
+	relevant := []string{"doc-a", "doc-b", "doc-c"}
+
+	matched := MatchRelevant(results, relevant)
+	want := []string{"", "doc-a", "", "doc-b"}
+	for i := range want {
+		if matched[i] != want[i] {
+			t.Fatalf("MatchRelevant() = %v, want %v", matched, want)
+		}
+	}
+
+	if got := RecallAtK(matched, len(relevant), 4); math.Abs(got-2.0/3) > 1e-9 {
+		t.Errorf("RecallAtK(4) = %f, want 0.667", got)
+	}
+	if got := RecallAtK(matched, len(relevant), 2); math.Abs(got-1.0/3) > 1e-9 {
+		t.Errorf("RecallAtK(2) = %f, want 0.333", got)
+	}
+	if got := ReciprocalRank(matched); got != 0.5 {
+		t.Errorf("ReciprocalRank() = %f, want 0.5", got)
+	}
+
+	dcg := 1/math.Log2(3) + 1/math.Log2(5)
+	idcg := 1 + 1/math.Log2(3) + 1/math.Log2(4)
+	if got := NDCGAtK(matched, len(relevant), 4); math.Abs(got-dcg/idcg) > 1e-9 {
+		t.Errorf("NDCGAtK() = %f, want %f", got, dcg/idcg)
+	}
+
+	if got := MatchRelevant(results, []string{"c4"}); got[3] != "c4" {
+		t.Errorf("chunk ID should match: %v", got)
+	}
+	if RecallAtK(nil, 0, 5) != 0 || NDCGAtK(nil, 0, 5) != 0 || ReciprocalRank(nil) != 0 {
+		t.Error("metrics without relevant IDs should be 0")
+	}
+}
+
+func TestMetrics_Compute(t *testing.T) {
+	results := []*evaluation.SampleResult{
+		{SampleID: "q1", Success: true, Score: 0.8, Category: "faq", Details: map[string]interface{}{MetricRecall: 1.0, MetricFaithfulness: 0.6}},
+		{SampleID: "q2", Score: 0.2, Category: "faq", Details: map[string]interface{}{MetricRecall: 0.0}},
+		{SampleID: "q3", Error: "boom", Details: map[string]interface{}{}},
+	}
+
+	metrics := NewMetrics()
+	summary := metrics.Compute(results)
+	if summary.Recall != 0.5 || summary.DimensionScores[MetricRecall] != 0.5 {
+		t.Errorf("Recall = %f, want 0.5", summary.Recall)
+	}
+	if summary.DimensionScores[MetricFaithfulness] != 0.6 {
+		t.Errorf("faithfulness should average over judged samples only: %v", summary.DimensionScores)
+	}
+	if math.Abs(summary.Accuracy-1.0/3) > 1e-9 || summary.Extra["error_count"] != 1 {
+		t.Errorf("summary = %+v", summary)
+	}
+
+	categories := metrics.ComputeCategoryMetrics(results)
+	if faq := categories["faq"]; faq == nil || faq.Total != 2 || faq.Accuracy != 0.5 || faq.AverageScore != 0.5 {
+		t.Errorf("category metrics = %+v", categories["faq"])
+	}
+}
+
+func TestParseJudgeScore(t *testing.T) {
+	tests := []struct {
+		response string
+		want     float64
+		wantErr  bool
+	}{
+		{`{"score": 8, "reason": "mostly supported"}`, 0.8, false},
+		{"```json\n{\"score\": 10}\n```", 1, false},
+		{"7", 0.7, false},
+		{`{"score": 15}`, 1, false},
+		{"no idea", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := parseJudgeScore(tt.response)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseJudgeScore(%q) error = %v", tt.response, err)
+			continue
+		}
+		if math.Abs(got.Score-tt.want) > 1e-9 {
+			t.Errorf("parseJudgeScore(%q) = %f, want %f", tt.response, got.Score, tt.want)
+		}
+	}
+}
@@ -0,0 +1,322 @@
+package rag
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// FilterOp 元数据过滤运算符
+type FilterOp string
+
+const (
+	// OpEq 等于；对 tags 表示包含该标签
+	OpEq FilterOp = "eq"
+	// OpNe 不等于；对 tags 表示不包含该标签
+	OpNe FilterOp = "ne"
+	// OpIn 等于列表中任一值（Value 为切片）；对 tags 表示包含任一标签
+	OpIn FilterOp = "in"
+	// OpGt 大于（数字、时间、字符串）
+	OpGt FilterOp = "gt"
+	// OpGte 大于等于
+	OpGte FilterOp = "gte"
+	// OpLt 小于
+	OpLt FilterOp = "lt"
+	// OpLte 小于等于
+	OpLte FilterOp = "lte"
+	// OpContains 字符串包含子串，或列表包含元素
+	OpContains FilterOp = "contains"
+)
+
+// FilterCondition 元数据过滤条件
+//
+// Field 支持 source、title、author、document_id、parent_id、tags、created_at、
+// ingested_at、expires_at；其余字段名（或 custom. 前缀）匹配 Metadata.Custom 中的键。
+// 时间字段的 Value 使用 time.Time。
+type FilterCondition struct {
+	Field string
+	Op    FilterOp
+	Value interface{}
+}
+
+// Where 创建过滤条件
+func Where(field string, op FilterOp, value interface{}) FilterCondition {
+	return FilterCondition{Field: field, Op: op, Value: value}
+}
+
+// MetadataFilter 元数据过滤器，各条件之间为 AND 关系
+type MetadataFilter []FilterCondition
+
+// Match 检查文档块是否满足全部条件，字段缺失时条件不满足（OpNe 除外）
+func (f MetadataFilter) Match(chunk DocumentChunk) bool {
+	for _, c := range f {
+		if !c.match(chunk) {
+			return false
+		}
+	}
+	return true
+}
+
+// Validate 检查条件是否合法
+func (f MetadataFilter) Validate() error {
+	for _, c := range f {
+		if c.Field == "" {
+			return fmt.Errorf("filter: empty field")
+		}
+		switch c.Op {
+		case OpEq, OpNe, OpGt, OpGte, OpLt, OpLte, OpContains:
+		case OpIn:
+			if v := reflect.ValueOf(c.Value); v.Kind() != reflect.Slice {
+				return fmt.Errorf("filter: %s in requires a slice value, got %T", c.Field, c.Value)
+			}
+		default:
+			return fmt.Errorf("filter: unknown operator %q", c.Op)
+		}
+	}
+	return nil
+}
+
+// FilteredSearcher 支持元数据过滤的向量存储
+//
+// 实现该接口的存储在检索时应用过滤条件（尽量下推到数据库），保证返回 topK 个满足条件的结果；
+// 未实现的存储由检索器扩大召回数量后再过滤。
+type FilteredSearcher interface {
+	// SearchWithFilter 搜索满足过滤条件的相似文档块
+	SearchWithFilter(ctx context.Context, query []float32, topK int, filter MetadataFilter) ([]RetrievalResult, error)
+}
+
+// searchWithFilter 在向量存储上执行过滤检索
+func searchWithFilter(ctx context.Context, store VectorStore, query []float32, topK int, filter MetadataFilter) ([]RetrievalResult, error) {
+	if len(filter) == 0 {
+		return store.Search(ctx, query, topK)
+	}
+	if fs, ok := store.(FilteredSearcher); ok {
+		return fs.SearchWithFilter(ctx, query, topK, filter)
+	}
+	return overfetchFiltered(ctx, store.Search, query, topK, filter.Match)
+}
+
+// overfetchFiltered 逐步扩大召回数量，直到过滤后结果足够或存储已没有更多结果
+func overfetchFiltered(ctx context.Context, search func(context.Context, []float32, int) ([]RetrievalResult, error), query []float32, topK int, match func(DocumentChunk) bool) ([]RetrievalResult, error) {
+	if topK <= 0 {
+		return nil, nil
+	}
+	for limit := topK * 4; ; limit *= 2 {
+		hits, err := search(ctx, query, limit)
+		if err != nil {
+			return nil, err
+		}
+		results := make([]RetrievalResult, 0, topK)
+		for _, hit := range hits {
+			if match(hit.Chunk) {
+				results = append(results, hit)
+				if len(results) == topK {
+					return results, nil
+				}
+			}
+		}
+		if len(hits) < limit || limit >= topK*256 {
+			return results, nil
+		}
+	}
+}
+
+// match 检查单个条件
+func (c FilterCondition) match(chunk DocumentChunk) bool {
+	actual, ok := filterField(chunk, c.Field)
+	if !ok {
+		return c.Op == OpNe
+	}
+
+	if tags, isTags := actual.([]string); isTags && c.Field == "tags" {
+		switch c.Op {
+		case OpEq, OpContains:
+			return containsValue(tags, c.Value)
+		case OpNe:
+			return !containsValue(tags, c.Value)
+		case OpIn:
+			for _, v := range sliceValues(c.Value) {
+				if containsValue(tags, v) {
+					return true
+				}
+			}
+			return false
+		}
+		return false
+	}
+
+	switch c.Op {
+	case OpEq:
+		return valuesEqual(actual, c.Value)
+	case OpNe:
+		return !valuesEqual(actual, c.Value)
+	case OpIn:
+		for _, v := range sliceValues(c.Value) {
+			if valuesEqual(actual, v) {
+				return true
+			}
+		}
+		return false
+	case OpContains:
+		if s, ok := actual.(string); ok {
+			sub, _ := c.Value.(string)
+			return strings.Contains(s, sub)
+		}
+		for _, v := range sliceValues(actual) {
+			if valuesEqual(v, c.Value) {
+				return true
+			}
+		}
+		return false
+	case OpGt, OpGte, OpLt, OpLte:
+		cmp, ok := compareValues(actual, c.Value)
+		if !ok {
+			return false
+		}
+		switch c.Op {
+		case OpGt:
+			return cmp > 0
+		case OpGte:
+			return cmp >= 0
+		case OpLt:
+			return cmp < 0
+		default:
+			return cmp <= 0
+		}
+	}
+	return false
+}
+
+// filterField 读取块的字段值，零值的内置字段视为缺失
+func filterField(chunk DocumentChunk, field string) (interface{}, bool) {
+	m := chunk.Metadata
+	str := func(s string) (interface{}, bool) { return s, s != "" }
+	tm := func(t time.Time) (interface{}, bool) { return t, !t.IsZero() }
+
+	switch field {
+	case "source":
+		return str(m.Source)
+	case "title":
+		return str(m.Title)
+	case "author":
+		return str(m.Author)
+	case "document_id":
+		return str(chunk.DocumentID)
+	case "parent_id":
+		return str(chunk.ParentID)
+	case "tags":
+		return m.Tags, true
+	case "created_at":
+		return tm(m.CreatedAt)
+	case "ingested_at":
+		return tm(m.IngestedAt)
+	case "expires_at":
+		return tm(m.ExpiresAt)
+	}
+	v, ok := m.Custom[strings.TrimPrefix(field, "custom.")]
+	return v, ok
+}
+
+// containsValue 检查字符串列表是否包含值
+func containsValue(list []string, v interface{}) bool {
+	s, ok := v.(string)
+	if !ok {
+		return false
+	}
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// sliceValues 将切片展开为元素列表，非切片返回 nil
+func sliceValues(v interface{}) []interface{} {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice {
+		return nil
+	}
+	values := make([]interface{}, rv.Len())
+	for i := range values {
+		values[i] = rv.Index(i).Interface()
+	}
+	return values
+}
+
+// valuesEqual 比较两个值，数字按 float64 比较，时间按 Equal 比较
+func valuesEqual(a, b interface{}) bool {
+	if cmp, ok := compareValues(a, b); ok {
+		return cmp == 0
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// compareValues 比较有序值（数字、时间、字符串），类型不可比较时返回 false
+func compareValues(a, b interface{}) (int, bool) {
+	if fa, ok := toFloat(a); ok {
+		fb, ok := toFloat(b)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case fa < fb:
+			return -1, true
+		case fa > fb:
+			return 1, true
+		}
+		return 0, true
+	}
+	if ta, ok := toTime(a); ok {
+		tb, ok := toTime(b)
+		if !ok {
+			return 0, false
+		}
+		return ta.Compare(tb), true
+	}
+	if sa, ok := a.(string); ok {
+		sb, ok := b.(string)
+		if !ok {
+			return 0, false
+		}
+		return strings.Compare(sa, sb), true
+	}
+	return 0, false
+}
+
+// toFloat 转换数字类型
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+// toTime 转换时间，支持 RFC3339 字符串（如从 JSON 还原的自定义元数据）
+func toTime(v interface{}) (time.Time, bool) {
+	switch t := v.(type) {
+	case time.Time:
+		return t, true
+	case string:
+		parsed, err := time.Parse(time.RFC3339, t)
+		return parsed, err == nil
+	}
+	return time.Time{}, false
+}
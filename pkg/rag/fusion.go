@@ -138,6 +138,73 @@ func (f *ScoreBasedFusion) Fuse(results [][]RetrievalResult, weights []float32,
 	return fusedResults[:topK]
 }
 
+// LinearFusion 线性加权融合
+// 每个结果集的分数先做 min-max 归一化到 [0, 1]，再按权重求和。
+// 适合融合分数量纲不同的结果（如 BM25 与余弦相似度）。
+type LinearFusion struct{}
+
+// NewLinearFusion 创建线性加权融合策略
+func NewLinearFusion() *LinearFusion {
+	return &LinearFusion{}
+}
+
+// Fuse 执行线性加权融合
+func (f *LinearFusion) Fuse(results [][]RetrievalResult, weights []float32, topK int) []RetrievalResult {
+	if len(results) == 0 {
+		return nil
+	}
+
+	scoreMap := make(map[string]float32)
+	chunkMap := make(map[string]RetrievalResult)
+
+	for queryIdx, queryResults := range results {
+		if len(queryResults) == 0 {
+			continue
+		}
+		weight := float32(1.0)
+		if queryIdx < len(weights) && weights[queryIdx] > 0 {
+			weight = weights[queryIdx]
+		}
+
+		minScore, maxScore := queryResults[0].Score, queryResults[0].Score
+		for _, result := range queryResults {
+			minScore = min(minScore, result.Score)
+			maxScore = max(maxScore, result.Score)
+		}
+
+		for _, result := range queryResults {
+			// 所有分数相同时视为同等相关
+			normalized := float32(1.0)
+			if maxScore > minScore {
+				normalized = (result.Score - minScore) / (maxScore - minScore)
+			}
+			chunkID := result.Chunk.ID
+			scoreMap[chunkID] += weight * normalized
+			if _, exists := chunkMap[chunkID]; !exists {
+				chunkMap[chunkID] = result
+			}
+		}
+	}
+
+	fusedResults := make([]RetrievalResult, 0, len(scoreMap))
+	for chunkID, score := range scoreMap {
+		result := chunkMap[chunkID]
+		result.Score = score
+		fusedResults = append(fusedResults, result)
+	}
+
+	sort.Slice(fusedResults, func(i, j int) bool {
+		return fusedResults[i].Score > fusedResults[j].Score
+	})
+
+	if topK > len(fusedResults) {
+		topK = len(fusedResults)
+	}
+
+	return fusedResults[:topK]
+}
+
 // compile-time interface check
 var _ FusionStrategy = (*RRFFusion)(nil)
 var _ FusionStrategy = (*ScoreBasedFusion)(nil)
+var _ FusionStrategy = (*LinearFusion)(nil)
@@ -0,0 +1,149 @@
+package rag
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ahhsitt/helloagents-go/pkg/core/llm"
+	"github.com/ahhsitt/helloagents-go/pkg/core/message"
+)
+
+// StreamingAnswerGenerator 支持流式输出的回答生成器
+//
+// QueryStream 优先使用流式生成；未实现该接口的生成器在生成完成后一次性输出整个回答。
+type StreamingAnswerGenerator interface {
+	AnswerGenerator
+	// GenerateStream 流式生成回答
+	//
+	// 返回两个 channel：
+	//   - <-chan string: 回答片段，生成结束后关闭
+	//   - <-chan error: 错误通道（最多一个错误）
+	GenerateStream(ctx context.Context, query string, context *RAGContext) (<-chan string, <-chan error)
+}
+
+// DefaultAnswerPrompt 默认回答提示模板，包含两个 %s 占位符（上下文、问题）
+const DefaultAnswerPrompt = `请根据以下上下文回答问题。如果上下文中没有相关信息，请直接说明无法回答，不要编造。
+
+上下文：
+%s
+
+问题：%s
+
+回答：`
+
+// LLMAnswerGenerator 基于 llm.Provider 的回答生成器，支持流式输出
+type LLMAnswerGenerator struct {
+	provider llm.Provider
+	prompt   string
+}
+
+// LLMAnswerGeneratorOption 回答生成器选项
+type LLMAnswerGeneratorOption func(*LLMAnswerGenerator)
+
+// WithAnswerPrompt 设置回答提示模板，模板包含两个 %s 占位符（上下文、问题）
+func WithAnswerPrompt(prompt string) LLMAnswerGeneratorOption {
+	return func(g *LLMAnswerGenerator) {
+		g.prompt = prompt
+	}
+}
+
+// NewLLMAnswerGenerator 创建回答生成器
+func NewLLMAnswerGenerator(provider llm.Provider, opts ...LLMAnswerGeneratorOption) *LLMAnswerGenerator {
+	g := &LLMAnswerGenerator{
+		provider: provider,
+		prompt:   DefaultAnswerPrompt,
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g
+}
+
+// Generate 基于上下文生成回答
+func (g *LLMAnswerGenerator) Generate(ctx context.Context, query string, ragContext *RAGContext) (string, error) {
+	resp, err := g.provider.Generate(ctx, g.request(query, ragContext))
+	if err != nil {
+		return "", err
+	}
+	return resp.Content, nil
+}
+
+// GenerateStream 基于上下文流式生成回答
+func (g *LLMAnswerGenerator) GenerateStream(ctx context.Context, query string, ragContext *RAGContext) (<-chan string, <-chan error) {
+	answerChan := make(chan string, 10)
+	errChan := make(chan error, 1)
+
+	go func() {
+		defer close(answerChan)
+		defer close(errChan)
+
+		llmChunks, llmErrs := g.provider.GenerateStream(ctx, g.request(query, ragContext))
+		for {
+			select {
+			case <-ctx.Done():
+				errChan <- ctx.Err()
+				return
+			case err, ok := <-llmErrs:
+				if !ok {
+					llmErrs = nil
+					continue
+				}
+				if err != nil {
+					errChan <- err
+					return
+				}
+			case chunk, ok := <-llmChunks:
+				if !ok {
+					// 内容通道先于错误通道关闭时，错误仍需转发
+					if llmErrs != nil {
+						if err := <-llmErrs; err != nil {
+							errChan <- err
+						}
+					}
+					return
+				}
+				if chunk.Content != "" {
+					select {
+					case answerChan <- chunk.Content:
+					case <-ctx.Done():
+						errChan <- ctx.Err()
+						return
+					}
+				}
+				if chunk.Done {
+					return
+				}
+			}
+		}
+	}()
+
+	return answerChan, errChan
+}
+
+// request 构建 LLM 请求
+func (g *LLMAnswerGenerator) request(query string, ragContext *RAGContext) llm.Request {
+	return llm.Request{
+		Messages: []message.Message{message.NewUserMessage(fmt.Sprintf(g.prompt, formatAnswerContext(ragContext), query))},
+	}
+}
+
+// formatAnswerContext 将检索结果格式化为带编号的上下文
+func formatAnswerContext(ragContext *RAGContext) string {
+	if ragContext == nil || len(ragContext.Results) == 0 {
+		return "（无）"
+	}
+	var sb strings.Builder
+	for i, r := range ragContext.Results {
+		fmt.Fprintf(&sb, "[%d] ", i+1)
+		if r.Chunk.Metadata.Source != "" {
+			fmt.Fprintf(&sb, "(来源: %s) ", r.Chunk.Metadata.Source)
+		}
+		sb.WriteString(r.Chunk.Content)
+		sb.WriteString("\n\n")
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// compile-time interface check
+var _ StreamingAnswerGenerator = (*LLMAnswerGenerator)(nil)
@@ -0,0 +1,594 @@
+package rag
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/ahhsitt/helloagents-go/pkg/memory"
+	"github.com/ahhsitt/helloagents-go/pkg/memory/store"
+)
+
+const (
+	// MetadataEntities 图索引块存储中块包含的实体 ID 列表（Metadata.Custom 键）
+	MetadataEntities = "entities"
+	// MetadataGraphEntity 图检索结果中，经由关系图到达该块的实体名称（Metadata.Custom 键）
+	MetadataGraphEntity = "graph_entity"
+	// MetadataGraphHop 图检索结果中该块距离起始实体的跳数，0 为起始实体本身（Metadata.Custom 键）
+	MetadataGraphHop = "graph_hop"
+)
+
+// 图实体属性键
+const (
+	// graphPropChunks 提及实体的块 ID 列表
+	graphPropChunks = "chunk_ids"
+	// graphPropContext 关系所在的句子
+	graphPropContext = "context"
+)
+
+// defaultGraphSearchLimit 按名称查找实体时的候选数量
+const defaultGraphSearchLimit = 50
+
+// GraphIndexer 知识图谱索引器（GraphRAG）
+//
+// 摄取时用 memory 包的实体提取器（规则或 LLM）从每个块中提取实体和关系，写入 GraphStore：
+// 实体按名称（不区分大小写）合并，属性中记录提及它的块 ID；关系的 Evidence 为陈述该关系的块 ID。
+// 块的副本（不含向量）连同实体 ID 写入块存储，供 GraphRetriever 按 ID 取回。
+type GraphIndexer struct {
+	extractor   memory.EntityExtractor
+	graph       store.GraphStore
+	chunks      ChunkStore
+	concurrency int
+	mu          sync.Mutex
+}
+
+// GraphIndexerOption 图索引器选项
+type GraphIndexerOption func(*GraphIndexer)
+
+// WithGraphChunkStore 设置图索引使用的块存储（默认内存存储）
+func WithGraphChunkStore(chunks ChunkStore) GraphIndexerOption {
+	return func(g *GraphIndexer) {
+		g.chunks = chunks
+	}
+}
+
+// WithGraphConcurrency 设置并发提取的块数（默认 1），使用 LLM 提取器时建议调大
+func WithGraphConcurrency(n int) GraphIndexerOption {
+	return func(g *GraphIndexer) {
+		if n > 0 {
+			g.concurrency = n
+		}
+	}
+}
+
+// NewGraphIndexer 创建图索引器
+//
+// extractor 为空时使用 memory.NewRuleEntityExtractor；graph 为空时使用内存图存储。
+func NewGraphIndexer(extractor memory.EntityExtractor, graph store.GraphStore, opts ...GraphIndexerOption) *GraphIndexer {
+	g := &GraphIndexer{
+		extractor:   extractor,
+		graph:       graph,
+		concurrency: 1,
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	if g.extractor == nil {
+		g.extractor = memory.NewRuleEntityExtractor()
+	}
+	if g.graph == nil {
+		g.graph = store.NewMemoryGraphStore()
+	}
+	if g.chunks == nil {
+		g.chunks = NewInMemoryChunkStore()
+	}
+	return g
+}
+
+// Graph 返回图存储
+func (g *GraphIndexer) Graph() store.GraphStore {
+	return g.graph
+}
+
+// Index 从块中提取实体和关系并写入图存储
+//
+// 重复索引同一块是幂等的。块的提取失败时返回错误，已写入的其他块保留。
+func (g *GraphIndexer) Index(ctx context.Context, chunks []DocumentChunk) error {
+	if len(chunks) == 0 {
+		return nil
+	}
+
+	extractions := make([]*memory.ExtractionResult, len(chunks))
+	errs := make([]error, len(chunks))
+	sem := make(chan struct{}, g.concurrency)
+	var wg sync.WaitGroup
+	for i := range chunks {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			extractions[i], errs[i] = g.extractor.Extract(ctx, chunks[i].Content)
+		}(i)
+	}
+	wg.Wait()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	stored := make([]DocumentChunk, 0, len(chunks))
+	for i, chunk := range chunks {
+		if errs[i] != nil {
+			return fmt.Errorf("failed to extract entities from chunk %s: %w", chunk.ID, errs[i])
+		}
+		entityIDs, err := g.indexChunk(ctx, chunk.ID, extractions[i])
+		if err != nil {
+			return err
+		}
+
+		chunk.Vector = nil
+		custom := make(map[string]interface{}, len(chunk.Metadata.Custom)+1)
+		for k, v := range chunk.Metadata.Custom {
+			custom[k] = v
+		}
+		custom[MetadataEntities] = entityIDs
+		chunk.Metadata.Custom = custom
+		stored = append(stored, chunk)
+	}
+	if err := g.chunks.Add(ctx, stored); err != nil {
+		return fmt.Errorf("failed to store graph chunks: %w", err)
+	}
+	return nil
+}
+
+// indexChunk 写入一个块的实体和关系，返回块中的实体 ID
+func (g *GraphIndexer) indexChunk(ctx context.Context, chunkID string, extraction *memory.ExtractionResult) ([]string, error) {
+	if extraction == nil {
+		return nil, nil
+	}
+
+	ids := make(map[string]string, len(extraction.Entities))
+	entityIDs := make([]string, 0, len(extraction.Entities))
+	for _, extracted := range extraction.Entities {
+		key := strings.ToLower(extracted.Name)
+		if _, ok := ids[key]; ok || key == "" {
+			continue
+		}
+		entity, err := g.upsertEntity(ctx, extracted, chunkID)
+		if err != nil {
+			return nil, err
+		}
+		ids[key] = entity.ID
+		entityIDs = append(entityIDs, entity.ID)
+	}
+
+	for _, rel := range extraction.Relations {
+		fromID, fromOK := ids[strings.ToLower(rel.FromEntity)]
+		toID, toOK := ids[strings.ToLower(rel.ToEntity)]
+		if !fromOK || !toOK || fromID == toID {
+			continue
+		}
+		if err := g.upsertRelation(ctx, fromID, toID, rel, chunkID); err != nil {
+			return nil, err
+		}
+	}
+	return entityIDs, nil
+}
+
+// upsertEntity 按名称合并实体并记录提及它的块
+func (g *GraphIndexer) upsertEntity(ctx context.Context, extracted memory.ExtractedEntity, chunkID string) (*store.GraphEntity, error) {
+	entity, err := g.entityByName(ctx, extracted.Name)
+	if err != nil {
+		return nil, err
+	}
+	if entity == nil {
+		entity = &store.GraphEntity{
+			ID:         uuid.NewSHA1(uuid.NameSpaceOID, []byte("entity:"+strings.ToLower(extracted.Name))).String(),
+			Name:       extracted.Name,
+			Type:       string(extracted.Type),
+			Properties: make(map[string]interface{}),
+		}
+	}
+
+	chunkIDs := stringList(entity.Properties[graphPropChunks])
+	if containsID(chunkIDs, chunkID) {
+		return entity, nil
+	}
+	if entity.Properties == nil {
+		entity.Properties = make(map[string]interface{})
+	}
+	entity.Properties[graphPropChunks] = append(chunkIDs, chunkID)
+	entity.Frequency = len(chunkIDs) + 1
+	if err := g.graph.AddEntity(ctx, entity); err != nil {
+		return nil, fmt.Errorf("failed to store entity %q: %w", extracted.Name, err)
+	}
+	return entity, nil
+}
+
+// upsertRelation 写入关系，同一对实体之间同类型的关系合并证据
+func (g *GraphIndexer) upsertRelation(ctx context.Context, fromID, toID string, extracted memory.ExtractedRelation, chunkID string) error {
+	relType := string(extracted.RelationType)
+	id := uuid.NewSHA1(uuid.NameSpaceOID, []byte("relation:"+fromID+"/"+relType+"/"+toID)).String()
+
+	existing, err := g.graph.GetRelations(ctx, fromID)
+	if err != nil {
+		return fmt.Errorf("failed to load relations: %w", err)
+	}
+	relation := &store.GraphRelation{
+		ID:           id,
+		FromEntityID: fromID,
+		ToEntityID:   toID,
+		Type:         relType,
+		Strength:     extracted.Confidence,
+		Properties:   map[string]interface{}{graphPropContext: extracted.Context},
+	}
+	for _, rel := range existing {
+		if rel.ID != id {
+			continue
+		}
+		if containsID(rel.Evidence, chunkID) {
+			return nil
+		}
+		relation.Evidence = append(relation.Evidence, rel.Evidence...)
+		relation.Strength = max(relation.Strength, rel.Strength)
+		relation.CreatedAt = rel.CreatedAt
+		if err := g.graph.DeleteRelation(ctx, id); err != nil {
+			return fmt.Errorf("failed to update relation: %w", err)
+		}
+		break
+	}
+	relation.Evidence = append(relation.Evidence, chunkID)
+	if relation.Strength <= 0 {
+		relation.Strength = 0.5
+	}
+	if err := g.graph.AddRelation(ctx, relation); err != nil {
+		return fmt.Errorf("failed to store relation: %w", err)
+	}
+	return nil
+}
+
+// RemoveChunks 从图中移除块：删除块存储中的副本，从实体和关系中去掉块 ID，
+// 不再被任何块提及的实体及其关系被删除
+func (g *GraphIndexer) RemoveChunks(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	chunks, err := g.chunks.Get(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("failed to load graph chunks: %w", err)
+	}
+	removed := make(map[string]struct{}, len(ids))
+	for _, id := range ids {
+		removed[id] = struct{}{}
+	}
+	entityIDs := make(map[string]struct{})
+	for _, chunk := range chunks {
+		for _, id := range stringList(chunk.Metadata.Custom[MetadataEntities]) {
+			entityIDs[id] = struct{}{}
+		}
+	}
+
+	for id := range entityIDs {
+		entity, err := g.graph.GetEntity(ctx, id)
+		if errors.Is(err, store.ErrNotFound) {
+			continue
+		} else if err != nil {
+			return fmt.Errorf("failed to load entity: %w", err)
+		}
+
+		remaining := withoutIDs(stringList(entity.Properties[graphPropChunks]), removed)
+		if len(remaining) == 0 {
+			if err := g.graph.DeleteEntity(ctx, id); err != nil && !errors.Is(err, store.ErrNotFound) {
+				return fmt.Errorf("failed to delete entity: %w", err)
+			}
+			continue
+		}
+		entity.Properties[graphPropChunks] = remaining
+		entity.Frequency = len(remaining)
+		if err := g.graph.AddEntity(ctx, entity); err != nil {
+			return fmt.Errorf("failed to update entity: %w", err)
+		}
+
+		relations, err := g.graph.GetRelations(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to load relations: %w", err)
+		}
+		for _, rel := range relations {
+			evidence := withoutIDs(rel.Evidence, removed)
+			if len(evidence) == len(rel.Evidence) {
+				continue
+			}
+			if err := g.graph.DeleteRelation(ctx, rel.ID); err != nil && !errors.Is(err, store.ErrNotFound) {
+				return fmt.Errorf("failed to update relation: %w", err)
+			}
+			if len(evidence) > 0 {
+				rel.Evidence = evidence
+				if err := g.graph.AddRelation(ctx, rel); err != nil {
+					return fmt.Errorf("failed to update relation: %w", err)
+				}
+			}
+		}
+	}
+	return g.chunks.Delete(ctx, ids)
+}
+
+// entityByName 按名称精确查找实体（不区分大小写），不存在时返回 nil
+func (g *GraphIndexer) entityByName(ctx context.Context, name string) (*store.GraphEntity, error) {
+	candidates, err := g.graph.SearchEntities(ctx, name, "", defaultGraphSearchLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search entities: %w", err)
+	}
+	for _, candidate := range candidates {
+		if strings.EqualFold(candidate.Name, name) {
+			return candidate, nil
+		}
+	}
+	return nil, nil
+}
+
+// GraphRetriever 知识图谱检索器（GraphRAG）
+//
+// 向量检索回答单跳问题，但多跳问题（"A 的作者所在的公司发布了什么"）需要的块往往与查询
+// 并不相似。GraphRetriever 以查询中提到的实体和向量检索命中块中的实体为起点，在关系图上
+// 遍历 1~hops 跳，收集陈述路径上关系的块和邻居实体所在的块，与向量检索结果融合后返回。
+// 经由图到达的块在 Metadata.Custom 中记录 MetadataGraphEntity 和 MetadataGraphHop。
+type GraphRetriever struct {
+	base    Retriever
+	indexer *GraphIndexer
+	hops    int
+	decay   float32
+	weights []float32
+	fusion  FusionStrategy
+}
+
+// GraphRetrieverOption 图检索器选项
+type GraphRetrieverOption func(*GraphRetriever)
+
+// WithGraphHops 设置关系图遍历的最大跳数（默认 2）
+func WithGraphHops(hops int) GraphRetrieverOption {
+	return func(r *GraphRetriever) {
+		if hops > 0 {
+			r.hops = hops
+		}
+	}
+}
+
+// WithGraphDecay 设置每跳的分数衰减系数（默认 0.5），用于图结果内部排序
+func WithGraphDecay(decay float32) GraphRetrieverOption {
+	return func(r *GraphRetriever) {
+		if decay > 0 && decay <= 1 {
+			r.decay = decay
+		}
+	}
+}
+
+// WithGraphFusion 设置向量结果与图结果的融合策略（默认 RRF，k=60）
+func WithGraphFusion(fusion FusionStrategy) GraphRetrieverOption {
+	return func(r *GraphRetriever) {
+		if fusion != nil {
+			r.fusion = fusion
+		}
+	}
+}
+
+// WithGraphWeights 设置向量结果和图结果的融合权重
+func WithGraphWeights(vector, graph float32) GraphRetrieverOption {
+	return func(r *GraphRetriever) {
+		r.weights = []float32{vector, graph}
+	}
+}
+
+// NewGraphRetriever 创建图检索器
+//
+// base 为向量检索器，indexer 为摄取时通过 WithGraphIndexer 写入的图索引器。
+func NewGraphRetriever(base Retriever, indexer *GraphIndexer, opts ...GraphRetrieverOption) *GraphRetriever {
+	r := &GraphRetriever{
+		base:    base,
+		indexer: indexer,
+		hops:    2,
+		decay:   0.5,
+		fusion:  NewRRFFusion(60),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// graphHit 图检索命中的块
+type graphHit struct {
+	score  float32
+	hop    int
+	entity string
+}
+
+// Retrieve 融合向量检索和图遍历的结果（实现 Retriever 接口）
+func (r *GraphRetriever) Retrieve(ctx context.Context, query string, topK int) ([]RetrievalResult, error) {
+	seeds, err := r.base.Retrieve(ctx, query, topK)
+	if err != nil {
+		return nil, err
+	}
+
+	start, err := r.startEntities(ctx, query, seeds)
+	if err != nil {
+		return nil, err
+	}
+
+	hits := make(map[string]graphHit)
+	add := func(chunkIDs []string, hit graphHit) {
+		for _, id := range chunkIDs {
+			if prev, ok := hits[id]; !ok || hit.score > prev.score {
+				hits[id] = hit
+			}
+		}
+	}
+	for _, entity := range start {
+		add(stringList(entity.Properties[graphPropChunks]), graphHit{score: 1, entity: entity.Name})
+
+		related, err := r.indexer.graph.FindRelatedEntities(ctx, entity.ID, "", r.hops)
+		if errors.Is(err, store.ErrNotFound) {
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to traverse graph: %w", err)
+		}
+		for _, neighbor := range related {
+			score := float32(1)
+			for _, rel := range neighbor.Path {
+				score *= r.decay * max(rel.Strength, 0.1)
+			}
+			hit := graphHit{score: score, hop: neighbor.Depth, entity: neighbor.Entity.Name}
+			if len(neighbor.Path) > 0 {
+				add(neighbor.Path[len(neighbor.Path)-1].Evidence, hit)
+			}
+			add(stringList(neighbor.Entity.Properties[graphPropChunks]), hit)
+		}
+	}
+
+	graphResults, err := r.loadHits(ctx, hits, topK)
+	if err != nil {
+		return nil, err
+	}
+	if len(graphResults) == 0 {
+		return seeds, nil
+	}
+	return r.fusion.Fuse([][]RetrievalResult{seeds, graphResults}, r.weights, topK), nil
+}
+
+// startEntities 返回遍历的起始实体：查询中提到的实体，以及向量检索命中块中的实体
+func (r *GraphRetriever) startEntities(ctx context.Context, query string, seeds []RetrievalResult) ([]*store.GraphEntity, error) {
+	var (
+		entities []*store.GraphEntity
+		seen     = make(map[string]struct{})
+	)
+	addEntity := func(entity *store.GraphEntity) {
+		if _, ok := seen[entity.ID]; !ok {
+			seen[entity.ID] = struct{}{}
+			entities = append(entities, entity)
+		}
+	}
+
+	extraction, err := r.indexer.extractor.Extract(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract query entities: %w", err)
+	}
+	for _, extracted := range extraction.Entities {
+		entity, err := r.indexer.entityByName(ctx, extracted.Name)
+		if err != nil {
+			return nil, err
+		}
+		if entity != nil {
+			addEntity(entity)
+		}
+	}
+
+	seedIDs := make([]string, len(seeds))
+	for i, seed := range seeds {
+		seedIDs[i] = seed.Chunk.ID
+	}
+	chunks, err := r.indexer.chunks.Get(ctx, seedIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load graph chunks: %w", err)
+	}
+	for _, chunk := range chunks {
+		for _, id := range stringList(chunk.Metadata.Custom[MetadataEntities]) {
+			if _, ok := seen[id]; ok {
+				continue
+			}
+			entity, err := r.indexer.graph.GetEntity(ctx, id)
+			if errors.Is(err, store.ErrNotFound) {
+				continue
+			} else if err != nil {
+				return nil, fmt.Errorf("failed to load entity: %w", err)
+			}
+			addEntity(entity)
+		}
+	}
+	return entities, nil
+}
+
+// loadHits 按分数取前 limit 个命中块的内容
+func (r *GraphRetriever) loadHits(ctx context.Context, hits map[string]graphHit, limit int) ([]RetrievalResult, error) {
+	ids := make([]string, 0, len(hits))
+	for id := range hits {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		a, b := hits[ids[i]], hits[ids[j]]
+		if a.score != b.score {
+			return a.score > b.score
+		}
+		return ids[i] < ids[j]
+	})
+	if len(ids) > limit {
+		ids = ids[:limit]
+	}
+
+	chunks, err := r.indexer.chunks.Get(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load graph chunks: %w", err)
+	}
+	byID := make(map[string]DocumentChunk, len(chunks))
+	for _, chunk := range chunks {
+		byID[chunk.ID] = chunk
+	}
+
+	results := make([]RetrievalResult, 0, len(ids))
+	for _, id := range ids {
+		chunk, ok := byID[id]
+		if !ok {
+			continue
+		}
+		hit := hits[id]
+		custom := make(map[string]interface{}, len(chunk.Metadata.Custom)+1)
+		for k, v := range chunk.Metadata.Custom {
+			if k != MetadataEntities {
+				custom[k] = v
+			}
+		}
+		custom[MetadataGraphEntity] = hit.entity
+		custom[MetadataGraphHop] = hit.hop
+		chunk.Metadata.Custom = custom
+		results = append(results, RetrievalResult{Chunk: chunk, Score: hit.score})
+	}
+	return results, nil
+}
+
+// stringList 读取字符串列表属性（兼容 JSON 解码得到的 []interface{}）
+func stringList(v interface{}) []string {
+	switch list := v.(type) {
+	case []string:
+		return append([]string(nil), list...)
+	case []interface{}:
+		out := make([]string, 0, len(list))
+		for _, item := range list {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+// withoutIDs 返回不在 removed 中的 ID
+func withoutIDs(ids []string, removed map[string]struct{}) []string {
+	kept := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := removed[id]; !ok {
+			kept = append(kept, id)
+		}
+	}
+	return kept
+}
+
+// compile-time interface check
+var _ Retriever = (*GraphRetriever)(nil)
@@ -0,0 +1,244 @@
+package rag
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// HTMLLoader HTML 文件加载器
+//
+// 提取页面正文文本并去除样板内容：脚本、样式、导航、页眉页脚、侧栏、表单，
+// 以及 class/id 中带有 nav、menu、sidebar、footer、cookie、banner、ad 等标记的元素。
+// 页面包含 <main> 或 <article> 时只提取其中的内容。
+// 标题取自 <title>（没有时取第一个 <h1>），作者取自 <meta name="author">。
+type HTMLLoader struct {
+	source string
+	reader io.Reader
+}
+
+// NewHTMLLoader 从 io.Reader 创建 HTML 加载器
+func NewHTMLLoader(source string, reader io.Reader) *HTMLLoader {
+	return &HTMLLoader{
+		source: source,
+		reader: reader,
+	}
+}
+
+// Load 加载文档
+func (l *HTMLLoader) Load(ctx context.Context) ([]Document, error) {
+	root, err := html.Parse(l.reader)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := DocumentMetadata{Source: l.source}
+	if title := findElement(root, atom.Title); title != nil {
+		metadata.Title = collapseSpaces(nodeText(title))
+	}
+	if metadata.Title == "" {
+		if h1 := findElement(root, atom.H1); h1 != nil {
+			metadata.Title = collapseSpaces(nodeText(h1))
+		}
+	}
+	metadata.Author = metaContent(root, "author")
+
+	body := findElement(root, atom.Main)
+	if body == nil {
+		body = findElement(root, atom.Article)
+	}
+	if body == nil {
+		body = findElement(root, atom.Body)
+	}
+	if body == nil {
+		body = root
+	}
+
+	var sb strings.Builder
+	extractHTMLText(body, &sb)
+
+	doc := Document{
+		ID:       generateID(),
+		Content:  normalizeExtractedText(sb.String()),
+		Metadata: metadata,
+	}
+	return []Document{doc}, nil
+}
+
+// SupportedExtensions 支持的文件扩展名
+func (l *HTMLLoader) SupportedExtensions() []string {
+	return []string{".html", ".htm"}
+}
+
+// htmlBoilerplateTags 不包含正文的元素
+var htmlBoilerplateTags = map[atom.Atom]bool{
+	atom.Script:   true,
+	atom.Style:    true,
+	atom.Noscript: true,
+	atom.Template: true,
+	atom.Nav:      true,
+	atom.Header:   true,
+	atom.Footer:   true,
+	atom.Aside:    true,
+	atom.Form:     true,
+	atom.Iframe:   true,
+	atom.Svg:      true,
+	atom.Button:   true,
+	atom.Head:     true,
+}
+
+// htmlBoilerplateMarkers class/id 中表示样板内容的标记
+var htmlBoilerplateMarkers = []string{"nav", "menu", "sidebar", "footer", "header", "cookie", "banner", "breadcrumb", "share", "social", "ad", "ads", "advert", "promo", "related", "comment"}
+
+// htmlBlockTags 提取文本时需要换行的块级元素
+var htmlBlockTags = map[atom.Atom]bool{
+	atom.P: true, atom.Div: true, atom.Section: true, atom.Article: true, atom.Main: true,
+	atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+	atom.Li: true, atom.Ul: true, atom.Ol: true, atom.Dl: true, atom.Dt: true, atom.Dd: true,
+	atom.Table: true, atom.Tr: true, atom.Blockquote: true, atom.Pre: true, atom.Br: true,
+	atom.Hr: true, atom.Figure: true, atom.Figcaption: true,
+}
+
+// isBoilerplate 判断元素是否为样板内容
+func isBoilerplate(n *html.Node) bool {
+	if htmlBoilerplateTags[n.DataAtom] {
+		return true
+	}
+	for _, attr := range n.Attr {
+		if attr.Key == "hidden" || attr.Key == "aria-hidden" && attr.Val == "true" {
+			return true
+		}
+		if attr.Key != "class" && attr.Key != "id" && attr.Key != "role" {
+			continue
+		}
+		for _, token := range strings.FieldsFunc(strings.ToLower(attr.Val), func(r rune) bool {
+			return r == ' ' || r == '-' || r == '_'
+		}) {
+			for _, marker := range htmlBoilerplateMarkers {
+				if token == marker {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// extractHTMLText 提取正文文本，块级元素之间换行
+func extractHTMLText(n *html.Node, sb *strings.Builder) {
+	switch n.Type {
+	case html.TextNode:
+		// 源码中的换行只是空白，<pre> 中的换行保留
+		if inPre(n) {
+			sb.WriteString(n.Data)
+		} else {
+			sb.WriteString(strings.ReplaceAll(n.Data, "\n", " "))
+		}
+		return
+	case html.ElementNode:
+		if isBoilerplate(n) {
+			return
+		}
+	case html.CommentNode, html.DoctypeNode:
+		return
+	}
+
+	block := n.Type == html.ElementNode && htmlBlockTags[n.DataAtom]
+	if block {
+		sb.WriteString("\n")
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		extractHTMLText(c, sb)
+		if c.Type == html.ElementNode && (c.DataAtom == atom.Td || c.DataAtom == atom.Th) {
+			sb.WriteString("\t")
+		}
+	}
+	if block {
+		sb.WriteString("\n")
+	}
+}
+
+// inPre 判断节点是否位于 <pre> 内
+func inPre(n *html.Node) bool {
+	for p := n.Parent; p != nil; p = p.Parent {
+		if p.Type == html.ElementNode && p.DataAtom == atom.Pre {
+			return true
+		}
+	}
+	return false
+}
+
+// findElement 深度优先查找第一个指定元素
+func findElement(n *html.Node, a atom.Atom) *html.Node {
+	if n.Type == html.ElementNode && n.DataAtom == a {
+		return n
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if found := findElement(c, a); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// nodeText 返回元素内的全部文本
+func nodeText(n *html.Node) string {
+	var sb strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			sb.WriteString(n.Data)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return sb.String()
+}
+
+// metaContent 返回 <meta name="..."> 的 content
+func metaContent(n *html.Node, name string) string {
+	if n.Type == html.ElementNode && n.DataAtom == atom.Meta {
+		var key, content string
+		for _, attr := range n.Attr {
+			switch attr.Key {
+			case "name":
+				key = attr.Val
+			case "content":
+				content = attr.Val
+			}
+		}
+		if strings.EqualFold(key, name) {
+			return strings.TrimSpace(content)
+		}
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if content := metaContent(c, name); content != "" {
+			return content
+		}
+	}
+	return ""
+}
+
+// collapseSpaces 将连续空白合并为一个空格
+func collapseSpaces(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// normalizeExtractedText 合并行内空白、去掉空行，段落之间保留一个空行
+func normalizeExtractedText(text string) string {
+	var paragraphs []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = collapseSpaces(line); line != "" {
+			paragraphs = append(paragraphs, line)
+		}
+	}
+	return strings.Join(paragraphs, "\n\n")
+}
+
+// compile-time interface check
+var _ DocumentLoader = (*HTMLLoader)(nil)
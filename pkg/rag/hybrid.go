@@ -0,0 +1,107 @@
+package rag
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// HybridRetriever 混合检索器
+//
+// 并行执行向量检索和关键词检索，再用融合策略合并两路结果。
+// 默认使用 RRF 融合（只看排名，不受两路分数量纲不同的影响）；
+// 需要按分数加权时使用 WithHybridFusion(NewLinearFusion())。
+// 一路检索失败时只使用另一路的结果，两路都失败时返回错误。
+type HybridRetriever struct {
+	dense         Retriever
+	sparse        Retriever
+	fusion        FusionStrategy
+	denseWeight   float32
+	sparseWeight  float32
+	candidateMult int
+}
+
+// HybridRetrieverOption 混合检索器选项
+type HybridRetrieverOption func(*HybridRetriever)
+
+// WithHybridWeights 设置向量检索和关键词检索的权重（默认均为 1，不大于 0 的权重按 1 处理）
+func WithHybridWeights(dense, sparse float32) HybridRetrieverOption {
+	return func(r *HybridRetriever) {
+		r.denseWeight = dense
+		r.sparseWeight = sparse
+	}
+}
+
+// WithHybridFusion 设置融合策略（默认 RRF，k=60）
+func WithHybridFusion(fusion FusionStrategy) HybridRetrieverOption {
+	return func(r *HybridRetriever) {
+		r.fusion = fusion
+	}
+}
+
+// WithHybridCandidates 设置每路检索的候选数量为 topK 的倍数（默认 2）
+func WithHybridCandidates(multiplier int) HybridRetrieverOption {
+	return func(r *HybridRetriever) {
+		if multiplier > 0 {
+			r.candidateMult = multiplier
+		}
+	}
+}
+
+// NewHybridRetriever 创建混合检索器
+//
+// dense 通常为 VectorRetriever，sparse 通常为 BM25Retriever。
+func NewHybridRetriever(dense, sparse Retriever, opts ...HybridRetrieverOption) *HybridRetriever {
+	r := &HybridRetriever{
+		dense:         dense,
+		sparse:        sparse,
+		fusion:        NewRRFFusion(60),
+		denseWeight:   1,
+		sparseWeight:  1,
+		candidateMult: 2,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Retrieve 混合检索（实现 Retriever 接口）
+func (r *HybridRetriever) Retrieve(ctx context.Context, query string, topK int) ([]RetrievalResult, error) {
+	fetchK := topK * r.candidateMult
+	retrievers := [2]Retriever{r.dense, r.sparse}
+
+	var (
+		wg      sync.WaitGroup
+		results [2][]RetrievalResult
+		errs    [2]error
+	)
+	for i, retriever := range retrievers {
+		wg.Add(1)
+		go func(i int, retriever Retriever) {
+			defer wg.Done()
+			results[i], errs[i] = retriever.Retrieve(ctx, query, fetchK)
+		}(i, retriever)
+	}
+	wg.Wait()
+
+	if errs[0] != nil && errs[1] != nil {
+		return nil, errors.Join(errs[0], errs[1])
+	}
+
+	weights := []float32{r.denseWeight, r.sparseWeight}
+	var (
+		lists      [][]RetrievalResult
+		useWeights []float32
+	)
+	for i := range retrievers {
+		if errs[i] == nil {
+			lists = append(lists, results[i])
+			useWeights = append(useWeights, weights[i])
+		}
+	}
+	return r.fusion.Fuse(lists, useWeights, topK), nil
+}
+
+// compile-time interface check
+var _ Retriever = (*HybridRetriever)(nil)
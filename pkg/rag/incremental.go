@@ -0,0 +1,253 @@
+package rag
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// IngestRecord 已摄取文档的内容指纹
+type IngestRecord struct {
+	// Hash 文档内容和元数据的哈希
+	Hash string `json:"hash"`
+	// Chunks 块 ID -> 块内容和元数据的哈希
+	Chunks map[string]string `json:"chunks"`
+	// Parents 父块 ID（父子分块模式）
+	Parents []string `json:"parents,omitempty"`
+}
+
+// IngestIndex 增量摄取索引，记录每个文档上次摄取时的指纹
+//
+// 管道据此跳过未变化的文档和块，并删除文档变更后不再存在的块。
+// 索引与向量存储需要同生命周期：使用持久化向量存储时应使用持久化索引（如 FileIngestIndex），
+// 否则重启后所有文档会被视为新文档重新嵌入（结果仍然正确，只是没有节省）。
+type IngestIndex interface {
+	// Get 获取文档的摄取记录
+	Get(docID string) (IngestRecord, bool)
+	// Put 保存文档的摄取记录
+	Put(docID string, record IngestRecord) error
+	// Delete 删除文档的摄取记录
+	Delete(docID string) error
+}
+
+// InMemoryIngestIndex 内存增量摄取索引
+type InMemoryIngestIndex struct {
+	records map[string]IngestRecord
+	mu      sync.RWMutex
+}
+
+// NewInMemoryIngestIndex 创建内存增量摄取索引
+func NewInMemoryIngestIndex() *InMemoryIngestIndex {
+	return &InMemoryIngestIndex{
+		records: make(map[string]IngestRecord),
+	}
+}
+
+// Get 获取文档的摄取记录
+func (i *InMemoryIngestIndex) Get(docID string) (IngestRecord, bool) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	record, ok := i.records[docID]
+	return record, ok
+}
+
+// Put 保存文档的摄取记录
+func (i *InMemoryIngestIndex) Put(docID string, record IngestRecord) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.records[docID] = record
+	return nil
+}
+
+// Delete 删除文档的摄取记录
+func (i *InMemoryIngestIndex) Delete(docID string) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	delete(i.records, docID)
+	return nil
+}
+
+// FileIngestIndex 基于 JSON 文件的增量摄取索引
+//
+// 每次修改后整体写回文件（先写临时文件再重命名），适合与 SQLite 等本地持久化向量存储搭配。
+type FileIngestIndex struct {
+	path string
+	mem  *InMemoryIngestIndex
+	mu   sync.Mutex
+}
+
+// NewFileIngestIndex 创建文件增量摄取索引，文件不存在时从空索引开始
+func NewFileIngestIndex(path string) (*FileIngestIndex, error) {
+	idx := &FileIngestIndex{
+		path: path,
+		mem:  NewInMemoryIngestIndex(),
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return idx, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &idx.mem.records); err != nil {
+		return nil, fmt.Errorf("failed to decode ingest index %s: %w", path, err)
+	}
+	return idx, nil
+}
+
+// Get 获取文档的摄取记录
+func (i *FileIngestIndex) Get(docID string) (IngestRecord, bool) {
+	return i.mem.Get(docID)
+}
+
+// Put 保存文档的摄取记录
+func (i *FileIngestIndex) Put(docID string, record IngestRecord) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	_ = i.mem.Put(docID, record)
+	return i.save()
+}
+
+// Delete 删除文档的摄取记录
+func (i *FileIngestIndex) Delete(docID string) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	_ = i.mem.Delete(docID)
+	return i.save()
+}
+
+// save 写回文件
+func (i *FileIngestIndex) save() error {
+	i.mem.mu.RLock()
+	data, err := json.Marshal(i.mem.records)
+	i.mem.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(i.path), filepath.Base(i.path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), i.path)
+}
+
+// IngestStats 摄取统计
+type IngestStats struct {
+	// Documents 处理的文档数量
+	Documents int `json:"documents"`
+	// UnchangedDocuments 内容未变化而跳过的文档数量
+	UnchangedDocuments int `json:"unchanged_documents"`
+	// EmbeddedChunks 新增或变化后重新嵌入的块数量
+	EmbeddedChunks int `json:"embedded_chunks"`
+	// UnchangedChunks 所属文档有变化但自身未变化的块数量
+	UnchangedChunks int `json:"unchanged_chunks"`
+	// DeletedChunks 文档变化后不再存在而被删除的块数量
+	DeletedChunks int `json:"deleted_chunks"`
+	// DuplicateChunks 检测到的近重复块数量（启用 WithDeduplicator 时）
+	DuplicateChunks int `json:"duplicate_chunks"`
+	// DroppedDuplicates 按去重策略丢弃、未写入向量存储的近重复块数量
+	DroppedDuplicates int `json:"dropped_duplicates"`
+}
+
+// hashDocument 计算文档指纹，摄取时间和过期时间不参与计算
+func hashDocument(doc Document) string {
+	return hashContent(doc.Content, doc.Metadata)
+}
+
+// hashChunk 计算块指纹
+func hashChunk(chunk DocumentChunk) string {
+	return hashContent(fmt.Sprintf("%d:%d:%s", chunk.StartOffset, chunk.EndOffset, chunk.Content), chunk.Metadata)
+}
+
+// hashContent 计算内容和元数据的 SHA-256
+func hashContent(content string, metadata DocumentMetadata) string {
+	metadata.IngestedAt = time.Time{}
+	metadata.ExpiresAt = time.Time{}
+	meta, _ := json.Marshal(metadata)
+
+	h := sha256.New()
+	h.Write([]byte(content))
+	h.Write([]byte{0})
+	h.Write(meta)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// DeleteDocument 删除文档的全部块及其摄取记录
+func (p *DefaultRAGPipeline) DeleteDocument(ctx context.Context, docID string) error {
+	if docID == "" {
+		return fmt.Errorf("document id is required")
+	}
+
+	if record, ok := p.index.Get(docID); ok && len(record.Chunks) > 0 {
+		ids := make([]string, 0, len(record.Chunks))
+		for id := range record.Chunks {
+			ids = append(ids, id)
+		}
+		if err := p.store.Delete(ctx, ids); err != nil {
+			return fmt.Errorf("failed to delete chunks: %w", err)
+		}
+		if p.graph != nil {
+			if err := p.graph.RemoveChunks(ctx, ids); err != nil {
+				return fmt.Errorf("failed to update graph index: %w", err)
+			}
+		}
+		if p.multiVector != nil {
+			if err := p.multiVector.RemoveChunks(ctx, ids); err != nil {
+				return fmt.Errorf("failed to update multi-vector index: %w", err)
+			}
+		}
+		if p.dedup != nil {
+			p.dedup.RemoveChunks(ids)
+		}
+	}
+	// 索引中没有记录的块（如索引丢失后）按文档 ID 清理
+	if _, err := p.store.DeleteByFilter(ctx, DeleteFilter{DocumentIDs: []string{docID}}); err != nil {
+		return fmt.Errorf("failed to delete chunks: %w", err)
+	}
+	if p.parents != nil {
+		if err := p.parents.DeleteByDocument(ctx, docID); err != nil {
+			return fmt.Errorf("failed to delete parent chunks: %w", err)
+		}
+	}
+	if p.checkpoints != nil {
+		if err := p.checkpoints.delete(ctx, docID); err != nil {
+			return fmt.Errorf("failed to delete ingest checkpoint: %w", err)
+		}
+	}
+	return p.index.Delete(docID)
+}
+
+// missingIDs 返回 prev 中不在 current 里的 ID
+func missingIDs(prev, current []string) []string {
+	if len(prev) == 0 {
+		return nil
+	}
+	keep := make(map[string]struct{}, len(current))
+	for _, id := range current {
+		keep[id] = struct{}{}
+	}
+	var missing []string
+	for _, id := range prev {
+		if _, ok := keep[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+	return missing
+}
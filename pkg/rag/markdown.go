@@ -0,0 +1,229 @@
+package rag
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MarkdownLoader Markdown 文件加载器
+//
+// 支持 YAML front matter（文件开头两行 --- 之间的 key: value）：title、author、tags、
+// date/created_at 写入对应的元数据字段，其余键写入 Metadata.Custom。
+// 没有 title 时使用正文的第一个一级标题。加载的文档内容不含 front matter。
+type MarkdownLoader struct {
+	source string
+	reader io.Reader
+}
+
+// NewMarkdownLoader 从 io.Reader 创建 Markdown 加载器
+func NewMarkdownLoader(source string, reader io.Reader) *MarkdownLoader {
+	return &MarkdownLoader{
+		source: source,
+		reader: reader,
+	}
+}
+
+// Load 加载文档
+func (l *MarkdownLoader) Load(ctx context.Context) ([]Document, error) {
+	content, err := io.ReadAll(l.reader)
+	if err != nil {
+		return nil, err
+	}
+
+	frontMatter, body := splitFrontMatter(string(content))
+	metadata := DocumentMetadata{Source: l.source}
+	applyFrontMatter(&metadata, parseFrontMatter(frontMatter))
+	if metadata.Title == "" {
+		metadata.Title = firstHeading(body)
+	}
+
+	doc := Document{
+		ID:       generateID(),
+		Content:  body,
+		Metadata: metadata,
+	}
+	return []Document{doc}, nil
+}
+
+// SupportedExtensions 支持的文件扩展名
+func (l *MarkdownLoader) SupportedExtensions() []string {
+	return []string{".md", ".markdown"}
+}
+
+// splitFrontMatter 拆分 front matter 和正文，没有 front matter 时原样返回正文
+func splitFrontMatter(content string) (string, string) {
+	content = strings.TrimPrefix(content, "\ufeff")
+	if !strings.HasPrefix(content, "---\n") && !strings.HasPrefix(content, "---\r\n") {
+		return "", content
+	}
+
+	rest := content[strings.Index(content, "\n")+1:]
+	for offset := 0; offset < len(rest); {
+		end := strings.Index(rest[offset:], "\n")
+		line := rest[offset:]
+		if end >= 0 {
+			line = rest[offset : offset+end]
+		}
+		if strings.TrimRight(line, "\r ") == "---" {
+			body := ""
+			if end >= 0 {
+				body = rest[offset+end+1:]
+			}
+			return rest[:offset], strings.TrimLeft(body, "\r\n")
+		}
+		if end < 0 {
+			break
+		}
+		offset += end + 1
+	}
+	return "", content
+}
+
+// parseFrontMatter 解析简单的 YAML front matter
+//
+// 支持标量、带引号的字符串、行内列表 [a, b] 和块列表（- item），不支持嵌套映射。
+func parseFrontMatter(frontMatter string) map[string]interface{} {
+	values := make(map[string]interface{})
+	var listKey string
+
+	scanner := bufio.NewScanner(strings.NewReader(frontMatter))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r ")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		if listKey != "" && strings.HasPrefix(trimmed, "- ") {
+			list, _ := values[listKey].([]string)
+			values[listKey] = append(list, unquoteYAML(strings.TrimSpace(trimmed[2:])))
+			continue
+		}
+		listKey = ""
+
+		key, value, ok := strings.Cut(line, ":")
+		if !ok || strings.HasPrefix(line, " ") {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+
+		switch {
+		case value == "":
+			listKey = key
+			values[key] = []string{}
+		case strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]"):
+			items := []string{}
+			for _, item := range strings.Split(value[1:len(value)-1], ",") {
+				if item = unquoteYAML(strings.TrimSpace(item)); item != "" {
+					items = append(items, item)
+				}
+			}
+			values[key] = items
+		default:
+			values[key] = scalarYAML(value)
+		}
+	}
+	return values
+}
+
+// unquoteYAML 去掉字符串两端的引号
+func unquoteYAML(s string) string {
+	if len(s) >= 2 && (s[0] == '"' && s[len(s)-1] == '"' || s[0] == '\'' && s[len(s)-1] == '\'') {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+// scalarYAML 将标量转换为布尔值、数字或字符串
+func scalarYAML(s string) interface{} {
+	if unquoted := unquoteYAML(s); unquoted != s {
+		return unquoted
+	}
+	switch s {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return n
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f
+	}
+	return s
+}
+
+// applyFrontMatter 将 front matter 写入文档元数据
+func applyFrontMatter(metadata *DocumentMetadata, values map[string]interface{}) {
+	for key, value := range values {
+		switch strings.ToLower(key) {
+		case "title":
+			metadata.Title = stringValue(value)
+			continue
+		case "author":
+			metadata.Author = stringValue(value)
+			continue
+		case "tags":
+			if tags, ok := value.([]string); ok {
+				metadata.Tags = tags
+			} else if tag := stringValue(value); tag != "" {
+				metadata.Tags = []string{tag}
+			}
+			continue
+		case "date", "created_at":
+			if t, ok := parseDate(stringValue(value)); ok {
+				metadata.CreatedAt = t
+				continue
+			}
+		}
+		if metadata.Custom == nil {
+			metadata.Custom = make(map[string]interface{})
+		}
+		metadata.Custom[key] = value
+	}
+}
+
+// stringValue 将标量转换为字符串
+func stringValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	return ""
+}
+
+// parseDate 解析常见的日期格式
+func parseDate(s string) (time.Time, bool) {
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// firstHeading 返回正文的第一个一级标题
+func firstHeading(body string) string {
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "# ") {
+			return strings.TrimSpace(line[2:])
+		}
+	}
+	return ""
+}
+
+// compile-time interface check
+var _ DocumentLoader = (*MarkdownLoader)(nil)
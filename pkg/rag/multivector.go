@@ -0,0 +1,225 @@
+package rag
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MultiVectorIndex 多向量索引（ColBERT 式延迟交互）
+//
+// 单向量模式下长块的嵌入是全部内容的平均，只涉及块中一小段的查询容易被稀释。
+// 多向量索引把每个块切分为多个片段（默认按句子），每个片段单独嵌入；检索时查询同样切分，
+// 块的分数为每个查询片段与块内所有片段的最大相似度的平均值（MaxSim）。
+//
+// 索引保存在内存中，与向量存储并行维护：通过 WithMultiVectorIndex 接入管道后，
+// 摄取和删除文档时自动同步。单向量检索仍是默认路径，用 NewMultiVectorRetriever 启用多向量检索。
+type MultiVectorIndex struct {
+	embedder  Embedder
+	segmenter func(string) []string
+
+	mu      sync.RWMutex
+	entries map[string]multiVectorEntry
+}
+
+// multiVectorEntry 块及其片段向量
+type multiVectorEntry struct {
+	chunk   DocumentChunk
+	vectors [][]float32
+}
+
+// MultiVectorIndexOption 多向量索引选项
+type MultiVectorIndexOption func(*MultiVectorIndex)
+
+// WithMultiVectorSegmenter 设置片段切分函数（默认按句子切分），块和查询使用同一切分函数
+func WithMultiVectorSegmenter(segmenter func(text string) []string) MultiVectorIndexOption {
+	return func(m *MultiVectorIndex) {
+		if segmenter != nil {
+			m.segmenter = segmenter
+		}
+	}
+}
+
+// NewMultiVectorIndex 创建多向量索引
+func NewMultiVectorIndex(embedder Embedder, opts ...MultiVectorIndexOption) *MultiVectorIndex {
+	m := &MultiVectorIndex{
+		embedder:  embedder,
+		segmenter: sentenceSegments,
+		entries:   make(map[string]multiVectorEntry),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// Index 切分并嵌入块的片段，ID 已存在时覆盖
+func (m *MultiVectorIndex) Index(ctx context.Context, chunks []DocumentChunk) error {
+	var texts []string
+	bounds := make([]int, len(chunks)+1)
+	for i, chunk := range chunks {
+		texts = append(texts, m.segments(chunk.Content)...)
+		bounds[i+1] = len(texts)
+	}
+	if len(texts) == 0 {
+		return nil
+	}
+
+	vectors, err := m.embedder.Embed(ctx, texts)
+	if err != nil {
+		return err
+	}
+	if len(vectors) != len(texts) {
+		return fmt.Errorf("embedder returned %d vectors for %d segments", len(vectors), len(texts))
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, chunk := range chunks {
+		m.entries[chunk.ID] = multiVectorEntry{chunk: chunk, vectors: vectors[bounds[i]:bounds[i+1]]}
+	}
+	return nil
+}
+
+// RemoveChunks 移除块
+func (m *MultiVectorIndex) RemoveChunks(ctx context.Context, ids []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, id := range ids {
+		delete(m.entries, id)
+	}
+	return nil
+}
+
+// Size 返回索引的块数量
+func (m *MultiVectorIndex) Size() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.entries)
+}
+
+// Search 按 MaxSim 分数返回 top K 个满足过滤条件的块，filter 为空时不过滤
+func (m *MultiVectorIndex) Search(ctx context.Context, query string, topK int, filter MetadataFilter) ([]RetrievalResult, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	segments := m.segments(query)
+	if len(segments) == 0 || topK <= 0 {
+		return nil, nil
+	}
+	queryVectors, err := m.embedder.Embed(ctx, segments)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	now := time.Now()
+	results := make([]RetrievalResult, 0, len(m.entries))
+	for _, entry := range m.entries {
+		if entry.chunk.Metadata.Expired(now) || !filter.Match(entry.chunk) {
+			continue
+		}
+		results = append(results, RetrievalResult{Chunk: entry.chunk, Score: maxSim(queryVectors, entry.vectors)})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Chunk.ID < results[j].Chunk.ID
+	})
+	if len(results) > topK {
+		results = results[:topK]
+	}
+	return results, nil
+}
+
+// segments 切分文本，没有可用片段时使用整段文本
+func (m *MultiVectorIndex) segments(text string) []string {
+	segments := m.segmenter(text)
+	if len(segments) == 0 && strings.TrimSpace(text) != "" {
+		return []string{text}
+	}
+	return segments
+}
+
+// sentenceSegments 按句子切分文本，去掉空白片段
+func sentenceSegments(text string) []string {
+	var segments []string
+	for _, sentence := range splitSentences(text) {
+		if sentence = strings.TrimSpace(sentence); sentence != "" {
+			segments = append(segments, sentence)
+		}
+	}
+	return segments
+}
+
+// maxSim 延迟交互分数：每个查询向量与文档向量的最大相似度的平均值
+func maxSim(query, doc [][]float32) float32 {
+	if len(query) == 0 || len(doc) == 0 {
+		return 0
+	}
+	var total float32
+	for _, q := range query {
+		best := float32(-1)
+		for _, d := range doc {
+			if s := cosineSimilarity(q, d); s > best {
+				best = s
+			}
+		}
+		total += best
+	}
+	return total / float32(len(query))
+}
+
+// MultiVectorRetriever 多向量检索器，用 MultiVectorIndex 的 MaxSim 分数检索
+type MultiVectorRetriever struct {
+	index     *MultiVectorIndex
+	threshold float32
+}
+
+// MultiVectorRetrieverOption 多向量检索器选项
+type MultiVectorRetrieverOption func(*MultiVectorRetriever)
+
+// WithMultiVectorThreshold 设置最低 MaxSim 分数
+func WithMultiVectorThreshold(threshold float32) MultiVectorRetrieverOption {
+	return func(r *MultiVectorRetriever) {
+		r.threshold = threshold
+	}
+}
+
+// NewMultiVectorRetriever 创建多向量检索器
+func NewMultiVectorRetriever(index *MultiVectorIndex, opts ...MultiVectorRetrieverOption) *MultiVectorRetriever {
+	r := &MultiVectorRetriever{index: index}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Retrieve 检索与查询相关的文档块
+func (r *MultiVectorRetriever) Retrieve(ctx context.Context, query string, topK int) ([]RetrievalResult, error) {
+	return r.RetrieveWithFilter(ctx, query, topK, nil)
+}
+
+// RetrieveWithFilter 检索满足元数据过滤条件的文档块
+func (r *MultiVectorRetriever) RetrieveWithFilter(ctx context.Context, query string, topK int, filter MetadataFilter) ([]RetrievalResult, error) {
+	results, err := r.index.Search(ctx, query, topK, filter)
+	if err != nil {
+		return nil, err
+	}
+	filtered := results[:0]
+	for _, res := range results {
+		if res.Score >= r.threshold {
+			filtered = append(filtered, res)
+		}
+	}
+	return filtered, nil
+}
+
+// compile-time interface check
+var _ Retriever = (*MultiVectorRetriever)(nil)
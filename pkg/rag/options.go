@@ -20,6 +20,18 @@ type RetrieveOptions struct {
 
 	// FetchMultiplier 多查询时的获取倍数（用于融合后有足够结果）
 	FetchMultiplier int
+
+	// StrategyTimeout 单个策略（一次变换、检索或后处理）的超时，0 表示仅受 Timeout 约束
+	StrategyTimeout time.Duration
+
+	// StableRounds 融合后 topK 连续保持不变的完成次数达到该值时取消剩余检索，0 表示等待全部完成
+	StableRounds int
+
+	// Normalizer 查询规范化器，设置后变换、检索和后处理都使用规范化后的查询
+	Normalizer QueryNormalizer
+
+	// Filter 元数据过滤条件，检索时下推到向量存储
+	Filter MetadataFilter
 }
 
 // DefaultRetrieveOptions 默认检索选项
@@ -41,6 +53,22 @@ func WithTransformer(transformer QueryTransformer) RetrieveOption {
 	}
 }
 
+// WithQueryNormalizer 在检索前规范化查询（拼写纠正、大小写、标点等），原始查询记录在 RetrievalReport 中
+func WithQueryNormalizer(normalizer QueryNormalizer) RetrieveOption {
+	return func(opts *RetrieveOptions) {
+		opts.Normalizer = normalizer
+	}
+}
+
+// WithFilter 只检索满足元数据条件的块，多次调用时条件累加（AND）
+//
+// 条件下推到实现 FilteredSearcher 的向量存储；其他存储扩大召回数量后再过滤。
+func WithFilter(conditions ...FilterCondition) RetrieveOption {
+	return func(opts *RetrieveOptions) {
+		opts.Filter = append(opts.Filter, conditions...)
+	}
+}
+
 // WithMQE 启用多查询扩展
 func WithMQE(llm LLMProvider, numQueries int) RetrieveOption {
 	return func(opts *RetrieveOptions) {
@@ -71,6 +99,20 @@ func WithHyDETransformer(transformer *HyDETransformer) RetrieveOption {
 	}
 }
 
+// WithSubQuestions 启用子问题分解，llm 为 nil 时只使用规则分解
+func WithSubQuestions(llm LLMProvider) RetrieveOption {
+	return func(opts *RetrieveOptions) {
+		opts.Transformers = append(opts.Transformers, NewSubQuestionTransformer(llm))
+	}
+}
+
+// WithSubQuestionTransformer 使用已配置的子问题分解变换器
+func WithSubQuestionTransformer(transformer *SubQuestionTransformer) RetrieveOption {
+	return func(opts *RetrieveOptions) {
+		opts.Transformers = append(opts.Transformers, transformer)
+	}
+}
+
 // WithPostProcessor 添加后处理器
 func WithPostProcessor(processor PostProcessor) RetrieveOption {
 	return func(opts *RetrieveOptions) {
@@ -79,6 +121,9 @@ func WithPostProcessor(processor PostProcessor) RetrieveOption {
 }
 
 // WithRerank 启用重排序
+//
+// 重排序器从 topK × FetchMultiplier 个候选中重新排序，结果再截断到 topK。
+// 内置 LLMReranker 和 HTTPReranker（Cohere 等交叉编码器服务）。
 func WithRerank(reranker Reranker) RetrieveOption {
 	return func(opts *RetrieveOptions) {
 		processor := NewRerankPostProcessor(reranker)
@@ -86,6 +131,29 @@ func WithRerank(reranker Reranker) RetrieveOption {
 	}
 }
 
+// WithContentKindBoost 按块的内容类型（MetadataContentKind）调整分数，如提高代码块的排名
+func WithContentKindBoost(weights map[string]float32) RetrieveOption {
+	return func(opts *RetrieveOptions) {
+		opts.PostProcessors = append(opts.PostProcessors, NewContentKindBooster(weights))
+	}
+}
+
+// WithRecencyBoost 按文档时效加权，weight 为时效所占比重（0~1），时效按天衰减
+//
+// 需要其他衰减尺度时使用 WithPostProcessor(NewRecencyBooster(weight, scale))。
+func WithRecencyBoost(weight float32) RetrieveOption {
+	return func(opts *RetrieveOptions) {
+		opts.PostProcessors = append(opts.PostProcessors, NewRecencyBooster(weight, DefaultRecencyScale))
+	}
+}
+
+// WithSourceWeights 按来源权威度加权，weights 为来源（或来源前缀）到分数乘数的映射
+func WithSourceWeights(weights map[string]float32) RetrieveOption {
+	return func(opts *RetrieveOptions) {
+		opts.PostProcessors = append(opts.PostProcessors, NewSourceWeightBooster(weights))
+	}
+}
+
 // WithFusion 设置融合策略
 func WithFusion(fusion FusionStrategy) RetrieveOption {
 	return func(opts *RetrieveOptions) {
@@ -123,6 +191,24 @@ func WithFetchMultiplier(multiplier int) RetrieveOption {
 	}
 }
 
+// WithStrategyTimeout 设置单个策略的超时
+//
+// 超时的策略被跳过，结果由已完成的策略融合。
+func WithStrategyTimeout(timeout time.Duration) RetrieveOption {
+	return func(opts *RetrieveOptions) {
+		opts.StrategyTimeout = timeout
+	}
+}
+
+// WithEarlyStop 在融合后的 topK 连续 rounds 次不变时取消仍在执行的检索
+func WithEarlyStop(rounds int) RetrieveOption {
+	return func(opts *RetrieveOptions) {
+		if rounds > 0 {
+			opts.StableRounds = rounds
+		}
+	}
+}
+
 // applyOptions 应用选项
 func applyOptions(opts []RetrieveOption) *RetrieveOptions {
 	options := DefaultRetrieveOptions()
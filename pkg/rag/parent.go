@@ -0,0 +1,358 @@
+package rag
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// MetadataMatchedChunks 父块检索结果中命中的子块 ID 列表（Metadata.Custom 键）
+const MetadataMatchedChunks = "matched_chunks"
+
+// ChunkStore 父块存储
+//
+// 父子分块（small-to-big）模式下，子块写入向量存储用于精确检索，
+// 父块按 ID 保存在这里，检索时由 ParentDocumentRetriever 展开。
+type ChunkStore interface {
+	// Add 添加块，ID 已存在时覆盖
+	Add(ctx context.Context, chunks []DocumentChunk) error
+	// Get 按 ID 获取块，不存在的 ID 被忽略
+	Get(ctx context.Context, ids []string) ([]DocumentChunk, error)
+	// GetByDocument 获取文档的全部块，按 Index 升序
+	GetByDocument(ctx context.Context, docID string) ([]DocumentChunk, error)
+	// Delete 删除块
+	Delete(ctx context.Context, ids []string) error
+	// DeleteByDocument 删除文档的全部块
+	DeleteByDocument(ctx context.Context, docID string) error
+}
+
+// InMemoryChunkStore 内存父块存储
+type InMemoryChunkStore struct {
+	chunks map[string]DocumentChunk
+	byDoc  map[string]map[string]struct{}
+	mu     sync.RWMutex
+}
+
+// NewInMemoryChunkStore 创建内存父块存储
+func NewInMemoryChunkStore() *InMemoryChunkStore {
+	return &InMemoryChunkStore{
+		chunks: make(map[string]DocumentChunk),
+		byDoc:  make(map[string]map[string]struct{}),
+	}
+}
+
+// Add 添加块
+func (s *InMemoryChunkStore) Add(ctx context.Context, chunks []DocumentChunk) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, chunk := range chunks {
+		s.deleteLocked(chunk.ID)
+		chunk.Vector = nil
+		s.chunks[chunk.ID] = chunk
+		if s.byDoc[chunk.DocumentID] == nil {
+			s.byDoc[chunk.DocumentID] = make(map[string]struct{})
+		}
+		s.byDoc[chunk.DocumentID][chunk.ID] = struct{}{}
+	}
+	return nil
+}
+
+// Get 按 ID 获取块
+func (s *InMemoryChunkStore) Get(ctx context.Context, ids []string) ([]DocumentChunk, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	chunks := make([]DocumentChunk, 0, len(ids))
+	for _, id := range ids {
+		if chunk, ok := s.chunks[id]; ok {
+			chunks = append(chunks, chunk)
+		}
+	}
+	return chunks, nil
+}
+
+// GetByDocument 获取文档的全部块
+func (s *InMemoryChunkStore) GetByDocument(ctx context.Context, docID string) ([]DocumentChunk, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	chunks := make([]DocumentChunk, 0, len(s.byDoc[docID]))
+	for id := range s.byDoc[docID] {
+		chunks = append(chunks, s.chunks[id])
+	}
+	sort.Slice(chunks, func(i, j int) bool { return chunks[i].Index < chunks[j].Index })
+	return chunks, nil
+}
+
+// Delete 删除块
+func (s *InMemoryChunkStore) Delete(ctx context.Context, ids []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, id := range ids {
+		s.deleteLocked(id)
+	}
+	return nil
+}
+
+// DeleteByDocument 删除文档的全部块
+func (s *InMemoryChunkStore) DeleteByDocument(ctx context.Context, docID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id := range s.byDoc[docID] {
+		delete(s.chunks, id)
+	}
+	delete(s.byDoc, docID)
+	return nil
+}
+
+// Size 返回块数量
+func (s *InMemoryChunkStore) Size() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.chunks)
+}
+
+// deleteLocked 删除块（调用方需持有写锁）
+func (s *InMemoryChunkStore) deleteLocked(id string) {
+	chunk, ok := s.chunks[id]
+	if !ok {
+		return
+	}
+	delete(s.chunks, id)
+	delete(s.byDoc[chunk.DocumentID], id)
+	if len(s.byDoc[chunk.DocumentID]) == 0 {
+		delete(s.byDoc, chunk.DocumentID)
+	}
+}
+
+// splitParentChild 将文档切分为父块和子块
+//
+// parentChunker 为 nil 时整个文档作为唯一的父块。子块由 childChunker 在父块内切分，
+// ParentID 指向所属父块，偏移量相对于原文档，Index 在文档内连续编号。
+func splitParentChild(doc Document, parentChunker, childChunker DocumentChunker) (parents, children []DocumentChunk) {
+	if parentChunker != nil {
+		parents = parentChunker.Chunk(doc)
+	} else if strings.TrimSpace(doc.Content) != "" {
+		parents = []DocumentChunk{{
+			ID:         generateChunkID(doc.ID+"/parent", 0),
+			DocumentID: doc.ID,
+			Content:    doc.Content,
+			EndOffset:  len(doc.Content),
+			Metadata:   doc.Metadata,
+		}}
+	}
+
+	for _, parent := range parents {
+		sub := childChunker.Chunk(Document{ID: parent.ID, Content: parent.Content, Metadata: doc.Metadata})
+		for _, child := range sub {
+			child.ID = generateChunkID(parent.ID, child.Index)
+			child.DocumentID = doc.ID
+			child.ParentID = parent.ID
+			child.Index = len(children)
+			child.StartOffset += parent.StartOffset
+			child.EndOffset += parent.StartOffset
+			children = append(children, child)
+		}
+	}
+	return parents, children
+}
+
+// ParentDocumentRetriever 父块检索器（small-to-big）
+//
+// 用基础检索器检索小的子块以获得精确匹配，再返回子块所属的父块供生成使用。
+// 多个子块命中同一父块时只返回一次，分数取命中子块的最高分，命中的子块 ID
+// 记录在 Metadata.Custom[MetadataMatchedChunks] 中。没有父块的结果原样返回。
+type ParentDocumentRetriever struct {
+	base        Retriever
+	parents     ChunkStore
+	window      int
+	fullDoc     bool
+	fetchFactor int
+}
+
+// ParentRetrieverOption 父块检索器选项
+type ParentRetrieverOption func(*ParentDocumentRetriever)
+
+// WithParentWindow 返回父块时同时合并前后各 n 个相邻父块（默认 0）
+func WithParentWindow(n int) ParentRetrieverOption {
+	return func(r *ParentDocumentRetriever) {
+		if n >= 0 {
+			r.window = n
+		}
+	}
+}
+
+// WithFullDocument 返回命中子块所属文档的全部父块（即完整文档）
+func WithFullDocument() ParentRetrieverOption {
+	return func(r *ParentDocumentRetriever) {
+		r.fullDoc = true
+	}
+}
+
+// WithParentFetchFactor 设置子块检索数量为 topK 的倍数（默认 4），用于多个子块指向同一父块时补足结果
+func WithParentFetchFactor(factor int) ParentRetrieverOption {
+	return func(r *ParentDocumentRetriever) {
+		if factor > 0 {
+			r.fetchFactor = factor
+		}
+	}
+}
+
+// NewParentDocumentRetriever 创建父块检索器
+//
+// base 检索子块（通常为基于子块向量存储的 VectorRetriever），parents 为摄取时
+// 通过 WithParentChunks 写入父块的存储。
+func NewParentDocumentRetriever(base Retriever, parents ChunkStore, opts ...ParentRetrieverOption) *ParentDocumentRetriever {
+	r := &ParentDocumentRetriever{
+		base:        base,
+		parents:     parents,
+		fetchFactor: 4,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Retrieve 检索子块并展开为父块（实现 Retriever 接口）
+func (r *ParentDocumentRetriever) Retrieve(ctx context.Context, query string, topK int) ([]RetrievalResult, error) {
+	children, err := r.base.Retrieve(ctx, query, topK*r.fetchFactor)
+	if err != nil {
+		return nil, err
+	}
+
+	// 按首次命中顺序分组（基础检索结果已按分数降序）
+	type group struct {
+		parent  DocumentChunk
+		score   float32
+		matched []string
+	}
+	var (
+		groups []*group
+		byKey  = make(map[string]*group)
+	)
+	for _, child := range children {
+		key := child.Chunk.ParentID
+		if key == "" {
+			key = "chunk:" + child.Chunk.ID
+		} else if r.fullDoc {
+			key = "doc:" + child.Chunk.DocumentID
+		}
+
+		g, ok := byKey[key]
+		if !ok {
+			if len(groups) == topK {
+				continue
+			}
+			g = &group{parent: child.Chunk, score: child.Score}
+			byKey[key] = g
+			groups = append(groups, g)
+		}
+		g.matched = append(g.matched, child.Chunk.ID)
+		if child.Score > g.score {
+			g.score = child.Score
+		}
+	}
+
+	results := make([]RetrievalResult, 0, len(groups))
+	for _, g := range groups {
+		if g.parent.ParentID != "" {
+			parent, err := r.expand(ctx, g.parent)
+			if err != nil {
+				return nil, err
+			}
+			g.parent = parent
+		}
+		g.parent.Vector = nil
+		g.parent.Metadata.Custom = withMatchedChunks(g.parent.Metadata.Custom, g.matched)
+		results = append(results, RetrievalResult{Chunk: g.parent, Score: g.score})
+	}
+	return results, nil
+}
+
+// expand 将子块展开为父块（含相邻窗口或完整文档），父块缺失时返回子块本身
+func (r *ParentDocumentRetriever) expand(ctx context.Context, child DocumentChunk) (DocumentChunk, error) {
+	if !r.fullDoc && r.window == 0 {
+		found, err := r.parents.Get(ctx, []string{child.ParentID})
+		if err != nil || len(found) == 0 {
+			return child, err
+		}
+		return found[0], nil
+	}
+
+	siblings, err := r.parents.GetByDocument(ctx, child.DocumentID)
+	if err != nil || len(siblings) == 0 {
+		return child, err
+	}
+	if r.fullDoc {
+		return mergeChunks(siblings, child.DocumentID), nil
+	}
+
+	pos := -1
+	for i, s := range siblings {
+		if s.ID == child.ParentID {
+			pos = i
+			break
+		}
+	}
+	if pos < 0 {
+		return child, nil
+	}
+	start := max(pos-r.window, 0)
+	end := min(pos+r.window+1, len(siblings))
+	merged := mergeChunks(siblings[start:end], siblings[pos].ID)
+	merged.Index = siblings[pos].Index
+	return merged, nil
+}
+
+// mergeChunks 按顺序合并相邻块，块之间重叠的文本只保留一次
+func mergeChunks(chunks []DocumentChunk, id string) DocumentChunk {
+	if len(chunks) == 1 {
+		return chunks[0]
+	}
+
+	merged := chunks[0]
+	merged.ID = id
+	content := chunks[0].Content
+	for _, c := range chunks[1:] {
+		if rest, ok := trimOverlap(content, c.Content); ok {
+			content += rest
+		} else {
+			content += "\n\n" + c.Content
+		}
+		merged.EndOffset = max(merged.EndOffset, c.EndOffset)
+	}
+	merged.Content = content
+	return merged
+}
+
+// minMergeOverlap 合并块时认定为重叠的最短文本长度，避免误删偶然相同的标点或单词
+const minMergeOverlap = 10
+
+// trimOverlap 去掉 next 开头与 prev 结尾重叠的部分
+func trimOverlap(prev, next string) (string, bool) {
+	for k := min(len(prev), len(next)); k >= minMergeOverlap; k-- {
+		if strings.HasSuffix(prev, next[:k]) {
+			return next[k:], true
+		}
+	}
+	return "", false
+}
+
+// withMatchedChunks 复制元数据并记录命中的子块
+func withMatchedChunks(custom map[string]interface{}, matched []string) map[string]interface{} {
+	copied := make(map[string]interface{}, len(custom)+1)
+	for k, v := range custom {
+		copied[k] = v
+	}
+	copied[MetadataMatchedChunks] = matched
+	return copied
+}
+
+// compile-time interface check
+var _ ChunkStore = (*InMemoryChunkStore)(nil)
+var _ Retriever = (*ParentDocumentRetriever)(nil)
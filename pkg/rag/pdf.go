@@ -0,0 +1,515 @@
+package rag
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// PDFTextExtractor 从 PDF 提取文本，每页一个字符串
+type PDFTextExtractor func(data []byte) ([]string, error)
+
+// PDFLoaderOption PDF 加载器选项
+type PDFLoaderOption func(*PDFLoader)
+
+// WithPDFTextExtractor 设置 PDF 文本提取器，替换内置的简单提取器
+func WithPDFTextExtractor(extractor PDFTextExtractor) PDFLoaderOption {
+	return func(l *PDFLoader) {
+		l.extractor = extractor
+	}
+}
+
+// WithPDFSplitPages 每页生成一个文档，页码（从 1 开始）写入 Metadata.Custom["page"]
+func WithPDFSplitPages() PDFLoaderOption {
+	return func(l *PDFLoader) {
+		l.splitPages = true
+	}
+}
+
+// PDFLoader PDF 文件加载器
+//
+// 内置提取器解析未压缩或 FlateDecode 压缩的页面内容流中的文本操作符（Tj、TJ、'、"），
+// 适用于常见的文字型 PDF；使用自定义字体编码（CID 字体）、扫描件或加密的 PDF 需要通过
+// WithPDFTextExtractor 接入专门的解析库或 OCR。
+type PDFLoader struct {
+	source     string
+	reader     io.Reader
+	extractor  PDFTextExtractor
+	splitPages bool
+}
+
+// NewPDFLoader 从 io.Reader 创建 PDF 加载器
+func NewPDFLoader(source string, reader io.Reader, opts ...PDFLoaderOption) *PDFLoader {
+	l := &PDFLoader{
+		source:    source,
+		reader:    reader,
+		extractor: ExtractPDFText,
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// Load 加载文档
+func (l *PDFLoader) Load(ctx context.Context) ([]Document, error) {
+	data, err := io.ReadAll(l.reader)
+	if err != nil {
+		return nil, err
+	}
+
+	pages, err := l.extractor(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract pdf text: %w", err)
+	}
+
+	metadata := DocumentMetadata{
+		Source: l.source,
+		Title:  pdfInfoString(data, "Title"),
+		Author: pdfInfoString(data, "Author"),
+	}
+
+	if !l.splitPages {
+		doc := Document{
+			ID:       generateID(),
+			Content:  strings.Join(pages, "\n\n"),
+			Metadata: metadata,
+		}
+		return []Document{doc}, nil
+	}
+
+	docs := make([]Document, 0, len(pages))
+	for i, page := range pages {
+		if strings.TrimSpace(page) == "" {
+			continue
+		}
+		pageMetadata := metadata
+		pageMetadata.Custom = map[string]interface{}{"page": i + 1}
+		docs = append(docs, Document{
+			ID:       generateID(),
+			Content:  page,
+			Metadata: pageMetadata,
+		})
+	}
+	return docs, nil
+}
+
+// SupportedExtensions 支持的文件扩展名
+func (l *PDFLoader) SupportedExtensions() []string {
+	return []string{".pdf"}
+}
+
+// ErrNotPDF 数据不是 PDF 文件
+var ErrNotPDF = errors.New("not a pdf file")
+
+var (
+	pdfStreamPattern  = regexp.MustCompile(`stream\r?\n`)
+	pdfEncryptPattern = regexp.MustCompile(`/Encrypt\s`)
+	pdfFilterPattern  = regexp.MustCompile(`/(\w+Decode)`)
+)
+
+// ExtractPDFText 内置的 PDF 文本提取器
+//
+// 按文件中出现的顺序解码各个流，含文本操作符的流视为一页的内容流。
+func ExtractPDFText(data []byte) ([]string, error) {
+	if !bytes.HasPrefix(bytes.TrimLeft(data, "\x00\t\r\n "), []byte("%PDF-")) {
+		return nil, ErrNotPDF
+	}
+	if pdfEncryptPattern.Match(data) {
+		return nil, errors.New("encrypted pdf is not supported")
+	}
+
+	var pages []string
+	for _, loc := range pdfStreamPattern.FindAllIndex(data, -1) {
+		// 跳过 endstream 中的匹配
+		if loc[0] >= 3 && string(data[loc[0]-3:loc[0]]) == "end" {
+			continue
+		}
+		end := bytes.Index(data[loc[1]:], []byte("endstream"))
+		if end < 0 {
+			break
+		}
+		dictStart := bytes.LastIndex(data[:loc[0]], []byte("obj"))
+		if dictStart < 0 {
+			continue
+		}
+		content, ok := decodePDFStream(data[dictStart:loc[0]], data[loc[1]:loc[1]+end])
+		if !ok || !bytes.Contains(content, []byte("BT")) {
+			continue
+		}
+		if text := pdfContentText(content); text != "" {
+			pages = append(pages, text)
+		}
+	}
+	return pages, nil
+}
+
+// decodePDFStream 按流字典中的过滤器解码流，只支持无过滤器和 FlateDecode
+func decodePDFStream(dict, raw []byte) ([]byte, bool) {
+	if bytes.Contains(dict, []byte("/Subtype/Image")) || bytes.Contains(dict, []byte("/Subtype /Image")) {
+		return nil, false
+	}
+	filter := bytes.Index(dict, []byte("/Filter"))
+	if filter < 0 {
+		return raw, true
+	}
+	filters := pdfFilterPattern.FindAllSubmatch(dict[filter:], -1)
+	if len(filters) != 1 || string(filters[0][1]) != "FlateDecode" {
+		return nil, false
+	}
+
+	zr, err := zlib.NewReader(bytes.NewReader(raw))
+	if err != nil {
+		return nil, false
+	}
+	defer zr.Close()
+	decoded, err := io.ReadAll(zr)
+	if err != nil && len(decoded) == 0 {
+		return nil, false
+	}
+	return decoded, true
+}
+
+// pdfContentText 解析内容流中的文本操作符
+func pdfContentText(content []byte) string {
+	var sb strings.Builder
+	var operands []interface{}
+	lex := &pdfLexer{data: content}
+
+	for {
+		token, ok := lex.next()
+		if !ok {
+			break
+		}
+		op, isOperator := token.(pdfOperator)
+		if !isOperator {
+			operands = append(operands, token)
+			continue
+		}
+
+		switch op {
+		case "Tj":
+			writePDFString(&sb, lastOperand(operands))
+		case "'", "\"":
+			sb.WriteString("\n")
+			writePDFString(&sb, lastOperand(operands))
+		case "TJ":
+			if items, ok := lastOperand(operands).([]interface{}); ok {
+				for _, item := range items {
+					switch v := item.(type) {
+					case string:
+						sb.WriteString(v)
+					case float64:
+						// 较大的负字距表示词间空格
+						if v < -200 {
+							sb.WriteString(" ")
+						}
+					}
+				}
+			}
+		case "Td", "TD":
+			if len(operands) >= 2 {
+				if ty, ok := operands[len(operands)-1].(float64); ok && ty != 0 {
+					sb.WriteString("\n")
+				} else {
+					sb.WriteString(" ")
+				}
+			}
+		case "T*", "ET":
+			sb.WriteString("\n")
+		case "Tm":
+			sb.WriteString(" ")
+		}
+		operands = operands[:0]
+	}
+
+	lines := strings.Split(sb.String(), "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		if line = collapseSpaces(line); line != "" {
+			kept = append(kept, line)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
+
+// lastOperand 返回最后一个操作数
+func lastOperand(operands []interface{}) interface{} {
+	if len(operands) == 0 {
+		return nil
+	}
+	return operands[len(operands)-1]
+}
+
+// writePDFString 写入字符串操作数
+func writePDFString(sb *strings.Builder, operand interface{}) {
+	if s, ok := operand.(string); ok {
+		sb.WriteString(s)
+	}
+}
+
+// pdfOperator 内容流操作符
+type pdfOperator string
+
+// pdfLexer 内容流词法分析器，返回 string（已解码的字符串）、float64、[]interface{}、
+// pdfOperator，名称和字典等其他对象返回 nil
+type pdfLexer struct {
+	data []byte
+	pos  int
+}
+
+func (l *pdfLexer) next() (interface{}, bool) {
+	l.skipSpace()
+	if l.pos >= len(l.data) {
+		return nil, false
+	}
+
+	c := l.data[l.pos]
+	switch {
+	case c == '(':
+		return decodePDFText(l.literalString()), true
+	case c == '<' && l.pos+1 < len(l.data) && l.data[l.pos+1] == '<':
+		l.skipDict()
+		return nil, true
+	case c == '<':
+		return decodePDFText(l.hexString()), true
+	case c == '[':
+		l.pos++
+		var items []interface{}
+		for {
+			l.skipSpace()
+			if l.pos >= len(l.data) {
+				return items, true
+			}
+			if l.data[l.pos] == ']' {
+				l.pos++
+				return items, true
+			}
+			item, ok := l.next()
+			if !ok {
+				return items, true
+			}
+			items = append(items, item)
+		}
+	case c == '/':
+		l.pos++
+		l.readWhile(isPDFRegular)
+		return nil, true
+	case c == '+' || c == '-' || c == '.' || c >= '0' && c <= '9':
+		n, _ := strconv.ParseFloat(string(l.readWhile(func(c byte) bool {
+			return c == '+' || c == '-' || c == '.' || c >= '0' && c <= '9'
+		})), 64)
+		return n, true
+	case c == ']' || c == '>' || c == ')' || c == '{' || c == '}':
+		l.pos++
+		return nil, true
+	}
+
+	word := l.readWhile(isPDFRegular)
+	if len(word) == 0 {
+		l.pos++
+		return nil, true
+	}
+	if string(word) == "BI" {
+		l.skipInlineImage()
+		return nil, true
+	}
+	return pdfOperator(word), true
+}
+
+func (l *pdfLexer) skipSpace() {
+	for l.pos < len(l.data) {
+		switch c := l.data[l.pos]; {
+		case c == '%':
+			for l.pos < len(l.data) && l.data[l.pos] != '\n' && l.data[l.pos] != '\r' {
+				l.pos++
+			}
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == '\f' || c == 0:
+			l.pos++
+		default:
+			return
+		}
+	}
+}
+
+func (l *pdfLexer) readWhile(pred func(byte) bool) []byte {
+	start := l.pos
+	for l.pos < len(l.data) && pred(l.data[l.pos]) {
+		l.pos++
+	}
+	return l.data[start:l.pos]
+}
+
+// literalString 读取 (...) 字符串，处理嵌套括号和转义
+func (l *pdfLexer) literalString() []byte {
+	l.pos++
+	var out []byte
+	depth := 1
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		l.pos++
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return out
+			}
+		case '\\':
+			if l.pos >= len(l.data) {
+				return out
+			}
+			e := l.data[l.pos]
+			l.pos++
+			switch e {
+			case 'n':
+				out = append(out, '\n')
+			case 'r':
+				out = append(out, '\r')
+			case 't':
+				out = append(out, '\t')
+			case 'b':
+				out = append(out, '\b')
+			case 'f':
+				out = append(out, '\f')
+			case '\r':
+				if l.pos < len(l.data) && l.data[l.pos] == '\n' {
+					l.pos++
+				}
+			case '\n':
+			default:
+				if e >= '0' && e <= '7' {
+					v := int(e - '0')
+					for i := 0; i < 2 && l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '7'; i++ {
+						v = v*8 + int(l.data[l.pos]-'0')
+						l.pos++
+					}
+					out = append(out, byte(v))
+				} else {
+					out = append(out, e)
+				}
+			}
+			continue
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+// hexString 读取 <...> 十六进制字符串
+func (l *pdfLexer) hexString() []byte {
+	l.pos++
+	var digits []byte
+	for l.pos < len(l.data) && l.data[l.pos] != '>' {
+		if c := l.data[l.pos]; isHexDigit(c) {
+			digits = append(digits, c)
+		}
+		l.pos++
+	}
+	l.pos++
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	out := make([]byte, len(digits)/2)
+	for i := range out {
+		out[i] = hexValue(digits[2*i])<<4 | hexValue(digits[2*i+1])
+	}
+	return out
+}
+
+// skipDict 跳过 << ... >> 字典（含嵌套）
+func (l *pdfLexer) skipDict() {
+	depth := 0
+	for l.pos < len(l.data) {
+		switch {
+		case bytes.HasPrefix(l.data[l.pos:], []byte("<<")):
+			depth++
+			l.pos += 2
+		case bytes.HasPrefix(l.data[l.pos:], []byte(">>")):
+			depth--
+			l.pos += 2
+			if depth == 0 {
+				return
+			}
+		case l.data[l.pos] == '(':
+			l.literalString()
+		default:
+			l.pos++
+		}
+	}
+}
+
+// skipInlineImage 跳过 BI ... ID <数据> EI 内联图像
+func (l *pdfLexer) skipInlineImage() {
+	if end := bytes.Index(l.data[l.pos:], []byte("EI")); end >= 0 {
+		l.pos += end + 2
+		return
+	}
+	l.pos = len(l.data)
+}
+
+func isPDFRegular(c byte) bool {
+	switch c {
+	case ' ', '\t', '\r', '\n', '\f', 0, '(', ')', '<', '>', '[', ']', '{', '}', '/', '%':
+		return false
+	}
+	return true
+}
+
+func isHexDigit(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'
+}
+
+func hexValue(c byte) byte {
+	switch {
+	case c >= '0' && c <= '9':
+		return c - '0'
+	case c >= 'a' && c <= 'f':
+		return c - 'a' + 10
+	default:
+		return c - 'A' + 10
+	}
+}
+
+// decodePDFText 解码 PDF 文本字符串：带 BOM 的 UTF-16BE 或按 Latin-1 近似的 PDFDocEncoding
+func decodePDFText(b []byte) string {
+	if len(b) >= 2 && b[0] == 0xfe && b[1] == 0xff {
+		units := make([]uint16, 0, (len(b)-2)/2)
+		for i := 2; i+1 < len(b); i += 2 {
+			units = append(units, uint16(b[i])<<8|uint16(b[i+1]))
+		}
+		return string(utf16.Decode(units))
+	}
+	runes := make([]rune, len(b))
+	for i, c := range b {
+		runes[i] = rune(c)
+	}
+	return string(runes)
+}
+
+// pdfInfoString 读取文档信息字典中的字符串字段（如 Title、Author）
+func pdfInfoString(data []byte, key string) string {
+	idx := bytes.Index(data, []byte("/"+key))
+	if idx < 0 {
+		return ""
+	}
+	lex := &pdfLexer{data: data, pos: idx + len(key) + 1}
+	lex.skipSpace()
+	if lex.pos >= len(lex.data) || lex.data[lex.pos] != '(' && lex.data[lex.pos] != '<' {
+		return ""
+	}
+	value, _ := lex.next()
+	s, _ := value.(string)
+	return strings.TrimSpace(s)
+}
+
+// compile-time interface check
+var _ DocumentLoader = (*PDFLoader)(nil)
@@ -4,6 +4,10 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
+	"time"
+
+	"github.com/ahhsitt/helloagents-go/pkg/core/retry"
 )
 
 // RAGPipeline RAG 管道接口
@@ -22,6 +26,10 @@ type RAGResponse struct {
 	Sources []Source `json:"sources"`
 	// Context 检索到的上下文
 	Context *RAGContext `json:"context"`
+	// Citations 逐句引用映射（生成器实现 CitingAnswerGenerator 时填充）
+	Citations []CitedSentence `json:"citations,omitempty"`
+	// Cached 回答是否来自语义回答缓存（此时 Sources、Context 和 Citations 为生成该回答时的检索结果）
+	Cached bool `json:"cached,omitempty"`
 }
 
 // Source 来源引用
@@ -46,6 +54,32 @@ type DefaultRAGPipeline struct {
 	store     VectorStore
 	retriever Retriever
 	generator AnswerGenerator
+	index     IngestIndex
+
+	// 父子分块模式
+	parents       ChunkStore
+	parentChunker DocumentChunker
+
+	// 知识图谱索引
+	graph *GraphIndexer
+
+	// 多向量索引
+	multiVector *MultiVectorIndex
+
+	// 摄取检查点
+	checkpoints *checkpointer
+
+	// 近重复块去重
+	dedup *ChunkDeduplicator
+
+	// 语义回答缓存
+	answerCache *SemanticAnswerCache
+
+	// 嵌入批次、并发、重试和进度
+	embedBatchSize   int
+	embedConcurrency int
+	embedRetry       *retry.Policy
+	ingestProgress   IngestProgressFunc
 }
 
 // AnswerGenerator 回答生成器接口
@@ -85,6 +119,102 @@ func WithStore(store VectorStore) RAGPipelineOption {
 	}
 }
 
+// WithIngestIndex 设置增量摄取索引（默认使用内存索引）
+func WithIngestIndex(index IngestIndex) RAGPipelineOption {
+	return func(p *DefaultRAGPipeline) {
+		p.index = index
+	}
+}
+
+// WithEmbedBatchSize 设置单次嵌入调用的最大文本数（默认 0，即一次嵌入全部块）
+//
+// 嵌入服务通常限制单次请求的输入数量，大规模摄取时应设置为服务允许的上限（如 OpenAI 为 2048）。
+func WithEmbedBatchSize(size int) RAGPipelineOption {
+	return func(p *DefaultRAGPipeline) {
+		if size >= 0 {
+			p.embedBatchSize = size
+		}
+	}
+}
+
+// WithEmbedConcurrency 设置并发嵌入的批次数（默认 1，即串行）
+func WithEmbedConcurrency(n int) RAGPipelineOption {
+	return func(p *DefaultRAGPipeline) {
+		if n > 0 {
+			p.embedConcurrency = n
+		}
+	}
+}
+
+// WithEmbedRetryPolicy 设置嵌入批次的重试策略（默认使用全局默认策略 retry.Default）
+//
+// 限速（429）、超时和服务不可用的批次按策略指数退避后重试，只重试失败的批次。
+// 传入 MaxAttempts 为 1 的策略可关闭重试。
+func WithEmbedRetryPolicy(policy retry.Policy) RAGPipelineOption {
+	return func(p *DefaultRAGPipeline) {
+		p.embedRetry = &policy
+	}
+}
+
+// WithIngestProgress 设置摄取进度回调
+func WithIngestProgress(fn IngestProgressFunc) RAGPipelineOption {
+	return func(p *DefaultRAGPipeline) {
+		p.ingestProgress = fn
+	}
+}
+
+// WithParentChunks 启用父子分块（small-to-big）摄取
+//
+// 文档先由 parentChunker 切分为父块（为 nil 时整个文档作为父块），父块写入 parents；
+// 再由管道的分块器在每个父块内切分为子块，子块的 ParentID 指向父块，嵌入后写入向量存储。
+// 检索时用 NewParentDocumentRetriever 将命中的子块展开为父块。
+func WithParentChunks(parents ChunkStore, parentChunker DocumentChunker) RAGPipelineOption {
+	return func(p *DefaultRAGPipeline) {
+		p.parents = parents
+		p.parentChunker = parentChunker
+	}
+}
+
+// WithGraphIndexer 启用知识图谱索引（GraphRAG）
+//
+// 摄取时从写入向量存储的块中提取实体和关系写入图索引，文档更新或删除时同步清理。
+// 检索时用 NewGraphRetriever 结合图遍历和向量检索。
+func WithGraphIndexer(indexer *GraphIndexer) RAGPipelineOption {
+	return func(p *DefaultRAGPipeline) {
+		p.graph = indexer
+	}
+}
+
+// WithMultiVectorIndex 启用多向量索引
+//
+// 摄取时把写入向量存储的块同时切分嵌入到多向量索引，文档更新或删除时同步清理。
+// 检索时用 NewMultiVectorRetriever 按 MaxSim 分数检索。
+func WithMultiVectorIndex(index *MultiVectorIndex) RAGPipelineOption {
+	return func(p *DefaultRAGPipeline) {
+		p.multiVector = index
+	}
+}
+
+// WithDeduplicator 在摄取时检测近重复块，按去重器的策略丢弃、合并或标注
+//
+// 新块与已写入的块以及同批次中更早的块比较，统计记录在 IngestStats 的 DuplicateChunks
+// 和 DroppedDuplicates 中。被丢弃的块不写入向量存储，也不记入增量索引。
+func WithDeduplicator(dedup *ChunkDeduplicator) RAGPipelineOption {
+	return func(p *DefaultRAGPipeline) {
+		p.dedup = dedup
+	}
+}
+
+// WithAnswerCache 启用语义回答缓存，与已回答查询足够相似的查询直接返回缓存的回答
+//
+// 查询嵌入默认使用管道的嵌入器；没有可用的嵌入器时不启用缓存。
+// 缓存只在生成成功后写入，新鲜度检查见 SemanticAnswerCache。
+func WithAnswerCache(cache *SemanticAnswerCache) RAGPipelineOption {
+	return func(p *DefaultRAGPipeline) {
+		p.answerCache = cache
+	}
+}
+
 // WithRetriever 设置检索器
 func WithRetriever(retriever Retriever) RAGPipelineOption {
 	return func(p *DefaultRAGPipeline) {
@@ -101,7 +231,7 @@ func WithGenerator(generator AnswerGenerator) RAGPipelineOption {
 
 // NewRAGPipeline 创建 RAG 管道
 func NewRAGPipeline(opts ...RAGPipelineOption) *DefaultRAGPipeline {
-	p := &DefaultRAGPipeline{}
+	p := &DefaultRAGPipeline{embedConcurrency: 1}
 
 	for _, opt := range opts {
 		opt(p)
@@ -116,51 +246,318 @@ func NewRAGPipeline(opts ...RAGPipelineOption) *DefaultRAGPipeline {
 		p.store = NewInMemoryVectorStore()
 	}
 
+	if p.index == nil {
+		p.index = NewInMemoryIngestIndex()
+	}
+
+	// 回答缓存默认随管道存储的版本失效
+	if p.answerCache != nil && p.answerCache.source == nil {
+		if versioned, ok := p.store.(VersionedStore); ok {
+			p.answerCache.source = versioned
+		}
+	}
+
 	return p
 }
 
 // Ingest 摄取文档
+//
+// 摄取是增量的，详见 IngestWithStats。
 func (p *DefaultRAGPipeline) Ingest(ctx context.Context, docs []Document) error {
+	_, err := p.IngestWithStats(ctx, docs)
+	return err
+}
+
+// IngestWithStats 增量摄取文档并返回统计
+//
+// 按文档 ID 记录内容指纹：内容和元数据都未变化的文档直接跳过（不分块、不嵌入）；
+// 变化的文档重新分块后只嵌入和写入新增或变化的块，并删除不再存在的旧块。
+// 未变化的块保留原有的摄取时间和过期时间。没有 ID 的文档每次都完整摄取。
+// 增量检测依赖稳定的文档 ID，加载器生成的随机 ID 需替换为稳定 ID（如来源路径）。
+func (p *DefaultRAGPipeline) IngestWithStats(ctx context.Context, docs []Document) (IngestStats, error) {
+	report, err := p.IngestWithReport(ctx, docs)
+	return report.IngestStats, err
+}
+
+// IngestWithReport 增量摄取文档并返回逐文档的摄取报告
+//
+// 行为与 IngestWithStats 相同，报告额外列出成功、失败和跳过的文档 ID。
+// 启用 WithIngestCheckpoints 时，报告中的 ResumedChunks 为从上次中断处恢复的块数量。
+func (p *DefaultRAGPipeline) IngestWithReport(ctx context.Context, docs []Document) (IngestReport, error) {
+	var report IngestReport
+	stats := &report.IngestStats
 	if p.embedder == nil {
-		return fmt.Errorf("embedder is required for ingestion")
+		return report, fmt.Errorf("embedder is required for ingestion")
 	}
 
-	var allChunks []DocumentChunk
-
-	// 分块处理每个文档
+	var (
+		allChunks     []DocumentChunk
+		resumedChunks []DocumentChunk
+		allParents    []DocumentChunk
+		stale         []string
+		staleParents  []string
+		pending       []pendingDoc
+		seen          = make(map[string]string)
+		active        = make(map[string]*IngestCheckpoint)
+		expected      = make(map[string]int) // 文档 ID -> 本次需要写入的块数
+		written       = make(map[string]int) // 文档 ID -> 本次已写入的块数
+		merged        = make(map[string]DocumentChunk)
+	)
+
+	// 分块处理每个文档，块继承文档的摄取时间和过期时间
+	now := time.Now()
 	for _, doc := range docs {
-		chunks := p.chunker.Chunk(doc)
-		allChunks = append(allChunks, chunks...)
+		stats.Documents++
+		docHash := hashDocument(doc)
+		prev, tracked := IngestRecord{}, false
+		var resumed map[string]string
+		if doc.ID != "" {
+			if hash, dup := seen[doc.ID]; dup && hash == docHash {
+				stats.UnchangedDocuments++
+				report.Skipped = append(report.Skipped, doc.ID)
+				continue
+			}
+			seen[doc.ID] = docHash
+
+			prev, tracked = p.index.Get(doc.ID)
+			if tracked && prev.Hash == docHash {
+				stats.UnchangedDocuments++
+				report.Skipped = append(report.Skipped, doc.ID)
+				continue
+			}
+			if p.checkpoints != nil {
+				cp, err := p.checkpoints.get(ctx, doc.ID)
+				if err != nil {
+					return report, fmt.Errorf("failed to load ingest checkpoint: %w", err)
+				}
+				if cp != nil && cp.Record.Hash == docHash {
+					if cp.Status == IngestStatusDone {
+						// 上次已完成但增量索引丢失，从检查点恢复索引
+						if err := p.index.Put(doc.ID, cp.Record); err != nil {
+							return report, fmt.Errorf("failed to update ingest index: %w", err)
+						}
+						stats.UnchangedDocuments++
+						report.Skipped = append(report.Skipped, doc.ID)
+						continue
+					}
+					resumed = cp.Completed
+				}
+			}
+		}
+
+		stampIngestion(&doc.Metadata, now)
+		record := IngestRecord{Hash: docHash, Chunks: make(map[string]string)}
+		chunks := p.chunker.Chunk
+		if p.parents != nil {
+			parents, children := splitParentChild(doc, p.parentChunker, p.chunker)
+			allParents = append(allParents, parents...)
+			for _, parent := range parents {
+				record.Parents = append(record.Parents, parent.ID)
+			}
+			staleParents = append(staleParents, missingIDs(prev.Parents, record.Parents)...)
+			chunks = func(Document) []DocumentChunk { return children }
+		}
+		toEmbed := 0
+		for _, chunk := range chunks(doc) {
+			chunkHash := hashChunk(chunk)
+			record.Chunks[chunk.ID] = chunkHash
+			if tracked && prev.Chunks[chunk.ID] == chunkHash {
+				stats.UnchangedChunks++
+				continue
+			}
+			if resumed[chunk.ID] == chunkHash {
+				report.ResumedChunks++
+				resumedChunks = append(resumedChunks, chunk)
+				continue
+			}
+			if p.dedup != nil {
+				deduped, keep := p.dedupChunk(chunk, stats, merged)
+				if !keep {
+					delete(record.Chunks, chunk.ID)
+					continue
+				}
+				chunk = deduped
+			}
+			allChunks = append(allChunks, chunk)
+			toEmbed++
+		}
+		for id := range prev.Chunks {
+			if _, ok := record.Chunks[id]; !ok {
+				stale = append(stale, id)
+			}
+		}
+		if doc.ID != "" {
+			pending = append(pending, pendingDoc{id: doc.ID, record: record})
+			expected[doc.ID] = toEmbed
+			if p.checkpoints != nil {
+				active[doc.ID] = &IngestCheckpoint{
+					DocumentID:  doc.ID,
+					Status:      IngestStatusInProgress,
+					Record:      record,
+					TotalChunks: toEmbed,
+					Completed:   resumed,
+				}
+			}
+		}
 	}
 
-	if len(allChunks) == 0 {
-		return nil
+	for _, cp := range active {
+		if err := p.checkpoints.put(ctx, cp); err != nil {
+			return report, fmt.Errorf("failed to save ingest checkpoint: %w", err)
+		}
 	}
 
-	// 批量生成嵌入
-	contents := make([]string, len(allChunks))
-	for i, chunk := range allChunks {
-		contents[i] = chunk.Content
+	if len(allParents) > 0 {
+		if err := p.parents.Add(ctx, allParents); err != nil {
+			return report, fmt.Errorf("failed to store parent chunks: %w", err)
+		}
 	}
 
-	embeddings, err := p.embedder.Embed(ctx, contents)
-	if err != nil {
-		return fmt.Errorf("failed to generate embeddings: %w", err)
+	// 近重复块合并到的首个块：本次写入的块在嵌入前更新元数据，之前写入的块在嵌入后重新写入
+	remerged := applyMerged(allChunks, merged)
+
+	if len(allChunks) > 0 {
+		// 分批并发生成嵌入，每批嵌入后立即写入向量存储（按 ID 覆盖旧版本）并更新检查点
+		var mu sync.Mutex
+		stored := make(map[string]struct{}, len(allChunks))
+		err := p.embedChunks(ctx, allChunks, func(ctx context.Context, batch []DocumentChunk) error {
+			if err := p.store.Add(ctx, batch); err != nil {
+				return fmt.Errorf("failed to store chunks: %w", err)
+			}
+			if p.checkpoints != nil {
+				if err := p.checkpoints.completeBatch(ctx, active, batch); err != nil {
+					return fmt.Errorf("failed to save ingest checkpoint: %w", err)
+				}
+			}
+			if p.dedup != nil {
+				p.dedup.update(batch)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			report.Batches++
+			for _, chunk := range batch {
+				written[chunk.DocumentID]++
+				stored[chunk.ID] = struct{}{}
+			}
+			return nil
+		})
+		if err != nil {
+			if p.dedup != nil {
+				// 未写入的块不能作为之后去重的依据
+				var unstored []string
+				for _, chunk := range allChunks {
+					if _, ok := stored[chunk.ID]; !ok {
+						unstored = append(unstored, chunk.ID)
+					}
+				}
+				p.dedup.RemoveChunks(unstored)
+			}
+			p.failIngest(ctx, &report, pending, expected, written, active, err)
+			return report, err
+		}
+		stats.EmbeddedChunks = len(allChunks)
+	}
+	if len(remerged) > 0 {
+		if err := p.store.Add(ctx, remerged); err != nil {
+			return report, fmt.Errorf("failed to store chunks: %w", err)
+		}
 	}
 
-	// 将嵌入向量附加到块
-	for i := range allChunks {
-		if i < len(embeddings) {
-			allChunks[i].Vector = embeddings[i]
+	// 恢复的块已在上次写入向量存储，但仍需写入派生索引
+	indexed := append(allChunks, resumedChunks...)
+	if len(indexed) > 0 {
+		if p.graph != nil {
+			// 变化的块先移除旧的实体关联再重新提取
+			ids := make([]string, len(indexed))
+			for i, chunk := range indexed {
+				ids[i] = chunk.ID
+			}
+			if err := p.graph.RemoveChunks(ctx, ids); err != nil {
+				return report, fmt.Errorf("failed to update graph index: %w", err)
+			}
+			if err := p.graph.Index(ctx, indexed); err != nil {
+				return report, fmt.Errorf("failed to update graph index: %w", err)
+			}
+		}
+		if p.multiVector != nil {
+			if err := p.multiVector.Index(ctx, indexed); err != nil {
+				return report, fmt.Errorf("failed to update multi-vector index: %w", err)
+			}
 		}
 	}
 
-	// 存储到向量数据库
-	if err := p.store.Add(ctx, allChunks); err != nil {
-		return fmt.Errorf("failed to store chunks: %w", err)
+	if len(stale) > 0 {
+		if err := p.store.Delete(ctx, stale); err != nil {
+			return report, fmt.Errorf("failed to delete stale chunks: %w", err)
+		}
+		stats.DeletedChunks = len(stale)
+		if p.graph != nil {
+			if err := p.graph.RemoveChunks(ctx, stale); err != nil {
+				return report, fmt.Errorf("failed to update graph index: %w", err)
+			}
+		}
+		if p.multiVector != nil {
+			if err := p.multiVector.RemoveChunks(ctx, stale); err != nil {
+				return report, fmt.Errorf("failed to update multi-vector index: %w", err)
+			}
+		}
+		if p.dedup != nil {
+			p.dedup.RemoveChunks(stale)
+		}
+	}
+	if len(staleParents) > 0 {
+		if err := p.parents.Delete(ctx, staleParents); err != nil {
+			return report, fmt.Errorf("failed to delete stale parent chunks: %w", err)
+		}
 	}
 
-	return nil
+	// 写入成功后才更新索引，失败时下次摄取会重试
+	for _, d := range pending {
+		if err := p.index.Put(d.id, d.record); err != nil {
+			return report, fmt.Errorf("failed to update ingest index: %w", err)
+		}
+		if cp, ok := active[d.id]; ok {
+			cp.Status, cp.Completed, cp.Error = IngestStatusDone, nil, ""
+			if err := p.checkpoints.put(ctx, cp); err != nil {
+				return report, fmt.Errorf("failed to save ingest checkpoint: %w", err)
+			}
+		}
+		report.Succeeded = append(report.Succeeded, d.id)
+	}
+	return report, nil
+}
+
+// pendingDoc 摄取完成后需要写入增量索引的文档
+type pendingDoc struct {
+	id     string
+	record IngestRecord
+}
+
+// failIngest 在报告中记录尚未写完全部块的文档，并将其检查点标记为失败
+//
+// 已写入的块保留在检查点中，下次摄取时从中断处继续。
+func (p *DefaultRAGPipeline) failIngest(ctx context.Context, report *IngestReport, pending []pendingDoc, expected, written map[string]int, active map[string]*IngestCheckpoint, cause error) {
+	report.Failed = make(map[string]string)
+	for _, d := range pending {
+		if written[d.id] >= expected[d.id] {
+			continue
+		}
+		report.Failed[d.id] = cause.Error()
+		if cp, ok := active[d.id]; ok {
+			cp.Status, cp.Error = IngestStatusFailed, cause.Error()
+			_ = p.checkpoints.put(ctx, cp)
+		}
+	}
+}
+
+// stampIngestion 设置摄取时间，并在设置了 TTL 且未指定过期时间时计算过期时间
+func stampIngestion(metadata *DocumentMetadata, now time.Time) {
+	if metadata.IngestedAt.IsZero() {
+		metadata.IngestedAt = now
+	}
+	if metadata.TTL > 0 && metadata.ExpiresAt.IsZero() {
+		metadata.ExpiresAt = metadata.IngestedAt.Add(metadata.TTL)
+	}
 }
 
 // IngestFromLoader 从加载器摄取文档
@@ -175,6 +572,146 @@ func (p *DefaultRAGPipeline) IngestFromLoader(ctx context.Context, loader Docume
 
 // Query 查询并生成回答
 func (p *DefaultRAGPipeline) Query(ctx context.Context, query string, topK int) (*RAGResponse, error) {
+	response, err := p.retrieveResponse(ctx, query, topK)
+	if err != nil {
+		return nil, err
+	}
+
+	// 查找语义缓存的回答
+	cached, vector := p.cachedAnswer(ctx, query, response)
+	if cached != nil {
+		return cached, nil
+	}
+
+	if citing, ok := p.generator.(CitingAnswerGenerator); ok {
+		// 生成带引用的回答
+		cited, err := citing.GenerateCited(ctx, query, response.Context)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate answer: %w", err)
+		}
+		response.Answer = cited.Answer
+		response.Citations = cited.Sentences
+	} else if p.generator != nil {
+		// 生成回答
+		answer, err := p.generator.Generate(ctx, query, response.Context)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate answer: %w", err)
+		}
+		response.Answer = answer
+	}
+
+	if vector != nil {
+		p.answerCache.Store(query, vector, response)
+	}
+	return response, nil
+}
+
+// RAGStreamResponse 流式 RAG 响应
+//
+// Sources 和 Context 在检索完成后立即可用，回答片段通过 Answer 逐步输出。
+// 调用方应读完 Answer 后再读取 Err 获取生成错误。
+type RAGStreamResponse struct {
+	// Sources 来源引用
+	Sources []Source `json:"sources"`
+	// Context 检索到的上下文
+	Context *RAGContext `json:"context"`
+	// Answer 回答片段，生成结束后关闭
+	Answer <-chan string `json:"-"`
+	// Err 生成错误（最多一个），生成结束后关闭
+	Err <-chan error `json:"-"`
+	// Cached 回答是否来自语义回答缓存（此时一次性输出完整回答）
+	Cached bool `json:"cached,omitempty"`
+}
+
+// QueryStream 查询并流式生成回答
+//
+// 检索失败时直接返回错误；生成过程中的错误通过 Err 返回。生成器实现
+// StreamingAnswerGenerator 时逐片段输出，否则生成完成后一次性输出；未设置生成器时 Answer 立即关闭。
+// 启用语义回答缓存时，流式生成完整结束后才写入缓存。
+func (p *DefaultRAGPipeline) QueryStream(ctx context.Context, query string, topK int) (*RAGStreamResponse, error) {
+	response, err := p.retrieveResponse(ctx, query, topK)
+	if err != nil {
+		return nil, err
+	}
+
+	// 查找语义缓存的回答
+	cached, vector := p.cachedAnswer(ctx, query, response)
+	if cached != nil {
+		response = cached
+	}
+
+	answerChan := make(chan string, 10)
+	errChan := make(chan error, 1)
+	stream := &RAGStreamResponse{
+		Sources: response.Sources,
+		Context: response.Context,
+		Answer:  answerChan,
+		Err:     errChan,
+		Cached:  response.Cached,
+	}
+
+	// storeAnswer 生成成功后写入语义缓存
+	storeAnswer := func(answer string) {
+		if vector != nil {
+			generated := *response
+			generated.Answer = answer
+			p.answerCache.Store(query, vector, &generated)
+		}
+	}
+
+	go func() {
+		defer close(answerChan)
+		defer close(errChan)
+
+		if p.generator == nil {
+			return
+		}
+
+		streaming, ok := p.generator.(StreamingAnswerGenerator)
+		if cached != nil || !ok {
+			answer := response.Answer
+			if cached == nil {
+				var err error
+				answer, err = p.generator.Generate(ctx, query, response.Context)
+				if err != nil {
+					errChan <- fmt.Errorf("failed to generate answer: %w", err)
+					return
+				}
+				storeAnswer(answer)
+			}
+			if answer != "" {
+				select {
+				case answerChan <- answer:
+				case <-ctx.Done():
+					errChan <- ctx.Err()
+				}
+			}
+			return
+		}
+
+		var answer strings.Builder
+		chunks, errs := streaming.GenerateStream(ctx, query, response.Context)
+		for chunk := range chunks {
+			select {
+			case answerChan <- chunk:
+				answer.WriteString(chunk)
+			case <-ctx.Done():
+				errChan <- ctx.Err()
+				return
+			}
+		}
+		if err := <-errs; err != nil {
+			errChan <- fmt.Errorf("failed to generate answer: %w", err)
+			return
+		}
+		storeAnswer(answer.String())
+	}()
+
+	return stream, nil
+}
+
+// retrieveResponse 检索文档并构建不含回答的响应
+func (p *DefaultRAGPipeline) retrieveResponse(ctx context.Context, query string, topK int) (*RAGResponse, error) {
 	if p.retriever == nil {
 		return nil, fmt.Errorf("retriever is required for query")
 	}
@@ -204,21 +741,10 @@ func (p *DefaultRAGPipeline) Query(ctx context.Context, query string, topK int)
 		}
 	}
 
-	response := &RAGResponse{
+	return &RAGResponse{
 		Sources: sources,
 		Context: ragContext,
-	}
-
-	// 生成回答
-	if p.generator != nil {
-		answer, err := p.generator.Generate(ctx, query, ragContext)
-		if err != nil {
-			return nil, fmt.Errorf("failed to generate answer: %w", err)
-		}
-		response.Answer = answer
-	}
-
-	return response, nil
+	}, nil
 }
 
 // QueryWithoutGeneration 仅检索不生成回答
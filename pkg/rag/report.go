@@ -0,0 +1,129 @@
+package rag
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// StrategyStage 检索策略所处阶段
+type StrategyStage string
+
+const (
+	// StageTransform 查询变换阶段（MQE、HyDE 等）
+	StageTransform StrategyStage = "transform"
+	// StageRetrieve 检索阶段（每个变换后的查询一次）
+	StageRetrieve StrategyStage = "retrieve"
+	// StagePostProcess 后处理阶段（重排序等）
+	StagePostProcess StrategyStage = "postprocess"
+)
+
+// StrategyStatus 策略执行状态
+type StrategyStatus string
+
+const (
+	// StrategyOK 执行成功
+	StrategyOK StrategyStatus = "ok"
+	// StrategyFailed 执行出错
+	StrategyFailed StrategyStatus = "failed"
+	// StrategyTimeout 超过单策略超时
+	StrategyTimeout StrategyStatus = "timeout"
+	// StrategyCancelled 因整体超时或提前停止被取消
+	StrategyCancelled StrategyStatus = "cancelled"
+)
+
+// StrategyResult 单个策略的执行结果
+type StrategyResult struct {
+	// Stage 所处阶段
+	Stage StrategyStage
+	// Name 策略名称（变换器/后处理器类型名，检索阶段为 "retrieve"）
+	Name string
+	// Query 策略处理的查询
+	Query string
+	// Status 执行状态
+	Status StrategyStatus
+	// Err 失败原因，成功时为 nil
+	Err error
+	// Duration 执行耗时
+	Duration time.Duration
+}
+
+// RetrievalReport 策略检索诊断报告
+type RetrievalReport struct {
+	// OriginalQuery 调用方传入的原始查询，用于展示
+	OriginalQuery string
+	// Query 实际用于检索的查询（配置规范化器时为规范化结果）
+	Query string
+	// Strategies 各策略的执行结果（按完成顺序）
+	Strategies []StrategyResult
+	// Partial 是否有策略未成功完成，结果仅由已完成的策略融合
+	Partial bool
+	// EarlyStopped 是否因 topK 稳定而提前取消剩余检索
+	EarlyStopped bool
+	// Duration 总耗时
+	Duration time.Duration
+}
+
+// Failed 返回所有未成功的策略
+func (r *RetrievalReport) Failed() []StrategyResult {
+	var failed []StrategyResult
+	for _, s := range r.Strategies {
+		if s.Status != StrategyOK {
+			failed = append(failed, s)
+		}
+	}
+	return failed
+}
+
+// Err 合并所有失败策略的错误，全部成功时返回 nil
+func (r *RetrievalReport) Err() error {
+	var errs []error
+	for _, s := range r.Failed() {
+		if s.Err != nil {
+			errs = append(errs, fmt.Errorf("%s %s: %w", s.Stage, s.Name, s.Err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// record 记录策略执行结果
+func (r *RetrievalReport) record(stage StrategyStage, name, query string, start time.Time, err error) {
+	status := strategyStatus(err)
+	if status != StrategyOK {
+		r.Partial = true
+	}
+	r.Strategies = append(r.Strategies, StrategyResult{
+		Stage:    stage,
+		Name:     name,
+		Query:    query,
+		Status:   status,
+		Err:      err,
+		Duration: time.Since(start),
+	})
+}
+
+// strategyStatus 根据错误判断策略状态
+func strategyStatus(err error) StrategyStatus {
+	switch {
+	case err == nil:
+		return StrategyOK
+	case errors.Is(err, context.DeadlineExceeded):
+		return StrategyTimeout
+	case errors.Is(err, context.Canceled):
+		return StrategyCancelled
+	default:
+		return StrategyFailed
+	}
+}
+
+// strategyName 返回策略的类型名（去掉指针和包前缀）
+func strategyName(strategy interface{}) string {
+	name := fmt.Sprintf("%T", strategy)
+	name = strings.TrimPrefix(name, "*")
+	if idx := strings.LastIndex(name, "."); idx >= 0 {
+		name = name[idx+1:]
+	}
+	return name
+}
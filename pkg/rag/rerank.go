@@ -0,0 +1,438 @@
+package rag
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RerankMode LLM 重排序方式
+type RerankMode string
+
+const (
+	// RerankPointwise 逐条打分：每个片段单独请求一次 LLM 给出 0-10 的相关性分数（默认）
+	RerankPointwise RerankMode = "pointwise"
+	// RerankListwise 列表排序：每批片段请求一次 LLM，由其输出按相关性排列的编号
+	RerankListwise RerankMode = "listwise"
+)
+
+// rerankConfig 重排序器的通用配置
+type rerankConfig struct {
+	batchSize int
+	topN      int
+
+	// LLM 重排序
+	mode   RerankMode
+	prompt string
+
+	// HTTP 重排序
+	endpoint   string
+	apiKey     string
+	model      string
+	httpClient *http.Client
+}
+
+// RerankerOption 重排序器选项
+type RerankerOption func(*rerankConfig)
+
+// WithRerankBatchSize 设置批大小
+//
+// LLMReranker 逐条模式下为并发请求数，列表模式下为每次请求的片段数（默认 10）；
+// HTTPReranker 为每次请求的文档数（默认 100）。
+func WithRerankBatchSize(n int) RerankerOption {
+	return func(c *rerankConfig) {
+		if n > 0 {
+			c.batchSize = n
+		}
+	}
+}
+
+// WithRerankTopN 重排序后只保留分数最高的 n 个结果（默认全部保留）
+func WithRerankTopN(n int) RerankerOption {
+	return func(c *rerankConfig) {
+		c.topN = n
+	}
+}
+
+// WithRerankMode 设置 LLM 重排序方式
+func WithRerankMode(mode RerankMode) RerankerOption {
+	return func(c *rerankConfig) {
+		c.mode = mode
+	}
+}
+
+// WithRerankPrompt 设置 LLM 重排序提示模板
+//
+// 逐条模式的模板包含两个 %s 占位符（查询、片段），列表模式的模板包含两个 %s 占位符（查询、编号片段列表）。
+func WithRerankPrompt(prompt string) RerankerOption {
+	return func(c *rerankConfig) {
+		c.prompt = prompt
+	}
+}
+
+// WithRerankEndpoint 设置 HTTP 重排序服务地址
+func WithRerankEndpoint(endpoint string) RerankerOption {
+	return func(c *rerankConfig) {
+		c.endpoint = endpoint
+	}
+}
+
+// WithRerankAPIKey 设置 HTTP 重排序服务的 API Key（以 Bearer Token 发送）
+func WithRerankAPIKey(apiKey string) RerankerOption {
+	return func(c *rerankConfig) {
+		c.apiKey = apiKey
+	}
+}
+
+// WithRerankModel 设置 HTTP 重排序模型
+func WithRerankModel(model string) RerankerOption {
+	return func(c *rerankConfig) {
+		c.model = model
+	}
+}
+
+// WithRerankHTTPClient 设置 HTTP 客户端
+func WithRerankHTTPClient(client *http.Client) RerankerOption {
+	return func(c *rerankConfig) {
+		c.httpClient = client
+	}
+}
+
+// DefaultPointwiseRerankPrompt 默认逐条打分提示模板
+const DefaultPointwiseRerankPrompt = `请评估文档片段与查询的相关性，给出 0 到 10 之间的整数分数（10 表示完全回答了查询，0 表示无关）。
+
+查询：%s
+
+文档片段：
+%s
+
+请只输出分数：`
+
+// DefaultListwiseRerankPrompt 默认列表排序提示模板
+const DefaultListwiseRerankPrompt = `以下是若干带编号的文档片段。请按与查询的相关性从高到低排列它们的编号。
+
+查询：%s
+
+文档片段：
+%s
+
+请只输出编号，用逗号分隔（如 3,1,2）：`
+
+// LLMReranker 使用 LLM 评估相关性的重排序器
+//
+// 重排序后 RetrievalResult.Score 为 [0, 1] 的相关性分数：逐条模式为 LLM 分数除以 10，
+// 列表模式按片段在所在批次中的名次换算。
+type LLMReranker struct {
+	llm    LLMProvider
+	config rerankConfig
+}
+
+// NewLLMReranker 创建 LLM 重排序器
+func NewLLMReranker(llm LLMProvider, opts ...RerankerOption) *LLMReranker {
+	r := &LLMReranker{
+		llm: llm,
+		config: rerankConfig{
+			batchSize: 10,
+			mode:      RerankPointwise,
+		},
+	}
+	for _, opt := range opts {
+		opt(&r.config)
+	}
+	if r.config.prompt == "" {
+		r.config.prompt = DefaultPointwiseRerankPrompt
+		if r.config.mode == RerankListwise {
+			r.config.prompt = DefaultListwiseRerankPrompt
+		}
+	}
+	return r
+}
+
+// Rerank 重排序（实现 Reranker 接口）
+func (r *LLMReranker) Rerank(ctx context.Context, query string, results []RetrievalResult) ([]RetrievalResult, error) {
+	if len(results) == 0 {
+		return results, nil
+	}
+
+	var (
+		scores []float32
+		err    error
+	)
+	if r.config.mode == RerankListwise {
+		scores, err = r.listwiseScores(ctx, query, results)
+	} else {
+		scores, err = r.pointwiseScores(ctx, query, results)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("llm reranker: %w", err)
+	}
+	return applyRerankScores(results, scores, r.config.topN), nil
+}
+
+// pointwiseScores 逐条请求打分，最多 batchSize 个请求并发
+func (r *LLMReranker) pointwiseScores(ctx context.Context, query string, results []RetrievalResult) ([]float32, error) {
+	scores := make([]float32, len(results))
+	errs := make([]error, len(results))
+	sem := make(chan struct{}, r.config.batchSize)
+
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			resp, err := r.llm.Generate(ctx, fmt.Sprintf(r.config.prompt, query, results[i].Chunk.Content))
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			score, ok := parseRerankScore(resp)
+			if !ok {
+				errs[i] = fmt.Errorf("no score in response %q", resp)
+				return
+			}
+			scores[i] = score
+		}(i)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return scores, nil
+}
+
+// listwiseScores 分批请求排序，分数按批内名次线性递减
+func (r *LLMReranker) listwiseScores(ctx context.Context, query string, results []RetrievalResult) ([]float32, error) {
+	scores := make([]float32, len(results))
+	for start := 0; start < len(results); start += r.config.batchSize {
+		end := min(start+r.config.batchSize, len(results))
+		batch := results[start:end]
+
+		var listing strings.Builder
+		for i, res := range batch {
+			fmt.Fprintf(&listing, "[%d] %s\n", i+1, res.Chunk.Content)
+		}
+		resp, err := r.llm.Generate(ctx, fmt.Sprintf(r.config.prompt, query, listing.String()))
+		if err != nil {
+			return nil, err
+		}
+
+		order := parseRerankOrder(resp, len(batch))
+		for rank, idx := range order {
+			scores[start+idx] = 1 - float32(rank)/float32(len(batch))
+		}
+	}
+	return scores, nil
+}
+
+// rerankNumber 匹配响应中的数字
+var rerankNumber = regexp.MustCompile(`\d+(?:\.\d+)?`)
+
+// parseRerankScore 解析逐条模式的分数（取第一个数字，按 10 分制归一化到 [0, 1]）
+func parseRerankScore(resp string) (float32, bool) {
+	match := rerankNumber.FindString(resp)
+	if match == "" {
+		return 0, false
+	}
+	score, err := strconv.ParseFloat(match, 32)
+	if err != nil {
+		return 0, false
+	}
+	return float32(min(max(score/10, 0), 1)), true
+}
+
+// parseRerankOrder 解析列表模式的编号顺序（从 1 开始），忽略重复和越界编号，
+// 未出现的片段按原顺序排在最后；返回从 0 开始的下标
+func parseRerankOrder(resp string, n int) []int {
+	seen := make([]bool, n)
+	order := make([]int, 0, n)
+	for _, match := range rerankNumber.FindAllString(resp, -1) {
+		idx, err := strconv.Atoi(match)
+		if err != nil || idx < 1 || idx > n || seen[idx-1] {
+			continue
+		}
+		seen[idx-1] = true
+		order = append(order, idx-1)
+	}
+	for i := range seen {
+		if !seen[i] {
+			order = append(order, i)
+		}
+	}
+	return order
+}
+
+// applyRerankScores 写入重排序分数，按分数降序（分数相同时保持原顺序）并截断到 topN
+func applyRerankScores(results []RetrievalResult, scores []float32, topN int) []RetrievalResult {
+	reranked := make([]RetrievalResult, len(results))
+	for i, res := range results {
+		res.Score = scores[i]
+		reranked[i] = res
+	}
+	sort.SliceStable(reranked, func(i, j int) bool {
+		return reranked[i].Score > reranked[j].Score
+	})
+	if topN > 0 && topN < len(reranked) {
+		reranked = reranked[:topN]
+	}
+	return reranked
+}
+
+// 默认 Cohere Rerank 配置
+const (
+	defaultCohereRerankEndpoint = "https://api.cohere.com/v2/rerank"
+	defaultCohereRerankModel    = "rerank-v3.5"
+)
+
+// HTTPReranker 调用交叉编码器重排序服务的重排序器
+//
+// 请求格式为 {"model", "query", "documents"}，兼容 Cohere、Jina、Voyage 以及
+// 自建的 bge-reranker 等服务；响应中的 results（或 data）数组需包含 index 和
+// relevance_score（或 score）。重排序后 RetrievalResult.Score 为服务返回的相关性分数。
+type HTTPReranker struct {
+	config rerankConfig
+}
+
+// NewHTTPReranker 创建 HTTP 重排序器，需通过 WithRerankEndpoint 指定服务地址
+func NewHTTPReranker(opts ...RerankerOption) *HTTPReranker {
+	r := &HTTPReranker{
+		config: rerankConfig{batchSize: 100},
+	}
+	for _, opt := range opts {
+		opt(&r.config)
+	}
+	if r.config.httpClient == nil {
+		r.config.httpClient = &http.Client{Timeout: 30 * time.Second}
+	}
+	return r
+}
+
+// NewCohereReranker 创建 Cohere Rerank 重排序器（默认模型 rerank-v3.5）
+func NewCohereReranker(apiKey string, opts ...RerankerOption) *HTTPReranker {
+	base := []RerankerOption{
+		WithRerankEndpoint(defaultCohereRerankEndpoint),
+		WithRerankModel(defaultCohereRerankModel),
+		WithRerankAPIKey(apiKey),
+	}
+	return NewHTTPReranker(append(base, opts...)...)
+}
+
+// httpRerankRequest 重排序请求
+type httpRerankRequest struct {
+	Model     string   `json:"model,omitempty"`
+	Query     string   `json:"query"`
+	Documents []string `json:"documents"`
+}
+
+// httpRerankItem 重排序响应中的单个结果
+type httpRerankItem struct {
+	Index          int      `json:"index"`
+	RelevanceScore *float32 `json:"relevance_score"`
+	Score          *float32 `json:"score"`
+}
+
+// httpRerankResponse 重排序响应
+type httpRerankResponse struct {
+	Results []httpRerankItem `json:"results"`
+	Data    []httpRerankItem `json:"data"`
+}
+
+// Rerank 重排序（实现 Reranker 接口）
+//
+// 文档按批大小分批请求，服务未返回的文档分数为 0。
+func (r *HTTPReranker) Rerank(ctx context.Context, query string, results []RetrievalResult) ([]RetrievalResult, error) {
+	if len(results) == 0 {
+		return results, nil
+	}
+	if r.config.endpoint == "" {
+		return nil, fmt.Errorf("http reranker: endpoint not set")
+	}
+
+	scores := make([]float32, len(results))
+	for start := 0; start < len(results); start += r.config.batchSize {
+		end := min(start+r.config.batchSize, len(results))
+		docs := make([]string, end-start)
+		for i, res := range results[start:end] {
+			docs[i] = res.Chunk.Content
+		}
+
+		items, err := r.call(ctx, query, docs)
+		if err != nil {
+			return nil, fmt.Errorf("http reranker: %w", err)
+		}
+		for _, item := range items {
+			if item.Index < 0 || item.Index >= len(docs) {
+				continue
+			}
+			switch {
+			case item.RelevanceScore != nil:
+				scores[start+item.Index] = *item.RelevanceScore
+			case item.Score != nil:
+				scores[start+item.Index] = *item.Score
+			}
+		}
+	}
+	return applyRerankScores(results, scores, r.config.topN), nil
+}
+
+// call 发送单批重排序请求
+func (r *HTTPReranker) call(ctx context.Context, query string, docs []string) ([]httpRerankItem, error) {
+	body, err := json.Marshal(httpRerankRequest{
+		Model:     r.config.model,
+		Query:     query,
+		Documents: docs,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.config.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if r.config.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+r.config.apiKey)
+	}
+
+	resp, err := r.config.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	var parsed httpRerankResponse
+	if err := json.Unmarshal(respBody, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if parsed.Results != nil {
+		return parsed.Results, nil
+	}
+	return parsed.Data, nil
+}
+
+// compile-time interface check
+var _ Reranker = (*LLMReranker)(nil)
+var _ Reranker = (*HTTPReranker)(nil)
@@ -2,7 +2,7 @@ package rag
 
 import (
 	"context"
-	"sync"
+	"time"
 )
 
 // Retriever 检索器接口
@@ -11,6 +11,14 @@ type Retriever interface {
 	Retrieve(ctx context.Context, query string, topK int) ([]RetrievalResult, error)
 }
 
+// RetrieverFunc 函数适配器，使普通函数（如 DefaultRAGPipeline.QueryWithoutGeneration）实现 Retriever 接口
+type RetrieverFunc func(ctx context.Context, query string, topK int) ([]RetrievalResult, error)
+
+// Retrieve 调用函数本身
+func (f RetrieverFunc) Retrieve(ctx context.Context, query string, topK int) ([]RetrievalResult, error) {
+	return f(ctx, query, topK)
+}
+
 // AdvancedRetriever 高级检索器接口（支持策略选项）
 type AdvancedRetriever interface {
 	Retriever
@@ -52,25 +60,55 @@ func NewVectorRetriever(store VectorStore, embedder Embedder, opts ...VectorRetr
 
 // Retrieve 检索与查询相关的文档块（实现 Retriever 接口）
 func (r *VectorRetriever) Retrieve(ctx context.Context, query string, topK int) ([]RetrievalResult, error) {
-	return r.simpleRetrieve(ctx, query, topK)
+	return r.simpleRetrieve(ctx, query, topK, nil)
 }
 
 // RetrieveWithOptions 使用策略选项检索（实现 AdvancedRetriever 接口）
 func (r *VectorRetriever) RetrieveWithOptions(ctx context.Context, query string, topK int, opts ...RetrieveOption) ([]RetrievalResult, error) {
+	results, _, err := r.RetrieveWithReport(ctx, query, topK, opts...)
+	return results, err
+}
+
+// RetrieveWithReport 使用策略选项检索，并返回各策略的执行报告
+//
+// 单个策略失败或超时不会导致整体失败：变换失败时保留原查询，检索失败时只融合
+// 已完成的结果，后处理失败时保留处理前的结果。仅当所有检索都失败时返回错误。
+func (r *VectorRetriever) RetrieveWithReport(ctx context.Context, query string, topK int, opts ...RetrieveOption) ([]RetrievalResult, *RetrievalReport, error) {
 	// 应用选项
 	options := applyOptions(opts)
+	report := &RetrievalReport{OriginalQuery: query}
+	start := time.Now()
+
+	if options.Normalizer != nil {
+		if normalized := options.Normalizer.NormalizeText(query); normalized != "" {
+			query = normalized
+		}
+	}
+	report.Query = query
+
+	if err := options.Filter.Validate(); err != nil {
+		return nil, report, err
+	}
 
-	// 如果没有变换器，执行简单检索
-	if len(options.Transformers) == 0 {
-		return r.simpleRetrieve(ctx, query, topK)
+	var (
+		results []RetrievalResult
+		err     error
+	)
+	if len(options.Transformers) == 0 && len(options.PostProcessors) == 0 {
+		// 如果没有变换器和后处理器，执行简单检索
+		results, err = r.simpleRetrieve(ctx, query, topK, options.Filter)
+		report.record(StageRetrieve, "retrieve", query, start, err)
+	} else {
+		// 执行策略管道
+		results, err = r.pipelineRetrieve(ctx, query, topK, options, report)
 	}
 
-	// 执行策略管道
-	return r.pipelineRetrieve(ctx, query, topK, options)
+	report.Duration = time.Since(start)
+	return results, report, err
 }
 
-// simpleRetrieve 简单检索（无策略）
-func (r *VectorRetriever) simpleRetrieve(ctx context.Context, query string, topK int) ([]RetrievalResult, error) {
+// simpleRetrieve 简单检索（无策略），filter 非空时只返回满足元数据条件的块
+func (r *VectorRetriever) simpleRetrieve(ctx context.Context, query string, topK int, filter MetadataFilter) ([]RetrievalResult, error) {
 	// 生成查询向量
 	embeddings, err := r.embedder.Embed(ctx, []string{query})
 	if err != nil {
@@ -84,7 +122,7 @@ func (r *VectorRetriever) simpleRetrieve(ctx context.Context, query string, topK
 	queryVector := embeddings[0]
 
 	// 从向量存储中搜索
-	results, err := r.store.Search(ctx, queryVector, topK)
+	results, err := searchWithFilter(ctx, r.store, queryVector, topK, filter)
 	if err != nil {
 		return nil, err
 	}
@@ -104,7 +142,7 @@ func (r *VectorRetriever) simpleRetrieve(ctx context.Context, query string, topK
 }
 
 // pipelineRetrieve 策略管道检索
-func (r *VectorRetriever) pipelineRetrieve(ctx context.Context, query string, topK int, options *RetrieveOptions) ([]RetrievalResult, error) {
+func (r *VectorRetriever) pipelineRetrieve(ctx context.Context, query string, topK int, options *RetrieveOptions, report *RetrievalReport) ([]RetrievalResult, error) {
 	// 设置超时
 	if options.Timeout > 0 {
 		var cancel context.CancelFunc
@@ -113,10 +151,7 @@ func (r *VectorRetriever) pipelineRetrieve(ctx context.Context, query string, to
 	}
 
 	// 阶段 1: 查询变换
-	transformedQueries, err := r.transformQueries(ctx, query, options.Transformers)
-	if err != nil {
-		return nil, err
-	}
+	transformedQueries := r.transformQueries(ctx, query, options, report)
 
 	// 如果没有变换结果，降级为原始查询
 	if len(transformedQueries) == 0 {
@@ -124,38 +159,28 @@ func (r *VectorRetriever) pipelineRetrieve(ctx context.Context, query string, to
 	}
 
 	// 阶段 2: 并行检索 + 融合
+	// 有后处理器（如重排序）时多取候选，后处理后再截断到 topK
 	fetchK := topK
-	if len(transformedQueries) > 1 && options.FetchMultiplier > 0 {
+	if (len(transformedQueries) > 1 || len(options.PostProcessors) > 0) && options.FetchMultiplier > 0 {
 		fetchK = topK * options.FetchMultiplier
 	}
+	candidateK := topK
+	if len(options.PostProcessors) > 0 {
+		candidateK = fetchK
+	}
 
-	results, weights, err := r.parallelRetrieve(ctx, transformedQueries, fetchK)
+	results, weights, err := r.parallelRetrieve(ctx, transformedQueries, fetchK, topK, options, report)
 	if err != nil {
 		return nil, err
 	}
 
-	// 融合结果
-	var fusedResults []RetrievalResult
-	if len(results) == 1 {
-		// 单查询，无需融合
-		fusedResults = results[0]
-		if len(fusedResults) > topK {
-			fusedResults = fusedResults[:topK]
-		}
-	} else {
-		// 多查询，使用融合策略
-		fusion := options.Fusion
-		if fusion == nil {
-			fusion = NewRRFFusion(60)
-		}
-		fusedResults = fusion.Fuse(results, weights, topK)
-	}
+	fusedResults := mergeSubQuestions(fuseResults(results, weights, candidateK, options.Fusion), results)
 
 	// 阶段 3: 后处理
 	if len(options.PostProcessors) > 0 {
-		fusedResults, err = r.postProcess(ctx, query, fusedResults, options.PostProcessors)
-		if err != nil {
-			return nil, err
+		fusedResults = r.postProcess(ctx, query, fusedResults, options, report)
+		if len(fusedResults) > topK {
+			fusedResults = fusedResults[:topK]
 		}
 	}
 
@@ -163,20 +188,36 @@ func (r *VectorRetriever) pipelineRetrieve(ctx context.Context, query string, to
 }
 
 // transformQueries 执行查询变换
-func (r *VectorRetriever) transformQueries(ctx context.Context, query string, transformers []QueryTransformer) ([]TransformedQuery, error) {
+func (r *VectorRetriever) transformQueries(ctx context.Context, query string, options *RetrieveOptions, report *RetrievalReport) []TransformedQuery {
 	// 初始查询
 	queries := []TransformedQuery{NewTransformedQuery(query)}
 
 	// 串行执行变换器
-	for _, transformer := range transformers {
+	for _, transformer := range options.Transformers {
+		name := strategyName(transformer)
 		var newQueries []TransformedQuery
 		for _, q := range queries {
-			transformed, err := transformer.Transform(ctx, q.Query)
+			var transformed []TransformedQuery
+			start := time.Now()
+			err := runStrategy(ctx, options.StrategyTimeout, func(ctx context.Context) error {
+				var err error
+				transformed, err = transformer.Transform(ctx, q.Query)
+				return err
+			})
+			report.record(StageTransform, name, q.Query, start, err)
 			if err != nil {
 				// 变换失败时保留原查询继续
 				newQueries = append(newQueries, q)
 				continue
 			}
+			// 后续变换（如 MQE）产生的查询继承子问题归属
+			if question := q.Metadata[MetadataSubQuestion]; question != "" {
+				for i := range transformed {
+					if transformed[i].Metadata[MetadataSubQuestion] == "" {
+						transformed[i] = transformed[i].WithMetadata(MetadataSubQuestion, question)
+					}
+				}
+			}
 			newQueries = append(newQueries, transformed...)
 		}
 		if len(newQueries) > 0 {
@@ -184,88 +225,199 @@ func (r *VectorRetriever) transformQueries(ctx context.Context, query string, tr
 		}
 	}
 
-	return queries, nil
+	return queries
 }
 
-// parallelRetrieve 并行检索多个查询
-func (r *VectorRetriever) parallelRetrieve(ctx context.Context, queries []TransformedQuery, topK int) ([][]RetrievalResult, []float32, error) {
-	results := make([][]RetrievalResult, len(queries))
-	weights := make([]float32, len(queries))
-	errors := make([]error, len(queries))
-
-	var wg sync.WaitGroup
-	wg.Add(len(queries))
+// retrieveOutcome 单个查询的检索结果
+type retrieveOutcome struct {
+	idx     int
+	results []RetrievalResult
+	err     error
+	start   time.Time
+}
 
+// parallelRetrieve 并行检索多个查询
+//
+// 按完成顺序收集结果；配置 StableRounds 时，融合后的 topK 连续稳定即取消剩余检索。
+// 返回的结果只包含成功的查询，顺序与 queries 一致。
+func (r *VectorRetriever) parallelRetrieve(ctx context.Context, queries []TransformedQuery, fetchK, topK int, options *RetrieveOptions, report *RetrievalReport) ([][]RetrievalResult, []float32, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	outcomes := make(chan retrieveOutcome, len(queries))
 	for i, q := range queries {
-		go func(idx int, query TransformedQuery) {
-			defer wg.Done()
-
-			// 检查上下文是否已取消
-			select {
-			case <-ctx.Done():
-				errors[idx] = ctx.Err()
-				return
-			default:
+		go func(idx int, query string) {
+			var results []RetrievalResult
+			start := time.Now()
+			err := runStrategy(ctx, options.StrategyTimeout, func(ctx context.Context) error {
+				var err error
+				results, err = r.simpleRetrieve(ctx, query, fetchK, options.Filter)
+				return err
+			})
+			outcome := retrieveOutcome{idx: idx, err: err, start: start}
+			if err == nil {
+				outcome.results = results
 			}
-
-			// 执行检索
-			result, err := r.simpleRetrieve(ctx, query.Query, topK)
-			if err != nil {
-				errors[idx] = err
-				return
+			outcomes <- outcome
+		}(i, q.Query)
+	}
+
+	// 每个检索在取消后立即返回，因此总能收齐所有结果
+	completed := make([][]RetrievalResult, len(queries))
+	succeeded := make([]bool, len(queries))
+	var (
+		firstErr error
+		lastTop  []string
+		stable   int
+	)
+	for remaining := len(queries); remaining > 0; remaining-- {
+		outcome := <-outcomes
+		report.record(StageRetrieve, "retrieve", queries[outcome.idx].Query, outcome.start, outcome.err)
+		if outcome.err != nil {
+			if firstErr == nil {
+				firstErr = outcome.err
 			}
+			continue
+		}
+		completed[outcome.idx] = outcome.results
+		if question := queries[outcome.idx].Metadata[MetadataSubQuestion]; question != "" {
+			completed[outcome.idx] = tagSubQuestion(outcome.results, question)
+		}
+		succeeded[outcome.idx] = true
 
-			results[idx] = result
-			weights[idx] = query.Weight
-		}(i, q)
+		if options.StableRounds <= 0 || report.EarlyStopped || remaining == 1 {
+			continue
+		}
+		results, weights := collectSucceeded(queries, completed, succeeded)
+		top := resultIDs(fuseResults(results, weights, topK, options.Fusion))
+		if lastTop != nil && equalIDs(top, lastTop) {
+			stable++
+		} else {
+			stable = 0
+		}
+		lastTop = top
+		if stable >= options.StableRounds {
+			// topK 已稳定，取消仍在执行的检索
+			report.EarlyStopped = true
+			cancel()
+		}
 	}
 
-	// 等待完成或超时
-	done := make(chan struct{})
-	go func() {
-		wg.Wait()
-		close(done)
-	}()
+	results, weights := collectSucceeded(queries, completed, succeeded)
 
-	select {
-	case <-done:
-		// 所有查询完成
-	case <-ctx.Done():
-		// 超时，使用已完成的结果
+	// 如果所有查询都失败，返回第一个错误
+	if len(results) == 0 && firstErr != nil {
+		return nil, nil, firstErr
 	}
 
-	// 过滤出成功的结果
-	var validResults [][]RetrievalResult
-	var validWeights []float32
-	for i, result := range results {
-		if result != nil {
-			validResults = append(validResults, result)
-			validWeights = append(validWeights, weights[i])
+	return results, weights, nil
+}
+
+// collectSucceeded 按查询顺序收集成功的结果及其权重
+func collectSucceeded(queries []TransformedQuery, completed [][]RetrievalResult, succeeded []bool) ([][]RetrievalResult, []float32) {
+	var results [][]RetrievalResult
+	var weights []float32
+	for i, ok := range succeeded {
+		if ok {
+			results = append(results, completed[i])
+			weights = append(weights, queries[i].Weight)
 		}
 	}
+	return results, weights
+}
 
-	// 如果所有查询都失败，返回第一个错误
-	if len(validResults) == 0 {
-		for _, err := range errors {
-			if err != nil {
-				return nil, nil, err
-			}
+// fuseResults 融合多个查询的结果，单查询时直接截断
+func fuseResults(results [][]RetrievalResult, weights []float32, topK int, fusion FusionStrategy) []RetrievalResult {
+	if len(results) == 0 {
+		return nil
+	}
+	if len(results) == 1 {
+		// 单查询，无需融合
+		fused := results[0]
+		if len(fused) > topK {
+			fused = fused[:topK]
 		}
+		return fused
+	}
+
+	// 多查询，使用融合策略
+	if fusion == nil {
+		fusion = NewRRFFusion(60)
+	}
+	return fusion.Fuse(results, weights, topK)
+}
+
+// resultIDs 返回结果的分块 ID 列表
+func resultIDs(results []RetrievalResult) []string {
+	ids := make([]string, len(results))
+	for i, result := range results {
+		ids[i] = result.Chunk.ID
 	}
+	return ids
+}
 
-	return validResults, validWeights, nil
+// equalIDs 判断两个 ID 列表是否完全一致
+func equalIDs(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
 }
 
-// postProcess 执行后处理
-func (r *VectorRetriever) postProcess(ctx context.Context, query string, results []RetrievalResult, processors []PostProcessor) ([]RetrievalResult, error) {
-	var err error
-	for _, processor := range processors {
-		results, err = processor.Process(ctx, query, results)
+// postProcess 执行后处理，失败的处理器被跳过
+func (r *VectorRetriever) postProcess(ctx context.Context, query string, results []RetrievalResult, options *RetrieveOptions, report *RetrievalReport) []RetrievalResult {
+	for _, processor := range options.PostProcessors {
+		var processed []RetrievalResult
+		// 传入副本，避免超时后处理器仍在修改返回的结果
+		input := append([]RetrievalResult(nil), results...)
+		start := time.Now()
+		err := runStrategy(ctx, options.StrategyTimeout, func(ctx context.Context) error {
+			var err error
+			processed, err = processor.Process(ctx, query, input)
+			return err
+		})
+		report.record(StagePostProcess, strategyName(processor), query, start, err)
 		if err != nil {
-			return nil, err
+			// 保留处理前的结果
+			continue
 		}
+		results = processed
+	}
+	return results
+}
+
+// runStrategy 在独立 goroutine 中执行策略，超时或取消时立即返回
+//
+// 超时返回后 fn 可能仍在运行，调用方只能在返回 nil 时读取 fn 写入的结果。
+func runStrategy(ctx context.Context, timeout time.Duration, fn func(ctx context.Context) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	var cancel context.CancelFunc
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- fn(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
 	}
-	return results, nil
 }
 
 // MultiRetriever 多检索器（支持多个检索源）
@@ -0,0 +1,436 @@
+package rag
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// MetadataRoute 路由检索结果来自的路由名称（Metadata.Custom 键）
+const MetadataRoute = "route"
+
+// Route 命名的检索目标
+type Route struct {
+	// Name 路由名称（唯一）
+	Name string
+	// Description 索引内容描述，供分类器判断查询是否属于该索引
+	Description string
+	// Examples 示例查询（可选），提高嵌入分类器的准确性
+	Examples []string
+	// Retriever 该索引的检索器；整个管道可用 RetrieverFunc(pipeline.QueryWithoutGeneration) 适配
+	Retriever Retriever
+}
+
+// RouteClassifier 查询路由分类器
+type RouteClassifier interface {
+	// Classify 返回查询应路由到的路由名称，按相关性降序；没有合适的路由时返回空
+	Classify(ctx context.Context, query string, routes []Route) ([]string, error)
+}
+
+// Router 多索引查询路由器
+//
+// 用分类器为查询选择索引（产品文档、代码、工单等），选中一个时直接检索，选中多个时并行检索后融合。
+// 分类失败或没有选中任何路由时使用默认路由，未设置默认路由时扇出到全部路由。
+// Router 实现 Retriever 接口，可直接作为管道的检索器；结果的 Metadata.Custom[MetadataRoute] 记录来源路由。
+type Router struct {
+	routes       []Route
+	classifier   RouteClassifier
+	fusion       FusionStrategy
+	maxRoutes    int
+	defaultRoute string
+}
+
+// RouterOption 路由器选项
+type RouterOption func(*Router)
+
+// WithRouterFusion 设置多路由结果的融合策略（默认 RRF，k=60）
+func WithRouterFusion(fusion FusionStrategy) RouterOption {
+	return func(r *Router) {
+		r.fusion = fusion
+	}
+}
+
+// WithMaxRoutes 设置单次查询最多检索的路由数量（默认 1，即只路由到最相关的索引）
+func WithMaxRoutes(n int) RouterOption {
+	return func(r *Router) {
+		if n > 0 {
+			r.maxRoutes = n
+		}
+	}
+}
+
+// WithDefaultRoute 设置分类失败或没有合适路由时使用的路由（默认扇出到全部路由）
+func WithDefaultRoute(name string) RouterOption {
+	return func(r *Router) {
+		r.defaultRoute = name
+	}
+}
+
+// NewRouter 创建查询路由器，classifier 为 nil 时每次查询都扇出到全部路由
+func NewRouter(classifier RouteClassifier, routes []Route, opts ...RouterOption) (*Router, error) {
+	if len(routes) == 0 {
+		return nil, errors.New("router: at least one route is required")
+	}
+	seen := make(map[string]bool, len(routes))
+	for _, route := range routes {
+		if route.Name == "" || route.Retriever == nil {
+			return nil, fmt.Errorf("router: route %q requires a name and a retriever", route.Name)
+		}
+		if seen[route.Name] {
+			return nil, fmt.Errorf("router: duplicate route %q", route.Name)
+		}
+		seen[route.Name] = true
+	}
+
+	r := &Router{
+		routes:     routes,
+		classifier: classifier,
+		fusion:     NewRRFFusion(60),
+		maxRoutes:  1,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	if r.defaultRoute != "" && !seen[r.defaultRoute] {
+		return nil, fmt.Errorf("router: unknown default route %q", r.defaultRoute)
+	}
+	return r, nil
+}
+
+// Route 返回查询将要检索的路由名称
+func (r *Router) Route(ctx context.Context, query string) []string {
+	var names []string
+	if r.classifier != nil {
+		if selected, err := r.classifier.Classify(ctx, query, r.routes); err == nil {
+			names = r.known(selected)
+		}
+	}
+	if len(names) > r.maxRoutes {
+		names = names[:r.maxRoutes]
+	}
+	if len(names) > 0 {
+		return names
+	}
+
+	if r.defaultRoute != "" {
+		return []string{r.defaultRoute}
+	}
+	all := make([]string, len(r.routes))
+	for i, route := range r.routes {
+		all[i] = route.Name
+	}
+	return all
+}
+
+// Retrieve 路由并检索（实现 Retriever 接口）
+//
+// 多路由时部分路由失败只使用成功的结果，全部失败时返回错误。
+func (r *Router) Retrieve(ctx context.Context, query string, topK int) ([]RetrievalResult, error) {
+	names := r.Route(ctx, query)
+
+	lists := make([][]RetrievalResult, len(names))
+	errs := make([]error, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, route Route) {
+			defer wg.Done()
+			results, err := route.Retriever.Retrieve(ctx, query, topK)
+			if err != nil {
+				errs[i] = fmt.Errorf("route %s: %w", route.Name, err)
+				return
+			}
+			lists[i] = tagRoute(results, route.Name)
+		}(i, r.lookup(name))
+	}
+	wg.Wait()
+
+	var (
+		succeeded [][]RetrievalResult
+		failed    []error
+	)
+	for i := range names {
+		if errs[i] != nil {
+			failed = append(failed, errs[i])
+			continue
+		}
+		succeeded = append(succeeded, lists[i])
+	}
+	if len(succeeded) == 0 {
+		return nil, errors.Join(failed...)
+	}
+	if len(succeeded) == 1 {
+		if len(succeeded[0]) > topK {
+			return succeeded[0][:topK], nil
+		}
+		return succeeded[0], nil
+	}
+	return r.fusion.Fuse(succeeded, nil, topK), nil
+}
+
+// known 过滤未知和重复的路由名称
+func (r *Router) known(names []string) []string {
+	var valid []string
+	seen := make(map[string]bool)
+	for _, name := range names {
+		if seen[name] {
+			continue
+		}
+		for _, route := range r.routes {
+			if route.Name == name {
+				seen[name] = true
+				valid = append(valid, name)
+				break
+			}
+		}
+	}
+	return valid
+}
+
+// lookup 按名称查找路由（名称已校验）
+func (r *Router) lookup(name string) Route {
+	for _, route := range r.routes {
+		if route.Name == name {
+			return route
+		}
+	}
+	return Route{}
+}
+
+// tagRoute 复制元数据并记录来源路由
+func tagRoute(results []RetrievalResult, name string) []RetrievalResult {
+	tagged := make([]RetrievalResult, len(results))
+	for i, res := range results {
+		custom := make(map[string]interface{}, len(res.Chunk.Metadata.Custom)+1)
+		for k, v := range res.Chunk.Metadata.Custom {
+			custom[k] = v
+		}
+		custom[MetadataRoute] = name
+		res.Chunk.Metadata.Custom = custom
+		tagged[i] = res
+	}
+	return tagged
+}
+
+// DefaultRoutePrompt 默认 LLM 路由提示模板，包含三个占位符（索引列表 %s、查询 %s、最多选择数量 %d）
+const DefaultRoutePrompt = `你是一个查询路由助手。根据查询内容，从下列索引中选择最可能包含答案的索引。
+
+索引：
+%s
+
+查询：%s
+
+请只输出索引名称；如果查询同时涉及多个索引，按相关性从高到低用逗号分隔（最多 %d 个）。如果都不相关，输出 none。`
+
+// LLMRouteClassifier 使用 LLM 选择路由
+type LLMRouteClassifier struct {
+	llm       LLMProvider
+	prompt    string
+	maxRoutes int
+}
+
+// LLMRouteClassifierOption LLM 路由分类器选项
+type LLMRouteClassifierOption func(*LLMRouteClassifier)
+
+// WithRoutePrompt 设置路由提示模板，见 DefaultRoutePrompt 的占位符
+func WithRoutePrompt(prompt string) LLMRouteClassifierOption {
+	return func(c *LLMRouteClassifier) {
+		c.prompt = prompt
+	}
+}
+
+// WithRouteChoices 设置提示中允许 LLM 选择的最多路由数量（默认 3）
+func WithRouteChoices(n int) LLMRouteClassifierOption {
+	return func(c *LLMRouteClassifier) {
+		if n > 0 {
+			c.maxRoutes = n
+		}
+	}
+}
+
+// NewLLMRouteClassifier 创建 LLM 路由分类器
+func NewLLMRouteClassifier(llm LLMProvider, opts ...LLMRouteClassifierOption) *LLMRouteClassifier {
+	c := &LLMRouteClassifier{
+		llm:       llm,
+		prompt:    DefaultRoutePrompt,
+		maxRoutes: 3,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Classify 让 LLM 按路由名称和描述选择路由
+func (c *LLMRouteClassifier) Classify(ctx context.Context, query string, routes []Route) ([]string, error) {
+	var listing strings.Builder
+	for _, route := range routes {
+		fmt.Fprintf(&listing, "- %s: %s\n", route.Name, route.Description)
+	}
+
+	resp, err := c.llm.Generate(ctx, fmt.Sprintf(c.prompt, strings.TrimSpace(listing.String()), query, c.maxRoutes))
+	if err != nil {
+		return nil, err
+	}
+	return parseRouteNames(resp, routes), nil
+}
+
+// parseRouteNames 从 LLM 响应中按出现顺序提取路由名称（不区分大小写，忽略引号和编号）
+func parseRouteNames(resp string, routes []Route) []string {
+	byName := make(map[string]string, len(routes))
+	for _, route := range routes {
+		byName[strings.ToLower(route.Name)] = route.Name
+	}
+
+	var names []string
+	fields := strings.FieldsFunc(resp, func(r rune) bool {
+		return r == ',' || r == '，' || r == '、' || r == '\n' || r == ';'
+	})
+	for _, field := range fields {
+		field = strings.ToLower(strings.Trim(removeNumberPrefix(strings.TrimSpace(field)), " \t`'\"*-."))
+		if name, ok := byName[field]; ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// EmbeddingRouteClassifier 使用嵌入相似度选择路由
+//
+// 每个路由用 "名称: 描述" 和示例查询的嵌入表示，路由分数取查询与这些文本的最高余弦相似度。
+// 返回最高分路由，以及与最高分相差不超过 margin 的其他路由（用于跨索引的查询）。
+// 路由文本的嵌入按路由名称缓存，路由内容变化后需要创建新的分类器。
+type EmbeddingRouteClassifier struct {
+	embedder  Embedder
+	threshold float32
+	margin    float32
+
+	mu    sync.Mutex
+	cache map[string][][]float32
+}
+
+// EmbeddingRouteClassifierOption 嵌入路由分类器选项
+type EmbeddingRouteClassifierOption func(*EmbeddingRouteClassifier)
+
+// WithRouteThreshold 设置最低相似度，最高分低于该值时不选择任何路由（默认 0）
+func WithRouteThreshold(threshold float32) EmbeddingRouteClassifierOption {
+	return func(c *EmbeddingRouteClassifier) {
+		c.threshold = threshold
+	}
+}
+
+// WithRouteMargin 设置与最高分的最大差距，差距内的路由一并返回（默认 0.05）
+func WithRouteMargin(margin float32) EmbeddingRouteClassifierOption {
+	return func(c *EmbeddingRouteClassifier) {
+		if margin >= 0 {
+			c.margin = margin
+		}
+	}
+}
+
+// NewEmbeddingRouteClassifier 创建嵌入路由分类器
+func NewEmbeddingRouteClassifier(embedder Embedder, opts ...EmbeddingRouteClassifierOption) *EmbeddingRouteClassifier {
+	c := &EmbeddingRouteClassifier{
+		embedder: embedder,
+		margin:   0.05,
+		cache:    make(map[string][][]float32),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Classify 按查询与路由文本的相似度选择路由
+func (c *EmbeddingRouteClassifier) Classify(ctx context.Context, query string, routes []Route) ([]string, error) {
+	if err := c.embedRoutes(ctx, routes); err != nil {
+		return nil, err
+	}
+	embeddings, err := c.embedder.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, err
+	}
+	if len(embeddings) == 0 {
+		return nil, nil
+	}
+
+	type scored struct {
+		name  string
+		score float32
+	}
+	c.mu.Lock()
+	candidates := make([]scored, 0, len(routes))
+	for _, route := range routes {
+		best := float32(-1)
+		for _, vec := range c.cache[route.Name] {
+			best = max(best, cosineSimilarity(embeddings[0], vec))
+		}
+		candidates = append(candidates, scored{name: route.Name, score: best})
+	}
+	c.mu.Unlock()
+
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].score > candidates[j].score })
+	if len(candidates) == 0 || candidates[0].score < c.threshold {
+		return nil, nil
+	}
+
+	names := []string{candidates[0].name}
+	for _, cand := range candidates[1:] {
+		if candidates[0].score-cand.score > c.margin {
+			break
+		}
+		names = append(names, cand.name)
+	}
+	return names, nil
+}
+
+// embedRoutes 嵌入尚未缓存的路由文本
+func (c *EmbeddingRouteClassifier) embedRoutes(ctx context.Context, routes []Route) error {
+	c.mu.Lock()
+	var (
+		texts  []string
+		owners []string
+	)
+	for _, route := range routes {
+		if _, ok := c.cache[route.Name]; ok {
+			continue
+		}
+		texts = append(texts, route.Name+": "+route.Description)
+		owners = append(owners, route.Name)
+		for _, example := range route.Examples {
+			texts = append(texts, example)
+			owners = append(owners, route.Name)
+		}
+	}
+	c.mu.Unlock()
+	if len(texts) == 0 {
+		return nil
+	}
+
+	vectors, err := c.embedder.Embed(ctx, texts)
+	if err != nil {
+		return fmt.Errorf("failed to embed routes: %w", err)
+	}
+	if len(vectors) != len(texts) {
+		return fmt.Errorf("failed to embed routes: expected %d vectors, got %d", len(texts), len(vectors))
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fresh := make(map[string][][]float32)
+	for i, owner := range owners {
+		fresh[owner] = append(fresh[owner], vectors[i])
+	}
+	for name, vecs := range fresh {
+		c.cache[name] = vecs
+	}
+	return nil
+}
+
+// compile-time interface check
+var _ Retriever = (*Router)(nil)
+var _ RouteClassifier = (*LLMRouteClassifier)(nil)
+var _ RouteClassifier = (*EmbeddingRouteClassifier)(nil)
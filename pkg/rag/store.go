@@ -5,6 +5,8 @@ import (
 	"math"
 	"sort"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -17,6 +19,8 @@ type VectorStore interface {
 	Search(ctx context.Context, query []float32, topK int) ([]RetrievalResult, error)
 	// Delete 删除文档块
 	Delete(ctx context.Context, ids []string) error
+	// DeleteByFilter 删除满足过滤条件的文档块，返回回收统计
+	DeleteByFilter(ctx context.Context, filter DeleteFilter) (DeleteStats, error)
 	// Clear 清空存储
 	Clear(ctx context.Context) error
 	// Size 返回存储的块数量
@@ -31,8 +35,9 @@ type Embedder interface {
 
 // InMemoryVectorStore 内存向量存储
 type InMemoryVectorStore struct {
-	chunks map[string]DocumentChunk
-	mu     sync.RWMutex
+	chunks  map[string]DocumentChunk
+	mu      sync.RWMutex
+	version atomic.Uint64
 }
 
 // NewInMemoryVectorStore 创建内存向量存储
@@ -50,11 +55,25 @@ func (s *InMemoryVectorStore) Add(ctx context.Context, chunks []DocumentChunk) e
 	for _, chunk := range chunks {
 		s.chunks[chunk.ID] = chunk
 	}
+	s.version.Add(1)
 	return nil
 }
 
 // Search 搜索相似文档块
 func (s *InMemoryVectorStore) Search(ctx context.Context, query []float32, topK int) ([]RetrievalResult, error) {
+	return s.search(query, topK, nil)
+}
+
+// SearchWithFilter 搜索满足元数据过滤条件的相似文档块（实现 FilteredSearcher 接口）
+func (s *InMemoryVectorStore) SearchWithFilter(ctx context.Context, query []float32, topK int, filter MetadataFilter) ([]RetrievalResult, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	return s.search(query, topK, filter)
+}
+
+// search 计算相似度并返回 top K，filter 为空时不过滤
+func (s *InMemoryVectorStore) search(query []float32, topK int, filter MetadataFilter) ([]RetrievalResult, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
@@ -65,8 +84,9 @@ func (s *InMemoryVectorStore) Search(ctx context.Context, query []float32, topK
 
 	scored := make([]scoredChunk, 0, len(s.chunks))
 
+	now := time.Now()
 	for _, chunk := range s.chunks {
-		if len(chunk.Vector) == 0 {
+		if len(chunk.Vector) == 0 || chunk.Metadata.Expired(now) || !filter.Match(chunk) {
 			continue
 		}
 		score := cosineSimilarity(query, chunk.Vector)
@@ -102,14 +122,42 @@ func (s *InMemoryVectorStore) Delete(ctx context.Context, ids []string) error {
 	for _, id := range ids {
 		delete(s.chunks, id)
 	}
+	s.version.Add(1)
 	return nil
 }
 
+// DeleteByFilter 删除满足过滤条件的文档块
+func (s *InMemoryVectorStore) DeleteByFilter(ctx context.Context, filter DeleteFilter) (DeleteStats, error) {
+	if filter.IsEmpty() {
+		return DeleteStats{}, errEmptyDeleteFilter
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	docs := make(map[string]struct{})
+	var stats DeleteStats
+	for id, chunk := range s.chunks {
+		if !filter.Match(chunk) {
+			continue
+		}
+		delete(s.chunks, id)
+		docs[chunk.DocumentID] = struct{}{}
+		stats.Chunks++
+	}
+	stats.Documents = len(docs)
+	if stats.Chunks > 0 {
+		s.version.Add(1)
+	}
+	return stats, nil
+}
+
 // Clear 清空存储
 func (s *InMemoryVectorStore) Clear(ctx context.Context) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.chunks = make(map[string]DocumentChunk)
+	s.version.Add(1)
 	return nil
 }
 
@@ -120,6 +168,11 @@ func (s *InMemoryVectorStore) Size() int {
 	return len(s.chunks)
 }
 
+// Version 返回内容版本，每次写入、删除或清空后递增（实现 VersionedStore 接口）
+func (s *InMemoryVectorStore) Version() uint64 {
+	return s.version.Load()
+}
+
 // cosineSimilarity 计算余弦相似度
 func cosineSimilarity(a, b []float32) float32 {
 	if len(a) != len(b) || len(a) == 0 {
@@ -153,3 +206,4 @@ func generateChunkID(docID string, index int) string {
 
 // compile-time interface check
 var _ VectorStore = (*InMemoryVectorStore)(nil)
+var _ FilteredSearcher = (*InMemoryVectorStore)(nil)
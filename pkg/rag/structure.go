@@ -0,0 +1,277 @@
+package rag
+
+import (
+	"context"
+	"regexp"
+	"strings"
+)
+
+// MetadataContentKind 块的内容类型（Metadata.Custom 键），取值为 ContentKindText、ContentKindCode 或 ContentKindTable
+const MetadataContentKind = "content_kind"
+
+// MetadataCodeLanguage 代码块围栏上声明的语言（Metadata.Custom 键）
+const MetadataCodeLanguage = "code_language"
+
+// 块内容类型
+const (
+	ContentKindText  = "text"
+	ContentKindCode  = "code"
+	ContentKindTable = "table"
+)
+
+// DefaultMaxBlockSize 代码块和表格保持完整的默认最大长度
+const DefaultMaxBlockSize = 2000
+
+// StructureAwareChunker 识别 Markdown 表格和代码块的分块器
+//
+// 围栏代码块（``` 或 ~~~）和表格作为不可分割的块单独成块，其余文本交给 Text 分块器处理。
+// 每个块在 Metadata.Custom[MetadataContentKind] 中记录内容类型，检索时可以用
+// WithFilter(Where(MetadataContentKind, OpEq, ContentKindCode)) 过滤，或用 WithContentKindBoost 加权。
+//
+// 超过 MaxBlockSize 的表格按行拆分，每部分重复表头；超长的代码块按行拆分，
+// 每部分保留围栏和语言标注，使拆分后的每个块仍能独立渲染。
+type StructureAwareChunker struct {
+	Text         DocumentChunker // 普通文本分块器
+	MaxBlockSize int             // 表格和代码块保持完整的最大长度
+}
+
+// NewStructureAwareChunker 创建结构感知分块器
+//
+// text 为 nil 时使用 NewRecursiveCharacterChunker(512, 50)，maxBlockSize 不大于 0 时使用 DefaultMaxBlockSize。
+func NewStructureAwareChunker(text DocumentChunker, maxBlockSize int) *StructureAwareChunker {
+	if text == nil {
+		text = NewRecursiveCharacterChunker(512, 50)
+	}
+	if maxBlockSize <= 0 {
+		maxBlockSize = DefaultMaxBlockSize
+	}
+	return &StructureAwareChunker{
+		Text:         text,
+		MaxBlockSize: maxBlockSize,
+	}
+}
+
+// Chunk 将文档分割成文本、代码和表格块
+func (c *StructureAwareChunker) Chunk(doc Document) []DocumentChunk {
+	var chunks []DocumentChunk
+	add := func(content string, start, end int, kind, language string) {
+		metadata := doc.Metadata
+		metadata.Custom = make(map[string]interface{}, len(doc.Metadata.Custom)+2)
+		for k, v := range doc.Metadata.Custom {
+			metadata.Custom[k] = v
+		}
+		metadata.Custom[MetadataContentKind] = kind
+		if language != "" {
+			metadata.Custom[MetadataCodeLanguage] = language
+		}
+		chunks = append(chunks, DocumentChunk{
+			ID:          generateChunkID(doc.ID, len(chunks)),
+			DocumentID:  doc.ID,
+			Content:     content,
+			Index:       len(chunks),
+			StartOffset: start,
+			EndOffset:   end,
+			Metadata:    metadata,
+		})
+	}
+
+	for _, block := range splitMarkdownBlocks(doc.Content) {
+		text := doc.Content[block.start:block.end]
+		switch block.kind {
+		case ContentKindText:
+			// 去掉与代码块、表格相邻的空行
+			trimmed := strings.TrimSpace(text)
+			if trimmed == "" {
+				continue
+			}
+			block.start += strings.Index(text, trimmed)
+			text = trimmed
+			for _, chunk := range c.Text.Chunk(Document{ID: doc.ID, Content: text, Metadata: doc.Metadata}) {
+				add(chunk.Content, block.start+chunk.StartOffset, block.start+chunk.EndOffset, ContentKindText, "")
+			}
+		default:
+			for _, part := range c.splitBlock(block, text) {
+				add(part.content, block.start+part.start, block.start+part.end, block.kind, block.language)
+			}
+		}
+	}
+	return chunks
+}
+
+// markdownBlock 文档中一段连续的文本、代码块或表格
+type markdownBlock struct {
+	kind       string
+	language   string
+	start, end int
+}
+
+// blockPart 拆分后的代码块或表格片段，偏移相对于块的起始位置
+type blockPart struct {
+	content    string
+	start, end int
+}
+
+var (
+	// codeFence 围栏代码块的起始行，最多缩进 3 个空格
+	codeFence = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})\\s*([^`\\s]*)")
+	// tableDelimiter 表格表头下的分隔行，如 |---|:--:|
+	tableDelimiter = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?\s*$`)
+)
+
+// splitMarkdownBlocks 将 Markdown 文本切分为连续的文本、代码块和表格
+//
+// 代码块包含起止围栏，未闭合的围栏延续到文档末尾；表格由包含 | 的表头行、
+// 包含 | 的分隔行和其后连续的包含 | 的行组成。
+func splitMarkdownBlocks(content string) []markdownBlock {
+	type line struct {
+		text       string
+		start, end int // end 包含换行符
+	}
+	var lines []line
+	for offset := 0; offset < len(content); {
+		end := strings.IndexByte(content[offset:], '\n')
+		if end < 0 {
+			end = len(content)
+		} else {
+			end += offset + 1
+		}
+		lines = append(lines, line{text: strings.TrimRight(content[offset:end], "\r\n"), start: offset, end: end})
+		offset = end
+	}
+
+	var blocks []markdownBlock
+	textStart := 0
+	emit := func(kind, language string, start, end int) {
+		if start > textStart {
+			blocks = append(blocks, markdownBlock{kind: ContentKindText, start: textStart, end: start})
+		}
+		blocks = append(blocks, markdownBlock{kind: kind, language: language, start: start, end: end})
+		textStart = end
+	}
+
+	for i := 0; i < len(lines); i++ {
+		if m := codeFence.FindStringSubmatch(lines[i].text); m != nil {
+			fence := m[1]
+			j := i + 1
+			for ; j < len(lines); j++ {
+				closing := strings.TrimSpace(lines[j].text)
+				if strings.HasPrefix(closing, fence) && strings.Trim(closing, fence[:1]) == "" {
+					break
+				}
+			}
+			last := min(j, len(lines)-1)
+			emit(ContentKindCode, m[2], lines[i].start, trimNewline(content, lines[last].end))
+			i = last
+			continue
+		}
+		if i+1 < len(lines) && strings.Contains(lines[i].text, "|") && tableDelimiter.MatchString(lines[i+1].text) &&
+			strings.Contains(lines[i+1].text, "|") {
+			j := i + 2
+			for j < len(lines) && strings.TrimSpace(lines[j].text) != "" && strings.Contains(lines[j].text, "|") {
+				j++
+			}
+			emit(ContentKindTable, "", lines[i].start, trimNewline(content, lines[j-1].end))
+			i = j - 1
+		}
+	}
+	if textStart < len(content) {
+		blocks = append(blocks, markdownBlock{kind: ContentKindText, start: textStart, end: len(content)})
+	}
+	return blocks
+}
+
+// trimNewline 去掉块末尾的换行符，使其留在后续文本中
+func trimNewline(content string, end int) int {
+	for end > 0 && (content[end-1] == '\n' || content[end-1] == '\r') {
+		end--
+	}
+	return end
+}
+
+// splitBlock 拆分超过 MaxBlockSize 的代码块或表格，未超过时原样返回
+func (c *StructureAwareChunker) splitBlock(block markdownBlock, text string) []blockPart {
+	if len(text) <= c.MaxBlockSize {
+		return []blockPart{{content: text, start: 0, end: len(text)}}
+	}
+
+	lines := strings.SplitAfter(text, "\n")
+	var header, footer string
+	body := lines
+	switch block.kind {
+	case ContentKindTable:
+		// 表头和分隔行在每部分重复
+		header = lines[0] + lines[1]
+		body = lines[2:]
+	case ContentKindCode:
+		// 起止围栏在每部分重复，未闭合的代码块补全围栏
+		header = lines[0]
+		body = lines[1:]
+		fence := codeFence.FindStringSubmatch(lines[0])[1]
+		if n := len(body); n > 0 && strings.TrimSpace(body[n-1]) != "" &&
+			strings.Trim(strings.TrimSpace(body[n-1]), fence[:1]) == "" {
+			body = body[:n-1]
+		}
+		footer = fence
+	}
+	if len(body) == 0 {
+		return []blockPart{{content: text, start: 0, end: len(text)}}
+	}
+
+	var parts []blockPart
+	offset := len(header)
+	for start := 0; start < len(body); {
+		size := len(header) + len(footer) + len(body[start])
+		end := start + 1
+		for end < len(body) && size+len(body[end]) <= c.MaxBlockSize {
+			size += len(body[end])
+			end++
+		}
+		segment := strings.Join(body[start:end], "")
+		content := header + segment
+		if footer != "" {
+			if !strings.HasSuffix(content, "\n") {
+				content += "\n"
+			}
+			content += footer
+		}
+		parts = append(parts, blockPart{
+			content: strings.TrimRight(content, "\r\n"),
+			start:   offset,
+			end:     offset + len(strings.TrimRight(segment, "\r\n")),
+		})
+		offset += len(segment)
+		start = end
+	}
+	return parts
+}
+
+// ContentKindBooster 按内容类型调整检索分数的后处理器
+//
+// 分数乘以内容类型对应的权重后重新排序，没有对应权重的类型保持原分数。
+// 没有 MetadataContentKind 的块视为 ContentKindText。
+type ContentKindBooster struct {
+	weights map[string]float32
+}
+
+// NewContentKindBooster 创建内容类型加权后处理器，weights 为内容类型到分数乘数的映射
+func NewContentKindBooster(weights map[string]float32) *ContentKindBooster {
+	return &ContentKindBooster{weights: weights}
+}
+
+// Process 按内容类型加权并重新排序
+func (b *ContentKindBooster) Process(ctx context.Context, query string, results []RetrievalResult) ([]RetrievalResult, error) {
+	return boostResults(results, func(res RetrievalResult) float32 {
+		kind, _ := res.Chunk.Metadata.Custom[MetadataContentKind].(string)
+		if kind == "" {
+			kind = ContentKindText
+		}
+		if weight, ok := b.weights[kind]; ok {
+			return weight
+		}
+		return 1
+	}), nil
+}
+
+// compile-time interface check
+var _ DocumentChunker = (*StructureAwareChunker)(nil)
+var _ PostProcessor = (*ContentKindBooster)(nil)
@@ -0,0 +1,269 @@
+package rag
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// MetadataSubQuestion 子问题分解产生的查询在 TransformedQuery.Metadata 中记录所属子问题的键
+const MetadataSubQuestion = "sub_question"
+
+// MetadataSubQuestions 子问题检索结果中命中该块的子问题列表（Metadata.Custom 键）
+const MetadataSubQuestions = "sub_questions"
+
+// DefaultSubQuestionPrompt 默认子问题分解提示模板，包含两个占位符（最多子问题数量 %d、问题 %s）
+const DefaultSubQuestionPrompt = `你是一个问题分解助手。如果下面的问题包含多个部分（例如比较多个对象，或同时询问多件事），
+请把它拆分为最多 %d 个可以独立检索的子问题，每个子问题都要完整、不依赖其他子问题的上下文。
+如果问题只有一个部分，原样输出这个问题。
+
+问题: %s
+
+请每行输出一个子问题，不要添加编号或其他内容：`
+
+// SubQuestionTransformer 子问题分解变换器
+//
+// 将复合问题（"比较 X 和 Y 的记忆系统"、"X vs Y"、多个并列问句）拆分为子问题，
+// 每个子问题独立检索，再由融合策略合并证据。子问题记录在 TransformedQuery.Metadata[MetadataSubQuestion] 中，
+// 检索结果的 Metadata.Custom[MetadataSubQuestions] 标注命中该块的子问题。
+//
+// 先按规则确定性分解（比较句式、vs、分号和多个问号）；设置 LLM 时，规则无法分解的问题
+// 再交给 LLM 分解，LLM 失败时保留原问题。
+type SubQuestionTransformer struct {
+	llm             LLMProvider
+	prompt          string
+	maxQuestions    int
+	includeOriginal bool
+}
+
+// SubQuestionTransformerOption 子问题变换器选项
+type SubQuestionTransformerOption func(*SubQuestionTransformer)
+
+// WithSubQuestionPrompt 设置自定义分解提示模板
+func WithSubQuestionPrompt(prompt string) SubQuestionTransformerOption {
+	return func(t *SubQuestionTransformer) {
+		t.prompt = prompt
+	}
+}
+
+// WithMaxSubQuestions 设置最多子问题数量（默认 4）
+func WithMaxSubQuestions(n int) SubQuestionTransformerOption {
+	return func(t *SubQuestionTransformer) {
+		if n > 0 {
+			t.maxQuestions = n
+		}
+	}
+}
+
+// WithSubQuestionOriginal 设置分解后是否保留原问题一并检索（默认 false）
+func WithSubQuestionOriginal(include bool) SubQuestionTransformerOption {
+	return func(t *SubQuestionTransformer) {
+		t.includeOriginal = include
+	}
+}
+
+// NewSubQuestionTransformer 创建子问题分解变换器，llm 为 nil 时只使用规则分解
+func NewSubQuestionTransformer(llm LLMProvider, opts ...SubQuestionTransformerOption) *SubQuestionTransformer {
+	t := &SubQuestionTransformer{
+		llm:          llm,
+		prompt:       DefaultSubQuestionPrompt,
+		maxQuestions: 4,
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// Transform 分解问题，无法分解时返回原问题
+func (t *SubQuestionTransformer) Transform(ctx context.Context, query string) ([]TransformedQuery, error) {
+	questions := DecomposeQuestion(query)
+	if len(questions) < 2 && t.llm != nil {
+		response, err := t.llm.Generate(ctx, fmt.Sprintf(t.prompt, t.maxQuestions, query))
+		if err == nil {
+			questions = parseMQEResponse(response, t.maxQuestions)
+		}
+	}
+	if len(questions) < 2 {
+		return []TransformedQuery{NewTransformedQuery(query).WithMetadata("source", "original")}, nil
+	}
+	if len(questions) > t.maxQuestions {
+		questions = questions[:t.maxQuestions]
+	}
+
+	results := make([]TransformedQuery, 0, len(questions)+1)
+	if t.includeOriginal {
+		results = append(results, NewTransformedQuery(query).WithMetadata("source", "original"))
+	}
+	for _, q := range questions {
+		results = append(results, NewTransformedQuery(q).
+			WithMetadata("source", "subquestion").
+			WithMetadata(MetadataSubQuestion, q))
+	}
+	return results, nil
+}
+
+var (
+	// compareEN "compare X with/and/to Y"
+	compareEN = regexp.MustCompile(`(?i)^(?:please\s+)?(?:compare|contrast)\s+(.+?)\s+(?:with|and|to|against|vs\.?|versus)\s+(.+?)$`)
+	// differenceEN "(what is the) difference(s) between X and Y"
+	differenceEN = regexp.MustCompile(`(?i)^(?:what(?:'s| is| are)?\s+)?(?:the\s+)?(?:differences?|similarities)\s+between\s+(.+?)\s+and\s+(.+?)$`)
+	// versusEN "X vs Y"
+	versusEN = regexp.MustCompile(`(?i)^(.+?)\s+(?:vs\.?|versus)\s+(.+?)$`)
+	// compareZH "比较/对比 X 和 Y 的 Z"、"X 和 Y 的区别"
+	compareZH = regexp.MustCompile(`^(?:请)?(比较|对比)?\s*(.+?)\s*(?:和|与|跟|同|以及)\s*(.+?)$`)
+	// differenceZH 中文比较问句结尾
+	differenceZH = regexp.MustCompile(`(?:的|之间的|之间)?(?:区别|差异|不同|异同|优劣|对比|比较)(?:是什么|有哪些|在哪里|在哪)?$|有(?:什么|哪些)(?:区别|差异|不同)$`)
+)
+
+// DecomposeQuestion 按规则分解复合问题，不需要调用 LLM
+//
+// 支持：分号或多个问号分隔的并列问题；"compare X with Y"、"difference between X and Y"、
+// "X vs Y"；"比较 X 和 Y 的 Z"、"X 与 Y 的区别"。比较句中省略的共同部分会补全到每个子问题，
+// 如 "compare Go's memory model with Rust's" 分解为 "Go's memory model" 和 "Rust's memory model"。
+// 无法分解时返回只包含原问题的切片。
+func DecomposeQuestion(query string) []string {
+	query = strings.TrimSpace(query)
+	if parts := splitQuestions(query); len(parts) > 1 {
+		return parts
+	}
+
+	body := strings.TrimRight(query, "?？.。!！ ")
+	if m := compareEN.FindStringSubmatch(body); m != nil {
+		return expandComparisonEN(m[1], m[2])
+	}
+	if m := differenceEN.FindStringSubmatch(body); m != nil {
+		return expandComparisonEN(m[1], m[2])
+	}
+	if m := versusEN.FindStringSubmatch(body); m != nil {
+		return expandComparisonEN(m[1], m[2])
+	}
+	if m := compareZH.FindStringSubmatch(body); m != nil {
+		rest, isDifference := m[3], false
+		if loc := differenceZH.FindStringIndex(rest); loc != nil {
+			rest, isDifference = rest[:loc[0]], true
+		}
+		if m[1] != "" || isDifference {
+			return expandComparisonZH(m[2], rest)
+		}
+	}
+	return []string{query}
+}
+
+// splitQuestions 按分号或问号拆分并列问题
+func splitQuestions(query string) []string {
+	var parts []string
+	start := 0
+	for i, r := range query {
+		if r == '?' || r == '？' || r == ';' || r == '；' {
+			if part := strings.TrimSpace(query[start : i+len(string(r))]); part != "" {
+				parts = append(parts, strings.TrimRight(part, ";；"))
+			}
+			start = i + len(string(r))
+		}
+	}
+	if tail := strings.TrimSpace(query[start:]); tail != "" {
+		parts = append(parts, tail)
+	}
+
+	// 过短的片段（如 "why?"）不视为独立问题
+	var questions []string
+	for _, part := range parts {
+		if len([]rune(strings.TrimRight(part, "?？"))) >= 4 {
+			questions = append(questions, part)
+		}
+	}
+	if len(questions) < 2 {
+		return nil
+	}
+	return questions
+}
+
+// expandComparisonEN 补全英文比较句中省略的共同部分
+func expandComparisonEN(left, right string) []string {
+	left, right = strings.TrimSpace(left), strings.TrimSpace(right)
+	// "X's memory system" + "Y's" -> "Y's memory system"
+	if idx := strings.Index(left, "'s "); idx >= 0 && strings.HasSuffix(right, "'s") {
+		right += left[idx+2:]
+	}
+	return []string{left, right}
+}
+
+// expandComparisonZH 补全中文比较句中的共同部分："X 和 Y 的 Z" -> "X 的 Z"、"Y 的 Z"
+func expandComparisonZH(left, right string) []string {
+	left, right = strings.TrimSpace(left), strings.TrimSpace(right)
+	if idx := strings.Index(right, "的"); idx >= 0 {
+		subject, aspect := strings.TrimSpace(right[:idx]), strings.TrimSpace(right[idx+len("的"):])
+		if subject != "" && aspect != "" {
+			return []string{left + "的" + aspect, subject + "的" + aspect}
+		}
+		// "X 的 Z" + "Y 的" -> "Y 的 Z"
+		right = subject
+		if i := strings.Index(left, "的"); i >= 0 && subject != "" {
+			right = subject + left[i:]
+		}
+	}
+	return []string{left, right}
+}
+
+// tagSubQuestion 在子问题的检索结果上记录子问题
+func tagSubQuestion(results []RetrievalResult, question string) []RetrievalResult {
+	tagged := make([]RetrievalResult, len(results))
+	for i, res := range results {
+		custom := make(map[string]interface{}, len(res.Chunk.Metadata.Custom)+1)
+		for k, v := range res.Chunk.Metadata.Custom {
+			custom[k] = v
+		}
+		custom[MetadataSubQuestions] = []string{question}
+		res.Chunk.Metadata.Custom = custom
+		tagged[i] = res
+	}
+	return tagged
+}
+
+// mergeSubQuestions 合并融合结果的子问题标注
+//
+// 同一块被多个子问题命中时记录全部子问题，按块在各子问题结果中的排名排序（排名最高的在前）。
+func mergeSubQuestions(fused []RetrievalResult, lists [][]RetrievalResult) []RetrievalResult {
+	type hit struct {
+		question string
+		rank     int
+	}
+	byChunk := make(map[string][]hit)
+	for _, list := range lists {
+		for rank, res := range list {
+			for _, q := range stringList(res.Chunk.Metadata.Custom[MetadataSubQuestions]) {
+				byChunk[res.Chunk.ID] = append(byChunk[res.Chunk.ID], hit{question: q, rank: rank})
+			}
+		}
+	}
+	if len(byChunk) == 0 {
+		return fused
+	}
+
+	merged := make([]RetrievalResult, len(fused))
+	for i, res := range fused {
+		if hits, ok := byChunk[res.Chunk.ID]; ok {
+			sort.SliceStable(hits, func(a, b int) bool { return hits[a].rank < hits[b].rank })
+			questions := make([]string, 0, len(hits))
+			for _, h := range hits {
+				if !containsID(questions, h.question) {
+					questions = append(questions, h.question)
+				}
+			}
+			custom := make(map[string]interface{}, len(res.Chunk.Metadata.Custom))
+			for k, v := range res.Chunk.Metadata.Custom {
+				custom[k] = v
+			}
+			custom[MetadataSubQuestions] = questions
+			res.Chunk.Metadata.Custom = custom
+		}
+		merged[i] = res
+	}
+	return merged
+}
+
+// compile-time interface check
+var _ QueryTransformer = (*SubQuestionTransformer)(nil)
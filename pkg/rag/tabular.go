@@ -0,0 +1,302 @@
+package rag
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// CSVLoaderOption CSV 加载器选项
+type CSVLoaderOption func(*CSVLoader)
+
+// WithCSVContentColumns 设置作为文档内容的列，默认使用全部列
+func WithCSVContentColumns(columns ...string) CSVLoaderOption {
+	return func(l *CSVLoader) {
+		l.contentColumns = columns
+	}
+}
+
+// WithCSVMetadataColumns 设置写入 Metadata.Custom 的列
+func WithCSVMetadataColumns(columns ...string) CSVLoaderOption {
+	return func(l *CSVLoader) {
+		l.metadataColumns = columns
+	}
+}
+
+// WithCSVDelimiter 设置分隔符（默认逗号）
+func WithCSVDelimiter(delimiter rune) CSVLoaderOption {
+	return func(l *CSVLoader) {
+		l.delimiter = delimiter
+	}
+}
+
+// CSVLoader CSV 文件加载器
+//
+// 第一行为表头，之后每行生成一个文档，内容为 "列名: 值" 的多行文本。
+// title、author、tags（以 ; 或 , 分隔）列写入对应的元数据字段。
+type CSVLoader struct {
+	source          string
+	reader          io.Reader
+	contentColumns  []string
+	metadataColumns []string
+	delimiter       rune
+}
+
+// NewCSVLoader 从 io.Reader 创建 CSV 加载器
+func NewCSVLoader(source string, reader io.Reader, opts ...CSVLoaderOption) *CSVLoader {
+	l := &CSVLoader{
+		source:    source,
+		reader:    reader,
+		delimiter: ',',
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// Load 加载文档
+func (l *CSVLoader) Load(ctx context.Context) ([]Document, error) {
+	r := csv.NewReader(l.reader)
+	r.Comma = l.delimiter
+	r.FieldsPerRecord = -1
+
+	header, err := r.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read csv header: %w", err)
+	}
+	for i := range header {
+		header[i] = strings.TrimSpace(header[i])
+	}
+
+	contentColumns := l.contentColumns
+	if len(contentColumns) == 0 {
+		contentColumns = header
+	}
+
+	var docs []Document
+	for row := 1; ; row++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read csv row %d: %w", row, err)
+		}
+
+		values := make(map[string]string, len(header))
+		for i, name := range header {
+			if i < len(record) {
+				values[name] = record[i]
+			}
+		}
+
+		var sb strings.Builder
+		for _, name := range contentColumns {
+			value := strings.TrimSpace(values[name])
+			if value == "" {
+				continue
+			}
+			if sb.Len() > 0 {
+				sb.WriteString("\n")
+			}
+			sb.WriteString(name + ": " + value)
+		}
+		if sb.Len() == 0 {
+			continue
+		}
+
+		metadata := DocumentMetadata{
+			Source: l.source,
+			Title:  values["title"],
+			Author: values["author"],
+			Custom: map[string]interface{}{"row": row},
+		}
+		if tags := values["tags"]; tags != "" {
+			metadata.Tags = splitTags(tags)
+		}
+		for _, name := range l.metadataColumns {
+			if value, ok := values[name]; ok {
+				metadata.Custom[name] = value
+			}
+		}
+
+		docs = append(docs, Document{
+			ID:       generateID(),
+			Content:  sb.String(),
+			Metadata: metadata,
+		})
+	}
+	return docs, nil
+}
+
+// SupportedExtensions 支持的文件扩展名
+func (l *CSVLoader) SupportedExtensions() []string {
+	return []string{".csv", ".tsv"}
+}
+
+// splitTags 按 ; 或 , 拆分标签
+func splitTags(s string) []string {
+	var tags []string
+	for _, tag := range strings.FieldsFunc(s, func(r rune) bool { return r == ';' || r == ',' }) {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// JSONLLoaderOption JSONL 加载器选项
+type JSONLLoaderOption func(*JSONLLoader)
+
+// WithJSONLContentField 设置作为文档内容的字段（默认依次尝试 content、text、body）
+func WithJSONLContentField(field string) JSONLLoaderOption {
+	return func(l *JSONLLoader) {
+		l.contentField = field
+	}
+}
+
+// JSONLLoader JSON Lines 文件加载器
+//
+// 每行一个 JSON 对象，生成一个文档：id 字段作为文档 ID，title、author、source、tags
+// 写入对应的元数据字段，内容字段之外的其他字段写入 Metadata.Custom。
+// 没有内容字段的行被跳过。
+type JSONLLoader struct {
+	source       string
+	reader       io.Reader
+	contentField string
+}
+
+// NewJSONLLoader 从 io.Reader 创建 JSONL 加载器
+func NewJSONLLoader(source string, reader io.Reader, opts ...JSONLLoaderOption) *JSONLLoader {
+	l := &JSONLLoader{
+		source: source,
+		reader: reader,
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// jsonlContentFields 未指定内容字段时依次尝试的字段
+var jsonlContentFields = []string{"content", "text", "body"}
+
+// Load 加载文档
+func (l *JSONLLoader) Load(ctx context.Context) ([]Document, error) {
+	contentFields := jsonlContentFields
+	if l.contentField != "" {
+		contentFields = []string{l.contentField}
+	}
+
+	var docs []Document
+	scanner := bufio.NewScanner(l.reader)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		var fields map[string]interface{}
+		if err := json.Unmarshal([]byte(text), &fields); err != nil {
+			return nil, fmt.Errorf("failed to parse jsonl line %d: %w", line, err)
+		}
+
+		var content, contentField string
+		for _, field := range contentFields {
+			if s, ok := fields[field].(string); ok && s != "" {
+				content, contentField = s, field
+				break
+			}
+		}
+		if content == "" {
+			continue
+		}
+
+		doc := Document{
+			ID:       generateID(),
+			Content:  content,
+			Metadata: DocumentMetadata{Source: l.source},
+		}
+		keys := make([]string, 0, len(fields))
+		for key := range fields {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			value := fields[key]
+			s, isString := value.(string)
+			switch {
+			case key == contentField:
+				continue
+			case key == "id" && isString && s != "":
+				doc.ID = s
+				continue
+			case key == "title" && isString:
+				doc.Metadata.Title = s
+				continue
+			case key == "author" && isString:
+				doc.Metadata.Author = s
+				continue
+			case key == "source" && isString:
+				doc.Metadata.Source = s
+				continue
+			case key == "tags":
+				if tags := jsonTags(value); tags != nil {
+					doc.Metadata.Tags = tags
+					continue
+				}
+			}
+			if doc.Metadata.Custom == nil {
+				doc.Metadata.Custom = make(map[string]interface{})
+			}
+			doc.Metadata.Custom[key] = value
+		}
+		docs = append(docs, doc)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return docs, nil
+}
+
+// SupportedExtensions 支持的文件扩展名
+func (l *JSONLLoader) SupportedExtensions() []string {
+	return []string{".jsonl", ".ndjson"}
+}
+
+// jsonTags 将字符串数组或分隔的字符串转换为标签
+func jsonTags(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		return splitTags(v)
+	case []interface{}:
+		tags := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				tags = append(tags, s)
+			}
+		}
+		return tags
+	}
+	return nil
+}
+
+// compile-time interface check
+var _ DocumentLoader = (*CSVLoader)(nil)
+var _ DocumentLoader = (*JSONLLoader)(nil)
@@ -2,6 +2,9 @@ package rag
 
 import (
 	"context"
+
+	"github.com/ahhsitt/helloagents-go/pkg/core/llm"
+	"github.com/ahhsitt/helloagents-go/pkg/core/message"
 )
 
 // QueryTransformer 查询变换器接口
@@ -12,6 +15,14 @@ type QueryTransformer interface {
 	Transform(ctx context.Context, query string) ([]TransformedQuery, error)
 }
 
+// QueryNormalizer 查询规范化器接口
+//
+// 在所有检索策略之前执行，memory.QueryNormalizer 满足该接口。
+type QueryNormalizer interface {
+	// NormalizeText 返回规范化后的查询
+	NormalizeText(query string) string
+}
+
 // TransformedQuery 变换后的查询
 type TransformedQuery struct {
 	// Query 变换后的查询文本
@@ -51,6 +62,27 @@ type LLMProvider interface {
 	Generate(ctx context.Context, prompt string) (string, error)
 }
 
+// providerLLM 将 llm.Provider 适配为 LLMProvider
+type providerLLM struct {
+	provider llm.Provider
+}
+
+// NewProviderLLM 将 llm.Provider 适配为 LLMProvider，提示作为单条用户消息发送
+func NewProviderLLM(provider llm.Provider) LLMProvider {
+	return &providerLLM{provider: provider}
+}
+
+// Generate 生成文本
+func (p *providerLLM) Generate(ctx context.Context, prompt string) (string, error) {
+	resp, err := p.provider.Generate(ctx, llm.Request{
+		Messages: []message.Message{message.NewUserMessage(prompt)},
+	})
+	if err != nil {
+		return "", err
+	}
+	return resp.Content, nil
+}
+
 // MultiQueryConfig MQE 配置
 type MultiQueryConfig struct {
 	// NumQueries 扩展查询数量，默认 3
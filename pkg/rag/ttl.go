@@ -0,0 +1,130 @@
+package rag
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// errEmptyDeleteFilter 过滤条件为空（防止误删全部数据，清空请使用 Clear）
+var errEmptyDeleteFilter = errors.New("delete by filter: empty filter, use Clear to remove everything")
+
+// DeleteFilter 批量删除的过滤条件
+//
+// 各条件之间为 AND 关系，零值条件不生效；至少需要设置一个条件。
+type DeleteFilter struct {
+	// Source 来源（精确匹配 Metadata.Source）
+	Source string
+	// DocumentIDs 文档 ID 列表
+	DocumentIDs []string
+	// IngestedAfter 只删除在该时间之后（含）摄取的块
+	IngestedAfter time.Time
+	// IngestedBefore 只删除在该时间之前摄取的块
+	IngestedBefore time.Time
+	// ExpiredAt 只删除在该时间已过期的块
+	ExpiredAt time.Time
+}
+
+// IsEmpty 检查是否未设置任何条件
+func (f DeleteFilter) IsEmpty() bool {
+	return f.Source == "" && len(f.DocumentIDs) == 0 &&
+		f.IngestedAfter.IsZero() && f.IngestedBefore.IsZero() && f.ExpiredAt.IsZero()
+}
+
+// Match 检查文档块是否满足过滤条件
+func (f DeleteFilter) Match(chunk DocumentChunk) bool {
+	if f.Source != "" && chunk.Metadata.Source != f.Source {
+		return false
+	}
+	if len(f.DocumentIDs) > 0 && !containsID(f.DocumentIDs, chunk.DocumentID) {
+		return false
+	}
+	ingested := chunk.Metadata.IngestedAt
+	if !f.IngestedAfter.IsZero() && (ingested.IsZero() || ingested.Before(f.IngestedAfter)) {
+		return false
+	}
+	if !f.IngestedBefore.IsZero() && (ingested.IsZero() || !ingested.Before(f.IngestedBefore)) {
+		return false
+	}
+	if !f.ExpiredAt.IsZero() && !chunk.Metadata.Expired(f.ExpiredAt) {
+		return false
+	}
+	return true
+}
+
+// containsID 检查 ids 是否包含 id
+func containsID(ids []string, id string) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+// DeleteStats 删除回收统计
+type DeleteStats struct {
+	// Chunks 删除的块数量
+	Chunks int `json:"chunks"`
+	// Documents 涉及的文档数量
+	Documents int `json:"documents"`
+}
+
+// Add 累加统计
+func (s *DeleteStats) Add(other DeleteStats) {
+	s.Chunks += other.Chunks
+	s.Documents += other.Documents
+}
+
+// TTLSweeper 过期内容清理器
+//
+// 定期删除 Metadata.ExpiresAt 已到期的文档块，并累计回收统计。
+type TTLSweeper struct {
+	store VectorStore
+
+	mu    sync.Mutex
+	total DeleteStats
+}
+
+// NewTTLSweeper 创建过期内容清理器
+func NewTTLSweeper(store VectorStore) *TTLSweeper {
+	return &TTLSweeper{store: store}
+}
+
+// Sweep 立即删除已过期的文档块，返回本次回收统计
+func (s *TTLSweeper) Sweep(ctx context.Context) (DeleteStats, error) {
+	stats, err := s.store.DeleteByFilter(ctx, DeleteFilter{ExpiredAt: time.Now()})
+	if err != nil {
+		return stats, err
+	}
+
+	s.mu.Lock()
+	s.total.Add(stats)
+	s.mu.Unlock()
+	return stats, nil
+}
+
+// Run 按固定间隔执行 Sweep，直到 ctx 被取消
+//
+// 通常在后台 goroutine 中运行：go sweeper.Run(ctx, time.Hour)。
+func (s *TTLSweeper) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			_, _ = s.Sweep(ctx)
+		}
+	}
+}
+
+// Stats 返回累计回收统计
+func (s *TTLSweeper) Stats() DeleteStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.total
+}
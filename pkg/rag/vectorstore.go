@@ -0,0 +1,329 @@
+package rag
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/ahhsitt/helloagents-go/pkg/memory/store"
+)
+
+// DefaultCollection 持久化向量存储的默认集合名
+const DefaultCollection = "rag_chunks"
+
+// 向量 payload 中的字段，source/document_id 单独保存以便按条件过滤
+const (
+	payloadDocumentID  = "document_id"
+	payloadParentID    = "parent_id"
+	payloadContent     = "content"
+	payloadIndex       = "chunk_index"
+	payloadStartOffset = "start_offset"
+	payloadEndOffset   = "end_offset"
+	payloadSource      = "source"
+	payloadMetadata    = "metadata"
+)
+
+// errScanUnsupported 后端不支持遍历，无法按时间条件删除
+var errScanUnsupported = errors.New("delete by filter: vector backend does not implement store.VectorScanner")
+
+// VectorStoreConfig 向量存储配置
+//
+// 复用 memory/store 的存储配置：Type 为 qdrant、pgvector、sqlite 时使用对应的持久化后端，
+// memory（或零值）时使用 InMemoryVectorStore。
+type VectorStoreConfig struct {
+	store.Config
+	// Collection 集合名（Qdrant 集合 / 表中的 collection 列），默认 DefaultCollection
+	Collection string
+}
+
+// NewVectorStore 根据配置创建向量存储
+func NewVectorStore(config *VectorStoreConfig) (VectorStore, error) {
+	if config == nil || config.Type == "" || config.Type == store.StoreTypeMemory {
+		return NewInMemoryVectorStore(), nil
+	}
+
+	switch config.Type {
+	case store.StoreTypeQdrant, store.StoreTypePgVector, store.StoreTypeSQLite:
+	default:
+		return nil, fmt.Errorf("unsupported vector store type: %s", config.Type)
+	}
+
+	backend, err := store.NewVectorStore(&config.Config)
+	if err != nil {
+		return nil, err
+	}
+	return NewStoreVectorStore(backend, config.Collection), nil
+}
+
+// StoreVectorStore 基于 memory/store 向量后端的持久化向量存储
+//
+// 文档块的内容和元数据保存在向量 payload 中，重启后可直接检索。
+// DeleteByFilter 需要后端实现 store.VectorScanner（内置的 Qdrant、pgvector、SQLite 后端均已实现）。
+type StoreVectorStore struct {
+	backend    store.VectorStore
+	collection string
+	version    atomic.Uint64
+}
+
+// NewStoreVectorStore 使用已有的向量后端创建向量存储，collection 为空时使用 DefaultCollection
+func NewStoreVectorStore(backend store.VectorStore, collection string) *StoreVectorStore {
+	if collection == "" {
+		collection = DefaultCollection
+	}
+	return &StoreVectorStore{
+		backend:    backend,
+		collection: collection,
+	}
+}
+
+// Add 添加文档块，ID 已存在时覆盖
+func (s *StoreVectorStore) Add(ctx context.Context, chunks []DocumentChunk) error {
+	records := make([]store.VectorRecord, 0, len(chunks))
+	for _, chunk := range chunks {
+		if len(chunk.Vector) == 0 {
+			return fmt.Errorf("chunk %s has no vector", chunk.ID)
+		}
+		payload, err := chunkPayload(chunk)
+		if err != nil {
+			return err
+		}
+		records = append(records, store.VectorRecord{
+			ID:       chunk.ID,
+			Vector:   chunk.Vector,
+			Payload:  payload,
+			MemoryID: chunk.DocumentID,
+		})
+	}
+	if err := s.backend.AddVectors(ctx, s.collection, records); err != nil {
+		return err
+	}
+	s.version.Add(1)
+	return nil
+}
+
+// Search 搜索相似文档块，跳过已过期的块
+func (s *StoreVectorStore) Search(ctx context.Context, query []float32, topK int) ([]RetrievalResult, error) {
+	return s.search(ctx, query, topK, nil, nil)
+}
+
+// SearchWithFilter 搜索满足元数据过滤条件的相似文档块（实现 FilteredSearcher 接口）
+//
+// source、document_id、parent_id 的等值条件下推到后端，其余条件在结果返回后匹配。
+func (s *StoreVectorStore) SearchWithFilter(ctx context.Context, query []float32, topK int, filter MetadataFilter) ([]RetrievalResult, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	var backendFilter *store.VectorFilter
+	for _, c := range filter {
+		value, ok := c.Value.(string)
+		if c.Op != OpEq || !ok {
+			continue
+		}
+		if backendFilter == nil {
+			backendFilter = &store.VectorFilter{Conditions: make(map[string]interface{})}
+		}
+		switch c.Field {
+		case "source":
+			backendFilter.Conditions[payloadSource] = value
+		case "document_id":
+			backendFilter.MemoryID = value
+		case "parent_id":
+			backendFilter.Conditions[payloadParentID] = value
+		}
+	}
+	return s.search(ctx, query, topK, backendFilter, filter.Match)
+}
+
+// search 从后端检索并过滤过期块和不满足 match 的块，结果不足时扩大召回数量重试
+func (s *StoreVectorStore) search(ctx context.Context, query []float32, topK int, backendFilter *store.VectorFilter, match func(DocumentChunk) bool) ([]RetrievalResult, error) {
+	if topK <= 0 {
+		return nil, nil
+	}
+
+	limit, maxLimit := topK, topK*16
+	if match != nil {
+		limit, maxLimit = topK*4, topK*256
+	}
+	for {
+		hits, err := s.backend.SearchSimilar(ctx, s.collection, query, limit, backendFilter)
+		if err != nil {
+			return nil, err
+		}
+
+		now := time.Now()
+		results := make([]RetrievalResult, 0, topK)
+		for _, hit := range hits {
+			chunk, err := chunkFromPayload(hit.ID, hit.Payload)
+			if err != nil {
+				return nil, err
+			}
+			if chunk.Metadata.Expired(now) || (match != nil && !match(chunk)) {
+				continue
+			}
+			results = append(results, RetrievalResult{Chunk: chunk, Score: hit.Score})
+			if len(results) == topK {
+				return results, nil
+			}
+		}
+		if len(hits) < limit || limit >= maxLimit {
+			return results, nil
+		}
+		limit *= 2
+	}
+}
+
+// Delete 删除文档块
+func (s *StoreVectorStore) Delete(ctx context.Context, ids []string) error {
+	if err := s.backend.DeleteVectors(ctx, s.collection, ids); err != nil {
+		return err
+	}
+	s.version.Add(1)
+	return nil
+}
+
+// DeleteByFilter 删除满足过滤条件的文档块
+//
+// Source 和单个文档 ID 条件下推到后端，其余条件在遍历 payload 时匹配。
+func (s *StoreVectorStore) DeleteByFilter(ctx context.Context, filter DeleteFilter) (DeleteStats, error) {
+	if filter.IsEmpty() {
+		return DeleteStats{}, errEmptyDeleteFilter
+	}
+	scanner, ok := s.backend.(store.VectorScanner)
+	if !ok {
+		return DeleteStats{}, errScanUnsupported
+	}
+
+	var backendFilter *store.VectorFilter
+	if filter.Source != "" || len(filter.DocumentIDs) == 1 {
+		backendFilter = &store.VectorFilter{}
+		if filter.Source != "" {
+			backendFilter.Conditions = map[string]interface{}{payloadSource: filter.Source}
+		}
+		if len(filter.DocumentIDs) == 1 {
+			backendFilter.MemoryID = filter.DocumentIDs[0]
+		}
+	}
+
+	var ids []string
+	docs := make(map[string]struct{})
+	err := scanner.ScanVectors(ctx, s.collection, backendFilter, func(rec store.VectorRecord) error {
+		chunk, err := chunkFromPayload(rec.ID, rec.Payload)
+		if err != nil {
+			return err
+		}
+		if filter.Match(chunk) {
+			ids = append(ids, rec.ID)
+			docs[chunk.DocumentID] = struct{}{}
+		}
+		return nil
+	})
+	if err != nil {
+		return DeleteStats{}, err
+	}
+	if len(ids) == 0 {
+		return DeleteStats{}, nil
+	}
+
+	if err := s.backend.DeleteVectors(ctx, s.collection, ids); err != nil {
+		return DeleteStats{}, err
+	}
+	s.version.Add(1)
+	return DeleteStats{Chunks: len(ids), Documents: len(docs)}, nil
+}
+
+// Clear 清空集合
+func (s *StoreVectorStore) Clear(ctx context.Context) error {
+	if err := s.backend.Clear(ctx, s.collection); err != nil {
+		return err
+	}
+	s.version.Add(1)
+	return nil
+}
+
+// Version 返回本进程写入产生的内容版本（实现 VersionedStore 接口）
+func (s *StoreVectorStore) Version() uint64 {
+	return s.version.Load()
+}
+
+// Size 返回存储的块数量，后端不可用时返回 0
+func (s *StoreVectorStore) Size() int {
+	stats, err := s.backend.GetStats(context.Background(), s.collection)
+	if err != nil || stats == nil {
+		return 0
+	}
+	return stats.VectorCount
+}
+
+// Backend 返回底层向量后端
+func (s *StoreVectorStore) Backend() store.VectorStore {
+	return s.backend
+}
+
+// Close 关闭底层向量后端
+func (s *StoreVectorStore) Close() error {
+	return s.backend.Close()
+}
+
+// chunkPayload 将文档块编码为 payload
+//
+// 元数据整体编码为 JSON 字符串，避免不同后端对嵌套结构和数字类型的处理差异。
+func chunkPayload(chunk DocumentChunk) (map[string]interface{}, error) {
+	metadata, err := json.Marshal(chunk.Metadata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode metadata of chunk %s: %w", chunk.ID, err)
+	}
+	return map[string]interface{}{
+		payloadDocumentID:  chunk.DocumentID,
+		payloadParentID:    chunk.ParentID,
+		payloadContent:     chunk.Content,
+		payloadIndex:       chunk.Index,
+		payloadStartOffset: chunk.StartOffset,
+		payloadEndOffset:   chunk.EndOffset,
+		payloadSource:      chunk.Metadata.Source,
+		payloadMetadata:    string(metadata),
+	}, nil
+}
+
+// chunkFromPayload 从 payload 还原文档块（不含向量）
+func chunkFromPayload(id string, payload map[string]interface{}) (DocumentChunk, error) {
+	chunk := DocumentChunk{
+		ID:          id,
+		Index:       payloadInt(payload[payloadIndex]),
+		StartOffset: payloadInt(payload[payloadStartOffset]),
+		EndOffset:   payloadInt(payload[payloadEndOffset]),
+	}
+	chunk.DocumentID, _ = payload[payloadDocumentID].(string)
+	chunk.ParentID, _ = payload[payloadParentID].(string)
+	chunk.Content, _ = payload[payloadContent].(string)
+
+	if raw, _ := payload[payloadMetadata].(string); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &chunk.Metadata); err != nil {
+			return DocumentChunk{}, fmt.Errorf("failed to decode metadata of chunk %s: %w", id, err)
+		}
+	}
+	return chunk, nil
+}
+
+// payloadInt 读取整数字段，兼容 JSON 解码后的 float64
+func payloadInt(v interface{}) int {
+	switch n := v.(type) {
+	case int:
+		return n
+	case int64:
+		return int(n)
+	case float64:
+		return int(n)
+	case json.Number:
+		i, _ := n.Int64()
+		return int(i)
+	}
+	return 0
+}
+
+// compile-time interface check
+var _ VectorStore = (*StoreVectorStore)(nil)
+var _ FilteredSearcher = (*StoreVectorStore)(nil)
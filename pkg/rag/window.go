@@ -0,0 +1,135 @@
+package rag
+
+import (
+	"context"
+	"strings"
+)
+
+// MetadataWindow 句子窗口分块中，句子及其前后相邻句子组成的窗口文本（Metadata.Custom 键）
+const MetadataWindow = "window"
+
+// MetadataOriginalText 句子窗口检索结果中，实际命中的原始句子（Metadata.Custom 键）
+const MetadataOriginalText = "original_text"
+
+// SentenceWindowChunker 句子窗口分块器
+//
+// 每个句子作为一个块，以获得精确的向量匹配；同时把句子前后各 WindowSize 个
+// 句子组成的原文片段存入 Metadata.Custom[MetadataWindow]，检索时由
+// SentenceWindowRetriever 替换为窗口文本，为生成提供上下文。
+type SentenceWindowChunker struct {
+	WindowSize int
+}
+
+// NewSentenceWindowChunker 创建句子窗口分块器，windowSize 为前后各附带的句子数（默认 3）
+func NewSentenceWindowChunker(windowSize int) *SentenceWindowChunker {
+	if windowSize < 0 {
+		windowSize = 3
+	}
+	return &SentenceWindowChunker{WindowSize: windowSize}
+}
+
+// Chunk 按句子分割文档并记录窗口
+func (c *SentenceWindowChunker) Chunk(doc Document) []DocumentChunk {
+	type span struct{ start, end int }
+
+	var spans []span
+	offset := 0
+	for _, sentence := range splitSentences(doc.Content) {
+		trimmed := strings.TrimSpace(sentence)
+		if trimmed != "" {
+			start := offset + strings.Index(sentence, trimmed)
+			spans = append(spans, span{start: start, end: start + len(trimmed)})
+		}
+		offset += len(sentence)
+	}
+
+	chunks := make([]DocumentChunk, 0, len(spans))
+	for i, s := range spans {
+		first := spans[max(i-c.WindowSize, 0)]
+		last := spans[min(i+c.WindowSize, len(spans)-1)]
+
+		metadata := doc.Metadata
+		metadata.Custom = make(map[string]interface{}, len(doc.Metadata.Custom)+1)
+		for k, v := range doc.Metadata.Custom {
+			metadata.Custom[k] = v
+		}
+		metadata.Custom[MetadataWindow] = doc.Content[first.start:last.end]
+
+		chunks = append(chunks, DocumentChunk{
+			ID:          generateChunkID(doc.ID, i),
+			DocumentID:  doc.ID,
+			Content:     doc.Content[s.start:s.end],
+			Index:       i,
+			StartOffset: s.start,
+			EndOffset:   s.end,
+			Metadata:    metadata,
+		})
+	}
+	return chunks
+}
+
+// SentenceWindowRetriever 句子窗口检索器
+//
+// 用基础检索器检索句子块，再把每个结果的内容替换为摄取时记录的窗口文本，
+// 命中的句子保存在 Metadata.Custom[MetadataOriginalText] 中。多个句子的窗口
+// 完全相同时只返回一次，分数取最高分，命中的块 ID 记录在
+// Metadata.Custom[MetadataMatchedChunks] 中。没有窗口的结果原样返回。
+type SentenceWindowRetriever struct {
+	base Retriever
+}
+
+// NewSentenceWindowRetriever 创建句子窗口检索器
+//
+// base 通常为基于 SentenceWindowChunker 摄取的向量存储的 VectorRetriever。
+func NewSentenceWindowRetriever(base Retriever) *SentenceWindowRetriever {
+	return &SentenceWindowRetriever{base: base}
+}
+
+// Retrieve 检索句子并替换为窗口文本（实现 Retriever 接口）
+func (r *SentenceWindowRetriever) Retrieve(ctx context.Context, query string, topK int) ([]RetrievalResult, error) {
+	hits, err := r.base.Retrieve(ctx, query, topK)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]RetrievalResult, 0, len(hits))
+	byWindow := make(map[string]int)
+	for _, hit := range hits {
+		window, ok := hit.Chunk.Metadata.Custom[MetadataWindow].(string)
+		if !ok || window == "" {
+			results = append(results, hit)
+			continue
+		}
+
+		key := hit.Chunk.DocumentID + "\x00" + window
+		if i, seen := byWindow[key]; seen {
+			custom := results[i].Chunk.Metadata.Custom
+			custom[MetadataMatchedChunks] = append(custom[MetadataMatchedChunks].([]string), hit.Chunk.ID)
+			if hit.Score > results[i].Score {
+				results[i].Score = hit.Score
+			}
+			continue
+		}
+
+		chunk := hit.Chunk
+		custom := make(map[string]interface{}, len(chunk.Metadata.Custom)+1)
+		for k, v := range chunk.Metadata.Custom {
+			if k != MetadataWindow {
+				custom[k] = v
+			}
+		}
+		custom[MetadataOriginalText] = chunk.Content
+		custom[MetadataMatchedChunks] = []string{chunk.ID}
+		chunk.Metadata.Custom = custom
+		chunk.Content = window
+		chunk.Vector = nil
+
+		byWindow[key] = len(results)
+		results = append(results, RetrievalResult{Chunk: chunk, Score: hit.Score})
+	}
+	return results, nil
+}
+
+// compile-time interface check
+var _ DocumentChunker = (*SentenceWindowChunker)(nil)
+var _ Retriever = (*SentenceWindowRetriever)(nil)
@@ -46,6 +46,22 @@ func (c *Calculator) Parameters() tools.ParameterSchema {
 	}
 }
 
+// Examples 返回使用示例
+func (c *Calculator) Examples() []tools.ToolExample {
+	return []tools.ToolExample{
+		{
+			Description: "Basic arithmetic with operator precedence",
+			Arguments:   map[string]interface{}{"expression": "2 + 3 * 4"},
+			Output:      "14",
+		},
+		{
+			Description: "Grouping with parentheses",
+			Arguments:   map[string]interface{}{"expression": "(10 - 5) / 2"},
+			Output:      "2.5",
+		},
+	}
+}
+
 // Execute 执行计算
 func (c *Calculator) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
 	exprRaw, ok := args["expression"]
@@ -158,3 +174,4 @@ func (c *Calculator) Validate(args map[string]interface{}) error {
 // compile-time interface check
 var _ tools.Tool = (*Calculator)(nil)
 var _ tools.ToolWithValidation = (*Calculator)(nil)
+var _ tools.ToolWithExamples = (*Calculator)(nil)
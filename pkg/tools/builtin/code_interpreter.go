@@ -0,0 +1,538 @@
+package builtin
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ahhsitt/helloagents-go/pkg/tools"
+)
+
+// 代码解释器默认配置
+const (
+	DefaultCodeTimeout = 30 * time.Second
+	// DefaultCodeMemoryLimit 解释器进程的内存上限
+	DefaultCodeMemoryLimit = 512 * 1024 * 1024 // 512MB
+	// DefaultCodeMaxOutput stdout 和 stderr 各自保留的最大字符数
+	DefaultCodeMaxOutput = 16 * 1024
+)
+
+// codeWaitDelay 进程被终止后等待其输出管道关闭的最长时间
+const codeWaitDelay = time.Second
+
+// CodeInterpreter 代码执行工具
+//
+// 在子进程或容器中执行 Python / JavaScript 代码片段，捕获 stdout、stderr 和异常，
+// 让 Agent 能完成计算器之外的数据处理和分析。
+//
+// 资源限制：
+//   - 每次执行的超时时间，超时后终止解释器（会话状态随之丢失）
+//   - 解释器进程的内存上限
+//   - 输出长度上限
+//
+// 指定 session_id 时，同一会话的多次调用共享变量、函数和导入的模块；
+// 不指定时每次调用使用独立的解释器。
+//
+// 使用示例：
+//
+//	interp := builtin.NewCodeInterpreter(
+//	    builtin.WithCodeTimeout(10*time.Second),
+//	    builtin.WithCodeRuntime(builtin.NewDockerRuntime()),
+//	)
+//	defer interp.Close()
+//
+//	result, err := interp.Execute(ctx, map[string]interface{}{
+//	    "language":   "python",
+//	    "code":       "import statistics\nstatistics.mean([1, 2, 3])",
+//	    "session_id": "analysis",
+//	})
+type CodeInterpreter struct {
+	// runtime 代码运行环境
+	runtime CodeRuntime
+	// languages 支持的语言，第一个为默认语言
+	languages []CodeLanguage
+	// timeout 单次执行的超时时间
+	timeout time.Duration
+	// memoryLimit 解释器进程的内存上限（0 表示不限制）
+	memoryLimit int64
+	// maxOutput stdout 和 stderr 各自保留的最大字符数
+	maxOutput int
+	// workDir 会话工作目录（为空则每个会话使用独立的临时目录）
+	workDir string
+	// sessions 持久会话，键为 "language:session_id"
+	sessions map[string]*codeSession
+	// mu 保护 sessions
+	mu sync.Mutex
+}
+
+// CodeInterpreterOption CodeInterpreter 配置选项
+type CodeInterpreterOption func(*CodeInterpreter)
+
+// NewCodeInterpreter 创建代码执行工具
+//
+// 默认在本地子进程中运行，支持 Python 和 JavaScript。
+func NewCodeInterpreter(opts ...CodeInterpreterOption) *CodeInterpreter {
+	c := &CodeInterpreter{
+		runtime:     NewSubprocessRuntime(),
+		languages:   []CodeLanguage{LanguagePython, LanguageJavaScript},
+		timeout:     DefaultCodeTimeout,
+		memoryLimit: DefaultCodeMemoryLimit,
+		maxOutput:   DefaultCodeMaxOutput,
+		sessions:    make(map[string]*codeSession),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// WithCodeRuntime 设置代码运行环境（默认 SubprocessRuntime）
+func WithCodeRuntime(runtime CodeRuntime) CodeInterpreterOption {
+	return func(c *CodeInterpreter) {
+		if runtime != nil {
+			c.runtime = runtime
+		}
+	}
+}
+
+// WithCodeLanguages 设置支持的语言，第一个为默认语言
+func WithCodeLanguages(languages ...CodeLanguage) CodeInterpreterOption {
+	return func(c *CodeInterpreter) {
+		if len(languages) > 0 {
+			c.languages = languages
+		}
+	}
+}
+
+// WithCodeTimeout 设置单次执行的超时时间（默认 DefaultCodeTimeout）
+func WithCodeTimeout(d time.Duration) CodeInterpreterOption {
+	return func(c *CodeInterpreter) {
+		c.timeout = d
+	}
+}
+
+// WithCodeMemoryLimit 设置解释器进程的内存上限（字节，0 表示不限制）
+func WithCodeMemoryLimit(bytes int64) CodeInterpreterOption {
+	return func(c *CodeInterpreter) {
+		c.memoryLimit = bytes
+	}
+}
+
+// WithCodeMaxOutput 设置 stdout 和 stderr 各自保留的最大字符数
+func WithCodeMaxOutput(chars int) CodeInterpreterOption {
+	return func(c *CodeInterpreter) {
+		if chars > 0 {
+			c.maxOutput = chars
+		}
+	}
+}
+
+// WithCodeWorkDir 设置会话工作目录，代码读写的相对路径基于此目录
+//
+// 未设置时每个会话使用独立的临时目录，会话结束后删除。
+func WithCodeWorkDir(dir string) CodeInterpreterOption {
+	return func(c *CodeInterpreter) {
+		c.workDir = dir
+	}
+}
+
+// Name 返回工具名称
+func (c *CodeInterpreter) Name() string {
+	return "code_interpreter"
+}
+
+// Description 返回工具描述
+func (c *CodeInterpreter) Description() string {
+	names := make([]string, len(c.languages))
+	for i, l := range c.languages {
+		names[i] = string(l)
+	}
+	return fmt.Sprintf("Execute %s code and return stdout, stderr and errors. "+
+		"The value of a trailing expression is printed. "+
+		"Pass the same session_id to keep variables between calls. Each run is limited to %v.",
+		strings.Join(names, " or "), c.timeout)
+}
+
+// Parameters 返回参数 Schema
+func (c *CodeInterpreter) Parameters() tools.ParameterSchema {
+	names := make([]string, len(c.languages))
+	for i, l := range c.languages {
+		names[i] = string(l)
+	}
+	return tools.ParameterSchema{
+		Type: "object",
+		Properties: map[string]tools.PropertySchema{
+			"code": {
+				Type:        "string",
+				Description: "The code to execute",
+			},
+			"language": {
+				Type:        "string",
+				Description: "Programming language of the code",
+				Enum:        names,
+				Default:     names[0],
+			},
+			"session_id": {
+				Type:        "string",
+				Description: "Reuse the interpreter state (variables, imports) of earlier calls with the same id",
+			},
+			"reset": {
+				Type:        "boolean",
+				Description: "Discard the session state before running",
+				Default:     false,
+			},
+		},
+		Required: []string{"code"},
+	}
+}
+
+// Execute 执行代码
+//
+// 代码抛出的异常作为结果的一部分返回给 Agent；超时、解释器崩溃等运行环境错误返回 error。
+func (c *CodeInterpreter) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
+	code, _ := args["code"].(string)
+	if strings.TrimSpace(code) == "" {
+		return "", fmt.Errorf("missing required parameter: code")
+	}
+	language := c.languages[0]
+	if l, ok := args["language"].(string); ok && l != "" {
+		language = CodeLanguage(strings.ToLower(l))
+	}
+	if !c.supports(language) {
+		return "", fmt.Errorf("unsupported language %q", language)
+	}
+	sessionID, _ := args["session_id"].(string)
+	reset, _ := args["reset"].(bool)
+
+	var session *codeSession
+	var err error
+	if sessionID == "" {
+		if session, err = c.startSession(language); err != nil {
+			return "", err
+		}
+		defer session.close()
+	} else if session, err = c.session(language, sessionID, reset); err != nil {
+		return "", err
+	}
+
+	result, err := session.run(ctx, code, c.timeout)
+	if err != nil {
+		if sessionID != "" {
+			c.dropSession(language, sessionID, session)
+			err = fmt.Errorf("%w (session %s was reset)", err, sessionID)
+		}
+		return "", err
+	}
+	return result.format(), nil
+}
+
+// CloseSession 结束指定会话，释放解释器进程
+func (c *CodeInterpreter) CloseSession(sessionID string) {
+	c.mu.Lock()
+	var closing []*codeSession
+	for key, s := range c.sessions {
+		if strings.HasSuffix(key, ":"+sessionID) {
+			closing = append(closing, s)
+			delete(c.sessions, key)
+		}
+	}
+	c.mu.Unlock()
+
+	for _, s := range closing {
+		s.close()
+	}
+}
+
+// Close 结束所有会话
+func (c *CodeInterpreter) Close() error {
+	c.mu.Lock()
+	sessions := c.sessions
+	c.sessions = make(map[string]*codeSession)
+	c.mu.Unlock()
+
+	for _, s := range sessions {
+		s.close()
+	}
+	return nil
+}
+
+// supports 判断是否支持该语言
+func (c *CodeInterpreter) supports(language CodeLanguage) bool {
+	for _, l := range c.languages {
+		if l == language {
+			return true
+		}
+	}
+	return false
+}
+
+// session 获取或创建持久会话
+func (c *CodeInterpreter) session(language CodeLanguage, sessionID string, reset bool) (*codeSession, error) {
+	key := string(language) + ":" + sessionID
+
+	c.mu.Lock()
+	if s, ok := c.sessions[key]; ok {
+		if !reset && s.alive() {
+			c.mu.Unlock()
+			return s, nil
+		}
+		delete(c.sessions, key)
+		go s.close()
+	}
+	c.mu.Unlock()
+
+	// 在锁外启动进程，避免阻塞其他会话
+	s, err := c.startSession(language)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// 并发请求已先创建了同一会话时沿用已有会话，保留其状态
+	if existing, ok := c.sessions[key]; ok && existing.alive() {
+		go s.close()
+		return existing, nil
+	}
+	c.sessions[key] = s
+	return s, nil
+}
+
+// dropSession 移除出错的会话
+func (c *CodeInterpreter) dropSession(language CodeLanguage, sessionID string, s *codeSession) {
+	key := string(language) + ":" + sessionID
+	c.mu.Lock()
+	if c.sessions[key] == s {
+		delete(c.sessions, key)
+	}
+	c.mu.Unlock()
+	s.close()
+}
+
+// startSession 启动解释器进程
+func (c *CodeInterpreter) startSession(language CodeLanguage) (*codeSession, error) {
+	workDir, ownsDir := c.workDir, false
+	if workDir == "" {
+		dir, err := os.MkdirTemp("", "helloagents-code-")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create work dir: %w", err)
+		}
+		workDir, ownsDir = dir, true
+	}
+
+	s, err := c.spawn(language, workDir)
+	if err != nil {
+		if ownsDir {
+			os.RemoveAll(workDir)
+		}
+		return nil, err
+	}
+	if ownsDir {
+		s.removeDir = workDir
+	}
+	return s, nil
+}
+
+// spawn 按运行环境创建并启动解释器命令
+func (c *CodeInterpreter) spawn(language CodeLanguage, workDir string) (*codeSession, error) {
+	spec, err := codeCommand(language, workDir, c.maxOutput, c.memoryLimit)
+	if err != nil {
+		return nil, err
+	}
+	cmd, cleanup, err := c.runtime.Command(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	s := &codeSession{
+		cmd:     cmd,
+		stdin:   stdin,
+		stdout:  bufio.NewReader(stdout),
+		cleanup: cleanup,
+		done:    make(chan struct{}),
+	}
+	cmd.Stderr = &limitedWriter{w: &s.stderr, limit: 4096}
+	// 解释器派生的子进程会继承 stderr，Wait 会等待其关闭；
+	// 用进程组统一终止，并限制进程退出后等待输出关闭的时间
+	setProcessGroup(cmd)
+	cmd.WaitDelay = codeWaitDelay
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start %s interpreter via %s: %w", language, c.runtime.Name(), err)
+	}
+	go func() {
+		_ = cmd.Wait()
+		close(s.done)
+	}()
+	return s, nil
+}
+
+// codeSession 一个运行中的解释器进程
+type codeSession struct {
+	// cmd 解释器命令
+	cmd *exec.Cmd
+	// stdin 发送代码
+	stdin io.WriteCloser
+	// stdout 读取执行结果
+	stdout *bufio.Reader
+	// stderr 解释器自身的错误输出（用户代码的 stderr 由驱动脚本单独捕获）
+	stderr bytes.Buffer
+	// cleanup 运行环境的清理函数
+	cleanup func()
+	// removeDir 会话结束时删除的临时目录
+	removeDir string
+	// done 进程退出时关闭
+	done chan struct{}
+	// mu 串行化同一会话中的执行
+	mu sync.Mutex
+	// closeOnce 保证只清理一次
+	closeOnce sync.Once
+}
+
+// codeResult 一次执行的结果
+type codeResult struct {
+	Stdout    string  `json:"stdout"`
+	Stderr    string  `json:"stderr"`
+	Error     *string `json:"error"`
+	Truncated bool    `json:"truncated"`
+}
+
+// alive 判断进程是否仍在运行
+func (s *codeSession) alive() bool {
+	select {
+	case <-s.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// run 执行一段代码，超时或进程退出时返回错误
+func (s *codeSession) run(ctx context.Context, code string, timeout time.Duration) (*codeResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	request, err := json.Marshal(map[string]string{"code": code})
+	if err != nil {
+		return nil, err
+	}
+
+	type response struct {
+		line []byte
+		err  error
+	}
+	responses := make(chan response, 1)
+	go func() {
+		if _, err := s.stdin.Write(append(request, '\n')); err != nil {
+			responses <- response{err: err}
+			return
+		}
+		line, err := s.stdout.ReadBytes('\n')
+		responses <- response{line: line, err: err}
+	}()
+
+	select {
+	case r := <-responses:
+		if r.err != nil {
+			// 进程异常退出（如超出内存限制被终止）
+			s.close()
+			return nil, fmt.Errorf("interpreter exited unexpectedly: %s", s.exitReason())
+		}
+		var result codeResult
+		if err := json.Unmarshal(r.line, &result); err != nil {
+			return nil, fmt.Errorf("invalid interpreter response: %w", err)
+		}
+		return &result, nil
+	case <-ctx.Done():
+		s.close()
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("code execution timed out after %v", timeout)
+		}
+		return nil, ctx.Err()
+	}
+}
+
+// exitReason 描述进程退出原因
+func (s *codeSession) exitReason() string {
+	<-s.done
+	reason := "process exited"
+	if state := s.cmd.ProcessState; state != nil {
+		reason = state.String()
+	}
+	if stderr := strings.TrimSpace(s.stderr.String()); stderr != "" {
+		reason += ": " + stderr
+	}
+	return reason
+}
+
+// close 终止进程并清理资源
+func (s *codeSession) close() {
+	s.closeOnce.Do(func() {
+		_ = s.stdin.Close()
+		killProcessGroup(s.cmd)
+		<-s.done
+		if s.cleanup != nil {
+			s.cleanup()
+		}
+		if s.removeDir != "" {
+			_ = os.RemoveAll(s.removeDir)
+		}
+	})
+}
+
+// format 格式化执行结果
+func (r *codeResult) format() string {
+	var sb strings.Builder
+	if r.Stdout != "" {
+		sb.WriteString(r.Stdout)
+		if !strings.HasSuffix(r.Stdout, "\n") {
+			sb.WriteString("\n")
+		}
+	}
+	if r.Stderr != "" {
+		sb.WriteString("[STDERR]\n" + r.Stderr)
+		if !strings.HasSuffix(r.Stderr, "\n") {
+			sb.WriteString("\n")
+		}
+	}
+	if r.Error != nil && *r.Error != "" {
+		sb.WriteString("[ERROR]\n" + *r.Error + "\n")
+	}
+	if r.Truncated {
+		sb.WriteString("[WARNING] Output truncated\n")
+	}
+	if sb.Len() == 0 {
+		return "(no output)"
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// compile-time interface check
+var (
+	_ tools.Tool  = (*CodeInterpreter)(nil)
+	_ CodeRuntime = (*SubprocessRuntime)(nil)
+	_ CodeRuntime = (*DockerRuntime)(nil)
+)
@@ -0,0 +1,15 @@
+//go:build !unix
+
+package builtin
+
+import "os/exec"
+
+// setProcessGroup 非 Unix 平台不支持进程组，子进程依赖 WaitDelay 兜底
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// killProcessGroup 终止解释器进程
+func killProcessGroup(cmd *exec.Cmd) {
+	if cmd.Process != nil {
+		_ = cmd.Process.Kill()
+	}
+}
@@ -0,0 +1,302 @@
+package builtin
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+
+	"github.com/google/uuid"
+)
+
+// CodeLanguage 代码解释器支持的语言
+type CodeLanguage string
+
+const (
+	// LanguagePython Python 3
+	LanguagePython CodeLanguage = "python"
+	// LanguageJavaScript JavaScript（Node.js）
+	LanguageJavaScript CodeLanguage = "javascript"
+)
+
+// CodeCommand 启动解释器会话所需的信息
+type CodeCommand struct {
+	// Language 代码语言
+	Language CodeLanguage
+	// Program 解释器程序名（python3 或 node）
+	Program string
+	// Args 解释器参数（包含会话驱动脚本）
+	Args []string
+	// WorkDir 会话工作目录
+	WorkDir string
+	// MemoryLimit 内存上限（字节，0 表示不限制）
+	MemoryLimit int64
+}
+
+// CodeRuntime 代码运行环境
+//
+// 负责把 CodeCommand 转换为实际执行的命令，内置本地子进程和 Docker 容器两种实现。
+type CodeRuntime interface {
+	// Name 返回运行环境名称
+	Name() string
+	// Command 创建解释器命令，cleanup（可为 nil）在会话结束、进程被终止后调用
+	Command(spec CodeCommand) (cmd *exec.Cmd, cleanup func(), err error)
+}
+
+// SubprocessRuntime 在本地子进程中运行代码
+//
+// 子进程使用精简的环境变量，工作目录为会话目录。本地运行不提供文件系统和网络隔离，
+// 需要隔离时使用 DockerRuntime。
+type SubprocessRuntime struct {
+	// interpreters 各语言的解释器路径
+	interpreters map[CodeLanguage]string
+}
+
+// SubprocessRuntimeOption SubprocessRuntime 配置选项
+type SubprocessRuntimeOption func(*SubprocessRuntime)
+
+// NewSubprocessRuntime 创建本地子进程运行环境
+func NewSubprocessRuntime(opts ...SubprocessRuntimeOption) *SubprocessRuntime {
+	r := &SubprocessRuntime{interpreters: make(map[CodeLanguage]string)}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// WithInterpreter 设置语言使用的解释器路径（默认从 PATH 查找 python3 / node）
+func WithInterpreter(language CodeLanguage, path string) SubprocessRuntimeOption {
+	return func(r *SubprocessRuntime) {
+		r.interpreters[language] = path
+	}
+}
+
+// Name 返回运行环境名称
+func (r *SubprocessRuntime) Name() string {
+	return "subprocess"
+}
+
+// Command 创建解释器子进程命令
+func (r *SubprocessRuntime) Command(spec CodeCommand) (*exec.Cmd, func(), error) {
+	program := spec.Program
+	if path, ok := r.interpreters[spec.Language]; ok {
+		program = path
+	}
+	path, err := exec.LookPath(program)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s interpreter not found: %w", spec.Language, err)
+	}
+
+	cmd := exec.Command(path, spec.Args...)
+	cmd.Dir = spec.WorkDir
+	cmd.Env = []string{
+		"PATH=" + os.Getenv("PATH"),
+		"HOME=" + spec.WorkDir,
+		"LANG=C.UTF-8",
+		"PYTHONIOENCODING=utf-8",
+		"PYTHONDONTWRITEBYTECODE=1",
+	}
+	return cmd, nil, nil
+}
+
+// 默认 Docker 镜像
+const (
+	DefaultDockerPythonImage = "python:3.12-slim"
+	DefaultDockerNodeImage   = "node:20-slim"
+)
+
+// DockerRuntime 在 Docker 容器中运行代码
+//
+// 每个会话对应一个容器：默认禁用网络，按 MemoryLimit 限制容器内存，
+// 会话目录挂载到容器的 /workspace，会话结束时强制删除容器。
+type DockerRuntime struct {
+	// binary docker 命令路径
+	binary string
+	// images 各语言使用的镜像
+	images map[CodeLanguage]string
+	// network 是否允许访问网络
+	network bool
+	// extraArgs 追加到 docker run 的参数
+	extraArgs []string
+}
+
+// DockerRuntimeOption DockerRuntime 配置选项
+type DockerRuntimeOption func(*DockerRuntime)
+
+// NewDockerRuntime 创建 Docker 容器运行环境
+func NewDockerRuntime(opts ...DockerRuntimeOption) *DockerRuntime {
+	r := &DockerRuntime{
+		binary: "docker",
+		images: map[CodeLanguage]string{
+			LanguagePython:     DefaultDockerPythonImage,
+			LanguageJavaScript: DefaultDockerNodeImage,
+		},
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// WithDockerBinary 设置 docker 命令路径（如 podman）
+func WithDockerBinary(binary string) DockerRuntimeOption {
+	return func(r *DockerRuntime) {
+		if binary != "" {
+			r.binary = binary
+		}
+	}
+}
+
+// WithDockerImage 设置语言使用的镜像，镜像中需包含 python3 或 node
+func WithDockerImage(language CodeLanguage, image string) DockerRuntimeOption {
+	return func(r *DockerRuntime) {
+		r.images[language] = image
+	}
+}
+
+// WithDockerNetwork 设置容器是否允许访问网络（默认禁用）
+func WithDockerNetwork(enabled bool) DockerRuntimeOption {
+	return func(r *DockerRuntime) {
+		r.network = enabled
+	}
+}
+
+// WithDockerArgs 追加 docker run 参数（如 --cpus=1）
+func WithDockerArgs(args ...string) DockerRuntimeOption {
+	return func(r *DockerRuntime) {
+		r.extraArgs = append(r.extraArgs, args...)
+	}
+}
+
+// Name 返回运行环境名称
+func (r *DockerRuntime) Name() string {
+	return "docker"
+}
+
+// Command 创建 docker run 命令
+func (r *DockerRuntime) Command(spec CodeCommand) (*exec.Cmd, func(), error) {
+	image, ok := r.images[spec.Language]
+	if !ok {
+		return nil, nil, fmt.Errorf("no docker image configured for %s", spec.Language)
+	}
+
+	name := "helloagents-code-" + uuid.NewString()[:12]
+	args := []string{"run", "--rm", "-i", "--name", name, "-w", "/workspace"}
+	if !r.network {
+		args = append(args, "--network", "none")
+	}
+	if spec.MemoryLimit > 0 {
+		limit := strconv.FormatInt(spec.MemoryLimit, 10)
+		args = append(args, "--memory", limit, "--memory-swap", limit)
+	}
+	if spec.WorkDir != "" {
+		args = append(args, "-v", spec.WorkDir+":/workspace")
+	}
+	args = append(args, r.extraArgs...)
+	args = append(args, image, spec.Program)
+	args = append(args, spec.Args...)
+
+	// 终止 docker 客户端不会停止容器，需要显式删除
+	cleanup := func() {
+		_ = exec.Command(r.binary, "rm", "-f", name).Run()
+	}
+	return exec.Command(r.binary, args...), cleanup, nil
+}
+
+// pythonDriver Python 会话驱动脚本
+//
+// 从 stdin 逐行读取 {"code": ...}，在持久的全局命名空间中执行，
+// 最后一条语句是表达式时输出其 repr（与 REPL 一致），每段代码输出一行 JSON 结果。
+const pythonDriver = `
+import ast, contextlib, io, json, sys, traceback
+limit, memory = int(sys.argv[1]), int(sys.argv[2])
+if memory > 0:
+    try:
+        import resource
+        resource.setrlimit(resource.RLIMIT_AS, (memory, memory))
+    except Exception:
+        pass
+namespace = {"__name__": "__main__"}
+real_stdout = sys.stdout
+for line in sys.stdin:
+    code = json.loads(line)["code"]
+    out, err, error = io.StringIO(), io.StringIO(), None
+    try:
+        with contextlib.redirect_stdout(out), contextlib.redirect_stderr(err):
+            tree = ast.parse(code, "<cell>", "exec")
+            last = None
+            if tree.body and isinstance(tree.body[-1], ast.Expr):
+                last = ast.Expression(tree.body.pop().value)
+            exec(compile(tree, "<cell>", "exec"), namespace)
+            if last is not None:
+                value = eval(compile(last, "<cell>", "eval"), namespace)
+                if value is not None:
+                    print(repr(value))
+    except BaseException as e:
+        if isinstance(e, SyntaxError):
+            error = "".join(traceback.format_exception_only(type(e), e))
+        else:
+            error = "".join(traceback.format_exception(type(e), e, e.__traceback__.tb_next))
+    out, err = out.getvalue(), err.getvalue()
+    result = {"stdout": out[:limit], "stderr": err[:limit], "error": error and error.strip(),
+              "truncated": len(out) > limit or len(err) > limit}
+    real_stdout.write(json.dumps(result) + "\n")
+    real_stdout.flush()
+`
+
+// nodeDriver JavaScript 会话驱动脚本
+//
+// 在持久的 vm 上下文中执行代码，捕获 console 输出；结果为 Promise 时等待完成，
+// 非 undefined 的结果值以 util.inspect 格式输出。
+const nodeDriver = `
+const vm = require('vm'), util = require('util'), readline = require('readline');
+const limit = Number(process.argv[1]);
+let out = [], err = [];
+const format = (args) => args.map((a) => typeof a === 'string' ? a : util.inspect(a)).join(' ') + '\n';
+const context = vm.createContext({
+  console: {
+    log: (...a) => out.push(format(a)), info: (...a) => out.push(format(a)), debug: (...a) => out.push(format(a)),
+    warn: (...a) => err.push(format(a)), error: (...a) => err.push(format(a)),
+  },
+  require, Buffer, URL, TextEncoder, TextDecoder, setTimeout, clearTimeout, setInterval, clearInterval,
+});
+const rl = readline.createInterface({ input: process.stdin, terminal: false });
+(async () => {
+  for await (const line of rl) {
+    const { code } = JSON.parse(line);
+    out = []; err = [];
+    let error = null;
+    try {
+      let value = vm.runInContext(code, context, { filename: '<cell>' });
+      if (value && typeof value.then === 'function') value = await value;
+      if (value !== undefined) out.push(util.inspect(value) + '\n');
+    } catch (e) {
+      error = e && e.stack ? String(e.stack) : String(e);
+    }
+    const stdout = out.join(''), stderr = err.join('');
+    process.stdout.write(JSON.stringify({
+      stdout: stdout.slice(0, limit), stderr: stderr.slice(0, limit), error,
+      truncated: stdout.length > limit || stderr.length > limit,
+    }) + '\n');
+  }
+})();
+`
+
+// codeCommand 返回语言对应的解释器命令
+func codeCommand(language CodeLanguage, workDir string, maxOutput int, memoryLimit int64) (CodeCommand, error) {
+	spec := CodeCommand{Language: language, WorkDir: workDir, MemoryLimit: memoryLimit}
+	switch language {
+	case LanguagePython:
+		spec.Program = "python3"
+		spec.Args = []string{"-u", "-c", pythonDriver, strconv.Itoa(maxOutput), strconv.FormatInt(memoryLimit, 10)}
+	case LanguageJavaScript:
+		spec.Program = "node"
+		if memoryLimit > 0 {
+			spec.Args = append(spec.Args, fmt.Sprintf("--max-old-space-size=%d", max(memoryLimit/(1024*1024), 16)))
+		}
+		spec.Args = append(spec.Args, "-e", nodeDriver, strconv.Itoa(maxOutput))
+	default:
+		return spec, fmt.Errorf("unsupported language %q", language)
+	}
+	return spec, nil
+}
@@ -0,0 +1,656 @@
+package builtin
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ahhsitt/helloagents-go/pkg/tools"
+)
+
+// 文件工具默认配置
+const (
+	// DefaultFileMaxSize 可读写的单个文件大小上限
+	DefaultFileMaxSize = 1024 * 1024 // 1MB
+	// DefaultFileMaxResults list_dir 和 search 返回的最大条目数
+	DefaultFileMaxResults = 200
+)
+
+// fileActions 文件工具支持的操作
+var fileActions = []string{"read_file", "write_file", "list_dir", "search", "apply_patch"}
+
+// skippedDirs 递归列目录和搜索时跳过的目录
+var skippedDirs = map[string]bool{".git": true, ".hg": true, ".svn": true, "node_modules": true}
+
+// ErrPathOutsideWorkspace 路径不在工作区内
+var ErrPathOutsideWorkspace = errors.New("path is outside the workspace")
+
+// FileTool 沙箱文件系统工具
+//
+// 为编码类 Agent 提供文件读写能力，所有操作限制在工作区根目录内。
+//
+// 支持的操作：
+//   - read_file: 读取文件，可指定行范围
+//   - write_file: 写入文件（自动创建父目录）
+//   - list_dir: 列出目录内容，可递归
+//   - search: 按正则表达式搜索文件内容（类似 grep）
+//   - apply_patch: 应用统一格式的 diff 补丁，支持多文件
+//
+// 安全特性：
+//   - 路径解析符号链接后校验，防止 ../ 或符号链接逃逸工作区
+//   - 单个文件大小限制，拒绝读取二进制文件
+//   - 只读模式禁止 write_file 和 apply_patch
+//   - write_file 和 apply_patch 支持 dry_run，只返回 diff 而不写入
+//
+// 使用示例：
+//
+//	fileTool, err := builtin.NewFileTool("./project")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	result, err := fileTool.Execute(ctx, map[string]interface{}{
+//	    "action":  "search",
+//	    "pattern": "func New",
+//	    "glob":    "*.go",
+//	})
+type FileTool struct {
+	// root 工作区根目录（已解析符号链接的绝对路径）
+	root string
+	// maxSize 单个文件大小上限
+	maxSize int64
+	// maxResults list_dir 和 search 返回的最大条目数
+	maxResults int
+	// readOnly 是否禁止写操作
+	readOnly bool
+	// mu 串行化写操作
+	mu sync.Mutex
+}
+
+// FileToolOption FileTool 配置选项
+type FileToolOption func(*FileTool)
+
+// NewFileTool 创建文件工具，root 必须是已存在的目录
+func NewFileTool(root string, opts ...FileToolOption) (*FileTool, error) {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("invalid workspace root: %w", err)
+	}
+	realRoot, err := filepath.EvalSymlinks(absRoot)
+	if err != nil {
+		return nil, fmt.Errorf("invalid workspace root: %w", err)
+	}
+	info, err := os.Stat(realRoot)
+	if err != nil {
+		return nil, fmt.Errorf("invalid workspace root: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("workspace root is not a directory: %s", root)
+	}
+
+	f := &FileTool{
+		root:       realRoot,
+		maxSize:    DefaultFileMaxSize,
+		maxResults: DefaultFileMaxResults,
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f, nil
+}
+
+// WithFileMaxSize 设置单个文件大小上限（字节，默认 DefaultFileMaxSize）
+func WithFileMaxSize(size int64) FileToolOption {
+	return func(f *FileTool) {
+		if size > 0 {
+			f.maxSize = size
+		}
+	}
+}
+
+// WithFileMaxResults 设置 list_dir 和 search 返回的最大条目数（默认 DefaultFileMaxResults）
+func WithFileMaxResults(n int) FileToolOption {
+	return func(f *FileTool) {
+		if n > 0 {
+			f.maxResults = n
+		}
+	}
+}
+
+// WithFileReadOnly 设置只读模式，禁止 write_file 和 apply_patch
+func WithFileReadOnly(readOnly bool) FileToolOption {
+	return func(f *FileTool) {
+		f.readOnly = readOnly
+	}
+}
+
+// Root 返回工作区根目录
+func (f *FileTool) Root() string {
+	return f.root
+}
+
+// Name 返回工具名称
+func (f *FileTool) Name() string {
+	return "file"
+}
+
+// Description 返回工具描述
+func (f *FileTool) Description() string {
+	desc := "Read, write, list and search files in the workspace, and apply unified diff patches. " +
+		"Paths are relative to the workspace root. Actions: read_file, write_file, list_dir, search, apply_patch."
+	if f.readOnly {
+		desc += " The workspace is read-only: write_file and apply_patch are disabled."
+	}
+	return desc
+}
+
+// Parameters 返回参数 Schema
+func (f *FileTool) Parameters() tools.ParameterSchema {
+	return tools.ParameterSchema{
+		Type: "object",
+		Properties: map[string]tools.PropertySchema{
+			"action": {
+				Type:        "string",
+				Description: "The operation to perform",
+				Enum:        fileActions,
+			},
+			"path": {
+				Type:        "string",
+				Description: "File or directory path relative to the workspace root (list_dir/search default to the root)",
+			},
+			"content": {
+				Type:        "string",
+				Description: "File content (write_file)",
+			},
+			"start_line": {
+				Type:        "integer",
+				Description: "First line to read, 1-based (read_file)",
+			},
+			"end_line": {
+				Type:        "integer",
+				Description: "Last line to read, inclusive (read_file)",
+			},
+			"recursive": {
+				Type:        "boolean",
+				Description: "List subdirectories recursively (list_dir)",
+				Default:     false,
+			},
+			"pattern": {
+				Type:        "string",
+				Description: "Regular expression to search for (search)",
+			},
+			"glob": {
+				Type:        "string",
+				Description: "Only search files whose name matches this glob, e.g. *.go (search)",
+			},
+			"patch": {
+				Type:        "string",
+				Description: "Unified diff to apply; --- and +++ headers select the files, otherwise path is used (apply_patch)",
+			},
+			"dry_run": {
+				Type:        "boolean",
+				Description: "Return the diff without writing any file (write_file/apply_patch)",
+				Default:     false,
+			},
+		},
+		Required: []string{"action"},
+	}
+}
+
+// Validate 验证参数
+func (f *FileTool) Validate(args map[string]interface{}) error {
+	action, _ := args["action"].(string)
+	if !containsString(fileActions, action) {
+		return fmt.Errorf("unsupported action %q, use one of %s", action, strings.Join(fileActions, ", "))
+	}
+
+	switch action {
+	case "read_file", "write_file":
+		if path, _ := args["path"].(string); path == "" {
+			return fmt.Errorf("%s requires path", action)
+		}
+	case "search":
+		if pattern, _ := args["pattern"].(string); pattern == "" {
+			return fmt.Errorf("search requires pattern")
+		}
+	case "apply_patch":
+		if patch, _ := args["patch"].(string); patch == "" {
+			return fmt.Errorf("apply_patch requires patch")
+		}
+	}
+	if action == "write_file" {
+		if _, ok := args["content"].(string); !ok {
+			return fmt.Errorf("write_file requires content")
+		}
+	}
+	if f.readOnly && (action == "write_file" || action == "apply_patch") {
+		return fmt.Errorf("%s is not allowed: workspace is read-only", action)
+	}
+	return nil
+}
+
+// Execute 执行文件操作
+func (f *FileTool) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
+	if err := f.Validate(args); err != nil {
+		return "", err
+	}
+
+	path, _ := args["path"].(string)
+	dryRun, _ := args["dry_run"].(bool)
+
+	switch args["action"].(string) {
+	case "read_file":
+		start, _ := toFloat64(args["start_line"])
+		end, _ := toFloat64(args["end_line"])
+		return f.readFile(path, int(start), int(end))
+	case "write_file":
+		return f.writeFile(path, args["content"].(string), dryRun)
+	case "list_dir":
+		recursive, _ := args["recursive"].(bool)
+		return f.listDir(ctx, path, recursive)
+	case "search":
+		glob, _ := args["glob"].(string)
+		return f.search(ctx, path, args["pattern"].(string), glob)
+	default:
+		return f.applyPatch(path, args["patch"].(string), dryRun)
+	}
+}
+
+// resolve 将路径解析为工作区内的绝对路径
+//
+// 对已存在的部分解析符号链接后再校验，防止通过符号链接逃逸工作区；
+// 不存在的部分（如待创建的文件）保持原样拼接，悬空的符号链接被拒绝。
+func (f *FileTool) resolve(path string) (string, error) {
+	if path == "" {
+		path = "."
+	}
+	full := filepath.Clean(path)
+	if !filepath.IsAbs(full) {
+		full = filepath.Join(f.root, full)
+	}
+
+	var rest []string
+	dir := full
+	for {
+		real, err := filepath.EvalSymlinks(dir)
+		if err == nil {
+			full = filepath.Join(append([]string{real}, rest...)...)
+			break
+		}
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+		// 路径存在但无法解析说明是悬空的符号链接，写入时会跟随链接创建目标，目标无法校验，直接拒绝
+		if _, lerr := os.Lstat(dir); lerr == nil {
+			return "", fmt.Errorf("%w: %s is a dangling symlink", ErrPathOutsideWorkspace, path)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		rest = append([]string{filepath.Base(dir)}, rest...)
+		dir = parent
+	}
+
+	rel, err := filepath.Rel(f.root, full)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%w: %s", ErrPathOutsideWorkspace, path)
+	}
+	return full, nil
+}
+
+// display 返回相对工作区根目录的显示路径
+func (f *FileTool) display(full string) string {
+	rel, err := filepath.Rel(f.root, full)
+	if err != nil {
+		return full
+	}
+	return filepath.ToSlash(rel)
+}
+
+// readText 读取文本文件，校验大小并拒绝二进制文件
+func (f *FileTool) readText(full string) (string, error) {
+	info, err := os.Stat(full)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("file not found: %s", f.display(full))
+		}
+		return "", err
+	}
+	if info.IsDir() {
+		return "", fmt.Errorf("%s is a directory", f.display(full))
+	}
+	if info.Size() > f.maxSize {
+		return "", fmt.Errorf("file %s is too large (%d bytes, limit %d)", f.display(full), info.Size(), f.maxSize)
+	}
+
+	data, err := os.ReadFile(full)
+	if err != nil {
+		return "", err
+	}
+	if isBinary(data) {
+		return "", fmt.Errorf("file %s appears to be binary", f.display(full))
+	}
+	return string(data), nil
+}
+
+// readFile 读取文件，start/end 为 1 起始的行范围（0 表示不限）
+func (f *FileTool) readFile(path string, start, end int) (string, error) {
+	full, err := f.resolve(path)
+	if err != nil {
+		return "", err
+	}
+	content, err := f.readText(full)
+	if err != nil {
+		return "", err
+	}
+	if start <= 0 && end <= 0 {
+		return content, nil
+	}
+
+	lines := splitLines(content)
+	if start <= 0 {
+		start = 1
+	}
+	if end <= 0 || end > len(lines) {
+		end = len(lines)
+	}
+	if start > end {
+		return "", fmt.Errorf("invalid line range %d-%d, file has %d lines", start, end, len(lines))
+	}
+	return strings.Join(lines[start-1:end], "\n") + "\n", nil
+}
+
+// writeFile 写入文件，返回与原内容的 diff
+func (f *FileTool) writeFile(path, content string, dryRun bool) (string, error) {
+	if int64(len(content)) > f.maxSize {
+		return "", fmt.Errorf("content is too large (%d bytes, limit %d)", len(content), f.maxSize)
+	}
+	full, err := f.resolve(path)
+	if err != nil {
+		return "", err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	oldName := devNull
+	var old string
+	if _, err := os.Stat(full); err == nil {
+		if old, err = f.readText(full); err != nil {
+			return "", err
+		}
+		oldName = "a/" + f.display(full)
+	} else if !os.IsNotExist(err) {
+		return "", err
+	}
+	diff := unifiedDiff(oldName, "b/"+f.display(full), old, content)
+
+	if dryRun {
+		return formatDryRun(diff), nil
+	}
+	if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+		return "", fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+		return "", fmt.Errorf("failed to write file: %w", err)
+	}
+	return fmt.Sprintf("Wrote %d bytes to %s", len(content), f.display(full)), nil
+}
+
+// listDir 列出目录内容，目录以 / 结尾，文件附带大小
+func (f *FileTool) listDir(ctx context.Context, path string, recursive bool) (string, error) {
+	full, err := f.resolve(path)
+	if err != nil {
+		return "", err
+	}
+	info, err := os.Stat(full)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("%s is not a directory", f.display(full))
+	}
+
+	var entries []string
+	truncated := false
+	err = filepath.WalkDir(full, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if p == full {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if len(entries) >= f.maxResults {
+			truncated = true
+			return fs.SkipAll
+		}
+
+		name := f.display(p)
+		switch {
+		case d.IsDir():
+			entries = append(entries, name+"/")
+			if !recursive || skippedDirs[d.Name()] {
+				return fs.SkipDir
+			}
+		case d.Type()&fs.ModeSymlink != 0:
+			entries = append(entries, name+"@")
+		default:
+			if info, err := d.Info(); err == nil {
+				entries = append(entries, fmt.Sprintf("%s (%d bytes)", name, info.Size()))
+			} else {
+				entries = append(entries, name)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if len(entries) == 0 {
+		return fmt.Sprintf("%s is empty", f.display(full)), nil
+	}
+	result := strings.Join(entries, "\n")
+	if truncated {
+		result += fmt.Sprintf("\n... (truncated at %d entries)", f.maxResults)
+	}
+	return result, nil
+}
+
+// search 在 path 下按正则表达式搜索文件内容，返回 "path:line: text"
+func (f *FileTool) search(ctx context.Context, path, pattern, glob string) (string, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return "", fmt.Errorf("invalid pattern: %w", err)
+	}
+	if glob != "" {
+		if _, err := filepath.Match(glob, ""); err != nil {
+			return "", fmt.Errorf("invalid glob: %w", err)
+		}
+	}
+	full, err := f.resolve(path)
+	if err != nil {
+		return "", err
+	}
+
+	var matches []string
+	truncated := false
+	err = filepath.WalkDir(full, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if d.IsDir() {
+			if p != full && skippedDirs[d.Name()] {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		// 跳过符号链接，避免读取工作区外的文件
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		if glob != "" {
+			if ok, _ := filepath.Match(glob, d.Name()); !ok {
+				return nil
+			}
+		}
+		if info, err := d.Info(); err != nil || info.Size() > f.maxSize {
+			return nil
+		}
+
+		data, err := os.ReadFile(p)
+		if err != nil || isBinary(data) {
+			return nil
+		}
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		scanner.Buffer(make([]byte, 64*1024), int(f.maxSize)+1)
+		for lineNo := 1; scanner.Scan(); lineNo++ {
+			line := scanner.Text()
+			if !re.MatchString(line) {
+				continue
+			}
+			if len(matches) >= f.maxResults {
+				truncated = true
+				return fs.SkipAll
+			}
+			matches = append(matches, fmt.Sprintf("%s:%d: %s", f.display(p), lineNo, truncateRunes(strings.TrimSpace(line), 200)))
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if len(matches) == 0 {
+		return fmt.Sprintf("No matches for %q", pattern), nil
+	}
+	result := strings.Join(matches, "\n")
+	if truncated {
+		result += fmt.Sprintf("\n... (truncated at %d matches)", f.maxResults)
+	}
+	return result, nil
+}
+
+// patchedFile 应用补丁后待写入的文件
+type patchedFile struct {
+	full    string
+	content string
+	remove  bool
+}
+
+// applyPatch 应用统一格式的补丁
+//
+// 先在内存中应用全部文件的修改，任一修改块不匹配时不写入任何文件。
+func (f *FileTool) applyPatch(path, patch string, dryRun bool) (string, error) {
+	patches, err := parsePatch(patch)
+	if err != nil {
+		return "", fmt.Errorf("invalid patch: %w", err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var results []patchedFile
+	var diffs []string
+	for _, p := range patches {
+		target := p.newPath
+		if target == devNull {
+			target = p.oldPath
+		}
+		if target == "" {
+			target = path
+		}
+		if target == "" {
+			return "", fmt.Errorf("patch has no file headers, path is required")
+		}
+		full, err := f.resolve(target)
+		if err != nil {
+			return "", err
+		}
+
+		var old string
+		if p.oldPath != devNull {
+			if old, err = f.readText(full); err != nil {
+				return "", err
+			}
+		} else if _, err := os.Stat(full); err == nil {
+			return "", fmt.Errorf("cannot create %s: file already exists", f.display(full))
+		}
+
+		content, err := applyHunks(old, p.hunks)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", f.display(full), err)
+		}
+		if int64(len(content)) > f.maxSize {
+			return "", fmt.Errorf("patched %s is too large (%d bytes, limit %d)", f.display(full), len(content), f.maxSize)
+		}
+
+		remove := p.newPath == devNull
+		oldName, newName := "a/"+f.display(full), "b/"+f.display(full)
+		if p.oldPath == devNull {
+			oldName = devNull
+		}
+		if remove {
+			newName, content = devNull, ""
+		}
+		diffs = append(diffs, unifiedDiff(oldName, newName, old, content))
+		results = append(results, patchedFile{full: full, content: content, remove: remove})
+	}
+
+	if dryRun {
+		return formatDryRun(strings.Join(diffs, "")), nil
+	}
+
+	names := make([]string, 0, len(results))
+	for _, r := range results {
+		if r.remove {
+			if err := os.Remove(r.full); err != nil {
+				return "", fmt.Errorf("failed to delete %s: %w", f.display(r.full), err)
+			}
+		} else {
+			if err := os.MkdirAll(filepath.Dir(r.full), 0755); err != nil {
+				return "", fmt.Errorf("failed to create directory: %w", err)
+			}
+			if err := os.WriteFile(r.full, []byte(r.content), 0644); err != nil {
+				return "", fmt.Errorf("failed to write %s: %w", f.display(r.full), err)
+			}
+		}
+		names = append(names, f.display(r.full))
+	}
+	sort.Strings(names)
+	return fmt.Sprintf("Patched %d file(s): %s", len(names), strings.Join(names, ", ")), nil
+}
+
+// formatDryRun 格式化 dry_run 结果
+func formatDryRun(diff string) string {
+	if diff == "" {
+		return "Dry run: no changes"
+	}
+	return "Dry run, no files were written:\n" + diff
+}
+
+// isBinary 根据前 8KB 是否包含 NUL 字节判断二进制内容
+func isBinary(data []byte) bool {
+	if len(data) > 8000 {
+		data = data[:8000]
+	}
+	return bytes.IndexByte(data, 0) >= 0
+}
+
+// compile-time interface check
+var _ tools.ToolWithValidation = (*FileTool)(nil)
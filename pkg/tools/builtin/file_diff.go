@@ -0,0 +1,357 @@
+package builtin
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// diffContextLines 统一 diff 中每个修改块前后保留的上下文行数
+const diffContextLines = 3
+
+// maxDiffCells 逐行比较的规模上限（行数乘积），超出时将不同部分整体视为替换
+const maxDiffCells = 4 * 1024 * 1024
+
+// diffLine diff 中的一行，kind 为 ' '（未变）、'-'（删除）或 '+'（新增）
+type diffLine struct {
+	kind byte
+	text string
+}
+
+// splitLines 按行拆分文本，不包含换行符
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// diffLines 计算从 a 到 b 的逐行编辑序列
+func diffLines(a, b []string) []diffLine {
+	// 去掉公共前缀和后缀，只比较中间不同的部分
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	lines := make([]diffLine, 0, len(a)+len(b))
+	for _, text := range a[:prefix] {
+		lines = append(lines, diffLine{' ', text})
+	}
+	lines = append(lines, diffMiddle(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, text := range a[len(a)-suffix:] {
+		lines = append(lines, diffLine{' ', text})
+	}
+	return lines
+}
+
+// diffMiddle 基于最长公共子序列比较两段文本
+func diffMiddle(a, b []string) []diffLine {
+	var lines []diffLine
+	if len(a)*len(b) > maxDiffCells {
+		for _, text := range a {
+			lines = append(lines, diffLine{'-', text})
+		}
+		for _, text := range b {
+			lines = append(lines, diffLine{'+', text})
+		}
+		return lines
+	}
+
+	// lcs[i][j] 为 a[i:] 与 b[j:] 的最长公共子序列长度
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, diffLine{' ', a[i]})
+			i++
+			j++
+		case j < len(b) && (i == len(a) || lcs[i][j+1] > lcs[i+1][j]):
+			lines = append(lines, diffLine{'+', b[j]})
+			j++
+		default:
+			lines = append(lines, diffLine{'-', a[i]})
+			i++
+		}
+	}
+	return lines
+}
+
+// unifiedDiff 生成统一格式的 diff，内容相同时返回空字符串
+func unifiedDiff(oldName, newName, oldText, newText string) string {
+	if oldText == newText {
+		return ""
+	}
+	lines := diffLines(splitLines(oldText), splitLines(newText))
+
+	var sb strings.Builder
+	sb.WriteString("--- " + oldName + "\n")
+	sb.WriteString("+++ " + newName + "\n")
+
+	for start := 0; start < len(lines); {
+		// 找到下一个修改
+		for start < len(lines) && lines[start].kind == ' ' {
+			start++
+		}
+		if start == len(lines) {
+			break
+		}
+
+		// 向后扩展修改块，直到连续未变的行超过两倍上下文
+		end := start
+		for end < len(lines) {
+			if lines[end].kind != ' ' {
+				end++
+				continue
+			}
+			run := end
+			for run < len(lines) && lines[run].kind == ' ' {
+				run++
+			}
+			if run == len(lines) || run-end > 2*diffContextLines {
+				break
+			}
+			end = run
+		}
+
+		from := max(start-diffContextLines, 0)
+		to := min(end+diffContextLines, len(lines))
+
+		// 计算块在新旧文件中的起始行号
+		oldLine, newLine := 1, 1
+		for _, l := range lines[:from] {
+			if l.kind != '+' {
+				oldLine++
+			}
+			if l.kind != '-' {
+				newLine++
+			}
+		}
+		oldCount, newCount := 0, 0
+		for _, l := range lines[from:to] {
+			if l.kind != '+' {
+				oldCount++
+			}
+			if l.kind != '-' {
+				newCount++
+			}
+		}
+		if oldCount == 0 {
+			oldLine--
+		}
+		if newCount == 0 {
+			newLine--
+		}
+
+		sb.WriteString(fmt.Sprintf("@@ -%d,%d +%d,%d @@\n", oldLine, oldCount, newLine, newCount))
+		for _, l := range lines[from:to] {
+			sb.WriteByte(l.kind)
+			sb.WriteString(l.text)
+			sb.WriteByte('\n')
+		}
+		start = to
+	}
+	return sb.String()
+}
+
+// filePatch 补丁中对单个文件的修改
+type filePatch struct {
+	// oldPath 原文件路径（/dev/null 表示新建文件）
+	oldPath string
+	// newPath 新文件路径（/dev/null 表示删除文件）
+	newPath string
+	// hunks 修改块
+	hunks []patchHunk
+}
+
+// patchHunk 统一 diff 中的一个修改块
+type patchHunk struct {
+	// oldStart 修改块在原文件中的起始行号
+	oldStart int
+	// lines 修改块内容
+	lines []diffLine
+}
+
+// devNull 补丁中表示文件不存在的路径
+const devNull = "/dev/null"
+
+// parsePatch 解析统一格式的补丁，支持多文件
+//
+// 没有 ---/+++ 文件头的补丁视为对单个文件的修改，路径为空，由调用方指定。
+func parsePatch(patch string) ([]*filePatch, error) {
+	var files []*filePatch
+	var current *filePatch
+	var hunk *patchHunk
+
+	lines := strings.Split(strings.ReplaceAll(patch, "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		switch {
+		case strings.HasPrefix(line, "--- ") && i+1 < len(lines) && strings.HasPrefix(lines[i+1], "+++ "):
+			current = &filePatch{
+				oldPath: patchPath(line[4:]),
+				newPath: patchPath(lines[i+1][4:]),
+			}
+			files = append(files, current)
+			hunk = nil
+			i++
+		case strings.HasPrefix(line, "@@"):
+			oldStart, err := parseHunkHeader(line)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+			if current == nil {
+				current = &filePatch{}
+				files = append(files, current)
+			}
+			current.hunks = append(current.hunks, patchHunk{oldStart: oldStart})
+			hunk = &current.hunks[len(current.hunks)-1]
+		case hunk == nil:
+			// 忽略文件头之前的说明文字（如 diff --git、index 行）
+		case line == "" && i == len(lines)-1:
+			// 补丁末尾的换行
+		case line == "":
+			// 部分生成的补丁会去掉空上下文行的前导空格
+			hunk.lines = append(hunk.lines, diffLine{' ', ""})
+		case line[0] == ' ' || line[0] == '-' || line[0] == '+':
+			hunk.lines = append(hunk.lines, diffLine{line[0], line[1:]})
+		case line[0] == '\\':
+			// "\ No newline at end of file"
+		default:
+			hunk = nil
+		}
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no hunks found in patch")
+	}
+	for _, f := range files {
+		if len(f.hunks) == 0 {
+			return nil, fmt.Errorf("patch for %s has no hunks", f.newPath)
+		}
+		// 去掉修改块末尾的空行，它们通常是文件之间的分隔而非上下文
+		for i := range f.hunks {
+			h := &f.hunks[i]
+			for len(h.lines) > 0 && h.lines[len(h.lines)-1] == (diffLine{' ', ""}) {
+				h.lines = h.lines[:len(h.lines)-1]
+			}
+		}
+	}
+	return files, nil
+}
+
+// patchPath 解析文件头中的路径，去掉 a/、b/ 前缀和时间戳
+func patchPath(s string) string {
+	if i := strings.IndexByte(s, '\t'); i >= 0 {
+		s = s[:i]
+	}
+	s = strings.TrimSpace(s)
+	if s == devNull {
+		return s
+	}
+	if strings.HasPrefix(s, "a/") || strings.HasPrefix(s, "b/") {
+		return s[2:]
+	}
+	return s
+}
+
+// parseHunkHeader 解析 "@@ -l,s +l,s @@" 中原文件的起始行号
+func parseHunkHeader(line string) (int, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 3 || !strings.HasPrefix(fields[1], "-") {
+		return 0, fmt.Errorf("invalid hunk header %q", line)
+	}
+	start, _, _ := strings.Cut(fields[1][1:], ",")
+	n, err := strconv.Atoi(start)
+	if err != nil {
+		return 0, fmt.Errorf("invalid hunk header %q", line)
+	}
+	return n, nil
+}
+
+// applyHunks 将修改块应用到文本
+//
+// 每个修改块先在声明的行号处匹配，失败时向前后搜索最近的匹配位置，
+// 以容忍行号偏移；上下文不匹配时返回错误。
+func applyHunks(text string, hunks []patchHunk) (string, error) {
+	lines := splitLines(text)
+	offset := 0
+
+	for n, h := range hunks {
+		var oldBlock, newBlock []string
+		for _, l := range h.lines {
+			if l.kind != '+' {
+				oldBlock = append(oldBlock, l.text)
+			}
+			if l.kind != '-' {
+				newBlock = append(newBlock, l.text)
+			}
+		}
+
+		want := h.oldStart - 1 + offset
+		if len(oldBlock) == 0 {
+			// 纯新增的修改块，oldStart 为插入位置之前的行号
+			want = h.oldStart + offset
+		}
+		pos := findBlock(lines, oldBlock, want)
+		if pos < 0 {
+			return "", fmt.Errorf("hunk %d does not apply at line %d: context does not match", n+1, h.oldStart)
+		}
+
+		updated := make([]string, 0, len(lines)-len(oldBlock)+len(newBlock))
+		updated = append(updated, lines[:pos]...)
+		updated = append(updated, newBlock...)
+		updated = append(updated, lines[pos+len(oldBlock):]...)
+		lines = updated
+		offset += pos - want + len(newBlock) - len(oldBlock)
+	}
+
+	if len(lines) == 0 {
+		return "", nil
+	}
+	return strings.Join(lines, "\n") + "\n", nil
+}
+
+// findBlock 查找与 block 完全匹配、距离 want 最近的位置，不存在时返回 -1
+func findBlock(lines, block []string, want int) int {
+	want = min(max(want, 0), len(lines))
+	for delta := 0; delta <= len(lines); delta++ {
+		for _, pos := range []int{want - delta, want + delta} {
+			if pos < 0 || pos+len(block) > len(lines) {
+				continue
+			}
+			if matchBlock(lines[pos:pos+len(block)], block) {
+				return pos
+			}
+		}
+	}
+	return -1
+}
+
+// matchBlock 逐行比较，忽略行尾空白
+func matchBlock(lines, block []string) bool {
+	for i := range block {
+		if strings.TrimRight(lines[i], " \t\r") != strings.TrimRight(block[i], " \t\r") {
+			return false
+		}
+	}
+	return true
+}
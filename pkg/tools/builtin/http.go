@@ -0,0 +1,388 @@
+package builtin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	agentctx "github.com/ahhsitt/helloagents-go/pkg/context"
+	"github.com/ahhsitt/helloagents-go/pkg/tools"
+)
+
+// HTTP 工具默认配置
+const (
+	DefaultHTTPTimeout      = 30 * time.Second
+	DefaultHTTPMaxRedirects = 5
+	// DefaultHTTPMaxTokens 返回给 Agent 的响应体 token 上限
+	DefaultHTTPMaxTokens = 2000
+)
+
+// httpMethods 支持的请求方法
+var httpMethods = []string{http.MethodGet, http.MethodPost, http.MethodPut}
+
+// HTTPTool HTTP 请求工具
+//
+// 让 Agent 直接调用 REST API，无需通过终端工具执行 curl。
+//
+// 安全特性：
+//   - 域名白名单/黑名单（黑名单优先；未设置白名单时允许所有未被拒绝的域名）
+//   - 重定向目标同样校验域名，并限制重定向次数
+//   - 只支持 http/https 和 GET/POST/PUT
+//   - 超时控制，响应体大小限制
+//   - 响应按 token 预算截断，避免撑爆上下文
+//
+// 使用示例：
+//
+//	tool := builtin.NewHTTPTool(
+//	    builtin.WithHTTPAllowedDomains("api.github.com", "*.example.com"),
+//	    builtin.WithHTTPHeaders(map[string]string{"Authorization": "Bearer " + token}),
+//	)
+//	result, err := tool.Execute(ctx, map[string]interface{}{
+//	    "method": "POST",
+//	    "url":    "https://api.example.com/items",
+//	    "body":   map[string]interface{}{"name": "widget"},
+//	})
+type HTTPTool struct {
+	// client HTTP 客户端
+	client *http.Client
+	// allowedDomains 允许访问的域名（为空则不限制）
+	allowedDomains []string
+	// deniedDomains 禁止访问的域名
+	deniedDomains []string
+	// headers 每个请求附带的请求头
+	headers map[string]string
+	// timeout 请求超时时间
+	timeout time.Duration
+	// maxRedirects 最大重定向次数
+	maxRedirects int
+	// maxTokens 响应体 token 上限
+	maxTokens int
+	// counter token 计数器
+	counter agentctx.TokenCounter
+}
+
+// HTTPToolOption HTTPTool 配置选项
+type HTTPToolOption func(*HTTPTool)
+
+// NewHTTPTool 创建 HTTP 请求工具
+func NewHTTPTool(opts ...HTTPToolOption) *HTTPTool {
+	t := &HTTPTool{
+		client:       &http.Client{},
+		headers:      make(map[string]string),
+		timeout:      DefaultHTTPTimeout,
+		maxRedirects: DefaultHTTPMaxRedirects,
+		maxTokens:    DefaultHTTPMaxTokens,
+		counter:      agentctx.NewEstimatedCounter(),
+	}
+
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	// 复制客户端，避免修改调用方传入的 CheckRedirect
+	client := *t.client
+	client.CheckRedirect = t.checkRedirect
+	t.client = &client
+
+	return t
+}
+
+// WithHTTPAllowedDomains 设置允许访问的域名白名单
+//
+// 域名同时匹配其子域名，"example.com" 和 "*.example.com" 都匹配 "api.example.com"。
+func WithHTTPAllowedDomains(domains ...string) HTTPToolOption {
+	return func(t *HTTPTool) {
+		t.allowedDomains = append(t.allowedDomains, normalizeDomains(domains)...)
+	}
+}
+
+// WithHTTPDeniedDomains 设置禁止访问的域名黑名单，优先于白名单，匹配规则同白名单
+func WithHTTPDeniedDomains(domains ...string) HTTPToolOption {
+	return func(t *HTTPTool) {
+		t.deniedDomains = append(t.deniedDomains, normalizeDomains(domains)...)
+	}
+}
+
+// WithHTTPHeaders 设置每个请求附带的请求头（如认证信息），Agent 传入的同名请求头不会覆盖
+func WithHTTPHeaders(headers map[string]string) HTTPToolOption {
+	return func(t *HTTPTool) {
+		for k, v := range headers {
+			t.headers[k] = v
+		}
+	}
+}
+
+// WithHTTPTimeout 设置请求超时（默认 DefaultHTTPTimeout）
+func WithHTTPTimeout(d time.Duration) HTTPToolOption {
+	return func(t *HTTPTool) {
+		t.timeout = d
+	}
+}
+
+// WithHTTPMaxRedirects 设置最大重定向次数（默认 DefaultHTTPMaxRedirects），0 表示不跟随重定向而直接返回 3xx 响应
+func WithHTTPMaxRedirects(n int) HTTPToolOption {
+	return func(t *HTTPTool) {
+		if n >= 0 {
+			t.maxRedirects = n
+		}
+	}
+}
+
+// WithHTTPMaxTokens 设置响应体 token 上限（默认 DefaultHTTPMaxTokens），超出部分截断
+func WithHTTPMaxTokens(n int) HTTPToolOption {
+	return func(t *HTTPTool) {
+		if n > 0 {
+			t.maxTokens = n
+		}
+	}
+}
+
+// WithHTTPTokenCounter 设置截断响应使用的 token 计数器（默认按字符估算）
+func WithHTTPTokenCounter(counter agentctx.TokenCounter) HTTPToolOption {
+	return func(t *HTTPTool) {
+		if counter != nil {
+			t.counter = counter
+		}
+	}
+}
+
+// WithHTTPClient 设置底层 HTTP 客户端（如自定义代理或 TLS 配置）
+func WithHTTPClient(client *http.Client) HTTPToolOption {
+	return func(t *HTTPTool) {
+		if client != nil {
+			t.client = client
+		}
+	}
+}
+
+// Name 返回工具名称
+func (t *HTTPTool) Name() string {
+	return "http_request"
+}
+
+// Description 返回工具描述
+func (t *HTTPTool) Description() string {
+	desc := "Send an HTTP request (GET, POST or PUT) to a REST API and return the status, content type and response body."
+	if len(t.allowedDomains) > 0 {
+		desc += " Allowed domains: " + strings.Join(t.allowedDomains, ", ") + "."
+	}
+	return desc
+}
+
+// Parameters 返回参数 Schema
+func (t *HTTPTool) Parameters() tools.ParameterSchema {
+	return tools.ParameterSchema{
+		Type: "object",
+		Properties: map[string]tools.PropertySchema{
+			"method": {
+				Type:        "string",
+				Description: "HTTP method",
+				Enum:        httpMethods,
+				Default:     http.MethodGet,
+			},
+			"url": {
+				Type:        "string",
+				Description: "Absolute http or https URL, including any query string",
+			},
+			"headers": {
+				Type:        "object",
+				Description: "Request headers as a name to value map",
+			},
+			"body": {
+				Type:        "object",
+				Description: "JSON request body for POST or PUT",
+			},
+		},
+		Required: []string{"url"},
+	}
+}
+
+// Execute 发送 HTTP 请求
+func (t *HTTPTool) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
+	rawURL, _ := args["url"].(string)
+	if strings.TrimSpace(rawURL) == "" {
+		return "", fmt.Errorf("missing required parameter: url")
+	}
+	target, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return "", fmt.Errorf("invalid url: %w", err)
+	}
+	if err := t.checkURL(target); err != nil {
+		return "", err
+	}
+
+	method := http.MethodGet
+	if m, ok := args["method"].(string); ok && m != "" {
+		method = strings.ToUpper(m)
+	}
+	if !containsString(httpMethods, method) {
+		return "", fmt.Errorf("unsupported method %s, use one of %s", method, strings.Join(httpMethods, ", "))
+	}
+
+	body, contentType, err := encodeHTTPBody(args["body"])
+	if err != nil {
+		return "", err
+	}
+	if body != nil && method == http.MethodGet {
+		return "", fmt.Errorf("GET requests cannot have a body")
+	}
+
+	if t.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, t.timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target.String(), bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if headers, ok := args["headers"].(map[string]interface{}); ok {
+		for k, v := range headers {
+			req.Header.Set(k, fmt.Sprint(v))
+		}
+	}
+	for k, v := range t.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, DefaultMaxOutputSize))
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("HTTP %s\n", resp.Status))
+	if ct := resp.Header.Get("Content-Type"); ct != "" {
+		sb.WriteString(fmt.Sprintf("Content-Type: %s\n", ct))
+	}
+	sb.WriteString("\n")
+	text, truncated := truncateToTokens(t.counter, string(data), t.maxTokens)
+	sb.WriteString(text)
+	if truncated {
+		sb.WriteString(fmt.Sprintf("\n... [truncated: response exceeds %d tokens]", t.maxTokens))
+	}
+	return sb.String(), nil
+}
+
+// checkRedirect 限制重定向次数，并校验重定向目标的域名
+func (t *HTTPTool) checkRedirect(req *http.Request, via []*http.Request) error {
+	if t.maxRedirects == 0 {
+		return http.ErrUseLastResponse
+	}
+	if len(via) > t.maxRedirects {
+		return fmt.Errorf("stopped after %d redirects", t.maxRedirects)
+	}
+	return t.checkURL(req.URL)
+}
+
+// checkURL 校验协议和域名
+func (t *HTTPTool) checkURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("unsupported url scheme %q, only http and https are allowed", u.Scheme)
+	}
+	host := strings.ToLower(u.Hostname())
+	if host == "" {
+		return fmt.Errorf("url has no host")
+	}
+	if matchDomain(host, t.deniedDomains) {
+		return fmt.Errorf("domain %s is denied", host)
+	}
+	if len(t.allowedDomains) > 0 && !matchDomain(host, t.allowedDomains) {
+		return fmt.Errorf("domain %s is not in the allowed list: %s", host, strings.Join(t.allowedDomains, ", "))
+	}
+	return nil
+}
+
+// encodeHTTPBody 编码请求体：字符串原样发送，其余值编码为 JSON
+func encodeHTTPBody(body interface{}) ([]byte, string, error) {
+	switch v := body.(type) {
+	case nil:
+		return nil, "", nil
+	case string:
+		if v == "" {
+			return nil, "", nil
+		}
+		if json.Valid([]byte(v)) {
+			return []byte(v), "application/json", nil
+		}
+		return []byte(v), "text/plain; charset=utf-8", nil
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to encode body as JSON: %w", err)
+		}
+		return data, "application/json", nil
+	}
+}
+
+// normalizeDomains 规范化域名列表：小写，去掉 "*." 前缀
+func normalizeDomains(domains []string) []string {
+	result := make([]string, 0, len(domains))
+	for _, d := range domains {
+		d = strings.ToLower(strings.TrimSpace(d))
+		d = strings.TrimPrefix(strings.TrimPrefix(d, "*"), ".")
+		if d != "" {
+			result = append(result, d)
+		}
+	}
+	return result
+}
+
+// matchDomain 判断 host 是否为列表中的域名或其子域名
+func matchDomain(host string, domains []string) bool {
+	for _, d := range domains {
+		if host == d || strings.HasSuffix(host, "."+d) {
+			return true
+		}
+	}
+	return false
+}
+
+// truncateToTokens 将文本截断到 maxTokens 以内，返回截断后的文本和是否发生截断
+func truncateToTokens(counter agentctx.TokenCounter, text string, maxTokens int) (string, bool) {
+	if maxTokens <= 0 || counter.Count(text) <= maxTokens {
+		return text, false
+	}
+
+	// 二分查找不超过预算的最长前缀（按 rune 截断，避免切断多字节字符）
+	runes := []rune(text)
+	lo, hi := 0, len(runes)
+	for lo < hi {
+		mid := (lo + hi + 1) / 2
+		if counter.Count(string(runes[:mid])) <= maxTokens {
+			lo = mid
+		} else {
+			hi = mid - 1
+		}
+	}
+	return string(runes[:lo]), true
+}
+
+// containsString 判断列表是否包含 s
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// compile-time interface check
+var _ tools.Tool = (*HTTPTool)(nil)
@@ -54,6 +54,7 @@ type MCPTool struct {
 	description    string
 	serverCommand  []string
 	serverEnv      map[string]string
+	serverDir      string
 	envKeys        []string
 	autoExpand     bool
 	prefix         string
@@ -61,6 +62,8 @@ type MCPTool struct {
 	transport      mcp.Transport
 	availableTools []mcp.ToolInfo
 	builtinServer  *mcp.Server
+	roots          []mcp.Root
+	rootsEnabled   bool
 	mu             sync.Mutex
 	initialized    bool
 }
@@ -117,6 +120,65 @@ func WithMCPServer(server *mcp.Server) MCPToolOption {
 	}
 }
 
+// WithMCPServerConfig 使用 mcpServers 配置中的 stdio 服务器（命令、参数、环境变量和工作目录）
+func WithMCPServerConfig(config mcp.ServerConfig) MCPToolOption {
+	return func(t *MCPTool) {
+		stdio := config.StdioConfig()
+		t.serverCommand = append([]string{stdio.Command}, stdio.Args...)
+		t.serverDir = stdio.Dir
+		if t.serverEnv == nil {
+			t.serverEnv = make(map[string]string)
+		}
+		for k, v := range stdio.Env {
+			t.serverEnv[k] = v
+		}
+	}
+}
+
+// NewMCPToolsFromConfig 读取 mcpServers 格式的配置文件（如 claude_desktop_config.json），
+// 为每个未禁用的 stdio 服务器创建以服务器名命名的 MCPTool
+//
+// opts 应用于每个工具，在配置之后应用。MCPTool 只支持 stdio 服务器，
+// HTTP、WebSocket 服务器请使用 mcp.NewManagerFromConfig。
+//
+// 使用示例:
+//
+//	mcpTools, err := builtin.NewMCPToolsFromConfig("claude_desktop_config.json")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	for _, tool := range mcpTools {
+//	    registry.MustRegister(tool)
+//	}
+func NewMCPToolsFromConfig(path string, opts ...MCPToolOption) ([]*MCPTool, error) {
+	config, err := mcp.LoadServersConfig(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var mcpTools []*MCPTool
+	for _, name := range config.Names() {
+		server := config.MCPServers[name]
+		if server.TransportType() != mcp.ServerTypeStdio {
+			return nil, fmt.Errorf("server %s: MCPTool only supports stdio servers, got %s", name, server.TransportType())
+		}
+		toolOpts := append([]MCPToolOption{WithMCPName(name), WithMCPServerConfig(server)}, opts...)
+		mcpTools = append(mcpTools, NewMCPTool(toolOpts...))
+	}
+	return mcpTools, nil
+}
+
+// WithMCPRoots 设置允许服务器访问的根目录
+//
+// 文件系统类 MCP 服务器通过 roots/list 获取根目录，只在这些目录内操作。
+// 本地目录可以用 mcp.FileRoot 转换。
+func WithMCPRoots(roots ...mcp.Root) MCPToolOption {
+	return func(t *MCPTool) {
+		t.roots = roots
+		t.rootsEnabled = true
+	}
+}
+
 // NewMCPTool 创建 MCP 工具
 func NewMCPTool(opts ...MCPToolOption) *MCPTool {
 	t := &MCPTool{
@@ -267,6 +329,7 @@ func (t *MCPTool) ensureInitialized(ctx context.Context) error {
 			Command: t.serverCommand[0],
 			Args:    t.serverCommand[1:],
 			Env:     env,
+			Dir:     t.serverDir,
 		})
 		if err != nil {
 			return fmt.Errorf("创建传输失败: %w", err)
@@ -278,7 +341,11 @@ func (t *MCPTool) ensureInitialized(ctx context.Context) error {
 	}
 
 	t.transport = transport
-	t.client = mcp.NewClient(transport)
+	var clientOpts []mcp.ClientOption
+	if t.rootsEnabled {
+		clientOpts = append(clientOpts, mcp.WithRoots(t.roots...))
+	}
+	t.client = mcp.NewClient(transport, clientOpts...)
 
 	// 初始化连接
 	if err := t.client.Initialize(ctx); err != nil {
@@ -296,6 +363,29 @@ func (t *MCPTool) ensureInitialized(ctx context.Context) error {
 	return nil
 }
 
+// SetRoots 替换允许服务器访问的根目录
+//
+// 已连接时通知服务器根目录已变化；未连接时在连接时声明。
+func (t *MCPTool) SetRoots(ctx context.Context, roots ...mcp.Root) error {
+	t.mu.Lock()
+	t.roots = roots
+	t.rootsEnabled = true
+	client := t.client
+	t.mu.Unlock()
+
+	if client == nil {
+		return nil
+	}
+	return client.SetRoots(ctx, roots)
+}
+
+// Roots 返回允许服务器访问的根目录
+func (t *MCPTool) Roots() []mcp.Root {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]mcp.Root(nil), t.roots...)
+}
+
 // prepareEnv 准备环境变量
 func (t *MCPTool) prepareEnv() map[string]string {
 	env := make(map[string]string)
@@ -615,6 +705,17 @@ func (t *MCPTool) callTool(ctx context.Context, args map[string]interface{}) (st
 		arguments = make(map[string]interface{})
 	}
 
+	for _, info := range t.availableTools {
+		if info.Name == toolName {
+			coerced, err := prepareArguments(info, arguments)
+			if err != nil {
+				return "", err
+			}
+			arguments = coerced
+			break
+		}
+	}
+
 	result, err := t.client.CallTool(ctx, toolName, arguments)
 	if err != nil {
 		return "", err
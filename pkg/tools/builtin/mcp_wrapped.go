@@ -3,6 +3,7 @@ package builtin
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 
 	"github.com/ahhsitt/helloagents-go/pkg/protocols/mcp"
 	"github.com/ahhsitt/helloagents-go/pkg/tools"
@@ -38,47 +39,15 @@ func (t *MCPWrappedTool) Description() string {
 	return t.description
 }
 
-// Parameters 返回参数 Schema
+// Parameters 返回由 MCP 工具 InputSchema 转换的参数 Schema
 func (t *MCPWrappedTool) Parameters() tools.ParameterSchema {
-	schema := tools.ParameterSchema{
-		Type:       "object",
-		Properties: make(map[string]tools.PropertySchema),
-	}
-
-	// 从 InputSchema 转换
-	if t.toolInfo.InputSchema != nil {
-		if props, ok := t.toolInfo.InputSchema["properties"].(map[string]interface{}); ok {
-			for name, propRaw := range props {
-				if prop, ok := propRaw.(map[string]interface{}); ok {
-					ps := tools.PropertySchema{}
-					if t, ok := prop["type"].(string); ok {
-						ps.Type = t
-					}
-					if d, ok := prop["description"].(string); ok {
-						ps.Description = d
-					}
-					if e, ok := prop["enum"].([]interface{}); ok {
-						for _, v := range e {
-							if s, ok := v.(string); ok {
-								ps.Enum = append(ps.Enum, s)
-							}
-						}
-					}
-					schema.Properties[name] = ps
-				}
-			}
-		}
-
-		if req, ok := t.toolInfo.InputSchema["required"].([]interface{}); ok {
-			for _, r := range req {
-				if s, ok := r.(string); ok {
-					schema.Required = append(schema.Required, s)
-				}
-			}
-		}
-	}
+	return inputSchemaToParameters(t.toolInfo.InputSchema)
+}
 
-	return schema
+// Validate 按 InputSchema 规范化并校验参数，错误信息列出参数要求以便 Agent 修正调用
+func (t *MCPWrappedTool) Validate(args map[string]interface{}) error {
+	_, err := prepareArguments(t.toolInfo, args)
+	return err
 }
 
 // Execute 执行工具
@@ -92,10 +61,120 @@ func (t *MCPWrappedTool) Execute(ctx context.Context, args map[string]interface{
 }
 
 // 确保实现 Tool 接口
-var _ tools.Tool = (*MCPWrappedTool)(nil)
+var _ tools.ToolWithValidation = (*MCPWrappedTool)(nil)
 
 // 辅助函数
 
+// prepareArguments 按工具的 InputSchema 转换参数类型、填充默认值并校验
+//
+// 服务器端通常严格校验类型，LLM 生成的 "42" 之类的字符串数值会导致调用失败，
+// 因此在发送前按 Schema 规范化；仍不符合 Schema 时返回带参数要求的错误。
+func prepareArguments(info mcp.ToolInfo, args map[string]interface{}) (map[string]interface{}, error) {
+	if info.InputSchema == nil {
+		return args, nil
+	}
+	schema := inputSchemaToParameters(info.InputSchema)
+	coerced := tools.CoerceArguments(schema, args)
+	if err := tools.Validate(schema, coerced); err != nil {
+		return nil, fmt.Errorf("工具 '%s' 参数无效: %v。参数要求: %s", info.Name, err, tools.DescribeParameters(schema))
+	}
+	return coerced, nil
+}
+
+// inputSchemaToParameters 将 JSON Schema 格式的 InputSchema 转换为参数 Schema
+func inputSchemaToParameters(inputSchema map[string]interface{}) tools.ParameterSchema {
+	schema := tools.ParameterSchema{
+		Type:       "object",
+		Properties: make(map[string]tools.PropertySchema),
+		// JSON Schema 默认允许额外属性
+		AdditionalProperties: inputSchema["additionalProperties"] != false,
+	}
+	if props, ok := inputSchema["properties"].(map[string]interface{}); ok {
+		for name, raw := range props {
+			if prop, ok := raw.(map[string]interface{}); ok {
+				schema.Properties[name] = propertyFromJSON(prop)
+			}
+		}
+	}
+	schema.Required = stringList(inputSchema["required"])
+	return schema
+}
+
+// propertyFromJSON 将 JSON Schema 属性转换为属性 Schema
+func propertyFromJSON(prop map[string]interface{}) tools.PropertySchema {
+	ps := tools.PropertySchema{Default: prop["default"]}
+	switch typ := prop["type"].(type) {
+	case string:
+		ps.Type = typ
+	case []interface{}:
+		// ["string", "null"] 形式的可空类型取第一个非 null 类型
+		for _, v := range typ {
+			if s, ok := v.(string); ok && s != "null" {
+				ps.Type = s
+				break
+			}
+		}
+	}
+	if d, ok := prop["description"].(string); ok {
+		ps.Description = d
+	}
+	if e, ok := prop["enum"].([]interface{}); ok {
+		for _, v := range e {
+			ps.Enum = append(ps.Enum, fmt.Sprint(v))
+		}
+	}
+	if items, ok := prop["items"].(map[string]interface{}); ok {
+		item := propertyFromJSON(items)
+		ps.Items = &item
+	}
+	if props, ok := prop["properties"].(map[string]interface{}); ok {
+		ps.Properties = make(map[string]tools.PropertySchema, len(props))
+		for name, raw := range props {
+			if p, ok := raw.(map[string]interface{}); ok {
+				ps.Properties[name] = propertyFromJSON(p)
+			}
+		}
+		if ps.Type == "" {
+			ps.Type = "object"
+		}
+	}
+	ps.Required = stringList(prop["required"])
+	if v, ok := toFloat64(prop["minimum"]); ok {
+		ps.Minimum = &v
+	}
+	if v, ok := toFloat64(prop["maximum"]); ok {
+		ps.Maximum = &v
+	}
+	if v, ok := toFloat64(prop["minLength"]); ok {
+		n := int(v)
+		ps.MinLength = &n
+	}
+	if v, ok := toFloat64(prop["maxLength"]); ok {
+		n := int(v)
+		ps.MaxLength = &n
+	}
+	if p, ok := prop["pattern"].(string); ok {
+		ps.Pattern = p
+	}
+	return ps
+}
+
+// stringList 将 JSON 数组转换为字符串列表
+func stringList(v interface{}) []string {
+	var result []string
+	switch list := v.(type) {
+	case []interface{}:
+		for _, item := range list {
+			if s, ok := item.(string); ok {
+				result = append(result, s)
+			}
+		}
+	case []string:
+		result = append(result, list...)
+	}
+	return result
+}
+
 // toFloat64 将 interface{} 转换为 float64
 func toFloat64(v interface{}) (float64, bool) {
 	switch n := v.(type) {
@@ -147,7 +147,8 @@ func (n *NoteTool) Name() string {
 // Description 返回工具描述
 func (n *NoteTool) Description() string {
 	return "笔记工具 - 创建、读取、更新、删除结构化笔记，支持任务状态、结论、阻塞项等类型。" +
-		"操作类型: create(创建), read(读取), update(更新), delete(删除), list(列表), search(搜索), summary(摘要)"
+		"操作类型: create(创建), read(读取), update(更新), delete(删除), list(列表), search(搜索), summary(摘要), " +
+		"export(导出到 Markdown 目录或 JSONL), import(从归档导入)"
 }
 
 // Parameters 返回参数 Schema
@@ -158,8 +159,8 @@ func (n *NoteTool) Parameters() tools.ParameterSchema {
 			"action": {
 				Type: "string",
 				Description: "操作类型: create(创建), read(读取), update(更新), " +
-					"delete(删除), list(列表), search(搜索), summary(摘要)",
-				Enum: []string{"create", "read", "update", "delete", "list", "search", "summary"},
+					"delete(删除), list(列表), search(搜索), summary(摘要), export(导出), import(导入)",
+				Enum: []string{"create", "read", "update", "delete", "list", "search", "summary", "export", "import"},
 			},
 			"title": {
 				Type:        "string",
@@ -196,6 +197,27 @@ func (n *NoteTool) Parameters() tools.ParameterSchema {
 				Description: "返回结果数量限制（默认10）",
 				Default:     10,
 			},
+			"path": {
+				Type:        "string",
+				Description: "归档路径（export/import时必需）：markdown 格式为目录，jsonl 格式为文件",
+			},
+			"format": {
+				Type:        "string",
+				Description: "归档格式（export默认markdown，import默认按路径推断）",
+				Enum:        []string{"markdown", "jsonl"},
+			},
+			"conflict": {
+				Type: "string",
+				Description: "导入时ID或标题冲突的处理方式: skip(跳过), " +
+					"overwrite(覆盖), rename(以新ID导入)",
+				Enum:    []string{"skip", "overwrite", "rename"},
+				Default: "skip",
+			},
+			"dry_run": {
+				Type:        "boolean",
+				Description: "只预览导入结果，不写入（import时使用）",
+				Default:     false,
+			},
 		},
 		Required: []string{"action"},
 	}
@@ -223,6 +245,10 @@ func (n *NoteTool) Execute(ctx context.Context, args map[string]interface{}) (st
 		return n.searchNotes(args)
 	case "summary":
 		return n.getSummary()
+	case "export":
+		return n.exportNotes(args)
+	case "import":
+		return n.importNotes(args)
 	default:
 		return "", fmt.Errorf("不支持的操作: %s", action)
 	}
@@ -251,6 +277,10 @@ func (n *NoteTool) Validate(args map[string]interface{}) error {
 		if _, ok := args["query"].(string); !ok {
 			return fmt.Errorf("search 操作需要 query 参数")
 		}
+	case "export", "import":
+		if _, ok := args["path"].(string); !ok {
+			return fmt.Errorf("%s 操作需要 path 参数", action)
+		}
 	}
 
 	return nil
@@ -0,0 +1,493 @@
+package builtin
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// NoteArchiveFormat 笔记归档格式
+type NoteArchiveFormat string
+
+const (
+	// NoteArchiveMarkdown Markdown 目录（每条笔记一个带 frontmatter 的 .md 文件）
+	NoteArchiveMarkdown NoteArchiveFormat = "markdown"
+	// NoteArchiveJSONL JSONL 文件（每行一条笔记）
+	NoteArchiveJSONL NoteArchiveFormat = "jsonl"
+)
+
+// NoteConflictPolicy 导入时的冲突处理策略
+//
+// 归档中的笔记与已有笔记 ID 相同，或标题相同（忽略大小写）时视为冲突。
+type NoteConflictPolicy string
+
+const (
+	// NoteConflictSkip 跳过冲突的笔记（默认）
+	NoteConflictSkip NoteConflictPolicy = "skip"
+	// NoteConflictOverwrite 用归档内容覆盖已有笔记（保留已有笔记 ID）
+	NoteConflictOverwrite NoteConflictPolicy = "overwrite"
+	// NoteConflictRename 以新 ID 导入，保留两份笔记
+	NoteConflictRename NoteConflictPolicy = "rename"
+)
+
+// NoteImportAction 单条笔记的导入结果
+type NoteImportAction string
+
+const (
+	// NoteImportCreated 新建
+	NoteImportCreated NoteImportAction = "created"
+	// NoteImportOverwritten 覆盖已有笔记
+	NoteImportOverwritten NoteImportAction = "overwritten"
+	// NoteImportRenamed 以新 ID 导入
+	NoteImportRenamed NoteImportAction = "renamed"
+	// NoteImportSkipped 跳过
+	NoteImportSkipped NoteImportAction = "skipped"
+)
+
+// NoteImportEntry 单条笔记的导入记录
+type NoteImportEntry struct {
+	// SourceID 归档中的笔记 ID
+	SourceID string `json:"source_id"`
+	// ID 导入后的笔记 ID（跳过时为空）
+	ID string `json:"id,omitempty"`
+	// Title 笔记标题
+	Title string `json:"title"`
+	// Action 导入动作
+	Action NoteImportAction `json:"action"`
+	// ConflictWith 冲突的已有笔记 ID
+	ConflictWith string `json:"conflict_with,omitempty"`
+}
+
+// NoteImportResult 导入结果
+type NoteImportResult struct {
+	// DryRun 是否为预览（未实际写入）
+	DryRun bool `json:"dry_run"`
+	// Entries 每条笔记的导入记录
+	Entries []NoteImportEntry `json:"entries"`
+}
+
+// Count 返回指定动作的笔记数量
+func (r *NoteImportResult) Count(action NoteImportAction) int {
+	count := 0
+	for _, e := range r.Entries {
+		if e.Action == action {
+			count++
+		}
+	}
+	return count
+}
+
+// String 返回导入结果摘要
+func (r *NoteImportResult) String() string {
+	var sb strings.Builder
+	if r.DryRun {
+		sb.WriteString("🔍 导入预览（未写入）\n")
+	} else {
+		sb.WriteString("✅ 笔记导入完成\n")
+	}
+	sb.WriteString(fmt.Sprintf("新建: %d, 覆盖: %d, 重命名: %d, 跳过: %d\n",
+		r.Count(NoteImportCreated), r.Count(NoteImportOverwritten),
+		r.Count(NoteImportRenamed), r.Count(NoteImportSkipped)))
+
+	for _, e := range r.Entries {
+		sb.WriteString(fmt.Sprintf("• [%s] %s", e.Action, e.Title))
+		if e.ConflictWith != "" {
+			sb.WriteString(fmt.Sprintf("（与 %s 冲突）", e.ConflictWith))
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// NoteImportOption 配置笔记导入
+type NoteImportOption func(*noteImportOptions)
+
+type noteImportOptions struct {
+	format   NoteArchiveFormat
+	conflict NoteConflictPolicy
+	dryRun   bool
+}
+
+// WithImportFormat 指定归档格式（默认根据路径推断：目录为 markdown，文件为 jsonl）
+func WithImportFormat(format NoteArchiveFormat) NoteImportOption {
+	return func(o *noteImportOptions) {
+		o.format = format
+	}
+}
+
+// WithConflictPolicy 设置冲突处理策略
+func WithConflictPolicy(policy NoteConflictPolicy) NoteImportOption {
+	return func(o *noteImportOptions) {
+		o.conflict = policy
+	}
+}
+
+// WithDryRun 只预览导入结果，不写入任何笔记
+func WithDryRun(dryRun bool) NoteImportOption {
+	return func(o *noteImportOptions) {
+		o.dryRun = dryRun
+	}
+}
+
+// ExportNotes 将所有笔记导出到归档
+//
+// markdown 格式导出到 path 目录，每条笔记一个 <id>.md 文件；
+// jsonl 格式导出到 path 文件，每行一条笔记。返回导出的笔记数量。
+func (n *NoteTool) ExportNotes(path string, format NoteArchiveFormat) (int, error) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	notes := make([]*Note, 0, len(n.index.Notes))
+	for _, entry := range n.index.Notes {
+		note, err := n.loadNoteLocked(entry.ID)
+		if err != nil {
+			return 0, err
+		}
+		notes = append(notes, note)
+	}
+
+	switch format {
+	case NoteArchiveMarkdown:
+		if err := os.MkdirAll(path, 0755); err != nil {
+			return 0, fmt.Errorf("创建导出目录失败: %w", err)
+		}
+		for _, note := range notes {
+			notePath := filepath.Join(path, note.ID+".md")
+			if err := os.WriteFile(notePath, []byte(n.noteToMarkdown(note)), 0600); err != nil {
+				return 0, fmt.Errorf("导出笔记失败: %w", err)
+			}
+		}
+	case NoteArchiveJSONL:
+		if err := writeNotesJSONL(path, notes); err != nil {
+			return 0, fmt.Errorf("导出笔记失败: %w", err)
+		}
+	default:
+		return 0, fmt.Errorf("不支持的归档格式: %s", format)
+	}
+
+	return len(notes), nil
+}
+
+// ImportNotes 从归档导入笔记
+//
+// 先解析整个归档，任一笔记无效时不写入任何内容。
+// 冲突按 ID 或标题检测，处理方式由 WithConflictPolicy 决定。
+func (n *NoteTool) ImportNotes(path string, opts ...NoteImportOption) (*NoteImportResult, error) {
+	options := &noteImportOptions{
+		conflict: NoteConflictSkip,
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	if options.format == "" {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("读取归档失败: %w", err)
+		}
+		options.format = NoteArchiveJSONL
+		if info.IsDir() {
+			options.format = NoteArchiveMarkdown
+		}
+	}
+
+	var notes []*Note
+	var err error
+	switch options.format {
+	case NoteArchiveMarkdown:
+		notes, err = n.readNotesMarkdown(path)
+	case NoteArchiveJSONL:
+		notes, err = readNotesJSONL(path)
+	default:
+		return nil, fmt.Errorf("不支持的归档格式: %s", options.format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch options.conflict {
+	case NoteConflictSkip, NoteConflictOverwrite, NoteConflictRename:
+	default:
+		return nil, fmt.Errorf("不支持的冲突策略: %s", options.conflict)
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return n.importNotesLocked(notes, options)
+}
+
+// importNotesLocked 导入已解析的笔记（需要持有锁）
+//
+// 先生成完整的导入计划，校验通过后才写入文件和索引。
+func (n *NoteTool) importNotesLocked(notes []*Note, options *noteImportOptions) (*NoteImportResult, error) {
+	result := &NoteImportResult{DryRun: options.dryRun}
+
+	// 冲突检测索引，随计划更新，以处理归档内部的重复
+	known := make(map[string]bool, len(n.index.Notes))
+	byTitle := make(map[string]string, len(n.index.Notes))
+	for _, entry := range n.index.Notes {
+		known[entry.ID] = true
+		byTitle[strings.ToLower(entry.Title)] = entry.ID
+	}
+	count := len(n.index.Notes)
+	noteCount := n.noteCount
+
+	var planned []Note
+	for _, note := range notes {
+		entry := NoteImportEntry{SourceID: note.ID, Title: note.Title}
+
+		if note.ID != "" && known[note.ID] {
+			entry.ConflictWith = note.ID
+		} else if id, ok := byTitle[strings.ToLower(note.Title)]; ok {
+			entry.ConflictWith = id
+		}
+
+		entry.Action = NoteImportCreated
+		if entry.ConflictWith != "" {
+			switch options.conflict {
+			case NoteConflictOverwrite:
+				entry.Action = NoteImportOverwritten
+			case NoteConflictRename:
+				entry.Action = NoteImportRenamed
+			default:
+				entry.Action = NoteImportSkipped
+			}
+		}
+		result.Entries = append(result.Entries, entry)
+		if entry.Action == NoteImportSkipped {
+			continue
+		}
+
+		imported := *note
+		if entry.Action == NoteImportOverwritten {
+			imported.ID = entry.ConflictWith
+		} else {
+			if count >= n.maxNotes {
+				n.noteCount = noteCount
+				return nil, fmt.Errorf("笔记数量已达上限 (%d)", n.maxNotes)
+			}
+			count++
+			if imported.ID == "" || entry.Action == NoteImportRenamed {
+				imported.ID = n.generateNoteID()
+				for known[imported.ID] {
+					imported.ID = n.generateNoteID()
+				}
+			}
+		}
+		if imported.Type == "" {
+			imported.Type = NoteTypeGeneral
+		}
+		if imported.CreatedAt.IsZero() {
+			imported.CreatedAt = time.Now()
+		}
+		if imported.UpdatedAt.IsZero() {
+			imported.UpdatedAt = imported.CreatedAt
+		}
+
+		result.Entries[len(result.Entries)-1].ID = imported.ID
+		known[imported.ID] = true
+		byTitle[strings.ToLower(imported.Title)] = imported.ID
+		planned = append(planned, imported)
+	}
+
+	if options.dryRun {
+		// 预览不消耗 ID 序号
+		n.noteCount = noteCount
+		return result, nil
+	}
+
+	positions := make(map[string]int, len(n.index.Notes))
+	for i, entry := range n.index.Notes {
+		positions[entry.ID] = i
+	}
+	for i := range planned {
+		note := &planned[i]
+		if err := os.WriteFile(n.getNotePath(note.ID), []byte(n.noteToMarkdown(note)), 0600); err != nil {
+			return nil, fmt.Errorf("保存笔记失败: %w", err)
+		}
+
+		indexEntry := NoteIndexEntry{
+			ID:        note.ID,
+			Title:     note.Title,
+			Type:      note.Type,
+			Tags:      note.Tags,
+			CreatedAt: note.CreatedAt,
+		}
+		if pos, ok := positions[note.ID]; ok {
+			n.index.Notes[pos] = indexEntry
+		} else {
+			positions[note.ID] = len(n.index.Notes)
+			n.index.Notes = append(n.index.Notes, indexEntry)
+		}
+	}
+
+	if err := n.saveIndexLocked(); err != nil {
+		return nil, fmt.Errorf("更新索引失败: %w", err)
+	}
+	return result, nil
+}
+
+// loadNoteLocked 读取笔记文件（需要持有锁）
+func (n *NoteTool) loadNoteLocked(noteID string) (*Note, error) {
+	data, err := os.ReadFile(n.getNotePath(noteID))
+	if err != nil {
+		return nil, fmt.Errorf("读取笔记失败: %w", err)
+	}
+	return n.markdownToNote(string(data))
+}
+
+// readNotesMarkdown 读取 Markdown 目录中的笔记（按文件名排序）
+func (n *NoteTool) readNotesMarkdown(dir string) ([]*Note, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("读取归档失败: %w", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".md") {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+
+	notes := make([]*Note, 0, len(names))
+	for _, name := range names {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("读取归档失败: %w", err)
+		}
+		note, err := n.markdownToNote(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if err := validateArchivedNote(note); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		notes = append(notes, note)
+	}
+	return notes, nil
+}
+
+// readNotesJSONL 读取 JSONL 文件中的笔记
+func readNotesJSONL(path string) ([]*Note, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取归档失败: %w", err)
+	}
+	defer f.Close()
+
+	var notes []*Note
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		note := &Note{}
+		if err := json.Unmarshal([]byte(text), note); err != nil {
+			return nil, fmt.Errorf("第 %d 行解析失败: %w", line, err)
+		}
+		if err := validateArchivedNote(note); err != nil {
+			return nil, fmt.Errorf("第 %d 行: %w", line, err)
+		}
+		notes = append(notes, note)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取归档失败: %w", err)
+	}
+	return notes, nil
+}
+
+// writeNotesJSONL 将笔记写入 JSONL 文件
+func writeNotesJSONL(path string, notes []*Note) error {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	for _, note := range notes {
+		if err := enc.Encode(note); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// validateArchivedNote 校验归档中的笔记
+func validateArchivedNote(note *Note) error {
+	if note.Title == "" || note.Content == "" {
+		return fmt.Errorf("笔记缺少 title 或 content")
+	}
+	if strings.ContainsAny(note.ID, `/\`) || note.ID == "." || note.ID == ".." {
+		return fmt.Errorf("无效的笔记 ID: %s", note.ID)
+	}
+	return nil
+}
+
+// exportNotes 执行 export 操作
+func (n *NoteTool) exportNotes(args map[string]interface{}) (string, error) {
+	path, _ := args["path"].(string)
+	if path == "" {
+		return "", fmt.Errorf("导出笔记需要提供 path")
+	}
+
+	format := NoteArchiveMarkdown
+	if f, ok := args["format"].(string); ok && f != "" {
+		format = NoteArchiveFormat(f)
+	}
+
+	count, err := n.ExportNotes(path, format)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("✅ 已导出 %d 条笔记\n格式: %s\n路径: %s", count, format, path), nil
+}
+
+// importNotes 执行 import 操作
+func (n *NoteTool) importNotes(args map[string]interface{}) (string, error) {
+	path, _ := args["path"].(string)
+	if path == "" {
+		return "", fmt.Errorf("导入笔记需要提供 path")
+	}
+
+	var opts []NoteImportOption
+	if f, ok := args["format"].(string); ok && f != "" {
+		opts = append(opts, WithImportFormat(NoteArchiveFormat(f)))
+	}
+	if c, ok := args["conflict"].(string); ok && c != "" {
+		opts = append(opts, WithConflictPolicy(NoteConflictPolicy(c)))
+	}
+	if dryRun, ok := args["dry_run"].(bool); ok {
+		opts = append(opts, WithDryRun(dryRun))
+	}
+
+	result, err := n.ImportNotes(path, opts...)
+	if err != nil {
+		return "", err
+	}
+	return result.String(), nil
+}
@@ -0,0 +1,723 @@
+package builtin
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	agentctx "github.com/ahhsitt/helloagents-go/pkg/context"
+	"github.com/ahhsitt/helloagents-go/pkg/tools"
+)
+
+// SQLDialect 数据库方言
+type SQLDialect string
+
+const (
+	// SQLDialectPostgres PostgreSQL
+	SQLDialectPostgres SQLDialect = "postgres"
+	// SQLDialectMySQL MySQL / MariaDB
+	SQLDialectMySQL SQLDialect = "mysql"
+	// SQLDialectSQLite SQLite
+	SQLDialectSQLite SQLDialect = "sqlite"
+)
+
+// SQL 工具默认配置
+const (
+	DefaultSQLTimeout = 30 * time.Second
+	// DefaultSQLMaxRows 查询返回的最大行数
+	DefaultSQLMaxRows = 100
+	// DefaultSQLMaxBytes 查询结果读取的最大字节数
+	DefaultSQLMaxBytes = 64 * 1024
+	// DefaultSQLMaxTokens 渲染后结果表格的 token 上限
+	DefaultSQLMaxTokens = 2000
+	// maxSQLCellChars 单元格显示的最大字符数
+	maxSQLCellChars = 200
+)
+
+// sqlDefaultDrivers 各方言默认的 database/sql 驱动名
+var sqlDefaultDrivers = map[SQLDialect]string{
+	SQLDialectPostgres: "pgx",
+	SQLDialectMySQL:    "mysql",
+	SQLDialectSQLite:   "sqlite3",
+}
+
+// sqlActions SQL 工具支持的操作
+var sqlActions = []string{"list_tables", "describe_table", "query"}
+
+// SQLTool 数据库查询工具
+//
+// 连接 PostgreSQL、MySQL 或 SQLite，支持列出表、查看表结构和执行查询，
+// 结果渲染为 Markdown 表格，并按 token 预算裁剪行数。
+//
+// 安全特性：
+//   - 默认只读：只允许 SELECT/WITH/SHOW/EXPLAIN 等查询语句，拒绝包含写操作关键字的语句，
+//     并在只读事务（SQLite 使用 query_only）中执行
+//   - 每次只能执行一条语句
+//   - 行数、字节数和 token 预算限制
+//   - 查询超时控制
+//
+// 本包不引入 PostgreSQL 和 MySQL 驱动，使用方需要导入驱动，如
+// _ "github.com/jackc/pgx/v5/stdlib"（驱动名 pgx）或 _ "github.com/go-sql-driver/mysql"（驱动名 mysql）。
+//
+// 使用示例：
+//
+//	tool, err := builtin.NewSQLTool(builtin.SQLDialectSQLite, "./app.db",
+//	    builtin.WithSQLMaxRows(50),
+//	)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer tool.Close()
+type SQLTool struct {
+	// db 数据库连接池
+	db *sql.DB
+	// ownsDB 是否由工具创建（Close 时关闭）
+	ownsDB bool
+	// dialect 数据库方言
+	dialect SQLDialect
+	// driverName database/sql 驱动名
+	driverName string
+	// readOnly 是否只读
+	readOnly bool
+	// timeout 查询超时时间
+	timeout time.Duration
+	// maxRows 返回的最大行数
+	maxRows int
+	// maxBytes 读取的最大字节数
+	maxBytes int
+	// maxTokens 结果 token 上限
+	maxTokens int
+	// counter token 计数器
+	counter agentctx.TokenCounter
+}
+
+// SQLToolOption SQLTool 配置选项
+type SQLToolOption func(*SQLTool)
+
+// NewSQLTool 创建数据库查询工具并验证连接
+//
+// 未通过 WithSQLDB 提供连接池时，使用方言默认驱动（pgx、mysql、sqlite3）打开 dsn。
+func NewSQLTool(dialect SQLDialect, dsn string, opts ...SQLToolOption) (*SQLTool, error) {
+	driverName, ok := sqlDefaultDrivers[dialect]
+	if !ok {
+		return nil, fmt.Errorf("unsupported SQL dialect %q", dialect)
+	}
+
+	t := &SQLTool{
+		dialect:    dialect,
+		driverName: driverName,
+		readOnly:   true,
+		timeout:    DefaultSQLTimeout,
+		maxRows:    DefaultSQLMaxRows,
+		maxBytes:   DefaultSQLMaxBytes,
+		maxTokens:  DefaultSQLMaxTokens,
+		counter:    agentctx.NewEstimatedCounter(),
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	if t.db == nil {
+		db, err := sql.Open(t.driverName, dsn)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open database: %w", err)
+		}
+		t.db, t.ownsDB = db, true
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), t.timeout)
+	defer cancel()
+	if err := t.db.PingContext(ctx); err != nil {
+		t.Close()
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	return t, nil
+}
+
+// WithSQLDB 使用已有的连接池，Close 不会关闭它
+func WithSQLDB(db *sql.DB) SQLToolOption {
+	return func(t *SQLTool) {
+		t.db = db
+	}
+}
+
+// WithSQLDriverName 设置 database/sql 驱动名（如 PostgreSQL 使用 lib/pq 时为 "postgres"）
+func WithSQLDriverName(name string) SQLToolOption {
+	return func(t *SQLTool) {
+		if name != "" {
+			t.driverName = name
+		}
+	}
+}
+
+// WithSQLReadOnly 设置是否只读（默认 true）
+//
+// 关闭只读后 query 操作可以执行写语句，返回受影响的行数。
+func WithSQLReadOnly(readOnly bool) SQLToolOption {
+	return func(t *SQLTool) {
+		t.readOnly = readOnly
+	}
+}
+
+// WithSQLTimeout 设置查询超时时间（默认 DefaultSQLTimeout）
+func WithSQLTimeout(d time.Duration) SQLToolOption {
+	return func(t *SQLTool) {
+		t.timeout = d
+	}
+}
+
+// WithSQLMaxRows 设置返回的最大行数（默认 DefaultSQLMaxRows）
+func WithSQLMaxRows(n int) SQLToolOption {
+	return func(t *SQLTool) {
+		if n > 0 {
+			t.maxRows = n
+		}
+	}
+}
+
+// WithSQLMaxBytes 设置读取的最大字节数（默认 DefaultSQLMaxBytes）
+func WithSQLMaxBytes(n int) SQLToolOption {
+	return func(t *SQLTool) {
+		if n > 0 {
+			t.maxBytes = n
+		}
+	}
+}
+
+// WithSQLMaxTokens 设置结果表格的 token 上限（默认 DefaultSQLMaxTokens，0 表示不限制）
+func WithSQLMaxTokens(n int) SQLToolOption {
+	return func(t *SQLTool) {
+		if n >= 0 {
+			t.maxTokens = n
+		}
+	}
+}
+
+// WithSQLTokenCounter 设置 token 计数器（默认使用估算计数器）
+func WithSQLTokenCounter(counter agentctx.TokenCounter) SQLToolOption {
+	return func(t *SQLTool) {
+		if counter != nil {
+			t.counter = counter
+		}
+	}
+}
+
+// Close 关闭由工具创建的连接池
+func (t *SQLTool) Close() error {
+	if t.ownsDB && t.db != nil {
+		return t.db.Close()
+	}
+	return nil
+}
+
+// Name 返回工具名称
+func (t *SQLTool) Name() string {
+	return "sql"
+}
+
+// Description 返回工具描述
+func (t *SQLTool) Description() string {
+	desc := fmt.Sprintf("Query a %s database. Actions: list_tables, describe_table (columns of a table), "+
+		"query (run one SQL statement, results are returned as a Markdown table, at most %d rows).", t.dialect, t.maxRows)
+	if t.readOnly {
+		desc += " The connection is read-only: only SELECT, WITH, SHOW and EXPLAIN statements are allowed."
+	}
+	return desc
+}
+
+// Parameters 返回参数 Schema
+func (t *SQLTool) Parameters() tools.ParameterSchema {
+	return tools.ParameterSchema{
+		Type: "object",
+		Properties: map[string]tools.PropertySchema{
+			"action": {
+				Type:        "string",
+				Description: "The operation to perform",
+				Enum:        sqlActions,
+			},
+			"table": {
+				Type:        "string",
+				Description: "Table name, optionally schema-qualified (describe_table)",
+			},
+			"sql": {
+				Type:        "string",
+				Description: fmt.Sprintf("A single %s SQL statement (query)", t.dialect),
+			},
+		},
+		Required: []string{"action"},
+	}
+}
+
+// Validate 验证参数
+func (t *SQLTool) Validate(args map[string]interface{}) error {
+	action, _ := args["action"].(string)
+	switch action {
+	case "list_tables":
+	case "describe_table":
+		if table, _ := args["table"].(string); strings.TrimSpace(table) == "" {
+			return fmt.Errorf("describe_table requires table")
+		}
+	case "query":
+		query, _ := args["sql"].(string)
+		if strings.TrimSpace(query) == "" {
+			return fmt.Errorf("query requires sql")
+		}
+		if _, err := checkSQLStatement(t.dialect, query, t.readOnly); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unsupported action %q, use one of %s", action, strings.Join(sqlActions, ", "))
+	}
+	return nil
+}
+
+// Execute 执行数据库操作
+func (t *SQLTool) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
+	if err := t.Validate(args); err != nil {
+		return "", err
+	}
+	if t.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, t.timeout)
+		defer cancel()
+	}
+
+	switch args["action"].(string) {
+	case "list_tables":
+		return t.listTables(ctx)
+	case "describe_table":
+		return t.describeTable(ctx, strings.TrimSpace(args["table"].(string)))
+	default:
+		return t.query(ctx, args["sql"].(string))
+	}
+}
+
+// listTables 列出表和视图
+func (t *SQLTool) listTables(ctx context.Context) (string, error) {
+	var query string
+	switch t.dialect {
+	case SQLDialectPostgres:
+		query = `SELECT CASE WHEN table_schema = current_schema() THEN table_name ELSE table_schema || '.' || table_name END, table_type
+			FROM information_schema.tables
+			WHERE table_schema NOT IN ('pg_catalog', 'information_schema')
+			ORDER BY table_schema, table_name`
+	case SQLDialectMySQL:
+		query = `SELECT table_name, table_type FROM information_schema.tables
+			WHERE table_schema = DATABASE() ORDER BY table_name`
+	default:
+		query = `SELECT name, type FROM sqlite_master
+			WHERE type IN ('table', 'view') AND name NOT LIKE 'sqlite_%' ORDER BY name`
+	}
+
+	result, err := t.fetch(ctx, query)
+	if err != nil {
+		return "", fmt.Errorf("failed to list tables: %w", err)
+	}
+	if len(result.rows) == 0 {
+		return "No tables found", nil
+	}
+	result.columns = []string{"Table", "Type"}
+	return t.render(result), nil
+}
+
+// describeTable 返回表的列定义
+func (t *SQLTool) describeTable(ctx context.Context, table string) (string, error) {
+	schema, name := "", table
+	if i := strings.LastIndexByte(table, '.'); i >= 0 {
+		schema, name = table[:i], table[i+1:]
+	}
+
+	var query string
+	var args []interface{}
+	switch t.dialect {
+	case SQLDialectPostgres:
+		query = `SELECT column_name, data_type, is_nullable, COALESCE(column_default, '')
+			FROM information_schema.columns
+			WHERE table_schema = COALESCE(NULLIF($1, ''), current_schema()) AND table_name = $2
+			ORDER BY ordinal_position`
+		args = []interface{}{schema, name}
+	case SQLDialectMySQL:
+		query = `SELECT column_name, column_type, is_nullable, COALESCE(column_default, ''), column_key
+			FROM information_schema.columns
+			WHERE table_schema = COALESCE(NULLIF(?, ''), DATABASE()) AND table_name = ?
+			ORDER BY ordinal_position`
+		args = []interface{}{schema, name}
+	default:
+		query = `SELECT name, type, CASE WHEN "notnull" = 1 THEN 'NO' ELSE 'YES' END, COALESCE(dflt_value, ''),
+			CASE WHEN pk > 0 THEN 'PRI' ELSE '' END
+			FROM pragma_table_info(?)`
+		args = []interface{}{name}
+	}
+
+	result, err := t.fetch(ctx, query, args...)
+	if err != nil {
+		return "", fmt.Errorf("failed to describe table %s: %w", table, err)
+	}
+	if len(result.rows) == 0 {
+		return "", fmt.Errorf("table %s not found", table)
+	}
+	result.columns = []string{"Column", "Type", "Nullable", "Default", "Key"}[:len(result.columns)]
+	return fmt.Sprintf("Table %s:\n\n%s", table, t.render(result)), nil
+}
+
+// query 执行 SQL 语句
+func (t *SQLTool) query(ctx context.Context, query string) (string, error) {
+	returnsRows, err := checkSQLStatement(t.dialect, query, t.readOnly)
+	if err != nil {
+		return "", err
+	}
+
+	if !returnsRows {
+		res, err := t.db.ExecContext(ctx, query)
+		if err != nil {
+			return "", fmt.Errorf("query failed: %w", err)
+		}
+		if n, err := res.RowsAffected(); err == nil {
+			return fmt.Sprintf("Statement executed, %d row(s) affected", n), nil
+		}
+		return "Statement executed", nil
+	}
+
+	var result *sqlResult
+	if t.readOnly {
+		result, err = t.fetchReadOnly(ctx, query)
+	} else {
+		result, err = t.fetch(ctx, query)
+	}
+	if err != nil {
+		return "", fmt.Errorf("query failed: %w", err)
+	}
+	if len(result.rows) == 0 {
+		if len(result.columns) == 0 {
+			return "Statement executed", nil
+		}
+		return "Query returned no rows (columns: " + strings.Join(result.columns, ", ") + ")", nil
+	}
+	return t.render(result), nil
+}
+
+// sqlQueryer 可执行查询的对象（*sql.DB、*sql.Conn、*sql.Tx）
+type sqlQueryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// fetchReadOnly 在只读事务中执行查询，作为语句检查之外的第二道防线
+func (t *SQLTool) fetchReadOnly(ctx context.Context, query string) (*sqlResult, error) {
+	// go-sqlite3 忽略只读事务选项，改为在连接上开启 query_only
+	if t.dialect == SQLDialectSQLite {
+		conn, err := t.db.Conn(ctx)
+		if err != nil {
+			return nil, err
+		}
+		defer conn.Close()
+		if _, err := conn.ExecContext(ctx, "PRAGMA query_only = ON"); err != nil {
+			return nil, err
+		}
+		defer conn.ExecContext(context.Background(), "PRAGMA query_only = OFF")
+		return t.fetchFrom(ctx, conn, query)
+	}
+
+	tx, err := t.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	return t.fetchFrom(ctx, tx, query)
+}
+
+// fetch 在连接池上执行查询
+func (t *SQLTool) fetch(ctx context.Context, query string, args ...interface{}) (*sqlResult, error) {
+	return t.fetchFrom(ctx, t.db, query, args...)
+}
+
+// sqlResult 查询结果
+type sqlResult struct {
+	// columns 列名
+	columns []string
+	// rows 已格式化的行
+	rows [][]string
+	// truncated 因行数或字节数限制未读取完的原因
+	truncated string
+}
+
+// fetchFrom 执行查询并读取结果，超过行数或字节数限制时停止读取
+func (t *SQLTool) fetchFrom(ctx context.Context, q sqlQueryer, query string, args ...interface{}) (*sqlResult, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	result := &sqlResult{columns: columns}
+
+	values := make([]interface{}, len(columns))
+	pointers := make([]interface{}, len(columns))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+
+	size := 0
+	for rows.Next() {
+		if len(result.rows) >= t.maxRows {
+			result.truncated = fmt.Sprintf("row limit %d", t.maxRows)
+			break
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, err
+		}
+		row := make([]string, len(values))
+		for i, v := range values {
+			row[i] = formatSQLValue(v)
+			size += len(row[i])
+		}
+		if size > t.maxBytes && len(result.rows) > 0 {
+			result.truncated = fmt.Sprintf("byte limit %d", t.maxBytes)
+			break
+		}
+		result.rows = append(result.rows, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// render 渲染为 Markdown 表格，超出 token 预算时减少行数
+func (t *SQLTool) render(result *sqlResult) string {
+	total := len(result.rows)
+	table := markdownTable(result.columns, result.rows)
+	shown := total
+
+	if t.maxTokens > 0 && t.counter.Count(table) > t.maxTokens {
+		// 二分查找预算内能容纳的最大行数（至少保留一行）
+		lo, hi := 1, total
+		for lo < hi {
+			mid := (lo + hi + 1) / 2
+			if t.counter.Count(markdownTable(result.columns, result.rows[:mid])) <= t.maxTokens {
+				lo = mid
+			} else {
+				hi = mid - 1
+			}
+		}
+		shown = lo
+		table = markdownTable(result.columns, result.rows[:shown])
+	}
+
+	switch {
+	case shown < total:
+		return table + fmt.Sprintf("\n(showing %d of %d rows, truncated to fit the token budget)", shown, total)
+	case result.truncated != "":
+		return table + fmt.Sprintf("\n(%d rows shown, more rows omitted by %s)", shown, result.truncated)
+	default:
+		return table + fmt.Sprintf("\n(%d rows)", total)
+	}
+}
+
+// markdownTable 渲染 Markdown 表格
+func markdownTable(columns []string, rows [][]string) string {
+	var sb strings.Builder
+	sb.WriteString("| " + strings.Join(escapeMarkdownCells(columns), " | ") + " |\n")
+	sb.WriteString("|" + strings.Repeat(" --- |", len(columns)) + "\n")
+	for _, row := range rows {
+		sb.WriteString("| " + strings.Join(escapeMarkdownCells(row), " | ") + " |\n")
+	}
+	return sb.String()
+}
+
+// escapeMarkdownCells 转义单元格中的竖线和换行，并截断过长的内容
+func escapeMarkdownCells(cells []string) []string {
+	escaped := make([]string, len(cells))
+	for i, c := range cells {
+		c = strings.NewReplacer("|", `\|`, "\r\n", " ", "\n", " ", "\r", " ").Replace(c)
+		escaped[i] = truncateRunes(c, maxSQLCellChars)
+	}
+	return escaped
+}
+
+// formatSQLValue 将数据库值格式化为字符串
+func formatSQLValue(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return "NULL"
+	case []byte:
+		if utf8.Valid(val) {
+			return string(val)
+		}
+		return fmt.Sprintf("<%d bytes>", len(val))
+	case time.Time:
+		return val.Format(time.RFC3339)
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+// sqlReadKeywords 只读语句允许的起始关键字
+var sqlReadKeywords = map[string]bool{
+	"SELECT": true, "WITH": true, "SHOW": true, "EXPLAIN": true,
+	"DESCRIBE": true, "DESC": true, "VALUES": true, "TABLE": true,
+}
+
+// sqlWriteKeywords 只读模式下禁止出现的关键字（作为函数调用时除外，如 replace()）
+var sqlWriteKeywords = map[string]bool{
+	"INSERT": true, "UPDATE": true, "DELETE": true, "MERGE": true, "UPSERT": true, "REPLACE": true,
+	"CREATE": true, "ALTER": true, "DROP": true, "TRUNCATE": true, "RENAME": true,
+	"GRANT": true, "REVOKE": true, "ATTACH": true, "DETACH": true, "VACUUM": true, "REINDEX": true,
+	"COPY": true, "CALL": true, "EXEC": true, "EXECUTE": true, "DO": true, "LOCK": true,
+	"INTO": true, "SET": true, "PRAGMA": true, "LOAD": true, "HANDLER": true,
+}
+
+// checkSQLStatement 检查语句，返回是否为返回结果集的查询语句
+//
+// 忽略注释和字符串字面量后按关键字判断：只允许单条语句；只读模式下必须以查询关键字开头，
+// 且不能包含写操作关键字。
+func checkSQLStatement(dialect SQLDialect, query string, readOnly bool) (bool, error) {
+	words, statements := sqlKeywords(dialect, query)
+	if len(words) == 0 {
+		return false, fmt.Errorf("empty SQL statement")
+	}
+	if statements > 1 {
+		return false, fmt.Errorf("only one SQL statement can be executed at a time")
+	}
+
+	isRead := sqlReadKeywords[words[0].word]
+	if !readOnly {
+		return isRead, nil
+	}
+	if !isRead {
+		return false, fmt.Errorf("%s statements are not allowed in read-only mode", words[0].word)
+	}
+	for _, w := range words {
+		if sqlWriteKeywords[w.word] && !w.call {
+			return false, fmt.Errorf("%s is not allowed in read-only mode", w.word)
+		}
+	}
+	return true, nil
+}
+
+// sqlWord SQL 中的关键字或标识符
+type sqlWord struct {
+	// word 大写形式
+	word string
+	// call 后面紧跟左括号（函数调用）
+	call bool
+}
+
+// sqlKeywords 提取语句中未加引号的单词，跳过注释、字符串和引号标识符，并统计语句数
+//
+// 注释和转义规则因方言而异：# 注释和字符串中的反斜杠转义只适用于 MySQL
+// （PostgreSQL 只在 E'...' 字符串中支持反斜杠转义），解析错误可能导致写语句被漏判。
+func sqlKeywords(dialect SQLDialect, query string) ([]sqlWord, int) {
+	var words []sqlWord
+	statements := 0
+	pending := false // 当前语句是否有内容
+
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == '-' && strings.HasPrefix(query[i:], "--"), c == '#' && dialect == SQLDialectMySQL:
+			for i < len(query) && query[i] != '\n' {
+				i++
+			}
+		case c == '/' && strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				i = len(query)
+			} else {
+				i += end + 4
+			}
+		case c == '\'' || c == '"' || c == '`':
+			// 引号内容，连续两个引号表示转义
+			backslash := c != '`' && dialect == SQLDialectMySQL ||
+				c == '\'' && dialect == SQLDialectPostgres && i > 0 && (query[i-1] == 'E' || query[i-1] == 'e')
+			i++
+			for i < len(query) {
+				if query[i] == '\\' && backslash {
+					i += 2
+					continue
+				}
+				if query[i] == c {
+					if i+1 < len(query) && query[i+1] == c {
+						i += 2
+						continue
+					}
+					break
+				}
+				i++
+			}
+			i++
+			pending = true
+		case c == '$':
+			// PostgreSQL 美元引号字符串 $tag$...$tag$
+			end := strings.IndexByte(query[i+1:], '$')
+			tag := ""
+			if end >= 0 {
+				tag = query[i : i+end+2]
+			}
+			if tag != "" && isSQLTag(tag[1:len(tag)-1]) {
+				closing := strings.Index(query[i+len(tag):], tag)
+				if closing < 0 {
+					i = len(query)
+				} else {
+					i += len(tag) + closing + len(tag)
+				}
+			} else {
+				i++
+			}
+			pending = true
+		case c == ';':
+			if pending {
+				statements++
+			}
+			pending = false
+			i++
+		case isSQLWordByte(c) && !(c >= '0' && c <= '9'):
+			start := i
+			for i < len(query) && isSQLWordByte(query[i]) {
+				i++
+			}
+			j := i
+			for j < len(query) && (query[j] == ' ' || query[j] == '\t' || query[j] == '\n' || query[j] == '\r') {
+				j++
+			}
+			words = append(words, sqlWord{
+				word: strings.ToUpper(query[start:i]),
+				call: j < len(query) && query[j] == '(',
+			})
+			pending = true
+		default:
+			if c != ' ' && c != '\t' && c != '\n' && c != '\r' {
+				pending = true
+			}
+			i++
+		}
+	}
+	if pending {
+		statements++
+	}
+	return words, statements
+}
+
+// isSQLWordByte 判断是否为标识符字符
+func isSQLWordByte(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80
+}
+
+// isSQLTag 判断美元引号标签是否合法（空标签或标识符）
+func isSQLTag(tag string) bool {
+	for i := 0; i < len(tag); i++ {
+		if !isSQLWordByte(tag[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// compile-time interface check
+var _ tools.ToolWithValidation = (*SQLTool)(nil)
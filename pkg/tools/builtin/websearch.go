@@ -0,0 +1,328 @@
+package builtin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ahhsitt/helloagents-go/pkg/rag"
+	"github.com/ahhsitt/helloagents-go/pkg/tools"
+)
+
+// 网页搜索工具默认配置
+const (
+	DefaultSearchMaxResults = 5
+	// DefaultSearchContentLimit 每个抓取页面保留的正文字符数
+	DefaultSearchContentLimit = 2000
+	// DefaultSearchFetchTimeout 抓取单个页面的超时时间
+	DefaultSearchFetchTimeout = 10 * time.Second
+	// maxSearchResults Agent 可请求的最大结果数
+	maxSearchResults = 20
+	// maxFetchPageSize 抓取页面的大小上限
+	maxFetchPageSize = 2 * 1024 * 1024
+)
+
+// SearchResult 搜索结果
+type SearchResult struct {
+	// Rank 排名，从 1 开始
+	Rank int `json:"rank"`
+	// Title 标题
+	Title string `json:"title"`
+	// URL 页面地址
+	URL string `json:"url"`
+	// Snippet 摘要
+	Snippet string `json:"snippet"`
+	// Content 抓取页面后提取的正文（启用页面抓取时）
+	Content string `json:"content,omitempty"`
+}
+
+// SearchProvider 搜索服务提供商
+//
+// 内置 SerpAPI、Tavily、Bing 和 DuckDuckGo 实现，实现此接口即可接入其他搜索服务。
+type SearchProvider interface {
+	// Name 返回提供商名称
+	Name() string
+	// Search 搜索 query，最多返回 maxResults 个按相关性排序的结果
+	Search(ctx context.Context, query string, maxResults int) ([]SearchResult, error)
+}
+
+// WebSearch 网页搜索工具
+//
+// 通过可替换的 SearchProvider 搜索网页，返回带标题、摘要和地址的排序结果；
+// 可选抓取排名靠前的页面并提取正文，让 Agent 无需再次调用工具即可阅读内容。
+//
+// 使用示例：
+//
+//	provider, err := builtin.NewTavilyProvider(os.Getenv("TAVILY_API_KEY"))
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	search := builtin.NewWebSearch(provider, builtin.WithSearchFetchTop(2))
+//
+//	// 无需 API 密钥
+//	search := builtin.NewWebSearch(builtin.NewDuckDuckGoProvider())
+type WebSearch struct {
+	// provider 搜索服务提供商
+	provider SearchProvider
+	// maxResults 默认结果数
+	maxResults int
+	// fetchTop 抓取正文的结果数（0 表示不抓取）
+	fetchTop int
+	// contentLimit 每个页面保留的正文字符数
+	contentLimit int
+	// fetchTimeout 抓取单个页面的超时时间
+	fetchTimeout time.Duration
+	// httpClient 抓取页面使用的 HTTP 客户端
+	httpClient *http.Client
+}
+
+// WebSearchOption WebSearch 配置选项
+type WebSearchOption func(*WebSearch)
+
+// NewWebSearch 创建网页搜索工具
+func NewWebSearch(provider SearchProvider, opts ...WebSearchOption) *WebSearch {
+	s := &WebSearch{
+		provider:     provider,
+		maxResults:   DefaultSearchMaxResults,
+		contentLimit: DefaultSearchContentLimit,
+		fetchTimeout: DefaultSearchFetchTimeout,
+		httpClient:   http.DefaultClient,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// WithSearchMaxResults 设置默认返回的结果数（默认 DefaultSearchMaxResults）
+func WithSearchMaxResults(n int) WebSearchOption {
+	return func(s *WebSearch) {
+		if n > 0 {
+			s.maxResults = n
+		}
+	}
+}
+
+// WithSearchFetchTop 抓取排名前 n 的页面并提取正文（默认不抓取）
+func WithSearchFetchTop(n int) WebSearchOption {
+	return func(s *WebSearch) {
+		if n >= 0 {
+			s.fetchTop = n
+		}
+	}
+}
+
+// WithSearchContentLimit 设置每个抓取页面保留的正文字符数（默认 DefaultSearchContentLimit）
+func WithSearchContentLimit(chars int) WebSearchOption {
+	return func(s *WebSearch) {
+		if chars > 0 {
+			s.contentLimit = chars
+		}
+	}
+}
+
+// WithSearchFetchTimeout 设置抓取单个页面的超时时间（默认 DefaultSearchFetchTimeout）
+func WithSearchFetchTimeout(d time.Duration) WebSearchOption {
+	return func(s *WebSearch) {
+		s.fetchTimeout = d
+	}
+}
+
+// WithSearchHTTPClient 设置抓取页面使用的 HTTP 客户端
+func WithSearchHTTPClient(client *http.Client) WebSearchOption {
+	return func(s *WebSearch) {
+		if client != nil {
+			s.httpClient = client
+		}
+	}
+}
+
+// Name 返回工具名称
+func (s *WebSearch) Name() string {
+	return "web_search"
+}
+
+// Description 返回工具描述
+func (s *WebSearch) Description() string {
+	desc := "Search the web and return ranked results with titles, URLs and snippets."
+	if s.fetchTop > 0 {
+		desc += fmt.Sprintf(" The main text of the top %d pages is included.", s.fetchTop)
+	}
+	return desc
+}
+
+// Parameters 返回参数 Schema
+func (s *WebSearch) Parameters() tools.ParameterSchema {
+	maxResults := float64(maxSearchResults)
+	minResults := float64(1)
+	return tools.ParameterSchema{
+		Type: "object",
+		Properties: map[string]tools.PropertySchema{
+			"query": {
+				Type:        "string",
+				Description: "The search query",
+			},
+			"max_results": {
+				Type:        "integer",
+				Description: "Number of results to return",
+				Default:     s.maxResults,
+				Minimum:     &minResults,
+				Maximum:     &maxResults,
+			},
+		},
+		Required: []string{"query"},
+	}
+}
+
+// Execute 执行搜索
+func (s *WebSearch) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
+	query, _ := args["query"].(string)
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return "", fmt.Errorf("missing required parameter: query")
+	}
+
+	maxResults := s.maxResults
+	if n, ok := toFloat64(args["max_results"]); ok && n >= 1 {
+		maxResults = int(n)
+	}
+	if maxResults > maxSearchResults {
+		maxResults = maxSearchResults
+	}
+
+	results, err := s.Search(ctx, query, maxResults)
+	if err != nil {
+		return "", err
+	}
+	return formatSearchResults(query, s.provider.Name(), results), nil
+}
+
+// Search 搜索并按配置抓取排名靠前页面的正文
+func (s *WebSearch) Search(ctx context.Context, query string, maxResults int) ([]SearchResult, error) {
+	raw, err := s.provider.Search(ctx, query, maxResults)
+	if err != nil {
+		return nil, fmt.Errorf("%s search failed: %w", s.provider.Name(), err)
+	}
+
+	// 去掉重复地址，重新编号
+	seen := make(map[string]bool, len(raw))
+	results := make([]SearchResult, 0, len(raw))
+	for _, r := range raw {
+		if r.URL == "" || seen[r.URL] {
+			continue
+		}
+		seen[r.URL] = true
+		r.Rank = len(results) + 1
+		results = append(results, r)
+		if len(results) == maxResults {
+			break
+		}
+	}
+
+	s.fetchContents(ctx, results)
+	return results, nil
+}
+
+// fetchContents 并发抓取前 fetchTop 个结果的页面正文，失败的页面保持为空
+func (s *WebSearch) fetchContents(ctx context.Context, results []SearchResult) {
+	n := s.fetchTop
+	if n > len(results) {
+		n = len(results)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(r *SearchResult) {
+			defer wg.Done()
+			content, err := s.fetchPage(ctx, r.URL)
+			if err == nil {
+				r.Content = truncateRunes(content, s.contentLimit)
+			}
+		}(&results[i])
+	}
+	wg.Wait()
+}
+
+// fetchPage 抓取页面并提取正文，HTML 页面去除导航、脚本等样板内容
+func (s *WebSearch) fetchPage(ctx context.Context, pageURL string) (string, error) {
+	if s.fetchTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.fetchTimeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("User-Agent", searchUserAgent)
+	req.Header.Set("Accept", "text/html,text/plain;q=0.9")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+
+	body := io.LimitReader(resp.Body, maxFetchPageSize)
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	switch {
+	case mediaType == "text/html" || mediaType == "application/xhtml+xml" || mediaType == "":
+		docs, err := rag.NewHTMLLoader(pageURL, body).Load(ctx)
+		if err != nil || len(docs) == 0 {
+			return "", fmt.Errorf("failed to extract page text: %w", err)
+		}
+		return docs[0].Content, nil
+	case strings.HasPrefix(mediaType, "text/"):
+		data, err := io.ReadAll(body)
+		return strings.TrimSpace(string(data)), err
+	default:
+		return "", fmt.Errorf("unsupported content type %s", mediaType)
+	}
+}
+
+// formatSearchResults 格式化搜索结果
+func formatSearchResults(query, provider string, results []SearchResult) string {
+	if len(results) == 0 {
+		return fmt.Sprintf("No results found for %q", query)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Search results for %q (%s):\n", query, provider))
+	for _, r := range results {
+		sb.WriteString(fmt.Sprintf("\n%d. %s\n   URL: %s\n", r.Rank, r.Title, r.URL))
+		if r.Snippet != "" {
+			sb.WriteString(fmt.Sprintf("   %s\n", r.Snippet))
+		}
+		if r.Content != "" {
+			sb.WriteString("   Content:\n")
+			for _, line := range strings.Split(r.Content, "\n") {
+				if line != "" {
+					sb.WriteString("   " + line + "\n")
+				}
+			}
+		}
+	}
+	return sb.String()
+}
+
+// truncateRunes 将文本截断到 limit 个字符以内
+func truncateRunes(s string, limit int) string {
+	runes := []rune(s)
+	if limit <= 0 || len(runes) <= limit {
+		return s
+	}
+	return string(runes[:limit]) + "..."
+}
+
+// compile-time interface check
+var _ tools.Tool = (*WebSearch)(nil)
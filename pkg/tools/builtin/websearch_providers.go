@@ -0,0 +1,405 @@
+package builtin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// 搜索服务默认地址
+const (
+	defaultSerpAPIURL    = "https://serpapi.com/search.json"
+	defaultTavilyURL     = "https://api.tavily.com/search"
+	defaultBingURL       = "https://api.bing.microsoft.com/v7.0/search"
+	defaultDuckDuckGoURL = "https://html.duckduckgo.com/html/"
+)
+
+// searchUserAgent 搜索和抓取页面使用的 User-Agent
+const searchUserAgent = "Mozilla/5.0 (compatible; HelloAgents/1.0)"
+
+// ErrSearchAPIKeyRequired 搜索服务缺少 API 密钥
+var ErrSearchAPIKeyRequired = errors.New("search API key is required")
+
+// SearchProviderOption 搜索服务提供商配置选项
+type SearchProviderOption func(*searchProviderConfig)
+
+// searchProviderConfig 搜索服务提供商的通用配置
+type searchProviderConfig struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// WithSearchBaseURL 设置搜索服务地址（用于代理或自托管兼容服务）
+func WithSearchBaseURL(baseURL string) SearchProviderOption {
+	return func(c *searchProviderConfig) {
+		if baseURL != "" {
+			c.baseURL = baseURL
+		}
+	}
+}
+
+// WithSearchProviderHTTPClient 设置调用搜索服务的 HTTP 客户端
+func WithSearchProviderHTTPClient(client *http.Client) SearchProviderOption {
+	return func(c *searchProviderConfig) {
+		if client != nil {
+			c.httpClient = client
+		}
+	}
+}
+
+// newSearchProviderConfig 应用选项，创建提供商配置
+func newSearchProviderConfig(baseURL string, opts []SearchProviderOption) searchProviderConfig {
+	c := searchProviderConfig{baseURL: baseURL, httpClient: &http.Client{Timeout: DefaultHTTPTimeout}}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
+
+// do 发送请求，状态码不是 200 时返回包含响应内容的错误
+func (c searchProviderConfig) do(req *http.Request) ([]byte, error) {
+	if req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", searchUserAgent)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxFetchPageSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, truncateRunes(strings.TrimSpace(string(data)), 200))
+	}
+	return data, nil
+}
+
+// SerpAPIProvider SerpAPI（Google 搜索结果）提供商
+type SerpAPIProvider struct {
+	apiKey string
+	engine string
+	config searchProviderConfig
+}
+
+// NewSerpAPIProvider 创建 SerpAPI 提供商，默认使用 Google 搜索引擎
+func NewSerpAPIProvider(apiKey string, opts ...SearchProviderOption) (*SerpAPIProvider, error) {
+	if apiKey == "" {
+		return nil, ErrSearchAPIKeyRequired
+	}
+	return &SerpAPIProvider{
+		apiKey: apiKey,
+		engine: "google",
+		config: newSearchProviderConfig(defaultSerpAPIURL, opts),
+	}, nil
+}
+
+// Name 返回提供商名称
+func (p *SerpAPIProvider) Name() string {
+	return "serpapi"
+}
+
+// Search 搜索
+func (p *SerpAPIProvider) Search(ctx context.Context, query string, maxResults int) ([]SearchResult, error) {
+	params := url.Values{
+		"engine":  {p.engine},
+		"q":       {query},
+		"num":     {strconv.Itoa(maxResults)},
+		"api_key": {p.apiKey},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.config.baseURL+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	data, err := p.config.do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Error          string `json:"error"`
+		OrganicResults []struct {
+			Title   string `json:"title"`
+			Link    string `json:"link"`
+			Snippet string `json:"snippet"`
+		} `json:"organic_results"`
+	}
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	if resp.Error != "" {
+		return nil, errors.New(resp.Error)
+	}
+
+	results := make([]SearchResult, 0, len(resp.OrganicResults))
+	for _, r := range resp.OrganicResults {
+		results = append(results, SearchResult{Title: r.Title, URL: r.Link, Snippet: r.Snippet})
+	}
+	return results, nil
+}
+
+// TavilyProvider Tavily 搜索提供商（面向 LLM 优化的搜索 API）
+type TavilyProvider struct {
+	apiKey string
+	config searchProviderConfig
+}
+
+// NewTavilyProvider 创建 Tavily 提供商
+func NewTavilyProvider(apiKey string, opts ...SearchProviderOption) (*TavilyProvider, error) {
+	if apiKey == "" {
+		return nil, ErrSearchAPIKeyRequired
+	}
+	return &TavilyProvider{
+		apiKey: apiKey,
+		config: newSearchProviderConfig(defaultTavilyURL, opts),
+	}, nil
+}
+
+// Name 返回提供商名称
+func (p *TavilyProvider) Name() string {
+	return "tavily"
+}
+
+// Search 搜索
+func (p *TavilyProvider) Search(ctx context.Context, query string, maxResults int) ([]SearchResult, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"query":       query,
+		"max_results": maxResults,
+	})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.config.baseURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	data, err := p.config.do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Results []struct {
+			Title   string `json:"title"`
+			URL     string `json:"url"`
+			Content string `json:"content"`
+		} `json:"results"`
+	}
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	results := make([]SearchResult, 0, len(resp.Results))
+	for _, r := range resp.Results {
+		results = append(results, SearchResult{Title: r.Title, URL: r.URL, Snippet: r.Content})
+	}
+	return results, nil
+}
+
+// BingProvider Bing Web Search API 提供商
+type BingProvider struct {
+	apiKey string
+	config searchProviderConfig
+}
+
+// NewBingProvider 创建 Bing 提供商
+func NewBingProvider(apiKey string, opts ...SearchProviderOption) (*BingProvider, error) {
+	if apiKey == "" {
+		return nil, ErrSearchAPIKeyRequired
+	}
+	return &BingProvider{
+		apiKey: apiKey,
+		config: newSearchProviderConfig(defaultBingURL, opts),
+	}, nil
+}
+
+// Name 返回提供商名称
+func (p *BingProvider) Name() string {
+	return "bing"
+}
+
+// Search 搜索
+func (p *BingProvider) Search(ctx context.Context, query string, maxResults int) ([]SearchResult, error) {
+	params := url.Values{
+		"q":     {query},
+		"count": {strconv.Itoa(maxResults)},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.config.baseURL+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Ocp-Apim-Subscription-Key", p.apiKey)
+	data, err := p.config.do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		WebPages struct {
+			Value []struct {
+				Name    string `json:"name"`
+				URL     string `json:"url"`
+				Snippet string `json:"snippet"`
+			} `json:"value"`
+		} `json:"webPages"`
+	}
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	results := make([]SearchResult, 0, len(resp.WebPages.Value))
+	for _, r := range resp.WebPages.Value {
+		results = append(results, SearchResult{Title: r.Name, URL: r.URL, Snippet: r.Snippet})
+	}
+	return results, nil
+}
+
+// DuckDuckGoProvider DuckDuckGo 搜索提供商
+//
+// 解析 DuckDuckGo 的 HTML 结果页，无需 API 密钥。结果页结构变化时可能需要更新解析规则。
+type DuckDuckGoProvider struct {
+	config searchProviderConfig
+}
+
+// NewDuckDuckGoProvider 创建 DuckDuckGo 提供商
+func NewDuckDuckGoProvider(opts ...SearchProviderOption) *DuckDuckGoProvider {
+	return &DuckDuckGoProvider{
+		config: newSearchProviderConfig(defaultDuckDuckGoURL, opts),
+	}
+}
+
+// Name 返回提供商名称
+func (p *DuckDuckGoProvider) Name() string {
+	return "duckduckgo"
+}
+
+// Search 搜索
+func (p *DuckDuckGoProvider) Search(ctx context.Context, query string, maxResults int) ([]SearchResult, error) {
+	form := url.Values{"q": {query}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.config.baseURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	data, err := p.config.do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := html.Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse results page: %w", err)
+	}
+
+	var results []SearchResult
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if len(results) >= maxResults {
+			return
+		}
+		if n.Type == html.ElementNode && hasClass(n, "result") && !hasClass(n, "result--ad") {
+			var r SearchResult
+			if link := findByClass(n, "result__a"); link != nil {
+				r.Title = strings.Join(strings.Fields(htmlText(link)), " ")
+				r.URL = duckDuckGoTarget(htmlAttr(link, "href"))
+			}
+			if snippet := findByClass(n, "result__snippet"); snippet != nil {
+				r.Snippet = strings.Join(strings.Fields(htmlText(snippet)), " ")
+			}
+			if r.URL != "" {
+				results = append(results, r)
+			}
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(root)
+	return results, nil
+}
+
+// duckDuckGoTarget 还原 DuckDuckGo 跳转链接（//duckduckgo.com/l/?uddg=...）的真实地址
+func duckDuckGoTarget(href string) string {
+	u, err := url.Parse(href)
+	if err != nil {
+		return href
+	}
+	if target := u.Query().Get("uddg"); target != "" {
+		return target
+	}
+	if u.Scheme == "" && strings.HasPrefix(href, "//") {
+		return "https:" + href
+	}
+	return href
+}
+
+// hasClass 判断元素的 class 是否包含 name
+func hasClass(n *html.Node, name string) bool {
+	for _, class := range strings.Fields(htmlAttr(n, "class")) {
+		if class == name {
+			return true
+		}
+	}
+	return false
+}
+
+// findByClass 深度优先查找 class 包含 name 的元素
+func findByClass(n *html.Node, name string) *html.Node {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && hasClass(c, name) {
+			return c
+		}
+		if found := findByClass(c, name); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// htmlAttr 返回元素的属性值
+func htmlAttr(n *html.Node, key string) string {
+	for _, attr := range n.Attr {
+		if attr.Key == key {
+			return attr.Val
+		}
+	}
+	return ""
+}
+
+// htmlText 返回元素内的全部文本
+func htmlText(n *html.Node) string {
+	var sb strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			sb.WriteString(n.Data)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return sb.String()
+}
+
+// compile-time interface check
+var (
+	_ SearchProvider = (*SerpAPIProvider)(nil)
+	_ SearchProvider = (*TavilyProvider)(nil)
+	_ SearchProvider = (*BingProvider)(nil)
+	_ SearchProvider = (*DuckDuckGoProvider)(nil)
+)
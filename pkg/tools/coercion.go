@@ -0,0 +1,139 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// CoerceArguments 按 Schema 规范化参数，返回新的参数表，不修改 args
+//
+// LLM 生成的参数常与 Schema 的类型不完全一致，转换规则：
+//   - 缺失或为 null 的参数使用 Schema 中的默认值
+//   - number/integer: 数字字符串转换为数值（如 "42" -> 42）
+//   - boolean: "true"/"false" 等字符串转换为布尔值
+//   - string: 数值和布尔值转换为字符串
+//   - array/object: JSON 字符串解析为数组或对象，并递归处理元素和属性
+//
+// 无法转换的值保持原样，由 Validate 报告错误。
+func CoerceArguments(schema ParameterSchema, args map[string]interface{}) map[string]interface{} {
+	return coerceObject(schema.Properties, args)
+}
+
+// coerceObject 规范化对象的属性
+func coerceObject(properties map[string]PropertySchema, args map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(args))
+	for name, value := range args {
+		if prop, ok := properties[name]; ok {
+			value = coerceValue(prop, value)
+		}
+		result[name] = value
+	}
+	for name, prop := range properties {
+		if value, ok := result[name]; (!ok || value == nil) && prop.Default != nil {
+			result[name] = prop.Default
+		}
+	}
+	return result
+}
+
+// coerceValue 按属性类型转换单个值
+func coerceValue(schema PropertySchema, value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+
+	switch schema.Type {
+	case "number", "integer":
+		switch v := value.(type) {
+		case string:
+			if f, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+				return f
+			}
+		case json.Number:
+			if f, err := v.Float64(); err == nil {
+				return f
+			}
+		}
+	case "boolean":
+		if s, ok := value.(string); ok {
+			if b, err := strconv.ParseBool(strings.TrimSpace(s)); err == nil {
+				return b
+			}
+		}
+	case "string":
+		switch v := value.(type) {
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64)
+		case int:
+			return strconv.Itoa(v)
+		case int64:
+			return strconv.FormatInt(v, 10)
+		case bool:
+			return strconv.FormatBool(v)
+		case json.Number:
+			return v.String()
+		}
+	case "array":
+		if s, ok := value.(string); ok {
+			var arr []interface{}
+			if json.Unmarshal([]byte(strings.TrimSpace(s)), &arr) == nil {
+				value = arr
+			}
+		}
+		if arr, ok := value.([]interface{}); ok && schema.Items != nil {
+			coerced := make([]interface{}, len(arr))
+			for i, elem := range arr {
+				coerced[i] = coerceValue(*schema.Items, elem)
+			}
+			return coerced
+		}
+	case "object":
+		if s, ok := value.(string); ok {
+			var obj map[string]interface{}
+			if json.Unmarshal([]byte(strings.TrimSpace(s)), &obj) == nil {
+				value = obj
+			}
+		}
+		if obj, ok := value.(map[string]interface{}); ok && len(schema.Properties) > 0 {
+			return coerceObject(schema.Properties, obj)
+		}
+	}
+	return value
+}
+
+// DescribeParameters 以 "name (type, required)" 的形式列出参数，用于参数错误提示
+func DescribeParameters(schema ParameterSchema) string {
+	required := make(map[string]bool, len(schema.Required))
+	for _, name := range schema.Required {
+		required[name] = true
+	}
+	names := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		prop := schema.Properties[name]
+		typ := prop.Type
+		if typ == "" {
+			typ = "any"
+		}
+		attrs := []string{typ}
+		if required[name] {
+			attrs = append(attrs, "required")
+		}
+		if len(prop.Enum) > 0 {
+			attrs = append(attrs, "one of "+strings.Join(prop.Enum, "|"))
+		}
+		if prop.Default != nil {
+			attrs = append(attrs, fmt.Sprintf("default %v", prop.Default))
+		}
+		parts = append(parts, fmt.Sprintf("%s (%s)", name, strings.Join(attrs, ", ")))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,131 @@
+package tools
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// defaultEventBuffer 注册表订阅通道的默认缓冲大小
+const defaultEventBuffer = 64
+
+// ToolEventType 注册表变更事件类型
+type ToolEventType string
+
+const (
+	// EventToolRegistered 工具已注册
+	EventToolRegistered ToolEventType = "registered"
+	// EventToolUnregistered 工具已取消注册
+	EventToolUnregistered ToolEventType = "unregistered"
+	// EventToolUpdated 提供者刷新后工具定义发生变化
+	EventToolUpdated ToolEventType = "updated"
+	// EventProviderError 刷新工具提供者失败
+	EventProviderError ToolEventType = "provider_error"
+)
+
+// ToolEvent 注册表变更事件
+type ToolEvent struct {
+	// Type 事件类型
+	Type ToolEventType `json:"type"`
+	// Name 发生变更的工具名称（提供者错误事件为空）
+	Name string `json:"name,omitempty"`
+	// Provider 工具所属的提供者名称（直接注册的工具为空）
+	Provider string `json:"provider,omitempty"`
+	// Error 错误信息（仅提供者错误事件）
+	Error string `json:"error,omitempty"`
+	// Timestamp 事件时间
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// registryEvents 注册表事件分发，零值可用
+type registryEvents struct {
+	mu   sync.RWMutex
+	subs map[chan ToolEvent]struct{}
+}
+
+// Watch 订阅注册表变更事件
+//
+// 返回的通道在 ctx 取消后关闭。事件以非阻塞方式投递，订阅者处理不及、
+// 通道缓冲已满时新事件被丢弃，注册表操作不会因订阅者而阻塞。
+// 需要完整工具列表时应在收到事件后调用 All 或 ToDefinitions 重新获取。
+func (r *Registry) Watch(ctx context.Context) <-chan ToolEvent {
+	ch := make(chan ToolEvent, defaultEventBuffer)
+
+	r.events.mu.Lock()
+	if r.events.subs == nil {
+		r.events.subs = make(map[chan ToolEvent]struct{})
+	}
+	r.events.subs[ch] = struct{}{}
+	r.events.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		r.events.mu.Lock()
+		delete(r.events.subs, ch)
+		close(ch)
+		r.events.mu.Unlock()
+	}()
+	return ch
+}
+
+// publish 向所有订阅者投递事件
+func (r *Registry) publish(events ...ToolEvent) {
+	r.events.mu.RLock()
+	defer r.events.mu.RUnlock()
+	if len(r.events.subs) == 0 {
+		return
+	}
+
+	now := time.Now()
+	for _, event := range events {
+		event.Timestamp = now
+		for ch := range r.events.subs {
+			select {
+			case ch <- event:
+			default:
+			}
+		}
+	}
+}
+
+// ChangeNotifier 工具列表变化通知器，零值可用
+//
+// 供 ToolProvider 实现 Watch：工具列表变化时调用 Notify，
+// 每个订阅通道最多缓存一个未读通知，连续的变化合并为一次通知。
+type ChangeNotifier struct {
+	mu   sync.Mutex
+	subs map[chan struct{}]struct{}
+}
+
+// Watch 订阅变化通知，返回的通道在 ctx 取消后关闭
+func (n *ChangeNotifier) Watch(ctx context.Context) <-chan struct{} {
+	ch := make(chan struct{}, 1)
+
+	n.mu.Lock()
+	if n.subs == nil {
+		n.subs = make(map[chan struct{}]struct{})
+	}
+	n.subs[ch] = struct{}{}
+	n.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		n.mu.Lock()
+		delete(n.subs, ch)
+		close(ch)
+		n.mu.Unlock()
+	}()
+	return ch
+}
+
+// Notify 通知所有订阅者工具列表已变化
+func (n *ChangeNotifier) Notify() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for ch := range n.subs {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
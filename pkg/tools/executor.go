@@ -93,10 +93,13 @@ func (e *Executor) Execute(ctx context.Context, name string, args map[string]int
 			}
 		}
 
-		// 执行工具
-		result, lastErr = tool.Execute(ctx, args)
+		// 执行工具，结构化工具的结果写入本次尝试的槽位
+		attemptCtx, slot := withStructuredSlot(ctx)
+		result, lastErr = tool.Execute(attemptCtx, args)
 		if lastErr == nil {
-			return NewToolResult(name, result)
+			toolResult := NewToolResult(name, appendSurfaced(ctx, result))
+			toolResult.Data = slot.get()
+			return toolResult
 		}
 
 		// 检查是否需要重试
@@ -109,6 +112,10 @@ func (e *Executor) Execute(ctx context.Context, name string, args map[string]int
 		}
 	}
 
+	// 失败调用标记的暂存条目不再展示
+	if scratch, ok := ScratchpadFromContext(ctx); ok {
+		scratch.DrainSurfaced()
+	}
 	return NewToolError(name, fmt.Errorf("%w: %v", errors.ErrToolExecutionFailed, lastErr))
 }
 
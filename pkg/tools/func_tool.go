@@ -30,6 +30,7 @@ type FuncTool struct {
 	params      ParameterSchema
 	fn          ToolFunc
 	validator   ValidatorFunc
+	examples    []ToolExample
 }
 
 // ToolFunc 工具执行函数类型
@@ -64,6 +65,13 @@ func WithValidator(v ValidatorFunc) FuncToolOption {
 	}
 }
 
+// WithExamples 设置工具使用示例
+func WithExamples(examples ...ToolExample) FuncToolOption {
+	return func(t *FuncTool) {
+		t.examples = append(t.examples, examples...)
+	}
+}
+
 // Name 返回工具名称
 func (t *FuncTool) Name() string {
 	return t.name
@@ -79,6 +87,11 @@ func (t *FuncTool) Parameters() ParameterSchema {
 	return t.params
 }
 
+// Examples 返回工具使用示例
+func (t *FuncTool) Examples() []ToolExample {
+	return t.examples
+}
+
 // Execute 执行工具
 func (t *FuncTool) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
 	if t.fn == nil {
@@ -109,6 +122,7 @@ func validateRequired(schema ParameterSchema, args map[string]interface{}) error
 // compile-time interface check
 var _ Tool = (*FuncTool)(nil)
 var _ ToolWithValidation = (*FuncTool)(nil)
+var _ ToolWithExamples = (*FuncTool)(nil)
 
 // SimpleTool 更简化的工具创建方式
 //
@@ -0,0 +1,310 @@
+package tools
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	agentctx "github.com/ahhsitt/helloagents-go/pkg/context"
+	"github.com/ahhsitt/helloagents-go/pkg/memory/store"
+)
+
+// DefaultHistoryCollection 工具执行记录的默认集合名
+const DefaultHistoryCollection = "tool_executions"
+
+// ExecutionRecord 一次工具调用的执行记录
+type ExecutionRecord struct {
+	// ID 记录 ID
+	ID string `json:"id"`
+	// Tool 工具名称
+	Tool string `json:"tool"`
+	// ArgsHash 参数的 SHA-256 摘要（参数本身不落盘，用于识别重复调用）
+	ArgsHash string `json:"args_hash"`
+	// Duration 执行耗时
+	Duration time.Duration `json:"duration"`
+	// Success 是否成功
+	Success bool `json:"success"`
+	// Error 错误信息
+	Error string `json:"error,omitempty"`
+	// ResultTokens 结果的 token 数量，即结果回填给 LLM 的成本
+	ResultTokens int `json:"result_tokens"`
+	// Timestamp 调用开始时间
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// ExecutionQuery 执行记录查询条件，零值字段不参与过滤
+type ExecutionQuery struct {
+	// Tool 工具名称
+	Tool string
+	// Success 只返回成功（true）或失败（false）的记录
+	Success *bool
+	// Since 起始时间（含）
+	Since time.Time
+	// Until 截止时间（不含）
+	Until time.Time
+	// Limit 最大返回数量，<= 0 表示不限制（只对 Query 生效）
+	Limit int
+}
+
+// ToolStats 单个工具的聚合统计
+type ToolStats struct {
+	// Tool 工具名称
+	Tool string `json:"tool"`
+	// Calls 调用次数
+	Calls int `json:"calls"`
+	// Failures 失败次数
+	Failures int `json:"failures"`
+	// FailureRate 失败率（0-1）
+	FailureRate float64 `json:"failure_rate"`
+	// TotalDuration 总耗时
+	TotalDuration time.Duration `json:"total_duration"`
+	// AvgDuration 平均耗时
+	AvgDuration time.Duration `json:"avg_duration"`
+	// MaxDuration 最长耗时
+	MaxDuration time.Duration `json:"max_duration"`
+	// TotalResultTokens 结果 token 总数
+	TotalResultTokens int `json:"total_result_tokens"`
+	// AvgResultTokens 平均每次调用的结果 token 数
+	AvgResultTokens int `json:"avg_result_tokens"`
+	// LastError 最近一次失败的错误信息
+	LastError string `json:"last_error,omitempty"`
+	// LastCalled 最近一次调用时间
+	LastCalled time.Time `json:"last_called"`
+}
+
+// ExecutionRecorder 工具执行历史记录器
+//
+// 将每次工具调用（工具名、参数摘要、耗时、成败、错误、结果 token 数）写入文档存储，
+// 提供按工具、成败和时间范围的查询以及按工具聚合的统计，用于发现失败最多的工具和评估 token 预算。
+//
+// 使用示例：
+//
+//	recorder := tools.NewExecutionRecorder(docStore)
+//	registry.Use(recorder.Middleware())
+//	stats, err := recorder.Stats(ctx, tools.ExecutionQuery{Since: time.Now().Add(-24 * time.Hour)})
+type ExecutionRecorder struct {
+	docs       store.DocumentStore
+	collection string
+	counter    agentctx.TokenCounter
+	onError    func(error)
+}
+
+// ExecutionRecorderOption ExecutionRecorder 配置选项
+type ExecutionRecorderOption func(*ExecutionRecorder)
+
+// WithHistoryCollection 设置执行记录的集合名（默认 DefaultHistoryCollection）
+func WithHistoryCollection(collection string) ExecutionRecorderOption {
+	return func(r *ExecutionRecorder) {
+		if collection != "" {
+			r.collection = collection
+		}
+	}
+}
+
+// WithHistoryTokenCounter 设置计算结果 token 数的计数器（默认按字符估算）
+func WithHistoryTokenCounter(counter agentctx.TokenCounter) ExecutionRecorderOption {
+	return func(r *ExecutionRecorder) {
+		if counter != nil {
+			r.counter = counter
+		}
+	}
+}
+
+// WithHistoryErrorHandler 设置中间件写入记录失败时的回调
+//
+// 写入失败不影响工具调用本身，默认忽略。
+func WithHistoryErrorHandler(fn func(error)) ExecutionRecorderOption {
+	return func(r *ExecutionRecorder) {
+		r.onError = fn
+	}
+}
+
+// NewExecutionRecorder 创建工具执行历史记录器
+func NewExecutionRecorder(docs store.DocumentStore, opts ...ExecutionRecorderOption) *ExecutionRecorder {
+	r := &ExecutionRecorder{
+		docs:       docs,
+		collection: DefaultHistoryCollection,
+		counter:    agentctx.NewEstimatedCounter(),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Middleware 返回记录每次工具调用的中间件
+//
+// 记录在工具返回后同步写入，上下文取消不会中断写入。
+func (r *ExecutionRecorder) Middleware() Middleware {
+	return func(next Tool) Tool {
+		return WrapExecute(next, func(ctx context.Context, args map[string]interface{}) (string, error) {
+			start := time.Now()
+			result, err := next.Execute(ctx, args)
+
+			record := ExecutionRecord{
+				Tool:         next.Name(),
+				ArgsHash:     HashArguments(args),
+				Duration:     time.Since(start),
+				Success:      err == nil,
+				ResultTokens: r.counter.Count(result),
+				Timestamp:    start,
+			}
+			if err != nil {
+				record.Error = err.Error()
+			}
+			if recordErr := r.Record(context.WithoutCancel(ctx), record); recordErr != nil && r.onError != nil {
+				r.onError(recordErr)
+			}
+			return result, err
+		})
+	}
+}
+
+// Record 写入一条执行记录，ID 和 Timestamp 为空时自动生成
+func (r *ExecutionRecorder) Record(ctx context.Context, record ExecutionRecord) error {
+	if record.ID == "" {
+		record.ID = uuid.NewString()
+	}
+	if record.Timestamp.IsZero() {
+		record.Timestamp = time.Now()
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	return r.docs.Put(ctx, r.collection, record.ID, store.Document{
+		ID:      record.ID,
+		Content: string(data),
+		Metadata: map[string]interface{}{
+			"tool":   record.Tool,
+			"status": recordStatus(record.Success),
+		},
+		CreatedAt: record.Timestamp,
+		UpdatedAt: record.Timestamp,
+	})
+}
+
+// Query 按条件查询执行记录，按调用时间倒序返回
+func (r *ExecutionRecorder) Query(ctx context.Context, q ExecutionQuery) ([]ExecutionRecord, error) {
+	return r.query(ctx, q)
+}
+
+// Stats 按工具聚合满足条件的执行记录，按失败次数、调用次数倒序返回
+func (r *ExecutionRecorder) Stats(ctx context.Context, q ExecutionQuery) ([]ToolStats, error) {
+	q.Limit = 0
+	records, err := r.query(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	byTool := make(map[string]*ToolStats)
+	lastFailure := make(map[string]time.Time)
+	for _, rec := range records {
+		s, ok := byTool[rec.Tool]
+		if !ok {
+			s = &ToolStats{Tool: rec.Tool}
+			byTool[rec.Tool] = s
+		}
+		s.Calls++
+		s.TotalDuration += rec.Duration
+		if rec.Duration > s.MaxDuration {
+			s.MaxDuration = rec.Duration
+		}
+		s.TotalResultTokens += rec.ResultTokens
+		if rec.Timestamp.After(s.LastCalled) {
+			s.LastCalled = rec.Timestamp
+		}
+		if !rec.Success {
+			s.Failures++
+			if !rec.Timestamp.Before(lastFailure[rec.Tool]) {
+				lastFailure[rec.Tool] = rec.Timestamp
+				s.LastError = rec.Error
+			}
+		}
+	}
+
+	stats := make([]ToolStats, 0, len(byTool))
+	for _, s := range byTool {
+		s.FailureRate = float64(s.Failures) / float64(s.Calls)
+		s.AvgDuration = s.TotalDuration / time.Duration(s.Calls)
+		s.AvgResultTokens = s.TotalResultTokens / s.Calls
+		stats = append(stats, *s)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Failures != stats[j].Failures {
+			return stats[i].Failures > stats[j].Failures
+		}
+		if stats[i].Calls != stats[j].Calls {
+			return stats[i].Calls > stats[j].Calls
+		}
+		return stats[i].Tool < stats[j].Tool
+	})
+	return stats, nil
+}
+
+// Clear 删除全部执行记录
+func (r *ExecutionRecorder) Clear(ctx context.Context) error {
+	return r.docs.Clear(ctx, r.collection)
+}
+
+// query 查询并解码执行记录，按调用时间倒序
+//
+// 工具名和成败在文档存储中过滤；各存储后端对数值元数据的比较方式不一致，
+// 时间范围和数量限制在解码后处理。
+func (r *ExecutionRecorder) query(ctx context.Context, q ExecutionQuery) ([]ExecutionRecord, error) {
+	var conditions []store.Filter
+	if q.Tool != "" {
+		conditions = append(conditions, store.Filter{Field: "tool", Op: "eq", Value: q.Tool})
+	}
+	if q.Success != nil {
+		conditions = append(conditions, store.Filter{Field: "status", Op: "eq", Value: recordStatus(*q.Success)})
+	}
+	docs, err := r.docs.Query(ctx, r.collection, store.Filter{And: conditions},
+		store.WithQueryOrderBy("created_at", true), store.WithQueryLimit(0))
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]ExecutionRecord, 0, len(docs))
+	for _, doc := range docs {
+		var rec ExecutionRecord
+		if err := json.Unmarshal([]byte(doc.Content), &rec); err != nil {
+			return nil, fmt.Errorf("failed to decode execution record %s: %w", doc.ID, err)
+		}
+		if !q.Since.IsZero() && rec.Timestamp.Before(q.Since) {
+			continue
+		}
+		if !q.Until.IsZero() && !rec.Timestamp.Before(q.Until) {
+			continue
+		}
+		records = append(records, rec)
+		if q.Limit > 0 && len(records) == q.Limit {
+			break
+		}
+	}
+	return records, nil
+}
+
+// recordStatus 返回记录成败对应的状态元数据
+func recordStatus(success bool) string {
+	if success {
+		return "success"
+	}
+	return "error"
+}
+
+// HashArguments 返回参数的 SHA-256 摘要（十六进制），键顺序不影响结果
+func HashArguments(args map[string]interface{}) string {
+	data, err := json.Marshal(args)
+	if err != nil {
+		data = []byte(fmt.Sprintf("%v", args))
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,157 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ToolExample 工具使用示例
+type ToolExample struct {
+	// Description 示例说明
+	Description string `json:"description,omitempty"`
+	// Arguments 示例参数
+	Arguments map[string]interface{} `json:"arguments"`
+	// Output 预期输出（可选）
+	Output string `json:"output,omitempty"`
+}
+
+// ToolWithExamples 提供使用示例的工具接口
+//
+// 实现此接口的工具会在生成使用手册时附带示例，
+// 帮助 LLM 或外部客户端理解工具的正确调用方式。
+type ToolWithExamples interface {
+	Tool
+	// Examples 返回工具使用示例
+	Examples() []ToolExample
+}
+
+// ToolManual 工具使用手册
+//
+// 汇总工具的名称、描述、参数 Schema 和使用示例，
+// 比单行描述提供更丰富的使用说明。
+type ToolManual struct {
+	// Name 工具名称
+	Name string `json:"name"`
+	// Description 工具描述
+	Description string `json:"description"`
+	// Parameters 参数 Schema
+	Parameters ParameterSchema `json:"parameters"`
+	// Examples 使用示例
+	Examples []ToolExample `json:"examples,omitempty"`
+	// OutputSchema 结构化结果 Schema（工具实现 ToolWithStructuredOutput 时）
+	OutputSchema *PropertySchema `json:"output_schema,omitempty"`
+}
+
+// NewToolManual 从工具生成使用手册
+func NewToolManual(t Tool) ToolManual {
+	manual := ToolManual{
+		Name:        t.Name(),
+		Description: t.Description(),
+		Parameters:  t.Parameters(),
+	}
+	if et, ok := t.(ToolWithExamples); ok {
+		manual.Examples = et.Examples()
+	}
+	if st, ok := Unwrap(t).(ToolWithStructuredOutput); ok {
+		schema := st.OutputSchema()
+		manual.OutputSchema = &schema
+	}
+	return manual
+}
+
+// Markdown 将使用手册渲染为 Markdown 文本
+func (m ToolManual) Markdown() string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("# %s\n\n", m.Name))
+	if m.Description != "" {
+		sb.WriteString(m.Description)
+		sb.WriteString("\n\n")
+	}
+
+	sb.WriteString("## Parameters\n\n")
+	if len(m.Parameters.Properties) == 0 {
+		sb.WriteString("No parameters required.\n\n")
+	} else {
+		// 按名称排序，保证输出稳定
+		names := make([]string, 0, len(m.Parameters.Properties))
+		for name := range m.Parameters.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			prop := m.Parameters.Properties[name]
+			sb.WriteString(fmt.Sprintf("- `%s` (%s", name, prop.Type))
+			if m.isRequired(name) {
+				sb.WriteString(", required")
+			}
+			sb.WriteString(")")
+			if prop.Description != "" {
+				sb.WriteString(": " + prop.Description)
+			}
+			if len(prop.Enum) > 0 {
+				sb.WriteString(fmt.Sprintf(" [allowed: %s]", strings.Join(prop.Enum, ", ")))
+			}
+			if prop.Default != nil {
+				sb.WriteString(fmt.Sprintf(" (default: %v)", prop.Default))
+			}
+			sb.WriteString("\n")
+		}
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("## Schema\n\n```json\n")
+	schemaBytes, _ := json.MarshalIndent(m.Parameters, "", "  ")
+	sb.Write(schemaBytes)
+	sb.WriteString("\n```\n")
+
+	if m.OutputSchema != nil {
+		sb.WriteString("\n## Output Schema\n\n```json\n")
+		outputBytes, _ := json.MarshalIndent(m.OutputSchema, "", "  ")
+		sb.Write(outputBytes)
+		sb.WriteString("\n```\n")
+	}
+
+	if len(m.Examples) > 0 {
+		sb.WriteString("\n## Examples\n")
+		for i, ex := range m.Examples {
+			sb.WriteString(fmt.Sprintf("\n### Example %d", i+1))
+			if ex.Description != "" {
+				sb.WriteString(": " + ex.Description)
+			}
+			sb.WriteString("\n\n```json\n")
+			argBytes, _ := json.MarshalIndent(ex.Arguments, "", "  ")
+			sb.Write(argBytes)
+			sb.WriteString("\n```\n")
+			if ex.Output != "" {
+				sb.WriteString(fmt.Sprintf("\nOutput: `%s`\n", ex.Output))
+			}
+		}
+	}
+
+	return sb.String()
+}
+
+// isRequired 检查参数是否必需
+func (m ToolManual) isRequired(name string) bool {
+	for _, req := range m.Parameters.Required {
+		if req == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Manual 获取指定工具的使用手册
+//
+// 如果工具不存在，返回 ErrToolNotFound。
+func (r *Registry) Manual(name string) (ToolManual, error) {
+	tool, err := r.Get(name)
+	if err != nil {
+		return ToolManual{}, err
+	}
+	return NewToolManual(tool), nil
+}
@@ -0,0 +1,346 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/ahhsitt/helloagents-go/pkg/core/errors"
+	"github.com/ahhsitt/helloagents-go/pkg/core/retry"
+	"github.com/ahhsitt/helloagents-go/pkg/otel"
+)
+
+// Middleware 工具中间件，包装工具返回新的工具
+//
+// 中间件用于在注册表层面统一添加日志、追踪、超时、重试、结果截断等横切逻辑，
+// 而不必在每个工具内部重复实现。通常使用 WrapExecute 只替换执行逻辑，
+// 包装后的工具保留原工具的名称、描述、参数 Schema、参数验证和使用示例。
+//
+// 使用示例:
+//
+//	registry.Use(
+//	    tools.LoggingMiddleware(slog.Default()),
+//	    tools.TimeoutMiddleware(10*time.Second),
+//	)
+//	registry.Use(func(next tools.Tool) tools.Tool {
+//	    return tools.WrapExecute(next, func(ctx context.Context, args map[string]interface{}) (string, error) {
+//	        start := time.Now()
+//	        result, err := next.Execute(ctx, args)
+//	        toolDuration.WithLabelValues(next.Name()).Observe(time.Since(start).Seconds())
+//	        return result, err
+//	    })
+//	})
+type Middleware func(Tool) Tool
+
+// ChainMiddleware 将多个中间件组合为一个，第一个中间件在最外层
+func ChainMiddleware(middlewares ...Middleware) Middleware {
+	return func(tool Tool) Tool {
+		for i := len(middlewares) - 1; i >= 0; i-- {
+			tool = middlewares[i](tool)
+		}
+		return tool
+	}
+}
+
+// WrapExecute 使用 execute 替换工具的执行逻辑
+//
+// 返回的工具保留原工具的名称、描述和参数 Schema；原工具实现 ToolWithValidation 时
+// 返回的工具也实现该接口，参数验证仍由原工具完成。
+func WrapExecute(tool Tool, execute ToolFunc) Tool {
+	wrapped := &wrappedTool{inner: tool, execute: execute}
+	if _, ok := tool.(ToolWithValidation); ok {
+		return &validatingWrappedTool{wrappedTool: wrapped}
+	}
+	return wrapped
+}
+
+// wrappedTool 替换了执行逻辑的工具
+type wrappedTool struct {
+	// inner 被包装的工具
+	inner Tool
+	// execute 新的执行函数
+	execute ToolFunc
+}
+
+// Name 返回原工具名称
+func (t *wrappedTool) Name() string {
+	return t.inner.Name()
+}
+
+// Description 返回原工具描述
+func (t *wrappedTool) Description() string {
+	return t.inner.Description()
+}
+
+// Parameters 返回原工具参数 Schema
+func (t *wrappedTool) Parameters() ParameterSchema {
+	return t.inner.Parameters()
+}
+
+// Execute 使用包装后的执行函数执行工具
+func (t *wrappedTool) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
+	return t.execute(ctx, args)
+}
+
+// Examples 返回原工具的使用示例
+func (t *wrappedTool) Examples() []ToolExample {
+	if et, ok := t.inner.(ToolWithExamples); ok {
+		return et.Examples()
+	}
+	return nil
+}
+
+// Unwrap 返回被包装的工具
+func (t *wrappedTool) Unwrap() Tool {
+	return t.inner
+}
+
+// validatingWrappedTool 原工具支持参数验证时的包装工具
+type validatingWrappedTool struct {
+	*wrappedTool
+}
+
+// Validate 使用原工具验证参数
+func (t *validatingWrappedTool) Validate(args map[string]interface{}) error {
+	return t.inner.(ToolWithValidation).Validate(args)
+}
+
+// compile-time interface check
+var (
+	_ ToolWithExamples   = (*wrappedTool)(nil)
+	_ ToolWithValidation = (*validatingWrappedTool)(nil)
+)
+
+// Unwrap 逐层解开中间件包装，返回最初注册的工具
+//
+// 工具实现 Unwrap() Tool 时视为包装工具，需要访问具体工具类型时使用。
+func Unwrap(tool Tool) Tool {
+	for {
+		u, ok := tool.(interface{ Unwrap() Tool })
+		if !ok {
+			return tool
+		}
+		inner := u.Unwrap()
+		if inner == nil {
+			return tool
+		}
+		tool = inner
+	}
+}
+
+// DefaultRedactKeys 未指定时日志和追踪中默认脱敏的参数名（不区分大小写）
+var DefaultRedactKeys = []string{"password", "secret", "token", "api_key", "apikey", "authorization", "access_key", "private_key"}
+
+// RedactedValue 脱敏后参数值的占位文本
+const RedactedValue = "[REDACTED]"
+
+// RedactArguments 返回参数的副本，名称匹配 keys 的参数值替换为 RedactedValue
+//
+// 参数名不区分大小写，嵌套的对象和数组同样处理；keys 为空时使用 DefaultRedactKeys。
+// 原参数不会被修改。
+func RedactArguments(args map[string]interface{}, keys ...string) map[string]interface{} {
+	if len(keys) == 0 {
+		keys = DefaultRedactKeys
+	}
+	if args == nil {
+		return nil
+	}
+	set := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		set[strings.ToLower(key)] = true
+	}
+	return redactValue(args, set).(map[string]interface{})
+}
+
+// redactValue 递归脱敏对象和数组中的参数
+func redactValue(value interface{}, keys map[string]bool) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			if keys[strings.ToLower(key)] {
+				out[key] = RedactedValue
+			} else {
+				out[key] = redactValue(val, keys)
+			}
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, val := range v {
+			out[i] = redactValue(val, keys)
+		}
+		return out
+	default:
+		return value
+	}
+}
+
+// formatArguments 将脱敏后的参数格式化为 JSON 文本
+func formatArguments(args map[string]interface{}, keys []string) string {
+	redacted := RedactArguments(args, keys...)
+	data, err := json.Marshal(redacted)
+	if err != nil {
+		return fmt.Sprintf("%v", redacted)
+	}
+	return string(data)
+}
+
+// LoggingMiddleware 记录每次工具调用的工具名、参数、耗时和结果的中间件
+//
+// 成功的调用以 Debug 级别记录，失败的调用以 Warn 级别记录。参数按 redactKeys 脱敏
+// （为空时使用 DefaultRedactKeys）。logger 为 nil 时使用 slog.Default()。
+func LoggingMiddleware(logger *slog.Logger, redactKeys ...string) Middleware {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return func(next Tool) Tool {
+		return WrapExecute(next, func(ctx context.Context, args map[string]interface{}) (string, error) {
+			start := time.Now()
+			result, err := next.Execute(ctx, args)
+
+			attrs := []any{
+				slog.String("tool", next.Name()),
+				slog.String("arguments", formatArguments(args, redactKeys)),
+				slog.Duration("duration", time.Since(start)),
+			}
+			if err != nil {
+				logger.WarnContext(ctx, "tool execution failed", append(attrs, slog.String("error", err.Error()))...)
+			} else {
+				logger.DebugContext(ctx, "tool executed", append(attrs, slog.Int("result_size", len(result)))...)
+			}
+			return result, err
+		})
+	}
+}
+
+// TracingMiddleware 为每次工具调用创建 tool.execute Span 并记录工具指标的中间件
+//
+// Span 包含工具名、脱敏后的参数（按 redactKeys，为空时使用 DefaultRedactKeys）、耗时和错误；
+// 指标包括调用次数、错误次数和调用耗时。tracer 或 metrics 为 nil 时不导出对应数据。
+func TracingMiddleware(tracer otel.Tracer, metrics otel.Metrics, redactKeys ...string) Middleware {
+	return func(next Tool) Tool {
+		return WrapExecute(next, func(ctx context.Context, args map[string]interface{}) (string, error) {
+			name := next.Name()
+			var span otel.Span = &otel.NoopSpan{}
+			if tracer != nil {
+				ctx, span = tracer.Start(ctx, "tool.execute",
+					otel.WithSpanKind(otel.SpanKindInternal),
+					otel.WithAttributes(
+						otel.ToolName(name),
+						attribute.String(otel.AttrToolArgs, formatArguments(args, redactKeys)),
+					),
+				)
+			}
+			defer span.End()
+
+			start := time.Now()
+			result, err := next.Execute(ctx, args)
+			duration := time.Since(start)
+
+			span.SetAttributes(otel.ToolDuration(duration.Milliseconds()))
+			status := "success"
+			if err != nil {
+				status = "error"
+				span.SetAttributes(attribute.String(otel.AttrToolError, err.Error()))
+				span.RecordError(err)
+				span.SetStatus(otel.StatusError, err.Error())
+			} else {
+				span.SetStatus(otel.StatusOK, "")
+			}
+
+			if metrics != nil {
+				toolAttr := otel.NewAttr("tool", name)
+				if err != nil {
+					metrics.Counter(otel.MetricToolErrors).Add(ctx, 1, toolAttr)
+				}
+				metrics.Counter(otel.MetricToolCalls).Add(ctx, 1, toolAttr, otel.NewAttr("status", status))
+				metrics.Histogram(otel.MetricToolCallDuration).Record(ctx, float64(duration.Microseconds())/1000, toolAttr)
+			}
+			return result, err
+		})
+	}
+}
+
+// TimeoutMiddleware 限制单次工具执行时间的中间件
+//
+// 超时后立即返回 errors.ErrToolTimeout，即使工具没有响应上下文取消；
+// 此时工具仍在后台运行直到自行返回，其结果被丢弃。d <= 0 时不限制。
+func TimeoutMiddleware(d time.Duration) Middleware {
+	return func(next Tool) Tool {
+		if d <= 0 {
+			return next
+		}
+		return WrapExecute(next, func(ctx context.Context, args map[string]interface{}) (string, error) {
+			ctx, cancel := context.WithTimeout(ctx, d)
+			defer cancel()
+
+			type outcome struct {
+				result string
+				err    error
+			}
+			done := make(chan outcome, 1)
+			go func() {
+				result, err := next.Execute(ctx, args)
+				done <- outcome{result, err}
+			}()
+
+			select {
+			case out := <-done:
+				return out.result, out.err
+			case <-ctx.Done():
+				if ctx.Err() == context.DeadlineExceeded {
+					return "", fmt.Errorf("%w: %s exceeded %v", errors.ErrToolTimeout, next.Name(), d)
+				}
+				return "", errors.ErrContextCanceled
+			}
+		})
+	}
+}
+
+// RetryMiddleware 按重试策略重试失败工具调用的中间件
+//
+// 重试事件以 tool.<工具名> 作为组件名记录到当前 Span。policy.Retryable 为空时
+// 只重试 errors.IsRetryable 认可的错误（限速、超时、服务不可用）；
+// 需要重试所有错误时将 Retryable 设置为始终返回 true 的函数。
+func RetryMiddleware(policy retry.Policy) Middleware {
+	return func(next Tool) Tool {
+		return WrapExecute(next, func(ctx context.Context, args map[string]interface{}) (string, error) {
+			var result string
+			err := policy.DoNamed(ctx, "tool."+next.Name(), func(ctx context.Context) error {
+				var err error
+				result, err = next.Execute(ctx, args)
+				return err
+			})
+			if err != nil {
+				return "", err
+			}
+			return result, nil
+		})
+	}
+}
+
+// TruncateResultMiddleware 将工具结果截断到 maxChars 个字符的中间件
+//
+// 被截断的结果末尾追加 "[WARNING] Output truncated" 提示。maxChars <= 0 时不截断。
+func TruncateResultMiddleware(maxChars int) Middleware {
+	return func(next Tool) Tool {
+		if maxChars <= 0 {
+			return next
+		}
+		return WrapExecute(next, func(ctx context.Context, args map[string]interface{}) (string, error) {
+			result, err := next.Execute(ctx, args)
+			if err != nil || utf8.RuneCountInString(result) <= maxChars {
+				return result, err
+			}
+			runes := []rune(result)
+			return string(runes[:maxChars]) + "\n[WARNING] Output truncated", nil
+		})
+	}
+}
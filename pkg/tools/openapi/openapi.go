@@ -0,0 +1,474 @@
+// Package openapi 将 OpenAPI 3.x / Swagger 2.0 规范导入为工具
+//
+// 规范中的每个操作（路径 + 方法）生成一个 tools.Tool：参数 Schema 由路径、查询、请求头参数和
+// JSON 请求体推导，执行时按规范拼装并发送 HTTP 请求，响应按字符上限截断后返回给 Agent。
+//
+// 使用示例：
+//
+//	list, err := openapi.NewToolsFromSpec(ctx, "https://petstore3.swagger.io/api/v3/openapi.json",
+//	    openapi.WithBearerToken(token),
+//	    openapi.WithToolPrefix("petstore_"),
+//	)
+//	for _, tool := range list {
+//	    registry.Register(tool)
+//	}
+//
+// 只支持 JSON 格式的规范，YAML 规范需先转换为 JSON。
+package openapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ahhsitt/helloagents-go/pkg/tools"
+)
+
+// 默认配置
+const (
+	// DefaultTimeout 单次请求超时时间
+	DefaultTimeout = 30 * time.Second
+	// DefaultMaxResponseChars 返回给 Agent 的响应体字符上限
+	DefaultMaxResponseChars = 8000
+	// maxSpecSize 规范文档大小上限
+	maxSpecSize = 10 * 1024 * 1024
+)
+
+// httpMethods 规范中按顺序识别的操作方法
+var httpMethods = []string{"get", "put", "post", "delete", "patch", "head", "options"}
+
+// Operation 规范中的一个 API 操作
+type Operation struct {
+	// ID 操作 ID（规范未声明时由方法和路径生成）
+	ID string
+	// Method HTTP 方法（大写）
+	Method string
+	// Path 路径模板，如 /pets/{petId}
+	Path string
+	// Summary 操作摘要
+	Summary string
+	// Description 操作描述
+	Description string
+	// Tags 操作标签
+	Tags []string
+	// Parameters 路径、查询、请求头和 Cookie 参数
+	Parameters []Parameter
+	// Body JSON 请求体 Schema（无请求体时为 nil）
+	Body *tools.PropertySchema
+	// BodyRequired 请求体是否必需
+	BodyRequired bool
+	// Security 生效的安全方案
+	Security []SecurityScheme
+}
+
+// Parameter 操作参数
+type Parameter struct {
+	// Name 参数名称
+	Name string
+	// In 参数位置：path、query、header、cookie
+	In string
+	// Description 参数描述
+	Description string
+	// Required 是否必需
+	Required bool
+	// Schema 参数 Schema
+	Schema tools.PropertySchema
+}
+
+// SecurityScheme 安全方案
+type SecurityScheme struct {
+	// Name 方案名称
+	Name string
+	// Type 方案类型：apiKey、http、basic、oauth2、openIdConnect
+	Type string
+	// In apiKey 的位置：header、query、cookie
+	In string
+	// ParamName apiKey 的参数名
+	ParamName string
+	// Scheme http 方案的认证方式，如 bearer、basic
+	Scheme string
+}
+
+// config 导入配置
+type config struct {
+	client    *http.Client
+	baseURL   string
+	headers   map[string]string
+	bearer    string
+	apiKey    string
+	basicUser string
+	basicPass string
+	timeout   time.Duration
+	maxChars  int
+	prefix    string
+	filter    func(Operation) bool
+}
+
+// Option 导入配置选项
+type Option func(*config)
+
+// WithHTTPClient 设置获取规范和调用 API 使用的 HTTP 客户端
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *config) {
+		if client != nil {
+			c.client = client
+		}
+	}
+}
+
+// WithBaseURL 设置 API 根地址，覆盖规范中的 servers / host
+func WithBaseURL(baseURL string) Option {
+	return func(c *config) {
+		c.baseURL = strings.TrimRight(baseURL, "/")
+	}
+}
+
+// WithHeaders 设置每个请求附带的请求头，优先于 Agent 传入的同名参数
+func WithHeaders(headers map[string]string) Option {
+	return func(c *config) {
+		for k, v := range headers {
+			c.headers[k] = v
+		}
+	}
+}
+
+// WithBearerToken 设置 Bearer 令牌，以 Authorization 请求头发送
+func WithBearerToken(token string) Option {
+	return func(c *config) {
+		c.bearer = token
+	}
+}
+
+// WithBasicAuth 设置 HTTP Basic 认证
+func WithBasicAuth(username, password string) Option {
+	return func(c *config) {
+		c.basicUser, c.basicPass = username, password
+	}
+}
+
+// WithAPIKey 设置 API Key，按操作声明的 apiKey 安全方案放入请求头、查询参数或 Cookie
+func WithAPIKey(key string) Option {
+	return func(c *config) {
+		c.apiKey = key
+	}
+}
+
+// WithTimeout 设置单次请求超时（默认 DefaultTimeout），0 表示不限制
+func WithTimeout(d time.Duration) Option {
+	return func(c *config) {
+		c.timeout = d
+	}
+}
+
+// WithMaxResponseChars 设置响应体字符上限（默认 DefaultMaxResponseChars），超出部分截断
+func WithMaxResponseChars(n int) Option {
+	return func(c *config) {
+		if n > 0 {
+			c.maxChars = n
+		}
+	}
+}
+
+// WithToolPrefix 设置工具名称前缀，避免多个 API 的操作重名
+func WithToolPrefix(prefix string) Option {
+	return func(c *config) {
+		c.prefix = prefix
+	}
+}
+
+// WithOperationFilter 只导入 filter 返回 true 的操作
+func WithOperationFilter(filter func(Operation) bool) Option {
+	return func(c *config) {
+		c.filter = filter
+	}
+}
+
+// newConfig 应用选项
+func newConfig(opts []Option) *config {
+	c := &config{
+		client:   http.DefaultClient,
+		headers:  make(map[string]string),
+		timeout:  DefaultTimeout,
+		maxChars: DefaultMaxResponseChars,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// NewToolsFromSpec 从规范生成工具
+//
+// source 可以是规范的 http(s) 地址、本地文件路径或 JSON 文档本身。
+// 规范中的服务器地址为相对路径时，相对于规范地址解析。
+func NewToolsFromSpec(ctx context.Context, source string, opts ...Option) ([]tools.Tool, error) {
+	cfg := newConfig(opts)
+	data, specURL, err := loadSpec(ctx, cfg.client, source)
+	if err != nil {
+		return nil, err
+	}
+
+	operations, baseURL, err := parseSpec(data, specURL)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.baseURL != "" {
+		baseURL = cfg.baseURL
+	}
+	if baseURL == "" {
+		return nil, fmt.Errorf("openapi: spec declares no server URL, use WithBaseURL")
+	}
+
+	used := make(map[string]int)
+	result := make([]tools.Tool, 0, len(operations))
+	for _, op := range operations {
+		if cfg.filter != nil && !cfg.filter(op) {
+			continue
+		}
+		name := toolName(cfg.prefix + op.ID)
+		if n := used[name]; n > 0 {
+			used[name]++
+			name = fmt.Sprintf("%s_%d", name, n+1)
+		} else {
+			used[name] = 1
+		}
+		result = append(result, newOperationTool(name, baseURL, op, cfg))
+	}
+	return result, nil
+}
+
+// loadSpec 读取规范内容，规范来自 http(s) 地址时同时返回该地址
+func loadSpec(ctx context.Context, client *http.Client, source string) ([]byte, string, error) {
+	source = strings.TrimSpace(source)
+	switch {
+	case source == "":
+		return nil, "", fmt.Errorf("openapi: empty spec source")
+	case strings.HasPrefix(source, "{"):
+		return []byte(source), "", nil
+	case strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://"):
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+		if err != nil {
+			return nil, "", fmt.Errorf("openapi: invalid spec url: %w", err)
+		}
+		req.Header.Set("Accept", "application/json")
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, "", fmt.Errorf("openapi: failed to fetch spec: %w", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode >= http.StatusBadRequest {
+			return nil, "", fmt.Errorf("openapi: failed to fetch spec: HTTP %s", resp.Status)
+		}
+		data, err := io.ReadAll(io.LimitReader(resp.Body, maxSpecSize))
+		if err != nil {
+			return nil, "", fmt.Errorf("openapi: failed to read spec: %w", err)
+		}
+		return data, source, nil
+	default:
+		data, err := os.ReadFile(source)
+		if err != nil {
+			return nil, "", fmt.Errorf("openapi: failed to read spec: %w", err)
+		}
+		return data, "", nil
+	}
+}
+
+// parseSpec 解析规范，返回按路径和方法排序的操作列表和 API 根地址
+func parseSpec(data []byte, specURL string) ([]Operation, string, error) {
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, "", fmt.Errorf("openapi: spec must be a JSON document (convert YAML specs to JSON first): %w", err)
+	}
+
+	var swagger2 bool
+	switch {
+	case strings.HasPrefix(stringField(doc, "openapi"), "3."):
+	case stringField(doc, "swagger") == "2.0":
+		swagger2 = true
+	default:
+		return nil, "", fmt.Errorf("openapi: unsupported spec version, want OpenAPI 3.x or Swagger 2.0")
+	}
+
+	r := &resolver{doc: doc}
+	schemes := r.securitySchemes(swagger2)
+	globalSecurity, hasGlobal := doc["security"].([]interface{})
+
+	paths := mapField(doc, "paths")
+	pathKeys := make([]string, 0, len(paths))
+	for path := range paths {
+		pathKeys = append(pathKeys, path)
+	}
+	sort.Strings(pathKeys)
+
+	var operations []Operation
+	for _, path := range pathKeys {
+		item := r.resolve(paths[path])
+		pathParams, _ := item["parameters"].([]interface{})
+		for _, method := range httpMethods {
+			rawOp, ok := item[method].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			op := Operation{
+				ID:          stringField(rawOp, "operationId"),
+				Method:      strings.ToUpper(method),
+				Path:        path,
+				Summary:     stringField(rawOp, "summary"),
+				Description: stringField(rawOp, "description"),
+			}
+			if op.ID == "" {
+				op.ID = method + "_" + path
+			}
+			for _, tag := range sliceField(rawOp, "tags") {
+				if s, ok := tag.(string); ok {
+					op.Tags = append(op.Tags, s)
+				}
+			}
+
+			opParams, _ := rawOp["parameters"].([]interface{})
+			op.Parameters, op.Body, op.BodyRequired = r.parameters(pathParams, opParams, swagger2)
+			if !swagger2 {
+				op.Body, op.BodyRequired = r.requestBody(rawOp["requestBody"])
+			}
+
+			security := globalSecurity
+			if opSecurity, ok := rawOp["security"].([]interface{}); ok {
+				security = opSecurity
+			} else if !hasGlobal {
+				security = nil
+			}
+			op.Security = effectiveSecurity(security, schemes)
+
+			operations = append(operations, op)
+		}
+	}
+
+	baseURL, err := serverURL(doc, swagger2, specURL)
+	if err != nil {
+		return nil, "", err
+	}
+	return operations, baseURL, nil
+}
+
+// serverURL 推导 API 根地址
+func serverURL(doc map[string]interface{}, swagger2 bool, specURL string) (string, error) {
+	var raw string
+	if swagger2 {
+		host := stringField(doc, "host")
+		basePath := stringField(doc, "basePath")
+		if host != "" {
+			scheme := "https"
+			if schemes := sliceField(doc, "schemes"); len(schemes) > 0 {
+				if s, ok := schemes[0].(string); ok {
+					scheme = s
+				}
+			}
+			raw = scheme + "://" + host + basePath
+		} else {
+			raw = basePath
+		}
+	} else if servers := sliceField(doc, "servers"); len(servers) > 0 {
+		server, _ := servers[0].(map[string]interface{})
+		raw = stringField(server, "url")
+		// 用默认值替换服务器变量
+		for name, v := range mapField(server, "variables") {
+			variable, _ := v.(map[string]interface{})
+			raw = strings.ReplaceAll(raw, "{"+name+"}", fmt.Sprint(variable["default"]))
+		}
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", fmt.Errorf("openapi: invalid server url %q: %w", raw, err)
+	}
+	if !u.IsAbs() {
+		if specURL == "" {
+			if raw == "" || raw == "/" {
+				return "", nil
+			}
+			return "", fmt.Errorf("openapi: relative server url %q needs WithBaseURL", raw)
+		}
+		base, err := url.Parse(specURL)
+		if err != nil {
+			return "", fmt.Errorf("openapi: invalid spec url: %w", err)
+		}
+		if raw == "" {
+			u = &url.URL{Scheme: base.Scheme, Host: base.Host}
+		} else {
+			u = base.ResolveReference(u)
+		}
+	}
+	return strings.TrimRight(u.String(), "/"), nil
+}
+
+// effectiveSecurity 将安全需求解析为安全方案，忽略未定义的方案
+func effectiveSecurity(requirements []interface{}, schemes map[string]SecurityScheme) []SecurityScheme {
+	var result []SecurityScheme
+	seen := make(map[string]bool)
+	for _, req := range requirements {
+		names, _ := req.(map[string]interface{})
+		keys := make([]string, 0, len(names))
+		for name := range names {
+			keys = append(keys, name)
+		}
+		sort.Strings(keys)
+		for _, name := range keys {
+			if scheme, ok := schemes[name]; ok && !seen[name] {
+				seen[name] = true
+				result = append(result, scheme)
+			}
+		}
+	}
+	return result
+}
+
+// toolName 将操作 ID 规范化为工具名称：只保留字母、数字、下划线和连字符，最长 64 个字符
+func toolName(id string) string {
+	var sb strings.Builder
+	lastUnderscore := false
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-':
+			sb.WriteRune(r)
+			lastUnderscore = false
+		default:
+			if !lastUnderscore {
+				sb.WriteByte('_')
+				lastUnderscore = true
+			}
+		}
+	}
+	name := strings.Trim(sb.String(), "_")
+	if len(name) > 64 {
+		name = strings.TrimRight(name[:64], "_")
+	}
+	if name == "" {
+		name = "operation"
+	}
+	return name
+}
+
+// stringField 读取字符串字段
+func stringField(m map[string]interface{}, key string) string {
+	s, _ := m[key].(string)
+	return s
+}
+
+// mapField 读取对象字段
+func mapField(m map[string]interface{}, key string) map[string]interface{} {
+	v, _ := m[key].(map[string]interface{})
+	return v
+}
+
+// sliceField 读取数组字段
+func sliceField(m map[string]interface{}, key string) []interface{} {
+	v, _ := m[key].([]interface{})
+	return v
+}
@@ -0,0 +1,40 @@
+package openapi
+
+import (
+	"context"
+
+	"github.com/ahhsitt/helloagents-go/pkg/tools"
+)
+
+// Provider 以规范为来源的工具提供者
+//
+// 通过 Registry.RegisterProvider 注册后，调用 Registry.RefreshProvider 即可重新拉取规范，
+// 新增、删除或修改的操作随之同步到注册表。
+//
+// 使用示例：
+//
+//	provider := openapi.NewProvider("petstore", specURL, openapi.WithAPIKey(key))
+//	registry.RegisterProvider(ctx, provider)
+type Provider struct {
+	name   string
+	source string
+	opts   []Option
+}
+
+// NewProvider 创建规范工具提供者，source 与 NewToolsFromSpec 相同
+func NewProvider(name, source string, opts ...Option) *Provider {
+	return &Provider{name: name, source: source, opts: opts}
+}
+
+// Name 返回提供者名称
+func (p *Provider) Name() string {
+	return p.name
+}
+
+// Tools 读取规范并生成工具
+func (p *Provider) Tools(ctx context.Context) ([]tools.Tool, error) {
+	return NewToolsFromSpec(ctx, p.source, p.opts...)
+}
+
+// compile-time interface check
+var _ tools.ToolProvider = (*Provider)(nil)
@@ -0,0 +1,278 @@
+package openapi
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ahhsitt/helloagents-go/pkg/tools"
+)
+
+// maxSchemaDepth Schema 展开的最大深度，超出后不再展开（防止循环引用）
+const maxSchemaDepth = 8
+
+// resolver 解析规范文档内的 $ref 引用
+type resolver struct {
+	doc map[string]interface{}
+}
+
+// resolve 返回对象节点，$ref 引用被替换为目标节点（只支持文档内引用）
+func (r *resolver) resolve(node interface{}) map[string]interface{} {
+	m, _ := node.(map[string]interface{})
+	for i := 0; i < maxSchemaDepth && m != nil; i++ {
+		ref, ok := m["$ref"].(string)
+		if !ok {
+			return m
+		}
+		m = r.lookup(ref)
+	}
+	return m
+}
+
+// lookup 按 JSON Pointer 查找节点
+func (r *resolver) lookup(ref string) map[string]interface{} {
+	if !strings.HasPrefix(ref, "#/") {
+		return nil
+	}
+	var node interface{} = r.doc
+	for _, part := range strings.Split(ref[2:], "/") {
+		part = strings.ReplaceAll(strings.ReplaceAll(part, "~1", "/"), "~0", "~")
+		m, ok := node.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		node = m[part]
+	}
+	m, _ := node.(map[string]interface{})
+	return m
+}
+
+// securitySchemes 读取规范定义的安全方案
+func (r *resolver) securitySchemes(swagger2 bool) map[string]SecurityScheme {
+	defs := mapField(r.doc, "securityDefinitions")
+	if !swagger2 {
+		defs = mapField(mapField(r.doc, "components"), "securitySchemes")
+	}
+
+	schemes := make(map[string]SecurityScheme, len(defs))
+	for name, raw := range defs {
+		def := r.resolve(raw)
+		if def == nil {
+			continue
+		}
+		scheme := SecurityScheme{
+			Name:      name,
+			Type:      stringField(def, "type"),
+			In:        stringField(def, "in"),
+			ParamName: stringField(def, "name"),
+			Scheme:    strings.ToLower(stringField(def, "scheme")),
+		}
+		if scheme.Type == "basic" {
+			scheme.Scheme = "basic"
+		}
+		schemes[name] = scheme
+	}
+	return schemes
+}
+
+// parameters 合并路径级和操作级参数（同名同位置时操作级优先）
+//
+// Swagger 2.0 的 in: body 参数作为请求体返回，formData 参数不支持，被忽略。
+func (r *resolver) parameters(pathParams, opParams []interface{}, swagger2 bool) ([]Parameter, *tools.PropertySchema, bool) {
+	var params []Parameter
+	var body *tools.PropertySchema
+	var bodyRequired bool
+	index := make(map[string]int)
+
+	for _, raw := range append(append([]interface{}{}, pathParams...), opParams...) {
+		p := r.resolve(raw)
+		if p == nil {
+			continue
+		}
+		name, in := stringField(p, "name"), stringField(p, "in")
+		required, _ := p["required"].(bool)
+
+		switch in {
+		case "path", "query", "header", "cookie":
+		case "body":
+			if swagger2 {
+				schema := r.schema(p["schema"], 0)
+				if schema.Description == "" {
+					schema.Description = stringField(p, "description")
+				}
+				body, bodyRequired = &schema, required
+			}
+			continue
+		default:
+			continue
+		}
+
+		// Swagger 2.0 的参数类型直接声明在参数上
+		schemaNode := p["schema"]
+		if swagger2 {
+			schemaNode = p
+		}
+		param := Parameter{
+			Name:        name,
+			In:          in,
+			Description: stringField(p, "description"),
+			Required:    required || in == "path",
+			Schema:      r.schema(schemaNode, 0),
+		}
+		if param.Schema.Description == "" {
+			param.Schema.Description = param.Description
+		}
+
+		key := in + ":" + name
+		if i, ok := index[key]; ok {
+			params[i] = param
+			continue
+		}
+		index[key] = len(params)
+		params = append(params, param)
+	}
+	return params, body, bodyRequired
+}
+
+// requestBody 读取 OpenAPI 3 的 JSON 请求体 Schema
+func (r *resolver) requestBody(raw interface{}) (*tools.PropertySchema, bool) {
+	body := r.resolve(raw)
+	if body == nil {
+		return nil, false
+	}
+	content := mapField(body, "content")
+	media := mapField(content, "application/json")
+	if media == nil {
+		// 兼容 application/vnd.api+json 等 JSON 媒体类型
+		types := make([]string, 0, len(content))
+		for t := range content {
+			types = append(types, t)
+		}
+		sort.Strings(types)
+		for _, t := range types {
+			if strings.Contains(t, "json") {
+				media = mapField(content, t)
+				break
+			}
+		}
+	}
+	if media == nil {
+		return nil, false
+	}
+
+	schema := r.schema(media["schema"], 0)
+	if schema.Description == "" {
+		schema.Description = stringField(body, "description")
+	}
+	required, _ := body["required"].(bool)
+	return &schema, required
+}
+
+// schema 将 JSON Schema 节点转换为 PropertySchema
+func (r *resolver) schema(raw interface{}, depth int) tools.PropertySchema {
+	node := r.resolve(raw)
+	if node == nil || depth > maxSchemaDepth {
+		return tools.PropertySchema{Type: "object"}
+	}
+
+	// allOf 合并属性；oneOf / anyOf 取第一个分支
+	if parts := sliceField(node, "allOf"); len(parts) > 0 {
+		merged := tools.PropertySchema{Type: "object", Properties: make(map[string]tools.PropertySchema)}
+		for _, part := range parts {
+			sub := r.schema(part, depth+1)
+			for k, v := range sub.Properties {
+				merged.Properties[k] = v
+			}
+			merged.Required = append(merged.Required, sub.Required...)
+			if merged.Description == "" {
+				merged.Description = sub.Description
+			}
+		}
+		if d := stringField(node, "description"); d != "" {
+			merged.Description = d
+		}
+		return merged
+	}
+	for _, key := range []string{"oneOf", "anyOf"} {
+		if parts := sliceField(node, key); len(parts) > 0 {
+			sub := r.schema(parts[0], depth+1)
+			if d := stringField(node, "description"); d != "" {
+				sub.Description = d
+			}
+			return sub
+		}
+	}
+
+	s := tools.PropertySchema{
+		Type:        schemaType(node),
+		Description: stringField(node, "description"),
+		Default:     node["default"],
+	}
+	// 规范中的正则为 ECMA 语法，Go 无法编译的（如先行断言）忽略
+	if pattern := stringField(node, "pattern"); pattern != "" {
+		if _, err := regexp.Compile(pattern); err == nil {
+			s.Pattern = pattern
+		}
+	}
+	for _, v := range sliceField(node, "enum") {
+		if v != nil {
+			s.Enum = append(s.Enum, fmt.Sprint(v))
+		}
+	}
+	if v, ok := node["minimum"].(float64); ok {
+		s.Minimum = &v
+	}
+	if v, ok := node["maximum"].(float64); ok {
+		s.Maximum = &v
+	}
+	if v, ok := node["minLength"].(float64); ok {
+		n := int(v)
+		s.MinLength = &n
+	}
+	if v, ok := node["maxLength"].(float64); ok {
+		n := int(v)
+		s.MaxLength = &n
+	}
+
+	switch s.Type {
+	case "array":
+		items := r.schema(node["items"], depth+1)
+		s.Items = &items
+	case "object":
+		if props := mapField(node, "properties"); len(props) > 0 {
+			s.Properties = make(map[string]tools.PropertySchema, len(props))
+			for name, prop := range props {
+				s.Properties[name] = r.schema(prop, depth+1)
+			}
+		}
+		for _, v := range sliceField(node, "required") {
+			if name, ok := v.(string); ok {
+				s.Required = append(s.Required, name)
+			}
+		}
+	}
+	return s
+}
+
+// schemaType 读取 Schema 类型，兼容 OpenAPI 3.1 的类型数组，未声明时根据结构推断
+func schemaType(node map[string]interface{}) string {
+	switch t := node["type"].(type) {
+	case string:
+		return t
+	case []interface{}:
+		for _, v := range t {
+			if s, ok := v.(string); ok && s != "null" {
+				return s
+			}
+		}
+	}
+	switch {
+	case node["properties"] != nil:
+		return "object"
+	case node["items"] != nil:
+		return "array"
+	default:
+		return "string"
+	}
+}
@@ -0,0 +1,259 @@
+package openapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/ahhsitt/helloagents-go/pkg/tools"
+)
+
+// maxResponseBytes 读取响应体的字节上限
+const maxResponseBytes = 1024 * 1024
+
+// binding 工具参数与操作参数的对应关系
+type binding struct {
+	arg   string
+	param Parameter
+}
+
+// OperationTool 由规范操作生成的工具
+type OperationTool struct {
+	name     string
+	baseURL  string
+	op       Operation
+	cfg      *config
+	bindings []binding
+	// bodyArg 请求体对应的工具参数名（无请求体时为空）
+	bodyArg string
+}
+
+// newOperationTool 创建操作工具，参数名冲突时以位置作前缀区分（如 header_id）
+func newOperationTool(name, baseURL string, op Operation, cfg *config) *OperationTool {
+	t := &OperationTool{name: name, baseURL: baseURL, op: op, cfg: cfg}
+
+	taken := make(map[string]bool)
+	for _, p := range op.Parameters {
+		arg := p.Name
+		if taken[arg] {
+			arg = p.In + "_" + p.Name
+		}
+		taken[arg] = true
+		t.bindings = append(t.bindings, binding{arg: arg, param: p})
+	}
+	if op.Body != nil {
+		t.bodyArg = "body"
+		if taken[t.bodyArg] {
+			t.bodyArg = "request_body"
+		}
+	}
+	return t
+}
+
+// Operation 返回工具对应的规范操作
+func (t *OperationTool) Operation() Operation {
+	return t.op
+}
+
+// Name 返回工具名称
+func (t *OperationTool) Name() string {
+	return t.name
+}
+
+// Description 返回工具描述：操作摘要和描述，附带请求方法和路径
+func (t *OperationTool) Description() string {
+	var parts []string
+	if t.op.Summary != "" {
+		parts = append(parts, t.op.Summary)
+	}
+	if d := strings.TrimSpace(t.op.Description); d != "" && d != t.op.Summary {
+		parts = append(parts, d)
+	}
+	parts = append(parts, fmt.Sprintf("Endpoint: %s %s", t.op.Method, t.op.Path))
+	return strings.Join(parts, "\n")
+}
+
+// Parameters 返回参数 Schema
+func (t *OperationTool) Parameters() tools.ParameterSchema {
+	schema := tools.ParameterSchema{
+		Type:       "object",
+		Properties: make(map[string]tools.PropertySchema, len(t.bindings)+1),
+	}
+	for _, b := range t.bindings {
+		schema.Properties[b.arg] = b.param.Schema
+		if b.param.Required {
+			schema.Required = append(schema.Required, b.arg)
+		}
+	}
+	if t.bodyArg != "" {
+		body := *t.op.Body
+		if body.Description == "" {
+			body.Description = "JSON request body"
+		}
+		schema.Properties[t.bodyArg] = body
+		if t.op.BodyRequired {
+			schema.Required = append(schema.Required, t.bodyArg)
+		}
+	}
+	return schema
+}
+
+// Execute 按操作定义发送 HTTP 请求，返回状态、内容类型和（截断后的）响应体
+func (t *OperationTool) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
+	path := t.op.Path
+	query := url.Values{}
+	headers := make(http.Header)
+	var cookies []*http.Cookie
+
+	for _, b := range t.bindings {
+		value, ok := args[b.arg]
+		if !ok || value == nil {
+			if b.param.Required {
+				return "", fmt.Errorf("missing required parameter: %s", b.arg)
+			}
+			continue
+		}
+		switch b.param.In {
+		case "path":
+			path = strings.ReplaceAll(path, "{"+b.param.Name+"}", url.PathEscape(formatValue(value)))
+		case "query":
+			if list, ok := value.([]interface{}); ok {
+				for _, item := range list {
+					query.Add(b.param.Name, formatValue(item))
+				}
+			} else {
+				query.Add(b.param.Name, formatValue(value))
+			}
+		case "header":
+			headers.Set(b.param.Name, formatValue(value))
+		case "cookie":
+			cookies = append(cookies, &http.Cookie{Name: b.param.Name, Value: formatValue(value)})
+		}
+	}
+
+	var body []byte
+	if t.bodyArg != "" {
+		switch value := args[t.bodyArg].(type) {
+		case nil:
+			if t.op.BodyRequired {
+				return "", fmt.Errorf("missing required parameter: %s", t.bodyArg)
+			}
+		case string:
+			if !json.Valid([]byte(value)) {
+				return "", fmt.Errorf("%s must be a JSON value", t.bodyArg)
+			}
+			body = []byte(value)
+		default:
+			data, err := json.Marshal(value)
+			if err != nil {
+				return "", fmt.Errorf("failed to encode %s as JSON: %w", t.bodyArg, err)
+			}
+			body = data
+		}
+	}
+
+	// 认证信息只作用于本次请求，不修改共享配置
+	for _, scheme := range t.op.Security {
+		if scheme.Type != "apiKey" || t.cfg.apiKey == "" {
+			continue
+		}
+		switch scheme.In {
+		case "header":
+			headers.Set(scheme.ParamName, t.cfg.apiKey)
+		case "query":
+			query.Set(scheme.ParamName, t.cfg.apiKey)
+		case "cookie":
+			cookies = append(cookies, &http.Cookie{Name: scheme.ParamName, Value: t.cfg.apiKey})
+		}
+	}
+
+	target := t.baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	if t.cfg.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, t.cfg.timeout)
+		defer cancel()
+	}
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, t.op.Method, target, reader)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header = headers
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	for _, c := range cookies {
+		req.AddCookie(c)
+	}
+	if t.cfg.basicUser != "" {
+		req.SetBasicAuth(t.cfg.basicUser, t.cfg.basicPass)
+	}
+	if t.cfg.bearer != "" {
+		req.Header.Set("Authorization", "Bearer "+t.cfg.bearer)
+	}
+	for k, v := range t.cfg.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := t.cfg.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("HTTP %s\n", resp.Status))
+	if ct := resp.Header.Get("Content-Type"); ct != "" {
+		sb.WriteString(fmt.Sprintf("Content-Type: %s\n", ct))
+	}
+	sb.WriteString("\n")
+	text := []rune(string(data))
+	if len(text) > t.cfg.maxChars {
+		sb.WriteString(string(text[:t.cfg.maxChars]))
+		sb.WriteString(fmt.Sprintf("\n... [truncated: response exceeds %d characters]", t.cfg.maxChars))
+	} else {
+		sb.WriteString(string(text))
+	}
+	return sb.String(), nil
+}
+
+// formatValue 将参数值格式化为字符串，整数值的浮点数不带小数部分
+func formatValue(v interface{}) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case []interface{}:
+		items := make([]string, len(val))
+		for i, item := range val {
+			items[i] = formatValue(item)
+		}
+		return strings.Join(items, ",")
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+// compile-time interface check
+var _ tools.Tool = (*OperationTool)(nil)
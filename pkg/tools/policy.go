@@ -0,0 +1,256 @@
+package tools
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/ahhsitt/helloagents-go/pkg/core/errors"
+)
+
+// DefaultCircuitCooldown 未设置 Cooldown 时的熔断持续时间
+const DefaultCircuitCooldown = 30 * time.Second
+
+// ExecutionPolicy 工具执行策略
+//
+// 限制单个工具的执行频率和并发数，并在工具连续失败时熔断，
+// 避免 Agent 循环反复调用不稳定的外部服务。各项限制的零值表示不启用。
+//
+// 熔断器在连续失败 FailureThreshold 次后打开，Cooldown 内的调用直接返回 errors.ErrCircuitOpen；
+// 冷却结束后放行一次试探调用，成功则关闭熔断器，失败则重新打开。
+// 调用方取消上下文导致的错误不计入失败次数。
+type ExecutionPolicy struct {
+	// MaxQPS 每秒最多执行次数（令牌桶补充速率），<= 0 表示不限制
+	MaxQPS float64
+	// Burst 令牌桶容量，<= 0 时取 MaxQPS 向上取整（至少为 1）
+	Burst int
+	// MaxConcurrent 最大并发执行数，<= 0 表示不限制
+	MaxConcurrent int
+	// MaxWait 超出频率或并发限制时的最长等待时间，0 表示立即拒绝
+	MaxWait time.Duration
+	// FailureThreshold 触发熔断的连续失败次数，<= 0 表示不熔断
+	FailureThreshold int
+	// Cooldown 熔断持续时间，<= 0 时使用 DefaultCircuitCooldown
+	Cooldown time.Duration
+}
+
+// PolicyMiddleware 按执行策略限制工具调用的中间件
+//
+// 每个工具按名称独立计算频率、并发和熔断状态，状态在多次 Registry.Use 之间保留。
+// 超出频率限制返回 errors.ErrRateLimited，超出并发限制返回 errors.ErrToolBusy，
+// 熔断期间返回 errors.ErrCircuitOpen。
+//
+// 使用示例:
+//
+//	// 所有工具共用同一策略
+//	registry.Use(tools.PolicyMiddleware(tools.ExecutionPolicy{MaxQPS: 5, MaxConcurrent: 2}))
+//	// 单个工具使用独立策略
+//	registry.Register(tools.ApplyPolicy(searchTool, tools.ExecutionPolicy{
+//	    MaxQPS:           1,
+//	    FailureThreshold: 3,
+//	    Cooldown:         time.Minute,
+//	}))
+func PolicyMiddleware(policy ExecutionPolicy) Middleware {
+	var mu sync.Mutex
+	guards := make(map[string]*policyGuard)
+
+	return func(next Tool) Tool {
+		name := next.Name()
+		mu.Lock()
+		guard, ok := guards[name]
+		if !ok {
+			guard = newPolicyGuard(policy)
+			guards[name] = guard
+		}
+		mu.Unlock()
+
+		return WrapExecute(next, func(ctx context.Context, args map[string]interface{}) (string, error) {
+			release, probe, err := guard.acquire(ctx, name)
+			if err != nil {
+				return "", err
+			}
+			defer release()
+
+			result, err := next.Execute(ctx, args)
+			guard.record(err, probe)
+			return result, err
+		})
+	}
+}
+
+// ApplyPolicy 为单个工具应用执行策略
+//
+// 返回的工具可以直接注册到注册表；与注册表级别的 PolicyMiddleware 同时使用时两者都生效。
+func ApplyPolicy(tool Tool, policy ExecutionPolicy) Tool {
+	return PolicyMiddleware(policy)(tool)
+}
+
+// policyGuard 单个工具的执行策略状态
+type policyGuard struct {
+	policy ExecutionPolicy
+	// burst 令牌桶容量
+	burst float64
+	// slots 并发槽位，为 nil 时不限制并发
+	slots chan struct{}
+
+	mu sync.Mutex
+	// tokens 当前令牌数，预约等待时可以为负
+	tokens float64
+	// last 上次补充令牌的时间
+	last time.Time
+	// failures 连续失败次数
+	failures int
+	// openUntil 熔断结束时间
+	openUntil time.Time
+	// probing 是否有试探调用正在执行
+	probing bool
+}
+
+// newPolicyGuard 创建执行策略状态
+func newPolicyGuard(policy ExecutionPolicy) *policyGuard {
+	if policy.Cooldown <= 0 {
+		policy.Cooldown = DefaultCircuitCooldown
+	}
+	g := &policyGuard{policy: policy}
+	if policy.MaxQPS > 0 {
+		g.burst = float64(policy.Burst)
+		if g.burst <= 0 {
+			g.burst = math.Max(1, math.Ceil(policy.MaxQPS))
+		}
+		g.tokens = g.burst
+		g.last = time.Now()
+	}
+	if policy.MaxConcurrent > 0 {
+		g.slots = make(chan struct{}, policy.MaxConcurrent)
+	}
+	return g
+}
+
+// acquire 依次检查熔断、频率和并发限制，返回释放并发槽位的函数和是否为试探调用
+func (g *policyGuard) acquire(ctx context.Context, name string) (release func(), probe bool, err error) {
+	probe, err = g.allowCircuit(name, time.Now())
+	if err != nil {
+		return nil, false, err
+	}
+	// 未能执行的试探调用不占用试探名额
+	defer func() {
+		if err != nil && probe {
+			g.mu.Lock()
+			g.probing = false
+			g.mu.Unlock()
+		}
+	}()
+
+	if err = g.waitRate(ctx, name); err != nil {
+		return nil, probe, err
+	}
+	if g.slots == nil {
+		return func() {}, probe, nil
+	}
+
+	select {
+	case g.slots <- struct{}{}:
+		return func() { <-g.slots }, probe, nil
+	default:
+	}
+	if g.policy.MaxWait <= 0 {
+		return nil, probe, fmt.Errorf("%w: %s allows %d concurrent executions", errors.ErrToolBusy, name, g.policy.MaxConcurrent)
+	}
+	timer := time.NewTimer(g.policy.MaxWait)
+	defer timer.Stop()
+	select {
+	case g.slots <- struct{}{}:
+		return func() { <-g.slots }, probe, nil
+	case <-timer.C:
+		return nil, probe, fmt.Errorf("%w: %s allows %d concurrent executions", errors.ErrToolBusy, name, g.policy.MaxConcurrent)
+	case <-ctx.Done():
+		return nil, probe, errors.ErrContextCanceled
+	}
+}
+
+// allowCircuit 检查熔断器状态，冷却结束后的第一次调用作为试探调用放行
+func (g *policyGuard) allowCircuit(name string, now time.Time) (probe bool, err error) {
+	threshold := g.policy.FailureThreshold
+	if threshold <= 0 {
+		return false, nil
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.failures < threshold {
+		return false, nil
+	}
+	if now.Before(g.openUntil) {
+		return false, fmt.Errorf("%w: %s failed %d times in a row, retry after %v",
+			errors.ErrCircuitOpen, name, g.failures, g.openUntil.Sub(now).Round(time.Millisecond))
+	}
+	if g.probing {
+		return false, fmt.Errorf("%w: %s is being probed after cooldown", errors.ErrCircuitOpen, name)
+	}
+	g.probing = true
+	return true, nil
+}
+
+// waitRate 从令牌桶取走一个令牌，需要等待时最多等待 MaxWait
+func (g *policyGuard) waitRate(ctx context.Context, name string) error {
+	if g.policy.MaxQPS <= 0 {
+		return nil
+	}
+
+	g.mu.Lock()
+	now := time.Now()
+	g.tokens = math.Min(g.burst, g.tokens+now.Sub(g.last).Seconds()*g.policy.MaxQPS)
+	g.last = now
+	wait := time.Duration((1 - g.tokens) / g.policy.MaxQPS * float64(time.Second))
+	if wait > g.policy.MaxWait {
+		g.mu.Unlock()
+		return fmt.Errorf("%w: %s allows %g calls per second", errors.ErrRateLimited, name, g.policy.MaxQPS)
+	}
+	// 预约令牌后在锁外等待
+	g.tokens--
+	g.mu.Unlock()
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		g.mu.Lock()
+		g.tokens++
+		g.mu.Unlock()
+		return errors.ErrContextCanceled
+	}
+}
+
+// record 根据执行结果更新熔断器状态
+func (g *policyGuard) record(err error, probe bool) {
+	threshold := g.policy.FailureThreshold
+	if threshold <= 0 {
+		return
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if probe {
+		g.probing = false
+	}
+	switch {
+	case err == nil:
+		g.failures = 0
+	case stderrors.Is(err, context.Canceled) || stderrors.Is(err, errors.ErrContextCanceled):
+		// 调用方取消不代表工具故障
+	default:
+		g.failures++
+		if probe || g.failures >= threshold {
+			g.failures = max(g.failures, threshold)
+			g.openUntil = time.Now().Add(g.policy.Cooldown)
+		}
+	}
+}
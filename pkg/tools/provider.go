@@ -0,0 +1,199 @@
+package tools
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"reflect"
+
+	"github.com/ahhsitt/helloagents-go/pkg/core/errors"
+)
+
+// ToolProvider 动态工具来源
+//
+// 提供者在运行时提供一组工具（如 MCP 服务器管理器、OpenAPI 导入器），
+// 通过 Registry.RegisterProvider 注册后，其工具随提供者刷新而增删。
+type ToolProvider interface {
+	// Name 返回提供者名称，在同一注册表中唯一
+	Name() string
+	// Tools 返回提供者当前的工具列表
+	//
+	// 部分工具不可用时可以同时返回可用的工具和错误。
+	Tools(ctx context.Context) ([]Tool, error)
+}
+
+// WatchableToolProvider 能主动通知工具列表变化的提供者
+//
+// 注册表订阅 Watch 通道，每收到一次通知就刷新该提供者的工具。
+// 实现时可以使用 ChangeNotifier。
+type WatchableToolProvider interface {
+	ToolProvider
+	// Watch 返回工具列表变化通知通道，ctx 取消后关闭
+	Watch(ctx context.Context) <-chan struct{}
+}
+
+// providerEntry 已注册的提供者
+type providerEntry struct {
+	provider ToolProvider
+	// names 提供者当前注册的工具名称
+	names map[string]struct{}
+	// stop 停止监听变化通知
+	stop context.CancelFunc
+}
+
+// RegisterProvider 注册工具提供者并同步其工具
+//
+// ctx 只用于首次同步。提供者实现 WatchableToolProvider 时，注册表在后台监听变化通知并自动刷新，
+// 直到 UnregisterProvider；刷新失败以 EventProviderError 事件通知。
+// 首次同步失败时提供者仍保持注册，返回同步错误。
+func (r *Registry) RegisterProvider(ctx context.Context, provider ToolProvider) error {
+	if provider == nil || provider.Name() == "" {
+		return errors.ErrInvalidTool
+	}
+	name := provider.Name()
+
+	r.mu.Lock()
+	if _, exists := r.providers[name]; exists {
+		r.mu.Unlock()
+		return fmt.Errorf("%w: provider %s", errors.ErrToolProviderAlreadyRegistered, name)
+	}
+	entry := &providerEntry{provider: provider, names: make(map[string]struct{})}
+
+	// 先订阅再同步，避免遗漏同步期间的变化
+	if watchable, ok := provider.(WatchableToolProvider); ok {
+		watchCtx, stop := context.WithCancel(context.Background())
+		entry.stop = stop
+		changes := watchable.Watch(watchCtx)
+		go func() {
+			for range changes {
+				if watchCtx.Err() != nil {
+					return
+				}
+				_ = r.RefreshProvider(watchCtx, name)
+			}
+		}()
+	}
+	r.providers[name] = entry
+	r.mu.Unlock()
+
+	return r.RefreshProvider(ctx, name)
+}
+
+// RefreshProvider 重新获取提供者的工具并同步到注册表
+//
+// 新增的工具被注册，消失的工具被取消注册，定义发生变化的工具被替换，并发出对应的事件。
+// 与直接注册的工具或其他提供者的工具重名时跳过该工具并在错误中报告。
+// 提供者返回错误且没有任何工具时保留现有工具。
+func (r *Registry) RefreshProvider(ctx context.Context, name string) error {
+	r.mu.RLock()
+	entry, ok := r.providers[name]
+	r.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("%w: provider %s", errors.ErrToolProviderNotFound, name)
+	}
+
+	list, err := entry.provider.Tools(ctx)
+	if err != nil {
+		r.publish(ToolEvent{Type: EventProviderError, Provider: name, Error: err.Error()})
+		if len(list) == 0 {
+			return fmt.Errorf("provider %s: %w", name, err)
+		}
+	}
+	errs := []error{err}
+
+	r.mu.Lock()
+	if r.providers[name] != entry {
+		r.mu.Unlock()
+		return fmt.Errorf("%w: provider %s", errors.ErrToolProviderNotFound, name)
+	}
+
+	var events []ToolEvent
+	current := make(map[string]struct{}, len(list))
+	for _, tool := range list {
+		if tool == nil || tool.Name() == "" {
+			continue
+		}
+		toolName := tool.Name()
+		if _, dup := current[toolName]; dup {
+			continue
+		}
+		if _, owned := entry.names[toolName]; !owned {
+			if _, exists := r.tools[toolName]; exists {
+				errs = append(errs, fmt.Errorf("provider %s: %w: %s", name, errors.ErrToolAlreadyRegistered, toolName))
+				continue
+			}
+		}
+		current[toolName] = struct{}{}
+
+		old, existed := r.tools[toolName]
+		r.tools[toolName] = tool
+		r.wrapped[toolName] = r.wrap(adaptStructured(tool))
+		r.owners[toolName] = name
+		switch {
+		case !existed:
+			events = append(events, ToolEvent{Type: EventToolRegistered, Name: toolName, Provider: name})
+		case !reflect.DeepEqual(ToDefinition(old), ToDefinition(tool)):
+			events = append(events, ToolEvent{Type: EventToolUpdated, Name: toolName, Provider: name})
+		}
+	}
+	for toolName := range entry.names {
+		if _, ok := current[toolName]; !ok {
+			r.removeLocked(toolName)
+			events = append(events, ToolEvent{Type: EventToolUnregistered, Name: toolName, Provider: name})
+		}
+	}
+	entry.names = current
+	if len(events) > 0 {
+		r.version++
+	}
+	r.mu.Unlock()
+
+	r.publish(events...)
+	return stderrors.Join(errs...)
+}
+
+// UnregisterProvider 取消注册提供者，停止监听并移除其全部工具
+func (r *Registry) UnregisterProvider(name string) error {
+	r.mu.Lock()
+	entry, ok := r.providers[name]
+	if !ok {
+		r.mu.Unlock()
+		return fmt.Errorf("%w: provider %s", errors.ErrToolProviderNotFound, name)
+	}
+	delete(r.providers, name)
+	if entry.stop != nil {
+		entry.stop()
+	}
+
+	events := make([]ToolEvent, 0, len(entry.names))
+	for toolName := range entry.names {
+		r.removeLocked(toolName)
+		events = append(events, ToolEvent{Type: EventToolUnregistered, Name: toolName, Provider: name})
+	}
+	if len(events) > 0 {
+		r.version++
+	}
+	r.mu.Unlock()
+
+	r.publish(events...)
+	return nil
+}
+
+// Providers 返回已注册的提供者名称
+func (r *Registry) Providers() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.providers))
+	for name := range r.providers {
+		names = append(names, name)
+	}
+	return names
+}
+
+// removeLocked 移除工具及其归属记录，调用方需持有写锁
+func (r *Registry) removeLocked(name string) {
+	delete(r.tools, name)
+	delete(r.wrapped, name)
+	delete(r.owners, name)
+}
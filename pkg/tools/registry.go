@@ -9,18 +9,66 @@ import (
 // Registry 工具注册表
 //
 // 用于管理和查找已注册的工具。支持并发安全的注册和查询。
+// 通过 Use 添加的中间件会应用到所有工具，Get、All 和 ToDefinitions 返回包装后的工具。
+// 工具也可以来自运行时增删工具的 ToolProvider，变更通过 Watch 通知。
 type Registry struct {
+	// tools 注册的原始工具
 	tools map[string]Tool
-	mu    sync.RWMutex
+	// wrapped 应用中间件后的工具
+	wrapped map[string]Tool
+	// owners 提供者注册的工具所属的提供者名称
+	owners map[string]string
+	// providers 已注册的工具提供者
+	providers map[string]*providerEntry
+	// middlewares 工具中间件，先添加的在外层
+	middlewares []Middleware
+	// version 工具列表版本，每次变更递增
+	version uint64
+	events  registryEvents
+	mu      sync.RWMutex
 }
 
 // NewRegistry 创建新的工具注册表
 func NewRegistry() *Registry {
 	return &Registry{
-		tools: make(map[string]Tool),
+		tools:     make(map[string]Tool),
+		wrapped:   make(map[string]Tool),
+		owners:    make(map[string]string),
+		providers: make(map[string]*providerEntry),
 	}
 }
 
+// Version 返回工具列表版本
+//
+// 每次注册、取消注册或提供者刷新导致工具变化时递增，
+// 调用方可以比较版本判断缓存的工具定义是否需要刷新。
+func (r *Registry) Version() uint64 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.version
+}
+
+// Use 添加工具中间件，先添加的在外层
+//
+// 中间件应用到已注册和之后注册的所有工具。
+func (r *Registry) Use(middlewares ...Middleware) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.middlewares = append(r.middlewares, middlewares...)
+	for name, tool := range r.tools {
+		r.wrapped[name] = r.wrap(adaptStructured(tool))
+	}
+}
+
+// wrap 对工具应用所有中间件
+func (r *Registry) wrap(tool Tool) Tool {
+	if len(r.middlewares) == 0 {
+		return tool
+	}
+	return ChainMiddleware(r.middlewares...)(tool)
+}
+
 // Register 注册工具
 //
 // 如果工具名已存在，将返回错误。
@@ -35,13 +83,17 @@ func (r *Registry) Register(tool Tool) error {
 	}
 
 	r.mu.Lock()
-	defer r.mu.Unlock()
-
 	if _, exists := r.tools[name]; exists {
+		r.mu.Unlock()
 		return errors.ErrToolAlreadyRegistered
 	}
 
 	r.tools[name] = tool
+	r.wrapped[name] = r.wrap(adaptStructured(tool))
+	r.version++
+	r.mu.Unlock()
+
+	r.publish(ToolEvent{Type: EventToolRegistered, Name: name})
 	return nil
 }
 
@@ -72,7 +124,7 @@ func (r *Registry) Get(name string) (Tool, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	tool, exists := r.tools[name]
+	tool, exists := r.wrapped[name]
 	if !exists {
 		return nil, errors.ErrToolNotFound
 	}
@@ -89,15 +141,24 @@ func (r *Registry) Has(name string) bool {
 }
 
 // Unregister 取消注册工具
+//
+// 提供者注册的工具同样可以取消注册，提供者下次刷新时会重新注册。
 func (r *Registry) Unregister(name string) error {
 	r.mu.Lock()
-	defer r.mu.Unlock()
-
 	if _, exists := r.tools[name]; !exists {
+		r.mu.Unlock()
 		return errors.ErrToolNotFound
 	}
 
-	delete(r.tools, name)
+	provider := r.owners[name]
+	if entry, ok := r.providers[provider]; ok {
+		delete(entry.names, name)
+	}
+	r.removeLocked(name)
+	r.version++
+	r.mu.Unlock()
+
+	r.publish(ToolEvent{Type: EventToolUnregistered, Name: name, Provider: provider})
 	return nil
 }
 
@@ -119,7 +180,7 @@ func (r *Registry) All() []Tool {
 	defer r.mu.RUnlock()
 
 	tools := make([]Tool, 0, len(r.tools))
-	for _, tool := range r.tools {
+	for _, tool := range r.wrapped {
 		tools = append(tools, tool)
 	}
 	return tools
@@ -132,11 +193,25 @@ func (r *Registry) Count() int {
 	return len(r.tools)
 }
 
-// Clear 清空所有已注册工具
+// Clear 清空所有已注册工具（保留中间件和提供者，提供者下次刷新时重新注册其工具）
 func (r *Registry) Clear() {
 	r.mu.Lock()
-	defer r.mu.Unlock()
+	events := make([]ToolEvent, 0, len(r.tools))
+	for name := range r.tools {
+		events = append(events, ToolEvent{Type: EventToolUnregistered, Name: name, Provider: r.owners[name]})
+	}
 	r.tools = make(map[string]Tool)
+	r.wrapped = make(map[string]Tool)
+	r.owners = make(map[string]string)
+	for _, entry := range r.providers {
+		entry.names = make(map[string]struct{})
+	}
+	if len(events) > 0 {
+		r.version++
+	}
+	r.mu.Unlock()
+
+	r.publish(events...)
 }
 
 // ToDefinitions 将所有工具转换为定义列表
@@ -145,7 +220,7 @@ func (r *Registry) ToDefinitions() []ToolDefinition {
 	defer r.mu.RUnlock()
 
 	defs := make([]ToolDefinition, 0, len(r.tools))
-	for _, tool := range r.tools {
+	for _, tool := range r.wrapped {
 		defs = append(defs, ToDefinition(tool))
 	}
 	return defs
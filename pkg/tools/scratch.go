@@ -0,0 +1,219 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ahhsitt/helloagents-go/pkg/core/errors"
+)
+
+const (
+	// DefaultScratchMaxEntrySize 单个暂存条目的默认大小上限（字节）
+	DefaultScratchMaxEntrySize = 1 << 20
+	// DefaultScratchMaxTotalSize 暂存区的默认总大小上限（字节）
+	DefaultScratchMaxTotalSize = 16 << 20
+)
+
+// Scratchpad 单次运行内工具共享的暂存区
+//
+// 工具之间可以通过暂存区传递较大的中间数据，而不必经过 LLM 上下文。
+// Agent 在每次 Run 开始时创建暂存区并通过 ctx 传给工具，Run 结束时清空。
+// 条目默认对 LLM 不可见，工具调用 Surface 后，执行器会把该条目附加到本次调用的观察结果中。
+//
+// 使用示例:
+//
+//	func (t *FetchTool) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
+//	    page := fetch(args["url"].(string))
+//	    if scratch, ok := tools.ScratchpadFromContext(ctx); ok {
+//	        _ = scratch.Put("page", page)
+//	        return "页面已保存到暂存区 page", nil
+//	    }
+//	    return page, nil
+//	}
+type Scratchpad struct {
+	entries      map[string]string
+	surfaced     []string
+	size         int
+	maxEntrySize int
+	maxTotalSize int
+	mu           sync.Mutex
+}
+
+// ScratchpadOption 暂存区配置选项
+type ScratchpadOption func(*Scratchpad)
+
+// WithScratchMaxEntrySize 设置单个条目的大小上限（字节），<= 0 表示不限制
+func WithScratchMaxEntrySize(n int) ScratchpadOption {
+	return func(s *Scratchpad) {
+		s.maxEntrySize = n
+	}
+}
+
+// WithScratchMaxTotalSize 设置暂存区的总大小上限（字节），<= 0 表示不限制
+func WithScratchMaxTotalSize(n int) ScratchpadOption {
+	return func(s *Scratchpad) {
+		s.maxTotalSize = n
+	}
+}
+
+// NewScratchpad 创建暂存区
+func NewScratchpad(opts ...ScratchpadOption) *Scratchpad {
+	s := &Scratchpad{
+		entries:      make(map[string]string),
+		maxEntrySize: DefaultScratchMaxEntrySize,
+		maxTotalSize: DefaultScratchMaxTotalSize,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// Put 写入条目，已存在的同名条目会被覆盖
+func (s *Scratchpad) Put(key, value string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.maxEntrySize > 0 && len(value) > s.maxEntrySize {
+		return fmt.Errorf("%w: %q is %d bytes, limit %d", errors.ErrScratchEntryTooLarge, key, len(value), s.maxEntrySize)
+	}
+
+	size := s.size - len(s.entries[key]) + len(value)
+	if s.maxTotalSize > 0 && size > s.maxTotalSize {
+		return fmt.Errorf("%w: %d bytes would exceed limit %d", errors.ErrScratchFull, size, s.maxTotalSize)
+	}
+
+	s.entries[key] = value
+	s.size = size
+	return nil
+}
+
+// Get 读取条目
+func (s *Scratchpad) Get(key string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	value, ok := s.entries[key]
+	return value, ok
+}
+
+// Delete 删除条目
+func (s *Scratchpad) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.size -= len(s.entries[key])
+	delete(s.entries, key)
+}
+
+// Keys 返回按字典序排列的条目名称
+func (s *Scratchpad) Keys() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys := make([]string, 0, len(s.entries))
+	for key := range s.entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Size 返回所有条目的总大小（字节）
+func (s *Scratchpad) Size() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.size
+}
+
+// Surface 标记条目在当前工具调用结束后作为观察结果提供给 LLM
+func (s *Scratchpad) Surface(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.entries[key]; !ok {
+		return fmt.Errorf("%w: %q", errors.ErrScratchEntryNotFound, key)
+	}
+	for _, k := range s.surfaced {
+		if k == key {
+			return nil
+		}
+	}
+	s.surfaced = append(s.surfaced, key)
+	return nil
+}
+
+// ScratchEntry 暂存区条目
+type ScratchEntry struct {
+	// Key 条目名称
+	Key string
+	// Value 条目内容
+	Value string
+}
+
+// DrainSurfaced 返回并清除待展示的条目，按 Surface 调用顺序排列
+func (s *Scratchpad) DrainSurfaced() []ScratchEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries := make([]ScratchEntry, 0, len(s.surfaced))
+	for _, key := range s.surfaced {
+		if value, ok := s.entries[key]; ok {
+			entries = append(entries, ScratchEntry{Key: key, Value: value})
+		}
+	}
+	s.surfaced = nil
+	return entries
+}
+
+// Clear 清空暂存区
+func (s *Scratchpad) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	clear(s.entries)
+	s.surfaced = nil
+	s.size = 0
+}
+
+// scratchpadKey 暂存区在 context 中的键
+type scratchpadKey struct{}
+
+// ContextWithScratchpad 返回携带暂存区的 context
+func ContextWithScratchpad(ctx context.Context, s *Scratchpad) context.Context {
+	return context.WithValue(ctx, scratchpadKey{}, s)
+}
+
+// ScratchpadFromContext 从 context 中获取暂存区
+func ScratchpadFromContext(ctx context.Context) (*Scratchpad, bool) {
+	s, ok := ctx.Value(scratchpadKey{}).(*Scratchpad)
+	return s, ok && s != nil
+}
+
+// appendSurfaced 将工具标记展示的暂存条目附加到观察结果
+func appendSurfaced(ctx context.Context, result string) string {
+	scratch, ok := ScratchpadFromContext(ctx)
+	if !ok {
+		return result
+	}
+	entries := scratch.DrainSurfaced()
+	if len(entries) == 0 {
+		return result
+	}
+
+	var sb strings.Builder
+	sb.WriteString(result)
+	for _, entry := range entries {
+		if sb.Len() > 0 {
+			sb.WriteString("\n\n")
+		}
+		sb.WriteString("[scratch:" + entry.Key + "]\n")
+		sb.WriteString(entry.Value)
+	}
+	return sb.String()
+}
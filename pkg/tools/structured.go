@@ -0,0 +1,109 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// ToolWithStructuredOutput 返回结构化结果的工具接口
+//
+// ExecuteStructured 返回的值通过 ToolResult.Data 传给 Agent，同时以 JSON 文本
+// （FormatStructuredResult）作为发送给 LLM 的结果。注册到 Registry 后，中间件链最内层
+// 调用的是 ExecuteStructured；工具自身的 Execute 应使用 ExecuteAsText 实现，
+// 这样直接包装或调用工具时结构化结果同样能传回 Executor:
+//
+//	func (t *WeatherTool) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
+//	    return tools.ExecuteAsText(ctx, t, args)
+//	}
+type ToolWithStructuredOutput interface {
+	Tool
+	// OutputSchema 返回结构化结果的 JSON Schema
+	OutputSchema() PropertySchema
+	// ExecuteStructured 执行工具并返回结构化结果
+	ExecuteStructured(ctx context.Context, args map[string]interface{}) (interface{}, error)
+}
+
+// FormatStructuredResult 将结构化结果格式化为发送给 LLM 的文本
+//
+// 字符串原样返回，其他值编码为紧凑 JSON，无法编码时使用 fmt 格式。
+func FormatStructuredResult(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.RawMessage:
+		return string(v)
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
+
+// ExecuteAsText 执行结构化工具并返回格式化后的文本结果
+//
+// 在 Executor 中调用时，结构化结果会记录到本次调用的 ToolResult.Data。
+func ExecuteAsText(ctx context.Context, tool ToolWithStructuredOutput, args map[string]interface{}) (string, error) {
+	value, err := tool.ExecuteStructured(ctx, args)
+	if err != nil {
+		return "", err
+	}
+	storeStructured(ctx, value)
+	return FormatStructuredResult(value), nil
+}
+
+// adaptStructured 将结构化工具包装为 Execute 调用 ExecuteStructured 的工具
+//
+// 使工具自身的 Execute 未使用 ExecuteAsText 时，结构化结果也能经过中间件传回 Executor。
+// 非结构化工具和已包装的工具原样返回。
+func adaptStructured(tool Tool) Tool {
+	st, ok := tool.(ToolWithStructuredOutput)
+	if !ok {
+		return tool
+	}
+	if _, wrapped := tool.(interface{ Unwrap() Tool }); wrapped {
+		return tool
+	}
+	adapter := &wrappedTool{inner: tool, execute: func(ctx context.Context, args map[string]interface{}) (string, error) {
+		return ExecuteAsText(ctx, st, args)
+	}}
+	if _, ok := tool.(ToolWithValidation); ok {
+		return &validatingWrappedTool{wrappedTool: adapter}
+	}
+	return adapter
+}
+
+// structuredSlotKey 上下文中结构化结果槽位的键
+type structuredSlotKey struct{}
+
+// structuredSlot 保存一次工具调用的结构化结果
+type structuredSlot struct {
+	mu    sync.Mutex
+	value interface{}
+}
+
+// withStructuredSlot 返回带有新结构化结果槽位的上下文
+func withStructuredSlot(ctx context.Context) (context.Context, *structuredSlot) {
+	slot := &structuredSlot{}
+	return context.WithValue(ctx, structuredSlotKey{}, slot), slot
+}
+
+// storeStructured 将结构化结果写入上下文中的槽位，没有槽位时忽略
+func storeStructured(ctx context.Context, value interface{}) {
+	if slot, ok := ctx.Value(structuredSlotKey{}).(*structuredSlot); ok {
+		slot.mu.Lock()
+		slot.value = value
+		slot.mu.Unlock()
+	}
+}
+
+// get 返回槽位中的结构化结果
+func (s *structuredSlot) get() interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.value
+}
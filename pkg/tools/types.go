@@ -81,6 +81,8 @@ type ToolResult struct {
 	Success bool `json:"success"`
 	// Result 执行结果
 	Result string `json:"result"`
+	// Data 结构化结果（工具实现 ToolWithStructuredOutput 时）
+	Data interface{} `json:"data,omitempty"`
 	// Error 错误信息（如有）
 	Error string `json:"error,omitempty"`
 }
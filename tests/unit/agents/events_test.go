@@ -0,0 +1,173 @@
+package agents_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ahhsitt/helloagents-go/pkg/agents"
+	agentctx "github.com/ahhsitt/helloagents-go/pkg/context"
+	"github.com/ahhsitt/helloagents-go/pkg/core/llm"
+	"github.com/ahhsitt/helloagents-go/pkg/core/message"
+	"github.com/ahhsitt/helloagents-go/pkg/tools"
+)
+
+// compressingBuilder 总是报告发生了压缩的构建器
+type compressingBuilder struct{}
+
+func (compressingBuilder) Build(context.Context, *agentctx.BuildInput) (string, error) {
+	return "", nil
+}
+
+func (b compressingBuilder) BuildMessages(ctx context.Context, input *agentctx.BuildInput) ([]message.Message, error) {
+	messages, _, err := b.BuildMessagesWithReport(ctx, input)
+	return messages, err
+}
+
+func (compressingBuilder) BuildMessagesWithReport(_ context.Context, input *agentctx.BuildInput) ([]message.Message, *agentctx.BuildReport, error) {
+	return []message.Message{message.NewUserMessage(input.Query)}, &agentctx.BuildReport{Tokens: 42, Compressed: true}, nil
+}
+
+func TestReActAgent_RunStreamEvents(t *testing.T) {
+	registry := tools.NewRegistry()
+	_ = registry.Register(tools.NewSimpleTool("search", "search the web", "q", "query",
+		func(context.Context, string) (string, error) { return "result", nil }))
+
+	pending := [][]message.ToolCall{
+		{{ID: "c1", Name: "search", Arguments: map[string]interface{}{"q": "go"}}},
+		{{ID: "c2", Name: "missing", Arguments: map[string]interface{}{}}},
+	}
+	provider := &mockProvider{generateFn: func(context.Context, llm.Request) (llm.Response, error) {
+		if len(pending) == 0 {
+			return llm.Response{Content: "answer"}, nil
+		}
+		next := pending[0]
+		pending = pending[1:]
+		return llm.Response{ToolCalls: next}, nil
+	}}
+
+	agent, err := agents.NewReAct(provider, registry,
+		agents.WithMaxIterations(3),
+		agents.WithContextBuilder(compressingBuilder{}),
+	)
+	if err != nil {
+		t.Fatalf("NewReAct() error = %v", err)
+	}
+
+	chunks, errs := agent.RunStream(context.Background(), agents.Input{Query: "what is go"})
+
+	var events []agents.StreamEvent
+	var order []agents.ChunkType
+	for chunk := range chunks {
+		order = append(order, chunk.Type)
+		if chunk.Type == agents.ChunkTypeEvent {
+			events = append(events, *chunk.Event)
+		}
+	}
+	if err := <-errs; err != nil {
+		t.Fatalf("RunStream() error = %v", err)
+	}
+
+	want := []agents.EventType{
+		agents.EventCompressionApplied,
+		agents.EventStepStarted,
+		agents.EventToolCallStarted,
+		agents.EventToolCallFinished,
+		agents.EventStepStarted,
+		agents.EventToolCallStarted,
+		agents.EventToolCallFinished,
+		agents.EventStepStarted,
+		agents.EventBudgetWarning,
+	}
+	if len(events) != len(want) {
+		t.Fatalf("expected %d events, got %d: %+v", len(want), len(events), events)
+	}
+	for i, ev := range events {
+		if ev.Type != want[i] {
+			t.Errorf("event %d: type = %s, want %s", i, ev.Type, want[i])
+		}
+	}
+
+	if events[0].Tokens != 42 {
+		t.Errorf("expected compression event with 42 tokens, got %d", events[0].Tokens)
+	}
+	if events[3].ToolName != "search" || !events[3].Success || events[3].ToolCallID != "c1" {
+		t.Errorf("unexpected tool finished event %+v", events[3])
+	}
+	if events[6].Success || events[6].Error == "" {
+		t.Errorf("expected failed tool call event, got %+v", events[6])
+	}
+	if events[7].Iteration != 3 || events[8].Iteration != 3 {
+		t.Errorf("expected budget warning on the last iteration, got %+v", events[8])
+	}
+
+	// 事件先于步骤、文本和完成信号
+	if order[len(order)-1] != agents.ChunkTypeDone || order[len(order)-2] != agents.ChunkTypeText {
+		t.Errorf("unexpected chunk order %v", order)
+	}
+}
+
+// unknownsBuilder 总是报告存在缺少证据的关键词的构建器
+type unknownsBuilder struct{}
+
+func (unknownsBuilder) Build(context.Context, *agentctx.BuildInput) (string, error) {
+	return "", nil
+}
+
+func (b unknownsBuilder) BuildMessages(ctx context.Context, input *agentctx.BuildInput) ([]message.Message, error) {
+	messages, _, err := b.BuildMessagesWithReport(ctx, input)
+	return messages, err
+}
+
+func (unknownsBuilder) BuildMessagesWithReport(_ context.Context, input *agentctx.BuildInput) ([]message.Message, *agentctx.BuildReport, error) {
+	messages := []message.Message{message.NewSystemMessage("context"), message.NewUserMessage(input.Query)}
+	return messages, &agentctx.BuildReport{KeyTerms: []string{"acme", "billing"}, Unknowns: []string{"acme"}}, nil
+}
+
+func TestAgents_ClarifyOnUnknowns(t *testing.T) {
+	var system string
+	provider := &mockProvider{generateFn: func(_ context.Context, req llm.Request) (llm.Response, error) {
+		system = req.Messages[0].Content
+		return llm.Response{Content: "Which ACME product do you mean?"}, nil
+	}}
+
+	for _, enabled := range []bool{false, true} {
+		agent, err := agents.NewReAct(provider, tools.NewRegistry(),
+			agents.WithContextBuilder(unknownsBuilder{}),
+			agents.WithClarifyOnUnknowns(enabled),
+		)
+		if err != nil {
+			t.Fatalf("NewReAct() error = %v", err)
+		}
+
+		chunks, errs := agent.RunStream(context.Background(), agents.Input{Query: "reset the acme billing password"})
+		var detected []string
+		for chunk := range chunks {
+			if chunk.Type == agents.ChunkTypeEvent && chunk.Event.Type == agents.EventUnknownsDetected {
+				detected = chunk.Event.Unknowns
+			}
+		}
+		if err := <-errs; err != nil {
+			t.Fatalf("RunStream() error = %v", err)
+		}
+
+		hinted := strings.Contains(system, "clarifying question") && strings.Contains(system, "acme")
+		if enabled != hinted || enabled != (len(detected) == 1) {
+			t.Errorf("enabled=%v: hinted=%v, detected=%v, system=%q", enabled, hinted, detected, system)
+		}
+	}
+
+	agent, err := agents.NewSimple(provider,
+		agents.WithContextBuilder(unknownsBuilder{}),
+		agents.WithClarifyOnUnknowns(true),
+	)
+	if err != nil {
+		t.Fatalf("NewSimple() error = %v", err)
+	}
+	if _, err := agent.Run(context.Background(), agents.Input{Query: "reset the acme billing password"}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if !strings.Contains(system, "clarifying question") {
+		t.Errorf("expected SimpleAgent to add the clarification note, got %q", system)
+	}
+}
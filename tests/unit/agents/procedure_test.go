@@ -0,0 +1,82 @@
+package agents_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ahhsitt/helloagents-go/pkg/agents"
+	"github.com/ahhsitt/helloagents-go/pkg/core/llm"
+	"github.com/ahhsitt/helloagents-go/pkg/core/message"
+	"github.com/ahhsitt/helloagents-go/pkg/memory"
+	"github.com/ahhsitt/helloagents-go/pkg/tools"
+)
+
+func TestReActAgent_ProceduralMemory(t *testing.T) {
+	ctx := context.Background()
+	registry := tools.NewRegistry()
+	_ = registry.Register(tools.NewSimpleTool("lookup_order", "look up an order", "id", "order id",
+		func(context.Context, string) (string, error) { return "shipped", nil }))
+	_ = registry.Register(tools.NewSimpleTool("notify", "notify the customer", "text", "message",
+		func(context.Context, string) (string, error) { return "sent", nil }))
+
+	var systemPrompts []string
+	var pending [][]message.ToolCall
+	provider := &mockProvider{generateFn: func(_ context.Context, req llm.Request) (llm.Response, error) {
+		if req.Messages[len(req.Messages)-1].Role == message.RoleUser {
+			var system []string
+			for _, msg := range req.Messages {
+				if msg.Role == message.RoleSystem {
+					system = append(system, msg.Content)
+				}
+			}
+			systemPrompts = append(systemPrompts, strings.Join(system, "\n---\n"))
+		}
+		if len(pending) == 0 {
+			return llm.Response{Content: "done"}, nil
+		}
+		next := pending[0]
+		pending = pending[1:]
+		return llm.Response{ToolCalls: next}, nil
+	}}
+	script := func() {
+		pending = [][]message.ToolCall{
+			{{ID: "1", Name: "lookup_order", Arguments: map[string]interface{}{"id": "A1"}}},
+			{{ID: "2", Name: "notify", Arguments: map[string]interface{}{"text": "shipped"}}},
+		}
+	}
+
+	procedures := memory.NewProceduralMemory()
+	agent, err := agents.NewReAct(provider, registry, agents.WithProceduralMemory(procedures))
+	if err != nil {
+		t.Fatalf("NewReAct() error = %v", err)
+	}
+
+	script()
+	if _, err := agent.Run(ctx, agents.Input{Query: "tell the customer where order A1 is"}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if procedures.Size() != 1 {
+		t.Fatalf("expected the tool sequence to be learned, got %d procedures", procedures.Size())
+	}
+	if strings.Contains(systemPrompts[0], "Procedures that solved similar tasks") {
+		t.Error("expected no recalled procedures on the first run")
+	}
+
+	script()
+	if _, err := agent.Run(ctx, agents.Input{Query: "tell the customer where order B7 is"}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if !strings.Contains(systemPrompts[1], "1. lookup_order") || !strings.Contains(systemPrompts[1], "2. notify") {
+		t.Errorf("expected learned procedure in the prompt, got:\n%s", systemPrompts[1])
+	}
+
+	// 遵循召回的程序时只更新成功次数，不重复学习
+	if procedures.Size() != 1 {
+		t.Fatalf("expected no duplicate procedure, got %d", procedures.Size())
+	}
+	matches, _ := procedures.FindProcedures(ctx, "where is my order")
+	if len(matches) != 1 || matches[0].Procedure.SuccessCount != 2 {
+		t.Errorf("expected success count 2, got %+v", matches)
+	}
+}
@@ -0,0 +1,82 @@
+package agents_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ahhsitt/helloagents-go/pkg/agents"
+	"github.com/ahhsitt/helloagents-go/pkg/core/llm"
+	"github.com/ahhsitt/helloagents-go/pkg/core/message"
+	"github.com/ahhsitt/helloagents-go/pkg/tools"
+)
+
+func TestReActAgent_RunScratchpad(t *testing.T) {
+	var scratch *tools.Scratchpad
+	registry := tools.NewRegistry()
+	_ = registry.Register(tools.NewSimpleTool("fetch", "fetch a page", "url", "page url",
+		func(ctx context.Context, _ string) (string, error) {
+			var ok bool
+			scratch, ok = tools.ScratchpadFromContext(ctx)
+			if !ok {
+				return "", errors.New("no scratchpad")
+			}
+			if err := scratch.Put("page", strings.Repeat("x", 64)); err != nil {
+				return "", err
+			}
+			return "saved page", nil
+		},
+	))
+	_ = registry.Register(tools.NewSimpleTool("summarize", "summarize the saved page", "key", "scratch key",
+		func(ctx context.Context, key string) (string, error) {
+			s, _ := tools.ScratchpadFromContext(ctx)
+			page, ok := s.Get(key)
+			if !ok {
+				return "", errors.New("missing page")
+			}
+			_ = s.Put("summary", page[:4])
+			return fmt.Sprintf("page has %d blocks", len(page)/16), s.Surface("summary")
+		},
+	))
+
+	var toolMessages []string
+	calls := [][]message.ToolCall{
+		{{ID: "1", Name: "fetch", Arguments: map[string]interface{}{"url": "https://example.com"}}},
+		{{ID: "2", Name: "summarize", Arguments: map[string]interface{}{"key": "page"}}},
+	}
+	provider := &mockProvider{generateFn: func(_ context.Context, req llm.Request) (llm.Response, error) {
+		last := req.Messages[len(req.Messages)-1]
+		if last.Role == message.RoleTool {
+			toolMessages = append(toolMessages, last.Content)
+		}
+		if len(calls) == 0 {
+			return llm.Response{Content: "done"}, nil
+		}
+		next := calls[0]
+		calls = calls[1:]
+		return llm.Response{ToolCalls: next}, nil
+	}}
+
+	agent, err := agents.NewReAct(provider, registry, agents.WithScratchpadLimits(128, 256))
+	if err != nil {
+		t.Fatalf("NewReAct() error = %v", err)
+	}
+	if _, err := agent.Run(context.Background(), agents.Input{Query: "summarize example.com"}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if len(toolMessages) != 2 {
+		t.Fatalf("expected 2 tool observations, got %d", len(toolMessages))
+	}
+	if strings.Contains(toolMessages[0], "xxxx") {
+		t.Errorf("page should stay out of the LLM context, got %q", toolMessages[0])
+	}
+	if toolMessages[1] != "page has 4 blocks\n\n[scratch:summary]\nxxxx" {
+		t.Errorf("unexpected surfaced observation %q", toolMessages[1])
+	}
+	if scratch == nil || scratch.Size() != 0 {
+		t.Error("expected scratchpad to be cleared at run end")
+	}
+}
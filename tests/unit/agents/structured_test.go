@@ -0,0 +1,110 @@
+package agents_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/ahhsitt/helloagents-go/pkg/agents"
+	"github.com/ahhsitt/helloagents-go/pkg/core/llm"
+	"github.com/ahhsitt/helloagents-go/pkg/core/message"
+	"github.com/ahhsitt/helloagents-go/pkg/tools"
+)
+
+// stockTool 返回结构化报价的测试工具
+type stockTool struct{}
+
+func (s *stockTool) Name() string        { return "stock" }
+func (s *stockTool) Description() string { return "Get a stock quote" }
+func (s *stockTool) Parameters() tools.ParameterSchema {
+	return tools.ParameterSchema{Type: "object", Properties: map[string]tools.PropertySchema{"symbol": {Type: "string"}}}
+}
+func (s *stockTool) OutputSchema() tools.PropertySchema {
+	return tools.PropertySchema{Type: "object"}
+}
+func (s *stockTool) ExecuteStructured(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	return map[string]interface{}{"symbol": args["symbol"], "price": 101.5}, nil
+}
+func (s *stockTool) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
+	return tools.ExecuteAsText(ctx, s, args)
+}
+
+func TestReActAgent_StructuredToolResult(t *testing.T) {
+	registry := tools.NewRegistry()
+	registry.MustRegister(&stockTool{})
+
+	var toolMsg message.Message
+	called := false
+	provider := &mockProvider{generateFn: func(_ context.Context, req llm.Request) (llm.Response, error) {
+		if !called {
+			called = true
+			return llm.Response{ToolCalls: []message.ToolCall{{ID: "1", Name: "stock", Arguments: map[string]interface{}{"symbol": "ACME"}}}}, nil
+		}
+		toolMsg = req.Messages[len(req.Messages)-1]
+		return llm.Response{Content: "ACME trades at 101.5"}, nil
+	}}
+
+	agent, err := agents.NewReAct(provider, registry)
+	if err != nil {
+		t.Fatalf("NewReAct() error = %v", err)
+	}
+	output, err := agent.Run(context.Background(), agents.Input{Query: "ACME price?"})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	want := map[string]interface{}{"symbol": "ACME", "price": 101.5}
+	if !reflect.DeepEqual(toolMsg.StructuredContent, want) || toolMsg.Content != `{"price":101.5,"symbol":"ACME"}` {
+		t.Errorf("tool message = %q, %#v", toolMsg.Content, toolMsg.StructuredContent)
+	}
+	var observation *agents.ReasoningStep
+	for i := range output.Steps {
+		if output.Steps[i].Type == agents.StepTypeObservation {
+			observation = &output.Steps[i]
+		}
+	}
+	if observation == nil || !reflect.DeepEqual(observation.ToolData, want) {
+		t.Errorf("observation step = %+v", observation)
+	}
+}
+
+func TestReActAgent_RefreshesToolsMidRun(t *testing.T) {
+	registry := tools.NewRegistry()
+	registry.MustRegister(&stockTool{})
+
+	var seen [][]string
+	provider := &mockProvider{generateFn: func(_ context.Context, req llm.Request) (llm.Response, error) {
+		names := make([]string, 0, len(req.Tools))
+		for _, def := range req.Tools {
+			names = append(names, def.Name)
+		}
+		seen = append(seen, names)
+		if len(seen) == 1 {
+			// 模拟提供者在运行期间热注册新工具
+			registry.MustRegister(&hotTool{})
+			return llm.Response{ToolCalls: []message.ToolCall{{ID: "1", Name: "stock", Arguments: map[string]interface{}{"symbol": "ACME"}}}}, nil
+		}
+		return llm.Response{Content: "done"}, nil
+	}}
+
+	agent, err := agents.NewReAct(provider, registry)
+	if err != nil {
+		t.Fatalf("NewReAct() error = %v", err)
+	}
+	if _, err := agent.Run(context.Background(), agents.Input{Query: "ACME price?"}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(seen) != 2 || len(seen[0]) != 1 || len(seen[1]) != 2 {
+		t.Errorf("request tools = %v, want the hot tool in the second request", seen)
+	}
+}
+
+// hotTool 运行期间注册的测试工具
+type hotTool struct{}
+
+func (h *hotTool) Name() string                      { return "hot" }
+func (h *hotTool) Description() string               { return "Registered mid-run" }
+func (h *hotTool) Parameters() tools.ParameterSchema { return tools.ParameterSchema{Type: "object"} }
+func (h *hotTool) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
+	return "hot", nil
+}
@@ -0,0 +1,69 @@
+package context_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	agentctx "github.com/ahhsitt/helloagents-go/pkg/context"
+)
+
+// benchmarkPackets 生成基准测试用的包（显式设置 Token 数，避免加载 tiktoken）
+func benchmarkPackets() []*agentctx.Packet {
+	packets := []*agentctx.Packet{
+		agentctx.NewPacket("You are a helpful assistant.", agentctx.WithPacketType(agentctx.PacketTypeInstructions), agentctx.WithTokenCount(8)),
+		agentctx.NewPacket("How do I roll back the api deploy?", agentctx.WithPacketType(agentctx.PacketTypeTask), agentctx.WithTokenCount(10)),
+	}
+	for i := 0; i < 20; i++ {
+		packets = append(packets, agentctx.NewPacket(
+			fmt.Sprintf("evidence %d: the api deploy to staging failed, roll back with the previous release", i),
+			agentctx.WithPacketType(agentctx.PacketTypeEvidence),
+			agentctx.WithSource(fmt.Sprintf("doc-%d", i)),
+			agentctx.WithRelevanceScore(0.5+float64(i)/100),
+			agentctx.WithTokenCount(20),
+		))
+	}
+	for i := 0; i < 10; i++ {
+		packets = append(packets, agentctx.NewPacket(
+			fmt.Sprintf("[user] message %d about the deploy\n", i),
+			agentctx.WithPacketType(agentctx.PacketTypeHistory),
+			agentctx.WithTimestamp(time.Now().Add(-time.Duration(i)*time.Minute)),
+			agentctx.WithTokenCount(8),
+		))
+	}
+	return packets
+}
+
+func BenchmarkDefaultStructurer_Structure(b *testing.B) {
+	packets := benchmarkPackets()
+	config := agentctx.NewConfig(agentctx.WithTokenCounter(agentctx.NewEstimatedCounter()))
+	structurer := agentctx.NewDefaultStructurer()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		structurer.Structure(packets, "How do I roll back the api deploy?", config)
+	}
+}
+
+func BenchmarkGSSCBuilder_Build(b *testing.B) {
+	packets := benchmarkPackets()
+	builder := agentctx.NewGSSCBuilder(
+		agentctx.WithConfig(agentctx.NewConfig(agentctx.WithTokenCounter(agentctx.NewEstimatedCounter()))),
+		agentctx.WithGatherer(agentctx.NewCompositeGatherer(nil, false)),
+	)
+	input := &agentctx.BuildInput{
+		Query:             "How do I roll back the api deploy?",
+		AdditionalPackets: packets,
+	}
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := builder.Build(ctx, input); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
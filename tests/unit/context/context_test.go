@@ -2,6 +2,7 @@ package context_test
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -290,6 +291,50 @@ func TestGSSCBuilder_BuildMessages(t *testing.T) {
 	}
 }
 
+// prefixCompressor 只保留上下文的前 n 个字节
+type prefixCompressor struct{ n int }
+
+func (c prefixCompressor) Compress(context string, _ *agentctx.Config) string {
+	if len(context) <= c.n {
+		return context
+	}
+	return context[:c.n]
+}
+
+func TestGSSCBuilder_BuildMessagesWithReport(t *testing.T) {
+	ctx := context.Background()
+	// 显式设置 Token 数并使用估算计数器，避免加载 tiktoken
+	input := &agentctx.BuildInput{
+		Query: "What is Go?",
+		AdditionalPackets: []*agentctx.Packet{
+			agentctx.NewPacket("You are a helpful assistant.", agentctx.WithPacketType(agentctx.PacketTypeInstructions), agentctx.WithTokenCount(8)),
+			agentctx.NewPacket("What is Go?", agentctx.WithPacketType(agentctx.PacketTypeTask), agentctx.WithTokenCount(4)),
+		},
+	}
+	opts := []agentctx.BuilderOption{
+		agentctx.WithConfig(agentctx.NewConfig(agentctx.WithTokenCounter(agentctx.NewEstimatedCounter()))),
+		agentctx.WithGatherer(agentctx.NewCompositeGatherer(nil, false)),
+	}
+
+	builder := agentctx.NewGSSCBuilder(opts...)
+	messages, report, err := builder.BuildMessagesWithReport(ctx, input)
+	if err != nil {
+		t.Fatalf("BuildMessagesWithReport() error = %v", err)
+	}
+	if len(messages) != 2 || report == nil || report.Compressed {
+		t.Fatalf("expected uncompressed system+user messages, got %d messages, report %+v", len(messages), report)
+	}
+
+	builder = agentctx.NewGSSCBuilder(append(opts, agentctx.WithCompressor(prefixCompressor{n: 16}))...)
+	messages, report, err = builder.BuildMessagesWithReport(ctx, input)
+	if err != nil {
+		t.Fatalf("BuildMessagesWithReport() error = %v", err)
+	}
+	if !report.Compressed || len(messages[0].Content) != 16 {
+		t.Errorf("expected compressed context, got report %+v and %q", report, messages[0].Content)
+	}
+}
+
 func TestDefaultStructurer_Structure(t *testing.T) {
 	structurer := agentctx.NewDefaultStructurer()
 	config := agentctx.DefaultConfig()
@@ -315,6 +360,55 @@ func TestDefaultStructurer_Structure(t *testing.T) {
 	}
 }
 
+func TestGSSCBuilder_StyleProfiles(t *testing.T) {
+	ctx := context.Background()
+	terse := &agentctx.StyleProfile{
+		Name:            "ops",
+		Persona:         "You are an on-call SRE.",
+		Language:        "English",
+		Verbosity:       agentctx.VerbosityTerse,
+		FormattingRules: []string{"numbered steps only"},
+		OutputTemplate:  "Steps to mitigate:",
+	}
+	builder := agentctx.NewGSSCBuilder(
+		agentctx.WithConfig(agentctx.NewConfig(agentctx.WithStyle(agentctx.ChatStyle()))),
+		agentctx.WithStyleProfiles(terse),
+	)
+
+	result, err := builder.BuildWithReport(ctx, &agentctx.BuildInput{
+		Query:              "The API is returning 502",
+		SystemInstructions: "Follow the incident policy.",
+		StyleName:          "ops",
+	})
+	if err != nil {
+		t.Fatalf("BuildWithReport() error = %v", err)
+	}
+	if result.Report.Style != "ops" {
+		t.Errorf("Report.Style = %q, want ops", result.Report.Style)
+	}
+	for _, want := range []string{
+		"[Role & Policies]\nFollow the incident policy.\nYou are an on-call SRE.\n请使用English回答。",
+		"[Output]\nSteps to mitigate:\n\n格式要求：\n- numbered steps only",
+	} {
+		if !containsSubstring(result.Context, want) {
+			t.Errorf("context missing %q:\n%s", want, result.Context)
+		}
+	}
+
+	// 未指定时使用配置中的默认风格
+	chat, err := builder.Build(ctx, &agentctx.BuildInput{Query: "hi"})
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if !containsSubstring(chat, agentctx.ChatStyle().Persona) || containsSubstring(chat, "on-call") {
+		t.Errorf("expected default chat style, got:\n%s", chat)
+	}
+
+	if _, err := builder.Build(ctx, &agentctx.BuildInput{Query: "hi", StyleName: "missing"}); !errors.Is(err, agentctx.ErrUnknownStyle) {
+		t.Errorf("expected ErrUnknownStyle, got %v", err)
+	}
+}
+
 func TestTruncateCompressor_Compress(t *testing.T) {
 	compressor := agentctx.NewTruncateCompressor()
 
@@ -740,3 +834,92 @@ func TestNoteGatherer_DefaultLimit(t *testing.T) {
 		t.Errorf("负数 Limit 应该使用默认值 5, 得到 %d", gatherer2.Limit)
 	}
 }
+
+// configRecorder 记录收集阶段看到的配置
+//
+// 包显式设置 Token 数，避免加载 tiktoken 编码。
+type configRecorder struct {
+	config *agentctx.Config
+}
+
+func (g *configRecorder) Gather(_ context.Context, input *agentctx.GatherInput) ([]*agentctx.Packet, error) {
+	g.config = input.Config
+	return []*agentctx.Packet{
+		agentctx.NewPacket(input.Query, agentctx.WithPacketType(agentctx.PacketTypeTask), agentctx.WithTokenCount(5)),
+		agentctx.NewPacket("beta feature notes",
+			agentctx.WithPacketType(agentctx.PacketTypeEvidence),
+			agentctx.WithSource("beta"),
+			agentctx.WithRelevanceScore(0.9),
+			agentctx.WithTokenCount(3),
+		),
+	}, nil
+}
+
+// flagScorer 在开启 beta 开关时提升 beta 来源的包
+type flagScorer struct{}
+
+func (s *flagScorer) Score(_ *agentctx.Packet, _ string) float64 { return 0 }
+
+func (s *flagScorer) ScoreTask(packet *agentctx.Packet, _ string, task *agentctx.TaskMeta) float64 {
+	if task.Flag("beta") && packet.Source == "beta" {
+		return 1
+	}
+	return 0
+}
+
+func TestGSSCBuilder_TaskMeta(t *testing.T) {
+	ctx := context.Background()
+	recorder := &configRecorder{}
+	builder := agentctx.NewGSSCBuilder(
+		agentctx.WithConfig(agentctx.NewConfig(
+			agentctx.WithMaxTokens(4000),
+			agentctx.WithTokenCounter(agentctx.NewEstimatedCounter()),
+			agentctx.WithTierMaxTokens("pro", 16000),
+			agentctx.WithLocaleOutputTemplate("en-US", "Answer in English."),
+		)),
+		agentctx.WithGatherer(recorder),
+		agentctx.WithSelector(agentctx.NewDefaultSelectorWithScorer(&flagScorer{})),
+	)
+
+	deadline := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+	task := &agentctx.TaskMeta{
+		Locale:   "en-US",
+		Urgency:  agentctx.UrgencyHigh,
+		Deadline: deadline,
+		UserTier: "pro",
+		Flags:    map[string]bool{"beta": true},
+	}
+	result, err := builder.BuildWithReport(ctx, &agentctx.BuildInput{Query: "how do I roll back", Task: task})
+	if err != nil {
+		t.Fatalf("BuildWithReport() error = %v", err)
+	}
+
+	if recorder.config.Task != task || recorder.config.MaxTokens != 16000 {
+		t.Errorf("gatherer should see task and tier budget, got task=%v max=%d", recorder.config.Task, recorder.config.MaxTokens)
+	}
+	for _, want := range []string{
+		"用户问题：how do I roll back\n语言区域：en-US\n紧急程度：high\n截止时间：2026-01-02T15:04:05Z",
+		"[Output]\nAnswer in English.",
+	} {
+		if !containsSubstring(result.Context, want) {
+			t.Errorf("context missing %q:\n%s", want, result.Context)
+		}
+	}
+	var beta *agentctx.Packet
+	for _, p := range result.Packets {
+		if p.Source == "beta" {
+			beta = p
+		}
+	}
+	if beta == nil || beta.CompositeScore != 1 {
+		t.Errorf("expected task-aware scorer to boost beta packet, got %+v", beta)
+	}
+
+	// 不带任务元数据时沿用默认配置
+	if _, err := builder.Build(ctx, &agentctx.BuildInput{Query: "how do I roll back"}); err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if recorder.config.Task != nil || recorder.config.MaxTokens != 4000 {
+		t.Errorf("expected default config without task, got task=%v max=%d", recorder.config.Task, recorder.config.MaxTokens)
+	}
+}
@@ -0,0 +1,77 @@
+package context_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	agentctx "github.com/ahhsitt/helloagents-go/pkg/context"
+)
+
+func TestGSSCBuilder_Diff(t *testing.T) {
+	builder := agentctx.NewGSSCBuilder()
+	ctx := context.Background()
+
+	constraint := agentctx.NewEvidencePacket("Never reveal the API key", "policy", 0.9)
+	doc := agentctx.NewEvidencePacket("Go is a compiled language", "docs", 0.8)
+
+	prev, err := builder.BuildWithReport(ctx, &agentctx.BuildInput{
+		Query:             "What is Go?",
+		AdditionalPackets: []*agentctx.Packet{constraint, doc},
+	})
+	if err != nil {
+		t.Fatalf("BuildWithReport() error = %v", err)
+	}
+
+	same, _ := builder.BuildWithReport(ctx, &agentctx.BuildInput{
+		Query:             "What is Go?",
+		AdditionalPackets: []*agentctx.Packet{constraint.Clone(), doc.Clone()},
+	})
+	if diff := builder.Diff(prev, same); !diff.IsEmpty() {
+		t.Errorf("identical builds should have empty diff, got:\n%s", diff)
+	}
+
+	next, _ := builder.BuildWithReport(ctx, &agentctx.BuildInput{
+		Query:              "What is Go?",
+		SystemInstructions: "You are a helpful assistant.",
+		AdditionalPackets:  []*agentctx.Packet{doc.Clone()},
+	})
+
+	diff := builder.Diff(prev, next)
+	if len(diff.RemovedPackets) != 1 || diff.RemovedPackets[0].Content != constraint.Content {
+		t.Errorf("expected constraint packet to be removed, got %v", diff.RemovedPackets)
+	}
+	if len(diff.AddedPackets) != 1 || diff.AddedPackets[0].Type != agentctx.PacketTypeInstructions {
+		t.Errorf("expected instructions packet to be added, got %v", diff.AddedPackets)
+	}
+	if len(diff.AddedSections) != 1 || diff.AddedSections[0] != "[Role & Policies]" {
+		t.Errorf("AddedSections = %v, want [[Role & Policies]]", diff.AddedSections)
+	}
+	if len(diff.ChangedSections) != 1 || diff.ChangedSections[0] != "[Evidence]" {
+		t.Errorf("ChangedSections = %v, want [[Evidence]]", diff.ChangedSections)
+	}
+	if !strings.Contains(diff.String(), "- packet [evidence] Never reveal the API key") {
+		t.Errorf("String() should describe removed packet, got:\n%s", diff)
+	}
+}
+
+func TestDiff_Reranked(t *testing.T) {
+	a := agentctx.NewEvidencePacket("alpha", "docs", 0.9)
+	b := agentctx.NewEvidencePacket("beta", "docs", 0.5)
+	c := agentctx.NewEvidencePacket("gamma", "docs", 0.4)
+
+	prev := &agentctx.BuildResult{Packets: []*agentctx.Packet{a, b, c}}
+	next := &agentctx.BuildResult{Packets: []*agentctx.Packet{c, a}}
+
+	diff := agentctx.Diff(prev, next)
+	if len(diff.RemovedPackets) != 1 || diff.RemovedPackets[0] != b {
+		t.Errorf("expected beta to be removed, got %v", diff.RemovedPackets)
+	}
+	if len(diff.RerankedPackets) != 2 {
+		t.Fatalf("expected 2 reranked packets, got %d", len(diff.RerankedPackets))
+	}
+	change := diff.RerankedPackets[0]
+	if change.Packet != c || change.OldRank != 1 || change.NewRank != 0 {
+		t.Errorf("unexpected rank change: %+v", change)
+	}
+}
@@ -0,0 +1,56 @@
+package context_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	agentctx "github.com/ahhsitt/helloagents-go/pkg/context"
+)
+
+func TestGSSCBuilder_ReportsUnknowns(t *testing.T) {
+	builder := agentctx.NewGSSCBuilder(
+		agentctx.WithConfig(agentctx.NewConfig(
+			agentctx.WithTokenCounter(agentctx.NewEstimatedCounter()),
+			agentctx.WithMinRelevance(0),
+		)),
+		agentctx.WithGatherer(agentctx.NewCompositeGatherer(nil, false)),
+	)
+
+	query := "How do I reset the ACME billing password? 退款流程"
+	result, err := builder.BuildWithReport(context.Background(), &agentctx.BuildInput{
+		Query: query,
+		AdditionalPackets: []*agentctx.Packet{
+			// 任务包包含查询本身，不能算作覆盖
+			agentctx.NewPacket(query, agentctx.WithPacketType(agentctx.PacketTypeTask), agentctx.WithTokenCount(12)),
+			agentctx.NewPacket("Reset a billing password from the account settings page. 退款流程见帮助中心。",
+				agentctx.WithPacketType(agentctx.PacketTypeEvidence), agentctx.WithTokenCount(16)),
+		},
+	})
+	if err != nil {
+		t.Fatalf("BuildWithReport() error = %v", err)
+	}
+
+	report := result.Report
+	if want := []string{"reset", "acme", "billing", "password", "退款流程"}; !reflect.DeepEqual(report.KeyTerms, want) {
+		t.Errorf("KeyTerms = %v, want %v", report.KeyTerms, want)
+	}
+	if !report.HasUnknowns() || !reflect.DeepEqual(report.Unknowns, []string{"acme"}) {
+		t.Errorf("Unknowns = %v, want [acme]", report.Unknowns)
+	}
+}
+
+func TestGSSCBuilder_NoUnknownsWithoutKeyTerms(t *testing.T) {
+	builder := agentctx.NewGSSCBuilder(
+		agentctx.WithConfig(agentctx.NewConfig(agentctx.WithTokenCounter(agentctx.NewEstimatedCounter()))),
+		agentctx.WithGatherer(agentctx.NewCompositeGatherer(nil, false)),
+	)
+
+	result, err := builder.BuildWithReport(context.Background(), &agentctx.BuildInput{Query: "what is it?"})
+	if err != nil {
+		t.Fatalf("BuildWithReport() error = %v", err)
+	}
+	if result.Report.HasUnknowns() || len(result.Report.KeyTerms) != 0 {
+		t.Errorf("expected no key terms, got %v / %v", result.Report.KeyTerms, result.Report.Unknowns)
+	}
+}
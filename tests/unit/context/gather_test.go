@@ -0,0 +1,325 @@
+package context_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	agentctx "github.com/ahhsitt/helloagents-go/pkg/context"
+	"github.com/ahhsitt/helloagents-go/pkg/core/message"
+	"github.com/ahhsitt/helloagents-go/pkg/otel"
+)
+
+// slowGatherer 在返回前等待指定时间，且不响应取消
+type slowGatherer struct {
+	delay time.Duration
+}
+
+func (g *slowGatherer) Gather(_ context.Context, _ *agentctx.GatherInput) ([]*agentctx.Packet, error) {
+	time.Sleep(g.delay)
+	return []*agentctx.Packet{agentctx.NewEvidencePacket("slow result", "slow", 0.9)}, nil
+}
+
+// failingGatherer 总是返回错误
+type failingGatherer struct{}
+
+func (g *failingGatherer) Gather(_ context.Context, _ *agentctx.GatherInput) ([]*agentctx.Packet, error) {
+	return nil, errors.New("backend unavailable")
+}
+
+func TestCompositeGatherer_TimeoutPartialResults(t *testing.T) {
+	composite := agentctx.NewCompositeGatherer([]agentctx.Gatherer{
+		agentctx.NewTaskGatherer(),
+		agentctx.NamedGatherer("rag", &slowGatherer{delay: 500 * time.Millisecond}),
+	}, true, agentctx.WithGathererTimeout(50*time.Millisecond))
+
+	start := time.Now()
+	packets, reports, err := composite.GatherWithReport(context.Background(), &agentctx.GatherInput{Query: "What is Go?"})
+	if err != nil {
+		t.Fatalf("GatherWithReport() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 300*time.Millisecond {
+		t.Errorf("slow gatherer stalled gather for %v", elapsed)
+	}
+
+	if len(packets) != 1 || packets[0].Type != agentctx.PacketTypeTask {
+		t.Errorf("expected only the task packet, got %d packets", len(packets))
+	}
+
+	if len(reports) != 2 {
+		t.Fatalf("expected 2 reports, got %d", len(reports))
+	}
+	if reports[0].Name != "TaskGatherer" || reports[0].PacketCount != 1 {
+		t.Errorf("unexpected task report: %+v", reports[0])
+	}
+	if reports[1].Name != "rag" || !reports[1].TimedOut || !errors.Is(reports[1].Err, agentctx.ErrGathererTimeout) {
+		t.Errorf("expected rag gatherer to time out, got %+v", reports[1])
+	}
+}
+
+func TestCompositeGatherer_ErrorPolicy(t *testing.T) {
+	gatherers := []agentctx.Gatherer{
+		agentctx.NewTaskGatherer(),
+		&failingGatherer{},
+	}
+	input := &agentctx.GatherInput{Query: "What is Go?"}
+
+	for _, parallel := range []bool{false, true} {
+		// 默认尽力而为：忽略失败的收集器
+		packets, err := agentctx.NewCompositeGatherer(gatherers, parallel).Gather(context.Background(), input)
+		if err != nil {
+			t.Errorf("best-effort Gather() error = %v", err)
+		}
+		if len(packets) != 1 {
+			t.Errorf("best-effort Gather() returned %d packets, want 1", len(packets))
+		}
+
+		// 快速失败：返回错误
+		failFast := agentctx.NewCompositeGatherer(gatherers, parallel, agentctx.WithGathererErrorPolicy(agentctx.GatherFailFast))
+		if _, err := failFast.Gather(context.Background(), input); err == nil {
+			t.Errorf("fail-fast Gather() (parallel=%v) should return error", parallel)
+		}
+	}
+}
+
+func TestGSSCBuilder_BuildWithReport(t *testing.T) {
+	builder := agentctx.NewGSSCBuilder(
+		agentctx.WithGatherer(agentctx.NewCompositeGatherer([]agentctx.Gatherer{
+			agentctx.NewInstructionsGatherer(),
+			agentctx.NewTaskGatherer(),
+			agentctx.NamedGatherer("rag", &slowGatherer{delay: 500 * time.Millisecond}),
+		}, true, agentctx.WithGathererTimeout(50*time.Millisecond))),
+	)
+
+	result, err := builder.BuildWithReport(context.Background(), &agentctx.BuildInput{
+		Query:              "What is Go?",
+		SystemInstructions: "You are a helpful assistant.",
+	})
+	if err != nil {
+		t.Fatalf("BuildWithReport() error = %v", err)
+	}
+
+	if !containsSubstring(result.Context, "[Task]") {
+		t.Error("Context should contain [Task] section")
+	}
+	if result.Report.GatheredPackets != 2 || len(result.Packets) != result.Report.SelectedPackets {
+		t.Errorf("unexpected packet counts: %+v", result.Report)
+	}
+	if result.Report.Tokens == 0 {
+		t.Error("Report should record token count")
+	}
+
+	timedOut := result.Report.TimedOutGatherers()
+	if len(timedOut) != 1 || timedOut[0] != "rag" {
+		t.Errorf("TimedOutGatherers() = %v, want [rag]", timedOut)
+	}
+}
+
+func TestGSSCBuilder_StageTimingsAndSlowGatherers(t *testing.T) {
+	metrics := otel.NewInMemoryMetrics()
+	builder := agentctx.NewGSSCBuilder(
+		agentctx.WithGatherer(agentctx.NewCompositeGatherer([]agentctx.Gatherer{
+			agentctx.NewTaskGatherer(),
+			agentctx.NamedGatherer("vector-db", &slowGatherer{delay: 30 * time.Millisecond}),
+		}, false)),
+		agentctx.WithMetrics(metrics),
+		agentctx.WithSlowGathererThreshold(0.5, 10*time.Millisecond),
+	)
+
+	result, err := builder.BuildWithReport(context.Background(), &agentctx.BuildInput{Query: "What is Go?"})
+	if err != nil {
+		t.Fatalf("BuildWithReport() error = %v", err)
+	}
+
+	stages := result.Report.Stages
+	if stages.Gather < 30*time.Millisecond || stages.Total < stages.Gather {
+		t.Errorf("unexpected stage timings: %+v", stages)
+	}
+	if len(result.Report.SlowGatherers) != 1 || result.Report.SlowGatherers[0] != "vector-db" {
+		t.Errorf("expected vector-db to be reported as slow, got %v", result.Report.SlowGatherers)
+	}
+	if len(result.Report.Warnings) != 1 {
+		t.Errorf("expected 1 warning, got %v", result.Report.Warnings)
+	}
+	if slowest, ok := result.Report.SlowestGatherer(); !ok || slowest.Name != "vector-db" {
+		t.Errorf("SlowestGatherer() = %+v", slowest)
+	}
+
+	// 每个阶段加总计一条记录
+	stageHist := metrics.Histogram(otel.MetricContextStageDuration).(*otel.InMemoryHistogram)
+	if n := len(stageHist.Values()); n != 5 {
+		t.Errorf("expected 5 stage duration records, got %d", n)
+	}
+	gathererHist := metrics.Histogram(otel.MetricContextGathererDuration).(*otel.InMemoryHistogram)
+	if n := len(gathererHist.Values()); n != 2 {
+		t.Errorf("expected 2 gatherer duration records, got %d", n)
+	}
+	if metrics.GetCounterValue(otel.MetricContextSlowGatherers) != 1 {
+		t.Error("expected slow gatherer counter to be incremented")
+	}
+}
+
+// orderedGatherer 按给定顺序返回证据包
+type orderedGatherer struct {
+	contents []string
+	ts       time.Time
+}
+
+func (g *orderedGatherer) Gather(_ context.Context, _ *agentctx.GatherInput) ([]*agentctx.Packet, error) {
+	packets := make([]*agentctx.Packet, len(g.contents))
+	for i, content := range g.contents {
+		packets[i] = agentctx.NewPacket(content,
+			agentctx.WithPacketType(agentctx.PacketTypeEvidence),
+			agentctx.WithSource("rag"),
+			agentctx.WithRelevanceScore(0.8),
+			agentctx.WithTimestamp(g.ts),
+		)
+	}
+	return packets, nil
+}
+
+func TestGSSCBuilder_DeterministicPacketOrder(t *testing.T) {
+	ctx := context.Background()
+	ts := time.Now().Add(-time.Minute)
+	build := func(contents ...string) *agentctx.BuildResult {
+		builder := agentctx.NewGSSCBuilder(agentctx.WithGatherer(agentctx.NewCompositeGatherer([]agentctx.Gatherer{
+			&orderedGatherer{contents: contents, ts: ts},
+			agentctx.NewTaskGatherer(),
+		}, true)))
+		result, err := builder.BuildWithReport(ctx, &agentctx.BuildInput{Query: "deploy status"})
+		if err != nil {
+			t.Fatalf("BuildWithReport() error = %v", err)
+		}
+		return result
+	}
+
+	first := build("alpha evidence", "beta evidence", "gamma evidence")
+	second := build("gamma evidence", "alpha evidence", "beta evidence")
+
+	if first.Context != second.Context {
+		t.Errorf("identical inputs produced different contexts:\n%s\n---\n%s", first.Context, second.Context)
+	}
+	if len(first.Packets) != len(second.Packets) {
+		t.Fatalf("packet counts differ: %d vs %d", len(first.Packets), len(second.Packets))
+	}
+	for i := range first.Packets {
+		if first.Packets[i].ID == "" || first.Packets[i].ID != second.Packets[i].ID {
+			t.Errorf("packet %d: IDs differ %q vs %q", i, first.Packets[i].ID, second.Packets[i].ID)
+		}
+	}
+	if first.Packets[0].Type != agentctx.PacketTypeTask {
+		t.Errorf("expected task packet first, got %s", first.Packets[0].Type)
+	}
+	if diff := agentctx.Diff(first, second); !diff.IsEmpty() {
+		t.Errorf("expected empty diff, got %s", diff)
+	}
+}
+
+func TestStablePacketID(t *testing.T) {
+	a := agentctx.NewEvidencePacket("same content", "rag", 0.5)
+	b := agentctx.NewEvidencePacket("same content", "rag", 0.9)
+	c := agentctx.NewEvidencePacket("same content", "memory", 0.5)
+
+	if a.ID != b.ID {
+		t.Errorf("expected equal IDs for same type/source/content, got %q and %q", a.ID, b.ID)
+	}
+	if a.ID == c.ID {
+		t.Error("expected different IDs for different sources")
+	}
+	if a.Clone().ID != a.ID {
+		t.Error("Clone should preserve ID")
+	}
+}
+
+// alternatingHistory 生成 n 轮交替的用户/助手对话
+func alternatingHistory(n int) []message.Message {
+	history := make([]message.Message, 0, n)
+	for i := 0; i < n; i++ {
+		role := message.RoleUser
+		if i%2 == 1 {
+			role = message.RoleAssistant
+		}
+		history = append(history, message.Message{Role: role, Content: fmt.Sprintf("turn %d", i)})
+	}
+	return history
+}
+
+func TestHistoryGatherer_TurnLimits(t *testing.T) {
+	config := agentctx.NewConfig(
+		agentctx.WithMaxHistoryTurns(4),
+		agentctx.WithMaxHistoryTurnsPerRole(message.RoleAssistant, 1),
+		agentctx.WithHistorySummarizer(agentctx.NewBriefHistorySummarizer(0)),
+	)
+
+	gatherer := agentctx.NewHistoryGatherer(10)
+	packets, err := gatherer.Gather(context.Background(), &agentctx.GatherInput{
+		History: alternatingHistory(8),
+		Config:  config,
+	})
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+	if len(packets) != 1 {
+		t.Fatalf("expected 1 history packet, got %d", len(packets))
+	}
+
+	// 保留最近的 1 条助手消息和 3 条用户消息，其余 4 条进入摘要
+	content := packets[0].Content
+	for _, want := range []string{"[user] turn 2", "[user] turn 4", "[user] turn 6", "[assistant] turn 7"} {
+		if !strings.Contains(content, want+"\n") {
+			t.Errorf("expected kept message %q, got:\n%s", want, content)
+		}
+	}
+	if strings.Contains(content, "[assistant] turn 5\n") {
+		t.Errorf("assistant turn 5 should be summarized, got:\n%s", content)
+	}
+	if !strings.HasPrefix(content, "[对话摘要] user: turn 0; assistant: turn 1") {
+		t.Errorf("expected overflow summary first, got:\n%s", content)
+	}
+	if got := packets[0].Metadata["message_count"]; got != 4 {
+		t.Errorf("message_count = %v, want 4", got)
+	}
+	if got := packets[0].Metadata["summarized_count"]; got != 4 {
+		t.Errorf("summarized_count = %v, want 4", got)
+	}
+}
+
+func TestSimpleBuilder_BuildMessagesTurnLimits(t *testing.T) {
+	var summarized []message.Message
+	config := agentctx.NewConfig(
+		agentctx.WithTokenCounter(agentctx.NewEstimatedCounter()),
+		agentctx.WithMaxHistoryTurns(3),
+		agentctx.WithHistorySummarizer(agentctx.HistorySummarizerFunc(
+			func(_ context.Context, overflow []message.Message) (string, error) {
+				summarized = overflow
+				return "earlier small talk", nil
+			},
+		)),
+	)
+
+	builder := agentctx.NewSimpleBuilder(config)
+	messages, err := builder.BuildMessages(context.Background(), &agentctx.BuildInput{
+		Query:   "next question",
+		History: alternatingHistory(6),
+	})
+	if err != nil {
+		t.Fatalf("BuildMessages() error = %v", err)
+	}
+
+	// 摘要 + 3 条历史 + 查询
+	if len(messages) != 5 {
+		t.Fatalf("expected 5 messages, got %d", len(messages))
+	}
+	if messages[0].Role != message.RoleSystem || messages[0].Content != "[对话摘要] earlier small talk" {
+		t.Errorf("expected summary system message first, got %+v", messages[0])
+	}
+	if messages[1].Content != "turn 3" || messages[3].Content != "turn 5" {
+		t.Errorf("expected turns 3-5 kept, got %q..%q", messages[1].Content, messages[3].Content)
+	}
+	if len(summarized) != 3 || summarized[0].Content != "turn 0" {
+		t.Errorf("expected turns 0-2 summarized in order, got %v", summarized)
+	}
+}
@@ -0,0 +1,163 @@
+package interop_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ahhsitt/helloagents-go/pkg/interop"
+	"github.com/ahhsitt/helloagents-go/pkg/rag"
+	"github.com/ahhsitt/helloagents-go/pkg/tools"
+)
+
+// 以下类型模拟 LangChainGo / Eino 中的同名类型
+
+type lcDocument struct {
+	PageContent string
+	Metadata    map[string]any
+	Score       float32
+}
+
+type lcTool struct{}
+
+func (lcTool) Name() string        { return "upper" }
+func (lcTool) Description() string { return "uppercase the input" }
+func (lcTool) Call(_ context.Context, input string) (string, error) {
+	return strings.ToUpper(input), nil
+}
+
+type lcRetriever struct{}
+
+func (lcRetriever) GetRelevantDocuments(context.Context, string) ([]lcDocument, error) {
+	return []lcDocument{
+		{PageContent: "Go has goroutines", Metadata: map[string]any{"source": "go.md", "lang": "en"}, Score: 0.9},
+		{PageContent: "Rust has ownership", Score: 0.5},
+	}, nil
+}
+
+type einoOption struct{ topK int }
+
+type einoDocument struct {
+	ID       string
+	Content  string
+	MetaData map[string]any
+}
+
+type einoToolInfo struct {
+	Name string
+	Desc string
+}
+
+type einoEmbedder struct{ opts []einoOption }
+
+func (e *einoEmbedder) EmbedStrings(_ context.Context, texts []string, opts ...einoOption) ([][]float64, error) {
+	e.opts = opts
+	vectors := make([][]float64, len(texts))
+	for i, t := range texts {
+		vectors[i] = []float64{float64(len(t)), 1}
+	}
+	return vectors, nil
+}
+
+type einoRetriever struct{}
+
+func (einoRetriever) Retrieve(_ context.Context, query string, opts ...einoOption) ([]*einoDocument, error) {
+	docs := []*einoDocument{
+		{ID: "d1", Content: "about " + query, MetaData: map[string]any{"_score": 0.8, "title": "Intro"}},
+		{ID: "d2", Content: "more about " + query},
+	}
+	if len(opts) > 0 && opts[0].topK < len(docs) {
+		docs = docs[:opts[0].topK]
+	}
+	return docs, nil
+}
+
+type einoTool struct{}
+
+func (einoTool) Info(context.Context) (*einoToolInfo, error) {
+	return &einoToolInfo{Name: "echo", Desc: "echo arguments"}, nil
+}
+
+func (einoTool) InvokableRun(_ context.Context, argumentsInJSON string, _ ...einoOption) (string, error) {
+	return argumentsInJSON, nil
+}
+
+func TestLangChainAdapters(t *testing.T) {
+	ctx := context.Background()
+
+	tool := interop.FromLangChainTool(lcTool{})
+	if out, err := tool.Execute(ctx, map[string]interface{}{"input": "hi"}); err != nil || out != "HI" {
+		t.Errorf("Execute() = %q, %v", out, err)
+	}
+	back := interop.ToLangChainTool(tool)
+	if out, err := back.Call(ctx, "round trip"); err != nil || out != "ROUND TRIP" {
+		t.Errorf("Call() = %q, %v", out, err)
+	}
+
+	retriever := interop.FromLangChainRetriever(lcRetriever{},
+		func(d lcDocument) interop.LangChainDocument { return interop.LangChainDocument(d) })
+	results, err := retriever.Retrieve(ctx, "languages", 1)
+	if err != nil || len(results) != 1 {
+		t.Fatalf("Retrieve() = %v, %v", results, err)
+	}
+	if r := results[0]; r.Score != 0.9 || r.Chunk.Metadata.Source != "go.md" || r.Chunk.Metadata.Custom["lang"] != "en" || r.Chunk.ID == "" {
+		t.Errorf("unexpected result %+v", r)
+	}
+
+	docs, err := interop.ToLangChainRetriever(retriever, 2,
+		func(d interop.LangChainDocument) lcDocument { return lcDocument(d) }).GetRelevantDocuments(ctx, "languages")
+	if err != nil || len(docs) != 2 || docs[0].Metadata["source"] != "go.md" || docs[1].Score != 0.5 {
+		t.Errorf("GetRelevantDocuments() = %+v, %v", docs, err)
+	}
+}
+
+func TestEinoAdapters(t *testing.T) {
+	ctx := context.Background()
+
+	einoEmb := &einoEmbedder{}
+	embedder := interop.FromEinoEmbedder[einoOption](einoEmb, einoOption{topK: 3})
+	vectors, err := embedder.Embed(ctx, []string{"abc"})
+	if err != nil || len(vectors) != 1 || vectors[0][0] != 3 || len(einoEmb.opts) != 1 {
+		t.Errorf("Embed() = %v, %v (opts %v)", vectors, err, einoEmb.opts)
+	}
+	var _ rag.Embedder = embedder
+	if back, _ := interop.ToEinoEmbedder[einoOption](embedder).EmbedStrings(ctx, []string{"abcd"}); len(back) != 1 || back[0][0] != 4 {
+		t.Errorf("EmbedStrings() = %v", back)
+	}
+
+	retriever := interop.FromEinoRetriever[einoOption](einoRetriever{},
+		func(d *einoDocument) interop.EinoDocument { return interop.EinoDocument(*d) }, einoOption{topK: 1})
+	results, err := retriever.Retrieve(ctx, "go", 5)
+	if err != nil || len(results) != 1 {
+		t.Fatalf("Retrieve() = %v, %v", results, err)
+	}
+	if r := results[0]; r.Chunk.ID != "d1" || r.Score != 0.8 || r.Chunk.Metadata.Title != "Intro" {
+		t.Errorf("unexpected result %+v", r)
+	}
+
+	docs, err := interop.ToEinoRetriever[einoOption](retriever, 1,
+		func(d interop.EinoDocument) *einoDocument { doc := einoDocument(d); return &doc }).Retrieve(ctx, "go")
+	if err != nil || len(docs) != 1 || docs[0].ID != "d1" || interop.EinoDocument(*docs[0]).Score() != float64(float32(0.8)) {
+		t.Errorf("Retrieve() = %+v, %v", docs, err)
+	}
+
+	tool, err := interop.FromEinoTool[einoOption](ctx, einoTool{}, func(info *einoToolInfo) interop.EinoToolInfo {
+		return interop.EinoToolInfo{Name: info.Name, Description: info.Desc, Parameters: tools.ParameterSchema{Type: "object"}}
+	})
+	if err != nil || tool.Name() != "echo" {
+		t.Fatalf("FromEinoTool() = %v, %v", tool, err)
+	}
+	if out, err := tool.Execute(ctx, map[string]interface{}{"q": "go"}); err != nil || out != `{"q":"go"}` {
+		t.Errorf("Execute() = %q, %v", out, err)
+	}
+
+	wrapped := interop.ToEinoTool[einoOption](tool, func(info interop.EinoToolInfo) *einoToolInfo {
+		return &einoToolInfo{Name: info.Name, Desc: info.Description}
+	})
+	if info, _ := wrapped.Info(ctx); info.Name != "echo" {
+		t.Errorf("Info() = %+v", info)
+	}
+	if out, err := wrapped.InvokableRun(ctx, `{"q":"rust"}`); err != nil || out != `{"q":"rust"}` {
+		t.Errorf("InvokableRun() = %q, %v", out, err)
+	}
+}
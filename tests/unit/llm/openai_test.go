@@ -1,6 +1,10 @@
 package llm_test
 
 import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/ahhsitt/helloagents-go/pkg/core/errors"
@@ -145,3 +149,41 @@ func TestMessageRoles(t *testing.T) {
 
 // Note: Integration tests that require actual API calls should be placed
 // in tests/integration/ and use environment variables for API keys
+
+func TestOpenAIClient_StructuredToolMessage(t *testing.T) {
+	var body struct {
+		Messages []struct {
+			Role       string `json:"role"`
+			Content    string `json:"content"`
+			ToolCallID string `json:"tool_call_id"`
+		} `json:"messages"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"ok"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	client, err := llm.NewOpenAI(llm.WithAPIKey("test-api-key"), llm.WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("NewOpenAI() error = %v", err)
+	}
+	toolMsg := message.NewToolMessage("call-1", "weather", "")
+	toolMsg.StructuredContent = map[string]interface{}{"temperature": 21.5}
+	_, err = client.Generate(context.Background(), llm.Request{Messages: []message.Message{
+		message.NewUserMessage("weather?"),
+		{Role: message.RoleAssistant, ToolCalls: []message.ToolCall{{ID: "call-1", Name: "weather"}}},
+		toolMsg,
+	}})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	if len(body.Messages) != 3 {
+		t.Fatalf("sent %d messages, want 3", len(body.Messages))
+	}
+	if got := body.Messages[2]; got.Role != "tool" || got.ToolCallID != "call-1" || got.Content != `{"temperature":21.5}` {
+		t.Errorf("tool message = %+v", got)
+	}
+}
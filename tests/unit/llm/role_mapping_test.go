@@ -0,0 +1,104 @@
+package llm_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ahhsitt/helloagents-go/pkg/core/llm"
+	"github.com/ahhsitt/helloagents-go/pkg/core/message"
+)
+
+// capturingProvider 记录收到的请求
+type capturingProvider struct {
+	lastRequest llm.Request
+}
+
+func (p *capturingProvider) Generate(_ context.Context, req llm.Request) (llm.Response, error) {
+	p.lastRequest = req
+	return llm.Response{Content: "ok"}, nil
+}
+
+func (p *capturingProvider) GenerateStream(_ context.Context, req llm.Request) (<-chan llm.StreamChunk, <-chan error) {
+	p.lastRequest = req
+	return nil, nil
+}
+
+func (p *capturingProvider) Embed(context.Context, []string) ([][]float32, error) { return nil, nil }
+func (p *capturingProvider) Name() string                                         { return "capturing" }
+func (p *capturingProvider) Model() string                                        { return "capturing-model" }
+func (p *capturingProvider) Close() error                                         { return nil }
+
+func toolConversation() []message.Message {
+	return []message.Message{
+		message.NewSystemMessage("You are helpful."),
+		message.NewUserMessage("What is 2+3?"),
+		{
+			Role:      message.RoleAssistant,
+			ToolCalls: []message.ToolCall{{ID: "1", Name: "calculator", Arguments: map[string]interface{}{"expression": "2+3"}}},
+		},
+		message.NewToolMessage("1", "calculator", "5"),
+	}
+}
+
+func TestRolePolicy_MergeSystem(t *testing.T) {
+	msgs := toolConversation()
+	result := llm.RolePolicy{MergeSystem: true}.Apply(msgs)
+
+	if len(result) != 3 {
+		t.Fatalf("expected 3 messages, got %d", len(result))
+	}
+	if result[0].Role != message.RoleUser || result[0].Content != "You are helpful.\n\nWhat is 2+3?" {
+		t.Errorf("unexpected merged message: %+v", result[0])
+	}
+	if msgs[0].Role != message.RoleSystem {
+		t.Error("Apply() should not modify the input slice")
+	}
+
+	// 没有 user 消息时插入一条
+	result = llm.RolePolicy{MergeSystem: true}.Apply([]message.Message{message.NewSystemMessage("rules")})
+	if len(result) != 1 || result[0].Role != message.RoleUser || result[0].Content != "rules" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+}
+
+func TestRolePolicy_ConvertToolMessages(t *testing.T) {
+	result := llm.RolePolicy{ConvertToolMessages: true}.Apply(toolConversation())
+
+	for _, msg := range result {
+		if msg.Role == message.RoleTool || len(msg.ToolCalls) > 0 {
+			t.Errorf("tool messages should be converted, got %+v", msg)
+		}
+	}
+	if result[2].Content != `[Tool call: calculator({"expression":"2+3"})]` {
+		t.Errorf("unexpected tool call text: %q", result[2].Content)
+	}
+	if result[3].Role != message.RoleUser || result[3].Content != "[Tool result: calculator]\n5" {
+		t.Errorf("unexpected tool result message: %+v", result[3])
+	}
+}
+
+func TestRoleMappingProvider_Strict(t *testing.T) {
+	inner := &capturingProvider{}
+	provider := llm.NewRoleMappingProvider(inner, llm.StrictRolePolicy())
+
+	msgs := append(toolConversation(), message.NewUserMessage("Thanks"))
+	if _, err := provider.Generate(context.Background(), llm.Request{Messages: msgs}); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	sent := inner.lastRequest.Messages
+	roles := make([]string, len(sent))
+	for i, msg := range sent {
+		roles[i] = string(msg.Role)
+	}
+	if got := strings.Join(roles, ","); got != "user,assistant,user" {
+		t.Errorf("roles = %s, want user,assistant,user", got)
+	}
+	if !strings.HasSuffix(sent[2].Content, "5\n\nThanks") {
+		t.Errorf("consecutive user messages should be merged, got %q", sent[2].Content)
+	}
+	if provider.Name() != "capturing" {
+		t.Errorf("Name() = %v, want capturing", provider.Name())
+	}
+}
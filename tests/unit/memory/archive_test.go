@@ -0,0 +1,84 @@
+package memory_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ahhsitt/helloagents-go/pkg/memory"
+	"github.com/ahhsitt/helloagents-go/pkg/memory/store"
+)
+
+// failingPutStore 写入总是失败的文档存储
+type failingPutStore struct {
+	store.DocumentStore
+}
+
+func (failingPutStore) Put(context.Context, string, string, store.Document) error {
+	return errors.New("disk full")
+}
+
+func TestMemoryManager_ForgetArchivesAndRestores(t *testing.T) {
+	ctx := context.Background()
+	archive := memory.NewArchiveStore(store.NewMemoryDocumentStore())
+	episodic := memory.NewEpisodicMemory()
+	working := memory.NewWorkingMemory()
+	manager := memory.NewMemoryManager(nil, memory.WithManagerUserID("alice"), memory.WithArchiveStore(archive))
+	_ = manager.RegisterMemory(memory.MemoryTypeEpisodic, episodic)
+	_ = manager.RegisterMemory(memory.MemoryTypeWorking, working)
+
+	lowID, _ := manager.AddMemory(ctx, "had coffee with the team", memory.WithAddMemoryType(memory.MemoryTypeEpisodic),
+		memory.WithAddImportance(0.1), memory.WithAddMetadata(map[string]interface{}{"source": "calendar"}))
+	_, _ = manager.AddMemory(ctx, "signed the enterprise contract", memory.WithAddMemoryType(memory.MemoryTypeEpisodic), memory.WithAddImportance(0.9))
+	chatID, _ := manager.AddMemory(ctx, "small talk about the weather", memory.WithAddMemoryType(memory.MemoryTypeWorking), memory.WithAddImportance(0.1))
+
+	n, err := manager.ForgetMemories(ctx, memory.ForgetByImportance, memory.WithThreshold(0.5))
+	if err != nil || n != 2 {
+		t.Fatalf("ForgetMemories() = %d, %v, want 2", n, err)
+	}
+	if episodic.Has(ctx, lowID) || working.Has(ctx, chatID) {
+		t.Fatal("expected forgotten memories to be removed from their stores")
+	}
+
+	archived, err := archive.List(ctx, memory.MemoryTypeEpisodic)
+	if err != nil || len(archived) != 1 {
+		t.Fatalf("List() = %d items, %v, want 1", len(archived), err)
+	}
+	got := archived[0]
+	if got.Item.ID != lowID || got.Item.UserID != "alice" || got.Item.Importance != 0.1 ||
+		got.Item.Metadata["source"] != "calendar" || got.Strategy != memory.ForgetByImportance || got.ArchivedAt.IsZero() {
+		t.Errorf("unexpected archived memory %+v (item %+v)", got, got.Item)
+	}
+
+	restored, err := manager.RestoreMemories(ctx, lowID)
+	if err != nil || restored != 1 {
+		t.Fatalf("RestoreMemories() = %d, %v", restored, err)
+	}
+	if !episodic.Has(ctx, lowID) {
+		t.Error("expected restored memory back in episodic memory")
+	}
+	if _, err := archive.Get(ctx, lowID); !errors.Is(err, memory.ErrNotFound) {
+		t.Errorf("expected restored memory to leave the archive, got %v", err)
+	}
+	if _, err := manager.RestoreMemories(ctx, lowID); !errors.Is(err, memory.ErrNotFound) {
+		t.Errorf("expected ErrNotFound restoring twice, got %v", err)
+	}
+
+	if _, err := memory.NewMemoryManager(nil).RestoreMemories(ctx, chatID); !errors.Is(err, memory.ErrArchiveNotConfigured) {
+		t.Errorf("expected ErrArchiveNotConfigured, got %v", err)
+	}
+}
+
+func TestForget_ArchiveFailureKeepsMemories(t *testing.T) {
+	ctx := context.Background()
+	archive := memory.NewArchiveStore(failingPutStore{store.NewMemoryDocumentStore()})
+	episodic := memory.NewEpisodicMemory()
+	_ = episodic.AddEpisode(ctx, memory.Episode{ID: "e1", Content: "minor event", Importance: 0.1})
+
+	if _, err := episodic.Forget(ctx, memory.ForgetByImportance, memory.WithThreshold(0.5), memory.WithForgetArchive(archive)); err == nil {
+		t.Fatal("expected archive error")
+	}
+	if !episodic.Has(ctx, "e1") {
+		t.Error("expected memory to survive a failed archive")
+	}
+}
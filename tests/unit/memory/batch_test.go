@@ -0,0 +1,105 @@
+package memory_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ahhsitt/helloagents-go/pkg/memory"
+)
+
+func batchItems(memType memory.MemoryType, contents ...string) []*memory.MemoryItem {
+	items := make([]*memory.MemoryItem, len(contents))
+	for i, content := range contents {
+		items[i] = memory.NewMemoryItem(content, memType)
+	}
+	return items
+}
+
+func TestSemanticMemory_AddBatch_SingleEmbedCall(t *testing.T) {
+	ctx := context.Background()
+	calls := 0
+	embedder := &mockEmbedder{embedFn: func(ctx context.Context, texts []string) ([][]float32, error) {
+		calls++
+		return newMockEmbedder().Embed(ctx, texts)
+	}}
+	mem := memory.NewSemanticMemory(embedder)
+
+	items := batchItems(memory.MemoryTypeSemantic, "Go is a compiled language", "Python is interpreted", "Rust has ownership")
+	ids, err := mem.AddBatch(ctx, items)
+	if err != nil {
+		t.Fatalf("AddBatch() error = %v", err)
+	}
+	if len(ids) != 3 || ids[0] != items[0].ID {
+		t.Fatalf("unexpected ids: %v", ids)
+	}
+	if calls != 1 {
+		t.Errorf("expected 1 embed call, got %d", calls)
+	}
+	if mem.Size() != 3 {
+		t.Errorf("expected size 3, got %d", mem.Size())
+	}
+
+	calls = 0
+	results, err := mem.RetrieveMulti(ctx, []string{"compiled language", "ownership"}, memory.WithLimit(1))
+	if err != nil {
+		t.Fatalf("RetrieveMulti() error = %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected 1 embed call for all queries, got %d", calls)
+	}
+	if len(results) != 2 || len(results[0]) != 1 || len(results[1]) != 1 {
+		t.Fatalf("unexpected results: %v", results)
+	}
+}
+
+func TestSemanticMemory_AddBatch_InvalidItem(t *testing.T) {
+	mem := memory.NewSemanticMemory(newMockEmbedder())
+	items := batchItems(memory.MemoryTypeSemantic, "valid", "")
+
+	if _, err := mem.AddBatch(context.Background(), items); !errors.Is(err, memory.ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput, got %v", err)
+	}
+	if mem.Size() != 0 {
+		t.Errorf("invalid batch should not be written, got size %d", mem.Size())
+	}
+}
+
+func TestMemory_RemoveBatch(t *testing.T) {
+	ctx := context.Background()
+	stores := map[string]memory.Memory{
+		"working":  memory.NewWorkingMemory(),
+		"episodic": memory.NewEpisodicMemory(),
+		"semantic": memory.NewSemanticMemory(nil),
+	}
+
+	for name, mem := range stores {
+		t.Run(name, func(t *testing.T) {
+			ids, err := mem.AddBatch(ctx, batchItems(memory.MemoryTypeWorking, "first note", "second note", "third note"))
+			if err != nil {
+				t.Fatalf("AddBatch() error = %v", err)
+			}
+
+			err = mem.RemoveBatch(ctx, []string{ids[0], ids[2], "missing"})
+			if !errors.Is(err, memory.ErrNotFound) {
+				t.Errorf("expected ErrNotFound for missing id, got %v", err)
+			}
+			if mem.Has(ctx, ids[0]) || mem.Has(ctx, ids[2]) || !mem.Has(ctx, ids[1]) {
+				t.Error("existing ids should be removed even when some are missing")
+			}
+
+			results, err := mem.RetrieveMulti(ctx, []string{"second", "first"})
+			if err != nil {
+				t.Fatalf("RetrieveMulti() error = %v", err)
+			}
+			if len(results) != 2 || len(results[0]) != 1 || results[0][0].Content != "second note" {
+				t.Fatalf("unexpected results: %v", results)
+			}
+			for _, item := range results[1] {
+				if item.Content == "first note" {
+					t.Error("removed item should not be retrieved")
+				}
+			}
+		})
+	}
+}
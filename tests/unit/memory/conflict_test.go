@@ -0,0 +1,89 @@
+package memory_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ahhsitt/helloagents-go/pkg/memory"
+)
+
+func TestMemoryManager_ConflictResolution(t *testing.T) {
+	ctx := context.Background()
+	provider := &summaryProvider{generateFn: func(prompt string) (string, error) {
+		if !strings.Contains(prompt, "user lives in SF") {
+			return `{"conflicts":[]}`, nil
+		}
+		return `{"conflicts":[{"index":1,"reason":"location changed","merged":"user moved from SF to NYC"}]}`, nil
+	}}
+
+	tests := []struct {
+		policy memory.ConflictPolicy
+		check  func(t *testing.T, semantic *memory.SemanticMemoryStore, id string)
+	}{
+		{memory.ConflictSupersede, func(t *testing.T, semantic *memory.SemanticMemoryStore, id string) {
+			if semantic.Has(ctx, "old") || !semantic.Has(ctx, id) || semantic.Size() != 1 {
+				t.Errorf("expected the new memory to replace the old one, size %d", semantic.Size())
+			}
+		}},
+		{memory.ConflictMerge, func(t *testing.T, semantic *memory.SemanticMemoryStore, id string) {
+			items, _ := semantic.Retrieve(ctx, "user moved", memory.WithLimit(1))
+			if id != "old" || semantic.Size() != 1 || len(items) != 1 || items[0].Content != "user moved from SF to NYC" {
+				t.Errorf("expected merged memory under the old id, got id %q and %+v", id, items)
+			}
+		}},
+		{memory.ConflictKeepBoth, func(t *testing.T, semantic *memory.SemanticMemoryStore, id string) {
+			items, _ := semantic.Retrieve(ctx, "user", memory.WithLimit(5))
+			flagged := 0
+			for _, item := range items {
+				if item.Metadata[memory.MetadataConflictsWith] != nil {
+					flagged++
+				}
+			}
+			if semantic.Size() != 2 || flagged != 2 {
+				t.Errorf("expected both memories flagged, size %d flagged %d", semantic.Size(), flagged)
+			}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			semantic := memory.NewSemanticMemory(newMockEmbedder())
+			_ = semantic.Store(ctx, "old", "user lives in SF", nil)
+
+			detector := memory.NewLLMConflictDetector(provider, nil)
+			manager := memory.NewMemoryManager(nil, memory.WithConflictDetection(detector, tt.policy))
+			_ = manager.RegisterMemory(memory.MemoryTypeSemantic, semantic)
+
+			id, err := manager.AddMemory(ctx, "user moved to NYC", memory.WithAddMemoryType(memory.MemoryTypeSemantic))
+			if err != nil {
+				t.Fatalf("AddMemory() error = %v", err)
+			}
+			tt.check(t, semantic, id)
+		})
+	}
+}
+
+func TestLLMConflictDetector_SimilarityFilter(t *testing.T) {
+	ctx := context.Background()
+	calls := 0
+	provider := &summaryProvider{generateFn: func(string) (string, error) {
+		calls++
+		return `{"conflicts":[{"index":1}]}`, nil
+	}}
+
+	detector := memory.NewLLMConflictDetector(provider, newMockEmbedder(), memory.WithConflictSimilarity(1.01))
+	item := memory.NewMemoryItem("user moved to NYC", memory.MemoryTypeSemantic)
+	candidates := []*memory.MemoryItem{memory.NewMemoryItem("user lives in SF", memory.MemoryTypeSemantic)}
+
+	conflicts, err := detector.Detect(ctx, item, candidates)
+	if err != nil || len(conflicts) != 0 || calls != 0 {
+		t.Errorf("expected dissimilar candidates to skip verification, got %v, %v, %d calls", conflicts, err, calls)
+	}
+
+	detector = memory.NewLLMConflictDetector(provider, newMockEmbedder(), memory.WithConflictSimilarity(-1))
+	conflicts, err = detector.Detect(ctx, item, candidates)
+	if err != nil || len(conflicts) != 1 || conflicts[0].Existing != candidates[0] {
+		t.Errorf("expected one verified conflict, got %v, %v", conflicts, err)
+	}
+}
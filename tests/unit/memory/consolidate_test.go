@@ -0,0 +1,123 @@
+package memory_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ahhsitt/helloagents-go/pkg/core/llm"
+	"github.com/ahhsitt/helloagents-go/pkg/memory"
+)
+
+// summaryProvider implements llm.Provider for consolidation tests
+type summaryProvider struct {
+	prompts    []string
+	generateFn func(prompt string) (string, error)
+}
+
+func (p *summaryProvider) Name() string  { return "mock" }
+func (p *summaryProvider) Model() string { return "mock-model" }
+func (p *summaryProvider) Close() error  { return nil }
+
+func (p *summaryProvider) Generate(ctx context.Context, req llm.Request) (llm.Response, error) {
+	prompt := req.Messages[len(req.Messages)-1].Content
+	p.prompts = append(p.prompts, prompt)
+	content, err := p.generateFn(prompt)
+	return llm.Response{Content: content}, err
+}
+
+func (p *summaryProvider) GenerateStream(ctx context.Context, req llm.Request) (<-chan llm.StreamChunk, <-chan error) {
+	return nil, nil
+}
+
+func (p *summaryProvider) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	return nil, nil
+}
+
+func newConsolidationManager(t *testing.T, contents ...string) (*memory.MemoryManager, *memory.WorkingMemory, *memory.EpisodicMemoryStore, []string) {
+	t.Helper()
+	ctx := context.Background()
+	working := memory.NewWorkingMemory()
+	episodic := memory.NewEpisodicMemory()
+	manager := memory.NewMemoryManager(nil)
+	_ = manager.RegisterMemory(memory.MemoryTypeWorking, working)
+	_ = manager.RegisterMemory(memory.MemoryTypeEpisodic, episodic)
+
+	ids := make([]string, len(contents))
+	for i, content := range contents {
+		id, err := manager.AddMemory(ctx, content,
+			memory.WithAddMemoryType(memory.MemoryTypeWorking),
+			memory.WithAddImportance(0.9),
+		)
+		if err != nil {
+			t.Fatalf("AddMemory() error = %v", err)
+		}
+		ids[i] = id
+	}
+	return manager, working, episodic, ids
+}
+
+func TestMemoryManager_ConsolidateWithSummarizer(t *testing.T) {
+	ctx := context.Background()
+	manager, working, episodic, ids := newConsolidationManager(t,
+		"deploy service api to staging cluster",
+		"deploy service api failed on staging cluster",
+		"buy milk tomorrow",
+	)
+	provider := &summaryProvider{generateFn: func(prompt string) (string, error) {
+		return "api deploy to staging failed", nil
+	}}
+
+	n, err := manager.ConsolidateMemories(ctx, memory.WithConsolidateSummarizer(provider))
+	if err != nil {
+		t.Fatalf("ConsolidateMemories() error = %v", err)
+	}
+	if n != 3 {
+		t.Errorf("expected 3 consolidated memories, got %d", n)
+	}
+	if len(provider.prompts) != 1 || !strings.Contains(provider.prompts[0], "failed on staging") {
+		t.Fatalf("expected one summary call for the deploy cluster, got %v", provider.prompts)
+	}
+	if working.Size() != 0 || episodic.Size() != 2 {
+		t.Fatalf("expected working=0 episodic=2, got %d and %d", working.Size(), episodic.Size())
+	}
+
+	items, err := episodic.Retrieve(ctx, "", memory.WithLimit(10))
+	if err != nil {
+		t.Fatalf("Retrieve() error = %v", err)
+	}
+	var summary *memory.MemoryItem
+	for _, item := range items {
+		if item.Content == "api deploy to staging failed" {
+			summary = item
+		}
+	}
+	if summary == nil {
+		t.Fatalf("expected summarized memory, got %v", items)
+	}
+	from, ok := summary.Metadata["consolidated_from"].([]string)
+	if !ok || len(from) != 2 || from[0] != ids[0] || from[1] != ids[1] {
+		t.Errorf("expected provenance %v, got %v", ids[:2], summary.Metadata["consolidated_from"])
+	}
+}
+
+func TestMemoryManager_ConsolidateSummarizerFailureKeepsWorking(t *testing.T) {
+	ctx := context.Background()
+	manager, working, episodic, _ := newConsolidationManager(t,
+		"deploy service api to staging cluster",
+		"deploy service api failed on staging cluster",
+	)
+	provider := &summaryProvider{generateFn: func(prompt string) (string, error) {
+		return "", errors.New("llm unavailable")
+	}}
+
+	n, err := manager.ConsolidateMemories(ctx, memory.WithConsolidateSummarizer(provider))
+	if err != nil {
+		t.Fatalf("ConsolidateMemories() error = %v", err)
+	}
+	if n != 0 || working.Size() != 2 || episodic.Size() != 0 {
+		t.Errorf("failed cluster should stay in working memory, got n=%d working=%d episodic=%d",
+			n, working.Size(), episodic.Size())
+	}
+}
@@ -0,0 +1,77 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ahhsitt/helloagents-go/pkg/memory"
+	"github.com/ahhsitt/helloagents-go/pkg/rag"
+)
+
+// the cache wraps embedders for both memory and RAG
+var _ rag.Embedder = (*memory.EmbeddingCache)(nil)
+
+func TestEmbeddingCache_DeduplicatesAndCaches(t *testing.T) {
+	ctx := context.Background()
+	inner := &constEmbedder{value: 1}
+	cache := memory.NewEmbeddingCache(inner, memory.WithEmbeddingCacheSize(2))
+
+	vectors, err := cache.Embed(ctx, []string{"a", "b", "a"})
+	if err != nil {
+		t.Fatalf("Embed() error = %v", err)
+	}
+	if len(vectors) != 3 || vectors[2] == nil {
+		t.Fatalf("expected a vector per input, got %v", vectors)
+	}
+	if len(inner.calls) != 1 || len(inner.calls[0]) != 2 {
+		t.Fatalf("expected one call with 2 unique texts, got %v", inner.calls)
+	}
+
+	_, _ = cache.Embed(ctx, []string{"b", "a"})
+	if len(inner.calls) != 1 {
+		t.Errorf("expected cached texts not to be re-embedded, got %v", inner.calls)
+	}
+
+	// 容量为 2，加入 c 后最久未使用的 b 被淘汰
+	_, _ = cache.Embed(ctx, []string{"c"})
+	_, _ = cache.Embed(ctx, []string{"b"})
+	if len(inner.calls) != 3 || inner.calls[2][0] != "b" {
+		t.Errorf("expected evicted text to be re-embedded, got %v", inner.calls)
+	}
+
+	stats := cache.Stats()
+	if stats.Hits != 3 || stats.Misses != 4 || stats.Size != 2 {
+		t.Errorf("Stats() = %+v, want 3 hits, 4 misses, size 2", stats)
+	}
+}
+
+func TestEmbeddingCache_Persistence(t *testing.T) {
+	ctx := context.Background()
+	docStore := newSQLiteStore(t)
+
+	first := &constEmbedder{value: 3}
+	_, err := memory.NewEmbeddingCache(first, memory.WithEmbeddingCacheStore(docStore, "")).Embed(ctx, []string{"hello"})
+	if err != nil {
+		t.Fatalf("Embed() error = %v", err)
+	}
+
+	// 模拟重启：新缓存从同一存储读取
+	second := &constEmbedder{value: 5}
+	vectors, err := memory.NewEmbeddingCache(second, memory.WithEmbeddingCacheStore(docStore, "")).Embed(ctx, []string{"hello"})
+	if err != nil {
+		t.Fatalf("Embed() error = %v", err)
+	}
+	if len(second.calls) != 0 || vectors[0][0] != 3 {
+		t.Errorf("expected persisted vector [3 1], got %v (calls %v)", vectors, second.calls)
+	}
+
+	// 不同命名空间互不命中
+	other := &constEmbedder{value: 7}
+	_, _ = memory.NewEmbeddingCache(other,
+		memory.WithEmbeddingCacheStore(docStore, ""),
+		memory.WithEmbeddingCacheNamespace("other-model"),
+	).Embed(ctx, []string{"hello"})
+	if len(other.calls) != 1 {
+		t.Errorf("expected a different namespace to miss, got %v", other.calls)
+	}
+}
@@ -0,0 +1,88 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ahhsitt/helloagents-go/pkg/memory"
+)
+
+// nextEvent 读取下一个事件，超时则失败
+func nextEvent(t *testing.T, events <-chan memory.MemoryEvent) memory.MemoryEvent {
+	t.Helper()
+	select {
+	case event := <-events:
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for memory event")
+		return memory.MemoryEvent{}
+	}
+}
+
+func TestMemoryManager_Subscribe(t *testing.T) {
+	ctx := context.Background()
+	manager := memory.NewMemoryManager(nil, memory.WithManagerUserID("alice"))
+	_ = manager.RegisterMemory(memory.MemoryTypeWorking, memory.NewWorkingMemory())
+	_ = manager.RegisterMemory(memory.MemoryTypeEpisodic, memory.NewEpisodicMemory())
+
+	subCtx, cancel := context.WithCancel(ctx)
+	events := manager.Subscribe(subCtx)
+	forgets := manager.Subscribe(subCtx, memory.WithEventTypes(memory.EventMemoryForgotten))
+
+	id, _ := manager.AddMemory(ctx, "prefers tea", memory.WithAddMemoryType(memory.MemoryTypeWorking), memory.WithAddImportance(0.9))
+	if e := nextEvent(t, events); e.Type != memory.EventMemoryAdded || e.ID != id || e.Content != "prefers tea" ||
+		e.MemoryType != memory.MemoryTypeWorking || e.UserID != "alice" || e.Timestamp.IsZero() {
+		t.Errorf("unexpected add event %+v", e)
+	}
+
+	_ = manager.UpdateMemory(ctx, memory.MemoryTypeWorking, id, memory.WithImportanceUpdate(0.95))
+	if e := nextEvent(t, events); e.Type != memory.EventMemoryUpdated || e.ID != id {
+		t.Errorf("unexpected update event %+v", e)
+	}
+	if err := manager.UpdateMemory(ctx, memory.MemoryTypeWorking, "missing", memory.WithImportanceUpdate(0.1)); err == nil {
+		t.Error("expected error updating missing memory")
+	}
+
+	if n, _ := manager.ConsolidateMemories(ctx); n != 1 {
+		t.Fatalf("ConsolidateMemories() = %d, want 1", n)
+	}
+	if e := nextEvent(t, events); e.Type != memory.EventMemoryConsolidated || e.Count != 1 || e.MemoryType != memory.MemoryTypeEpisodic {
+		t.Errorf("unexpected consolidate event %+v", e)
+	}
+
+	if n, _ := manager.ForgetMemories(ctx, memory.ForgetByImportance, memory.WithThreshold(1)); n != 1 {
+		t.Fatalf("ForgetMemories() = %d, want 1", n)
+	}
+	for _, ch := range []<-chan memory.MemoryEvent{events, forgets} {
+		if e := nextEvent(t, ch); e.Type != memory.EventMemoryForgotten || e.Count != 1 || e.Strategy != memory.ForgetByImportance {
+			t.Errorf("unexpected forget event %+v", e)
+		}
+	}
+
+	cancel()
+	for range events {
+	}
+	if _, ok := <-forgets; ok {
+		t.Error("expected filtered subscription to close after cancel")
+	}
+}
+
+func TestMemoryManager_SubscribeDropsWhenFull(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	manager := memory.NewMemoryManager(nil)
+	_ = manager.RegisterMemory(memory.MemoryTypeWorking, memory.NewWorkingMemory())
+
+	events := manager.Subscribe(ctx, memory.WithEventBuffer(1))
+	for _, content := range []string{"one", "two", "three"} {
+		_, _ = manager.AddMemory(ctx, content, memory.WithAddMemoryType(memory.MemoryTypeWorking))
+	}
+
+	if e := nextEvent(t, events); e.Content != "one" {
+		t.Errorf("expected first event kept, got %+v", e)
+	}
+	if got := manager.DroppedEvents(); got != 2 {
+		t.Errorf("DroppedEvents() = %d, want 2", got)
+	}
+}
@@ -0,0 +1,129 @@
+package memory_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ahhsitt/helloagents-go/pkg/memory"
+)
+
+const extractionResponse = "```json\n" + `{
+  "entities": [
+    {"name": "Alice", "type": "Person", "confidence": 0.95},
+    {"name": "Acme", "type": "organization", "confidence": 0.9},
+    {"name": "Berlin", "type": "city", "confidence": 0.8},
+    {"name": "maybe", "type": "concept", "confidence": 0.1}
+  ],
+  "relations": [
+    {"from": "Alice", "to": "Acme", "type": "works_at", "confidence": 0.9, "context": "Alice works at Acme"},
+    {"from": "Acme", "to": "Berlin", "type": "headquartered_in", "confidence": 0.7},
+    {"from": "Alice", "to": "maybe", "type": "knows", "confidence": 0.9}
+  ]
+}` + "\n```"
+
+func TestLLMEntityExtractor_Extract(t *testing.T) {
+	provider := &summaryProvider{generateFn: func(prompt string) (string, error) {
+		return extractionResponse, nil
+	}}
+	extractor := memory.NewLLMEntityExtractor(provider, memory.WithMinExtractionConfidence(0.5))
+
+	content := "Alice works at Acme in Berlin."
+	result, err := extractor.Extract(context.Background(), content)
+	if err != nil {
+		t.Fatalf("Extract() error = %v", err)
+	}
+
+	if len(result.Entities) != 3 {
+		t.Fatalf("expected 3 entities above confidence threshold, got %d", len(result.Entities))
+	}
+	if result.Entities[0].Type != memory.EntityTypePerson {
+		t.Errorf("expected type to be normalized to person, got %s", result.Entities[0].Type)
+	}
+	if result.Entities[2].Type != memory.EntityTypeOther {
+		t.Errorf("expected unknown type to map to other, got %s", result.Entities[2].Type)
+	}
+	if result.Entities[1].StartPos != 15 || result.Entities[1].EndPos != 19 {
+		t.Errorf("expected Acme at [15,19), got [%d,%d)", result.Entities[1].StartPos, result.Entities[1].EndPos)
+	}
+
+	// 指向被过滤实体的关系被丢弃
+	if len(result.Relations) != 2 {
+		t.Fatalf("expected 2 relations, got %d", len(result.Relations))
+	}
+	if result.Relations[1].RelationType != memory.RelationTypeRelatedTo {
+		t.Errorf("expected unknown relation to map to related_to, got %s", result.Relations[1].RelationType)
+	}
+}
+
+func TestLLMEntityExtractor_Fallback(t *testing.T) {
+	provider := &summaryProvider{generateFn: func(prompt string) (string, error) {
+		return "", errors.New("rate limited")
+	}}
+
+	extractor := memory.NewLLMEntityExtractor(provider)
+	if _, err := extractor.Extract(context.Background(), "Alice Smith works at Acme Corp"); err == nil {
+		t.Error("expected error without fallback")
+	}
+
+	extractor = memory.NewLLMEntityExtractor(provider, memory.WithExtractionFallback(memory.NewRuleEntityExtractor()))
+	result, err := extractor.Extract(context.Background(), "Alice Smith works at Acme Corp")
+	if err != nil {
+		t.Fatalf("Extract() error = %v", err)
+	}
+	if len(result.Entities) == 0 {
+		t.Error("expected rule-based fallback to find entities")
+	}
+}
+
+func TestMemoryManager_EntityExtractor(t *testing.T) {
+	ctx := context.Background()
+	provider := &summaryProvider{generateFn: func(prompt string) (string, error) {
+		return extractionResponse, nil
+	}}
+	semantic := memory.NewSemanticMemory(nil)
+	manager := memory.NewMemoryManager(nil,
+		memory.WithEntityExtractor(memory.NewLLMEntityExtractor(provider, memory.WithMinExtractionConfidence(0.5))),
+	)
+	_ = manager.RegisterMemory(memory.MemoryTypeSemantic, semantic)
+
+	id, err := manager.AddMemory(ctx, "Alice works at Acme in Berlin.", memory.WithAddMemoryType(memory.MemoryTypeSemantic))
+	if err != nil {
+		t.Fatalf("AddMemory() error = %v", err)
+	}
+
+	if semantic.EntityCount() != 3 || semantic.RelationCount() != 2 {
+		t.Fatalf("expected 3 entities and 2 relations, got %d/%d", semantic.EntityCount(), semantic.RelationCount())
+	}
+
+	alice, err := semantic.GetEntityByName(ctx, "alice")
+	if err != nil {
+		t.Fatalf("GetEntityByName() error = %v", err)
+	}
+	related, err := semantic.GetRelatedEntities(ctx, alice.ID, 1)
+	if err != nil {
+		t.Fatalf("GetRelatedEntities() error = %v", err)
+	}
+	if len(related) != 1 || related[0].Entity.Name != "Acme" {
+		t.Fatalf("expected Alice related to Acme, got %v", related)
+	}
+	rel := related[0].Path[0]
+	if rel.RelationType != memory.RelationTypeWorksAt || rel.Strength != 0.9 {
+		t.Errorf("unexpected relation %s with strength %v", rel.RelationType, rel.Strength)
+	}
+	if len(rel.Evidence) != 1 || rel.Evidence[0] != id {
+		t.Errorf("expected memory %s as evidence, got %v", id, rel.Evidence)
+	}
+
+	// 再次提到同名实体只增加频率
+	if _, err := manager.AddMemory(ctx, "Alice works at Acme in Berlin.", memory.WithAddMemoryType(memory.MemoryTypeSemantic)); err != nil {
+		t.Fatalf("AddMemory() error = %v", err)
+	}
+	if semantic.EntityCount() != 3 {
+		t.Errorf("expected entities to be deduplicated, got %d", semantic.EntityCount())
+	}
+	alice, _ = semantic.GetEntityByName(ctx, "Alice")
+	if alice.Frequency != 2 {
+		t.Errorf("expected Alice frequency 2, got %d", alice.Frequency)
+	}
+}
@@ -0,0 +1,59 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ahhsitt/helloagents-go/pkg/memory"
+)
+
+func TestSemanticMemory_SearchWithGraphExpansion(t *testing.T) {
+	ctx := context.Background()
+	m := memory.NewSemanticMemory(nil)
+
+	for id, content := range map[string]string{
+		"r1": "Alice leads the search team",
+		"r2": "Acme Corp is headquartered in the old harbour",
+		"r3": "Berlin has great coffee",
+		"r4": "notes about gardening",
+	} {
+		_ = m.Store(ctx, id, content, nil)
+	}
+
+	alice := memory.NewEntity("Alice", memory.EntityTypePerson)
+	acme := memory.NewEntity("Acme Corp", memory.EntityTypeOrganization)
+	berlin := memory.NewEntity("Berlin", memory.EntityTypeLocation)
+	for _, e := range []*memory.Entity{alice, acme, berlin} {
+		_ = m.AddEntity(ctx, e)
+	}
+	worksAt := memory.NewRelation(alice.ID, acme.ID, memory.RelationTypeWorksAt)
+	locatedIn := memory.NewRelation(acme.ID, berlin.ID, memory.RelationTypeLocatedIn)
+	locatedIn.UpdateStrength(0.8)
+	_ = m.AddRelation(ctx, worksAt)
+	_ = m.AddRelation(ctx, locatedIn)
+
+	results, err := m.SearchWithGraphExpansion(ctx, "search team", 1)
+	if err != nil {
+		t.Fatalf("SearchWithGraphExpansion() error = %v", err)
+	}
+	if len(results) != 2 || results[0].ID != "r1" || results[0].Hop != 0 || results[1].ID != "r2" {
+		t.Fatalf("unexpected one-hop results %+v", results)
+	}
+	hop := results[1]
+	if hop.Hop != 1 || hop.SeedID != "r1" || hop.Entity.ID != acme.ID || len(hop.Path) != 1 || hop.Score != results[0].Score*0.5 {
+		t.Errorf("unexpected provenance %+v", hop)
+	}
+
+	results, _ = m.SearchWithGraphExpansion(ctx, "search team", 1, memory.WithExpansionHops(2), memory.WithExpansionDecay(1))
+	if len(results) != 3 || results[2].ID != "r3" || results[2].Hop != 2 || results[2].Entity.ID != berlin.ID {
+		t.Fatalf("unexpected two-hop results %+v", results)
+	}
+	if results[2].Score != results[0].Score*0.8 {
+		t.Errorf("expected relation strength applied, got %v", results[2].Score)
+	}
+
+	results, _ = m.SearchWithGraphExpansion(ctx, "search team", 1, memory.WithExpansionHops(2), memory.WithExpansionLimit(2))
+	if len(results) != 2 {
+		t.Errorf("expected limit 2, got %d", len(results))
+	}
+}
@@ -0,0 +1,100 @@
+package memory_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ahhsitt/helloagents-go/pkg/memory"
+	"github.com/ahhsitt/helloagents-go/pkg/memory/store"
+)
+
+func TestSemanticMemory_GraphStoreBacked(t *testing.T) {
+	ctx := context.Background()
+	graph := store.NewMemoryGraphStore()
+	m := memory.NewSemanticMemory(nil, memory.WithSemanticGraphStore(graph))
+
+	alice := memory.NewEntity("Alice", memory.EntityTypePerson)
+	acme := memory.NewEntity("Acme Corp", memory.EntityTypeOrganization)
+	berlin := memory.NewEntity("Berlin", memory.EntityTypeLocation)
+	for _, e := range []*memory.Entity{alice, acme, berlin} {
+		if err := m.AddEntity(ctx, e); err != nil {
+			t.Fatalf("AddEntity(%s) error = %v", e.Name, err)
+		}
+	}
+
+	// 同名实体只增加频率
+	if err := m.AddEntity(ctx, memory.NewEntity("alice", memory.EntityTypePerson)); err != nil {
+		t.Fatalf("AddEntity() error = %v", err)
+	}
+	got, err := m.GetEntityByName(ctx, "ALICE")
+	if err != nil {
+		t.Fatalf("GetEntityByName() error = %v", err)
+	}
+	if got.ID != alice.ID || got.Frequency != 2 {
+		t.Errorf("expected alice with frequency 2, got id=%s frequency=%d", got.ID, got.Frequency)
+	}
+
+	worksAt := memory.NewRelation(alice.ID, acme.ID, memory.RelationTypeWorksAt)
+	locatedIn := memory.NewRelation(acme.ID, berlin.ID, memory.RelationTypeLocatedIn)
+	for _, r := range []*memory.Relation{worksAt, locatedIn} {
+		if err := m.AddRelation(ctx, r); err != nil {
+			t.Fatalf("AddRelation() error = %v", err)
+		}
+	}
+	if err := m.AddRelation(ctx, memory.NewRelation(alice.ID, "missing", memory.RelationTypeKnows)); !errors.Is(err, memory.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for missing entity, got %v", err)
+	}
+
+	stats, err := graph.GetStats(ctx)
+	if err != nil {
+		t.Fatalf("GetStats() error = %v", err)
+	}
+	if stats.EntityCount != 3 || stats.RelationCount != 2 {
+		t.Fatalf("expected 3 entities and 2 relations in graph store, got %d/%d", stats.EntityCount, stats.RelationCount)
+	}
+	if m.EntityCount() != 3 || m.RelationCount() != 2 {
+		t.Errorf("expected counts from graph store, got %d/%d", m.EntityCount(), m.RelationCount())
+	}
+
+	related, err := m.GetRelatedEntities(ctx, alice.ID, 2)
+	if err != nil {
+		t.Fatalf("GetRelatedEntities() error = %v", err)
+	}
+	if len(related) != 2 {
+		t.Fatalf("expected 2 related entities, got %d", len(related))
+	}
+	if related[0].Entity.ID != acme.ID || related[1].Entity.ID != berlin.ID || related[1].Depth != 2 {
+		t.Errorf("unexpected traversal order: %s(%d), %s(%d)",
+			related[0].Entity.Name, related[0].Depth, related[1].Entity.Name, related[1].Depth)
+	}
+	if len(related[1].Path) != 2 || related[1].Path[1].RelationType != memory.RelationTypeLocatedIn {
+		t.Errorf("expected two-hop path ending in located_in, got %v", related[1].Path)
+	}
+
+	// 新实例共享同一个图数据库，图谱不随实例丢失
+	restored := memory.NewSemanticMemory(nil, memory.WithSemanticGraphStore(graph))
+	if _, err := restored.GetEntity(ctx, berlin.ID); err != nil {
+		t.Errorf("GetEntity() on restored store error = %v", err)
+	}
+	if results, _ := restored.SearchEntities(ctx, "acme", 0); len(results) != 1 {
+		t.Errorf("expected 1 entity matching acme, got %d", len(results))
+	}
+
+	if err := m.DeleteEntity(ctx, acme.ID); err != nil {
+		t.Fatalf("DeleteEntity() error = %v", err)
+	}
+	if _, err := m.GetRelation(ctx, worksAt.ID); !errors.Is(err, memory.ErrNotFound) {
+		t.Errorf("expected relation removed with entity, got %v", err)
+	}
+	if m.RelationCount() != 0 {
+		t.Errorf("expected no relations after delete, got %d", m.RelationCount())
+	}
+
+	if err := m.Clear(ctx); err != nil {
+		t.Fatalf("Clear() error = %v", err)
+	}
+	if m.EntityCount() != 0 {
+		t.Errorf("expected empty graph after clear, got %d", m.EntityCount())
+	}
+}
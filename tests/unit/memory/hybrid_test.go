@@ -0,0 +1,61 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ahhsitt/helloagents-go/pkg/memory"
+)
+
+func TestBM25Index_Search(t *testing.T) {
+	index := memory.NewBM25Index(-1, -1)
+	index.Fit([]string{
+		"the cat sat on the mat",
+		"dogs and cats are pets",
+		"cat cat cat",
+		"订单支付超时",
+	})
+
+	results := index.Search("cat", 0)
+	if len(results) != 2 {
+		t.Fatalf("expected 2 documents containing 'cat', got %+v", results)
+	}
+	if results[0].Index != 2 || results[1].Index != 0 {
+		t.Errorf("expected the denser, shorter document first, got %+v", results)
+	}
+
+	if results := index.Search("支付", 1); len(results) != 1 || results[0].Index != 3 {
+		t.Errorf("expected Chinese match on document 3, got %+v", results)
+	}
+	if results := index.Search("unrelated", 0); len(results) != 0 {
+		t.Errorf("expected no results without term overlap, got %+v", results)
+	}
+}
+
+func TestSemanticMemory_HybridFusion(t *testing.T) {
+	ctx := context.Background()
+
+	for name, opt := range map[string]memory.SemanticMemoryOption{
+		"weighted": memory.WithSemanticHybridSearch(0.5),
+		"rrf":      memory.WithSemanticHybridRRF(0),
+	} {
+		t.Run(name, func(t *testing.T) {
+			// 所有文本的向量相同，只有 BM25 能区分精确关键词命中
+			mem := memory.NewSemanticMemory(&constEmbedder{value: 1}, opt)
+			_ = mem.Store(ctx, "weather", "the weather is nice today", nil)
+			_ = mem.Store(ctx, "error", "billing failed with error code E1234", nil)
+			_ = mem.Store(ctx, "invoice", "billing invoices are sent monthly", nil)
+
+			results, err := mem.Search(ctx, "E1234", 2)
+			if err != nil {
+				t.Fatalf("Search() error = %v", err)
+			}
+			if len(results) != 2 || results[0].ID != "error" {
+				t.Fatalf("expected exact keyword hit first, got %+v", results)
+			}
+			if results[0].Score <= results[1].Score {
+				t.Errorf("expected keyword hit to outscore vector-only neighbor, got %+v", results)
+			}
+		})
+	}
+}
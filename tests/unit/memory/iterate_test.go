@@ -0,0 +1,84 @@
+package memory_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/ahhsitt/helloagents-go/pkg/memory"
+)
+
+func TestEpisodicMemory_IterateWhileRemoving(t *testing.T) {
+	ctx := context.Background()
+	mem := memory.NewEpisodicMemory()
+	// 超过一页，覆盖分页和游标重新定位
+	const total = memory.DefaultIteratePageSize*2 + 10
+	for i := 0; i < total; i++ {
+		_ = mem.AddEpisode(ctx, memory.Episode{ID: fmt.Sprintf("ep-%d", i), Content: "event", Importance: 0.5})
+	}
+
+	seen := make(map[string]bool)
+	err := mem.Iterate(ctx, memory.IterateFilter{}, func(item *memory.MemoryItem) error {
+		if seen[item.ID] {
+			t.Fatalf("item %s visited twice", item.ID)
+		}
+		seen[item.ID] = true
+		// 回调中写同一存储不会死锁
+		return mem.Remove(ctx, item.ID)
+	})
+	if err != nil {
+		t.Fatalf("Iterate() error = %v", err)
+	}
+	if len(seen) != total {
+		t.Errorf("expected %d items visited, got %d", total, len(seen))
+	}
+	if stats, _ := mem.GetStats(ctx); stats.Count != 0 {
+		t.Errorf("expected all episodes removed, got %d", stats.Count)
+	}
+}
+
+func TestMemoryManager_IterateAndStream(t *testing.T) {
+	ctx := context.Background()
+	episodic := memory.NewEpisodicMemory()
+	semantic := memory.NewSemanticMemory(newMockEmbedder())
+	manager := memory.NewMemoryManager(nil)
+	_ = manager.RegisterMemory(memory.MemoryTypeEpisodic, episodic)
+	_ = manager.RegisterMemory(memory.MemoryTypeSemantic, semantic)
+
+	_ = episodic.AddEpisode(ctx, memory.Episode{ID: "e1", UserID: "alice", Content: "deployed", Importance: 0.9})
+	_ = episodic.AddEpisode(ctx, memory.Episode{ID: "e2", UserID: "bob", Content: "deployed", Importance: 0.9})
+	_ = semantic.Store(ctx, "s1", "alice likes Go", map[string]interface{}{"user_id": "alice"})
+
+	var ids []string
+	err := manager.Iterate(ctx, memory.IterateFilter{UserID: "alice"}, func(item *memory.MemoryItem) error {
+		ids = append(ids, item.ID)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Iterate() error = %v", err)
+	}
+	if fmt.Sprint(ids) != "[e1 s1]" {
+		t.Errorf("expected alice's items in type order, got %v", ids)
+	}
+
+	count := 0
+	_ = manager.Iterate(ctx, memory.IterateFilter{}, func(*memory.MemoryItem) error {
+		count++
+		return memory.ErrStopIteration
+	})
+	if count != 1 {
+		t.Errorf("expected ErrStopIteration to stop after 1 item, got %d", count)
+	}
+
+	items, errs := manager.Stream(ctx, memory.IterateFilter{MemoryTypes: []memory.MemoryType{memory.MemoryTypeEpisodic}}, 0)
+	streamed := 0
+	for range items {
+		streamed++
+	}
+	if err := <-errs; err != nil {
+		t.Fatalf("Stream() error = %v", err)
+	}
+	if streamed != 2 {
+		t.Errorf("expected 2 episodic items streamed, got %d", streamed)
+	}
+}
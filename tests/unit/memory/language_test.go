@@ -0,0 +1,90 @@
+package memory_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/ahhsitt/helloagents-go/pkg/memory"
+)
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"用户更喜欢简洁的回答", memory.LanguageChinese},
+		{"The user prefers concise answers", memory.LanguageEnglish},
+		{"今日はいい天気ですね", memory.LanguageJapanese},
+		{"안녕하세요 반갑습니다", memory.LanguageKorean},
+		{"我们在 Kubernetes cluster 上部署 billing service", memory.LanguageMixed},
+		{"调用 API 超时", memory.LanguageChinese},
+		{"12345 !?", memory.LanguageUnknown},
+	}
+	for _, tt := range tests {
+		if got := memory.DetectLanguage(tt.text); got != tt.want {
+			t.Errorf("DetectLanguage(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+// constEmbedder returns the same vector for every text and records its calls
+type constEmbedder struct {
+	value float32
+	calls [][]string
+}
+
+func (e *constEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	e.calls = append(e.calls, texts)
+	vectors := make([][]float32, len(texts))
+	for i := range texts {
+		vectors[i] = []float32{e.value, 1}
+	}
+	return vectors, nil
+}
+
+func TestLanguageRoutingEmbedder_RoutesByLanguage(t *testing.T) {
+	zh := &constEmbedder{value: 1}
+	fallback := &constEmbedder{value: 2}
+	embedder := memory.NewLanguageRoutingEmbedder(fallback, memory.WithLanguageRoute(memory.LanguageChinese, zh))
+
+	vectors, err := embedder.Embed(context.Background(), []string{"你好世界", "hello world", "再见"})
+	if err != nil {
+		t.Fatalf("Embed() error = %v", err)
+	}
+	got := []float32{vectors[0][0], vectors[1][0], vectors[2][0]}
+	if !reflect.DeepEqual(got, []float32{1, 2, 1}) {
+		t.Errorf("routed vectors = %v, want [1 2 1]", got)
+	}
+	if len(zh.calls) != 1 || len(zh.calls[0]) != 2 || len(fallback.calls) != 1 {
+		t.Errorf("expected one batched call per embedder, got zh=%v fallback=%v", zh.calls, fallback.calls)
+	}
+
+	if _, err := memory.NewLanguageRoutingEmbedder(nil).Embed(context.Background(), []string{"hi"}); err == nil {
+		t.Error("expected error without a fallback embedder")
+	}
+}
+
+func TestSemanticMemory_LanguageDetection(t *testing.T) {
+	ctx := context.Background()
+	mem := memory.NewSemanticMemory(&constEmbedder{value: 1}, memory.WithSemanticLanguageDetection(0.5))
+
+	_ = mem.Store(ctx, "zh", "用户更喜欢简洁的回答", nil)
+	_ = mem.Store(ctx, "en", "The user prefers concise answers", map[string]interface{}{})
+
+	results, err := mem.Search(ctx, "简洁回答", 2)
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(results) != 2 || results[0].ID != "zh" {
+		t.Fatalf("Chinese query should prefer Chinese record, got %+v", results)
+	}
+	if results[0].Metadata["language"] != memory.LanguageChinese || results[1].Metadata["language"] != memory.LanguageEnglish {
+		t.Errorf("expected language tags, got %v and %v", results[0].Metadata, results[1].Metadata)
+	}
+
+	results, _ = mem.Search(ctx, "concise answers", 1)
+	if len(results) != 1 || results[0].ID != "en" {
+		t.Errorf("English query should prefer English record, got %+v", results)
+	}
+}
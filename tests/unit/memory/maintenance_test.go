@@ -0,0 +1,142 @@
+package memory_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ahhsitt/helloagents-go/pkg/memory"
+	"github.com/ahhsitt/helloagents-go/pkg/otel"
+)
+
+// nopWriteCloser 为 bytes.Buffer 添加空 Close
+type nopWriteCloser struct{ io.Writer }
+
+func (nopWriteCloser) Close() error { return nil }
+
+func TestParseSchedule(t *testing.T) {
+	base := time.Date(2024, 5, 1, 10, 30, 0, 0, time.Local)
+	cases := []struct {
+		spec string
+		want time.Time
+	}{
+		{"@every 15m", base.Add(15 * time.Minute)},
+		{"@hourly", base.Add(time.Hour)},
+		{"@daily", time.Date(2024, 5, 2, 0, 0, 0, 0, time.Local)},
+		{"03:15", time.Date(2024, 5, 2, 3, 15, 0, 0, time.Local)},
+		{"22:00", time.Date(2024, 5, 1, 22, 0, 0, 0, time.Local)},
+	}
+	for _, tc := range cases {
+		s, err := memory.ParseSchedule(tc.spec)
+		if err != nil {
+			t.Fatalf("ParseSchedule(%q) error = %v", tc.spec, err)
+		}
+		if got := s.Next(base); !got.Equal(tc.want) {
+			t.Errorf("ParseSchedule(%q).Next() = %v, want %v", tc.spec, got, tc.want)
+		}
+	}
+
+	for _, spec := range []string{"", "@every", "@every -1m", "25:00", "*/5 * * * *"} {
+		if _, err := memory.ParseSchedule(spec); !errors.Is(err, memory.ErrInvalidSchedule) {
+			t.Errorf("ParseSchedule(%q) error = %v, want ErrInvalidSchedule", spec, err)
+		}
+	}
+}
+
+func TestMaintenanceScheduler_BuiltinJobs(t *testing.T) {
+	ctx := context.Background()
+	working := memory.NewWorkingMemory()
+	episodic := memory.NewEpisodicMemory()
+	manager := memory.NewMemoryManager(nil)
+	_ = manager.RegisterMemory(memory.MemoryTypeWorking, working)
+	_ = manager.RegisterMemory(memory.MemoryTypeEpisodic, episodic)
+
+	for i, content := range []string{"deploy the api", "fix the login bug", "review the roadmap"} {
+		_, _ = manager.AddMemory(ctx, content, memory.WithAddMemoryType(memory.MemoryTypeEpisodic), memory.WithAddImportance(float32(i+1)/4))
+	}
+	_, _ = manager.AddMemory(ctx, "user prefers dark mode", memory.WithAddMemoryType(memory.MemoryTypeWorking), memory.WithAddImportance(0.9))
+
+	var snapshot bytes.Buffer
+	metrics := otel.NewInMemoryMetrics()
+	scheduler := memory.NewMaintenanceScheduler(manager, memory.WithSchedulerMetrics(metrics))
+	for _, job := range []memory.MaintenanceJob{
+		memory.ConsolidationJob(memory.Every(time.Hour)),
+		memory.ForgetByCapacityJob(memory.Every(time.Hour), 2),
+		memory.ReindexJob(memory.Every(time.Hour)),
+		memory.SnapshotJob(memory.Every(time.Hour), func(context.Context) (io.WriteCloser, error) {
+			return nopWriteCloser{&snapshot}, nil
+		}),
+	} {
+		if err := scheduler.AddJob(job); err != nil {
+			t.Fatalf("AddJob(%s) error = %v", job.Name, err)
+		}
+	}
+
+	if n, err := scheduler.RunNow(ctx, "consolidate"); err != nil || n != 1 {
+		t.Errorf("consolidate = %d, %v, want 1", n, err)
+	}
+	if n, err := scheduler.RunNow(ctx, "forget_by_capacity"); err != nil || n != 2 {
+		t.Errorf("forget_by_capacity = %d, %v, want 2", n, err)
+	}
+	if n, err := scheduler.RunNow(ctx, "reindex"); err != nil || n != 2 {
+		t.Errorf("reindex = %d, %v, want 2", n, err)
+	}
+	if _, err := scheduler.RunNow(ctx, "snapshot"); err != nil || !strings.Contains(snapshot.String(), "dark mode") {
+		t.Errorf("snapshot error = %v, output %q", err, snapshot.String())
+	}
+	if _, err := scheduler.RunNow(ctx, "missing"); !errors.Is(err, memory.ErrJobNotFound) {
+		t.Errorf("expected ErrJobNotFound, got %v", err)
+	}
+
+	stats := scheduler.Stats()
+	if len(stats) != 4 || stats[1].Name != "forget_by_capacity" || stats[1].Runs != 1 || stats[1].Affected != 2 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+	if got := metrics.GetCounterValue(otel.MetricMemoryMaintenanceRuns); got != 4 {
+		t.Errorf("maintenance runs = %d, want 4", got)
+	}
+	if got := metrics.GetCounterValue(otel.MetricMemoryMaintenanceAffected); got != 5 {
+		t.Errorf("maintenance affected = %d, want 5", got)
+	}
+}
+
+func TestMaintenanceScheduler_RunUntilCancelled(t *testing.T) {
+	var runs atomic.Int32
+	scheduler := memory.NewMaintenanceScheduler(memory.NewMemoryManager(nil))
+	err := scheduler.AddJob(memory.MaintenanceJob{
+		Name:     "flaky",
+		Schedule: memory.Every(5 * time.Millisecond),
+		Jitter:   time.Millisecond,
+		Run: func(context.Context, *memory.MemoryManager) (int, error) {
+			if runs.Add(1)%2 == 0 {
+				return 0, errors.New("boom")
+			}
+			return 1, nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("AddJob() error = %v", err)
+	}
+	if err := scheduler.AddJob(memory.MaintenanceJob{Name: "flaky", Schedule: memory.Every(time.Second), Run: func(context.Context, *memory.MemoryManager) (int, error) { return 0, nil }}); !errors.Is(err, memory.ErrJobExists) {
+		t.Errorf("expected ErrJobExists, got %v", err)
+	}
+	if err := scheduler.AddJob(memory.MaintenanceJob{Name: "busy", Schedule: memory.Every(0), Run: func(context.Context, *memory.MemoryManager) (int, error) { return 0, nil }}); !errors.Is(err, memory.ErrInvalidSchedule) {
+		t.Errorf("expected ErrInvalidSchedule, got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Millisecond)
+	defer cancel()
+	if err := scheduler.Run(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Run() error = %v, want deadline exceeded", err)
+	}
+
+	stats := scheduler.Stats()[0]
+	if stats.Runs < 2 || stats.Failures == 0 || stats.Runs != int(runs.Load()) || !stats.NextRun.IsZero() {
+		t.Errorf("unexpected stats after cancellation: %+v", stats)
+	}
+}
@@ -0,0 +1,47 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ahhsitt/helloagents-go/pkg/memory"
+)
+
+func TestQueryNormalizer_Normalize(t *testing.T) {
+	n := memory.NewQueryNormalizer()
+	n.Fit([]string{"billing failed with error code", "the billing service", "gpt-4o pricing"})
+
+	got := n.Normalize("  Ｂｉｌｌｉｎｇ  servce?? faild!! gpt-4o ")
+	if got.Original != "  Ｂｉｌｌｉｎｇ  servce?? faild!! gpt-4o " {
+		t.Errorf("expected original query preserved, got %q", got.Original)
+	}
+	if got.Text != "billing service failed gpt-4o" {
+		t.Errorf("Normalize() = %q, want %q", got.Text, "billing service failed gpt-4o")
+	}
+	if got.Corrections["servce"] != "service" || got.Corrections["faild"] != "failed" {
+		t.Errorf("unexpected corrections %v", got.Corrections)
+	}
+
+	// 短词和词表外的远距离词不纠正
+	if got := n.NormalizeText("teh zzzzzz"); got != "teh zzzzzz" {
+		t.Errorf("expected no correction, got %q", got)
+	}
+}
+
+func TestSemanticMemory_QueryNormalizer(t *testing.T) {
+	ctx := context.Background()
+	mem := memory.NewSemanticMemory(&constEmbedder{value: 1},
+		memory.WithSemanticHybridSearch(0.5),
+		memory.WithSemanticQueryNormalizer(memory.NewQueryNormalizer()),
+	)
+	_ = mem.Store(ctx, "weather", "the weather is nice today", nil)
+	_ = mem.Store(ctx, "billing", "billing failed for the customer", nil)
+
+	results, err := mem.Search(ctx, "Biling FAILD!!", 2)
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(results) == 0 || results[0].ID != "billing" {
+		t.Fatalf("expected typo query to match the billing record first, got %+v", results)
+	}
+}
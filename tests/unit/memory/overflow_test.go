@@ -0,0 +1,65 @@
+package memory_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ahhsitt/helloagents-go/pkg/core/message"
+	"github.com/ahhsitt/helloagents-go/pkg/memory"
+)
+
+func TestWorkingMemory_OverflowSummarizer(t *testing.T) {
+	ctx := context.Background()
+	fail := true
+	provider := &summaryProvider{generateFn: func(prompt string) (string, error) {
+		if fail {
+			return "", errors.New("llm unavailable")
+		}
+		return "user is Alice and prefers Go", nil
+	}}
+	mem := memory.NewWorkingMemory(memory.WithMaxSize(2), memory.WithOverflowSummarizer(provider))
+
+	_ = mem.AddMessage(ctx, message.NewUserMessage("my name is Alice"))
+	_ = mem.AddMessage(ctx, message.NewAssistantMessage("hi Alice"))
+	// 摘要失败：淘汰的消息暂存，写入不受影响
+	if err := mem.AddMessage(ctx, message.NewUserMessage("I prefer Go")); err != nil {
+		t.Fatalf("AddMessage() error = %v", err)
+	}
+	if summary, _ := mem.GetOverflowSummary(ctx); summary != "" {
+		t.Fatalf("expected no summary after a failed call, got %q", summary)
+	}
+
+	fail = false
+	_ = mem.AddMessage(ctx, message.NewAssistantMessage("noted"))
+	last := provider.prompts[len(provider.prompts)-1]
+	if !strings.Contains(last, "my name is Alice") || !strings.Contains(last, "hi Alice") {
+		t.Errorf("expected the retry to include earlier evicted turns, got %q", last)
+	}
+	if summary, _ := mem.GetOverflowSummary(ctx); summary != "user is Alice and prefers Go" {
+		t.Errorf("GetOverflowSummary() = %q", summary)
+	}
+	if mem.Size() != 2 {
+		t.Errorf("summary should not take a maxSize slot, size %d", mem.Size())
+	}
+
+	msgs, err := mem.GetMessagesWithinTokenLimit(ctx)
+	if err != nil {
+		t.Fatalf("GetMessagesWithinTokenLimit() error = %v", err)
+	}
+	if len(msgs) != 3 || msgs[0].Role != message.RoleSystem || !strings.Contains(msgs[0].Content, "prefers Go") {
+		t.Errorf("expected summary followed by 2 messages, got %+v", msgs)
+	}
+
+	// 再次溢出时在已有摘要的基础上合并
+	_ = mem.AddMessage(ctx, message.NewUserMessage("what's my name?"))
+	if last := provider.prompts[len(provider.prompts)-1]; !strings.Contains(last, "user is Alice and prefers Go") {
+		t.Errorf("expected rolling summary in prompt, got %q", last)
+	}
+
+	history, _ := mem.GetHistory(ctx, 0)
+	if len(history) != 3 || history[0].Role != message.RoleSystem {
+		t.Errorf("expected full history to start with the summary, got %+v", history)
+	}
+}
@@ -0,0 +1,171 @@
+package memory_test
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ahhsitt/helloagents-go/pkg/core/message"
+	"github.com/ahhsitt/helloagents-go/pkg/memory"
+	"github.com/ahhsitt/helloagents-go/pkg/memory/store"
+)
+
+func newSQLiteStore(t *testing.T) store.DocumentStore {
+	t.Helper()
+	s, err := store.NewSQLiteDocumentStore(filepath.Join(t.TempDir(), "memory.db"))
+	if err != nil {
+		t.Fatalf("failed to open sqlite store: %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	return s
+}
+
+func TestWorkingMemory_Persistence(t *testing.T) {
+	ctx := context.Background()
+	docStore := newSQLiteStore(t)
+
+	mem := memory.NewWorkingMemory(memory.WithPersistence(docStore, ""))
+	if err := mem.AddMessageWithImportance(ctx, message.NewUserMessage("My name is Alice"), 0.9); err != nil {
+		t.Fatalf("AddMessageWithImportance() error = %v", err)
+	}
+	reply := message.NewAssistantMessage("Nice to meet you")
+	reply.Attachments = []message.Attachment{{ID: "artifact-1", MimeType: "image/png", Caption: "a wave"}}
+	if err := mem.AddMessage(ctx, reply); err != nil {
+		t.Fatalf("AddMessage() error = %v", err)
+	}
+	id, err := mem.Add(ctx, memory.NewMemoryItem("temporary note", memory.MemoryTypeWorking))
+	if err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if err := mem.Remove(ctx, id); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+
+	// 模拟重启：新实例从同一存储懒加载
+	restarted := memory.NewWorkingMemory(memory.WithPersistence(docStore, ""))
+	history, err := restarted.GetHistory(ctx, 0)
+	if err != nil {
+		t.Fatalf("GetHistory() error = %v", err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("expected 2 persisted messages, got %d", len(history))
+	}
+	if history[0].Content != "My name is Alice" || history[1].Role != message.RoleAssistant {
+		t.Errorf("unexpected history: %+v", history)
+	}
+	if len(history[1].Attachments) != 1 || history[1].Attachments[0].Caption != "a wave" {
+		t.Errorf("attachments should be persisted, got %+v", history[1].Attachments)
+	}
+
+	important, _ := restarted.GetImportant(ctx, 1)
+	if len(important) != 1 || important[0].Importance < 0.89 {
+		t.Errorf("importance should be persisted, got %+v", important)
+	}
+
+	if err := restarted.Clear(ctx); err != nil {
+		t.Fatalf("Clear() error = %v", err)
+	}
+	if n := memory.NewWorkingMemory(memory.WithPersistence(docStore, "")).Size(); n != 0 {
+		t.Errorf("expected empty store after Clear, got %d", n)
+	}
+}
+
+func TestWorkingMemory_ReloadRespectsMaxSizeAndTTL(t *testing.T) {
+	ctx := context.Background()
+	docStore := store.NewMemoryDocumentStore()
+
+	replica := memory.NewWorkingMemory(
+		memory.WithPersistence(docStore, ""),
+		memory.WithPersistenceReload(time.Nanosecond),
+		memory.WithMaxSize(3),
+		memory.WithTTL(time.Hour),
+	)
+	if n := replica.Size(); n != 0 {
+		t.Fatalf("Size() = %d before other replicas write, want 0", n)
+	}
+
+	// 另一个副本写入超过 maxSize 的消息，其中一条已超过 TTL
+	writer := memory.NewWorkingMemory(memory.WithPersistence(docStore, ""), memory.WithMaxSize(100))
+	stale := message.NewUserMessage("stale")
+	stale.Timestamp = time.Now().Add(-2 * time.Hour)
+	_ = writer.AddMessage(ctx, stale)
+	for i := 0; i < 5; i++ {
+		msg := message.NewUserMessage(fmt.Sprintf("message %d", i))
+		msg.Timestamp = time.Now().Add(time.Duration(i) * time.Millisecond)
+		_ = writer.AddMessage(ctx, msg)
+	}
+
+	if n := replica.Size(); n != 3 {
+		t.Fatalf("Size() after reload = %d, want maxSize 3", n)
+	}
+	history, _ := replica.GetHistory(ctx, 0)
+	if len(history) != 3 || history[0].Content != "message 2" || history[2].Content != "message 4" {
+		t.Errorf("history = %+v, want the 3 newest messages", history)
+	}
+
+	// 重启时的首次加载同样裁剪
+	restarted := memory.NewWorkingMemory(memory.WithPersistence(docStore, ""), memory.WithMaxSize(2))
+	if n := restarted.Size(); n != 2 {
+		t.Errorf("Size() after restart = %d, want maxSize 2", n)
+	}
+	withTTL := memory.NewWorkingMemory(memory.WithPersistence(docStore, ""), memory.WithTTL(time.Hour))
+	if n := withTTL.Size(); n != 5 {
+		t.Errorf("Size() after restart with TTL = %d, want 5 unexpired messages", n)
+	}
+}
+
+func TestEpisodicMemory_Persistence(t *testing.T) {
+	ctx := context.Background()
+	docStore := newSQLiteStore(t)
+
+	mem := memory.NewEpisodicMemory(memory.WithEpisodicPersistence(docStore, "episodes"))
+	if err := mem.AddEpisode(ctx, memory.Episode{
+		ID:         "ep1",
+		Type:       "deploy",
+		Content:    "Deployed version 1.2 to production",
+		Importance: 0.8,
+		SessionID:  "s1",
+		Outcome:    "success",
+	}); err != nil {
+		t.Fatalf("AddEpisode() error = %v", err)
+	}
+	if err := mem.AddEpisode(ctx, memory.Episode{ID: "ep2", Content: "Rolled back version 1.2", SessionID: "s1"}); err != nil {
+		t.Fatalf("AddEpisode() error = %v", err)
+	}
+	if err := mem.Update(ctx, "ep2", memory.WithImportanceUpdate(0.6)); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	restarted := memory.NewEpisodicMemory(memory.WithEpisodicPersistence(docStore, "episodes"))
+	episodes, err := restarted.GetSessionEpisodes(ctx, "s1")
+	if err != nil {
+		t.Fatalf("GetSessionEpisodes() error = %v", err)
+	}
+	if len(episodes) != 2 {
+		t.Fatalf("expected 2 persisted episodes, got %d", len(episodes))
+	}
+
+	byID := make(map[string]memory.Episode)
+	for _, ep := range episodes {
+		byID[ep.ID] = ep
+	}
+	if ep := byID["ep1"]; ep.Type != "deploy" || ep.Outcome != "success" {
+		t.Errorf("unexpected episode: %+v", ep)
+	}
+	if ep := byID["ep2"]; ep.Importance < 0.59 || ep.Importance > 0.61 {
+		t.Errorf("updated importance should be persisted, got %f", ep.Importance)
+	}
+
+	results, err := restarted.Retrieve(ctx, "deployed production")
+	if err != nil || len(results) == 0 {
+		t.Errorf("Retrieve() should find persisted episodes, got %v (err=%v)", results, err)
+	}
+
+	// 不同集合互不影响
+	other := memory.NewEpisodicMemory(memory.WithEpisodicPersistence(docStore, ""))
+	if other.Size() != 0 {
+		t.Errorf("default collection should be empty, got %d", other.Size())
+	}
+}
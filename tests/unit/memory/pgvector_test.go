@@ -0,0 +1,179 @@
+package memory_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ahhsitt/helloagents-go/pkg/memory/store"
+)
+
+// fakePg 记录执行的 SQL，查询返回预设结果的 database/sql 驱动
+type fakePg struct {
+	mu      sync.Mutex
+	stmts   []string
+	args    [][]driver.Value
+	results map[string][][]driver.Value // SQL 片段 -> 返回的行
+}
+
+func (d *fakePg) Open(string) (driver.Conn, error) { return &fakePgConn{d: d}, nil }
+
+func (d *fakePg) record(query string, args []driver.Value) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.stmts = append(d.stmts, query)
+	d.args = append(d.args, args)
+}
+
+func (d *fakePg) find(fragment string) (string, []driver.Value) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for i, s := range d.stmts {
+		if strings.Contains(s, fragment) {
+			return s, d.args[i]
+		}
+	}
+	return "", nil
+}
+
+type fakePgConn struct{ d *fakePg }
+
+func (c *fakePgConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakePgStmt{d: c.d, query: query}, nil
+}
+func (c *fakePgConn) Close() error              { return nil }
+func (c *fakePgConn) Begin() (driver.Tx, error) { return fakePgTx{}, nil }
+
+type fakePgTx struct{}
+
+func (fakePgTx) Commit() error   { return nil }
+func (fakePgTx) Rollback() error { return nil }
+
+type fakePgStmt struct {
+	d     *fakePg
+	query string
+}
+
+func (s *fakePgStmt) Close() error  { return nil }
+func (s *fakePgStmt) NumInput() int { return -1 }
+
+func (s *fakePgStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.d.record(s.query, args)
+	return driver.RowsAffected(1), nil
+}
+
+func (s *fakePgStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.d.record(s.query, args)
+	for fragment, rows := range s.d.results {
+		if strings.Contains(s.query, fragment) {
+			return &fakePgRows{rows: rows}, nil
+		}
+	}
+	return &fakePgRows{rows: [][]driver.Value{{int64(0)}}}, nil
+}
+
+type fakePgRows struct {
+	rows [][]driver.Value
+	pos  int
+}
+
+func (r *fakePgRows) Columns() []string {
+	if len(r.rows) == 0 {
+		return nil
+	}
+	return make([]string, len(r.rows[0]))
+}
+func (r *fakePgRows) Close() error { return nil }
+func (r *fakePgRows) Next(dest []driver.Value) error {
+	if r.pos >= len(r.rows) {
+		return io.EOF
+	}
+	copy(dest, r.rows[r.pos])
+	r.pos++
+	return nil
+}
+
+var fakePgSeq int
+
+// newFakePg 注册一个新的假驱动，返回驱动名
+func newFakePg(results map[string][][]driver.Value) (*fakePg, string) {
+	fakePgSeq++
+	name := fmt.Sprintf("fakepg%d", fakePgSeq)
+	d := &fakePg{results: results}
+	sql.Register(name, d)
+	return d, name
+}
+
+func TestPgVectorStore_MigrateAndSearch(t *testing.T) {
+	ctx := context.Background()
+	d, name := newFakePg(map[string][][]driver.Value{
+		"ORDER BY embedding":                         {{"v1", "m1", []byte(`{"memory_id":"m1","user_id":"alice"}`), 0.92}},
+		"SELECT count(*) FROM vecs WHERE collection": {{int64(7)}},
+	})
+
+	s, err := store.NewPgVectorStore(store.PgVectorConfig{
+		DriverName: name,
+		Table:      "vecs",
+		Dimensions: 3,
+		Index:      store.PgVectorIndexIVFFlat,
+		IVFLists:   50,
+	})
+	if err != nil {
+		t.Fatalf("NewPgVectorStore() error = %v", err)
+	}
+	defer s.Close()
+
+	for _, fragment := range []string{"CREATE EXTENSION IF NOT EXISTS vector", "embedding vector(3)", "USING ivfflat (embedding vector_cosine_ops) WITH (lists = 50)"} {
+		if q, _ := d.find(fragment); q == "" {
+			t.Errorf("expected migration containing %q", fragment)
+		}
+	}
+
+	if err := s.AddVectors(ctx, "c", []store.VectorRecord{{ID: "v1", Vector: []float32{1, 0, 0.5}, MemoryID: "m1"}}); err != nil {
+		t.Fatalf("AddVectors() error = %v", err)
+	}
+	if _, args := d.find("ON CONFLICT (collection, id)"); len(args) < 4 || args[3] != "[1,0,0.5]" {
+		t.Errorf("unexpected upsert args %v", args)
+	}
+
+	results, err := s.SearchSimilar(ctx, "c", []float32{1, 0, 0}, 5, &store.VectorFilter{UserID: "alice"})
+	if err != nil {
+		t.Fatalf("SearchSimilar() error = %v", err)
+	}
+	if len(results) != 1 || results[0].ID != "v1" || results[0].MemoryID != "m1" || results[0].Payload["user_id"] != "alice" {
+		t.Errorf("unexpected results %+v", results)
+	}
+	query, args := d.find("ORDER BY embedding")
+	if !strings.Contains(query, "payload @> $3::jsonb") || len(args) != 4 || args[2] != `{"user_id":"alice"}` {
+		t.Errorf("unexpected search query %q with args %v", query, args)
+	}
+
+	stats, err := s.GetStats(ctx, "c")
+	if err != nil || stats.VectorCount != 7 || stats.Dimensions != 3 {
+		t.Errorf("GetStats() = %+v, %v", stats, err)
+	}
+}
+
+func TestPgVectorStore_InvalidInput(t *testing.T) {
+	_, name := newFakePg(nil)
+
+	if _, err := store.NewPgVectorStore(store.PgVectorConfig{DriverName: name, Table: "bad;name", Dimensions: 3}); !errors.Is(err, store.ErrInvalidInput) {
+		t.Errorf("expected ErrInvalidInput for table name, got %v", err)
+	}
+
+	s, err := store.NewPgVectorStore(store.PgVectorConfig{DriverName: name, Dimensions: 3, Index: store.PgVectorIndexNone})
+	if err != nil {
+		t.Fatalf("NewPgVectorStore() error = %v", err)
+	}
+	defer s.Close()
+	err = s.AddVectors(context.Background(), "c", []store.VectorRecord{{ID: "v", Vector: []float32{1, 2}}})
+	if !errors.Is(err, store.ErrInvalidInput) {
+		t.Errorf("expected ErrInvalidInput for dimension mismatch, got %v", err)
+	}
+}
@@ -0,0 +1,121 @@
+package memory_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ahhsitt/helloagents-go/pkg/memory"
+)
+
+func TestProceduralMemory_FindProcedures(t *testing.T) {
+	ctx := context.Background()
+	m := memory.NewProceduralMemory()
+
+	deployID, err := m.AddProcedure(ctx, memory.Procedure{
+		Name:     "Deploy service to kubernetes",
+		Triggers: []string{"deploy a service", "roll out a new release"},
+		Steps: []memory.ProcedureStep{
+			{Description: "build the image", Tool: "docker_build"},
+			{Description: "apply the manifests", Tool: "kubectl_apply"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("AddProcedure() error = %v", err)
+	}
+	weatherID, _ := m.AddProcedure(ctx, memory.Procedure{
+		Name:  "Check weather forecast",
+		Steps: []memory.ProcedureStep{{Tool: "weather", Arguments: map[string]interface{}{"city": "Paris"}}},
+	})
+
+	if _, err := m.AddProcedure(ctx, memory.Procedure{Name: "empty"}); !errors.Is(err, memory.ErrInvalidInput) {
+		t.Errorf("expected ErrInvalidInput for procedure without steps, got %v", err)
+	}
+
+	matches, err := m.FindProcedures(ctx, "deploy the billing service", memory.WithLimit(1))
+	if err != nil {
+		t.Fatalf("FindProcedures() error = %v", err)
+	}
+	if len(matches) != 1 || matches[0].Procedure.ID != deployID {
+		t.Fatalf("expected deploy procedure, got %+v", matches)
+	}
+
+	formatted := matches[0].Procedure.Format()
+	if !strings.Contains(formatted, "1. build the image (docker_build)") || !strings.Contains(formatted, "2. apply the manifests (kubectl_apply)") {
+		t.Errorf("unexpected formatted procedure:\n%s", formatted)
+	}
+
+	// 成功率影响排名：同等相似度下，失败多的程序得分更低
+	before := matches[0].Score
+	_ = m.RecordOutcome(ctx, deployID, false)
+	_ = m.RecordOutcome(ctx, deployID, false)
+	matches, _ = m.FindProcedures(ctx, "deploy the billing service", memory.WithLimit(1))
+	if matches[0].Score >= before {
+		t.Errorf("expected failures to lower the score, got %v >= %v", matches[0].Score, before)
+	}
+	if matches[0].Procedure.FailureCount != 2 {
+		t.Errorf("expected 2 failures, got %d", matches[0].Procedure.FailureCount)
+	}
+
+	if err := m.RecordOutcome(ctx, "missing", true); !errors.Is(err, memory.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	items, err := m.Retrieve(ctx, "weather forecast in paris")
+	if err != nil {
+		t.Fatalf("Retrieve() error = %v", err)
+	}
+	if len(items) == 0 || items[0].ID != weatherID || items[0].MemoryType != memory.MemoryTypeProcedural {
+		t.Fatalf("expected weather procedure item, got %v", items)
+	}
+}
+
+func TestProceduralMemory_AddFromItem(t *testing.T) {
+	ctx := context.Background()
+	m := memory.NewProceduralMemory()
+	manager := memory.NewMemoryManager(nil, memory.WithManagerUserID("alice"))
+	_ = manager.RegisterMemory(memory.MemoryTypeProcedural, m)
+
+	id, err := manager.AddMemory(ctx, "Reset a user password",
+		memory.WithAddMemoryType(memory.MemoryTypeProcedural),
+		memory.WithAddMetadata(map[string]interface{}{
+			"steps": []string{"look up the account", "send the reset link"},
+		}),
+	)
+	if err != nil {
+		t.Fatalf("AddMemory() error = %v", err)
+	}
+
+	p, err := m.GetProcedure(ctx, id)
+	if err != nil {
+		t.Fatalf("GetProcedure() error = %v", err)
+	}
+	if p.Name != "Reset a user password" || len(p.Steps) != 2 || p.UserID != "alice" {
+		t.Errorf("unexpected procedure %+v", p)
+	}
+
+	matches, _ := m.FindProcedures(ctx, "reset password", memory.WithUserIDFilter("bob"))
+	if len(matches) != 0 {
+		t.Errorf("expected no procedures for bob, got %d", len(matches))
+	}
+	matches, _ = m.FindProcedures(ctx, "reset password", memory.WithUserIDFilter("alice"))
+	if len(matches) != 1 {
+		t.Errorf("expected alice's procedure, got %d", len(matches))
+	}
+
+	var buf bytes.Buffer
+	if err := manager.Export(ctx, &buf); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	restored := memory.NewProceduralMemory()
+	target := memory.NewMemoryManager(nil)
+	_ = target.RegisterMemory(memory.MemoryTypeProcedural, restored)
+	if err := target.Import(ctx, &buf); err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if !restored.Has(ctx, id) {
+		t.Error("expected procedure restored from snapshot")
+	}
+}
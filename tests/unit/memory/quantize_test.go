@@ -0,0 +1,135 @@
+package memory_test
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/ahhsitt/helloagents-go/pkg/memory"
+)
+
+// newRandomEmbedder 按文本哈希生成确定的 dim 维随机向量
+func newRandomEmbedder(dim int) *mockEmbedder {
+	return &mockEmbedder{embedFn: func(_ context.Context, texts []string) ([][]float32, error) {
+		vectors := make([][]float32, len(texts))
+		for i, text := range texts {
+			h := fnv.New64a()
+			_, _ = h.Write([]byte(text))
+			rng := rand.New(rand.NewSource(int64(h.Sum64())))
+			vec := make([]float32, dim)
+			for j := range vec {
+				vec[j] = float32(rng.NormFloat64())
+			}
+			vectors[i] = vec
+		}
+		return vectors, nil
+	}}
+}
+
+// quantizedStore 创建写入 n 条记录的语义存储
+func quantizedStore(t *testing.T, q memory.VectorQuantization, n int) *memory.SemanticMemoryStore {
+	t.Helper()
+	mem := memory.NewSemanticMemory(newRandomEmbedder(256), memory.WithSemanticVectorQuantization(q))
+	items := make([]*memory.MemoryItem, n)
+	for i := range items {
+		items[i] = memory.NewMemoryItem(fmt.Sprintf("record %d", i), memory.MemoryTypeSemantic)
+	}
+	if _, err := mem.AddBatch(context.Background(), items); err != nil {
+		t.Fatalf("AddBatch() error = %v", err)
+	}
+	return mem
+}
+
+// 量化的精度与内存取舍：float16 内存减半且排序不变，int8 内存约为四分之一且前几名基本不变
+func TestSemanticMemory_VectorQuantizationTradeoff(t *testing.T) {
+	ctx := context.Background()
+	const n, topK = 300, 10
+
+	baseline := quantizedStore(t, memory.QuantizationNone, n)
+	baseStats, _ := baseline.GetStats(ctx)
+	vectorBytes := int64(n * 256 * 4)
+	otherBytes := baseStats.StorageBytes - vectorBytes
+
+	tests := []struct {
+		name       memory.VectorQuantization
+		maxRatio   float64 // 向量存储相对 float32 的最大占比
+		minOverlap int     // 前 topK 名与 float32 相同的最少数量
+		maxScore   float64 // 分数最大误差
+	}{
+		{memory.QuantizationFloat16, 0.55, topK, 1e-3},
+		{memory.QuantizationInt8, 0.30, topK - 2, 1e-2},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.name), func(t *testing.T) {
+			mem := quantizedStore(t, tt.name, n)
+			stats, _ := mem.GetStats(ctx)
+			ratio := float64(stats.StorageBytes-otherBytes) / float64(vectorBytes)
+			if ratio > tt.maxRatio {
+				t.Errorf("vector storage ratio = %.2f, want <= %.2f", ratio, tt.maxRatio)
+			}
+
+			for _, query := range []string{"query a", "query b", "query c"} {
+				want, _ := baseline.Search(ctx, query, topK)
+				got, _ := mem.Search(ctx, query, topK)
+				if len(got) != topK || got[0].Content != want[0].Content {
+					t.Fatalf("%s: top result = %v, want %s", query, got, want[0].Content)
+				}
+
+				scores := make(map[string]float32, len(want))
+				for _, r := range want {
+					scores[r.Content] = r.Score
+				}
+				overlap := 0
+				for _, r := range got {
+					if s, ok := scores[r.Content]; ok {
+						overlap++
+						if d := math.Abs(float64(s - r.Score)); d > tt.maxScore {
+							t.Errorf("%s: score of %s differs by %g", query, r.Content, d)
+						}
+					}
+				}
+				if overlap < tt.minOverlap {
+					t.Errorf("%s: top-%d overlap = %d, want >= %d", query, topK, overlap, tt.minOverlap)
+				}
+			}
+		})
+	}
+}
+
+func TestSemanticMemory_QuantizedSnapshotRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	mem := quantizedStore(t, memory.QuantizationInt8, 5)
+
+	records, err := mem.ExportSnapshot(ctx)
+	if err != nil {
+		t.Fatalf("ExportSnapshot() error = %v", err)
+	}
+	want, _ := newRandomEmbedder(256).Embed(ctx, []string{"record 0"})
+	for _, rec := range records {
+		if rec.Semantic == nil || rec.Semantic.Content != "record 0" {
+			continue
+		}
+		got := rec.Semantic.Vector
+		if len(got) != len(want[0]) {
+			t.Fatalf("expected a dequantized %d-dim vector, got %d", len(want[0]), len(got))
+		}
+		for i := range got {
+			if d := math.Abs(float64(got[i] - want[0][i])); d > 0.05 {
+				t.Fatalf("dimension %d differs by %g", i, d)
+			}
+		}
+	}
+
+	restored := memory.NewSemanticMemory(newRandomEmbedder(256), memory.WithSemanticVectorQuantization(memory.QuantizationFloat16))
+	if err := restored.ImportSnapshot(ctx, records); err != nil {
+		t.Fatalf("ImportSnapshot() error = %v", err)
+	}
+	results, _ := restored.Search(ctx, "record 3", 1)
+	if len(results) != 1 || results[0].Content != "record 3" {
+		t.Errorf("expected the restored record to be found, got %+v", results)
+	}
+}
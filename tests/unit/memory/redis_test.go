@@ -0,0 +1,285 @@
+package memory_test
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ahhsitt/helloagents-go/pkg/core/message"
+	"github.com/ahhsitt/helloagents-go/pkg/memory"
+	"github.com/ahhsitt/helloagents-go/pkg/memory/store"
+)
+
+// fakeRedis 实现测试所需命令子集的内存 RESP 服务器
+type fakeRedis struct {
+	mu      sync.Mutex
+	strings map[string]string
+	expires map[string]time.Time
+	sets    map[string]map[string]bool
+}
+
+func newFakeRedis(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+
+	srv := &fakeRedis{
+		strings: make(map[string]string),
+		expires: make(map[string]time.Time),
+		sets:    make(map[string]map[string]bool),
+	}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go srv.serve(conn)
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func (s *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+		if _, err := io.WriteString(conn, s.exec(args)); err != nil {
+			return
+		}
+	}
+}
+
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(line)[1:])
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, n)
+	for i := range args {
+		if _, err := r.ReadString('\n'); err != nil {
+			return nil, err
+		}
+		arg, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		args[i] = strings.TrimSuffix(arg, "\r\n")
+	}
+	return args, nil
+}
+
+func bulk(v string) string { return fmt.Sprintf("$%d\r\n%s\r\n", len(v), v) }
+
+func (s *fakeRedis) get(key string) (string, bool) {
+	if exp, ok := s.expires[key]; ok && time.Now().After(exp) {
+		delete(s.strings, key)
+		delete(s.expires, key)
+	}
+	v, ok := s.strings[key]
+	return v, ok
+}
+
+func (s *fakeRedis) exec(args []string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch strings.ToUpper(args[0]) {
+	case "PING":
+		return "+PONG\r\n"
+	case "SET":
+		s.strings[args[1]] = args[2]
+		delete(s.expires, args[1])
+		if len(args) == 5 && args[3] == "PX" {
+			ms, _ := strconv.Atoi(args[4])
+			s.expires[args[1]] = time.Now().Add(time.Duration(ms) * time.Millisecond)
+		}
+		return "+OK\r\n"
+	case "GET":
+		if v, ok := s.get(args[1]); ok {
+			return bulk(v)
+		}
+		return "$-1\r\n"
+	case "MGET":
+		out := fmt.Sprintf("*%d\r\n", len(args)-1)
+		for _, key := range args[1:] {
+			if v, ok := s.get(key); ok {
+				out += bulk(v)
+			} else {
+				out += "$-1\r\n"
+			}
+		}
+		return out
+	case "DEL":
+		n := 0
+		for _, key := range args[1:] {
+			if _, ok := s.get(key); ok {
+				n++
+			} else if _, ok := s.sets[key]; ok {
+				n++
+			}
+			delete(s.strings, key)
+			delete(s.sets, key)
+		}
+		return fmt.Sprintf(":%d\r\n", n)
+	case "SADD":
+		if s.sets[args[1]] == nil {
+			s.sets[args[1]] = make(map[string]bool)
+		}
+		for _, m := range args[2:] {
+			s.sets[args[1]][m] = true
+		}
+		return ":1\r\n"
+	case "SREM":
+		for _, m := range args[2:] {
+			delete(s.sets[args[1]], m)
+		}
+		return ":1\r\n"
+	case "SMEMBERS":
+		set := s.sets[args[1]]
+		out := fmt.Sprintf("*%d\r\n", len(set))
+		for m := range set {
+			out += bulk(m)
+		}
+		return out
+	default:
+		return "-ERR unknown command\r\n"
+	}
+}
+
+func newRedisStore(t *testing.T, addr string) *store.RedisDocumentStore {
+	t.Helper()
+	s, err := store.NewRedisDocumentStore(store.RedisConfig{Addr: addr})
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	return s
+}
+
+func TestRedisDocumentStore_CRUD(t *testing.T) {
+	ctx := context.Background()
+	s := newRedisStore(t, newFakeRedis(t))
+
+	for i := 0; i < 3; i++ {
+		doc := store.Document{
+			Content:  fmt.Sprintf("doc %d", i),
+			Metadata: map[string]interface{}{"rank": i},
+		}
+		if err := s.Put(ctx, "c", fmt.Sprintf("d%d", i), doc); err != nil {
+			t.Fatalf("Put: %v", err)
+		}
+	}
+
+	doc, err := s.Get(ctx, "c", "d1")
+	if err != nil || doc.Content != "doc 1" {
+		t.Fatalf("Get = %v, %v", doc, err)
+	}
+
+	results, err := s.Query(ctx, "c",
+		store.Filter{Field: "rank", Op: "gte", Value: 1.0}, // JSON 数字还原为 float64
+		store.WithQueryOrderBy("rank", true),
+	)
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if len(results) != 2 || results[0].ID != "d2" {
+		t.Errorf("Query = %+v, want d2 then d1", results)
+	}
+
+	if err := s.Delete(ctx, "c", "d0"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := s.Delete(ctx, "c", "d0"); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("second Delete error = %v, want ErrNotFound", err)
+	}
+	if n, _ := s.Count(ctx, "c", store.Filter{}); n != 2 {
+		t.Errorf("Count = %d, want 2", n)
+	}
+
+	if err := s.Clear(ctx, "c"); err != nil {
+		t.Fatalf("Clear: %v", err)
+	}
+	if _, err := s.Get(ctx, "c", "d1"); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("Get after Clear error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestRedisDocumentStore_TTL(t *testing.T) {
+	ctx := context.Background()
+	s := newRedisStore(t, newFakeRedis(t))
+
+	if err := s.PutWithTTL(ctx, "c", "short", store.Document{Content: "x"}, 20*time.Millisecond); err != nil {
+		t.Fatalf("PutWithTTL: %v", err)
+	}
+	if err := s.Put(ctx, "c", "long", store.Document{Content: "y"}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	time.Sleep(40 * time.Millisecond)
+
+	if _, err := s.Get(ctx, "c", "short"); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("expired Get error = %v, want ErrNotFound", err)
+	}
+	if n, _ := s.Count(ctx, "c", store.Filter{}); n != 1 {
+		t.Errorf("Count = %d, want 1", n)
+	}
+}
+
+func TestWorkingMemory_SharedRedisPersistence(t *testing.T) {
+	ctx := context.Background()
+	addr := newFakeRedis(t)
+
+	replicaA := memory.NewWorkingMemory(
+		memory.WithPersistence(newRedisStore(t, addr), "session"),
+		memory.WithTTL(time.Hour),
+	)
+	replicaB := memory.NewWorkingMemory(
+		memory.WithPersistence(newRedisStore(t, addr), "session"),
+		memory.WithPersistenceReload(time.Nanosecond),
+		memory.WithTTL(time.Hour),
+	)
+
+	if err := replicaB.AddMessage(ctx, message.NewUserMessage("from b")); err != nil {
+		t.Fatalf("AddMessage: %v", err)
+	}
+	if err := replicaA.AddMessage(ctx, message.NewUserMessage("from a")); err != nil {
+		t.Fatalf("AddMessage: %v", err)
+	}
+
+	history, err := replicaB.GetHistory(ctx, 0)
+	if err != nil {
+		t.Fatalf("GetHistory: %v", err)
+	}
+	if len(history) != 2 || history[0].Content != "from b" || history[1].Content != "from a" {
+		t.Errorf("replica B history = %+v, want both replicas' messages", history)
+	}
+
+	// 已超过 TTL 的消息不写入 Redis
+	old := message.NewUserMessage("stale")
+	old.Timestamp = time.Now().Add(-2 * time.Hour)
+	if err := replicaA.AddMessage(ctx, old); err != nil {
+		t.Fatalf("AddMessage: %v", err)
+	}
+	if history, _ := replicaB.GetHistory(ctx, 0); len(history) != 2 {
+		t.Errorf("replica B history has %d messages, want 2", len(history))
+	}
+}
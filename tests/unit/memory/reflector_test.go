@@ -0,0 +1,132 @@
+package memory_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ahhsitt/helloagents-go/pkg/memory"
+)
+
+func newReflectionStores(t *testing.T, episodes ...memory.Episode) (*memory.EpisodicMemoryStore, *memory.SemanticMemoryStore) {
+	t.Helper()
+	ctx := context.Background()
+	episodic := memory.NewEpisodicMemory()
+	for i, ep := range episodes {
+		if ep.Timestamp == 0 {
+			ep.Timestamp = int64(1000 + i)
+		}
+		if err := episodic.AddEpisode(ctx, ep); err != nil {
+			t.Fatalf("AddEpisode() error = %v", err)
+		}
+	}
+	return episodic, memory.NewSemanticMemory(newMockEmbedder())
+}
+
+func TestReflector_WritesInsightsWithSourceEpisodes(t *testing.T) {
+	ctx := context.Background()
+	episodic, semantic := newReflectionStores(t,
+		memory.Episode{ID: "e1", Content: "user asked for a shorter answer"},
+		memory.Episode{ID: "e2", Content: "weather API timed out"},
+		memory.Episode{ID: "e3", Content: "user said: too long, just the summary"},
+	)
+
+	provider := &summaryProvider{generateFn: func(prompt string) (string, error) {
+		return "```json\n" + `{"insights":[
+			{"content":"User prefers concise answers","importance":0.8,"evidence":[1,3,3,9]},
+			{"content":"unsupported claim","evidence":[]}
+		]}` + "\n```", nil
+	}}
+
+	reflector := memory.NewReflector(provider, episodic, semantic)
+	insights, err := reflector.Reflect(ctx)
+	if err != nil {
+		t.Fatalf("Reflect() error = %v", err)
+	}
+	if len(insights) != 1 {
+		t.Fatalf("len(insights) = %d, want 1", len(insights))
+	}
+	if got := insights[0].SourceEpisodes; !reflect.DeepEqual(got, []string{"e1", "e3"}) {
+		t.Errorf("SourceEpisodes = %v, want [e1 e3]", got)
+	}
+	if !strings.Contains(provider.prompts[0], "[2] weather API timed out") {
+		t.Errorf("prompt missing numbered episode: %q", provider.prompts[0])
+	}
+
+	items, err := semantic.Retrieve(ctx, "concise answers", memory.WithLimit(5))
+	if err != nil {
+		t.Fatalf("Retrieve() error = %v", err)
+	}
+	if len(items) != 1 || items[0].ID != insights[0].ID {
+		t.Fatalf("semantic memory = %v, want the stored insight", items)
+	}
+	if got := items[0].Metadata["source_episodes"]; !reflect.DeepEqual(got, []string{"e1", "e3"}) {
+		t.Errorf("metadata source_episodes = %v, want [e1 e3]", got)
+	}
+
+	// 已反思的事件不会重复提交
+	if _, err := reflector.Reflect(ctx); err != nil {
+		t.Fatalf("second Reflect() error = %v", err)
+	}
+	if len(provider.prompts) != 1 {
+		t.Errorf("LLM called %d times, want 1", len(provider.prompts))
+	}
+}
+
+func TestReflector_GroupsByUserAndRespectsMinEpisodes(t *testing.T) {
+	ctx := context.Background()
+	episodic, semantic := newReflectionStores(t,
+		memory.Episode{ID: "a1", Content: "alice one", UserID: "alice"},
+		memory.Episode{ID: "b1", Content: "bob one", UserID: "bob"},
+		memory.Episode{ID: "a2", Content: "alice two", UserID: "alice"},
+	)
+
+	provider := &summaryProvider{generateFn: func(prompt string) (string, error) {
+		return `{"insights":[{"content":"alice insight","evidence":[1,2]}]}`, nil
+	}}
+
+	reflector := memory.NewReflector(provider, episodic, semantic, memory.WithReflectionMinEpisodes(2))
+	insights, err := reflector.Reflect(ctx)
+	if err != nil {
+		t.Fatalf("Reflect() error = %v", err)
+	}
+	if len(provider.prompts) != 1 || strings.Contains(provider.prompts[0], "bob") {
+		t.Fatalf("prompts = %q, want one prompt with only alice's episodes", provider.prompts)
+	}
+	if len(insights) != 1 || insights[0].UserID != "alice" {
+		t.Fatalf("insights = %+v, want one insight for alice", insights)
+	}
+}
+
+func TestReflector_FailureKeepsEpisodesPending(t *testing.T) {
+	ctx := context.Background()
+	episodic, semantic := newReflectionStores(t,
+		memory.Episode{ID: "e1", Content: "first"},
+		memory.Episode{ID: "e2", Content: "second"},
+		memory.Episode{ID: "e3", Content: "third"},
+	)
+
+	fail := true
+	provider := &summaryProvider{generateFn: func(prompt string) (string, error) {
+		if fail {
+			return "", errors.New("llm down")
+		}
+		return `{"insights":[{"content":"recovered","evidence":[1]}]}`, nil
+	}}
+
+	reflector := memory.NewReflector(provider, episodic, semantic)
+	if _, err := reflector.Reflect(ctx); err == nil {
+		t.Fatal("Reflect() error = nil, want llm error")
+	}
+
+	fail = false
+	insights, err := reflector.Reflect(ctx)
+	if err != nil {
+		t.Fatalf("Reflect() retry error = %v", err)
+	}
+	if len(insights) != 1 || semantic.Size() != 1 {
+		t.Errorf("insights = %d, semantic size = %d, want 1 and 1", len(insights), semantic.Size())
+	}
+}
@@ -0,0 +1,124 @@
+package memory_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ahhsitt/helloagents-go/pkg/memory"
+)
+
+// fixedScoreMemory 检索时返回固定分数结果的记忆存储
+type fixedScoreMemory struct {
+	memory.Memory
+	memType memory.MemoryType
+	items   []fixedScore
+}
+
+type fixedScore struct {
+	id         string
+	score      float64
+	importance float32
+}
+
+func (m *fixedScoreMemory) Retrieve(context.Context, string, ...memory.RetrieveOption) ([]*memory.MemoryItem, error) {
+	items := make([]*memory.MemoryItem, len(m.items))
+	for i, s := range m.items {
+		items[i] = memory.NewMemoryItem("memory "+s.id, m.memType,
+			memory.WithID(s.id), memory.WithImportance(s.importance), memory.WithMetadataKV("score", s.score))
+	}
+	return items, nil
+}
+
+func newScoredManager(opts ...memory.ManagerOption) *memory.MemoryManager {
+	manager := memory.NewMemoryManager(nil, opts...)
+	// 两个记忆类型的分数尺度相差 100 倍
+	_ = manager.RegisterMemory(memory.MemoryTypeEpisodic, &fixedScoreMemory{memType: memory.MemoryTypeEpisodic, items: []fixedScore{
+		{"a1", 90, 0.1}, {"a2", 10, 0.9},
+	}})
+	_ = manager.RegisterMemory(memory.MemoryTypeSemantic, &fixedScoreMemory{memType: memory.MemoryTypeSemantic, items: []fixedScore{
+		{"b1", 0.8, 0.5}, {"b2", 0.2, 0.2}, {"b3", 0.5, 0.3},
+	}})
+	return manager
+}
+
+func resultIDs(items []*memory.MemoryItem) string {
+	ids := make([]string, len(items))
+	for i, item := range items {
+		ids[i] = item.ID
+	}
+	return strings.Join(ids, ",")
+}
+
+func TestRetrieveMemories_ScoreNormalization(t *testing.T) {
+	ctx := context.Background()
+
+	cases := []struct {
+		name   string
+		method memory.ScoreNormalization
+		want   string
+	}{
+		{"none sorts by importance", memory.NormalizeNone, "a2,b1,b3,b2,a1"},
+		{"min-max", memory.NormalizeMinMax, "b1,a1,b3,a2,b2"},
+		{"z-score", memory.NormalizeZScore, "b1,a1,b3,a2,b2"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			results, err := newScoredManager(memory.WithScoreNormalization(tc.method)).RetrieveMemories(ctx, "q")
+			if err != nil {
+				t.Fatalf("RetrieveMemories() error = %v", err)
+			}
+			if got := resultIDs(results); got != tc.want {
+				t.Errorf("order = %s, want %s", got, tc.want)
+			}
+		})
+	}
+
+	results, _ := newScoredManager(memory.WithScoreNormalization(memory.NormalizeMinMax)).RetrieveMemories(ctx, "q", memory.WithLimit(1))
+	if len(results) != 1 || results[0].GetMetadataFloat(memory.MetadataScore) != 1 || results[0].GetMetadataFloat(memory.MetadataRawScore) != 0.8 {
+		t.Errorf("expected normalized and raw scores on top result, got %+v", results)
+	}
+}
+
+func TestRetrieveMemories_Reranker(t *testing.T) {
+	ctx := context.Background()
+
+	crossEncoder := memory.NewCrossEncoderReranker(func(_ context.Context, query string, passages []string) ([]float32, error) {
+		scores := make([]float32, len(passages))
+		for i, p := range passages {
+			if strings.HasSuffix(p, "b2") {
+				scores[i] = 0.99
+			}
+		}
+		return scores, nil
+	})
+	results, err := newScoredManager(memory.WithScoreNormalization(memory.NormalizeMinMax), memory.WithReranker(crossEncoder)).
+		RetrieveMemories(ctx, "q", memory.WithLimit(2))
+	if err != nil {
+		t.Fatalf("RetrieveMemories() error = %v", err)
+	}
+	// 重排序分数相同时按重要性排序
+	if got := resultIDs(results); got != "b2,a2" {
+		t.Errorf("cross encoder order = %s, want b2,a2", got)
+	}
+
+	provider := &summaryProvider{generateFn: func(prompt string) (string, error) {
+		if !strings.Contains(prompt, "memory a1") {
+			t.Errorf("expected memories in rerank prompt, got %q", prompt)
+		}
+		return `{"scores":[{"index":4,"score":0.9},{"index":1,"score":0.3}]}`, nil
+	}}
+	results, _ = newScoredManager(memory.WithReranker(memory.NewLLMReranker(provider))).RetrieveMemories(ctx, "q")
+	if got := resultIDs(results); got != "b2,a2,b1,b3,a1" {
+		t.Errorf("llm reranker order = %s, want b2,a2,b1,b3,a1", got)
+	}
+
+	failing := memory.NewCrossEncoderReranker(func(context.Context, string, []string) ([]float32, error) {
+		return nil, errors.New("service unavailable")
+	})
+	results, _ = newScoredManager(memory.WithReranker(failing)).RetrieveMemories(ctx, "q")
+	if got := resultIDs(results); got != "a2,b1,b3,b2,a1" {
+		t.Errorf("expected pre-rerank order when reranker fails, got %s", got)
+	}
+}
@@ -0,0 +1,122 @@
+package memory_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ahhsitt/helloagents-go/pkg/memory"
+	"github.com/ahhsitt/helloagents-go/pkg/memory/store"
+)
+
+func TestSemanticMemory_ResolveEntities(t *testing.T) {
+	ctx := context.Background()
+	m := memory.NewSemanticMemory(nil)
+
+	openai := memory.NewEntityWithOptions("OpenAI", memory.EntityTypeOrganization, memory.WithEntityFrequency(3))
+	openaiInc := memory.NewEntity("OpenAI, Inc.", memory.EntityTypeOrganization)
+	openAI := memory.NewEntity("Open AI", memory.EntityTypeOther)
+	ibm := memory.NewEntityWithOptions("IBM", memory.EntityTypeOrganization, memory.WithEntityFrequency(2))
+	bigBlue := memory.NewEntity("Big Blue", memory.EntityTypeOrganization)
+	alice := memory.NewEntity("Alice", memory.EntityTypePerson)
+	ibm.Vector = []float32{1, 0, 0}
+	bigBlue.Vector = []float32{0.99, 0.05, 0}
+	alice.Vector = []float32{0, 1, 0}
+	for _, e := range []*memory.Entity{openai, openaiInc, openAI, ibm, bigBlue, alice} {
+		if err := m.AddEntity(ctx, e); err != nil {
+			t.Fatalf("AddEntity(%s) error = %v", e.Name, err)
+		}
+	}
+
+	worksAt := memory.NewRelation(alice.ID, openai.ID, memory.RelationTypeWorksAt)
+	worksAt.AddEvidence("m1")
+	dupWorksAt := memory.NewRelation(alice.ID, openaiInc.ID, memory.RelationTypeWorksAt)
+	dupWorksAt.AddEvidence("m2")
+	self := memory.NewRelation(openAI.ID, openai.ID, memory.RelationTypeSimilarTo)
+	for _, r := range []*memory.Relation{worksAt, dupWorksAt, self} {
+		if err := m.AddRelation(ctx, r); err != nil {
+			t.Fatalf("AddRelation() error = %v", err)
+		}
+	}
+
+	provider := &summaryProvider{generateFn: func(prompt string) (string, error) {
+		if !strings.Contains(prompt, "IBM") || !strings.Contains(prompt, "Big Blue") {
+			t.Errorf("expected embedding candidates in prompt, got %q", prompt)
+		}
+		return `{"same":[1]}`, nil
+	}}
+
+	preview, err := m.ResolveEntities(ctx, memory.WithResolveSimilarity(0.95), memory.WithResolveDryRun())
+	if err != nil || len(preview) != 2 || m.EntityCount() != 6 {
+		t.Fatalf("dry run = %+v, %v (entities %d)", preview, err, m.EntityCount())
+	}
+
+	merges, err := m.ResolveEntities(ctx, memory.WithResolveSimilarity(0.95), memory.WithResolveAdjudicator(provider))
+	if err != nil {
+		t.Fatalf("ResolveEntities() error = %v", err)
+	}
+	if len(merges) != 2 || merges[0].KeepID != openai.ID || len(merges[0].DuplicateIDs) != 2 || merges[1].KeepID != ibm.ID {
+		t.Fatalf("unexpected merges %+v", merges)
+	}
+	if m.EntityCount() != 3 || m.RelationCount() != 1 {
+		t.Errorf("expected 3 entities and 1 relation, got %d/%d", m.EntityCount(), m.RelationCount())
+	}
+
+	got, err := m.GetEntityByName(ctx, "openai, inc.")
+	if err != nil || got.ID != openai.ID || got.Frequency != 5 {
+		t.Fatalf("GetEntityByName(alias) = %+v, %v", got, err)
+	}
+	if aliases, _ := got.GetProperty(memory.PropertyAliases); len(aliases.([]string)) != 2 {
+		t.Errorf("expected 2 aliases, got %v", aliases)
+	}
+
+	rel, err := m.GetRelation(ctx, worksAt.ID)
+	if err != nil || rel.ToEntityID != openai.ID || strings.Join(rel.Evidence, ",") != "m1,m2" {
+		t.Errorf("expected merged evidence on works_at, got %+v, %v", rel, err)
+	}
+
+	// 别名再次出现时命中保留实体
+	_ = m.AddEntity(ctx, memory.NewEntity("OpenAI, Inc.", memory.EntityTypeOrganization))
+	if m.EntityCount() != 3 {
+		t.Errorf("expected alias to resolve to existing entity, got %d entities", m.EntityCount())
+	}
+}
+
+func TestSemanticMemory_MergeEntitiesGraphStore(t *testing.T) {
+	ctx := context.Background()
+	graph := store.NewMemoryGraphStore()
+	m := memory.NewSemanticMemory(nil, memory.WithSemanticGraphStore(graph))
+
+	keep := memory.NewEntity("OpenAI", memory.EntityTypeOrganization)
+	dup := memory.NewEntity("OpenAI Inc", memory.EntityTypeOrganization)
+	sam := memory.NewEntity("Sam", memory.EntityTypePerson)
+	for _, e := range []*memory.Entity{keep, dup, sam} {
+		_ = m.AddEntity(ctx, e)
+	}
+	leads := memory.NewRelation(sam.ID, dup.ID, memory.RelationTypeWorksAt)
+	leads.AddEvidence("m1")
+	_ = m.AddRelation(ctx, leads)
+
+	if err := m.MergeEntities(ctx, keep.ID, []string{"missing"}); !errors.Is(err, memory.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for missing duplicate, got %v", err)
+	}
+	if err := m.MergeEntities(ctx, keep.ID, []string{dup.ID}); err != nil {
+		t.Fatalf("MergeEntities() error = %v", err)
+	}
+
+	if _, err := graph.GetEntity(ctx, dup.ID); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("expected duplicate removed from graph, got %v", err)
+	}
+	related, err := m.GetRelatedEntities(ctx, sam.ID, 1)
+	if err != nil || len(related) != 1 || related[0].Entity.ID != keep.ID {
+		t.Fatalf("expected sam related to kept entity, got %+v, %v", related, err)
+	}
+	if evidence := related[0].Path[0].Evidence; len(evidence) != 1 || evidence[0] != "m1" {
+		t.Errorf("expected evidence preserved, got %v", evidence)
+	}
+	merged, _ := m.GetEntity(ctx, keep.ID)
+	if merged.Frequency != 2 {
+		t.Errorf("expected frequency 2, got %d", merged.Frequency)
+	}
+}
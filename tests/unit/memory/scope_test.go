@@ -0,0 +1,138 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ahhsitt/helloagents-go/pkg/memory"
+)
+
+func newScopedItem(content, userID, sessionID string, memType memory.MemoryType) *memory.MemoryItem {
+	return memory.NewMemoryItem(content, memType,
+		memory.WithUserID(userID),
+		memory.WithMetadataKV("session_id", sessionID),
+	)
+}
+
+func TestMemoryStores_UserSessionScope(t *testing.T) {
+	ctx := context.Background()
+	stores := map[memory.MemoryType]memory.Memory{
+		memory.MemoryTypeWorking:  memory.NewWorkingMemory(),
+		memory.MemoryTypeEpisodic: memory.NewEpisodicMemory(),
+		memory.MemoryTypeSemantic: memory.NewSemanticMemory(nil),
+	}
+
+	for memType, store := range stores {
+		t.Run(string(memType), func(t *testing.T) {
+			items := []*memory.MemoryItem{
+				newScopedItem("alice deploy notes for api", "alice", "s1", memType),
+				newScopedItem("alice deploy checklist", "alice", "s2", memType),
+				newScopedItem("bob deploy notes for api", "bob", "s3", memType),
+			}
+			if _, err := store.AddBatch(ctx, items); err != nil {
+				t.Fatalf("AddBatch() error = %v", err)
+			}
+
+			results, err := store.Retrieve(ctx, "deploy", memory.WithUserIDFilter("alice"))
+			if err != nil {
+				t.Fatalf("Retrieve() error = %v", err)
+			}
+			if len(results) != 2 {
+				t.Fatalf("expected 2 results for alice, got %d", len(results))
+			}
+			for _, item := range results {
+				if item.UserID != "alice" {
+					t.Errorf("expected only alice's memories, got user %q", item.UserID)
+				}
+			}
+
+			results, err = store.Retrieve(ctx, "deploy",
+				memory.WithUserIDFilter("alice"),
+				memory.WithSessionIDFilter("s2"),
+			)
+			if err != nil {
+				t.Fatalf("Retrieve() error = %v", err)
+			}
+			if len(results) != 1 || results[0].ID != items[1].ID {
+				t.Fatalf("expected alice's s2 memory, got %v", results)
+			}
+
+			results, err = store.Retrieve(ctx, "deploy",
+				memory.WithUserIDFilter("bob"),
+				memory.WithSessionIDFilter("s1"),
+			)
+			if err != nil {
+				t.Fatalf("Retrieve() error = %v", err)
+			}
+			if len(results) != 0 {
+				t.Errorf("expected no results across users, got %d", len(results))
+			}
+
+			stats, err := store.GetStats(ctx, memory.WithUserIDFilter("bob"))
+			if err != nil {
+				t.Fatalf("GetStats() error = %v", err)
+			}
+			if stats.Count != 1 {
+				t.Errorf("expected 1 memory for bob, got %d", stats.Count)
+			}
+			stats, err = store.GetStats(ctx)
+			if err != nil {
+				t.Fatalf("GetStats() error = %v", err)
+			}
+			if stats.Count != 3 {
+				t.Errorf("expected 3 memories in total, got %d", stats.Count)
+			}
+		})
+	}
+}
+
+func TestMemoryManager_DefaultUserScope(t *testing.T) {
+	ctx := context.Background()
+	episodic := memory.NewEpisodicMemory()
+	alice := memory.NewMemoryManager(nil, memory.WithManagerUserID("alice"))
+	bob := memory.NewMemoryManager(nil, memory.WithManagerUserID("bob"))
+	_ = alice.RegisterMemory(memory.MemoryTypeEpisodic, episodic)
+	_ = bob.RegisterMemory(memory.MemoryTypeEpisodic, episodic)
+
+	for _, add := range []struct {
+		manager *memory.MemoryManager
+		content string
+		session string
+	}{
+		{alice, "meeting about quarterly roadmap", "s1"},
+		{alice, "meeting about hiring plan", "s2"},
+		{bob, "meeting about roadmap review", "s3"},
+	} {
+		_, err := add.manager.AddMemory(ctx, add.content,
+			memory.WithAddMemoryType(memory.MemoryTypeEpisodic),
+			memory.WithAddSessionID(add.session),
+		)
+		if err != nil {
+			t.Fatalf("AddMemory() error = %v", err)
+		}
+	}
+
+	results, err := bob.RetrieveMemories(ctx, "meeting roadmap")
+	if err != nil {
+		t.Fatalf("RetrieveMemories() error = %v", err)
+	}
+	if len(results) != 1 || results[0].UserID != "bob" {
+		t.Fatalf("expected bob's memory only, got %v", results)
+	}
+
+	results, err = alice.RetrieveMemories(ctx, "meeting", memory.WithSessionIDFilter("s2"))
+	if err != nil {
+		t.Fatalf("RetrieveMemories() error = %v", err)
+	}
+	if len(results) != 1 || results[0].Content != "meeting about hiring plan" {
+		t.Fatalf("expected alice's s2 memory, got %v", results)
+	}
+
+	stats, err := alice.GetStats(ctx, memory.WithUserIDFilter("alice"))
+	if err != nil {
+		t.Fatalf("GetStats() error = %v", err)
+	}
+	if stats.TotalCount != 2 {
+		t.Errorf("expected 2 memories for alice, got %d", stats.TotalCount)
+	}
+}
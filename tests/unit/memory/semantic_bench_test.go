@@ -0,0 +1,126 @@
+package memory_test
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ahhsitt/helloagents-go/pkg/memory"
+)
+
+// remoteEmbedDelay 模拟远程嵌入服务的调用耗时
+const remoteEmbedDelay = time.Millisecond
+
+// newSlowEmbedder 每次调用耗时 remoteEmbedDelay 的嵌入器
+func newSlowEmbedder() *mockEmbedder {
+	fast := newMockEmbedder()
+	return &mockEmbedder{embedFn: func(ctx context.Context, texts []string) ([][]float32, error) {
+		time.Sleep(remoteEmbedDelay)
+		return fast.Embed(ctx, texts)
+	}}
+}
+
+// latencies 并发收集操作耗时
+type latencies struct {
+	mu     sync.Mutex
+	values []time.Duration
+}
+
+func (l *latencies) add(d time.Duration) {
+	l.mu.Lock()
+	l.values = append(l.values, d)
+	l.mu.Unlock()
+}
+
+// percentile 返回第 p 百分位的耗时（毫秒）
+func (l *latencies) percentile(p float64) float64 {
+	if len(l.values) == 0 {
+		return 0
+	}
+	sort.Slice(l.values, func(i, j int) bool { return l.values[i] < l.values[j] })
+	idx := int(float64(len(l.values)-1) * p)
+	return float64(l.values[idx]) / float64(time.Millisecond)
+}
+
+// BenchmarkSemanticMemory_MixedReadWrite 在慢嵌入器下并发检索和写入（9:1），
+// 报告检索和写入的 p99 延迟。嵌入在锁外进行时写入不会被检索的嵌入调用阻塞。
+func BenchmarkSemanticMemory_MixedReadWrite(b *testing.B) {
+	ctx := context.Background()
+	mem := memory.NewSemanticMemory(newSlowEmbedder())
+	items := make([]*memory.MemoryItem, 500)
+	for i := range items {
+		items[i] = memory.NewMemoryItem(fmt.Sprintf("record %d about deploys, billing and user preferences", i), memory.MemoryTypeSemantic)
+	}
+	if _, err := mem.AddBatch(ctx, items); err != nil {
+		b.Fatalf("AddBatch() error = %v", err)
+	}
+	mem.Flush()
+
+	var reads, writes latencies
+	var seq atomic.Int64
+
+	b.SetParallelism(4)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			n := seq.Add(1)
+			start := time.Now()
+			if n%10 == 0 {
+				_ = mem.Store(ctx, "", fmt.Sprintf("new fact %d about deploys", n), nil)
+				writes.add(time.Since(start))
+			} else {
+				_, _ = mem.Search(ctx, "deploy billing", 5)
+				reads.add(time.Since(start))
+			}
+		}
+	})
+	b.StopTimer()
+
+	b.ReportMetric(reads.percentile(0.99), "read-p99-ms")
+	b.ReportMetric(writes.percentile(0.99), "write-p99-ms")
+}
+
+// BenchmarkSemanticMemory_EntityWritesDuringSearch 检索进行时写入实体，
+// 实体和关系使用独立的锁，不会等待记录检索。
+func BenchmarkSemanticMemory_EntityWritesDuringSearch(b *testing.B) {
+	ctx := context.Background()
+	mem := memory.NewSemanticMemory(newSlowEmbedder())
+	for i := 0; i < 200; i++ {
+		_ = mem.Store(ctx, fmt.Sprintf("r%d", i), fmt.Sprintf("record %d about the search team", i), nil)
+	}
+	mem.Flush()
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					_, _ = mem.Search(ctx, "search team", 5)
+				}
+			}
+		}()
+	}
+
+	var writes latencies
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		start := time.Now()
+		_ = mem.AddEntity(ctx, &memory.Entity{ID: fmt.Sprintf("e%d", i), Name: fmt.Sprintf("Entity %d", i), Vector: []float32{1}})
+		writes.add(time.Since(start))
+	}
+	b.StopTimer()
+	close(stop)
+	wg.Wait()
+
+	b.ReportMetric(writes.percentile(0.99), "write-p99-ms")
+}
@@ -0,0 +1,136 @@
+package memory_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ahhsitt/helloagents-go/pkg/core/message"
+	"github.com/ahhsitt/helloagents-go/pkg/memory"
+)
+
+func newSnapshotManager() (*memory.MemoryManager, *memory.WorkingMemory, *memory.EpisodicMemoryStore, *memory.SemanticMemoryStore) {
+	working := memory.NewWorkingMemory()
+	episodic := memory.NewEpisodicMemory()
+	semantic := memory.NewSemanticMemory(newMockEmbedder())
+
+	manager := memory.NewMemoryManager(nil)
+	_ = manager.RegisterMemory(memory.MemoryTypeWorking, working)
+	_ = manager.RegisterMemory(memory.MemoryTypeEpisodic, episodic)
+	_ = manager.RegisterMemory(memory.MemoryTypeSemantic, semantic)
+	return manager, working, episodic, semantic
+}
+
+func TestMemoryManager_ExportImportRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	src, working, episodic, semantic := newSnapshotManager()
+
+	_ = working.AddMessageWithImportance(ctx, message.NewUserMessage("hello"), 0.7)
+	_ = working.AddMessage(ctx, message.NewAssistantMessage("hi there"))
+	_ = episodic.AddEpisode(ctx, memory.Episode{ID: "ep-1", Type: "deploy", Content: "deployed api v2", Importance: 0.9})
+
+	id, err := semantic.Add(ctx, memory.NewMemoryItem("Go is a programming language", memory.MemoryTypeSemantic,
+		memory.WithUserID("alice"), memory.WithImportance(0.8)))
+	if err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	_ = semantic.AddEntity(ctx, &memory.Entity{ID: "e-go", Name: "Go", Type: memory.EntityTypeConcept})
+	_ = semantic.AddEntity(ctx, &memory.Entity{ID: "e-google", Name: "Google", Type: memory.EntityTypeOrganization})
+	_ = semantic.AddRelation(ctx, &memory.Relation{ID: "r-1", FromEntityID: "e-google", ToEntityID: "e-go", RelationType: memory.RelationTypeHasA, Strength: 0.6})
+
+	var buf bytes.Buffer
+	if err := src.Export(ctx, &buf); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	// 文件头 + 2 条消息 + 1 个事件 + 1 条语义记录 + 2 个实体 + 1 个关系
+	if len(lines) != 8 {
+		t.Fatalf("expected 8 lines, got %d:\n%s", len(lines), buf.String())
+	}
+	var header memory.SnapshotRecord
+	if err := json.Unmarshal([]byte(lines[0]), &header); err != nil {
+		t.Fatalf("unmarshal header: %v", err)
+	}
+	if header.Kind != memory.SnapshotKindHeader || header.Version != memory.SnapshotVersion {
+		t.Fatalf("unexpected header %+v", header)
+	}
+
+	dst, working2, episodic2, semantic2 := newSnapshotManager()
+	if err := dst.Import(ctx, bytes.NewReader(buf.Bytes())); err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+
+	msgs, _ := working2.GetHistory(ctx, 0)
+	if len(msgs) != 2 || msgs[0].Content != "hello" || msgs[1].Content != "hi there" {
+		t.Fatalf("unexpected restored messages %v", msgs)
+	}
+
+	episodes, _ := episodic2.GetEpisodes(ctx, nil)
+	if len(episodes) != 1 || episodes[0].ID != "ep-1" || episodes[0].Importance != 0.9 {
+		t.Errorf("unexpected restored episodes %+v", episodes)
+	}
+
+	results, err := semantic2.Retrieve(ctx, "Go is a programming language", memory.WithUserIDFilter("alice"))
+	if err != nil {
+		t.Fatalf("Retrieve() error = %v", err)
+	}
+	if len(results) != 1 || results[0].ID != id || results[0].Importance != 0.8 {
+		t.Fatalf("expected restored record with importance preserved, got %v", results)
+	}
+
+	rel, err := semantic2.GetRelation(ctx, "r-1")
+	if err != nil {
+		t.Fatalf("GetRelation() error = %v", err)
+	}
+	if rel.FromEntityID != "e-google" || rel.ToEntityID != "e-go" {
+		t.Errorf("unexpected restored relation %+v", rel)
+	}
+	if _, err := semantic2.GetEntity(ctx, "e-go"); err != nil {
+		t.Errorf("GetEntity() error = %v", err)
+	}
+
+	// 重复导入不会产生重复的消息和事件
+	if err := dst.Import(ctx, bytes.NewReader(buf.Bytes())); err != nil {
+		t.Fatalf("second Import() error = %v", err)
+	}
+	msgs, _ = working2.GetHistory(ctx, 0)
+	if len(msgs) != 2 {
+		t.Errorf("expected 2 messages after re-import, got %d", len(msgs))
+	}
+	if episodic2.Size() != 1 || semantic2.Size() != 1 {
+		t.Errorf("expected no duplicates after re-import, got %d episodes and %d records", episodic2.Size(), semantic2.Size())
+	}
+}
+
+func TestMemoryManager_ImportErrors(t *testing.T) {
+	ctx := context.Background()
+	manager, _, _, _ := newSnapshotManager()
+
+	err := manager.Import(ctx, strings.NewReader(`{"kind":"header","version":99}`+"\n"))
+	if !errors.Is(err, memory.ErrSnapshotVersion) {
+		t.Errorf("expected ErrSnapshotVersion, got %v", err)
+	}
+
+	err = manager.Import(ctx, strings.NewReader(`{"kind":"message","memory_type":"working","message":{"role":"user","content":"x"}}`+"\n"))
+	if !errors.Is(err, memory.ErrInvalidInput) {
+		t.Errorf("expected ErrInvalidInput for missing header, got %v", err)
+	}
+
+	snapshot := `{"kind":"header","version":1}` + "\n" +
+		`{"kind":"episode","memory_type":"perceptual","episode":{"id":"p-1","content":"x"}}` + "\n"
+	err = manager.Import(ctx, strings.NewReader(snapshot))
+	if !errors.Is(err, memory.ErrMemoryTypeNotFound) {
+		t.Errorf("expected ErrMemoryTypeNotFound, got %v", err)
+	}
+
+	snapshot = `{"kind":"header","version":1}` + "\n" +
+		`{"kind":"episode","memory_type":"working","episode":{"id":"p-1","content":"x"}}` + "\n"
+	err = manager.Import(ctx, strings.NewReader(snapshot))
+	if !errors.Is(err, memory.ErrInvalidInput) {
+		t.Errorf("expected ErrInvalidInput for mismatched record, got %v", err)
+	}
+}
@@ -0,0 +1,82 @@
+package memory_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/ahhsitt/helloagents-go/pkg/memory/store"
+)
+
+func TestSQLiteVectorStore_PersistAndFilter(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "vectors.db")
+
+	s, err := store.NewSQLiteVectorStore(store.SQLiteVectorConfig{Path: path, Dimensions: 3})
+	if err != nil {
+		t.Fatalf("NewSQLiteVectorStore() error = %v", err)
+	}
+	err = s.AddVectors(ctx, "c", []store.VectorRecord{
+		{ID: "a", MemoryID: "m1", Vector: []float32{1, 0, 0}, Payload: map[string]interface{}{"user_id": "u1", "lang": "en"}},
+		{ID: "b", MemoryID: "m2", Vector: []float32{0, 1, 0}, Payload: map[string]interface{}{"user_id": "u2", "lang": "en"}},
+		{ID: "c", MemoryID: "m3", Vector: []float32{0.9, 0.1, 0}, Payload: map[string]interface{}{"user_id": "u1", "lang": "fr"}},
+	})
+	if err != nil {
+		t.Fatalf("AddVectors() error = %v", err)
+	}
+	if err := s.AddVectors(ctx, "c", []store.VectorRecord{{ID: "bad", Vector: []float32{1}}}); err == nil {
+		t.Error("expected a dimension mismatch error")
+	}
+	_ = s.Close()
+
+	// 重新打开后数据仍在
+	s, err = store.NewSQLiteVectorStore(store.SQLiteVectorConfig{Path: path, Dimensions: 3})
+	if err != nil {
+		t.Fatalf("reopen error = %v", err)
+	}
+	defer s.Close()
+
+	results, err := s.SearchSimilar(ctx, "c", []float32{1, 0, 0}, 2, nil)
+	if err != nil || len(results) != 2 || results[0].ID != "a" || results[1].ID != "c" {
+		t.Fatalf("SearchSimilar() = %+v, %v", results, err)
+	}
+	if results[0].MemoryID != "m1" || results[0].Payload["lang"] != "en" {
+		t.Errorf("unexpected payload: %+v", results[0])
+	}
+
+	results, _ = s.SearchSimilar(ctx, "c", []float32{1, 0, 0}, 10, &store.VectorFilter{UserID: "u1", Conditions: map[string]interface{}{"lang": "fr"}})
+	if len(results) != 1 || results[0].ID != "c" {
+		t.Errorf("filtered search = %+v", results)
+	}
+
+	var scanned []string
+	err = s.ScanVectors(ctx, "c", &store.VectorFilter{Conditions: map[string]interface{}{"lang": "en"}}, func(rec store.VectorRecord) error {
+		scanned = append(scanned, rec.ID)
+		return s.DeleteVectors(ctx, "c", []string{rec.ID})
+	})
+	if err != nil || len(scanned) != 2 || scanned[0] != "a" || scanned[1] != "b" {
+		t.Errorf("ScanVectors() = %v, %v", scanned, err)
+	}
+
+	stats, _ := s.GetStats(ctx, "c")
+	if stats.VectorCount != 1 || stats.Dimensions != 3 {
+		t.Errorf("GetStats() = %+v", stats)
+	}
+	if other, _ := s.GetStats(ctx, "other"); other.VectorCount != 0 {
+		t.Errorf("collections should be isolated, got %+v", other)
+	}
+}
+
+func TestNewVectorStore_SQLite(t *testing.T) {
+	s, err := store.NewVectorStore(&store.Config{
+		Type:       store.StoreTypeSQLite,
+		SQLitePath: filepath.Join(t.TempDir(), "vectors.db"),
+	})
+	if err != nil {
+		t.Fatalf("NewVectorStore() error = %v", err)
+	}
+	defer s.Close()
+	if _, ok := s.(*store.SQLiteVectorStore); !ok {
+		t.Errorf("expected *store.SQLiteVectorStore, got %T", s)
+	}
+}
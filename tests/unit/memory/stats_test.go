@@ -0,0 +1,96 @@
+package memory_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ahhsitt/helloagents-go/pkg/memory"
+)
+
+func TestMemoryManager_GetStatsExtended(t *testing.T) {
+	ctx := context.Background()
+	episodic := memory.NewEpisodicMemory()
+	semantic := memory.NewSemanticMemory(newMockEmbedder())
+	manager := memory.NewMemoryManager(nil)
+	_ = manager.RegisterMemory(memory.MemoryTypeEpisodic, episodic)
+	_ = manager.RegisterMemory(memory.MemoryTypeSemantic, semantic)
+
+	old := time.Now().Add(-48 * time.Hour).UnixMilli()
+	_ = episodic.AddEpisode(ctx, memory.Episode{ID: "old", Content: "an event from two days ago", Timestamp: old, Importance: 0.1})
+	_ = episodic.AddEpisode(ctx, memory.Episode{ID: "new", Content: "an event from today", Importance: 0.9})
+	_ = semantic.Store(ctx, "s1", "Go is a statically typed language", nil)
+
+	if n, err := episodic.Forget(ctx, memory.ForgetByImportance, memory.WithThreshold(0.5)); err != nil || n != 1 {
+		t.Fatalf("Forget() = %d, %v", n, err)
+	}
+
+	stats, err := manager.GetStats(ctx)
+	if err != nil {
+		t.Fatalf("GetStats() error = %v", err)
+	}
+	if !stats.Consistent || stats.SnapshotAt.IsZero() {
+		t.Errorf("expected a consistent snapshot, got consistent=%v at %v", stats.Consistent, stats.SnapshotAt)
+	}
+	if stats.TotalCount != 2 || stats.AddedLast24h != 2 || stats.ForgottenLast24h != 1 {
+		t.Errorf("unexpected totals: count=%d added=%d forgotten=%d", stats.TotalCount, stats.AddedLast24h, stats.ForgottenLast24h)
+	}
+
+	semStats := stats.ByType[memory.MemoryTypeSemantic]
+	if semStats.TotalTokens == 0 || semStats.StorageBytes <= int64(len("Go is a statically typed language")) {
+		t.Errorf("expected tokens and vector bytes for semantic memory, got %+v", semStats)
+	}
+	if stats.TotalTokens != semStats.TotalTokens+stats.ByType[memory.MemoryTypeEpisodic].TotalTokens {
+		t.Errorf("total tokens %d do not match per-type tokens", stats.TotalTokens)
+	}
+
+	// 限定范围时遗忘记录不区分用户，不计入
+	scoped, _ := manager.GetStats(ctx, memory.WithUserIDFilter("alice"))
+	if scoped.ForgottenLast24h != 0 {
+		t.Errorf("expected no forgotten count for a scoped snapshot, got %d", scoped.ForgottenLast24h)
+	}
+}
+
+func TestMemoryManager_GetStatsConcurrentWrites(t *testing.T) {
+	ctx := context.Background()
+	working := memory.NewWorkingMemory(memory.WithMaxSize(0))
+	episodic := memory.NewEpisodicMemory()
+	manager := memory.NewMemoryManager(nil)
+	_ = manager.RegisterMemory(memory.MemoryTypeWorking, working)
+	_ = manager.RegisterMemory(memory.MemoryTypeEpisodic, episodic)
+
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				memType := memory.MemoryTypeWorking
+				if i%2 == 1 {
+					memType = memory.MemoryTypeEpisodic
+				}
+				_, _ = manager.AddMemory(ctx, fmt.Sprintf("item %d-%d", w, i), memory.WithAddMemoryType(memType))
+			}
+		}(w)
+	}
+
+	last := 0
+	for i := 0; i < 20; i++ {
+		stats, err := manager.GetStats(ctx)
+		if err != nil {
+			t.Fatalf("GetStats() error = %v", err)
+		}
+		if stats.TotalCount < last {
+			t.Fatalf("total count went backwards: %d -> %d", last, stats.TotalCount)
+		}
+		last = stats.TotalCount
+	}
+	wg.Wait()
+
+	stats, _ := manager.GetStats(ctx)
+	if stats.TotalCount != 200 {
+		t.Errorf("expected 200 items, got %d", stats.TotalCount)
+	}
+}
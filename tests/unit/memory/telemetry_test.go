@@ -0,0 +1,84 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/ahhsitt/helloagents-go/pkg/memory"
+	"github.com/ahhsitt/helloagents-go/pkg/otel"
+)
+
+func TestMemoryManager_Telemetry(t *testing.T) {
+	ctx := context.Background()
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	metrics := otel.NewInMemoryMetrics()
+
+	manager := memory.NewMemoryManager(nil, memory.WithTelemetry(otel.NewTracer(tp.Tracer("test")), metrics))
+	_ = manager.RegisterMemory(memory.MemoryTypeWorking, memory.NewWorkingMemory())
+	_ = manager.RegisterMemory(memory.MemoryTypeEpisodic, memory.NewEpisodicMemory())
+
+	if _, err := manager.AddMemory(ctx, "deployed the billing service", memory.WithAddMemoryType(memory.MemoryTypeEpisodic), memory.WithAddImportance(0.1)); err != nil {
+		t.Fatalf("AddMemory() error = %v", err)
+	}
+	if _, err := manager.AddMemory(ctx, "user prefers dark mode", memory.WithAddMemoryType(memory.MemoryTypeWorking), memory.WithAddImportance(0.9)); err != nil {
+		t.Fatalf("AddMemory() error = %v", err)
+	}
+	if _, err := manager.RetrieveMemories(ctx, "billing", memory.WithMemoryTypeFilter(memory.MemoryTypeEpisodic)); err != nil {
+		t.Fatalf("RetrieveMemories() error = %v", err)
+	}
+	if n, err := manager.ConsolidateMemories(ctx); err != nil || n != 1 {
+		t.Fatalf("ConsolidateMemories() = %d, %v", n, err)
+	}
+	// 整合后工作记忆为空，检索未命中
+	if _, err := manager.RetrieveMemories(ctx, "dark mode", memory.WithMemoryTypeFilter(memory.MemoryTypeWorking)); err != nil {
+		t.Fatalf("RetrieveMemories() error = %v", err)
+	}
+	if _, err := manager.ForgetMemories(ctx, memory.ForgetByImportance, memory.WithThreshold(0.5)); err != nil {
+		t.Fatalf("ForgetMemories() error = %v", err)
+	}
+
+	spans := make(map[string]int)
+	for _, s := range exporter.GetSpans() {
+		spans[s.Name]++
+	}
+	for _, name := range []string{"memory.add", "memory.retrieve", "memory.retrieve.episodic", "memory.consolidate", "memory.forget", "memory.forget.episodic"} {
+		if spans[name] == 0 {
+			t.Errorf("expected span %q, got %v", name, spans)
+		}
+	}
+
+	if got := metrics.GetCounterValue(otel.MetricMemoryOperations); got != 6 {
+		t.Errorf("memory.operations = %d, want 6", got)
+	}
+	if hits, misses := metrics.GetCounterValue(otel.MetricMemoryHits), metrics.GetCounterValue(otel.MetricMemoryMisses); hits != 1 || misses != 1 {
+		t.Errorf("hits = %d, misses = %d, want 1 and 1", hits, misses)
+	}
+	if got := metrics.GetCounterValue(otel.MetricMemoryConsolidated); got != 1 {
+		t.Errorf("memory.consolidated = %d, want 1", got)
+	}
+	if got := metrics.GetCounterValue(otel.MetricMemoryForgotten); got != 1 {
+		t.Errorf("memory.forgotten = %d, want 1", got)
+	}
+	if got := metrics.Histogram(otel.MetricMemoryRetrieveDuration).(*otel.InMemoryHistogram).Values(); len(got) != 2 {
+		t.Errorf("expected 2 retrieval latency samples, got %d", len(got))
+	}
+}
+
+func TestMemoryManager_TelemetryRecordsErrors(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	manager := memory.NewMemoryManager(nil, memory.WithTelemetry(otel.NewTracer(tp.Tracer("test")), nil))
+
+	if _, err := manager.AddMemory(context.Background(), "orphan", memory.WithAddMemoryType(memory.MemoryTypeSemantic)); err == nil {
+		t.Fatal("expected error for unregistered memory type")
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 || spans[0].Name != "memory.add" || len(spans[0].Events) == 0 {
+		t.Fatalf("expected one memory.add span with a recorded error, got %+v", spans)
+	}
+}
@@ -0,0 +1,85 @@
+package memory_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ahhsitt/helloagents-go/pkg/memory"
+)
+
+// tick 等待时钟前进并返回当前时间，保证前后操作的记录时间不同
+func tick() time.Time {
+	time.Sleep(2 * time.Millisecond)
+	now := time.Now()
+	time.Sleep(2 * time.Millisecond)
+	return now
+}
+
+func TestEpisodicMemory_GetStateAt(t *testing.T) {
+	ctx := context.Background()
+	mem := memory.NewEpisodicMemory(memory.WithEpisodicVersioning())
+
+	_ = mem.AddEpisode(ctx, memory.Episode{ID: "e1", Content: "user lives in SF"})
+	_ = mem.AddEpisode(ctx, memory.Episode{ID: "e2", Content: "deploy failed with timeout"})
+	t1 := tick()
+
+	_ = mem.Update(ctx, "e1", memory.WithContentUpdate("user lives in NYC"))
+	_ = mem.Remove(ctx, "e2")
+	t2 := tick()
+
+	state, err := mem.GetStateAt(ctx, t1)
+	if err != nil {
+		t.Fatalf("GetStateAt() error = %v", err)
+	}
+	if len(state) != 2 || state[0].Content != "user lives in SF" || state[1].ID != "e2" {
+		t.Errorf("unexpected state at t1: %+v", state)
+	}
+
+	state, _ = mem.GetStateAt(ctx, t2)
+	if len(state) != 1 || state[0].Content != "user lives in NYC" {
+		t.Errorf("unexpected state at t2: %+v", state)
+	}
+
+	versions, err := mem.GetVersions(ctx, "e1")
+	if err != nil || len(versions) != 2 || versions[0].ValidTo.IsZero() || !versions[1].ValidTo.IsZero() {
+		t.Errorf("GetVersions() = %+v, %v", versions, err)
+	}
+	// 只更新重要性（如检索强化）不产生新版本
+	for i := 0; i < 5; i++ {
+		_ = mem.Update(ctx, "e1", memory.WithImportanceUpdate(0.9))
+	}
+	if versions, _ := mem.GetVersions(ctx, "e1"); len(versions) != 2 {
+		t.Errorf("importance-only updates created versions: %d, want 2", len(versions))
+	}
+	if _, err := mem.GetVersions(ctx, "missing"); !errors.Is(err, memory.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	// 检索时按历史状态评分
+	items, err := mem.Retrieve(ctx, "deploy timeout", memory.WithAsOf(t1))
+	if err != nil || len(items) == 0 || items[0].ID != "e2" {
+		t.Errorf("Retrieve(AsOf t1) = %v, %v", items, err)
+	}
+	items, _ = mem.Retrieve(ctx, "deploy timeout", memory.WithAsOf(t2))
+	for _, item := range items {
+		if item.ID == "e2" {
+			t.Errorf("removed episode should not be visible at t2")
+		}
+	}
+}
+
+func TestEpisodicMemory_GetStateAtWithoutVersioning(t *testing.T) {
+	ctx := context.Background()
+	mem := memory.NewEpisodicMemory()
+	now := time.Now()
+
+	_ = mem.AddEpisode(ctx, memory.Episode{ID: "old", Content: "yesterday", Timestamp: now.Add(-24 * time.Hour).UnixMilli()})
+	_ = mem.AddEpisode(ctx, memory.Episode{ID: "new", Content: "today", Timestamp: now.UnixMilli()})
+
+	state, err := mem.GetStateAt(ctx, now.Add(-time.Hour))
+	if err != nil || len(state) != 1 || state[0].ID != "old" {
+		t.Errorf("GetStateAt() = %+v, %v", state, err)
+	}
+}
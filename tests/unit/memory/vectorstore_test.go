@@ -0,0 +1,126 @@
+package memory_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ahhsitt/helloagents-go/pkg/memory"
+	"github.com/ahhsitt/helloagents-go/pkg/memory/store"
+)
+
+func TestSemanticMemory_VectorStoreBacked(t *testing.T) {
+	ctx := context.Background()
+	vectors := store.NewMemoryVectorStore()
+	m := memory.NewSemanticMemoryWithVectorStore(newMockEmbedder(), vectors, "")
+
+	items := []*memory.MemoryItem{
+		memory.NewMemoryItem("Go is a programming language", memory.MemoryTypeSemantic, memory.WithUserID("alice")),
+		memory.NewMemoryItem("Python is a programming language", memory.MemoryTypeSemantic, memory.WithUserID("bob")),
+	}
+	ids, err := m.AddBatch(ctx, items)
+	if err != nil {
+		t.Fatalf("AddBatch() error = %v", err)
+	}
+
+	stats, err := vectors.GetStats(ctx, memory.DefaultSemanticCollection)
+	if err != nil {
+		t.Fatalf("GetStats() error = %v", err)
+	}
+	if stats.VectorCount != 2 {
+		t.Fatalf("expected 2 vectors in vector store, got %d", stats.VectorCount)
+	}
+
+	results, err := m.Retrieve(ctx, "Go is a programming language", memory.WithLimit(1))
+	if err != nil {
+		t.Fatalf("Retrieve() error = %v", err)
+	}
+	if len(results) != 1 || results[0].ID != ids[0] {
+		t.Fatalf("expected Go memory first, got %v", results)
+	}
+
+	results, err = m.Retrieve(ctx, "Go is a programming language", memory.WithUserIDFilter("bob"))
+	if err != nil {
+		t.Fatalf("Retrieve() error = %v", err)
+	}
+	if len(results) != 1 || results[0].UserID != "bob" {
+		t.Fatalf("expected only bob's memory, got %v", results)
+	}
+
+	// 新建的存储没有本地缓存，结果由向量 payload 还原
+	restored := memory.NewSemanticMemoryWithVectorStore(newMockEmbedder(), vectors, "")
+	results, err = restored.Retrieve(ctx, "Python is a programming language", memory.WithLimit(1))
+	if err != nil {
+		t.Fatalf("Retrieve() error = %v", err)
+	}
+	if len(results) != 1 || results[0].Content != "Python is a programming language" || results[0].UserID != "bob" {
+		t.Fatalf("expected Python memory restored from payload, got %v", results)
+	}
+
+	if err := m.Delete(ctx, ids[0]); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	stats, _ = vectors.GetStats(ctx, memory.DefaultSemanticCollection)
+	if stats.VectorCount != 1 {
+		t.Errorf("expected 1 vector after delete, got %d", stats.VectorCount)
+	}
+
+	if err := m.Clear(ctx); err != nil {
+		t.Fatalf("Clear() error = %v", err)
+	}
+	stats, _ = vectors.GetStats(ctx, memory.DefaultSemanticCollection)
+	if stats.VectorCount != 0 {
+		t.Errorf("expected empty vector store after clear, got %d", stats.VectorCount)
+	}
+}
+
+// slowVectorStore 写入内容 slow 的向量后暂停返回，并记录每个 ID 最后写入的内容
+type slowVectorStore struct {
+	store.VectorStore
+	mu   sync.Mutex
+	last map[string]interface{}
+}
+
+func (s *slowVectorStore) AddVectors(ctx context.Context, collection string, records []store.VectorRecord) error {
+	if err := s.VectorStore.AddVectors(ctx, collection, records); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	for _, rec := range records {
+		s.last[rec.ID] = rec.Payload["content"]
+	}
+	s.mu.Unlock()
+	if records[0].Payload["content"] == "slow" {
+		time.Sleep(50 * time.Millisecond)
+	}
+	return nil
+}
+
+func TestSemanticMemory_ConcurrentWritesStayConsistent(t *testing.T) {
+	ctx := context.Background()
+	vectors := &slowVectorStore{VectorStore: store.NewMemoryVectorStore(), last: make(map[string]interface{})}
+	m := memory.NewSemanticMemoryWithVectorStore(newMockEmbedder(), vectors, "")
+
+	// slow 先写入向量数据库但迟于 fast 写入本地，同一 ID 的写入必须串行
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		_ = m.Store(ctx, "id-1", "slow", nil)
+	}()
+	go func() {
+		defer wg.Done()
+		time.Sleep(10 * time.Millisecond)
+		_ = m.Store(ctx, "id-1", "fast", nil)
+	}()
+	wg.Wait()
+
+	records, err := m.ExportSnapshot(ctx)
+	if err != nil || len(records) != 1 || records[0].Semantic == nil {
+		t.Fatalf("ExportSnapshot() = %v, %v", records, err)
+	}
+	if local, remote := records[0].Semantic.Content, vectors.last["id-1"]; remote != local {
+		t.Errorf("vector store has %v, local record has %q", remote, local)
+	}
+}
@@ -440,3 +440,35 @@ func TestWorkingMemory_CalculateScore(t *testing.T) {
 		t.Error("expected at least one result")
 	}
 }
+
+func TestWorkingMemory_Attachments(t *testing.T) {
+	mem := memory.NewWorkingMemory()
+	ctx := context.Background()
+
+	msg := message.NewUserMessage("")
+	msg.Attachments = []message.Attachment{
+		{ID: "artifact-1", MimeType: "image/png", Caption: "architecture diagram of the billing service"},
+	}
+	if err := msg.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v, want attachment-only user message to be valid", err)
+	}
+	_ = mem.AddMessage(ctx, msg)
+	_ = mem.AddMessage(ctx, message.NewAssistantMessage("The diagram shows three services"))
+
+	history, err := mem.GetHistory(ctx, 0)
+	if err != nil {
+		t.Fatalf("GetHistory() error = %v", err)
+	}
+	if len(history[0].Attachments) != 1 || !history[0].Attachments[0].IsImage() {
+		t.Fatalf("expected image attachment in history, got %+v", history[0].Attachments)
+	}
+
+	results, _ := mem.Retrieve(ctx, "billing diagram", memory.WithLimit(1))
+	if len(results) != 1 {
+		t.Fatalf("expected caption to be searchable, got %d results", len(results))
+	}
+	atts, ok := results[0].Metadata["attachments"].([]message.Attachment)
+	if !ok || atts[0].ID != "artifact-1" {
+		t.Errorf("expected attachments in item metadata, got %v", results[0].Metadata["attachments"])
+	}
+}
@@ -0,0 +1,238 @@
+package a2a_test
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ahhsitt/helloagents-go/pkg/agents"
+	"github.com/ahhsitt/helloagents-go/pkg/core/config"
+	"github.com/ahhsitt/helloagents-go/pkg/protocols/a2a"
+)
+
+// echoAgent 以多个文本块回复 "echo: <query>"，记录收到的输入
+type echoAgent struct {
+	inputs chan agents.Input
+}
+
+func (a *echoAgent) Run(ctx context.Context, input agents.Input) (agents.Output, error) {
+	return agents.Output{Response: "echo: " + input.Query}, nil
+}
+
+func (a *echoAgent) RunStream(ctx context.Context, input agents.Input) (<-chan agents.StreamChunk, <-chan error) {
+	if a.inputs != nil {
+		a.inputs <- input
+	}
+	chunks := make(chan agents.StreamChunk, 4)
+	errs := make(chan error, 1)
+	chunks <- agents.StreamChunk{Type: agents.ChunkTypeText, Content: "echo: "}
+	chunks <- agents.StreamChunk{Type: agents.ChunkTypeStep}
+	chunks <- agents.StreamChunk{Type: agents.ChunkTypeText, Content: input.Query}
+	chunks <- agents.StreamChunk{Type: agents.ChunkTypeDone, Done: true}
+	close(chunks)
+	close(errs)
+	return chunks, errs
+}
+
+func (a *echoAgent) Name() string { return "echo agent" }
+
+func (a *echoAgent) Config() config.AgentConfig { return config.AgentConfig{Name: "echo agent"} }
+
+// newEchoServer 启动发布 echoAgent 的 A2A 服务器
+func newEchoServer(t *testing.T) (*httptest.Server, *a2a.Client) {
+	t.Helper()
+	server := a2a.NewAgentServer(&echoAgent{}, a2a.AgentCard{
+		Description: "Echoes the query",
+		Skills:      []a2a.AgentSkill{{ID: "echo", Name: "Echo", Description: "Repeat text", Tags: []string{"test"}}},
+	})
+	ts := httptest.NewServer(server)
+	t.Cleanup(ts.Close)
+	return ts, a2a.NewClient(ts.URL + "/")
+}
+
+func TestClient_Card(t *testing.T) {
+	ts, client := newEchoServer(t)
+
+	card, err := client.Card(context.Background())
+	if err != nil {
+		t.Fatalf("Card() error = %v", err)
+	}
+	if card.Name != "echo agent" || card.URL != ts.URL+"/" || !card.Capabilities.Streaming {
+		t.Errorf("Card() = %+v", card)
+	}
+	if card.ProtocolVersion != a2a.ProtocolVersion || len(card.DefaultInputModes) == 0 || len(card.Skills) != 1 {
+		t.Errorf("Card() defaults = %+v", card)
+	}
+}
+
+func TestClient_SendMessage(t *testing.T) {
+	_, client := newEchoServer(t)
+	ctx := context.Background()
+
+	result, err := client.SendMessage(ctx, a2a.NewTextMessage(a2a.RoleUser, "hello"))
+	if err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+	task := result.Task
+	if task == nil || task.Status.State != a2a.TaskStateCompleted {
+		t.Fatalf("SendMessage() task = %+v, want completed", task)
+	}
+	if result.Text() != "echo: hello" || len(task.Artifacts) != 1 {
+		t.Errorf("SendMessage() text = %q, artifacts = %d", result.Text(), len(task.Artifacts))
+	}
+	if len(task.History) != 2 || task.History[0].Role != a2a.RoleUser || task.History[1].Role != a2a.RoleAgent {
+		t.Errorf("SendMessage() history = %+v", task.History)
+	}
+
+	one := 1
+	got, err := client.GetTask(ctx, task.ID, &one)
+	if err != nil {
+		t.Fatalf("GetTask() error = %v", err)
+	}
+	if len(got.History) != 1 || got.History[0].Text() != "echo: hello" {
+		t.Errorf("GetTask() history = %+v, want last agent message", got.History)
+	}
+
+	var rpcErr *a2a.JSONRPCError
+	if _, err := client.GetTask(ctx, "missing", nil); !errors.As(err, &rpcErr) || rpcErr.Code != a2a.CodeTaskNotFound {
+		t.Errorf("GetTask(missing) error = %v, want task not found", err)
+	}
+	if _, err := client.CancelTask(ctx, task.ID); !errors.As(err, &rpcErr) || rpcErr.Code != a2a.CodeTaskNotCancelable {
+		t.Errorf("CancelTask(completed) error = %v, want not cancelable", err)
+	}
+
+	// 继续已有任务不受支持，未知任务返回任务不存在
+	msg := a2a.NewTextMessage(a2a.RoleUser, "again")
+	msg.TaskID = task.ID
+	if _, err := client.SendMessage(ctx, msg); !errors.As(err, &rpcErr) || rpcErr.Code != a2a.CodeUnsupportedOperation {
+		t.Errorf("SendMessage(existing task) error = %v, want unsupported operation", err)
+	}
+	msg.TaskID = "missing"
+	if _, err := client.SendMessage(ctx, msg); !errors.As(err, &rpcErr) || rpcErr.Code != a2a.CodeTaskNotFound {
+		t.Errorf("SendMessage(unknown task) error = %v, want task not found", err)
+	}
+}
+
+func TestClient_StreamMessage(t *testing.T) {
+	_, client := newEchoServer(t)
+
+	events, errs := client.StreamMessage(context.Background(), a2a.NewTextMessage(a2a.RoleUser, "hi"))
+	var kinds, chunks []string
+	var final *a2a.TaskStatusUpdateEvent
+	for event := range events {
+		kinds = append(kinds, event.Kind)
+		switch {
+		case event.ArtifactUpdate != nil:
+			update := event.ArtifactUpdate
+			chunks = append(chunks, update.Artifact.Parts[0].Text)
+			if update.Append != (len(chunks) > 1) || update.LastChunk != (len(chunks) == 2) {
+				t.Errorf("artifact chunk %d append = %v, lastChunk = %v", len(chunks), update.Append, update.LastChunk)
+			}
+		case event.StatusUpdate != nil && event.StatusUpdate.Final:
+			final = event.StatusUpdate
+		}
+	}
+	if err := <-errs; err != nil {
+		t.Fatalf("StreamMessage() error = %v", err)
+	}
+
+	want := "task status-update artifact-update artifact-update status-update"
+	if got := strings.Join(kinds, " "); got != want {
+		t.Errorf("event kinds = %q, want %q", got, want)
+	}
+	if strings.Join(chunks, "") != "echo: hi" {
+		t.Errorf("artifact chunks = %q", chunks)
+	}
+	if final == nil || final.Status.State != a2a.TaskStateCompleted {
+		t.Errorf("final status = %+v, want completed", final)
+	}
+}
+
+func TestServer_CancelTask(t *testing.T) {
+	started := make(chan struct{})
+	server := a2a.NewServer(a2a.AgentCard{Name: "slow"}, func(ctx context.Context, req a2a.TaskRequest, emit func(string)) (string, error) {
+		close(started)
+		<-ctx.Done()
+		return "", ctx.Err()
+	})
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+	client := a2a.NewClient(ts.URL)
+	ctx := context.Background()
+
+	events, errs := client.StreamMessage(ctx, a2a.NewTextMessage(a2a.RoleUser, "work"))
+	first := <-events
+	if first.Task == nil {
+		t.Fatalf("first event = %+v, want task", first)
+	}
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("handler not started")
+	}
+
+	task, err := client.CancelTask(ctx, first.Task.ID)
+	if err != nil || task.Status.State != a2a.TaskStateCanceled {
+		t.Fatalf("CancelTask() = %+v, %v", task, err)
+	}
+
+	var final *a2a.TaskStatusUpdateEvent
+	for event := range events {
+		if event.StatusUpdate != nil && event.StatusUpdate.Final {
+			final = event.StatusUpdate
+		}
+	}
+	if err := <-errs; err != nil {
+		t.Fatalf("StreamMessage() error = %v", err)
+	}
+	if final == nil || final.Status.State != a2a.TaskStateCanceled {
+		t.Errorf("final status = %+v, want canceled", final)
+	}
+}
+
+func TestAgentTool(t *testing.T) {
+	agent := &echoAgent{inputs: make(chan agents.Input, 2)}
+	ts := httptest.NewServer(a2a.NewAgentServer(agent, a2a.AgentCard{Description: "Echoes the query"}))
+	defer ts.Close()
+	ctx := context.Background()
+
+	tool, err := a2a.NewAgentToolFromCard(ctx, a2a.NewClient(ts.URL+"/"), a2a.WithSharedContext())
+	if err != nil {
+		t.Fatalf("NewAgentToolFromCard() error = %v", err)
+	}
+	if tool.Name() != "echo_agent" || !strings.Contains(tool.Description(), "Echoes the query") {
+		t.Errorf("tool = %q, %q", tool.Name(), tool.Description())
+	}
+
+	for _, query := range []string{"one", "two"} {
+		result, err := tool.Execute(ctx, map[string]interface{}{"query": query})
+		if err != nil || result != "echo: "+query {
+			t.Errorf("Execute(%s) = %q, %v", query, result, err)
+		}
+	}
+	first, second := <-agent.inputs, <-agent.inputs
+	if first.SessionID == "" || first.SessionID != second.SessionID {
+		t.Errorf("session ids = %q, %q, want shared context", first.SessionID, second.SessionID)
+	}
+
+	if _, err := tool.Execute(ctx, map[string]interface{}{}); err == nil {
+		t.Error("Execute() without query error = nil")
+	}
+}
+
+func TestAgentTool_FailedTask(t *testing.T) {
+	server := a2a.NewServer(a2a.AgentCard{Name: "broken"}, func(ctx context.Context, req a2a.TaskRequest, emit func(string)) (string, error) {
+		return "", errors.New("model unavailable")
+	})
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	tool := a2a.NewAgentTool(a2a.NewClient(ts.URL), a2a.WithToolName("broken"))
+	_, err := tool.Execute(context.Background(), map[string]interface{}{"query": "hi"})
+	if err == nil || !strings.Contains(err.Error(), "failed: model unavailable") {
+		t.Errorf("Execute() error = %v, want failed task error", err)
+	}
+}
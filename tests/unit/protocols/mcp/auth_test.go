@@ -0,0 +1,312 @@
+package mcp_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ahhsitt/helloagents-go/pkg/protocols/mcp"
+)
+
+// fakeAuthServer 最小的 OAuth 授权服务器：动态注册、授权码、刷新和客户端凭证
+type fakeAuthServer struct {
+	*httptest.Server
+
+	mu      sync.Mutex
+	issued  map[string]bool // access token -> 有效
+	grants  []string
+	clients int
+}
+
+func newFakeAuthServer(t *testing.T) *fakeAuthServer {
+	t.Helper()
+	as := &fakeAuthServer{issued: make(map[string]bool)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/oauth-authorization-server", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(mcp.AuthorizationServerMetadata{
+			Issuer:                as.URL,
+			AuthorizationEndpoint: as.URL + "/oauth/authorize",
+			TokenEndpoint:         as.URL + "/oauth/token",
+			RegistrationEndpoint:  as.URL + "/oauth/register",
+		})
+	})
+	mux.HandleFunc("/oauth/register", func(w http.ResponseWriter, r *http.Request) {
+		as.mu.Lock()
+		as.clients++
+		id := fmt.Sprintf("client-%d", as.clients)
+		as.mu.Unlock()
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]string{"client_id": id})
+	})
+	mux.HandleFunc("/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		grant := r.PostForm.Get("grant_type")
+		switch grant {
+		case "authorization_code":
+			if r.PostForm.Get("code") != "good-code" || r.PostForm.Get("code_verifier") == "" {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+				return
+			}
+		case "client_credentials":
+			if id, secret, ok := r.BasicAuth(); !ok || id != "service" || secret != "s3cret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				json.NewEncoder(w).Encode(map[string]string{"error": "invalid_client"})
+				return
+			}
+		}
+
+		as.mu.Lock()
+		as.grants = append(as.grants, grant)
+		access := fmt.Sprintf("access-%d", len(as.grants))
+		as.issued[access] = true
+		as.mu.Unlock()
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token":  access,
+			"token_type":    "Bearer",
+			"refresh_token": "refresh",
+			"expires_in":    3600,
+		})
+	})
+	as.Server = httptest.NewServer(mux)
+	t.Cleanup(as.Close)
+	return as
+}
+
+// validate 校验由本服务器签发且未吊销的令牌
+func (as *fakeAuthServer) validate(_ context.Context, token string) (*mcp.TokenInfo, error) {
+	as.mu.Lock()
+	defer as.mu.Unlock()
+	if !as.issued[token] {
+		return nil, errors.New("unknown token")
+	}
+	return &mcp.TokenInfo{Subject: "alice", Scopes: []string{"tools"}}, nil
+}
+
+// revokeAll 吊销全部已签发的令牌
+func (as *fakeAuthServer) revokeAll() {
+	as.mu.Lock()
+	defer as.mu.Unlock()
+	as.issued = make(map[string]bool)
+}
+
+func (as *fakeAuthServer) grantTypes() []string {
+	as.mu.Lock()
+	defer as.mu.Unlock()
+	return append([]string(nil), as.grants...)
+}
+
+func newWhoAmIServer() *mcp.Server {
+	server := newEchoServer()
+	server.AddTool(mcp.ServerTool{
+		Name:        "whoami",
+		InputSchema: map[string]interface{}{"type": "object"},
+		Handler: func(ctx context.Context, args map[string]interface{}) (string, error) {
+			info, ok := mcp.TokenInfoFromContext(ctx)
+			if !ok {
+				return "anonymous", nil
+			}
+			return info.Subject, nil
+		},
+	})
+	return server
+}
+
+func TestBearerAuth_Challenges(t *testing.T) {
+	validator := func(ctx context.Context, token string) (*mcp.TokenInfo, error) {
+		switch token {
+		case "reader":
+			return &mcp.TokenInfo{Subject: "bob", Scopes: []string{"read"}}, nil
+		case "expired":
+			return &mcp.TokenInfo{Scopes: []string{"tools"}, ExpiresAt: time.Now().Add(-time.Minute)}, nil
+		}
+		return nil, errors.New("unknown token")
+	}
+	ts := httptest.NewServer(mcp.NewHTTPHandler(newEchoServer(), mcp.WithHTTPAuth(validator,
+		mcp.WithRequiredScopes("tools"),
+		mcp.WithProtectedResourceMetadata(mcp.ProtectedResourceMetadata{
+			Resource:             "https://mcp.example.com/mcp",
+			AuthorizationServers: []string{"https://auth.example.com"},
+		}),
+	)))
+	defer ts.Close()
+
+	tests := []struct {
+		name   string
+		header string
+		status int
+		want   string
+	}{
+		{"missing", "", http.StatusUnauthorized, "resource_metadata="},
+		{"wrong scheme", "Basic abc", http.StatusUnauthorized, `realm="mcp"`},
+		{"unknown", "Bearer nope", http.StatusUnauthorized, `error="invalid_token"`},
+		{"expired", "Bearer expired", http.StatusUnauthorized, `error="invalid_token"`},
+		{"insufficient scope", "Bearer reader", http.StatusForbidden, `error="insufficient_scope"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodPost, ts.URL+mcp.DefaultHTTPEndpoint, strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"ping"}`))
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("POST error = %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.status {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.status)
+			}
+			if got := resp.Header.Get("WWW-Authenticate"); !strings.HasPrefix(got, "Bearer ") || !strings.Contains(got, tt.want) {
+				t.Errorf("WWW-Authenticate = %q, want it to contain %s", got, tt.want)
+			}
+		})
+	}
+
+	// 受保护资源元数据无需认证
+	resp, err := http.Get(ts.URL + "/.well-known/oauth-protected-resource/mcp")
+	if err != nil {
+		t.Fatalf("GET metadata error = %v", err)
+	}
+	defer resp.Body.Close()
+	var metadata mcp.ProtectedResourceMetadata
+	if err := json.NewDecoder(resp.Body).Decode(&metadata); err != nil || len(metadata.AuthorizationServers) != 1 {
+		t.Errorf("metadata = %+v, %v", metadata, err)
+	}
+}
+
+func TestHTTPTransport_StaticToken(t *testing.T) {
+	ts := httptest.NewServer(mcp.NewHTTPHandler(newWhoAmIServer(), mcp.WithHTTPAuth(mcp.StaticTokenValidator("t0ken"))))
+	defer ts.Close()
+	ctx := context.Background()
+
+	client := mcp.NewClient(mcp.NewHTTPTransport(mcp.HTTPTransportConfig{
+		URL:         ts.URL + mcp.DefaultHTTPEndpoint,
+		TokenSource: mcp.StaticTokenSource("t0ken"),
+	}))
+	defer client.Close()
+	if err := client.Ping(ctx); err != nil {
+		t.Fatalf("Ping() error = %v", err)
+	}
+
+	bad := mcp.NewClient(mcp.NewHTTPTransport(mcp.HTTPTransportConfig{
+		URL:         ts.URL + mcp.DefaultHTTPEndpoint,
+		TokenSource: mcp.StaticTokenSource("wrong"),
+	}))
+	if err := bad.Ping(ctx); !errors.Is(err, mcp.ErrUnauthorized) {
+		t.Errorf("Ping() with wrong token error = %v, want ErrUnauthorized", err)
+	}
+}
+
+func TestOAuthClient_AuthorizationCodeFlow(t *testing.T) {
+	as := newFakeAuthServer(t)
+	mcpServer := httptest.NewServer(mcp.NewHTTPHandler(newWhoAmIServer(), mcp.WithHTTPAuth(as.validate,
+		mcp.WithProtectedResourceMetadata(mcp.ProtectedResourceMetadata{AuthorizationServers: []string{as.URL}}),
+	)))
+	defer mcpServer.Close()
+	ctx := context.Background()
+
+	var saved *mcp.OAuthToken
+	oauth := mcp.NewOAuthClient(mcp.OAuthConfig{
+		ServerURL:   mcpServer.URL + mcp.DefaultHTTPEndpoint,
+		RedirectURI: "http://localhost:8765/callback",
+		Scopes:      []string{"tools"},
+		OnToken:     func(token *mcp.OAuthToken) { saved = token },
+	})
+
+	// 发现授权服务器并动态注册客户端
+	authURL, verifier, err := oauth.AuthorizationURL(ctx, "xyz")
+	if err != nil {
+		t.Fatalf("AuthorizationURL() error = %v", err)
+	}
+	u, _ := url.Parse(authURL)
+	query := u.Query()
+	if !strings.HasPrefix(authURL, as.URL+"/oauth/authorize?") || query.Get("client_id") != "client-1" ||
+		query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "" || query.Get("state") != "xyz" {
+		t.Errorf("AuthorizationURL() = %s", authURL)
+	}
+	if oauth.ClientID() != "client-1" {
+		t.Errorf("ClientID() = %q, want client-1", oauth.ClientID())
+	}
+
+	if _, err := oauth.Exchange(ctx, "bad-code", verifier); err == nil {
+		t.Error("Exchange(bad-code) error = nil, want invalid_grant")
+	}
+	if _, err := oauth.Exchange(ctx, "good-code", verifier); err != nil {
+		t.Fatalf("Exchange() error = %v", err)
+	}
+	if saved == nil || saved.AccessToken == "" {
+		t.Errorf("OnToken not called with the token: %+v", saved)
+	}
+
+	client := mcp.NewClient(mcp.NewHTTPTransport(mcp.HTTPTransportConfig{
+		URL:         mcpServer.URL + mcp.DefaultHTTPEndpoint,
+		TokenSource: oauth,
+	}))
+	defer client.Close()
+	if result, err := client.CallTool(ctx, "whoami", nil); err != nil || result != "alice" {
+		t.Fatalf("CallTool(whoami) = %q, %v, want alice", result, err)
+	}
+
+	// 服务器吊销令牌后返回 401，传输作废令牌、刷新后重试
+	as.revokeAll()
+	if result, err := client.CallTool(ctx, "whoami", nil); err != nil || result != "alice" {
+		t.Fatalf("CallTool(whoami) after revoke = %q, %v, want alice", result, err)
+	}
+	grants := as.grantTypes()
+	if len(grants) != 2 || grants[0] != "authorization_code" || grants[1] != "refresh_token" {
+		t.Errorf("grants = %v, want authorization_code then refresh_token", grants)
+	}
+}
+
+func TestOAuthClient_RefreshesExpiredToken(t *testing.T) {
+	as := newFakeAuthServer(t)
+	ctx := context.Background()
+	oauth := mcp.NewOAuthClient(mcp.OAuthConfig{
+		AuthorizationServer: as.URL,
+		ClientID:            "preregistered",
+		Token:               &mcp.OAuthToken{AccessToken: "old", RefreshToken: "refresh", ExpiresAt: time.Now().Add(-time.Second)},
+	})
+
+	token, err := oauth.Token(ctx)
+	if err != nil || token.AccessToken != "access-1" || token.RefreshToken != "refresh" {
+		t.Fatalf("Token() = %+v, %v, want refreshed token", token, err)
+	}
+	if again, _ := oauth.Token(ctx); again.AccessToken != "access-1" {
+		t.Errorf("Token() = %s, want the cached token", again.AccessToken)
+	}
+	if grants := as.grantTypes(); len(grants) != 1 || grants[0] != "refresh_token" {
+		t.Errorf("grants = %v, want one refresh_token grant", grants)
+	}
+}
+
+func TestOAuthClient_ClientCredentials(t *testing.T) {
+	as := newFakeAuthServer(t)
+	ctx := context.Background()
+
+	oauth := mcp.NewOAuthClient(mcp.OAuthConfig{
+		AuthorizationServer: as.URL,
+		ClientID:            "service",
+		ClientSecret:        "s3cret",
+	})
+	token, err := oauth.Token(ctx)
+	if err != nil || token.AccessToken == "" {
+		t.Fatalf("Token() = %+v, %v", token, err)
+	}
+	if grants := as.grantTypes(); len(grants) != 1 || grants[0] != "client_credentials" {
+		t.Errorf("grants = %v, want client_credentials", grants)
+	}
+
+	public := mcp.NewOAuthClient(mcp.OAuthConfig{AuthorizationServer: as.URL, ClientID: "public"})
+	if _, err := public.Token(ctx); !errors.Is(err, mcp.ErrAuthorizationRequired) {
+		t.Errorf("Token() without credentials error = %v, want ErrAuthorizationRequired", err)
+	}
+}
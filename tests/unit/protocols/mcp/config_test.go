@@ -0,0 +1,111 @@
+package mcp_test
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ahhsitt/helloagents-go/pkg/protocols/mcp"
+)
+
+func TestParseServersConfig(t *testing.T) {
+	t.Setenv("TEST_MCP_TOKEN", "s3cret")
+	config, err := mcp.ParseServersConfig([]byte(`{
+		"mcpServers": {
+			"github": {
+				"command": "npx",
+				"args": ["-y", "@modelcontextprotocol/server-github"],
+				"env": {"GITHUB_PERSONAL_ACCESS_TOKEN": "${TEST_MCP_TOKEN}", "LITERAL": "a$b"}
+			},
+			"remote": {"url": "https://example.com/mcp", "headers": {"Authorization": "Bearer ${TEST_MCP_TOKEN}"}},
+			"socket": {"url": "wss://example.com/mcp"},
+			"old": {"command": "old-server", "disabled": true}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("ParseServersConfig() error = %v", err)
+	}
+
+	if got := strings.Join(config.Names(), ","); got != "github,remote,socket" {
+		t.Errorf("Names() = %q, want enabled servers sorted", got)
+	}
+	for name, want := range map[string]string{
+		"github": mcp.ServerTypeStdio,
+		"remote": mcp.ServerTypeHTTP,
+		"socket": mcp.ServerTypeWebSocket,
+	} {
+		if got := config.MCPServers[name].TransportType(); got != want {
+			t.Errorf("%s TransportType() = %q, want %q", name, got, want)
+		}
+	}
+
+	stdio := config.MCPServers["github"].StdioConfig()
+	if stdio.Command != "npx" || len(stdio.Args) != 2 {
+		t.Errorf("StdioConfig() = %+v", stdio)
+	}
+	if stdio.Env["GITHUB_PERSONAL_ACCESS_TOKEN"] != "s3cret" || stdio.Env["LITERAL"] != "a$b" {
+		t.Errorf("StdioConfig().Env = %v, want ${VAR} expanded only", stdio.Env)
+	}
+}
+
+func TestParseServersConfig_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want string
+	}{
+		{"malformed", `{"mcpServers": [`, "failed to parse"},
+		{"no servers", `{}`, "no mcpServers"},
+		{"missing command", `{"mcpServers": {"a": {"type": "stdio"}}}`, "server a: command is required"},
+		{"missing url", `{"mcpServers": {"a": {"type": "http"}}}`, "server a: url is required"},
+		{"unsupported type", `{"mcpServers": {"a": {"type": "sse", "url": "http://x"}}}`, "unsupported transport type"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := mcp.ParseServersConfig([]byte(tt.json))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ParseServersConfig() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+
+	// 禁用的服务器不校验
+	if _, err := mcp.ParseServersConfig([]byte(`{"mcpServers": {"a": {"type": "stdio", "disabled": true}}}`)); err != nil {
+		t.Errorf("ParseServersConfig() with disabled invalid server error = %v", err)
+	}
+}
+
+func TestNewManagerFromConfig(t *testing.T) {
+	httpServer := httptest.NewServer(mcp.NewHTTPHandler(newEchoServer()))
+	defer httpServer.Close()
+	wsServer := httptest.NewServer(mcp.NewWebSocketHandler(newEchoServer()))
+	defer wsServer.Close()
+
+	path := filepath.Join(t.TempDir(), "claude_desktop_config.json")
+	data := fmt.Sprintf(`{"mcpServers": {"http": {"url": %q}, "ws": {"url": %q}}}`,
+		httpServer.URL+mcp.DefaultHTTPEndpoint, wsURL(wsServer))
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	manager, err := mcp.NewManagerFromConfig(path, mcp.WithHealthCheckInterval(0))
+	if err != nil {
+		t.Fatalf("NewManagerFromConfig() error = %v", err)
+	}
+	defer manager.Close()
+
+	for _, name := range []string{"http", "ws"} {
+		result, err := manager.CallTool(context.Background(), name, "echo", map[string]interface{}{"text": name})
+		if err != nil || result != name {
+			t.Errorf("CallTool(%s) = %q, %v", name, result, err)
+		}
+	}
+
+	if _, err := mcp.LoadServersConfig(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("LoadServersConfig() with missing file error = nil")
+	}
+}
@@ -0,0 +1,180 @@
+package mcp_test
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ahhsitt/helloagents-go/pkg/core/retry"
+	"github.com/ahhsitt/helloagents-go/pkg/protocols/mcp"
+	"github.com/ahhsitt/helloagents-go/pkg/tools"
+)
+
+// newManagedEchoServer 启动 WebSocket 回显服务器，返回处理器和统计拨号次数的传输工厂
+func newManagedEchoServer(t *testing.T) (*mcp.WebSocketHandler, mcp.TransportFactory, *atomic.Int32) {
+	t.Helper()
+	handler := mcp.NewWebSocketHandler(newEchoServer())
+	ts := httptest.NewServer(handler)
+	t.Cleanup(ts.Close)
+
+	dials := &atomic.Int32{}
+	factory := func(ctx context.Context) (mcp.Transport, error) {
+		dials.Add(1)
+		return mcp.NewWebSocketTransport(mcp.WebSocketTransportConfig{URL: wsURL(ts), DisableReconnect: true})
+	}
+	return handler, factory, dials
+}
+
+func newTestManager(t *testing.T) *mcp.Manager {
+	t.Helper()
+	manager := mcp.NewManager(
+		mcp.WithHealthCheckInterval(0),
+		mcp.WithManagerClientOptions(mcp.WithClientRetryPolicy(retry.Policy{MaxAttempts: 1})),
+	)
+	t.Cleanup(func() { manager.Close() })
+	return manager
+}
+
+func TestManager_MergedTools(t *testing.T) {
+	_, factoryA, dialsA := newManagedEchoServer(t)
+	_, factoryB, _ := newManagedEchoServer(t)
+	manager := newTestManager(t)
+	if err := manager.Add("alpha", factoryA); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if err := manager.Add("beta", factoryB); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if err := manager.Add("alpha", factoryB); err == nil {
+		t.Error("Add() duplicate error = nil, want error")
+	}
+
+	// 延迟连接：登记时不拨号
+	if dialsA.Load() != 0 {
+		t.Fatalf("dials after Add = %d, want 0", dialsA.Load())
+	}
+
+	registry := tools.NewRegistry()
+	if err := manager.RegisterTools(context.Background(), registry); err != nil {
+		t.Fatalf("RegisterTools() error = %v", err)
+	}
+	if !registry.Has("alpha_echo") || !registry.Has("beta_echo") || registry.Count() != 2 {
+		t.Fatalf("registry tools = %v, want alpha_echo and beta_echo", registry.List())
+	}
+
+	tool, _ := registry.Get("beta_echo")
+	result, err := tool.Execute(context.Background(), map[string]interface{}{"text": "hi"})
+	if err != nil || result != "hi" {
+		t.Errorf("Execute() = %q, %v, want hi", result, err)
+	}
+	if dialsA.Load() != 1 {
+		t.Errorf("dials = %d, want 1", dialsA.Load())
+	}
+}
+
+func TestManager_SkipsUnavailableServers(t *testing.T) {
+	_, factory, _ := newManagedEchoServer(t)
+	manager := newTestManager(t)
+	manager.Add("ok", factory)
+	manager.Add("down", func(ctx context.Context) (mcp.Transport, error) {
+		return nil, errors.New("connection refused")
+	})
+
+	list, err := manager.Tools(context.Background())
+	if err == nil {
+		t.Error("Tools() error = nil, want the unavailable server's error")
+	}
+	if len(list) != 1 || list[0].Name() != "ok_echo" {
+		t.Errorf("Tools() = %d tools, want ok_echo only", len(list))
+	}
+
+	statuses := manager.Status()
+	if len(statuses) != 2 || statuses[0].Name != "down" || statuses[0].Connected || statuses[0].LastError == nil {
+		t.Errorf("Status() = %+v", statuses)
+	}
+}
+
+func TestManager_HealthCheckReconnects(t *testing.T) {
+	handler, factory, dials := newManagedEchoServer(t)
+	manager := newTestManager(t)
+	manager.Add("echo", factory)
+	ctx := context.Background()
+
+	// 未连接过的服务器不做检查
+	if failures := manager.HealthCheck(ctx); len(failures) != 0 || dials.Load() != 0 {
+		t.Fatalf("HealthCheck() before use = %v, dials %d", failures, dials.Load())
+	}
+	if _, err := manager.CallTool(ctx, "echo", "echo", map[string]interface{}{"text": "a"}); err != nil {
+		t.Fatalf("CallTool() error = %v", err)
+	}
+
+	handler.Close()
+	if failures := manager.HealthCheck(ctx); len(failures) != 0 {
+		t.Fatalf("HealthCheck() = %v, want reconnected", failures)
+	}
+	status := manager.Status()[0]
+	if !status.Connected || status.Reconnects != 1 || dials.Load() != 2 {
+		t.Errorf("Status() = %+v, dials %d, want reconnected once", status, dials.Load())
+	}
+}
+
+func TestManager_CallToolReconnectsOnNextUse(t *testing.T) {
+	handler, factory, _ := newManagedEchoServer(t)
+	manager := newTestManager(t)
+	manager.Add("echo", factory)
+	ctx := context.Background()
+
+	if _, err := manager.CallTool(ctx, "echo", "echo", map[string]interface{}{"text": "a"}); err != nil {
+		t.Fatalf("CallTool() error = %v", err)
+	}
+
+	// 连接断开后的调用失败并断开客户端，下一次调用重新连接
+	handler.Close()
+	if _, err := manager.CallTool(ctx, "echo", "echo", map[string]interface{}{"text": "b"}); err == nil {
+		t.Fatal("CallTool() after disconnect error = nil, want error")
+	}
+	if manager.Status()[0].Connected {
+		t.Error("Status().Connected = true after failed call, want false")
+	}
+	result, err := manager.CallTool(ctx, "echo", "echo", map[string]interface{}{"text": "c"})
+	if err != nil || result != "c" {
+		t.Errorf("CallTool() after reconnect = %q, %v, want c", result, err)
+	}
+}
+
+func TestManager_AsToolProvider(t *testing.T) {
+	_, factoryA, _ := newManagedEchoServer(t)
+	_, factoryB, _ := newManagedEchoServer(t)
+	manager := newTestManager(t)
+	manager.Add("alpha", factoryA)
+
+	registry := tools.NewRegistry()
+	if err := registry.RegisterProvider(context.Background(), manager); err != nil {
+		t.Fatalf("RegisterProvider() error = %v", err)
+	}
+	t.Cleanup(func() { registry.UnregisterProvider(manager.Name()) })
+	if !registry.Has("alpha_echo") || registry.Providers()[0] != mcp.DefaultManagerName {
+		t.Fatalf("registry tools = %v, providers = %v", registry.List(), registry.Providers())
+	}
+
+	// 运行期间登记、移除的服务器自动同步到注册表
+	manager.Add("beta", factoryB)
+	deadline := time.Now().Add(2 * time.Second)
+	for !registry.Has("beta_echo") && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !registry.Has("beta_echo") {
+		t.Fatalf("registry tools = %v, want beta_echo after Add", registry.List())
+	}
+
+	manager.Remove("alpha")
+	for registry.Has("alpha_echo") && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if registry.Has("alpha_echo") {
+		t.Errorf("registry tools = %v, want alpha_echo removed", registry.List())
+	}
+}
@@ -0,0 +1,110 @@
+package mcp_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ahhsitt/helloagents-go/pkg/protocols/mcp"
+	"github.com/ahhsitt/helloagents-go/pkg/tools"
+	"github.com/ahhsitt/helloagents-go/pkg/tools/builtin"
+)
+
+func TestToolManualURI(t *testing.T) {
+	uri := mcp.ToolManualURI("calculator")
+	if uri != "tool://calculator/manual" {
+		t.Errorf("ToolManualURI() = %v, want %v", uri, "tool://calculator/manual")
+	}
+
+	name, ok := mcp.ParseToolManualURI(uri)
+	if !ok || name != "calculator" {
+		t.Errorf("ParseToolManualURI() = %v, %v, want calculator, true", name, ok)
+	}
+
+	if _, ok := mcp.ParseToolManualURI("file:///tmp/manual"); ok {
+		t.Error("ParseToolManualURI() should reject non-tool URIs")
+	}
+}
+
+func TestServerAddToolManuals(t *testing.T) {
+	registry := tools.NewRegistry()
+	registry.MustRegister(builtin.NewCalculator())
+	registry.MustRegister(tools.NewFuncTool(
+		"echo",
+		"Echo the input",
+		tools.ParameterSchema{
+			Type: "object",
+			Properties: map[string]tools.PropertySchema{
+				"text": {Type: "string", Description: "Text to echo"},
+			},
+			Required: []string{"text"},
+		},
+		func(_ context.Context, args map[string]interface{}) (string, error) {
+			return args["text"].(string), nil
+		},
+		tools.WithExamples(tools.ToolExample{
+			Description: "Echo a greeting",
+			Arguments:   map[string]interface{}{"text": "hello"},
+			Output:      "hello",
+		}),
+	))
+
+	server := mcp.NewServer("test-server", "Test")
+	server.AddToolManuals(registry)
+
+	input := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"resources/list"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"resources/read","params":{"uri":"tool://calculator/manual"}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"resources/read","params":{"uri":"tool://echo/manual"}}`,
+	}, "\n")
+
+	var out bytes.Buffer
+	if err := server.Run(context.Background(), strings.NewReader(input), &out); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 responses, got %d", len(lines))
+	}
+
+	var listResp mcp.JSONRPCResponse
+	if err := json.Unmarshal([]byte(lines[0]), &listResp); err != nil {
+		t.Fatalf("failed to parse list response: %v", err)
+	}
+	var list mcp.ListResourcesResult
+	if err := json.Unmarshal(listResp.Result, &list); err != nil {
+		t.Fatalf("failed to parse resources: %v", err)
+	}
+	if len(list.Resources) != 2 {
+		t.Errorf("resource count = %v, want %v", len(list.Resources), 2)
+	}
+
+	for i, want := range []string{"2 + 3 * 4", "Echo a greeting"} {
+		var resp mcp.JSONRPCResponse
+		if err := json.Unmarshal([]byte(lines[i+1]), &resp); err != nil {
+			t.Fatalf("failed to parse read response: %v", err)
+		}
+		if resp.Error != nil {
+			t.Fatalf("unexpected error: %v", resp.Error.Message)
+		}
+		var read mcp.ReadResourceResult
+		if err := json.Unmarshal(resp.Result, &read); err != nil {
+			t.Fatalf("failed to parse read result: %v", err)
+		}
+		if len(read.Contents) != 1 {
+			t.Fatalf("contents count = %v, want 1", len(read.Contents))
+		}
+		if read.Contents[0].MimeType != mcp.ToolManualMimeType {
+			t.Errorf("MimeType = %v, want %v", read.Contents[0].MimeType, mcp.ToolManualMimeType)
+		}
+		if !strings.Contains(read.Contents[0].Text, want) {
+			t.Errorf("manual should contain %q, got:\n%s", want, read.Contents[0].Text)
+		}
+		if !strings.Contains(read.Contents[0].Text, "## Schema") {
+			t.Error("manual should contain schema section")
+		}
+	}
+}
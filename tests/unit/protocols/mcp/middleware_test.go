@@ -0,0 +1,157 @@
+package mcp_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ahhsitt/helloagents-go/pkg/protocols/mcp"
+)
+
+// recordMiddleware 在 next 前后记录 name
+func recordMiddleware(order *[]string, name string) mcp.Middleware {
+	return func(next mcp.Handler) mcp.Handler {
+		return func(ctx context.Context, req *mcp.JSONRPCRequest) (*mcp.JSONRPCResponse, error) {
+			*order = append(*order, name+">")
+			resp, err := next(ctx, req)
+			*order = append(*order, "<"+name)
+			return resp, err
+		}
+	}
+}
+
+func TestServer_UseMiddleware(t *testing.T) {
+	server := newEchoServer()
+	var order []string
+	server.Use(recordMiddleware(&order, "a"), recordMiddleware(&order, "b"))
+	server.Use(func(next mcp.Handler) mcp.Handler {
+		return func(ctx context.Context, req *mcp.JSONRPCRequest) (*mcp.JSONRPCResponse, error) {
+			if req.Method == mcp.MethodListPrompts {
+				return nil, errors.New("prompts disabled")
+			}
+			return next(ctx, req)
+		}
+	})
+	ts := httptest.NewServer(mcp.NewWebSocketHandler(server))
+	defer ts.Close()
+	client, _ := newNotifiedClient(t, ts)
+	ctx := context.Background()
+
+	order = nil
+	if result, err := client.CallTool(ctx, "echo", map[string]interface{}{"text": "hi"}); err != nil || result != "hi" {
+		t.Fatalf("CallTool() = %q, %v", result, err)
+	}
+	if got := strings.Join(order, " "); got != "a> b> <b <a" {
+		t.Errorf("middleware order = %q", got)
+	}
+
+	// 拦截器返回的普通错误转换为内部错误响应
+	if _, err := client.ListPrompts(ctx); err == nil || !strings.Contains(err.Error(), "-32603") {
+		t.Errorf("ListPrompts() error = %v, want internal error", err)
+	}
+}
+
+func TestToolRateLimitMiddleware(t *testing.T) {
+	server := newEchoServer()
+	server.Use(mcp.ToolRateLimitMiddleware(0.001, 2))
+	ts := httptest.NewServer(mcp.NewWebSocketHandler(server))
+	defer ts.Close()
+	client, _ := newNotifiedClient(t, ts)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.CallTool(ctx, "echo", map[string]interface{}{"text": "hi"}); err != nil {
+			t.Fatalf("CallTool() #%d error = %v", i+1, err)
+		}
+	}
+	_, err := client.CallTool(ctx, "echo", map[string]interface{}{"text": "hi"})
+	if err == nil || !strings.Contains(err.Error(), "-32000") || !strings.Contains(err.Error(), "Rate limit exceeded") {
+		t.Errorf("CallTool() over limit error = %v, want rate limit error", err)
+	}
+	// 只限制工具调用
+	if _, err := client.ListTools(ctx); err != nil {
+		t.Errorf("ListTools() error = %v", err)
+	}
+}
+
+func TestToolArgumentsMiddleware(t *testing.T) {
+	server := newEchoServer()
+	server.Use(mcp.ToolArgumentsMiddleware(func(ctx context.Context, tool string, args map[string]interface{}) (map[string]interface{}, error) {
+		text, _ := args["text"].(string)
+		if text == "" {
+			return nil, errors.New("text is required")
+		}
+		args["text"] = strings.ReplaceAll(text, "secret", "[redacted]")
+		return args, nil
+	}))
+	ts := httptest.NewServer(mcp.NewWebSocketHandler(server))
+	defer ts.Close()
+	client, _ := newNotifiedClient(t, ts)
+	ctx := context.Background()
+
+	result, err := client.CallTool(ctx, "echo", map[string]interface{}{"text": "my secret key"})
+	if err != nil || result != "my [redacted] key" {
+		t.Errorf("CallTool() = %q, %v, want redacted text", result, err)
+	}
+	if _, err := client.CallTool(ctx, "echo", nil); err == nil || !strings.Contains(err.Error(), "-32602") {
+		t.Errorf("CallTool() without text error = %v, want invalid params", err)
+	}
+}
+
+func TestClient_Middleware(t *testing.T) {
+	var sent atomic.Int32
+	transport := mcp.NewMemoryTransport(func(request []byte) ([]byte, error) {
+		// 通知（initialized）不经过拦截器
+		if !strings.Contains(string(request), `"id":null`) {
+			sent.Add(1)
+		}
+		return nil, nil
+	})
+
+	var order []string
+	// 不经过传输直接应答全部请求
+	offline := mcp.Middleware(func(next mcp.Handler) mcp.Handler {
+		return func(ctx context.Context, req *mcp.JSONRPCRequest) (*mcp.JSONRPCResponse, error) {
+			return &mcp.JSONRPCResponse{JSONRPC: mcp.JSONRPCVersion, ID: req.ID, Result: []byte(`{}`)}, nil
+		}
+	})
+	client := mcp.NewClient(transport, mcp.WithClientMiddleware(recordMiddleware(&order, "outer"), offline))
+	ctx := context.Background()
+
+	if err := client.Ping(ctx); err != nil {
+		t.Fatalf("Ping() error = %v", err)
+	}
+	if sent.Load() != 0 {
+		t.Errorf("transport requests = %d, want 0", sent.Load())
+	}
+	if got := strings.Join(order, " "); got != "outer> <outer outer> <outer" { // initialize, ping
+		t.Errorf("middleware order = %q", got)
+	}
+}
+
+func TestLoggingMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	server := newEchoServer()
+	server.Use(mcp.LoggingMiddleware(logger))
+	ts := httptest.NewServer(mcp.NewWebSocketHandler(server))
+	defer ts.Close()
+	client, _ := newNotifiedClient(t, ts)
+	ctx := context.Background()
+
+	client.CallTool(ctx, "echo", map[string]interface{}{"text": "hi"})
+	client.CallTool(ctx, "missing", nil)
+
+	logs := buf.String()
+	if !strings.Contains(logs, "level=DEBUG") || !strings.Contains(logs, "tool=echo") {
+		t.Errorf("logs missing successful call:\n%s", logs)
+	}
+	if !strings.Contains(logs, "level=WARN") || !strings.Contains(logs, "tool=missing") || !strings.Contains(logs, "code=-32602") {
+		t.Errorf("logs missing failed call:\n%s", logs)
+	}
+}
@@ -0,0 +1,189 @@
+package mcp_test
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ahhsitt/helloagents-go/pkg/protocols/mcp"
+)
+
+// newProgressServer 返回带有两个工具的服务器：count 报告三次进度后返回，
+// wait 一直阻塞到请求被取消，取消时关闭 cancelled
+func newProgressServer(cancelled chan struct{}) *mcp.Server {
+	server := mcp.NewServer("progress-server", "Progress server")
+	server.AddTool(mcp.ServerTool{
+		Name:        "count",
+		InputSchema: map[string]interface{}{"type": "object"},
+		Handler: func(ctx context.Context, args map[string]interface{}) (string, error) {
+			for i := 1; i <= 3; i++ {
+				if err := mcp.ReportProgress(ctx, float64(i), 3, fmt.Sprintf("step %d", i)); err != nil {
+					return "", err
+				}
+			}
+			return "counted", nil
+		},
+	})
+	server.AddTool(mcp.ServerTool{
+		Name:        "wait",
+		InputSchema: map[string]interface{}{"type": "object"},
+		Handler: func(ctx context.Context, args map[string]interface{}) (string, error) {
+			<-ctx.Done()
+			close(cancelled)
+			return "", ctx.Err()
+		},
+	})
+	return server
+}
+
+// collectProgress 返回进度回调和读取已收到进度的函数
+func collectProgress() (func(mcp.ProgressParams), func() []mcp.ProgressParams) {
+	var mu sync.Mutex
+	var got []mcp.ProgressParams
+	return func(p mcp.ProgressParams) {
+			mu.Lock()
+			defer mu.Unlock()
+			got = append(got, p)
+		}, func() []mcp.ProgressParams {
+			mu.Lock()
+			defer mu.Unlock()
+			return append([]mcp.ProgressParams(nil), got...)
+		}
+}
+
+func checkProgress(t *testing.T, got []mcp.ProgressParams) {
+	t.Helper()
+	if len(got) != 3 {
+		t.Fatalf("progress = %+v, want 3 notifications", got)
+	}
+	for i, p := range got {
+		if p.Progress != float64(i+1) || p.Total != 3 || p.Message != fmt.Sprintf("step %d", i+1) {
+			t.Errorf("progress[%d] = %+v", i, p)
+		}
+	}
+}
+
+func TestClient_CallToolProgress(t *testing.T) {
+	server := newProgressServer(make(chan struct{}))
+	ctx := context.Background()
+
+	t.Run("websocket", func(t *testing.T) {
+		ts := httptest.NewServer(mcp.NewWebSocketHandler(server))
+		defer ts.Close()
+		transport, err := mcp.NewWebSocketTransport(mcp.WebSocketTransportConfig{URL: wsURL(ts)})
+		if err != nil {
+			t.Fatalf("NewWebSocketTransport() error = %v", err)
+		}
+		client := mcp.NewClient(transport)
+		defer client.Close()
+
+		onProgress, progress := collectProgress()
+		result, err := client.CallTool(ctx, "count", nil, mcp.WithProgress(onProgress))
+		if err != nil || result != "counted" {
+			t.Fatalf("CallTool() = %q, %v", result, err)
+		}
+		// 进度通知与响应走同一连接，先于响应到达
+		checkProgress(t, progress())
+	})
+
+	t.Run("streamable http", func(t *testing.T) {
+		ts := httptest.NewServer(mcp.NewHTTPHandler(server))
+		defer ts.Close()
+		client := mcp.NewClient(mcp.NewHTTPTransport(mcp.HTTPTransportConfig{URL: ts.URL + mcp.DefaultHTTPEndpoint}))
+		defer client.Close()
+
+		onProgress, progress := collectProgress()
+		result, err := client.CallTool(ctx, "count", nil, mcp.WithProgress(onProgress))
+		if err != nil || result != "counted" {
+			t.Fatalf("CallTool() = %q, %v", result, err)
+		}
+		checkProgress(t, progress())
+
+		// 不请求进度时工具照常执行
+		if result, err := client.CallTool(ctx, "count", nil); err != nil || result != "counted" {
+			t.Errorf("CallTool() without progress = %q, %v", result, err)
+		}
+	})
+}
+
+func TestClient_CallToolCancel(t *testing.T) {
+	cancelled := make(chan struct{})
+	ts := httptest.NewServer(mcp.NewWebSocketHandler(newProgressServer(cancelled)))
+	defer ts.Close()
+	client, _ := newNotifiedClient(t, ts)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := client.CallTool(ctx, "wait", nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("CallTool() error = %v, want deadline exceeded", err)
+	}
+
+	// 客户端发送取消通知，服务器取消工具执行（连接仍然保持）
+	select {
+	case <-cancelled:
+	case <-time.After(2 * time.Second):
+		t.Fatal("tool was not cancelled")
+	}
+	if err := client.Ping(context.Background()); err != nil {
+		t.Errorf("Ping() after cancel error = %v", err)
+	}
+}
+
+func TestServer_RunStdioProgressAndCancel(t *testing.T) {
+	cancelled := make(chan struct{})
+	server := newProgressServer(cancelled)
+	inReader, inWriter := io.Pipe()
+	outReader, outWriter := io.Pipe()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		server.Run(ctx, inReader, outWriter)
+		outWriter.Close()
+	}()
+	defer inWriter.Close()
+
+	lines := bufio.NewScanner(outReader)
+	readLine := func() string {
+		if !lines.Scan() {
+			t.Fatalf("server output closed: %v", lines.Err())
+		}
+		return lines.Text()
+	}
+
+	io.WriteString(inWriter, `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"count","_meta":{"progressToken":"tok"}}}`+"\n")
+	for i := 1; i <= 3; i++ {
+		line := readLine()
+		if !strings.Contains(line, mcp.NotificationProgress) || !strings.Contains(line, `"progressToken":"tok"`) {
+			t.Fatalf("progress notification %d = %s", i, line)
+		}
+	}
+	if line := readLine(); !strings.Contains(line, `"id":1`) || !strings.Contains(line, "counted") {
+		t.Fatalf("count response = %s", line)
+	}
+
+	// 长时间运行的请求不阻塞后续消息的读取，取消通知可以送达；被取消的请求没有响应
+	io.WriteString(inWriter, `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"wait"}}`+"\n")
+	io.WriteString(inWriter, `{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":2,"reason":"user abort"}}`+"\n")
+	select {
+	case <-cancelled:
+	case <-time.After(2 * time.Second):
+		t.Fatal("tool was not cancelled")
+	}
+	io.WriteString(inWriter, `{"jsonrpc":"2.0","id":3,"method":"ping"}`+"\n")
+	if line := readLine(); !strings.Contains(line, `"id":3`) {
+		t.Errorf("response after cancel = %s, want ping response", line)
+	}
+}
+
+func TestReportProgress_WithoutToken(t *testing.T) {
+	if err := mcp.ReportProgress(context.Background(), 1, 2, "ignored"); err != nil {
+		t.Errorf("ReportProgress() without token error = %v", err)
+	}
+}
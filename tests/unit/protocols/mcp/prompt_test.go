@@ -0,0 +1,82 @@
+package mcp_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ahhsitt/helloagents-go/pkg/protocols/mcp"
+)
+
+func newPromptServer() *mcp.Server {
+	server := mcp.NewServer("prompt-server", "Prompt server")
+	server.AddPrompt(mcp.ServerPrompt{
+		Name:        "translate",
+		Description: "Translate text into a language",
+		Arguments: []mcp.PromptArgument{
+			{Name: "text", Required: true},
+			{Name: "language", Description: "Target language"},
+		},
+		Handler: mcp.TemplatePromptHandler("Translate into {{ language }}: {{text}}"),
+	})
+	server.AddPrompt(mcp.ServerPrompt{
+		Name: "greet",
+		Handler: func(ctx context.Context, args map[string]string) ([]mcp.PromptMessage, error) {
+			return []mcp.PromptMessage{
+				{Role: "user", Content: mcp.Content{Type: "text", Text: "hi"}},
+				{Role: "assistant", Content: mcp.Content{Type: "text", Text: "hello"}},
+			}, nil
+		},
+	})
+	return server
+}
+
+func TestServer_Prompts(t *testing.T) {
+	ts := httptest.NewServer(mcp.NewWebSocketHandler(newPromptServer()))
+	defer ts.Close()
+	client, _ := newNotifiedClient(t, ts)
+	ctx := context.Background()
+
+	prompts, err := client.ListPrompts(ctx)
+	if err != nil || len(prompts) != 2 {
+		t.Fatalf("ListPrompts() = %+v, %v", prompts, err)
+	}
+	if prompts[0].Name != "greet" || prompts[1].Name != "translate" || len(prompts[1].Arguments) != 2 {
+		t.Errorf("ListPrompts() = %+v, want greet and translate sorted by name", prompts)
+	}
+
+	messages, err := client.GetPrompt(ctx, "translate", map[string]string{"text": "{{language}}", "language": "French"})
+	if err != nil || len(messages) != 1 {
+		t.Fatalf("GetPrompt() = %+v, %v", messages, err)
+	}
+	if got := messages[0].Content.Text; got != "Translate into French: {{language}}" {
+		t.Errorf("GetPrompt() text = %q", got)
+	}
+
+	messages, err = client.GetPrompt(ctx, "greet", nil)
+	if err != nil || len(messages) != 2 || messages[1].Role != "assistant" {
+		t.Errorf("GetPrompt(greet) = %+v, %v", messages, err)
+	}
+
+	if _, err := client.GetPrompt(ctx, "translate", map[string]string{"language": "French"}); err == nil {
+		t.Error("GetPrompt() without required argument error = nil, want error")
+	}
+	if _, err := client.GetPrompt(ctx, "missing", nil); err == nil {
+		t.Error("GetPrompt(missing) error = nil, want error")
+	}
+}
+
+func TestServer_PromptsListChanged(t *testing.T) {
+	server := newPromptServer()
+	ts := httptest.NewServer(mcp.NewWebSocketHandler(server))
+	defer ts.Close()
+	client, notifications := newNotifiedClient(t, ts)
+
+	server.RemovePrompt("greet")
+	waitNotification(t, notifications, mcp.NotificationPromptsListChanged)
+
+	prompts, err := client.ListPrompts(context.Background())
+	if err != nil || len(prompts) != 1 {
+		t.Errorf("ListPrompts() after RemovePrompt = %+v, %v", prompts, err)
+	}
+}
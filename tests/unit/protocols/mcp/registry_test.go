@@ -0,0 +1,79 @@
+package mcp_test
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ahhsitt/helloagents-go/pkg/protocols/mcp"
+	"github.com/ahhsitt/helloagents-go/pkg/tools"
+)
+
+func newWeatherRegistry() *tools.Registry {
+	registry := tools.NewRegistry()
+	registry.MustRegister(tools.NewFuncTool(
+		"weather",
+		"Get weather information for a location",
+		tools.ParameterSchema{
+			Type: "object",
+			Properties: map[string]tools.PropertySchema{
+				"location": {Type: "string", Description: "City name"},
+				"unit":     {Type: "string", Enum: []string{"celsius", "fahrenheit"}},
+			},
+			Required: []string{"location"},
+		},
+		func(ctx context.Context, args map[string]interface{}) (string, error) {
+			return fmt.Sprintf("Sunny in %s", args["location"]), nil
+		},
+	))
+	registry.MustRegister(tools.NewFuncTool("fail", "Always fails", tools.ParameterSchema{Type: "object"},
+		func(ctx context.Context, args map[string]interface{}) (string, error) {
+			return "", fmt.Errorf("boom")
+		},
+	))
+	return registry
+}
+
+func TestNewServerFromRegistry(t *testing.T) {
+	ts := httptest.NewServer(mcp.NewWebSocketHandler(mcp.NewServerFromRegistry(newWeatherRegistry())))
+	defer ts.Close()
+	client, _ := newNotifiedClient(t, ts)
+	ctx := context.Background()
+
+	list, err := client.ListTools(ctx)
+	if err != nil || len(list) != 2 {
+		t.Fatalf("ListTools() = %+v, %v", list, err)
+	}
+	var weather mcp.ToolInfo
+	for _, info := range list {
+		if info.Name == "weather" {
+			weather = info
+		}
+	}
+	if weather.Description != "Get weather information for a location" || weather.InputSchema["type"] != "object" {
+		t.Errorf("weather tool = %+v", weather)
+	}
+	props, _ := weather.InputSchema["properties"].(map[string]interface{})
+	location, _ := props["location"].(map[string]interface{})
+	if location["type"] != "string" || location["description"] != "City name" {
+		t.Errorf("location schema = %v", props["location"])
+	}
+	if required, _ := weather.InputSchema["required"].([]interface{}); len(required) != 1 || required[0] != "location" {
+		t.Errorf("required = %v, want [location]", weather.InputSchema["required"])
+	}
+
+	result, err := client.CallTool(ctx, "weather", map[string]interface{}{"location": "Paris"})
+	if err != nil || result != "Sunny in Paris" {
+		t.Errorf("CallTool() = %q, %v", result, err)
+	}
+
+	// 缺少必需参数和执行失败都以工具错误返回
+	if _, err := client.CallTool(ctx, "weather", map[string]interface{}{}); err == nil || !strings.Contains(err.Error(), "location") {
+		t.Errorf("CallTool() without location error = %v, want missing location", err)
+	}
+	if _, err := client.CallTool(ctx, "fail", nil); err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("CallTool(fail) error = %v, want boom", err)
+	}
+}
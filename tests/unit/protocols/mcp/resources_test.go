@@ -0,0 +1,217 @@
+package mcp_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ahhsitt/helloagents-go/pkg/protocols/mcp"
+)
+
+func newWeatherServer(t *testing.T) *mcp.Server {
+	t.Helper()
+	server := mcp.NewServer("weather-server", "Weather server")
+	err := server.AddResourceTemplate(mcp.ServerResourceTemplate{
+		URITemplate: "weather://{city}",
+		Name:        "weather",
+		MimeType:    "text/plain",
+		Handler: func(ctx context.Context, uri string, params map[string]string) (string, error) {
+			return "sunny in " + params["city"], nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("AddResourceTemplate() error = %v", err)
+	}
+	return server
+}
+
+// newNotifiedClient 连接 WebSocket 服务器，服务器通知写入返回的通道
+func newNotifiedClient(t *testing.T, ts *httptest.Server) (*mcp.Client, <-chan mcp.JSONRPCNotification) {
+	t.Helper()
+	notifications := make(chan mcp.JSONRPCNotification, 16)
+	transport, err := mcp.NewWebSocketTransport(mcp.WebSocketTransportConfig{
+		URL: wsURL(ts),
+		OnMessage: func(message []byte) {
+			var n mcp.JSONRPCNotification
+			if json.Unmarshal(message, &n) == nil {
+				notifications <- n
+			}
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewWebSocketTransport() error = %v", err)
+	}
+	client := mcp.NewClient(transport)
+	t.Cleanup(func() { client.Close() })
+	if err := client.Initialize(context.Background()); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+	return client, notifications
+}
+
+func waitNotification(t *testing.T, ch <-chan mcp.JSONRPCNotification, method string) mcp.JSONRPCNotification {
+	t.Helper()
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case n := <-ch:
+			if n.Method == method {
+				return n
+			}
+		case <-timeout:
+			t.Fatalf("no %s notification received", method)
+			return mcp.JSONRPCNotification{}
+		}
+	}
+}
+
+func TestServer_ResourceTemplates(t *testing.T) {
+	server := newWeatherServer(t)
+	err := server.AddResourceTemplate(mcp.ServerResourceTemplate{
+		URITemplate: "file:///{+path}",
+		Handler: func(ctx context.Context, uri string, params map[string]string) (string, error) {
+			return params["path"], nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("AddResourceTemplate() error = %v", err)
+	}
+	ts := httptest.NewServer(mcp.NewWebSocketHandler(server))
+	defer ts.Close()
+	client, _ := newNotifiedClient(t, ts)
+	ctx := context.Background()
+
+	templates, err := client.ListResourceTemplates(ctx)
+	if err != nil || len(templates) != 2 || templates[0].URITemplate != "weather://{city}" {
+		t.Fatalf("ListResourceTemplates() = %+v, %v", templates, err)
+	}
+
+	tests := []struct {
+		uri  string
+		want string
+	}{
+		{"weather://paris", "sunny in paris"},
+		{"weather://new%20york", "sunny in new york"},
+		{"file:///etc/hosts", "etc/hosts"},
+	}
+	for _, tt := range tests {
+		got, err := client.ReadResource(ctx, tt.uri)
+		if err != nil || got != tt.want {
+			t.Errorf("ReadResource(%q) = %q, %v, want %q", tt.uri, got, err, tt.want)
+		}
+	}
+
+	// {city} 不匹配包含 "/" 的片段
+	if _, err := client.ReadResource(ctx, "weather://paris/today"); err == nil {
+		t.Error("ReadResource(weather://paris/today) error = nil, want resource not found")
+	}
+}
+
+func TestServer_AddResourceTemplateInvalid(t *testing.T) {
+	server := mcp.NewServer("s", "")
+	handler := func(ctx context.Context, uri string, params map[string]string) (string, error) { return "", nil }
+	for _, tmpl := range []string{"weather://{city", "weather://city}", "weather://{}"} {
+		if err := server.AddResourceTemplate(mcp.ServerResourceTemplate{URITemplate: tmpl, Handler: handler}); err == nil {
+			t.Errorf("AddResourceTemplate(%q) error = nil, want error", tmpl)
+		}
+	}
+	if err := server.AddResourceTemplate(mcp.ServerResourceTemplate{URITemplate: "weather://{city}"}); err == nil {
+		t.Error("AddResourceTemplate() without handler error = nil, want error")
+	}
+}
+
+func TestServer_ResourceSubscription(t *testing.T) {
+	server := newWeatherServer(t)
+	ts := httptest.NewServer(mcp.NewWebSocketHandler(server))
+	defer ts.Close()
+	client, notifications := newNotifiedClient(t, ts)
+	_, otherNotifications := newNotifiedClient(t, ts)
+	ctx := context.Background()
+
+	if err := client.SubscribeResource(ctx, "weather://unknown/path"); err == nil {
+		t.Error("SubscribeResource(unknown) error = nil, want error")
+	}
+	if err := client.SubscribeResource(ctx, "weather://paris"); err != nil {
+		t.Fatalf("SubscribeResource() error = %v", err)
+	}
+
+	// 只有订阅了该资源的会话收到通知
+	if sent := server.NotifyResourceUpdated("weather://paris"); sent != 1 {
+		t.Errorf("NotifyResourceUpdated() = %d, want 1", sent)
+	}
+	n := waitNotification(t, notifications, mcp.NotificationResourceUpdated)
+	var params mcp.ResourceUpdatedParams
+	if err := json.Unmarshal(n.Params, &params); err != nil || params.URI != "weather://paris" {
+		t.Errorf("updated params = %s, want uri weather://paris", n.Params)
+	}
+	select {
+	case n := <-otherNotifications:
+		t.Errorf("unsubscribed client received %s", n.Method)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if err := client.UnsubscribeResource(ctx, "weather://paris"); err != nil {
+		t.Fatalf("UnsubscribeResource() error = %v", err)
+	}
+	if sent := server.NotifyResourceUpdated("weather://paris"); sent != 0 {
+		t.Errorf("NotifyResourceUpdated() after unsubscribe = %d, want 0", sent)
+	}
+}
+
+func TestServer_ResourcesListChanged(t *testing.T) {
+	server := newWeatherServer(t)
+	ts := httptest.NewServer(mcp.NewWebSocketHandler(server))
+	defer ts.Close()
+	client, notifications := newNotifiedClient(t, ts)
+
+	server.AddResource(mcp.ServerResource{
+		URI:     "config://app",
+		Handler: func(ctx context.Context) (string, error) { return "{}", nil },
+	})
+	waitNotification(t, notifications, mcp.NotificationResourcesListChanged)
+
+	resources, err := client.ListResources(context.Background())
+	if err != nil || len(resources) != 1 {
+		t.Fatalf("ListResources() = %+v, %v", resources, err)
+	}
+
+	server.RemoveResource("config://app")
+	waitNotification(t, notifications, mcp.NotificationResourcesListChanged)
+}
+
+func TestServer_RunStdioNotifications(t *testing.T) {
+	server := newWeatherServer(t)
+	inReader, inWriter := io.Pipe()
+	outReader, outWriter := io.Pipe()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		server.Run(ctx, inReader, outWriter)
+		outWriter.Close()
+	}()
+	defer inWriter.Close()
+
+	lines := bufio.NewScanner(outReader)
+	readLine := func() string {
+		if !lines.Scan() {
+			t.Fatalf("server output closed: %v", lines.Err())
+		}
+		return lines.Text()
+	}
+
+	io.WriteString(inWriter, `{"jsonrpc":"2.0","id":1,"method":"resources/subscribe","params":{"uri":"weather://oslo"}}`+"\n")
+	if line := readLine(); !strings.Contains(line, `"result"`) {
+		t.Fatalf("subscribe response = %s", line)
+	}
+
+	go server.NotifyResourceUpdated("weather://oslo")
+	line := readLine()
+	if !strings.Contains(line, mcp.NotificationResourceUpdated) || strings.Contains(line, `"id"`) {
+		t.Errorf("notification = %s", line)
+	}
+}
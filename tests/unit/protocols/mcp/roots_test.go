@@ -0,0 +1,139 @@
+package mcp_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ahhsitt/helloagents-go/pkg/protocols/mcp"
+)
+
+// newRootsServer 返回带有 roots 工具的服务器，工具返回客户端根目录的 URI；
+// 客户端通知根目录变化时，重新获取的根目录写入 changed
+func newRootsServer(changed chan<- []mcp.Root) *mcp.Server {
+	server := mcp.NewServer("roots-server", "Roots server")
+	server.AddTool(mcp.ServerTool{
+		Name:        "roots",
+		InputSchema: map[string]interface{}{"type": "object"},
+		Handler: func(ctx context.Context, args map[string]interface{}) (string, error) {
+			roots, err := mcp.ListRoots(ctx)
+			if err != nil {
+				return "", err
+			}
+			uris := make([]string, 0, len(roots))
+			for _, root := range roots {
+				uris = append(uris, root.URI)
+			}
+			return strings.Join(uris, ","), nil
+		},
+	})
+	server.SetRootsChangedHandler(func(ctx context.Context) {
+		roots, err := mcp.ListRoots(ctx)
+		if err == nil {
+			changed <- roots
+		}
+	})
+	return server
+}
+
+func TestClient_Roots(t *testing.T) {
+	changed := make(chan []mcp.Root, 1)
+	ts := httptest.NewServer(mcp.NewWebSocketHandler(newRootsServer(changed)))
+	defer ts.Close()
+	ctx := context.Background()
+
+	transport, err := mcp.NewWebSocketTransport(mcp.WebSocketTransportConfig{URL: wsURL(ts)})
+	if err != nil {
+		t.Fatalf("NewWebSocketTransport() error = %v", err)
+	}
+	client := mcp.NewClient(transport, mcp.WithRoots(mcp.Root{URI: "file:///workspace/a", Name: "a"}))
+	defer client.Close()
+
+	if result, err := client.CallTool(ctx, "roots", nil); err != nil || result != "file:///workspace/a" {
+		t.Fatalf("CallTool(roots) = %q, %v", result, err)
+	}
+
+	roots := []mcp.Root{{URI: "file:///workspace/a"}, {URI: "file:///workspace/b"}}
+	if err := client.SetRoots(ctx, roots); err != nil {
+		t.Fatalf("SetRoots() error = %v", err)
+	}
+	select {
+	case got := <-changed:
+		if len(got) != 2 || got[1].URI != "file:///workspace/b" {
+			t.Errorf("roots after change = %+v", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("server was not notified of roots change")
+	}
+	if got := client.Roots(); len(got) != 2 {
+		t.Errorf("Roots() = %+v, want 2 roots", got)
+	}
+}
+
+func TestClient_RootsNotSupported(t *testing.T) {
+	ts := httptest.NewServer(mcp.NewWebSocketHandler(newRootsServer(make(chan []mcp.Root, 1))))
+	defer ts.Close()
+	client, _ := newNotifiedClient(t, ts)
+
+	_, err := client.CallTool(context.Background(), "roots", nil)
+	if err == nil || !strings.Contains(err.Error(), mcp.ErrRootsNotSupported.Error()) {
+		t.Errorf("CallTool(roots) error = %v, want roots not supported", err)
+	}
+}
+
+func TestServer_RunStdioListRoots(t *testing.T) {
+	server := newRootsServer(make(chan []mcp.Root, 1))
+	inReader, inWriter := io.Pipe()
+	outReader, outWriter := io.Pipe()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		server.Run(ctx, inReader, outWriter)
+		outWriter.Close()
+	}()
+	defer inWriter.Close()
+
+	lines := bufio.NewScanner(outReader)
+	readLine := func() string {
+		if !lines.Scan() {
+			t.Fatalf("server output closed: %v", lines.Err())
+		}
+		return lines.Text()
+	}
+
+	io.WriteString(inWriter, `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05","capabilities":{"roots":{"listChanged":true}},"clientInfo":{"name":"test","version":"1"}}}`+"\n")
+	readLine()
+	io.WriteString(inWriter, `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"roots"}}`+"\n")
+
+	// 服务器在处理工具调用时向客户端请求根目录
+	var req mcp.JSONRPCRequest
+	if err := json.Unmarshal([]byte(readLine()), &req); err != nil || req.Method != mcp.MethodListRoots {
+		t.Fatalf("server request = %+v, %v, want roots/list", req, err)
+	}
+	id, _ := json.Marshal(req.ID)
+	io.WriteString(inWriter, `{"jsonrpc":"2.0","id":`+string(id)+`,"result":{"roots":[{"uri":"file:///srv"}]}}`+"\n")
+
+	if line := readLine(); !strings.Contains(line, `"id":2`) || !strings.Contains(line, "file:///srv") {
+		t.Errorf("tools/call response = %s", line)
+	}
+}
+
+func TestFileRoot(t *testing.T) {
+	dir := t.TempDir()
+	root, err := mcp.FileRoot(dir)
+	if err != nil {
+		t.Fatalf("FileRoot() error = %v", err)
+	}
+	if !strings.HasPrefix(root.URI, "file:///") || !strings.HasSuffix(root.URI, filepath.ToSlash(filepath.Base(dir))) {
+		t.Errorf("URI = %q", root.URI)
+	}
+	if root.Name != filepath.Base(dir) {
+		t.Errorf("Name = %q, want %q", root.Name, filepath.Base(dir))
+	}
+}
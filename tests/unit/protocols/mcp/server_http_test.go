@@ -0,0 +1,293 @@
+package mcp_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ahhsitt/helloagents-go/pkg/protocols/mcp"
+)
+
+func newEchoServer() *mcp.Server {
+	server := mcp.NewServer("echo-server", "Echo server")
+	server.AddTool(mcp.ServerTool{
+		Name:        "echo",
+		Description: "Echo the text argument",
+		InputSchema: map[string]interface{}{"type": "object"},
+		Handler: func(ctx context.Context, args map[string]interface{}) (string, error) {
+			text, _ := args["text"].(string)
+			return text, nil
+		},
+	})
+	return server
+}
+
+func postJSON(t *testing.T, url, sessionID, body string) *http.Response {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if sessionID != "" {
+		req.Header.Set(mcp.HeaderSessionID, sessionID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("POST %s error = %v", url, err)
+	}
+	return resp
+}
+
+func TestHTTPHandler_StreamableClient(t *testing.T) {
+	handler := mcp.NewHTTPHandler(newEchoServer())
+	ts := httptest.NewServer(handler)
+	defer ts.Close()
+
+	client := mcp.NewClient(mcp.NewHTTPTransport(mcp.HTTPTransportConfig{URL: ts.URL + mcp.DefaultHTTPEndpoint}))
+	ctx := context.Background()
+	if err := client.Initialize(ctx); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+	if info := client.ServerInfo(); info == nil || info.Name != "echo-server" {
+		t.Errorf("ServerInfo() = %+v", info)
+	}
+	if handler.SessionCount() != 1 {
+		t.Errorf("SessionCount() = %d, want 1", handler.SessionCount())
+	}
+
+	tools, err := client.ListTools(ctx)
+	if err != nil || len(tools) != 1 {
+		t.Fatalf("ListTools() = %v, %v", tools, err)
+	}
+	result, err := client.CallTool(ctx, "echo", map[string]interface{}{"text": "hello"})
+	if err != nil || result != "hello" {
+		t.Errorf("CallTool() = %q, %v, want hello", result, err)
+	}
+
+	if err := client.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if handler.SessionCount() != 0 {
+		t.Errorf("SessionCount() = %d after Close, want 0", handler.SessionCount())
+	}
+}
+
+func TestHTTPHandler_SessionRequired(t *testing.T) {
+	ts := httptest.NewServer(mcp.NewHTTPHandler(newEchoServer()))
+	defer ts.Close()
+	url := ts.URL + mcp.DefaultHTTPEndpoint
+	ping := `{"jsonrpc":"2.0","id":1,"method":"ping"}`
+
+	resp := postJSON(t, url, "", ping)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("missing session status = %d, want 400", resp.StatusCode)
+	}
+	resp = postJSON(t, url, "unknown", ping)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("unknown session status = %d, want 404", resp.StatusCode)
+	}
+
+	resp = postJSON(t, url, "", `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`)
+	resp.Body.Close()
+	sessionID := resp.Header.Get(mcp.HeaderSessionID)
+	if resp.StatusCode != http.StatusOK || sessionID == "" {
+		t.Fatalf("initialize status = %d, session = %q", resp.StatusCode, sessionID)
+	}
+
+	// 只有通知时返回 202
+	resp = postJSON(t, url, sessionID, `{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		t.Errorf("notification status = %d, want 202", resp.StatusCode)
+	}
+
+	// 批量请求返回响应数组
+	resp = postJSON(t, url, sessionID, `[{"jsonrpc":"2.0","id":2,"method":"ping"},{"jsonrpc":"2.0","method":"notifications/initialized"},{"jsonrpc":"2.0","id":3,"method":"tools/list"}]`)
+	var batch []mcp.JSONRPCResponse
+	if err := json.NewDecoder(resp.Body).Decode(&batch); err != nil {
+		t.Fatalf("decode batch response error = %v", err)
+	}
+	resp.Body.Close()
+	if len(batch) != 2 || batch[0].Error != nil || batch[1].Error != nil {
+		t.Errorf("batch responses = %+v, want 2 successful responses", batch)
+	}
+
+	req, _ := http.NewRequest(http.MethodDelete, url, nil)
+	req.Header.Set(mcp.HeaderSessionID, sessionID)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("DELETE error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("DELETE status = %d, want 204", resp.StatusCode)
+	}
+	resp = postJSON(t, url, sessionID, ping)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("deleted session status = %d, want 404", resp.StatusCode)
+	}
+}
+
+// readEvent 读取一个 SSE 事件
+func readEvent(t *testing.T, reader *bufio.Reader) (string, string) {
+	t.Helper()
+	var event, data string
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("read event error = %v", err)
+		}
+		line = strings.TrimRight(line, "\n")
+		switch {
+		case line == "":
+			return event, data
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+func TestHTTPHandler_LegacySSE(t *testing.T) {
+	handler := mcp.NewHTTPHandler(newEchoServer())
+	ts := httptest.NewServer(handler)
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+mcp.DefaultSSEEndpoint, nil)
+	stream, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET /sse error = %v", err)
+	}
+	defer stream.Body.Close()
+	reader := bufio.NewReader(stream.Body)
+
+	event, endpoint := readEvent(t, reader)
+	if event != "endpoint" || !strings.HasPrefix(endpoint, mcp.DefaultSSEMessageEndpoint+"?sessionId=") {
+		t.Fatalf("first event = %q %q, want endpoint", event, endpoint)
+	}
+
+	resp := postJSON(t, ts.URL+endpoint, "", `{"jsonrpc":"2.0","id":7,"method":"tools/call","params":{"name":"echo","arguments":{"text":"hi"}}}`)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("POST message status = %d, want 202", resp.StatusCode)
+	}
+
+	event, data := readEvent(t, reader)
+	if event != "message" {
+		t.Fatalf("event = %q, want message", event)
+	}
+	var rpc mcp.JSONRPCResponse
+	if err := json.Unmarshal([]byte(data), &rpc); err != nil {
+		t.Fatalf("decode message error = %v", err)
+	}
+	var result mcp.CallToolResult
+	if err := json.Unmarshal(rpc.Result, &result); err != nil || len(result.Content) != 1 || result.Content[0].Text != "hi" {
+		t.Errorf("result = %s, want echo of hi", rpc.Result)
+	}
+
+	// 断开事件流后会话被移除
+	cancel()
+	deadline := time.Now().Add(time.Second)
+	for handler.SessionCount() != 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if handler.SessionCount() != 0 {
+		t.Errorf("SessionCount() = %d after disconnect, want 0", handler.SessionCount())
+	}
+}
+
+func TestHTTPHandler_AllowedOrigins(t *testing.T) {
+	ts := httptest.NewServer(mcp.NewHTTPHandler(newEchoServer(), mcp.WithAllowedOrigins("http://localhost:3000")))
+	defer ts.Close()
+
+	for origin, want := range map[string]int{
+		"http://localhost:3000": http.StatusOK,
+		"http://evil.example":   http.StatusForbidden,
+		"":                      http.StatusOK,
+	} {
+		req, _ := http.NewRequest(http.MethodPost, ts.URL+mcp.DefaultHTTPEndpoint,
+			strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`))
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("POST error = %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Errorf("origin %q status = %d, want %d", origin, resp.StatusCode, want)
+		}
+	}
+}
+
+func TestHTTPHandler_DefaultOriginCheck(t *testing.T) {
+	ts := httptest.NewServer(mcp.NewHTTPHandler(newEchoServer()))
+	defer ts.Close()
+	u, _ := url.Parse(ts.URL)
+	rebound := "evil.example:" + u.Port()
+
+	// 未配置允许列表时 Origin 和 Host 都必须是本机地址
+	cases := []struct {
+		origin, host string
+		want         int
+	}{
+		{"http://localhost:3000", "", http.StatusOK},
+		{"http://[::1]:8080", "", http.StatusOK},
+		{ts.URL, "", http.StatusOK},
+		{"http://evil.example", "", http.StatusForbidden},
+		{"null", "", http.StatusForbidden},
+		// DNS 重绑定：浏览器发送的 Host 和 Origin 都是攻击者的域名
+		{"http://" + rebound, rebound, http.StatusForbidden},
+		{"http://localhost:3000", rebound, http.StatusForbidden},
+	}
+	for _, tc := range cases {
+		req, _ := http.NewRequest(http.MethodPost, ts.URL+mcp.DefaultHTTPEndpoint,
+			strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`))
+		req.Header.Set("Origin", tc.origin)
+		if tc.host != "" {
+			req.Host = tc.host
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("POST error = %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tc.want {
+			t.Errorf("origin %q host %q status = %d, want %d", tc.origin, tc.host, resp.StatusCode, tc.want)
+		}
+		if tc.want == http.StatusForbidden && resp.Header.Get("Mcp-Session-Id") != "" {
+			t.Errorf("origin %q host %q created a session", tc.origin, tc.host)
+		}
+	}
+}
+
+func TestServer_RunHTTPStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- newEchoServer().RunHTTP(ctx, "127.0.0.1:0")
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("RunHTTP() error = %v, want context.Canceled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("RunHTTP did not stop after cancel")
+	}
+}
@@ -0,0 +1,221 @@
+package mcp_test
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	coreerrors "github.com/ahhsitt/helloagents-go/pkg/core/errors"
+	"github.com/ahhsitt/helloagents-go/pkg/core/retry"
+	"github.com/ahhsitt/helloagents-go/pkg/protocols/mcp"
+)
+
+func wsURL(ts *httptest.Server) string {
+	return "ws" + strings.TrimPrefix(ts.URL, "http")
+}
+
+func newSlowEchoServer() *mcp.Server {
+	server := newEchoServer()
+	server.AddTool(mcp.ServerTool{
+		Name:        "sleep",
+		Description: "Sleep for ms milliseconds then echo the text argument",
+		InputSchema: map[string]interface{}{"type": "object"},
+		Handler: func(ctx context.Context, args map[string]interface{}) (string, error) {
+			ms, _ := args["ms"].(float64)
+			select {
+			case <-time.After(time.Duration(ms) * time.Millisecond):
+			case <-ctx.Done():
+				return "", ctx.Err()
+			}
+			text, _ := args["text"].(string)
+			return text, nil
+		},
+	})
+	return server
+}
+
+func TestWebSocketTransport_Client(t *testing.T) {
+	ts := httptest.NewServer(mcp.NewWebSocketHandler(newEchoServer()))
+	defer ts.Close()
+
+	transport, err := mcp.NewWebSocketTransport(mcp.WebSocketTransportConfig{URL: wsURL(ts)})
+	if err != nil {
+		t.Fatalf("NewWebSocketTransport() error = %v", err)
+	}
+	client := mcp.NewClient(transport)
+	defer client.Close()
+
+	ctx := context.Background()
+	if err := client.Initialize(ctx); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+	result, err := client.CallTool(ctx, "echo", map[string]interface{}{"text": "hello"})
+	if err != nil || result != "hello" {
+		t.Errorf("CallTool() = %q, %v, want hello", result, err)
+	}
+}
+
+func TestWebSocketTransport_CorrelatesConcurrentRequests(t *testing.T) {
+	ts := httptest.NewServer(mcp.NewWebSocketHandler(newSlowEchoServer()))
+	defer ts.Close()
+
+	transport, err := mcp.NewWebSocketTransport(mcp.WebSocketTransportConfig{URL: wsURL(ts)})
+	if err != nil {
+		t.Fatalf("NewWebSocketTransport() error = %v", err)
+	}
+	defer transport.Close()
+
+	// 先发出的请求耗时更长，响应顺序与请求相反
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			text := fmt.Sprintf("req-%d", i)
+			request, _ := mcp.NewRequest(i+1, mcp.MethodCallTool, mcp.CallToolParams{
+				Name:      "sleep",
+				Arguments: map[string]interface{}{"ms": float64(100 - i*20), "text": text},
+			})
+			data, err := transport.Send(context.Background(), request)
+			if err != nil {
+				t.Errorf("Send(%d) error = %v", i, err)
+				return
+			}
+			if !strings.Contains(string(data), text) {
+				t.Errorf("Send(%d) response = %s, want %s", i, data, text)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestWebSocketTransport_Reconnects(t *testing.T) {
+	handler := mcp.NewWebSocketHandler(newEchoServer())
+	ts := httptest.NewServer(handler)
+	defer ts.Close()
+
+	transport, err := mcp.NewWebSocketTransport(mcp.WebSocketTransportConfig{
+		URL:       wsURL(ts),
+		Reconnect: &retry.Policy{InitialBackoff: 10 * time.Millisecond, MaxBackoff: 50 * time.Millisecond, Multiplier: 2},
+	})
+	if err != nil {
+		t.Fatalf("NewWebSocketTransport() error = %v", err)
+	}
+	client := mcp.NewClient(transport, mcp.WithClientRetryPolicy(retry.Policy{
+		MaxAttempts:    10,
+		InitialBackoff: 20 * time.Millisecond,
+	}))
+	defer client.Close()
+
+	ctx := context.Background()
+	if err := client.Ping(ctx); err != nil {
+		t.Fatalf("Ping() error = %v", err)
+	}
+
+	// 服务器断开全部连接，客户端在后台重连，重连期间的请求由重试策略重试
+	handler.Close()
+	if _, err := client.CallTool(ctx, "echo", map[string]interface{}{"text": "again"}); err != nil {
+		t.Fatalf("CallTool() after disconnect error = %v", err)
+	}
+	if transport.Reconnects() != 1 {
+		t.Errorf("Reconnects() = %d, want 1", transport.Reconnects())
+	}
+}
+
+func TestWebSocketTransport_PongTimeout(t *testing.T) {
+	// 完成握手后不再响应任何帧的服务器
+	silent := make(chan struct{})
+	defer close(silent)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h := sha1.New()
+		h.Write([]byte(r.Header.Get("Sec-WebSocket-Key") + "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"))
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n" +
+			"Sec-WebSocket-Accept: " + base64.StdEncoding.EncodeToString(h.Sum(nil)) + "\r\n\r\n")
+		rw.Flush()
+		<-silent
+	}))
+	defer ts.Close()
+
+	transport, err := mcp.NewWebSocketTransport(mcp.WebSocketTransportConfig{
+		URL:              wsURL(ts),
+		PingInterval:     20 * time.Millisecond,
+		PongTimeout:      20 * time.Millisecond,
+		DisableReconnect: true,
+	})
+	if err != nil {
+		t.Fatalf("NewWebSocketTransport() error = %v", err)
+	}
+	defer transport.Close()
+
+	request, _ := mcp.NewRequest(1, mcp.MethodPing, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	_, err = transport.Send(ctx, request)
+	if !errors.Is(err, coreerrors.ErrProviderUnavailable) {
+		t.Errorf("Send() error = %v, want ErrProviderUnavailable after the pong timeout", err)
+	}
+}
+
+func TestWebSocketHandler_KeepAlive(t *testing.T) {
+	handler := mcp.NewWebSocketHandler(newEchoServer(), mcp.WithWebSocketKeepAlive(10*time.Millisecond, 50*time.Millisecond))
+	ts := httptest.NewServer(handler)
+	defer ts.Close()
+
+	transport, err := mcp.NewWebSocketTransport(mcp.WebSocketTransportConfig{URL: wsURL(ts), PingInterval: -1})
+	if err != nil {
+		t.Fatalf("NewWebSocketTransport() error = %v", err)
+	}
+	defer transport.Close()
+
+	// 客户端自动应答服务器的 ping，空闲连接保持打开
+	time.Sleep(150 * time.Millisecond)
+	if handler.ConnectionCount() != 1 {
+		t.Fatalf("ConnectionCount() = %d, want 1", handler.ConnectionCount())
+	}
+	request, _ := mcp.NewRequest(1, mcp.MethodPing, nil)
+	if _, err := transport.Send(context.Background(), request); err != nil {
+		t.Errorf("Send() error = %v", err)
+	}
+	if transport.Reconnects() != 0 {
+		t.Errorf("Reconnects() = %d, want 0", transport.Reconnects())
+	}
+}
+
+func TestWebSocketHandler_DefaultOriginCheck(t *testing.T) {
+	ts := httptest.NewServer(mcp.NewWebSocketHandler(newEchoServer()))
+	defer ts.Close()
+
+	// 未配置允许列表时拒绝来自其他站点页面的握手（跨站 WebSocket 劫持）
+	for origin, want := range map[string]int{
+		"http://localhost:3000": http.StatusSwitchingProtocols,
+		"http://evil.example":   http.StatusForbidden,
+	} {
+		req, _ := http.NewRequest(http.MethodGet, ts.URL, nil)
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Connection", "Upgrade")
+		req.Header.Set("Upgrade", "websocket")
+		req.Header.Set("Sec-WebSocket-Version", "13")
+		req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("handshake error = %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Errorf("origin %q status = %d, want %d", origin, resp.StatusCode, want)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package rag_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ahhsitt/helloagents-go/pkg/rag"
+)
+
+func newAnswerCachePipeline(t *testing.T, provider *streamProvider, cache *rag.SemanticAnswerCache) *rag.DefaultRAGPipeline {
+	t.Helper()
+	embedder := keywordEmbedder("refund", "shipping")
+	store := rag.NewInMemoryVectorStore()
+	pipeline := rag.NewRAGPipeline(
+		rag.WithEmbedder(embedder),
+		rag.WithStore(store),
+		rag.WithRetriever(rag.NewVectorRetriever(store, embedder)),
+		rag.WithGenerator(rag.NewLLMAnswerGenerator(provider)),
+		rag.WithAnswerCache(cache),
+	)
+	docs := []rag.Document{
+		{ID: "refunds", Content: "Refunds are issued within 14 days.", Metadata: rag.DocumentMetadata{Source: "faq.md"}},
+		{ID: "shipping", Content: "Shipping takes 3 to 5 business days.", Metadata: rag.DocumentMetadata{Source: "faq.md"}},
+	}
+	if err := pipeline.Ingest(context.Background(), docs); err != nil {
+		t.Fatalf("Ingest() error = %v", err)
+	}
+	return pipeline
+}
+
+func TestAnswerCache_ReusesSimilarQuery(t *testing.T) {
+	ctx := context.Background()
+	provider := &streamProvider{tokens: []string{"Within 14 days."}}
+	cache := rag.NewSemanticAnswerCache()
+	pipeline := newAnswerCachePipeline(t, provider, cache)
+
+	first, err := pipeline.Query(ctx, "How do refunds work?", 1)
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if first.Cached {
+		t.Error("first answer should be generated")
+	}
+
+	second, err := pipeline.Query(ctx, "refund timeline", 1)
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if !second.Cached || second.Answer != first.Answer {
+		t.Errorf("second = %+v, want cached answer %q", second, first.Answer)
+	}
+	if len(second.Sources) != 1 || second.Sources[0].DocumentID != "refunds" {
+		t.Errorf("Sources = %+v, want the sources of the cached answer", second.Sources)
+	}
+	if len(provider.prompts) != 1 {
+		t.Errorf("generator called %d times, want 1", len(provider.prompts))
+	}
+
+	if _, err := pipeline.Query(ctx, "shipping time?", 1); err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(provider.prompts) != 2 {
+		t.Errorf("dissimilar query should be generated, generator called %d times", len(provider.prompts))
+	}
+
+	stats := cache.Stats()
+	if stats.Hits != 1 || stats.Misses != 2 || stats.Size != 2 {
+		t.Errorf("Stats() = %+v, want 1 hit, 2 misses, size 2", stats)
+	}
+}
+
+func TestAnswerCache_StaleAfterReingest(t *testing.T) {
+	ctx := context.Background()
+	provider := &streamProvider{tokens: []string{"Within 14 days."}}
+	cache := rag.NewSemanticAnswerCache()
+	pipeline := newAnswerCachePipeline(t, provider, cache)
+
+	if _, err := pipeline.Query(ctx, "refund policy", 1); err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+
+	// 更新退款文档后，检索到的块摄取时间晚于缓存的回答
+	time.Sleep(time.Millisecond)
+	updated := []rag.Document{{ID: "refunds", Content: "Refunds are issued within 30 days.", Metadata: rag.DocumentMetadata{Source: "faq.md"}}}
+	if err := pipeline.Ingest(ctx, updated); err != nil {
+		t.Fatalf("Ingest() error = %v", err)
+	}
+
+	resp, err := pipeline.Query(ctx, "refund policy", 1)
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if resp.Cached {
+		t.Error("answer should be regenerated after the source was re-ingested")
+	}
+	if len(provider.prompts) != 2 || !strings.Contains(provider.prompts[1], "30 days") {
+		t.Errorf("prompts = %q, want a second prompt with the updated content", provider.prompts)
+	}
+	if stats := cache.Stats(); stats.Stale != 1 || stats.Size != 1 {
+		t.Errorf("Stats() = %+v, want 1 stale entry replaced by the new answer", stats)
+	}
+
+	// 存储版本变化后缓存的回答作废，即使更新的是无关文档
+	other := []rag.Document{{ID: "shipping", Content: "Shipping takes 2 business days.", Metadata: rag.DocumentMetadata{Source: "faq.md"}}}
+	if err := pipeline.Ingest(ctx, other); err != nil {
+		t.Fatalf("Ingest() error = %v", err)
+	}
+	if resp, err := pipeline.Query(ctx, "refund policy", 1); err != nil || resp.Cached {
+		t.Errorf("Query() = %+v, %v, want a regenerated answer", resp, err)
+	}
+	if resp, err := pipeline.Query(ctx, "refund policy", 1); err != nil || !resp.Cached {
+		t.Errorf("Query() = %+v, %v, want cached answer while the store is unchanged", resp, err)
+	}
+}
+
+func TestAnswerCache_StaleAfterDelete(t *testing.T) {
+	ctx := context.Background()
+	provider := &streamProvider{tokens: []string{"Within 14 days."}}
+	cache := rag.NewSemanticAnswerCache()
+	pipeline := newAnswerCachePipeline(t, provider, cache)
+
+	if _, err := pipeline.Query(ctx, "refund policy", 1); err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if err := pipeline.DeleteDocument(ctx, "refunds"); err != nil {
+		t.Fatalf("DeleteDocument() error = %v", err)
+	}
+
+	// 回答依据的块已被删除，不能再以它们为来源返回缓存的回答
+	resp, err := pipeline.Query(ctx, "refund policy", 1)
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if resp.Cached {
+		t.Errorf("Query() = %+v, want the cached answer to be discarded after delete", resp)
+	}
+	for _, src := range resp.Sources {
+		if src.DocumentID == "refunds" {
+			t.Errorf("Sources = %+v, want no deleted chunks", resp.Sources)
+		}
+	}
+	if stats := cache.Stats(); stats.Stale != 1 {
+		t.Errorf("Stats() = %+v, want 1 stale entry", stats)
+	}
+}
+
+func TestAnswerCache_QueryStream(t *testing.T) {
+	ctx := context.Background()
+	provider := &streamProvider{tokens: []string{"Within ", "14 days."}}
+	pipeline := newAnswerCachePipeline(t, provider, rag.NewSemanticAnswerCache())
+
+	stream, err := pipeline.QueryStream(ctx, "refund policy", 1)
+	if err != nil {
+		t.Fatalf("QueryStream() error = %v", err)
+	}
+	if _, err := collectAnswer(stream); err != nil {
+		t.Fatalf("stream error = %v", err)
+	}
+
+	stream, err = pipeline.QueryStream(ctx, "refunds?", 1)
+	if err != nil {
+		t.Fatalf("QueryStream() error = %v", err)
+	}
+	chunks, err := collectAnswer(stream)
+	if err != nil {
+		t.Fatalf("stream error = %v", err)
+	}
+	if !stream.Cached || strings.Join(chunks, "") != "Within 14 days." {
+		t.Errorf("cached = %v, chunks = %q, want the full cached answer", stream.Cached, chunks)
+	}
+	if len(provider.prompts) != 1 {
+		t.Errorf("generator called %d times, want 1", len(provider.prompts))
+	}
+}
+
+func TestSemanticAnswerCache_LookupOptions(t *testing.T) {
+	cache := rag.NewSemanticAnswerCache(
+		rag.WithAnswerCacheThreshold(0.9),
+		rag.WithAnswerCacheSize(1),
+		rag.WithAnswerCacheMaxAge(time.Hour),
+	)
+	cache.Store("a", []float32{1, 0}, &rag.RAGResponse{Answer: "A"})
+
+	if _, ok := cache.Lookup([]float32{0.7, 0.7}, nil); ok {
+		t.Error("vector below the threshold should miss")
+	}
+	if resp, ok := cache.Lookup([]float32{1, 0.1}, nil); !ok || resp.Answer != "A" {
+		t.Errorf("Lookup() = %+v, %v, want A", resp, ok)
+	}
+
+	// 容量为 1 时新回答淘汰旧回答
+	cache.Store("b", []float32{0, 1}, &rag.RAGResponse{Answer: "B"})
+	if _, ok := cache.Lookup([]float32{1, 0}, nil); ok {
+		t.Error("evicted answer should miss")
+	}
+
+	future := []rag.RetrievalResult{{Chunk: rag.DocumentChunk{Metadata: rag.DocumentMetadata{IngestedAt: time.Now().Add(time.Minute)}}}}
+	if _, ok := cache.Lookup([]float32{0, 1}, future); ok {
+		t.Error("answer older than the retrieved chunks should be stale")
+	}
+	if stats := cache.Stats(); stats.Stale != 1 || stats.Size != 0 {
+		t.Errorf("Stats() = %+v, want the stale answer removed", stats)
+	}
+
+	cache.Store("c", []float32{1, 0}, &rag.RAGResponse{Answer: "C"})
+	cache.Invalidate()
+	if stats := cache.Stats(); stats.Size != 0 {
+		t.Errorf("Size = %d after Invalidate, want 0", stats.Size)
+	}
+}
@@ -0,0 +1,114 @@
+package rag_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ahhsitt/helloagents-go/pkg/rag"
+)
+
+func bm25Corpus() []rag.DocumentChunk {
+	return []rag.DocumentChunk{
+		{ID: "c1", DocumentID: "d1", Content: "Connection failed with ERR_CONN_42 after timeout", Metadata: rag.DocumentMetadata{Source: "runbook"}},
+		{ID: "c2", DocumentID: "d2", Content: "Retry the connection with exponential backoff", Metadata: rag.DocumentMetadata{Source: "guide"}},
+		{ID: "c3", DocumentID: "d3", Content: "Upgrade to v1.2.3 to fix the memory leak", Metadata: rag.DocumentMetadata{Source: "changelog"}},
+		{ID: "c4", DocumentID: "d4", Content: "数据库连接池配置说明", Metadata: rag.DocumentMetadata{Source: "wiki"}},
+	}
+}
+
+func TestBM25Retriever_ExactIdentifiers(t *testing.T) {
+	ctx := context.Background()
+	r := rag.NewBM25Retriever()
+	_ = r.Add(ctx, bm25Corpus())
+
+	cases := map[string]string{
+		"ERR_CONN_42":         "c1",
+		"err conn 42":         "c1",
+		"v1.2.3":              "c3",
+		"exponential retry":   "c2",
+		"连接池":                 "c4",
+		"what is ERR_CONN_42": "c1",
+	}
+	for query, want := range cases {
+		results, err := r.Retrieve(ctx, query, 1)
+		if err != nil || len(results) != 1 || results[0].Chunk.ID != want {
+			t.Errorf("Retrieve(%q) = %+v, %v, want %s", query, results, err, want)
+		}
+	}
+
+	if results, _ := r.Retrieve(ctx, "kubernetes", 5); len(results) != 0 {
+		t.Errorf("expected no results for an unknown term, got %+v", results)
+	}
+
+	_ = r.Delete(ctx, []string{"c1"})
+	if results, _ := r.Retrieve(ctx, "ERR_CONN_42", 5); len(results) != 0 {
+		t.Errorf("expected deleted chunk to be gone, got %+v", results)
+	}
+	stats, _ := r.DeleteByFilter(ctx, rag.DeleteFilter{Source: "wiki"})
+	if stats.Chunks != 1 || r.Size() != 2 {
+		t.Errorf("DeleteByFilter() = %+v, size %d", stats, r.Size())
+	}
+
+	_ = r.Add(ctx, []rag.DocumentChunk{{ID: "old", Content: "ERR_OLD", Metadata: rag.DocumentMetadata{ExpiresAt: time.Now().Add(-time.Second)}}})
+	if results, _ := r.Retrieve(ctx, "ERR_OLD", 5); len(results) != 0 {
+		t.Errorf("expected expired chunk to be skipped, got %+v", results)
+	}
+}
+
+func TestHybridRetriever(t *testing.T) {
+	ctx := context.Background()
+	keywords := rag.NewBM25Retriever()
+	store := rag.NewKeywordIndexedStore(rag.NewInMemoryVectorStore(), keywords)
+
+	// 向量检索偏向 c2，关键词检索命中 c1
+	chunks := bm25Corpus()
+	vectors := map[string][]float32{"c1": {0, 1}, "c2": {1, 0}, "c3": {0.5, 0.5}, "c4": {0, 1}}
+	for i := range chunks {
+		chunks[i].Vector = vectors[chunks[i].ID]
+	}
+	if err := store.Add(ctx, chunks); err != nil {
+		t.Fatal(err)
+	}
+	if keywords.Size() != 4 {
+		t.Fatalf("expected the keyword index to be populated, got %d", keywords.Size())
+	}
+
+	embedder := &mockEmbedder{embedFn: func(_ context.Context, texts []string) ([][]float32, error) {
+		return [][]float32{{1, 0}}, nil
+	}}
+	dense := rag.NewVectorRetriever(store, embedder)
+
+	results, err := rag.NewHybridRetriever(dense, keywords).Retrieve(ctx, "ERR_CONN_42", 2)
+	if err != nil || len(results) != 2 {
+		t.Fatalf("Retrieve() = %+v, %v", results, err)
+	}
+	ids := map[string]bool{results[0].Chunk.ID: true, results[1].Chunk.ID: true}
+	if !ids["c1"] || !ids["c2"] {
+		t.Errorf("expected both the keyword and the vector hit, got %+v", results)
+	}
+
+	// 加权线性融合偏向关键词
+	results, _ = rag.NewHybridRetriever(dense, keywords,
+		rag.WithHybridFusion(rag.NewLinearFusion()),
+		rag.WithHybridWeights(0.2, 0.8),
+	).Retrieve(ctx, "ERR_CONN_42", 1)
+	if len(results) != 1 || results[0].Chunk.ID != "c1" {
+		t.Errorf("expected keyword-weighted fusion to rank c1 first, got %+v", results)
+	}
+
+	// 一路失败时降级为另一路
+	failing := &mockEmbedder{embedFn: func(context.Context, []string) ([][]float32, error) {
+		return nil, errors.New("embedding service down")
+	}}
+	results, err = rag.NewHybridRetriever(rag.NewVectorRetriever(store, failing), keywords).Retrieve(ctx, "ERR_CONN_42", 1)
+	if err != nil || len(results) != 1 || results[0].Chunk.ID != "c1" {
+		t.Errorf("expected keyword-only fallback, got %+v, %v", results, err)
+	}
+
+	_ = store.Delete(ctx, []string{"c1"})
+	if keywords.Size() != 3 || store.Size() != 3 {
+		t.Errorf("expected deletes to reach both indexes, got %d and %d", keywords.Size(), store.Size())
+	}
+}
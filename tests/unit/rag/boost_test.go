@@ -0,0 +1,59 @@
+package rag_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ahhsitt/helloagents-go/pkg/rag"
+)
+
+func TestRecencyBoost(t *testing.T) {
+	now := time.Now()
+	results := []rag.RetrievalResult{
+		{Chunk: rag.DocumentChunk{ID: "old", Metadata: rag.DocumentMetadata{CreatedAt: now.Add(-30 * 24 * time.Hour)}}, Score: 0.9},
+		{Chunk: rag.DocumentChunk{ID: "new", Metadata: rag.DocumentMetadata{CreatedAt: now.Add(-time.Hour)}}, Score: 0.8},
+		{Chunk: rag.DocumentChunk{ID: "ingested", Metadata: rag.DocumentMetadata{IngestedAt: now.Add(-48 * time.Hour)}}, Score: 0.8},
+		{Chunk: rag.DocumentChunk{ID: "undated"}, Score: 0.85},
+	}
+
+	boosted, _ := rag.NewRecencyBooster(0.3, 0).Process(context.Background(), "q", results)
+	if got := resultIDs(boosted); !equalIDs(got, []string{"new", "ingested", "old", "undated"}) {
+		t.Errorf("order = %v", got)
+	}
+	if results[0].Score != 0.9 {
+		t.Error("input results should not be modified")
+	}
+
+	unchanged, _ := rag.NewRecencyBooster(0, 0).Process(context.Background(), "q", results)
+	if got := resultIDs(unchanged); !equalIDs(got, []string{"old", "undated", "new", "ingested"}) {
+		t.Errorf("zero weight should keep similarity order, got %v", got)
+	}
+}
+
+func TestSourceWeights(t *testing.T) {
+	ctx := context.Background()
+	pipeline := rag.NewRAGPipeline(rag.WithEmbedder(&mockEmbedder{}))
+	_ = pipeline.Ingest(ctx, []rag.Document{
+		{ID: "forum", Content: "a forum answer", Metadata: rag.DocumentMetadata{Source: "https://forum.example.com/t/1"}},
+		{ID: "docs", Content: "the official docs", Metadata: rag.DocumentMetadata{Source: "https://docs.example.com/guide"}},
+		{ID: "handbook", Content: "the handbook", Metadata: rag.DocumentMetadata{Source: "handbook.md"}},
+	})
+	retriever := rag.NewVectorRetriever(pipeline.GetStore(), &mockEmbedder{})
+
+	results, err := retriever.RetrieveWithOptions(ctx, "answer", 3, rag.WithSourceWeights(map[string]float32{
+		"https://docs.example.com/":  2,
+		"https://forum.example.com/": 0.5,
+		"handbook.md":                1.5,
+	}))
+	if err != nil {
+		t.Fatalf("RetrieveWithOptions() error = %v", err)
+	}
+	var docs []string
+	for _, r := range results {
+		docs = append(docs, r.Chunk.DocumentID)
+	}
+	if !equalIDs(docs, []string{"docs", "handbook", "forum"}) {
+		t.Errorf("order = %v", docs)
+	}
+}
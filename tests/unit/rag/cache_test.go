@@ -0,0 +1,103 @@
+package rag_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ahhsitt/helloagents-go/pkg/rag"
+)
+
+func TestQueryCache_HitsAndEviction(t *testing.T) {
+	ctx := context.Background()
+	calls := 0
+	base := rag.RetrieverFunc(func(ctx context.Context, query string, topK int) ([]rag.RetrievalResult, error) {
+		calls++
+		return fixedRetriever(query)(ctx, query, topK)
+	})
+	cache := rag.NewQueryCache(base, rag.WithQueryCacheSize(2))
+
+	first, _ := cache.Retrieve(ctx, "a", 3)
+	first[0].Score = -1
+	again, _ := cache.Retrieve(ctx, "a", 3)
+	if calls != 1 || again[0].Score == -1 {
+		t.Errorf("second lookup should hit an unmodified copy: calls=%d, %+v", calls, again)
+	}
+	_, _ = cache.Retrieve(ctx, "a", 5) // topK 不同视为不同查询
+	_, _ = cache.Retrieve(ctx, "b", 3) // 淘汰最久未使用的 "a"/3
+	_, _ = cache.Retrieve(ctx, "a", 3)
+	if calls != 4 {
+		t.Errorf("calls = %d, want 4", calls)
+	}
+
+	stats := cache.Stats()
+	if stats.Hits != 1 || stats.Misses != 4 || stats.Evictions != 2 || stats.Size != 2 {
+		t.Errorf("Stats() = %+v", stats)
+	}
+	if stats.HitRate() != 0.2 {
+		t.Errorf("HitRate() = %v", stats.HitRate())
+	}
+
+	failing := rag.NewQueryCache(rag.RetrieverFunc(func(context.Context, string, int) ([]rag.RetrievalResult, error) {
+		return nil, errors.New("store down")
+	}))
+	if _, err := failing.Retrieve(ctx, "a", 3); err == nil || failing.Stats().Size != 0 {
+		t.Errorf("errors should propagate without caching: %v, %+v", err, failing.Stats())
+	}
+}
+
+func TestQueryCache_TTL(t *testing.T) {
+	ctx := context.Background()
+	calls := 0
+	base := rag.RetrieverFunc(func(ctx context.Context, query string, topK int) ([]rag.RetrievalResult, error) {
+		calls++
+		return nil, nil
+	})
+	cache := rag.NewQueryCache(base, rag.WithQueryCacheTTL(20*time.Millisecond))
+
+	_, _ = cache.Retrieve(ctx, "q", 1)
+	_, _ = cache.Retrieve(ctx, "q", 1)
+	time.Sleep(30 * time.Millisecond)
+	_, _ = cache.Retrieve(ctx, "q", 1)
+	if calls != 2 {
+		t.Errorf("expired entry should be refetched: calls = %d", calls)
+	}
+}
+
+func TestQueryCache_InvalidatedByIngest(t *testing.T) {
+	ctx := context.Background()
+	embeds := 0
+	embedder := keywordEmbedder("go", "rust")
+	counting := &mockEmbedder{embedFn: func(ctx context.Context, texts []string) ([][]float32, error) {
+		embeds++
+		return embedder.Embed(ctx, texts)
+	}}
+	pipeline := rag.NewRAGPipeline(rag.WithEmbedder(counting))
+	_ = pipeline.Ingest(ctx, []rag.Document{{ID: "go", Content: "Go has goroutines."}})
+
+	store := pipeline.GetStore()
+	cache := rag.NewQueryCache(rag.NewVectorRetriever(store, counting),
+		rag.WithQueryCacheSource(store.(rag.VersionedStore)))
+
+	_, _ = cache.Retrieve(ctx, "rust", 5)
+	embeds = 0
+	results, _ := cache.Retrieve(ctx, "rust", 5)
+	if embeds != 0 || len(results) != 1 {
+		t.Fatalf("cached query should skip the embedder: embeds=%d, %+v", embeds, results)
+	}
+
+	_ = pipeline.Ingest(ctx, []rag.Document{{ID: "rust", Content: "Rust has ownership."}})
+	results, _ = cache.Retrieve(ctx, "rust", 5)
+	if len(results) != 2 || results[0].Chunk.DocumentID != "rust" {
+		t.Errorf("ingest should invalidate the cache, got %+v", results)
+	}
+	if stats := cache.Stats(); stats.Invalidations != 1 || stats.Misses != 2 {
+		t.Errorf("Stats() = %+v", stats)
+	}
+
+	cache.Invalidate()
+	if stats := cache.Stats(); stats.Size != 0 || stats.Invalidations != 2 {
+		t.Errorf("Invalidate() left %+v", stats)
+	}
+}
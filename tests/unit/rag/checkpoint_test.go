@@ -0,0 +1,93 @@
+package rag_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ahhsitt/helloagents-go/pkg/core/retry"
+	"github.com/ahhsitt/helloagents-go/pkg/memory/store"
+	"github.com/ahhsitt/helloagents-go/pkg/rag"
+)
+
+func TestIngest_ResumesFromCheckpoint(t *testing.T) {
+	ctx := context.Background()
+	vectors := rag.NewInMemoryVectorStore()
+	checkpoints := store.NewMemoryDocumentStore()
+	policy := retry.DefaultPolicy()
+	policy.MaxAttempts = 1
+
+	// newPipeline 模拟进程重启：向量存储和检查点保留，增量索引为新的内存索引
+	var calls atomic.Int32
+	newPipeline := func(failAt int32) *rag.DefaultRAGPipeline {
+		calls.Store(0)
+		embedder := &mockEmbedder{embedFn: func(ctx context.Context, texts []string) ([][]float32, error) {
+			if calls.Add(1) == failAt {
+				return nil, errors.New("embedding service crashed")
+			}
+			return (&mockEmbedder{}).Embed(ctx, texts)
+		}}
+		return rag.NewRAGPipeline(
+			rag.WithEmbedder(embedder),
+			rag.WithStore(vectors),
+			rag.WithEmbedBatchSize(1),
+			rag.WithEmbedRetryPolicy(policy),
+			rag.WithIngestCheckpoints(checkpoints, ""),
+		)
+	}
+	docs := manyDocs(3)
+
+	pipeline := newPipeline(3)
+	report, err := pipeline.IngestWithReport(ctx, docs)
+	if err == nil {
+		t.Fatal("expected the third batch to fail")
+	}
+	if report.Batches != 2 || len(report.Failed) != 1 || report.Failed["doc-2"] == "" || len(report.Succeeded) != 0 {
+		t.Errorf("failed run report = %+v", report)
+	}
+	cp, _ := pipeline.IngestCheckpoint(ctx, "doc-2")
+	if cp == nil || cp.Status != rag.IngestStatusFailed || cp.TotalChunks != 1 || len(cp.Completed) != 0 {
+		t.Errorf("doc-2 checkpoint = %+v", cp)
+	}
+	if cp, _ := pipeline.IngestCheckpoint(ctx, "doc-0"); cp == nil || len(cp.Completed) != 1 || cp.Batches != 1 {
+		t.Errorf("doc-0 checkpoint = %+v", cp)
+	}
+
+	pipeline = newPipeline(0)
+	report, err = pipeline.IngestWithReport(ctx, docs)
+	if err != nil {
+		t.Fatalf("resumed IngestWithReport() error = %v", err)
+	}
+	if calls.Load() != 1 || report.ResumedChunks != 2 || report.EmbeddedChunks != 1 || len(report.Succeeded) != 3 {
+		t.Errorf("resume should only embed the missing chunk: calls=%d, %+v", calls.Load(), report)
+	}
+	if cp, _ := pipeline.IngestCheckpoint(ctx, "doc-2"); cp == nil || cp.Status != rag.IngestStatusDone {
+		t.Errorf("doc-2 checkpoint after resume = %+v", cp)
+	}
+
+	// 增量索引再次丢失时，已完成的文档按检查点跳过
+	pipeline = newPipeline(0)
+	report, _ = pipeline.IngestWithReport(ctx, append(docs, docs[0]))
+	if calls.Load() != 0 || len(report.Skipped) != 4 || report.UnchangedDocuments != 4 || vectors.Size() != 3 {
+		t.Errorf("completed documents should be skipped: calls=%d, %+v", calls.Load(), report)
+	}
+
+	_ = pipeline.DeleteDocument(ctx, "doc-1")
+	if cp, _ := pipeline.IngestCheckpoint(ctx, "doc-1"); cp != nil {
+		t.Errorf("DeleteDocument should remove the checkpoint, got %+v", cp)
+	}
+}
+
+func TestIngestWithReport_DuplicateDocuments(t *testing.T) {
+	pipeline := rag.NewRAGPipeline(rag.WithEmbedder(&mockEmbedder{}))
+	doc := rag.Document{ID: "faq", Content: "How do I reset my password?"}
+
+	report, err := pipeline.IngestWithReport(context.Background(), []rag.Document{doc, doc})
+	if err != nil {
+		t.Fatalf("IngestWithReport() error = %v", err)
+	}
+	if !equalIDs(report.Succeeded, []string{"faq"}) || !equalIDs(report.Skipped, []string{"faq"}) || report.EmbeddedChunks != 1 {
+		t.Errorf("report = %+v", report)
+	}
+}
@@ -0,0 +1,81 @@
+package rag_test
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ahhsitt/helloagents-go/pkg/rag"
+)
+
+func TestParseCitations(t *testing.T) {
+	answer := "Go is compiled [1]. It has goroutines.[2][3]\n" +
+		"Channels connect them [1, 2]! Version 1.22 added range over int [9].\n" +
+		"The spec is public.\n" +
+		"[2]"
+
+	got := rag.ParseCitations(answer, 3)
+	want := []rag.CitedSentence{
+		{Text: "Go is compiled.", Sources: []int{0}},
+		{Text: "It has goroutines.", Sources: []int{1, 2}},
+		{Text: "Channels connect them!", Sources: []int{0, 1}},
+		{Text: "Version 1.22 added range over int.", InvalidMarkers: []int{9}, Uncited: true},
+		{Text: "The spec is public.", Sources: []int{1}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ParseCitations() =\n%+v\nwant\n%+v", got, want)
+	}
+
+	chinese := rag.ParseCitations("Go 是编译型语言。[1]它支持并发[2]。没有来源。", 2)
+	if len(chinese) != 3 {
+		t.Fatalf("expected 3 sentences, got %+v", chinese)
+	}
+	if chinese[0].Text != "Go 是编译型语言。" || !reflect.DeepEqual(chinese[0].Sources, []int{0}) {
+		t.Errorf("sentence 0 = %+v", chinese[0])
+	}
+	if !reflect.DeepEqual(chinese[1].Sources, []int{1}) || !chinese[2].Uncited {
+		t.Errorf("sentences = %+v", chinese)
+	}
+}
+
+func TestCitedAnswer_Verification(t *testing.T) {
+	verified := &rag.CitedAnswer{Sentences: rag.ParseCitations("A [1]. B [2].", 2)}
+	if !verified.Verified() || len(verified.Uncited()) != 0 {
+		t.Errorf("expected a fully cited answer to verify: %+v", verified.Sentences)
+	}
+
+	unverified := &rag.CitedAnswer{Sentences: rag.ParseCitations("A [1]. B. C [5].", 2)}
+	if unverified.Verified() {
+		t.Error("expected an answer with uncited sentences to fail verification")
+	}
+	uncited := unverified.Uncited()
+	if len(uncited) != 2 || uncited[0].Text != "B." || uncited[1].Text != "C." {
+		t.Errorf("Uncited() = %+v", uncited)
+	}
+}
+
+func TestPipeline_QueryWithCitations(t *testing.T) {
+	provider := &streamProvider{tokens: []string{"Go is a compiled language [1]. ", "It was released in 2009."}}
+	pipeline := newStreamPipeline(t, rag.NewCitationGenerator(provider))
+
+	resp, err := pipeline.Query(context.Background(), "what is go", 3)
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if !strings.Contains(resp.Answer, "[1]") {
+		t.Errorf("Answer should keep citation markers: %q", resp.Answer)
+	}
+	if len(resp.Citations) != 2 {
+		t.Fatalf("Citations = %+v, want 2 sentences", resp.Citations)
+	}
+	if src := resp.Citations[0].Sources; len(src) != 1 || resp.Sources[src[0]].Source != "go.md" {
+		t.Errorf("first sentence should cite go.md: %+v", resp.Citations[0])
+	}
+	if !resp.Citations[1].Uncited {
+		t.Errorf("second sentence should be flagged as uncited: %+v", resp.Citations[1])
+	}
+	if len(provider.prompts) != 1 || !strings.Contains(provider.prompts[0], "[1] (来源: go.md) Go is a compiled language.") {
+		t.Errorf("prompt should number the context: %q", provider.prompts)
+	}
+}
@@ -0,0 +1,108 @@
+package rag_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ahhsitt/helloagents-go/pkg/rag"
+)
+
+const boilerplate = "Copyright Example Corp. All rights reserved. Subscribe to our newsletter for product updates, release notes and security advisories."
+
+// pageDoc 由正文段落和页脚样板组成的页面
+func pageDoc(id, body string) rag.Document {
+	return rag.Document{ID: id, Content: body + "\n\n" + boilerplate, Metadata: rag.DocumentMetadata{Source: id + ".html", Tags: []string{id}}}
+}
+
+// storedChunks 返回向量存储中的全部块
+func storedChunks(t *testing.T, pipeline *rag.DefaultRAGPipeline) []rag.DocumentChunk {
+	t.Helper()
+	results, err := pipeline.GetStore().Search(context.Background(), []float32{1, 0, 0}, 100)
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	chunks := make([]rag.DocumentChunk, len(results))
+	for i, r := range results {
+		chunks[i] = r.Chunk
+	}
+	return chunks
+}
+
+func TestChunkDeduplicator_Similarity(t *testing.T) {
+	d := rag.NewChunkDeduplicator()
+	near := strings.Replace(boilerplate, "product updates", "product news", 1)
+	if s := d.Similarity(boilerplate, near); s < 0.6 || s == 1 {
+		t.Errorf("near-duplicate similarity = %v", s)
+	}
+	if s := d.Similarity(boilerplate, "Install the CLI with go install and run the setup wizard."); s > 0.1 {
+		t.Errorf("unrelated similarity = %v", s)
+	}
+
+	first := rag.DocumentChunk{ID: "a", Content: boilerplate}
+	if _, dup := d.Check(first); dup {
+		t.Error("first chunk cannot be a duplicate")
+	}
+	if id, dup := d.Check(rag.DocumentChunk{ID: "b", Content: boilerplate + " "}); !dup || id != "a" {
+		t.Errorf("Check() = %q, %v", id, dup)
+	}
+	if stats := d.Stats(); stats.Checked != 2 || stats.Duplicates != 1 || stats.Dropped != 1 {
+		t.Errorf("Stats() = %+v", stats)
+	}
+}
+
+func TestIngest_DedupPolicies(t *testing.T) {
+	ctx := context.Background()
+	docs := []rag.Document{
+		pageDoc("install", "Install the CLI with go install."),
+		pageDoc("config", "Configure the API key in config.yaml."),
+	}
+	newPipeline := func(policy rag.DedupPolicy) *rag.DefaultRAGPipeline {
+		return rag.NewRAGPipeline(
+			rag.WithEmbedder(&mockEmbedder{}),
+			rag.WithChunker(rag.NewRecursiveCharacterChunker(150, 0)),
+			rag.WithDeduplicator(rag.NewChunkDeduplicator(rag.WithDedupPolicy(policy))),
+		)
+	}
+
+	skip := newPipeline(rag.DedupSkip)
+	stats, err := skip.IngestWithStats(ctx, docs)
+	if err != nil {
+		t.Fatalf("IngestWithStats() error = %v", err)
+	}
+	if stats.DuplicateChunks != 1 || stats.DroppedDuplicates != 1 || stats.EmbeddedChunks != 3 || skip.GetStore().Size() != 3 {
+		t.Errorf("skip stats = %+v, store size %d", stats, skip.GetStore().Size())
+	}
+
+	merge := newPipeline(rag.DedupMerge)
+	_, _ = merge.IngestWithStats(ctx, docs)
+	_, _ = merge.IngestWithStats(ctx, []rag.Document{pageDoc("faq", "Frequently asked questions.")})
+	var footer *rag.DocumentChunk
+	for _, c := range storedChunks(t, merge) {
+		if strings.HasPrefix(c.Content, "Copyright") {
+			footer = &c
+		}
+	}
+	if footer == nil || merge.GetStore().Size() != 4 {
+		t.Fatalf("expected one footer chunk, store size %d", merge.GetStore().Size())
+	}
+	sources, _ := footer.Metadata.Custom[rag.MetadataDuplicateSources].([]string)
+	if !equalIDs(sources, []string{"config.html", "faq.html"}) || !equalIDs(footer.Metadata.Tags, []string{"install", "config", "faq"}) {
+		t.Errorf("merged metadata = %v, tags %v", sources, footer.Metadata.Tags)
+	}
+
+	keep := newPipeline(rag.DedupKeep)
+	stats, _ = keep.IngestWithStats(ctx, docs)
+	if stats.DuplicateChunks != 1 || stats.DroppedDuplicates != 0 || keep.GetStore().Size() != 4 {
+		t.Errorf("keep stats = %+v", stats)
+	}
+	tagged := 0
+	for _, c := range storedChunks(t, keep) {
+		if c.Metadata.Custom[rag.MetadataDuplicateOf] != nil {
+			tagged++
+		}
+	}
+	if tagged != 1 {
+		t.Errorf("expected one chunk tagged as duplicate, got %d", tagged)
+	}
+}
@@ -0,0 +1,118 @@
+package rag_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	coreerrors "github.com/ahhsitt/helloagents-go/pkg/core/errors"
+	"github.com/ahhsitt/helloagents-go/pkg/core/retry"
+	"github.com/ahhsitt/helloagents-go/pkg/rag"
+)
+
+func manyDocs(n int) []rag.Document {
+	docs := make([]rag.Document, n)
+	for i := range docs {
+		docs[i] = rag.Document{ID: fmt.Sprintf("doc-%d", i), Content: fmt.Sprintf("document number %d", i)}
+	}
+	return docs
+}
+
+func TestIngest_ParallelEmbedding(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		batches  []int
+		inFlight atomic.Int32
+		peak     atomic.Int32
+		failed   atomic.Bool
+	)
+	embedder := &mockEmbedder{embedFn: func(ctx context.Context, texts []string) ([][]float32, error) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		// 首个批次被限速一次
+		if strings.Contains(texts[0], "number 0") && failed.CompareAndSwap(false, true) {
+			return nil, coreerrors.ErrRateLimited
+		}
+		time.Sleep(5 * time.Millisecond)
+
+		mu.Lock()
+		batches = append(batches, len(texts))
+		mu.Unlock()
+		vectors := make([][]float32, len(texts))
+		for i := range texts {
+			vectors[i] = []float32{1, 0}
+		}
+		return vectors, nil
+	}}
+
+	policy := retry.DefaultPolicy()
+	policy.InitialBackoff = time.Millisecond
+	var progress []int
+	pipeline := rag.NewRAGPipeline(
+		rag.WithEmbedder(embedder),
+		rag.WithEmbedBatchSize(4),
+		rag.WithEmbedConcurrency(3),
+		rag.WithEmbedRetryPolicy(policy),
+		rag.WithIngestProgress(func(embedded, total int) {
+			if total != 10 {
+				t.Errorf("total = %d, want 10", total)
+			}
+			progress = append(progress, embedded)
+		}),
+	)
+
+	stats, err := pipeline.IngestWithStats(context.Background(), manyDocs(10))
+	if err != nil {
+		t.Fatalf("IngestWithStats() error = %v", err)
+	}
+	if stats.EmbeddedChunks != 10 || pipeline.GetStore().Size() != 10 {
+		t.Errorf("embedded %d chunks, store has %d", stats.EmbeddedChunks, pipeline.GetStore().Size())
+	}
+	if len(batches) != 3 || peak.Load() < 2 {
+		t.Errorf("expected 3 batches embedded concurrently, got %v (peak %d)", batches, peak.Load())
+	}
+	if !failed.Load() {
+		t.Error("rate-limited batch should have been retried")
+	}
+	if len(progress) != 3 || progress[2] != 10 {
+		t.Errorf("progress = %v", progress)
+	}
+}
+
+func TestIngest_EmbeddingFailure(t *testing.T) {
+	var calls atomic.Int32
+	embedder := &mockEmbedder{embedFn: func(ctx context.Context, texts []string) ([][]float32, error) {
+		calls.Add(1)
+		return nil, errors.New("bad request")
+	}}
+	pipeline := rag.NewRAGPipeline(rag.WithEmbedder(embedder), rag.WithEmbedBatchSize(2))
+
+	err := pipeline.Ingest(context.Background(), manyDocs(6))
+	if err == nil || !strings.Contains(err.Error(), "bad request") {
+		t.Fatalf("expected the embedding error, got %v", err)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("non-retryable error should stop ingestion after one call, got %d", calls.Load())
+	}
+	if pipeline.GetStore().Size() != 0 {
+		t.Error("nothing should be stored when embedding fails")
+	}
+
+	short := &mockEmbedder{embedFn: func(ctx context.Context, texts []string) ([][]float32, error) {
+		return [][]float32{{1, 0}}, nil
+	}}
+	if err := rag.NewRAGPipeline(rag.WithEmbedder(short)).Ingest(context.Background(), manyDocs(2)); err == nil {
+		t.Error("expected an error when the embedder returns too few vectors")
+	}
+}
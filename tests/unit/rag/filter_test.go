@@ -0,0 +1,138 @@
+package rag_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ahhsitt/helloagents-go/pkg/rag"
+)
+
+// plainStore 隐藏 FilteredSearcher，验证检索器的回退过滤
+type plainStore struct {
+	rag.VectorStore
+}
+
+func filterChunks() []rag.DocumentChunk {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	return []rag.DocumentChunk{
+		{ID: "w1", DocumentID: "wiki-1", Content: "wiki one", Vector: []float32{1, 0}, Metadata: rag.DocumentMetadata{
+			Source: "wiki", Tags: []string{"go", "db"}, CreatedAt: day(1), Custom: map[string]interface{}{"year": 2022},
+		}},
+		{ID: "w2", DocumentID: "wiki-2", Content: "wiki two", Vector: []float32{0.9, 0.1}, Metadata: rag.DocumentMetadata{
+			Source: "wiki", Tags: []string{"go"}, CreatedAt: day(10), Custom: map[string]interface{}{"year": 2024},
+		}},
+		{ID: "b1", DocumentID: "blog-1", Content: "blog one", Vector: []float32{0.95, 0.05}, Metadata: rag.DocumentMetadata{
+			Source: "blog", Tags: []string{"db"}, CreatedAt: day(20), Custom: map[string]interface{}{"year": 2024},
+		}},
+	}
+}
+
+func TestMetadataFilter_Match(t *testing.T) {
+	chunks := filterChunks()
+	tests := []struct {
+		name   string
+		filter rag.MetadataFilter
+		want   []string
+	}{
+		{"eq source", rag.MetadataFilter{rag.Where("source", rag.OpEq, "wiki")}, []string{"w1", "w2"}},
+		{"ne source", rag.MetadataFilter{rag.Where("source", rag.OpNe, "wiki")}, []string{"b1"}},
+		{"in document", rag.MetadataFilter{rag.Where("document_id", rag.OpIn, []string{"wiki-2", "blog-1"})}, []string{"w2", "b1"}},
+		{"tag", rag.MetadataFilter{rag.Where("tags", rag.OpContains, "db")}, []string{"w1", "b1"}},
+		{"tags in", rag.MetadataFilter{rag.Where("tags", rag.OpIn, []string{"x", "go"})}, []string{"w1", "w2"}},
+		{"date range", rag.MetadataFilter{
+			rag.Where("created_at", rag.OpGte, time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC)),
+			rag.Where("created_at", rag.OpLt, time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)),
+		}, []string{"w2"}},
+		{"custom number", rag.MetadataFilter{rag.Where("year", rag.OpGt, 2023.5)}, []string{"w2", "b1"}},
+		{"custom prefix", rag.MetadataFilter{rag.Where("custom.year", rag.OpEq, 2022)}, []string{"w1"}},
+		{"missing field", rag.MetadataFilter{rag.Where("author", rag.OpEq, "x")}, nil},
+		{"and", rag.MetadataFilter{rag.Where("source", rag.OpEq, "wiki"), rag.Where("tags", rag.OpEq, "db")}, []string{"w1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, c := range chunks {
+				if tt.filter.Match(c) {
+					got = append(got, c.ID)
+				}
+			}
+			if !equalIDs(got, tt.want) {
+				t.Errorf("matched %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if err := (rag.MetadataFilter{rag.Where("source", "like", "w")}).Validate(); err == nil {
+		t.Error("expected an error for an unknown operator")
+	}
+	if err := (rag.MetadataFilter{rag.Where("source", rag.OpIn, "wiki")}).Validate(); err == nil {
+		t.Error("expected an error for a non-slice in value")
+	}
+}
+
+func TestVectorRetriever_WithFilter(t *testing.T) {
+	ctx := context.Background()
+	embedder := &mockEmbedder{embedFn: func(_ context.Context, texts []string) ([][]float32, error) {
+		return [][]float32{{1, 0}}, nil
+	}}
+	sqlite := newSQLiteRAGStore(t, filepath.Join(t.TempDir(), "rag.db"))
+	defer sqlite.Close()
+
+	stores := map[string]rag.VectorStore{
+		"memory":   rag.NewInMemoryVectorStore(),
+		"sqlite":   sqlite,
+		"fallback": plainStore{rag.NewInMemoryVectorStore()},
+	}
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			if err := store.Add(ctx, filterChunks()); err != nil {
+				t.Fatalf("Add() error = %v", err)
+			}
+			retriever := rag.NewVectorRetriever(store, embedder)
+
+			results, err := retriever.RetrieveWithOptions(ctx, "q", 5, rag.WithFilter(rag.Where("source", rag.OpEq, "blog")))
+			if err != nil {
+				t.Fatalf("RetrieveWithOptions() error = %v", err)
+			}
+			if got := resultIDs(results); !equalIDs(got, []string{"b1"}) {
+				t.Errorf("source filter returned %v, want [b1]", got)
+			}
+
+			results, err = retriever.RetrieveWithOptions(ctx, "q", 1,
+				rag.WithFilter(rag.Where("source", rag.OpEq, "wiki")),
+				rag.WithFilter(rag.Where("year", rag.OpGte, 2024)))
+			if err != nil {
+				t.Fatalf("RetrieveWithOptions() error = %v", err)
+			}
+			if got := resultIDs(results); !equalIDs(got, []string{"w2"}) {
+				t.Errorf("combined filter returned %v, want [w2]", got)
+			}
+
+			if _, err := retriever.RetrieveWithOptions(ctx, "q", 1, rag.WithFilter(rag.Where("", rag.OpEq, "x"))); err == nil {
+				t.Error("expected an error for an invalid filter")
+			}
+		})
+	}
+}
+
+func resultIDs(results []rag.RetrievalResult) []string {
+	ids := make([]string, len(results))
+	for i, r := range results {
+		ids[i] = r.Chunk.ID
+	}
+	return ids
+}
+
+func equalIDs(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,138 @@
+package rag_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ahhsitt/helloagents-go/pkg/core/llm"
+	"github.com/ahhsitt/helloagents-go/pkg/rag"
+)
+
+// streamProvider implements llm.Provider, streaming a fixed list of tokens
+type streamProvider struct {
+	tokens  []string
+	err     error
+	prompts []string
+}
+
+func (p *streamProvider) Name() string  { return "mock" }
+func (p *streamProvider) Model() string { return "mock-model" }
+func (p *streamProvider) Close() error  { return nil }
+
+func (p *streamProvider) Generate(ctx context.Context, req llm.Request) (llm.Response, error) {
+	p.prompts = append(p.prompts, req.Messages[len(req.Messages)-1].Content)
+	return llm.Response{Content: strings.Join(p.tokens, "")}, p.err
+}
+
+func (p *streamProvider) GenerateStream(ctx context.Context, req llm.Request) (<-chan llm.StreamChunk, <-chan error) {
+	p.prompts = append(p.prompts, req.Messages[len(req.Messages)-1].Content)
+	chunks := make(chan llm.StreamChunk)
+	errs := make(chan error, 1)
+	go func() {
+		defer close(chunks)
+		defer close(errs)
+		for _, tok := range p.tokens {
+			chunks <- llm.StreamChunk{Content: tok}
+		}
+		if p.err != nil {
+			errs <- p.err
+			return
+		}
+		chunks <- llm.StreamChunk{Done: true, FinishReason: "stop"}
+	}()
+	return chunks, errs
+}
+
+func (p *streamProvider) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	return nil, nil
+}
+
+func newStreamPipeline(t *testing.T, generator rag.AnswerGenerator) *rag.DefaultRAGPipeline {
+	t.Helper()
+	embedder := &mockEmbedder{}
+	store := rag.NewInMemoryVectorStore()
+	pipeline := rag.NewRAGPipeline(
+		rag.WithEmbedder(embedder),
+		rag.WithStore(store),
+		rag.WithRetriever(rag.NewVectorRetriever(store, embedder)),
+		rag.WithGenerator(generator),
+	)
+	docs := []rag.Document{{ID: "doc", Content: "Go is a compiled language.", Metadata: rag.DocumentMetadata{Source: "go.md"}}}
+	if err := pipeline.Ingest(context.Background(), docs); err != nil {
+		t.Fatalf("Ingest() error = %v", err)
+	}
+	return pipeline
+}
+
+func collectAnswer(stream *rag.RAGStreamResponse) ([]string, error) {
+	var chunks []string
+	for chunk := range stream.Answer {
+		chunks = append(chunks, chunk)
+	}
+	return chunks, <-stream.Err
+}
+
+func TestPipeline_QueryStream(t *testing.T) {
+	provider := &streamProvider{tokens: []string{"Go ", "is ", "compiled."}}
+	pipeline := newStreamPipeline(t, rag.NewLLMAnswerGenerator(provider))
+
+	stream, err := pipeline.QueryStream(context.Background(), "what is go", 3)
+	if err != nil {
+		t.Fatalf("QueryStream() error = %v", err)
+	}
+	if len(stream.Sources) != 1 || stream.Sources[0].Source != "go.md" {
+		t.Errorf("Sources = %+v, want one source from go.md", stream.Sources)
+	}
+
+	chunks, err := collectAnswer(stream)
+	if err != nil {
+		t.Fatalf("stream error = %v", err)
+	}
+	if strings.Join(chunks, "|") != "Go |is |compiled." {
+		t.Errorf("chunks = %q", chunks)
+	}
+	if len(provider.prompts) != 1 || !strings.Contains(provider.prompts[0], "Go is a compiled language.") ||
+		!strings.Contains(provider.prompts[0], "what is go") {
+		t.Errorf("prompt does not include context and question: %q", provider.prompts)
+	}
+}
+
+func TestPipeline_QueryStreamGenerationError(t *testing.T) {
+	provider := &streamProvider{tokens: []string{"partial"}, err: errors.New("boom")}
+	pipeline := newStreamPipeline(t, rag.NewLLMAnswerGenerator(provider))
+
+	stream, err := pipeline.QueryStream(context.Background(), "q", 1)
+	if err != nil {
+		t.Fatalf("QueryStream() error = %v", err)
+	}
+	chunks, err := collectAnswer(stream)
+	if err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("stream error = %v, want boom", err)
+	}
+	if len(chunks) != 1 || chunks[0] != "partial" {
+		t.Errorf("chunks = %q, want partial output before the error", chunks)
+	}
+}
+
+func TestPipeline_QueryStreamNonStreamingGenerator(t *testing.T) {
+	pipeline := newStreamPipeline(t, &rag.SimpleAnswerGenerator{})
+
+	stream, err := pipeline.QueryStream(context.Background(), "q", 1)
+	if err != nil {
+		t.Fatalf("QueryStream() error = %v", err)
+	}
+	chunks, err := collectAnswer(stream)
+	if err != nil {
+		t.Fatalf("stream error = %v", err)
+	}
+	if len(chunks) != 1 || !strings.Contains(chunks[0], "Go is a compiled language.") {
+		t.Errorf("chunks = %q, want the whole answer as one chunk", chunks)
+	}
+
+	noRetriever := rag.NewRAGPipeline()
+	if _, err := noRetriever.QueryStream(context.Background(), "q", 1); err == nil {
+		t.Error("expected an error without retriever")
+	}
+}
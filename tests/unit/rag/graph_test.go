@@ -0,0 +1,167 @@
+package rag_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ahhsitt/helloagents-go/pkg/memory"
+	"github.com/ahhsitt/helloagents-go/pkg/memory/store"
+	"github.com/ahhsitt/helloagents-go/pkg/rag"
+)
+
+// stubExtractor 识别已知实体名称，并按句子中的关键词给出关系
+type stubExtractor struct {
+	names     []string
+	relations map[string]memory.ExtractedRelation
+}
+
+func (e *stubExtractor) Extract(ctx context.Context, content string) (*memory.ExtractionResult, error) {
+	result := &memory.ExtractionResult{}
+	lower := strings.ToLower(content)
+	for _, name := range e.names {
+		if strings.Contains(lower, strings.ToLower(name)) {
+			result.Entities = append(result.Entities, memory.ExtractedEntity{Name: name, Type: memory.EntityTypeOther})
+		}
+	}
+	for keyword, rel := range e.relations {
+		if strings.Contains(lower, keyword) {
+			result.Relations = append(result.Relations, rel)
+		}
+	}
+	return result, nil
+}
+
+func graphFixture() (*stubExtractor, []rag.Document) {
+	extractor := &stubExtractor{
+		names: []string{"Alice", "Falcon", "Acme", "Berlin"},
+		relations: map[string]memory.ExtractedRelation{
+			"wrote":        {FromEntity: "Falcon", ToEntity: "Alice", RelationType: memory.RelationTypeCreatedBy, Confidence: 0.9},
+			"works at":     {FromEntity: "Alice", ToEntity: "Acme", RelationType: memory.RelationTypeWorksAt, Confidence: 0.9},
+			"headquarters": {FromEntity: "Acme", ToEntity: "Berlin", RelationType: memory.RelationTypeLocatedIn, Confidence: 0.9},
+		},
+	}
+	docs := []rag.Document{
+		{ID: "falcon", Content: "Alice wrote the Falcon library."},
+		{ID: "alice", Content: "Alice works at Acme."},
+		{ID: "acme", Content: "Acme has its headquarters in Berlin."},
+		{ID: "berlin", Content: "Berlin hosts a large tech conference."},
+		{ID: "fruit", Content: "Bananas are yellow."},
+	}
+	return extractor, docs
+}
+
+func TestGraphRetriever_MultiHop(t *testing.T) {
+	ctx := context.Background()
+	extractor, docs := graphFixture()
+	graph := store.NewMemoryGraphStore()
+	indexer := rag.NewGraphIndexer(extractor, graph, rag.WithGraphConcurrency(2))
+	embedder := keywordEmbedder("falcon", "banana")
+	pipeline := rag.NewRAGPipeline(rag.WithEmbedder(embedder), rag.WithGraphIndexer(indexer))
+	if err := pipeline.Ingest(ctx, docs); err != nil {
+		t.Fatalf("Ingest() error = %v", err)
+	}
+
+	stats, _ := graph.GetStats(ctx)
+	if stats.EntityCount != 4 || stats.RelationCount != 3 {
+		t.Fatalf("graph has %d entities and %d relations, want 4 and 3", stats.EntityCount, stats.RelationCount)
+	}
+
+	// 向量检索只命中直接提到 Falcon 的块
+	vector := rag.NewVectorRetriever(pipeline.GetStore(), embedder)
+	base := rag.RetrieverFunc(func(ctx context.Context, query string, topK int) ([]rag.RetrievalResult, error) {
+		return vector.Retrieve(ctx, query, 1)
+	})
+	results, err := rag.NewGraphRetriever(base, indexer).Retrieve(ctx, "Which events happen in the city where the Falcon author works?", 5)
+	if err != nil {
+		t.Fatalf("Retrieve() error = %v", err)
+	}
+	if len(results) != 4 || results[0].Chunk.DocumentID != "falcon" {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+	// Falcon 和 Alice 为起始实体，Alice -> Acme -> Berlin 两跳
+	var city *rag.RetrievalResult
+	for i := range results {
+		if results[i].Chunk.DocumentID == "berlin" {
+			city = &results[i]
+		}
+		if results[i].Chunk.DocumentID == "fruit" {
+			t.Error("unrelated chunk should not be reached through the graph")
+		}
+	}
+	if city == nil {
+		t.Fatalf("two-hop chunk missing from %+v", results)
+	}
+	if city.Chunk.Metadata.Custom[rag.MetadataGraphEntity] != "Berlin" || city.Chunk.Metadata.Custom[rag.MetadataGraphHop] != 2 {
+		t.Errorf("graph metadata = %v", city.Chunk.Metadata.Custom)
+	}
+
+	// 一跳时无法到达
+	results, _ = rag.NewGraphRetriever(base, indexer, rag.WithGraphHops(1)).Retrieve(ctx, "Falcon author", 5)
+	for _, r := range results {
+		if r.Chunk.DocumentID == "berlin" {
+			t.Error("berlin should need two hops")
+		}
+	}
+}
+
+func TestGraphIndexer_Incremental(t *testing.T) {
+	ctx := context.Background()
+	extractor, docs := graphFixture()
+	graph := store.NewMemoryGraphStore()
+	indexer := rag.NewGraphIndexer(extractor, graph)
+	pipeline := rag.NewRAGPipeline(rag.WithEmbedder(keywordEmbedder("falcon")), rag.WithGraphIndexer(indexer))
+	if err := pipeline.Ingest(ctx, docs); err != nil {
+		t.Fatalf("Ingest() error = %v", err)
+	}
+
+	// 重复索引是幂等的
+	_ = indexer.Index(ctx, []rag.DocumentChunk{{ID: "extra", Content: "Alice works at Acme."}})
+	_ = indexer.Index(ctx, []rag.DocumentChunk{{ID: "extra", Content: "Alice works at Acme."}})
+	rels, _ := graph.GetRelations(ctx, entityID(t, graph, "Acme"))
+	for _, rel := range rels {
+		if rel.Type == string(memory.RelationTypeWorksAt) && len(rel.Evidence) != 2 {
+			t.Errorf("works_at evidence = %v, want the document chunk and extra", rel.Evidence)
+		}
+	}
+	if err := indexer.RemoveChunks(ctx, []string{"extra"}); err != nil {
+		t.Fatalf("RemoveChunks() error = %v", err)
+	}
+	rels, _ = graph.GetRelations(ctx, entityID(t, graph, "Acme"))
+	for _, rel := range rels {
+		for _, id := range rel.Evidence {
+			if id == "extra" {
+				t.Errorf("removed chunk still cited by %s", rel.Type)
+			}
+		}
+	}
+
+	// 文档更新后不再陈述的关系被删除
+	docs[2].Content = "Acme sells software."
+	if err := pipeline.Ingest(ctx, docs); err != nil {
+		t.Fatalf("Ingest() error = %v", err)
+	}
+	stats, _ := graph.GetStats(ctx)
+	if stats.RelationCount != 2 || stats.EntityCount != 4 {
+		t.Errorf("graph has %d entities and %d relations, want 4 and 2", stats.EntityCount, stats.RelationCount)
+	}
+
+	if err := pipeline.DeleteDocument(ctx, "falcon"); err != nil {
+		t.Fatalf("DeleteDocument() error = %v", err)
+	}
+	if found, _ := graph.SearchEntities(ctx, "Falcon", "", 10); len(found) != 0 {
+		t.Errorf("Falcon should be removed with its only document, got %+v", found)
+	}
+	if entityID(t, graph, "Alice") == "" {
+		t.Error("Alice is still mentioned by another document")
+	}
+}
+
+func entityID(t *testing.T, graph store.GraphStore, name string) string {
+	t.Helper()
+	found, err := graph.SearchEntities(context.Background(), name, "", 10)
+	if err != nil || len(found) == 0 {
+		return ""
+	}
+	return found[0].ID
+}
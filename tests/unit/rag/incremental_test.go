@@ -0,0 +1,124 @@
+package rag_test
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ahhsitt/helloagents-go/pkg/rag"
+)
+
+// countingEmbedder 记录被嵌入的文本
+func countingEmbedder(embedded *[]string) *mockEmbedder {
+	return &mockEmbedder{embedFn: func(_ context.Context, texts []string) ([][]float32, error) {
+		*embedded = append(*embedded, texts...)
+		vectors := make([][]float32, len(texts))
+		for i := range texts {
+			vectors[i] = []float32{1, 0, 0}
+		}
+		return vectors, nil
+	}}
+}
+
+// paragraphChunker 按空行分块，块 ID 由文档 ID 和位置决定
+type paragraphChunker struct{}
+
+func (paragraphChunker) Chunk(doc rag.Document) []rag.DocumentChunk {
+	var chunks []rag.DocumentChunk
+	for i, part := range strings.Split(doc.Content, "\n\n") {
+		chunks = append(chunks, rag.DocumentChunk{
+			ID:         fmt.Sprintf("%s-%d", doc.ID, i),
+			DocumentID: doc.ID,
+			Content:    part,
+			Index:      i,
+			Metadata:   doc.Metadata,
+		})
+	}
+	return chunks
+}
+
+func TestPipeline_IncrementalIngest(t *testing.T) {
+	ctx := context.Background()
+	var embedded []string
+	pipeline := rag.NewRAGPipeline(
+		rag.WithEmbedder(countingEmbedder(&embedded)),
+		rag.WithChunker(paragraphChunker{}),
+	)
+
+	docs := []rag.Document{
+		{ID: "a", Content: "one\n\ntwo\n\nthree"},
+		{ID: "b", Content: "beta"},
+	}
+	stats, err := pipeline.IngestWithStats(ctx, docs)
+	if err != nil || stats.EmbeddedChunks != 4 || pipeline.GetStore().Size() != 4 {
+		t.Fatalf("first ingest = %+v, %v, size %d", stats, err, pipeline.GetStore().Size())
+	}
+
+	// 重复摄取不重新嵌入
+	embedded = nil
+	stats, _ = pipeline.IngestWithStats(ctx, docs)
+	if stats.UnchangedDocuments != 2 || len(embedded) != 0 {
+		t.Errorf("re-ingest = %+v, embedded %v", stats, embedded)
+	}
+
+	// 只有变化的块被重新嵌入，多余的旧块被删除
+	docs[0].Content = "one\n\nTWO"
+	stats, _ = pipeline.IngestWithStats(ctx, docs)
+	if strings.Join(embedded, ",") != "TWO" || stats.UnchangedChunks != 1 || stats.DeletedChunks != 1 {
+		t.Errorf("changed ingest = %+v, embedded %v", stats, embedded)
+	}
+	if n := pipeline.GetStore().Size(); n != 3 {
+		t.Errorf("Size() = %d, want 3", n)
+	}
+
+	// 元数据变化也视为变化
+	embedded = nil
+	docs[1].Metadata.Title = "Beta"
+	_ = pipeline.Ingest(ctx, docs)
+	if strings.Join(embedded, ",") != "beta" {
+		t.Errorf("expected metadata change to re-embed, got %v", embedded)
+	}
+
+	if err := pipeline.DeleteDocument(ctx, "a"); err != nil {
+		t.Fatalf("DeleteDocument() error = %v", err)
+	}
+	if n := pipeline.GetStore().Size(); n != 1 {
+		t.Errorf("Size() = %d after delete, want 1", n)
+	}
+
+	// 删除后再次摄取视为新文档
+	embedded = nil
+	_ = pipeline.Ingest(ctx, docs[:1])
+	if len(embedded) != 2 {
+		t.Errorf("expected the deleted document to be re-embedded, got %v", embedded)
+	}
+}
+
+func TestFileIngestIndex_Reload(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "index.json")
+	docs := []rag.Document{{ID: "a", Content: "alpha"}}
+
+	index, err := rag.NewFileIngestIndex(path)
+	if err != nil {
+		t.Fatalf("NewFileIngestIndex() error = %v", err)
+	}
+	store := rag.NewInMemoryVectorStore()
+	var embedded []string
+	_ = rag.NewRAGPipeline(rag.WithEmbedder(countingEmbedder(&embedded)), rag.WithStore(store), rag.WithIngestIndex(index)).Ingest(ctx, docs)
+
+	index, err = rag.NewFileIngestIndex(path)
+	if err != nil {
+		t.Fatalf("reload error = %v", err)
+	}
+	if _, ok := index.Get("a"); !ok {
+		t.Fatal("expected the record to survive a reload")
+	}
+	embedded = nil
+	_ = rag.NewRAGPipeline(rag.WithEmbedder(countingEmbedder(&embedded)), rag.WithStore(store), rag.WithIngestIndex(index)).Ingest(ctx, docs)
+	if len(embedded) != 0 {
+		t.Errorf("expected no embedding after reload, got %v", embedded)
+	}
+}
@@ -0,0 +1,269 @@
+package rag_test
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/ahhsitt/helloagents-go/pkg/rag"
+)
+
+func TestMarkdownLoader_FrontMatter(t *testing.T) {
+	input := `---
+title: "Deploy Guide"
+author: Alice
+tags: [ops, deploy]
+date: 2024-03-01
+version: 2
+owners:
+  - sre
+  - platform
+---
+
+# Ignored Heading
+
+Run the deploy script.
+`
+	docs, err := rag.NewMarkdownLoader("guide.md", strings.NewReader(input)).Load(context.Background())
+	if err != nil || len(docs) != 1 {
+		t.Fatalf("Load() = %v, %v", docs, err)
+	}
+	doc := docs[0]
+	if doc.Metadata.Title != "Deploy Guide" || doc.Metadata.Author != "Alice" || doc.Metadata.Source != "guide.md" {
+		t.Errorf("unexpected metadata: %+v", doc.Metadata)
+	}
+	if strings.Join(doc.Metadata.Tags, ",") != "ops,deploy" || doc.Metadata.CreatedAt.Year() != 2024 {
+		t.Errorf("unexpected tags or date: %v %v", doc.Metadata.Tags, doc.Metadata.CreatedAt)
+	}
+	if doc.Metadata.Custom["version"] != int64(2) || fmt.Sprint(doc.Metadata.Custom["owners"]) != "[sre platform]" {
+		t.Errorf("unexpected custom metadata: %v", doc.Metadata.Custom)
+	}
+	if strings.Contains(doc.Content, "---") || !strings.HasPrefix(doc.Content, "# Ignored Heading") {
+		t.Errorf("expected front matter to be stripped, got %q", doc.Content)
+	}
+
+	// 没有 front matter 时使用一级标题
+	docs, _ = rag.NewMarkdownLoader("notes.md", strings.NewReader("intro\n# Notes\nbody")).Load(context.Background())
+	if docs[0].Metadata.Title != "Notes" || docs[0].Content != "intro\n# Notes\nbody" {
+		t.Errorf("unexpected document without front matter: %+v", docs[0])
+	}
+}
+
+func TestHTMLLoader_StripsBoilerplate(t *testing.T) {
+	input := `<!DOCTYPE html>
+<html><head><title>Release Notes</title><meta name="author" content="Bob"><style>p{}</style></head>
+<body>
+<nav><a href="/">Home</a></nav>
+<div class="cookie-banner">We use cookies</div>
+<main>
+  <h1>Version 2.0</h1>
+  <p>Adds   streaming
+  support.</p>
+  <script>track()</script>
+  <ul><li>Faster</li><li>Smaller</li></ul>
+  <div id="sidebar">Related posts</div>
+</main>
+<footer>Copyright</footer>
+</body></html>`
+
+	docs, err := rag.NewHTMLLoader("notes.html", strings.NewReader(input)).Load(context.Background())
+	if err != nil || len(docs) != 1 {
+		t.Fatalf("Load() = %v, %v", docs, err)
+	}
+	doc := docs[0]
+	if doc.Metadata.Title != "Release Notes" || doc.Metadata.Author != "Bob" {
+		t.Errorf("unexpected metadata: %+v", doc.Metadata)
+	}
+	want := "Version 2.0\n\nAdds streaming support.\n\nFaster\n\nSmaller"
+	if doc.Content != want {
+		t.Errorf("Content = %q, want %q", doc.Content, want)
+	}
+}
+
+// buildPDF 构造一个每页一个 FlateDecode 内容流的最小 PDF
+func buildPDF(title string, pages ...string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	fmt.Fprintf(&buf, "1 0 obj\n<< /Title (%s) /Author (Carol) >>\nendobj\n", title)
+	for i, content := range pages {
+		var z bytes.Buffer
+		zw := zlib.NewWriter(&z)
+		_, _ = zw.Write([]byte(content))
+		_ = zw.Close()
+		fmt.Fprintf(&buf, "%d 0 obj\n<< /Length %d /Filter /FlateDecode >>\nstream\n", i+2, z.Len())
+		buf.Write(z.Bytes())
+		buf.WriteString("\nendstream\nendobj\n")
+	}
+	buf.WriteString("trailer\n<< /Info 1 0 R >>\n%%EOF\n")
+	return buf.Bytes()
+}
+
+func TestPDFLoader(t *testing.T) {
+	data := buildPDF("Handbook",
+		"BT /F1 12 Tf 72 720 Td (Hello, \\(PDF\\) world) Tj 0 -14 Td [(Second) -300 (line)] TJ ET",
+		"BT /F1 12 Tf <FEFF00500061006700650020> Tj (two) Tj ET",
+	)
+
+	docs, err := rag.NewPDFLoader("handbook.pdf", bytes.NewReader(data)).Load(context.Background())
+	if err != nil || len(docs) != 1 {
+		t.Fatalf("Load() = %v, %v", docs, err)
+	}
+	if docs[0].Metadata.Title != "Handbook" || docs[0].Metadata.Author != "Carol" {
+		t.Errorf("unexpected metadata: %+v", docs[0].Metadata)
+	}
+	want := "Hello, (PDF) world\nSecond line\n\nPage two"
+	if docs[0].Content != want {
+		t.Errorf("Content = %q, want %q", docs[0].Content, want)
+	}
+
+	docs, err = rag.NewPDFLoader("handbook.pdf", bytes.NewReader(data), rag.WithPDFSplitPages()).Load(context.Background())
+	if err != nil || len(docs) != 2 || docs[1].Metadata.Custom["page"] != 2 || docs[1].Content != "Page two" {
+		t.Fatalf("expected one document per page, got %+v, %v", docs, err)
+	}
+
+	if _, err := rag.NewPDFLoader("x.pdf", strings.NewReader("plain text")).Load(context.Background()); err == nil {
+		t.Error("expected an error for a non-pdf input")
+	}
+}
+
+func TestCSVLoader(t *testing.T) {
+	input := "title,body,category,tags\nReset password,Use the account page,account,faq;auth\nEmpty,,misc,\n"
+	docs, err := rag.NewCSVLoader("faq.csv", strings.NewReader(input),
+		rag.WithCSVContentColumns("title", "body"),
+		rag.WithCSVMetadataColumns("category"),
+	).Load(context.Background())
+	if err != nil || len(docs) != 2 {
+		t.Fatalf("Load() = %v, %v", docs, err)
+	}
+	if docs[0].Content != "title: Reset password\nbody: Use the account page" {
+		t.Errorf("unexpected content: %q", docs[0].Content)
+	}
+	if docs[0].Metadata.Title != "Reset password" || docs[0].Metadata.Custom["category"] != "account" ||
+		docs[0].Metadata.Custom["row"] != 1 || strings.Join(docs[0].Metadata.Tags, ",") != "faq,auth" {
+		t.Errorf("unexpected metadata: %+v", docs[0].Metadata)
+	}
+}
+
+func TestJSONLLoader(t *testing.T) {
+	input := `{"id": "a", "text": "first", "title": "A", "tags": ["x"], "lang": "en"}
+
+{"id": "b", "summary": "no content"}
+{"content": "third", "source": "wiki"}
+`
+	docs, err := rag.NewJSONLLoader("data.jsonl", strings.NewReader(input)).Load(context.Background())
+	if err != nil || len(docs) != 2 {
+		t.Fatalf("Load() = %v, %v", docs, err)
+	}
+	if docs[0].ID != "a" || docs[0].Content != "first" || docs[0].Metadata.Title != "A" ||
+		docs[0].Metadata.Tags[0] != "x" || docs[0].Metadata.Custom["lang"] != "en" || docs[0].Metadata.Source != "data.jsonl" {
+		t.Errorf("unexpected first document: %+v", docs[0])
+	}
+	if docs[1].Content != "third" || docs[1].Metadata.Source != "wiki" {
+		t.Errorf("unexpected second document: %+v", docs[1])
+	}
+
+	if _, err := rag.NewJSONLLoader("bad.jsonl", strings.NewReader("{bad")).Load(context.Background()); err == nil {
+		t.Error("expected an error for an invalid line")
+	}
+}
+
+func TestDirectoryLoader(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"readme.md":             "# Readme\ncontent",
+		"notes.txt":             "plain notes",
+		"docs/guide.md":         "# Guide\nguide body",
+		"docs/drafts/wip.md":    "# WIP",
+		"docs/page.html":        "<html><body><p>page body</p></body></html>",
+		"data/rows.jsonl":       `{"content": "row"}`,
+		"image.png":             "binary",
+		".git/config.txt":       "hidden",
+		"docs/deep/nested.txt":  "nested",
+		"docs/deep/skip.log.md": "# skipped by exclude",
+	}
+	for name, content := range files {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sources := func(docs []rag.Document) []string {
+		var rels []string
+		for _, doc := range docs {
+			rel, _ := filepath.Rel(root, doc.Metadata.Source)
+			rels = append(rels, filepath.ToSlash(rel))
+		}
+		sort.Strings(rels)
+		return rels
+	}
+	ctx := context.Background()
+
+	docs, err := rag.NewDirectoryLoader(root).Load(ctx)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	got := strings.Join(sources(docs), ",")
+	want := "data/rows.jsonl,docs/deep/nested.txt,docs/deep/skip.log.md,docs/drafts/wip.md,docs/guide.md,docs/page.html,notes.txt,readme.md"
+	if got != want {
+		t.Errorf("sources = %s, want %s", got, want)
+	}
+	for _, doc := range docs {
+		if doc.Metadata.CreatedAt.IsZero() {
+			t.Errorf("expected file modification time for %s", doc.Metadata.Source)
+		}
+	}
+
+	docs, _ = rag.NewDirectoryLoader(root,
+		rag.WithGlob("docs/**/*.md"),
+		rag.WithExcludeGlob("drafts", "*.log.md"),
+	).Load(ctx)
+	if got := strings.Join(sources(docs), ","); got != "docs/guide.md" {
+		t.Errorf("glob sources = %s", got)
+	}
+
+	docs, _ = rag.NewDirectoryLoader(root, rag.WithRecursive(false), rag.WithGlob("*.txt", "*.md")).Load(ctx)
+	if got := strings.Join(sources(docs), ","); got != "notes.txt,readme.md" {
+		t.Errorf("non-recursive sources = %s", got)
+	}
+
+	// 自定义加载器和跳过错误
+	broken := filepath.Join(root, "broken.pdf")
+	_ = os.WriteFile(broken, []byte("not a pdf"), 0o644)
+	if _, err := rag.NewDirectoryLoader(root, rag.WithGlob("*.pdf")).Load(ctx); err == nil {
+		t.Error("expected an error for the broken pdf")
+	}
+	loader := rag.NewDirectoryLoader(root, rag.WithGlob("*.pdf", "*.png"), rag.WithSkipErrors(),
+		rag.WithLoaderFor(".png", func(source string, _ io.Reader) rag.DocumentLoader {
+			return rag.NewStringLoader("image placeholder", rag.DocumentMetadata{Source: source})
+		}),
+	)
+	docs, err = loader.Load(ctx)
+	if err != nil || len(docs) != 1 || docs[0].Content != "image placeholder" || loader.Errors()[broken] == nil {
+		t.Errorf("expected the png to load and the pdf error to be recorded, got %v, %v, %v", docs, err, loader.Errors())
+	}
+}
+
+func TestPipeline_IngestFromDirectory(t *testing.T) {
+	root := t.TempDir()
+	_ = os.WriteFile(filepath.Join(root, "a.md"), []byte("---\ntitle: A\n---\nalpha content"), 0o644)
+	_ = os.WriteFile(filepath.Join(root, "b.txt"), []byte("beta content"), 0o644)
+
+	pipeline := rag.NewRAGPipeline(rag.WithEmbedder(newMockEmbedder()))
+	if err := pipeline.IngestFromLoader(context.Background(), rag.NewDirectoryLoader(root)); err != nil {
+		t.Fatalf("IngestFromLoader() error = %v", err)
+	}
+	if n := pipeline.GetStore().Size(); n != 2 {
+		t.Errorf("expected 2 chunks, got %d", n)
+	}
+}
@@ -0,0 +1,51 @@
+package rag_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ahhsitt/helloagents-go/pkg/rag"
+)
+
+func TestMultiVectorRetriever(t *testing.T) {
+	ctx := context.Background()
+	embedder := keywordEmbedder("go", "rust", "python", "java", "safety")
+	index := rag.NewMultiVectorIndex(embedder)
+	pipeline := rag.NewRAGPipeline(rag.WithEmbedder(embedder), rag.WithMultiVectorIndex(index))
+	_ = pipeline.Ingest(ctx, []rag.Document{
+		{ID: "long", Content: "Go is fast. Python is popular. Java is verbose. Rust gives memory safety.", Metadata: rag.DocumentMetadata{Source: "guide"}},
+		{ID: "short", Content: "Rust crates.", Metadata: rag.DocumentMetadata{Source: "notes"}},
+	})
+	if index.Size() != 2 {
+		t.Fatalf("index size = %d, want 2", index.Size())
+	}
+
+	// 单向量模式下长块的嵌入被其他主题稀释
+	single, _ := rag.NewVectorRetriever(pipeline.GetStore(), embedder).Retrieve(ctx, "rust safety", 2)
+	if single[0].Chunk.DocumentID != "short" {
+		t.Errorf("single-vector top result = %s, want short", single[0].Chunk.DocumentID)
+	}
+
+	retriever := rag.NewMultiVectorRetriever(index)
+	results, err := retriever.Retrieve(ctx, "rust safety", 2)
+	if err != nil {
+		t.Fatalf("Retrieve() error = %v", err)
+	}
+	if len(results) != 2 || results[0].Chunk.DocumentID != "long" || results[0].Score < 0.99 {
+		t.Errorf("MaxSim should rank the matching sentence's chunk first: %+v", results)
+	}
+
+	results, _ = retriever.RetrieveWithFilter(ctx, "rust safety", 2, rag.MetadataFilter{rag.Where("source", rag.OpEq, "notes")})
+	if len(results) != 1 || results[0].Chunk.DocumentID != "short" {
+		t.Errorf("filtered results = %+v", results)
+	}
+	results, _ = rag.NewMultiVectorRetriever(index, rag.WithMultiVectorThreshold(0.9)).Retrieve(ctx, "rust safety", 2)
+	if len(results) != 1 {
+		t.Errorf("threshold should drop weak matches: %+v", results)
+	}
+
+	_ = pipeline.DeleteDocument(ctx, "long")
+	if index.Size() != 1 {
+		t.Errorf("deleting a document should update the index, size = %d", index.Size())
+	}
+}
@@ -0,0 +1,23 @@
+package rag_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ahhsitt/helloagents-go/pkg/memory"
+	"github.com/ahhsitt/helloagents-go/pkg/rag"
+)
+
+func TestRetrieveWithReport_QueryNormalizer(t *testing.T) {
+	retriever := rag.NewVectorRetriever(rag.NewInMemoryVectorStore(), newMockEmbedder())
+	normalizer := memory.NewQueryNormalizer()
+	normalizer.Fit([]string{"refund policy"})
+
+	_, report, err := retriever.RetrieveWithReport(context.Background(), "Refnd POLICY?", 3, rag.WithQueryNormalizer(normalizer))
+	if err != nil {
+		t.Fatalf("RetrieveWithReport() error = %v", err)
+	}
+	if report.OriginalQuery != "Refnd POLICY?" || report.Query != "refund policy" {
+		t.Errorf("report queries = %q / %q, want original preserved and normalized query used", report.OriginalQuery, report.Query)
+	}
+}
@@ -0,0 +1,88 @@
+package rag_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ahhsitt/helloagents-go/pkg/rag"
+)
+
+// keywordEmbedder 按关键词生成正交向量
+func keywordEmbedder(keywords ...string) *mockEmbedder {
+	return &mockEmbedder{embedFn: func(_ context.Context, texts []string) ([][]float32, error) {
+		vectors := make([][]float32, len(texts))
+		for i, text := range texts {
+			vectors[i] = make([]float32, len(keywords)+1)
+			vectors[i][len(keywords)] = 0.1
+			for k, kw := range keywords {
+				if strings.Contains(strings.ToLower(text), kw) {
+					vectors[i][k] = 1
+				}
+			}
+		}
+		return vectors, nil
+	}}
+}
+
+func TestParentDocumentRetriever(t *testing.T) {
+	ctx := context.Background()
+	embedder := keywordEmbedder("alpha", "beta", "gamma")
+	parents := rag.NewInMemoryChunkStore()
+	store := rag.NewInMemoryVectorStore()
+	pipeline := rag.NewRAGPipeline(
+		rag.WithEmbedder(embedder),
+		rag.WithStore(store),
+		rag.WithChunker(rag.NewRecursiveCharacterChunker(40, 0)),
+		rag.WithParentChunks(parents, paragraphChunker{}),
+	)
+
+	doc := rag.Document{ID: "doc", Content: "Intro text here. The alpha setting is on.\n\n" +
+		"Second part. The beta flag controls retries.\n\nThird part. Gamma is deprecated."}
+	if err := pipeline.Ingest(ctx, []rag.Document{doc}); err != nil {
+		t.Fatalf("Ingest() error = %v", err)
+	}
+	if parents.Size() != 3 || store.Size() <= 3 {
+		t.Fatalf("expected 3 parents and more children, got %d and %d", parents.Size(), store.Size())
+	}
+
+	base := rag.NewVectorRetriever(store, embedder)
+	child, _ := base.Retrieve(ctx, "beta", 1)
+	if child[0].Chunk.ParentID == "" || strings.Contains(child[0].Chunk.Content, "Second part") {
+		t.Fatalf("expected a small child chunk with a parent, got %+v", child[0].Chunk)
+	}
+
+	results, err := rag.NewParentDocumentRetriever(base, parents).Retrieve(ctx, "beta", 1)
+	if err != nil || len(results) != 1 {
+		t.Fatalf("Retrieve() = %+v, %v", results, err)
+	}
+	if results[0].Chunk.Content != "Second part. The beta flag controls retries." {
+		t.Errorf("expected the parent paragraph, got %q", results[0].Chunk.Content)
+	}
+	if matched := results[0].Chunk.Metadata.Custom[rag.MetadataMatchedChunks].([]string); len(matched) == 0 || matched[0] != child[0].Chunk.ID {
+		t.Errorf("unexpected matched chunks: %v", matched)
+	}
+
+	// 相邻窗口
+	results, _ = rag.NewParentDocumentRetriever(base, parents, rag.WithParentWindow(1)).Retrieve(ctx, "gamma", 1)
+	if got := results[0].Chunk.Content; !strings.HasPrefix(got, "Second part") || !strings.HasSuffix(got, "Gamma is deprecated.") {
+		t.Errorf("window content = %q", got)
+	}
+
+	// 完整文档，多个命中合并为一个结果
+	results, _ = rag.NewParentDocumentRetriever(base, parents, rag.WithFullDocument()).Retrieve(ctx, "alpha beta", 3)
+	if len(results) != 1 || !strings.HasPrefix(results[0].Chunk.Content, "Intro text") || !strings.HasSuffix(results[0].Chunk.Content, "deprecated.") {
+		t.Errorf("full document results = %+v", results)
+	}
+
+	// 文档更新后过时的父块被删除
+	doc.Content = "Only one paragraph about alpha."
+	_ = pipeline.Ingest(ctx, []rag.Document{doc})
+	if parents.Size() != 1 {
+		t.Errorf("expected stale parents to be removed, got %d", parents.Size())
+	}
+	_ = pipeline.DeleteDocument(ctx, "doc")
+	if parents.Size() != 0 || store.Size() != 0 {
+		t.Errorf("expected DeleteDocument to remove parents and children, got %d and %d", parents.Size(), store.Size())
+	}
+}
@@ -2,6 +2,8 @@ package rag_test
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"testing"
 	"time"
 
@@ -259,3 +261,130 @@ func TestRetrieveOptions_Defaults(t *testing.T) {
 		t.Fatalf("expected 1 result, got %d", len(results))
 	}
 }
+
+// slowEmbedder 对包含 "slow" 的查询阻塞直到上下文结束
+func slowEmbedder() *mockEmbedder {
+	return &mockEmbedder{embedFn: func(ctx context.Context, texts []string) ([][]float32, error) {
+		if strings.Contains(texts[0], "slow") {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		return newMockEmbedder().Embed(ctx, texts)
+	}}
+}
+
+func partialStore(t *testing.T) *rag.InMemoryVectorStore {
+	t.Helper()
+	store := rag.NewInMemoryVectorStore()
+	_ = store.Add(context.Background(), []rag.DocumentChunk{
+		{ID: "chunk-1", Content: "Content 1", Vector: []float32{1.0, 0.0, 0.0}},
+		{ID: "chunk-2", Content: "Content 2", Vector: []float32{0.0, 1.0, 0.0}},
+	})
+	return store
+}
+
+func TestVectorRetriever_StrategyTimeoutPartialResults(t *testing.T) {
+	ctx := context.Background()
+	mqe := &mockLLMProvider{generateFn: func(ctx context.Context, prompt string) (string, error) {
+		return "fast variant\nslow variant", nil
+	}}
+	hyde := &mockLLMProvider{generateFn: func(ctx context.Context, prompt string) (string, error) {
+		<-ctx.Done()
+		return "", ctx.Err()
+	}}
+
+	retriever := rag.NewVectorRetriever(partialStore(t), slowEmbedder())
+	start := time.Now()
+	results, report, err := retriever.RetrieveWithReport(ctx, "query", 2,
+		rag.WithMQE(mqe, 2),
+		rag.WithHyDE(hyde),
+		rag.WithStrategyTimeout(50*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatalf("expected partial results, got error %v", err)
+	}
+	if time.Since(start) > 2*time.Second {
+		t.Fatalf("slow strategies should be cut off, took %v", time.Since(start))
+	}
+	if len(results) == 0 {
+		t.Fatal("expected results from completed strategies")
+	}
+	if !report.Partial || report.Err() == nil {
+		t.Fatalf("expected partial report with errors, got %+v", report)
+	}
+
+	var hydeTimeouts, retrieveTimeouts int
+	for _, s := range report.Failed() {
+		switch {
+		case s.Stage == rag.StageTransform && s.Name == "HyDETransformer" && s.Status == rag.StrategyTimeout:
+			hydeTimeouts++
+		case s.Stage == rag.StageRetrieve && s.Query == "slow variant" && s.Status == rag.StrategyTimeout:
+			retrieveTimeouts++
+		}
+	}
+	if hydeTimeouts == 0 || retrieveTimeouts != 1 {
+		t.Errorf("expected HyDE and slow retrieval timeouts, got %+v", report.Failed())
+	}
+}
+
+func TestVectorRetriever_EarlyStopCancelsLosers(t *testing.T) {
+	ctx := context.Background()
+	llm := &mockLLMProvider{generateFn: func(ctx context.Context, prompt string) (string, error) {
+		return "variant one\nvariant two\nslow variant", nil
+	}}
+
+	retriever := rag.NewVectorRetriever(partialStore(t), slowEmbedder())
+	results, report, err := retriever.RetrieveWithReport(ctx, "query", 1,
+		rag.WithMQE(llm, 3),
+		rag.WithEarlyStop(1),
+		rag.WithTimeout(5*time.Second),
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !report.EarlyStopped {
+		t.Fatal("expected early stop once topK is stable")
+	}
+	if report.Duration > 2*time.Second {
+		t.Errorf("slow retrieval should be cancelled, took %v", report.Duration)
+	}
+	if len(results) != 1 || results[0].Chunk.ID != "chunk-1" {
+		t.Errorf("unexpected results: %v", results)
+	}
+
+	slowCancelled := false
+	for _, s := range report.Failed() {
+		if s.Query == "slow variant" && s.Status == rag.StrategyCancelled {
+			slowCancelled = true
+		}
+	}
+	if !slowCancelled {
+		t.Errorf("expected slow retrieval to be cancelled, got %+v", report.Failed())
+	}
+}
+
+func TestVectorRetriever_PostProcessorFailureKeepsResults(t *testing.T) {
+	ctx := context.Background()
+	llm := &mockLLMProvider{generateFn: func(ctx context.Context, prompt string) (string, error) {
+		return "variant", nil
+	}}
+	reranker := &mockReranker{rerankFn: func(ctx context.Context, query string, results []rag.RetrievalResult) ([]rag.RetrievalResult, error) {
+		return nil, errors.New("rerank service unavailable")
+	}}
+
+	retriever := rag.NewVectorRetriever(partialStore(t), newMockEmbedder())
+	results, report, err := retriever.RetrieveWithReport(ctx, "query", 2,
+		rag.WithMQE(llm, 1),
+		rag.WithRerank(reranker),
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected fused results to be kept, got %d", len(results))
+	}
+	failed := report.Failed()
+	if len(failed) != 1 || failed[0].Stage != rag.StagePostProcess || failed[0].Status != rag.StrategyFailed {
+		t.Errorf("expected one failed post-processor, got %+v", failed)
+	}
+}
@@ -0,0 +1,155 @@
+package rag_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ahhsitt/helloagents-go/pkg/rag"
+)
+
+func rerankCandidates() []rag.RetrievalResult {
+	return []rag.RetrievalResult{
+		{Chunk: rag.DocumentChunk{ID: "a", Content: "weather report"}, Score: 0.9},
+		{Chunk: rag.DocumentChunk{ID: "b", Content: "reset your password from the account page"}, Score: 0.8},
+		{Chunk: rag.DocumentChunk{ID: "c", Content: "password policy"}, Score: 0.7},
+	}
+}
+
+func rerankIDs(results []rag.RetrievalResult) string {
+	ids := make([]string, len(results))
+	for i, r := range results {
+		ids[i] = r.Chunk.ID
+	}
+	return strings.Join(ids, ",")
+}
+
+func TestLLMReranker_Pointwise(t *testing.T) {
+	var calls atomic.Int32
+	llm := &mockLLMProvider{generateFn: func(_ context.Context, prompt string) (string, error) {
+		calls.Add(1)
+		switch {
+		case strings.Contains(prompt, "account page"):
+			return "Score: 9", nil
+		case strings.Contains(prompt, "password policy"):
+			return "6/10", nil
+		}
+		return "1", nil
+	}}
+
+	results, err := rag.NewLLMReranker(llm, rag.WithRerankTopN(2)).Rerank(context.Background(), "how to reset password", rerankCandidates())
+	if err != nil {
+		t.Fatalf("Rerank() error = %v", err)
+	}
+	if rerankIDs(results) != "b,c" || results[0].Score != 0.9 || calls.Load() != 3 {
+		t.Errorf("Rerank() = %+v, calls %d", results, calls.Load())
+	}
+
+	bad := &mockLLMProvider{generateFn: func(context.Context, string) (string, error) { return "not sure", nil }}
+	if _, err := rag.NewLLMReranker(bad).Rerank(context.Background(), "q", rerankCandidates()); err == nil {
+		t.Error("expected an error when no score can be parsed")
+	}
+}
+
+func TestLLMReranker_Listwise(t *testing.T) {
+	var prompts []string
+	llm := &mockLLMProvider{generateFn: func(_ context.Context, prompt string) (string, error) {
+		prompts = append(prompts, prompt)
+		if len(prompts) == 1 {
+			return "[2] > [2] > [9] > [1]", nil
+		}
+		return "1", nil
+	}}
+
+	results, err := rag.NewLLMReranker(llm,
+		rag.WithRerankMode(rag.RerankListwise),
+		rag.WithRerankBatchSize(2),
+	).Rerank(context.Background(), "reset password", rerankCandidates())
+	if err != nil {
+		t.Fatalf("Rerank() error = %v", err)
+	}
+	// 第一批 b 排第一，a 其次；第二批只有 c
+	if len(prompts) != 2 || !strings.Contains(prompts[0], "[2] reset your password") {
+		t.Errorf("unexpected prompts: %q", prompts)
+	}
+	if rerankIDs(results) != "b,c,a" {
+		t.Errorf("Rerank() order = %s", rerankIDs(results))
+	}
+}
+
+func TestHTTPReranker(t *testing.T) {
+	var requests []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		requests = append(requests, body)
+
+		docs := body["documents"].([]interface{})
+		type item struct {
+			Index int     `json:"index"`
+			Score float64 `json:"relevance_score"`
+		}
+		var items []item
+		for i, d := range docs {
+			if strings.Contains(d.(string), "password") {
+				items = append(items, item{Index: i, Score: 0.5 + float64(len(d.(string)))/100})
+			}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"results": items})
+	}))
+	defer server.Close()
+
+	reranker := rag.NewCohereReranker("secret",
+		rag.WithRerankEndpoint(server.URL),
+		rag.WithRerankBatchSize(2),
+	)
+	results, err := reranker.Rerank(context.Background(), "reset password", rerankCandidates())
+	if err != nil {
+		t.Fatalf("Rerank() error = %v", err)
+	}
+	if rerankIDs(results) != "b,c,a" || results[2].Score != 0 {
+		t.Errorf("Rerank() = %+v", results)
+	}
+	if len(requests) != 2 || requests[0]["model"] != "rerank-v3.5" || requests[0]["query"] != "reset password" {
+		t.Errorf("unexpected requests: %v", requests)
+	}
+
+	if _, err := rag.NewCohereReranker("wrong", rag.WithRerankEndpoint(server.URL)).Rerank(context.Background(), "q", rerankCandidates()); err == nil {
+		t.Error("expected an error for a non-200 response")
+	}
+}
+
+func TestVectorRetriever_WithLLMRerank(t *testing.T) {
+	ctx := context.Background()
+	store := rag.NewInMemoryVectorStore()
+	candidates := rerankCandidates()
+	for i := range candidates {
+		candidates[i].Chunk.Vector = []float32{1, float32(i)}
+	}
+	_ = store.Add(ctx, []rag.DocumentChunk{candidates[0].Chunk, candidates[1].Chunk, candidates[2].Chunk})
+
+	llm := &mockLLMProvider{generateFn: func(_ context.Context, prompt string) (string, error) {
+		if strings.Contains(prompt, "account page") {
+			return "10", nil
+		}
+		return "0", nil
+	}}
+	retriever := rag.NewVectorRetriever(store, &mockEmbedder{embedFn: func(context.Context, []string) ([][]float32, error) {
+		return [][]float32{{1, 0}}, nil
+	}})
+
+	results, err := retriever.RetrieveWithOptions(ctx, "reset password", 1,
+		rag.WithRerank(rag.NewLLMReranker(llm)),
+	)
+	if err != nil || len(results) != 1 || results[0].Chunk.ID != "b" {
+		t.Errorf("RetrieveWithOptions() = %+v, %v", results, err)
+	}
+}
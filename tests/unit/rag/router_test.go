@@ -0,0 +1,151 @@
+package rag_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ahhsitt/helloagents-go/pkg/rag"
+)
+
+// fixedRetriever 返回固定结果的检索器
+func fixedRetriever(ids ...string) rag.RetrieverFunc {
+	return func(ctx context.Context, query string, topK int) ([]rag.RetrievalResult, error) {
+		results := make([]rag.RetrievalResult, 0, len(ids))
+		for i, id := range ids {
+			if i == topK {
+				break
+			}
+			results = append(results, rag.RetrievalResult{Chunk: rag.DocumentChunk{ID: id}, Score: 1 - float32(i)*0.1})
+		}
+		return results, nil
+	}
+}
+
+func routerRoutes() []rag.Route {
+	return []rag.Route{
+		{Name: "docs", Description: "product documentation and user guides", Retriever: fixedRetriever("d1", "d2")},
+		{Name: "code", Description: "source code and API reference", Examples: []string{"show me the code sample"}, Retriever: fixedRetriever("c1", "c2")},
+		{Name: "tickets", Description: "support tickets and incidents", Retriever: fixedRetriever("t1")},
+	}
+}
+
+func TestRouter_LLMClassifier(t *testing.T) {
+	ctx := context.Background()
+	var prompts []string
+	llm := &mockLLMProvider{generateFn: func(_ context.Context, prompt string) (string, error) {
+		prompts = append(prompts, prompt)
+		if strings.Contains(prompt, "outage") {
+			return "1. Tickets, `code`", nil
+		}
+		if strings.Contains(prompt, "weather") {
+			return "none", nil
+		}
+		return "code", nil
+	}}
+
+	router, err := rag.NewRouter(rag.NewLLMRouteClassifier(llm), routerRoutes(), rag.WithMaxRoutes(2))
+	if err != nil {
+		t.Fatalf("NewRouter() error = %v", err)
+	}
+
+	results, err := router.Retrieve(ctx, "how do I call Parse", 5)
+	if err != nil {
+		t.Fatalf("Retrieve() error = %v", err)
+	}
+	if got := resultIDs(results); !equalIDs(got, []string{"c1", "c2"}) {
+		t.Errorf("single route returned %v", got)
+	}
+	if results[0].Chunk.Metadata.Custom[rag.MetadataRoute] != "code" {
+		t.Errorf("result should be tagged with its route: %+v", results[0].Chunk.Metadata)
+	}
+	if !strings.Contains(prompts[0], "- docs: product documentation") {
+		t.Errorf("prompt should list routes: %q", prompts[0])
+	}
+
+	if names := router.Route(ctx, "outage in the API"); !equalIDs(names, []string{"tickets", "code"}) {
+		t.Errorf("Route() = %v, want [tickets code]", names)
+	}
+	results, _ = router.Retrieve(ctx, "outage in the API", 3)
+	// 两个路由的首个结果同分，融合后顺序不固定
+	if got := resultIDs(results); len(got) != 3 || got[2] != "c2" {
+		t.Errorf("fan-out should fuse both routes: %v", resultIDs(results))
+	}
+
+	if names := router.Route(ctx, "weather today"); len(names) != 3 {
+		t.Errorf("no match should fan out to all routes, got %v", names)
+	}
+}
+
+func TestRouter_DefaultRouteAndErrors(t *testing.T) {
+	ctx := context.Background()
+	failing := &mockLLMProvider{generateFn: func(context.Context, string) (string, error) {
+		return "", errors.New("llm down")
+	}}
+
+	routes := routerRoutes()
+	routes[1].Retriever = rag.RetrieverFunc(func(context.Context, string, int) ([]rag.RetrievalResult, error) {
+		return nil, errors.New("index offline")
+	})
+	router, err := rag.NewRouter(rag.NewLLMRouteClassifier(failing), routes, rag.WithDefaultRoute("docs"))
+	if err != nil {
+		t.Fatalf("NewRouter() error = %v", err)
+	}
+	results, err := router.Retrieve(ctx, "q", 5)
+	if err != nil || !equalIDs(resultIDs(results), []string{"d1", "d2"}) {
+		t.Errorf("classifier failure should use the default route: %v, %v", resultIDs(results), err)
+	}
+
+	// 无分类器时扇出，失败的路由被跳过
+	fanOut, _ := rag.NewRouter(nil, routes)
+	results, err = fanOut.Retrieve(ctx, "q", 10)
+	if err != nil || len(results) != 3 {
+		t.Errorf("fan-out should skip the failing route: %v, %v", resultIDs(results), err)
+	}
+
+	onlyFailing, _ := rag.NewRouter(nil, routes[1:2])
+	if _, err := onlyFailing.Retrieve(ctx, "q", 5); err == nil || !strings.Contains(err.Error(), "index offline") {
+		t.Errorf("expected the route error, got %v", err)
+	}
+
+	if _, err := rag.NewRouter(nil, nil); err == nil {
+		t.Error("expected an error without routes")
+	}
+	if _, err := rag.NewRouter(nil, append(routerRoutes(), routerRoutes()[0])); err == nil {
+		t.Error("expected an error for duplicate routes")
+	}
+	if _, err := rag.NewRouter(nil, routerRoutes(), rag.WithDefaultRoute("missing")); err == nil {
+		t.Error("expected an error for an unknown default route")
+	}
+}
+
+func TestRouter_EmbeddingClassifier(t *testing.T) {
+	ctx := context.Background()
+	embedder := keywordEmbedder("documentation", "code", "ticket")
+	calls := 0
+	counting := &mockEmbedder{embedFn: func(ctx context.Context, texts []string) ([][]float32, error) {
+		calls++
+		return embedder.Embed(ctx, texts)
+	}}
+
+	classifier := rag.NewEmbeddingRouteClassifier(counting, rag.WithRouteThreshold(0.5))
+	router, err := rag.NewRouter(classifier, routerRoutes(), rag.WithMaxRoutes(3))
+	if err != nil {
+		t.Fatalf("NewRouter() error = %v", err)
+	}
+
+	if names := router.Route(ctx, "where is the code for this"); !equalIDs(names, []string{"code"}) {
+		t.Errorf("Route() = %v, want [code]", names)
+	}
+	if names := router.Route(ctx, "ticket about documentation"); !equalIDs(names, []string{"docs", "tickets"}) {
+		t.Errorf("Route() = %v, want [docs tickets]", names)
+	}
+	if names := router.Route(ctx, "hello"); len(names) != 3 {
+		t.Errorf("below-threshold query should fan out, got %v", names)
+	}
+	// 路由文本只嵌入一次，之后每次查询只嵌入查询本身
+	if calls != 4 {
+		t.Errorf("embedder called %d times, want 4", calls)
+	}
+}
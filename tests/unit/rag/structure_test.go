@@ -0,0 +1,105 @@
+package rag_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ahhsitt/helloagents-go/pkg/rag"
+)
+
+const structuredDoc = "# Setup\n\nInstall the CLI first.\n\n" +
+	"```go\nfunc main() {\n\n\tfmt.Println(\"hi\")\n}\n```\n\n" +
+	"| Flag | Meaning |\n|------|---------|\n| -v | verbose |\n| -q | quiet |\n\n" +
+	"That is all."
+
+func TestStructureAwareChunker(t *testing.T) {
+	chunker := rag.NewStructureAwareChunker(rag.NewRecursiveCharacterChunker(20, 0), 0)
+	chunks := chunker.Chunk(rag.Document{ID: "doc", Content: structuredDoc})
+
+	var code, table []rag.DocumentChunk
+	for _, c := range chunks {
+		switch c.Metadata.Custom[rag.MetadataContentKind] {
+		case rag.ContentKindCode:
+			code = append(code, c)
+		case rag.ContentKindTable:
+			table = append(table, c)
+		case rag.ContentKindText:
+		default:
+			t.Errorf("chunk %q has no content kind", c.Content)
+		}
+		if structuredDoc[c.StartOffset:c.EndOffset] != c.Content && c.Metadata.Custom[rag.MetadataContentKind] != rag.ContentKindText {
+			t.Errorf("offsets [%d:%d] do not match %q", c.StartOffset, c.EndOffset, c.Content)
+		}
+	}
+	if len(code) != 1 || !strings.HasPrefix(code[0].Content, "```go\n") || !strings.HasSuffix(code[0].Content, "}\n```") {
+		t.Fatalf("code fence should stay whole: %+v", code)
+	}
+	if code[0].Metadata.Custom[rag.MetadataCodeLanguage] != "go" {
+		t.Errorf("language = %v", code[0].Metadata.Custom[rag.MetadataCodeLanguage])
+	}
+	if len(table) != 1 || strings.Count(table[0].Content, "\n") != 3 {
+		t.Fatalf("table should stay whole: %+v", table)
+	}
+	if last := chunks[len(chunks)-1]; last.Content != "That is all." {
+		t.Errorf("trailing text = %q", last.Content)
+	}
+}
+
+func TestStructureAwareChunker_SplitsOversizedBlocks(t *testing.T) {
+	var rows strings.Builder
+	rows.WriteString("| id | name |\n| --- | --- |\n")
+	for i := 0; i < 20; i++ {
+		rows.WriteString("| 1 | row |\n")
+	}
+	code := "```python\n" + strings.Repeat("print('x')\n", 20) + "```"
+
+	chunker := rag.NewStructureAwareChunker(nil, 80)
+	chunks := chunker.Chunk(rag.Document{ID: "doc", Content: rows.String() + "\n" + code})
+	var tables, codes int
+	for _, c := range chunks {
+		if len(c.Content) > 80 {
+			t.Errorf("part exceeds the block size: %q", c.Content)
+		}
+		switch c.Metadata.Custom[rag.MetadataContentKind] {
+		case rag.ContentKindTable:
+			tables++
+			if !strings.HasPrefix(c.Content, "| id | name |\n| --- | --- |\n| 1 |") {
+				t.Errorf("table part should repeat the header: %q", c.Content)
+			}
+		case rag.ContentKindCode:
+			codes++
+			if !strings.HasPrefix(c.Content, "```python\nprint") || !strings.HasSuffix(c.Content, "')\n```") {
+				t.Errorf("code part should keep its fences: %q", c.Content)
+			}
+		}
+	}
+	if tables < 2 || codes < 2 {
+		t.Errorf("expected oversized blocks to be split, got %d table and %d code parts", tables, codes)
+	}
+}
+
+func TestContentKindBoost(t *testing.T) {
+	ctx := context.Background()
+	pipeline := rag.NewRAGPipeline(
+		rag.WithEmbedder(&mockEmbedder{}),
+		rag.WithChunker(rag.NewStructureAwareChunker(nil, 0)),
+	)
+	_ = pipeline.Ingest(ctx, []rag.Document{{ID: "doc", Content: structuredDoc}})
+	retriever := rag.NewVectorRetriever(pipeline.GetStore(), &mockEmbedder{})
+
+	results, err := retriever.RetrieveWithOptions(ctx, "flags", 3,
+		rag.WithContentKindBoost(map[string]float32{rag.ContentKindTable: 2}))
+	if err != nil || len(results) == 0 {
+		t.Fatalf("RetrieveWithOptions() = %v, %v", results, err)
+	}
+	if results[0].Chunk.Metadata.Custom[rag.MetadataContentKind] != rag.ContentKindTable {
+		t.Errorf("boosted table should rank first, got %+v", results[0].Chunk)
+	}
+
+	results, _ = retriever.RetrieveWithOptions(ctx, "code", 3,
+		rag.WithFilter(rag.Where(rag.MetadataContentKind, rag.OpEq, rag.ContentKindCode)))
+	if len(results) != 1 || results[0].Chunk.Metadata.Custom[rag.MetadataCodeLanguage] != "go" {
+		t.Errorf("content kind filter = %+v", results)
+	}
+}
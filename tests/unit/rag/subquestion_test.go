@@ -0,0 +1,93 @@
+package rag_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ahhsitt/helloagents-go/pkg/rag"
+)
+
+func TestDecomposeQuestion(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"Compare LangChain's memory system with AutoGen's", []string{"LangChain's memory system", "AutoGen's memory system"}},
+		{"What is the difference between BM25 and dense retrieval?", []string{"BM25", "dense retrieval"}},
+		{"Qdrant vs pgvector", []string{"Qdrant", "pgvector"}},
+		{"How do I install it? How do I configure the API key?", []string{"How do I install it?", "How do I configure the API key?"}},
+		{"list the loaders; explain chunking", []string{"list the loaders", "explain chunking"}},
+		{"比较 LangChain 和 AutoGen 的记忆系统", []string{"LangChain的记忆系统", "AutoGen的记忆系统"}},
+		{"Redis与Memcached有什么区别？", []string{"Redis", "Memcached"}},
+		{"What is retrieval-augmented generation?", []string{"What is retrieval-augmented generation?"}},
+		{"猫和狗", []string{"猫和狗"}},
+	}
+	for _, tt := range tests {
+		if got := rag.DecomposeQuestion(tt.query); !equalIDs(got, tt.want) {
+			t.Errorf("DecomposeQuestion(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestSubQuestionTransformer(t *testing.T) {
+	ctx := context.Background()
+	calls := 0
+	llm := &mockLLMProvider{generateFn: func(context.Context, string) (string, error) {
+		calls++
+		return "1. How does Go schedule goroutines?\n2. How does Go collect garbage?", nil
+	}}
+	transformer := rag.NewSubQuestionTransformer(llm, rag.WithSubQuestionOriginal(true))
+
+	// 规则能分解时不调用 LLM
+	queries, _ := transformer.Transform(ctx, "Go vs Rust")
+	if calls != 0 || len(queries) != 3 || queries[1].Metadata[rag.MetadataSubQuestion] != "Go" {
+		t.Errorf("rule decomposition = %+v (llm calls %d)", queries, calls)
+	}
+	if queries[0].Query != "Go vs Rust" || queries[0].Metadata[rag.MetadataSubQuestion] != "" {
+		t.Errorf("original query should come first without a sub-question: %+v", queries[0])
+	}
+
+	queries, _ = transformer.Transform(ctx, "Explain how the Go runtime manages goroutines and memory")
+	if calls != 1 || len(queries) != 3 || queries[2].Query != "How does Go collect garbage?" {
+		t.Errorf("llm decomposition = %+v", queries)
+	}
+
+	failing := rag.NewSubQuestionTransformer(&mockLLMProvider{generateFn: func(context.Context, string) (string, error) {
+		return "", errors.New("llm down")
+	}})
+	queries, err := failing.Transform(ctx, "Explain goroutines")
+	if err != nil || len(queries) != 1 || queries[0].Query != "Explain goroutines" {
+		t.Errorf("failure should keep the original question: %+v, %v", queries, err)
+	}
+}
+
+func TestVectorRetriever_WithSubQuestions(t *testing.T) {
+	ctx := context.Background()
+	embedder := keywordEmbedder("langchain", "autogen", "memory")
+	pipeline := rag.NewRAGPipeline(rag.WithEmbedder(embedder))
+	_ = pipeline.Ingest(ctx, []rag.Document{
+		{ID: "lc", Content: "LangChain memory keeps a conversation buffer."},
+		{ID: "ag", Content: "AutoGen memory stores teachable facts."},
+		{ID: "other", Content: "Unrelated release notes."},
+	})
+	retriever := rag.NewVectorRetriever(pipeline.GetStore(), embedder)
+
+	results, err := retriever.RetrieveWithOptions(ctx, "Compare LangChain's memory with AutoGen's", 2, rag.WithSubQuestions(nil))
+	if err != nil {
+		t.Fatalf("RetrieveWithOptions() error = %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected evidence for both sub-questions, got %+v", results)
+	}
+	for _, r := range results {
+		questions, _ := r.Chunk.Metadata.Custom[rag.MetadataSubQuestions].([]string)
+		want := "LangChain's memory"
+		if r.Chunk.DocumentID == "ag" {
+			want = "AutoGen's memory"
+		}
+		if len(questions) == 0 || questions[0] != want {
+			t.Errorf("%s attributed to %v, want %q first", r.Chunk.DocumentID, questions, want)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package rag_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ahhsitt/helloagents-go/pkg/rag"
+)
+
+func TestInMemoryVectorStore_DeleteByFilter(t *testing.T) {
+	ctx := context.Background()
+	store := rag.NewInMemoryVectorStore()
+	day1 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	day2 := day1.Add(24 * time.Hour)
+
+	_ = store.Add(ctx, []rag.DocumentChunk{
+		{ID: "a1", DocumentID: "a", Metadata: rag.DocumentMetadata{Source: "wiki", IngestedAt: day1}},
+		{ID: "a2", DocumentID: "a", Metadata: rag.DocumentMetadata{Source: "wiki", IngestedAt: day1}},
+		{ID: "b1", DocumentID: "b", Metadata: rag.DocumentMetadata{Source: "wiki", IngestedAt: day2}},
+		{ID: "c1", DocumentID: "c", Metadata: rag.DocumentMetadata{Source: "blog", IngestedAt: day1}},
+	})
+
+	if _, err := store.DeleteByFilter(ctx, rag.DeleteFilter{}); err == nil {
+		t.Fatal("expected error for empty filter")
+	}
+
+	stats, err := store.DeleteByFilter(ctx, rag.DeleteFilter{Source: "wiki", IngestedBefore: day2})
+	if err != nil {
+		t.Fatalf("DeleteByFilter() error = %v", err)
+	}
+	if stats.Chunks != 2 || stats.Documents != 1 {
+		t.Errorf("stats = %+v, want 2 chunks from 1 document", stats)
+	}
+	if store.Size() != 2 {
+		t.Errorf("Size() = %d, want 2", store.Size())
+	}
+
+	stats, _ = store.DeleteByFilter(ctx, rag.DeleteFilter{IngestedAfter: day2})
+	if stats.Chunks != 1 {
+		t.Errorf("stats = %+v, want 1 chunk ingested on day 2", stats)
+	}
+}
+
+func TestTTLSweeper_RemovesExpiredChunks(t *testing.T) {
+	ctx := context.Background()
+	store := rag.NewInMemoryVectorStore()
+	pipeline := rag.NewRAGPipeline(rag.WithEmbedder(newMockEmbedder()), rag.WithStore(store))
+
+	err := pipeline.Ingest(ctx, []rag.Document{
+		{ID: "fresh", Content: "fresh content", Metadata: rag.DocumentMetadata{TTL: time.Hour}},
+		{ID: "stale", Content: "stale content", Metadata: rag.DocumentMetadata{ExpiresAt: time.Now().Add(-time.Minute)}},
+		{ID: "forever", Content: "permanent content"},
+	})
+	if err != nil {
+		t.Fatalf("Ingest() error = %v", err)
+	}
+
+	results, _ := store.Search(ctx, []float32{1, 0, 0}, 10)
+	if len(results) != 2 {
+		t.Errorf("Search() returned %d results, want 2 unexpired chunks", len(results))
+	}
+	for _, r := range results {
+		if r.Chunk.DocumentID == "stale" {
+			t.Error("expired chunk should not be returned by Search")
+		}
+		if r.Chunk.Metadata.IngestedAt.IsZero() {
+			t.Errorf("chunk %s missing ingestion time", r.Chunk.ID)
+		}
+	}
+
+	sweeper := rag.NewTTLSweeper(store)
+	stats, err := sweeper.Sweep(ctx)
+	if err != nil {
+		t.Fatalf("Sweep() error = %v", err)
+	}
+	if stats.Chunks != 1 || stats.Documents != 1 {
+		t.Errorf("stats = %+v, want 1 chunk from 1 document", stats)
+	}
+	if store.Size() != 2 {
+		t.Errorf("Size() = %d, want 2 remaining chunks", store.Size())
+	}
+
+	_, _ = sweeper.Sweep(ctx)
+	if total := sweeper.Stats(); total.Chunks != 1 {
+		t.Errorf("cumulative stats = %+v, want 1 chunk", total)
+	}
+}
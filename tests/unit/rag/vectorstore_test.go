@@ -0,0 +1,114 @@
+package rag_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ahhsitt/helloagents-go/pkg/memory/store"
+	"github.com/ahhsitt/helloagents-go/pkg/rag"
+)
+
+func newSQLiteRAGStore(t *testing.T, path string) *rag.StoreVectorStore {
+	t.Helper()
+	vs, err := rag.NewVectorStore(&rag.VectorStoreConfig{
+		Config: store.Config{Type: store.StoreTypeSQLite, SQLitePath: path},
+	})
+	if err != nil {
+		t.Fatalf("NewVectorStore() error = %v", err)
+	}
+	s, ok := vs.(*rag.StoreVectorStore)
+	if !ok {
+		t.Fatalf("expected *rag.StoreVectorStore, got %T", vs)
+	}
+	return s
+}
+
+func TestStoreVectorStore_PersistsAcrossRestart(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "rag.db")
+	ingested := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	s := newSQLiteRAGStore(t, path)
+	err := s.Add(ctx, []rag.DocumentChunk{
+		{
+			ID: "c1", DocumentID: "d1", Content: "alpha", Index: 0, StartOffset: 0, EndOffset: 5,
+			Vector: []float32{1, 0},
+			Metadata: rag.DocumentMetadata{
+				Source: "wiki", Title: "Alpha", Tags: []string{"x"}, IngestedAt: ingested,
+				Custom: map[string]interface{}{"lang": "en"},
+			},
+		},
+		{ID: "c2", DocumentID: "d1", Content: "beta", Index: 1, Vector: []float32{0, 1}, Metadata: rag.DocumentMetadata{Source: "wiki"}},
+		{ID: "c3", DocumentID: "d2", Content: "gamma", Vector: []float32{1, 0.1}, Metadata: rag.DocumentMetadata{Source: "blog"}},
+	})
+	if err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if err := s.Add(ctx, []rag.DocumentChunk{{ID: "novec"}}); err == nil {
+		t.Error("expected an error for a chunk without vector")
+	}
+	_ = s.Close()
+
+	s = newSQLiteRAGStore(t, path)
+	defer s.Close()
+	if s.Size() != 3 {
+		t.Fatalf("Size() = %d after reopen, want 3", s.Size())
+	}
+
+	results, err := s.Search(ctx, []float32{1, 0}, 1)
+	if err != nil || len(results) != 1 {
+		t.Fatalf("Search() = %v, %v", results, err)
+	}
+	got := results[0].Chunk
+	if got.ID != "c1" || got.DocumentID != "d1" || got.Content != "alpha" || got.EndOffset != 5 ||
+		got.Metadata.Title != "Alpha" || got.Metadata.Tags[0] != "x" || !got.Metadata.IngestedAt.Equal(ingested) ||
+		got.Metadata.Custom["lang"] != "en" {
+		t.Errorf("chunk did not round-trip: %+v", got)
+	}
+
+	stats, err := s.DeleteByFilter(ctx, rag.DeleteFilter{Source: "wiki"})
+	if err != nil || stats.Chunks != 2 || stats.Documents != 1 {
+		t.Errorf("DeleteByFilter() = %+v, %v", stats, err)
+	}
+	if _, err := s.DeleteByFilter(ctx, rag.DeleteFilter{}); err == nil {
+		t.Error("expected an error for an empty filter")
+	}
+	if s.Size() != 1 {
+		t.Errorf("Size() = %d, want 1", s.Size())
+	}
+}
+
+func TestStoreVectorStore_TTL(t *testing.T) {
+	ctx := context.Background()
+	s := newSQLiteRAGStore(t, filepath.Join(t.TempDir(), "rag.db"))
+	defer s.Close()
+
+	past := time.Now().Add(-time.Minute)
+	_ = s.Add(ctx, []rag.DocumentChunk{
+		{ID: "old", DocumentID: "d1", Content: "old", Vector: []float32{1, 0}, Metadata: rag.DocumentMetadata{ExpiresAt: past}},
+		{ID: "new", DocumentID: "d2", Content: "new", Vector: []float32{0.5, 0.5}},
+	})
+
+	results, _ := s.Search(ctx, []float32{1, 0}, 1)
+	if len(results) != 1 || results[0].Chunk.ID != "new" {
+		t.Errorf("expected the expired chunk to be skipped, got %+v", results)
+	}
+
+	stats, err := rag.NewTTLSweeper(s).Sweep(ctx)
+	if err != nil || stats.Chunks != 1 || s.Size() != 1 {
+		t.Errorf("Sweep() = %+v, %v, size %d", stats, err, s.Size())
+	}
+}
+
+func TestNewVectorStore_Factory(t *testing.T) {
+	if vs, err := rag.NewVectorStore(nil); err != nil {
+		t.Errorf("NewVectorStore(nil) error = %v", err)
+	} else if _, ok := vs.(*rag.InMemoryVectorStore); !ok {
+		t.Errorf("expected in-memory store by default, got %T", vs)
+	}
+	if _, err := rag.NewVectorStore(&rag.VectorStoreConfig{Config: store.Config{Type: store.StoreTypeNeo4j}}); err == nil {
+		t.Error("expected an error for a non-vector store type")
+	}
+}
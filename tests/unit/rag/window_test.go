@@ -0,0 +1,82 @@
+package rag_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/ahhsitt/helloagents-go/pkg/rag"
+)
+
+const windowDoc = "Intro text here. The alpha setting is on. It defaults to false.\n" +
+	"The beta flag controls retries. Gamma is deprecated."
+
+func TestSentenceWindowChunker(t *testing.T) {
+	doc := rag.Document{ID: "doc", Content: windowDoc, Metadata: rag.DocumentMetadata{
+		Source: "manual",
+		Custom: map[string]interface{}{"lang": "en"},
+	}}
+
+	chunks := rag.NewSentenceWindowChunker(1).Chunk(doc)
+	if len(chunks) != 5 {
+		t.Fatalf("expected one chunk per sentence, got %d", len(chunks))
+	}
+
+	beta := chunks[3]
+	if beta.Content != "The beta flag controls retries." || windowDoc[beta.StartOffset:beta.EndOffset] != beta.Content {
+		t.Errorf("unexpected sentence chunk %q at %d:%d", beta.Content, beta.StartOffset, beta.EndOffset)
+	}
+	if got := beta.Metadata.Custom[rag.MetadataWindow]; got != "It defaults to false.\nThe beta flag controls retries. Gamma is deprecated." {
+		t.Errorf("window = %q", got)
+	}
+	if got := chunks[0].Metadata.Custom[rag.MetadataWindow]; got != "Intro text here. The alpha setting is on." {
+		t.Errorf("first window = %q", got)
+	}
+	if beta.Metadata.Custom["lang"] != "en" || beta.Metadata.Source != "manual" {
+		t.Errorf("document metadata should be kept: %+v", beta.Metadata)
+	}
+	if _, ok := doc.Metadata.Custom[rag.MetadataWindow]; ok {
+		t.Error("chunking should not modify the document metadata")
+	}
+}
+
+func TestSentenceWindowRetriever(t *testing.T) {
+	ctx := context.Background()
+	embedder := keywordEmbedder("alpha", "beta", "gamma")
+	store := newSQLiteRAGStore(t, filepath.Join(t.TempDir(), "window.db"))
+	pipeline := rag.NewRAGPipeline(
+		rag.WithEmbedder(embedder),
+		rag.WithStore(store),
+		rag.WithChunker(rag.NewSentenceWindowChunker(1)),
+	)
+	if err := pipeline.Ingest(ctx, []rag.Document{{ID: "doc", Content: windowDoc}}); err != nil {
+		t.Fatalf("Ingest() error = %v", err)
+	}
+
+	base := rag.NewVectorRetriever(store, embedder)
+	results, err := rag.NewSentenceWindowRetriever(base).Retrieve(ctx, "beta", 1)
+	if err != nil || len(results) != 1 {
+		t.Fatalf("Retrieve() = %+v, %v", results, err)
+	}
+	chunk := results[0].Chunk
+	if chunk.Content != "It defaults to false.\nThe beta flag controls retries. Gamma is deprecated." {
+		t.Errorf("expected the sentence window, got %q", chunk.Content)
+	}
+	if chunk.Metadata.Custom[rag.MetadataOriginalText] != "The beta flag controls retries." {
+		t.Errorf("original sentence = %v", chunk.Metadata.Custom[rag.MetadataOriginalText])
+	}
+	if _, ok := chunk.Metadata.Custom[rag.MetadataWindow]; ok {
+		t.Error("window key should be removed once the content is replaced")
+	}
+
+	// 窗口相同的命中合并为一个结果
+	wide := rag.NewRAGPipeline(rag.WithEmbedder(embedder), rag.WithChunker(rag.NewSentenceWindowChunker(10)))
+	_ = wide.Ingest(ctx, []rag.Document{{ID: "doc", Content: windowDoc}})
+	results, _ = rag.NewSentenceWindowRetriever(rag.NewVectorRetriever(wide.GetStore(), embedder)).Retrieve(ctx, "alpha beta", 2)
+	if len(results) != 1 || results[0].Chunk.Content != windowDoc {
+		t.Fatalf("expected one merged window, got %+v", results)
+	}
+	if matched := results[0].Chunk.Metadata.Custom[rag.MetadataMatchedChunks].([]string); len(matched) != 2 {
+		t.Errorf("matched chunks = %v", matched)
+	}
+}
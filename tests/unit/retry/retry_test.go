@@ -0,0 +1,112 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/ahhsitt/helloagents-go/pkg/core/config"
+	coreerrors "github.com/ahhsitt/helloagents-go/pkg/core/errors"
+	"github.com/ahhsitt/helloagents-go/pkg/core/retry"
+)
+
+func fastPolicy() retry.Policy {
+	return retry.Policy{MaxAttempts: 3, InitialBackoff: time.Millisecond}
+}
+
+func TestPolicy_RetriesRetryableErrors(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	ctx, span := tp.Tracer("test").Start(context.Background(), "op")
+
+	calls := 0
+	err := fastPolicy().DoNamed(ctx, "test", func(context.Context) error {
+		calls++
+		if calls < 3 {
+			return coreerrors.ErrRateLimited
+		}
+		return nil
+	})
+	span.End()
+	if err != nil || calls != 3 {
+		t.Fatalf("Do() = %v after %d calls, want success after 3", err, calls)
+	}
+
+	events := exporter.GetSpans()[0].Events
+	if len(events) != 2 || events[0].Name != retry.EventRetry {
+		t.Errorf("expected 2 retry events on the span, got %+v", events)
+	}
+}
+
+func TestPolicy_StopsOnNonRetryableError(t *testing.T) {
+	fatal := errors.New("bad request")
+	calls := 0
+	err := fastPolicy().Do(context.Background(), func(context.Context) error {
+		calls++
+		return fatal
+	})
+	if !errors.Is(err, fatal) || calls != 1 {
+		t.Errorf("Do() = %v after %d calls, want fatal error after 1", err, calls)
+	}
+
+	// 自定义错误类别：只重试超时
+	p := fastPolicy()
+	p.Retryable = retry.RetryOn(retry.ClassTimeout)
+	calls = 0
+	_ = p.Do(context.Background(), func(context.Context) error {
+		calls++
+		return coreerrors.ErrRateLimited
+	})
+	if calls != 1 {
+		t.Errorf("expected rate limit not to be retried, got %d calls", calls)
+	}
+}
+
+func TestPolicy_PerAttemptTimeout(t *testing.T) {
+	p := fastPolicy()
+	p.PerAttemptTimeout = 10 * time.Millisecond
+
+	calls := 0
+	err := p.Do(context.Background(), func(ctx context.Context) error {
+		calls++
+		if calls == 1 {
+			<-ctx.Done()
+			return ctx.Err()
+		}
+		return nil
+	})
+	if err != nil || calls != 2 {
+		t.Errorf("Do() = %v after %d calls, want timed-out attempt to be retried", err, calls)
+	}
+}
+
+func TestPolicy_Backoff(t *testing.T) {
+	p := retry.Policy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: 300 * time.Millisecond, Multiplier: 2}
+	want := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond}
+	for i, w := range want {
+		if got := p.Backoff(i + 1); got != w {
+			t.Errorf("Backoff(%d) = %v, want %v", i+1, got, w)
+		}
+	}
+}
+
+func TestFromConfig(t *testing.T) {
+	p, err := retry.FromConfig(config.RetryConfig{MaxAttempts: 5, RetryOn: []string{"timeout"}})
+	if err != nil {
+		t.Fatalf("FromConfig() error = %v", err)
+	}
+	if p.MaxAttempts != 5 || p.InitialBackoff != retry.Default().InitialBackoff {
+		t.Errorf("expected overrides on top of defaults, got %+v", p)
+	}
+	if p.Retryable(coreerrors.ErrRateLimited) || !p.Retryable(coreerrors.ErrTimeout) {
+		t.Error("expected only timeouts to be retryable")
+	}
+
+	if _, err := retry.FromConfig(config.RetryConfig{RetryOn: []string{"sometimes"}}); !errors.Is(err, coreerrors.ErrInvalidConfig) {
+		t.Errorf("expected ErrInvalidConfig for unknown class, got %v", err)
+	}
+}
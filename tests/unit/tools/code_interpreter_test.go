@@ -0,0 +1,187 @@
+package tools_test
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ahhsitt/helloagents-go/pkg/tools/builtin"
+)
+
+func requireInterpreter(t *testing.T, program string) {
+	t.Helper()
+	if _, err := exec.LookPath(program); err != nil {
+		t.Skipf("%s not available", program)
+	}
+}
+
+func TestCodeInterpreter_Python(t *testing.T) {
+	requireInterpreter(t, "python3")
+	interp := builtin.NewCodeInterpreter()
+	defer interp.Close()
+	ctx := context.Background()
+
+	out, err := interp.Execute(ctx, map[string]interface{}{
+		"code": "import sys\nprint('hello')\nprint('warn', file=sys.stderr)\nsum(range(10))",
+	})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if out != "hello\n45\n[STDERR]\nwarn" {
+		t.Errorf("Execute() = %q", out)
+	}
+
+	out, err = interp.Execute(ctx, map[string]interface{}{"code": "print('before')\n1/0"})
+	if err != nil {
+		t.Fatalf("Execute() error = %v, want exception in result", err)
+	}
+	if !strings.HasPrefix(out, "before\n[ERROR]") || !strings.Contains(out, "ZeroDivisionError") || strings.Contains(out, "driver") {
+		t.Errorf("Execute() with exception = %q", out)
+	}
+}
+
+func TestCodeInterpreter_Session(t *testing.T) {
+	requireInterpreter(t, "python3")
+	interp := builtin.NewCodeInterpreter()
+	defer interp.Close()
+	ctx := context.Background()
+
+	run := func(args map[string]interface{}) string {
+		t.Helper()
+		out, err := interp.Execute(ctx, args)
+		if err != nil {
+			t.Fatalf("Execute(%v) error = %v", args, err)
+		}
+		return out
+	}
+
+	run(map[string]interface{}{"code": "data = [3, 1, 2]", "session_id": "s1"})
+	if out := run(map[string]interface{}{"code": "sorted(data)", "session_id": "s1"}); out != "[1, 2, 3]" {
+		t.Errorf("session state = %q", out)
+	}
+	if out := run(map[string]interface{}{"code": "'data' in globals()"}); out != "False" {
+		t.Errorf("one-shot run sees session state: %q", out)
+	}
+	if out := run(map[string]interface{}{"code": "'data' in globals()", "session_id": "s1", "reset": true}); out != "False" {
+		t.Errorf("reset session = %q", out)
+	}
+
+	interp.CloseSession("s1")
+	if out := run(map[string]interface{}{"code": "'data' in globals()", "session_id": "s1"}); out != "False" {
+		t.Errorf("closed session = %q", out)
+	}
+}
+
+func TestCodeInterpreter_Limits(t *testing.T) {
+	requireInterpreter(t, "python3")
+	interp := builtin.NewCodeInterpreter(
+		builtin.WithCodeTimeout(500*time.Millisecond),
+		builtin.WithCodeMaxOutput(10),
+		builtin.WithCodeMemoryLimit(256*1024*1024),
+	)
+	defer interp.Close()
+	ctx := context.Background()
+
+	interp.Execute(ctx, map[string]interface{}{"code": "x = 1", "session_id": "slow"})
+	start := time.Now()
+	_, err := interp.Execute(ctx, map[string]interface{}{"code": "while True: pass", "session_id": "slow"})
+	if err == nil || !strings.Contains(err.Error(), "timed out") || !strings.Contains(err.Error(), "session slow was reset") {
+		t.Errorf("Execute() error = %v, want timeout", err)
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Errorf("timeout took %v", time.Since(start))
+	}
+	out, err := interp.Execute(ctx, map[string]interface{}{"code": "'x' in globals()", "session_id": "slow"})
+	if err != nil || out != "False" {
+		t.Errorf("Execute() after timeout = %q, %v, want fresh session", out, err)
+	}
+
+	out, _ = interp.Execute(ctx, map[string]interface{}{"code": "print('a' * 100)"})
+	if out != "aaaaaaaaaa\n[WARNING] Output truncated" {
+		t.Errorf("Execute() truncated = %q", out)
+	}
+
+	out, err = interp.Execute(ctx, map[string]interface{}{"code": "b = bytearray(1024 * 1024 * 1024)"})
+	if err == nil && !strings.Contains(out, "MemoryError") {
+		t.Errorf("Execute() over memory limit = %q, want MemoryError", out)
+	}
+}
+
+func TestCodeInterpreter_JavaScript(t *testing.T) {
+	requireInterpreter(t, "node")
+	dir := t.TempDir()
+	interp := builtin.NewCodeInterpreter(builtin.WithCodeWorkDir(dir))
+	defer interp.Close()
+	ctx := context.Background()
+
+	out, err := interp.Execute(ctx, map[string]interface{}{
+		"language":   "javascript",
+		"code":       "const nums = [1, 2, 3]; console.log('sum', nums.reduce((a, b) => a + b)); require('fs').writeFileSync('out.txt', 'ok');",
+		"session_id": "js",
+	})
+	if err != nil || out != "sum 6" {
+		t.Errorf("Execute() = %q, %v", out, err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "out.txt")); string(data) != "ok" {
+		t.Errorf("work dir file = %q", data)
+	}
+
+	out, _ = interp.Execute(ctx, map[string]interface{}{"language": "javascript", "code": "Promise.resolve(nums.length)", "session_id": "js"})
+	if out != "3" {
+		t.Errorf("session state = %q", out)
+	}
+	out, _ = interp.Execute(ctx, map[string]interface{}{"language": "javascript", "code": "undefinedFn()"})
+	if !strings.Contains(out, "[ERROR]") || !strings.Contains(out, "ReferenceError") {
+		t.Errorf("Execute() with exception = %q", out)
+	}
+
+	if _, err := interp.Execute(ctx, map[string]interface{}{"language": "ruby", "code": "puts 1"}); err == nil {
+		t.Error("Execute() with unsupported language error = nil")
+	}
+}
+
+func TestDockerRuntime_Command(t *testing.T) {
+	runtime := builtin.NewDockerRuntime(
+		builtin.WithDockerImage(builtin.LanguagePython, "python:3.11"),
+		builtin.WithDockerArgs("--cpus=1"),
+	)
+	cmd, cleanup, err := runtime.Command(builtin.CodeCommand{
+		Language:    builtin.LanguagePython,
+		Program:     "python3",
+		Args:        []string{"-c", "print(1)"},
+		WorkDir:     "/tmp/work",
+		MemoryLimit: 64 * 1024 * 1024,
+	})
+	if err != nil || cleanup == nil {
+		t.Fatalf("Command() error = %v, cleanup = %v", err, cleanup != nil)
+	}
+	args := strings.Join(cmd.Args, " ")
+	for _, want := range []string{"docker run --rm -i", "--network none", "--memory 67108864", "-v /tmp/work:/workspace", "--cpus=1 python:3.11 python3 -c print(1)"} {
+		if !strings.Contains(args, want) {
+			t.Errorf("docker args %q missing %q", args, want)
+		}
+	}
+}
+
+func TestCodeInterpreter_TimeoutKillsChildProcesses(t *testing.T) {
+	requireInterpreter(t, "python3")
+	requireInterpreter(t, "sleep")
+	interp := builtin.NewCodeInterpreter(builtin.WithCodeTimeout(500 * time.Millisecond))
+	defer interp.Close()
+
+	// 子进程继承解释器的 stderr，且比超时活得更久
+	start := time.Now()
+	_, err := interp.Execute(context.Background(), map[string]interface{}{
+		"code": "import subprocess, time\nsubprocess.Popen(['sleep', '30'])\ntime.sleep(30)",
+	})
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("Execute() error = %v, want timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Execute() returned after %v, want the timeout to be enforced", elapsed)
+	}
+}
@@ -0,0 +1,74 @@
+package tools_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ahhsitt/helloagents-go/pkg/tools"
+)
+
+func TestCoerceArguments(t *testing.T) {
+	schema := tools.ParameterSchema{
+		Type: "object",
+		Properties: map[string]tools.PropertySchema{
+			"count":   {Type: "integer"},
+			"ratio":   {Type: "number"},
+			"enabled": {Type: "boolean"},
+			"label":   {Type: "string"},
+			"unit":    {Type: "string", Default: "celsius"},
+			"ids":     {Type: "array", Items: &tools.PropertySchema{Type: "number"}},
+			"filter": {Type: "object", Properties: map[string]tools.PropertySchema{
+				"limit": {Type: "integer", Default: float64(10)},
+			}},
+		},
+		Required: []string{"count"},
+	}
+	args := map[string]interface{}{
+		"count":   "3",
+		"ratio":   " 0.5 ",
+		"enabled": "true",
+		"label":   float64(42),
+		"ids":     `["1", 2]`,
+		"filter":  `{}`,
+		"extra":   "kept",
+	}
+
+	got := tools.CoerceArguments(schema, args)
+	want := map[string]interface{}{
+		"count":   float64(3),
+		"ratio":   0.5,
+		"enabled": true,
+		"label":   "42",
+		"unit":    "celsius",
+		"ids":     []interface{}{float64(1), float64(2)},
+		"filter":  map[string]interface{}{"limit": float64(10)},
+		"extra":   "kept",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CoerceArguments() = %#v, want %#v", got, want)
+	}
+	if args["count"] != "3" {
+		t.Error("CoerceArguments() modified the input map")
+	}
+
+	// 无法转换的值保持原样，由 Validate 报告
+	bad := tools.CoerceArguments(schema, map[string]interface{}{"count": "many"})
+	if err := tools.Validate(schema, bad); err == nil {
+		t.Error("Validate() with non-numeric count error = nil")
+	}
+}
+
+func TestDescribeParameters(t *testing.T) {
+	schema := tools.ParameterSchema{
+		Properties: map[string]tools.PropertySchema{
+			"b":    {Type: "number"},
+			"a":    {Type: "number"},
+			"mode": {Type: "string", Enum: []string{"fast", "slow"}, Default: "fast"},
+		},
+		Required: []string{"a"},
+	}
+	want := "a (number, required), b (number), mode (string, one of fast|slow, default fast)"
+	if got := tools.DescribeParameters(schema); got != want {
+		t.Errorf("DescribeParameters() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,248 @@
+package tools_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ahhsitt/helloagents-go/pkg/tools/builtin"
+)
+
+// newFileWorkspace 创建包含示例文件的工作区
+func newFileWorkspace(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	files := map[string]string{
+		"main.go":         "package main\n\nfunc main() {\n\tprintln(\"hello\")\n}\n",
+		"pkg/util.go":     "package pkg\n\n// Add 相加\nfunc Add(a, b int) int {\n\treturn a + b\n}\n",
+		"pkg/README.md":   "# pkg\n\nfunc in docs\n",
+		".git/config":     "func hidden\n",
+		"assets/logo.bin": "\x00\x01func\x02",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestFileTool_ReadAndList(t *testing.T) {
+	root := newFileWorkspace(t)
+	tool, err := builtin.NewFileTool(root)
+	if err != nil {
+		t.Fatalf("NewFileTool() error = %v", err)
+	}
+	ctx := context.Background()
+
+	content, err := tool.Execute(ctx, map[string]interface{}{"action": "read_file", "path": "pkg/util.go"})
+	if err != nil || !strings.Contains(content, "func Add") {
+		t.Errorf("read_file = %q, %v", content, err)
+	}
+	lines, err := tool.Execute(ctx, map[string]interface{}{"action": "read_file", "path": "main.go", "start_line": float64(3), "end_line": float64(4)})
+	if err != nil || lines != "func main() {\n\tprintln(\"hello\")\n" {
+		t.Errorf("read_file range = %q, %v", lines, err)
+	}
+	if _, err := tool.Execute(ctx, map[string]interface{}{"action": "read_file", "path": "assets/logo.bin"}); err == nil {
+		t.Error("read_file(binary) error = nil")
+	}
+
+	list, err := tool.Execute(ctx, map[string]interface{}{"action": "list_dir"})
+	if err != nil {
+		t.Fatalf("list_dir error = %v", err)
+	}
+	if !strings.Contains(list, "pkg/") || !strings.Contains(list, "main.go (") || strings.Contains(list, "pkg/util.go") {
+		t.Errorf("list_dir = %q", list)
+	}
+	list, _ = tool.Execute(ctx, map[string]interface{}{"action": "list_dir", "recursive": true})
+	if !strings.Contains(list, "pkg/util.go") || strings.Contains(list, ".git/config") {
+		t.Errorf("list_dir recursive = %q", list)
+	}
+}
+
+func TestFileTool_PathJail(t *testing.T) {
+	root := newFileWorkspace(t)
+	outside := t.TempDir()
+	os.WriteFile(filepath.Join(outside, "secret.txt"), []byte("secret"), 0644)
+	if err := os.Symlink(outside, filepath.Join(root, "escape")); err != nil {
+		t.Skipf("symlink not supported: %v", err)
+	}
+
+	tool, _ := builtin.NewFileTool(root)
+	ctx := context.Background()
+	for _, path := range []string{"../secret.txt", filepath.Join(outside, "secret.txt"), "escape/secret.txt", "escape/new.txt"} {
+		_, err := tool.Execute(ctx, map[string]interface{}{"action": "read_file", "path": path})
+		if !errors.Is(err, builtin.ErrPathOutsideWorkspace) {
+			t.Errorf("read_file(%s) error = %v, want outside workspace", path, err)
+		}
+	}
+	_, err := tool.Execute(ctx, map[string]interface{}{"action": "write_file", "path": "escape/new.txt", "content": "x"})
+	if !errors.Is(err, builtin.ErrPathOutsideWorkspace) {
+		t.Errorf("write_file(escape) error = %v, want outside workspace", err)
+	}
+	if _, err := os.Stat(filepath.Join(outside, "new.txt")); !os.IsNotExist(err) {
+		t.Error("write_file escaped the workspace")
+	}
+
+	// 绝对路径在工作区内时允许访问
+	if _, err := tool.Execute(ctx, map[string]interface{}{"action": "read_file", "path": filepath.Join(tool.Root(), "main.go")}); err != nil {
+		t.Errorf("read_file(absolute) error = %v", err)
+	}
+}
+
+func TestFileTool_DanglingSymlink(t *testing.T) {
+	root := newFileWorkspace(t)
+	outside := t.TempDir()
+	target := filepath.Join(outside, "pwned.txt")
+	if err := os.Symlink(target, filepath.Join(root, "evil")); err != nil {
+		t.Skipf("symlink not supported: %v", err)
+	}
+	os.Symlink(filepath.Join(outside, "missing"), filepath.Join(root, "evildir"))
+
+	tool, _ := builtin.NewFileTool(root)
+	ctx := context.Background()
+	for _, args := range []map[string]interface{}{
+		{"action": "write_file", "path": "evil", "content": "x"},
+		{"action": "write_file", "path": "evildir/new.txt", "content": "x"},
+		{"action": "apply_patch", "patch": "--- /dev/null\n+++ b/evil\n@@ -0,0 +1 @@\n+x\n"},
+	} {
+		if _, err := tool.Execute(ctx, args); !errors.Is(err, builtin.ErrPathOutsideWorkspace) {
+			t.Errorf("%v error = %v, want outside workspace", args, err)
+		}
+	}
+	if entries, _ := os.ReadDir(outside); len(entries) != 0 {
+		t.Errorf("write escaped the workspace: %v", entries)
+	}
+}
+
+func TestFileTool_WriteFile(t *testing.T) {
+	root := newFileWorkspace(t)
+	tool, _ := builtin.NewFileTool(root, builtin.WithFileMaxSize(100))
+	ctx := context.Background()
+
+	args := map[string]interface{}{"action": "write_file", "path": "main.go", "content": "package main\n\nfunc main() {}\n", "dry_run": true}
+	diff, err := tool.Execute(ctx, args)
+	if err != nil {
+		t.Fatalf("write_file dry run error = %v", err)
+	}
+	for _, want := range []string{"--- a/main.go", "+++ b/main.go", "-func main() {", "+func main() {}"} {
+		if !strings.Contains(diff, want) {
+			t.Errorf("dry run diff missing %q:\n%s", want, diff)
+		}
+	}
+	if data, _ := os.ReadFile(filepath.Join(root, "main.go")); !strings.Contains(string(data), "hello") {
+		t.Error("dry run modified the file")
+	}
+
+	if _, err := tool.Execute(ctx, map[string]interface{}{"action": "write_file", "path": "new/dir/a.txt", "content": "a\n"}); err != nil {
+		t.Fatalf("write_file error = %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(root, "new/dir/a.txt")); string(data) != "a\n" {
+		t.Errorf("written file = %q", data)
+	}
+	if _, err := tool.Execute(ctx, map[string]interface{}{"action": "write_file", "path": "big.txt", "content": strings.Repeat("x", 101)}); err == nil {
+		t.Error("write_file over size limit error = nil")
+	}
+
+	readOnly, _ := builtin.NewFileTool(root, builtin.WithFileReadOnly(true))
+	if err := readOnly.Validate(map[string]interface{}{"action": "write_file", "path": "a.txt", "content": ""}); err == nil {
+		t.Error("Validate(write_file) in read-only mode error = nil")
+	}
+}
+
+func TestFileTool_Search(t *testing.T) {
+	root := newFileWorkspace(t)
+	tool, _ := builtin.NewFileTool(root)
+	ctx := context.Background()
+
+	result, err := tool.Execute(ctx, map[string]interface{}{"action": "search", "pattern": `func \w+\(`})
+	if err != nil {
+		t.Fatalf("search error = %v", err)
+	}
+	if !strings.Contains(result, "main.go:3: func main() {") || !strings.Contains(result, "pkg/util.go:4: func Add") {
+		t.Errorf("search = %q", result)
+	}
+	if strings.Contains(result, ".git") || strings.Contains(result, "logo.bin") {
+		t.Errorf("search included skipped files: %q", result)
+	}
+
+	result, _ = tool.Execute(ctx, map[string]interface{}{"action": "search", "pattern": "func", "glob": "*.md", "path": "pkg"})
+	if result != "pkg/README.md:3: func in docs" {
+		t.Errorf("search with glob = %q", result)
+	}
+	if _, err := tool.Execute(ctx, map[string]interface{}{"action": "search", "pattern": "("}); err == nil {
+		t.Error("search with invalid pattern error = nil")
+	}
+}
+
+func TestFileTool_ApplyPatch(t *testing.T) {
+	root := newFileWorkspace(t)
+	tool, _ := builtin.NewFileTool(root)
+	ctx := context.Background()
+
+	// 行号有偏差时仍按上下文匹配
+	patch := `diff --git a/pkg/util.go b/pkg/util.go
+--- a/pkg/util.go
++++ b/pkg/util.go
+@@ -5,3 +5,7 @@
+ func Add(a, b int) int {
+ 	return a + b
+ }
++
++func Sub(a, b int) int {
++	return a - b
++}
+--- /dev/null
++++ b/notes.txt
+@@ -0,0 +1,1 @@
++created by patch
+--- a/main.go
++++ /dev/null
+@@ -1,5 +0,0 @@
+-package main
+-
+-func main() {
+-	println("hello")
+-}
+`
+	dry, err := tool.Execute(ctx, map[string]interface{}{"action": "apply_patch", "patch": patch, "dry_run": true})
+	if err != nil {
+		t.Fatalf("apply_patch dry run error = %v", err)
+	}
+	if !strings.Contains(dry, "+func Sub(a, b int) int {") || !strings.Contains(dry, "+++ /dev/null") {
+		t.Errorf("dry run = %q", dry)
+	}
+	if _, err := os.Stat(filepath.Join(root, "notes.txt")); !os.IsNotExist(err) {
+		t.Error("dry run created a file")
+	}
+
+	if _, err := tool.Execute(ctx, map[string]interface{}{"action": "apply_patch", "patch": patch}); err != nil {
+		t.Fatalf("apply_patch error = %v", err)
+	}
+	util, _ := os.ReadFile(filepath.Join(root, "pkg/util.go"))
+	if !strings.HasSuffix(string(util), "}\n\nfunc Sub(a, b int) int {\n\treturn a - b\n}\n") {
+		t.Errorf("patched util.go = %q", util)
+	}
+	if notes, _ := os.ReadFile(filepath.Join(root, "notes.txt")); string(notes) != "created by patch\n" {
+		t.Errorf("notes.txt = %q", notes)
+	}
+	if _, err := os.Stat(filepath.Join(root, "main.go")); !os.IsNotExist(err) {
+		t.Error("main.go was not deleted")
+	}
+
+	// 上下文不匹配时不写入任何文件
+	bad := "@@ -1,2 +1,2 @@\n-# pkg\n+# package\n@@ -10,1 +10,1 @@\n-missing line\n+replacement\n"
+	if _, err := tool.Execute(ctx, map[string]interface{}{"action": "apply_patch", "path": "pkg/README.md", "patch": bad}); err == nil || !strings.Contains(err.Error(), "hunk 2") {
+		t.Errorf("apply_patch(mismatch) error = %v, want hunk 2 error", err)
+	}
+	if readme, _ := os.ReadFile(filepath.Join(root, "pkg/README.md")); !strings.HasPrefix(string(readme), "# pkg\n") {
+		t.Errorf("README.md modified after failed patch: %q", readme)
+	}
+}
@@ -0,0 +1,111 @@
+package tools_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ahhsitt/helloagents-go/pkg/memory/store"
+	"github.com/ahhsitt/helloagents-go/pkg/tools"
+)
+
+// newFlakyTool 参数 fail 为 true 时返回错误的测试工具
+func newFlakyTool(name string) tools.Tool {
+	return tools.NewFuncTool(name, "Flaky tool", tools.ParameterSchema{Type: "object"},
+		func(ctx context.Context, args map[string]interface{}) (string, error) {
+			if fail, _ := args["fail"].(bool); fail {
+				return "", errors.New("upstream unavailable")
+			}
+			return strings.Repeat("ok ", 40), nil
+		})
+}
+
+func TestExecutionRecorder_MiddlewareAndStats(t *testing.T) {
+	ctx := context.Background()
+	recorder := tools.NewExecutionRecorder(store.NewMemoryDocumentStore())
+	registry := tools.NewRegistry()
+	registry.MustRegister(newFlakyTool("search"))
+	registry.MustRegister(newFlakyTool("fetch"))
+	registry.Use(recorder.Middleware())
+	executor := tools.NewExecutor(registry)
+
+	executor.Execute(ctx, "search", map[string]interface{}{"q": "go"})
+	executor.Execute(ctx, "search", map[string]interface{}{"fail": true})
+	executor.Execute(ctx, "fetch", map[string]interface{}{"fail": true})
+	executor.Execute(ctx, "fetch", map[string]interface{}{"fail": true})
+
+	all, err := recorder.Query(ctx, tools.ExecutionQuery{})
+	if err != nil || len(all) != 4 {
+		t.Fatalf("Query() = %d records, %v, want 4", len(all), err)
+	}
+	if all[0].Tool != "fetch" || all[0].Timestamp.Before(all[3].Timestamp) {
+		t.Errorf("Query() not newest first: %+v", all)
+	}
+
+	success := true
+	ok, _ := recorder.Query(ctx, tools.ExecutionQuery{Tool: "search", Success: &success})
+	if len(ok) != 1 || ok[0].ResultTokens == 0 || ok[0].ArgsHash != tools.HashArguments(map[string]interface{}{"q": "go"}) {
+		t.Errorf("successful search records = %+v", ok)
+	}
+	if limited, _ := recorder.Query(ctx, tools.ExecutionQuery{Limit: 1}); len(limited) != 1 {
+		t.Errorf("Query(Limit: 1) = %d records", len(limited))
+	}
+	if future, _ := recorder.Query(ctx, tools.ExecutionQuery{Since: time.Now().Add(time.Hour)}); len(future) != 0 {
+		t.Errorf("Query(Since: future) = %d records, want 0", len(future))
+	}
+
+	stats, err := recorder.Stats(ctx, tools.ExecutionQuery{})
+	if err != nil || len(stats) != 2 {
+		t.Fatalf("Stats() = %+v, %v", stats, err)
+	}
+	// 失败最多的工具排在前面
+	if stats[0].Tool != "fetch" || stats[0].Failures != 2 || stats[0].FailureRate != 1 || stats[0].LastError == "" {
+		t.Errorf("stats[0] = %+v", stats[0])
+	}
+	if stats[1].Calls != 2 || stats[1].FailureRate != 0.5 || stats[1].AvgResultTokens != stats[1].TotalResultTokens/2 {
+		t.Errorf("stats[1] = %+v", stats[1])
+	}
+
+	if err := recorder.Clear(ctx); err != nil {
+		t.Fatalf("Clear() error = %v", err)
+	}
+	if all, _ := recorder.Query(ctx, tools.ExecutionQuery{}); len(all) != 0 {
+		t.Errorf("Query() after Clear = %d records", len(all))
+	}
+}
+
+func TestExecutionRecorder_SQLite(t *testing.T) {
+	docs, err := store.NewSQLiteDocumentStore(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatalf("NewSQLiteDocumentStore() error = %v", err)
+	}
+	defer docs.Close()
+
+	ctx := context.Background()
+	recorder := tools.NewExecutionRecorder(docs, tools.WithHistoryCollection("calls"))
+	base := time.Now().Add(-time.Hour)
+	for i, success := range []bool{true, false, true} {
+		rec := tools.ExecutionRecord{Tool: "sql", Success: success, Duration: time.Duration(i+1) * time.Second, Timestamp: base.Add(time.Duration(i) * time.Minute)}
+		if err := recorder.Record(ctx, rec); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	}
+
+	failed := false
+	records, err := recorder.Query(ctx, tools.ExecutionQuery{Tool: "sql", Success: &failed})
+	if err != nil || len(records) != 1 || records[0].Duration != 2*time.Second {
+		t.Errorf("failed records = %+v, %v", records, err)
+	}
+	recent, _ := recorder.Query(ctx, tools.ExecutionQuery{Since: base.Add(30 * time.Second)})
+	if len(recent) != 2 {
+		t.Errorf("Query(Since) = %d records, want 2", len(recent))
+	}
+
+	stats, _ := recorder.Stats(ctx, tools.ExecutionQuery{})
+	if len(stats) != 1 || stats[0].MaxDuration != 3*time.Second || stats[0].AvgDuration != 2*time.Second {
+		t.Errorf("Stats() = %+v", stats)
+	}
+}
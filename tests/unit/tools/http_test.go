@@ -0,0 +1,129 @@
+package tools_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ahhsitt/helloagents-go/pkg/tools/builtin"
+)
+
+func newHTTPTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/echo", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{
+			"method":        r.Method,
+			"body":          string(body),
+			"contentType":   r.Header.Get("Content-Type"),
+			"authorization": r.Header.Get("Authorization"),
+			"trace":         r.Header.Get("X-Trace"),
+		})
+	})
+	mux.HandleFunc("/large", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, strings.Repeat("word ", 5000))
+	})
+	mux.HandleFunc("/redirect", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, r.URL.Query().Get("to"), http.StatusFound)
+	})
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(2 * time.Second):
+		case <-r.Context().Done():
+		}
+	})
+	ts := httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestHTTPTool_Requests(t *testing.T) {
+	ts := newHTTPTestServer(t)
+	tool := builtin.NewHTTPTool(
+		builtin.WithHTTPAllowedDomains("127.0.0.1"),
+		builtin.WithHTTPHeaders(map[string]string{"Authorization": "Bearer configured"}),
+	)
+	ctx := context.Background()
+
+	result, err := tool.Execute(ctx, map[string]interface{}{
+		"method":  "post",
+		"url":     ts.URL + "/echo",
+		"headers": map[string]interface{}{"X-Trace": "abc", "Authorization": "Bearer agent"},
+		"body":    map[string]interface{}{"name": "widget"},
+	})
+	if err != nil {
+		t.Fatalf("Execute(POST) error = %v", err)
+	}
+	for _, want := range []string{"HTTP 200 OK", "Content-Type: application/json", `"method":"POST"`,
+		`"contentType":"application/json"`, `"trace":"abc"`, `"authorization":"Bearer configured"`, `{\"name\":\"widget\"}`} {
+		if !strings.Contains(result, want) {
+			t.Errorf("Execute(POST) result missing %q:\n%s", want, result)
+		}
+	}
+
+	if _, err := tool.Execute(ctx, map[string]interface{}{"method": "DELETE", "url": ts.URL + "/echo"}); err == nil {
+		t.Error("Execute(DELETE) error = nil, want unsupported method")
+	}
+	if _, err := tool.Execute(ctx, map[string]interface{}{"url": "file:///etc/passwd"}); err == nil {
+		t.Error("Execute(file://) error = nil, want unsupported scheme")
+	}
+}
+
+func TestHTTPTool_DomainPolicy(t *testing.T) {
+	ts := newHTTPTestServer(t)
+	ctx := context.Background()
+
+	allowOther := builtin.NewHTTPTool(builtin.WithHTTPAllowedDomains("*.example.com"))
+	if _, err := allowOther.Execute(ctx, map[string]interface{}{"url": ts.URL + "/echo"}); err == nil ||
+		!strings.Contains(err.Error(), "not in the allowed list") {
+		t.Errorf("Execute() outside allowlist error = %v", err)
+	}
+
+	deny := builtin.NewHTTPTool(builtin.WithHTTPAllowedDomains("127.0.0.1"), builtin.WithHTTPDeniedDomains("127.0.0.1"))
+	if _, err := deny.Execute(ctx, map[string]interface{}{"url": ts.URL + "/echo"}); err == nil ||
+		!strings.Contains(err.Error(), "denied") {
+		t.Errorf("Execute() on denied domain error = %v", err)
+	}
+
+	// 重定向到白名单之外的域名被拒绝
+	tool := builtin.NewHTTPTool(builtin.WithHTTPAllowedDomains("127.0.0.1"))
+	_, err := tool.Execute(ctx, map[string]interface{}{"url": ts.URL + "/redirect?to=http://localhost/echo"})
+	if err == nil || !strings.Contains(err.Error(), "localhost is not in the allowed list") {
+		t.Errorf("Execute() redirect outside allowlist error = %v", err)
+	}
+	result, err := tool.Execute(ctx, map[string]interface{}{"url": ts.URL + "/redirect?to=/echo"})
+	if err != nil || !strings.Contains(result, `"method":"GET"`) {
+		t.Errorf("Execute() allowed redirect = %q, %v", result, err)
+	}
+
+	noRedirect := builtin.NewHTTPTool(builtin.WithHTTPMaxRedirects(0))
+	result, err = noRedirect.Execute(ctx, map[string]interface{}{"url": ts.URL + "/redirect?to=/echo"})
+	if err != nil || !strings.HasPrefix(result, "HTTP 302") {
+		t.Errorf("Execute() without redirects = %q, %v, want 302", result, err)
+	}
+}
+
+func TestHTTPTool_LimitsResponseAndTime(t *testing.T) {
+	ts := newHTTPTestServer(t)
+	ctx := context.Background()
+
+	tool := builtin.NewHTTPTool(builtin.WithHTTPMaxTokens(100), builtin.WithHTTPTimeout(100*time.Millisecond))
+	result, err := tool.Execute(ctx, map[string]interface{}{"url": ts.URL + "/large"})
+	if err != nil {
+		t.Fatalf("Execute(large) error = %v", err)
+	}
+	if !strings.Contains(result, "[truncated: response exceeds 100 tokens]") || len(result) > 1000 {
+		t.Errorf("Execute(large) result length = %d, want truncated", len(result))
+	}
+
+	if _, err := tool.Execute(ctx, map[string]interface{}{"url": ts.URL + "/slow"}); err == nil {
+		t.Error("Execute(slow) error = nil, want timeout")
+	}
+}
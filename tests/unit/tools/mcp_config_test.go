@@ -0,0 +1,46 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ahhsitt/helloagents-go/pkg/tools/builtin"
+)
+
+func writeMCPConfig(t *testing.T, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "mcp.json")
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestNewMCPToolsFromConfig(t *testing.T) {
+	path := writeMCPConfig(t, `{"mcpServers": {
+		"filesystem": {"command": "npx", "args": ["-y", "@modelcontextprotocol/server-filesystem", "/tmp"]},
+		"sqlite": {"command": "uvx", "args": ["mcp-server-sqlite"]},
+		"old": {"command": "old", "disabled": true}
+	}}`)
+
+	mcpTools, err := builtin.NewMCPToolsFromConfig(path, builtin.WithMCPAutoExpand(false))
+	if err != nil {
+		t.Fatalf("NewMCPToolsFromConfig() error = %v", err)
+	}
+	var names []string
+	for _, tool := range mcpTools {
+		names = append(names, tool.Name())
+	}
+	if got := strings.Join(names, ","); got != "filesystem,sqlite" {
+		t.Errorf("tool names = %q, want filesystem,sqlite", got)
+	}
+}
+
+func TestNewMCPToolsFromConfig_RejectsRemoteServers(t *testing.T) {
+	path := writeMCPConfig(t, `{"mcpServers": {"remote": {"url": "https://example.com/mcp"}}}`)
+	if _, err := builtin.NewMCPToolsFromConfig(path); err == nil || !strings.Contains(err.Error(), "stdio") {
+		t.Errorf("NewMCPToolsFromConfig() error = %v, want stdio-only error", err)
+	}
+}
@@ -0,0 +1,48 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ahhsitt/helloagents-go/pkg/tools"
+	"github.com/ahhsitt/helloagents-go/pkg/tools/builtin"
+)
+
+func TestMCPWrappedTool_CoercesArguments(t *testing.T) {
+	mcpTool := builtin.NewMCPTool(builtin.WithMCPName("calc"))
+	defer mcpTool.Close()
+
+	var add tools.ToolWithValidation
+	for _, tool := range mcpTool.GetExpandedTools() {
+		if tool.Name() == "calc_add" {
+			add = tool.(tools.ToolWithValidation)
+		}
+	}
+	if add == nil {
+		t.Fatal("calc_add not found in expanded tools")
+	}
+
+	args := map[string]interface{}{"a": "2", "b": " 3.5"}
+	if err := add.Validate(args); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	result, err := add.Execute(context.Background(), args)
+	if err != nil || !strings.Contains(result, "5.50") {
+		t.Errorf("Execute() = %q, %v, want string numbers coerced", result, err)
+	}
+
+	err = add.Validate(map[string]interface{}{"a": "two"})
+	if err == nil {
+		t.Fatal("Validate() with invalid arguments error = nil")
+	}
+	for _, want := range []string{"add", "a (number, required)", "b (number, required)"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Validate() error = %q, want it to mention %q", err, want)
+		}
+	}
+	if _, err := add.Execute(context.Background(), map[string]interface{}{"a": 1}); err == nil ||
+		!strings.Contains(err.Error(), "missing required parameter: b") {
+		t.Errorf("Execute() without b error = %v, want missing parameter", err)
+	}
+}
@@ -0,0 +1,240 @@
+package tools_test
+
+import (
+	"bytes"
+	"context"
+	stderrors "errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/ahhsitt/helloagents-go/pkg/core/errors"
+	"github.com/ahhsitt/helloagents-go/pkg/core/retry"
+	"github.com/ahhsitt/helloagents-go/pkg/otel"
+	"github.com/ahhsitt/helloagents-go/pkg/tools"
+)
+
+// tagMiddleware 在结果前后添加标记，用于检查中间件顺序
+func tagMiddleware(tag string) tools.Middleware {
+	return func(next tools.Tool) tools.Tool {
+		return tools.WrapExecute(next, func(ctx context.Context, args map[string]interface{}) (string, error) {
+			result, err := next.Execute(ctx, args)
+			return tag + "(" + result + ")", err
+		})
+	}
+}
+
+func TestRegistry_Use(t *testing.T) {
+	registry := tools.NewRegistry()
+	registry.MustRegister(newMockTool("before"))
+	registry.Use(tagMiddleware("a"), tagMiddleware("b"))
+	registry.MustRegister(newMockTool("after"))
+
+	for _, name := range []string{"before", "after"} {
+		tool, err := registry.Get(name)
+		if err != nil {
+			t.Fatalf("Get(%q) error = %v", name, err)
+		}
+		if tool.Name() != name {
+			t.Errorf("Name() = %q, want %q", tool.Name(), name)
+		}
+		if out, _ := tool.Execute(context.Background(), nil); out != "a(b(mock result))" {
+			t.Errorf("Execute(%q) = %q", name, out)
+		}
+		if _, ok := tools.Unwrap(tool).(*mockTool); !ok {
+			t.Errorf("Unwrap(%q) = %T, want *mockTool", name, tools.Unwrap(tool))
+		}
+	}
+
+	registry.Use(tagMiddleware("c"))
+	tool, _ := registry.Get("before")
+	if out, _ := tool.Execute(context.Background(), nil); out != "a(b(c(mock result)))" {
+		t.Errorf("Execute() after second Use = %q", out)
+	}
+	if defs := registry.ToDefinitions(); len(defs) != 2 {
+		t.Errorf("ToDefinitions() returned %d definitions", len(defs))
+	}
+}
+
+func TestWrapExecute_PreservesInterfaces(t *testing.T) {
+	validated := tools.NewFuncTool("echo", "Echo input", tools.ParameterSchema{Type: "object"},
+		func(ctx context.Context, args map[string]interface{}) (string, error) { return "ok", nil },
+		tools.WithValidator(func(args map[string]interface{}) error {
+			if args["input"] == nil {
+				return stderrors.New("input is required")
+			}
+			return nil
+		}),
+		tools.WithExamples(tools.ToolExample{Description: "echo", Arguments: map[string]interface{}{"input": "hi"}}),
+	)
+	wrapped := tagMiddleware("x")(validated)
+
+	validator, ok := wrapped.(tools.ToolWithValidation)
+	if !ok {
+		t.Fatal("wrapped tool does not implement ToolWithValidation")
+	}
+	if err := validator.Validate(map[string]interface{}{}); err == nil {
+		t.Error("Validate() error = nil, want inner validation error")
+	}
+	if manual := tools.NewToolManual(wrapped); len(manual.Examples) != 1 {
+		t.Errorf("manual examples = %v", manual.Examples)
+	}
+
+	if _, ok := tagMiddleware("x")(newMockTool("plain")).(tools.ToolWithValidation); ok {
+		t.Error("wrapped tool without validation implements ToolWithValidation")
+	}
+}
+
+func TestRedactArguments(t *testing.T) {
+	args := map[string]interface{}{
+		"query":   "select",
+		"API_KEY": "sk-123",
+		"headers": map[string]interface{}{"Authorization": "Bearer x", "Accept": "json"},
+		"items":   []interface{}{map[string]interface{}{"password": "p"}},
+	}
+	redacted := tools.RedactArguments(args)
+
+	if redacted["query"] != "select" || redacted["API_KEY"] != tools.RedactedValue {
+		t.Errorf("RedactArguments() = %v", redacted)
+	}
+	headers := redacted["headers"].(map[string]interface{})
+	if headers["Authorization"] != tools.RedactedValue || headers["Accept"] != "json" {
+		t.Errorf("nested headers = %v", headers)
+	}
+	if item := redacted["items"].([]interface{})[0].(map[string]interface{}); item["password"] != tools.RedactedValue {
+		t.Errorf("array item = %v", item)
+	}
+	if args["API_KEY"] != "sk-123" {
+		t.Error("RedactArguments() modified the original arguments")
+	}
+
+	if custom := tools.RedactArguments(args, "query"); custom["query"] != tools.RedactedValue || custom["API_KEY"] != "sk-123" {
+		t.Errorf("RedactArguments(query) = %v", custom)
+	}
+}
+
+func TestLoggingMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	failing := tools.NewFuncTool("fail", "Always fails", tools.ParameterSchema{Type: "object"},
+		func(ctx context.Context, args map[string]interface{}) (string, error) {
+			return "", stderrors.New("boom")
+		})
+
+	mw := tools.LoggingMiddleware(logger)
+	mw(newMockTool("ok")).Execute(context.Background(), map[string]interface{}{"token": "secret-value"})
+	mw(failing).Execute(context.Background(), nil)
+
+	out := buf.String()
+	for _, want := range []string{"level=DEBUG msg=\"tool executed\" tool=ok", "[REDACTED]", "result_size=11", "level=WARN msg=\"tool execution failed\" tool=fail", "error=boom"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("log missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "secret-value") {
+		t.Errorf("log contains unredacted argument:\n%s", out)
+	}
+}
+
+func TestTracingMiddleware(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	metrics := otel.NewInMemoryMetrics()
+	failing := tools.NewFuncTool("fail", "Always fails", tools.ParameterSchema{Type: "object"},
+		func(ctx context.Context, args map[string]interface{}) (string, error) {
+			return "", stderrors.New("boom")
+		})
+
+	mw := tools.TracingMiddleware(otel.NewTracer(tp.Tracer("test")), metrics)
+	mw(newMockTool("ok")).Execute(context.Background(), map[string]interface{}{"password": "hunter2"})
+	mw(failing).Execute(context.Background(), nil)
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans, want 2", len(spans))
+	}
+	attrs := map[string]string{}
+	for _, kv := range spans[0].Attributes {
+		attrs[string(kv.Key)] = kv.Value.Emit()
+	}
+	if spans[0].Name != "tool.execute" || attrs[otel.AttrToolName] != "ok" || attrs[otel.AttrToolArgs] != `{"password":"[REDACTED]"}` {
+		t.Errorf("span = %s %v", spans[0].Name, attrs)
+	}
+	if spans[1].Status.Description != "boom" {
+		t.Errorf("error span status = %v", spans[1].Status)
+	}
+	if calls := metrics.GetCounterValue(otel.MetricToolCalls); calls != 2 {
+		t.Errorf("tool.calls = %d, want 2", calls)
+	}
+	if errs := metrics.GetCounterValue(otel.MetricToolErrors); errs != 1 {
+		t.Errorf("tool.errors = %d, want 1", errs)
+	}
+}
+
+func TestTimeoutMiddleware(t *testing.T) {
+	// 工具忽略上下文取消，中间件仍按时返回
+	stuck := tools.NewFuncTool("stuck", "Never returns in time", tools.ParameterSchema{Type: "object"},
+		func(ctx context.Context, args map[string]interface{}) (string, error) {
+			time.Sleep(500 * time.Millisecond)
+			return "late", nil
+		})
+	tool := tools.TimeoutMiddleware(20 * time.Millisecond)(stuck)
+
+	start := time.Now()
+	_, err := tool.Execute(context.Background(), nil)
+	if !stderrors.Is(err, errors.ErrToolTimeout) {
+		t.Errorf("Execute() error = %v, want ErrToolTimeout", err)
+	}
+	if time.Since(start) > 200*time.Millisecond {
+		t.Errorf("Execute() took %v", time.Since(start))
+	}
+
+	if out, err := tools.TimeoutMiddleware(time.Second)(newMockTool("fast")).Execute(context.Background(), nil); err != nil || out != "mock result" {
+		t.Errorf("Execute() = %q, %v", out, err)
+	}
+}
+
+func TestRetryMiddleware(t *testing.T) {
+	attempts := 0
+	flaky := tools.NewFuncTool("flaky", "Fails twice", tools.ParameterSchema{Type: "object"},
+		func(ctx context.Context, args map[string]interface{}) (string, error) {
+			attempts++
+			if attempts < 3 {
+				return "", errors.ErrRateLimited
+			}
+			return "done", nil
+		})
+	policy := retry.Policy{MaxAttempts: 3, InitialBackoff: time.Millisecond}
+
+	out, err := tools.RetryMiddleware(policy)(flaky).Execute(context.Background(), nil)
+	if err != nil || out != "done" || attempts != 3 {
+		t.Errorf("Execute() = %q, %v after %d attempts", out, err, attempts)
+	}
+
+	// 不可重试的错误只尝试一次
+	attempts = 0
+	failing := tools.NewFuncTool("fail", "Always fails", tools.ParameterSchema{Type: "object"},
+		func(ctx context.Context, args map[string]interface{}) (string, error) {
+			attempts++
+			return "", stderrors.New("bad input")
+		})
+	if _, err := tools.RetryMiddleware(policy)(failing).Execute(context.Background(), nil); err == nil || attempts != 1 {
+		t.Errorf("Execute() error = %v after %d attempts, want 1", err, attempts)
+	}
+}
+
+func TestTruncateResultMiddleware(t *testing.T) {
+	tool := tools.TruncateResultMiddleware(4)(newMockTool("long"))
+	if out, _ := tool.Execute(context.Background(), nil); out != "mock\n[WARNING] Output truncated" {
+		t.Errorf("Execute() = %q", out)
+	}
+
+	short := tools.TruncateResultMiddleware(100)(newMockTool("short"))
+	if out, _ := short.Execute(context.Background(), nil); out != "mock result" {
+		t.Errorf("Execute() = %q", out)
+	}
+}
@@ -541,3 +541,106 @@ func extractNoteID(result string) string {
 	}
 	return ""
 }
+
+func TestNoteTool_ExportImportJSONL(t *testing.T) {
+	src, _ := setupNoteTool(t)
+	ctx := context.Background()
+
+	for _, title := range []string{"部署流程", "数据库配置"} {
+		if _, err := src.Execute(ctx, map[string]interface{}{
+			"action":    "create",
+			"title":     title,
+			"content":   title + "的详细说明",
+			"note_type": "reference",
+			"tags":      []string{"ops"},
+		}); err != nil {
+			t.Fatalf("创建笔记失败: %v", err)
+		}
+	}
+
+	archive := filepath.Join(t.TempDir(), "notes.jsonl")
+	count, err := src.ExportNotes(archive, builtin.NoteArchiveJSONL)
+	if err != nil || count != 2 {
+		t.Fatalf("导出失败: count=%d, err=%v", count, err)
+	}
+
+	dst, _ := setupNoteTool(t)
+	result, err := dst.ImportNotes(archive)
+	if err != nil {
+		t.Fatalf("导入失败: %v", err)
+	}
+	if result.Count(builtin.NoteImportCreated) != 2 {
+		t.Errorf("期望新建 2 条笔记，得到: %s", result)
+	}
+
+	notes, _ := dst.SearchNotes("部署", 10)
+	if len(notes) != 1 || notes[0].Type != "reference" || len(notes[0].Tags) != 1 {
+		t.Errorf("导入的笔记内容不正确: %+v", notes)
+	}
+	if notes[0].ID != result.Entries[0].SourceID {
+		t.Errorf("无冲突时应保留原 ID，得到 %s", notes[0].ID)
+	}
+
+	// 再次导入：默认跳过冲突
+	result, err = dst.ImportNotes(archive)
+	if err != nil {
+		t.Fatalf("再次导入失败: %v", err)
+	}
+	if result.Count(builtin.NoteImportSkipped) != 2 {
+		t.Errorf("期望跳过 2 条冲突笔记，得到: %s", result)
+	}
+}
+
+func TestNoteTool_ImportMarkdownConflictsAndDryRun(t *testing.T) {
+	src, _ := setupNoteTool(t)
+	dst, _ := setupNoteTool(t)
+	ctx := context.Background()
+
+	_, _ = src.Execute(ctx, map[string]interface{}{"action": "create", "title": "会议纪要", "content": "新版本"})
+	_, _ = src.Execute(ctx, map[string]interface{}{"action": "create", "title": "待办事项", "content": "检查日志"})
+	createResult, _ := dst.Execute(ctx, map[string]interface{}{"action": "create", "title": "会议纪要", "content": "旧版本"})
+	existingID := extractNoteID(createResult)
+
+	archiveDir := filepath.Join(t.TempDir(), "archive")
+	if _, err := src.Execute(ctx, map[string]interface{}{"action": "export", "path": archiveDir, "format": "markdown"}); err != nil {
+		t.Fatalf("导出失败: %v", err)
+	}
+
+	// 预览不写入
+	preview, err := dst.Execute(ctx, map[string]interface{}{
+		"action":   "import",
+		"path":     archiveDir,
+		"conflict": "overwrite",
+		"dry_run":  true,
+	})
+	if err != nil {
+		t.Fatalf("预览失败: %v", err)
+	}
+	if !strings.Contains(preview, "覆盖: 1") || !strings.Contains(preview, "新建: 1") {
+		t.Errorf("预览结果不正确: %s", preview)
+	}
+	if notes, _ := dst.ListNotes("", 0); len(notes) != 1 || notes[0].Content != "旧版本" {
+		t.Errorf("预览不应写入笔记: %+v", notes)
+	}
+
+	// 按标题冲突覆盖，保留已有 ID
+	result, err := dst.ImportNotes(archiveDir, builtin.WithConflictPolicy(builtin.NoteConflictOverwrite))
+	if err != nil {
+		t.Fatalf("导入失败: %v", err)
+	}
+	notes, _ := dst.ListNotes("", 0)
+	if len(notes) != 2 {
+		t.Fatalf("期望 2 条笔记，得到 %d: %s", len(notes), result)
+	}
+	if notes[0].ID != existingID || notes[0].Content != "新版本" {
+		t.Errorf("冲突笔记应被覆盖并保留 ID，得到: %+v", notes[0])
+	}
+
+	// rename 策略保留两份
+	if _, err := dst.ImportNotes(archiveDir, builtin.WithConflictPolicy(builtin.NoteConflictRename)); err != nil {
+		t.Fatalf("导入失败: %v", err)
+	}
+	if notes, _ := dst.ListNotes("", 0); len(notes) != 4 {
+		t.Errorf("rename 策略应新增 2 条笔记，得到 %d", len(notes))
+	}
+}
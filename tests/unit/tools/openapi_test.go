@@ -0,0 +1,251 @@
+package tools_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ahhsitt/helloagents-go/pkg/tools"
+	"github.com/ahhsitt/helloagents-go/pkg/tools/openapi"
+)
+
+// petstoreSpec 测试用 OpenAPI 3 规范，服务器地址为相对路径
+const petstoreSpec = `{
+  "openapi": "3.0.3",
+  "info": {"title": "Petstore", "version": "1.0"},
+  "servers": [{"url": "/api"}],
+  "security": [{"apiKey": []}],
+  "components": {
+    "securitySchemes": {"apiKey": {"type": "apiKey", "in": "header", "name": "X-API-Key"}},
+    "parameters": {
+      "PetID": {"name": "petId", "in": "path", "required": true, "description": "Pet ID", "schema": {"type": "integer"}}
+    },
+    "schemas": {
+      "NewPet": {
+        "type": "object",
+        "required": ["name"],
+        "properties": {
+          "name": {"type": "string"},
+          "tag": {"type": "string", "enum": ["cat", "dog"]},
+          "owner": {"$ref": "#/components/schemas/Owner"}
+        }
+      },
+      "Owner": {"type": "object", "properties": {"name": {"type": "string"}, "pets": {"type": "array", "items": {"$ref": "#/components/schemas/NewPet"}}}}
+    }
+  },
+  "paths": {
+    "/pets": {
+      "get": {
+        "operationId": "listPets",
+        "summary": "List pets",
+        "tags": ["pets"],
+        "parameters": [
+          {"name": "limit", "in": "query", "schema": {"type": "integer", "maximum": 100}},
+          {"name": "tags", "in": "query", "schema": {"type": "array", "items": {"type": "string"}}}
+        ]
+      },
+      "post": {
+        "operationId": "createPet",
+        "summary": "Create a pet",
+        "requestBody": {"required": true, "content": {"application/json": {"schema": {"$ref": "#/components/schemas/NewPet"}}}}
+      }
+    },
+    "/pets/{petId}": {
+      "parameters": [{"$ref": "#/components/parameters/PetID"}],
+      "get": {"operationId": "getPet", "summary": "Get a pet", "security": []},
+      "delete": {"summary": "Delete a pet", "tags": ["admin"]}
+    }
+  }
+}`
+
+// newPetstoreServer 启动同时提供规范和 API 的测试服务器，API 回显收到的请求
+func newPetstoreServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, petstoreSpec)
+	})
+	mux.HandleFunc("/api/", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{
+			"method": r.Method,
+			"path":   r.URL.Path,
+			"query":  r.URL.RawQuery,
+			"body":   string(body),
+			"apiKey": r.Header.Get("X-API-Key"),
+		})
+	})
+	ts := httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// toolsByName 按名称索引工具
+func toolsByName(list []tools.Tool) map[string]tools.Tool {
+	result := make(map[string]tools.Tool, len(list))
+	for _, tool := range list {
+		result[tool.Name()] = tool
+	}
+	return result
+}
+
+func TestOpenAPI_NewToolsFromSpec(t *testing.T) {
+	ts := newPetstoreServer(t)
+	list, err := openapi.NewToolsFromSpec(context.Background(), ts.URL+"/openapi.json", openapi.WithAPIKey("secret"))
+	if err != nil {
+		t.Fatalf("NewToolsFromSpec() error = %v", err)
+	}
+	byName := toolsByName(list)
+	for _, name := range []string{"listPets", "createPet", "getPet", "delete_pets_petId"} {
+		if byName[name] == nil {
+			t.Fatalf("tools = %v, missing %s", len(list), name)
+		}
+	}
+
+	params := byName["listPets"].Parameters()
+	if params.Properties["limit"].Type != "integer" || *params.Properties["limit"].Maximum != 100 {
+		t.Errorf("limit schema = %+v", params.Properties["limit"])
+	}
+	if params.Properties["tags"].Items == nil || params.Properties["tags"].Items.Type != "string" {
+		t.Errorf("tags schema = %+v", params.Properties["tags"])
+	}
+
+	create := byName["createPet"].Parameters()
+	body := create.Properties["body"]
+	if body.Properties["name"].Type != "string" || len(body.Properties["tag"].Enum) != 2 || len(create.Required) != 1 {
+		t.Errorf("createPet schema = %+v", create)
+	}
+	// 循环引用被截断而不是无限展开
+	if body.Properties["owner"].Properties["pets"].Items == nil {
+		t.Errorf("owner schema = %+v", body.Properties["owner"])
+	}
+
+	get := byName["getPet"].Parameters()
+	if get.Properties["petId"].Description != "Pet ID" || len(get.Required) != 1 || get.Required[0] != "petId" {
+		t.Errorf("getPet schema = %+v", get)
+	}
+	if !strings.Contains(byName["getPet"].Description(), "GET /pets/{petId}") {
+		t.Errorf("Description() = %q", byName["getPet"].Description())
+	}
+}
+
+func TestOpenAPI_Execute(t *testing.T) {
+	ts := newPetstoreServer(t)
+	list, err := openapi.NewToolsFromSpec(context.Background(), ts.URL+"/openapi.json", openapi.WithAPIKey("secret"))
+	if err != nil {
+		t.Fatalf("NewToolsFromSpec() error = %v", err)
+	}
+	byName := toolsByName(list)
+	ctx := context.Background()
+
+	result, err := byName["listPets"].Execute(ctx, map[string]interface{}{
+		"limit": float64(10),
+		"tags":  []interface{}{"a", "b"},
+	})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	for _, want := range []string{"HTTP 200", `"path":"/api/pets"`, `"query":"limit=10\u0026tags=a\u0026tags=b"`, `"apiKey":"secret"`} {
+		if !strings.Contains(result, want) {
+			t.Errorf("listPets result missing %s: %s", want, result)
+		}
+	}
+
+	result, err = byName["createPet"].Execute(ctx, map[string]interface{}{
+		"body": map[string]interface{}{"name": "Rex"},
+	})
+	if err != nil || !strings.Contains(result, `"method":"POST"`) || !strings.Contains(result, `{\"name\":\"Rex\"}`) {
+		t.Errorf("createPet = %s, %v", result, err)
+	}
+	if _, err := byName["createPet"].Execute(ctx, nil); err == nil {
+		t.Error("createPet without body error = nil, want missing parameter")
+	}
+
+	// 操作声明 security: [] 时不注入 API Key
+	result, err = byName["getPet"].Execute(ctx, map[string]interface{}{"petId": float64(7)})
+	if err != nil || !strings.Contains(result, `"path":"/api/pets/7"`) || !strings.Contains(result, `"apiKey":""`) {
+		t.Errorf("getPet = %s, %v", result, err)
+	}
+}
+
+func TestOpenAPI_OptionsAndSwagger2(t *testing.T) {
+	var gotAuth string
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		io.WriteString(w, strings.Repeat("x", 100))
+	}))
+	t.Cleanup(api.Close)
+
+	spec := `{
+	  "swagger": "2.0",
+	  "host": "example.invalid",
+	  "basePath": "/v1",
+	  "paths": {
+	    "/users/{id}": {
+	      "get": {"operationId": "users.get", "tags": ["users"], "parameters": [{"name": "id", "in": "path", "type": "string", "required": true}]},
+	      "put": {"operationId": "users.update", "tags": ["users"], "parameters": [
+	        {"name": "id", "in": "path", "type": "string", "required": true},
+	        {"name": "user", "in": "body", "schema": {"type": "object", "properties": {"email": {"type": "string"}}}}
+	      ]}
+	    },
+	    "/admin": {"post": {"operationId": "admin", "tags": ["admin"]}}
+	  }
+	}`
+	list, err := openapi.NewToolsFromSpec(context.Background(), spec,
+		openapi.WithBaseURL(api.URL),
+		openapi.WithBearerToken("token"),
+		openapi.WithToolPrefix("crm_"),
+		openapi.WithMaxResponseChars(10),
+		openapi.WithOperationFilter(func(op openapi.Operation) bool { return op.Tags[0] == "users" }),
+	)
+	if err != nil {
+		t.Fatalf("NewToolsFromSpec() error = %v", err)
+	}
+	byName := toolsByName(list)
+	if len(list) != 2 || byName["crm_users_get"] == nil || byName["crm_users_update"] == nil {
+		t.Fatalf("tools = %v", byName)
+	}
+	if byName["crm_users_update"].Parameters().Properties["body"].Properties["email"].Type != "string" {
+		t.Errorf("users.update schema = %+v", byName["crm_users_update"].Parameters())
+	}
+
+	result, err := byName["crm_users_get"].Execute(context.Background(), map[string]interface{}{"id": "a b"})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if gotAuth != "Bearer token" || !strings.Contains(result, "xxxxxxxxxx\n... [truncated") {
+		t.Errorf("auth = %q, result = %s", gotAuth, result)
+	}
+}
+
+func TestOpenAPI_InvalidSpecs(t *testing.T) {
+	ctx := context.Background()
+	cases := map[string]string{
+		"yaml":        "openapi: 3.0.0",
+		"version":     `{"openapi": "4.0"}`,
+		"no server":   `{"openapi": "3.0.0", "paths": {}}`,
+		"missing url": "https://127.0.0.1:0/openapi.json",
+	}
+	for name, source := range cases {
+		if _, err := openapi.NewToolsFromSpec(ctx, source); err == nil {
+			t.Errorf("%s: error = nil, want error", name)
+		}
+	}
+}
+
+func TestOpenAPI_Provider(t *testing.T) {
+	ts := newPetstoreServer(t)
+	registry := tools.NewRegistry()
+	provider := openapi.NewProvider("petstore", ts.URL+"/openapi.json", openapi.WithToolPrefix("pet_"))
+	if err := registry.RegisterProvider(context.Background(), provider); err != nil {
+		t.Fatalf("RegisterProvider() error = %v", err)
+	}
+	if registry.Count() != 4 || !registry.Has("pet_listPets") {
+		t.Errorf("registry tools = %v", registry.List())
+	}
+}
@@ -0,0 +1,147 @@
+package tools_test
+
+import (
+	"context"
+	stderrors "errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ahhsitt/helloagents-go/pkg/core/errors"
+	"github.com/ahhsitt/helloagents-go/pkg/tools"
+)
+
+func TestPolicyMiddleware_RateLimit(t *testing.T) {
+	tool := tools.ApplyPolicy(newMockTool("search"), tools.ExecutionPolicy{MaxQPS: 10, Burst: 2})
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, err := tool.Execute(ctx, nil); err != nil {
+			t.Fatalf("Execute() #%d error = %v", i, err)
+		}
+	}
+	if _, err := tool.Execute(ctx, nil); !stderrors.Is(err, errors.ErrRateLimited) {
+		t.Errorf("Execute() over burst error = %v, want ErrRateLimited", err)
+	}
+
+	// 允许等待时排队到下一个令牌
+	waiting := tools.ApplyPolicy(newMockTool("search"), tools.ExecutionPolicy{MaxQPS: 20, Burst: 1, MaxWait: time.Second})
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if _, err := waiting.Execute(ctx, nil); err != nil {
+			t.Fatalf("Execute() with MaxWait #%d error = %v", i, err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 80*time.Millisecond {
+		t.Errorf("3 calls at 20 QPS took %v, want >= 100ms", elapsed)
+	}
+}
+
+func TestPolicyMiddleware_Concurrency(t *testing.T) {
+	var running, peak int32
+	release := make(chan struct{})
+	slow := tools.NewFuncTool("slow", "Blocks until released", tools.ParameterSchema{Type: "object"},
+		func(ctx context.Context, args map[string]interface{}) (string, error) {
+			n := atomic.AddInt32(&running, 1)
+			for {
+				p := atomic.LoadInt32(&peak)
+				if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+					break
+				}
+			}
+			<-release
+			atomic.AddInt32(&running, -1)
+			return "done", nil
+		})
+	tool := tools.ApplyPolicy(slow, tools.ExecutionPolicy{MaxConcurrent: 2})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tool.Execute(context.Background(), nil)
+		}()
+	}
+	for atomic.LoadInt32(&running) < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	if _, err := tool.Execute(context.Background(), nil); !stderrors.Is(err, errors.ErrToolBusy) {
+		t.Errorf("Execute() over concurrency limit error = %v, want ErrToolBusy", err)
+	}
+	close(release)
+	wg.Wait()
+
+	if peak != 2 {
+		t.Errorf("peak concurrency = %d, want 2", peak)
+	}
+	if out, err := tool.Execute(context.Background(), nil); err != nil || out != "done" {
+		t.Errorf("Execute() after release = %q, %v", out, err)
+	}
+}
+
+func TestPolicyMiddleware_CircuitBreaker(t *testing.T) {
+	var calls int32
+	var healthy atomic.Bool
+	flaky := tools.NewFuncTool("flaky", "Fails until healthy", tools.ParameterSchema{Type: "object"},
+		func(ctx context.Context, args map[string]interface{}) (string, error) {
+			atomic.AddInt32(&calls, 1)
+			if healthy.Load() {
+				return "ok", nil
+			}
+			return "", stderrors.New("upstream down")
+		})
+	tool := tools.ApplyPolicy(flaky, tools.ExecutionPolicy{FailureThreshold: 2, Cooldown: 50 * time.Millisecond})
+	ctx := context.Background()
+
+	tool.Execute(ctx, nil)
+	tool.Execute(ctx, nil)
+	if _, err := tool.Execute(ctx, nil); !stderrors.Is(err, errors.ErrCircuitOpen) {
+		t.Fatalf("Execute() after threshold error = %v, want ErrCircuitOpen", err)
+	}
+	if calls != 2 {
+		t.Errorf("tool called %d times, want 2 (open circuit must not call the tool)", calls)
+	}
+
+	// 冷却后试探失败，熔断器重新打开
+	time.Sleep(60 * time.Millisecond)
+	if _, err := tool.Execute(ctx, nil); err == nil || stderrors.Is(err, errors.ErrCircuitOpen) {
+		t.Errorf("probe error = %v, want tool error", err)
+	}
+	if _, err := tool.Execute(ctx, nil); !stderrors.Is(err, errors.ErrCircuitOpen) {
+		t.Errorf("Execute() after failed probe error = %v, want ErrCircuitOpen", err)
+	}
+
+	// 冷却后试探成功，熔断器关闭
+	time.Sleep(60 * time.Millisecond)
+	healthy.Store(true)
+	for i := 0; i < 3; i++ {
+		if out, err := tool.Execute(ctx, nil); err != nil || out != "ok" {
+			t.Errorf("Execute() after recovery = %q, %v", out, err)
+		}
+	}
+}
+
+func TestPolicyMiddleware_PerToolState(t *testing.T) {
+	registry := tools.NewRegistry()
+	registry.MustRegister(newMockTool("a"))
+	registry.MustRegister(newMockTool("b"))
+	registry.Use(tools.PolicyMiddleware(tools.ExecutionPolicy{MaxQPS: 1, Burst: 1}))
+
+	a, _ := registry.Get("a")
+	b, _ := registry.Get("b")
+	if _, err := a.Execute(context.Background(), nil); err != nil {
+		t.Fatalf("a.Execute() error = %v", err)
+	}
+	if _, err := b.Execute(context.Background(), nil); err != nil {
+		t.Errorf("b.Execute() error = %v, want independent limit", err)
+	}
+
+	// 再次 Use 重新包装工具，限流状态保留
+	registry.Use(tagMiddleware("x"))
+	a, _ = registry.Get("a")
+	if _, err := a.Execute(context.Background(), nil); !stderrors.Is(err, errors.ErrRateLimited) {
+		t.Errorf("a.Execute() after Use error = %v, want ErrRateLimited", err)
+	}
+}
@@ -0,0 +1,168 @@
+package tools_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	coreerrors "github.com/ahhsitt/helloagents-go/pkg/core/errors"
+	"github.com/ahhsitt/helloagents-go/pkg/tools"
+)
+
+// dynamicProvider 工具列表可在运行时修改的测试提供者
+type dynamicProvider struct {
+	tools.ChangeNotifier
+	name string
+
+	mu    sync.Mutex
+	tools []tools.Tool
+	err   error
+}
+
+func (p *dynamicProvider) Name() string { return p.name }
+
+func (p *dynamicProvider) Tools(ctx context.Context) ([]tools.Tool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]tools.Tool(nil), p.tools...), p.err
+}
+
+func (p *dynamicProvider) set(list ...tools.Tool) {
+	p.mu.Lock()
+	p.tools = list
+	p.mu.Unlock()
+	p.Notify()
+}
+
+// staticProvider 不支持变化通知的测试提供者
+type staticProvider struct {
+	name  string
+	tools []tools.Tool
+}
+
+func (p *staticProvider) Name() string { return p.name }
+func (p *staticProvider) Tools(ctx context.Context) ([]tools.Tool, error) {
+	return p.tools, nil
+}
+
+// waitEvent 等待指定类型和名称的事件
+func waitEvent(t *testing.T, events <-chan tools.ToolEvent, typ tools.ToolEventType, name string) tools.ToolEvent {
+	t.Helper()
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case event := <-events:
+			if event.Type == typ && event.Name == name {
+				return event
+			}
+		case <-timeout:
+			t.Fatalf("timed out waiting for %s event of %q", typ, name)
+			return tools.ToolEvent{}
+		}
+	}
+}
+
+func TestRegistry_StaticProvider(t *testing.T) {
+	registry := tools.NewRegistry()
+	provider := &staticProvider{name: "static", tools: []tools.Tool{newMockTool("a"), newMockTool("b")}}
+
+	if err := registry.RegisterProvider(context.Background(), provider); err != nil {
+		t.Fatalf("RegisterProvider() error = %v", err)
+	}
+	if !registry.Has("a") || !registry.Has("b") {
+		t.Fatalf("registry tools = %v, want a and b", registry.List())
+	}
+	err := registry.RegisterProvider(context.Background(), provider)
+	if !errors.Is(err, coreerrors.ErrToolProviderAlreadyRegistered) {
+		t.Errorf("RegisterProvider() duplicate error = %v", err)
+	}
+
+	if err := registry.UnregisterProvider("static"); err != nil {
+		t.Fatalf("UnregisterProvider() error = %v", err)
+	}
+	if registry.Count() != 0 || len(registry.Providers()) != 0 {
+		t.Errorf("after UnregisterProvider: tools = %v, providers = %v", registry.List(), registry.Providers())
+	}
+	if err := registry.UnregisterProvider("static"); !errors.Is(err, coreerrors.ErrToolProviderNotFound) {
+		t.Errorf("UnregisterProvider() missing error = %v", err)
+	}
+}
+
+func TestRegistry_WatchableProvider(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	registry := tools.NewRegistry()
+	events := registry.Watch(ctx)
+	provider := &dynamicProvider{name: "dyn", tools: []tools.Tool{newMockTool("search")}}
+	if err := registry.RegisterProvider(context.Background(), provider); err != nil {
+		t.Fatalf("RegisterProvider() error = %v", err)
+	}
+	event := waitEvent(t, events, tools.EventToolRegistered, "search")
+	if event.Provider != "dyn" || event.Timestamp.IsZero() {
+		t.Errorf("event = %+v", event)
+	}
+	version := registry.Version()
+
+	// 热注册：提供者新增工具后注册表自动刷新
+	provider.set(newMockTool("search"), newMockTool("fetch"))
+	waitEvent(t, events, tools.EventToolRegistered, "fetch")
+	if !registry.Has("fetch") || registry.Version() <= version {
+		t.Errorf("after add: tools = %v, version = %d", registry.List(), registry.Version())
+	}
+
+	// 定义变化发出更新事件
+	changed := newMockTool("search")
+	changed.description = "Search v2"
+	provider.set(changed, newMockTool("fetch"))
+	waitEvent(t, events, tools.EventToolUpdated, "search")
+	if tool, _ := registry.Get("search"); tool.Description() != "Search v2" {
+		t.Errorf("Description() = %q, want Search v2", tool.Description())
+	}
+
+	provider.set(changed)
+	waitEvent(t, events, tools.EventToolUnregistered, "fetch")
+	if registry.Has("fetch") {
+		t.Error("fetch still registered after provider removed it")
+	}
+
+	// 取消注册后不再响应通知
+	registry.UnregisterProvider("dyn")
+	provider.set(newMockTool("late"))
+	time.Sleep(20 * time.Millisecond)
+	if registry.Count() != 0 {
+		t.Errorf("tools after UnregisterProvider = %v", registry.List())
+	}
+}
+
+func TestRegistry_ProviderConflicts(t *testing.T) {
+	registry := tools.NewRegistry()
+	registry.MustRegister(newMockTool("shared"))
+
+	provider := &dynamicProvider{name: "dyn", tools: []tools.Tool{newMockTool("shared"), newMockTool("own")}}
+	err := registry.RegisterProvider(context.Background(), provider)
+	if !errors.Is(err, coreerrors.ErrToolAlreadyRegistered) {
+		t.Errorf("RegisterProvider() error = %v, want ErrToolAlreadyRegistered", err)
+	}
+	if !registry.Has("own") {
+		t.Error("non-conflicting tool not registered")
+	}
+
+	// 提供者失败且没有返回工具时保留现有工具
+	provider.mu.Lock()
+	provider.tools, provider.err = nil, errors.New("upstream down")
+	provider.mu.Unlock()
+	if err := registry.RefreshProvider(context.Background(), "dyn"); err == nil {
+		t.Error("RefreshProvider() error = nil, want provider error")
+	}
+	if !registry.Has("own") || !registry.Has("shared") {
+		t.Errorf("tools after failed refresh = %v", registry.List())
+	}
+
+	registry.UnregisterProvider("dyn")
+	if !registry.Has("shared") || registry.Has("own") {
+		t.Errorf("tools after UnregisterProvider = %v, want shared only", registry.List())
+	}
+}
@@ -0,0 +1,104 @@
+package tools_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	coreerrors "github.com/ahhsitt/helloagents-go/pkg/core/errors"
+	"github.com/ahhsitt/helloagents-go/pkg/tools"
+)
+
+func TestScratchpad_Limits(t *testing.T) {
+	scratch := tools.NewScratchpad(
+		tools.WithScratchMaxEntrySize(8),
+		tools.WithScratchMaxTotalSize(12),
+	)
+
+	if err := scratch.Put("a", "12345678"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if err := scratch.Put("b", "123456789"); !errors.Is(err, coreerrors.ErrScratchEntryTooLarge) {
+		t.Errorf("expected ErrScratchEntryTooLarge, got %v", err)
+	}
+	if err := scratch.Put("b", "12345"); !errors.Is(err, coreerrors.ErrScratchFull) {
+		t.Errorf("expected ErrScratchFull, got %v", err)
+	}
+
+	// 覆盖同名条目按新大小计算
+	if err := scratch.Put("a", "1234"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if err := scratch.Put("b", "12345678"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if scratch.Size() != 12 {
+		t.Errorf("Size() = %d, want 12", scratch.Size())
+	}
+
+	scratch.Delete("a")
+	if _, ok := scratch.Get("a"); ok {
+		t.Error("expected a to be deleted")
+	}
+	if keys := scratch.Keys(); len(keys) != 1 || keys[0] != "b" {
+		t.Errorf("Keys() = %v, want [b]", keys)
+	}
+
+	scratch.Clear()
+	if scratch.Size() != 0 || len(scratch.Keys()) != 0 {
+		t.Error("expected empty scratchpad after Clear")
+	}
+}
+
+func TestExecutor_SurfacesScratchEntries(t *testing.T) {
+	registry := tools.NewRegistry()
+	_ = registry.Register(tools.NewSimpleTool("produce", "store data", "input", "unused",
+		func(ctx context.Context, _ string) (string, error) {
+			scratch, ok := tools.ScratchpadFromContext(ctx)
+			if !ok {
+				return "", errors.New("no scratchpad")
+			}
+			if err := scratch.Put("rows", "id,name\n1,go"); err != nil {
+				return "", err
+			}
+			return "stored 1 row", nil
+		},
+	))
+	_ = registry.Register(tools.NewSimpleTool("consume", "read data", "input", "unused",
+		func(ctx context.Context, _ string) (string, error) {
+			scratch, _ := tools.ScratchpadFromContext(ctx)
+			if err := scratch.Surface("rows"); err != nil {
+				return "", err
+			}
+			return "surfaced rows", nil
+		},
+	))
+
+	executor := tools.NewExecutor(registry)
+	ctx := tools.ContextWithScratchpad(context.Background(), tools.NewScratchpad())
+
+	result := executor.Execute(ctx, "produce", map[string]interface{}{"input": ""})
+	if !result.Success || result.Result != "stored 1 row" {
+		t.Fatalf("expected produce result without scratch data, got %+v", result)
+	}
+
+	result = executor.Execute(ctx, "consume", map[string]interface{}{"input": ""})
+	if !result.Success {
+		t.Fatalf("consume failed: %s", result.Error)
+	}
+	if !strings.HasSuffix(result.Result, "[scratch:rows]\nid,name\n1,go") {
+		t.Errorf("expected surfaced entry in observation, got %q", result.Result)
+	}
+
+	// 展示过的条目不会重复出现
+	result = executor.Execute(ctx, "produce", map[string]interface{}{"input": ""})
+	if strings.Contains(result.Result, "[scratch:") {
+		t.Errorf("expected surfaced entries to be drained, got %q", result.Result)
+	}
+
+	// 未经 Agent 运行的 context 中没有暂存区
+	if _, ok := tools.ScratchpadFromContext(context.Background()); ok {
+		t.Error("expected no scratchpad in background context")
+	}
+}
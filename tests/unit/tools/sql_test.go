@@ -0,0 +1,199 @@
+package tools_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/ahhsitt/helloagents-go/pkg/tools/builtin"
+)
+
+// newSQLiteDB 创建包含示例数据的 SQLite 数据库，返回文件路径
+func newSQLiteDB(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "test.db")
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	stmts := []string{
+		`CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT NOT NULL, bio TEXT, score REAL DEFAULT 0)`,
+		`CREATE VIEW top_users AS SELECT name FROM users WHERE score > 50`,
+	}
+	for i := 1; i <= 30; i++ {
+		stmts = append(stmts, fmt.Sprintf(`INSERT INTO users (name, bio, score) VALUES ('user%d', 'likes a|b', %d)`, i, i*3))
+	}
+	stmts = append(stmts, `INSERT INTO users (name, bio) VALUES ('multi', 'line one
+line two')`)
+	for _, stmt := range stmts {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+	return path
+}
+
+func TestSQLTool_Introspection(t *testing.T) {
+	tool, err := builtin.NewSQLTool(builtin.SQLDialectSQLite, newSQLiteDB(t))
+	if err != nil {
+		t.Fatalf("NewSQLTool() error = %v", err)
+	}
+	defer tool.Close()
+	ctx := context.Background()
+
+	tables, err := tool.Execute(ctx, map[string]interface{}{"action": "list_tables"})
+	if err != nil {
+		t.Fatalf("list_tables error = %v", err)
+	}
+	if !strings.Contains(tables, "| top_users | view |") || !strings.Contains(tables, "| users | table |") {
+		t.Errorf("list_tables = %q", tables)
+	}
+
+	desc, err := tool.Execute(ctx, map[string]interface{}{"action": "describe_table", "table": "users"})
+	if err != nil {
+		t.Fatalf("describe_table error = %v", err)
+	}
+	for _, want := range []string{"| Column | Type | Nullable | Default | Key |", "| id | INTEGER | YES |  | PRI |", "| name | TEXT | NO |", "| score | REAL | YES | 0 |"} {
+		if !strings.Contains(desc, want) {
+			t.Errorf("describe_table missing %q:\n%s", want, desc)
+		}
+	}
+	if _, err := tool.Execute(ctx, map[string]interface{}{"action": "describe_table", "table": "missing"}); err == nil {
+		t.Error("describe_table(missing) error = nil")
+	}
+}
+
+func TestSQLTool_Query(t *testing.T) {
+	tool, err := builtin.NewSQLTool(builtin.SQLDialectSQLite, newSQLiteDB(t), builtin.WithSQLMaxRows(5))
+	if err != nil {
+		t.Fatalf("NewSQLTool() error = %v", err)
+	}
+	defer tool.Close()
+	ctx := context.Background()
+
+	out, err := tool.Execute(ctx, map[string]interface{}{"action": "query", "sql": "SELECT name, bio, replace(name, 'user', 'u') AS short FROM users ORDER BY id"})
+	if err != nil {
+		t.Fatalf("query error = %v", err)
+	}
+	want := "| name | bio | short |\n| --- | --- | --- |\n| user1 | likes a\\|b | u1 |\n"
+	if !strings.HasPrefix(out, want) || !strings.Contains(out, "5 rows shown, more rows omitted by row limit 5") {
+		t.Errorf("query = %q", out)
+	}
+
+	out, _ = tool.Execute(ctx, map[string]interface{}{"action": "query", "sql": "SELECT bio, NULL AS empty FROM users WHERE name = 'multi'"})
+	if !strings.Contains(out, "| line one line two | NULL |") || !strings.Contains(out, "(1 rows)") {
+		t.Errorf("query with newline and NULL = %q", out)
+	}
+	out, _ = tool.Execute(ctx, map[string]interface{}{"action": "query", "sql": "SELECT id FROM users WHERE id < 0"})
+	if out != "Query returned no rows (columns: id)" {
+		t.Errorf("empty query = %q", out)
+	}
+}
+
+func TestSQLTool_ReadOnly(t *testing.T) {
+	path := newSQLiteDB(t)
+	tool, err := builtin.NewSQLTool(builtin.SQLDialectSQLite, path)
+	if err != nil {
+		t.Fatalf("NewSQLTool() error = %v", err)
+	}
+	defer tool.Close()
+	ctx := context.Background()
+
+	rejected := []string{
+		"DELETE FROM users",
+		"SELECT 1; DROP TABLE users",
+		"WITH x AS (DELETE FROM users RETURNING *) SELECT * FROM x",
+		"SELECT * INTO backup FROM users",
+		"/* SELECT */ UPDATE users SET name = 'x'",
+		"PRAGMA writable_schema = ON",
+	}
+	for _, stmt := range rejected {
+		if err := tool.Validate(map[string]interface{}{"action": "query", "sql": stmt}); err == nil {
+			t.Errorf("Validate(%q) error = nil, want rejected", stmt)
+		}
+	}
+
+	allowed := []string{
+		"SELECT 'DELETE FROM users; DROP TABLE users' AS s",
+		"SELECT count(*) FROM users -- DROP TABLE users",
+		"SELECT \"update\" FROM (SELECT 1 AS \"update\");",
+	}
+	for _, stmt := range allowed {
+		if _, err := tool.Execute(ctx, map[string]interface{}{"action": "query", "sql": stmt}); err != nil {
+			t.Errorf("Execute(%q) error = %v", stmt, err)
+		}
+	}
+
+	writable, err := builtin.NewSQLTool(builtin.SQLDialectSQLite, path, builtin.WithSQLReadOnly(false))
+	if err != nil {
+		t.Fatalf("NewSQLTool() error = %v", err)
+	}
+	defer writable.Close()
+	out, err := writable.Execute(ctx, map[string]interface{}{"action": "query", "sql": "DELETE FROM users WHERE score < 10"})
+	if err != nil || out != "Statement executed, 4 row(s) affected" {
+		t.Errorf("write query = %q, %v", out, err)
+	}
+	if err := writable.Validate(map[string]interface{}{"action": "query", "sql": "DELETE FROM a; DELETE FROM b"}); err == nil {
+		t.Error("Validate(multiple statements) error = nil")
+	}
+}
+
+func TestSQLTool_TokenBudget(t *testing.T) {
+	tool, err := builtin.NewSQLTool(builtin.SQLDialectSQLite, newSQLiteDB(t), builtin.WithSQLMaxTokens(60))
+	if err != nil {
+		t.Fatalf("NewSQLTool() error = %v", err)
+	}
+	defer tool.Close()
+
+	out, err := tool.Execute(context.Background(), map[string]interface{}{"action": "query", "sql": "SELECT id, name, bio FROM users"})
+	if err != nil {
+		t.Fatalf("query error = %v", err)
+	}
+	rows := strings.Count(out, "\n| ") - 1
+	if rows < 1 || rows >= 31 || !strings.Contains(out, fmt.Sprintf("showing %d of 31 rows", rows)) {
+		t.Errorf("query with token budget = %q", out)
+	}
+}
+
+func TestSQLTool_UnsupportedDialect(t *testing.T) {
+	if _, err := builtin.NewSQLTool("oracle", "dsn"); err == nil {
+		t.Error("NewSQLTool(oracle) error = nil")
+	}
+}
+
+func TestSQLTool_DialectQuoting(t *testing.T) {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "dialect.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// PostgreSQL 普通字符串不支持反斜杠转义，# 不是注释
+	pg, err := builtin.NewSQLTool(builtin.SQLDialectPostgres, "", builtin.WithSQLDB(db))
+	if err != nil {
+		t.Fatalf("NewSQLTool() error = %v", err)
+	}
+	for _, stmt := range []string{`SELECT 'a\'; DROP TABLE users; --'`, "SELECT 1 # 2; DROP TABLE users"} {
+		if err := pg.Validate(map[string]interface{}{"action": "query", "sql": stmt}); err == nil {
+			t.Errorf("postgres Validate(%q) error = nil", stmt)
+		}
+	}
+	if err := pg.Validate(map[string]interface{}{"action": "query", "sql": `SELECT E'it\'s; DROP', $$ DELETE $$`}); err != nil {
+		t.Errorf("postgres Validate(escaped strings) error = %v", err)
+	}
+
+	mysql, err := builtin.NewSQLTool(builtin.SQLDialectMySQL, "", builtin.WithSQLDB(db))
+	if err != nil {
+		t.Fatalf("NewSQLTool() error = %v", err)
+	}
+	if err := mysql.Validate(map[string]interface{}{"action": "query", "sql": `SELECT 'it\'s; DROP' # DELETE`}); err != nil {
+		t.Errorf("mysql Validate(escaped string) error = %v", err)
+	}
+}
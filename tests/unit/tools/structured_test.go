@@ -0,0 +1,97 @@
+package tools_test
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ahhsitt/helloagents-go/pkg/tools"
+)
+
+// weatherTool 返回结构化结果的测试工具
+type weatherTool struct{}
+
+func (w *weatherTool) Name() string        { return "weather" }
+func (w *weatherTool) Description() string { return "Get weather for a city" }
+func (w *weatherTool) Parameters() tools.ParameterSchema {
+	return tools.ParameterSchema{
+		Type:       "object",
+		Properties: map[string]tools.PropertySchema{"city": {Type: "string"}},
+		Required:   []string{"city"},
+	}
+}
+func (w *weatherTool) OutputSchema() tools.PropertySchema {
+	return tools.PropertySchema{
+		Type: "object",
+		Properties: map[string]tools.PropertySchema{
+			"city":        {Type: "string"},
+			"temperature": {Type: "number"},
+		},
+	}
+}
+func (w *weatherTool) ExecuteStructured(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	return map[string]interface{}{"city": args["city"], "temperature": 21.5}, nil
+}
+
+// Execute 故意不使用 ExecuteAsText，验证注册表的适配
+func (w *weatherTool) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
+	return "sunny", nil
+}
+
+func TestExecutor_StructuredResult(t *testing.T) {
+	registry := tools.NewRegistry()
+	registry.MustRegister(&weatherTool{})
+	registry.MustRegister(newMockTool("plain"))
+	registry.Use(tools.LoggingMiddleware(nil), tools.TimeoutMiddleware(0))
+	executor := tools.NewExecutor(registry)
+
+	result := executor.Execute(context.Background(), "weather", map[string]interface{}{"city": "Paris"})
+	if !result.Success {
+		t.Fatalf("Execute() error = %s", result.Error)
+	}
+	want := map[string]interface{}{"city": "Paris", "temperature": 21.5}
+	if !reflect.DeepEqual(result.Data, want) {
+		t.Errorf("Data = %#v, want %#v", result.Data, want)
+	}
+	if result.Result != `{"city":"Paris","temperature":21.5}` {
+		t.Errorf("Result = %q", result.Result)
+	}
+
+	if plain := executor.Execute(context.Background(), "plain", nil); plain.Data != nil || plain.Result != "mock result" {
+		t.Errorf("plain tool result = %+v", plain)
+	}
+}
+
+func TestFormatStructuredResult(t *testing.T) {
+	cases := []struct {
+		value interface{}
+		want  string
+	}{
+		{nil, ""},
+		{"text", "text"},
+		{[]int{1, 2}, "[1,2]"},
+		{struct {
+			Name string `json:"name"`
+		}{"go"}, `{"name":"go"}`},
+	}
+	for _, c := range cases {
+		if got := tools.FormatStructuredResult(c.value); got != c.want {
+			t.Errorf("FormatStructuredResult(%v) = %q, want %q", c.value, got, c.want)
+		}
+	}
+}
+
+func TestToolManual_OutputSchema(t *testing.T) {
+	registry := tools.NewRegistry()
+	registry.MustRegister(&weatherTool{})
+	tool, _ := registry.Get("weather")
+
+	manual := tools.NewToolManual(tool)
+	if manual.OutputSchema == nil || manual.OutputSchema.Properties["temperature"].Type != "number" {
+		t.Fatalf("OutputSchema = %+v", manual.OutputSchema)
+	}
+	if !strings.Contains(manual.Markdown(), "## Output Schema") {
+		t.Error("Markdown() missing output schema section")
+	}
+}
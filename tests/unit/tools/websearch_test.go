@@ -0,0 +1,186 @@
+package tools_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/ahhsitt/helloagents-go/pkg/tools/builtin"
+)
+
+// stubProvider 返回固定结果的搜索提供商
+type stubProvider struct {
+	results []builtin.SearchResult
+	err     error
+}
+
+func (p *stubProvider) Name() string { return "stub" }
+
+func (p *stubProvider) Search(ctx context.Context, query string, maxResults int) ([]builtin.SearchResult, error) {
+	return p.results, p.err
+}
+
+func TestSearchProviders(t *testing.T) {
+	var gotQuery url.Values
+	var gotHeader http.Header
+	var gotBody map[string]interface{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/serpapi", func(w http.ResponseWriter, r *http.Request) {
+		gotQuery = r.URL.Query()
+		io.WriteString(w, `{"organic_results":[{"title":"Go","link":"https://go.dev","snippet":"The Go language"}]}`)
+	})
+	mux.HandleFunc("/tavily", func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header
+		json.NewDecoder(r.Body).Decode(&gotBody)
+		io.WriteString(w, `{"results":[{"title":"Go","url":"https://go.dev","content":"The Go language"}]}`)
+	})
+	mux.HandleFunc("/bing", func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header
+		gotQuery = r.URL.Query()
+		io.WriteString(w, `{"webPages":{"value":[{"name":"Go","url":"https://go.dev","snippet":"The Go language"}]}}`)
+	})
+	mux.HandleFunc("/ddg", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		gotQuery = r.Form
+		io.WriteString(w, `<html><body>
+<div class="result results_links result--ad"><a class="result__a" href="https://ads.example">Ad</a></div>
+<div class="result results_links">
+  <h2><a class="result__a" href="//duckduckgo.com/l/?uddg=https%3A%2F%2Fgo.dev&rut=x">The <b>Go</b> Programming Language</a></h2>
+  <a class="result__snippet" href="#">The Go   language</a>
+</div>
+<div class="result results_links"><a class="result__a" href="https://pkg.go.dev">Packages</a></div>
+</body></html>`)
+	})
+	mux.HandleFunc("/error", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid key", http.StatusUnauthorized)
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	ctx := context.Background()
+
+	serp, _ := builtin.NewSerpAPIProvider("serp-key", builtin.WithSearchBaseURL(ts.URL+"/serpapi"))
+	tavily, _ := builtin.NewTavilyProvider("tavily-key", builtin.WithSearchBaseURL(ts.URL+"/tavily"))
+	bing, _ := builtin.NewBingProvider("bing-key", builtin.WithSearchBaseURL(ts.URL+"/bing"))
+	ddg := builtin.NewDuckDuckGoProvider(builtin.WithSearchBaseURL(ts.URL + "/ddg"))
+
+	for _, p := range []builtin.SearchProvider{serp, tavily, bing, ddg} {
+		results, err := p.Search(ctx, "golang", 1)
+		if err != nil {
+			t.Fatalf("%s Search() error = %v", p.Name(), err)
+		}
+		if len(results) != 1 || results[0].URL != "https://go.dev" || results[0].Snippet != "The Go language" {
+			t.Errorf("%s Search() = %+v", p.Name(), results)
+		}
+
+		switch p.Name() {
+		case "serpapi":
+			if gotQuery.Get("q") != "golang" || gotQuery.Get("api_key") != "serp-key" || gotQuery.Get("num") != "1" {
+				t.Errorf("serpapi query = %v", gotQuery)
+			}
+		case "tavily":
+			if gotHeader.Get("Authorization") != "Bearer tavily-key" || gotBody["query"] != "golang" || gotBody["max_results"] != float64(1) {
+				t.Errorf("tavily request = %v, %v", gotHeader, gotBody)
+			}
+		case "bing":
+			if gotHeader.Get("Ocp-Apim-Subscription-Key") != "bing-key" || gotQuery.Get("count") != "1" {
+				t.Errorf("bing request = %v, %v", gotHeader, gotQuery)
+			}
+		case "duckduckgo":
+			if gotQuery.Get("q") != "golang" || results[0].Title != "The Go Programming Language" {
+				t.Errorf("duckduckgo query = %v, result = %+v", gotQuery, results[0])
+			}
+		}
+	}
+
+	if _, err := builtin.NewTavilyProvider(""); !errors.Is(err, builtin.ErrSearchAPIKeyRequired) {
+		t.Errorf("NewTavilyProvider(\"\") error = %v", err)
+	}
+	failing, _ := builtin.NewBingProvider("bad", builtin.WithSearchBaseURL(ts.URL+"/error"))
+	if _, err := failing.Search(ctx, "golang", 1); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("Search() error = %v, want HTTP 401", err)
+	}
+}
+
+func TestWebSearch_Execute(t *testing.T) {
+	provider := &stubProvider{results: []builtin.SearchResult{
+		{Title: "First", URL: "https://a.example", Snippet: "first snippet"},
+		{Title: "Duplicate", URL: "https://a.example"},
+		{Title: "Second", URL: "https://b.example", Snippet: "second snippet"},
+		{Title: "Third", URL: "https://c.example"},
+	}}
+	search := builtin.NewWebSearch(provider)
+	ctx := context.Background()
+
+	results, err := search.Search(ctx, "q", 2)
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(results) != 2 || results[1].Title != "Second" || results[1].Rank != 2 {
+		t.Errorf("Search() = %+v, want deduplicated and re-ranked", results)
+	}
+
+	out, err := search.Execute(ctx, map[string]interface{}{"query": "golang", "max_results": float64(3)})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	for _, want := range []string{`"golang" (stub)`, "1. First", "URL: https://b.example", "second snippet", "3. Third"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Execute() output missing %q:\n%s", want, out)
+		}
+	}
+
+	if _, err := search.Execute(ctx, map[string]interface{}{"query": " "}); err == nil {
+		t.Error("Execute() with empty query error = nil")
+	}
+	provider.err = errors.New("quota exceeded")
+	if _, err := search.Execute(ctx, map[string]interface{}{"query": "golang"}); err == nil || !strings.Contains(err.Error(), "stub search failed") {
+		t.Errorf("Execute() error = %v, want provider error", err)
+	}
+}
+
+func TestWebSearch_FetchTop(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/article", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		io.WriteString(w, `<html><head><script>var x = 1;</script></head><body>
+<nav>Home | About</nav>
+<article><h1>Readable</h1><p>`+strings.Repeat("Main article text. ", 20)+`</p></article>
+</body></html>`)
+	})
+	mux.HandleFunc("/plain", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "plain text body")
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	provider := &stubProvider{results: []builtin.SearchResult{
+		{Title: "Article", URL: ts.URL + "/article"},
+		{Title: "Missing", URL: ts.URL + "/missing"},
+		{Title: "Plain", URL: ts.URL + "/plain"},
+	}}
+	search := builtin.NewWebSearch(provider, builtin.WithSearchFetchTop(2), builtin.WithSearchContentLimit(100))
+
+	results, err := search.Search(context.Background(), "q", 3)
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	article := results[0].Content
+	if !strings.Contains(article, "Main article text.") || strings.Contains(article, "var x") {
+		t.Errorf("article content = %q, want extracted main text", article)
+	}
+	if len([]rune(article)) > 103 {
+		t.Errorf("article content length = %d, want truncated to limit", len([]rune(article)))
+	}
+	if results[1].Content != "" || results[2].Content != "" {
+		t.Errorf("contents = %q, %q, want only successful top hits fetched", results[1].Content, results[2].Content)
+	}
+	if !strings.Contains(search.Description(), "top 2 pages") {
+		t.Errorf("Description() = %q", search.Description())
+	}
+}